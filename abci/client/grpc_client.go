@@ -76,7 +76,6 @@ func (cli *grpcClient) OnStart(ctx context.Context) error {
 			cli.mtx.Lock()
 			defer cli.mtx.Unlock()
 
-			reqres.SetDone()
 			reqres.Done()
 
 			// Notify client listener if set
@@ -85,9 +84,7 @@ func (cli *grpcClient) OnStart(ctx context.Context) error {
 			}
 
 			// Notify reqRes listener if set
-			if cb := reqres.GetCallback(); cb != nil {
-				cb(reqres.Response)
-			}
+			reqres.InvokeCallback()
 		}
 
 		for {
@@ -368,3 +365,35 @@ func (cli *grpcClient) ApplySnapshotChunk(
 	req := types.ToRequestApplySnapshotChunk(params)
 	return cli.client.ApplySnapshotChunk(ctx, req.GetApplySnapshotChunk(), grpc.WaitForReady(true))
 }
+
+func (cli *grpcClient) PrepareProposal(
+	ctx context.Context,
+	params types.RequestPrepareProposal) (*types.ResponsePrepareProposal, error) {
+
+	req := types.ToRequestPrepareProposal(params)
+	return cli.client.PrepareProposal(ctx, req.GetPrepareProposal(), grpc.WaitForReady(true))
+}
+
+func (cli *grpcClient) ProcessProposal(
+	ctx context.Context,
+	params types.RequestProcessProposal) (*types.ResponseProcessProposal, error) {
+
+	req := types.ToRequestProcessProposal(params)
+	return cli.client.ProcessProposal(ctx, req.GetProcessProposal(), grpc.WaitForReady(true))
+}
+
+func (cli *grpcClient) ExtendVote(
+	ctx context.Context,
+	params types.RequestExtendVote) (*types.ResponseExtendVote, error) {
+
+	req := types.ToRequestExtendVote(params)
+	return cli.client.ExtendVote(ctx, req.GetExtendVote(), grpc.WaitForReady(true))
+}
+
+func (cli *grpcClient) VerifyVoteExtension(
+	ctx context.Context,
+	params types.RequestVerifyVoteExtension) (*types.ResponseVerifyVoteExtension, error) {
+
+	req := types.ToRequestVerifyVoteExtension(params)
+	return cli.client.VerifyVoteExtension(ctx, req.GetVerifyVoteExtension(), grpc.WaitForReady(true))
+}
@@ -94,6 +94,11 @@ func (app *localClient) Info(ctx context.Context, req types.RequestInfo) (*types
 	app.mtx.Lock()
 	defer app.mtx.Unlock()
 
+	// The caller may have given up while waiting for the lock.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	res := app.Application.Info(req)
 	return &res, nil
 }
@@ -128,6 +133,11 @@ func (app *localClient) Query(
 	app.mtx.Lock()
 	defer app.mtx.Unlock()
 
+	// The caller may have given up while waiting for the lock.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	res := app.Application.Query(req)
 	return &res, nil
 }
@@ -222,10 +232,56 @@ func (app *localClient) ApplySnapshotChunk(
 	return &res, nil
 }
 
+func (app *localClient) PrepareProposal(
+	ctx context.Context,
+	req types.RequestPrepareProposal) (*types.ResponsePrepareProposal, error) {
+
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.PrepareProposal(req)
+	return &res, nil
+}
+
+func (app *localClient) ProcessProposal(
+	ctx context.Context,
+	req types.RequestProcessProposal) (*types.ResponseProcessProposal, error) {
+
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.ProcessProposal(req)
+	return &res, nil
+}
+
+func (app *localClient) ExtendVote(
+	ctx context.Context,
+	req types.RequestExtendVote) (*types.ResponseExtendVote, error) {
+
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.ExtendVote(req)
+	return &res, nil
+}
+
+func (app *localClient) VerifyVoteExtension(
+	ctx context.Context,
+	req types.RequestVerifyVoteExtension) (*types.ResponseVerifyVoteExtension, error) {
+
+	app.mtx.Lock()
+	defer app.mtx.Unlock()
+
+	res := app.Application.VerifyVoteExtension(req)
+	return &res, nil
+}
+
 //-------------------------------------------------------
 
 func (app *localClient) callback(req *types.Request, res *types.Response) *ReqRes {
-	app.Callback(req, res)
+	if app.Callback != nil {
+		app.Callback(req, res)
+	}
 	return newLocalReqRes(req, res)
 }
 
@@ -48,6 +48,62 @@ func TestProperSyncCalls(t *testing.T) {
 	}
 }
 
+func TestAbandonedQuery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := slowApp{}
+	logger := log.NewNopLogger()
+
+	_, c := setupClientServer(ctx, t, logger, app)
+
+	// Keep the application busy.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := c.BeginBlock(ctx, types.RequestBeginBlock{})
+		assert.NoError(t, err)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// The query gives up before the application answers.
+	qctx, qcancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer qcancel()
+	start := time.Now()
+	_, err := c.Query(qctx, types.RequestQuery{Path: "abandoned"})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+
+	// The client is still usable, and gets the response of the next query.
+	res, err := c.Query(ctx, types.RequestQuery{Path: "next"})
+	require.NoError(t, err)
+	assert.Equal(t, "next", res.Log)
+	assert.NoError(t, c.Error())
+	<-done
+}
+
+func TestCallbackAfterResponse(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, c := setupClientServer(ctx, t, log.NewNopLogger(), slowApp{})
+
+	reqRes, err := c.CheckTxAsync(ctx, types.RequestCheckTx{Tx: []byte("tx")})
+	require.NoError(t, err)
+	require.NoError(t, c.Flush(ctx))
+	reqRes.Wait()
+
+	// The callback is set after the response arrived, and is still called.
+	called := make(chan *types.Response, 1)
+	reqRes.SetCallback(func(res *types.Response) { called <- res })
+	select {
+	case res := <-called:
+		assert.NotNil(t, res.GetCheckTx())
+	case <-time.After(time.Second):
+		require.Fail(t, "callback not called")
+	}
+}
+
 func setupClientServer(
 	ctx context.Context,
 	t *testing.T,
@@ -83,3 +139,7 @@ func (slowApp) BeginBlock(req types.RequestBeginBlock) types.ResponseBeginBlock
 	time.Sleep(200 * time.Millisecond)
 	return types.ResponseBeginBlock{}
 }
+
+func (slowApp) Query(req types.RequestQuery) types.ResponseQuery {
+	return types.ResponseQuery{Log: req.Path}
+}
@@ -16,7 +16,10 @@ import (
 	"github.com/tendermint/tendermint/abci/example/code"
 	abciserver "github.com/tendermint/tendermint/abci/server"
 	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 )
 
 const (
@@ -191,6 +194,56 @@ func TestValUpdates(t *testing.T) {
 
 }
 
+func TestValidatorSetUpdates(t *testing.T) {
+	dir := t.TempDir()
+	logger := log.NewTestingLogger(t)
+
+	kvstore := NewPersistentKVStoreApplication(logger, dir)
+
+	authority := ed25519.GenPrivKey()
+	kvstore.SetValidatorAuthority("test-chain", authority.PubKey())
+
+	vals := RandVals(3)
+	kvstore.InitChain(types.RequestInitChain{Validators: vals[:2]})
+
+	signedTx := func(update tmtypes.ValidatorSetUpdate, key crypto.PrivKey) []byte {
+		bz, err := update.SignBytes()
+		require.NoError(t, err)
+		sig, err := key.Sign(bz)
+		require.NoError(t, err)
+		tx, err := update.Tx(sig)
+		require.NoError(t, err)
+		return tx
+	}
+
+	v := vals[2]
+	update := tmtypes.ValidatorSetUpdate{
+		ChainID:         "test-chain",
+		EffectiveHeight: 2,
+		Updates:         []types.ValidatorUpdate{v},
+	}
+	tx := signedTx(update, authority)
+
+	// updates not signed by the authority, or for another chain, are rejected
+	require.Equal(t, code.CodeTypeOK, kvstore.CheckTx(types.RequestCheckTx{Tx: tx}).Code)
+	require.Equal(t, code.CodeTypeUnauthorized,
+		kvstore.CheckTx(types.RequestCheckTx{Tx: signedTx(update, ed25519.GenPrivKey())}).Code)
+	otherChain := update
+	otherChain.ChainID = "other-chain"
+	require.Equal(t, code.CodeTypeUnauthorized,
+		kvstore.CheckTx(types.RequestCheckTx{Tx: signedTx(otherChain, authority)}).Code)
+
+	// the update is applied at its effective height, not when delivered
+	makeApplyBlock(t, kvstore, 1, nil, tx)
+	valsEqual(t, vals[:2], kvstore.Validators())
+	makeApplyBlock(t, kvstore, 2, []types.ValidatorUpdate{v})
+	valsEqual(t, vals, kvstore.Validators())
+	makeApplyBlock(t, kvstore, 3, nil)
+
+	// updates whose effective height has passed are rejected
+	require.Equal(t, code.CodeTypeUnauthorized, kvstore.CheckTx(types.RequestCheckTx{Tx: tx}).Code)
+}
+
 func makeApplyBlock(
 	t *testing.T,
 	kvstore types.Application,
@@ -3,6 +3,7 @@ package kvstore
 import (
 	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,13 +12,19 @@ import (
 
 	"github.com/tendermint/tendermint/abci/example/code"
 	"github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/encoding"
 	"github.com/tendermint/tendermint/libs/log"
 	cryptoproto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+	tmtypes "github.com/tendermint/tendermint/types"
 )
 
 const (
 	ValidatorSetChangePrefix string = "val:"
+
+	// the key prefix of the validator set updates of the validator authority
+	// waiting for their effective height
+	scheduledValidatorSetUpdatePrefix string = "valsched:"
 )
 
 //-----------------------------------------
@@ -32,6 +39,10 @@ type PersistentKVStoreApplication struct {
 
 	valAddrToPubKeyMap map[string]cryptoproto.PublicKey
 
+	// the validator authority allowed to update the validator set, if any
+	chainID      string
+	valAuthority crypto.PubKey
+
 	logger log.Logger
 }
 
@@ -51,6 +62,14 @@ func NewPersistentKVStoreApplication(logger log.Logger, dbDir string) *Persisten
 	}
 }
 
+// SetValidatorAuthority lets the validator authority of the chain chainID
+// update the validator set with the transactions carrying its signed
+// tmtypes.ValidatorSetUpdate.
+func (app *PersistentKVStoreApplication) SetValidatorAuthority(chainID string, authority crypto.PubKey) {
+	app.chainID = chainID
+	app.valAuthority = authority
+}
+
 func (app *PersistentKVStoreApplication) Close() error {
 	return app.app.state.db.Close()
 }
@@ -62,7 +81,8 @@ func (app *PersistentKVStoreApplication) Info(req types.RequestInfo) types.Respo
 	return res
 }
 
-// tx is either "val:pubkey!power" or "key=value" or just arbitrary bytes
+// tx is either "val:pubkey!power", a validator set update of the validator
+// authority, "key=value" or just arbitrary bytes
 func (app *PersistentKVStoreApplication) DeliverTx(req types.RequestDeliverTx) types.ResponseDeliverTx {
 	// if it starts with "val:", update the validator set
 	// format is "val:pubkey!power"
@@ -72,11 +92,21 @@ func (app *PersistentKVStoreApplication) DeliverTx(req types.RequestDeliverTx) t
 		return app.execValidatorTx(req.Tx)
 	}
 
+	// validator set updates are kept until their effective height
+	if tmtypes.IsValidatorSetUpdateTx(req.Tx) {
+		return app.scheduleValidatorSetUpdate(req.Tx)
+	}
+
 	// otherwise, update the key-value store
 	return app.app.DeliverTx(req)
 }
 
 func (app *PersistentKVStoreApplication) CheckTx(req types.RequestCheckTx) types.ResponseCheckTx {
+	if tmtypes.IsValidatorSetUpdateTx(req.Tx) {
+		if _, err := app.verifyValidatorSetUpdate(req.Tx); err != nil {
+			return types.ResponseCheckTx{Code: code.CodeTypeUnauthorized, Log: err.Error()}
+		}
+	}
 	return app.app.CheckTx(req)
 }
 
@@ -143,6 +173,7 @@ func (app *PersistentKVStoreApplication) BeginBlock(req types.RequestBeginBlock)
 
 // Update the validator set
 func (app *PersistentKVStoreApplication) EndBlock(req types.RequestEndBlock) types.ResponseEndBlock {
+	app.applyValidatorSetUpdates(req.Height)
 	return types.ResponseEndBlock{ValidatorUpdates: app.ValUpdates}
 }
 
@@ -166,6 +197,26 @@ func (app *PersistentKVStoreApplication) ApplySnapshotChunk(
 	return types.ResponseApplySnapshotChunk{Result: types.ResponseApplySnapshotChunk_ABORT}
 }
 
+func (app *PersistentKVStoreApplication) PrepareProposal(
+	req types.RequestPrepareProposal) types.ResponsePrepareProposal {
+	return types.ResponsePrepareProposal{Txs: req.Txs}
+}
+
+func (app *PersistentKVStoreApplication) ProcessProposal(
+	req types.RequestProcessProposal) types.ResponseProcessProposal {
+	return types.ResponseProcessProposal{Status: types.ResponseProcessProposal_ACCEPT}
+}
+
+func (app *PersistentKVStoreApplication) ExtendVote(
+	req types.RequestExtendVote) types.ResponseExtendVote {
+	return types.ResponseExtendVote{}
+}
+
+func (app *PersistentKVStoreApplication) VerifyVoteExtension(
+	req types.RequestVerifyVoteExtension) types.ResponseVerifyVoteExtension {
+	return types.ResponseVerifyVoteExtension{Status: types.ResponseVerifyVoteExtension_ACCEPT}
+}
+
 //---------------------------------------------
 // update validators
 
@@ -280,3 +331,84 @@ func (app *PersistentKVStoreApplication) updateValidator(v types.ValidatorUpdate
 
 	return types.ResponseDeliverTx{Code: code.CodeTypeOK}
 }
+
+//---------------------------------------------
+// validator set updates of the validator authority
+
+func scheduledValidatorSetUpdateKey(height int64, tx []byte) []byte {
+	return []byte(fmt.Sprintf("%s%020d:%X", scheduledValidatorSetUpdatePrefix, height, tmtypes.Tx(tx).Hash()))
+}
+
+// verifyValidatorSetUpdate decodes the validator set update carried by tx and
+// checks it was signed by the validator authority for a height to come.
+func (app *PersistentKVStoreApplication) verifyValidatorSetUpdate(tx []byte) (tmtypes.ValidatorSetUpdate, error) {
+	if app.valAuthority == nil {
+		return tmtypes.ValidatorSetUpdate{}, errors.New("no validator authority")
+	}
+	update, sig, err := tmtypes.ValidatorSetUpdateFromTx(tx)
+	if err != nil {
+		return update, err
+	}
+	if err := update.ValidateBasic(); err != nil {
+		return update, err
+	}
+	if update.ChainID != app.chainID {
+		return update, fmt.Errorf("wrong chain ID %q", update.ChainID)
+	}
+	if err := update.Verify(app.valAuthority, sig); err != nil {
+		return update, err
+	}
+	if update.EffectiveHeight <= app.app.state.Height {
+		return update, fmt.Errorf("effective height %d has passed", update.EffectiveHeight)
+	}
+	return update, nil
+}
+
+// scheduleValidatorSetUpdate stores the validator set update carried by tx
+// until its effective height.
+func (app *PersistentKVStoreApplication) scheduleValidatorSetUpdate(tx []byte) types.ResponseDeliverTx {
+	update, err := app.verifyValidatorSetUpdate(tx)
+	if err != nil {
+		return types.ResponseDeliverTx{
+			Code: code.CodeTypeUnauthorized,
+			Log:  fmt.Sprintf("invalid validator set update: %v", err)}
+	}
+	if err := app.app.state.db.Set(scheduledValidatorSetUpdateKey(update.EffectiveHeight, tx), tx); err != nil {
+		panic(err)
+	}
+	return types.ResponseDeliverTx{Code: code.CodeTypeOK}
+}
+
+// applyValidatorSetUpdates applies the validator set updates scheduled for
+// height.
+func (app *PersistentKVStoreApplication) applyValidatorSetUpdates(height int64) {
+	prefix := []byte(fmt.Sprintf("%s%020d:", scheduledValidatorSetUpdatePrefix, height))
+	itr, err := dbm.IteratePrefix(app.app.state.db, prefix)
+	if err != nil {
+		panic(err)
+	}
+	var keys, txs [][]byte
+	for ; itr.Valid(); itr.Next() {
+		keys = append(keys, append([]byte(nil), itr.Key()...))
+		txs = append(txs, append([]byte(nil), itr.Value()...))
+	}
+	if err := itr.Error(); err != nil {
+		panic(err)
+	}
+	itr.Close()
+
+	for i, tx := range txs {
+		update, _, err := tmtypes.ValidatorSetUpdateFromTx(tx)
+		if err != nil {
+			panic(err)
+		}
+		for _, vu := range update.Updates {
+			if r := app.updateValidator(vu); r.IsErr() {
+				app.logger.Error("error applying validator set update", "height", height, "r", r)
+			}
+		}
+		if err := app.app.state.db.Delete(keys[i]); err != nil {
+			panic(err)
+		}
+	}
+}
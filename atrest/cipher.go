@@ -0,0 +1,155 @@
+// Package atrest encrypts the data that a node stores on disk: the consensus
+// WAL, and the values of the blockstore and evidence databases.
+//
+// Data is encrypted with AES-GCM under keys supplied by a KeyProvider. Each
+// sealed value records the ID of the key it was encrypted with, so that keys
+// can be rotated: new data is encrypted with the current key, and data
+// encrypted with the previous keys stays readable as long as the provider
+// returns them. Reencrypt rewrites a database with the current key, after
+// which the previous keys can be retired.
+//
+// Data written before encryption was enabled is read as is, so encryption can
+// be enabled on an existing node; Reencrypt encrypts it as well.
+package atrest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tendermint/tendermint/crypto"
+)
+
+const (
+	// sealedMagic starts every sealed value. No protobuf message starts with
+	// it, as its wire type (6) is invalid, so sealed values can be told apart
+	// from the plaintext values written before encryption was enabled.
+	sealedMagic   = 0xfe
+	sealedVersion = 0x01
+
+	headerSize = 2 + 4 // magic, version, key ID
+	nonceSize  = 12
+
+	// Overhead is the number of bytes that sealing adds to a value.
+	Overhead = headerSize + nonceSize + 16
+)
+
+// ErrNotSealed is returned by Cipher.Open for data that was not sealed.
+var ErrNotSealed = errors.New("data is not encrypted")
+
+// Cipher seals and opens data with AES-GCM, using the keys of a KeyProvider.
+// It is safe for concurrent use. A nil *Cipher disables encryption wherever
+// one is accepted.
+type Cipher struct {
+	keys KeyProvider
+
+	mtx   sync.RWMutex
+	aeads map[uint32]cipher.AEAD
+}
+
+// NewCipher returns a Cipher encrypting with the keys of provider.
+func NewCipher(provider KeyProvider) *Cipher {
+	return &Cipher{
+		keys:  provider,
+		aeads: make(map[uint32]cipher.AEAD),
+	}
+}
+
+// IsSealed reports whether data was sealed by a Cipher.
+func IsSealed(data []byte) bool {
+	return len(data) >= headerSize && data[0] == sealedMagic && data[1] == sealedVersion
+}
+
+// KeyID returns the ID of the key that sealed data was encrypted with.
+func KeyID(data []byte) (uint32, error) {
+	if !IsSealed(data) {
+		return 0, ErrNotSealed
+	}
+	return binary.BigEndian.Uint32(data[2:headerSize]), nil
+}
+
+// Seal encrypts and authenticates plaintext with the current key, and
+// authenticates additionalData, which must be passed to Open again.
+func (c *Cipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	id, err := c.keys.CurrentKey()
+	if err != nil {
+		return nil, fmt.Errorf("getting the current encryption key: %w", err)
+	}
+	aead, err := c.aead(id)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, headerSize+nonceSize, Overhead+len(plaintext))
+	out[0], out[1] = sealedMagic, sealedVersion
+	binary.BigEndian.PutUint32(out[2:headerSize], id)
+	nonce := out[headerSize:]
+	copy(nonce, crypto.CRandBytes(nonceSize))
+	return aead.Seal(out, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts and authenticates data sealed by Seal with the same
+// additionalData. It returns ErrNotSealed if data was not sealed.
+func (c *Cipher) Open(data, additionalData []byte) ([]byte, error) {
+	id, err := KeyID(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < Overhead {
+		return nil, fmt.Errorf("encrypted data is too short: %d bytes", len(data))
+	}
+	aead, err := c.aead(id)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := data[headerSize : headerSize+nonceSize]
+	plaintext, err := aead.Open(nil, nonce, data[headerSize+nonceSize:], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting data with key %d: %w", id, err)
+	}
+	return plaintext, nil
+}
+
+// IsCurrent reports whether data is sealed with the current key.
+func (c *Cipher) IsCurrent(data []byte) (bool, error) {
+	current, err := c.keys.CurrentKey()
+	if err != nil {
+		return false, fmt.Errorf("getting the current encryption key: %w", err)
+	}
+	id, err := KeyID(data)
+	if errors.Is(err, ErrNotSealed) {
+		return false, nil
+	}
+	return id == current, err
+}
+
+func (c *Cipher) aead(id uint32) (cipher.AEAD, error) {
+	c.mtx.RLock()
+	aead, ok := c.aeads[id]
+	c.mtx.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	key, err := c.keys.Key(id)
+	if err != nil {
+		return nil, fmt.Errorf("getting encryption key %d: %w", id, err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key %d: %w", id, err)
+	}
+	aead, err = cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mtx.Lock()
+	c.aeads[id] = aead
+	c.mtx.Unlock()
+	return aead, nil
+}
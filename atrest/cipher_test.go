@@ -0,0 +1,168 @@
+package atrest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func TestCipher(t *testing.T) {
+	c := NewCipher(GenKeyring())
+
+	sealed, err := c.Seal([]byte("block"), []byte("H:1"))
+	require.NoError(t, err)
+	assert.True(t, IsSealed(sealed))
+	assert.Len(t, sealed, len("block")+Overhead)
+	assert.NotContains(t, string(sealed), "block")
+
+	plaintext, err := c.Open(sealed, []byte("H:1"))
+	require.NoError(t, err)
+	assert.Equal(t, "block", string(plaintext))
+
+	// The additional data is authenticated.
+	_, err = c.Open(sealed, []byte("H:2"))
+	assert.Error(t, err)
+
+	// So is the ciphertext.
+	sealed[len(sealed)-1] ^= 1
+	_, err = c.Open(sealed, []byte("H:1"))
+	assert.Error(t, err)
+
+	_, err = c.Open([]byte("block"), nil)
+	assert.ErrorIs(t, err, ErrNotSealed)
+	_, err = c.Open(sealed[:headerSize+nonceSize], []byte("H:1"))
+	assert.Error(t, err)
+}
+
+func TestKeyRotation(t *testing.T) {
+	keyring := GenKeyring()
+	c := NewCipher(keyring)
+
+	old, err := c.Seal([]byte("old"), nil)
+	require.NoError(t, err)
+	current, err := c.IsCurrent(old)
+	require.NoError(t, err)
+	assert.True(t, current)
+
+	assert.EqualValues(t, 2, keyring.Rotate())
+	sealed, err := c.Seal([]byte("new"), nil)
+	require.NoError(t, err)
+
+	id, err := KeyID(sealed)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, id)
+	current, err = c.IsCurrent(old)
+	require.NoError(t, err)
+	assert.False(t, current)
+
+	// The data encrypted with the previous key is still readable.
+	plaintext, err := c.Open(old, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(plaintext))
+
+	// Until the key is removed.
+	assert.Error(t, keyring.Remove(2), "the current key cannot be removed")
+	require.NoError(t, keyring.Remove(1))
+	_, err = NewCipher(keyring).Open(old, nil)
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "encryption_key.json")
+
+	keyring := GenKeyring()
+	keyring.Rotate()
+	require.NoError(t, keyring.Save(path))
+
+	loaded, err := LoadKeyFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, keyring, loaded)
+
+	_, err = NewKeyring(1, map[uint32][]byte{1: []byte("short")})
+	assert.Error(t, err)
+	_, err = NewKeyring(2, map[uint32][]byte{1: crypto.CRandBytes(KeySize)})
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+type staticProvider struct {
+	key []byte
+}
+
+func (p staticProvider) CurrentKey() (uint32, error)   { return 7, nil }
+func (p staticProvider) Key(id uint32) ([]byte, error) { return p.key, nil }
+
+func TestCipherFromConfig(t *testing.T) {
+	cfg := config.TestBaseConfig()
+	cfg.RootDir = t.TempDir()
+
+	c, err := CipherFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Nil(t, c, "encryption is disabled by default")
+
+	cfg.EncryptionKeyProvider = FileKeyProvider
+	_, err = CipherFromConfig(cfg)
+	assert.Error(t, err, "the key file does not exist")
+
+	require.NoError(t, GenKeyring().Save(cfg.EncryptionKeyFile()))
+	c, err = CipherFromConfig(cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, c)
+
+	cfg.EncryptionKeyProvider = "test-kms"
+	_, err = CipherFromConfig(cfg)
+	assert.Error(t, err, "the provider is not registered")
+
+	RegisterKeyProvider("test-kms", func(config.BaseConfig) (KeyProvider, error) {
+		return staticProvider{key: crypto.CRandBytes(KeySize)}, nil
+	})
+	c, err = CipherFromConfig(cfg)
+	require.NoError(t, err)
+	sealed, err := c.Seal([]byte("data"), nil)
+	require.NoError(t, err)
+	id, err := KeyID(sealed)
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, id)
+}
+
+// The overhead of encryption on the size of the values stored by a node: votes
+// and WAL messages are small, block parts are 64kB.
+var benchmarkSizes = []int{128, 1024, 64 * 1024}
+
+func BenchmarkSeal(b *testing.B) {
+	c := NewCipher(GenKeyring())
+	for _, size := range benchmarkSizes {
+		plaintext := crypto.CRandBytes(size)
+		b.Run(fmt.Sprintf("%dB", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Seal(plaintext, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkOpen(b *testing.B) {
+	c := NewCipher(GenKeyring())
+	for _, size := range benchmarkSizes {
+		sealed, err := c.Seal(crypto.CRandBytes(size), nil)
+		require.NoError(b, err)
+		b.Run(fmt.Sprintf("%dB", size), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Open(sealed, nil); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+package atrest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/config"
+)
+
+// reencryptBatchSize is the number of values rewritten per batch by
+// Reencrypt.
+const reencryptBatchSize = 1000
+
+// sealedMarkerKey is the key of the marker stored in a database once all its
+// values are encrypted: by Reencrypt, or by WrapDBProvider for a new database.
+// It doesn't collide with the keys of the blockstore and evidence databases,
+// which are encoded with orderedcode. Its value is sealed, like the others.
+var sealedMarkerKey = []byte("atrest:sealed")
+
+// ErrUnsealedValue is returned when reading a value stored unencrypted from a
+// database whose values were all encrypted.
+var ErrUnsealedValue = errors.New("unencrypted value in an encrypted database")
+
+// encryptedDB encrypts the values of a database. The keys are stored in
+// plaintext, so that the order of iteration is preserved: for the blockstore
+// and evidence databases, they only hold heights, hashes and evidence IDs.
+//
+// Each value is authenticated together with its key, so a value cannot be
+// moved to another key without being detected. Once the database is marked
+// sealed, a value stored unencrypted cannot be slipped in either.
+type encryptedDB struct {
+	db     dbm.DB
+	cipher *Cipher
+
+	// sealedOnce loads sealed, whether the database is marked sealed, the
+	// first time an unencrypted value is read. It can only be marked while
+	// the node is not running.
+	sealedOnce sync.Once
+	sealed     bool
+	sealedErr  error
+
+	// migrating allows the unencrypted values, for Reencrypt to encrypt them
+	// even if the database is marked sealed.
+	migrating bool
+}
+
+var _ dbm.DB = (*encryptedDB)(nil)
+
+// WrapDB returns a database storing the values of db encrypted with c. It
+// returns db if c is nil. Values that were stored unencrypted are returned as
+// is, until Reencrypt encrypted them all: they are then rejected with
+// ErrUnsealedValue.
+func WrapDB(db dbm.DB, c *Cipher) dbm.DB {
+	if c == nil {
+		return db
+	}
+	return &encryptedDB{db: db, cipher: c}
+}
+
+func (edb *encryptedDB) seal(key, value []byte) ([]byte, error) {
+	if value == nil {
+		// Let the database reject it.
+		return nil, nil
+	}
+	return edb.cipher.Seal(value, key)
+}
+
+func (edb *encryptedDB) open(key, value []byte) ([]byte, error) {
+	if IsSealed(value) {
+		return edb.cipher.Open(value, key)
+	}
+	if edb.migrating {
+		return value, nil
+	}
+	edb.sealedOnce.Do(func() {
+		edb.sealed, edb.sealedErr = edb.db.Has(sealedMarkerKey)
+	})
+	if edb.sealedErr != nil {
+		return nil, edb.sealedErr
+	}
+	if edb.sealed {
+		return nil, fmt.Errorf("value of key %X: %w", key, ErrUnsealedValue)
+	}
+	return value, nil
+}
+
+// markSealed marks db as having all its values encrypted with c.
+func markSealed(db dbm.DB, c *Cipher) error {
+	marker, err := c.Seal([]byte{1}, sealedMarkerKey)
+	if err != nil {
+		return err
+	}
+	return db.SetSync(sealedMarkerKey, marker)
+}
+
+// Get implements dbm.DB.
+func (edb *encryptedDB) Get(key []byte) ([]byte, error) {
+	value, err := edb.db.Get(key)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return edb.open(key, value)
+}
+
+// Has implements dbm.DB.
+func (edb *encryptedDB) Has(key []byte) (bool, error) {
+	return edb.db.Has(key)
+}
+
+// Set implements dbm.DB.
+func (edb *encryptedDB) Set(key, value []byte) error {
+	sealed, err := edb.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return edb.db.Set(key, sealed)
+}
+
+// SetSync implements dbm.DB.
+func (edb *encryptedDB) SetSync(key, value []byte) error {
+	sealed, err := edb.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return edb.db.SetSync(key, sealed)
+}
+
+// Delete implements dbm.DB.
+func (edb *encryptedDB) Delete(key []byte) error {
+	return edb.db.Delete(key)
+}
+
+// DeleteSync implements dbm.DB.
+func (edb *encryptedDB) DeleteSync(key []byte) error {
+	return edb.db.DeleteSync(key)
+}
+
+// Iterator implements dbm.DB.
+func (edb *encryptedDB) Iterator(start, end []byte) (dbm.Iterator, error) {
+	it, err := edb.db.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return newEncryptedIterator(it, edb), nil
+}
+
+// ReverseIterator implements dbm.DB.
+func (edb *encryptedDB) ReverseIterator(start, end []byte) (dbm.Iterator, error) {
+	it, err := edb.db.ReverseIterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return newEncryptedIterator(it, edb), nil
+}
+
+// Close implements dbm.DB.
+func (edb *encryptedDB) Close() error {
+	return edb.db.Close()
+}
+
+// NewBatch implements dbm.DB.
+func (edb *encryptedDB) NewBatch() dbm.Batch {
+	return &encryptedBatch{Batch: edb.db.NewBatch(), db: edb}
+}
+
+// Print implements dbm.DB. It prints the values encrypted.
+func (edb *encryptedDB) Print() error {
+	return edb.db.Print()
+}
+
+// Stats implements dbm.DB.
+func (edb *encryptedDB) Stats() map[string]string {
+	return edb.db.Stats()
+}
+
+type encryptedBatch struct {
+	dbm.Batch
+	db *encryptedDB
+}
+
+// Set implements dbm.Batch.
+func (b *encryptedBatch) Set(key, value []byte) error {
+	sealed, err := b.db.seal(key, value)
+	if err != nil {
+		return err
+	}
+	return b.Batch.Set(key, sealed)
+}
+
+// encryptedIterator decrypts the values of an iterator. A value that fails to
+// decrypt is returned as nil, and the error is reported by Error. The sealed
+// marker is skipped.
+type encryptedIterator struct {
+	dbm.Iterator
+	db  *encryptedDB
+	err error
+}
+
+func newEncryptedIterator(it dbm.Iterator, edb *encryptedDB) *encryptedIterator {
+	eit := &encryptedIterator{Iterator: it, db: edb}
+	eit.skipMarker()
+	return eit
+}
+
+func (it *encryptedIterator) skipMarker() {
+	if it.Iterator.Valid() && bytes.Equal(it.Iterator.Key(), sealedMarkerKey) {
+		it.Iterator.Next()
+	}
+}
+
+// Next implements dbm.Iterator.
+func (it *encryptedIterator) Next() {
+	it.Iterator.Next()
+	it.skipMarker()
+}
+
+// Valid implements dbm.Iterator.
+func (it *encryptedIterator) Valid() bool {
+	return it.err == nil && it.Iterator.Valid()
+}
+
+// Value implements dbm.Iterator.
+func (it *encryptedIterator) Value() []byte {
+	value, err := it.db.open(it.Iterator.Key(), it.Iterator.Value())
+	if err != nil {
+		it.err = err
+		return nil
+	}
+	return value
+}
+
+// Error implements dbm.Iterator.
+func (it *encryptedIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
+
+// Reencrypt rewrites the values of db, as stored by a database returned by
+// WrapDB, with the current key of c: the values encrypted with other keys, and
+// the values stored unencrypted. It returns the number of values rewritten.
+// It is the migration path of the databases written before encryption was
+// enabled: once done, db is marked sealed, and the values stored unencrypted
+// are rejected.
+//
+// Once all the databases and the WAL of a node have been rewritten after a key
+// rotation, the previous keys are not needed anymore. The node must not be
+// running.
+func Reencrypt(ctx context.Context, db dbm.DB, c *Cipher) (int, error) {
+	edb := &encryptedDB{db: db, cipher: c, migrating: true}
+	var (
+		start []byte
+		total int
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		// Not every backend allows writes while an iterator is open, so the
+		// values are read and rewritten in chunks.
+		keys, values, err := readChunk(db, start, reencryptBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(keys) == 0 {
+			return total, markSealed(db, c)
+		}
+
+		batch := edb.NewBatch()
+		pending := 0
+		for i, key := range keys {
+			if bytes.Equal(key, sealedMarkerKey) {
+				continue
+			}
+			current, err := c.IsCurrent(values[i])
+			if err != nil {
+				_ = batch.Close()
+				return total, err
+			}
+			if current {
+				continue
+			}
+			plaintext, err := edb.open(key, values[i])
+			if err != nil {
+				_ = batch.Close()
+				return total, fmt.Errorf("value of key %X: %w", key, err)
+			}
+			if err := batch.Set(key, plaintext); err != nil {
+				_ = batch.Close()
+				return total, err
+			}
+			pending++
+		}
+		if pending > 0 {
+			if err := batch.WriteSync(); err != nil {
+				_ = batch.Close()
+				return total, err
+			}
+			total += pending
+		}
+		_ = batch.Close()
+
+		// Resume after the last key.
+		start = append(keys[len(keys)-1], 0)
+	}
+}
+
+// readChunk returns copies of the first n keys and values of db from start.
+func readChunk(db dbm.DB, start []byte, n int) (keys, values [][]byte, err error) {
+	it, err := db.Iterator(start, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer it.Close()
+
+	for ; it.Valid() && len(keys) < n; it.Next() {
+		keys = append(keys, append([]byte(nil), it.Key()...))
+		values = append(values, append([]byte(nil), it.Value()...))
+	}
+	return keys, values, it.Error()
+}
+
+// EncryptedDBs are the IDs of the databases of a node that are encrypted at
+// rest.
+var EncryptedDBs = []string{"blockstore", "evidence"}
+
+// WrapDBProvider returns a DBProvider wrapping the databases of provider
+// listed in EncryptedDBs with WrapDB, marking the new ones sealed. It returns
+// provider if c is nil.
+func WrapDBProvider(provider config.DBProvider, c *Cipher) config.DBProvider {
+	if c == nil {
+		return provider
+	}
+	return func(ctx *config.DBContext) (dbm.DB, error) {
+		db, err := provider(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range EncryptedDBs {
+			if ctx.ID != id {
+				continue
+			}
+			// A new database never holds unencrypted values.
+			if err := markSealedIfEmpty(db, c); err != nil {
+				_ = db.Close()
+				return nil, fmt.Errorf("marking %s database sealed: %w", id, err)
+			}
+			return WrapDB(db, c), nil
+		}
+		return db, nil
+	}
+}
+
+// markSealedIfEmpty marks db sealed with markSealed if it is empty.
+func markSealedIfEmpty(db dbm.DB, c *Cipher) error {
+	it, err := db.Iterator(nil, nil)
+	if err != nil {
+		return err
+	}
+	empty := !it.Valid()
+	if err := it.Close(); err != nil {
+		return err
+	}
+	if !empty {
+		return nil
+	}
+	return markSealed(db, c)
+}
@@ -0,0 +1,197 @@
+package atrest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto"
+)
+
+func TestEncryptedDB(t *testing.T) {
+	raw := dbm.NewMemDB()
+	require.NoError(t, raw.Set([]byte("a"), []byte("written before encryption")))
+
+	db := WrapDB(raw, NewCipher(GenKeyring()))
+	require.NoError(t, db.Set([]byte("b"), []byte("value b")))
+	batch := db.NewBatch()
+	require.NoError(t, batch.Set([]byte("c"), []byte("value c")))
+	require.NoError(t, batch.Delete([]byte("b")))
+	require.NoError(t, batch.Set([]byte("d"), []byte("value d")))
+	require.NoError(t, batch.Write())
+	require.NoError(t, batch.Close())
+
+	// The values are stored encrypted, the keys are not.
+	stored, err := raw.Get([]byte("c"))
+	require.NoError(t, err)
+	assert.True(t, IsSealed(stored))
+	assert.NotContains(t, string(stored), "value c")
+
+	value, err := db.Get([]byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, "written before encryption", string(value))
+	value, err = db.Get([]byte("c"))
+	require.NoError(t, err)
+	assert.Equal(t, "value c", string(value))
+	value, err = db.Get([]byte("b"))
+	require.NoError(t, err)
+	assert.Nil(t, value)
+
+	it, err := db.ReverseIterator(nil, nil)
+	require.NoError(t, err)
+	var entries []string
+	for ; it.Valid(); it.Next() {
+		entries = append(entries, fmt.Sprintf("%s=%s", it.Key(), it.Value()))
+	}
+	require.NoError(t, it.Error())
+	require.NoError(t, it.Close())
+	assert.Equal(t, []string{"d=value d", "c=value c", "a=written before encryption"}, entries)
+
+	// A value is bound to its key.
+	require.NoError(t, raw.Set([]byte("e"), stored))
+	_, err = db.Get([]byte("e"))
+	assert.Error(t, err)
+
+	it, err = db.Iterator([]byte("e"), nil)
+	require.NoError(t, err)
+	require.True(t, it.Valid())
+	assert.Nil(t, it.Value())
+	assert.False(t, it.Valid())
+	assert.Error(t, it.Error())
+	require.NoError(t, it.Close())
+
+	assert.Equal(t, raw, WrapDB(raw, nil), "a nil cipher disables encryption")
+}
+
+func TestReencrypt(t *testing.T) {
+	ctx := context.Background()
+	keyring := GenKeyring()
+	c := NewCipher(keyring)
+
+	raw := dbm.NewMemDB()
+	db := WrapDB(raw, c)
+	for i := 0; i < 2*reencryptBatchSize+10; i++ {
+		key := []byte(fmt.Sprintf("key%05d", i))
+		if i%2 == 0 {
+			require.NoError(t, raw.Set(key, key))
+		} else {
+			require.NoError(t, db.Set(key, key))
+		}
+	}
+
+	// The unencrypted values are encrypted.
+	n, err := Reencrypt(ctx, raw, c)
+	require.NoError(t, err)
+	assert.Equal(t, reencryptBatchSize+5, n)
+
+	n, err = Reencrypt(ctx, raw, c)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// Once all the values are encrypted, an unencrypted value is rejected,
+	// until Reencrypt encrypts it.
+	require.NoError(t, raw.Set([]byte("key00000"), []byte("key00000")))
+	_, err = WrapDB(raw, c).Get([]byte("key00000"))
+	assert.ErrorIs(t, err, ErrUnsealedValue)
+	n, err = Reencrypt(ctx, raw, c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	value, err := WrapDB(raw, c).Get([]byte("key00000"))
+	require.NoError(t, err)
+	assert.Equal(t, "key00000", string(value))
+
+	// After a rotation, all the values are encrypted with the new key, and the
+	// previous key can be removed.
+	keyring.Rotate()
+	n, err = Reencrypt(ctx, raw, c)
+	require.NoError(t, err)
+	assert.Equal(t, 2*reencryptBatchSize+10, n)
+	require.NoError(t, keyring.Remove(1))
+
+	db = WrapDB(raw, NewCipher(keyring))
+	it, err := db.Iterator(nil, nil)
+	require.NoError(t, err)
+	defer it.Close()
+	count := 0
+	for ; it.Valid(); it.Next() {
+		assert.Equal(t, it.Key(), it.Value())
+		count++
+	}
+	require.NoError(t, it.Error())
+	assert.Equal(t, 2*reencryptBatchSize+10, count)
+}
+
+func TestWrapDBProvider(t *testing.T) {
+	cfg := config.TestConfig()
+	provider := WrapDBProvider(config.DefaultDBProvider, NewCipher(GenKeyring()))
+
+	for _, id := range EncryptedDBs {
+		db, err := provider(&config.DBContext{ID: id, Config: cfg})
+		require.NoError(t, err)
+		require.IsType(t, &encryptedDB{}, db, id)
+
+		// A new database is marked sealed, which the iterators skip.
+		raw := db.(*encryptedDB).db
+		require.NoError(t, raw.Set([]byte("a"), []byte("unencrypted")))
+		_, err = db.Get([]byte("a"))
+		assert.ErrorIs(t, err, ErrUnsealedValue, id)
+		it, err := db.Iterator(nil, nil)
+		require.NoError(t, err)
+		var keys []string
+		for ; it.Valid(); it.Next() {
+			keys = append(keys, string(it.Key()))
+		}
+		assert.Equal(t, []string{"a"}, keys, id)
+		require.NoError(t, it.Close())
+	}
+	db, err := provider(&config.DBContext{ID: "state", Config: cfg})
+	require.NoError(t, err)
+	assert.IsType(t, &dbm.MemDB{}, db)
+}
+
+// BenchmarkDB compares the writes and reads of a memory database, so that the
+// cost of the encryption is not hidden by the disk, with and without
+// encryption.
+func BenchmarkDB(b *testing.B) {
+	for _, encrypted := range []bool{false, true} {
+		for _, size := range benchmarkSizes {
+			name := fmt.Sprintf("encrypted=%t/%dB", encrypted, size)
+			value := crypto.CRandBytes(size)
+
+			newDB := func() dbm.DB {
+				var c *Cipher
+				if encrypted {
+					c = NewCipher(GenKeyring())
+				}
+				return WrapDB(dbm.NewMemDB(), c)
+			}
+
+			b.Run(name+"/Set", func(b *testing.B) {
+				db := newDB()
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := db.Set([]byte(fmt.Sprintf("key%d", i%1000)), value); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+			b.Run(name+"/Get", func(b *testing.B) {
+				db := newDB()
+				require.NoError(b, db.Set([]byte("key"), value))
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := db.Get([]byte("key")); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
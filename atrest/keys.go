@@ -0,0 +1,222 @@
+package atrest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
+	tmos "github.com/tendermint/tendermint/libs/os"
+)
+
+// KeySize is the size of the keys generated by the file key provider: AES-256.
+const KeySize = 32
+
+// ErrKeyNotFound is returned, wrapped, by a KeyProvider that does not have
+// the requested key.
+var ErrKeyNotFound = errors.New("encryption key not found")
+
+// KeyProvider provides the AES keys used to encrypt data at rest, e.g. from a
+// file or a key management service. A key is identified by an ID, which is
+// stored with the data it encrypts: the key with a given ID must never change.
+type KeyProvider interface {
+	// CurrentKey returns the ID of the key to encrypt new data with.
+	CurrentKey() (uint32, error)
+
+	// Key returns the key with the given ID, of 16, 24 or 32 bytes to select
+	// AES-128, AES-192 or AES-256. It is called once per key by a Cipher.
+	Key(id uint32) ([]byte, error)
+}
+
+// KeyProviderFunc returns the KeyProvider of a node.
+type KeyProviderFunc func(cfg config.BaseConfig) (KeyProvider, error)
+
+// FileKeyProvider is the name of the built-in key provider, which reads the
+// keys from the key file of the node. See Keyring.
+const FileKeyProvider = "file"
+
+var (
+	providersMtx sync.RWMutex
+	providers    = map[string]KeyProviderFunc{
+		FileKeyProvider: func(cfg config.BaseConfig) (KeyProvider, error) {
+			return LoadKeyFile(cfg.EncryptionKeyFile())
+		},
+	}
+)
+
+// RegisterKeyProvider registers the key provider selected by the
+// encryption-key-provider config option with the given name. This allows
+// builds to fetch the keys from a key management service. Registering a name
+// again replaces the previous provider.
+func RegisterKeyProvider(name string, provider KeyProviderFunc) {
+	providersMtx.Lock()
+	defer providersMtx.Unlock()
+
+	providers[name] = provider
+}
+
+// CipherFromConfig returns the Cipher of the node configured by cfg, or nil if
+// encryption at rest is disabled.
+func CipherFromConfig(cfg config.BaseConfig) (*Cipher, error) {
+	name := cfg.EncryptionKeyProvider
+	if name == "" {
+		return nil, nil
+	}
+
+	providersMtx.RLock()
+	newProvider, ok := providers[name]
+	providersMtx.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key provider %q", name)
+	}
+	provider, err := newProvider(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key provider %q: %w", name, err)
+	}
+	return NewCipher(provider), nil
+}
+
+// Keyring is a KeyProvider holding its keys in memory. It is stored in the
+// key file of the node as JSON:
+//
+//	{
+//	  "current_key": 2,
+//	  "keys": [
+//	    {"id": 1, "key": "<base64>"},
+//	    {"id": 2, "key": "<base64>"}
+//	  ]
+//	}
+type Keyring struct {
+	current uint32
+	keys    map[uint32][]byte
+}
+
+var _ KeyProvider = (*Keyring)(nil)
+
+type keyFile struct {
+	CurrentKey uint32         `json:"current_key"`
+	Keys       []keyFileEntry `json:"keys"`
+}
+
+type keyFileEntry struct {
+	ID  uint32 `json:"id"`
+	Key []byte `json:"key"`
+}
+
+// NewKeyring returns a Keyring with the given keys, by ID, which encrypts new
+// data with the key current.
+func NewKeyring(current uint32, keys map[uint32][]byte) (*Keyring, error) {
+	kr := &Keyring{current: current, keys: make(map[uint32][]byte, len(keys))}
+	for id, key := range keys {
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("key %d has invalid size %d (must be 16, 24 or 32 bytes)", id, len(key))
+		}
+		kr.keys[id] = key
+	}
+	if _, ok := kr.keys[current]; !ok {
+		return nil, fmt.Errorf("current key %d: %w", current, ErrKeyNotFound)
+	}
+	return kr, nil
+}
+
+// GenKeyring returns a Keyring with a new random key, of ID 1.
+func GenKeyring() *Keyring {
+	return &Keyring{
+		current: 1,
+		keys:    map[uint32][]byte{1: crypto.CRandBytes(KeySize)},
+	}
+}
+
+// LoadKeyFile loads the Keyring stored in the file at path.
+func LoadKeyFile(path string) (*Keyring, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var kf keyFile
+	if err := json.Unmarshal(bz, &kf); err != nil {
+		return nil, fmt.Errorf("decoding key file %s: %w", path, err)
+	}
+	keys := make(map[uint32][]byte, len(kf.Keys))
+	for _, entry := range kf.Keys {
+		if _, ok := keys[entry.ID]; ok {
+			return nil, fmt.Errorf("key file %s: duplicate key %d", path, entry.ID)
+		}
+		keys[entry.ID] = entry.Key
+	}
+	kr, err := NewKeyring(kf.CurrentKey, keys)
+	if err != nil {
+		return nil, fmt.Errorf("key file %s: %w", path, err)
+	}
+	return kr, nil
+}
+
+// Save atomically writes the Keyring to the file at path, readable by the
+// owner only.
+func (kr *Keyring) Save(path string) error {
+	kf := keyFile{CurrentKey: kr.current}
+	for id, key := range kr.keys {
+		kf.Keys = append(kf.Keys, keyFileEntry{ID: id, Key: key})
+	}
+	sort.Slice(kf.Keys, func(i, j int) bool { return kf.Keys[i].ID < kf.Keys[j].ID })
+
+	bz, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tmos.EnsureDir(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(path, bz, 0600)
+}
+
+// Rotate adds a new random key to the Keyring and makes it the current key.
+// It returns the ID of the new key.
+func (kr *Keyring) Rotate() uint32 {
+	var id uint32
+	for existing := range kr.keys {
+		if existing > id {
+			id = existing
+		}
+	}
+	id++
+	kr.keys[id] = crypto.CRandBytes(KeySize)
+	kr.current = id
+	return id
+}
+
+// Remove removes the key with the given ID from the Keyring, once no data is
+// encrypted with it anymore. The current key cannot be removed.
+func (kr *Keyring) Remove(id uint32) error {
+	if id == kr.current {
+		return fmt.Errorf("key %d is the current key", id)
+	}
+	if _, ok := kr.keys[id]; !ok {
+		return fmt.Errorf("key %d: %w", id, ErrKeyNotFound)
+	}
+	delete(kr.keys, id)
+	return nil
+}
+
+// CurrentKey implements KeyProvider.
+func (kr *Keyring) CurrentKey() (uint32, error) {
+	return kr.current, nil
+}
+
+// Key implements KeyProvider.
+func (kr *Keyring) Key(id uint32) ([]byte, error) {
+	key, ok := kr.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("key %d: %w", id, ErrKeyNotFound)
+	}
+	return key, nil
+}
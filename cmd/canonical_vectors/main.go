@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tendermint/tendermint/types/canonical"
+)
+
+// canonical_vectors prints the golden test vectors of the canonical encodings,
+// or verifies test vectors, e.g. produced by another implementation, against
+// this one.
+func main() {
+	var (
+		dump   = flag.Bool("dump", false, "print the golden test vectors as JSON and exit")
+		verify = flag.String("verify", "", "file of test vectors to verify, - for the standard input (defaults to the golden vectors)")
+	)
+	flag.Parse()
+
+	if *dump {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(canonical.Vectors()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	vectors := canonical.Vectors()
+	if *verify != "" {
+		var err error
+		if vectors, err = readVectors(*verify); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	failed := 0
+	for _, v := range vectors {
+		if err := v.Verify(); err != nil {
+			failed++
+			fmt.Printf("FAIL  %-8s %s: %v\n", v.Kind, v.Name, err)
+		} else {
+			fmt.Printf("PASS  %-8s %s\n", v.Kind, v.Name)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d vectors failed\n", failed, len(vectors))
+		os.Exit(1)
+	}
+	fmt.Printf("All %d vectors passed\n", len(vectors))
+}
+
+func readVectors(path string) ([]canonical.Vector, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return canonical.ReadVectors(r)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/privval/conformance"
+)
+
+// signer_conformance listens for a remote signer like a node would, and runs
+// the conformance checks of the privval protocol against it.
+func main() {
+	var (
+		addr           = flag.String("addr", "tcp://127.0.0.1:26659", "Address to listen on for the signer (tcp:// or unix://)")
+		chainID        = flag.String("chain-id", "mychain", "chain id the signer is configured with")
+		startHeight    = flag.Int64("start-height", 0, "first height to sign (defaults to the current Unix time)")
+		maxLatency     = flag.Duration("max-latency", time.Second, "longest acceptable response time")
+		connectTimeout = flag.Duration("connect-timeout", 30*time.Second, "how long to wait for the signer to (re)connect")
+
+		logger = log.MustNewDefaultLogger(log.LogFormatPlain, log.LogLevelError).
+			With("module", "signer_conformance")
+	)
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	endpoint, err := privval.NewSignerListener(*addr, logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	cfg := conformance.DefaultConfig(*chainID)
+	cfg.StartHeight = *startHeight
+	cfg.MaxLatency = *maxLatency
+	cfg.ConnectTimeout = *connectTimeout
+
+	fmt.Printf("Waiting for the signer to connect to %s\n", *addr)
+	results, err := conformance.NewHarness(logger, endpoint, cfg).Run(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Passed() {
+			fmt.Printf("PASS  %-30s %s\n", r.Name, r.Latency)
+		} else {
+			failed++
+			fmt.Printf("FAIL  %-30s %s: %v\n", r.Name, r.Latency, r.Err)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("%d of %d checks failed\n", failed, len(results))
+		os.Exit(1)
+	}
+	fmt.Printf("All %d checks passed\n", len(results))
+}
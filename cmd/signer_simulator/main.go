@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	tmnet "github.com/tendermint/tendermint/libs/net"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/privval/conformance"
+	"github.com/tendermint/tendermint/types"
+)
+
+// signer_simulator is a remote signer that dials a node and signs with a file
+// key, optionally injecting faults, to exercise a node against slow, flaky and
+// misbehaving signers.
+func main() {
+	var (
+		addr             = flag.String("addr", "tcp://127.0.0.1:26659", "Address of the node's privval listener (tcp:// or unix://)")
+		chainID          = flag.String("chain-id", "mychain", "chain id")
+		privValKeyPath   = flag.String("priv-key", "", "priv val key file path (a throwaway key is generated if empty)")
+		privValStatePath = flag.String("priv-state", "", "priv val state file path")
+		latency          = flag.Duration("latency", 0, "latency added to every response")
+		jitter           = flag.Duration("jitter", 0, "maximum random latency added on top of -latency")
+		disconnectEvery  = flag.Int("disconnect-every", 0, "drop the connection instead of answering every n-th request")
+		malformedRate    = flag.Float64("malformed-rate", 0, "fraction of requests answered with a malformed response")
+		doubleSign       = flag.Bool("double-sign", false, "sign every request, ignoring the last signed state")
+
+		logger = log.MustNewDefaultLogger(log.LogFormatPlain, log.LogLevelInfo).
+			With("module", "signer_simulator")
+	)
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	pv, err := loadOrGenFilePV(*privValKeyPath, *privValStatePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	var privVal types.PrivValidator = pv
+	if *doubleSign {
+		logger.Info("Double signing: the last signed state is ignored")
+		privVal = types.NewMockPVWithParams(pv.Key.PrivKey, false, false)
+	}
+
+	var dialer privval.SocketDialer
+	protocol, address := tmnet.ProtocolAndAddress(*addr)
+	switch protocol {
+	case "unix":
+		dialer = privval.DialUnixFn(address)
+	case "tcp":
+		dialer = privval.DialTCPFn(address, 3*time.Second, ed25519.GenPrivKey())
+	default:
+		fmt.Fprintf(os.Stderr, "wrong address: expected either 'tcp' or 'unix' protocols, got %s\n", protocol)
+		os.Exit(1)
+	}
+
+	logger.Info(
+		"Starting signer simulator",
+		"addr", *addr,
+		"chainID", *chainID,
+		"pubKey", pv.Key.PubKey,
+		"latency", *latency,
+		"jitter", *jitter,
+		"disconnectEvery", *disconnectEvery,
+		"malformedRate", *malformedRate,
+		"doubleSign", *doubleSign,
+	)
+
+	endpoint := privval.NewSignerDialerEndpoint(logger, dialer,
+		privval.SignerDialerEndpointConnRetries(1<<30),
+		privval.SignerDialerEndpointRetryWaitInterval(time.Second),
+	)
+	if err := endpoint.Start(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ss := privval.NewSignerServer(endpoint, *chainID, privVal)
+	ss.SetRequestHandler(conformance.NewFaultyRequestHandler(conformance.Faults{
+		Latency:         *latency,
+		Jitter:          *jitter,
+		DisconnectEvery: *disconnectEvery,
+		MalformedRate:   *malformedRate,
+	}, endpoint))
+	if err := ss.Start(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	<-ctx.Done()
+	ss.Wait()
+}
+
+func loadOrGenFilePV(keyPath, statePath string) (*privval.FilePV, error) {
+	if keyPath != "" {
+		return privval.LoadFilePV(keyPath, statePath)
+	}
+
+	dir, err := os.MkdirTemp("", "signer-simulator-*")
+	if err != nil {
+		return nil, err
+	}
+	return privval.GenFilePV(filepath.Join(dir, "key.json"), filepath.Join(dir, "state.json"), "")
+}
@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/atrest"
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/dbcompress"
+)
+
+// CompressDataCmd rewrites the blockstore and state databases of a node with
+// the configured compression.
+var CompressDataCmd = &cobra.Command{
+	Use:   "compress-data",
+	Short: "Rewrite the blockstore and state databases with the configured compression",
+	Long: `
+Rewrite the values of the blockstore and state databases of the node, i.e. the
+block parts and the block results, with the compression of db-compression: the
+values written uncompressed, or with another compression, are compressed again,
+or, with "none", decompressed. The disk space is reclaimed as the databases are
+compacted.
+
+The node reads the values whatever their compression, so this is only needed
+to compress the existing data after enabling compression, or to downgrade to a
+binary built without zstd support. Set db-compression in the configuration as
+well, for the node to compress the new data.
+
+The node must not be running.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		codec, err := dbcompress.CodecFromConfig(config.BaseConfig)
+		if err != nil {
+			return err
+		}
+		cipher, err := atrest.CipherFromConfig(config.BaseConfig)
+		if err != nil {
+			return err
+		}
+
+		provider := atrest.WrapDBProvider(cfg.DefaultDBProvider, cipher)
+		for _, id := range dbcompress.CompressedDBs {
+			db, err := provider(&cfg.DBContext{ID: id, Config: config})
+			if err != nil {
+				return fmt.Errorf("opening %s database: %w", id, err)
+			}
+			n, err := dbcompress.Recompress(cmd.Context(), db, codec)
+			if closeErr := db.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("compressing %s database: %w", id, err)
+			}
+			logger.Info("Compressed database", "db", id, "compression", codec, "values", n)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addDBFlags(CompressDataCmd)
+	CompressDataCmd.Flags().String(
+		"db-compression",
+		config.DBCompression,
+		"compression of the blockstore and state databases: none | snappy | zstd")
+}
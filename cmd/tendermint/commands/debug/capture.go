@@ -0,0 +1,88 @@
+package debug
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpcclient "github.com/tendermint/tendermint/rpc/jsonrpc/client"
+)
+
+var (
+	captureDuration time.Duration
+	captureRedact   string
+
+	flagDuration = "duration"
+	flagRedact   = "redact"
+)
+
+var captureCmd = &cobra.Command{
+	Use:   "capture [peer-id] [channel]",
+	Short: "Capture the messages exchanged with a peer on a channel into a file",
+	Long: `Capture the decoded messages exchanged with a peer on a p2p channel, given in
+decimal or hexadecimal, for some time into a file of the captures directory of the
+node's home, with one JSON object per message. The node must enable the unsafe RPC
+methods.
+
+The bytes fields of the messages, e.g. transactions and signatures, can be replaced
+by their SHA-256 hash with --redact=bytes, and the messages can be left out, keeping
+their type and size, with --redact=all.
+
+Example:
+$ tendermint debug capture 0d12ee2ea5a8f9c8fb2a3a7e7cc5d9d6d1c1a7e3 0x20 --duration 30s`,
+	Args: cobra.ExactArgs(2),
+	RunE: captureCmdHandler,
+}
+
+func init() {
+	captureCmd.Flags().DurationVar(
+		&captureDuration,
+		flagDuration,
+		10*time.Second,
+		"the duration of the capture, at most 10 minutes",
+	)
+	captureCmd.Flags().StringVar(
+		&captureRedact,
+		flagRedact,
+		"none",
+		"the redaction of the messages: none, bytes or all",
+	)
+}
+
+func captureCmdHandler(cmd *cobra.Command, args []string) error {
+	channel, err := strconv.ParseUint(args[1], 0, 8)
+	if err != nil {
+		return fmt.Errorf("invalid channel %q: %w", args[1], err)
+	}
+	if captureDuration < time.Second {
+		return fmt.Errorf("the capture must last at least a second")
+	}
+
+	rpc, err := rpcclient.New(nodeRPCAddr)
+	if err != nil {
+		return fmt.Errorf("failed to create new http client: %w", err)
+	}
+
+	ctx := cmd.Context()
+	var res coretypes.ResultCaptureMessages
+	if err := rpc.Call(ctx, "unsafe_capture_messages", map[string]interface{}{
+		"peer_id": args[0],
+		"channel": channel,
+		"seconds": int(captureDuration / time.Second),
+		"redact":  captureRedact,
+	}, &res); err != nil {
+		return fmt.Errorf("failed to start the capture: %w", err)
+	}
+
+	logger.Info("capturing messages", "file", res.File, "until", res.Until)
+	select {
+	case <-time.After(time.Until(res.Until)):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	logger.Info("captured messages", "file", res.File)
+	return nil
+}
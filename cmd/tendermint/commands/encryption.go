@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tendermint/tendermint/atrest"
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/consensus"
+	tmos "github.com/tendermint/tendermint/libs/os"
+)
+
+// GenEncryptionKeyCmd generates the key file of the "file" encryption key
+// provider, or rotates its key.
+var GenEncryptionKeyCmd = &cobra.Command{
+	Use:   "gen-encryption-key",
+	Short: "Generate or rotate the key encrypting the data at rest",
+	Long: `
+Generate the key file of the "file" encryption key provider, which encrypts the
+consensus WAL, and the blockstore and evidence databases of the node.
+
+If the key file exists, a new key is added to it and becomes the current key:
+the node encrypts new data with it after a restart, and the previous keys are
+kept to read the data encrypted with them. Run reencrypt-data to rewrite all
+the data with the current key before removing a previous key from the file.
+`,
+	RunE: genEncryptionKey,
+}
+
+func genEncryptionKey(cmd *cobra.Command, args []string) error {
+	keyFile := config.EncryptionKeyFile()
+	if !tmos.FileExists(keyFile) {
+		if err := atrest.GenKeyring().Save(keyFile); err != nil {
+			return err
+		}
+		logger.Info("Generated encryption key file", "path", keyFile)
+		return nil
+	}
+
+	keyring, err := atrest.LoadKeyFile(keyFile)
+	if err != nil {
+		return err
+	}
+	id := keyring.Rotate()
+	if err := keyring.Save(keyFile); err != nil {
+		return err
+	}
+	logger.Info("Rotated encryption key", "path", keyFile, "current_key", id)
+	return nil
+}
+
+// ReencryptDataCmd rewrites the encrypted data of a node with the current key.
+var ReencryptDataCmd = &cobra.Command{
+	Use:   "reencrypt-data",
+	Short: "Rewrite the data encrypted at rest with the current key",
+	Long: `
+Rewrite the consensus WAL, and the blockstore and evidence databases of the node,
+with the current key of the encryption key provider, including the data written
+before encryption was enabled. Once done, the previous keys are not needed anymore.
+
+The node must not be running.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cipher, err := atrest.CipherFromConfig(config.BaseConfig)
+		if err != nil {
+			return err
+		}
+		if cipher == nil {
+			return errors.New("encryption at rest is disabled: no encryption-key-provider is configured")
+		}
+
+		for _, id := range atrest.EncryptedDBs {
+			db, err := cfg.DefaultDBProvider(&cfg.DBContext{ID: id, Config: config})
+			if err != nil {
+				return fmt.Errorf("opening %s database: %w", id, err)
+			}
+			n, err := atrest.Reencrypt(cmd.Context(), db, cipher)
+			if closeErr := db.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return fmt.Errorf("reencrypting %s database: %w", id, err)
+			}
+			logger.Info("Reencrypted database", "db", id, "values", n)
+		}
+
+		if err := consensus.ReencryptWAL(config.Consensus.WalFile(), cipher); err != nil {
+			return fmt.Errorf("reencrypting the WAL: %w", err)
+		}
+		logger.Info("Reencrypted WAL", "path", config.Consensus.WalFile())
+		return nil
+	},
+}
+
+func init() {
+	addDBFlags(ReencryptDataCmd)
+}
@@ -60,11 +60,13 @@ var (
 	dir                string
 	maxOpenConnections int
 
-	sequential     bool
-	trustingPeriod time.Duration
-	trustedHeight  int64
-	trustedHash    []byte
-	trustLevelStr  string
+	sequential           bool
+	maxConcurrentFetches int
+	tipHeightTolerance   int64
+	trustingPeriod       time.Duration
+	trustedHeight        int64
+	trustedHash          []byte
+	trustLevelStr        string
 
 	logLevel  string
 	logFormat string
@@ -99,6 +101,12 @@ func init() {
 	LightCmd.Flags().BoolVar(&sequential, "sequential", false,
 		"sequential verification. Verify all headers sequentially as opposed to using skipping verification",
 	)
+	LightCmd.Flags().IntVar(&maxConcurrentFetches, "max-concurrent-fetches", 1,
+		"maximum number of light blocks fetched concurrently from a provider while verifying a header",
+	)
+	LightCmd.Flags().Int64Var(&tipHeightTolerance, "tip-height-tolerance", 2,
+		"number of blocks by which the witnesses may be behind or ahead of the primary for /status_verified",
+	)
 }
 
 func runProxy(cmd *cobra.Command, args []string) error {
@@ -144,7 +152,7 @@ func runProxy(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("can't parse trust level: %w", err)
 	}
 
-	options := []light.Option{light.Logger(logger)}
+	options := []light.Option{light.Logger(logger), light.MaxConcurrentFetches(maxConcurrentFetches)}
 
 	if sequential {
 		options = append(options, light.SequentialVerification())
@@ -174,6 +182,10 @@ func runProxy(cmd *cobra.Command, args []string) error {
 	cfg := rpcserver.DefaultConfig()
 	cfg.MaxBodyBytes = config.RPC.MaxBodyBytes
 	cfg.MaxHeaderBytes = config.RPC.MaxHeaderBytes
+	cfg.HTTP2 = config.RPC.HTTP2
+	cfg.HTTP2MaxConcurrentStreams = config.RPC.HTTP2MaxConcurrentStreams
+	cfg.HTTP2MaxUploadBufferPerStream = config.RPC.HTTP2StreamWindowBytes
+	cfg.HTTP2MaxUploadBufferPerConnection = config.RPC.HTTP2ConnWindowBytes
 	cfg.MaxOpenConnections = maxOpenConnections
 	// If necessary adjust global WriteTimeout to ensure it's greater than
 	// TimeoutBroadcastTxCommit.
@@ -182,7 +194,10 @@ func runProxy(cmd *cobra.Command, args []string) error {
 		cfg.WriteTimeout = config.RPC.TimeoutBroadcastTxCommit + 1*time.Second
 	}
 
-	p, err := lproxy.NewProxy(c, listenAddr, primaryAddr, cfg, logger, lrpc.KeyPathFn(lrpc.DefaultMerkleKeyPathFn()))
+	p, err := lproxy.NewProxy(c, listenAddr, primaryAddr, cfg, logger,
+		lrpc.KeyPathFn(lrpc.DefaultMerkleKeyPathFn()),
+		lrpc.TipHeightTolerance(tipHeightTolerance),
+	)
 	if err != nil {
 		return err
 	}
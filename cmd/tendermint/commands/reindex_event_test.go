@@ -100,7 +100,7 @@ func TestLoadEventSink(t *testing.T) {
 		cfg := tmcfg.TestConfig()
 		cfg.TxIndex.Indexer = tc.sinks
 		cfg.TxIndex.PsqlConn = tc.connURL
-		_, err := loadEventSinks(cfg)
+		_, err := loadEventSinks(cfg, "test-chain")
 		if tc.loadErr {
 			require.Error(t, err)
 		} else {
@@ -109,6 +109,25 @@ func TestLoadEventSink(t *testing.T) {
 	}
 }
 
+func TestReIndexSinkConfig(t *testing.T) {
+	cfg := tmcfg.TestConfig()
+	cfg.TxIndex.Indexer = []string{"null"}
+	require.Same(t, cfg, reindexSinkConfig(cfg))
+
+	reindexIndexers = []string{"kv", "psql"}
+	reindexPsqlConn = "postgresql://localhost/events"
+	defer func() {
+		reindexIndexers = nil
+		reindexPsqlConn = ""
+	}()
+	sinkCfg := reindexSinkConfig(cfg)
+	require.Equal(t, []string{"kv", "psql"}, sinkCfg.TxIndex.Indexer)
+	require.Equal(t, "postgresql://localhost/events", sinkCfg.TxIndex.PsqlConn)
+	// The config of the node is left as is.
+	require.Equal(t, []string{"null"}, cfg.TxIndex.Indexer)
+	require.Empty(t, cfg.TxIndex.PsqlConn)
+}
+
 func TestLoadBlockStore(t *testing.T) {
 	testCfg, err := tmcfg.ResetTestRoot(t.Name())
 	require.NoError(t, err)
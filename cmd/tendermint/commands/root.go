@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"time"
 
@@ -21,8 +23,42 @@ func init() {
 	registerFlagsRootCmd(RootCmd)
 }
 
+const (
+	remoteConfigFlag         = "remote-config"
+	remoteConfigChecksumFlag = "remote-config-sha256"
+)
+
 func registerFlagsRootCmd(cmd *cobra.Command) {
 	cmd.PersistentFlags().String("log-level", config.LogLevel, "log level")
+	cmd.PersistentFlags().String(remoteConfigFlag, "",
+		"fetch the config from a remote source (http(s)://..., consul(+https)://host:port/key or etcd(+https)://host:port/key), "+
+			"overridden by the local config file")
+	cmd.PersistentFlags().String(remoteConfigChecksumFlag, "",
+		"hex-encoded SHA-256 hash the remote config must have")
+}
+
+// mergeRemoteConfig reads the remote config, if any, into viper, under the
+// local config file: settings of the local file override remote ones.
+func mergeRemoteConfig(ctx context.Context) error {
+	source := viper.GetString(remoteConfigFlag)
+	if source == "" {
+		return nil
+	}
+	data, err := cfg.FetchRemoteConfig(ctx, source, viper.GetString(remoteConfigChecksumFlag))
+	if err != nil {
+		return err
+	}
+
+	viper.SetConfigType("toml")
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("error in remote config: %w", err)
+	}
+	if err := viper.MergeInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return err
+		}
+	}
+	return nil
 }
 
 // ParseConfig retrieves the default environment configuration,
@@ -50,6 +86,10 @@ var RootCmd = &cobra.Command{
 			return nil
 		}
 
+		if err := mergeRemoteConfig(cmd.Context()); err != nil {
+			return err
+		}
+
 		config, err = ParseConfig()
 		if err != nil {
 			return err
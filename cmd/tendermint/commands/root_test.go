@@ -2,6 +2,8 @@ package commands
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -154,6 +156,54 @@ func TestRootConfig(t *testing.T) {
 	}
 }
 
+func TestRootRemoteConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = w.Write([]byte("log-level = \"debug\"\nmoniker = \"remote\"\n"))
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		args  []string
+		local map[string]string
+
+		logLvl  string
+		moniker string
+	}{
+		{nil, nil, "debug", "remote"},                                          // remote config
+		{nil, map[string]string{"moniker": "local"}, "debug", "local"},         // local file overrides
+		{[]string{"--log-level=info"}, map[string]string{}, "info", "remote"},  // flag overrides
+		{[]string{"--remote-config-sha256=00"}, nil, "", ""},                   // checksum mismatch
+		{[]string{"--remote-config=ftp://localhost/config.toml"}, nil, "", ""}, // invalid source
+	}
+
+	for i, tc := range cases {
+		defaultRoot := t.TempDir()
+		idxString := strconv.Itoa(i)
+		clearConfig(t, defaultRoot)
+
+		if tc.local != nil {
+			configFilePath := filepath.Join(defaultRoot, "config")
+			require.NoError(t, tmos.EnsureDir(configFilePath, 0700))
+			require.NoError(t, WriteConfigVals(configFilePath, tc.local))
+		}
+
+		rootCmd := testRootCmd()
+		cmd := cli.PrepareBaseCmd(rootCmd, "TM", defaultRoot)
+		cmd.Exit = func(int) {}
+
+		args := append([]string{rootCmd.Use, "--remote-config=" + srv.URL}, tc.args...)
+		err := cli.RunWithArgs(cmd, args, nil)
+		if tc.logLvl == "" {
+			require.Error(t, err, idxString)
+			continue
+		}
+		require.NoError(t, err, idxString)
+
+		assert.Equal(t, tc.logLvl, config.LogLevel, idxString)
+		assert.Equal(t, tc.moniker, config.Moniker, idxString)
+	}
+}
+
 // WriteConfigVals writes a toml file with the given values.
 // It returns an error if writing was impossible.
 func WriteConfigVals(dir string, vals map[string]string) error {
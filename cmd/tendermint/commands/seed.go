@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	cfg "github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/node"
+	"github.com/tendermint/tendermint/types"
+)
+
+var seedChainID string
+
+// SeedCmd is the command running a dedicated seed node.
+var SeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Run a seed node, which only exchanges peer addresses",
+	Long: `
+seed runs a dedicated seed node: the node only runs the peer exchange reactor
+and its address book, without consensus, mempool, state or RPC server, and
+whatever the mode of the config file. Nodes joining the network dial it to
+learn the addresses of their peers.
+
+The seed node only needs its node key and the p2p options of the config. The
+chain ID of the network is read from the genesis file, unless it is given with
+--chain-id, in which case there is no need of a genesis file.
+`,
+	RunE: runSeed,
+}
+
+func init() {
+	SeedCmd.Flags().StringVar(&seedChainID, "chain-id", "",
+		"chain ID of the network, instead of the one of the genesis file")
+	SeedCmd.Flags().String("moniker", config.Moniker, "node name")
+	SeedCmd.Flags().String("p2p.laddr", config.P2P.ListenAddress,
+		"node listen address. (0.0.0.0:0 means any interface, any port)")
+	SeedCmd.Flags().String("p2p.external-address", config.P2P.ExternalAddress,
+		"address to advertise to peers for them to dial")
+	SeedCmd.Flags().String("p2p.bootstrap-peers", config.P2P.BootstrapPeers,
+		"comma-delimited ID@host:port peers to populate the address book with on startup")
+	SeedCmd.Flags().String("p2p.persistent-peers", config.P2P.PersistentPeers,
+		"comma-delimited ID@host:port persistent peers")
+	SeedCmd.Flags().Uint16("p2p.max-connections", config.P2P.MaxConnections,
+		"maximum number of connected peers")
+	SeedCmd.Flags().String("rpc.pprof-laddr", config.RPC.PprofListenAddress,
+		"pprof listen address (https://golang.org/pkg/net/http/pprof)")
+	addDBFlags(SeedCmd)
+}
+
+func runSeed(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+
+	n, err := NewSeedNode(ctx, config, logger, seedChainID)
+	if err != nil {
+		return fmt.Errorf("failed to create seed node: %w", err)
+	}
+	if err := n.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start seed node: %w", err)
+	}
+
+	logger.Info("started seed node", "node", n.String())
+
+	<-ctx.Done()
+	n.Wait()
+	return nil
+}
+
+// NewSeedNode returns a seed node with the p2p options of the config, whatever
+// its mode. The node is part of the network chainID, or of the network of the
+// genesis file if chainID is empty.
+func NewSeedNode(ctx context.Context, conf *cfg.Config, logger log.Logger, chainID string) (service.Service, error) {
+	seedConf := *conf
+	seedConf.BaseConfig.Mode = cfg.ModeSeed
+	p2pConf := *conf.P2P
+	p2pConf.PexReactor = true
+	seedConf.P2P = &p2pConf
+
+	var genDoc *types.GenesisDoc
+	if chainID != "" {
+		genDoc = &types.GenesisDoc{ChainID: chainID}
+		if err := genDoc.ValidateAndComplete(); err != nil {
+			return nil, err
+		}
+	}
+	return node.New(ctx, &seedConf, logger, nil, genDoc)
+}
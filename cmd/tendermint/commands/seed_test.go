@@ -0,0 +1,41 @@
+package commands_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/cmd/tendermint/commands"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestNewSeedNode(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg, err := config.ResetTestRoot(t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(cfg.RootDir) })
+	cfg.P2P.ListenAddress = "tcp://127.0.0.1:0"
+	cfg.P2P.PexReactor = false
+
+	// The seed node doesn't need a genesis file when given the chain ID,
+	// and runs the peer exchange reactor whatever the config.
+	require.NoError(t, os.Remove(cfg.GenesisFile()))
+	_, err = commands.NewSeedNode(ctx, cfg, log.NewNopLogger(), "")
+	require.Error(t, err)
+
+	n, err := commands.NewSeedNode(ctx, cfg, log.NewNopLogger(), "seed-chain")
+	require.NoError(t, err)
+	require.Equal(t, config.ModeValidator, cfg.Mode)
+	require.False(t, cfg.P2P.PexReactor)
+
+	require.NoError(t, n.Start(ctx))
+	require.True(t, n.IsRunning())
+	cancel()
+	n.Wait()
+	require.False(t, n.IsRunning())
+}
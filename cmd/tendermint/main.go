@@ -26,9 +26,13 @@ func main() {
 		cmd.TestnetFilesCmd,
 		cmd.ShowNodeIDCmd,
 		cmd.GenNodeKeyCmd,
+		cmd.GenEncryptionKeyCmd,
+		cmd.ReencryptDataCmd,
+		cmd.CompressDataCmd,
 		cmd.VersionCmd,
 		cmd.InspectCmd,
 		cmd.RollbackStateCmd,
+		cmd.SeedCmd,
 		cmd.MakeKeyMigrateCommand(),
 		debug.DebugCmd,
 		cli.NewCompletionCmd(rootCmd, true),
@@ -1,15 +1,21 @@
 package config
 
 import (
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/libs/log"
 	tmos "github.com/tendermint/tendermint/libs/os"
 	"github.com/tendermint/tendermint/types"
@@ -27,6 +33,14 @@ const (
 	ModeFull      = "full"
 	ModeValidator = "validator"
 	ModeSeed      = "seed"
+
+	IntegrityCheckOff    = "off"
+	IntegrityCheckReport = "report"
+	IntegrityCheckRepair = "repair"
+
+	DBCompressionNone   = "none"
+	DBCompressionSnappy = "snappy"
+	DBCompressionZstd   = "zstd"
 )
 
 // NOTE: Most of the structs & relevant comments + the
@@ -47,14 +61,16 @@ var (
 	defaultPrivValKeyName   = "priv_validator_key.json"
 	defaultPrivValStateName = "priv_validator_state.json"
 
-	defaultNodeKeyName = "node_key.json"
+	defaultNodeKeyName       = "node_key.json"
+	defaultEncryptionKeyName = "encryption_key.json"
 
 	defaultConfigFilePath   = filepath.Join(defaultConfigDir, defaultConfigFileName)
 	defaultGenesisJSONPath  = filepath.Join(defaultConfigDir, defaultGenesisJSONName)
 	defaultPrivValKeyPath   = filepath.Join(defaultConfigDir, defaultPrivValKeyName)
 	defaultPrivValStatePath = filepath.Join(defaultDataDir, defaultPrivValStateName)
 
-	defaultNodeKeyPath = filepath.Join(defaultConfigDir, defaultNodeKeyName)
+	defaultNodeKeyPath       = filepath.Join(defaultConfigDir, defaultNodeKeyName)
+	defaultEncryptionKeyPath = filepath.Join(defaultConfigDir, defaultEncryptionKeyName)
 )
 
 // Config defines the top level configuration for a Tendermint node
@@ -67,9 +83,11 @@ type Config struct {
 	P2P             *P2PConfig             `mapstructure:"p2p"`
 	Mempool         *MempoolConfig         `mapstructure:"mempool"`
 	StateSync       *StateSyncConfig       `mapstructure:"statesync"`
+	BlockSync       *BlockSyncConfig       `mapstructure:"blocksync"`
 	Consensus       *ConsensusConfig       `mapstructure:"consensus"`
 	TxIndex         *TxIndexConfig         `mapstructure:"tx-index"`
 	Instrumentation *InstrumentationConfig `mapstructure:"instrumentation"`
+	Resources       *ResourcesConfig       `mapstructure:"resources"`
 	PrivValidator   *PrivValidatorConfig   `mapstructure:"priv-validator"`
 }
 
@@ -81,9 +99,11 @@ func DefaultConfig() *Config {
 		P2P:             DefaultP2PConfig(),
 		Mempool:         DefaultMempoolConfig(),
 		StateSync:       DefaultStateSyncConfig(),
+		BlockSync:       DefaultBlockSyncConfig(),
 		Consensus:       DefaultConsensusConfig(),
 		TxIndex:         DefaultTxIndexConfig(),
 		Instrumentation: DefaultInstrumentationConfig(),
+		Resources:       DefaultResourcesConfig(),
 		PrivValidator:   DefaultPrivValidatorConfig(),
 	}
 }
@@ -103,9 +123,11 @@ func TestConfig() *Config {
 		P2P:             TestP2PConfig(),
 		Mempool:         TestMempoolConfig(),
 		StateSync:       TestStateSyncConfig(),
+		BlockSync:       TestBlockSyncConfig(),
 		Consensus:       TestConsensusConfig(),
 		TxIndex:         TestTxIndexConfig(),
 		Instrumentation: TestInstrumentationConfig(),
+		Resources:       TestResourcesConfig(),
 		PrivValidator:   DefaultPrivValidatorConfig(),
 	}
 }
@@ -136,12 +158,18 @@ func (cfg *Config) ValidateBasic() error {
 	if err := cfg.StateSync.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [statesync] section: %w", err)
 	}
+	if err := cfg.BlockSync.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [blocksync] section: %w", err)
+	}
 	if err := cfg.Consensus.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [consensus] section: %w", err)
 	}
 	if err := cfg.Instrumentation.ValidateBasic(); err != nil {
 		return fmt.Errorf("error in [instrumentation] section: %w", err)
 	}
+	if err := cfg.Resources.ValidateBasic(); err != nil {
+		return fmt.Errorf("error in [resources] section: %w", err)
+	}
 	return nil
 }
 
@@ -200,6 +228,10 @@ type BaseConfig struct { //nolint: maligned
 	// Database directory
 	DBPath string `mapstructure:"db-dir"`
 
+	// Directory of the forensic bundles captured on app hash mismatches,
+	// empty to disable them
+	ForensicsPath string `mapstructure:"forensics-dir"`
+
 	// Output level for logging
 	LogLevel string `mapstructure:"log-level"`
 
@@ -219,23 +251,65 @@ type BaseConfig struct { //nolint: maligned
 	// so the app can decide if we should keep the connection or not
 	FilterPeers bool `mapstructure:"filter-peers"` // false
 
+	// Encryption at rest of the consensus WAL, and of the blockstore and
+	// evidence databases: "" (disabled) | "file" | the name of a key provider
+	// registered with atrest.RegisterKeyProvider
+	EncryptionKeyProvider string `mapstructure:"encryption-key-provider"`
+
+	// A JSON file containing the keys of the "file" encryption key provider
+	EncryptionKey string `mapstructure:"encryption-key-file"`
+
+	// Compression of the values of the blockstore and state databases, i.e.
+	// of the block parts and the block results: none | snappy | zstd. zstd
+	// requires a binary built with the zstd build tag.
+	DBCompression string `mapstructure:"db-compression"`
+
+	// Feature flags to set, given as "<name>" to enable a flag, or
+	// "<name>=<bool>". The flags not given have their default value.
+	Features []string `mapstructure:"features"`
+
+	// Consistency check of the blockstore, the state, the consensus WAL and
+	// the last signed state of the validator at startup: off | report | repair
+	// * report
+	//   - refuse to start on an inconsistency, with the commands recovering from it
+	// * repair
+	//   - as report, but repair the inconsistencies which can be safely repaired,
+	//     e.g. by moving aside a consensus WAL ahead of the blockstore
+	IntegrityCheck string `mapstructure:"integrity-check"`
+
+	// Minimum number of the last blocks to retain when the application
+	// requests pruning with a retain height, 0 to retain no minimum. The
+	// blocks within the evidence window are always retained.
+	MinRetainBlocks int64 `mapstructure:"min-retain-blocks"`
+
+	// How often the blocks, their results and the indexed transactions below
+	// the retain height are pruned
+	PruningInterval time.Duration `mapstructure:"pruning-interval"`
+
 	Other map[string]interface{} `mapstructure:",remain"`
 }
 
 // DefaultBaseConfig returns a default base configuration for a Tendermint node
 func DefaultBaseConfig() BaseConfig {
 	return BaseConfig{
-		Genesis:     defaultGenesisJSONPath,
-		NodeKey:     defaultNodeKeyPath,
-		Mode:        defaultMode,
-		Moniker:     defaultMoniker,
-		ProxyApp:    "tcp://127.0.0.1:26658",
-		ABCI:        "socket",
-		LogLevel:    DefaultLogLevel,
-		LogFormat:   log.LogFormatPlain,
-		FilterPeers: false,
-		DBBackend:   "goleveldb",
-		DBPath:      "data",
+		Genesis:       defaultGenesisJSONPath,
+		NodeKey:       defaultNodeKeyPath,
+		Mode:          defaultMode,
+		Moniker:       defaultMoniker,
+		ProxyApp:      "tcp://127.0.0.1:26658",
+		ABCI:          "socket",
+		LogLevel:      DefaultLogLevel,
+		LogFormat:     log.LogFormatPlain,
+		FilterPeers:   false,
+		DBBackend:     "goleveldb",
+		DBPath:        "data",
+		ForensicsPath: "data/forensics",
+		EncryptionKey: defaultEncryptionKeyPath,
+		DBCompression: DBCompressionNone,
+
+		IntegrityCheck: IntegrityCheckOff,
+
+		PruningInterval: 10 * time.Second,
 	}
 }
 
@@ -263,6 +337,11 @@ func (cfg BaseConfig) NodeKeyFile() string {
 	return rootify(cfg.NodeKey, cfg.RootDir)
 }
 
+// EncryptionKeyFile returns the full path to the encryption_key.json file
+func (cfg BaseConfig) EncryptionKeyFile() string {
+	return rootify(cfg.EncryptionKey, cfg.RootDir)
+}
+
 // LoadNodeKey loads NodeKey located in filePath.
 func (cfg BaseConfig) LoadNodeKeyID() (types.NodeID, error) {
 	jsonBytes, err := os.ReadFile(cfg.NodeKeyFile())
@@ -303,6 +382,15 @@ func (cfg BaseConfig) DBDir() string {
 	return rootify(cfg.DBPath, cfg.RootDir)
 }
 
+// ForensicsDir returns the full path to the directory of the forensic
+// bundles, or "" if they are disabled.
+func (cfg BaseConfig) ForensicsDir() string {
+	if cfg.ForensicsPath == "" {
+		return ""
+	}
+	return rootify(cfg.ForensicsPath, cfg.RootDir)
+}
+
 // ValidateBasic performs basic validation (checking param bounds, etc.) and
 // returns an error if any check fails.
 func (cfg BaseConfig) ValidateBasic() error {
@@ -321,6 +409,38 @@ func (cfg BaseConfig) ValidateBasic() error {
 		return fmt.Errorf("unknown mode: %v", cfg.Mode)
 	}
 
+	switch cfg.IntegrityCheck {
+	case IntegrityCheckOff, IntegrityCheckReport, IntegrityCheckRepair:
+	default:
+		return fmt.Errorf("unknown integrity check: %v (must be 'off', 'report' or 'repair')", cfg.IntegrityCheck)
+	}
+
+	switch cfg.DBCompression {
+	case DBCompressionNone, DBCompressionSnappy, DBCompressionZstd:
+	default:
+		return fmt.Errorf("unknown db compression: %v (must be 'none', 'snappy' or 'zstd')", cfg.DBCompression)
+	}
+
+	if cfg.MinRetainBlocks < 0 {
+		return errors.New("min-retain-blocks can't be negative")
+	}
+	if cfg.PruningInterval <= 0 {
+		return errors.New("pruning-interval must be positive")
+	}
+
+	for _, flag := range cfg.Features {
+		name := flag
+		if i := strings.IndexByte(flag, '='); i >= 0 {
+			if _, err := strconv.ParseBool(strings.TrimSpace(flag[i+1:])); err != nil {
+				return fmt.Errorf("invalid value of feature flag %q", flag)
+			}
+			name = flag[:i]
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("invalid feature flag %q", flag)
+		}
+	}
+
 	return nil
 }
 
@@ -406,9 +526,16 @@ func (cfg *PrivValidatorConfig) AreSecurityOptionsPresent() bool {
 type RPCConfig struct {
 	RootDir string `mapstructure:"home"`
 
-	// TCP or UNIX socket address for the RPC server to listen on
+	// TCP or UNIX socket address for the RPC server to listen on, e.g.
+	// "unix:///var/run/tendermint/rpc.sock". Several comma-separated
+	// addresses can be given, to serve the RPC on each of them.
 	ListenAddress string `mapstructure:"laddr"`
 
+	// Permissions of the UNIX sockets of ListenAddress, in octal (e.g.
+	// "0660"), so that only the local processes allowed to can call the RPC.
+	// If empty, they are set by the umask of the process.
+	UnixSocketMode string `mapstructure:"unix-socket-mode"`
+
 	// A list of origins a cross-domain request can be executed from.
 	// If the special '*' value is present in the list, all origins will be allowed.
 	// An origin may contain a wildcard (*) to replace 0 or more characters (i.e.: http://*.domain.com).
@@ -421,9 +548,28 @@ type RPCConfig struct {
 	// A list of non simple headers the client is allowed to use with cross-domain requests.
 	CORSAllowedHeaders []string `mapstructure:"cors-allowed-headers"`
 
+	// TCP or UNIX socket address for the gRPC server to listen on. The gRPC
+	// server serves the tendermint.rpc.CoreAPI service, which has the methods
+	// of the RPC server except the unsafe ones. An empty address disables it.
+	GRPCListenAddress string `mapstructure:"grpc-laddr"`
+
+	// Maximum number of simultaneous connections.
+	// Does not include RPC (HTTP&WebSocket) connections. See max-open-connections
+	// If you want to accept a larger number than the default, make sure
+	// you increase your OS limits.
+	// 0 - unlimited.
+	GRPCMaxOpenConnections int `mapstructure:"grpc-max-open-connections"`
+
 	// Activate unsafe RPC commands like /dial-persistent-peers and /unsafe-flush-mempool
 	Unsafe bool `mapstructure:"unsafe"`
 
+	// Remove the RPC methods submitting transactions or evidence, or otherwise
+	// mutating the state of the node, i.e. /broadcast_tx_*,
+	// /broadcast_evidence, /remove_tx, /validator_set_update and the unsafe
+	// methods, from the HTTP, websocket and gRPC servers, to expose query-only
+	// endpoints. It takes precedence over Unsafe.
+	ReadOnly bool `mapstructure:"read-only"`
+
 	// Maximum number of simultaneous connections (including WebSocket).
 	// If you want to accept a larger number than the default, make sure
 	// you increase your OS limits.
@@ -442,18 +588,114 @@ type RPCConfig struct {
 	// to the estimated maximum number of broadcast_tx_commit calls per block.
 	MaxSubscriptionsPerClient int `mapstructure:"max-subscriptions-per-client"`
 
+	// Maximum number of concurrent WebSocket connections, across all the
+	// listen addresses. Further connections are rejected with status 503
+	// Service Unavailable. 0 means no limit.
+	MaxWebsocketConnections int `mapstructure:"max-websocket-connections"`
+
+	// How long the node waits, when it shuts down, for the events already
+	// published to the WebSocket subscriptions to be written, before closing
+	// the connections with a close frame. New subscriptions are rejected in
+	// the meantime. The connections still open afterwards are dropped.
+	WebsocketDrainTimeout time.Duration `mapstructure:"websocket-drain-timeout"`
+
+	// How long the events published by the node are retained, so that a
+	// WebSocket client can resume its subscriptions after a disconnection
+	// without missing any event: the client passes the sequence number of the
+	// last event it received to /subscribe. 0 disables the event log.
+	EventLogWindowSize time.Duration `mapstructure:"event-log-window-size"`
+
+	// Maximum number of events retained by the event log. 0 means no limit
+	// other than the window size.
+	EventLogMaxItems int `mapstructure:"event-log-max-items"`
+
+	// If true, the event log is stored in the eventlog database rather than
+	// in memory: the clients can resume their subscriptions across restarts
+	// of the node, and a subscription whose buffer is full catches up from
+	// the event log instead of being terminated, as long as the events it
+	// missed are retained. Requires event-log-window-size.
+	EventLogPersistent bool `mapstructure:"event-log-persistent"`
+
 	// How long to wait for a tx to be committed during /broadcast_tx_commit
 	// WARNING: Using a value larger than 10s will result in increasing the
 	// global HTTP write timeout, which applies to all connections and endpoints.
 	// See https://github.com/tendermint/tendermint/issues/3435
 	TimeoutBroadcastTxCommit time.Duration `mapstructure:"timeout-broadcast-tx-commit"`
 
+	// How long to remember the idempotency key of a successful broadcast_tx_*
+	// request, given in the Idempotency-Key header or the idempotency_key
+	// parameter. Retries of the request within the window get its response
+	// instead of broadcasting the transaction again. 0 disables idempotency
+	// keys.
+	IdempotencyWindow time.Duration `mapstructure:"idempotency-window"`
+
+	// How long to remember the transactions broadcast over each RPC
+	// listener. Duplicates of a transaction broadcast within the window get
+	// the result of the original broadcast, and broadcast_tx_commit waits for
+	// it to be committed, instead of being rejected by the mempool cache. 0
+	// disables deduplication.
+	BroadcastDedupWindow time.Duration `mapstructure:"broadcast-dedup-window"`
+
+	// Reject the broadcast_tx_* requests while the node is syncing more than
+	// this many blocks behind its peers, or the snapshot it restores, with a
+	// "node catching up" error: the transactions would be checked against a
+	// stale state, and most of them would fail their recheck once the node
+	// caught up. 0 accepts the transactions regardless of the sync state.
+	BroadcastMaxSyncLag int64 `mapstructure:"broadcast-max-sync-lag"`
+
+	// How long an abci_query or abci_info request waits for the application.
+	// The request is also abandoned as soon as its client goes away or its
+	// gRPC deadline expires. 0 means no limit.
+	TimeoutABCIQuery time.Duration `mapstructure:"timeout-abci-query"`
+
+	// Number of responses to abci_query requests kept in memory, for the
+	// queries the application declares cacheable in its responses, with a
+	// max-age or until the next block: the same queries are then answered
+	// without querying the application. 0 disables the cache.
+	ABCIQueryCacheSize int `mapstructure:"abci-query-cache-size"`
+
 	// Maximum size of request body, in bytes
 	MaxBodyBytes int64 `mapstructure:"max-body-bytes"`
 
 	// Maximum size of request header, in bytes
 	MaxHeaderBytes int `mapstructure:"max-header-bytes"`
 
+	// Stream the responses to JSON-RPC batches of at least this many requests
+	// as they are computed, with chunked transfer encoding, instead of
+	// buffering the whole batch response in memory. 0 disables streaming.
+	StreamBatchThreshold int `mapstructure:"stream-batch-threshold"`
+
+	// Enforce the JSON-RPC 2.0 specification, for clients relying on it: the
+	// requests must have the "jsonrpc": "2.0" member, IDs must be strings,
+	// integers or null and are echoed as sent, and every response, including
+	// the errors, has an ID and the standard structure.
+	StrictJSONRPC bool `mapstructure:"strict-jsonrpc"`
+
+	// Number of responses to immutable queries (block, block_results, commit
+	// and validators at a given past height) kept in memory, to serve them
+	// without reading the stores, with ETag and Cache-Control headers. 0
+	// disables the cache.
+	ResponseCacheSize int `mapstructure:"response-cache-size"`
+
+	// How long a response is kept in the cache, and may be kept by HTTP
+	// caches. 0 keeps the responses until they are evicted by newer ones.
+	ResponseCacheTTL time.Duration `mapstructure:"response-cache-ttl"`
+
+	// Serve HTTP/2 over cleartext connections (h2c), so that clients can
+	// multiplex many in-flight calls over a single connection, and apply the
+	// HTTP/2 limits below to cleartext and TLS connections. Websocket
+	// connections keep using HTTP/1.1.
+	HTTP2 bool `mapstructure:"http2"`
+
+	// Maximum number of concurrent calls (streams) per HTTP/2 connection
+	HTTP2MaxConcurrentStreams uint32 `mapstructure:"http2-max-concurrent-streams"`
+
+	// HTTP/2 flow control window of a call, in bytes
+	HTTP2StreamWindowBytes int32 `mapstructure:"http2-stream-window-bytes"`
+
+	// HTTP/2 flow control window of a connection, shared by its calls, in bytes
+	HTTP2ConnWindowBytes int32 `mapstructure:"http2-conn-window-bytes"`
+
 	// The path to a file containing certificate that is used to create the HTTPS server.
 	// Might be either absolute path or path related to Tendermint's config directory.
 	//
@@ -472,30 +714,126 @@ type RPCConfig struct {
 	// Otherwise, HTTP server is run.
 	TLSKeyFile string `mapstructure:"tls-key-file"`
 
+	// A list of domain names for which the RPC server obtains and renews
+	// certificates automatically from an ACME certificate authority (such
+	// as Let's Encrypt). Certificates are requested on demand during the
+	// TLS handshake and solved using the TLS-ALPN-01 challenge.
+	//
+	// NOTE: ACME cannot be combined with tls-cert-file and tls-key-file.
+	ACMEDomains []string `mapstructure:"acme-domains"`
+
+	// Contact email address registered with the ACME account. Optional.
+	ACMEEmail string `mapstructure:"acme-email"`
+
+	// Directory where ACME account keys and certificates are cached.
+	// Might be either absolute path or path related to Tendermint's data directory.
+	ACMECacheDir string `mapstructure:"acme-cache-dir"`
+
+	// ACME directory URL of the certificate authority. If empty, the Let's
+	// Encrypt production directory is used.
+	ACMEDirectoryURL string `mapstructure:"acme-directory-url"`
+
+	// TCP address on which to answer ACME HTTP-01 challenges, typically
+	// ":80". If empty, only the TLS-ALPN-01 challenge is served.
+	ACMEHTTPListenAddress string `mapstructure:"acme-http-laddr"`
+
 	// pprof listen address (https://golang.org/pkg/net/http/pprof)
 	PprofListenAddress string `mapstructure:"pprof-laddr"`
+
+	// Number of items returned per page by paginated endpoints when no
+	// per_page is given, and the largest per_page accepted. Larger values
+	// are rejected, unless the unsafe endpoints are enabled.
+	DefaultPerPage int `mapstructure:"default-per-page"`
+	MaxPerPage     int `mapstructure:"max-per-page"`
+
+	// Per-endpoint overrides of DefaultPerPage and MaxPerPage, keyed by
+	// endpoint name (e.g. "tx_search"). Zero values fall back to the global
+	// settings.
+	PerPage map[string]RPCPageLimits `mapstructure:"per-page"`
+
+	// Rate limit of the calls of all the endpoints, over HTTP and WebSocket.
+	// Calls over the limit are rejected with status 429 (HTTP) or a JSON-RPC
+	// error. A zero rate disables it.
+	RateLimit RPCRateLimit `mapstructure:"rate-limit"`
+
+	// Rate limits of the calls of specific endpoints, keyed by endpoint name
+	// (e.g. "tx_search"), which apply in addition to RateLimit.
+	MethodRateLimits map[string]RPCRateLimit `mapstructure:"method-rate-limit"`
+}
+
+// RPCPageLimits are the pagination defaults and caps of an RPC endpoint.
+type RPCPageLimits struct {
+	DefaultPerPage int `mapstructure:"default-per-page"`
+	MaxPerPage     int `mapstructure:"max-per-page"`
+}
+
+// RPCRateLimit is a token bucket rate limit of RPC calls.
+type RPCRateLimit struct {
+	// Average number of calls accepted per second. 0 means no limit.
+	Rate float64 `mapstructure:"rate"`
+
+	// Maximum number of calls accepted at once, after a quiet period.
+	Burst int `mapstructure:"burst"`
+
+	// Apply the limit to each remote IP address separately, rather than to
+	// all the clients together.
+	PerIP bool `mapstructure:"per-ip"`
 }
 
 // DefaultRPCConfig returns a default configuration for the RPC server
 func DefaultRPCConfig() *RPCConfig {
 	return &RPCConfig{
 		ListenAddress:      "tcp://127.0.0.1:26657",
+		UnixSocketMode:     "",
 		CORSAllowedOrigins: []string{},
 		CORSAllowedMethods: []string{http.MethodHead, http.MethodGet, http.MethodPost},
 		CORSAllowedHeaders: []string{"Origin", "Accept", "Content-Type", "X-Requested-With", "X-Server-Time"},
 
+		GRPCListenAddress:      "",
+		GRPCMaxOpenConnections: 900,
+
 		Unsafe:             false,
 		MaxOpenConnections: 900,
 
 		MaxSubscriptionClients:    100,
 		MaxSubscriptionsPerClient: 5,
+		MaxWebsocketConnections:   0,
+		WebsocketDrainTimeout:     5 * time.Second,
+		EventLogWindowSize:        30 * time.Second,
+		EventLogMaxItems:          0,
 		TimeoutBroadcastTxCommit:  10 * time.Second,
+		IdempotencyWindow:         10 * time.Minute,
+		BroadcastDedupWindow:      0,
+		BroadcastMaxSyncLag:       100,
+		TimeoutABCIQuery:          10 * time.Second,
+		ABCIQueryCacheSize:        0,
 
 		MaxBodyBytes:   int64(1000000), // 1MB
 		MaxHeaderBytes: 1 << 20,        // same as the net/http default
 
+		StreamBatchThreshold: 0,
+		StrictJSONRPC:        false,
+
+		ResponseCacheSize: 0,
+		ResponseCacheTTL:  10 * time.Minute,
+
+		HTTP2:                     false,
+		HTTP2MaxConcurrentStreams: 250,
+		HTTP2StreamWindowBytes:    1 << 20, // 1MB
+		HTTP2ConnWindowBytes:      8 << 20, // 8MB
+
 		TLSCertFile: "",
 		TLSKeyFile:  "",
+
+		ACMEDomains:  []string{},
+		ACMECacheDir: "acme",
+
+		DefaultPerPage: 30,
+		MaxPerPage:     100,
+		PerPage:        map[string]RPCPageLimits{},
+
+		RateLimit:        RPCRateLimit{PerIP: true},
+		MethodRateLimits: map[string]RPCRateLimit{},
 	}
 }
 
@@ -510,6 +848,9 @@ func TestRPCConfig() *RPCConfig {
 // ValidateBasic performs basic validation (checking param bounds, etc.) and
 // returns an error if any check fails.
 func (cfg *RPCConfig) ValidateBasic() error {
+	if cfg.GRPCMaxOpenConnections < 0 {
+		return errors.New("grpc-max-open-connections can't be negative")
+	}
 	if cfg.MaxOpenConnections < 0 {
 		return errors.New("max-open-connections can't be negative")
 	}
@@ -519,18 +860,152 @@ func (cfg *RPCConfig) ValidateBasic() error {
 	if cfg.MaxSubscriptionsPerClient < 0 {
 		return errors.New("max-subscriptions-per-client can't be negative")
 	}
+	if cfg.MaxWebsocketConnections < 0 {
+		return errors.New("max-websocket-connections can't be negative")
+	}
+	if cfg.WebsocketDrainTimeout < 0 {
+		return errors.New("websocket-drain-timeout can't be negative")
+	}
+	if cfg.EventLogWindowSize < 0 {
+		return errors.New("event-log-window-size can't be negative")
+	}
+	if cfg.EventLogMaxItems < 0 {
+		return errors.New("event-log-max-items can't be negative")
+	}
+	if cfg.EventLogPersistent && cfg.EventLogWindowSize == 0 {
+		return errors.New("event-log-persistent requires event-log-window-size")
+	}
 	if cfg.TimeoutBroadcastTxCommit < 0 {
 		return errors.New("timeout-broadcast-tx-commit can't be negative")
 	}
+	if _, err := cfg.UnixSocketFileMode(); err != nil {
+		return err
+	}
+	if cfg.IdempotencyWindow < 0 {
+		return errors.New("idempotency-window can't be negative")
+	}
+	if cfg.BroadcastDedupWindow < 0 {
+		return errors.New("broadcast-dedup-window can't be negative")
+	}
+	if cfg.BroadcastMaxSyncLag < 0 {
+		return errors.New("broadcast-max-sync-lag can't be negative")
+	}
+	if cfg.TimeoutABCIQuery < 0 {
+		return errors.New("timeout-abci-query can't be negative")
+	}
+	if cfg.ABCIQueryCacheSize < 0 {
+		return errors.New("abci-query-cache-size can't be negative")
+	}
 	if cfg.MaxBodyBytes < 0 {
 		return errors.New("max-body-bytes can't be negative")
 	}
 	if cfg.MaxHeaderBytes < 0 {
 		return errors.New("max-header-bytes can't be negative")
 	}
+	if cfg.StreamBatchThreshold < 0 {
+		return errors.New("stream-batch-threshold can't be negative")
+	}
+	if cfg.ResponseCacheSize < 0 {
+		return errors.New("response-cache-size can't be negative")
+	}
+	if cfg.ResponseCacheTTL < 0 {
+		return errors.New("response-cache-ttl can't be negative")
+	}
+	if cfg.HTTP2 {
+		if cfg.HTTP2MaxConcurrentStreams == 0 {
+			return errors.New("http2-max-concurrent-streams must be positive")
+		}
+		// HTTP/2 windows can't be smaller than the initial window of 64KB.
+		if cfg.HTTP2StreamWindowBytes < 65535 {
+			return errors.New("http2-stream-window-bytes must be at least 65535")
+		}
+		if cfg.HTTP2ConnWindowBytes < cfg.HTTP2StreamWindowBytes {
+			return errors.New("http2-conn-window-bytes can't be less than http2-stream-window-bytes")
+		}
+	}
+	if err := validatePageLimits("", RPCPageLimits{cfg.DefaultPerPage, cfg.MaxPerPage}); err != nil {
+		return err
+	}
+	for endpoint := range cfg.PerPage {
+		if err := validatePageLimits(endpoint, cfg.PageLimits(endpoint)); err != nil {
+			return err
+		}
+	}
+	if err := validateRateLimit("rate-limit", cfg.RateLimit); err != nil {
+		return err
+	}
+	for endpoint, limit := range cfg.MethodRateLimits {
+		if err := validateRateLimit("method-rate-limit."+endpoint, limit); err != nil {
+			return err
+		}
+	}
+	if cfg.IsACMEEnabled() {
+		if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+			return errors.New("acme-domains can't be combined with tls-cert-file and tls-key-file")
+		}
+		if cfg.ACMECacheDir == "" {
+			return errors.New("acme-cache-dir can't be empty when acme-domains is set")
+		}
+	}
+	return nil
+}
+
+func validatePageLimits(endpoint string, limits RPCPageLimits) error {
+	prefix := ""
+	if endpoint != "" {
+		prefix = "per-page." + endpoint + "."
+	}
+	if limits.DefaultPerPage < 1 {
+		return fmt.Errorf("%sdefault-per-page must be positive", prefix)
+	}
+	if limits.MaxPerPage < 1 {
+		return fmt.Errorf("%smax-per-page must be positive", prefix)
+	}
+	if limits.DefaultPerPage > limits.MaxPerPage {
+		return fmt.Errorf("%sdefault-per-page (%d) can't be greater than max-per-page (%d)",
+			prefix, limits.DefaultPerPage, limits.MaxPerPage)
+	}
 	return nil
 }
 
+func validateRateLimit(name string, limit RPCRateLimit) error {
+	if limit.Rate < 0 {
+		return fmt.Errorf("%s.rate can't be negative", name)
+	}
+	if limit.Rate > 0 && limit.Burst < 1 {
+		return fmt.Errorf("%s.burst must be positive", name)
+	}
+	return nil
+}
+
+// PageLimits returns the pagination defaults and caps of the given endpoint,
+// applying its overrides to the global settings.
+func (cfg RPCConfig) PageLimits(endpoint string) RPCPageLimits {
+	limits := RPCPageLimits{DefaultPerPage: cfg.DefaultPerPage, MaxPerPage: cfg.MaxPerPage}
+	if override, ok := cfg.PerPage[endpoint]; ok {
+		if override.DefaultPerPage != 0 {
+			limits.DefaultPerPage = override.DefaultPerPage
+		}
+		if override.MaxPerPage != 0 {
+			limits.MaxPerPage = override.MaxPerPage
+		}
+	}
+	return limits
+}
+
+// UnixSocketFileMode returns the permissions of the UNIX sockets of the RPC
+// server, or 0 if they are left to the umask.
+func (cfg RPCConfig) UnixSocketFileMode() (os.FileMode, error) {
+	if cfg.UnixSocketMode == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(cfg.UnixSocketMode, 8, 32)
+	if err != nil || mode == 0 || mode > 0777 {
+		return 0, fmt.Errorf("unix-socket-mode must be octal permissions between 0001 and 0777, got %q", cfg.UnixSocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
 // IsCorsEnabled returns true if cross-origin resource sharing is enabled.
 func (cfg *RPCConfig) IsCorsEnabled() bool {
 	return len(cfg.CORSAllowedOrigins) != 0
@@ -556,6 +1031,20 @@ func (cfg RPCConfig) IsTLSEnabled() bool {
 	return cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
 }
 
+// IsACMEEnabled returns true if certificates are managed via ACME.
+func (cfg RPCConfig) IsACMEEnabled() bool {
+	return len(cfg.ACMEDomains) != 0
+}
+
+// ACMECacheDirPath returns the full path to the ACME certificate cache.
+func (cfg RPCConfig) ACMECacheDirPath() string {
+	path := cfg.ACMECacheDir
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return rootify(filepath.Join(defaultDataDir, path), cfg.RootDir)
+}
+
 //-----------------------------------------------------------------------------
 // P2PConfig
 
@@ -586,17 +1075,78 @@ type P2PConfig struct { //nolint: maligned
 	// Comma separated list of nodes to keep persistent connections to
 	PersistentPeers string `mapstructure:"persistent-peers"`
 
-	// UPNP port forwarding
+	// Map the p2p port on the NAT gateway with UPnP, or NAT-PMP if the
+	// gateway doesn't support UPnP, and advertise the mapped address. It is
+	// ignored if the external address is set.
 	UPNP bool `mapstructure:"upnp"`
 
+	// Address (host:port) of a relay server, accepting the inbound connections
+	// of the node when it is behind a NAT and the port can't be mapped. The
+	// node advertises the port the relay reserves for it. It is ignored if the
+	// external address is set.
+	Relay string `mapstructure:"relay"`
+
+	// Address (host:port) to run a relay server on, for the nodes behind a
+	// NAT. Empty disables the relay server.
+	RelayServerAddress string `mapstructure:"relay-server-address"`
+
+	// Range of the ports reserved by the relay server, as "<first>-<last>".
+	// Empty lets the system choose the ports.
+	RelayPorts string `mapstructure:"relay-ports"`
+
+	// Maximum number of nodes the relay server reserves a port for at a time.
+	// 0 means no limit.
+	RelayMaxReservations int `mapstructure:"relay-max-reservations"`
+
+	// Comma separated list of the IDs of the nodes allowed to reserve a port
+	// on the relay server. Empty allows any node.
+	RelayAllowedNodeIDs string `mapstructure:"relay-allowed-node-ids"`
+
 	// MaxConnections defines the maximum number of connected peers (inbound and
 	// outbound).
 	MaxConnections uint16 `mapstructure:"max-connections"`
 
+	// MaxPeers is the maximum number of peers kept in the peer store. When
+	// exceeded, the lowest-scored unconnected peers are deleted. 0 means no
+	// limit.
+	MaxPeers uint16 `mapstructure:"max-peers"`
+
+	// MaxAddressesPerPeer is the maximum number of addresses kept for a peer.
+	// 0 means no limit.
+	MaxAddressesPerPeer uint16 `mapstructure:"max-addresses-per-peer"`
+
+	// MaxAddressFailures is the number of consecutive dial failures after
+	// which an address is removed from the peer store. 0 disables this.
+	MaxAddressFailures uint32 `mapstructure:"max-address-failures"`
+
+	// StaleAddressTime is how long after its last successful dial an address
+	// whose dials now fail is removed from the peer store. 0 disables this.
+	StaleAddressTime time.Duration `mapstructure:"stale-address-time"`
+
+	// PeerGCInterval is how often unreachable and stale addresses are removed
+	// from the peer store. 0 disables garbage collection.
+	PeerGCInterval time.Duration `mapstructure:"peer-gc-interval"`
+
 	// MaxIncomingConnectionAttempts rate limits the number of incoming connection
 	// attempts per IP address.
 	MaxIncomingConnectionAttempts uint `mapstructure:"max-incoming-connection-attempts"`
 
+	// MaxConcurrentDials is the maximum number of outbound dials in progress.
+	// 0 means the default of 8.
+	MaxConcurrentDials uint16 `mapstructure:"max-concurrent-dials"`
+
+	// MaxConcurrentDialsPerSubnet is the maximum number of outbound dials in
+	// progress to the addresses of a subnet. 0 means no limit.
+	MaxConcurrentDialsPerSubnet uint16 `mapstructure:"max-concurrent-dials-per-subnet"`
+
+	// DialInterval is the minimum time between the starts of two outbound
+	// dials. 0 disables it.
+	DialInterval time.Duration `mapstructure:"dial-interval"`
+
+	// QuarantineForkPeers, if true, denies the peers which committed another
+	// block than the node at some height, and disconnects them.
+	QuarantineForkPeers bool `mapstructure:"quarantine-fork-peers"`
+
 	// Set true to enable the peer-exchange reactor
 	PexReactor bool `mapstructure:"pex"`
 
@@ -619,6 +1169,16 @@ type P2PConfig struct { //nolint: maligned
 	// Rate at which packets can be received, in bytes/second
 	RecvRate int64 `mapstructure:"recv-rate"`
 
+	// Rates at which each peer can send messages on the given channels, in
+	// bytes/second, given as "<channel ID>=<rate>" pairs, e.g. "0x30=1048576".
+	// The messages received faster are delayed, throttling the peer.
+	ChannelRecvRates []string `mapstructure:"channel-recv-rates"`
+
+	// Channels this node doesn't serve, e.g. "0x30" for the mempool channel.
+	// They are not advertised to the peers, which don't send messages over
+	// them, and the messages received over them are dropped.
+	OptOutChannels []string `mapstructure:"opt-out-channels"`
+
 	// Peer connection configuration.
 	HandshakeTimeout time.Duration `mapstructure:"handshake-timeout"`
 	DialTimeout      time.Duration `mapstructure:"dial-timeout"`
@@ -631,6 +1191,152 @@ type P2PConfig struct { //nolint: maligned
 	// layer uses. Options are: "fifo" and "priority",
 	// with the default being "priority".
 	QueueType string `mapstructure:"queue-type"`
+
+	// Planned maintenance windows of this node, e.g. restarts for an upgrade,
+	// announced to peers so they can avoid relying on it around those times.
+	// Each window is a "<start>/<end>" pair of RFC3339 timestamps.
+	MaintenanceWindows []string `mapstructure:"maintenance-windows"`
+
+	// Peers with a maintenance window starting within this margin, or in
+	// progress, are avoided as block sync and state sync sources when other
+	// peers are available.
+	MaintenanceAvoidMargin time.Duration `mapstructure:"maintenance-avoid-margin"`
+
+	// Record the validators attesting to run the peers in their handshake, in
+	// the validator book of the node.
+	ValidatorBook bool `mapstructure:"validator-book"`
+
+	// Attest in the handshake that the validator runs this node, by signing
+	// its node ID with the local private validator key. This reveals the node
+	// of the validator to its peers.
+	AttestValidator bool `mapstructure:"attest-validator"`
+
+	// Peer filter rules, applied when dialing and accepting peers. A peer
+	// matching a deny rule is rejected. Otherwise, if there are allow rules
+	// of a kind, the peer must match one of them. The CIDRs match the IP
+	// address of the peer, and may be single IP addresses, and the chain IDs
+	// the network of the peer announced in its handshake.
+	AllowCIDRs    []string `mapstructure:"allow-cidrs"`
+	DenyCIDRs     []string `mapstructure:"deny-cidrs"`
+	AllowNodeIDs  []string `mapstructure:"allow-node-ids"`
+	DenyNodeIDs   []string `mapstructure:"deny-node-ids"`
+	AllowChainIDs []string `mapstructure:"allow-chain-ids"`
+	DenyChainIDs  []string `mapstructure:"deny-chain-ids"`
+}
+
+// validatePeerFilter validates the peer filter rules.
+func (cfg *P2PConfig) validatePeerFilter() error {
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{{"allow-cidrs", cfg.AllowCIDRs}, {"deny-cidrs", cfg.DenyCIDRs}} {
+		for _, value := range list.values {
+			value = strings.TrimSpace(value)
+			if net.ParseIP(value) != nil {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(value); err != nil {
+				return fmt.Errorf("invalid %s entry %q: %w", list.name, value, err)
+			}
+		}
+	}
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{{"allow-node-ids", cfg.AllowNodeIDs}, {"deny-node-ids", cfg.DenyNodeIDs}} {
+		for _, value := range list.values {
+			if _, err := types.NewNodeID(strings.TrimSpace(value)); err != nil {
+				return fmt.Errorf("invalid %s entry: %w", list.name, err)
+			}
+		}
+	}
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{{"allow-chain-ids", cfg.AllowChainIDs}, {"deny-chain-ids", cfg.DenyChainIDs}} {
+		for _, value := range list.values {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("empty %s entry", list.name)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseMaintenanceWindow parses a "<start>/<end>" pair of RFC3339 timestamps.
+func ParseMaintenanceWindow(window string) (start, end time.Time, err error) {
+	parts := strings.SplitN(window, "/", 2)
+	if len(parts) != 2 {
+		return start, end, fmt.Errorf("maintenance window %q must have the form <start>/<end>", window)
+	}
+	if start, err = time.Parse(time.RFC3339, strings.TrimSpace(parts[0])); err != nil {
+		return start, end, fmt.Errorf("invalid start of maintenance window %q: %w", window, err)
+	}
+	if end, err = time.Parse(time.RFC3339, strings.TrimSpace(parts[1])); err != nil {
+		return start, end, fmt.Errorf("invalid end of maintenance window %q: %w", window, err)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("maintenance window %q ends before it starts", window)
+	}
+	return start, end, nil
+}
+
+// RelayPortRange parses the RelayPorts. Both ports are 0 if it is empty.
+func (cfg *P2PConfig) RelayPortRange() (first, last int, err error) {
+	if cfg.RelayPorts == "" {
+		return 0, 0, nil
+	}
+	parts := strings.SplitN(cfg.RelayPorts, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("relay-ports %q must have the form <first>-<last>", cfg.RelayPorts)
+	}
+	if first, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+		return 0, 0, fmt.Errorf("invalid first port of relay-ports %q: %w", cfg.RelayPorts, err)
+	}
+	if last, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+		return 0, 0, fmt.Errorf("invalid last port of relay-ports %q: %w", cfg.RelayPorts, err)
+	}
+	if first <= 0 || last > 65535 || first > last {
+		return 0, 0, fmt.Errorf("invalid relay-ports %q", cfg.RelayPorts)
+	}
+	return first, last, nil
+}
+
+// ChannelRecvRateLimits parses the ChannelRecvRates by channel ID.
+func (cfg *P2PConfig) ChannelRecvRateLimits() (map[byte]int64, error) {
+	limits := make(map[byte]int64, len(cfg.ChannelRecvRates))
+	for _, pair := range cfg.ChannelRecvRates {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("channel receive rate %q must have the form <channel ID>=<rate>", pair)
+		}
+		chID, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 0, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid channel ID of channel receive rate %q: %w", pair, err)
+		}
+		rate, err := strconv.ParseInt(strings.TrimSpace(parts[1]), 10, 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid rate of channel receive rate %q, must be a positive number of bytes/second", pair)
+		}
+		if _, ok := limits[byte(chID)]; ok {
+			return nil, fmt.Errorf("duplicate channel receive rate for channel %#x", chID)
+		}
+		limits[byte(chID)] = rate
+	}
+	return limits, nil
+}
+
+// OptOutChannelIDs parses the OptOutChannels.
+func (cfg *P2PConfig) OptOutChannelIDs() ([]byte, error) {
+	ids := make([]byte, 0, len(cfg.OptOutChannels))
+	for _, channel := range cfg.OptOutChannels {
+		id, err := strconv.ParseUint(strings.TrimSpace(channel), 0, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid opt-out channel %q: %w", channel, err)
+		}
+		ids = append(ids, byte(id))
+	}
+	return ids, nil
 }
 
 // DefaultP2PConfig returns a default configuration for the peer-to-peer layer
@@ -639,8 +1345,17 @@ func DefaultP2PConfig() *P2PConfig {
 		ListenAddress:                 "tcp://0.0.0.0:26656",
 		ExternalAddress:               "",
 		UPNP:                          false,
+		RelayMaxReservations:          16,
 		MaxConnections:                64,
+		MaxPeers:                      1000,
+		MaxAddressesPerPeer:           16,
+		MaxAddressFailures:            16,
+		StaleAddressTime:              7 * 24 * time.Hour,
+		PeerGCInterval:                10 * time.Minute,
 		MaxIncomingConnectionAttempts: 100,
+		MaxConcurrentDials:            8,
+		MaxConcurrentDialsPerSubnet:   2,
+		DialInterval:                  250 * time.Millisecond,
 		FlushThrottleTimeout:          100 * time.Millisecond,
 		// The MTU (Maximum Transmission Unit) for Ethernet is 1500 bytes.
 		// The IP header and the TCP header take up 20 bytes each at least (unless
@@ -650,12 +1365,22 @@ func DefaultP2PConfig() *P2PConfig {
 		MaxPacketMsgPayloadSize: 1400,
 		SendRate:                5120000, // 5 mB/s
 		RecvRate:                5120000, // 5 mB/s
+		ChannelRecvRates:        []string{},
+		OptOutChannels:          []string{},
 		PexReactor:              true,
 		AllowDuplicateIP:        false,
 		HandshakeTimeout:        20 * time.Second,
 		DialTimeout:             3 * time.Second,
 		TestDialFail:            false,
 		QueueType:               "priority",
+		MaintenanceWindows:      []string{},
+		MaintenanceAvoidMargin:  10 * time.Minute,
+		AllowCIDRs:              []string{},
+		DenyCIDRs:               []string{},
+		AllowNodeIDs:            []string{},
+		DenyNodeIDs:             []string{},
+		AllowChainIDs:           []string{},
+		DenyChainIDs:            []string{},
 	}
 }
 
@@ -674,6 +1399,41 @@ func (cfg *P2PConfig) ValidateBasic() error {
 	if cfg.RecvRate < 0 {
 		return errors.New("recv-rate can't be negative")
 	}
+	if _, err := cfg.ChannelRecvRateLimits(); err != nil {
+		return err
+	}
+	if _, err := cfg.OptOutChannelIDs(); err != nil {
+		return err
+	}
+	for _, window := range cfg.MaintenanceWindows {
+		if _, _, err := ParseMaintenanceWindow(window); err != nil {
+			return err
+		}
+	}
+	if cfg.MaintenanceAvoidMargin < 0 {
+		return errors.New("maintenance-avoid-margin can't be negative")
+	}
+	if cfg.MaxPeers > 0 && cfg.MaxPeers < cfg.MaxConnections {
+		return errors.New("max-peers can't be less than max-connections")
+	}
+	if cfg.StaleAddressTime < 0 {
+		return errors.New("stale-address-time can't be negative")
+	}
+	if cfg.PeerGCInterval < 0 {
+		return errors.New("peer-gc-interval can't be negative")
+	}
+	if cfg.DialInterval < 0 {
+		return errors.New("dial-interval can't be negative")
+	}
+	if _, _, err := cfg.RelayPortRange(); err != nil {
+		return err
+	}
+	if cfg.RelayMaxReservations < 0 {
+		return errors.New("relay-max-reservations can't be negative")
+	}
+	if err := cfg.validatePeerFilter(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -703,9 +1463,23 @@ type MempoolConfig struct {
 	// max-txs-bytes=5MB, mempool will only accept 5 transactions).
 	MaxTxsBytes int64 `mapstructure:"max-txs-bytes"`
 
+	// Limit the total size of the txs in the mempool submitted over RPC from a
+	// single IP address. 0 means unlimited.
+	MaxSourceIPBytes int64 `mapstructure:"max-source-ip-bytes"`
+
 	// Size of the cache (used to filter transactions we saw earlier) in transactions
 	CacheSize int `mapstructure:"cache-size"`
 
+	// Number of evicted transactions whose reason and time of eviction are
+	// kept in memory, to report their status over RPC. 0 disables the journal.
+	EvictionJournalSize int `mapstructure:"eviction-journal-size"`
+
+	// Number of connections to the application over which the CheckTx
+	// requests of new and rechecked transactions are spread, so that an
+	// application serving them concurrently can check several transactions
+	// in parallel.
+	CheckTxConnections int `mapstructure:"check-tx-connections"`
+
 	// Do not remove invalid transactions from the cache (default: false)
 	// Set to true if it's not possible for any invalid transaction to become
 	// valid again in the future.
@@ -735,6 +1509,38 @@ type MempoolConfig struct {
 	// has existed in the mempool at least TTLNumBlocks number of blocks or if
 	// it's insertion time into the mempool is beyond TTLDuration.
 	TTLNumBlocks int64 `mapstructure:"ttl-num-blocks"`
+
+	// FilterPrefixes is a list of hex-encoded prefixes. Transactions starting
+	// with any of them are dropped before CheckTx.
+	FilterPrefixes []string `mapstructure:"filter-prefixes"`
+
+	// FilterPeerRate, if non-zero, limits the number of transactions per
+	// second accepted from a single peer before CheckTx. Transactions
+	// submitted locally are not limited.
+	FilterPeerRate float64 `mapstructure:"filter-peer-rate"`
+
+	// FilterPeerBurst is the number of transactions a peer may send in a
+	// burst above FilterPeerRate.
+	FilterPeerBurst int `mapstructure:"filter-peer-burst"`
+
+	// FilterPlugins is a list of paths to filter plugins executed, in order,
+	// before CheckTx. Go plugins (.so) are supported by default; other formats
+	// require a build that registers a loader for them.
+	FilterPlugins []string `mapstructure:"filter-plugins"`
+
+	// ProposalMaxTxBytes, if non-zero, excludes the transactions larger than
+	// it from the blocks proposed by the node. They stay in the mempool.
+	ProposalMaxTxBytes int64 `mapstructure:"proposal-max-tx-bytes"`
+
+	// ProposalFilterPrefixes is a list of hex-encoded prefixes. Transactions
+	// starting with any of them are excluded from the blocks proposed by the
+	// node.
+	ProposalFilterPrefixes []string `mapstructure:"proposal-filter-prefixes"`
+
+	// ProposalFilterPlugins is a list of paths to filter plugins executed, in
+	// order, on the transactions reaped for the blocks proposed by the node.
+	// They are loaded as the FilterPlugins.
+	ProposalFilterPlugins []string `mapstructure:"proposal-filter-plugins"`
 }
 
 // DefaultMempoolConfig returns a default configuration for the Tendermint mempool.
@@ -750,6 +1556,11 @@ func DefaultMempoolConfig() *MempoolConfig {
 		MaxTxBytes:   1024 * 1024, // 1MB
 		TTLDuration:  0 * time.Second,
 		TTLNumBlocks: 0,
+
+		CheckTxConnections:  1,
+		EvictionJournalSize: 10000,
+
+		FilterPeerBurst: 100,
 	}
 }
 
@@ -760,6 +1571,26 @@ func TestMempoolConfig() *MempoolConfig {
 	return cfg
 }
 
+// FilterPluginPaths returns the paths of the filter plugins, relative to the
+// root directory unless absolute.
+func (cfg *MempoolConfig) FilterPluginPaths() []string {
+	paths := make([]string, len(cfg.FilterPlugins))
+	for i, path := range cfg.FilterPlugins {
+		paths[i] = rootify(path, cfg.RootDir)
+	}
+	return paths
+}
+
+// ProposalFilterPluginPaths returns the paths of the proposal filter plugins,
+// relative to the root directory unless absolute.
+func (cfg *MempoolConfig) ProposalFilterPluginPaths() []string {
+	paths := make([]string, len(cfg.ProposalFilterPlugins))
+	for i, path := range cfg.ProposalFilterPlugins {
+		paths[i] = rootify(path, cfg.RootDir)
+	}
+	return paths
+}
+
 // ValidateBasic performs basic validation (checking param bounds, etc.) and
 // returns an error if any check fails.
 func (cfg *MempoolConfig) ValidateBasic() error {
@@ -769,9 +1600,18 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 	if cfg.MaxTxsBytes < 0 {
 		return errors.New("max-txs-bytes can't be negative")
 	}
+	if cfg.MaxSourceIPBytes < 0 {
+		return errors.New("max-source-ip-bytes can't be negative")
+	}
 	if cfg.CacheSize < 0 {
 		return errors.New("cache-size can't be negative")
 	}
+	if cfg.EvictionJournalSize < 0 {
+		return errors.New("eviction-journal-size can't be negative")
+	}
+	if cfg.CheckTxConnections < 1 {
+		return errors.New("check-tx-connections must be at least 1")
+	}
 	if cfg.MaxTxBytes < 0 {
 		return errors.New("max-tx-bytes can't be negative")
 	}
@@ -781,6 +1621,25 @@ func (cfg *MempoolConfig) ValidateBasic() error {
 	if cfg.TTLNumBlocks < 0 {
 		return errors.New("ttl-num-blocks can't be negative")
 	}
+	for _, prefix := range cfg.FilterPrefixes {
+		if _, err := hex.DecodeString(prefix); err != nil || prefix == "" {
+			return fmt.Errorf("invalid filter-prefixes entry %q: must be non-empty hex", prefix)
+		}
+	}
+	if cfg.FilterPeerRate < 0 {
+		return errors.New("filter-peer-rate can't be negative")
+	}
+	if cfg.FilterPeerBurst < 0 {
+		return errors.New("filter-peer-burst can't be negative")
+	}
+	if cfg.ProposalMaxTxBytes < 0 {
+		return errors.New("proposal-max-tx-bytes can't be negative")
+	}
+	for _, prefix := range cfg.ProposalFilterPrefixes {
+		if _, err := hex.DecodeString(prefix); err != nil || prefix == "" {
+			return fmt.Errorf("invalid proposal-filter-prefixes entry %q: must be non-empty hex", prefix)
+		}
+	}
 
 	return nil
 }
@@ -820,16 +1679,52 @@ type StateSyncConfig struct {
 	DiscoveryTime time.Duration `mapstructure:"discovery-time"`
 
 	// Temporary directory for state sync snapshot chunks, defaults to os.TempDir().
-	// The synchronizer will create a new, randomly named directory within this directory
-	// and remove it when the sync is complete.
+	// The synchronizer will create a directory named after the snapshot within this
+	// directory, and remove it when the sync of the snapshot is complete or the snapshot
+	// is rejected. A node restarted during the sync resumes the snapshot with the chunks
+	// of the directory.
 	TempDir string `mapstructure:"temp-dir"`
 
-	// The timeout duration before re-requesting a chunk, possibly from a different
-	// peer (default: 15 seconds).
+	// The timeout duration before re-requesting a chunk, from a different peer if
+	// possible (default: 15 seconds). The timeout is doubled on each request of the
+	// chunk, up to 8 times.
 	ChunkRequestTimeout time.Duration `mapstructure:"chunk-request-timeout"`
 
 	// The number of concurrent chunk and block fetchers to run (default: 4).
 	Fetchers int32 `mapstructure:"fetchers"`
+
+	// HTTPS mirrors to fetch the snapshot chunks from, in addition to peers.
+	// A mirror publishes the manifest of a snapshot at
+	// <mirror>/<height>/<format>/manifest.json, and its chunks, concatenated in
+	// order, at <mirror>/<height>/<format>/chunks, which must support range
+	// requests. The chunks are checked against the SHA-256 hashes of the
+	// manifest.
+	ChunkMirrors []string `mapstructure:"chunk-mirrors"`
+
+	// Node IDs of the preferred snapshot providers: their snapshots are
+	// tried first, and the chunks are requested from them first.
+	PreferredProviders []string `mapstructure:"preferred-providers"`
+
+	// If true, only the snapshots offered by the preferred providers, and
+	// their chunks, are accepted, e.g. to bootstrap only from one's own
+	// infrastructure.
+	ProviderAllowlist bool `mapstructure:"provider-allowlist"`
+
+	// How often the snapshot service lists the snapshots of the application,
+	// records the sizes and hashes of the chunks of the new ones, and prunes
+	// the records of the old ones. 0 disables the snapshot service.
+	SnapshotInterval time.Duration `mapstructure:"snapshot-interval"`
+
+	// The number of most recent snapshots the snapshot service keeps records
+	// of. 0 keeps the records of all the snapshots of the application.
+	SnapshotKeepRecent uint32 `mapstructure:"snapshot-keep-recent"`
+
+	// After a state sync, once the blocks of the evidence window are
+	// backfilled, keep backfilling the headers and commits of the blocks down
+	// to this height in the background, for the node to serve them to light
+	// clients. A backfill interrupted by a restart is not resumed. 0 only
+	// backfills the evidence window.
+	BackfillHeight int64 `mapstructure:"backfill-height"`
 }
 
 func (cfg *StateSyncConfig) TrustHashBytes() []byte {
@@ -848,6 +1743,7 @@ func DefaultStateSyncConfig() *StateSyncConfig {
 		DiscoveryTime:       15 * time.Second,
 		ChunkRequestTimeout: 15 * time.Second,
 		Fetchers:            4,
+		SnapshotKeepRecent:  2,
 	}
 }
 
@@ -858,6 +1754,14 @@ func TestStateSyncConfig() *StateSyncConfig {
 
 // ValidateBasic performs basic validation.
 func (cfg *StateSyncConfig) ValidateBasic() error {
+	if cfg.SnapshotInterval < 0 {
+		return errors.New("snapshot-interval can't be negative")
+	}
+
+	if cfg.BackfillHeight < 0 {
+		return errors.New("backfill-height can't be negative")
+	}
+
 	if !cfg.Enable {
 		return nil
 	}
@@ -905,6 +1809,87 @@ func (cfg *StateSyncConfig) ValidateBasic() error {
 		return errors.New("fetchers is required")
 	}
 
+	for _, id := range cfg.PreferredProviders {
+		if err := types.NodeID(id).Validate(); err != nil {
+			return fmt.Errorf("invalid preferred-providers entry %q: %w", id, err)
+		}
+	}
+
+	if cfg.ProviderAllowlist && len(cfg.PreferredProviders) == 0 {
+		return errors.New("provider-allowlist requires preferred-providers")
+	}
+
+	for _, mirror := range cfg.ChunkMirrors {
+		u, err := url.Parse(mirror)
+		if err != nil {
+			return fmt.Errorf("invalid chunk-mirrors entry %q: %w", mirror, err)
+		}
+		if u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("chunk-mirrors entry %q is not an https URL", mirror)
+		}
+	}
+
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// BlockSyncConfig
+
+// BlockSyncConfig defines the configuration for the Tendermint block sync
+// service, and in particular when it hands over to consensus and back.
+//
+// Lags are counted in heights behind the highest height reported by peers,
+// not counting the last height, whose block can't be verified before the
+// commit for it is in the next block.
+type BlockSyncConfig struct {
+	// Block sync hands over to consensus once it is at most this many heights
+	// behind the highest peer. 0 hands over only when fully caught up.
+	SwitchToConsensusLag int64 `mapstructure:"switch-to-consensus-lag"`
+
+	// Consensus hands back to block sync if it falls more than this many
+	// heights behind the highest peer for ReentryDelay. It must be greater
+	// than SwitchToConsensusLag, so that the node doesn't flap between the two.
+	// 0 disables switching back to block sync.
+	ReentryLag int64 `mapstructure:"reentry-lag"`
+
+	// How long consensus must stay more than ReentryLag heights behind before
+	// switching back to block sync. The node stays in consensus if the lag
+	// shrank fast enough over the last ReentryDelay to be closed within
+	// another ReentryDelay.
+	ReentryDelay time.Duration `mapstructure:"reentry-delay"`
+}
+
+// DefaultBlockSyncConfig returns a default configuration for the block sync
+// service.
+func DefaultBlockSyncConfig() *BlockSyncConfig {
+	return &BlockSyncConfig{
+		SwitchToConsensusLag: 0,
+		ReentryLag:           100,
+		ReentryDelay:         30 * time.Second,
+	}
+}
+
+// TestBlockSyncConfig returns a configuration for testing the block sync
+// service.
+func TestBlockSyncConfig() *BlockSyncConfig {
+	return DefaultBlockSyncConfig()
+}
+
+// ValidateBasic performs basic validation.
+func (cfg *BlockSyncConfig) ValidateBasic() error {
+	if cfg.SwitchToConsensusLag < 0 {
+		return errors.New("switch-to-consensus-lag can't be negative")
+	}
+	if cfg.ReentryLag < 0 {
+		return errors.New("reentry-lag can't be negative")
+	}
+	if cfg.ReentryLag > 0 && cfg.ReentryLag <= cfg.SwitchToConsensusLag {
+		return fmt.Errorf("reentry-lag (%d) must be greater than switch-to-consensus-lag (%d)",
+			cfg.ReentryLag, cfg.SwitchToConsensusLag)
+	}
+	if cfg.ReentryDelay < 0 {
+		return errors.New("reentry-delay can't be negative")
+	}
 	return nil
 }
 
@@ -948,6 +1933,17 @@ type ConsensusConfig struct {
 	PeerQueryMaj23SleepDuration time.Duration `mapstructure:"peer-query-maj23-sleep-duration"`
 
 	DoubleSignCheckHeight int64 `mapstructure:"double-sign-check-height"`
+
+	// Base64-encoded ed25519 public key of the validator authority, an
+	// external party allowed to schedule validator set updates through the
+	// RPC. The signed updates are broadcast as transactions, which the
+	// application verifies and applies. If empty, externally computed
+	// validator updates are rejected.
+	ValidatorAuthorityPubKey string `mapstructure:"validator-authority-pub-key"`
+
+	// Minimum number of blocks between the latest committed height and the
+	// effective height of an externally scheduled validator update.
+	ValidatorUpdateMinDelay int64 `mapstructure:"validator-update-min-delay"`
 }
 
 // DefaultConsensusConfig returns a default configuration for the consensus service
@@ -967,6 +1963,7 @@ func DefaultConsensusConfig() *ConsensusConfig {
 		PeerGossipSleepDuration:     100 * time.Millisecond,
 		PeerQueryMaj23SleepDuration: 2000 * time.Millisecond,
 		DoubleSignCheckHeight:       int64(0),
+		ValidatorUpdateMinDelay:     int64(10),
 	}
 }
 
@@ -984,6 +1981,7 @@ func TestConsensusConfig() *ConsensusConfig {
 	cfg.PeerGossipSleepDuration = 5 * time.Millisecond
 	cfg.PeerQueryMaj23SleepDuration = 250 * time.Millisecond
 	cfg.DoubleSignCheckHeight = int64(0)
+	cfg.ValidatorUpdateMinDelay = int64(1)
 	return cfg
 }
 
@@ -1068,6 +2066,18 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 	if cfg.DoubleSignCheckHeight < 0 {
 		return errors.New("double-sign-check-height can't be negative")
 	}
+	if cfg.ValidatorUpdateMinDelay < 0 {
+		return errors.New("validator-update-min-delay can't be negative")
+	}
+	if cfg.ValidatorAuthorityPubKey != "" {
+		bz, err := base64.StdEncoding.DecodeString(cfg.ValidatorAuthorityPubKey)
+		if err != nil {
+			return fmt.Errorf("invalid validator-authority-pub-key: %w", err)
+		}
+		if len(bz) != ed25519.PubKeySize {
+			return fmt.Errorf("validator-authority-pub-key must be %d bytes, got %d", ed25519.PubKeySize, len(bz))
+		}
+	}
 	return nil
 }
 
@@ -1084,7 +2094,8 @@ func (cfg *ConsensusConfig) ValidateBasic() error {
 // including composite keys to index.
 type TxIndexConfig struct {
 	// The backend database list to back the indexer.
-	// If list contains `null`, meaning no indexer service will be used.
+	// If the list is empty or only contains `null`, no indexer service will
+	// be used. `null` is ignored alongside other indexers.
 	//
 	// Options:
 	//   1) "null" - no indexer services.
@@ -1096,6 +2107,10 @@ type TxIndexConfig struct {
 	// The PostgreSQL connection configuration, the connection format:
 	// postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 	PsqlConn string `mapstructure:"psql-conn"`
+
+	// If true, the CheckTx responses of the transactions entering the mempool
+	// are stored by the event sinks supporting them, by transaction hash.
+	IndexCheckTx bool `mapstructure:"index-check-tx"`
 }
 
 // DefaultTxIndexConfig returns a default configuration for the transaction indexer.
@@ -1131,6 +2146,13 @@ type InstrumentationConfig struct {
 
 	// Instrumentation namespace.
 	Namespace string `mapstructure:"namespace"`
+
+	// URLs of the collectors to which a signed status report of the node is
+	// pushed periodically. Telemetry is disabled if empty.
+	TelemetryCollectors []string `mapstructure:"telemetry-collectors"`
+
+	// Interval between two telemetry reports.
+	TelemetryInterval time.Duration `mapstructure:"telemetry-interval"`
 }
 
 // DefaultInstrumentationConfig returns a default configuration for metrics
@@ -1141,6 +2163,8 @@ func DefaultInstrumentationConfig() *InstrumentationConfig {
 		PrometheusListenAddr: ":26660",
 		MaxOpenConnections:   3,
 		Namespace:            "tendermint",
+		TelemetryCollectors:  []string{},
+		TelemetryInterval:    time.Minute,
 	}
 }
 
@@ -1156,6 +2180,94 @@ func (cfg *InstrumentationConfig) ValidateBasic() error {
 	if cfg.MaxOpenConnections < 0 {
 		return errors.New("max-open-connections can't be negative")
 	}
+	for _, collector := range cfg.TelemetryCollectors {
+		u, err := url.Parse(collector)
+		if err != nil {
+			return fmt.Errorf("invalid telemetry collector %q: %w", collector, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("telemetry collector %q must be an http or https URL", collector)
+		}
+	}
+	if len(cfg.TelemetryCollectors) > 0 && cfg.TelemetryInterval < time.Second {
+		return errors.New("telemetry-interval must be at least 1s")
+	}
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// ResourcesConfig
+
+// ResourcesConfig defines the limits of the resources of the node, which are
+// watched to shed load before the node runs out of them.
+type ResourcesConfig struct {
+	// Interval between two checks of the resource usage. 0 disables the
+	// watchdog.
+	CheckInterval time.Duration `mapstructure:"check-interval"`
+
+	// Maximum number of open file descriptors, including the connections.
+	// 0 is the limit of the process set by the OS (RLIMIT_NOFILE).
+	MaxOpenFiles int64 `mapstructure:"max-open-files"`
+
+	// Maximum number of goroutines. 0 is unlimited.
+	MaxGoroutines int64 `mapstructure:"max-goroutines"`
+
+	// Maximum resident memory, in bytes. 0 is unlimited.
+	MaxMemoryBytes int64 `mapstructure:"max-memory-bytes"`
+
+	// Minimum free space of the disk of the data directory, in bytes. 0 is
+	// unlimited.
+	MinFreeDiskBytes int64 `mapstructure:"min-free-disk-bytes"`
+
+	// Fraction of a limit from which an alert is logged.
+	AlertThreshold float64 `mapstructure:"alert-threshold"`
+
+	// Fraction of a limit from which the node sheds load, i.e. rejects the
+	// RPC requests and inbound peers, until the usage of every resource is
+	// back under the alert threshold.
+	ShedThreshold float64 `mapstructure:"shed-threshold"`
+}
+
+// DefaultResourcesConfig returns a default configuration of the resource
+// limits.
+func DefaultResourcesConfig() *ResourcesConfig {
+	return &ResourcesConfig{
+		CheckInterval:  10 * time.Second,
+		AlertThreshold: 0.8,
+		ShedThreshold:  0.9,
+	}
+}
+
+// TestResourcesConfig returns a configuration of the resource limits for
+// testing.
+func TestResourcesConfig() *ResourcesConfig {
+	return DefaultResourcesConfig()
+}
+
+// ValidateBasic performs basic validation (checking param bounds, etc.) and
+// returns an error if any check fails.
+func (cfg *ResourcesConfig) ValidateBasic() error {
+	if cfg.CheckInterval < 0 {
+		return errors.New("check-interval can't be negative")
+	}
+	if cfg.MaxOpenFiles < 0 {
+		return errors.New("max-open-files can't be negative")
+	}
+	if cfg.MaxGoroutines < 0 {
+		return errors.New("max-goroutines can't be negative")
+	}
+	if cfg.MaxMemoryBytes < 0 {
+		return errors.New("max-memory-bytes can't be negative")
+	}
+	if cfg.MinFreeDiskBytes < 0 {
+		return errors.New("min-free-disk-bytes can't be negative")
+	}
+	if cfg.AlertThreshold <= 0 || cfg.AlertThreshold > 1 {
+		return errors.New("alert-threshold must be in (0, 1]")
+	}
+	if cfg.ShedThreshold < cfg.AlertThreshold || cfg.ShedThreshold > 1 {
+		return errors.New("shed-threshold must be between alert-threshold and 1")
+	}
 	return nil
 }
 
@@ -2,6 +2,7 @@ package config
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +24,10 @@ func TestDefaultConfig(t *testing.T) {
 
 	assert.Equal(t, "/foo/bar", cfg.GenesisFile())
 	assert.Equal(t, "/opt/data", cfg.DBDir())
+	assert.Equal(t, "/foo/config/encryption_key.json", cfg.EncryptionKeyFile())
+	assert.Equal(t, "/foo/data/forensics", cfg.ForensicsDir())
+	cfg.ForensicsPath = ""
+	assert.Equal(t, "", cfg.ForensicsDir())
 }
 
 func TestConfigValidateBasic(t *testing.T) {
@@ -56,6 +61,27 @@ func TestBaseConfigValidateBasic(t *testing.T) {
 	// tamper with log format
 	cfg.LogFormat = "invalid"
 	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.Features = []string{"blocksync.verify-ahead", "module.feature=false"}
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.Features = []string{"module.feature=maybe"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.Features = []string{"=true"}
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.MinRetainBlocks = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg = TestBaseConfig()
+	cfg.PruningInterval = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestBaseConfig()
+	cfg.DBCompression = DBCompressionZstd
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.DBCompression = "gzip"
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestRPCConfigValidateBasic(t *testing.T) {
@@ -63,12 +89,25 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 	assert.NoError(t, cfg.ValidateBasic())
 
 	fieldsToTest := []string{
+		"GRPCMaxOpenConnections",
 		"MaxOpenConnections",
 		"MaxSubscriptionClients",
 		"MaxSubscriptionsPerClient",
+		"MaxWebsocketConnections",
+		"WebsocketDrainTimeout",
+		"EventLogWindowSize",
+		"EventLogMaxItems",
 		"TimeoutBroadcastTxCommit",
+		"IdempotencyWindow",
+		"BroadcastDedupWindow",
+		"BroadcastMaxSyncLag",
+		"TimeoutABCIQuery",
+		"ABCIQueryCacheSize",
 		"MaxBodyBytes",
 		"MaxHeaderBytes",
+		"StreamBatchThreshold",
+		"ResponseCacheSize",
+		"ResponseCacheTTL",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -76,6 +115,42 @@ func TestRPCConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.RateLimit = RPCRateLimit{Rate: -1}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RateLimit = RPCRateLimit{Rate: 1}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RateLimit = RPCRateLimit{Rate: 1, Burst: 1}
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.MethodRateLimits = map[string]RPCRateLimit{"tx_search": {Rate: 1}}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MethodRateLimits = nil
+
+	cfg.EventLogPersistent = true
+	cfg.EventLogWindowSize = time.Hour
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.EventLogWindowSize = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.EventLogPersistent = false
+
+	cfg.HTTP2 = true
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.HTTP2MaxConcurrentStreams = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.HTTP2MaxConcurrentStreams = 100
+	cfg.HTTP2StreamWindowBytes = 1024
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.HTTP2StreamWindowBytes = 1 << 20
+	cfg.HTTP2ConnWindowBytes = 1 << 19
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.HTTP2 = false
+
+	cfg.UnixSocketMode = "0660"
+	assert.NoError(t, cfg.ValidateBasic())
+	for _, mode := range []string{"rw", "0o660", "0", "1777"} {
+		cfg.UnixSocketMode = mode
+		assert.Error(t, cfg.ValidateBasic(), mode)
+	}
 }
 
 func TestMempoolConfigValidateBasic(t *testing.T) {
@@ -85,8 +160,12 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 	fieldsToTest := []string{
 		"Size",
 		"MaxTxsBytes",
+		"MaxSourceIPBytes",
 		"CacheSize",
+		"EvictionJournalSize",
 		"MaxTxBytes",
+		"FilterPeerBurst",
+		"ProposalMaxTxBytes",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -94,11 +173,76 @@ func TestMempoolConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.FilterPeerRate = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.FilterPeerRate = 0
+
+	cfg.FilterPrefixes = []string{"zz"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.FilterPrefixes = []string{"abcd"}
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.ProposalFilterPrefixes = []string{""}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.ProposalFilterPrefixes = []string{"abcd"}
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.CheckTxConnections = 0
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.CheckTxConnections = 4
+	assert.NoError(t, cfg.ValidateBasic())
 }
 
 func TestStateSyncConfigValidateBasic(t *testing.T) {
 	cfg := TestStateSyncConfig()
 	require.NoError(t, cfg.ValidateBasic())
+
+	cfg.Enable = true
+	cfg.UseP2P = true
+	cfg.TrustHeight = 1
+	cfg.TrustHash = "0A0B"
+	require.NoError(t, cfg.ValidateBasic())
+
+	cfg.ChunkMirrors = []string{"https://snapshots.example.com/chain"}
+	require.NoError(t, cfg.ValidateBasic())
+	cfg.ChunkMirrors = []string{"http://snapshots.example.com/chain"}
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ChunkMirrors = []string{""}
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ChunkMirrors = nil
+
+	cfg.PreferredProviders = []string{strings.Repeat("ab", 20)}
+	cfg.ProviderAllowlist = true
+	require.NoError(t, cfg.ValidateBasic())
+	cfg.PreferredProviders = []string{"not a node ID"}
+	require.Error(t, cfg.ValidateBasic())
+	cfg.PreferredProviders = nil
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ProviderAllowlist = false
+
+	cfg.Enable = false
+	cfg.SnapshotInterval = -time.Second
+	require.Error(t, cfg.ValidateBasic())
+}
+
+func TestBlockSyncConfigValidateBasic(t *testing.T) {
+	cfg := TestBlockSyncConfig()
+	require.NoError(t, cfg.ValidateBasic())
+
+	cfg.SwitchToConsensusLag = -1
+	require.Error(t, cfg.ValidateBasic())
+	cfg.SwitchToConsensusLag = 10
+
+	cfg.ReentryLag = 10
+	require.Error(t, cfg.ValidateBasic())
+	cfg.ReentryLag = 0
+	require.NoError(t, cfg.ValidateBasic())
+	cfg.ReentryLag = 11
+	require.NoError(t, cfg.ValidateBasic())
+
+	cfg.ReentryDelay = -1
+	require.Error(t, cfg.ValidateBasic())
 }
 
 func TestConsensusConfig_ValidateBasic(t *testing.T) {
@@ -149,6 +293,17 @@ func TestInstrumentationConfigValidateBasic(t *testing.T) {
 	// tamper with maximum open connections
 	cfg.MaxOpenConnections = -1
 	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestInstrumentationConfig()
+	cfg.TelemetryCollectors = []string{"https://collector.example.com/reports"}
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.TelemetryInterval = 100 * time.Millisecond
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.TelemetryInterval = time.Minute
+	cfg.TelemetryCollectors = []string{"tcp://collector.example.com:26660"}
+	assert.Error(t, cfg.ValidateBasic())
 }
 
 func TestP2PConfigValidateBasic(t *testing.T) {
@@ -160,6 +315,9 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		"MaxPacketMsgPayloadSize",
 		"SendRate",
 		"RecvRate",
+		"StaleAddressTime",
+		"PeerGCInterval",
+		"DialInterval",
 	}
 
 	for _, fieldName := range fieldsToTest {
@@ -167,4 +325,89 @@ func TestP2PConfigValidateBasic(t *testing.T) {
 		assert.Error(t, cfg.ValidateBasic())
 		reflect.ValueOf(cfg).Elem().FieldByName(fieldName).SetInt(0)
 	}
+
+	cfg.MaintenanceWindows = []string{"2026-10-20T14:00:00Z/2026-10-20T14:30:00Z"}
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.MaintenanceWindows = []string{"2026-10-20T14:30:00Z/2026-10-20T14:00:00Z"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaintenanceWindows = []string{"2026-10-20T14:00:00Z"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaintenanceWindows = nil
+
+	cfg.ChannelRecvRates = []string{"0x30=1048576", "32=1024"}
+	assert.NoError(t, cfg.ValidateBasic())
+	limits, err := cfg.ChannelRecvRateLimits()
+	assert.NoError(t, err)
+	assert.Equal(t, map[byte]int64{0x30: 1048576, 0x20: 1024}, limits)
+	for _, rate := range []string{"0x30", "0x100=1", "0x30=0", "0x30=x", "0x30=1,0x30=2"} {
+		cfg.ChannelRecvRates = strings.Split(rate, ",")
+		assert.Error(t, cfg.ValidateBasic(), rate)
+	}
+	cfg.ChannelRecvRates = nil
+
+	cfg.OptOutChannels = []string{"0x30", "56"}
+	assert.NoError(t, cfg.ValidateBasic())
+	ids, err := cfg.OptOutChannelIDs()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0x30, 0x38}, ids)
+	cfg.OptOutChannels = []string{"0x100"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.OptOutChannels = nil
+
+	cfg.RelayPorts = "26700-26799"
+	assert.NoError(t, cfg.ValidateBasic())
+	first, last, err := cfg.RelayPortRange()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{26700, 26799}, []int{first, last})
+	for _, ports := range []string{"26700", "26799-26700", "0-10", "1-65536", "x-26799"} {
+		cfg.RelayPorts = ports
+		assert.Error(t, cfg.ValidateBasic(), ports)
+	}
+	cfg.RelayPorts = ""
+	cfg.RelayMaxReservations = -1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.RelayMaxReservations = 0
+
+	cfg.AllowCIDRs = []string{"10.0.0.0/8", "192.0.2.7", "2001:db8::/32"}
+	cfg.DenyNodeIDs = []string{"f9baeaa15fedf5e1ef7448dd60f46c01f1a9e9c4"}
+	cfg.AllowChainIDs = []string{"test-chain"}
+	assert.NoError(t, cfg.ValidateBasic())
+	cfg.DenyCIDRs = []string{"10.0.0.0/33"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.DenyCIDRs = nil
+	cfg.AllowNodeIDs = []string{"node"}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.AllowNodeIDs = nil
+	cfg.DenyChainIDs = []string{""}
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.DenyChainIDs = nil
+
+	cfg.MaxPeers = cfg.MaxConnections - 1
+	assert.Error(t, cfg.ValidateBasic())
+	cfg.MaxPeers = 0
+	assert.NoError(t, cfg.ValidateBasic())
+}
+
+func TestResourcesConfigValidateBasic(t *testing.T) {
+	cfg := TestResourcesConfig()
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.CheckInterval = 0
+	cfg.MinFreeDiskBytes = 1 << 30
+	cfg.AlertThreshold = 0.9
+	assert.NoError(t, cfg.ValidateBasic())
+
+	cfg.MaxOpenFiles = -1
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestResourcesConfig()
+	cfg.AlertThreshold = 0
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg = TestResourcesConfig()
+	cfg.ShedThreshold = 0.5
+	assert.Error(t, cfg.ValidateBasic())
+
+	cfg.ShedThreshold = 1.5
+	assert.Error(t, cfg.ValidateBasic())
 }
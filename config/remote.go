@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// remoteConfigTimeout bounds the time spent fetching a remote config.
+	remoteConfigTimeout = 30 * time.Second
+
+	// maxRemoteConfigBytes bounds the size of a remote config.
+	maxRemoteConfigBytes = 1 << 20
+)
+
+// remoteConfigClient is the client fetching the remote configs.
+var remoteConfigClient = http.DefaultClient
+
+// FetchRemoteConfig fetches a configuration, in the TOML format of
+// config.toml, from source, which is one of:
+//
+//	http(s)://host/path            the file at a URL
+//	consul(+https)://host:port/key the value of a key of the Consul KV store
+//	etcd(+https)://host:port/key   the value of a key of the etcd (v3) KV store
+//
+// The Consul and etcd APIs are reached over https with the +https schemes,
+// or for Consul if the CONSUL_HTTP_SSL environment variable is true. The
+// Consul ACL token, if any, is read from the CONSUL_HTTP_TOKEN environment
+// variable, and is only sent over plain http to a loopback address. If
+// checksum is not empty, it is the hex-encoded SHA-256 hash that the
+// configuration must have.
+func FetchRemoteConfig(ctx context.Context, source, checksum string) ([]byte, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote config source: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, remoteConfigTimeout)
+	defer cancel()
+
+	var data []byte
+	switch u.Scheme {
+	case "http", "https":
+		data, err = fetchHTTP(ctx, http.MethodGet, source, nil, nil)
+	case "consul", "consul+https":
+		data, err = fetchConsul(ctx, u)
+	case "etcd", "etcd+https":
+		data, err = fetchEtcd(ctx, u)
+	default:
+		return nil, fmt.Errorf("unsupported remote config source %q", source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fetching remote config from %s: %w", u.Redacted(), err)
+	}
+
+	if checksum != "" {
+		sum := sha256.Sum256(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), checksum) {
+			return nil, fmt.Errorf("remote config from %s has SHA-256 %X, expected %s",
+				u.Redacted(), sum, checksum)
+		}
+	}
+	return data, nil
+}
+
+func fetchHTTP(ctx context.Context, method, target string, header http.Header, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := remoteConfigClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server responded with status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteConfigBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRemoteConfigBytes {
+		return nil, fmt.Errorf("config exceeds %d bytes", maxRemoteConfigBytes)
+	}
+	return data, nil
+}
+
+// apiScheme returns the scheme of the API of a KV store for the scheme of a
+// remote config source, https if it has the +https suffix or useSSL is set.
+func apiScheme(u *url.URL, useSSL bool) string {
+	if useSSL || strings.HasSuffix(u.Scheme, "+https") {
+		return "https"
+	}
+	return "http"
+}
+
+// isLoopback returns true if host is localhost or a loopback IP address.
+func isLoopback(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func fetchConsul(ctx context.Context, u *url.URL) ([]byte, error) {
+	var useSSL bool
+	if v := os.Getenv("CONSUL_HTTP_SSL"); v != "" {
+		var err error
+		if useSSL, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid CONSUL_HTTP_SSL %q: %w", v, err)
+		}
+	}
+	target := url.URL{
+		Scheme:   apiScheme(u, useSSL),
+		Host:     u.Host,
+		Path:     "/v1/kv/" + strings.TrimPrefix(u.Path, "/"),
+		RawQuery: "raw",
+	}
+	header := make(http.Header)
+	if token := os.Getenv("CONSUL_HTTP_TOKEN"); token != "" {
+		if target.Scheme == "http" && !isLoopback(u.Hostname()) {
+			return nil, errors.New("refusing to send CONSUL_HTTP_TOKEN over plain http to a remote host, " +
+				"use consul+https:// or set CONSUL_HTTP_SSL")
+		}
+		header.Set("X-Consul-Token", token)
+	}
+	return fetchHTTP(ctx, http.MethodGet, target.String(), header, nil)
+}
+
+func fetchEtcd(ctx context.Context, u *url.URL) ([]byte, error) {
+	target := url.URL{Scheme: apiScheme(u, false), Host: u.Host, Path: "/v3/kv/range"}
+	key := strings.TrimPrefix(u.Path, "/")
+	body, err := json.Marshal(map[string][]byte{"key": []byte(key)})
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	data, err := fetchHTTP(ctx, http.MethodPost, target.String(), header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// The gRPC gateway of etcd encodes bytes fields in base64.
+	var rsp struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.Unmarshal(data, &rsp); err != nil {
+		return nil, fmt.Errorf("decoding etcd response: %w", err)
+	}
+	if len(rsp.Kvs) == 0 {
+		return nil, errors.New("key not found")
+	}
+	return base64.StdEncoding.DecodeString(rsp.Kvs[0].Value)
+}
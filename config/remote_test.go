@@ -0,0 +1,118 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchRemoteConfig(t *testing.T) {
+	const remote = "moniker = \"remote\"\n"
+	sum := sha256.Sum256([]byte(remote))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/config.toml":
+			_, _ = w.Write([]byte(remote))
+
+		case req.URL.Path == "/v1/kv/fleet/config.toml" && req.URL.RawQuery == "raw":
+			if req.Header.Get("X-Consul-Token") != "secret" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(remote))
+
+		case req.URL.Path == "/v3/kv/range" && req.Method == http.MethodPost:
+			var body struct {
+				Key []byte `json:"key"`
+			}
+			require.NoError(t, json.NewDecoder(req.Body).Decode(&body))
+			if string(body.Key) != "fleet/config.toml" {
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"kvs":[{"value":"` + base64.StdEncoding.EncodeToString([]byte(remote)) + `"}]}`))
+
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	t.Setenv("CONSUL_HTTP_TOKEN", "secret")
+
+	ctx := context.Background()
+	for _, source := range []string{
+		srv.URL + "/config.toml",
+		"consul://" + host + "/fleet/config.toml",
+		"etcd://" + host + "/fleet/config.toml",
+	} {
+		data, err := FetchRemoteConfig(ctx, source, "")
+		require.NoError(t, err, source)
+		assert.Equal(t, remote, string(data), source)
+
+		_, err = FetchRemoteConfig(ctx, source, hex.EncodeToString(sum[:]))
+		assert.NoError(t, err, source)
+		_, err = FetchRemoteConfig(ctx, source, strings.Repeat("00", sha256.Size))
+		assert.Error(t, err, source)
+	}
+
+	for _, source := range []string{
+		srv.URL + "/missing.toml",
+		"etcd://" + host + "/missing",
+		"ftp://" + host + "/config.toml",
+		// The token is not sent over plain http to a remote host.
+		"consul://192.0.2.1:8500/fleet/config.toml",
+	} {
+		_, err := FetchRemoteConfig(ctx, source, "")
+		assert.Error(t, err, source)
+	}
+}
+
+func TestFetchRemoteConfigHTTPS(t *testing.T) {
+	const remote = "moniker = \"remote\"\n"
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.URL.Path == "/v1/kv/fleet/config.toml" && req.Header.Get("X-Consul-Token") == "secret":
+			_, _ = w.Write([]byte(remote))
+		case req.URL.Path == "/v3/kv/range":
+			_, _ = w.Write([]byte(`{"kvs":[{"value":"` + base64.StdEncoding.EncodeToString([]byte(remote)) + `"}]}`))
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	client := remoteConfigClient
+	remoteConfigClient = srv.Client()
+	defer func() { remoteConfigClient = client }()
+	t.Setenv("CONSUL_HTTP_TOKEN", "secret")
+
+	ctx := context.Background()
+	for _, source := range []string{
+		"consul+https://" + host + "/fleet/config.toml",
+		"etcd+https://" + host + "/fleet/config.toml",
+	} {
+		data, err := FetchRemoteConfig(ctx, source, "")
+		require.NoError(t, err, source)
+		assert.Equal(t, remote, string(data), source)
+	}
+
+	// CONSUL_HTTP_SSL switches consul:// to https.
+	_, err := FetchRemoteConfig(ctx, "consul://"+host+"/fleet/config.toml", "")
+	require.Error(t, err)
+	t.Setenv("CONSUL_HTTP_SSL", "true")
+	data, err := FetchRemoteConfig(ctx, "consul://"+host+"/fleet/config.toml", "")
+	require.NoError(t, err)
+	assert.Equal(t, remote, string(data))
+}
@@ -127,6 +127,11 @@ db-backend = "{{ .BaseConfig.DBBackend }}"
 # Database directory
 db-dir = "{{ js .BaseConfig.DBPath }}"
 
+# Directory of the forensic bundles captured on app hash mismatches, with the
+# block, the ABCI requests and responses of its execution, and a summary of the
+# state. Empty disables them.
+forensics-dir = "{{ js .BaseConfig.ForensicsPath }}"
+
 # Output level for logging, including package level options
 log-level = "{{ .BaseConfig.LogLevel }}"
 
@@ -148,6 +153,47 @@ abci = "{{ .BaseConfig.ABCI }}"
 # so the app can decide if we should keep the connection or not
 filter-peers = {{ .BaseConfig.FilterPeers }}
 
+# Encryption at rest of the consensus WAL, and of the blockstore and evidence
+# databases, with AES-GCM: "" (disabled) | "file" | the name of a key provider
+# compiled in with the Tendermint binary, e.g. for a key management service.
+# Data written while encryption was disabled stays readable.
+encryption-key-provider = "{{ .BaseConfig.EncryptionKeyProvider }}"
+
+# Path to the JSON file containing the keys of the "file" encryption key provider,
+# created by the gen-encryption-key command
+encryption-key-file = "{{ js .BaseConfig.EncryptionKey }}"
+
+# Compression of the values of the blockstore and state databases, i.e. of the
+# block parts and the block results: none | snappy | zstd. zstd requires a
+# binary built with the zstd build tag. Data written with another compression,
+# or none, stays readable: the compress-data command rewrites it.
+db-compression = "{{ .BaseConfig.DBCompression }}"
+
+# Feature flags gating the experimental and beta behaviors of the node,
+# comma-separated: "<name>" enables a flag, "<name>=false" disables it. The
+# flags not given have their default value. The flags of the node, and their
+# values, are listed by the /status RPC endpoint.
+features = "{{ StringsJoin .BaseConfig.Features "," }}"
+
+# Consistency check at startup of the blockstore, the state, the consensus WAL
+# and the last signed state of the validator: off | report | repair
+# * report
+#   - refuse to start on an inconsistency, e.g. a state ahead of the blocks, and
+#     print the commands recovering from it
+# * repair
+#   - as report, but first repair the inconsistencies which can be safely
+#     repaired, e.g. by moving aside a consensus WAL ahead of the blockstore
+integrity-check = "{{ .BaseConfig.IntegrityCheck }}"
+
+# Minimum number of the last blocks to retain when the application requests
+# pruning with a retain height on Commit, 0 to retain no minimum. The blocks
+# within the evidence window are always retained.
+min-retain-blocks = {{ .BaseConfig.MinRetainBlocks }}
+
+# How often the blocks, their results and the indexed transactions below the
+# retain height are pruned, in the background.
+pruning-interval = "{{ .BaseConfig.PruningInterval }}"
+
 
 #######################################################
 ###       Priv Validator Configuration              ###
@@ -185,9 +231,16 @@ root-ca-file = "{{ js .PrivValidator.RootCA }}"
 #######################################################
 [rpc]
 
-# TCP or UNIX socket address for the RPC server to listen on
+# TCP or UNIX socket address for the RPC server to listen on, e.g.
+# "unix:///var/run/tendermint/rpc.sock". Several comma-separated addresses can
+# be given, to serve the RPC on each of them.
 laddr = "{{ .RPC.ListenAddress }}"
 
+# Permissions of the UNIX sockets of laddr, in octal (e.g. "0660"), so that
+# only the local processes allowed to can call the RPC. If empty, they are set
+# by the umask of the process.
+unix-socket-mode = "{{ .RPC.UnixSocketMode }}"
+
 # A list of origins a cross-domain request can be executed from
 # Default value '[]' disables cors support
 # Use '["*"]' to allow any origin
@@ -199,9 +252,30 @@ cors-allowed-methods = [{{ range .RPC.CORSAllowedMethods }}{{ printf "%q, " . }}
 # A list of non simple headers the client is allowed to use with cross-domain requests
 cors-allowed-headers = [{{ range .RPC.CORSAllowedHeaders }}{{ printf "%q, " . }}{{end}}]
 
+# TCP or UNIX socket address for the gRPC server to listen on
+# The gRPC server serves the tendermint.rpc.CoreAPI service, which has the
+# methods of the RPC server except the unsafe ones. An empty address disables it.
+grpc-laddr = "{{ .RPC.GRPCListenAddress }}"
+
+# Maximum number of simultaneous connections.
+# Does not include RPC (HTTP&WebSocket) connections. See max-open-connections
+# If you want to accept a larger number than the default, make sure
+# you increase your OS limits.
+# 0 - unlimited.
+# Should be < {ulimit -Sn} - {MaxNumInboundPeers} - {MaxNumOutboundPeers} - {N of wal, db and other open files}
+# 1024 - 40 - 10 - 50 = 924 = ~900
+grpc-max-open-connections = {{ .RPC.GRPCMaxOpenConnections }}
+
 # Activate unsafe RPC commands like /dial-seeds and /unsafe-flush-mempool
 unsafe = {{ .RPC.Unsafe }}
 
+# Remove the RPC methods submitting transactions or evidence, or otherwise
+# mutating the state of the node, i.e. /broadcast_tx_*, /broadcast_evidence,
+# /remove_tx, /validator_set_update and the unsafe methods, from the HTTP,
+# websocket and gRPC servers, to expose query-only endpoints. It takes
+# precedence over unsafe.
+read-only = {{ .RPC.ReadOnly }}
+
 # Maximum number of simultaneous connections (including WebSocket).
 # If you want to accept a larger number than the default, make sure
 # you increase your OS limits.
@@ -220,18 +294,112 @@ max-subscription-clients = {{ .RPC.MaxSubscriptionClients }}
 # to the estimated maximum number of broadcast_tx_commit calls per block.
 max-subscriptions-per-client = {{ .RPC.MaxSubscriptionsPerClient }}
 
+# Maximum number of concurrent WebSocket connections, across all the listen
+# addresses. Further connections are rejected with status 503 Service
+# Unavailable. 0 means no limit.
+max-websocket-connections = {{ .RPC.MaxWebsocketConnections }}
+
+# How long the node waits, when it shuts down, for the events already
+# published to the WebSocket subscriptions to be written, before closing the
+# connections with a close frame. New subscriptions are rejected in the
+# meantime. The connections still open afterwards are dropped.
+websocket-drain-timeout = "{{ .RPC.WebsocketDrainTimeout }}"
+
+# How long the events published by the node are retained, so that a
+# WebSocket client can resume its subscriptions after a disconnection
+# without missing any event: the client passes the sequence number of the
+# last event it received to /subscribe. 0 disables the event log.
+event-log-window-size = "{{ .RPC.EventLogWindowSize }}"
+
+# Maximum number of events retained by the event log. 0 means no limit
+# other than the window size.
+event-log-max-items = {{ .RPC.EventLogMaxItems }}
+
+# If true, the event log is stored in the eventlog database rather than in
+# memory: the clients can resume their subscriptions across restarts of the
+# node, and a subscription whose buffer is full catches up from the event log
+# instead of being terminated, as long as the events it missed are retained.
+# Set event-log-window-size to the retention needed, e.g. "24h".
+event-log-persistent = {{ .RPC.EventLogPersistent }}
+
 # How long to wait for a tx to be committed during /broadcast_tx_commit.
 # WARNING: Using a value larger than 10s will result in increasing the
 # global HTTP write timeout, which applies to all connections and endpoints.
 # See https://github.com/tendermint/tendermint/issues/3435
 timeout-broadcast-tx-commit = "{{ .RPC.TimeoutBroadcastTxCommit }}"
 
+# How long to remember the idempotency key of a successful broadcast_tx_*
+# request, given in the Idempotency-Key header or the idempotency_key
+# parameter. Retries of the request within the window get its response
+# instead of broadcasting the transaction again. 0 disables idempotency keys.
+idempotency-window = "{{ .RPC.IdempotencyWindow }}"
+
+# How long to remember the transactions broadcast over each RPC listener.
+# Duplicates of a transaction broadcast within the window get the result of the
+# original broadcast, and broadcast_tx_commit waits for it to be committed,
+# instead of being rejected by the mempool cache. 0 disables deduplication.
+broadcast-dedup-window = "{{ .RPC.BroadcastDedupWindow }}"
+
+# Reject the broadcast_tx_* requests while the node is syncing more than this
+# many blocks behind its peers, or the snapshot it restores, with a "node
+# catching up" error: the transactions would be checked against a stale state,
+# and most of them would fail their recheck once the node caught up. 0 accepts
+# the transactions regardless of the sync state.
+broadcast-max-sync-lag = {{ .RPC.BroadcastMaxSyncLag }}
+
+# How long an abci_query or abci_info request waits for the application. The
+# request is also abandoned as soon as its client goes away or its gRPC
+# deadline expires. 0 means no limit.
+timeout-abci-query = "{{ .RPC.TimeoutABCIQuery }}"
+
+# Number of responses to abci_query requests kept in memory, for the queries
+# the application declares cacheable in its responses, with a max-age or until
+# the next block: the same queries are then answered without querying the
+# application. 0 disables the cache.
+abci-query-cache-size = {{ .RPC.ABCIQueryCacheSize }}
+
 # Maximum size of request body, in bytes
 max-body-bytes = {{ .RPC.MaxBodyBytes }}
 
 # Maximum size of request header, in bytes
 max-header-bytes = {{ .RPC.MaxHeaderBytes }}
 
+# Stream the responses to JSON-RPC batches of at least this many requests as
+# they are computed, with chunked transfer encoding, instead of buffering the
+# whole batch response in memory. 0 disables streaming.
+stream-batch-threshold = {{ .RPC.StreamBatchThreshold }}
+
+# Enforce the JSON-RPC 2.0 specification, for clients relying on it: the
+# requests must have the "jsonrpc": "2.0" member, IDs must be strings, integers
+# or null and are echoed as sent, and every response, including the errors, has
+# an ID and the standard structure.
+strict-jsonrpc = {{ .RPC.StrictJSONRPC }}
+
+# Number of responses to immutable queries (block, block_results, commit and
+# validators at a given past height) kept in memory, to serve them without
+# reading the stores, with ETag and Cache-Control headers. 0 disables the
+# cache.
+response-cache-size = {{ .RPC.ResponseCacheSize }}
+
+# How long a response is kept in the cache, and may be kept by HTTP caches.
+# 0 keeps the responses until they are evicted by newer ones.
+response-cache-ttl = "{{ .RPC.ResponseCacheTTL }}"
+
+# Serve HTTP/2 over cleartext connections (h2c), so that clients can multiplex
+# many in-flight calls over a single connection, and apply the HTTP/2 limits
+# below to cleartext and TLS connections. Websocket connections keep using
+# HTTP/1.1.
+http2 = {{ .RPC.HTTP2 }}
+
+# Maximum number of concurrent calls (streams) per HTTP/2 connection
+http2-max-concurrent-streams = {{ .RPC.HTTP2MaxConcurrentStreams }}
+
+# HTTP/2 flow control window of a call, in bytes
+http2-stream-window-bytes = {{ .RPC.HTTP2StreamWindowBytes }}
+
+# HTTP/2 flow control window of a connection, shared by its calls, in bytes
+http2-conn-window-bytes = {{ .RPC.HTTP2ConnWindowBytes }}
+
 # The path to a file containing certificate that is used to create the HTTPS server.
 # Might be either absolute path or path related to Tendermint's config directory.
 # If the certificate is signed by a certificate authority,
@@ -247,9 +415,74 @@ tls-cert-file = "{{ .RPC.TLSCertFile }}"
 # Otherwise, HTTP server is run.
 tls-key-file = "{{ .RPC.TLSKeyFile }}"
 
+# A list of domain names for which the RPC server obtains and renews
+# certificates automatically from an ACME certificate authority (such as
+# Let's Encrypt). Certificates are renewed before expiry and picked up
+# without a restart.
+# NOTE: acme-domains cannot be combined with tls-cert-file and tls-key-file.
+acme-domains = [{{ range .RPC.ACMEDomains }}{{ printf "%q, " . }}{{end}}]
+
+# Contact email address registered with the ACME account.
+acme-email = "{{ .RPC.ACMEEmail }}"
+
+# Directory where ACME account keys and certificates are cached.
+# Might be either absolute path or path related to Tendermint's data directory.
+acme-cache-dir = "{{ .RPC.ACMECacheDir }}"
+
+# ACME directory URL of the certificate authority. If empty, the Let's
+# Encrypt production directory is used.
+acme-directory-url = "{{ .RPC.ACMEDirectoryURL }}"
+
+# TCP address on which to answer ACME HTTP-01 challenges, e.g. ":80".
+# If empty, only the TLS-ALPN-01 challenge is used.
+acme-http-laddr = "{{ .RPC.ACMEHTTPListenAddress }}"
+
 # pprof listen address (https://golang.org/pkg/net/http/pprof)
 pprof-laddr = "{{ .RPC.PprofListenAddress }}"
 
+# Number of items returned per page by paginated endpoints (e.g. /tx_search,
+# /validators) when per_page is not given, and the largest per_page accepted.
+# Requests for larger pages are rejected, unless unsafe is enabled.
+default-per-page = {{ .RPC.DefaultPerPage }}
+max-per-page = {{ .RPC.MaxPerPage }}
+
+# Per-endpoint overrides of the settings above. Unset values fall back to the
+# global settings, e.g.:
+#
+# [rpc.per-page.tx_search]
+# default-per-page = 10
+# max-per-page = 1000
+{{ range $endpoint, $limits := .RPC.PerPage }}
+[rpc.per-page.{{ $endpoint }}]
+default-per-page = {{ $limits.DefaultPerPage }}
+max-per-page = {{ $limits.MaxPerPage }}
+{{ end }}
+
+# Rate limit of the calls of all the endpoints, over HTTP and WebSocket, as a
+# token bucket: calls are accepted at rate calls per second on average, with
+# bursts of up to burst calls. Calls over the limit are rejected with status
+# 429 Too Many Requests (HTTP) or a JSON-RPC error. A zero rate disables it.
+# With per-ip, the limit applies to each remote IP address separately, rather
+# than to all the clients together.
+[rpc.rate-limit]
+rate = {{ .RPC.RateLimit.Rate }}
+burst = {{ .RPC.RateLimit.Burst }}
+per-ip = {{ .RPC.RateLimit.PerIP }}
+
+# Rate limits of specific endpoints, which apply in addition to the limit
+# above, e.g. to throttle expensive endpoints:
+#
+# [rpc.method-rate-limit.tx_search]
+# rate = 1
+# burst = 5
+# per-ip = true
+{{ range $endpoint, $limit := .RPC.MethodRateLimits }}
+[rpc.method-rate-limit.{{ $endpoint }}]
+rate = {{ $limit.Rate }}
+burst = {{ $limit.Burst }}
+per-ip = {{ $limit.PerIP }}
+{{ end }}
+
 #######################################################
 ###           P2P Configuration Options             ###
 #######################################################
@@ -283,15 +516,75 @@ bootstrap-peers = "{{ .P2P.BootstrapPeers }}"
 # Comma separated list of nodes to keep persistent connections to
 persistent-peers = "{{ .P2P.PersistentPeers }}"
 
-# UPNP port forwarding
+# Map the p2p port on the NAT gateway with UPnP, or NAT-PMP if the gateway
+# doesn't support UPnP, and advertise the mapped address. It is ignored if
+# the external address is set.
 upnp = {{ .P2P.UPNP }}
 
+# Address (host:port) of a relay server, accepting the inbound connections of
+# the node when it is behind a NAT and the port can't be mapped. The node
+# advertises the port the relay reserves for it. It is ignored if the
+# external address is set.
+relay = "{{ .P2P.Relay }}"
+
+# Address (host:port) to run a relay server on, for the nodes behind a NAT.
+# Empty disables the relay server.
+relay-server-address = "{{ .P2P.RelayServerAddress }}"
+
+# Range of the ports reserved by the relay server, as "<first>-<last>".
+# Empty lets the system choose the ports.
+relay-ports = "{{ .P2P.RelayPorts }}"
+
+# Maximum number of nodes the relay server reserves a port for at a time.
+# 0 means no limit.
+relay-max-reservations = {{ .P2P.RelayMaxReservations }}
+
+# Comma separated list of the IDs of the nodes allowed to reserve a port on
+# the relay server. Empty allows any node.
+relay-allowed-node-ids = "{{ .P2P.RelayAllowedNodeIDs }}"
+
 # Maximum number of connections (inbound and outbound).
 max-connections = {{ .P2P.MaxConnections }}
 
+# Maximum number of peers kept in the peer store. When exceeded, the
+# lowest-scored unconnected peers are deleted. 0 means no limit.
+max-peers = {{ .P2P.MaxPeers }}
+
+# Maximum number of addresses kept for a single peer. 0 means no limit.
+max-addresses-per-peer = {{ .P2P.MaxAddressesPerPeer }}
+
+# Number of consecutive failed dials after which an address is removed from
+# the peer store. 0 disables this.
+max-address-failures = {{ .P2P.MaxAddressFailures }}
+
+# Addresses whose dials fail are removed from the peer store once this long
+# has passed since they were last dialed successfully. 0 disables this.
+stale-address-time = "{{ .P2P.StaleAddressTime }}"
+
+# How often unreachable and stale addresses, and the peers left without any
+# address, are removed from the peer store. 0 disables garbage collection.
+peer-gc-interval = "{{ .P2P.PeerGCInterval }}"
+
 # Rate limits the number of incoming connection attempts per IP address.
 max-incoming-connection-attempts = {{ .P2P.MaxIncomingConnectionAttempts }}
 
+# Maximum number of outbound dials in progress. The addresses of the
+# best-scored peers are dialed first. 0 means the default of 8.
+max-concurrent-dials = {{ .P2P.MaxConcurrentDials }}
+
+# Maximum number of outbound dials in progress to the addresses of a subnet:
+# a /24 for IPv4, a /48 for IPv6, or a hostname. 0 means no limit.
+max-concurrent-dials-per-subnet = {{ .P2P.MaxConcurrentDialsPerSubnet }}
+
+# Minimum time between the starts of two outbound dials, spreading the dials
+# after a restart. 0 disables it.
+dial-interval = "{{ .P2P.DialInterval }}"
+
+# If true, the peers which committed another block than this node at some
+# height are denied by the peer filter and disconnected. The forks are
+# reported in any case.
+quarantine-fork-peers = {{ .P2P.QuarantineForkPeers }}
+
 # Set true to enable the peer-exchange reactor
 pex = {{ .P2P.PexReactor }}
 
@@ -322,12 +615,71 @@ send-rate = {{ .P2P.SendRate }}
 # TODO: Remove once MConnConnection is removed.
 recv-rate = {{ .P2P.RecvRate }}
 
+# Rates at which each peer can send messages on the given channels, in
+# bytes/second, as "<channel ID>=<rate>" pairs, e.g. ["0x30=1048576"] for the
+# mempool channel. The messages received faster are delayed, throttling the
+# peer without disconnecting it. The bandwidth used by each peer, by channel,
+# is reported by the /peer_bandwidth RPC endpoint.
+channel-recv-rates = [{{ range .P2P.ChannelRecvRates }}{{ printf "%q, " . }}{{end}}]
+
+# Channels this node doesn't serve, e.g. ["0x30"] for the mempool channel of
+# a node not relaying transactions. They are not advertised to the peers,
+# which don't send messages over them, and the messages received over them
+# are dropped. A seed node only serves the peer exchange channel.
+opt-out-channels = [{{ range .P2P.OptOutChannels }}{{ printf "%q, " . }}{{end}}]
+
+# Planned maintenance windows of this node (e.g. restarts for an upgrade),
+# announced to peers so they can avoid relying on it around those times.
+# Each window is a "<start>/<end>" pair of RFC3339 timestamps, e.g.
+# ["2022-03-01T10:00:00Z/2022-03-01T10:30:00Z"]
+maintenance-windows = [{{ range .P2P.MaintenanceWindows }}{{ printf "%q, " . }}{{end}}]
+
+# Peers with a maintenance window in progress or starting within this margin
+# are avoided as block sync and state sync sources when other peers are
+# available.
+maintenance-avoid-margin = "{{ .P2P.MaintenanceAvoidMargin }}"
+
+# Record the validators attesting to run the peers in their handshake, in the
+# validator book of the node, reported by the /validator_book RPC endpoint
+# and used by the consensus.validator-votes-first feature flag.
+validator-book = {{ .P2P.ValidatorBook }}
+
+# Attest in the handshake that the validator runs this node, by signing its
+# node ID with the private validator key, which must be local. This reveals
+# the node of the validator to its peers, so don't enable it on a validator
+# hidden behind sentry nodes.
+attest-validator = {{ .P2P.AttestValidator }}
+
+# Peer filter rules, applied when dialing and accepting peers, so that the
+# node only connects to the peers of a private network. A peer matching a
+# deny rule is rejected. Otherwise, if there are allow rules of a kind, the
+# peer must match one of them. The CIDRs match the IP address of the peer,
+# e.g. ["10.0.0.0/8", "192.0.2.7"], and the chain IDs the network the peer
+# announces in its handshake. The rules can be changed at runtime with the
+# /unsafe_peer_filter_add and /unsafe_peer_filter_remove RPC endpoints.
+allow-cidrs = [{{ range .P2P.AllowCIDRs }}{{ printf "%q, " . }}{{end}}]
+deny-cidrs = [{{ range .P2P.DenyCIDRs }}{{ printf "%q, " . }}{{end}}]
+allow-node-ids = [{{ range .P2P.AllowNodeIDs }}{{ printf "%q, " . }}{{end}}]
+deny-node-ids = [{{ range .P2P.DenyNodeIDs }}{{ printf "%q, " . }}{{end}}]
+allow-chain-ids = [{{ range .P2P.AllowChainIDs }}{{ printf "%q, " . }}{{end}}]
+deny-chain-ids = [{{ range .P2P.DenyChainIDs }}{{ printf "%q, " . }}{{end}}]
+
 
 #######################################################
 ###          Mempool Configuration Option          ###
 #######################################################
 [mempool]
 
+# The mempool orders the transactions by the priority returned by the
+# application in CheckTx: proposals are filled with the transactions of highest
+# priority first, and when it is full, transactions of lower priority are
+# evicted to make room for a new one.
+#
+# It holds a single transaction per sender reported by the application in
+# CheckTx. Another transaction of the sender is rejected, unless CheckTx flags
+# it as a replacement (e.g. a fee bump) and its priority is higher, in which
+# case it replaces the transaction of the sender.
+
 recheck = {{ .Mempool.Recheck }}
 broadcast = {{ .Mempool.Broadcast }}
 
@@ -339,9 +691,25 @@ size = {{ .Mempool.Size }}
 # max-txs-bytes=5MB, mempool will only accept 5 transactions).
 max-txs-bytes = {{ .Mempool.MaxTxsBytes }}
 
+# Limit the total size of the txs in the mempool submitted over RPC from a
+# single IP address. When exceeded, txs of the IP address with a lower priority
+# are evicted to make room, or the new tx is rejected.
+# 0 means unlimited.
+max-source-ip-bytes = {{ .Mempool.MaxSourceIPBytes }}
+
 # Size of the cache (used to filter transactions we saw earlier) in transactions
 cache-size = {{ .Mempool.CacheSize }}
 
+# Number of evicted transactions whose reason and time of eviction are kept in
+# memory, for the tx_status RPC endpoint to report them. 0 disables the journal.
+eviction-journal-size = {{ .Mempool.EvictionJournalSize }}
+
+# Number of connections to the application over which the CheckTx requests of
+# new and rechecked transactions are spread. Applications that serve these
+# connections concurrently can then check several transactions in parallel.
+# Local (in-process) applications always check one transaction at a time.
+check-tx-connections = {{ .Mempool.CheckTxConnections }}
+
 # Do not remove invalid transactions from the cache (default: false)
 # Set to true if it's not possible for any invalid transaction to become valid
 # again in the future.
@@ -370,8 +738,49 @@ ttl-duration = "{{ .Mempool.TTLDuration }}"
 # Note, if ttl-duration is also defined, a transaction will be removed if it
 # has existed in the mempool at least ttl-num-blocks number of blocks or if
 # it's insertion time into the mempool is beyond ttl-duration.
+#
+# The expired transactions are published as TxExpired events, which can be
+# subscribed to by transaction hash, e.g. "tm.event='TxExpired' AND tx.hash='XYZ'".
 ttl-num-blocks = {{ .Mempool.TTLNumBlocks }}
 
+# Node-local filters applied to transactions before they are passed to the
+# application's CheckTx. Filtered transactions are dropped and never gossiped.
+# Transactions larger than max-tx-bytes are always dropped.
+
+# Comma separated list of hex-encoded prefixes. Transactions starting with any
+# of them are dropped.
+filter-prefixes = "{{ StringsJoin .Mempool.FilterPrefixes "," }}"
+
+# filter-peer-rate, if non-zero, limits the number of transactions per second
+# accepted from a single peer. Transactions submitted over RPC are not limited.
+filter-peer-rate = {{ .Mempool.FilterPeerRate }}
+
+# Number of transactions a peer may send in a burst above filter-peer-rate.
+filter-peer-burst = {{ .Mempool.FilterPeerBurst }}
+
+# Comma separated list of paths to filter plugins, executed in order after the
+# built-in filters. Go plugins (.so) must export a "FilterTx" function with the
+# signature func(tx []byte, peerID string) error. Relative paths are resolved
+# against the home directory.
+filter-plugins = "{{ StringsJoin .Mempool.FilterPlugins "," }}"
+
+# Node-local filters applied to the transactions reaped for the blocks proposed
+# by the node, independently of the application, e.g. to exclude a class of
+# transactions in an emergency. Filtered transactions stay in the mempool, and
+# are still gossiped and included in the blocks proposed by other nodes.
+
+# proposal-max-tx-bytes, if non-zero, excludes the transactions larger than it.
+proposal-max-tx-bytes = {{ .Mempool.ProposalMaxTxBytes }}
+
+# Comma separated list of hex-encoded prefixes. Transactions starting with any
+# of them are excluded.
+proposal-filter-prefixes = "{{ StringsJoin .Mempool.ProposalFilterPrefixes "," }}"
+
+# Comma separated list of paths to filter plugins, executed in order after the
+# built-in proposal filters. They are loaded as the filter-plugins, the peerID
+# being empty.
+proposal-filter-plugins = "{{ StringsJoin .Mempool.ProposalFilterPlugins "," }}"
+
 #######################################################
 ###         State Sync Configuration Options        ###
 #######################################################
@@ -405,17 +814,71 @@ trust-period = "{{ .StateSync.TrustPeriod }}"
 discovery-time = "{{ .StateSync.DiscoveryTime }}"
 
 # Temporary directory for state sync snapshot chunks, defaults to os.TempDir().
-# The synchronizer will create a new, randomly named directory within this directory
-# and remove it when the sync is complete.
+# The synchronizer will create a directory named after the snapshot within this
+# directory, and remove it when the sync of the snapshot is complete or the snapshot
+# is rejected. A node restarted during the sync resumes the snapshot with the chunks
+# of the directory.
 temp-dir = "{{ .StateSync.TempDir }}"
 
-# The timeout duration before re-requesting a chunk, possibly from a different
-# peer (default: 15 seconds).
+# The timeout duration before re-requesting a chunk, from a different peer if
+# possible (default: 15 seconds). The timeout is doubled on each request of the
+# chunk, up to 8 times.
 chunk-request-timeout = "{{ .StateSync.ChunkRequestTimeout }}"
 
 # The number of concurrent chunk and block fetchers to run (default: 4).
 fetchers = "{{ .StateSync.Fetchers }}"
 
+# HTTPS mirrors to fetch the snapshot chunks from, in addition to peers,
+# comma-separated. A mirror publishes the manifest of a snapshot, with the
+# SHA-256 hash of each chunk, at <mirror>/<height>/<format>/manifest.json, and
+# the chunks, concatenated in order, at <mirror>/<height>/<format>/chunks, which
+# must support range requests.
+chunk-mirrors = "{{ StringsJoin .StateSync.ChunkMirrors "," }}"
+
+# Node IDs of the preferred snapshot providers, comma-separated: their
+# snapshots are tried first, and the chunks are requested from them first.
+preferred-providers = "{{ StringsJoin .StateSync.PreferredProviders "," }}"
+
+# If true, only the snapshots offered by the preferred providers, and their
+# chunks, are accepted, e.g. to bootstrap only from one's own infrastructure.
+# The chunk mirrors are still used.
+provider-allowlist = {{ .StateSync.ProviderAllowlist }}
+
+# How often the snapshot service lists the snapshots of the application,
+# records the sizes and hashes of the chunks of the new ones, and prunes the
+# records of the old ones, which the snapshots RPC endpoint reports. The
+# application still takes and deletes the snapshots. 0 disables the service.
+snapshot-interval = "{{ .StateSync.SnapshotInterval }}"
+
+# The number of most recent snapshots the snapshot service keeps records of.
+# 0 keeps the records of all the snapshots of the application.
+snapshot-keep-recent = {{ .StateSync.SnapshotKeepRecent }}
+
+# After a state sync, once the blocks of the evidence window are backfilled,
+# keep backfilling the headers and commits of the blocks down to this height in
+# the background, for the node to serve them to light clients. A backfill
+# interrupted by a restart is not resumed. 0 only backfills the evidence window.
+backfill-height = {{ .StateSync.BackfillHeight }}
+
+#######################################################
+###         Block Sync Configuration Options        ###
+#######################################################
+[blocksync]
+
+# Lags are counted in heights behind the highest height reported by peers.
+
+# Block sync hands over to consensus once the node is at most this many
+# heights behind. 0 hands over only when fully caught up.
+switch-to-consensus-lag = {{ .BlockSync.SwitchToConsensusLag }}
+
+# Consensus hands back to block sync if the node falls more than this many
+# heights behind for reentry-delay, unless the lag shrank over the last
+# reentry-delay fast enough to be closed within another reentry-delay. Must be
+# greater than switch-to-consensus-lag. 0 disables switching back to block
+# sync.
+reentry-lag = {{ .BlockSync.ReentryLag }}
+reentry-delay = "{{ .BlockSync.ReentryDelay }}"
+
 #######################################################
 ###         Consensus Configuration Options         ###
 #######################################################
@@ -457,13 +920,28 @@ create-empty-blocks-interval = "{{ .Consensus.CreateEmptyBlocksInterval }}"
 peer-gossip-sleep-duration = "{{ .Consensus.PeerGossipSleepDuration }}"
 peer-query-maj23-sleep-duration = "{{ .Consensus.PeerQueryMaj23SleepDuration }}"
 
+# Base64-encoded ed25519 public key of the validator authority: an external
+# party (e.g. a staking module outside the ABCI application) allowed to
+# schedule validator set updates via the /validator_set_update RPC endpoint.
+# The node broadcasts the signed updates as transactions, which the ABCI
+# application verifies against the same authority and applies.
+# If empty, externally computed validator updates are rejected.
+validator-authority-pub-key = "{{ .Consensus.ValidatorAuthorityPubKey }}"
+
+# Minimum number of blocks between the latest committed height and the
+# effective height of an externally scheduled validator update.
+validator-update-min-delay = {{ .Consensus.ValidatorUpdateMinDelay }}
+
 #######################################################
 ###   Transaction Indexer Configuration Options     ###
 #######################################################
 [tx-index]
 
 # The backend database list to back the indexer.
-# If list contains "null" or "", meaning no indexer service will be used.
+# If the list is empty or only contains "null", no indexer service will be used.
+# Several indexers, e.g. ["kv", "psql"], index every block independently: an
+# indexer failing to index a block does not keep the others from indexing it.
+# "null" is ignored alongside other indexers.
 #
 # The application will set which txs to index. In some cases a node operator will be able
 # to decide which txs to index based on configuration set in the application.
@@ -479,6 +957,11 @@ indexer = [{{ range $i, $e := .TxIndex.Indexer }}{{if $i}}, {{end}}{{ printf "%q
 #   postgresql://<user>:<password>@<host>:<port>/<db>?<opts>
 psql-conn = "{{ .TxIndex.PsqlConn }}"
 
+# If true, the CheckTx responses of the transactions entering the mempool,
+# published as CheckTx events, are stored by transaction hash by the "kv"
+# indexer, to look up how the application classified a transaction.
+index-check-tx = {{ .TxIndex.IndexCheckTx }}
+
 #######################################################
 ###       Instrumentation Configuration Options     ###
 #######################################################
@@ -500,6 +983,49 @@ max-open-connections = {{ .Instrumentation.MaxOpenConnections }}
 
 # Instrumentation namespace
 namespace = "{{ .Instrumentation.Namespace }}"
+
+# URLs of the collectors to which a status report of the node (height, peers,
+# version, sync state and resource usage), signed with the node key, is pushed
+# periodically. Telemetry is disabled if empty.
+telemetry-collectors = [{{ range .Instrumentation.TelemetryCollectors }}{{ printf "%q, " . }}{{end}}]
+
+# Interval between two telemetry reports.
+telemetry-interval = "{{ .Instrumentation.TelemetryInterval }}"
+
+#######################################################
+###        Resource Limits Configuration Options    ###
+#######################################################
+[resources]
+
+# The watchdog checks the resource usage of the node against the limits below.
+# As a limit is approached, an alert is logged, then the node sheds load: it
+# rejects the RPC requests and the inbound peers until the usage goes down,
+# rather than being killed by the OS, or failing to write its data, once the
+# limit is hit.
+
+# Interval between two checks of the resource usage. 0 disables the watchdog.
+check-interval = "{{ .Resources.CheckInterval }}"
+
+# Maximum number of open file descriptors, including the connections.
+# 0 is the limit of the process set by the OS (ulimit -n).
+max-open-files = {{ .Resources.MaxOpenFiles }}
+
+# Maximum number of goroutines. 0 is unlimited.
+max-goroutines = {{ .Resources.MaxGoroutines }}
+
+# Maximum resident memory, in bytes. 0 is unlimited.
+max-memory-bytes = {{ .Resources.MaxMemoryBytes }}
+
+# Minimum free space of the disk of the data directory, in bytes.
+# 0 is unlimited.
+min-free-disk-bytes = {{ .Resources.MinFreeDiskBytes }}
+
+# Fraction of a limit from which an alert is logged.
+alert-threshold = {{ .Resources.AlertThreshold }}
+
+# Fraction of a limit from which the node sheds load, until the usage of every
+# resource is back under the alert threshold.
+shed-threshold = {{ .Resources.ShedThreshold }}
 `
 
 /****** these are for test settings ***********/
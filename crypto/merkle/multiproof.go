@@ -0,0 +1,289 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/tendermint/tendermint/crypto/tmhash"
+)
+
+// MultiProof represents a Merkle proof of the inclusion of several items of
+// the same tree. Hashes shared by the paths of the items are included once,
+// which makes it smaller than the individual proofs of the items combined.
+//
+// Aunts holds the hashes of the subtrees that contain none of the proven
+// items, in the order a left-to-right traversal of the tree reaches them.
+type MultiProof struct {
+	Total      int64    `json:"total,string"` // Total number of items.
+	Indices    []int64  `json:"indices"`      // Indices of items to prove, in increasing order.
+	LeafHashes [][]byte `json:"leaf_hashes"`  // Hashes of item values.
+	Aunts      [][]byte `json:"aunts"`        // Hashes of subtrees without items to prove.
+}
+
+// MultiProofFromByteSlices computes a proof of the inclusion of the items at
+// the given indices.
+func MultiProofFromByteSlices(items [][]byte, indices []int64) (rootHash []byte, proof *MultiProof, err error) {
+	if len(indices) == 0 {
+		return nil, nil, errors.New("no indices to prove")
+	}
+
+	sorted := make([]int64, len(indices))
+	copy(sorted, indices)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i, index := range sorted {
+		if index < 0 || index >= int64(len(items)) {
+			return nil, nil, fmt.Errorf("index %d out of range [0, %d)", index, len(items))
+		}
+		if i > 0 && index == sorted[i-1] {
+			return nil, nil, fmt.Errorf("duplicate index %d", index)
+		}
+	}
+
+	proof = &MultiProof{
+		Total:   int64(len(items)),
+		Indices: sorted,
+	}
+	rootHash = proof.build(items, 0, sorted)
+	return rootHash, proof, nil
+}
+
+// build computes the hash of the subtree of items starting at offset,
+// recording the hashes needed to prove the items at indices.
+func (mp *MultiProof) build(items [][]byte, offset int64, indices []int64) []byte {
+	if len(indices) == 0 {
+		hash := HashFromByteSlices(items)
+		mp.Aunts = append(mp.Aunts, hash)
+		return hash
+	}
+	if len(items) == 1 {
+		hash := leafHash(items[0])
+		mp.LeafHashes = append(mp.LeafHashes, hash)
+		return hash
+	}
+	k := getSplitPoint(int64(len(items)))
+	split := splitIndices(indices, offset+k)
+	left := mp.build(items[:k], offset, indices[:split])
+	right := mp.build(items[k:], offset+k, indices[split:])
+	return innerHash(left, right)
+}
+
+// Verify that the MultiProof proves the root hash, given the values of the
+// items at mp.Indices.
+func (mp *MultiProof) Verify(rootHash []byte, leaves [][]byte) error {
+	if err := mp.ValidateBasic(); err != nil {
+		return err
+	}
+	if len(leaves) != len(mp.Indices) {
+		return fmt.Errorf("expected %d leaves, got %d", len(mp.Indices), len(leaves))
+	}
+	for i, leaf := range leaves {
+		leafHash := leafHash(leaf)
+		if !bytes.Equal(mp.LeafHashes[i], leafHash) {
+			return fmt.Errorf("invalid leaf hash #%d: wanted %X got %X", i, leafHash, mp.LeafHashes[i])
+		}
+	}
+	computedHash := mp.ComputeRootHash()
+	if !bytes.Equal(computedHash, rootHash) {
+		return fmt.Errorf("invalid root hash: wanted %X got %X", rootHash, computedHash)
+	}
+	return nil
+}
+
+// Compute the root hash given the leaf hashes. Does not verify the result.
+// If the proof is malformed, the result is nil.
+func (mp *MultiProof) ComputeRootHash() []byte {
+	if mp.Total <= 0 || len(mp.Indices) == 0 {
+		return nil
+	}
+	var leaves, aunts int
+	hash := mp.computeHash(0, mp.Total, mp.Indices, &leaves, &aunts)
+	if leaves != len(mp.LeafHashes) || aunts != len(mp.Aunts) {
+		return nil
+	}
+	return hash
+}
+
+// computeHash computes the hash of the subtree of total items starting at
+// offset, consuming the leaf hashes and aunts from the given positions.
+func (mp *MultiProof) computeHash(offset, total int64, indices []int64, leaves, aunts *int) []byte {
+	if len(indices) == 0 {
+		if *aunts >= len(mp.Aunts) {
+			return nil
+		}
+		*aunts++
+		return mp.Aunts[*aunts-1]
+	}
+	if total == 1 {
+		if indices[0] != offset || len(indices) != 1 || *leaves >= len(mp.LeafHashes) {
+			return nil
+		}
+		*leaves++
+		return mp.LeafHashes[*leaves-1]
+	}
+	k := getSplitPoint(total)
+	split := splitIndices(indices, offset+k)
+	left := mp.computeHash(offset, k, indices[:split], leaves, aunts)
+	if left == nil {
+		return nil
+	}
+	right := mp.computeHash(offset+k, total-k, indices[split:], leaves, aunts)
+	if right == nil {
+		return nil
+	}
+	return innerHash(left, right)
+}
+
+// splitIndices returns the position of the first of the sorted indices that
+// is not less than k.
+func splitIndices(indices []int64, k int64) int {
+	return sort.Search(len(indices), func(i int) bool { return indices[i] >= k })
+}
+
+// ValidateBasic performs basic validation.
+// NOTE: it expects the leaf hashes and aunts to be of size tmhash.Size, and it
+// expects at most MaxAunts aunts per proven item.
+func (mp *MultiProof) ValidateBasic() error {
+	if mp.Total <= 0 {
+		return errors.New("non-positive Total")
+	}
+	if len(mp.Indices) == 0 {
+		return errors.New("no Indices")
+	}
+	for i, index := range mp.Indices {
+		if index < 0 || index >= mp.Total {
+			return fmt.Errorf("index %d out of range [0, %d)", index, mp.Total)
+		}
+		if i > 0 && index <= mp.Indices[i-1] {
+			return errors.New("indices must be strictly increasing")
+		}
+	}
+	if len(mp.LeafHashes) != len(mp.Indices) {
+		return fmt.Errorf("expected %d leaf hashes, got %d", len(mp.Indices), len(mp.LeafHashes))
+	}
+	for i, hash := range mp.LeafHashes {
+		if len(hash) != tmhash.Size {
+			return fmt.Errorf("expected LeafHashes#%d size to be %d, got %d", i, tmhash.Size, len(hash))
+		}
+	}
+	if len(mp.Aunts) > MaxAunts*len(mp.Indices) {
+		return fmt.Errorf("expected no more than %d aunts, got %d", MaxAunts*len(mp.Indices), len(mp.Aunts))
+	}
+	for i, hash := range mp.Aunts {
+		if len(hash) != tmhash.Size {
+			return fmt.Errorf("expected Aunts#%d size to be %d, got %d", i, tmhash.Size, len(hash))
+		}
+	}
+	return nil
+}
+
+// subtree identifies the node of a tree covering count items from start.
+type subtree struct {
+	start, count int64
+}
+
+// BatchVerifier verifies proofs of several items of the same tree against its
+// root hash. It remembers the inner hashes established by the proofs it has
+// verified, so that a proof sharing part of its path with them stops hashing
+// as soon as it reaches a known node. It is not safe for concurrent use.
+type BatchVerifier struct {
+	rootHash []byte
+	total    int64
+	known    map[subtree][]byte
+}
+
+// NewBatchVerifier returns a verifier of proofs against rootHash.
+func NewBatchVerifier(rootHash []byte) *BatchVerifier {
+	return &BatchVerifier{
+		rootHash: rootHash,
+		known:    make(map[subtree][]byte),
+	}
+}
+
+// Verify verifies that sp proves the inclusion of leaf in the tree. All the
+// proofs verified by a BatchVerifier must be of trees of the same size.
+func (bv *BatchVerifier) Verify(sp *Proof, leaf []byte) error {
+	if err := sp.ValidateBasic(); err != nil {
+		return err
+	}
+	if sp.Index >= sp.Total {
+		return fmt.Errorf("proof index %d out of range [0, %d)", sp.Index, sp.Total)
+	}
+	if bv.total != 0 && sp.Total != bv.total {
+		return fmt.Errorf("proof of a tree of %d items, expected %d", sp.Total, bv.total)
+	}
+	leafHash := leafHash(leaf)
+	if !bytes.Equal(sp.LeafHash, leafHash) {
+		return fmt.Errorf("invalid leaf hash: wanted %X got %X", leafHash, sp.LeafHash)
+	}
+
+	// Walk down from the root to find the subtrees on the path of the item.
+	path := make([]subtree, 0, len(sp.Aunts)+1)
+	node := subtree{0, sp.Total}
+	for node.count > 1 {
+		path = append(path, node)
+		k := getSplitPoint(node.count)
+		if sp.Index < node.start+k {
+			node = subtree{node.start, k}
+		} else {
+			node = subtree{node.start + k, node.count - k}
+		}
+	}
+	if len(sp.Aunts) != len(path) {
+		return fmt.Errorf("expected %d aunts, got %d", len(path), len(sp.Aunts))
+	}
+
+	// Hash back up until reaching a known node. Past it, the hashes of the
+	// path are known, so the aunts are only compared with them.
+	established := make(map[subtree][]byte, 2*len(path)+1)
+	hash := sp.LeafHash
+	matched := false
+	for i := len(path) - 1; ; i-- {
+		if !matched {
+			if known, ok := bv.known[node]; ok {
+				if !bytes.Equal(known, hash) {
+					return fmt.Errorf("invalid hash of items [%d, %d): wanted %X got %X",
+						node.start, node.start+node.count, known, hash)
+				}
+				matched = true
+			} else {
+				established[node] = hash
+			}
+		}
+		if i < 0 {
+			if !matched && !bytes.Equal(hash, bv.rootHash) {
+				return fmt.Errorf("invalid root hash: wanted %X got %X", bv.rootHash, hash)
+			}
+			break
+		}
+
+		parent := path[i]
+		aunt := sp.Aunts[len(path)-1-i]
+		sibling := subtree{parent.start, parent.count - node.count}
+		if node.start == parent.start {
+			sibling.start = node.start + node.count
+		}
+		if known, ok := bv.known[sibling]; ok {
+			if !bytes.Equal(known, aunt) {
+				return fmt.Errorf("invalid aunt #%d: wanted %X got %X", len(path)-1-i, known, aunt)
+			}
+		} else {
+			established[sibling] = aunt
+		}
+		if !matched {
+			if node.start == parent.start {
+				hash = innerHash(hash, aunt)
+			} else {
+				hash = innerHash(aunt, hash)
+			}
+		}
+		node = parent
+	}
+
+	bv.total = sp.Total
+	for node, hash := range established {
+		bv.known[node] = hash
+	}
+	return nil
+}
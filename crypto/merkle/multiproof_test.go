@@ -0,0 +1,109 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/tmhash"
+)
+
+func makeItems(total int) [][]byte {
+	items := make([][]byte, total)
+	for i := range items {
+		items[i] = []byte(fmt.Sprintf("item %d", i))
+	}
+	return items
+}
+
+func TestMultiProof(t *testing.T) {
+	for total := 1; total <= 20; total++ {
+		items := makeItems(total)
+		rootHash := HashFromByteSlices(items)
+		_, proofs := ProofsFromByteSlices(items)
+
+		// prove every pair of items, in both orders
+		for i := 0; i < total; i++ {
+			for j := i; j < total; j++ {
+				indices := []int64{int64(j), int64(i)}
+				leaves := [][]byte{items[i], items[j]}
+				if i == j {
+					indices, leaves = indices[:1], leaves[:1]
+				}
+
+				root, proof, err := MultiProofFromByteSlices(items, indices)
+				require.NoError(t, err)
+				require.Equal(t, rootHash, root)
+				require.NoError(t, proof.Verify(rootHash, leaves), "total %d, items %d and %d", total, i, j)
+				require.LessOrEqual(t, len(proof.Aunts), len(proofs[i].Aunts)+len(proofs[j].Aunts))
+
+				require.Error(t, proof.Verify(rootHash, [][]byte{[]byte("other")}))
+				require.Error(t, proof.Verify(tmhash.Sum([]byte("other")), leaves))
+			}
+		}
+	}
+}
+
+func TestMultiProofMalformed(t *testing.T) {
+	items := makeItems(10)
+	rootHash := HashFromByteSlices(items)
+
+	_, _, err := MultiProofFromByteSlices(items, nil)
+	assert.Error(t, err)
+	_, _, err = MultiProofFromByteSlices(items, []int64{10})
+	assert.Error(t, err)
+	_, _, err = MultiProofFromByteSlices(items, []int64{3, 3})
+	assert.Error(t, err)
+
+	leaves := [][]byte{items[2], items[5], items[9]}
+	testCases := map[string]func(*MultiProof){
+		"missing aunt":      func(mp *MultiProof) { mp.Aunts = mp.Aunts[1:] },
+		"extra aunt":        func(mp *MultiProof) { mp.Aunts = append(mp.Aunts, mp.Aunts[0]) },
+		"swapped aunts":     func(mp *MultiProof) { mp.Aunts[0], mp.Aunts[1] = mp.Aunts[1], mp.Aunts[0] },
+		"shifted index":     func(mp *MultiProof) { mp.Indices[0] = 3 },
+		"unsorted indices":  func(mp *MultiProof) { mp.Indices[0], mp.Indices[1] = mp.Indices[1], mp.Indices[0] },
+		"index out of tree": func(mp *MultiProof) { mp.Indices[2] = 10 },
+		"wrong total":       func(mp *MultiProof) { mp.Total = 11 },
+	}
+	for name, malform := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, proof, err := MultiProofFromByteSlices(items, []int64{2, 5, 9})
+			require.NoError(t, err)
+			require.NoError(t, proof.Verify(rootHash, leaves))
+
+			malform(proof)
+			assert.Error(t, proof.Verify(rootHash, leaves))
+		})
+	}
+}
+
+func TestBatchVerifier(t *testing.T) {
+	items := makeItems(13)
+	rootHash, proofs := ProofsFromByteSlices(items)
+
+	bv := NewBatchVerifier(rootHash)
+	for i, proof := range proofs {
+		require.NoError(t, bv.Verify(proof, items[i]), "item %d", i)
+	}
+	// once the whole tree is known, proofs are checked against it
+	for i, proof := range proofs {
+		require.NoError(t, bv.Verify(proof, items[i]), "item %d", i)
+		require.Error(t, bv.Verify(proof, []byte("other")), "item %d", i)
+	}
+
+	// a proof with a wrong aunt is rejected, even when it reaches a known node
+	bad := *proofs[4]
+	bad.Aunts = append([][]byte(nil), bad.Aunts...)
+	bad.Aunts[0] = tmhash.Sum([]byte("other"))
+	assert.Error(t, bv.Verify(&bad, items[4]))
+	assert.Error(t, NewBatchVerifier(rootHash).Verify(&bad, items[4]))
+
+	// proofs of trees of another size are rejected
+	_, others := ProofsFromByteSlices(items[:12])
+	assert.Error(t, bv.Verify(others[0], items[0]))
+
+	// the first proof must reach the root
+	assert.Error(t, NewBatchVerifier(rootHash).Verify(others[0], items[0]))
+}
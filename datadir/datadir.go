@@ -0,0 +1,262 @@
+// Package datadir provides read-only access to the blocks and states stored
+// in the data directory of a node, for analytics and migration tools.
+//
+// The data directory must not be in use by a running node. With the default
+// goleveldb backend, the databases are opened read-only and Open fails while
+// a node holds them, so it is safe to run any number of tools concurrently on
+// a stopped node. Other backends are opened as usual: the DataDir API never
+// writes, but the caller must make sure that no node is running.
+package datadir
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/atrest"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/dbcompress"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+)
+
+// ErrNotFound is returned, wrapped, when the requested data is not in the
+// data directory, e.g. because it was pruned or never synced.
+var ErrNotFound = errors.New("not found")
+
+// State is the latest state of the node, as of the last committed block.
+type State struct {
+	Version  version.Consensus
+	Software string
+
+	ChainID       string
+	InitialHeight int64
+
+	LastBlockHeight int64
+	LastBlockID     types.BlockID
+	LastBlockTime   time.Time
+
+	NextValidators              *types.ValidatorSet
+	Validators                  *types.ValidatorSet
+	LastValidators              *types.ValidatorSet
+	LastHeightValidatorsChanged int64
+
+	ConsensusParams                  types.ConsensusParams
+	LastHeightConsensusParamsChanged int64
+
+	LastResultsHash []byte
+	AppHash         []byte
+}
+
+// DataDir is the data directory of a node, opened for reading.
+type DataDir struct {
+	blockDB    dbm.DB
+	stateDB    dbm.DB
+	blockStore *store.BlockStore
+	stateStore sm.Store
+}
+
+// Open opens the block and state databases in the data directory of cfg, with
+// the backend, the encryption key provider and the compression of cfg. It
+// fails if either database does not exist.
+func Open(cfg *config.Config) (*DataDir, error) {
+	cipher, err := atrest.CipherFromConfig(cfg.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := dbcompress.CodecFromConfig(cfg.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+	blockDB, err := openDB("blockstore", cfg)
+	if err != nil {
+		return nil, err
+	}
+	stateDB, err := openDB("state", cfg)
+	if err != nil {
+		blockDB.Close()
+		return nil, err
+	}
+	return &DataDir{
+		blockDB:    blockDB,
+		stateDB:    stateDB,
+		blockStore: store.NewBlockStore(dbcompress.WrapDB(atrest.WrapDB(blockDB, cipher), codec)),
+		stateStore: sm.NewStore(dbcompress.WrapDB(stateDB, codec)),
+	}, nil
+}
+
+func openDB(name string, cfg *config.Config) (dbm.DB, error) {
+	dir := cfg.DBDir()
+	if !tmos.FileExists(filepath.Join(dir, name+".db")) {
+		return nil, fmt.Errorf("no %s database in %s", name, dir)
+	}
+
+	backend := dbm.BackendType(cfg.DBBackend)
+	var (
+		db  dbm.DB
+		err error
+	)
+	if backend == dbm.GoLevelDBBackend {
+		db, err = dbm.NewGoLevelDBWithOpts(name, dir, &opt.Options{
+			ReadOnly:       true,
+			ErrorIfMissing: true,
+		})
+	} else {
+		db, err = dbm.NewDB(name, backend, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening %s database (is the node running?): %w", name, err)
+	}
+	return db, nil
+}
+
+// Close closes the databases.
+func (d *DataDir) Close() error {
+	blockErr := d.blockDB.Close()
+	if err := d.stateDB.Close(); err != nil {
+		return err
+	}
+	return blockErr
+}
+
+// Base returns the height of the first stored block, or 0 if there is none.
+func (d *DataDir) Base() int64 {
+	return d.blockStore.Base()
+}
+
+// Height returns the height of the last stored block, or 0 if there is none.
+func (d *DataDir) Height() int64 {
+	return d.blockStore.Height()
+}
+
+// State returns the latest state of the node.
+func (d *DataDir) State() (*State, error) {
+	state, err := d.stateStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	if state.IsEmpty() {
+		return nil, fmt.Errorf("state: %w", ErrNotFound)
+	}
+	return &State{
+		Version:                          state.Version.Consensus,
+		Software:                         state.Version.Software,
+		ChainID:                          state.ChainID,
+		InitialHeight:                    state.InitialHeight,
+		LastBlockHeight:                  state.LastBlockHeight,
+		LastBlockID:                      state.LastBlockID,
+		LastBlockTime:                    state.LastBlockTime,
+		NextValidators:                   state.NextValidators,
+		Validators:                       state.Validators,
+		LastValidators:                   state.LastValidators,
+		LastHeightValidatorsChanged:      state.LastHeightValidatorsChanged,
+		ConsensusParams:                  state.ConsensusParams,
+		LastHeightConsensusParamsChanged: state.LastHeightConsensusParamsChanged,
+		LastResultsHash:                  state.LastResultsHash,
+		AppHash:                          state.AppHash,
+	}, nil
+}
+
+// Block returns the block at height.
+func (d *DataDir) Block(height int64) (*types.Block, error) {
+	block := d.blockStore.LoadBlock(height)
+	if block == nil {
+		return nil, fmt.Errorf("block at height %d: %w", height, ErrNotFound)
+	}
+	return block, nil
+}
+
+// BlockMeta returns the metadata of the block at height.
+func (d *DataDir) BlockMeta(height int64) (*types.BlockMeta, error) {
+	meta := d.blockStore.LoadBlockMeta(height)
+	if meta == nil {
+		return nil, fmt.Errorf("block meta at height %d: %w", height, ErrNotFound)
+	}
+	return meta, nil
+}
+
+// Commit returns the canonical commit of the block at height, which is stored
+// with the block at height+1.
+func (d *DataDir) Commit(height int64) (*types.Commit, error) {
+	commit := d.blockStore.LoadBlockCommit(height)
+	if commit == nil {
+		return nil, fmt.Errorf("commit at height %d: %w", height, ErrNotFound)
+	}
+	return commit, nil
+}
+
+// Validators returns the validator set of the block at height.
+func (d *DataDir) Validators(height int64) (*types.ValidatorSet, error) {
+	vals, err := d.stateStore.LoadValidators(height)
+	if errors.As(err, &sm.ErrNoValSetForHeight{}) {
+		return nil, fmt.Errorf("validators at height %d: %w", height, ErrNotFound)
+	}
+	return vals, err
+}
+
+// ConsensusParams returns the consensus params of the block at height.
+func (d *DataDir) ConsensusParams(height int64) (types.ConsensusParams, error) {
+	return d.stateStore.LoadConsensusParams(height)
+}
+
+// ABCIResponses returns the responses of the application to the block at
+// height, unless they were pruned.
+func (d *DataDir) ABCIResponses(height int64) (*tmstate.ABCIResponses, error) {
+	rsps, err := d.stateStore.LoadABCIResponses(height)
+	if errors.As(err, &sm.ErrNoABCIResponsesForHeight{}) {
+		return nil, fmt.Errorf("ABCI responses at height %d: %w", height, ErrNotFound)
+	}
+	return rsps, err
+}
+
+// IterateBlocks calls fn with the blocks from height from to height to,
+// inclusive, in increasing order of height, and stops at the first error
+// returned by fn. The range is clamped to the stored blocks; a zero to means
+// the last stored block.
+func (d *DataDir) IterateBlocks(from, to int64, fn func(*types.Block) error) error {
+	return d.iterate(from, to, func(height int64) error {
+		block, err := d.Block(height)
+		if err != nil {
+			return err
+		}
+		return fn(block)
+	})
+}
+
+// IterateABCIResponses calls fn with the ABCI responses to the blocks from
+// height from to height to, as IterateBlocks.
+func (d *DataDir) IterateABCIResponses(
+	from, to int64,
+	fn func(height int64, rsps *tmstate.ABCIResponses) error,
+) error {
+	return d.iterate(from, to, func(height int64) error {
+		rsps, err := d.ABCIResponses(height)
+		if err != nil {
+			return err
+		}
+		return fn(height, rsps)
+	})
+}
+
+func (d *DataDir) iterate(from, to int64, fn func(height int64) error) error {
+	if base := d.Base(); from < base {
+		from = base
+	}
+	if height := d.Height(); to == 0 || to > height {
+		to = height
+	}
+	for height := from; height <= to && height > 0; height++ {
+		if err := fn(height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
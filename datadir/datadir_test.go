@@ -0,0 +1,137 @@
+package datadir
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/test/factory"
+	"github.com/tendermint/tendermint/internal/store"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestDataDir(t *testing.T) {
+	cfg, err := config.ResetTestRoot(t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(cfg.RootDir)
+	cfg.DBBackend = string(dbm.GoLevelDBBackend)
+
+	_, err = Open(cfg)
+	require.Error(t, err, "no databases yet")
+
+	// Write a few blocks, as a node would.
+	blockDB, err := dbm.NewDB("blockstore", dbm.GoLevelDBBackend, cfg.DBDir())
+	require.NoError(t, err)
+	stateDB, err := dbm.NewDB("state", dbm.GoLevelDBBackend, cfg.DBDir())
+	require.NoError(t, err)
+	blockStore := store.NewBlockStore(blockDB)
+	stateStore := sm.NewStore(stateDB)
+
+	state, err := sm.MakeGenesisStateFromFile(cfg.GenesisFile())
+	require.NoError(t, err)
+	require.NoError(t, stateStore.Save(state))
+
+	lastCommit := new(types.Commit)
+	for height := int64(1); height <= 3; height++ {
+		block, err := factory.MakeBlock(state, height, lastCommit)
+		require.NoError(t, err)
+		parts, err := block.MakePartSet(types.BlockPartSizeBytes)
+		require.NoError(t, err)
+		blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: parts.Header()}
+		lastCommit = types.NewCommit(height, 0, blockID, []types.CommitSig{{
+			BlockIDFlag:      types.BlockIDFlagCommit,
+			ValidatorAddress: state.Validators.Validators[0].Address,
+			Timestamp:        block.Time,
+			Signature:        []byte("signature"),
+		}})
+		blockStore.SaveBlock(block, parts, lastCommit)
+		require.NoError(t, stateStore.SaveABCIResponses(height, &tmstate.ABCIResponses{
+			DeliverTxs: []*abci.ResponseDeliverTx{{Code: uint32(height)}},
+		}))
+
+		state.LastBlockHeight = height
+		state.LastBlockID = blockID
+		state.LastValidators = state.Validators.Copy()
+		require.NoError(t, stateStore.Save(state))
+	}
+
+	// The databases can't be opened while in use by the node.
+	_, err = Open(cfg)
+	require.Error(t, err)
+
+	require.NoError(t, blockStore.Close())
+	require.NoError(t, stateStore.Close())
+
+	dd, err := Open(cfg)
+	require.NoError(t, err)
+	defer dd.Close()
+
+	// Any number of readers may open the data directory.
+	dd2, err := Open(cfg)
+	require.NoError(t, err)
+	require.NoError(t, dd2.Close())
+
+	assert.EqualValues(t, 1, dd.Base())
+	assert.EqualValues(t, 3, dd.Height())
+
+	st, err := dd.State()
+	require.NoError(t, err)
+	assert.Equal(t, state.ChainID, st.ChainID)
+	assert.EqualValues(t, 3, st.LastBlockHeight)
+	assert.Equal(t, state.LastBlockID, st.LastBlockID)
+
+	block, err := dd.Block(2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, block.Height)
+	meta, err := dd.BlockMeta(2)
+	require.NoError(t, err)
+	assert.Equal(t, block.Hash(), meta.BlockID.Hash)
+	commit, err := dd.Commit(2)
+	require.NoError(t, err)
+	assert.Equal(t, meta.BlockID, commit.BlockID)
+
+	vals, err := dd.Validators(1)
+	require.NoError(t, err)
+	assert.Equal(t, state.Validators.Hash(), vals.Hash())
+	params, err := dd.ConsensusParams(1)
+	require.NoError(t, err)
+	assert.Equal(t, state.ConsensusParams, params)
+
+	_, err = dd.Block(4)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = dd.ABCIResponses(4)
+	assert.True(t, errors.Is(err, ErrNotFound))
+	_, err = dd.Validators(100)
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	var heights []int64
+	require.NoError(t, dd.IterateBlocks(0, 0, func(block *types.Block) error {
+		heights = append(heights, block.Height)
+		return nil
+	}))
+	assert.Equal(t, []int64{1, 2, 3}, heights)
+
+	var codes []uint32
+	require.NoError(t, dd.IterateABCIResponses(2, 10, func(height int64, rsps *tmstate.ABCIResponses) error {
+		codes = append(codes, rsps.DeliverTxs[0].Code)
+		return nil
+	}))
+	assert.Equal(t, []uint32{2, 3}, codes)
+
+	errStop := errors.New("stop")
+	heights = nil
+	err = dd.IterateBlocks(1, 3, func(block *types.Block) error {
+		heights = append(heights, block.Height)
+		return errStop
+	})
+	assert.Equal(t, errStop, err)
+	assert.Equal(t, []int64{1}, heights)
+}
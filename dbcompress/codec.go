@@ -0,0 +1,123 @@
+// Package dbcompress compresses the values that a node stores in its
+// blockstore and state databases: mostly the block parts and the block
+// results, which are highly compressible protobuf data.
+//
+// Each compressed value records the codec it was compressed with, so that the
+// codec can be changed: new values are compressed with the configured codec,
+// and the values written with another codec, or uncompressed, stay readable.
+// Recompress rewrites a database with the configured codec.
+package dbcompress
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang/snappy"
+
+	"github.com/tendermint/tendermint/config"
+)
+
+const (
+	// compressedMagic starts every compressed value. No protobuf message
+	// starts with it, as its wire type (7) is invalid, so compressed values
+	// can be told apart from the values written uncompressed. It differs
+	// from the magic of the values sealed by atrest.
+	compressedMagic = 0xff
+
+	headerSize = 2 // magic, codec ID
+
+	// minCompressSize is the size under which values are stored
+	// uncompressed, as compressing them would hardly save anything.
+	minCompressSize = 128
+)
+
+// Codec compresses the values of a database.
+type Codec string
+
+// The codecs, named as in the db-compression config option.
+const (
+	None   Codec = config.DBCompressionNone
+	Snappy Codec = config.DBCompressionSnappy
+	Zstd   Codec = config.DBCompressionZstd
+)
+
+// The IDs of the codecs, as recorded in the compressed values. They must
+// never change.
+const (
+	snappyID = 0x01
+	zstdID   = 0x02
+)
+
+// ErrZstdDisabled is returned for the values compressed with zstd, by a binary
+// built without the zstd build tag.
+var ErrZstdDisabled = errors.New("zstd support is not compiled in: rebuild with the zstd build tag")
+
+// CodecFromConfig returns the codec selected by the db-compression config
+// option. It fails if the codec is not compiled in.
+func CodecFromConfig(cfg config.BaseConfig) (Codec, error) {
+	switch codec := Codec(cfg.DBCompression); codec {
+	case None, "":
+		return None, nil
+	case Snappy:
+		return Snappy, nil
+	case Zstd:
+		if !zstdEnabled {
+			return None, ErrZstdDisabled
+		}
+		return Zstd, nil
+	default:
+		return None, fmt.Errorf("unknown db compression %q", codec)
+	}
+}
+
+// IsCompressed reports whether value was compressed by a database returned by
+// WrapDB.
+func IsCompressed(value []byte) bool {
+	return len(value) >= headerSize && value[0] == compressedMagic
+}
+
+// compress returns value compressed with c, or value itself if c is None, if
+// it is too small to be worth compressing, or if it does not compress.
+func (c Codec) compress(value []byte) ([]byte, error) {
+	if c == None || len(value) < minCompressSize {
+		return value, nil
+	}
+
+	var (
+		id   byte
+		body []byte
+		err  error
+	)
+	switch c {
+	case Snappy:
+		id, body = snappyID, snappy.Encode(nil, value)
+	case Zstd:
+		id = zstdID
+		body, err = zstdCompress(value)
+	default:
+		return nil, fmt.Errorf("unknown db compression %q", c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if headerSize+len(body) >= len(value) {
+		return value, nil
+	}
+	return append([]byte{compressedMagic, id}, body...), nil
+}
+
+// decompress returns value decompressed, or value itself if it was stored
+// uncompressed.
+func decompress(value []byte) ([]byte, error) {
+	if !IsCompressed(value) {
+		return value, nil
+	}
+	switch value[1] {
+	case snappyID:
+		return snappy.Decode(nil, value[headerSize:])
+	case zstdID:
+		return zstdDecompress(value[headerSize:])
+	default:
+		return nil, fmt.Errorf("unknown compression codec ID %d", value[1])
+	}
+}
@@ -0,0 +1,256 @@
+package dbcompress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/config"
+)
+
+// recompressBatchSize is the number of values read per batch by Recompress.
+const recompressBatchSize = 1000
+
+// compressedDB compresses the values of a database with a codec, and
+// decompresses the values it reads whatever their codec.
+type compressedDB struct {
+	db    dbm.DB
+	codec Codec
+}
+
+var _ dbm.DB = (*compressedDB)(nil)
+
+// WrapDB returns a database storing the values of db compressed with codec.
+// Values that were stored with another codec, or uncompressed, are returned
+// decompressed, so the databases that may have been compressed must be
+// wrapped even if codec is None.
+//
+// If the database is encrypted, it must be wrapped by WrapDB after being
+// wrapped by atrest.WrapDB, since encrypted values do not compress.
+func WrapDB(db dbm.DB, codec Codec) dbm.DB {
+	return &compressedDB{db: db, codec: codec}
+}
+
+// Get implements dbm.DB.
+func (cdb *compressedDB) Get(key []byte) ([]byte, error) {
+	value, err := cdb.db.Get(key)
+	if err != nil || value == nil {
+		return value, err
+	}
+	return decompress(value)
+}
+
+// Has implements dbm.DB.
+func (cdb *compressedDB) Has(key []byte) (bool, error) {
+	return cdb.db.Has(key)
+}
+
+// Set implements dbm.DB.
+func (cdb *compressedDB) Set(key, value []byte) error {
+	compressed, err := cdb.codec.compress(value)
+	if err != nil {
+		return err
+	}
+	return cdb.db.Set(key, compressed)
+}
+
+// SetSync implements dbm.DB.
+func (cdb *compressedDB) SetSync(key, value []byte) error {
+	compressed, err := cdb.codec.compress(value)
+	if err != nil {
+		return err
+	}
+	return cdb.db.SetSync(key, compressed)
+}
+
+// Delete implements dbm.DB.
+func (cdb *compressedDB) Delete(key []byte) error {
+	return cdb.db.Delete(key)
+}
+
+// DeleteSync implements dbm.DB.
+func (cdb *compressedDB) DeleteSync(key []byte) error {
+	return cdb.db.DeleteSync(key)
+}
+
+// Iterator implements dbm.DB.
+func (cdb *compressedDB) Iterator(start, end []byte) (dbm.Iterator, error) {
+	it, err := cdb.db.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedIterator{Iterator: it}, nil
+}
+
+// ReverseIterator implements dbm.DB.
+func (cdb *compressedDB) ReverseIterator(start, end []byte) (dbm.Iterator, error) {
+	it, err := cdb.db.ReverseIterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedIterator{Iterator: it}, nil
+}
+
+// Close implements dbm.DB.
+func (cdb *compressedDB) Close() error {
+	return cdb.db.Close()
+}
+
+// NewBatch implements dbm.DB.
+func (cdb *compressedDB) NewBatch() dbm.Batch {
+	return &compressedBatch{Batch: cdb.db.NewBatch(), codec: cdb.codec}
+}
+
+// Print implements dbm.DB. It prints the values compressed.
+func (cdb *compressedDB) Print() error {
+	return cdb.db.Print()
+}
+
+// Stats implements dbm.DB.
+func (cdb *compressedDB) Stats() map[string]string {
+	return cdb.db.Stats()
+}
+
+type compressedBatch struct {
+	dbm.Batch
+	codec Codec
+}
+
+// Set implements dbm.Batch.
+func (b *compressedBatch) Set(key, value []byte) error {
+	compressed, err := b.codec.compress(value)
+	if err != nil {
+		return err
+	}
+	return b.Batch.Set(key, compressed)
+}
+
+// compressedIterator decompresses the values of an iterator. A value that
+// fails to decompress is returned as nil, and the error is reported by Error.
+type compressedIterator struct {
+	dbm.Iterator
+	err error
+}
+
+// Valid implements dbm.Iterator.
+func (it *compressedIterator) Valid() bool {
+	return it.err == nil && it.Iterator.Valid()
+}
+
+// Value implements dbm.Iterator.
+func (it *compressedIterator) Value() []byte {
+	value, err := decompress(it.Iterator.Value())
+	if err != nil {
+		it.err = err
+		return nil
+	}
+	return value
+}
+
+// Error implements dbm.Iterator.
+func (it *compressedIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.Iterator.Error()
+}
+
+// Recompress rewrites the values of db, as stored by a database returned by
+// WrapDB, with codec: the values compressed with another codec, and the values
+// stored uncompressed, or, if codec is None, the compressed values. It returns
+// the number of values rewritten.
+//
+// The space freed by the rewritten values is reclaimed by the compactions of
+// the database. The node must not be running.
+func Recompress(ctx context.Context, db dbm.DB, codec Codec) (int, error) {
+	var (
+		start []byte
+		total int
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		// Not every backend allows writes while an iterator is open, so the
+		// values are read and rewritten in chunks.
+		keys, values, err := readChunk(db, start, recompressBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(keys) == 0 {
+			return total, nil
+		}
+
+		batch := db.NewBatch()
+		pending := 0
+		for i, key := range keys {
+			value, err := decompress(values[i])
+			if err != nil {
+				_ = batch.Close()
+				return total, fmt.Errorf("value of key %X: %w", key, err)
+			}
+			compressed, err := codec.compress(value)
+			if err != nil {
+				_ = batch.Close()
+				return total, err
+			}
+			if bytes.Equal(compressed, values[i]) {
+				continue
+			}
+			if err := batch.Set(key, compressed); err != nil {
+				_ = batch.Close()
+				return total, err
+			}
+			pending++
+		}
+		if pending > 0 {
+			if err := batch.WriteSync(); err != nil {
+				_ = batch.Close()
+				return total, err
+			}
+			total += pending
+		}
+		_ = batch.Close()
+
+		// Resume after the last key.
+		start = append(keys[len(keys)-1], 0)
+	}
+}
+
+// readChunk returns copies of the first n keys and values of db from start.
+func readChunk(db dbm.DB, start []byte, n int) (keys, values [][]byte, err error) {
+	it, err := db.Iterator(start, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer it.Close()
+
+	for ; it.Valid() && len(keys) < n; it.Next() {
+		keys = append(keys, append([]byte(nil), it.Key()...))
+		values = append(values, append([]byte(nil), it.Value()...))
+	}
+	return keys, values, it.Error()
+}
+
+// CompressedDBs are the IDs of the databases of a node whose values are
+// compressed.
+var CompressedDBs = []string{"blockstore", "state"}
+
+// WrapDBProvider returns a DBProvider wrapping the databases of provider
+// listed in CompressedDBs with WrapDB.
+func WrapDBProvider(provider config.DBProvider, codec Codec) config.DBProvider {
+	return func(ctx *config.DBContext) (dbm.DB, error) {
+		db, err := provider(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range CompressedDBs {
+			if ctx.ID == id {
+				return WrapDB(db, codec), nil
+			}
+		}
+		return db, nil
+	}
+}
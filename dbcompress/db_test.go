@@ -0,0 +1,180 @@
+package dbcompress
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/atrest"
+	"github.com/tendermint/tendermint/config"
+)
+
+// compressible returns a value of n bytes which compresses well.
+func compressible(n int) []byte {
+	return bytes.Repeat([]byte("block part "), n/11+1)[:n]
+}
+
+func TestCompressedDB(t *testing.T) {
+	raw := dbm.NewMemDB()
+	require.NoError(t, raw.Set([]byte("a"), compressible(1000)))
+
+	db := WrapDB(raw, Snappy)
+	require.NoError(t, db.Set([]byte("b"), compressible(1000)))
+	require.NoError(t, db.Set([]byte("small"), []byte("small value")))
+	batch := db.NewBatch()
+	require.NoError(t, batch.Set([]byte("c"), compressible(2000)))
+	require.NoError(t, batch.Write())
+	require.NoError(t, batch.Close())
+
+	// The large values are stored compressed, the small ones are not.
+	stored, err := raw.Get([]byte("c"))
+	require.NoError(t, err)
+	assert.True(t, IsCompressed(stored))
+	assert.Less(t, len(stored), 2000)
+	stored, err = raw.Get([]byte("small"))
+	require.NoError(t, err)
+	assert.Equal(t, "small value", string(stored))
+
+	for key, size := range map[string]int{"a": 1000, "b": 1000, "c": 2000} {
+		value, err := db.Get([]byte(key))
+		require.NoError(t, err)
+		assert.Equal(t, compressible(size), value, key)
+	}
+
+	// The values stay readable once compression is disabled.
+	db = WrapDB(raw, None)
+	require.NoError(t, db.Set([]byte("d"), compressible(1000)))
+	stored, err = raw.Get([]byte("d"))
+	require.NoError(t, err)
+	assert.False(t, IsCompressed(stored))
+
+	it, err := db.Iterator(nil, nil)
+	require.NoError(t, err)
+	var entries []string
+	for ; it.Valid(); it.Next() {
+		entries = append(entries, fmt.Sprintf("%s=%d", it.Key(), len(it.Value())))
+	}
+	require.NoError(t, it.Error())
+	require.NoError(t, it.Close())
+	assert.Equal(t, []string{"a=1000", "b=1000", "c=2000", "d=1000", "small=11"}, entries)
+
+	// A corrupted value is reported.
+	require.NoError(t, raw.Set([]byte("e"), []byte{compressedMagic, snappyID, 0xff, 0xff}))
+	_, err = db.Get([]byte("e"))
+	assert.Error(t, err)
+
+	it, err = db.Iterator([]byte("e"), nil)
+	require.NoError(t, err)
+	require.True(t, it.Valid())
+	assert.Nil(t, it.Value())
+	assert.False(t, it.Valid())
+	assert.Error(t, it.Error())
+	require.NoError(t, it.Close())
+}
+
+func TestCompressedDB_Encrypted(t *testing.T) {
+	raw := dbm.NewMemDB()
+	db := WrapDB(atrest.WrapDB(raw, atrest.NewCipher(atrest.GenKeyring())), Snappy)
+	require.NoError(t, db.Set([]byte("a"), compressible(10000)))
+
+	// The value is compressed, then encrypted.
+	stored, err := raw.Get([]byte("a"))
+	require.NoError(t, err)
+	assert.True(t, atrest.IsSealed(stored))
+	assert.Less(t, len(stored), 10000)
+
+	value, err := db.Get([]byte("a"))
+	require.NoError(t, err)
+	assert.Equal(t, compressible(10000), value)
+}
+
+func TestRecompress(t *testing.T) {
+	ctx := context.Background()
+
+	raw := dbm.NewMemDB()
+	db := WrapDB(raw, Snappy)
+	for i := 0; i < 2*recompressBatchSize+10; i++ {
+		key := []byte(fmt.Sprintf("key%05d", i))
+		if i%2 == 0 {
+			require.NoError(t, raw.Set(key, compressible(1000)))
+		} else {
+			require.NoError(t, db.Set(key, compressible(1000)))
+		}
+	}
+	require.NoError(t, raw.Set([]byte("small"), []byte("small value")))
+
+	// The uncompressed values are compressed.
+	n, err := Recompress(ctx, raw, Snappy)
+	require.NoError(t, err)
+	assert.Equal(t, recompressBatchSize+5, n)
+
+	n, err = Recompress(ctx, raw, Snappy)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	// Without compression, all the values are decompressed.
+	n, err = Recompress(ctx, raw, None)
+	require.NoError(t, err)
+	assert.Equal(t, 2*recompressBatchSize+10, n)
+
+	it, err := raw.Iterator(nil, nil)
+	require.NoError(t, err)
+	defer it.Close()
+	count := 0
+	for ; it.Valid(); it.Next() {
+		assert.False(t, IsCompressed(it.Value()))
+		count++
+	}
+	require.NoError(t, it.Error())
+	assert.Equal(t, 2*recompressBatchSize+11, count)
+}
+
+func TestCodecFromConfig(t *testing.T) {
+	cfg := config.TestBaseConfig()
+	codec, err := CodecFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, None, codec)
+
+	cfg.DBCompression = config.DBCompressionSnappy
+	codec, err = CodecFromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, Snappy, codec)
+
+	cfg.DBCompression = config.DBCompressionZstd
+	codec, err = CodecFromConfig(cfg)
+	if zstdEnabled {
+		require.NoError(t, err)
+		assert.Equal(t, Zstd, codec)
+
+		db := WrapDB(dbm.NewMemDB(), Zstd)
+		require.NoError(t, db.Set([]byte("a"), compressible(1000)))
+		value, err := db.Get([]byte("a"))
+		require.NoError(t, err)
+		assert.Equal(t, compressible(1000), value)
+	} else {
+		assert.Equal(t, ErrZstdDisabled, err)
+
+		// The values compressed with zstd are reported as such.
+		_, err = decompress([]byte{compressedMagic, zstdID, 0})
+		assert.Equal(t, ErrZstdDisabled, err)
+	}
+}
+
+func TestWrapDBProvider(t *testing.T) {
+	cfg := config.TestConfig()
+	provider := WrapDBProvider(config.DefaultDBProvider, Snappy)
+
+	for _, id := range CompressedDBs {
+		db, err := provider(&config.DBContext{ID: id, Config: cfg})
+		require.NoError(t, err)
+		assert.IsType(t, &compressedDB{}, db, id)
+	}
+	db, err := provider(&config.DBContext{ID: "evidence", Config: cfg})
+	require.NoError(t, err)
+	assert.IsType(t, &dbm.MemDB{}, db)
+}
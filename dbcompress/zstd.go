@@ -0,0 +1,18 @@
+//go:build zstd
+// +build zstd
+
+package dbcompress
+
+import (
+	"github.com/DataDog/zstd"
+)
+
+const zstdEnabled = true
+
+func zstdCompress(value []byte) ([]byte, error) {
+	return zstd.Compress(nil, value)
+}
+
+func zstdDecompress(value []byte) ([]byte, error) {
+	return zstd.Decompress(nil, value)
+}
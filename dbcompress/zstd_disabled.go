@@ -0,0 +1,14 @@
+//go:build !zstd
+// +build !zstd
+
+package dbcompress
+
+const zstdEnabled = false
+
+func zstdCompress(value []byte) ([]byte, error) {
+	return nil, ErrZstdDisabled
+}
+
+func zstdDecompress(value []byte) ([]byte, error) {
+	return nil, ErrZstdDisabled
+}
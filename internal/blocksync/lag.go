@@ -0,0 +1,74 @@
+package blocksync
+
+import (
+	"time"
+)
+
+// lagTracker follows the lag of the node behind the highest peer while
+// consensus is more than the reentry lag behind, to tell whether consensus is
+// catching up on its own or whether the node should go back to block sync.
+type lagTracker struct {
+	// window is how long the lag is observed before deciding, and the time
+	// within which consensus must be expected to catch up.
+	window time.Duration
+
+	behindSince time.Time
+	// samples are the lags observed over the last window, the oldest first.
+	// The oldest one may predate the window, so that they span it.
+	samples []lagSample
+}
+
+type lagSample struct {
+	time time.Time
+	lag  int64
+}
+
+func newLagTracker(window time.Duration) *lagTracker {
+	return &lagTracker{window: window}
+}
+
+// observe records the lag of the node at now, while it is behind.
+func (t *lagTracker) observe(now time.Time, lag int64) {
+	if t.behindSince.IsZero() {
+		t.behindSince = now
+	}
+	t.samples = append(t.samples, lagSample{time: now, lag: lag})
+	for len(t.samples) > 2 && !t.samples[1].time.After(now.Add(-t.window)) {
+		t.samples = t.samples[1:]
+	}
+}
+
+// reset forgets the lags observed, once the node is no longer behind.
+func (t *lagTracker) reset() {
+	t.behindSince = time.Time{}
+	t.samples = nil
+}
+
+// catchUpRate returns by how many heights per second the lag shrank over the
+// samples, negative if it grew.
+func (t *lagTracker) catchUpRate() float64 {
+	if len(t.samples) < 2 {
+		return 0
+	}
+	first, last := t.samples[0], t.samples[len(t.samples)-1]
+	elapsed := last.time.Sub(first.time).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(first.lag-last.lag) / elapsed
+}
+
+// shouldReenter reports whether the node has been behind for the whole window
+// at now, and consensus is not catching up fast enough to close the gap
+// within another window.
+func (t *lagTracker) shouldReenter(now time.Time) bool {
+	if t.behindSince.IsZero() || now.Sub(t.behindSince) < t.window {
+		return false
+	}
+	rate := t.catchUpRate()
+	if rate <= 0 {
+		return true
+	}
+	lag := t.samples[len(t.samples)-1].lag
+	return float64(lag)/rate > t.window.Seconds()
+}
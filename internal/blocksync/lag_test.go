@@ -0,0 +1,42 @@
+package blocksync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLagTracker(t *testing.T) {
+	start := time.Now()
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	// Consensus falls further behind: the node goes back to block sync once
+	// behind for the whole window.
+	tracker := newLagTracker(10 * time.Second)
+	for s := 0; s < 10; s++ {
+		tracker.observe(at(s), int64(200+s))
+		assert.False(t, tracker.shouldReenter(at(s)), s)
+	}
+	tracker.observe(at(10), 210)
+	assert.True(t, tracker.shouldReenter(at(10)))
+	assert.InDelta(t, -1, tracker.catchUpRate(), 0.01)
+
+	// Consensus catches up by 50 heights per second: the 150 heights left
+	// are closed within the window, so it is left to it.
+	tracker.reset()
+	assert.False(t, tracker.shouldReenter(at(10)))
+	for s := 10; s <= 20; s++ {
+		tracker.observe(at(s), int64(650-50*(s-10)))
+	}
+	assert.InDelta(t, 50, tracker.catchUpRate(), 0.01)
+	assert.False(t, tracker.shouldReenter(at(20)))
+
+	// Consensus slows down to 5 heights per second: the rate is measured
+	// over the last window only, and the node goes back to block sync.
+	for s := 21; s <= 30; s++ {
+		tracker.observe(at(s), int64(150-5*(s-20)))
+	}
+	assert.InDelta(t, 5, tracker.catchUpRate(), 0.01)
+	assert.True(t, tracker.shouldReenter(at(30)))
+}
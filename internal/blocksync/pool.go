@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/tendermint/tendermint/internal/libs/flowrate"
+	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/libs/service"
 	"github.com/tendermint/tendermint/types"
@@ -29,9 +30,8 @@ eg, L = latency = 0.1s
 
 const (
 	requestIntervalMS         = 2
-	maxTotalRequesters        = 600
+	maxTotalRequesters        = 1000 // heights requested ahead of the pool height
 	maxPeerErrBuffer          = 1000
-	maxPendingRequests        = maxTotalRequesters
 	maxPendingRequestsPerPeer = 20
 
 	// Minimum recv rate to ensure we're receiving blocks from a peer fast
@@ -44,6 +44,18 @@ const (
 
 	// Maximum difference between current and new block's height.
 	maxDiffBetweenCurrentAndReceivedBlockHeight = 100
+
+	// A request is stalled once it has waited stallFactor times the block
+	// latency of its peer, and at least minStallTimeout: it is then sent to
+	// another peer. A peer stalling maxPeerStalls requests in a row is
+	// removed.
+	stallFactor     = 4
+	minStallTimeout = 2 * time.Second
+	maxPeerStalls   = 3
+
+	// Weight of the last sample in the moving average of the block latency of
+	// a peer.
+	latencyAlpha = 0.2
 )
 
 var peerTimeout = 15 * time.Second // not const so we can override with tests
@@ -54,9 +66,14 @@ var peerTimeout = 15 * time.Second // not const so we can override with tests
 	in sequence from peers that reported higher heights than ours.
 	Every so often we ask peers what height they're on so we can keep going.
 
-	Requests are continuously made for blocks of higher heights until
-	the limit is reached. If most of the requests have no available peers, and we
-	are not at peer limits, we can probably switch to consensus reactor
+	The scheduler of the pool requests the blocks of a window of heights ahead
+	of the pool height, lowest heights first. Each block is requested from the
+	peer expected to deliver it first, according to the block latency measured
+	for the peer and its pending requests. A peer may have up to
+	maxPendingRequestsPerPeer pending requests; the limit is halved each time
+	one of its requests stalls, and raised back by one with each block it
+	delivers. If most of the requests have no available peers, and we are not
+	at peer limits, we can probably switch to consensus reactor.
 */
 
 // BlockRequest stores a block request identified by the block Height and the
@@ -75,12 +92,16 @@ type BlockPool struct {
 
 	mtx sync.RWMutex
 	// block requests
-	requesters map[int64]*bpRequester
-	height     int64 // the lowest key in requesters.
+	requests map[int64]*bpRequest
+	height   int64 // the lowest key in requests.
 	// peers
 	peers         map[types.NodeID]*bpPeer
 	maxPeerHeight int64 // the biggest reported height
 
+	// maintenance, if set, tells which peers are about to go down for
+	// maintenance; they are only picked when no other peer can serve a height.
+	maintenance *p2p.PeerMaintenance
+
 	// atomic
 	numPending int32 // number of requests pending assignment or block response
 
@@ -105,7 +126,7 @@ func NewBlockPool(
 	bp := &BlockPool{
 		logger:       logger,
 		peers:        make(map[types.NodeID]*bpPeer),
-		requesters:   make(map[int64]*bpRequester),
+		requests:     make(map[int64]*bpRequest),
 		height:       start,
 		startHeight:  start,
 		numPending:   0,
@@ -118,12 +139,12 @@ func NewBlockPool(
 	return bp
 }
 
-// OnStart implements service.Service by spawning requesters routine and recording
-// pool's start time.
+// OnStart implements service.Service by spawning the scheduler routine and
+// recording pool's start time.
 func (pool *BlockPool) OnStart(ctx context.Context) error {
 	pool.lastAdvance = time.Now()
 	pool.lastHundredBlockTimeStamp = pool.lastAdvance
-	go pool.makeRequestersRoutine(ctx)
+	go pool.scheduleRoutine(ctx)
 
 	go func() {
 		defer close(pool.exitedCh)
@@ -135,32 +156,90 @@ func (pool *BlockPool) OnStart(ctx context.Context) error {
 
 func (*BlockPool) OnStop() {}
 
-// spawns requesters as needed
-func (pool *BlockPool) makeRequestersRoutine(ctx context.Context) {
+// scheduleRoutine removes the timed out peers and sends the block requests
+// scheduled, until the pool stops.
+func (pool *BlockPool) scheduleRoutine(ctx context.Context) {
+	ticker := time.NewTicker(requestIntervalMS * time.Millisecond)
+	defer ticker.Stop()
+
 	for {
-		if !pool.IsRunning() {
-			break
+		select {
+		case <-ctx.Done():
+			return
+		case <-pool.exitedCh:
+			return
+		case <-ticker.C:
 		}
 
-		_, numPending, lenRequesters := pool.GetStatus()
-		switch {
-		case numPending >= maxPendingRequests:
-			// sleep for a bit.
-			time.Sleep(requestIntervalMS * time.Millisecond)
-			// check for timed out peers
-			pool.removeTimedoutPeers()
-		case lenRequesters >= maxTotalRequesters:
-			// sleep for a bit.
-			time.Sleep(requestIntervalMS * time.Millisecond)
-			// check for timed out peers
-			pool.removeTimedoutPeers()
-		default:
-			// request for more blocks.
-			pool.makeNextRequester(ctx)
+		pool.removeTimedoutPeers()
+		for _, request := range pool.schedule(time.Now()) {
+			pool.sendRequest(ctx, request.Height, request.PeerID)
 		}
 	}
 }
 
+// schedule extends the window of requests up to the highest peer, moves the
+// stalled requests away from their peers, and assigns the unassigned requests
+// to peers, lowest heights first. It returns the requests to send.
+func (pool *BlockPool) schedule(now time.Time) []BlockRequest {
+	pool.mtx.Lock()
+	defer pool.mtx.Unlock()
+
+	for next := pool.height + pool.requestsLen(); next <= pool.maxPeerHeight &&
+		len(pool.requests) < maxTotalRequesters; next++ {
+		pool.requests[next] = &bpRequest{height: next}
+		atomic.AddInt32(&pool.numPending, 1)
+	}
+
+	var scheduled []BlockRequest
+	for height := pool.height; height < pool.height+pool.requestsLen(); height++ {
+		request := pool.requests[height]
+		if request.block != nil {
+			continue
+		}
+		if request.peerID != "" {
+			peer := pool.peers[request.peerID]
+			if peer == nil || now.Sub(request.requestedAt) < peer.stallTimeout() {
+				continue
+			}
+			pool.stall(request, peer, now)
+		}
+
+		peer := pool.pickPeer(height, request.previousPeers)
+		if peer == nil {
+			continue
+		}
+		peer.incrPending()
+		request.peerID = peer.id
+		request.requestedAt = now
+		scheduled = append(scheduled, BlockRequest{height, peer.id})
+	}
+	return scheduled
+}
+
+// stall moves the stalled request away from its peer, penalizing the peer.
+func (pool *BlockPool) stall(request *bpRequest, peer *bpPeer, now time.Time) {
+	waited := now.Sub(request.requestedAt)
+	pool.logger.Debug("block request stalled", "height", request.height, "peer", peer.id,
+		"latency", peer.latency, "waited", waited)
+
+	peer.release()
+	peer.observeLatency(waited)
+	peer.stalls++
+	if peer.maxPending /= 2; peer.maxPending < 1 {
+		peer.maxPending = 1
+	}
+	request.previousPeers = append(request.previousPeers, peer.id)
+	request.peerID = ""
+
+	if peer.stalls >= maxPeerStalls && !peer.didTimeout {
+		err := fmt.Errorf("peer stalled %d block requests in a row", peer.stalls)
+		pool.sendError(err, peer.id)
+		pool.logger.Error("SendTimeout", "peer", peer.id, "reason", err)
+		peer.didTimeout = true
+	}
+}
+
 func (pool *BlockPool) removeTimedoutPeers() {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
@@ -188,16 +267,22 @@ func (pool *BlockPool) removeTimedoutPeers() {
 }
 
 // GetStatus returns pool's height, numPending requests and the number of
-// requesters.
+// requests of the window.
 func (pool *BlockPool) GetStatus() (height int64, numPending int32, lenRequesters int) {
 	pool.mtx.RLock()
 	defer pool.mtx.RUnlock()
 
-	return pool.height, atomic.LoadInt32(&pool.numPending), len(pool.requesters)
+	return pool.height, atomic.LoadInt32(&pool.numPending), len(pool.requests)
 }
 
 // IsCaughtUp returns true if this node is caught up, false - otherwise.
 func (pool *BlockPool) IsCaughtUp() bool {
+	return pool.isCaughtUpWithin(0)
+}
+
+// isCaughtUpWithin returns whether the pool is at most lag heights behind the
+// highest peer.
+func (pool *BlockPool) isCaughtUpWithin(lag int64) bool {
 	pool.mtx.RLock()
 	defer pool.mtx.RUnlock()
 
@@ -208,7 +293,31 @@ func (pool *BlockPool) IsCaughtUp() bool {
 
 	// NOTE: we use maxPeerHeight - 1 because to sync block H requires block H+1
 	// to verify the LastCommit.
-	return pool.height >= (pool.maxPeerHeight - 1)
+	return pool.height >= (pool.maxPeerHeight - 1 - lag)
+}
+
+// lag returns how many heights a node about to sync height is behind the
+// highest peer, not counting the last height, or 0 if there are no peers.
+func (pool *BlockPool) lag(height int64) int64 {
+	pool.mtx.RLock()
+	defer pool.mtx.RUnlock()
+
+	if len(pool.peers) == 0 || height >= pool.maxPeerHeight-1 {
+		return 0
+	}
+	return pool.maxPeerHeight - 1 - height
+}
+
+// copyPeersTo adds the peers of the pool, with their reported ranges, and the
+// maintenance schedule to other.
+func (pool *BlockPool) copyPeersTo(other *BlockPool) {
+	pool.mtx.RLock()
+	defer pool.mtx.RUnlock()
+
+	other.maintenance = pool.maintenance
+	for _, peer := range pool.peers {
+		other.SetPeerRange(peer.id, peer.base, peer.height)
+	}
 }
 
 // PeekTwoBlocks returns blocks at pool.height and pool.height+1.
@@ -219,26 +328,42 @@ func (pool *BlockPool) PeekTwoBlocks() (first *types.Block, second *types.Block)
 	pool.mtx.RLock()
 	defer pool.mtx.RUnlock()
 
-	if r := pool.requesters[pool.height]; r != nil {
-		first = r.getBlock()
+	if r := pool.requests[pool.height]; r != nil {
+		first = r.block
 	}
-	if r := pool.requesters[pool.height+1]; r != nil {
-		second = r.getBlock()
+	if r := pool.requests[pool.height+1]; r != nil {
+		second = r.block
 	}
 	return
 }
 
-// PopRequest pops the first block at pool.height.
+// PeekBlocks returns up to max consecutive blocks starting at pool.height,
+// stopping at the first height whose block has not been received yet.
+func (pool *BlockPool) PeekBlocks(max int) []*types.Block {
+	pool.mtx.RLock()
+	defer pool.mtx.RUnlock()
+
+	blocks := make([]*types.Block, 0, max)
+	for h := pool.height; len(blocks) < max; h++ {
+		r := pool.requests[h]
+		if r == nil || r.block == nil {
+			break
+		}
+		blocks = append(blocks, r.block)
+	}
+	return blocks
+}
+
+// PopRequest pops the first block at pool.height and returns the ID of the
+// peer that delivered it.
 // It must have been validated by 'second'.Commit from PeekTwoBlocks().
-func (pool *BlockPool) PopRequest() {
+func (pool *BlockPool) PopRequest() types.NodeID {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
-	if r := pool.requesters[pool.height]; r != nil {
-		if err := r.Stop(); err != nil {
-			pool.logger.Error("error stopping requester", "err", err)
-		}
-		delete(pool.requesters, pool.height)
+	if r := pool.requests[pool.height]; r != nil {
+		peerID := r.peerID
+		delete(pool.requests, pool.height)
 		pool.height++
 		pool.lastAdvance = time.Now()
 
@@ -254,9 +379,10 @@ func (pool *BlockPool) PopRequest() {
 			pool.lastHundredBlockTimeStamp = time.Now()
 		}
 
-	} else {
-		panic(fmt.Sprintf("Expected requester to pop, got nothing at height %v", pool.height))
+		return peerID
 	}
+
+	panic(fmt.Sprintf("Expected requester to pop, got nothing at height %v", pool.height))
 }
 
 // RedoRequest invalidates the block at pool.height,
@@ -266,23 +392,28 @@ func (pool *BlockPool) RedoRequest(height int64) types.NodeID {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
-	request := pool.requesters[height]
-	peerID := request.getPeerID()
+	request := pool.requests[height]
+	if request == nil {
+		return ""
+	}
+	peerID := request.peerID
 	if peerID != types.NodeID("") {
-		// RemovePeer will redo all requesters associated with this peer.
+		// RemovePeer will redo all requests associated with this peer.
 		pool.removePeer(peerID)
 	}
 	return peerID
 }
 
-// AddBlock validates that the block comes from the peer it was expected from and calls the requester to store it.
+// AddBlock validates that the block comes from the peer it was requested from
+// and stores it. The block of a stalled request is still accepted from the
+// previous peers of the request, if it wasn't received yet.
 // TODO: ensure that blocks come in order for each peer.
 func (pool *BlockPool) AddBlock(peerID types.NodeID, block *types.Block, blockSize int) {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
-	requester := pool.requesters[block.Height]
-	if requester == nil {
+	request := pool.requests[block.Height]
+	if request == nil {
 		pool.logger.Error("peer sent us a block we didn't expect",
 			"peer", peerID, "curHeight", pool.height, "blockHeight", block.Height)
 		diff := pool.height - block.Height
@@ -295,17 +426,33 @@ func (pool *BlockPool) AddBlock(peerID types.NodeID, block *types.Block, blockSi
 		return
 	}
 
-	if requester.setBlock(block, peerID) {
-		atomic.AddInt32(&pool.numPending, -1)
-		peer := pool.peers[peerID]
-		if peer != nil {
-			peer.decrPending(blockSize)
+	previous := containsNodeID(request.previousPeers, peerID)
+	if request.block != nil || (peerID != request.peerID && !previous) {
+		if request.block != nil && previous {
+			// A late response to a request moved to another peer.
+			return
 		}
-	} else {
 		err := errors.New("requester is different or block already exists")
-		pool.logger.Error(err.Error(), "peer", peerID, "requester", requester.getPeerID(), "blockHeight", block.Height)
+		pool.logger.Error(err.Error(), "peer", peerID, "requester", request.peerID, "blockHeight", block.Height)
 		pool.sendError(err, peerID)
+		return
 	}
+
+	if peer := pool.peers[request.peerID]; peer != nil {
+		if peerID == request.peerID {
+			peer.observeLatency(time.Since(request.requestedAt))
+			peer.delivered()
+			peer.decrPending(blockSize)
+		} else {
+			// The block of the moved request came from a previous peer
+			// first: the request of the current peer is dropped.
+			peer.release()
+			request.previousPeers = append(request.previousPeers, peer.id)
+		}
+	}
+	request.block = block
+	request.peerID = peerID
+	atomic.AddInt32(&pool.numPending, -1)
 }
 
 // MaxPeerHeight returns the highest reported height.
@@ -352,10 +499,16 @@ func (pool *BlockPool) RemovePeer(peerID types.NodeID) {
 	pool.removePeer(peerID)
 }
 
+// removePeer removes the peer, and unassigns its requests, dropping the
+// blocks it delivered, to request them from other peers.
 func (pool *BlockPool) removePeer(peerID types.NodeID) {
-	for _, requester := range pool.requesters {
-		if requester.getPeerID() == peerID {
-			requester.redo(peerID)
+	for _, request := range pool.requests {
+		if request.peerID == peerID {
+			if request.block != nil {
+				atomic.AddInt32(&pool.numPending, 1)
+			}
+			request.peerID = ""
+			request.block = nil
 		}
 	}
 
@@ -386,58 +539,79 @@ func (pool *BlockPool) updateMaxPeerHeight() {
 	pool.maxPeerHeight = max
 }
 
-// Pick an available peer with the given height available.
+// Pick an available peer with the given height available, and increment its
+// pending requests.
 // If no peers are available, returns nil.
 func (pool *BlockPool) pickIncrAvailablePeer(height int64) *bpPeer {
 	pool.mtx.Lock()
 	defer pool.mtx.Unlock()
 
+	peer := pool.pickPeer(height, nil)
+	if peer != nil {
+		peer.incrPending()
+	}
+	return peer
+}
+
+// pickPeer returns the peer with the height available expected to deliver
+// its block first, or nil if no peer is available. The peers about to go
+// down for maintenance and the peers in avoid are only picked if no other
+// peer is available.
+func (pool *BlockPool) pickPeer(height int64, avoid []types.NodeID) *bpPeer {
+	defaultLatency := pool.averageLatency()
+
+	var (
+		best        *bpPeer
+		bestAvoided bool
+		bestDelay   float64
+	)
 	for _, peer := range pool.peers {
-		if peer.didTimeout {
-			pool.removePeer(peer.id)
-			continue
-		}
-		if peer.numPending >= maxPendingRequestsPerPeer {
+		if peer.didTimeout || peer.numPending >= peer.maxPending {
 			continue
 		}
 		if height < peer.base || height > peer.height {
 			continue
 		}
-		peer.incrPending()
-		return peer
+		avoided := pool.maintenance.Avoid(peer.id) || containsNodeID(avoid, peer.id)
+		delay := peer.expectedDelay(defaultLatency)
+		if best == nil || (bestAvoided && !avoided) || (avoided == bestAvoided && delay < bestDelay) {
+			best, bestAvoided, bestDelay = peer, avoided, delay
+		}
 	}
-	return nil
+	return best
 }
 
-func (pool *BlockPool) makeNextRequester(ctx context.Context) {
-	pool.mtx.Lock()
-	defer pool.mtx.Unlock()
-
-	nextHeight := pool.height + pool.requestersLen()
-	if nextHeight > pool.maxPeerHeight {
-		return
+// averageLatency returns the average block latency of the peers it was
+// measured for, or 0 if there are none.
+func (pool *BlockPool) averageLatency() time.Duration {
+	var (
+		sum time.Duration
+		n   int
+	)
+	for _, peer := range pool.peers {
+		if peer.latency > 0 {
+			sum += peer.latency
+			n++
+		}
 	}
-
-	request := newBPRequester(pool.logger, pool, nextHeight)
-
-	pool.requesters[nextHeight] = request
-	atomic.AddInt32(&pool.numPending, 1)
-
-	err := request.Start(ctx)
-	if err != nil {
-		request.logger.Error("error starting request", "err", err)
+	if n == 0 {
+		return 0
 	}
+	return sum / time.Duration(n)
 }
 
-func (pool *BlockPool) requestersLen() int64 {
-	return int64(len(pool.requesters))
+func (pool *BlockPool) requestsLen() int64 {
+	return int64(len(pool.requests))
 }
 
-func (pool *BlockPool) sendRequest(height int64, peerID types.NodeID) {
+func (pool *BlockPool) sendRequest(ctx context.Context, height int64, peerID types.NodeID) {
 	if !pool.IsRunning() {
 		return
 	}
-	pool.requestsCh <- BlockRequest{height, peerID}
+	select {
+	case pool.requestsCh <- BlockRequest{height, peerID}:
+	case <-ctx.Done():
+	}
 }
 
 func (pool *BlockPool) sendError(err error, peerID types.NodeID) {
@@ -454,13 +628,13 @@ func (pool *BlockPool) debug() string {
 	defer pool.mtx.Unlock()
 
 	str := ""
-	nextHeight := pool.height + pool.requestersLen()
+	nextHeight := pool.height + pool.requestsLen()
 	for h := pool.height; h < nextHeight; h++ {
-		if pool.requesters[h] == nil {
+		if pool.requests[h] == nil {
 			str += fmt.Sprintf("H(%v):X ", h)
 		} else {
 			str += fmt.Sprintf("H(%v):", h)
-			str += fmt.Sprintf("B?(%v) ", pool.requesters[h].block != nil)
+			str += fmt.Sprintf("B?(%v) ", pool.requests[h].block != nil)
 		}
 	}
 	return str
@@ -480,17 +654,33 @@ func (pool *BlockPool) getLastSyncRate() float64 {
 	return pool.lastSyncRate
 }
 
+func containsNodeID(ids []types.NodeID, id types.NodeID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+	return false
+}
+
 //-------------------------------------
 
 type bpPeer struct {
 	didTimeout  bool
 	numPending  int32
+	maxPending  int32 // the limit of pending requests, lowered by stalls
 	height      int64
 	base        int64
 	pool        *BlockPool
 	id          types.NodeID
 	recvMonitor *flowrate.Monitor
 
+	// latency is the moving average of the time the peer took to deliver the
+	// blocks requested, 0 until the first one. stalls is the number of
+	// requests stalled since the last block delivered.
+	latency time.Duration
+	stalls  int
+
 	timeout *time.Timer
 
 	logger log.Logger
@@ -503,6 +693,7 @@ func newBPPeer(pool *BlockPool, peerID types.NodeID, base int64, height int64) *
 		base:       base,
 		height:     height,
 		numPending: 0,
+		maxPending: maxPendingRequestsPerPeer,
 		logger:     log.NewNopLogger(),
 	}
 	return peer
@@ -540,158 +731,85 @@ func (peer *bpPeer) decrPending(recvSize int) {
 	}
 }
 
-func (peer *bpPeer) onTimeout() {
-	peer.pool.mtx.Lock()
-	defer peer.pool.mtx.Unlock()
-
-	err := errors.New("peer did not send us anything")
-	peer.pool.sendError(err, peer.id)
-	peer.logger.Error("SendTimeout", "reason", err, "timeout", peerTimeout)
-	peer.didTimeout = true
-}
-
-//-------------------------------------
-
-type bpRequester struct {
-	service.BaseService
-	logger     log.Logger
-	pool       *BlockPool
-	height     int64
-	gotBlockCh chan struct{}
-	redoCh     chan types.NodeID // redo may send multitime, add peerId to identify repeat
-
-	mtx    sync.Mutex
-	peerID types.NodeID
-	block  *types.Block
+// release drops a pending request of the peer without a block.
+func (peer *bpPeer) release() {
+	peer.numPending--
+	if peer.numPending == 0 {
+		peer.timeout.Stop()
+	}
 }
 
-func newBPRequester(logger log.Logger, pool *BlockPool, height int64) *bpRequester {
-	bpr := &bpRequester{
-		logger:     pool.logger,
-		pool:       pool,
-		height:     height,
-		gotBlockCh: make(chan struct{}, 1),
-		redoCh:     make(chan types.NodeID, 1),
-
-		peerID: "",
-		block:  nil,
+// observeLatency adds a sample to the block latency of the peer.
+func (peer *bpPeer) observeLatency(latency time.Duration) {
+	switch {
+	case latency <= 0:
+	case peer.latency == 0:
+		peer.latency = latency
+	default:
+		peer.latency = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(peer.latency))
 	}
-	bpr.BaseService = *service.NewBaseService(logger, "bpRequester", bpr)
-	return bpr
 }
 
-func (bpr *bpRequester) OnStart(ctx context.Context) error {
-	go bpr.requestRoutine(ctx)
-	return nil
+// delivered raises the limit of pending requests of the peer after it
+// delivered a block, up to maxPendingRequestsPerPeer.
+func (peer *bpPeer) delivered() {
+	peer.stalls = 0
+	if peer.maxPending < maxPendingRequestsPerPeer {
+		peer.maxPending++
+	}
 }
 
-func (*bpRequester) OnStop() {}
-
-// Returns true if the peer matches and block doesn't already exist.
-func (bpr *bpRequester) setBlock(block *types.Block, peerID types.NodeID) bool {
-	bpr.mtx.Lock()
-	if bpr.block != nil || bpr.peerID != peerID {
-		bpr.mtx.Unlock()
-		return false
+// expectedDelay returns an estimate of the time the peer would take to
+// deliver a new request: its block latency, or defaultLatency if it wasn't
+// measured yet, times the number of requests it would have pending.
+func (peer *bpPeer) expectedDelay(defaultLatency time.Duration) float64 {
+	latency := peer.latency
+	if latency == 0 {
+		latency = defaultLatency
 	}
-	bpr.block = block
-	bpr.mtx.Unlock()
-
-	select {
-	case bpr.gotBlockCh <- struct{}{}:
-	default:
+	if latency == 0 {
+		latency = time.Millisecond
 	}
-	return true
+	return float64(latency) * float64(peer.numPending+1)
 }
 
-func (bpr *bpRequester) getBlock() *types.Block {
-	bpr.mtx.Lock()
-	defer bpr.mtx.Unlock()
-	return bpr.block
-}
-
-func (bpr *bpRequester) getPeerID() types.NodeID {
-	bpr.mtx.Lock()
-	defer bpr.mtx.Unlock()
-	return bpr.peerID
+// stallTimeout returns how long a request of the peer is waited for before
+// it is stalled.
+func (peer *bpPeer) stallTimeout() time.Duration {
+	if peer.latency == 0 {
+		return peerTimeout
+	}
+	timeout := stallFactor * peer.latency
+	switch {
+	case timeout < minStallTimeout:
+		return minStallTimeout
+	case timeout > peerTimeout:
+		return peerTimeout
+	}
+	return timeout
 }
 
-// This is called from the requestRoutine, upon redo().
-func (bpr *bpRequester) reset() {
-	bpr.mtx.Lock()
-	defer bpr.mtx.Unlock()
-
-	if bpr.block != nil {
-		atomic.AddInt32(&bpr.pool.numPending, 1)
-	}
+func (peer *bpPeer) onTimeout() {
+	peer.pool.mtx.Lock()
+	defer peer.pool.mtx.Unlock()
 
-	bpr.peerID = ""
-	bpr.block = nil
+	err := errors.New("peer did not send us anything")
+	peer.pool.sendError(err, peer.id)
+	peer.logger.Error("SendTimeout", "reason", err, "timeout", peerTimeout)
+	peer.didTimeout = true
 }
 
-// Tells bpRequester to pick another peer and try again.
-// NOTE: Nonblocking, and does nothing if another redo
-// was already requested.
-func (bpr *bpRequester) redo(peerID types.NodeID) {
-	select {
-	case bpr.redoCh <- peerID:
-	default:
-	}
-}
+//-------------------------------------
 
-// Responsible for making more requests as necessary
-// Returns only when a block is found (e.g. AddBlock() is called)
-func (bpr *bpRequester) requestRoutine(ctx context.Context) {
-	bprPoolDone := make(chan struct{})
-	go func() {
-		defer close(bprPoolDone)
-		bpr.pool.Wait()
-	}()
+// bpRequest is the request of the block at a height of the window. It is
+// assigned to peerID, unless it is empty, since requestedAt. Once received,
+// block is set, and peerID is the peer which delivered it.
+type bpRequest struct {
+	height      int64
+	peerID      types.NodeID
+	requestedAt time.Time
+	block       *types.Block
 
-OUTER_LOOP:
-	for {
-		// Pick a peer to send request to.
-		var peer *bpPeer
-	PICK_PEER_LOOP:
-		for {
-			if !bpr.IsRunning() || !bpr.pool.IsRunning() {
-				return
-			}
-			peer = bpr.pool.pickIncrAvailablePeer(bpr.height)
-			if peer == nil {
-				time.Sleep(requestIntervalMS * time.Millisecond)
-				continue PICK_PEER_LOOP
-			}
-			break PICK_PEER_LOOP
-		}
-		bpr.mtx.Lock()
-		bpr.peerID = peer.id
-		bpr.mtx.Unlock()
-
-		// Send request and wait.
-		bpr.pool.sendRequest(bpr.height, peer.id)
-	WAIT_LOOP:
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-bpr.pool.exitedCh:
-				if err := bpr.Stop(); err != nil {
-					bpr.logger.Error("error stopped requester", "err", err)
-				}
-				return
-			case peerID := <-bpr.redoCh:
-				if peerID == bpr.peerID {
-					bpr.reset()
-					continue OUTER_LOOP
-				} else {
-					continue WAIT_LOOP
-				}
-			case <-bpr.gotBlockCh:
-				// We got a block!
-				// Continue the for-loop and wait til Quit.
-				continue WAIT_LOOP
-			}
-		}
-	}
+	// the peers the request was assigned to before, which stalled on it
+	previousPeers []types.NodeID
 }
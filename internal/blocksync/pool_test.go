@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/libs/log"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 	"github.com/tendermint/tendermint/types"
@@ -234,3 +235,147 @@ func TestBlockPoolRemovePeer(t *testing.T) {
 
 	assert.EqualValues(t, 0, pool.MaxPeerHeight())
 }
+
+func TestBlockPoolAvoidsPeersInMaintenance(t *testing.T) {
+	requestsCh := make(chan BlockRequest)
+	errorsCh := make(chan peerError)
+	pool := NewBlockPool(log.TestingLogger(), 1, requestsCh, errorsCh)
+
+	pool.maintenance = p2p.NewPeerMaintenance(time.Hour)
+	pool.maintenance.Set("leaving", []p2p.MaintenanceWindow{{
+		Start: time.Now().Add(time.Minute),
+		End:   time.Now().Add(time.Hour),
+	}})
+
+	pool.SetPeerRange("leaving", 1, 10)
+	pool.SetPeerRange("staying", 1, 10)
+
+	for i := 0; i < maxPendingRequestsPerPeer; i++ {
+		peer := pool.pickIncrAvailablePeer(1)
+		require.NotNil(t, peer)
+		require.EqualValues(t, "staying", peer.id)
+	}
+
+	// the peer in maintenance is used once no other peer is available
+	peer := pool.pickIncrAvailablePeer(1)
+	require.NotNil(t, peer)
+	require.EqualValues(t, "leaving", peer.id)
+}
+
+func TestBlockPoolLag(t *testing.T) {
+	requestsCh := make(chan BlockRequest)
+	errorsCh := make(chan peerError)
+	pool := NewBlockPool(log.TestingLogger(), 10, requestsCh, errorsCh)
+
+	// Without peers, the pool is neither caught up nor lagging.
+	require.False(t, pool.IsCaughtUp())
+	require.Zero(t, pool.lag(10))
+
+	pool.SetPeerRange("P1", 1, 20)
+	require.EqualValues(t, 9, pool.lag(10))
+	require.Zero(t, pool.lag(19))
+	require.Zero(t, pool.lag(25))
+	require.False(t, pool.IsCaughtUp())
+	require.False(t, pool.isCaughtUpWithin(8))
+	require.True(t, pool.isCaughtUpWithin(9))
+
+	// A new pool starts out with the peers of the old one.
+	other := NewBlockPool(log.TestingLogger(), 15, requestsCh, errorsCh)
+	pool.copyPeersTo(other)
+	require.EqualValues(t, 20, other.MaxPeerHeight())
+	require.EqualValues(t, 4, other.lag(15))
+}
+
+func TestBlockPoolPicksFastestPeer(t *testing.T) {
+	requestsCh := make(chan BlockRequest)
+	errorsCh := make(chan peerError)
+	pool := NewBlockPool(log.TestingLogger(), 1, requestsCh, errorsCh)
+
+	pool.SetPeerRange("fast", 1, 10)
+	pool.SetPeerRange("slow", 1, 10)
+	pool.peers["fast"].observeLatency(10 * time.Millisecond)
+	pool.peers["slow"].observeLatency(100 * time.Millisecond)
+
+	// The fast peer is picked until its pending requests make it expected
+	// to deliver later than the slow one.
+	for i := 0; i < 9; i++ {
+		peer := pool.pickIncrAvailablePeer(1)
+		require.NotNil(t, peer)
+		require.EqualValues(t, "fast", peer.id)
+	}
+	pool.peers["fast"].incrPending()
+	peer := pool.pickIncrAvailablePeer(1)
+	require.NotNil(t, peer)
+	require.EqualValues(t, "slow", peer.id)
+
+	// A new peer is expected to be as fast as the average peer.
+	pool.SetPeerRange("new", 1, 10)
+	peer = pool.pickIncrAvailablePeer(1)
+	require.NotNil(t, peer)
+	require.EqualValues(t, "new", peer.id)
+}
+
+func TestBlockPoolStalledRequests(t *testing.T) {
+	requestsCh := make(chan BlockRequest)
+	errorsCh := make(chan peerError)
+	pool := NewBlockPool(log.TestingLogger(), 1, requestsCh, errorsCh)
+
+	pool.SetPeerRange("staller", 1, 2)
+	pool.peers["staller"].observeLatency(10 * time.Millisecond)
+	now := time.Now()
+	requests := pool.schedule(now)
+	require.Equal(t, []BlockRequest{{1, "staller"}, {2, "staller"}}, requests)
+
+	// The requests stall on the peer, and are moved to another one once
+	// there is one.
+	pool.SetPeerRange("other", 1, 2)
+	require.Empty(t, pool.schedule(now.Add(minStallTimeout-time.Millisecond)))
+	requests = pool.schedule(now.Add(minStallTimeout))
+	require.Equal(t, []BlockRequest{{1, "other"}, {2, "other"}}, requests)
+
+	staller := pool.peers["staller"]
+	require.EqualValues(t, 0, staller.numPending)
+	require.EqualValues(t, maxPendingRequestsPerPeer/4, staller.maxPending)
+	require.Equal(t, 2, staller.stalls)
+	require.Greater(t, staller.latency, 10*time.Millisecond)
+
+	// The late block of the staller is still accepted, and the block of the
+	// other peer then ignored.
+	pool.AddBlock("staller", &types.Block{Header: types.Header{Height: 1}}, 100)
+	pool.AddBlock("other", &types.Block{Header: types.Header{Height: 1}}, 100)
+	pool.AddBlock("other", &types.Block{Header: types.Header{Height: 2}}, 100)
+	first, second := pool.PeekTwoBlocks()
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	require.EqualValues(t, "staller", pool.PopRequest())
+	require.EqualValues(t, "other", pool.PopRequest())
+
+	other := pool.peers["other"]
+	require.EqualValues(t, 0, other.numPending)
+	require.Zero(t, other.stalls)
+	_, numPending, _ := pool.GetStatus()
+	require.Zero(t, numPending)
+}
+
+func TestBlockPoolRemovesStallingPeer(t *testing.T) {
+	requestsCh := make(chan BlockRequest)
+	errorsCh := make(chan peerError)
+	pool := NewBlockPool(log.TestingLogger(), 1, requestsCh, errorsCh)
+
+	pool.SetPeerRange("staller", 1, 10)
+	pool.peers["staller"].observeLatency(10 * time.Millisecond)
+	now := time.Now()
+	require.Len(t, pool.schedule(now), 10)
+
+	// With no other peer, the stalled requests are sent to the peer again,
+	// within its lowered limit, until it stalled too many of them.
+	for _, request := range pool.schedule(now.Add(minStallTimeout)) {
+		require.EqualValues(t, "staller", request.PeerID)
+	}
+	require.True(t, pool.peers["staller"].didTimeout)
+
+	pool.removeTimedoutPeers()
+	require.Empty(t, pool.peers)
+	_, numPending, _ := pool.GetStatus()
+	require.EqualValues(t, 10, numPending)
+}
@@ -1,6 +1,7 @@
 package blocksync
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -9,8 +10,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/features"
 	"github.com/tendermint/tendermint/internal/p2p"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/store"
@@ -22,6 +25,12 @@ import (
 
 var _ service.Service = (*Reactor)(nil)
 
+// FlagVerifyAhead gates the verification of the queued blocks on a worker
+// pool, ahead of the apply loop. When it is disabled, each block is verified
+// by the apply loop.
+var FlagVerifyAhead = features.Declare("blocksync.verify-ahead",
+	"Verify the queued blocks on a worker pool, ahead of the apply loop.", features.Beta, true)
+
 const (
 	// BlockSyncChannel is a channel for blocks and status updates
 	BlockSyncChannel = p2p.ChannelID(0x40)
@@ -53,6 +62,10 @@ type consensusReactor interface {
 	// For when we switch from block sync reactor to the consensus
 	// machine.
 	SwitchToConsensus(ctx context.Context, state sm.State, skipWAL bool)
+
+	// For when consensus falls too far behind and we switch back to block
+	// sync. Consensus is paused until SwitchToConsensus is called again.
+	SwitchToBlockSync(ctx context.Context) error
 }
 
 type peerError struct {
@@ -68,16 +81,21 @@ func (e peerError) Error() string {
 type Reactor struct {
 	service.BaseService
 	logger log.Logger
+	cfg    *config.BlockSyncConfig
 
 	// immutable
 	initialState sm.State
 
 	blockExec   *sm.BlockExecutor
 	store       *store.BlockStore
-	pool        *BlockPool
 	consReactor consensusReactor
 	blockSync   *atomicBool
 
+	// pool is replaced by a new one when switching back to block sync, see
+	// getPool().
+	poolMtx sync.RWMutex
+	pool    *BlockPool
+
 	blockSyncCh *p2p.Channel
 	// blockSyncOutBridgeCh defines a channel that acts as a bridge between sending Envelope
 	// messages that the reactor will consume in processBlockSyncCh and receiving messages
@@ -89,8 +107,8 @@ type Reactor struct {
 	blockSyncOutBridgeCh chan p2p.Envelope
 	peerUpdates          *p2p.PeerUpdates
 
-	requestsCh <-chan BlockRequest
-	errorsCh   <-chan peerError
+	requestsCh chan BlockRequest
+	errorsCh   chan peerError
 
 	// poolWG is used to synchronize the graceful shutdown of the poolRoutine and
 	// requestRoutine spawned goroutines when stopping the reactor and before
@@ -100,6 +118,12 @@ type Reactor struct {
 	metrics  *consensus.Metrics
 	eventBus *eventbus.EventBus
 
+	// peerStats, if set, is credited with the blocks delivered by each peer.
+	peerStats *p2p.PeerStatsStore
+
+	// features are the feature flags of the node, or the defaults if nil.
+	features *features.Set
+
 	syncStartTime time.Time
 }
 
@@ -107,6 +131,7 @@ type Reactor struct {
 func NewReactor(
 	ctx context.Context,
 	logger log.Logger,
+	cfg *config.BlockSyncConfig,
 	state sm.State,
 	blockExec *sm.BlockExecutor,
 	store *store.BlockStore,
@@ -137,6 +162,7 @@ func NewReactor(
 
 	r := &Reactor{
 		logger:               logger,
+		cfg:                  cfg,
 		initialState:         state,
 		blockExec:            blockExec,
 		store:                store,
@@ -166,14 +192,15 @@ func NewReactor(
 // goroutine. If the pool fails to start, an error is returned.
 func (r *Reactor) OnStart(ctx context.Context) error {
 	if r.blockSync.IsSet() {
-		if err := r.pool.Start(ctx); err != nil {
+		pool := r.getPool()
+		if err := pool.Start(ctx); err != nil {
 			return err
 		}
 		r.poolWG.Add(1)
 		go r.requestRoutine(ctx)
 
 		r.poolWG.Add(1)
-		go r.poolRoutine(ctx, false)
+		go r.poolRoutine(ctx, pool, false)
 	}
 
 	go r.processBlockSyncCh(ctx)
@@ -187,7 +214,7 @@ func (r *Reactor) OnStart(ctx context.Context) error {
 // blocking until they all exit.
 func (r *Reactor) OnStop() {
 	if r.blockSync.IsSet() {
-		if err := r.pool.Stop(); err != nil {
+		if err := r.getPool().Stop(); err != nil {
 			r.logger.Error("failed to stop pool", "err", err)
 		}
 	}
@@ -237,7 +264,7 @@ func (r *Reactor) handleBlockSyncMessage(ctx context.Context, envelope *p2p.Enve
 			return err
 		}
 
-		r.pool.AddBlock(envelope.From, block, block.Size())
+		r.getPool().AddBlock(envelope.From, block, block.Size())
 
 	case *bcproto.StatusRequest:
 		return r.blockSyncCh.Send(ctx, p2p.Envelope{
@@ -248,7 +275,7 @@ func (r *Reactor) handleBlockSyncMessage(ctx context.Context, envelope *p2p.Enve
 			},
 		})
 	case *bcproto.StatusResponse:
-		r.pool.SetPeerRange(envelope.From, msg.Base, msg.Height)
+		r.getPool().SetPeerRange(envelope.From, msg.Base, msg.Height)
 
 	case *bcproto.NoBlockResponse:
 		logger.Debug("peer does not have the requested block", "height", msg.Height)
@@ -347,7 +374,7 @@ func (r *Reactor) processPeerUpdate(peerUpdate p2p.PeerUpdate) {
 		}
 
 	case p2p.PeerStatusDown:
-		r.pool.RemovePeer(peerUpdate.NodeID)
+		r.getPool().RemovePeer(peerUpdate.NodeID)
 	}
 }
 
@@ -370,9 +397,10 @@ func (r *Reactor) processPeerUpdates(ctx context.Context) {
 func (r *Reactor) SwitchToBlockSync(ctx context.Context, state sm.State) error {
 	r.blockSync.Set()
 	r.initialState = state
-	r.pool.height = state.LastBlockHeight + 1
+	pool := r.getPool()
+	pool.height = state.LastBlockHeight + 1
 
-	if err := r.pool.Start(ctx); err != nil {
+	if err := pool.Start(ctx); err != nil {
 		return err
 	}
 
@@ -382,11 +410,103 @@ func (r *Reactor) SwitchToBlockSync(ctx context.Context, state sm.State) error {
 	go r.requestRoutine(ctx)
 
 	r.poolWG.Add(1)
-	go r.poolRoutine(ctx, true)
+	go r.poolRoutine(ctx, pool, true)
 
 	return nil
 }
 
+// getPool returns the current block pool.
+func (r *Reactor) getPool() *BlockPool {
+	r.poolMtx.RLock()
+	defer r.poolMtx.RUnlock()
+	return r.pool
+}
+
+// reentryRoutine watches how far behind the highest peer the node is after
+// block sync handed over to consensus, and switches back to block sync if it
+// stays more than ReentryLag heights behind for ReentryDelay, unless the lag
+// shrinks fast enough for consensus to catch up within another ReentryDelay.
+func (r *Reactor) reentryRoutine(ctx context.Context) {
+	defer r.poolWG.Done()
+
+	ticker := time.NewTicker(switchToConsensusIntervalSeconds * time.Second)
+	defer ticker.Stop()
+
+	tracker := newLagTracker(r.cfg.ReentryDelay)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// The pool keeps tracking the heights of peers after the switch.
+		pool := r.getPool()
+		lag := pool.lag(r.store.Height() + 1)
+		if lag <= r.cfg.ReentryLag {
+			tracker.reset()
+			continue
+		}
+		now := time.Now()
+		tracker.observe(now, lag)
+		if !tracker.shouldReenter(now) {
+			continue
+		}
+
+		r.logger.Info("fell behind; switching back to block sync",
+			"height", r.store.Height(),
+			"max_peer_height", pool.MaxPeerHeight(),
+			"lag", lag,
+			"catch_up_rate", tracker.catchUpRate(),
+		)
+		if err := r.switchBackToBlockSync(ctx, pool); err != nil {
+			r.logger.Error("failed to switch back to block sync", "err", err)
+			tracker.reset()
+			continue
+		}
+		return
+	}
+}
+
+// switchBackToBlockSync pauses consensus and restarts block sync with a new
+// pool, seeded with the peers known to the old one.
+func (r *Reactor) switchBackToBlockSync(ctx context.Context, old *BlockPool) error {
+	if err := r.consReactor.SwitchToBlockSync(ctx); err != nil {
+		return err
+	}
+
+	// Consensus is paused between heights, so the stored state is final.
+	state, err := r.blockExec.Store().Load()
+	if err != nil {
+		return err
+	}
+	if state.LastBlockHeight != r.store.Height() {
+		return fmt.Errorf("state (%v) and store (%v) height mismatch", state.LastBlockHeight, r.store.Height())
+	}
+
+	pool := NewBlockPool(r.logger, state.LastBlockHeight+1, r.requestsCh, r.errorsCh)
+	old.copyPeersTo(pool)
+
+	r.poolMtx.Lock()
+	r.pool = pool
+	r.poolMtx.Unlock()
+
+	r.blockSync.Set()
+	r.initialState = state
+	r.syncStartTime = time.Now()
+	if err := pool.Start(ctx); err != nil {
+		return err
+	}
+
+	r.poolWG.Add(1)
+	go r.poolRoutine(ctx, pool, false)
+
+	return r.PublishStatus(ctx, types.EventDataBlockSyncStatus{
+		Complete: false,
+		Height:   state.LastBlockHeight,
+	})
+}
+
 func (r *Reactor) requestRoutine(ctx context.Context) {
 	statusUpdateTicker := time.NewTicker(statusUpdateIntervalSeconds * time.Second)
 	defer statusUpdateTicker.Stop()
@@ -431,7 +551,7 @@ func (r *Reactor) requestRoutine(ctx context.Context) {
 // do.
 //
 // NOTE: Don't sleep in the FOR_LOOP or otherwise slow it down!
-func (r *Reactor) poolRoutine(ctx context.Context, stateSynced bool) {
+func (r *Reactor) poolRoutine(ctx context.Context, pool *BlockPool, stateSynced bool) {
 	var (
 		trySyncTicker           = time.NewTicker(trySyncIntervalMS * time.Millisecond)
 		switchToConsensusTicker = time.NewTicker(switchToConsensusIntervalSeconds * time.Second)
@@ -452,13 +572,22 @@ func (r *Reactor) poolRoutine(ctx context.Context, stateSynced bool) {
 
 	defer r.poolWG.Done()
 
+	verifyCtx, cancelVerify := context.WithCancel(ctx)
+	defer cancelVerify()
+
+	verifyAhead := r.features.Enabled(FlagVerifyAhead)
+	verifier := newBlockVerifier(chainID)
+	if verifyAhead {
+		verifier.start(verifyCtx, 0)
+	}
+
 FOR_LOOP:
 	for {
 		select {
 		case <-switchToConsensusTicker.C:
 			var (
-				height, numPending, lenRequesters = r.pool.GetStatus()
-				lastAdvance                       = r.pool.LastAdvance()
+				height, numPending, lenRequesters = pool.GetStatus()
+				lastAdvance                       = pool.LastAdvance()
 			)
 
 			r.logger.Debug(
@@ -469,7 +598,7 @@ FOR_LOOP:
 			)
 
 			switch {
-			case r.pool.IsCaughtUp():
+			case pool.isCaughtUpWithin(r.cfg.SwitchToConsensusLag):
 				r.logger.Info("switching to consensus reactor", "height", height)
 
 			case time.Since(lastAdvance) > syncTimeout:
@@ -479,13 +608,13 @@ FOR_LOOP:
 				r.logger.Info(
 					"not caught up yet",
 					"height", height,
-					"max_peer_height", r.pool.MaxPeerHeight(),
+					"max_peer_height", pool.MaxPeerHeight(),
 					"timeout_in", syncTimeout-time.Since(lastAdvance),
 				)
 				continue
 			}
 
-			if err := r.pool.Stop(); err != nil {
+			if err := pool.Stop(); err != nil {
 				r.logger.Error("failed to stop pool", "err", err)
 			}
 
@@ -493,6 +622,11 @@ FOR_LOOP:
 
 			if r.consReactor != nil {
 				r.consReactor.SwitchToConsensus(ctx, state, blocksSynced > 0 || stateSynced)
+
+				if r.cfg.ReentryLag > 0 {
+					r.poolWG.Add(1)
+					go r.reentryRoutine(ctx)
+				}
 			}
 
 			break FOR_LOOP
@@ -514,7 +648,7 @@ FOR_LOOP:
 			// TODO: Uncouple from request routine.
 
 			// see if there are any blocks to sync
-			first, second := r.pool.PeekTwoBlocks()
+			first, second := pool.PeekTwoBlocks()
 			if first == nil || second == nil {
 				// we need both to sync the first block
 				continue FOR_LOOP
@@ -523,26 +657,27 @@ FOR_LOOP:
 				didProcessCh <- struct{}{}
 			}
 
-			firstParts, err := first.MakePartSet(types.BlockPartSizeBytes)
-			if err != nil {
+			// Keep the verification workers busy with the blocks queued behind
+			// this one before blocking on it.
+			if verifyAhead {
+				r.scheduleVerification(pool, verifier, state)
+			}
+
+			// Finally, verify the first block using the second's commit.
+			res := verifier.verify(ctx, first, second, state.Validators)
+			if res.parts == nil {
 				r.logger.Error("failed to make ",
 					"height", first.Height,
-					"err", err.Error())
+					"err", res.err.Error())
 				break FOR_LOOP
 			}
 
 			var (
-				firstPartSetHeader = firstParts.Header()
-				firstID            = types.BlockID{Hash: first.Hash(), PartSetHeader: firstPartSetHeader}
+				firstParts = res.parts
+				firstID    = res.blockID
 			)
 
-			// Finally, verify the first block using the second's commit.
-			//
-			// NOTE: We can probably make this more efficient, but note that calling
-			// first.Hash() doesn't verify the tx contents, so MakePartSet() is
-			// currently necessary.
-			if err = state.Validators.VerifyCommitLight(chainID, firstID, first.Height, second.LastCommit); err != nil {
-				err = fmt.Errorf("invalid last commit: %w", err)
+			if err := res.err; err != nil {
 				r.logger.Error(
 					err.Error(),
 					"last_commit", second.LastCommit,
@@ -552,7 +687,7 @@ FOR_LOOP:
 
 				// NOTE: We've already removed the peer's request, but we still need
 				// to clean up the rest.
-				peerID := r.pool.RedoRequest(first.Height)
+				peerID := pool.RedoRequest(first.Height)
 				if serr := r.blockSyncCh.SendError(ctx, p2p.PeerError{
 					NodeID: peerID,
 					Err:    err,
@@ -560,7 +695,7 @@ FOR_LOOP:
 					break FOR_LOOP
 				}
 
-				peerID2 := r.pool.RedoRequest(second.Height)
+				peerID2 := pool.RedoRequest(second.Height)
 				if peerID2 != peerID {
 					if serr := r.blockSyncCh.SendError(ctx, p2p.PeerError{
 						NodeID: peerID2,
@@ -572,7 +707,11 @@ FOR_LOOP:
 
 				continue FOR_LOOP
 			} else {
-				r.pool.PopRequest()
+				peerID := pool.PopRequest()
+				verifier.prune(first.Height + 1)
+				if r.peerStats != nil {
+					r.peerStats.RecordBlockDelivered(peerID)
+				}
 
 				// TODO: batch saves so we do not persist to disk every block
 				r.store.SaveBlock(first, firstParts, second.LastCommit)
@@ -595,8 +734,8 @@ FOR_LOOP:
 					lastRate = 0.9*lastRate + 0.1*(100/time.Since(lastHundred).Seconds())
 					r.logger.Info(
 						"block sync rate",
-						"height", r.pool.height,
-						"max_peer_height", r.pool.MaxPeerHeight(),
+						"height", pool.height,
+						"max_peer_height", pool.MaxPeerHeight(),
 						"blocks/s", lastRate,
 					)
 
@@ -608,14 +747,59 @@ FOR_LOOP:
 
 		case <-ctx.Done():
 			break FOR_LOOP
-		case <-r.pool.exitedCh:
+		case <-pool.exitedCh:
 			break FOR_LOOP
 		}
 	}
 }
 
+// scheduleVerification queues the verification of the blocks received ahead
+// of the pool height. The validator set that will verify a block is not known
+// until the blocks before it are applied, so only blocks whose header claims
+// the current or next validator set of state are scheduled.
+func (r *Reactor) scheduleVerification(pool *BlockPool, verifier *blockVerifier, state sm.State) {
+	blocks := pool.PeekBlocks(verifyAheadBlocks + 1)
+	if len(blocks) < 2 {
+		return
+	}
+
+	var (
+		valsHash     = state.Validators.Hash()
+		nextValsHash = state.NextValidators.Hash()
+	)
+
+	for i := 0; i < len(blocks)-1; i++ {
+		first, second := blocks[i], blocks[i+1]
+		switch {
+		case bytes.Equal(first.ValidatorsHash, valsHash):
+			verifier.schedule(first, second, state.Validators, valsHash)
+		case bytes.Equal(first.ValidatorsHash, nextValsHash):
+			verifier.schedule(first, second, state.NextValidators, nextValsHash)
+		}
+	}
+}
+
+// SetFeatures sets the feature flags of the node. It must be called before the
+// reactor is started.
+func (r *Reactor) SetFeatures(set *features.Set) {
+	r.features = set
+}
+
+// SetPeerStats sets the store credited with the blocks delivered by peers
+// during block sync. It must be called before the reactor is started.
+func (r *Reactor) SetPeerStats(stats *p2p.PeerStatsStore) {
+	r.peerStats = stats
+}
+
+// SetPeerMaintenance sets the tracker of announced peer maintenance windows,
+// used to avoid requesting blocks from peers about to go down. It must be
+// called before the reactor is started.
+func (r *Reactor) SetPeerMaintenance(maintenance *p2p.PeerMaintenance) {
+	r.getPool().maintenance = maintenance
+}
+
 func (r *Reactor) GetMaxPeerBlockHeight() int64 {
-	return r.pool.MaxPeerHeight()
+	return r.getPool().MaxPeerHeight()
 }
 
 func (r *Reactor) GetTotalSyncedTime() time.Duration {
@@ -630,9 +814,10 @@ func (r *Reactor) GetRemainingSyncTime() time.Duration {
 		return time.Duration(0)
 	}
 
-	targetSyncs := r.pool.targetSyncBlocks()
-	currentSyncs := r.store.Height() - r.pool.startHeight + 1
-	lastSyncRate := r.pool.getLastSyncRate()
+	pool := r.getPool()
+	targetSyncs := pool.targetSyncBlocks()
+	currentSyncs := r.store.Height() - pool.startHeight + 1
+	lastSyncRate := pool.getLastSyncRate()
 	if currentSyncs < 0 || lastSyncRate < 0.001 {
 		return time.Duration(0)
 	}
@@ -174,6 +174,7 @@ func (rts *reactorTestSuite) addNode(
 	rts.reactors[nodeID], err = NewReactor(
 		ctx,
 		rts.logger.With("nodeID", nodeID),
+		config.TestBlockSyncConfig(),
 		state.Copy(),
 		blockExec,
 		blockStore,
@@ -238,6 +239,46 @@ func TestReactor_AbruptDisconnect(t *testing.T) {
 	rts.network.Nodes[rts.nodes[1]].PeerManager.Disconnected(ctx, rts.nodes[0])
 }
 
+func TestReactor_AbruptDisconnectMidSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg, err := config.ResetTestRoot("block_sync_reactor_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cfg.RootDir)
+
+	genDoc, privVals := factory.RandGenesisDoc(ctx, t, cfg, 1, false, 30)
+	maxBlockHeight := int64(512)
+
+	rts := setup(ctx, t, genDoc, privVals[0], []int64{maxBlockHeight, 0}, 0)
+
+	require.Equal(t, maxBlockHeight, rts.reactors[rts.nodes[0]].store.Height())
+
+	rts.start(ctx, t)
+
+	secondaryPool := rts.reactors[rts.nodes[1]].pool
+
+	require.Eventually(
+		t,
+		func() bool {
+			height, _, _ := secondaryPool.GetStatus()
+			return secondaryPool.MaxPeerHeight() > 0 && height > 0 && height < maxBlockHeight-1
+		},
+		10*time.Second,
+		time.Millisecond,
+		"expected node to be partially synced",
+	)
+
+	// Remove the synced node while the window of requests scheduled on it is
+	// in flight, which should not result in any deadlocks or race conditions
+	// between the scheduler of the pool and poolRoutine.
+	rts.peerChans[rts.nodes[1]] <- p2p.PeerUpdate{
+		Status: p2p.PeerStatusDown,
+		NodeID: rts.nodes[0],
+	}
+	rts.network.Nodes[rts.nodes[1]].PeerManager.Disconnected(ctx, rts.nodes[0])
+}
+
 func TestReactor_SyncTime(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
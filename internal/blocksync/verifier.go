@@ -0,0 +1,170 @@
+package blocksync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// verifyAheadBlocks is the maximum number of blocks, counted from the pool
+// height, that are verified ahead of the apply loop.
+const verifyAheadBlocks = 64
+
+// verifyResult is the outcome of verifying a block with the commit of the
+// block following it.
+type verifyResult struct {
+	parts   *types.PartSet
+	blockID types.BlockID
+
+	// err is set if the block could not be verified. If parts is nil, the
+	// block could not be split into parts, which is not the peer's fault.
+	err error
+}
+
+type verifyJob struct {
+	first, second *types.Block
+	valSet        *types.ValidatorSet
+	valSetHash    []byte
+
+	done   chan struct{}
+	result verifyResult
+}
+
+// matches returns true if the job verifies first against the commit in second
+// using a validator set with the given hash.
+func (j *verifyJob) matches(first, second *types.Block, valSetHash []byte) bool {
+	return j.first == first && j.second == second && bytes.Equal(j.valSetHash, valSetHash)
+}
+
+// blockVerifier runs the stateless checks of block sync - ValidateBasic and
+// the commit signature verification - on a pool of workers, ahead of the
+// single-threaded apply loop. Results are keyed by height and only used if
+// the blocks and validator set the apply loop ends up with are the ones that
+// were verified, so a stale or mispredicted job just costs some CPU.
+type blockVerifier struct {
+	chainID string
+	jobs    chan *verifyJob
+
+	mtx     sync.Mutex
+	pending map[int64]*verifyJob
+}
+
+func newBlockVerifier(chainID string) *blockVerifier {
+	return &blockVerifier{
+		chainID: chainID,
+		jobs:    make(chan *verifyJob, verifyAheadBlocks),
+		pending: make(map[int64]*verifyJob),
+	}
+}
+
+// start spawns the given number of workers, or one per CPU if workers is not
+// positive. The workers exit when ctx is canceled.
+func (bv *blockVerifier) start(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case job := <-bv.jobs:
+					job.result = verifyBlock(bv.chainID, job.first, job.second, job.valSet)
+					close(job.done)
+				}
+			}
+		}()
+	}
+}
+
+// schedule queues the verification of first against the commit in second
+// using valSet, unless an identical job is already pending. It never blocks;
+// if the queue is full the job is dropped and the apply loop verifies the
+// block itself.
+func (bv *blockVerifier) schedule(first, second *types.Block, valSet *types.ValidatorSet, valSetHash []byte) {
+	bv.mtx.Lock()
+	defer bv.mtx.Unlock()
+
+	if job, ok := bv.pending[first.Height]; ok && job.matches(first, second, valSetHash) {
+		return
+	}
+
+	// The validator set is copied since verification lazily caches values in
+	// it, which must not race with the apply loop or other workers.
+	job := &verifyJob{
+		first:      first,
+		second:     second,
+		valSet:     valSet.Copy(),
+		valSetHash: valSetHash,
+		done:       make(chan struct{}),
+	}
+
+	select {
+	case bv.jobs <- job:
+		bv.pending[first.Height] = job
+	default:
+	}
+}
+
+// verify returns the result of verifying first against the commit in second
+// using valSet, waiting for a matching scheduled job if there is one and
+// verifying synchronously otherwise.
+func (bv *blockVerifier) verify(
+	ctx context.Context,
+	first, second *types.Block,
+	valSet *types.ValidatorSet,
+) verifyResult {
+	bv.mtx.Lock()
+	job, ok := bv.pending[first.Height]
+	bv.mtx.Unlock()
+
+	if ok && job.matches(first, second, valSet.Hash()) {
+		select {
+		case <-job.done:
+			return job.result
+		case <-ctx.Done():
+			return verifyResult{err: ctx.Err()}
+		}
+	}
+
+	return verifyBlock(bv.chainID, first, second, valSet)
+}
+
+// prune discards the jobs for heights below height.
+func (bv *blockVerifier) prune(height int64) {
+	bv.mtx.Lock()
+	defer bv.mtx.Unlock()
+
+	for h := range bv.pending {
+		if h < height {
+			delete(bv.pending, h)
+		}
+	}
+}
+
+// verifyBlock checks first and verifies it using the commit in second.
+func verifyBlock(chainID string, first, second *types.Block, valSet *types.ValidatorSet) verifyResult {
+	parts, err := first.MakePartSet(types.BlockPartSizeBytes)
+	if err != nil {
+		return verifyResult{err: err}
+	}
+
+	if err := first.ValidateBasic(); err != nil {
+		return verifyResult{parts: parts, err: fmt.Errorf("invalid block: %w", err)}
+	}
+
+	// NOTE: We can probably make this more efficient, but note that calling
+	// first.Hash() doesn't verify the tx contents, so MakePartSet() is
+	// currently necessary.
+	blockID := types.BlockID{Hash: first.Hash(), PartSetHeader: parts.Header()}
+	if err := valSet.VerifyCommitLight(chainID, blockID, first.Height, second.LastCommit); err != nil {
+		return verifyResult{parts: parts, blockID: blockID, err: fmt.Errorf("invalid last commit: %w", err)}
+	}
+
+	return verifyResult{parts: parts, blockID: blockID}
+}
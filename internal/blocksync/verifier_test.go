@@ -0,0 +1,76 @@
+package blocksync
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	sm "github.com/tendermint/tendermint/internal/state"
+	sf "github.com/tendermint/tendermint/internal/state/test/factory"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestBlockVerifier(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg, err := config.ResetTestRoot("block_sync_verifier_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cfg.RootDir)
+
+	genDoc, privVals := factory.RandGenesisDoc(ctx, t, cfg, 1, false, 30)
+	state, err := sm.MakeGenesisState(genDoc)
+	require.NoError(t, err)
+
+	first, err := sf.MakeBlock(state, 1, types.NewCommit(0, 0, types.BlockID{}, nil))
+	require.NoError(t, err)
+	firstParts, err := first.MakePartSet(types.BlockPartSizeBytes)
+	require.NoError(t, err)
+	firstID := types.BlockID{Hash: first.Hash(), PartSetHeader: firstParts.Header()}
+
+	makeSecond := func(blockID types.BlockID) *types.Block {
+		vote, err := factory.MakeVote(ctx, privVals[0], genDoc.ChainID, 0, 1, 0, 2, blockID, time.Now())
+		require.NoError(t, err)
+		commit := types.NewCommit(vote.Height, vote.Round, blockID, []types.CommitSig{vote.CommitSig()})
+		second, err := sf.MakeBlock(state, 2, commit)
+		require.NoError(t, err)
+		return second
+	}
+
+	verifier := newBlockVerifier(genDoc.ChainID)
+	verifier.start(ctx, 2)
+
+	// a valid block is verified ahead of time
+	second := makeSecond(firstID)
+	valsHash := state.Validators.Hash()
+	verifier.schedule(first, second, state.Validators, valsHash)
+	require.Contains(t, verifier.pending, first.Height)
+
+	res := verifier.verify(ctx, first, second, state.Validators)
+	require.NoError(t, res.err)
+	require.Equal(t, firstID, res.blockID)
+	require.Equal(t, firstParts.Header(), res.parts.Header())
+
+	// a commit for another block is rejected, whether scheduled or not
+	badSecond := makeSecond(factory.MakeBlockID())
+	verifier.schedule(first, badSecond, state.Validators, valsHash)
+	res = verifier.verify(ctx, first, badSecond, state.Validators)
+	require.Error(t, res.err)
+	require.NotNil(t, res.parts)
+
+	verifier.prune(first.Height + 1)
+	require.Empty(t, verifier.pending)
+	res = verifier.verify(ctx, first, badSecond, state.Validators)
+	require.Error(t, res.err)
+
+	// a scheduled job is ignored if the validator set does not match
+	otherVals, _ := factory.RandValidatorSet(ctx, t, 1, 10)
+	verifier.schedule(first, second, otherVals, otherVals.Hash())
+	res = verifier.verify(ctx, first, second, state.Validators)
+	require.NoError(t, res.err)
+}
@@ -203,6 +203,7 @@ func TestByzantinePrevoteEquivocation(t *testing.T) {
 		proposerAddr := lazyNodeState.privValidatorPubKey.Address()
 
 		block, blockParts, err := lazyNodeState.blockExec.CreateProposalBlock(
+			ctx,
 			lazyNodeState.Height, lazyNodeState.state, commit, proposerAddr,
 		)
 		require.NoError(t, err)
@@ -161,6 +161,38 @@ func signVote(
 	return v
 }
 
+// signExtendedPrecommit signs the precommit of vs for blockID carrying
+// extension.
+func signExtendedPrecommit(
+	ctx context.Context,
+	t *testing.T,
+	cfg *config.Config,
+	vs *validatorStub,
+	height int64,
+	round int32,
+	blockID types.BlockID,
+	extension []byte,
+) *types.Vote {
+	t.Helper()
+	pubKey, err := vs.GetPubKey(ctx)
+	require.NoError(t, err)
+	vote := &types.Vote{
+		Type:             tmproto.PrecommitType,
+		Height:           height,
+		Round:            round,
+		BlockID:          blockID,
+		Timestamp:        tmtime.Now(),
+		ValidatorAddress: pubKey.Address(),
+		ValidatorIndex:   vs.Index,
+		Extension:        extension,
+	}
+	v := vote.ToProto()
+	require.NoError(t, vs.SignVote(ctx, cfg.ChainID(), v))
+	vote.Signature = v.Signature
+	vote.Timestamp = v.Timestamp
+	return vote
+}
+
 func signVotes(
 	ctx context.Context,
 	t *testing.T,
@@ -237,7 +269,7 @@ func decideProposal(
 	t.Helper()
 
 	cs1.mtx.Lock()
-	block, blockParts, err := cs1.createProposalBlock()
+	block, blockParts, err := cs1.createProposalBlock(ctx)
 	require.NoError(t, err)
 	validRound := cs1.ValidRound
 	chainID := cs1.state.ChainID
@@ -399,7 +431,7 @@ func newState(
 
 func newStateWithConfig(
 	ctx context.Context,
-	t *testing.T,
+	t testing.TB,
 	logger log.Logger,
 	thisConfig *config.Config,
 	state sm.State,
@@ -412,7 +444,7 @@ func newStateWithConfig(
 
 func newStateWithConfigAndBlockStore(
 	ctx context.Context,
-	t *testing.T,
+	t testing.TB,
 	logger log.Logger,
 	thisConfig *config.Config,
 	state sm.State,
@@ -0,0 +1,267 @@
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// forkDetectorWindow is the number of heights, below the highest reported,
+// whose reports are retained.
+const forkDetectorWindow = 100
+
+// ForkDetector compares the blocks the peers report to commit, in the
+// NewValidBlock messages they broadcast at the commit step, with each other
+// and with the blocks committed by the node, to detect the peers on another
+// branch: a fork of the chain, or a clone of the network with the same chain
+// ID. The blocks are compared by their part set headers, which identify them
+// as well as their hashes.
+//
+// The peers which committed another block than the node can be quarantined:
+// the block committed by the node is final, whatever the majority of the
+// peers reports.
+type ForkDetector struct {
+	logger     log.Logger
+	metrics    *Metrics
+	blockStore sm.BlockStore
+
+	// quarantine, if not nil, is called with the peers contradicting the
+	// node.
+	quarantine func(types.NodeID, error)
+
+	mtx         sync.Mutex
+	reports     map[int64]map[types.NodeID]types.PartSetHeader
+	local       map[int64]types.PartSetHeader // the blocks committed by the node
+	forks       map[int64]types.EventDataForkDetected
+	quarantined map[types.NodeID]bool
+	maxHeight   int64
+}
+
+// NewForkDetector returns a fork detector loading the blocks of the node from
+// blockStore. If quarantine is not nil, it is called with the peers which
+// committed another block than the node, once each.
+func NewForkDetector(
+	logger log.Logger,
+	blockStore sm.BlockStore,
+	metrics *Metrics,
+	quarantine func(types.NodeID, error),
+) *ForkDetector {
+	return &ForkDetector{
+		logger:      logger,
+		metrics:     metrics,
+		blockStore:  blockStore,
+		quarantine:  quarantine,
+		reports:     make(map[int64]map[types.NodeID]types.PartSetHeader),
+		local:       make(map[int64]types.PartSetHeader),
+		forks:       make(map[int64]types.EventDataForkDetected),
+		quarantined: make(map[types.NodeID]bool),
+	}
+}
+
+// ObservePeer records that the peer committed the block with the part set
+// header psh at height. It returns the fork at height, and true, if the
+// branches at height changed.
+func (d *ForkDetector) ObservePeer(peerID types.NodeID, height int64, psh types.PartSetHeader) (types.EventDataForkDetected, bool) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if !d.observable(height) {
+		return types.EventDataForkDetected{}, false
+	}
+	peers, ok := d.reports[height]
+	if !ok {
+		peers = make(map[types.NodeID]types.PartSetHeader)
+		d.reports[height] = peers
+	}
+	if prev, ok := peers[peerID]; ok && prev.Equals(psh) {
+		return types.EventDataForkDetected{}, false
+	}
+	peers[peerID] = psh
+	return d.check(height)
+}
+
+// ObserveLocal records that the node committed the block with the part set
+// header psh at height. It returns the fork at height, and true, if the
+// branches at height changed.
+func (d *ForkDetector) ObserveLocal(height int64, psh types.PartSetHeader) (types.EventDataForkDetected, bool) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	if !d.observable(height) {
+		return types.EventDataForkDetected{}, false
+	}
+	if prev, ok := d.local[height]; ok && prev.Equals(psh) {
+		return types.EventDataForkDetected{}, false
+	}
+	d.local[height] = psh
+	return d.check(height)
+}
+
+// RemovePeer forgets the reports of the peer, once it is disconnected.
+func (d *ForkDetector) RemovePeer(peerID types.NodeID) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	for height, peers := range d.reports {
+		if _, ok := peers[peerID]; !ok {
+			continue
+		}
+		delete(peers, peerID)
+		if _, ok := d.forks[height]; ok {
+			d.check(height)
+		}
+	}
+	d.updatePeersMetric()
+}
+
+// Forks returns the forks detected at the recent heights, lowest first.
+func (d *ForkDetector) Forks() []types.EventDataForkDetected {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	forks := make([]types.EventDataForkDetected, 0, len(d.forks))
+	for _, fork := range d.forks {
+		forks = append(forks, fork)
+	}
+	sort.Slice(forks, func(i, j int) bool { return forks[i].Height < forks[j].Height })
+	return forks
+}
+
+// observable reports whether the reports at height are retained, and prunes
+// those below the window. The caller must hold d.mtx.
+func (d *ForkDetector) observable(height int64) bool {
+	if height <= 0 || height <= d.maxHeight-forkDetectorWindow {
+		return false
+	}
+	if height <= d.maxHeight {
+		return true
+	}
+	d.maxHeight = height
+	for h := range d.reports {
+		if h <= height-forkDetectorWindow {
+			delete(d.reports, h)
+		}
+	}
+	for h := range d.local {
+		if h <= height-forkDetectorWindow {
+			delete(d.local, h)
+		}
+	}
+	for h := range d.forks {
+		if h <= height-forkDetectorWindow {
+			delete(d.forks, h)
+		}
+	}
+	d.updatePeersMetric()
+	return true
+}
+
+// check groups the blocks reported at height into branches, and updates the
+// fork at height. It returns the fork, and true, if there is one and its
+// branches changed. The caller must hold d.mtx.
+func (d *ForkDetector) check(height int64) (types.EventDataForkDetected, bool) {
+	local, hasLocal := d.local[height]
+	if !hasLocal && d.blockStore != nil {
+		if meta := d.blockStore.LoadBlockMeta(height); meta != nil {
+			local, hasLocal = meta.BlockID.PartSetHeader, true
+		}
+	}
+
+	var branches []types.ForkBranch
+	branchOf := func(psh types.PartSetHeader) *types.ForkBranch {
+		for i := range branches {
+			if branches[i].BlockParts.Equals(psh) {
+				return &branches[i]
+			}
+		}
+		branches = append(branches, types.ForkBranch{BlockParts: psh})
+		return &branches[len(branches)-1]
+	}
+	if hasLocal {
+		branchOf(local).Local = true
+	}
+	for peerID, psh := range d.reports[height] {
+		b := branchOf(psh)
+		b.Peers = append(b.Peers, peerID)
+	}
+
+	if len(branches) < 2 {
+		if _, ok := d.forks[height]; ok {
+			// The peers on the other branches disconnected.
+			delete(d.forks, height)
+			d.updatePeersMetric()
+		}
+		return types.EventDataForkDetected{}, false
+	}
+
+	size := func(b types.ForkBranch) int {
+		if b.Local {
+			return len(b.Peers) + 1
+		}
+		return len(b.Peers)
+	}
+	for i := range branches {
+		sort.Slice(branches[i].Peers, func(a, b int) bool { return branches[i].Peers[a] < branches[i].Peers[b] })
+	}
+	sort.Slice(branches, func(i, j int) bool {
+		if si, sj := size(branches[i]), size(branches[j]); si != sj {
+			return si > sj
+		}
+		return bytes.Compare(branches[i].BlockParts.Hash, branches[j].BlockParts.Hash) < 0
+	})
+	if size(branches[0]) > size(branches[1]) {
+		branches[0].Majority = true
+	}
+	fork := types.EventDataForkDetected{Height: height, Branches: branches}
+
+	if _, ok := d.forks[height]; !ok {
+		d.metrics.ForkHeights.Add(1)
+	}
+	d.forks[height] = fork
+	d.updatePeersMetric()
+	d.logger.Error("peers committed different blocks at the same height",
+		"height", height, "branches", len(branches), "majority", branches[0].Majority,
+		"local_on_majority", branches[0].Majority && branches[0].Local)
+
+	// Without a block of the node at height, there is no telling which branch
+	// is the right one.
+	if d.quarantine != nil && hasLocal {
+		for _, b := range branches {
+			if b.Local {
+				continue
+			}
+			for _, peerID := range b.Peers {
+				if d.quarantined[peerID] {
+					continue
+				}
+				d.quarantined[peerID] = true
+				d.logger.Error("quarantining peer on another branch", "peer", peerID, "height", height)
+				d.quarantine(peerID, fmt.Errorf("peer committed block %v at height %d, instead of %v",
+					b.BlockParts, height, local))
+			}
+		}
+	}
+	return fork, true
+}
+
+// updatePeersMetric sets the number of peers on another branch than the
+// majority. The caller must hold d.mtx.
+func (d *ForkDetector) updatePeersMetric() {
+	peers := make(map[types.NodeID]bool)
+	for _, fork := range d.forks {
+		for _, b := range fork.Branches {
+			if b.Majority {
+				continue
+			}
+			for _, peerID := range b.Peers {
+				peers[peerID] = true
+			}
+		}
+	}
+	d.metrics.ForkPeers.Set(float64(len(peers)))
+}
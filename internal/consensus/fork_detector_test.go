@@ -0,0 +1,82 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestForkDetector(t *testing.T) {
+	blockA := types.PartSetHeader{Total: 1, Hash: []byte("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")}
+	blockB := types.PartSetHeader{Total: 1, Hash: []byte("bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb")}
+
+	quarantined := make(map[types.NodeID]int)
+	d := NewForkDetector(log.TestingLogger(), nil, NopMetrics(), func(id types.NodeID, err error) {
+		require.Error(t, err)
+		quarantined[id]++
+	})
+
+	// The peers agreeing with the node are not a fork.
+	_, ok := d.ObserveLocal(10, blockA)
+	require.False(t, ok)
+	_, ok = d.ObservePeer("p1", 10, blockA)
+	require.False(t, ok)
+	assert.Empty(t, d.Forks())
+
+	// A single peer on another branch is the minority.
+	fork, ok := d.ObservePeer("p2", 10, blockB)
+	require.True(t, ok)
+	require.Len(t, fork.Branches, 2)
+	assert.Equal(t, int64(10), fork.Height)
+	assert.True(t, fork.Branches[0].Majority)
+	assert.True(t, fork.Branches[0].Local)
+	assert.Equal(t, []types.NodeID{"p1"}, fork.Branches[0].Peers)
+	assert.False(t, fork.Branches[1].Majority)
+	assert.Equal(t, []types.NodeID{"p2"}, fork.Branches[1].Peers)
+	assert.Equal(t, map[types.NodeID]int{"p2": 1}, quarantined)
+
+	// A report repeated doesn't change the fork.
+	_, ok = d.ObservePeer("p2", 10, blockB)
+	require.False(t, ok)
+
+	// Without a block of the node, no peer is quarantined.
+	_, ok = d.ObservePeer("p3", 11, blockB)
+	require.False(t, ok)
+	fork, ok = d.ObservePeer("p4", 11, blockA)
+	require.True(t, ok)
+	assert.False(t, fork.Branches[0].Majority)
+	_, ok = d.ObservePeer("p5", 11, blockB)
+	require.True(t, ok)
+	assert.Equal(t, map[types.NodeID]int{"p2": 1}, quarantined)
+	require.Len(t, d.Forks(), 2)
+
+	// Once the node commits a block, the peers contradicting it are
+	// quarantined, even on the majority branch, and the peers agreeing with
+	// it are not, even on a minority branch.
+	fork, ok = d.ObserveLocal(11, blockA)
+	require.True(t, ok)
+	assert.False(t, fork.Branches[0].Majority)
+	assert.Equal(t, map[types.NodeID]int{"p2": 1, "p3": 1, "p5": 1}, quarantined)
+	fork, ok = d.ObservePeer("p6", 11, blockB)
+	require.True(t, ok)
+	assert.True(t, fork.Branches[0].Majority)
+	assert.False(t, fork.Branches[0].Local)
+	assert.Equal(t, map[types.NodeID]int{"p2": 1, "p3": 1, "p5": 1, "p6": 1}, quarantined)
+
+	// The fork is forgotten once the peers on the other branch disconnect.
+	d.RemovePeer("p2")
+	forks := d.Forks()
+	require.Len(t, forks, 1)
+	assert.Equal(t, int64(11), forks[0].Height)
+
+	// The heights out of the window are discarded.
+	_, ok = d.ObservePeer("p1", 11+forkDetectorWindow, blockA)
+	require.False(t, ok)
+	assert.Empty(t, d.Forks())
+	_, ok = d.ObservePeer("p2", 10, blockB)
+	require.False(t, ok)
+}
@@ -0,0 +1,79 @@
+package consensus
+
+import (
+	"context"
+	"sync"
+
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	tmtime "github.com/tendermint/tendermint/libs/time"
+	"github.com/tendermint/tendermint/types"
+)
+
+// lockHistorySize is the number of recent lock changes kept by the State.
+const lockHistorySize = 100
+
+// lockHistory is a ring buffer of the most recent lock changes.
+type lockHistory struct {
+	mtx     sync.Mutex
+	changes []types.EventDataLockChange
+	next    int
+}
+
+func (h *lockHistory) add(change types.EventDataLockChange) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	if len(h.changes) < lockHistorySize {
+		h.changes = append(h.changes, change)
+		return
+	}
+	h.changes[h.next] = change
+	h.next = (h.next + 1) % lockHistorySize
+}
+
+// list returns the changes, oldest first.
+func (h *lockHistory) list() []types.EventDataLockChange {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+
+	out := make([]types.EventDataLockChange, 0, len(h.changes))
+	out = append(out, h.changes[h.next:]...)
+	return append(out, h.changes[:h.next]...)
+}
+
+// GetLockHistory returns the most recent changes of the locked and valid
+// blocks, oldest first.
+func (cs *State) GetLockHistory() []types.EventDataLockChange {
+	return cs.lockHistory.list()
+}
+
+// recordLockChange reports a change of the locked or valid block, made in the
+// given round of the current height. It must be called after the change.
+func (cs *State) recordLockChange(ctx context.Context, round int32, kind, reason string, blockHash tmbytes.HexBytes) {
+	change := types.EventDataLockChange{
+		Height:      cs.Height,
+		Round:       round,
+		Kind:        kind,
+		Reason:      reason,
+		BlockHash:   blockHash,
+		LockedRound: cs.LockedRound,
+		ValidRound:  cs.ValidRound,
+		Time:        tmtime.Now(),
+	}
+
+	cs.logger.Info("lock change",
+		"height", change.Height,
+		"round", change.Round,
+		"kind", kind,
+		"reason", reason,
+		"block_hash", blockHash,
+	)
+	cs.metrics.LockChanges.With("kind", kind, "reason", reason).Add(1)
+	cs.metrics.LockedRound.Set(float64(cs.LockedRound))
+	cs.metrics.ValidRound.Set(float64(cs.ValidRound))
+	cs.lockHistory.add(change)
+
+	if err := cs.eventBus.PublishEventLockChange(ctx, change); err != nil {
+		cs.logger.Error("failed publishing lock change", "err", err)
+	}
+}
@@ -0,0 +1,24 @@
+package consensus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestLockHistory(t *testing.T) {
+	var h lockHistory
+	require.Empty(t, h.list())
+
+	for i := 0; i < lockHistorySize+10; i++ {
+		h.add(types.EventDataLockChange{Height: int64(i)})
+	}
+
+	changes := h.list()
+	require.Len(t, changes, lockHistorySize)
+	for i, c := range changes {
+		require.EqualValues(t, i+10, c.Height)
+	}
+}
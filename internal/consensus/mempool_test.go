@@ -44,7 +44,7 @@ func TestMempoolNoProgressUntilTxsAvailable(t *testing.T) {
 	cs := newStateWithConfig(ctx, t, log.TestingLogger(), config, state, privVals[0], NewCounterApplication())
 	assertMempool(t, cs.txNotifier).EnableTxsAvailable()
 	height, round := cs.Height, cs.Round
-	newBlockCh := subscribe(ctx, t, cs.eventBus, types.EventQueryNewBlock)
+	newBlockCh := subscribeBuffered(ctx, t, cs.eventBus, types.EventQueryNewBlock, 2)
 	startTestRound(ctx, cs, height, round)
 
 	ensureNewEventOnChannel(t, newBlockCh) // first block gets committed
@@ -59,12 +59,27 @@ type Metrics struct {
 	// Whether or not a node is state syncing. 1 if yes, 0 if no.
 	StateSyncing metrics.Gauge
 
+	// Last height replayed into the app during the handshake.
+	ReplayHeight metrics.Gauge
+	// Number of blocks left to replay into the app during the handshake.
+	ReplayRemainingBlocks metrics.Gauge
+
 	// Number of blockparts transmitted by peer.
 	BlockParts metrics.Counter
+	// Number of votes and block parts received from a peer that we already
+	// had, by kind.
+	DuplicateMessages metrics.Counter
 
 	// Histogram of time taken per step annotated with reason that the step proceeded.
 	StepTime metrics.Histogram
 
+	// Number of changes of the locked and valid blocks, by kind and reason.
+	LockChanges metrics.Counter
+	// Round of the locked block, -1 if not locked.
+	LockedRound metrics.Gauge
+	// Round of the valid block, -1 if there is none.
+	ValidRound metrics.Gauge
+
 	// QuroumPrevoteMessageDelay is the interval in seconds between the proposal
 	// timestamp and the timestamp of the earliest prevote that achieved a quorum
 	// during the prevote step.
@@ -80,6 +95,12 @@ type Metrics struct {
 	// timestamp and the timestamp of the latest prevote in a round where 100%
 	// of the voting power on the network issued prevotes.
 	FullPrevoteMessageDelay metrics.Gauge
+
+	// Number of heights at which the peers reported different blocks.
+	ForkHeights metrics.Counter
+	// Number of connected peers which reported a block other than that of
+	// the majority, at a recent height.
+	ForkPeers metrics.Gauge
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -200,18 +221,54 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Name:      "state_syncing",
 			Help:      "Whether or not a node is state syncing. 1 if yes, 0 if no.",
 		}, labels).With(labelsAndValues...),
+		ReplayHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "replay_height",
+			Help:      "Last height replayed into the app during the handshake.",
+		}, labels).With(labelsAndValues...),
+		ReplayRemainingBlocks: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "replay_remaining_blocks",
+			Help:      "Number of blocks left to replay into the app during the handshake.",
+		}, labels).With(labelsAndValues...),
 		BlockParts: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_parts",
 			Help:      "Number of blockparts transmitted by peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
+		DuplicateMessages: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "duplicate_messages",
+			Help:      "Number of votes and block parts received from a peer that we already had.",
+		}, append(labels, "peer_id", "kind")).With(labelsAndValues...),
 		StepTime: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "step_time",
 			Help:      "Time spent per step.",
 		}, append(labels, "step", "reason")).With(labelsAndValues...),
+		LockChanges: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "lock_changes",
+			Help:      "Number of changes of the locked and valid blocks, by kind and reason.",
+		}, append(labels, "kind", "reason")).With(labelsAndValues...),
+		LockedRound: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "locked_round",
+			Help:      "Round of the locked block, -1 if not locked.",
+		}, labels).With(labelsAndValues...),
+		ValidRound: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "valid_round",
+			Help:      "Round of the valid block, -1 if there is none.",
+		}, labels).With(labelsAndValues...),
 		QuorumPrevoteMessageDelay: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
@@ -226,6 +283,18 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Help: "Difference in seconds between the proposal timestamp and the timestamp " +
 				"of the latest prevote that achieved 100% of the voting power in the prevote step.",
 		}, labels).With(labelsAndValues...),
+		ForkHeights: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "fork_heights",
+			Help:      "Number of heights at which the peers reported different blocks.",
+		}, labels).With(labelsAndValues...),
+		ForkPeers: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "fork_peers",
+			Help:      "Number of connected peers which reported a block other than that of the majority at a recent height.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
@@ -255,9 +324,17 @@ func NopMetrics() *Metrics {
 		CommittedHeight:           discard.NewGauge(),
 		BlockSyncing:              discard.NewGauge(),
 		StateSyncing:              discard.NewGauge(),
+		ReplayHeight:              discard.NewGauge(),
+		ReplayRemainingBlocks:     discard.NewGauge(),
 		BlockParts:                discard.NewCounter(),
+		DuplicateMessages:         discard.NewCounter(),
+		LockChanges:               discard.NewCounter(),
+		LockedRound:               discard.NewGauge(),
+		ValidRound:                discard.NewGauge(),
 		QuorumPrevoteMessageDelay: discard.NewGauge(),
 		FullPrevoteMessageDelay:   discard.NewGauge(),
+		ForkHeights:               discard.NewCounter(),
+		ForkPeers:                 discard.NewGauge(),
 	}
 }
 
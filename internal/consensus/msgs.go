@@ -32,6 +32,7 @@ func init() {
 	jsontypes.MustRegister(&HasVoteMessage{})
 	jsontypes.MustRegister(&VoteSetMaj23Message{})
 	jsontypes.MustRegister(&VoteSetBitsMessage{})
+	jsontypes.MustRegister(&GossipFeedbackMessage{})
 }
 
 // NewRoundStepMessage is sent for every step taken in the ConsensusState.
@@ -332,6 +333,32 @@ func (m *VoteSetBitsMessage) String() string {
 	return fmt.Sprintf("[VSB %v/%02d/%v %v %v]", m.Height, m.Round, m.Type, m.BlockID, m.Votes)
 }
 
+// GossipFeedbackMessage is sent periodically to a peer to report the
+// percentage of the votes and block parts it sent us recently that we already
+// had, so that it can tune its gossip to us.
+type GossipFeedbackMessage struct {
+	DuplicateVotesPct      uint32
+	DuplicateBlockPartsPct uint32
+}
+
+func (*GossipFeedbackMessage) TypeTag() string { return "tendermint/GossipFeedback" }
+
+// ValidateBasic performs basic validation.
+func (m *GossipFeedbackMessage) ValidateBasic() error {
+	if m.DuplicateVotesPct > 100 {
+		return fmt.Errorf("invalid DuplicateVotesPct %d, must be at most 100", m.DuplicateVotesPct)
+	}
+	if m.DuplicateBlockPartsPct > 100 {
+		return fmt.Errorf("invalid DuplicateBlockPartsPct %d, must be at most 100", m.DuplicateBlockPartsPct)
+	}
+	return nil
+}
+
+// String returns a string representation.
+func (m *GossipFeedbackMessage) String() string {
+	return fmt.Sprintf("[GossipFeedback V:%d%% BP:%d%%]", m.DuplicateVotesPct, m.DuplicateBlockPartsPct)
+}
+
 // MsgToProto takes a consensus message type and returns the proto defined
 // consensus message.
 //
@@ -456,6 +483,16 @@ func MsgToProto(msg Message) (*tmcons.Message, error) {
 			Sum: vsb,
 		}
 
+	case *GossipFeedbackMessage:
+		pb = tmcons.Message{
+			Sum: &tmcons.Message_GossipFeedback{
+				GossipFeedback: &tmcons.GossipFeedback{
+					DuplicateVotesPct:      msg.DuplicateVotesPct,
+					DuplicateBlockPartsPct: msg.DuplicateBlockPartsPct,
+				},
+			},
+		}
+
 	default:
 		return nil, fmt.Errorf("consensus: message not recognized: %T", msg)
 	}
@@ -578,6 +615,11 @@ func MsgFromProto(msg *tmcons.Message) (Message, error) {
 			BlockID: *bi,
 			Votes:   bits,
 		}
+	case *tmcons.Message_GossipFeedback:
+		pb = &GossipFeedbackMessage{
+			DuplicateVotesPct:      msg.GossipFeedback.DuplicateVotesPct,
+			DuplicateBlockPartsPct: msg.GossipFeedback.DuplicateBlockPartsPct,
+		}
 	default:
 		return nil, fmt.Errorf("consensus: message not recognized: %T", msg)
 	}
@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/merkle"
 	"github.com/tendermint/tendermint/crypto/tmhash"
 	cstypes "github.com/tendermint/tendermint/internal/consensus/types"
@@ -187,6 +188,17 @@ func TestMsgToProto(t *testing.T) {
 				},
 			},
 		}, false},
+		{"successful GossipFeedback", &GossipFeedbackMessage{
+			DuplicateVotesPct:      50,
+			DuplicateBlockPartsPct: 100,
+		}, &tmcons.Message{
+			Sum: &tmcons.Message_GossipFeedback{
+				GossipFeedback: &tmcons.GossipFeedback{
+					DuplicateVotesPct:      50,
+					DuplicateBlockPartsPct: 100,
+				},
+			},
+		}, false},
 		{"failure", nil, &tmcons.Message{}, true},
 	}
 	for _, tt := range testsCases {
@@ -415,6 +427,9 @@ func TestConsMsgsVectors(t *testing.T) {
 		{"VoteSetBits", &tmcons.Message{Sum: &tmcons.Message_VoteSetBits{
 			VoteSetBits: &tmcons.VoteSetBits{Height: 1, Round: 1, Type: tmproto.PrevoteType, BlockID: pbBi, Votes: *pbBits}}},
 			"4a5708011001180122480a206164645f6d6f72655f6578636c616d6174696f6e5f6d61726b735f636f64652d1224080112206164645f6d6f72655f6578636c616d6174696f6e5f6d61726b735f636f64652d2a050801120100"},
+		{"GossipFeedback", &tmcons.Message{Sum: &tmcons.Message_GossipFeedback{
+			GossipFeedback: &tmcons.GossipFeedback{DuplicateVotesPct: 50, DuplicateBlockPartsPct: 100}}},
+			"520408321064"},
 	}
 
 	for _, tc := range testCases {
@@ -698,6 +713,30 @@ func TestBlockPartMessageValidateBasic(t *testing.T) {
 	assert.Equal(t, true, message.ValidateBasic() != nil, "Validate Basic had an unexpected result")
 }
 
+func TestVoteMessageMaxSize(t *testing.T) {
+	// The largest vote fits in the limit of the vote channel.
+	vote := &types.Vote{
+		Type:   tmproto.PrecommitType,
+		Height: math.MaxInt64,
+		Round:  math.MaxInt32,
+		BlockID: types.BlockID{
+			Hash: tmrand.Bytes(tmhash.Size),
+			PartSetHeader: types.PartSetHeader{
+				Total: math.MaxUint32,
+				Hash:  tmrand.Bytes(tmhash.Size),
+			},
+		},
+		Timestamp:        time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		ValidatorAddress: tmrand.Bytes(crypto.AddressSize),
+		ValidatorIndex:   math.MaxInt32,
+		Signature:        tmrand.Bytes(types.MaxSignatureSize),
+	}
+	require.NoError(t, vote.ValidateBasic())
+	msg, err := MsgToProto(&VoteMessage{Vote: vote})
+	require.NoError(t, err)
+	require.LessOrEqual(t, proto.Size(msg), maxVoteMsgSize)
+}
+
 func TestHasVoteMessageValidateBasic(t *testing.T) {
 	const (
 		validSignedMsgType   tmproto.SignedMsgType = 0x01
@@ -733,3 +772,28 @@ func TestHasVoteMessageValidateBasic(t *testing.T) {
 		})
 	}
 }
+
+func TestGossipFeedbackMessageValidateBasic(t *testing.T) {
+	testCases := []struct {
+		testName   string
+		votes      uint32
+		blockParts uint32
+		expectErr  bool
+	}{
+		{"Valid Message", 0, 100, false},
+		{"Invalid Votes", 101, 0, true},
+		{"Invalid Block Parts", 0, 101, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			message := GossipFeedbackMessage{
+				DuplicateVotesPct:      tc.votes,
+				DuplicateBlockPartsPct: tc.blockParts,
+			}
+
+			assert.Equal(t, tc.expectErr, message.ValidateBasic() != nil, "Validate Basic had an unexpected result")
+		})
+	}
+}
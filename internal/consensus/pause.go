@@ -0,0 +1,66 @@
+package consensus
+
+import (
+	"context"
+
+	sm "github.com/tendermint/tendermint/internal/state"
+)
+
+// pause stops the state machine from processing messages and timeouts, e.g.
+// while block sync catches up after the node fell behind. It returns once the
+// receive routine is paused, between two steps of the state machine, so that
+// no block is being committed.
+func (cs *State) pause(ctx context.Context) error {
+	paused := make(chan struct{})
+	select {
+	case cs.pauseCh <- paused:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-paused:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// resume resumes the paused state machine from state, which is usually ahead
+// of the height it was paused at.
+func (cs *State) resume(ctx context.Context, state sm.State) error {
+	select {
+	case cs.resumeCh <- state:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// waitResume is run by the receive routine while paused. It returns false if
+// ctx is done before the state machine is resumed. Timeouts fired meanwhile
+// are handled once resumed, and ignored then if they are stale.
+func (cs *State) waitResume(ctx context.Context, paused chan struct{}) bool {
+	cs.logger.Info("pausing consensus", "height", cs.Height, "round", cs.Round)
+	close(paused)
+
+	select {
+	case state := <-cs.resumeCh:
+		cs.mtx.Lock()
+		// Any block being committed when paused has since been synced, and we
+		// have no votes for the new height, so reconstruct LastCommit from the
+		// stored commit.
+		cs.CommitRound = -1
+		if state.LastBlockHeight > 0 {
+			cs.reconstructLastCommit(state)
+		}
+		cs.updateToState(ctx, state)
+		cs.mtx.Unlock()
+
+		cs.logger.Info("resuming consensus", "height", cs.Height)
+		cs.scheduleRound0(cs.GetRoundState())
+		return true
+
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,48 @@
+package consensus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestStatePauseResume(t *testing.T) {
+	config := configSetup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cs, _ := randState(ctx, t, config, log.TestingLogger(), 1)
+	height, round := cs.Height, cs.Round
+
+	newBlockCh := subscribe(ctx, t, cs.eventBus, types.EventQueryNewBlock)
+
+	startTestRound(ctx, cs, height, round)
+	ensureNewBlock(t, newBlockCh, height)
+
+	// No block is committed while paused. Blocks committed before may still
+	// be delivered.
+	require.NoError(t, cs.pause(ctx))
+	pausedHeight := cs.GetRoundState().Height
+	timeout := time.After(ensureTimeout)
+WAIT:
+	for {
+		select {
+		case msg := <-newBlockCh:
+			block := msg.Data().(types.EventDataNewBlock).Block
+			require.Less(t, block.Height, pausedHeight, "block committed while paused")
+		case <-timeout:
+			break WAIT
+		}
+	}
+
+	// Once resumed from the stored state, the next block is committed.
+	state, err := cs.blockExec.Store().Load()
+	require.NoError(t, err)
+	require.NoError(t, cs.resume(ctx, state))
+	ensureNewBlock(t, newBlockCh, state.LastBlockHeight+1)
+}
@@ -21,14 +21,71 @@ var (
 	ErrPeerStateInvalidStartTime = errors.New("peer state invalid startTime")
 )
 
+// duplicateGossipThreshold is the percentage of duplicates, reported by a peer
+// in its gossip feedback, above which we pause after sending it a vote or a
+// block part, to give it time to receive the next ones from its other peers.
+const duplicateGossipThreshold = 50
+
 // peerStateStats holds internal statistics for a peer.
 type peerStateStats struct {
 	Votes      int `json:"votes,string"`
 	BlockParts int `json:"block_parts,string"`
+
+	// The votes and block parts received from the peer that we already had.
+	DuplicateVotes      int `json:"duplicate_votes,string"`
+	DuplicateBlockParts int `json:"duplicate_block_parts,string"`
 }
 
 func (pss peerStateStats) String() string {
-	return fmt.Sprintf("peerStateStats{votes: %d, blockParts: %d}", pss.Votes, pss.BlockParts)
+	return fmt.Sprintf("peerStateStats{votes: %d, blockParts: %d, duplicateVotes: %d, duplicateBlockParts: %d}",
+		pss.Votes, pss.BlockParts, pss.DuplicateVotes, pss.DuplicateBlockParts)
+}
+
+// gossipWindow counts the votes and block parts received from a peer since
+// the last gossip feedback sent to it, and how many of them were new to us.
+type gossipWindow struct {
+	votes, newVotes           int
+	blockParts, newBlockParts int
+}
+
+// duplicates returns the number of duplicate votes and block parts.
+func (w gossipWindow) duplicates() (votes, blockParts int) {
+	return dupCount(w.votes, w.newVotes), dupCount(w.blockParts, w.newBlockParts)
+}
+
+// feedback returns the gossip feedback reporting the window.
+func (w gossipWindow) feedback() *GossipFeedbackMessage {
+	votes, blockParts := w.duplicates()
+	return &GossipFeedbackMessage{
+		DuplicateVotesPct:      dupPct(votes, w.votes),
+		DuplicateBlockPartsPct: dupPct(blockParts, w.blockParts),
+	}
+}
+
+// dupCount returns the number of duplicates among the received messages. The
+// new messages of a window may have been received in the previous one, as
+// they are only counted once processed.
+func dupCount(received, added int) int {
+	if added >= received {
+		return 0
+	}
+	return received - added
+}
+
+func dupPct(duplicates, received int) uint32 {
+	if received == 0 {
+		return 0
+	}
+	return uint32(duplicates * 100 / received)
+}
+
+// gossipDelay returns the pause after sending a message to a peer which
+// reported pct percent of duplicates, up to sleep.
+func gossipDelay(pct uint32, sleep time.Duration) time.Duration {
+	if pct <= duplicateGossipThreshold {
+		return 0
+	}
+	return sleep * time.Duration(pct-duplicateGossipThreshold) / (100 - duplicateGossipThreshold)
 }
 
 // PeerState contains the known state of a peer, including its connection and
@@ -45,6 +102,15 @@ type PeerState struct {
 	PRS     cstypes.PeerRoundState `json:"round_state"`
 	Stats   *peerStateStats        `json:"stats"`
 
+	// window counts the messages received since the last gossip feedback sent
+	// to the peer, and feedback is the last gossip feedback it sent us.
+	window   gossipWindow
+	feedback GossipFeedbackMessage
+
+	// voteWakeup wakes up the vote gossip routine of the peer, to send it a
+	// new vote right away.
+	voteWakeup chan struct{}
+
 	broadcastWG sync.WaitGroup
 	closer      *tmsync.Closer
 }
@@ -52,9 +118,10 @@ type PeerState struct {
 // NewPeerState returns a new PeerState for the given node ID.
 func NewPeerState(logger log.Logger, peerID types.NodeID) *PeerState {
 	return &PeerState{
-		peerID: peerID,
-		logger: logger,
-		closer: tmsync.NewCloser(),
+		peerID:     peerID,
+		logger:     logger,
+		closer:     tmsync.NewCloser(),
+		voteWakeup: make(chan struct{}, 1),
 		PRS: cstypes.PeerRoundState{
 			Round:              -1,
 			ProposalPOLRound:   -1,
@@ -65,6 +132,15 @@ func NewPeerState(logger log.Logger, peerID types.NodeID) *PeerState {
 	}
 }
 
+// wakeVoteGossip wakes up the vote gossip routine of the peer, if it is
+// waiting for new votes.
+func (ps *PeerState) wakeVoteGossip() {
+	select {
+	case ps.voteWakeup <- struct{}{}:
+	default:
+	}
+}
+
 // SetRunning sets the running state of the peer.
 func (ps *PeerState) SetRunning(v bool) {
 	ps.mtx.Lock()
@@ -327,10 +403,19 @@ func (ps *PeerState) RecordVote() int {
 	defer ps.mtx.Unlock()
 
 	ps.Stats.Votes++
+	ps.window.newVotes++
 
 	return ps.Stats.Votes
 }
 
+// RecordVoteReceived records a vote received from the peer, new or not.
+func (ps *PeerState) RecordVoteReceived() {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	ps.window.votes++
+}
+
 // VotesSent returns the number of blocks for which peer has been sending us
 // votes.
 func (ps *PeerState) VotesSent() int {
@@ -347,9 +432,60 @@ func (ps *PeerState) RecordBlockPart() int {
 	defer ps.mtx.Unlock()
 
 	ps.Stats.BlockParts++
+	ps.window.newBlockParts++
 	return ps.Stats.BlockParts
 }
 
+// RecordBlockPartReceived records a block part received from the peer, new or
+// not.
+func (ps *PeerState) RecordBlockPartReceived() {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	ps.window.blockParts++
+}
+
+// closeGossipWindow returns the counts of the messages received from the peer
+// since the last call, adding the duplicates to the statistics.
+func (ps *PeerState) closeGossipWindow() gossipWindow {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	w := ps.window
+	ps.window = gossipWindow{}
+
+	votes, blockParts := w.duplicates()
+	ps.Stats.DuplicateVotes += votes
+	ps.Stats.DuplicateBlockParts += blockParts
+	return w
+}
+
+// ApplyGossipFeedbackMessage records the gossip feedback sent by the peer.
+func (ps *PeerState) ApplyGossipFeedbackMessage(msg *GossipFeedbackMessage) {
+	ps.mtx.Lock()
+	defer ps.mtx.Unlock()
+
+	ps.feedback = *msg
+}
+
+// VoteGossipDelay returns the pause after sending a vote to the peer, given
+// its gossip feedback, up to sleep.
+func (ps *PeerState) VoteGossipDelay(sleep time.Duration) time.Duration {
+	ps.mtx.RLock()
+	defer ps.mtx.RUnlock()
+
+	return gossipDelay(ps.feedback.DuplicateVotesPct, sleep)
+}
+
+// BlockPartGossipDelay returns the pause after sending a block part to the
+// peer, given its gossip feedback, up to sleep.
+func (ps *PeerState) BlockPartGossipDelay(sleep time.Duration) time.Duration {
+	ps.mtx.RLock()
+	defer ps.mtx.RUnlock()
+
+	return gossipDelay(ps.feedback.DuplicateBlockPartsPct, sleep)
+}
+
 // BlockPartsSent returns the number of useful block parts the peer has sent us.
 func (ps *PeerState) BlockPartsSent() int {
 	ps.mtx.Lock()
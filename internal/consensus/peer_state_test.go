@@ -0,0 +1,50 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestPeerStateGossipFeedback(t *testing.T) {
+	ps := NewPeerState(log.NewNopLogger(), "peer")
+
+	// 4 votes received, 1 of them new, and 2 new block parts.
+	for i := 0; i < 4; i++ {
+		ps.RecordVoteReceived()
+	}
+	ps.RecordVote()
+	for i := 0; i < 2; i++ {
+		ps.RecordBlockPartReceived()
+		ps.RecordBlockPart()
+	}
+
+	w := ps.closeGossipWindow()
+	require.Equal(t, &GossipFeedbackMessage{DuplicateVotesPct: 75, DuplicateBlockPartsPct: 0}, w.feedback())
+	require.Equal(t, 3, ps.Stats.DuplicateVotes)
+	require.Equal(t, 0, ps.Stats.DuplicateBlockParts)
+	require.Equal(t, gossipWindow{}, ps.closeGossipWindow())
+
+	// A message received in the previous window is not a duplicate.
+	ps.RecordVote()
+	require.Equal(t, &GossipFeedbackMessage{}, ps.closeGossipWindow().feedback())
+	require.Equal(t, 3, ps.Stats.DuplicateVotes)
+}
+
+func TestPeerStateGossipDelay(t *testing.T) {
+	ps := NewPeerState(log.NewNopLogger(), "peer")
+	sleep := 100 * time.Millisecond
+	require.Zero(t, ps.VoteGossipDelay(sleep))
+	require.Zero(t, ps.BlockPartGossipDelay(sleep))
+
+	ps.ApplyGossipFeedbackMessage(&GossipFeedbackMessage{DuplicateVotesPct: 75, DuplicateBlockPartsPct: 50})
+	require.Equal(t, 50*time.Millisecond, ps.VoteGossipDelay(sleep))
+	require.Zero(t, ps.BlockPartGossipDelay(sleep))
+
+	ps.ApplyGossipFeedbackMessage(&GossipFeedbackMessage{DuplicateVotesPct: 100, DuplicateBlockPartsPct: 100})
+	require.Equal(t, sleep, ps.VoteGossipDelay(sleep))
+	require.Equal(t, sleep, ps.BlockPartGossipDelay(sleep))
+}
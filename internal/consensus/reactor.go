@@ -10,6 +10,7 @@ import (
 
 	cstypes "github.com/tendermint/tendermint/internal/consensus/types"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/features"
 	"github.com/tendermint/tendermint/internal/p2p"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/libs/bits"
@@ -26,6 +27,13 @@ var (
 	_ p2p.Wrapper     = (*tmcons.Message)(nil)
 )
 
+// FlagValidatorVotesFirst gates the prioritization of the vote gossip to the
+// peers run by the current validators, as known from the validator book of
+// the node: they are sent the new votes as soon as they are added, while the
+// other peers are sent them on their next gossip round.
+var FlagValidatorVotesFirst = features.Declare("consensus.validator-votes-first",
+	"Gossip the new votes to the peers run by validators first.", features.Experimental, false)
+
 // GetChannelDescriptor produces an instance of a descriptor for this
 // package's required channels.
 func getChannelDescriptors() map[p2p.ChannelID]*p2p.ChannelDescriptor {
@@ -55,7 +63,7 @@ func getChannelDescriptors() map[p2p.ChannelID]*p2p.ChannelDescriptor {
 			Priority:            10,
 			SendQueueCapacity:   64,
 			RecvBufferCapacity:  128,
-			RecvMessageCapacity: maxMsgSize,
+			RecvMessageCapacity: maxVoteMsgSize,
 		},
 		VoteSetBitsChannel: {
 			ID:                  VoteSetBitsChannel,
@@ -76,9 +84,22 @@ const (
 
 	maxMsgSize = 1048576 // 1MB; NOTE: keep in sync with types.PartSet sizes.
 
+	// maxVoteMsgSize is the limit of the vote channel, which only carries
+	// single votes of about 200 bytes plus their extension, whatever the
+	// size of the block.
+	maxVoteMsgSize = 1024 + types.MaxVoteExtensionSize
+
 	blocksToContributeToBecomeGoodPeer = 10000
 	votesToContributeToBecomeGoodPeer  = 10000
 
+	// gossipFeedbackInterval is the interval at which each peer is told the
+	// share of the votes and block parts it sent us that we already had.
+	gossipFeedbackInterval = 10 * time.Second
+	// messagesToContributeToBecomeGoodPeer is the number of votes and block
+	// parts a peer must send us in a gossip feedback interval, most of them
+	// new, to be reported as good.
+	messagesToContributeToBecomeGoodPeer = 100
+
 	listenerIDConsensus = "consensus-reactor"
 )
 
@@ -98,6 +119,13 @@ type BlockSyncReactor interface {
 	GetRemainingSyncTime() time.Duration
 }
 
+// ValidatorBook maps the peers to the validators running them.
+type ValidatorBook interface {
+	// ValidatorAddress returns the address of the validator running the
+	// peer, if known.
+	ValidatorAddress(peerID types.NodeID) (types.Address, bool)
+}
+
 //go:generate ../../scripts/mockery_generate.sh ConsSyncReactor
 // ConsSyncReactor defines an interface used for testing abilities of node.startStateSync.
 type ConsSyncReactor interface {
@@ -118,6 +146,13 @@ type Reactor struct {
 	mtx      sync.RWMutex
 	peers    map[types.NodeID]*PeerState
 	waitSync bool
+	// validators is the validator set of the current round, tracked when the
+	// votes are gossiped to the validators first.
+	validators *types.ValidatorSet
+
+	features      *features.Set
+	validatorBook ValidatorBook
+	forkDetector  *ForkDetector
 
 	stateCh       *p2p.Channel
 	dataCh        *p2p.Channel
@@ -188,6 +223,7 @@ func (r *Reactor) OnStart(ctx context.Context) error {
 	// TODO: Evaluate if we need this to be synchronized via WaitGroup as to not
 	// leak the goroutine when stopping the reactor.
 	go r.peerStatsRoutine(ctx)
+	go r.gossipFeedbackRoutine(ctx)
 
 	r.subscribeToBroadcastEvents()
 
@@ -239,6 +275,24 @@ func (r *Reactor) SetEventBus(b *eventbus.EventBus) {
 	r.state.SetEventBus(b)
 }
 
+// SetFeatures sets the feature flags of the node. It must be called before the
+// reactor is started.
+func (r *Reactor) SetFeatures(set *features.Set) {
+	r.features = set
+}
+
+// SetValidatorBook sets the book of the validators running the peers. It must
+// be called before the reactor is started.
+func (r *Reactor) SetValidatorBook(book ValidatorBook) {
+	r.validatorBook = book
+}
+
+// SetForkDetector sets the detector of the peers committing other blocks than
+// the node. It must be called before the reactor is started.
+func (r *Reactor) SetForkDetector(d *ForkDetector) {
+	r.forkDetector = d
+}
+
 // WaitSync returns whether the consensus reactor is waiting for state/block sync.
 func (r *Reactor) WaitSync() bool {
 	r.mtx.RLock()
@@ -248,18 +302,27 @@ func (r *Reactor) WaitSync() bool {
 }
 
 // SwitchToConsensus switches from block-sync mode to consensus mode. It resets
-// the state, turns off block-sync, and starts the consensus state-machine.
+// the state, turns off block-sync, and starts the consensus state-machine, or
+// resumes it if it was paused by SwitchToBlockSync.
 func (r *Reactor) SwitchToConsensus(ctx context.Context, state sm.State, skipWAL bool) {
 	r.logger.Info("switching to consensus")
 
-	// we have no votes, so reconstruct LastCommit from SeenCommit
-	if state.LastBlockHeight > 0 {
-		r.state.reconstructLastCommit(state)
-	}
+	resuming := r.state.IsRunning()
+	if resuming {
+		if err := r.state.resume(ctx, state); err != nil {
+			r.logger.Error("failed to resume consensus", "err", err)
+			return
+		}
+	} else {
+		// we have no votes, so reconstruct LastCommit from SeenCommit
+		if state.LastBlockHeight > 0 {
+			r.state.reconstructLastCommit(state)
+		}
 
-	// NOTE: The line below causes broadcastNewRoundStepRoutine() to broadcast a
-	// NewRoundStepMessage.
-	r.state.updateToState(ctx, state)
+		// NOTE: The line below causes broadcastNewRoundStepRoutine() to broadcast a
+		// NewRoundStepMessage.
+		r.state.updateToState(ctx, state)
+	}
 
 	r.mtx.Lock()
 	r.waitSync = false
@@ -268,18 +331,20 @@ func (r *Reactor) SwitchToConsensus(ctx context.Context, state sm.State, skipWAL
 	r.Metrics.BlockSyncing.Set(0)
 	r.Metrics.StateSyncing.Set(0)
 
-	if skipWAL {
-		r.state.doWALCatchup = false
-	}
+	if !resuming {
+		if skipWAL {
+			r.state.doWALCatchup = false
+		}
 
-	if err := r.state.Start(ctx); err != nil {
-		panic(fmt.Sprintf(`failed to start consensus state: %v
+		if err := r.state.Start(ctx); err != nil {
+			panic(fmt.Sprintf(`failed to start consensus state: %v
 
 conS:
 %+v
 
 conR:
 %+v`, err, r.state, r))
+		}
 	}
 
 	d := types.EventDataBlockSyncStatus{Complete: true, Height: state.LastBlockHeight}
@@ -288,6 +353,24 @@ conR:
 	}
 }
 
+// SwitchToBlockSync switches from consensus mode back to block-sync mode,
+// when the node has fallen too far behind its peers. It pauses the consensus
+// state-machine and turns on block-sync, until SwitchToConsensus is called.
+func (r *Reactor) SwitchToBlockSync(ctx context.Context) error {
+	r.logger.Info("switching back to block sync")
+
+	if err := r.state.pause(ctx); err != nil {
+		return err
+	}
+
+	r.mtx.Lock()
+	r.waitSync = true
+	r.mtx.Unlock()
+
+	r.Metrics.BlockSyncing.Set(1)
+	return nil
+}
+
 // String returns a string representation of the Reactor.
 //
 // NOTE: For now, it is just a hard-coded string to avoid accessing unprotected
@@ -367,6 +450,10 @@ func (r *Reactor) subscribeToBroadcastEvents() {
 			if err := r.broadcastNewRoundStepMessage(ctx, data.(*cstypes.RoundState)); err != nil {
 				return err
 			}
+			if r.validatorVotesFirst() {
+				r.setValidators(data.(*cstypes.RoundState).Validators)
+			}
+			r.observeLocalCommit(ctx, data.(*cstypes.RoundState))
 			select {
 			case r.state.onStopCh <- data.(*cstypes.RoundState):
 				return nil
@@ -396,6 +483,9 @@ func (r *Reactor) subscribeToBroadcastEvents() {
 		listenerIDConsensus,
 		types.EventVoteValue,
 		func(ctx context.Context, data tmevents.EventData) error {
+			if r.validatorVotesFirst() {
+				r.wakeValidatorPeers()
+			}
 			return r.broadcastHasVoteMessage(ctx, data.(*types.Vote))
 		},
 	)
@@ -404,6 +494,58 @@ func (r *Reactor) subscribeToBroadcastEvents() {
 	}
 }
 
+// observeLocalCommit reports the block committed by the node to the fork
+// detector, once it moves to the next height.
+func (r *Reactor) observeLocalCommit(ctx context.Context, rs *cstypes.RoundState) {
+	if r.forkDetector == nil || rs.Step != cstypes.RoundStepNewHeight {
+		return
+	}
+	meta := r.state.blockStore.LoadBlockMeta(rs.Height - 1)
+	if meta == nil {
+		return
+	}
+	if fork, ok := r.forkDetector.ObserveLocal(meta.Header.Height, meta.BlockID.PartSetHeader); ok {
+		r.publishFork(ctx, fork)
+	}
+}
+
+// publishFork publishes the fork detected at a height on the event bus.
+func (r *Reactor) publishFork(ctx context.Context, fork types.EventDataForkDetected) {
+	if err := r.eventBus.PublishEventForkDetected(ctx, fork); err != nil {
+		r.logger.Error("failed publishing fork detected event", "err", err)
+	}
+}
+
+// validatorVotesFirst returns true if the votes are gossiped to the peers run
+// by validators first.
+func (r *Reactor) validatorVotesFirst() bool {
+	return r.validatorBook != nil && r.features.Enabled(FlagValidatorVotesFirst)
+}
+
+func (r *Reactor) setValidators(vals *types.ValidatorSet) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.validators = vals
+}
+
+// wakeValidatorPeers wakes up the vote gossip routines of the peers run by
+// the current validators, so that they are sent a new vote right away. The
+// consensus state is locked while the vote events are fired, so the
+// validators are those of the last round step event.
+func (r *Reactor) wakeValidatorPeers() {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if r.validators == nil {
+		return
+	}
+	for peerID, ps := range r.peers {
+		if addr, ok := r.validatorBook.ValidatorAddress(peerID); ok && r.validators.HasAddress(addr) {
+			ps.wakeVoteGossip()
+		}
+	}
+}
+
 func (r *Reactor) unsubscribeFromBroadcastEvents() {
 	r.state.evsw.RemoveListener(listenerIDConsensus)
 }
@@ -540,6 +682,17 @@ OUTER_LOOP:
 				}
 
 				ps.SetHasProposalBlockPart(prs.Height, prs.Round, index)
+
+				// If the peer already had most of the block parts we sent it,
+				// give it time to receive the next ones from its other peers.
+				if delay := ps.BlockPartGossipDelay(r.state.config.PeerGossipSleepDuration); delay > 0 {
+					timer.Reset(delay)
+					select {
+					case <-timer.C:
+					case <-ctx.Done():
+						return
+					}
+				}
 				continue OUTER_LOOP
 			}
 		}
@@ -670,6 +823,18 @@ func (r *Reactor) pickSendVote(ctx context.Context, ps *PeerState, votes types.V
 	}
 
 	ps.SetHasVote(vote)
+
+	// If the peer already had most of the votes we sent it, give it time to
+	// receive the next ones from its other peers.
+	if delay := ps.VoteGossipDelay(r.state.config.PeerGossipSleepDuration); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return true, ctx.Err()
+		}
+	}
 	return true, nil
 }
 
@@ -840,6 +1005,10 @@ OUTER_LOOP:
 		case <-ctx.Done():
 			return
 		case <-timer.C:
+		case <-ps.voteWakeup:
+			if !timer.Stop() {
+				<-timer.C
+			}
 		}
 		continue OUTER_LOOP
 	}
@@ -1051,6 +1220,10 @@ func (r *Reactor) processPeerUpdate(ctx context.Context, peerUpdate p2p.PeerUpda
 		}
 
 	case p2p.PeerStatusDown:
+		if r.forkDetector != nil {
+			r.forkDetector.RemovePeer(peerUpdate.NodeID)
+		}
+
 		ps, ok := r.peers[peerUpdate.NodeID]
 		if ok && ps.IsRunning() {
 			// signal to all spawned goroutines for the peer to gracefully exit
@@ -1097,11 +1270,22 @@ func (r *Reactor) handleStateMessage(ctx context.Context, envelope *p2p.Envelope
 		ps.ApplyNewRoundStepMessage(msgI.(*NewRoundStepMessage))
 
 	case *tmcons.NewValidBlock:
-		ps.ApplyNewValidBlockMessage(msgI.(*NewValidBlockMessage))
+		nvb := msgI.(*NewValidBlockMessage)
+		ps.ApplyNewValidBlockMessage(nvb)
+
+		// A peer at the commit step reports the block it commits.
+		if r.forkDetector != nil && nvb.IsCommit {
+			if fork, ok := r.forkDetector.ObservePeer(ps.peerID, nvb.Height, nvb.BlockPartSetHeader); ok {
+				r.publishFork(ctx, fork)
+			}
+		}
 
 	case *tmcons.HasVote:
 		ps.ApplyHasVoteMessage(msgI.(*HasVoteMessage))
 
+	case *tmcons.GossipFeedback:
+		ps.ApplyGossipFeedbackMessage(msgI.(*GossipFeedbackMessage))
+
 	case *tmcons.VoteSetMaj23:
 		r.state.mtx.RLock()
 		height, votes := r.state.Height, r.state.Votes
@@ -1192,6 +1376,7 @@ func (r *Reactor) handleDataMessage(ctx context.Context, envelope *p2p.Envelope,
 		bpMsg := msgI.(*BlockPartMessage)
 
 		ps.SetHasProposalBlockPart(bpMsg.Height, bpMsg.Round, int(bpMsg.Part.Index))
+		ps.RecordBlockPartReceived()
 		r.Metrics.BlockParts.With("peer_id", string(envelope.From)).Add(1)
 		select {
 		case r.state.peerMsgQueue <- msgInfo{bpMsg, envelope.From}:
@@ -1236,6 +1421,7 @@ func (r *Reactor) handleVoteMessage(ctx context.Context, envelope *p2p.Envelope,
 		ps.EnsureVoteBitArrays(height, valSize)
 		ps.EnsureVoteBitArrays(height-1, lastCommitSize)
 		ps.SetHasVote(vMsg.Vote)
+		ps.RecordVoteReceived()
 
 		select {
 		case r.state.peerMsgQueue <- msgInfo{vMsg, envelope.From}:
@@ -1500,6 +1686,66 @@ func (r *Reactor) peerStatsRoutine(ctx context.Context) {
 	}
 }
 
+// gossipFeedbackRoutine periodically sends each peer a gossip feedback, see
+// sendGossipFeedback.
+func (r *Reactor) gossipFeedbackRoutine(ctx context.Context) {
+	ticker := time.NewTicker(gossipFeedbackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mtx.RLock()
+		peers := make([]*PeerState, 0, len(r.peers))
+		for _, ps := range r.peers {
+			peers = append(peers, ps)
+		}
+		r.mtx.RUnlock()
+
+		for _, ps := range peers {
+			if err := r.sendGossipFeedback(ctx, ps); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendGossipFeedback tells the peer the percentage of the votes and block
+// parts it sent us since the last feedback that we already had, so that it can
+// tune its gossip to us. A peer that sent us enough messages, most of them
+// new, is also reported as good.
+func (r *Reactor) sendGossipFeedback(ctx context.Context, ps *PeerState) error {
+	w := ps.closeGossipWindow()
+	if w.votes == 0 && w.blockParts == 0 {
+		return nil
+	}
+
+	votes, blockParts := w.duplicates()
+	r.Metrics.DuplicateMessages.With("peer_id", string(ps.peerID), "kind", "vote").Add(float64(votes))
+	r.Metrics.DuplicateMessages.With("peer_id", string(ps.peerID), "kind", "block_part").Add(float64(blockParts))
+
+	if received := w.votes + w.blockParts; received >= messagesToContributeToBecomeGoodPeer &&
+		2*(votes+blockParts) < received {
+		r.peerUpdates.SendUpdate(ctx, p2p.PeerUpdate{
+			NodeID: ps.peerID,
+			Status: p2p.PeerStatusGood,
+		})
+	}
+
+	msg := w.feedback()
+	return r.stateCh.Send(ctx, p2p.Envelope{
+		To: ps.peerID,
+		Message: &tmcons.GossipFeedback{
+			DuplicateVotesPct:      msg.DuplicateVotesPct,
+			DuplicateBlockPartsPct: msg.DuplicateBlockPartsPct,
+		},
+	})
+}
+
 func (r *Reactor) GetConsensusState() *State {
 	return r.state
 }
@@ -0,0 +1,179 @@
+package consensus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	"github.com/tendermint/tendermint/config"
+	cstypes "github.com/tendermint/tendermint/internal/consensus/types"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/p2psim"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+	tmtime "github.com/tendermint/tendermint/libs/time"
+	tmcons "github.com/tendermint/tendermint/proto/tendermint/consensus"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// BenchmarkReactorVoteGossip measures the cost of a consensus round with many
+// validators: every virtual peer is a validator, which prevotes and precommits
+// nil as soon as the node enters a new round, and the node relays the votes to
+// the peers missing them. An operation is a round. The CPU time includes the
+// signature of the votes by the peers.
+func BenchmarkReactorVoteGossip(b *testing.B) {
+	for _, numPeers := range []int{10, 50, 100} {
+		b.Run(fmt.Sprintf("peers=%d", numPeers), func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			cfg, err := config.ResetTestRoot("consensus_reactor_bench")
+			require.NoError(b, err)
+			b.Cleanup(func() { _ = os.RemoveAll(cfg.RootDir) })
+			cfg.Consensus.TimeoutProposeDelta = 0
+			cfg.Consensus.TimeoutPrevoteDelta = 0
+			cfg.Consensus.TimeoutPrecommit = time.Millisecond
+			cfg.Consensus.TimeoutPrecommitDelta = 0
+
+			logger := log.NewNopLogger()
+			genDoc, privVals := factory.RandGenesisDoc(ctx, b, cfg, numPeers+1, false, testMinPower)
+			state, err := sm.MakeGenesisState(genDoc)
+			require.NoError(b, err)
+
+			sim, err := p2psim.NewNetwork(ctx, logger, p2psim.Options{Network: cfg.ChainID()})
+			require.NoError(b, err)
+
+			cs := newStateWithConfig(ctx, b, logger, cfg, state, privVals[0], kvstore.NewApplication())
+			reactor, err := NewReactor(ctx, logger, cs, sim.OpenChannel,
+				sim.PeerManager.Subscribe(ctx), false, NopMetrics())
+			require.NoError(b, err)
+			reactor.SetEventBus(cs.eventBus)
+
+			voters := &nilVoters{
+				chainID:  cfg.ChainID(),
+				valSet:   state.Validators,
+				privVals: privVals[1:],
+				peers:    make(map[types.NodeID]*nilVoter),
+			}
+
+			// The node cannot make progress alone, with a fraction of the
+			// voting power: the rounds start with the peers.
+			require.NoError(b, reactor.Start(ctx))
+			b.ResetTimer()
+			sim.ResetStats()
+			_, err = sim.AddPeers(ctx, numPeers, voters)
+			require.NoError(b, err)
+			for cs.GetRoundState().Round < int32(b.N) {
+				time.Sleep(time.Millisecond)
+			}
+			b.StopTimer()
+			sim.Stats().Report(b)
+		})
+	}
+}
+
+// nilVoters is a p2psim behavior making each peer a validator voting nil in
+// every round of the first height.
+type nilVoters struct {
+	chainID  string
+	valSet   *types.ValidatorSet
+	privVals []types.PrivValidator
+
+	mtx   sync.Mutex
+	peers map[types.NodeID]*nilVoter
+}
+
+type nilVoter struct {
+	privVal types.PrivValidator
+	rounds  chan int32 // the latest round entered by the node
+}
+
+// voter returns the validator of peer, assigned on first use.
+func (vs *nilVoters) voter(peer *p2psim.Peer) *nilVoter {
+	vs.mtx.Lock()
+	defer vs.mtx.Unlock()
+
+	v, ok := vs.peers[peer.ID]
+	if !ok {
+		v = &nilVoter{
+			privVal: vs.privVals[len(vs.peers)],
+			rounds:  make(chan int32, 1),
+		}
+		vs.peers[peer.ID] = v
+	}
+	return v
+}
+
+// Receive implements p2psim.Receiver.
+func (vs *nilVoters) Receive(_ context.Context, peer *p2psim.Peer, _ p2p.ChannelID, msg proto.Message) {
+	step, ok := msg.(*tmcons.NewRoundStep)
+	if !ok || step.Height != 1 {
+		return
+	}
+	v := vs.voter(peer)
+	select {
+	case <-v.rounds:
+	default:
+	}
+	v.rounds <- step.Round
+}
+
+// Run implements p2psim.Behavior.
+func (vs *nilVoters) Run(ctx context.Context, peer *p2psim.Peer) error {
+	v := vs.voter(peer)
+	pubKey, err := v.privVal.GetPubKey(ctx)
+	if err != nil {
+		return err
+	}
+	index, _ := vs.valSet.GetByAddress(pubKey.Address())
+
+	last := int32(-1)
+	for {
+		var round int32
+		select {
+		case round = <-v.rounds:
+		case <-ctx.Done():
+			return nil
+		}
+		if round <= last {
+			continue
+		}
+		last = round
+
+		// Announce the step of the peer, so that the node gossips it the
+		// votes of the round, then vote.
+		if err := peer.Send(ctx, StateChannel, &tmcons.NewRoundStep{
+			Height:          1,
+			Round:           round,
+			Step:            uint32(cstypes.RoundStepPrecommit),
+			LastCommitRound: -1,
+		}); err != nil {
+			return err
+		}
+		for _, voteType := range []tmproto.SignedMsgType{tmproto.PrevoteType, tmproto.PrecommitType} {
+			vote := (&types.Vote{
+				Type:             voteType,
+				Height:           1,
+				Round:            round,
+				Timestamp:        tmtime.Now(),
+				ValidatorAddress: pubKey.Address(),
+				ValidatorIndex:   index,
+			}).ToProto()
+			if err := v.privVal.SignVote(ctx, vs.chainID, vote); err != nil {
+				return err
+			}
+			if err := peer.Send(ctx, VoteChannel, &tmcons.Vote{Vote: vote}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto/encoding"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/features"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/p2p/p2ptest"
@@ -849,3 +850,45 @@ func TestReactorValidatorSetChanges(t *testing.T) {
 
 	waitForBlockWithUpdatedValsAndValidateIt(ctx, t, nPeers, activeVals, blocksSubs, states)
 }
+
+type testValidatorBook map[types.NodeID]types.Address
+
+func (b testValidatorBook) ValidatorAddress(peerID types.NodeID) (types.Address, bool) {
+	addr, ok := b[peerID]
+	return addr, ok
+}
+
+func TestReactorWakeValidatorPeers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	vals, _ := factory.RandValidatorSet(ctx, t, 2, 10)
+	book := testValidatorBook{
+		"validator": vals.Validators[0].Address,
+		// Attested by a key which is not a validator.
+		"other": types.Address("not a validator"),
+	}
+
+	r := &Reactor{peers: make(map[types.NodeID]*PeerState)}
+	for _, peerID := range []types.NodeID{"validator", "other", "unknown"} {
+		r.peers[peerID] = NewPeerState(log.NewNopLogger(), peerID)
+	}
+	require.False(t, r.validatorVotesFirst())
+
+	set, err := features.ParseSet([]string{FlagValidatorVotesFirst.Name})
+	require.NoError(t, err)
+	r.SetFeatures(set)
+	r.SetValidatorBook(book)
+	require.True(t, r.validatorVotesFirst())
+
+	// The validators are not known before the first round step.
+	r.wakeValidatorPeers()
+	require.Empty(t, r.peers["validator"].voteWakeup)
+
+	r.setValidators(vals)
+	r.wakeValidatorPeers()
+	r.wakeValidatorPeers()
+	require.Len(t, r.peers["validator"].voteWakeup, 1)
+	require.Empty(t, r.peers["other"].voteWakeup)
+	require.Empty(t, r.peers["unknown"].voteWakeup)
+}
@@ -8,6 +8,7 @@ import (
 	"hash/crc32"
 	"io"
 	"reflect"
+	"sync"
 	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -142,7 +143,8 @@ func (cs *State) catchupReplay(ctx context.Context, csHeight int64) error {
 	cs.logger.Info("Catchup by replaying consensus messages", "height", csHeight)
 
 	var msg *TimedWALMessage
-	dec := WALDecoder{gr}
+	dec := NewWALDecoder(gr)
+	dec.SetCipher(cs.walCipher)
 
 LOOP:
 	for {
@@ -200,6 +202,25 @@ func makeHeightSearchFunc(height int64) auto.SearchFunc {
 // we were last, and using the WAL to recover there.)
 //---------------------------------------------------
 
+// replayProgressInterval is the interval between the logs of the progress of
+// the replay of the blocks into the app.
+const replayProgressInterval = 10 * time.Second
+
+// ReplayProgress is the progress of the replay of the stored blocks into the
+// app during the handshake.
+type ReplayProgress struct {
+	// StartHeight and TargetHeight are the first and the last heights to
+	// replay, both 0 until the replay starts.
+	StartHeight  int64
+	TargetHeight int64
+	// Height is the last height replayed, StartHeight-1 before the first.
+	Height    int64
+	StartTime time.Time
+	// Remaining is the estimated time to replay the remaining blocks, from the
+	// mean time of the blocks replayed so far.
+	Remaining time.Duration
+}
+
 type Handshaker struct {
 	stateStore   sm.Store
 	initialState sm.State
@@ -207,8 +228,14 @@ type Handshaker struct {
 	eventBus     types.BlockEventPublisher
 	genDoc       *types.GenesisDoc
 	logger       log.Logger
+	forensics    *sm.Forensics
+	metrics      *Metrics
 
 	nBlocks int // number of blocks applied to the state
+
+	mtx      sync.Mutex
+	progress ReplayProgress
+	lastLog  time.Time // of the progress
 }
 
 func NewHandshaker(
@@ -227,15 +254,82 @@ func NewHandshaker(
 		eventBus:     eventBus,
 		genDoc:       genDoc,
 		logger:       logger,
+		metrics:      NopMetrics(),
 		nBlocks:      0,
 	}
 }
 
+// SetMetrics sets the metrics updated with the progress of the replay.
+func (h *Handshaker) SetMetrics(metrics *Metrics) {
+	h.metrics = metrics
+}
+
+// SetForensics sets the forensics capturing a bundle on an app hash mismatch,
+// before the handshake panics.
+func (h *Handshaker) SetForensics(forensics *sm.Forensics) {
+	h.forensics = forensics
+}
+
 // NBlocks returns the number of blocks applied to the state.
 func (h *Handshaker) NBlocks() int {
 	return h.nBlocks
 }
 
+// Progress returns the progress of the replay of the blocks into the app. It
+// is safe to call concurrently with Handshake.
+func (h *Handshaker) Progress() ReplayProgress {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	return h.progress
+}
+
+// startReplay records the start of the replay of the blocks first to last.
+func (h *Handshaker) startReplay(first, last int64) {
+	now := time.Now()
+	h.mtx.Lock()
+	h.progress = ReplayProgress{
+		StartHeight:  first,
+		TargetHeight: last,
+		Height:       first - 1,
+		StartTime:    now,
+	}
+	h.lastLog = now
+	h.mtx.Unlock()
+
+	h.metrics.ReplayHeight.Set(float64(first - 1))
+	h.metrics.ReplayRemainingBlocks.Set(float64(last - first + 1))
+	h.logger.Info("Replaying blocks into the app", "from", first, "to", last)
+}
+
+// replayed records the replay of the block at height, and logs the progress
+// every replayProgressInterval and at the last block.
+func (h *Handshaker) replayed(height int64) {
+	now := time.Now()
+	h.mtx.Lock()
+	p := &h.progress
+	p.Height = height
+	replayed, remaining := height-p.StartHeight+1, p.TargetHeight-height
+	if replayed > 0 && remaining >= 0 {
+		p.Remaining = now.Sub(p.StartTime) / time.Duration(replayed) * time.Duration(remaining)
+	}
+	logProgress := remaining <= 0 || now.Sub(h.lastLog) >= replayProgressInterval
+	if logProgress {
+		h.lastLog = now
+	}
+	eta := p.Remaining
+	h.mtx.Unlock()
+
+	h.metrics.ReplayHeight.Set(float64(height))
+	h.metrics.ReplayRemainingBlocks.Set(float64(remaining))
+	if logProgress {
+		h.logger.Info("Replay progress",
+			"height", height,
+			"replayed", replayed,
+			"remaining", remaining,
+			"eta", eta)
+	}
+}
+
 // TODO: retry the handshake/replay if it fails ?
 func (h *Handshaker) Handshake(ctx context.Context, proxyApp proxy.AppConns) error {
 
@@ -358,7 +452,7 @@ func (h *Handshaker) ReplayBlocks(
 	// First handle edge cases and constraints on the storeBlockHeight and storeBlockBase.
 	switch {
 	case storeBlockHeight == 0:
-		assertAppHashEqualsOneFromState(appHash, state)
+		h.assertAppHashEqualsOneFromState(appHash, state)
 		return appHash, nil
 
 	case appBlockHeight == 0 && state.InitialHeight < storeBlockBase:
@@ -394,7 +488,7 @@ func (h *Handshaker) ReplayBlocks(
 
 		} else if appBlockHeight == storeBlockHeight {
 			// We're good!
-			assertAppHashEqualsOneFromState(appHash, state)
+			h.assertAppHashEqualsOneFromState(appHash, state)
 			return appHash, nil
 		}
 
@@ -413,6 +507,7 @@ func (h *Handshaker) ReplayBlocks(
 			// NOTE: We could instead use the cs.WAL on cs.Start,
 			// but we'd have to allow the WAL to replay a block that wrote it's #ENDHEIGHT
 			h.logger.Info("Replay last block using real app")
+			h.startReplay(storeBlockHeight, storeBlockHeight)
 			state, err = h.replayBlock(ctx, state, storeBlockHeight, proxyApp.Consensus())
 			return state.AppHash, err
 
@@ -428,6 +523,7 @@ func (h *Handshaker) ReplayBlocks(
 			}
 
 			h.logger.Info("Replay last block using mock app")
+			h.startReplay(storeBlockHeight, storeBlockHeight)
 			state, err = h.replayBlock(ctx, state, storeBlockHeight, mockApp)
 			if err != nil {
 				return nil, err
@@ -469,12 +565,22 @@ func (h *Handshaker) replayBlocks(
 	if firstBlock == 1 {
 		firstBlock = state.InitialHeight
 	}
+	h.startReplay(firstBlock, storeBlockHeight)
+	// Each block is executed to completion, so that an interruption does not
+	// leave the app in the middle of a block.
+	blockCtx := context.Background()
 	for i := firstBlock; i <= finalBlock; i++ {
-		h.logger.Info("Applying block", "height", i)
+		// The app committed the blocks replayed so far, so an interrupted
+		// replay resumes from the next one on restart.
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("replay interrupted before height %d, resuming from it on restart: %w", i, err)
+		}
+
+		h.logger.Debug("Applying block", "height", i)
 		block := h.store.LoadBlock(i)
 		// Extra check to ensure the app was not changed in a way it shouldn't have.
 		if len(appHash) > 0 {
-			assertAppHashEqualsOneFromBlock(appHash, block)
+			h.assertAppHashEqualsOneFromBlock(appHash, block, state)
 		}
 
 		if i == finalBlock && !mutateState {
@@ -483,13 +589,13 @@ func (h *Handshaker) replayBlocks(
 			blockExec := sm.NewBlockExecutor(
 				h.stateStore, h.logger, proxyApp.Consensus(), emptyMempool{}, sm.EmptyEvidencePool{}, h.store)
 			blockExec.SetEventBus(h.eventBus)
-			appHash, err = sm.ExecCommitBlock(ctx,
+			appHash, err = sm.ExecCommitBlock(blockCtx,
 				blockExec, proxyApp.Consensus(), block, h.logger, h.stateStore, h.genDoc.InitialHeight, state)
 			if err != nil {
 				return nil, err
 			}
 		} else {
-			appHash, err = sm.ExecCommitBlock(ctx,
+			appHash, err = sm.ExecCommitBlock(blockCtx,
 				nil, proxyApp.Consensus(), block, h.logger, h.stateStore, h.genDoc.InitialHeight, state)
 			if err != nil {
 				return nil, err
@@ -497,6 +603,7 @@ func (h *Handshaker) replayBlocks(
 		}
 
 		h.nBlocks++
+		h.replayed(i)
 	}
 
 	if mutateState {
@@ -508,7 +615,7 @@ func (h *Handshaker) replayBlocks(
 		appHash = state.AppHash
 	}
 
-	assertAppHashEqualsOneFromState(appHash, state)
+	h.assertAppHashEqualsOneFromState(appHash, state)
 	return appHash, nil
 }
 
@@ -524,7 +631,11 @@ func (h *Handshaker) replayBlock(
 
 	// Use stubs for both mempool and evidence pool since no transactions nor
 	// evidence are needed here - block already exists.
-	blockExec := sm.NewBlockExecutor(h.stateStore, h.logger, proxyApp, emptyMempool{}, sm.EmptyEvidencePool{}, h.store)
+	var opts []sm.BlockExecutorOption
+	if h.forensics != nil {
+		opts = append(opts, sm.BlockExecutorWithForensics(h.forensics))
+	}
+	blockExec := sm.NewBlockExecutor(h.stateStore, h.logger, proxyApp, emptyMempool{}, sm.EmptyEvidencePool{}, h.store, opts...)
 	blockExec.SetEventBus(h.eventBus)
 
 	var err error
@@ -534,12 +645,14 @@ func (h *Handshaker) replayBlock(
 	}
 
 	h.nBlocks++
+	h.replayed(height)
 
 	return state, nil
 }
 
-func assertAppHashEqualsOneFromBlock(appHash []byte, block *types.Block) {
+func (h *Handshaker) assertAppHashEqualsOneFromBlock(appHash []byte, block *types.Block, state sm.State) {
 	if !bytes.Equal(appHash, block.AppHash) {
+		h.captureAppHashMismatch(state, block.Height-1, appHash, block.AppHash)
 		panic(fmt.Sprintf(`block.AppHash does not match AppHash after replay. Got %X, expected %X.
 
 Block: %v
@@ -548,8 +661,9 @@ Block: %v
 	}
 }
 
-func assertAppHashEqualsOneFromState(appHash []byte, state sm.State) {
+func (h *Handshaker) assertAppHashEqualsOneFromState(appHash []byte, state sm.State) {
 	if !bytes.Equal(appHash, state.AppHash) {
+		h.captureAppHashMismatch(state, state.LastBlockHeight, appHash, state.AppHash)
 		panic(fmt.Sprintf(`state.AppHash does not match AppHash after replay. Got
 %X, expected %X.
 
@@ -559,3 +673,15 @@ Did you reset Tendermint without resetting your application's data?`,
 			appHash, state.AppHash, state))
 	}
 }
+
+// captureAppHashMismatch captures the forensic bundle of the mismatch of the
+// app hash resulting from the block at height, if the forensics are set.
+func (h *Handshaker) captureAppHashMismatch(state sm.State, height int64, appHash, expectedAppHash []byte) {
+	if h.forensics == nil {
+		return
+	}
+	_, err := h.forensics.CaptureAppHashMismatch(sm.AppHashMismatchReplay, state, height, appHash, expectedAppHash)
+	if err != nil {
+		h.logger.Error("failed to capture the forensic bundle of an app hash mismatch", "height", height, "err", err)
+	}
+}
@@ -12,7 +12,9 @@ import (
 
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/dbcompress"
 	"github.com/tendermint/tendermint/internal/eventbus"
 	"github.com/tendermint/tendermint/internal/proxy"
 	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
@@ -131,12 +133,14 @@ type playback struct {
 }
 
 func newPlayback(fileName string, fp *os.File, cs *State, genState sm.State) *playback {
+	dec := NewWALDecoder(fp)
+	dec.SetCipher(cs.walCipher)
 	return &playback{
 		cs:           cs,
 		fp:           fp,
 		fileName:     fileName,
 		genesisState: genState,
-		dec:          NewWALDecoder(fp),
+		dec:          dec,
 	}
 }
 
@@ -148,7 +152,7 @@ func (pb *playback) replayReset(ctx context.Context, count int, newStepSub event
 	pb.cs.Wait()
 
 	newCS := NewState(ctx, pb.cs.logger, pb.cs.config, pb.genesisState.Copy(), pb.cs.blockExec,
-		pb.cs.blockStore, pb.cs.txNotifier, pb.cs.evpool)
+		pb.cs.blockStore, pb.cs.txNotifier, pb.cs.evpool, StateWALCipher(pb.cs.walCipher))
 	newCS.SetEventBus(pb.cs.eventBus)
 	newCS.startForReplay()
 
@@ -161,6 +165,7 @@ func (pb *playback) replayReset(ctx context.Context, count int, newStepSub event
 	}
 	pb.fp = fp
 	pb.dec = NewWALDecoder(fp)
+	pb.dec.SetCipher(pb.cs.walCipher)
 	count = pb.count - count
 	fmt.Printf("Reseting from %d to %d\n", pb.count, count)
 	pb.count = 0
@@ -300,13 +305,22 @@ func newConsensusStateForReplay(
 	logger log.Logger,
 	csConfig *config.ConsensusConfig,
 ) (*State, error) {
+	cipher, err := atrest.CipherFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := dbcompress.CodecFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	dbType := dbm.BackendType(cfg.DBBackend)
 	// Get BlockStore
 	blockStoreDB, err := dbm.NewDB("blockstore", dbType, cfg.DBDir())
 	if err != nil {
 		return nil, err
 	}
-	blockStore := store.NewBlockStore(blockStoreDB)
+	blockStore := store.NewBlockStore(dbcompress.WrapDB(atrest.WrapDB(blockStoreDB, cipher), codec))
 
 	// Get State
 	stateDB, err := dbm.NewDB("state", dbType, cfg.DBDir())
@@ -314,7 +328,7 @@ func newConsensusStateForReplay(
 		return nil, err
 	}
 
-	stateStore := sm.NewStore(stateDB)
+	stateStore := sm.NewStore(dbcompress.WrapDB(stateDB, codec))
 	gdoc, err := sm.MakeGenesisDocFromFile(cfg.GenesisFile())
 	if err != nil {
 		return nil, err
@@ -348,7 +362,7 @@ func newConsensusStateForReplay(
 	blockExec := sm.NewBlockExecutor(stateStore, logger, proxyApp.Consensus(), mempool, evpool, blockStore)
 
 	consensusState := NewState(ctx, logger, csConfig, state.Copy(), blockExec,
-		blockStore, mempool, evpool)
+		blockStore, mempool, evpool, StateWALCipher(cipher))
 
 	consensusState.SetEventBus(eventBus)
 	return consensusState, nil
@@ -383,7 +383,7 @@ func setupSimulator(ctx context.Context, t *testing.T) *simulatorTestSuite {
 	newValidatorTx1 := kvstore.MakeValSetChangeTx(valPubKey1ABCI, testMinPower)
 	err = assertMempool(t, css[0].txNotifier).CheckTx(ctx, newValidatorTx1, nil, mempool.TxInfo{})
 	assert.NoError(t, err)
-	propBlock, _, err := css[0].createProposalBlock() // changeProposer(t, cs1, vs2)
+	propBlock, _, err := css[0].createProposalBlock(ctx) // changeProposer(t, cs1, vs2)
 	require.NoError(t, err)
 	propBlockParts, err := propBlock.MakePartSet(partSize)
 	require.NoError(t, err)
@@ -417,7 +417,7 @@ func setupSimulator(ctx context.Context, t *testing.T) *simulatorTestSuite {
 	updateValidatorTx1 := kvstore.MakeValSetChangeTx(updatePubKey1ABCI, 25)
 	err = assertMempool(t, css[0].txNotifier).CheckTx(ctx, updateValidatorTx1, nil, mempool.TxInfo{})
 	assert.NoError(t, err)
-	propBlock, _, err = css[0].createProposalBlock() // changeProposer(t, cs1, vs2)
+	propBlock, _, err = css[0].createProposalBlock(ctx) // changeProposer(t, cs1, vs2)
 	require.NoError(t, err)
 	propBlockParts, err = propBlock.MakePartSet(partSize)
 	require.NoError(t, err)
@@ -458,7 +458,7 @@ func setupSimulator(ctx context.Context, t *testing.T) *simulatorTestSuite {
 	newValidatorTx3 := kvstore.MakeValSetChangeTx(newVal3ABCI, testMinPower)
 	err = assertMempool(t, css[0].txNotifier).CheckTx(ctx, newValidatorTx3, nil, mempool.TxInfo{})
 	assert.NoError(t, err)
-	propBlock, _, err = css[0].createProposalBlock() // changeProposer(t, cs1, vs2)
+	propBlock, _, err = css[0].createProposalBlock(ctx) // changeProposer(t, cs1, vs2)
 	require.NoError(t, err)
 	propBlockParts, err = propBlock.MakePartSet(partSize)
 	require.NoError(t, err)
@@ -544,7 +544,7 @@ func setupSimulator(ctx context.Context, t *testing.T) *simulatorTestSuite {
 	removeValidatorTx3 := kvstore.MakeValSetChangeTx(newVal3ABCI, 0)
 	err = assertMempool(t, css[0].txNotifier).CheckTx(ctx, removeValidatorTx3, nil, mempool.TxInfo{})
 	assert.NoError(t, err)
-	propBlock, _, err = css[0].createProposalBlock() // changeProposer(t, cs1, vs2)
+	propBlock, _, err = css[0].createProposalBlock(ctx) // changeProposer(t, cs1, vs2)
 	require.NoError(t, err)
 	propBlockParts, err = propBlock.MakePartSet(partSize)
 	require.NoError(t, err)
@@ -861,6 +861,81 @@ func testHandshakeReplay(
 	if handshaker.NBlocks() != expectedBlocksToSync {
 		t.Fatalf("Expected handshake to sync %d blocks, got %d", expectedBlocksToSync, handshaker.NBlocks())
 	}
+
+	progress := handshaker.Progress()
+	if expectedBlocksToSync > 0 {
+		assert.Equal(t, store.Height(), progress.TargetHeight)
+		assert.Equal(t, store.Height(), progress.Height)
+		assert.EqualValues(t, expectedBlocksToSync, progress.Height-progress.StartHeight+1)
+		assert.Zero(t, progress.Remaining)
+	} else {
+		assert.Zero(t, progress.TargetHeight)
+	}
+}
+
+// interruptingApp cancels a context after committing a number of blocks.
+type interruptingApp struct {
+	*kvstore.PersistentKVStoreApplication
+	commits int
+	cancel  context.CancelFunc
+}
+
+func (app *interruptingApp) Commit() abci.ResponseCommit {
+	res := app.PersistentKVStoreApplication.Commit()
+	if app.commits--; app.commits == 0 {
+		app.cancel()
+	}
+	return res
+}
+
+func TestHandshakeReplayInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sim := setupSimulator(ctx, t)
+	cfg := sim.Config
+	logger := log.TestingLogger()
+
+	stateStore := sm.NewStore(dbm.NewMemDB())
+	store := newMockBlockStore(t, cfg, sim.GenesisState.ConsensusParams)
+	store.chain = sim.Chain
+	store.commits = sim.Commits
+	state := buildTMStateFromChain(ctx, t, cfg, logger, sim.Mempool, sim.Evpool,
+		stateStore, sim.GenesisState.Copy(), sim.Chain, 0, 0, store)
+	genDoc, err := sm.MakeGenesisDocFromFile(cfg.GenesisFile())
+	require.NoError(t, err)
+
+	kvstoreApp := kvstore.NewPersistentKVStoreApplication(logger,
+		filepath.Join(cfg.DBDir(), fmt.Sprintf("replay_test_interrupted_r%d", rand.Int())))
+	t.Cleanup(func() { require.NoError(t, kvstoreApp.Close()) })
+
+	// The replay stops after the block it is replaying when interrupted.
+	hctx, hcancel := context.WithCancel(ctx)
+	defer hcancel()
+	app := &interruptingApp{PersistentKVStoreApplication: kvstoreApp, commits: 2, cancel: hcancel}
+	proxyApp := proxy.NewAppConns(abciclient.NewLocalCreator(app), logger, proxy.NopMetrics())
+	require.NoError(t, proxyApp.Start(ctx))
+
+	handshaker := NewHandshaker(logger, stateStore, state, store, eventbus.NopEventBus{}, genDoc)
+	err = handshaker.Handshake(hctx, proxyApp)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled), err)
+	assert.Equal(t, 2, handshaker.NBlocks())
+	progress := handshaker.Progress()
+	assert.EqualValues(t, 1, progress.StartHeight)
+	assert.EqualValues(t, 2, progress.Height)
+	assert.Equal(t, store.Height(), progress.TargetHeight)
+
+	// And resumes from the next one.
+	handshaker = NewHandshaker(logger, stateStore, state, store, eventbus.NopEventBus{}, genDoc)
+	require.NoError(t, handshaker.Handshake(ctx, proxyApp))
+	assert.EqualValues(t, numBlocks-2, handshaker.NBlocks())
+	assert.EqualValues(t, 3, handshaker.Progress().StartHeight)
+
+	res, err := proxyApp.Query().Info(ctx, abci.RequestInfo{})
+	require.NoError(t, err)
+	assert.Equal(t, state.AppHash, res.LastBlockAppHash)
+	assert.EqualValues(t, numBlocks, res.LastBlockHeight)
 }
 
 func applyBlock(
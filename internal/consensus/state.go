@@ -15,6 +15,7 @@ import (
 
 	"github.com/gogo/protobuf/proto"
 
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	cstypes "github.com/tendermint/tendermint/internal/consensus/types"
@@ -38,6 +39,7 @@ var (
 	ErrInvalidProposalSignature   = errors.New("error invalid proposal signature")
 	ErrInvalidProposalPOLRound    = errors.New("error invalid proposal POL round")
 	ErrAddingVote                 = errors.New("error adding vote")
+	ErrInvalidVoteExtension       = errors.New("error invalid vote extension")
 	ErrSignatureFoundInPastBlocks = errors.New("found signature from the same key")
 
 	errPubKeyIsNotSet = errors.New("pubkey is not set. Look for \"Can't get private validator pubkey\" errors")
@@ -143,6 +145,10 @@ type State struct {
 	internalMsgQueue chan msgInfo
 	timeoutTicker    TimeoutTicker
 
+	// pauseCh and resumeCh pause and resume the receive routine, see pause().
+	pauseCh  chan chan struct{}
+	resumeCh chan sm.State
+
 	// information about about added votes and block parts are written on this channel
 	// so statistics can be computed by reactor
 	statsMsgQueue chan msgInfo
@@ -154,8 +160,9 @@ type State struct {
 	// a Write-Ahead Log ensures we can recover from any kind of crash
 	// and helps us avoid signing conflicting votes
 	wal          WAL
-	replayMode   bool // so we don't log signing errors during replay
-	doWALCatchup bool // determines if we even try to do the catchup
+	walCipher    *atrest.Cipher // encrypts the WAL opened by OpenWAL, if set
+	replayMode   bool           // so we don't log signing errors during replay
+	doWALCatchup bool           // determines if we even try to do the catchup
 
 	// for tests where we want to limit the number of transitions the state makes
 	nSteps int
@@ -175,6 +182,23 @@ type State struct {
 	// for reporting metrics
 	metrics *Metrics
 
+	// timings of the consensus steps of the current round, reported in the
+	// profile of the committed block
+	stepTimes struct {
+		propose   time.Time
+		prevote   time.Time
+		precommit time.Time
+
+		// when +2/3 of the prevotes and precommits of the round first
+		// agreed on a block or nil
+		prevoteQuorum   time.Time
+		precommitQuorum time.Time
+	}
+	blockProfile types.EventDataBlockProfile
+
+	// recent changes of the locked and valid blocks
+	lockHistory lockHistory
+
 	// wait the channel event happening for shutting down the state gracefully
 	onStopCh chan *cstypes.RoundState
 }
@@ -202,6 +226,8 @@ func NewState(
 		txNotifier:       txNotifier,
 		peerMsgQueue:     make(chan msgInfo, msgQueueSize),
 		internalMsgQueue: make(chan msgInfo, msgQueueSize),
+		pauseCh:          make(chan chan struct{}),
+		resumeCh:         make(chan sm.State),
 		timeoutTicker:    NewTimeoutTicker(logger),
 		statsMsgQueue:    make(chan msgInfo, msgQueueSize),
 		done:             make(chan struct{}),
@@ -246,6 +272,11 @@ func StateMetrics(metrics *Metrics) StateOption {
 	return func(cs *State) { cs.metrics = metrics }
 }
 
+// StateWALCipher makes the State encrypt its WAL with c.
+func StateWALCipher(c *atrest.Cipher) StateOption {
+	return func(cs *State) { cs.walCipher = c }
+}
+
 // String returns a string.
 func (cs *State) String() string {
 	// better not to access shared variables
@@ -410,7 +441,7 @@ func (cs *State) OnStart(ctx context.Context) error {
 			cs.logger.Debug("backed up WAL file", "src", cs.config.WalFile(), "dst", corruptedFile)
 
 			// 3) try to repair (WAL file will be overwritten!)
-			if err := repairWalFile(corruptedFile, cs.config.WalFile()); err != nil {
+			if err := repairWalFile(corruptedFile, cs.config.WalFile(), cs.walCipher); err != nil {
 				cs.logger.Error("the WAL repair failed", "err", err)
 				return err
 			}
@@ -524,6 +555,7 @@ func (cs *State) OpenWAL(ctx context.Context, walFile string) (WAL, error) {
 		cs.logger.Error("failed to open WAL", "file", walFile, "err", err)
 		return nil, err
 	}
+	wal.SetCipher(cs.walCipher)
 
 	if err := wal.Start(ctx); err != nil {
 		cs.logger.Error("failed to start WAL", "err", err)
@@ -640,6 +672,45 @@ func (cs *State) updateRoundStep(round int32, step cstypes.RoundStepType) {
 	cs.Step = step
 }
 
+// resetBlockProfile discards the step timings collected so far unless they
+// belong to the given height and round.
+func (cs *State) resetBlockProfile(height int64, round int32) {
+	if cs.blockProfile.Height == height && cs.blockProfile.Round == round {
+		return
+	}
+	cs.blockProfile = types.EventDataBlockProfile{Height: height, Round: round}
+	cs.stepTimes.propose = time.Time{}
+	cs.stepTimes.prevote = time.Time{}
+	cs.stepTimes.precommit = time.Time{}
+	cs.stepTimes.prevoteQuorum = time.Time{}
+	cs.stepTimes.precommitQuorum = time.Time{}
+}
+
+// recordQuorum records when +2/3 of the votes of the current round of the
+// type of vote first agreed on a block or nil.
+func (cs *State) recordQuorum(vote *types.Vote, votes *types.VoteSet) {
+	if vote.Round != cs.Round || !votes.HasTwoThirdsMajority() {
+		return
+	}
+	cs.resetBlockProfile(cs.Height, cs.Round)
+	quorum := &cs.stepTimes.prevoteQuorum
+	if vote.Type == tmproto.PrecommitType {
+		quorum = &cs.stepTimes.precommitQuorum
+	}
+	if quorum.IsZero() {
+		*quorum = tmtime.Now()
+	}
+}
+
+// quorumWait returns the time from the start of a step until the quorum,
+// which is zero if the quorum was reached before the step or not at all.
+func quorumWait(start, quorum time.Time) time.Duration {
+	if start.IsZero() || quorum.IsZero() || quorum.Before(start) {
+		return 0
+	}
+	return quorum.Sub(start)
+}
+
 // enterNewRound(height, 0) at cs.StartTime.
 func (cs *State) scheduleRound0(rs *cstypes.RoundState) {
 	// cs.logger.Info("scheduleRound0", "now", tmtime.Now(), "startTime", cs.StartTime)
@@ -793,6 +864,8 @@ func (cs *State) updateToState(ctx context.Context, state sm.State) {
 	cs.ValidRound = -1
 	cs.ValidBlock = nil
 	cs.ValidBlockParts = nil
+	cs.metrics.LockedRound.Set(-1)
+	cs.metrics.ValidRound.Set(-1)
 	cs.Votes = cstypes.NewHeightVoteSet(state.ChainID, height, validators)
 	cs.CommitRound = -1
 	cs.LastValidators = state.LastValidators
@@ -908,6 +981,12 @@ func (cs *State) receiveRoutine(ctx context.Context, maxSteps int) {
 			// go to the next step
 			cs.handleTimeout(ctx, ti, rs)
 
+		case paused := <-cs.pauseCh:
+			if !cs.waitResume(ctx, paused) {
+				onExit(cs)
+				return
+			}
+
 		case <-ctx.Done():
 			onExit(cs)
 			return
@@ -1184,6 +1263,9 @@ func (cs *State) enterPropose(ctx context.Context, height int64, round int32) {
 
 	logger.Debug("entering propose step", "current", fmt.Sprintf("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 
+	cs.resetBlockProfile(height, round)
+	cs.stepTimes.propose = tmtime.Now()
+
 	defer func() {
 		// Done enterPropose:
 		cs.updateRoundStep(round, cstypes.RoundStepPropose)
@@ -1253,7 +1335,7 @@ func (cs *State) defaultDecideProposal(ctx context.Context, height int64, round
 	} else {
 		// Create a new proposal block from state/txs from the mempool.
 		var err error
-		block, blockParts, err = cs.createProposalBlock()
+		block, blockParts, err = cs.createProposalBlock(ctx)
 		if block == nil || err != nil {
 			return
 		}
@@ -1313,7 +1395,7 @@ func (cs *State) isProposalComplete() bool {
 //
 // NOTE: keep it side-effect free for clarity.
 // CONTRACT: cs.privValidator is not nil.
-func (cs *State) createProposalBlock() (block *types.Block, blockParts *types.PartSet, err error) {
+func (cs *State) createProposalBlock(ctx context.Context) (block *types.Block, blockParts *types.PartSet, err error) {
 	if cs.privValidator == nil {
 		return nil, nil, errors.New("entered createProposalBlock with privValidator being nil")
 	}
@@ -1343,7 +1425,7 @@ func (cs *State) createProposalBlock() (block *types.Block, blockParts *types.Pa
 
 	proposerAddr := cs.privValidatorPubKey.Address()
 
-	return cs.blockExec.CreateProposalBlock(cs.Height, cs.state, commit, proposerAddr)
+	return cs.blockExec.CreateProposalBlock(ctx, cs.Height, cs.state, commit, proposerAddr)
 }
 
 // Enter: `timeoutPropose` after entering Propose.
@@ -1369,6 +1451,12 @@ func (cs *State) enterPrevote(ctx context.Context, height int64, round int32) {
 
 	logger.Debug("entering prevote step", "current", fmt.Sprintf("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 
+	cs.resetBlockProfile(height, round)
+	cs.stepTimes.prevote = tmtime.Now()
+	if !cs.stepTimes.propose.IsZero() {
+		cs.blockProfile.ProposalWait = cs.stepTimes.prevote.Sub(cs.stepTimes.propose)
+	}
+
 	// Sign and broadcast vote as necessary
 	cs.doPrevote(ctx, height, round)
 
@@ -1402,6 +1490,20 @@ func (cs *State) defaultDoPrevote(ctx context.Context, height int64, round int32
 		return
 	}
 
+	// Let the application validate the proposal block. If it rejects the
+	// block, prevote nil.
+	accepted, err := cs.blockExec.ProcessProposal(ctx, cs.ProposalBlock)
+	if err != nil {
+		logger.Error("prevote step: failed to process the ProposalBlock", "err", err)
+		cs.signAddVote(ctx, tmproto.PrevoteType, nil, types.PartSetHeader{})
+		return
+	}
+	if !accepted {
+		logger.Error("prevote step: the application rejected the ProposalBlock")
+		cs.signAddVote(ctx, tmproto.PrevoteType, nil, types.PartSetHeader{})
+		return
+	}
+
 	// Prevote cs.ProposalBlock
 	// NOTE: the proposal signature is validated when it is received,
 	// and the proposal block parts are validated as they are received (against the merkle hash in the proposal)
@@ -1459,6 +1561,15 @@ func (cs *State) enterPrecommit(ctx context.Context, height int64, round int32)
 
 	logger.Debug("entering precommit step", "current", fmt.Sprintf("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 
+	cs.resetBlockProfile(height, round)
+	cs.stepTimes.precommit = tmtime.Now()
+	// The polka may belong to a round skipped to, before its prevotes were
+	// recorded.
+	if cs.stepTimes.prevoteQuorum.IsZero() && cs.Votes.Prevotes(round).HasTwoThirdsMajority() {
+		cs.stepTimes.prevoteQuorum = cs.stepTimes.precommit
+	}
+	cs.blockProfile.PrevoteQuorum = quorumWait(cs.stepTimes.prevote, cs.stepTimes.prevoteQuorum)
+
 	defer func() {
 		// Done enterPrecommit:
 		cs.updateRoundStep(round, cstypes.RoundStepPrecommit)
@@ -1504,6 +1615,7 @@ func (cs *State) enterPrecommit(ctx context.Context, height int64, round int32)
 			if err := cs.eventBus.PublishEventUnlock(ctx, cs.RoundStateEvent()); err != nil {
 				logger.Error("failed publishing event unlock", "err", err)
 			}
+			cs.recordLockChange(ctx, round, types.LockChangeUnlock, types.LockChangeReasonNilPolka, nil)
 		}
 
 		cs.signAddVote(ctx, tmproto.PrecommitType, nil, types.PartSetHeader{})
@@ -1520,6 +1632,7 @@ func (cs *State) enterPrecommit(ctx context.Context, height int64, round int32)
 		if err := cs.eventBus.PublishEventRelock(ctx, cs.RoundStateEvent()); err != nil {
 			logger.Error("failed publishing event relock", "err", err)
 		}
+		cs.recordLockChange(ctx, round, types.LockChangeRelock, types.LockChangeReasonPolka, blockID.Hash)
 
 		cs.signAddVote(ctx, tmproto.PrecommitType, blockID.Hash, blockID.PartSetHeader)
 		return
@@ -1541,6 +1654,7 @@ func (cs *State) enterPrecommit(ctx context.Context, height int64, round int32)
 		if err := cs.eventBus.PublishEventLock(ctx, cs.RoundStateEvent()); err != nil {
 			logger.Error("failed publishing event lock", "err", err)
 		}
+		cs.recordLockChange(ctx, round, types.LockChangeLock, types.LockChangeReasonPolka, blockID.Hash)
 
 		cs.signAddVote(ctx, tmproto.PrecommitType, blockID.Hash, blockID.PartSetHeader)
 		return
@@ -1551,6 +1665,7 @@ func (cs *State) enterPrecommit(ctx context.Context, height int64, round int32)
 	// The +2/3 prevotes for this round is the POL for our unlock.
 	logger.Debug("precommit step; +2/3 prevotes for a block we do not have; voting nil", "block_id", blockID)
 
+	wasLocked := cs.LockedBlock != nil
 	cs.LockedRound = -1
 	cs.LockedBlock = nil
 	cs.LockedBlockParts = nil
@@ -1563,6 +1678,9 @@ func (cs *State) enterPrecommit(ctx context.Context, height int64, round int32)
 	if err := cs.eventBus.PublishEventUnlock(ctx, cs.RoundStateEvent()); err != nil {
 		logger.Error("failed publishing event unlock", "err", err)
 	}
+	if wasLocked {
+		cs.recordLockChange(ctx, round, types.LockChangeUnlock, types.LockChangeReasonUnknownBlockPolka, nil)
+	}
 
 	cs.signAddVote(ctx, tmproto.PrecommitType, nil, types.PartSetHeader{})
 }
@@ -1613,6 +1731,14 @@ func (cs *State) enterCommit(ctx context.Context, height int64, commitRound int3
 
 	logger.Debug("entering commit step", "current", fmt.Sprintf("%v/%v/%v", cs.Height, cs.Round, cs.Step))
 
+	cs.resetBlockProfile(height, commitRound)
+	// The commit follows +2/3 precommits for the block, which may belong to a
+	// round skipped to, before its precommits were recorded.
+	if cs.stepTimes.precommitQuorum.IsZero() {
+		cs.stepTimes.precommitQuorum = tmtime.Now()
+	}
+	cs.blockProfile.PrecommitQuorum = quorumWait(cs.stepTimes.precommit, cs.stepTimes.precommitQuorum)
+
 	defer func() {
 		// Done enterCommit:
 		// keep cs.Round the same, commitRound points to the right Precommits set.
@@ -1767,14 +1893,16 @@ func (cs *State) finalizeCommit(ctx context.Context, height int64) {
 
 	// Execute and commit the block, update and save the state, and update the mempool.
 	// NOTE The block.AppHash wont reflect these txs until the next block.
-	stateCopy, err := cs.blockExec.ApplyBlock(ctx,
+	stateCopy, err := cs.blockExec.ApplyBlockWithProfile(ctx,
 		stateCopy,
 		types.BlockID{
 			Hash:          block.Hash(),
 			PartSetHeader: blockParts.Header(),
 		},
 		block,
+		cs.blockProfile,
 	)
+	cs.resetBlockProfile(0, 0)
 	if err != nil {
 		logger.Error("failed to apply block", "err", err)
 		return
@@ -2011,6 +2139,8 @@ func (cs *State) addProposalBlockPart(
 				cs.ValidRound = cs.Round
 				cs.ValidBlock = cs.ProposalBlock
 				cs.ValidBlockParts = cs.ProposalBlockParts
+				cs.recordLockChange(ctx, cs.Round, types.LockChangeValidBlock,
+					types.LockChangeReasonCompleteProposal, blockID.Hash)
 			}
 			// TODO: In case there is +2/3 majority in Prevotes set for some
 			// block and cs.ProposalBlock contains different block, either
@@ -2107,6 +2237,10 @@ func (cs *State) addVote(
 			return
 		}
 
+		if err := cs.verifyVoteExtension(ctx, vote, cs.LastValidators, cs.LastCommit); err != nil {
+			return false, err
+		}
+
 		added, err = cs.LastCommit.AddVote(vote)
 		if !added {
 			return
@@ -2136,6 +2270,10 @@ func (cs *State) addVote(
 		return
 	}
 
+	if err := cs.verifyVoteExtension(ctx, vote, cs.Validators, cs.Votes.Precommits(vote.Round)); err != nil {
+		return false, err
+	}
+
 	height := cs.Height
 	added, err = cs.Votes.AddVote(vote, peerID)
 	if !added {
@@ -2152,6 +2290,7 @@ func (cs *State) addVote(
 	case tmproto.PrevoteType:
 		prevotes := cs.Votes.Prevotes(vote.Round)
 		cs.logger.Debug("added vote to prevote", "vote", vote, "prevotes", prevotes.StringShort())
+		cs.recordQuorum(vote, prevotes)
 
 		// If +2/3 prevotes for a block or nil for *any* round:
 		if blockID, ok := prevotes.TwoThirdsMajority(); ok {
@@ -2175,6 +2314,7 @@ func (cs *State) addVote(
 				if err := cs.eventBus.PublishEventUnlock(ctx, cs.RoundStateEvent()); err != nil {
 					return added, err
 				}
+				cs.recordLockChange(ctx, vote.Round, types.LockChangeUnlock, types.LockChangeReasonLaterPolka, nil)
 			}
 
 			// Update Valid* if we can.
@@ -2185,6 +2325,8 @@ func (cs *State) addVote(
 					cs.ValidRound = vote.Round
 					cs.ValidBlock = cs.ProposalBlock
 					cs.ValidBlockParts = cs.ProposalBlockParts
+					cs.recordLockChange(ctx, vote.Round, types.LockChangeValidBlock,
+						types.LockChangeReasonPolka, blockID.Hash)
 				} else {
 					cs.logger.Debug(
 						"valid block we do not know about; set ProposalBlock=nil",
@@ -2236,6 +2378,7 @@ func (cs *State) addVote(
 			"validator", vote.ValidatorAddress.String(),
 			"vote_timestamp", vote.Timestamp,
 			"data", precommits.LogString())
+		cs.recordQuorum(vote, precommits)
 
 		blockID, ok := precommits.TwoThirdsMajority()
 		if ok {
@@ -2263,6 +2406,46 @@ func (cs *State) addVote(
 	return added, err
 }
 
+// verifyVoteExtension asks the application to verify the extension of a
+// precommit for a block from another validator of vals, once its signature,
+// which covers the extension, is checked. precommits is the set the vote is
+// added to.
+func (cs *State) verifyVoteExtension(
+	ctx context.Context,
+	vote *types.Vote,
+	vals *types.ValidatorSet,
+	precommits *types.VoteSet,
+) error {
+	if vote.Type != tmproto.PrecommitType || vote.BlockID.IsZero() {
+		return nil
+	}
+	if cs.privValidatorPubKey != nil && bytes.Equal(vote.ValidatorAddress, cs.privValidatorPubKey.Address()) {
+		return nil
+	}
+
+	_, val := vals.GetByIndex(vote.ValidatorIndex)
+	if val == nil {
+		return types.ErrVoteInvalidValidatorIndex
+	}
+	// The duplicates of a vote were already verified.
+	if existing := precommits.GetByIndex(vote.ValidatorIndex); existing != nil &&
+		bytes.Equal(existing.Signature, vote.Signature) {
+		return nil
+	}
+	if err := vote.Verify(cs.state.ChainID, val.PubKey); err != nil {
+		return err
+	}
+
+	accepted, err := cs.blockExec.VerifyVoteExtension(ctx, vote)
+	if err != nil {
+		return err
+	}
+	if !accepted {
+		return ErrInvalidVoteExtension
+	}
+	return nil
+}
+
 // CONTRACT: cs.privValidator is not nil.
 func (cs *State) signVote(
 	ctx context.Context,
@@ -2293,6 +2476,15 @@ func (cs *State) signVote(
 		BlockID:          types.BlockID{Hash: hash, PartSetHeader: header},
 	}
 
+	// Precommits for a block carry the data the application attaches to them.
+	if msgType == tmproto.PrecommitType && len(hash) != 0 {
+		ext, err := cs.blockExec.ExtendVote(ctx, vote)
+		if err != nil {
+			return nil, err
+		}
+		vote.Extension = ext
+	}
+
 	v := vote.ToProto()
 
 	// If the signedMessageType is for precommit,
@@ -2475,8 +2667,8 @@ func CompareHRS(h1 int64, r1 int32, s1 cstypes.RoundStepType, h2 int64, r2 int32
 }
 
 // repairWalFile decodes messages from src (until the decoder errors) and
-// writes them to dst.
-func repairWalFile(src, dst string) error {
+// writes them to dst, both encrypted with c if it is not nil.
+func repairWalFile(src, dst string, c *atrest.Cipher) error {
 	in, err := os.Open(src)
 	if err != nil {
 		return err
@@ -2493,6 +2685,8 @@ func repairWalFile(src, dst string) error {
 		dec = NewWALDecoder(in)
 		enc = NewWALEncoder(out)
 	)
+	dec.SetCipher(c)
+	enc.SetCipher(c)
 
 	// best-case repair (until first error is encountered)
 	for {
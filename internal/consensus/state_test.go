@@ -11,10 +11,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/crypto/tmhash"
 	cstypes "github.com/tendermint/tendermint/internal/consensus/types"
 	"github.com/tendermint/tendermint/internal/eventbus"
 	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/libs/log"
 	tmrand "github.com/tendermint/tendermint/libs/rand"
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
@@ -220,7 +222,7 @@ func TestStateBadProposal(t *testing.T) {
 	proposalCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryCompleteProposal)
 	voteCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryVote)
 
-	propBlock, _, err := cs1.createProposalBlock() // changeProposer(t, cs1, vs2)
+	propBlock, _, err := cs1.createProposalBlock(ctx) // changeProposer(t, cs1, vs2)
 	require.NoError(t, err)
 
 	// make the second validator the proposer by incrementing round
@@ -275,6 +277,152 @@ func TestStateBadProposal(t *testing.T) {
 	signAddVotes(ctx, t, config, cs1, tmproto.PrecommitType, propBlock.Hash(), bps2.Header(), vs2)
 }
 
+// rejectingApp rejects all the proposals.
+type rejectingApp struct {
+	*kvstore.Application
+}
+
+func (app rejectingApp) ProcessProposal(abci.RequestProcessProposal) abci.ResponseProcessProposal {
+	return abci.ResponseProcessProposal{Status: abci.ResponseProcessProposal_REJECT}
+}
+
+func TestStateRejectedProposal(t *testing.T) {
+	config := configSetup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, privVals := randGenesisState(ctx, t, config, 1, false, 10)
+	app := rejectingApp{kvstore.NewApplication()}
+	cs1 := newState(ctx, t, log.TestingLogger(), state, privVals[0], app)
+	height, round := cs1.Height, cs1.Round
+
+	proposalCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryCompleteProposal)
+	voteCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryVote)
+
+	startTestRound(ctx, cs1, height, round)
+
+	// The proposal is valid, but the application rejects it: prevote nil.
+	ensureNewProposal(t, proposalCh, height, round)
+	ensurePrevoteMatch(t, voteCh, height, round, nil)
+}
+
+// extendingApp attaches an extension to its precommits and only accepts the
+// same extension from the other validators.
+type extendingApp struct {
+	*kvstore.Application
+}
+
+var testVoteExtension = []byte("extension")
+
+func (app extendingApp) ExtendVote(abci.RequestExtendVote) abci.ResponseExtendVote {
+	return abci.ResponseExtendVote{VoteExtension: testVoteExtension}
+}
+
+func (app extendingApp) VerifyVoteExtension(req abci.RequestVerifyVoteExtension) abci.ResponseVerifyVoteExtension {
+	if !bytes.Equal(req.VoteExtension, testVoteExtension) {
+		return abci.ResponseVerifyVoteExtension{Status: abci.ResponseVerifyVoteExtension_REJECT}
+	}
+	return abci.ResponseVerifyVoteExtension{Status: abci.ResponseVerifyVoteExtension_ACCEPT}
+}
+
+func TestStateVoteExtensions(t *testing.T) {
+	config := configSetup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, privVals := randGenesisState(ctx, t, config, 2, false, 10)
+	cs1 := newState(ctx, t, log.TestingLogger(), state, privVals[0], extendingApp{kvstore.NewApplication()})
+	vs2 := newValidatorStub(privVals[1], 1)
+	incrementHeight(vs2)
+	height, round := cs1.Height, cs1.Round
+
+	voteCh := subscribeBuffered(ctx, t, cs1.eventBus, types.EventQueryVote, 4)
+	newBlockCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryNewBlock)
+
+	startTestRound(ctx, cs1, height, round)
+	ensurePrevote(t, voteCh, height, round)
+
+	rs := cs1.GetRoundState()
+	blockID := types.BlockID{Hash: rs.ProposalBlock.Hash(), PartSetHeader: rs.ProposalBlockParts.Header()}
+	signAddVotes(ctx, t, config, cs1, tmproto.PrevoteType, blockID.Hash, blockID.PartSetHeader, vs2)
+	ensurePrevote(t, voteCh, height, round)
+
+	// Our precommit carries the extension of the application.
+	ensurePrecommit(t, voteCh, height, round)
+	rs = cs1.GetRoundState()
+	require.Equal(t, testVoteExtension, rs.Votes.Precommits(round).GetByIndex(0).Extension)
+
+	signPrecommit := func(extension []byte) *types.Vote {
+		return signExtendedPrecommit(ctx, t, config, vs2, height, round, blockID, extension)
+	}
+
+	// The application rejects the extension of the precommit of vs2: it is
+	// ignored.
+	addVotes(cs1, signPrecommit([]byte("other extension")))
+	ensureNoNewEventOnChannel(t, voteCh)
+
+	addVotes(cs1, signPrecommit(testVoteExtension))
+	ensurePrecommit(t, voteCh, height, round)
+	ensureNewBlock(t, newBlockCh, height)
+
+	// The extensions are part of the commit.
+	commit := cs1.blockStore.LoadSeenCommit()
+	require.NotNil(t, commit)
+	for _, sig := range commit.Signatures {
+		assert.Equal(t, testVoteExtension, sig.Extension)
+	}
+}
+
+// The extension of a precommit for the last height, received while waiting
+// for the commit timeout, is verified as well.
+func TestStateLateVoteExtension(t *testing.T) {
+	config := configSetup(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, privVals := randGenesisState(ctx, t, config, 4, false, 10)
+	cs1 := newState(ctx, t, log.TestingLogger(), state, privVals[0], extendingApp{kvstore.NewApplication()})
+	// Wait for the late precommit at the new height.
+	cs1.config.SkipTimeoutCommit = false
+	cs1.config.TimeoutCommit = time.Minute
+	vss := make([]*validatorStub, len(privVals))
+	for i := range vss {
+		vss[i] = newValidatorStub(privVals[i], int32(i))
+	}
+	incrementHeight(vss[1:]...)
+	height, round := cs1.Height, cs1.Round
+
+	voteCh := subscribeBuffered(ctx, t, cs1.eventBus, types.EventQueryVote, 4)
+	newBlockCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryNewBlock)
+
+	startTestRound(ctx, cs1, height, round)
+	ensurePrevote(t, voteCh, height, round)
+
+	rs := cs1.GetRoundState()
+	blockID := types.BlockID{Hash: rs.ProposalBlock.Hash(), PartSetHeader: rs.ProposalBlockParts.Header()}
+	signAddVotes(ctx, t, config, cs1, tmproto.PrevoteType, blockID.Hash, blockID.PartSetHeader, vss[1], vss[2])
+	ensurePrevote(t, voteCh, height, round)
+	ensurePrevote(t, voteCh, height, round)
+	ensurePrecommit(t, voteCh, height, round)
+
+	addVotes(cs1,
+		signExtendedPrecommit(ctx, t, config, vss[1], height, round, blockID, testVoteExtension),
+		signExtendedPrecommit(ctx, t, config, vss[2], height, round, blockID, testVoteExtension))
+	ensurePrecommit(t, voteCh, height, round)
+	ensurePrecommit(t, voteCh, height, round)
+	ensureNewBlock(t, newBlockCh, height)
+
+	// The application rejects the extension of the late precommit: it is not
+	// added to the last commit.
+	addVotes(cs1, signExtendedPrecommit(ctx, t, config, vss[3], height, round, blockID, []byte("other extension")))
+	ensureNoNewEventOnChannel(t, voteCh)
+	assert.Nil(t, cs1.GetRoundState().LastCommit.GetByIndex(3))
+
+	addVotes(cs1, signExtendedPrecommit(ctx, t, config, vss[3], height, round, blockID, testVoteExtension))
+	ensurePrecommit(t, voteCh, height, round)
+	assert.Equal(t, testVoteExtension, cs1.GetRoundState().LastCommit.GetByIndex(3).Extension)
+}
+
 func TestStateOversizedBlock(t *testing.T) {
 	config := configSetup(t)
 	ctx, cancel := context.WithCancel(context.Background())
@@ -290,7 +438,7 @@ func TestStateOversizedBlock(t *testing.T) {
 	timeoutProposeCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryTimeoutPropose)
 	voteCh := subscribe(ctx, t, cs1.eventBus, types.EventQueryVote)
 
-	propBlock, _, err := cs1.createProposalBlock()
+	propBlock, _, err := cs1.createProposalBlock(ctx)
 	require.NoError(t, err)
 	propBlock.Data.Txs = []types.Tx{tmrand.Bytes(2001)}
 	propBlock.Header.DataHash = propBlock.Data.Hash()
@@ -850,6 +998,20 @@ func TestStateLockPOLUnlock(t *testing.T) {
 
 	signAddVotes(ctx, t, config, cs1, tmproto.PrecommitType, nil, types.PartSetHeader{}, vs2, vs3)
 	ensureNewRound(t, newRoundCh, height, round+1)
+
+
+	// the lock changes were recorded
+	changes := cs1.GetLockHistory()
+	require.Len(t, changes, 3)
+	require.Equal(t, types.LockChangeValidBlock, changes[0].Kind)
+	require.Equal(t, types.LockChangeLock, changes[1].Kind)
+	require.Equal(t, types.LockChangeReasonPolka, changes[1].Reason)
+	require.EqualValues(t, 0, changes[1].LockedRound)
+	require.Equal(t, tmbytes.HexBytes(theBlockHash), changes[1].BlockHash)
+	require.Equal(t, types.LockChangeUnlock, changes[2].Kind)
+	require.Equal(t, types.LockChangeReasonLaterPolka, changes[2].Reason)
+	require.EqualValues(t, 1, changes[2].Round)
+	require.EqualValues(t, -1, changes[2].LockedRound)
 }
 
 // 4 vals, v1 locks on proposed block in the first round but the other validators only prevote
@@ -2106,3 +2268,43 @@ func subscribe(
 	}()
 	return ch
 }
+
+// subscribeBuffered is like subscribe, but queues up to limit events, for the
+// tests reading events published back to back, e.g. the blocks committed by a
+// single validator.
+func subscribeBuffered(
+	ctx context.Context,
+	t *testing.T,
+	eventBus *eventbus.EventBus,
+	q tmpubsub.Query,
+	limit int,
+) <-chan tmpubsub.Message {
+	t.Helper()
+	sub, err := eventBus.SubscribeWithArgs(ctx, tmpubsub.SubscribeArgs{
+		ClientID: testSubscriber,
+		Query:    q,
+		Limit:    limit,
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe %q to %v: %v", testSubscriber, q, err)
+	}
+	ch := make(chan tmpubsub.Message)
+	go func() {
+		for {
+			next, err := sub.Next(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				t.Errorf("Subscription for %v unexpectedly terminated: %v", q, err)
+				return
+			}
+			select {
+			case ch <- next:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
+	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/gogo/protobuf/proto"
 
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/internal/jsontypes"
 	auto "github.com/tendermint/tendermint/internal/libs/autofile"
 	"github.com/tendermint/tendermint/libs/log"
@@ -82,7 +85,8 @@ type BaseWAL struct {
 
 	group *auto.Group
 
-	enc *WALEncoder
+	enc    *WALEncoder
+	cipher *atrest.Cipher
 
 	flushTicker   *time.Ticker
 	flushInterval time.Duration
@@ -117,6 +121,13 @@ func (wal *BaseWAL) SetFlushInterval(i time.Duration) {
 	wal.flushInterval = i
 }
 
+// SetCipher makes the WAL encrypt the messages it writes with c, and decrypt
+// the messages it reads. It must be called before the WAL is started.
+func (wal *BaseWAL) SetCipher(c *atrest.Cipher) {
+	wal.cipher = c
+	wal.enc.SetCipher(c)
+}
+
 func (wal *BaseWAL) Group() *auto.Group {
 	return wal.group
 }
@@ -257,6 +268,7 @@ func (wal *BaseWAL) SearchForEndHeight(
 		}
 
 		dec := NewWALDecoder(gr)
+		dec.SetCipher(wal.cipher)
 		for {
 			msg, err = dec.Decode()
 			if err == io.EOF {
@@ -294,13 +306,23 @@ func (wal *BaseWAL) SearchForEndHeight(
 // A WALEncoder writes custom-encoded WAL messages to an output stream.
 //
 // Format: 4 bytes CRC sum + 4 bytes length + arbitrary-length value
+//
+// With a cipher, the value is encrypted, and the CRC sum and length are those
+// of the encrypted value.
 type WALEncoder struct {
-	wr io.Writer
+	wr     io.Writer
+	cipher *atrest.Cipher
 }
 
 // NewWALEncoder returns a new encoder that writes to wr.
 func NewWALEncoder(wr io.Writer) *WALEncoder {
-	return &WALEncoder{wr}
+	return &WALEncoder{wr: wr}
+}
+
+// SetCipher makes the encoder encrypt the messages with c. A nil c disables
+// encryption.
+func (enc *WALEncoder) SetCipher(c *atrest.Cipher) {
+	enc.cipher = c
 }
 
 // Encode writes the custom encoding of v to the stream. It returns an error if
@@ -320,12 +342,17 @@ func (enc *WALEncoder) Encode(v *TimedWALMessage) error {
 	if err != nil {
 		panic(fmt.Errorf("encode timed wall message failure: %w", err))
 	}
+	if len(data) > maxMsgSizeBytes {
+		return fmt.Errorf("msg is too big: %d bytes, max: %d bytes", len(data), maxMsgSizeBytes)
+	}
+	if enc.cipher != nil {
+		if data, err = enc.cipher.Seal(data, nil); err != nil {
+			return fmt.Errorf("encrypting msg: %w", err)
+		}
+	}
 
 	crc := crc32.Checksum(data, crc32c)
 	length := uint32(len(data))
-	if length > maxMsgSizeBytes {
-		return fmt.Errorf("msg is too big: %d bytes, max: %d bytes", length, maxMsgSizeBytes)
-	}
 	totalLength := 8 + int(length)
 
 	msg := make([]byte, totalLength)
@@ -362,12 +389,19 @@ func (e DataCorruptionError) Cause() error {
 // It will also compare the checksums and make sure data size is equal to the
 // length from the header. If that is not the case, error will be returned.
 type WALDecoder struct {
-	rd io.Reader
+	rd     io.Reader
+	cipher *atrest.Cipher
 }
 
 // NewWALDecoder returns a new decoder that reads from rd.
 func NewWALDecoder(rd io.Reader) *WALDecoder {
-	return &WALDecoder{rd}
+	return &WALDecoder{rd: rd}
+}
+
+// SetCipher makes the decoder decrypt the encrypted messages with c. The
+// messages written without encryption are decoded in any case.
+func (dec *WALDecoder) SetCipher(c *atrest.Cipher) {
+	dec.cipher = c
 }
 
 // Decode reads the next custom-encoded value from its reader and returns it.
@@ -390,11 +424,11 @@ func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 	}
 	length := binary.BigEndian.Uint32(b)
 
-	if length > maxMsgSizeBytes {
+	if length > maxMsgSizeBytes+atrest.Overhead {
 		return nil, DataCorruptionError{fmt.Errorf(
 			"length %d exceeded maximum possible value of %d bytes",
 			length,
-			maxMsgSizeBytes)}
+			maxMsgSizeBytes+atrest.Overhead)}
 	}
 
 	data := make([]byte, length)
@@ -409,6 +443,17 @@ func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 		return nil, DataCorruptionError{fmt.Errorf("checksums do not match: read: %v, actual: %v", crc, actualCRC)}
 	}
 
+	// The data passed the checksum, so a failure to decrypt it is not
+	// corruption: the key is missing or wrong.
+	if atrest.IsSealed(data) {
+		if dec.cipher == nil {
+			return nil, errors.New("the WAL is encrypted, but no encryption key provider is configured")
+		}
+		if data, err = dec.cipher.Open(data, nil); err != nil {
+			return nil, fmt.Errorf("decrypting WAL message: %w", err)
+		}
+	}
+
 	var res = new(tmcons.TimedWALMessage)
 	err = proto.Unmarshal(data, res)
 	if err != nil {
@@ -427,6 +472,130 @@ func (dec *WALDecoder) Decode() (*TimedWALMessage, error) {
 	return tMsgWal, err
 }
 
+// ReencryptWAL rewrites the files of the WAL at walFile, the head and the
+// rotated files, with the current key of c: the messages encrypted with other
+// keys, and the messages written without encryption. The node must not be
+// running.
+func ReencryptWAL(walFile string, c *atrest.Cipher) error {
+	rotated, err := filepath.Glob(walFile + ".[0-9][0-9][0-9]*")
+	if err != nil {
+		return err
+	}
+	for _, path := range append(rotated, walFile) {
+		if !tmos.FileExists(path) {
+			continue
+		}
+		if err := reencryptWALFile(path, c); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// WALFiles returns the paths of the existing files of the WAL at walFile,
+// from the newest, the head, to the oldest rotated file.
+func WALFiles(walFile string) ([]string, error) {
+	rotated, err := filepath.Glob(walFile + ".[0-9][0-9][0-9]*")
+	if err != nil {
+		return nil, err
+	}
+	// The indexes have at least 3 digits, so the longer names come later.
+	sort.Slice(rotated, func(i, j int) bool {
+		if len(rotated[i]) != len(rotated[j]) {
+			return len(rotated[i]) > len(rotated[j])
+		}
+		return rotated[i] > rotated[j]
+	})
+
+	var files []string
+	if tmos.FileExists(walFile) {
+		files = append(files, walFile)
+	}
+	return append(files, rotated...), nil
+}
+
+// WALLastEndHeight returns the height of the last #ENDHEIGHT message of the
+// WAL at walFile, reading its files from the newest, or -1 if it has none.
+// The messages of a file following a corrupted one are skipped.
+func WALLastEndHeight(walFile string, c *atrest.Cipher) (int64, error) {
+	files, err := WALFiles(walFile)
+	if err != nil {
+		return -1, err
+	}
+	for _, path := range files {
+		height, err := lastEndHeight(path, c)
+		if err != nil {
+			return -1, fmt.Errorf("%s: %w", path, err)
+		}
+		if height >= 0 {
+			return height, nil
+		}
+	}
+	return -1, nil
+}
+
+func lastEndHeight(path string, c *atrest.Cipher) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return -1, err
+	}
+	defer f.Close()
+
+	height := int64(-1)
+	dec := NewWALDecoder(f)
+	dec.SetCipher(c)
+	for {
+		msg, err := dec.Decode()
+		if errors.Is(err, io.EOF) || IsDataCorruptionError(err) {
+			return height, nil
+		} else if err != nil {
+			return -1, err
+		}
+		if m, ok := msg.Msg.(EndHeightMessage); ok {
+			height = m.Height
+		}
+	}
+}
+
+func reencryptWALFile(path string, c *atrest.Cipher) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".reencrypt-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(out.Name()) //nolint:errcheck // it is renamed on success
+	defer out.Close()
+
+	dec := NewWALDecoder(in)
+	dec.SetCipher(c)
+	enc := NewWALEncoder(out)
+	enc.SetCipher(c)
+	for {
+		msg, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+	}
+
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(out.Name(), path)
+}
+
 type nilWAL struct{}
 
 var _ WAL = nilWAL{}
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"path/filepath"
 
 	"testing"
@@ -13,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/crypto/merkle"
 	"github.com/tendermint/tendermint/internal/consensus/types"
 	"github.com/tendermint/tendermint/internal/libs/autofile"
@@ -103,6 +106,75 @@ func TestWALEncoderDecoder(t *testing.T) {
 	}
 }
 
+func TestWALEncoderDecoderEncrypted(t *testing.T) {
+	now := tmtime.Now()
+	msg := TimedWALMessage{Time: now, Msg: timeoutInfo{Duration: time.Second, Height: 1, Round: 1, Step: types.RoundStepPropose}}
+	c := atrest.NewCipher(atrest.GenKeyring())
+
+	b := new(bytes.Buffer)
+	enc := NewWALEncoder(b)
+	enc.SetCipher(c)
+	require.NoError(t, enc.Encode(&msg))
+	encrypted := b.Bytes()
+
+	dec := NewWALDecoder(bytes.NewReader(encrypted))
+	dec.SetCipher(c)
+	decoded, err := dec.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, msg.Time.UTC(), decoded.Time)
+	assert.Equal(t, msg.Msg, decoded.Msg)
+
+	// A WAL encrypted with an unknown key, or without a key, cannot be read,
+	// but this is not data corruption.
+	for _, c := range []*atrest.Cipher{nil, atrest.NewCipher(atrest.GenKeyring())} {
+		dec = NewWALDecoder(bytes.NewReader(encrypted))
+		dec.SetCipher(c)
+		_, err = dec.Decode()
+		assert.Error(t, err)
+		assert.False(t, IsDataCorruptionError(err))
+	}
+
+	// The messages written without encryption are read with a key.
+	b.Reset()
+	require.NoError(t, NewWALEncoder(b).Encode(&msg))
+	dec = NewWALDecoder(b)
+	dec.SetCipher(c)
+	decoded, err = dec.Decode()
+	require.NoError(t, err)
+	assert.Equal(t, msg.Msg, decoded.Msg)
+}
+
+func TestReencryptWAL(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewNopLogger()
+	walBody, err := WALWithNBlocks(ctx, t, logger, 3)
+	require.NoError(t, err)
+	walFile := tempWALWithData(t, walBody)
+
+	keyring := atrest.GenKeyring()
+	c := atrest.NewCipher(keyring)
+	require.NoError(t, ReencryptWAL(walFile, c))
+
+	// The WAL can only be read with the key after the rotation of the key and
+	// the removal of the previous one.
+	keyring.Rotate()
+	require.NoError(t, ReencryptWAL(walFile, c))
+	require.NoError(t, keyring.Remove(1))
+
+	wal, err := NewWAL(ctx, logger, walFile)
+	require.NoError(t, err)
+	_, _, err = wal.SearchForEndHeight(2, &WALSearchOptions{})
+	assert.Error(t, err)
+
+	wal.SetCipher(atrest.NewCipher(keyring))
+	gr, found, err := wal.SearchForEndHeight(2, &WALSearchOptions{})
+	require.NoError(t, err)
+	require.True(t, found)
+	t.Cleanup(func() { _ = gr.Close() })
+}
+
 func TestWALWrite(t *testing.T) {
 	walDir := t.TempDir()
 	walFile := filepath.Join(walDir, "wal")
@@ -213,3 +285,32 @@ func TestWALPeriodicSync(t *testing.T) {
 		gr.Close()
 	}
 }
+
+func BenchmarkWALEncoder(b *testing.B) {
+	msg := &TimedWALMessage{
+		Time: tmtime.Now(),
+		Msg: msgInfo{Msg: &BlockPartMessage{
+			Height: 1,
+			Round:  1,
+			Part: &tmtypes.Part{
+				Index: 1,
+				Bytes: make([]byte, tmtypes.BlockPartSizeBytes),
+				Proof: merkle.Proof{Total: 1, Index: 1, LeafHash: make([]byte, 32)},
+			},
+		}},
+	}
+	for _, encrypted := range []bool{false, true} {
+		b.Run(fmt.Sprintf("encrypted=%t", encrypted), func(b *testing.B) {
+			enc := NewWALEncoder(io.Discard)
+			if encrypted {
+				enc.SetCipher(atrest.NewCipher(atrest.GenKeyring()))
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := enc.Encode(msg); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
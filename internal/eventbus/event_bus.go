@@ -16,6 +16,8 @@ import (
 type Subscription interface {
 	ID() string
 	Next(context.Context) (tmpubsub.Message, error)
+	Pending() int
+	Skipped() uint64
 }
 
 // EventBus is a common bus for all events going through the system.
@@ -28,8 +30,14 @@ type EventBus struct {
 
 // NewDefault returns a new event bus with default options.
 func NewDefault(l log.Logger) *EventBus {
+	return New(l, tmpubsub.BufferCapacity(0))
+}
+
+// New returns a new event bus whose pubsub server is configured with the given
+// options, e.g. to enable its event log.
+func New(l log.Logger, options ...tmpubsub.Option) *EventBus {
 	logger := l.With("module", "eventbus")
-	pubsub := tmpubsub.NewServer(l, tmpubsub.BufferCapacity(0))
+	pubsub := tmpubsub.NewServer(l, options...)
 	b := &EventBus{pubsub: pubsub}
 	b.BaseService = *service.NewBaseService(logger, "EventBus", b)
 	return b
@@ -161,6 +169,49 @@ func (b *EventBus) PublishEventTx(ctx context.Context, data types.EventDataTx) e
 	return b.pubsub.PublishWithEvents(ctx, data, events)
 }
 
+// PublishEventTxExpired publishes the expiry of a transaction, which can be
+// subscribed to by its hash, e.g. "tm.event = 'TxExpired' AND tx.hash = 'XYZ'".
+func (b *EventBus) PublishEventTxExpired(ctx context.Context, data types.EventDataTxExpired) error {
+	tokens := strings.Split(types.TxHashKey, ".")
+	events := []abci.Event{
+		types.EventTxExpired,
+		{
+			Type: tokens[0],
+			Attributes: []abci.EventAttribute{
+				{
+					Key:   tokens[1],
+					Value: fmt.Sprintf("%X", data.Tx.Hash()),
+				},
+			},
+		},
+	}
+
+	return b.pubsub.PublishWithEvents(ctx, data, events)
+}
+
+// PublishEventCheckTx publishes the CheckTx response of a transaction, with
+// its events, which can be subscribed to by its hash or by the events, e.g.
+// "tm.event = 'CheckTx' AND fee.tier = 'high'".
+func (b *EventBus) PublishEventCheckTx(ctx context.Context, data types.EventDataCheckTx) error {
+	events := append([]abci.Event{}, data.Result.Events...)
+
+	// add Tendermint-reserved events
+	events = append(events, types.EventCheckTx)
+
+	tokens := strings.Split(types.TxHashKey, ".")
+	events = append(events, abci.Event{
+		Type: tokens[0],
+		Attributes: []abci.EventAttribute{
+			{
+				Key:   tokens[1],
+				Value: fmt.Sprintf("%X", data.Tx.Hash()),
+			},
+		},
+	})
+
+	return b.pubsub.PublishWithEvents(ctx, data, events)
+}
+
 func (b *EventBus) PublishEventNewRoundStep(ctx context.Context, data types.EventDataRoundState) error {
 	return b.Publish(ctx, types.EventNewRoundStepValue, data)
 }
@@ -197,10 +248,31 @@ func (b *EventBus) PublishEventLock(ctx context.Context, data types.EventDataRou
 	return b.Publish(ctx, types.EventLockValue, data)
 }
 
+func (b *EventBus) PublishEventLockChange(ctx context.Context, data types.EventDataLockChange) error {
+	return b.Publish(ctx, types.EventLockChangeValue, data)
+}
+
 func (b *EventBus) PublishEventValidatorSetUpdates(ctx context.Context, data types.EventDataValidatorSetUpdates) error {
 	return b.Publish(ctx, types.EventValidatorSetUpdatesValue, data)
 }
 
+func (b *EventBus) PublishEventValidatorSetUpdateScheduled(
+	ctx context.Context,
+	data types.EventDataValidatorSetUpdateScheduled,
+) error {
+	return b.Publish(ctx, types.EventValidatorSetUpdateScheduledValue, data)
+}
+
+// PublishEventForkDetected publishes the blocks committed by the peers at the
+// height of a fork.
+func (b *EventBus) PublishEventForkDetected(ctx context.Context, data types.EventDataForkDetected) error {
+	return b.Publish(ctx, types.EventForkDetectedValue, data)
+}
+
+func (b *EventBus) PublishEventBlockProfile(ctx context.Context, data types.EventDataBlockProfile) error {
+	return b.Publish(ctx, types.EventBlockProfileValue, data)
+}
+
 //-----------------------------------------------------------------------------
 
 // NopEventBus implements a types.BlockEventPublisher that discards all events.
@@ -225,3 +297,7 @@ func (NopEventBus) PublishEventTx(context.Context, types.EventDataTx) error {
 func (NopEventBus) PublishEventValidatorSetUpdates(context.Context, types.EventDataValidatorSetUpdates) error {
 	return nil
 }
+
+func (NopEventBus) PublishEventBlockProfile(context.Context, types.EventDataBlockProfile) error {
+	return nil
+}
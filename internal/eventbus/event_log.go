@@ -0,0 +1,223 @@
+package eventbus
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/jsontypes"
+	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
+	"github.com/tendermint/tendermint/types"
+)
+
+// EventLogStore is a pubsub.LogStore retaining the events published on the
+// bus in a database, so that the subscribers can catch up with the events
+// they missed across restarts of the node. The entries are keyed by sequence
+// number, and their values hold the time they were published, followed by
+// the JSON encoding of their data and events.
+type EventLogStore struct {
+	db dbm.DB
+
+	mtx    sync.Mutex
+	count  int       // number of entries stored
+	oldest time.Time // time of the oldest entry, zero if none
+}
+
+var _ tmpubsub.LogStore = (*EventLogStore)(nil)
+
+// eventLogValue is the JSON encoding of the data and events of an entry.
+type eventLogValue struct {
+	Data   json.RawMessage `json:"data"`
+	Events []abci.Event    `json:"events"`
+}
+
+// NewEventLogStore returns an event log store retaining the events in db,
+// which must not hold any other data.
+func NewEventLogStore(db dbm.DB) (*EventLogStore, error) {
+	s := &EventLogStore{db: db}
+
+	it, err := db.Iterator(nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	for ; it.Valid(); it.Next() {
+		if s.count == 0 {
+			if s.oldest, err = decodeEventLogTime(it.Value()); err != nil {
+				return nil, fmt.Errorf("invalid event log entry %X: %w", it.Key(), err)
+			}
+		}
+		s.count++
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func eventLogKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+func decodeEventLogTime(bz []byte) (time.Time, error) {
+	if len(bz) < 8 {
+		return time.Time{}, errors.New("truncated value")
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(bz))), nil
+}
+
+// Append implements pubsub.LogStore. The data of e must be tagged with
+// jsontypes, as the event data.
+func (s *EventLogStore) Append(e tmpubsub.LogEntry) error {
+	data, ok := e.Data.(jsontypes.Tagged)
+	if !ok {
+		return fmt.Errorf("type %T is not tagged", e.Data)
+	}
+	raw, err := jsontypes.Marshal(data)
+	if err != nil {
+		return err
+	}
+	bz, err := json.Marshal(eventLogValue{Data: raw, Events: e.Events})
+	if err != nil {
+		return err
+	}
+	value := make([]byte, 8, 8+len(bz))
+	binary.BigEndian.PutUint64(value, uint64(e.Time.UnixNano()))
+	value = append(value, bz...)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err := s.db.Set(eventLogKey(e.Seq), value); err != nil {
+		return err
+	}
+	if s.count == 0 {
+		s.oldest = e.Time
+	}
+	s.count++
+	return nil
+}
+
+// Scan implements pubsub.LogStore.
+func (s *EventLogStore) Scan(after uint64, fn func(tmpubsub.LogEntry) bool) error {
+	it, err := s.db.Iterator(eventLogKey(after+1), nil)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		e, err := decodeEventLogEntry(it.Key(), it.Value())
+		if err != nil {
+			return fmt.Errorf("invalid event log entry %X: %w", it.Key(), err)
+		}
+		if !fn(e) {
+			return nil
+		}
+	}
+	return it.Error()
+}
+
+func decodeEventLogEntry(key, value []byte) (tmpubsub.LogEntry, error) {
+	if len(key) != 8 {
+		return tmpubsub.LogEntry{}, errors.New("invalid key")
+	}
+	t, err := decodeEventLogTime(value)
+	if err != nil {
+		return tmpubsub.LogEntry{}, err
+	}
+	var v eventLogValue
+	if err := json.Unmarshal(value[8:], &v); err != nil {
+		return tmpubsub.LogEntry{}, err
+	}
+	var data types.TMEventData
+	if err := jsontypes.Unmarshal(v.Data, &data); err != nil {
+		return tmpubsub.LogEntry{}, err
+	}
+	return tmpubsub.LogEntry{
+		Seq:    binary.BigEndian.Uint64(key),
+		Time:   t,
+		Data:   data,
+		Events: v.Events,
+	}, nil
+}
+
+// Bounds implements pubsub.LogStore.
+func (s *EventLogStore) Bounds() (first, last uint64, err error) {
+	it, err := s.db.Iterator(nil, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer it.Close()
+	if !it.Valid() {
+		return 0, 0, it.Error()
+	}
+	first = binary.BigEndian.Uint64(it.Key())
+
+	rit, err := s.db.ReverseIterator(nil, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rit.Close()
+	if !rit.Valid() {
+		return 0, 0, rit.Error()
+	}
+	return first, binary.BigEndian.Uint64(rit.Key()), nil
+}
+
+// Prune implements pubsub.LogStore. It only reads the database if some
+// entries are to be discarded.
+func (s *EventLogStore) Prune(cutoff time.Time, maxItems int) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.count == 0 || (!s.oldest.Before(cutoff) && (maxItems <= 0 || s.count <= maxItems)) {
+		return nil
+	}
+
+	it, err := s.db.Iterator(nil, nil)
+	if err != nil {
+		return err
+	}
+	var (
+		keys   [][]byte
+		oldest time.Time
+	)
+	for ; it.Valid(); it.Next() {
+		t, err := decodeEventLogTime(it.Value())
+		if err != nil {
+			it.Close()
+			return fmt.Errorf("invalid event log entry %X: %w", it.Key(), err)
+		}
+		if !t.Before(cutoff) && (maxItems <= 0 || s.count-len(keys) <= maxItems) {
+			oldest = t
+			break
+		}
+		keys = append(keys, append([]byte(nil), it.Key()...))
+	}
+	err = it.Error()
+	it.Close()
+	if err != nil {
+		return err
+	}
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	s.count -= len(keys)
+	s.oldest = oldest
+	return nil
+}
@@ -0,0 +1,117 @@
+package eventbus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/eventbus"
+	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
+	tmquery "github.com/tendermint/tendermint/internal/pubsub/query"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestEventLogStore(t *testing.T) {
+	db := dbm.NewMemDB()
+	store, err := eventbus.NewEventLogStore(db)
+	require.NoError(t, err)
+
+	start := time.Now()
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, store.Append(tmpubsub.LogEntry{
+			Seq:  uint64(i),
+			Time: start.Add(time.Duration(i) * time.Second),
+			Data: types.EventDataRoundState{Height: int64(i)},
+		}))
+	}
+	require.Error(t, store.Append(tmpubsub.LogEntry{Seq: 6, Time: start, Data: "untagged"}))
+
+	var heights []int64
+	require.NoError(t, store.Scan(2, func(e tmpubsub.LogEntry) bool {
+		heights = append(heights, e.Data.(types.EventDataRoundState).Height)
+		return len(heights) < 2
+	}))
+	assert.Equal(t, []int64{3, 4}, heights)
+
+	// The entries before the cutoff, then those in excess of the maximum,
+	// are discarded.
+	require.NoError(t, store.Prune(start.Add(2*time.Second), 0))
+	first, last, err := store.Bounds()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), first)
+	assert.Equal(t, uint64(5), last)
+
+	// The count of the entries is restored when the store is reopened.
+	store, err = eventbus.NewEventLogStore(db)
+	require.NoError(t, err)
+	require.NoError(t, store.Prune(start, 2))
+	first, last, err = store.Bounds()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), first)
+	assert.Equal(t, uint64(5), last)
+}
+
+func TestEventBusPersistentEventLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := dbm.NewMemDB()
+
+	newBus := func(ctx context.Context) *eventbus.EventBus {
+		store, err := eventbus.NewEventLogStore(db)
+		require.NoError(t, err)
+		bus := eventbus.New(log.TestingLogger(),
+			tmpubsub.EventLog(time.Hour, 0), tmpubsub.PersistentEventLog(store))
+		require.NoError(t, bus.Start(ctx))
+		return bus
+	}
+	query := tmquery.MustCompile(`tm.event='NewRoundStep'`)
+
+	busCtx, busCancel := context.WithCancel(ctx)
+	bus := newBus(busCtx)
+	// The subscription can only queue one event: the others are read back
+	// from the log instead of terminating it.
+	sub, err := bus.SubscribeWithArgs(ctx, tmpubsub.SubscribeArgs{
+		ClientID: "slow",
+		Query:    query,
+		Limit:    1,
+	})
+	require.NoError(t, err)
+	for h := int64(1); h <= 5; h++ {
+		require.NoError(t, bus.PublishEventNewRoundStep(ctx, types.EventDataRoundState{Height: h}))
+	}
+	var seqs []uint64
+	for h := int64(1); h <= 5; h++ {
+		msg, err := sub.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, h, msg.Data().(types.EventDataRoundState).Height)
+		seqs = append(seqs, msg.Seq())
+	}
+	require.NoError(t, bus.PublishEventNewRoundStep(ctx, types.EventDataRoundState{Height: 6}))
+	msg, err := sub.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int64(6), msg.Data().(types.EventDataRoundState).Height)
+
+	// After a restart, the subscription resumes after the last event
+	// received, and the sequence numbers carry on.
+	busCancel()
+	bus.Wait()
+	bus = newBus(ctx)
+	resumed, err := bus.SubscribeWithArgs(ctx, tmpubsub.SubscribeArgs{
+		ClientID: "resumed",
+		Query:    query,
+		After:    seqs[3],
+	})
+	require.NoError(t, err)
+	require.NoError(t, bus.PublishEventNewRoundStep(ctx, types.EventDataRoundState{Height: 7}))
+	for h := int64(5); h <= 7; h++ {
+		msg, err = resumed.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, h, msg.Data().(types.EventDataRoundState).Height)
+	}
+	require.Equal(t, seqs[4]+2, msg.Seq())
+}
@@ -0,0 +1,119 @@
+// Package features implements the feature flags gating the experimental
+// behaviors of the node, so that they can be enabled on some nodes during a
+// rollout.
+//
+// The flags are declared by the packages implementing the behaviors, with
+// Declare, usually in a package-level variable:
+//
+//	var FlagVerifyAhead = features.Declare("blocksync.verify-ahead",
+//		"Verify the queued blocks on a worker pool.", features.Beta, true)
+//
+// Their values on a node are parsed from the config into a Set, which the
+// node hands over to the packages checking them with Set.Enabled.
+package features
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stage is the maturity of a feature.
+type Stage string
+
+const (
+	// Experimental features are disabled by default, and may change or be
+	// removed at any time.
+	Experimental Stage = "experimental"
+	// Beta features are usually enabled by default, and can be disabled if
+	// they misbehave.
+	Beta Stage = "beta"
+)
+
+// Flag is a feature flag.
+type Flag struct {
+	Name        string
+	Description string
+	Stage       Stage
+	Default     bool
+}
+
+var (
+	validName = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*(\.[a-z0-9]+(-[a-z0-9]+)*)+$`)
+
+	mtx   sync.RWMutex
+	flags = make(map[string]*Flag)
+)
+
+// Declare declares a feature flag, named "<module>.<feature>", and enabled
+// by default if def is true. It panics if the name is invalid or already
+// declared.
+func Declare(name, description string, stage Stage, def bool) *Flag {
+	if !validName.MatchString(name) {
+		panic(fmt.Sprintf("invalid feature flag name %q", name))
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	if _, ok := flags[name]; ok {
+		panic(fmt.Sprintf("feature flag %q declared twice", name))
+	}
+	flag := &Flag{Name: name, Description: description, Stage: stage, Default: def}
+	flags[name] = flag
+	return flag
+}
+
+// Declared returns the declared flags, sorted by name.
+func Declared() []*Flag {
+	mtx.RLock()
+	defer mtx.RUnlock()
+	out := make([]*Flag, 0, len(flags))
+	for _, flag := range flags {
+		out = append(out, flag)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Set is the value of the feature flags on a node. A nil Set has the default
+// values.
+type Set struct {
+	values map[*Flag]bool
+}
+
+// ParseSet parses the values of the flags given as "<name>", to enable a
+// flag, or "<name>=<bool>". The flags not given have their default value.
+func ParseSet(specs []string) (*Set, error) {
+	set := &Set{values: make(map[*Flag]bool)}
+	mtx.RLock()
+	defer mtx.RUnlock()
+	for _, spec := range specs {
+		name, value := strings.TrimSpace(spec), true
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			var err error
+			if value, err = strconv.ParseBool(strings.TrimSpace(name[i+1:])); err != nil {
+				return nil, fmt.Errorf("invalid value of feature flag %q: %w", spec, err)
+			}
+			name = strings.TrimSpace(name[:i])
+		}
+		flag, ok := flags[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown feature flag %q", name)
+		}
+		set.values[flag] = value
+	}
+	return set, nil
+}
+
+// Enabled returns true if flag is enabled.
+func (s *Set) Enabled(flag *Flag) bool {
+	if s != nil {
+		if value, ok := s.values[flag]; ok {
+			return value
+		}
+	}
+	return flag.Default
+}
@@ -0,0 +1,91 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	flagOn  = Declare("test.flag-on", "A flag enabled by default.", Beta, true)
+	flagOff = Declare("test.flag-off", "A flag disabled by default.", Experimental, false)
+)
+
+func TestDeclare(t *testing.T) {
+	for _, name := range []string{"", "flag", "Test.flag", "test.", "test..flag", "test.flag_1", "test.-flag"} {
+		assert.Panics(t, func() { Declare(name, "", Experimental, false) }, name)
+	}
+	assert.Panics(t, func() { Declare("test.flag-on", "", Experimental, false) })
+
+	declared := Declared()
+	require.Contains(t, declared, flagOn)
+	require.Contains(t, declared, flagOff)
+	for i := 1; i < len(declared); i++ {
+		assert.Less(t, declared[i-1].Name, declared[i].Name)
+	}
+}
+
+func TestParseSet(t *testing.T) {
+	var set *Set
+	assert.True(t, set.Enabled(flagOn))
+	assert.False(t, set.Enabled(flagOff))
+
+	set, err := ParseSet(nil)
+	require.NoError(t, err)
+	assert.True(t, set.Enabled(flagOn))
+	assert.False(t, set.Enabled(flagOff))
+
+	set, err = ParseSet([]string{"test.flag-on = false", " test.flag-off"})
+	require.NoError(t, err)
+	assert.False(t, set.Enabled(flagOn))
+	assert.True(t, set.Enabled(flagOff))
+
+	set, err = ParseSet([]string{"test.flag-off=1"})
+	require.NoError(t, err)
+	assert.True(t, set.Enabled(flagOn))
+	assert.True(t, set.Enabled(flagOff))
+
+	_, err = ParseSet([]string{"test.unknown"})
+	assert.Error(t, err)
+	_, err = ParseSet([]string{"test.flag-on=maybe"})
+	assert.Error(t, err)
+}
+
+// labeledGauge records the values set on a gauge by feature.
+type labeledGauge struct {
+	values map[string]float64
+	labels []string
+}
+
+func (g *labeledGauge) With(labelValues ...string) metrics.Gauge {
+	return &labeledGauge{values: g.values, labels: append(g.labels, labelValues...)}
+}
+
+func (g *labeledGauge) Set(value float64) {
+	for i := 0; i+1 < len(g.labels); i += 2 {
+		if g.labels[i] == "feature" {
+			g.values[g.labels[i+1]] = value
+		}
+	}
+}
+
+func (g *labeledGauge) Add(float64) {}
+
+func TestMetricsRecord(t *testing.T) {
+	gauge := &labeledGauge{values: make(map[string]float64)}
+	m := &Metrics{Enabled: gauge}
+
+	set, err := ParseSet([]string{"test.flag-on=false"})
+	require.NoError(t, err)
+	m.Record(set)
+	assert.Equal(t, 0.0, gauge.values["test.flag-on"])
+	assert.Equal(t, 0.0, gauge.values["test.flag-off"])
+
+	set, err = ParseSet([]string{"test.flag-off"})
+	require.NoError(t, err)
+	m.Record(set)
+	assert.Equal(t, 1.0, gauge.values["test.flag-on"])
+	assert.Equal(t, 1.0, gauge.values["test.flag-off"])
+}
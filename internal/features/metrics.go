@@ -0,0 +1,56 @@
+package features
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this package.
+	MetricsSubsystem = "features"
+)
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Whether a feature flag is enabled (1) or not (0), labeled by feature
+	// and stage.
+	Enabled metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	return &Metrics{
+		Enabled: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "enabled",
+			Help:      "Whether a feature flag is enabled (1) or not (0).",
+		}, append(labels, "feature", "stage")).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Enabled: discard.NewGauge(),
+	}
+}
+
+// Record sets the metrics of the flags of s.
+func (m *Metrics) Record(s *Set) {
+	for _, flag := range Declared() {
+		value := 0.0
+		if s.Enabled(flag) {
+			value = 1
+		}
+		m.Enabled.With("feature", flag.Name, "stage", string(flag.Stage)).Set(value)
+	}
+}
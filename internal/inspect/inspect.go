@@ -7,7 +7,9 @@ import (
 	"net"
 	"net/http"
 
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/dbcompress"
 	"github.com/tendermint/tendermint/internal/eventbus"
 	"github.com/tendermint/tendermint/internal/inspect/rpc"
 	rpccore "github.com/tendermint/tendermint/internal/rpc/core"
@@ -60,12 +62,21 @@ func New(cfg *config.RPCConfig, bs state.BlockStore, ss state.Store, es []indexe
 
 // NewFromConfig constructs an Inspector using the values defined in the passed in config.
 func NewFromConfig(logger log.Logger, cfg *config.Config) (*Inspector, error) {
-	bsDB, err := config.DefaultDBProvider(&config.DBContext{ID: "blockstore", Config: cfg})
+	cipher, err := atrest.CipherFromConfig(cfg.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := dbcompress.CodecFromConfig(cfg.BaseConfig)
+	if err != nil {
+		return nil, err
+	}
+	dbProvider := dbcompress.WrapDBProvider(atrest.WrapDBProvider(config.DefaultDBProvider, cipher), codec)
+	bsDB, err := dbProvider(&config.DBContext{ID: "blockstore", Config: cfg})
 	if err != nil {
 		return nil, err
 	}
 	bs := store.NewBlockStore(bsDB)
-	sDB, err := config.DefaultDBProvider(&config.DBContext{ID: "state", Config: cfg})
+	sDB, err := dbProvider(&config.DBContext{ID: "state", Config: cfg})
 	if err != nil {
 		return nil, err
 	}
@@ -46,8 +46,8 @@ func Routes(cfg config.RPCConfig, s state.Store, bs state.BlockStore, es []index
 		"commit":           server.NewRPCFunc(env.Commit, "height"),
 		"validators":       server.NewRPCFunc(env.Validators, "height", "page", "per_page"),
 		"tx":               server.NewRPCFunc(env.Tx, "hash", "prove"),
-		"tx_search":        server.NewRPCFunc(env.TxSearch, "query", "prove", "page", "per_page", "order_by"),
-		"block_search":     server.NewRPCFunc(env.BlockSearch, "query", "page", "per_page", "order_by"),
+		"tx_search":        server.NewRPCFunc(env.TxSearch, "query", "prove", "page", "per_page", "order_by", "cursor"),
+		"block_search":     server.NewRPCFunc(env.BlockSearch, "query", "page", "per_page", "order_by", "cursor"),
 	}
 }
 
@@ -92,7 +92,7 @@ func addCORSHandler(rpcConfig *config.RPCConfig, h http.Handler) http.Handler {
 // ListenAndServe listens on the address specified in srv.Addr and handles any
 // incoming requests over HTTP using the Inspector rpc handler specified on the server.
 func (srv *Server) ListenAndServe(ctx context.Context) error {
-	listener, err := server.Listen(srv.Addr, srv.Config.MaxOpenConnections)
+	listener, err := server.ListenWithConfig(srv.Addr, serverRPCConfig(srv.Config))
 	if err != nil {
 		return err
 	}
@@ -107,7 +107,7 @@ func (srv *Server) ListenAndServe(ctx context.Context) error {
 // ListenAndServeTLS listens on the address specified in srv.Addr. ListenAndServeTLS handles
 // incoming requests over HTTPS using the Inspector rpc handler specified on the server.
 func (srv *Server) ListenAndServeTLS(ctx context.Context, certFile, keyFile string) error {
-	listener, err := server.Listen(srv.Addr, srv.Config.MaxOpenConnections)
+	listener, err := server.ListenWithConfig(srv.Addr, serverRPCConfig(srv.Config))
 	if err != nil {
 		return err
 	}
@@ -122,6 +122,12 @@ func serverRPCConfig(r *config.RPCConfig) *server.Config {
 	cfg := server.DefaultConfig()
 	cfg.MaxBodyBytes = r.MaxBodyBytes
 	cfg.MaxHeaderBytes = r.MaxHeaderBytes
+	cfg.HTTP2 = r.HTTP2
+	cfg.HTTP2MaxConcurrentStreams = r.HTTP2MaxConcurrentStreams
+	cfg.HTTP2MaxUploadBufferPerStream = r.HTTP2StreamWindowBytes
+	cfg.HTTP2MaxUploadBufferPerConnection = r.HTTP2ConnWindowBytes
+	cfg.MaxOpenConnections = r.MaxOpenConnections
+	cfg.UnixSocketMode, _ = r.UnixSocketFileMode() // checked by ValidateBasic
 	// If necessary adjust global WriteTimeout to ensure it's greater than
 	// TimeoutBroadcastTxCommit.
 	// See https://github.com/tendermint/tendermint/issues/3435
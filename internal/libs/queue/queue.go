@@ -121,6 +121,13 @@ func (q *Queue) Add(item interface{}) error {
 	return nil
 }
 
+// Len returns the number of items in the queue.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.queueLen
+}
+
 // Remove removes and returns the frontmost (oldest) item in the queue and
 // reports whether an item was available.  If the queue is empty, Remove
 // returns nil, false.
@@ -81,6 +81,19 @@ func TestSoftQuota(t *testing.T) {
 	}
 }
 
+func TestLen(t *testing.T) {
+	q := mustQueue(t, Options{HardLimit: 4})
+	q.mustAdd("foo")
+	q.mustAdd("bar")
+	if n := q.Len(); n != 2 {
+		t.Errorf("Len: got %d, want 2", n)
+	}
+	q.mustRemove("foo")
+	if n := q.Len(); n != 1 {
+		t.Errorf("Len: got %d, want 1", n)
+	}
+}
+
 func TestBurstCredit(t *testing.T) {
 	q := mustQueue(t, Options{SoftQuota: 2, HardLimit: 5})
 	q.mustAdd("foo")
@@ -0,0 +1,150 @@
+package mempool
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Dump is the content of a mempool, for it to be restored into the mempool of
+// another node, e.g. to reproduce the state of a production mempool in a lab.
+type Dump struct {
+	// Height is the height of the last block the mempool was updated with,
+	// and Time when the mempool was dumped.
+	Height int64     `json:"height,string"`
+	Time   time.Time `json:"time"`
+
+	// Txs are the transactions of the mempool, in priority order.
+	Txs []DumpedTx `json:"txs"`
+}
+
+// DumpedTx is a transaction of a mempool dump, with the metadata given to it
+// by the application on CheckTx and by the mempool.
+type DumpedTx struct {
+	Tx        types.Tx  `json:"tx"`
+	Priority  int64     `json:"priority,string"`
+	GasWanted int64     `json:"gas_wanted,string"`
+	Sender    string    `json:"sender,omitempty"`
+	SourceIP  string    `json:"source_ip,omitempty"`
+	Height    int64     `json:"height,string"` // at which the transaction was last validated
+	Timestamp time.Time `json:"timestamp"`     // at which the transaction was first received
+}
+
+// LoadDump reads the mempool dump in the file at path.
+func LoadDump(path string) (Dump, error) {
+	var dump Dump
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return dump, err
+	}
+	if err := json.Unmarshal(bz, &dump); err != nil {
+		return dump, fmt.Errorf("invalid mempool dump %s: %w", path, err)
+	}
+	return dump, nil
+}
+
+// Save writes the mempool dump to the file at path, replacing any file there.
+func (d Dump) Save(path string) error {
+	bz, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return tempfile.WriteFileAtomic(path, bz, 0600)
+}
+
+// Dump returns the transactions of the mempool with their metadata. It is
+// thread-safe.
+func (txmp *TxMempool) Dump() Dump {
+	txmp.mtx.RLock()
+	defer txmp.mtx.RUnlock()
+
+	wTxs := txmp.txsByPriority()
+	dump := Dump{
+		Height: txmp.height,
+		Time:   time.Now(),
+		Txs:    make([]DumpedTx, 0, len(wTxs)),
+	}
+	for _, wtx := range wTxs {
+		dump.Txs = append(dump.Txs, DumpedTx{
+			Tx:        wtx.tx,
+			Priority:  wtx.priority,
+			GasWanted: wtx.gasWanted,
+			Sender:    wtx.sender,
+			SourceIP:  wtx.sourceIP,
+			Height:    wtx.height,
+			Timestamp: wtx.timestamp,
+		})
+	}
+	return dump
+}
+
+// Restore inserts the transactions of dump into the mempool, as they were in
+// the dumped mempool, without checking them with the application. The
+// transactions already in the mempool, too large, or of a sender which has
+// another transaction in the mempool are skipped. It stops at the first
+// transaction the mempool is too full for. It returns the number of
+// transactions inserted.
+//
+// The transactions keep their timestamp, and may expire at the next block if
+// mempool.ttl-duration is set. They are rechecked with the application after
+// the next block if mempool.recheck is set.
+func (txmp *TxMempool) Restore(dump Dump) (int, error) {
+	txmp.Lock()
+	defer txmp.Unlock()
+	txmp.admissionMtx.Lock()
+	defer txmp.admissionMtx.Unlock()
+
+	restored := 0
+	for _, dtx := range dump.Txs {
+		key := dtx.Tx.Key()
+		if len(dtx.Tx) > txmp.config.MaxTxBytes || txmp.txStore.GetTxByHash(key) != nil ||
+			(dtx.Sender != "" && txmp.txStore.GetTxBySender(dtx.Sender) != nil) {
+			continue
+		}
+
+		wtx := &WrappedTx{
+			tx:        dtx.Tx,
+			hash:      key,
+			height:    dtx.Height,
+			gasWanted: dtx.GasWanted,
+			priority:  dtx.Priority,
+			sender:    dtx.Sender,
+			sourceIP:  dtx.SourceIP,
+			timestamp: dtx.Timestamp,
+			peers:     map[uint16]struct{}{},
+		}
+		if err := txmp.canAddTx(wtx); err != nil {
+			return restored, err
+		}
+		txmp.cache.Push(wtx.tx)
+		txmp.insertTx(wtx)
+		txmp.metrics.TxSizeBytes.Observe(float64(wtx.Size()))
+		restored++
+	}
+
+	txmp.metrics.Size.Set(float64(txmp.Size()))
+	if restored > 0 {
+		txmp.notifyTxsAvailable()
+	}
+	return restored, nil
+}
+
+// txsByPriority returns the transactions of the mempool in priority order,
+// the same as ReapMaxTxs.
+//
+// NOTE: requires a read-lock on the mempool
+func (txmp *TxMempool) txsByPriority() []*WrappedTx {
+	wTxs := txmp.txStore.GetAllTxs()
+	sort.Slice(wTxs, func(i, j int) bool {
+		if wTxs[i].priority == wTxs[j].priority {
+			return wTxs[i].timestamp.Before(wTxs[j].timestamp)
+		}
+		return wTxs[i].priority > wTxs[j].priority
+	})
+	return wTxs
+}
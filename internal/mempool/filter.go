@@ -0,0 +1,248 @@
+package mempool
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/types"
+)
+
+// TxFilter is a node-local filter applied to incoming transactions before
+// they are passed to the application's CheckTx. Filters are meant to cheaply
+// drop obvious spam; a transaction rejected by a filter is never seen by the
+// application and is not gossiped.
+type TxFilter interface {
+	// Name identifies the filter in logs, errors and metrics.
+	Name() string
+
+	// FilterTx returns a non-nil error if the transaction must be dropped.
+	FilterTx(tx types.Tx, txInfo TxInfo) error
+}
+
+// TxFilterFunc adapts a function to the TxFilter interface.
+type TxFilterFunc struct {
+	FilterName string
+	Func       func(types.Tx, TxInfo) error
+}
+
+// Name implements TxFilter.
+func (f TxFilterFunc) Name() string { return f.FilterName }
+
+// FilterTx implements TxFilter.
+func (f TxFilterFunc) FilterTx(tx types.Tx, txInfo TxInfo) error { return f.Func(tx, txInfo) }
+
+// WithTxFilters sets the chain of filters executed, in order, before CheckTx.
+func WithTxFilters(filters ...TxFilter) TxMempoolOption {
+	return func(txmp *TxMempool) { txmp.filters = filters }
+}
+
+// TxFiltersFromConfig builds the filter chain described by cfg: the prefix
+// filter, the per-peer rate filter and then the configured plugins, each only
+// if enabled.
+func TxFiltersFromConfig(cfg *config.MempoolConfig) ([]TxFilter, error) {
+	var filters []TxFilter
+
+	if len(cfg.FilterPrefixes) > 0 {
+		prefixes := make([][]byte, len(cfg.FilterPrefixes))
+		for i, prefix := range cfg.FilterPrefixes {
+			bz, err := hex.DecodeString(prefix)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter prefix %q: %w", prefix, err)
+			}
+			prefixes[i] = bz
+		}
+		filters = append(filters, NewPrefixFilter(prefixes))
+	}
+
+	if cfg.FilterPeerRate > 0 {
+		filters = append(filters, NewRateFilter(cfg.FilterPeerRate, cfg.FilterPeerBurst))
+	}
+
+	for _, path := range cfg.FilterPluginPaths() {
+		f, err := LoadTxFilter(path)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	return filters, nil
+}
+
+// WithProposalFilters sets the chain of filters executed, in order, on the
+// transactions reaped for a block proposal by ReapMaxBytesMaxGas. A
+// transaction rejected by a filter is left out of the proposal, but stays in
+// the mempool. The TxInfo passed to the filters is empty.
+func WithProposalFilters(filters ...TxFilter) TxMempoolOption {
+	return func(txmp *TxMempool) { txmp.proposalFilters = filters }
+}
+
+// ProposalFiltersFromConfig builds the proposal filter chain described by
+// cfg: the size filter, the prefix filter and then the configured plugins,
+// each only if enabled.
+func ProposalFiltersFromConfig(cfg *config.MempoolConfig) ([]TxFilter, error) {
+	var filters []TxFilter
+
+	if cfg.ProposalMaxTxBytes > 0 {
+		filters = append(filters, NewMaxBytesFilter(cfg.ProposalMaxTxBytes))
+	}
+
+	if len(cfg.ProposalFilterPrefixes) > 0 {
+		prefixes := make([][]byte, len(cfg.ProposalFilterPrefixes))
+		for i, prefix := range cfg.ProposalFilterPrefixes {
+			bz, err := hex.DecodeString(prefix)
+			if err != nil {
+				return nil, fmt.Errorf("invalid proposal filter prefix %q: %w", prefix, err)
+			}
+			prefixes[i] = bz
+		}
+		filters = append(filters, NewPrefixFilter(prefixes))
+	}
+
+	for _, path := range cfg.ProposalFilterPluginPaths() {
+		f, err := LoadTxFilter(path)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, f)
+	}
+
+	return filters, nil
+}
+
+// filterTx runs the filter chain and returns the first rejection, if any.
+func (txmp *TxMempool) filterTx(tx types.Tx, txInfo TxInfo) error {
+	for _, f := range txmp.filters {
+		if err := f.FilterTx(tx, txInfo); err != nil {
+			txmp.metrics.FilteredTxs.With("filter", f.Name()).Add(1)
+			return fmt.Errorf("rejected by filter %s: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// proposalFilterTx runs the proposal filter chain and returns the first
+// rejection, if any.
+func (txmp *TxMempool) proposalFilterTx(tx types.Tx) error {
+	for _, f := range txmp.proposalFilters {
+		if err := f.FilterTx(tx, TxInfo{}); err != nil {
+			txmp.metrics.ProposalFilteredTxs.With("filter", f.Name()).Add(1)
+			return fmt.Errorf("excluded by proposal filter %s: %w", f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// NewMaxBytesFilter returns a filter dropping transactions larger than
+// maxBytes.
+func NewMaxBytesFilter(maxBytes int64) TxFilter {
+	return TxFilterFunc{
+		FilterName: "max_bytes",
+		Func: func(tx types.Tx, _ TxInfo) error {
+			if int64(len(tx)) > maxBytes {
+				return fmt.Errorf("transaction of %d bytes exceeds the maximum of %d bytes", len(tx), maxBytes)
+			}
+			return nil
+		},
+	}
+}
+
+// NewPrefixFilter returns a filter dropping transactions that start with any
+// of the given prefixes.
+func NewPrefixFilter(prefixes [][]byte) TxFilter {
+	return TxFilterFunc{
+		FilterName: "prefix",
+		Func: func(tx types.Tx, _ TxInfo) error {
+			for _, prefix := range prefixes {
+				if bytes.HasPrefix(tx, prefix) {
+					return fmt.Errorf("transaction prefix %X is blacklisted", prefix)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// maxRateLimitedPeers bounds the number of per-peer buckets kept by the rate
+// filter before idle buckets are pruned.
+const maxRateLimitedPeers = 1024
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateFilter limits the number of transactions accepted from each peer using
+// a token bucket per peer. Transactions submitted locally, e.g. over RPC, are
+// not limited.
+type RateFilter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mtx     sync.Mutex
+	buckets map[types.NodeID]*tokenBucket
+}
+
+// NewRateFilter returns a filter accepting on average rate transactions per
+// second from each peer, with bursts of up to burst transactions.
+func NewRateFilter(rate float64, burst int) *RateFilter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateFilter{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[types.NodeID]*tokenBucket),
+	}
+}
+
+// Name implements TxFilter.
+func (rf *RateFilter) Name() string { return "rate" }
+
+// FilterTx implements TxFilter.
+func (rf *RateFilter) FilterTx(_ types.Tx, txInfo TxInfo) error {
+	if txInfo.SenderNodeID == "" {
+		return nil
+	}
+
+	rf.mtx.Lock()
+	defer rf.mtx.Unlock()
+
+	now := rf.now()
+	b, ok := rf.buckets[txInfo.SenderNodeID]
+	if !ok {
+		if len(rf.buckets) >= maxRateLimitedPeers {
+			rf.prune(now)
+		}
+		b = &tokenBucket{tokens: rf.burst, last: now}
+		rf.buckets[txInfo.SenderNodeID] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * rf.rate
+	if b.tokens > rf.burst {
+		b.tokens = rf.burst
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return fmt.Errorf("peer %s exceeded %.2f txs/s", txInfo.SenderNodeID, rf.rate)
+	}
+	b.tokens--
+	return nil
+}
+
+// prune drops the buckets of peers that have been idle long enough for their
+// bucket to refill completely.
+func (rf *RateFilter) prune(now time.Time) {
+	for id, b := range rf.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*rf.rate >= rf.burst {
+			delete(rf.buckets, id)
+		}
+	}
+}
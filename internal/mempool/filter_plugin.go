@@ -0,0 +1,88 @@
+package mempool
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// TxFilterLoader loads a TxFilter from the file at path.
+type TxFilterLoader func(path string) (TxFilter, error)
+
+var (
+	filterLoadersMtx sync.RWMutex
+	filterLoaders    = map[string]TxFilterLoader{
+		".so": LoadGoPluginFilter,
+	}
+)
+
+// RegisterTxFilterLoader registers the loader used for filter files with the
+// given extension, e.g. ".wasm". This allows builds that embed a WebAssembly
+// runtime to support sandboxed filters without making the runtime a
+// dependency of every build. Registering an extension again replaces the
+// previous loader.
+func RegisterTxFilterLoader(ext string, loader TxFilterLoader) {
+	filterLoadersMtx.Lock()
+	defer filterLoadersMtx.Unlock()
+
+	filterLoaders[strings.ToLower(ext)] = loader
+}
+
+// LoadTxFilter loads the filter at path using the loader registered for its
+// file extension.
+func LoadTxFilter(path string) (TxFilter, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+
+	filterLoadersMtx.RLock()
+	loader, ok := filterLoaders[ext]
+	filterLoadersMtx.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("no loader registered for %q filters (%s)", ext, path)
+	}
+	return loader(path)
+}
+
+// GoPluginFilterSymbol is the symbol looked up in Go plugin filters. It must
+// be a function, or a variable holding a function, with the signature
+//
+//	func(tx []byte, peerID string) error
+//
+// peerID is empty for transactions submitted locally.
+const GoPluginFilterSymbol = "FilterTx"
+
+// LoadGoPluginFilter loads a filter from a Go plugin built with
+// `go build -buildmode=plugin`. The plugin must be built with the same Go
+// version and dependency versions as the node.
+func LoadGoPluginFilter(path string) (TxFilter, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening filter plugin: %w", err)
+	}
+	sym, err := p.Lookup(GoPluginFilterSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("filter plugin %s: %w", path, err)
+	}
+
+	var fn func([]byte, string) error
+	switch f := sym.(type) {
+	case func([]byte, string) error:
+		fn = f
+	case *func([]byte, string) error:
+		fn = *f
+	default:
+		return nil, fmt.Errorf("filter plugin %s: %s has type %T, expected func([]byte, string) error",
+			path, GoPluginFilterSymbol, sym)
+	}
+
+	return TxFilterFunc{
+		FilterName: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Func: func(tx types.Tx, txInfo TxInfo) error {
+			return fn(tx, string(txInfo.SenderNodeID))
+		},
+	}, nil
+}
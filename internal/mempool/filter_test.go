@@ -0,0 +1,118 @@
+package mempool
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPrefixFilter(t *testing.T) {
+	f := NewPrefixFilter([][]byte{[]byte("spam"), {0xff}})
+
+	require.NoError(t, f.FilterTx(types.Tx("sender=ok"), TxInfo{}))
+	require.Error(t, f.FilterTx(types.Tx("spam=1"), TxInfo{}))
+	require.Error(t, f.FilterTx(types.Tx{0xff, 0x01}, TxInfo{}))
+}
+
+func TestRateFilter(t *testing.T) {
+	now := time.Now()
+
+	f := NewRateFilter(1, 2)
+	f.now = func() time.Time { return now }
+
+	peer := TxInfo{SenderID: 1, SenderNodeID: types.NodeID("aa")}
+	other := TxInfo{SenderID: 2, SenderNodeID: types.NodeID("bb")}
+
+	// the burst is available immediately
+	require.NoError(t, f.FilterTx(nil, peer))
+	require.NoError(t, f.FilterTx(nil, peer))
+	require.Error(t, f.FilterTx(nil, peer))
+
+	// peers are limited independently and local txs are never limited
+	require.NoError(t, f.FilterTx(nil, other))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, f.FilterTx(nil, TxInfo{}))
+	}
+
+	// tokens refill at the configured rate
+	now = now.Add(time.Second)
+	require.NoError(t, f.FilterTx(nil, peer))
+	require.Error(t, f.FilterTx(nil, peer))
+}
+
+func TestLoadTxFilter(t *testing.T) {
+	_, err := LoadTxFilter("filter.wasm")
+	require.Error(t, err)
+
+	RegisterTxFilterLoader(".test", func(path string) (TxFilter, error) {
+		return NewPrefixFilter(nil), nil
+	})
+	f, err := LoadTxFilter("filter.TEST")
+	require.NoError(t, err)
+	require.Equal(t, "prefix", f.Name())
+}
+
+func TestTxMempool_CheckTxFilters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errBlocked := errors.New("blocked")
+	var calls int
+	counting := TxFilterFunc{
+		FilterName: "counting",
+		Func: func(types.Tx, TxInfo) error {
+			calls++
+			return nil
+		},
+	}
+	blocking := TxFilterFunc{
+		FilterName: "blocking",
+		Func: func(tx types.Tx, _ TxInfo) error {
+			if string(tx) == "blocked=1" {
+				return errBlocked
+			}
+			return nil
+		},
+	}
+
+	txmp := setup(ctx, t, 100, WithTxFilters(blocking, counting))
+
+	err := txmp.CheckTx(ctx, types.Tx("blocked=1"), nil, TxInfo{})
+	require.True(t, types.IsPreCheckError(err))
+	require.ErrorIs(t, err, errBlocked)
+	require.Equal(t, 0, calls, "filters after a rejection must not run")
+
+	require.NoError(t, txmp.CheckTx(ctx, types.Tx("sender-0=allowed=1"), nil, TxInfo{}))
+	require.Equal(t, 1, calls)
+	require.Equal(t, 1, txmp.Size())
+}
+
+func TestTxMempool_ProposalFilters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.TestMempoolConfig()
+	cfg.ProposalMaxTxBytes = 20
+	cfg.ProposalFilterPrefixes = []string{hex.EncodeToString([]byte("spam"))}
+	filters, err := ProposalFiltersFromConfig(cfg)
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+
+	txmp := setup(ctx, t, 100, WithProposalFilters(filters...))
+	for _, tx := range []string{"sender-0=ok=1", "spam-1=blocked=2", "sender-2=too-large-for-proposals=3"} {
+		require.NoError(t, txmp.CheckTx(ctx, types.Tx(tx), nil, TxInfo{}))
+	}
+
+	// The filtered transactions are left out of the proposals, but stay in
+	// the mempool.
+	require.Equal(t, types.Txs{types.Tx("sender-0=ok=1")}, txmp.ReapMaxBytesMaxGas(-1, -1))
+	require.Equal(t, 3, txmp.Size())
+	require.Len(t, txmp.ReapMaxTxs(-1), 3)
+}
@@ -0,0 +1,109 @@
+package mempool
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// Reasons of the eviction of a transaction from the mempool, besides the
+// expiry reasons of types.EventDataTxExpired.
+const (
+	// The mempool was full, and the transaction had a lower priority than an
+	// incoming one.
+	EvictedMempoolFull = "mempool_full"
+	// The sender, or the RPC client, of the transaction exceeded its quota
+	// with an incoming transaction of a higher priority.
+	EvictedQuotaExceeded = "quota_exceeded"
+	// The transaction was replaced by another one of the same sender.
+	EvictedReplaced = "replaced"
+	// The transaction was no longer valid when rechecked after a block.
+	EvictedRecheckFailed = "recheck_failed"
+	// The transaction was removed over RPC.
+	EvictedRemoved = "removed"
+	// The mempool was flushed.
+	EvictedFlushed = "flushed"
+)
+
+// TxEviction records the eviction of a transaction from the mempool.
+type TxEviction struct {
+	Reason string
+	Time   time.Time
+	Height int64 // of the last block the mempool was updated with
+}
+
+// evictionJournal keeps the evictions of the most recently evicted
+// transactions, up to its size. It is safe for concurrent use.
+type evictionJournal struct {
+	size int
+
+	mtx     sync.Mutex
+	entries map[types.TxKey]*list.Element
+	order   *list.List // of *evictionEntry, the oldest first
+}
+
+type evictionEntry struct {
+	key      types.TxKey
+	eviction TxEviction
+}
+
+// newEvictionJournal returns a journal of up to size evictions. A journal of
+// size 0 records nothing.
+func newEvictionJournal(size int) *evictionJournal {
+	return &evictionJournal{
+		size:    size,
+		entries: make(map[types.TxKey]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// record records the eviction of the transaction with the given key,
+// dropping the oldest eviction if the journal is full.
+func (j *evictionJournal) record(key types.TxKey, reason string, height int64) {
+	if j.size <= 0 {
+		return
+	}
+	entry := &evictionEntry{
+		key:      key,
+		eviction: TxEviction{Reason: reason, Time: time.Now(), Height: height},
+	}
+
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if elem, ok := j.entries[key]; ok {
+		j.order.Remove(elem)
+	} else if j.order.Len() >= j.size {
+		front := j.order.Front()
+		delete(j.entries, front.Value.(*evictionEntry).key)
+		j.order.Remove(front)
+	}
+	j.entries[key] = j.order.PushBack(entry)
+}
+
+// forget drops the eviction of the transaction with the given key, once the
+// transaction is back in the mempool.
+func (j *evictionJournal) forget(key types.TxKey) {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	if elem, ok := j.entries[key]; ok {
+		delete(j.entries, key)
+		j.order.Remove(elem)
+	}
+}
+
+// get returns the eviction of the transaction with the given key, if it is
+// in the journal.
+func (j *evictionJournal) get(key types.TxKey) (TxEviction, bool) {
+	j.mtx.Lock()
+	defer j.mtx.Unlock()
+
+	elem, ok := j.entries[key]
+	if !ok {
+		return TxEviction{}, false
+	}
+	return elem.Value.(*evictionEntry).eviction, true
+}
@@ -1,7 +1,6 @@
 package mempool
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -12,6 +11,7 @@ import (
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/eventbus"
 	"github.com/tendermint/tendermint/internal/libs/clist"
 	"github.com/tendermint/tendermint/internal/proxy"
 	"github.com/tendermint/tendermint/libs/log"
@@ -34,6 +34,10 @@ type TxMempool struct {
 	config       *config.MempoolConfig
 	proxyAppConn proxy.AppConnMempool
 
+	// eventBus, if set, publishes the CheckTx responses and the expiry of
+	// transactions.
+	eventBus *eventbus.EventBus
+
 	// txsAvailable fires once for each height when the mempool is not empty
 	txsAvailable         chan struct{}
 	notifiedTxsAvailable bool
@@ -44,32 +48,25 @@ type TxMempool struct {
 	// sizeBytes defines the total size of the mempool (sum of all tx bytes)
 	sizeBytes int64
 
+	// quotas caps the total size of the transactions of a single RPC client.
+	quotas *txQuotas
+
 	// cache defines a fixed-size cache of already seen transactions as this
 	// reduces pressure on the proxyApp.
 	cache TxCache
 
+	// evictions records why and when the most recently evicted transactions
+	// left the mempool, other than by being committed.
+	evictions *evictionJournal
+
 	// txStore defines the main storage of valid transactions. Indexes are built
 	// on top of this store.
 	txStore *TxStore
 
 	// gossipIndex defines the gossiping index of valid transactions via a
-	// thread-safe linked-list. We also use the gossip index as a cursor for
-	// rechecking transactions already in the mempool.
+	// thread-safe linked-list. A snapshot of it is rechecked after each block.
 	gossipIndex *clist.CList
 
-	// recheckCursor and recheckEnd are used as cursors based on the gossip index
-	// to recheck transactions that are already in the mempool. Iteration is not
-	// thread-safe and transaction may be mutated in serial order.
-	//
-	// XXX/TODO: It might be somewhat of a codesmell to use the gossip index for
-	// iterator and cursor management when rechecking transactions. If the gossip
-	// index changes or is removed in a future refactor, this will have to be
-	// refactored. Instead, we should consider just keeping a slice of a snapshot
-	// of the mempool's current transactions during Update and an integer cursor
-	// into that slice. This, however, requires additional O(n) space complexity.
-	recheckCursor *clist.CElement // next expected response
-	recheckEnd    *clist.CElement // re-checking stops here
-
 	// priorityIndex defines the priority index of valid transactions via a
 	// thread-safe priority queue.
 	priorityIndex *TxPriorityQueue
@@ -86,9 +83,22 @@ type TxMempool struct {
 	// from the mempool. A read-lock is implicitly acquired when executing CheckTx,
 	// however, a caller must explicitly grab a write-lock via Lock when updating
 	// the mempool via Update().
-	mtx       sync.RWMutex
-	preCheck  PreCheckFunc
-	postCheck PostCheckFunc
+	mtx sync.RWMutex
+
+	// The admission lock serializes the changes of the transactions and indexes
+	// made by the CheckTx callbacks, which may be called concurrently by the
+	// connections of proxyAppConn, and by the other writers. It is acquired
+	// after mtx, if both are needed, and never held while calling CheckTx.
+	admissionMtx sync.Mutex
+
+	filters         []TxFilter
+	proposalFilters []TxFilter
+	preCheck        PreCheckFunc
+	postCheck       PostCheckFunc
+
+	// replacementPolicy decides if a transaction flagged as a replacement by
+	// CheckTx replaces the existing transaction of the same sender.
+	replacementPolicy ReplacementPolicy
 }
 
 func NewTxMempool(
@@ -105,7 +115,9 @@ func NewTxMempool(
 		proxyAppConn:  proxyAppConn,
 		height:        height,
 		cache:         NopTxCache{},
+		evictions:     newEvictionJournal(cfg.EvictionJournalSize),
 		metrics:       NopMetrics(),
+		quotas:        newTxQuotas(cfg.MaxSourceIPBytes),
 		txStore:       NewTxStore(),
 		gossipIndex:   clist.New(),
 		priorityIndex: NewTxPriorityQueue(),
@@ -121,7 +133,7 @@ func NewTxMempool(
 		txmp.cache = NewLRUTxCache(cfg.CacheSize)
 	}
 
-	proxyAppConn.SetResponseCallback(txmp.defaultTxCallback)
+	txmp.replacementPolicy = PriorityReplacementPolicy
 
 	for _, opt := range options {
 		opt(txmp)
@@ -144,6 +156,13 @@ func WithPostCheck(f PostCheckFunc) TxMempoolOption {
 	return func(txmp *TxMempool) { txmp.postCheck = f }
 }
 
+// WithEventBus sets the event bus on which the mempool publishes the CheckTx
+// responses of the transactions it receives, and the expiry of its
+// transactions.
+func WithEventBus(eventBus *eventbus.EventBus) TxMempoolOption {
+	return func(txmp *TxMempool) { txmp.eventBus = eventBus }
+}
+
 // WithMetrics sets the mempool's metrics collector.
 func WithMetrics(metrics *Metrics) TxMempoolOption {
 	return func(txmp *TxMempool) { txmp.metrics = metrics }
@@ -172,6 +191,101 @@ func (txmp *TxMempool) SizeBytes() int64 {
 	return atomic.LoadInt64(&txmp.sizeBytes)
 }
 
+// GasWanted returns the gas wanted reported by the application's CheckTx for
+// the transaction with the given key, if it is in the mempool. It is
+// thread-safe.
+func (txmp *TxMempool) GasWanted(txKey types.TxKey) (int64, bool) {
+	txmp.mtx.RLock()
+	defer txmp.mtx.RUnlock()
+
+	wtx := txmp.txStore.GetTxByHash(txKey)
+	if wtx == nil {
+		return 0, false
+	}
+	return wtx.gasWanted, true
+}
+
+// hasTx returns true if the transaction with the given key is in the mempool,
+// or was seen recently, as recorded by the cache. It is thread-safe.
+func (txmp *TxMempool) hasTx(key types.TxKey) bool {
+	return txmp.txStore.GetTxByHash(key) != nil || txmp.cache.Has(key)
+}
+
+// TxDetails describes a transaction in the mempool, for inspection.
+type TxDetails struct {
+	Hash      types.TxKey
+	Size      int
+	GasWanted int64
+	Priority  int64
+	Sender    string
+	Height    int64     // at which the transaction was last validated
+	Timestamp time.Time // at which the transaction was first received
+}
+
+// InspectTxs returns the details of the transactions in the mempool, in
+// priority order, the same as ReapMaxTxs. If sender is not empty, only the
+// transactions of this sender, as defined by the application, are returned.
+// It is thread-safe.
+func (txmp *TxMempool) InspectTxs(sender string) []TxDetails {
+	txmp.mtx.RLock()
+	defer txmp.mtx.RUnlock()
+
+	wTxs := txmp.txsByPriority()
+	details := make([]TxDetails, 0, len(wTxs))
+	for _, wtx := range wTxs {
+		if sender != "" && wtx.sender != sender {
+			continue
+		}
+		details = append(details, TxDetails{
+			Hash:      wtx.hash,
+			Size:      wtx.Size(),
+			GasWanted: wtx.gasWanted,
+			Priority:  wtx.priority,
+			Sender:    wtx.sender,
+			Height:    wtx.height,
+			Timestamp: wtx.timestamp,
+		})
+	}
+	return details
+}
+
+// PendingTx returns the details of the transaction with the given key, if it
+// is in the mempool, with its rank in priority order: the number of
+// transactions which would be reaped before it. It is thread-safe.
+func (txmp *TxMempool) PendingTx(key types.TxKey) (TxDetails, int, bool) {
+	txmp.mtx.RLock()
+	defer txmp.mtx.RUnlock()
+
+	wtx := txmp.txStore.GetTxByHash(key)
+	if wtx == nil {
+		return TxDetails{}, 0, false
+	}
+
+	rank := 0
+	for _, other := range txmp.txStore.GetAllTxs() {
+		if other.priority > wtx.priority ||
+			(other.priority == wtx.priority && other.timestamp.Before(wtx.timestamp)) {
+			rank++
+		}
+	}
+	return TxDetails{
+		Hash:      wtx.hash,
+		Size:      wtx.Size(),
+		GasWanted: wtx.gasWanted,
+		Priority:  wtx.priority,
+		Sender:    wtx.sender,
+		Height:    wtx.height,
+		Timestamp: wtx.timestamp,
+	}, rank, true
+}
+
+// EvictedTx returns the eviction of the transaction with the given key, if it
+// was evicted from the mempool and is still recorded in the eviction journal,
+// of mempool.eviction-journal-size transactions. It is thread-safe.
+func (txmp *TxMempool) EvictedTx(key types.TxKey) (TxEviction, bool) {
+	return txmp.evictions.get(key)
+}
+
 // FlushAppConn executes FlushSync on the mempool's proxyAppConn.
 //
 // NOTE: The caller must obtain a write-lock prior to execution.
@@ -243,6 +357,10 @@ func (txmp *TxMempool) CheckTx(
 		}
 	}
 
+	if err := txmp.filterTx(tx, txInfo); err != nil {
+		return types.ErrPreCheck{Reason: err}
+	}
+
 	if txmp.preCheck != nil {
 		if err := txmp.preCheck(tx); err != nil {
 			return types.ErrPreCheck{Reason: err}
@@ -276,18 +394,30 @@ func (txmp *TxMempool) CheckTx(
 		return err
 	}
 
+	height := txmp.height
 	reqRes.SetCallback(func(res *abci.Response) {
-		if txmp.recheckCursor != nil {
-			panic("recheck cursor is non-nil in CheckTx callback")
-		}
-
 		wtx := &WrappedTx{
 			tx:        tx,
 			hash:      txHash,
 			timestamp: time.Now().UTC(),
-			height:    txmp.height,
+			height:    height,
 		}
+		txmp.admissionMtx.Lock()
 		txmp.initTxCallback(wtx, res, txInfo)
+		txmp.admissionMtx.Unlock()
+
+		// The request submitting the transaction, e.g. broadcast_tx_async,
+		// may be done by the time the application responds.
+		if checkTxRes, ok := res.Value.(*abci.Response_CheckTx); ok && txmp.eventBus != nil {
+			err := txmp.eventBus.PublishEventCheckTx(context.Background(), types.EventDataCheckTx{
+				Tx:     tx,
+				Height: height,
+				Result: *checkTxRes.CheckTx,
+			})
+			if err != nil {
+				txmp.logger.Error("failed to publish CheckTx response", "tx", tx.Hash(), "err", err)
+			}
+		}
 
 		if cb != nil {
 			cb(res)
@@ -300,10 +430,12 @@ func (txmp *TxMempool) CheckTx(
 func (txmp *TxMempool) RemoveTxByKey(txKey types.TxKey) error {
 	txmp.Lock()
 	defer txmp.Unlock()
+	txmp.admissionMtx.Lock()
+	defer txmp.admissionMtx.Unlock()
 
 	// remove the committed transaction from the transaction store and indexes
 	if wtx := txmp.txStore.GetTxByHash(txKey); wtx != nil {
-		txmp.removeTx(wtx, false)
+		txmp.evictTx(wtx, false, EvictedRemoved)
 		return nil
 	}
 
@@ -319,12 +451,14 @@ func (txmp *TxMempool) RemoveTxByKey(txKey types.TxKey) error {
 func (txmp *TxMempool) Flush() {
 	txmp.mtx.RLock()
 	defer txmp.mtx.RUnlock()
+	txmp.admissionMtx.Lock()
+	defer txmp.admissionMtx.Unlock()
 
 	txmp.heightIndex.Reset()
 	txmp.timestampIndex.Reset()
 
 	for _, wtx := range txmp.txStore.GetAllTxs() {
-		txmp.removeTx(wtx, false)
+		txmp.evictTx(wtx, false, EvictedFlushed)
 	}
 
 	atomic.SwapInt64(&txmp.sizeBytes, 0)
@@ -332,7 +466,8 @@ func (txmp *TxMempool) Flush() {
 }
 
 // ReapMaxBytesMaxGas returns a list of transactions within the provided size
-// and gas constraints. Transaction are retrieved in priority order.
+// and gas constraints. Transaction are retrieved in priority order. The
+// transactions rejected by the proposal filters are left out.
 //
 // NOTE:
 // - Transactions returned are not removed from the mempool transaction
@@ -358,8 +493,12 @@ func (txmp *TxMempool) ReapMaxBytesMaxGas(maxBytes, maxGas int64) types.Txs {
 	txs := make([]types.Tx, 0, txmp.priorityIndex.NumTxs())
 	for txmp.priorityIndex.NumTxs() > 0 {
 		wtx := txmp.priorityIndex.PopTx()
-		txs = append(txs, wtx.tx)
 		wTxs = append(wTxs, wtx)
+		if err := txmp.proposalFilterTx(wtx.tx); err != nil {
+			txmp.logger.Debug("left transaction out of the proposal", "tx_hash", wtx.hash, "err", err)
+			continue
+		}
+		txs = append(txs, wtx.tx)
 		size := types.ComputeProtoSizeForTxs([]types.Tx{wtx.tx})
 
 		// Ensure we have capacity for the transaction with respect to the
@@ -423,6 +562,7 @@ func (txmp *TxMempool) ReapMaxTxs(max int) types.Txs {
 //
 // NOTE:
 // - The caller must explicitly acquire a write-lock.
+// - The transactions are rechecked without waiting for the responses.
 func (txmp *TxMempool) Update(
 	ctx context.Context,
 	blockHeight int64,
@@ -432,6 +572,7 @@ func (txmp *TxMempool) Update(
 	newPostFn PostCheckFunc,
 ) error {
 
+	txmp.admissionMtx.Lock()
 	txmp.height = blockHeight
 	txmp.notifiedTxsAvailable = false
 
@@ -457,24 +598,33 @@ func (txmp *TxMempool) Update(
 		}
 	}
 
-	txmp.purgeExpiredTxs(blockHeight)
+	expired := txmp.purgeExpiredTxs(blockHeight)
 
 	// If there any uncommitted transactions left in the mempool, we either
 	// initiate re-CheckTx per remaining transaction or notify that remaining
 	// transactions are left.
-	if txmp.Size() > 0 {
-		if txmp.config.Recheck {
-			txmp.logger.Debug(
-				"executing re-CheckTx for all remaining transactions",
-				"num_txs", txmp.Size(),
-				"height", blockHeight,
-			)
-			txmp.updateReCheckTxs(ctx)
-		} else {
-			txmp.notifyTxsAvailable()
+	if txmp.Size() > 0 && !txmp.config.Recheck {
+		txmp.notifyTxsAvailable()
+	}
+	txmp.admissionMtx.Unlock()
+
+	if txmp.eventBus != nil {
+		for _, event := range expired {
+			if err := txmp.eventBus.PublishEventTxExpired(ctx, event); err != nil {
+				txmp.logger.Error("failed to publish expired transaction", "tx", event.Tx.Hash(), "err", err)
+			}
 		}
 	}
 
+	if txmp.Size() > 0 && txmp.config.Recheck {
+		txmp.logger.Debug(
+			"executing re-CheckTx for all remaining transactions",
+			"num_txs", txmp.Size(),
+			"height", blockHeight,
+		)
+		txmp.updateReCheckTxs(ctx)
+	}
+
 	txmp.metrics.Size.Set(float64(txmp.Size()))
 	return nil
 }
@@ -490,6 +640,11 @@ func (txmp *TxMempool) Update(
 // reports an error, the transaction is rejected. Otherwise, we attempt to insert
 // the transaction into the mempool.
 //
+// If the application flags the transaction as a replacement, with the Replace
+// field of ResponseCheckTx, and the mempool holds a transaction of the same
+// sender, the replacement policy decides if the new transaction replaces it.
+// The existing transaction is only evicted if the new one is inserted.
+//
 // When inserting a transaction, we first check if there is sufficient capacity.
 // If there is, the transaction is added to the txStore and all indexes.
 // Otherwise, if the mempool is full, we attempt to find a lower priority transaction
@@ -497,7 +652,7 @@ func (txmp *TxMempool) Update(
 // the new incoming transaction is rejected.
 //
 // NOTE:
-// - An explicit lock is NOT required.
+// - The caller must hold the admission lock.
 func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo TxInfo) {
 	checkTxRes, ok := res.Value.(*abci.Response_CheckTx)
 	if !ok {
@@ -534,19 +689,76 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 	sender := checkTxRes.CheckTx.Sender
 	priority := checkTxRes.CheckTx.Priority
 
+	// replaced is the existing transaction of the sender replaced by wtx. It
+	// is removed before the quotas and the capacity are checked, as wtx takes
+	// its place, and restored if wtx is rejected.
+	var replaced *WrappedTx
 	if len(sender) > 0 {
-		if wtx := txmp.txStore.GetTxBySender(sender); wtx != nil {
-			txmp.logger.Error(
-				"rejected incoming good transaction; tx already exists for sender",
-				"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
-				"sender", sender,
+		if existing := txmp.txStore.GetTxBySender(sender); existing != nil {
+			if !checkTxRes.CheckTx.Replace {
+				txmp.logger.Error(
+					"rejected incoming good transaction; tx already exists for sender",
+					"tx", fmt.Sprintf("%X", existing.tx.Hash()),
+					"sender", sender,
+				)
+				txmp.metrics.RejectedTxs.Add(1)
+				return
+			}
+
+			err := txmp.replacementPolicy(
+				ReplacementTx{
+					Tx:        existing.tx,
+					Sender:    sender,
+					Priority:  existing.priority,
+					GasWanted: existing.gasWanted,
+				},
+				ReplacementTx{
+					Tx:        wtx.tx,
+					Sender:    sender,
+					Priority:  priority,
+					GasWanted: checkTxRes.CheckTx.GasWanted,
+				},
 			)
-			txmp.metrics.RejectedTxs.Add(1)
-			return
+			if err != nil {
+				txmp.cache.Remove(wtx.tx)
+				txmp.logger.Info(
+					"rejected incoming good transaction; replacement rejected",
+					"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
+					"existing_tx", fmt.Sprintf("%X", existing.tx.Hash()),
+					"sender", sender,
+					"err", err.Error(),
+				)
+				checkTxRes.CheckTx.MempoolError = err.Error()
+				txmp.metrics.RejectedTxs.Add(1)
+				return
+			}
+
+			replaced = existing
+			txmp.removeTx(replaced, false)
 		}
 	}
 
-	if err := txmp.canAddTx(wtx); err != nil {
+	wtx.priority = priority
+	wtx.sender = sender
+	wtx.sourceIP = txInfo.SourceIP
+	// The victims of the quota are only evicted once wtx is admitted.
+	victims, err := txmp.quotaVictims(wtx)
+	if err != nil {
+		txmp.restoreTx(replaced)
+		txmp.cache.Remove(wtx.tx)
+		txmp.logger.Info(
+			"rejected incoming good transaction; quota exceeded",
+			"tx", fmt.Sprintf("%X", wtx.tx.Hash()),
+			"err", err.Error(),
+		)
+		checkTxRes.CheckTx.MempoolError = err.Error()
+		txmp.metrics.RejectedTxs.Add(1)
+		return
+	}
+
+	// If evicting the victims of the quota does not make enough room for wtx,
+	// the transactions of lower priority, which include the victims, must.
+	if err := txmp.canAddTxWithout(wtx, victims); err != nil {
 		evictTxs := txmp.priorityIndex.GetEvictableTxs(
 			priority,
 			int64(wtx.Size()),
@@ -556,6 +768,7 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 		if len(evictTxs) == 0 {
 			// No room for the new incoming transaction so we just remove it from
 			// the cache.
+			txmp.restoreTx(replaced)
 			txmp.cache.Remove(wtx.tx)
 			txmp.logger.Error(
 				"rejected incoming good transaction; mempool full",
@@ -565,14 +778,34 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 			txmp.metrics.RejectedTxs.Add(1)
 			return
 		}
+	}
+
+	for _, victim := range victims {
+		txmp.evictTx(victim, true, EvictedQuotaExceeded)
+		txmp.logger.Debug(
+			"evicted existing good transaction; quota exceeded",
+			"old_tx", fmt.Sprintf("%X", victim.tx.Hash()),
+			"old_priority", victim.priority,
+			"new_tx", fmt.Sprintf("%X", wtx.tx.Hash()),
+			"new_priority", wtx.priority,
+		)
+		txmp.metrics.EvictedTxs.Add(1)
+	}
 
+	if err := txmp.canAddTx(wtx); err != nil {
 		// evict an existing transaction(s)
 		//
 		// NOTE:
 		// - The transaction, toEvict, can be removed while a concurrent
 		//   reCheckTx callback is being executed for the same transaction.
+		evictTxs := txmp.priorityIndex.GetEvictableTxs(
+			priority,
+			int64(wtx.Size()),
+			txmp.SizeBytes(),
+			txmp.config.MaxTxsBytes,
+		)
 		for _, toEvict := range evictTxs {
-			txmp.removeTx(toEvict, true)
+			txmp.evictTx(toEvict, true, EvictedMempoolFull)
 			txmp.logger.Debug(
 				"evicted existing good transaction; mempool full",
 				"old_tx", fmt.Sprintf("%X", toEvict.tx.Hash()),
@@ -585,8 +818,6 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 	}
 
 	wtx.gasWanted = checkTxRes.CheckTx.GasWanted
-	wtx.priority = priority
-	wtx.sender = sender
 	wtx.peers = map[uint16]struct{}{
 		txInfo.SenderID: {},
 	}
@@ -602,20 +833,28 @@ func (txmp *TxMempool) initTxCallback(wtx *WrappedTx, res *abci.Response, txInfo
 		"height", txmp.height,
 		"num_txs", txmp.Size(),
 	)
+	if replaced != nil {
+		txmp.evictions.record(replaced.hash, EvictedReplaced, txmp.height)
+		txmp.logger.Debug(
+			"replaced existing good transaction",
+			"old_tx", fmt.Sprintf("%X", replaced.tx.Hash()),
+			"old_priority", replaced.priority,
+			"new_tx", fmt.Sprintf("%X", wtx.tx.Hash()),
+			"new_priority", wtx.priority,
+			"sender", sender,
+		)
+		txmp.metrics.ReplacedTxs.Add(1)
+	}
 	txmp.notifyTxsAvailable()
 }
 
-// defaultTxCallback is the CheckTx application callback used when a transaction
-// is being re-checked (if re-checking is enabled). The caller must hold a mempool
-// write-lock (via Lock()) and when executing Update(), if the mempool is non-empty
-// and Recheck is enabled, then all remaining transactions will be rechecked via
-// CheckTxAsync. The order transactions are rechecked must be the same as the
-// order in which this callback is called.
-func (txmp *TxMempool) defaultTxCallback(req *abci.Request, res *abci.Response) {
-	if txmp.recheckCursor == nil {
-		return
-	}
-
+// recheckTxCallback is the callback invoked for a transaction already in the
+// mempool after CheckTx has been executed again by the ABCI application, when
+// re-checking is enabled. The transaction is removed if it is no longer valid.
+//
+// NOTE:
+// - The caller must hold the admission lock.
+func (txmp *TxMempool) recheckTxCallback(wtx *WrappedTx, res *abci.Response) {
 	txmp.metrics.RecheckTimes.Add(1)
 
 	checkTxRes, ok := res.Value.(*abci.Response_CheckTx)
@@ -626,36 +865,6 @@ func (txmp *TxMempool) defaultTxCallback(req *abci.Request, res *abci.Response)
 		)
 		return
 	}
-	tx := req.GetCheckTx().Tx
-	wtx := txmp.recheckCursor.Value.(*WrappedTx)
-
-	// Search through the remaining list of tx to recheck for a transaction that matches
-	// the one we received from the ABCI application.
-	for {
-		if bytes.Equal(tx, wtx.tx) {
-			// We've found a tx in the recheck list that matches the tx that we
-			// received from the ABCI application.
-			// Break, and use this transaction for further checks.
-			break
-		}
-
-		txmp.logger.Error(
-			"re-CheckTx transaction mismatch",
-			"got", wtx.tx.Hash(),
-			"expected", types.Tx(tx).Key(),
-		)
-
-		if txmp.recheckCursor == txmp.recheckEnd {
-			// we reached the end of the recheckTx list without finding a tx
-			// matching the one we received from the ABCI application.
-			// Return without processing any tx.
-			txmp.recheckCursor = nil
-			return
-		}
-
-		txmp.recheckCursor = txmp.recheckCursor.Next()
-		wtx = txmp.recheckCursor.Value.(*WrappedTx)
-	}
 
 	// Only evaluate transactions that have not been removed. This can happen
 	// if an existing transaction is evicted during CheckTx and while this
@@ -663,7 +872,7 @@ func (txmp *TxMempool) defaultTxCallback(req *abci.Request, res *abci.Response)
 	if !txmp.txStore.IsTxRemoved(wtx.hash) {
 		var err error
 		if txmp.postCheck != nil {
-			err = txmp.postCheck(tx, checkTxRes.CheckTx)
+			err = txmp.postCheck(wtx.tx, checkTxRes.CheckTx)
 		}
 
 		if checkTxRes.CheckTx.Code == abci.CodeTypeOK && err == nil {
@@ -677,62 +886,78 @@ func (txmp *TxMempool) defaultTxCallback(req *abci.Request, res *abci.Response)
 				"code", checkTxRes.CheckTx.Code,
 			)
 
-			if wtx.gossipEl != txmp.recheckCursor {
-				panic("corrupted reCheckTx cursor")
-			}
-
-			txmp.removeTx(wtx, !txmp.config.KeepInvalidTxsInCache)
-		}
-	}
-
-	// move reCheckTx cursor to next element
-	if txmp.recheckCursor == txmp.recheckEnd {
-		txmp.recheckCursor = nil
-	} else {
-		txmp.recheckCursor = txmp.recheckCursor.Next()
-	}
-
-	if txmp.recheckCursor == nil {
-		txmp.logger.Debug("finished rechecking transactions")
-
-		if txmp.Size() > 0 {
-			txmp.notifyTxsAvailable()
+			txmp.evictTx(wtx, !txmp.config.KeepInvalidTxsInCache, EvictedRecheckFailed)
 		}
 	}
 
 	txmp.metrics.Size.Set(float64(txmp.Size()))
 }
 
-// updateReCheckTxs updates the recheck cursors using the gossipIndex. For
-// each transaction, it executes CheckTxAsync. The global callback defined on
-// the proxyAppConn will be executed for each transaction after CheckTx is
-// executed.
+// updateReCheckTxs executes CheckTxAsync for each transaction of the gossip
+// index. The responses may come in any order, as the requests are spread over
+// the connections of proxyAppConn, and each is handled by recheckTxCallback.
+// Once all of them are handled, the caller is notified if transactions are
+// left.
 //
 // NOTE:
-// - The caller must have a write-lock when executing updateReCheckTxs.
+// - The caller must have a write-lock when executing updateReCheckTxs, but
+//   not the admission lock.
 func (txmp *TxMempool) updateReCheckTxs(ctx context.Context) {
-	if txmp.Size() == 0 {
-		panic("attempted to update re-CheckTx txs when mempool is empty")
+	var wtxs []*WrappedTx
+	for e := txmp.gossipIndex.Front(); e != nil; e = e.Next() {
+		wtxs = append(wtxs, e.Value.(*WrappedTx))
+	}
+	if len(wtxs) == 0 {
+		return
 	}
 
-	txmp.recheckCursor = txmp.gossipIndex.Front()
-	txmp.recheckEnd = txmp.gossipIndex.Back()
+	// pending is the number of transactions of this recheck which are not
+	// handled yet. It must be decremented with the admission lock held.
+	pending := len(wtxs)
+	finish := func() {
+		pending--
+		if pending == 0 {
+			txmp.logger.Debug("finished rechecking transactions")
 
-	for e := txmp.gossipIndex.Front(); e != nil; e = e.Next() {
-		wtx := e.Value.(*WrappedTx)
+			if txmp.Size() > 0 {
+				txmp.notifyTxsAvailable()
+			}
+		}
+	}
+	skip := func() {
+		txmp.admissionMtx.Lock()
+		defer txmp.admissionMtx.Unlock()
+		finish()
+	}
+
+	for _, wtx := range wtxs {
+		wtx := wtx
 
 		// Only execute CheckTx if the transaction is not marked as removed which
 		// could happen if the transaction was evicted.
-		if !txmp.txStore.IsTxRemoved(wtx.hash) {
-			_, err := txmp.proxyAppConn.CheckTxAsync(ctx, abci.RequestCheckTx{
-				Tx:   wtx.tx,
-				Type: abci.CheckTxType_Recheck,
-			})
-			if err != nil {
-				// no need in retrying since the tx will be rechecked after the next block
-				txmp.logger.Error("failed to execute CheckTx during rechecking", "err", err)
-			}
+		if txmp.txStore.IsTxRemoved(wtx.hash) {
+			skip()
+			continue
 		}
+
+		reqRes, err := txmp.proxyAppConn.CheckTxAsync(ctx, abci.RequestCheckTx{
+			Tx:   wtx.tx,
+			Type: abci.CheckTxType_Recheck,
+		})
+		if err != nil {
+			// no need in retrying since the tx will be rechecked after the next block
+			txmp.logger.Error("failed to execute CheckTx during rechecking", "err", err)
+			skip()
+			continue
+		}
+
+		reqRes.SetCallback(func(res *abci.Response) {
+			txmp.admissionMtx.Lock()
+			defer txmp.admissionMtx.Unlock()
+
+			txmp.recheckTxCallback(wtx, res)
+			finish()
+		})
 	}
 
 	if _, err := txmp.proxyAppConn.FlushAsync(ctx); err != nil {
@@ -744,10 +969,19 @@ func (txmp *TxMempool) updateReCheckTxs(ctx context.Context) {
 // the mempool due to mempool configured constraints. If it returns nil,
 // the transaction can be inserted into the mempool.
 func (txmp *TxMempool) canAddTx(wtx *WrappedTx) error {
+	return txmp.canAddTxWithout(wtx, nil)
+}
+
+// canAddTxWithout is like canAddTx, but as if the transactions evicted were
+// removed from the mempool.
+func (txmp *TxMempool) canAddTxWithout(wtx *WrappedTx, evicted []*WrappedTx) error {
 	var (
-		numTxs    = txmp.Size()
+		numTxs    = txmp.Size() - len(evicted)
 		sizeBytes = txmp.SizeBytes()
 	)
+	for _, tx := range evicted {
+		sizeBytes -= int64(tx.Size())
+	}
 
 	if numTxs >= txmp.config.Size || int64(wtx.Size())+sizeBytes > txmp.config.MaxTxsBytes {
 		return types.ErrMempoolIsFull{
@@ -774,6 +1008,8 @@ func (txmp *TxMempool) insertTx(wtx *WrappedTx) {
 	wtx.gossipEl = gossipEl
 
 	atomic.AddInt64(&txmp.sizeBytes, int64(wtx.Size()))
+	txmp.quotas.add(wtx)
+	txmp.evictions.forget(wtx.hash)
 }
 
 func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool) {
@@ -792,28 +1028,47 @@ func (txmp *TxMempool) removeTx(wtx *WrappedTx, removeFromCache bool) {
 	wtx.gossipEl.DetachPrev()
 
 	atomic.AddInt64(&txmp.sizeBytes, int64(-wtx.Size()))
+	txmp.quotas.remove(wtx)
 
 	if removeFromCache {
 		txmp.cache.Remove(wtx.tx)
 	}
 }
 
+// evictTx removes the transaction wtx, evicted for the given reason, and
+// records its eviction in the journal.
+func (txmp *TxMempool) evictTx(wtx *WrappedTx, removeFromCache bool, reason string) {
+	txmp.removeTx(wtx, removeFromCache)
+	txmp.evictions.record(wtx.hash, reason, txmp.height)
+}
+
+// restoreTx inserts back the transaction wtx, replaced by a transaction that
+// was then rejected. It does nothing if wtx is nil.
+func (txmp *TxMempool) restoreTx(wtx *WrappedTx) {
+	if wtx != nil {
+		txmp.insertTx(wtx)
+	}
+}
+
 // purgeExpiredTxs removes all transactions that have exceeded their respective
 // height- and/or time-based TTLs from their respective indexes. Every expired
 // transaction will be removed from the mempool, but preserved in the cache.
+// It returns the expiry events of the transactions.
 //
 // NOTE: purgeExpiredTxs must only be called during TxMempool#Update in which
 // the caller has a write-lock on the mempool and so we can safely iterate over
 // the height and time based indexes.
-func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) {
+func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) []types.EventDataTxExpired {
 	now := time.Now()
 	expiredTxs := make(map[types.TxKey]*WrappedTx)
+	reasons := make(map[types.TxKey]string)
 
 	if txmp.config.TTLNumBlocks > 0 {
 		purgeIdx := -1
 		for i, wtx := range txmp.heightIndex.txs {
 			if (blockHeight - wtx.height) > txmp.config.TTLNumBlocks {
 				expiredTxs[wtx.tx.Key()] = wtx
+				reasons[wtx.tx.Key()] = types.TxExpiredReasonNumBlocks
 				purgeIdx = i
 			} else {
 				// since the index is sorted, we know no other txs can be be purged
@@ -831,6 +1086,9 @@ func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) {
 		for i, wtx := range txmp.timestampIndex.txs {
 			if now.Sub(wtx.timestamp) > txmp.config.TTLDuration {
 				expiredTxs[wtx.tx.Key()] = wtx
+				if _, ok := reasons[wtx.tx.Key()]; !ok {
+					reasons[wtx.tx.Key()] = types.TxExpiredReasonDuration
+				}
 				purgeIdx = i
 			} else {
 				// since the index is sorted, we know no other txs can be be purged
@@ -843,9 +1101,20 @@ func (txmp *TxMempool) purgeExpiredTxs(blockHeight int64) {
 		}
 	}
 
-	for _, wtx := range expiredTxs {
-		txmp.removeTx(wtx, false)
+	events := make([]types.EventDataTxExpired, 0, len(expiredTxs))
+	for key, wtx := range expiredTxs {
+		txmp.evictTx(wtx, false, reasons[key])
+		events = append(events, types.EventDataTxExpired{
+			Tx:             wtx.tx,
+			Height:         blockHeight,
+			Reason:         reasons[key],
+			InsertedHeight: wtx.height,
+			InsertedTime:   wtx.timestamp,
+		})
 	}
+	txmp.metrics.ExpiredTxs.Add(float64(len(expiredTxs)))
+
+	return events
 }
 
 func (txmp *TxMempool) notifyTxsAvailable() {
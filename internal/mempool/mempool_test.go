@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,12 +22,16 @@ import (
 	"github.com/tendermint/tendermint/abci/example/kvstore"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/proxy"
+	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/types"
 )
 
 // application extends the KV store application by overriding CheckTx to provide
-// transaction priority based on the value in the key/value pair.
+// transaction priority based on the value in the key/value pair. Transactions
+// whose key starts with "replace" are flagged as replacements.
 type application struct {
 	*kvstore.Application
 }
@@ -69,6 +74,7 @@ func (app *application) CheckTx(req abci.RequestCheckTx) abci.ResponseCheckTx {
 		Sender:    sender,
 		Code:      code.CodeTypeOK,
 		GasWanted: 1,
+		Replace:   bytes.HasPrefix(parts[1], []byte("replace")),
 	}
 }
 
@@ -217,6 +223,153 @@ func TestTxMempool_Size(t *testing.T) {
 	require.Equal(t, int64(2850), txmp.SizeBytes())
 }
 
+func TestTxMempool_GasWanted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txmp := setup(ctx, t, 0)
+	txs := checkTxs(ctx, t, txmp, 10, 0)
+
+	for _, tx := range txs {
+		gas, ok := txmp.GasWanted(tx.tx.Key())
+		require.True(t, ok)
+		require.Equal(t, int64(1), gas)
+	}
+
+	_, ok := txmp.GasWanted(types.Tx("missing").Key())
+	require.False(t, ok)
+}
+
+func TestTxMempool_InspectTxs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txmp := setup(ctx, t, 0)
+	txs := checkTxs(ctx, t, txmp, 10, 0)
+
+	details := txmp.InspectTxs("")
+	require.Len(t, details, len(txs))
+	reaped := txmp.ReapMaxTxs(-1)
+	for i, d := range details {
+		require.Equal(t, reaped[i].Key(), d.Hash)
+		require.Equal(t, len(reaped[i]), d.Size)
+		require.Equal(t, int64(1), d.GasWanted)
+		require.NotEmpty(t, d.Sender)
+		require.False(t, d.Timestamp.IsZero())
+	}
+
+	sender := details[3].Sender
+	filtered := txmp.InspectTxs(sender)
+	require.Equal(t, []TxDetails{details[3]}, filtered)
+	require.Empty(t, txmp.InspectTxs("unknown"))
+}
+
+func TestTxMempool_TxStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txmp := setup(ctx, t, 100)
+	txmp.evictions = newEvictionJournal(2)
+
+	txs := types.Txs{
+		types.Tx("sender-0=replace-a=10"),
+		types.Tx("sender-1=b=30"),
+		types.Tx("sender-2=c=20"),
+	}
+	for _, tx := range txs {
+		require.NoError(t, txmp.CheckTx(ctx, tx, nil, TxInfo{}))
+	}
+
+	// the rank follows the priority order
+	for i, rank := range []int{2, 0, 1} {
+		details, r, ok := txmp.PendingTx(txs[i].Key())
+		require.True(t, ok)
+		require.Equal(t, rank, r)
+		require.Equal(t, txs[i].Key(), details.Hash)
+	}
+	_, _, ok := txmp.PendingTx(types.Tx("missing").Key())
+	require.False(t, ok)
+
+	// the replaced and removed transactions are journaled
+	replacement := types.Tx("sender-0=replace-d=40")
+	require.NoError(t, txmp.CheckTx(ctx, replacement, nil, TxInfo{}))
+	_, _, ok = txmp.PendingTx(txs[0].Key())
+	require.False(t, ok)
+	eviction, ok := txmp.EvictedTx(txs[0].Key())
+	require.True(t, ok)
+	require.Equal(t, EvictedReplaced, eviction.Reason)
+	require.False(t, eviction.Time.IsZero())
+
+	require.NoError(t, txmp.RemoveTxByKey(txs[1].Key()))
+	eviction, ok = txmp.EvictedTx(txs[1].Key())
+	require.True(t, ok)
+	require.Equal(t, EvictedRemoved, eviction.Reason)
+
+	// the committed transactions are not, and the oldest evictions are
+	// dropped once the journal is full
+	txmp.Lock()
+	require.NoError(t, txmp.Update(ctx, 1, types.Txs{replacement},
+		[]*abci.ResponseDeliverTx{{Code: abci.CodeTypeOK}}, nil, nil))
+	txmp.Unlock()
+	_, ok = txmp.EvictedTx(replacement.Key())
+	require.False(t, ok)
+
+	txmp.Flush()
+	eviction, ok = txmp.EvictedTx(txs[2].Key())
+	require.True(t, ok)
+	require.Equal(t, EvictedFlushed, eviction.Reason)
+	require.Equal(t, int64(1), eviction.Height)
+	_, ok = txmp.EvictedTx(txs[0].Key())
+	require.False(t, ok)
+
+	// a transaction back in the mempool is no longer evicted
+	require.NoError(t, txmp.CheckTx(ctx, txs[1], nil, TxInfo{}))
+	_, ok = txmp.EvictedTx(txs[1].Key())
+	require.False(t, ok)
+}
+
+func TestTxMempool_DumpRestore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txmp := setup(ctx, t, 100)
+	txs := types.Txs{
+		types.Tx("sender-0=a=10"),
+		types.Tx("sender-1=b=30"),
+		types.Tx("sender-2=c=20"),
+	}
+	for _, tx := range txs {
+		require.NoError(t, txmp.CheckTx(ctx, tx, nil, TxInfo{SourceIP: "10.0.0.1"}))
+	}
+
+	path := filepath.Join(t.TempDir(), "mempool.json")
+	require.NoError(t, txmp.Dump().Save(path))
+	dump, err := LoadDump(path)
+	require.NoError(t, err)
+	require.Len(t, dump.Txs, 3)
+	require.Equal(t, txs[1], dump.Txs[0].Tx)
+
+	// The restored mempool has the same transactions with the same metadata,
+	// except for the one of a sender already in the mempool.
+	other := setup(ctx, t, 100)
+	require.NoError(t, other.CheckTx(ctx, types.Tx("sender-2=d=5"), nil, TxInfo{}))
+	restored, err := other.Restore(dump)
+	require.NoError(t, err)
+	require.Equal(t, 2, restored)
+	require.Equal(t, txmp.InspectTxs("sender-0"), other.InspectTxs("sender-0"))
+	require.Equal(t, txmp.InspectTxs("sender-1"), other.InspectTxs("sender-1"))
+	require.Equal(t, types.Txs{txs[1], txs[0]}, other.ReapMaxTxs(2))
+	// The restored transactions are cached, and not checked again.
+	require.NoError(t, other.CheckTx(ctx, txs[0], nil, TxInfo{}))
+	require.Equal(t, 3, other.Size())
+
+	// The transactions already in the mempool are skipped.
+	restored, err = other.Restore(dump)
+	require.NoError(t, err)
+	require.Equal(t, 0, restored)
+	require.Equal(t, 3, other.Size())
+}
+
 func TestTxMempool_Flush(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -413,6 +566,132 @@ func TestTxMempool_CheckTxSameSender(t *testing.T) {
 	require.Equal(t, 1, txmp.Size())
 }
 
+func TestTxMempool_CheckTxReplace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txmp := setup(ctx, t, 100)
+
+	var res *abci.ResponseCheckTx
+	cb := func(r *abci.Response) { res = r.GetCheckTx() }
+	requireTx := func(tx types.Tx) {
+		t.Helper()
+		require.Equal(t, 1, txmp.Size())
+		require.Equal(t, int64(len(tx)), txmp.SizeBytes())
+		require.Equal(t, tx, txmp.txStore.GetTxBySender("sender-0").tx)
+	}
+
+	tx1 := types.Tx("sender-0=replace-a=50")
+	require.NoError(t, txmp.CheckTx(ctx, tx1, cb, TxInfo{}))
+	requireTx(tx1)
+
+	// the default policy requires a higher priority
+	require.NoError(t, txmp.CheckTx(ctx, []byte("sender-0=replace-b=50"), cb, TxInfo{}))
+	require.Contains(t, res.MempoolError, "priority")
+	requireTx(tx1)
+
+	// the transactions not flagged as replacements are rejected
+	require.NoError(t, txmp.CheckTx(ctx, []byte("sender-0=key-c=60"), cb, TxInfo{}))
+	requireTx(tx1)
+
+	tx4 := types.Tx("sender-0=replace-d=60")
+	require.NoError(t, txmp.CheckTx(ctx, tx4, cb, TxInfo{}))
+	require.Empty(t, res.MempoolError)
+	requireTx(tx4)
+	require.Nil(t, txmp.txStore.GetTxByHash(tx1.Key()))
+
+	// the replaced transaction is kept if the replacement is rejected
+	txmp.quotas = newTxQuotas(int64(len(tx4)))
+	require.NoError(t, txmp.CheckTx(ctx, []byte("sender-0=replace-longer=70"), cb, TxInfo{SourceIP: "192.0.2.1"}))
+	require.Contains(t, res.MempoolError, "quota")
+	requireTx(tx4)
+
+	txmp.Lock()
+	require.NoError(t, txmp.Update(ctx, 1, types.Txs{tx4},
+		[]*abci.ResponseDeliverTx{{Code: abci.CodeTypeOK}}, nil, nil))
+	txmp.Unlock()
+	require.Equal(t, 0, txmp.Size())
+	require.Equal(t, int64(0), txmp.SizeBytes())
+
+	// the policy can be replaced
+	txmp = setup(ctx, t, 100, WithReplacementPolicy(func(existing, incoming ReplacementTx) error {
+		return errors.New("no replacements")
+	}))
+	require.NoError(t, txmp.CheckTx(ctx, tx1, cb, TxInfo{}))
+	require.NoError(t, txmp.CheckTx(ctx, tx4, cb, TxInfo{}))
+	require.Equal(t, "no replacements", res.MempoolError)
+	requireTx(tx1)
+}
+
+func TestTxMempool_SourceIPQuota(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txmp := setup(ctx, t, 100)
+
+	// txs of the same size, from distinct senders, with the given priority
+	tx := func(i int, priority int64) types.Tx {
+		return []byte(fmt.Sprintf("sender-%02d=value=%03d", i, priority))
+	}
+	txmp.quotas = newTxQuotas(int64(3 * len(tx(0, 0))))
+
+	client := TxInfo{SourceIP: "192.0.2.1"}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, txmp.CheckTx(ctx, tx(i, int64(10*(i+1))), nil, client))
+	}
+	require.Equal(t, 3, txmp.Size())
+
+	// a tx of higher priority evicts the lowest priority tx of the client
+	require.NoError(t, txmp.CheckTx(ctx, tx(3, 15), nil, client))
+	require.Equal(t, 3, txmp.Size())
+	require.Nil(t, txmp.txStore.GetTxByHash(tx(0, 10).Key()))
+	require.NotNil(t, txmp.txStore.GetTxByHash(tx(3, 15).Key()))
+
+	// a tx of lower priority is rejected
+	require.NoError(t, txmp.CheckTx(ctx, tx(4, 5), nil, client))
+	require.Equal(t, 3, txmp.Size())
+	require.Nil(t, txmp.txStore.GetTxByHash(tx(4, 5).Key()))
+
+	// other clients and peers are not affected
+	require.NoError(t, txmp.CheckTx(ctx, tx(5, 5), nil, TxInfo{SourceIP: "192.0.2.2"}))
+	require.NoError(t, txmp.CheckTx(ctx, tx(6, 5), nil, TxInfo{SenderID: 1}))
+	require.Equal(t, 5, txmp.Size())
+
+	// committing the client's txs releases its quota
+	txmp.Lock()
+	require.NoError(t, txmp.Update(ctx, 1, types.Txs{tx(1, 20), tx(2, 30)},
+		[]*abci.ResponseDeliverTx{{Code: abci.CodeTypeOK}, {Code: abci.CodeTypeOK}}, nil, nil))
+	txmp.Unlock()
+	require.NoError(t, txmp.CheckTx(ctx, tx(7, 5), nil, client))
+	require.NoError(t, txmp.CheckTx(ctx, tx(8, 5), nil, client))
+	require.Equal(t, 5, txmp.Size())
+}
+
+func TestTxMempool_SourceIPQuotaMempoolFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	txmp := setup(ctx, t, 100)
+	client := TxInfo{SourceIP: "192.0.2.1"}
+
+	lowTx := types.Tx("sender-0=value=5")
+	require.NoError(t, txmp.CheckTx(ctx, lowTx, nil, client))
+	require.NoError(t, txmp.CheckTx(ctx, []byte("sender-1=value=50"), nil, TxInfo{SenderID: 1}))
+	require.NoError(t, txmp.CheckTx(ctx, []byte("sender-2=value=50"), nil, TxInfo{SenderID: 1}))
+	require.Equal(t, 3, txmp.Size())
+
+	// bigTx evicts lowTx to fit in the quota of the client, but does not fit
+	// in the full mempool even then: it is rejected, and lowTx is kept.
+	bigTx := types.Tx("sender-3=" + strings.Repeat("v", 30) + "=10")
+	txmp.quotas = newTxQuotas(int64(len(bigTx) + 1))
+	txmp.config.MaxTxsBytes = txmp.SizeBytes()
+
+	require.NoError(t, txmp.CheckTx(ctx, bigTx, nil, client))
+	require.Equal(t, 3, txmp.Size())
+	require.Nil(t, txmp.txStore.GetTxByHash(bigTx.Key()))
+	require.NotNil(t, txmp.txStore.GetTxByHash(lowTx.Key()))
+}
+
 func TestTxMempool_ConcurrentTxs(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -480,6 +759,74 @@ func TestTxMempool_ConcurrentTxs(t *testing.T) {
 	require.Zero(t, txmp.SizeBytes())
 }
 
+func TestTxMempool_CheckTxConnections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The local clients do not share a mutex, so that the transactions are
+	// checked and admitted concurrently.
+	app := &application{kvstore.NewApplication()}
+	logger := log.TestingLogger()
+	conns := make([]abciclient.Client, 4)
+	for i := range conns {
+		conns[i] = abciclient.NewLocalClient(logger, nil, app)
+		require.NoError(t, conns[i].Start(ctx))
+	}
+	appConnMem := proxy.NewAppConnMempoolPool(conns, proxy.NopMetrics())
+	txmp := NewTxMempool(logger, config.TestMempoolConfig(), appConnMem, 0)
+	txmp.EnableTxsAvailable()
+
+	var (
+		wg  sync.WaitGroup
+		mtx sync.Mutex
+		txs []testTx
+	)
+	for i := range conns {
+		wg.Add(1)
+		go func(peerID uint16) {
+			defer wg.Done()
+
+			peerTxs := checkTxs(ctx, t, txmp, 50, peerID)
+			mtx.Lock()
+			txs = append(txs, peerTxs...)
+			mtx.Unlock()
+		}(uint16(i))
+	}
+	wg.Wait()
+	require.Equal(t, len(txs), txmp.Size())
+	<-txmp.TxsAvailable()
+
+	// The transactions with an odd priority fail the recheck and are removed.
+	postCheck := func(tx types.Tx, res *abci.ResponseCheckTx) error {
+		if res.Priority%2 == 1 {
+			return errors.New("odd priority")
+		}
+		return nil
+	}
+	var expected int
+	for _, tx := range txs {
+		if tx.priority%2 == 0 {
+			expected++
+		}
+	}
+
+	txmp.Lock()
+	require.NoError(t, txmp.Update(ctx, 1, nil, nil, nil, postCheck))
+	txmp.Unlock()
+
+	require.Equal(t, expected, txmp.Size())
+	for _, wtx := range txmp.txStore.GetAllTxs() {
+		require.Zero(t, wtx.priority%2)
+	}
+	if expected > 0 {
+		select {
+		case <-txmp.TxsAvailable():
+		case <-time.After(time.Second):
+			require.Fail(t, "expected transactions event")
+		}
+	}
+}
+
 func TestTxMempool_ExpiredTxs_NumBlocks(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -533,6 +880,101 @@ func TestTxMempool_ExpiredTxs_NumBlocks(t *testing.T) {
 	require.GreaterOrEqual(t, txmp.heightIndex.Size(), 45)
 }
 
+func TestTxMempool_ExpiredTxs_Events(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.NewDefault(log.TestingLogger())
+	require.NoError(t, eventBus.Start(ctx))
+	sub, err := eventBus.SubscribeWithArgs(ctx, tmpubsub.SubscribeArgs{
+		ClientID: "test",
+		Query:    types.EventQueryTxExpired,
+		Limit:    10,
+	})
+	require.NoError(t, err)
+
+	txmp := setup(ctx, t, 0, WithEventBus(eventBus))
+	txmp.height = 100
+	txmp.config.TTLNumBlocks = 10
+
+	tTxs := checkTxs(ctx, t, txmp, 3, 0)
+	require.Equal(t, 3, txmp.Size())
+
+	// No transaction expires until the TTL is exceeded.
+	txmp.Lock()
+	require.NoError(t, txmp.Update(ctx, 110, nil, nil, nil, nil))
+	txmp.Unlock()
+	require.Equal(t, 3, txmp.Size())
+
+	txmp.Lock()
+	require.NoError(t, txmp.Update(ctx, 111, nil, nil, nil, nil))
+	txmp.Unlock()
+	require.Zero(t, txmp.Size())
+
+	expired := make(map[string]bool)
+	for i := 0; i < len(tTxs); i++ {
+		msg, err := sub.Next(ctx)
+		require.NoError(t, err)
+		event := msg.Data().(types.EventDataTxExpired)
+		require.EqualValues(t, 111, event.Height)
+		require.EqualValues(t, 100, event.InsertedHeight)
+		require.Equal(t, types.TxExpiredReasonNumBlocks, event.Reason)
+		require.False(t, event.InsertedTime.IsZero())
+		require.Contains(t, msg.Events(), abci.Event{
+			Type:       "tx",
+			Attributes: []abci.EventAttribute{{Key: "hash", Value: fmt.Sprintf("%X", event.Tx.Hash())}},
+		})
+		expired[string(event.Tx)] = true
+	}
+	for _, tx := range tTxs {
+		require.True(t, expired[string(tx.tx)])
+	}
+}
+
+func TestTxMempool_CheckTx_Events(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.NewDefault(log.TestingLogger())
+	require.NoError(t, eventBus.Start(ctx))
+	sub, err := eventBus.SubscribeWithArgs(ctx, tmpubsub.SubscribeArgs{
+		ClientID: "test",
+		Query:    types.EventQueryCheckTx,
+		Limit:    10,
+	})
+	require.NoError(t, err)
+
+	txmp := setup(ctx, t, 0, WithEventBus(eventBus))
+	txmp.height = 100
+
+	tTxs := checkTxs(ctx, t, txmp, 3, 0)
+	checked := make(map[string]bool)
+	for i := 0; i < len(tTxs); i++ {
+		msg, err := sub.Next(ctx)
+		require.NoError(t, err)
+		event := msg.Data().(types.EventDataCheckTx)
+		require.EqualValues(t, 100, event.Height)
+		require.Equal(t, abci.CodeTypeOK, event.Result.Code)
+		require.Contains(t, msg.Events(), abci.Event{
+			Type:       "tx",
+			Attributes: []abci.EventAttribute{{Key: "hash", Value: fmt.Sprintf("%X", event.Tx.Hash())}},
+		})
+		checked[string(event.Tx)] = true
+	}
+	for _, tx := range tTxs {
+		require.True(t, checked[string(tx.tx)])
+	}
+
+	// The transactions are only published when they are first checked.
+	txmp.Lock()
+	require.NoError(t, txmp.Update(ctx, 101, nil, nil, nil, nil))
+	txmp.Unlock()
+	tctx, tcancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer tcancel()
+	_, err = sub.Next(tctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
 func TestTxMempool_CheckTxPostCheckError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -37,8 +37,27 @@ type Metrics struct {
 	// CheckTx.
 	EvictedTxs metrics.Counter
 
+	// ExpiredTxs defines the number of expired transactions. These are valid
+	// transactions that were evicted from the mempool because they were not
+	// included in a block within ttl-num-blocks blocks or ttl-duration.
+	ExpiredTxs metrics.Counter
+
+	// ReplacedTxs defines the number of replaced transactions. These are valid
+	// transactions that were evicted from the mempool in favor of a
+	// transaction of the same sender flagged as a replacement by CheckTx.
+	ReplacedTxs metrics.Counter
+
 	// Number of times transactions are rechecked in the mempool.
 	RecheckTimes metrics.Counter
+
+	// FilteredTxs defines the number of transactions dropped by the node-local
+	// filter chain before CheckTx, labeled by filter.
+	FilteredTxs metrics.Counter
+
+	// ProposalFilteredTxs defines the number of transactions left out of the
+	// blocks proposed by the node by the proposal filter chain, labeled by
+	// filter.
+	ProposalFilteredTxs metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -86,23 +105,55 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Help:      "Number of evicted transactions.",
 		}, labels).With(labelsAndValues...),
 
+		ExpiredTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "expired_txs",
+			Help:      "Number of transactions evicted from the mempool at the end of their TTL.",
+		}, labels).With(labelsAndValues...),
+
+		ReplacedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "replaced_txs",
+			Help:      "Number of transactions replaced by a transaction of the same sender.",
+		}, labels).With(labelsAndValues...),
+
 		RecheckTimes: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "recheck_times",
 			Help:      "Number of times transactions are rechecked in the mempool.",
 		}, labels).With(labelsAndValues...),
+
+		FilteredTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "filtered_txs",
+			Help:      "Number of transactions dropped by node-local filters before CheckTx.",
+		}, append(labels, "filter")).With(labelsAndValues...),
+
+		ProposalFilteredTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "proposal_filtered_txs",
+			Help:      "Number of transactions left out of the proposed blocks by node-local proposal filters.",
+		}, append(labels, "filter")).With(labelsAndValues...),
 	}
 }
 
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Size:         discard.NewGauge(),
-		TxSizeBytes:  discard.NewHistogram(),
-		FailedTxs:    discard.NewCounter(),
-		RejectedTxs:  discard.NewCounter(),
-		EvictedTxs:   discard.NewCounter(),
-		RecheckTimes: discard.NewCounter(),
+		Size:                discard.NewGauge(),
+		TxSizeBytes:         discard.NewHistogram(),
+		FailedTxs:           discard.NewCounter(),
+		RejectedTxs:         discard.NewCounter(),
+		EvictedTxs:          discard.NewCounter(),
+		ExpiredTxs:          discard.NewCounter(),
+		ReplacedTxs:         discard.NewCounter(),
+		RecheckTimes:        discard.NewCounter(),
+		FilteredTxs:         discard.NewCounter(),
+		ProposalFilteredTxs: discard.NewCounter(),
 	}
 }
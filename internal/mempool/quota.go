@@ -0,0 +1,109 @@
+package mempool
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// txQuotas tracks the bytes of the transactions in the mempool per source IP,
+// for transactions submitted over RPC, and enforces the configured cap on
+// them.
+//
+// NOTE: There is no quota per sender, as reported by the application in
+// CheckTx: the mempool holds a single transaction per sender, which is
+// already capped by MaxTxBytes.
+type txQuotas struct {
+	maxSourceIPBytes int64
+
+	mtx           sync.Mutex
+	sourceIPBytes map[string]int64
+}
+
+func newTxQuotas(maxSourceIPBytes int64) *txQuotas {
+	return &txQuotas{
+		maxSourceIPBytes: maxSourceIPBytes,
+		sourceIPBytes:    make(map[string]int64),
+	}
+}
+
+// add accounts for a transaction inserted in the mempool.
+func (q *txQuotas) add(wtx *WrappedTx) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if wtx.sourceIP != "" {
+		q.sourceIPBytes[wtx.sourceIP] += int64(wtx.Size())
+	}
+}
+
+// remove accounts for a transaction removed from the mempool.
+func (q *txQuotas) remove(wtx *WrappedTx) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if wtx.sourceIP == "" {
+		return
+	}
+	size := int64(wtx.Size())
+	if q.sourceIPBytes[wtx.sourceIP] <= size {
+		delete(q.sourceIPBytes, wtx.sourceIP)
+		return
+	}
+	q.sourceIPBytes[wtx.sourceIP] -= size
+}
+
+// excess returns the number of bytes by which the source IP of wtx would
+// exceed its quota if wtx was inserted, zero if within quota.
+func (q *txQuotas) excess(wtx *WrappedTx) int64 {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if q.maxSourceIPBytes <= 0 || wtx.sourceIP == "" {
+		return 0
+	}
+	if over := q.sourceIPBytes[wtx.sourceIP] + int64(wtx.Size()) - q.maxSourceIPBytes; over > 0 {
+		return over
+	}
+	return 0
+}
+
+// quotaVictims returns the transactions of lower priority from the source IP
+// of wtx to evict, lowest priority and newest first, to make room for wtx
+// within its quota. It returns an error if wtx cannot fit in its quota even
+// then. Nothing is evicted: the caller evicts the victims once wtx is
+// admitted.
+//
+// NOTE: wtx.priority and wtx.sourceIP must be set.
+func (txmp *TxMempool) quotaVictims(wtx *WrappedTx) ([]*WrappedTx, error) {
+	excess := txmp.quotas.excess(wtx)
+	if excess == 0 {
+		return nil, nil
+	}
+
+	var candidates []*WrappedTx
+	for _, other := range txmp.txStore.GetAllTxs() {
+		if other.sourceIP == wtx.sourceIP && other.priority < wtx.priority {
+			candidates = append(candidates, other)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].timestamp.After(candidates[j].timestamp)
+	})
+
+	var victims []*WrappedTx
+	for _, other := range candidates {
+		if excess <= 0 {
+			break
+		}
+		excess -= int64(other.Size())
+		victims = append(victims, other)
+	}
+	if excess > 0 {
+		return nil, fmt.Errorf("source IP %s exceeds its quota of %d bytes", wtx.sourceIP, txmp.quotas.maxSourceIPBytes)
+	}
+	return victims, nil
+}
@@ -9,10 +9,12 @@ import (
 	"time"
 
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/features"
 	"github.com/tendermint/tendermint/internal/libs/clist"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/libs/log"
+	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/libs/service"
 	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
 	"github.com/tendermint/tendermint/types"
@@ -23,6 +25,26 @@ var (
 	_ p2p.Wrapper     = (*protomem.Message)(nil)
 )
 
+// FlagAnnounceTxs gates the announcement of transactions to peers by hash,
+// with HaveTxs messages, the peers requesting the transactions they do not
+// have with WantTxs messages, instead of the transactions being sent in full
+// to every peer. All the peers must support these messages.
+var FlagAnnounceTxs = features.Declare("mempool.announce-txs",
+	"Announce transactions to peers by hash, and send them in full on request.", features.Experimental, false)
+
+const (
+	// maxAnnouncedTxs is the maximum number of hashes of a HaveTxs or WantTxs
+	// message.
+	maxAnnouncedTxs = 1000
+
+	// wantTxTimeout is how long a transaction requested from a peer is waited
+	// for, before it can be requested from another peer announcing it.
+	wantTxTimeout = 5 * time.Second
+
+	// maxWantedTxs is the maximum number of transactions waited for at once.
+	maxWantedTxs = 10000
+)
+
 // PeerManager defines the interface contract required for getting necessary
 // peer information. This should eventually be replaced with a message-oriented
 // approach utilizing the p2p stack.
@@ -59,6 +81,13 @@ type Reactor struct {
 
 	mtx          sync.Mutex
 	peerRoutines map[types.NodeID]*tmsync.Closer
+
+	features *features.Set
+
+	// wanted records when the transactions requested from peers, by key, were
+	// requested.
+	wantedMtx sync.Mutex
+	wanted    map[types.TxKey]time.Time
 }
 
 // NewReactor returns a reference to a new reactor.
@@ -87,6 +116,7 @@ func NewReactor(
 		peerUpdates:  peerUpdates,
 		peerRoutines: make(map[types.NodeID]*tmsync.Closer),
 		observePanic: defaultObservePanic,
+		wanted:       make(map[types.TxKey]time.Time),
 	}
 
 	r.BaseService = *service.NewBaseService(logger, "Mempool", r)
@@ -95,6 +125,12 @@ func NewReactor(
 
 func defaultObservePanic(r interface{}) {}
 
+// SetFeatures sets the feature flags of the node. It must be called before the
+// reactor is started.
+func (r *Reactor) SetFeatures(set *features.Set) {
+	r.features = set
+}
+
 // getChannelDescriptor produces an instance of a descriptor for this
 // package's required channels.
 func getChannelDescriptor(cfg *config.MempoolConfig) *p2p.ChannelDescriptor {
@@ -104,12 +140,21 @@ func getChannelDescriptor(cfg *config.MempoolConfig) *p2p.ChannelDescriptor {
 			Txs: &protomem.Txs{Txs: [][]byte{largestTx}},
 		},
 	}
+	hashes := make([][]byte, maxAnnouncedTxs)
+	for i := range hashes {
+		hashes[i] = make([]byte, len(types.TxKey{}))
+	}
+	haveMsg := protomem.Message{
+		Sum: &protomem.Message_HaveTxs{
+			HaveTxs: &protomem.HaveTxs{Hashes: hashes},
+		},
+	}
 
 	return &p2p.ChannelDescriptor{
 		ID:                  MempoolChannel,
 		MessageType:         new(protomem.Message),
 		Priority:            5,
-		RecvMessageCapacity: batchMsg.Size(),
+		RecvMessageCapacity: tmmath.MaxInt(batchMsg.Size(), haveMsg.Size()),
 		RecvBufferCapacity:  128,
 	}
 }
@@ -143,9 +188,10 @@ func (r *Reactor) OnStop() {
 }
 
 // handleMempoolMessage handles envelopes sent from peers on the MempoolChannel.
-// For every tx in the message, we execute CheckTx. It returns an error if an
-// empty set of txs are sent in an envelope or if we receive an unexpected
-// message type.
+// For every tx in the message, we execute CheckTx. The announced txs we do not
+// have are requested from the peer, and the txs requested by the peer are sent
+// to it. It returns an error if an empty set of txs or hashes are sent in an
+// envelope or if we receive an unexpected message type.
 func (r *Reactor) handleMempoolMessage(ctx context.Context, envelope *p2p.Envelope) error {
 	logger := r.logger.With("peer", envelope.From)
 
@@ -162,11 +208,21 @@ func (r *Reactor) handleMempoolMessage(ctx context.Context, envelope *p2p.Envelo
 		}
 
 		for _, tx := range protoTxs {
+			r.wantedMtx.Lock()
+			delete(r.wanted, types.Tx(tx).Key())
+			r.wantedMtx.Unlock()
+
 			if err := r.mempool.CheckTx(ctx, types.Tx(tx), nil, txInfo); err != nil {
 				logger.Error("checktx failed for tx", "tx", fmt.Sprintf("%X", types.Tx(tx).Hash()), "err", err)
 			}
 		}
 
+	case *protomem.HaveTxs:
+		return r.handleHaveTxs(ctx, envelope.From, msg.GetHashes())
+
+	case *protomem.WantTxs:
+		return r.handleWantTxs(ctx, envelope.From, msg.GetHashes())
+
 	default:
 		return fmt.Errorf("received unknown message: %T", msg)
 	}
@@ -174,6 +230,94 @@ func (r *Reactor) handleMempoolMessage(ctx context.Context, envelope *p2p.Envelo
 	return nil
 }
 
+// handleHaveTxs requests from a peer the txs it announced which are neither
+// in the mempool nor in its cache, nor already requested from another peer.
+func (r *Reactor) handleHaveTxs(ctx context.Context, peerID types.NodeID, hashes [][]byte) error {
+	keys, err := txKeys(hashes)
+	if err != nil {
+		return err
+	}
+	peerMempoolID := r.ids.GetForPeer(peerID)
+
+	now := time.Now()
+	want := make([][]byte, 0, len(keys))
+	r.wantedMtx.Lock()
+	for i, key := range keys {
+		if r.mempool.hasTx(key) {
+			// The tx is not announced back to the peer.
+			r.mempool.txStore.GetOrSetPeerByTxHash(key, peerMempoolID)
+			continue
+		}
+		if at, ok := r.wanted[key]; ok && now.Sub(at) < wantTxTimeout {
+			continue
+		}
+		if len(r.wanted) >= maxWantedTxs {
+			for key, at := range r.wanted {
+				if now.Sub(at) >= wantTxTimeout {
+					delete(r.wanted, key)
+				}
+			}
+			if len(r.wanted) >= maxWantedTxs {
+				break
+			}
+		}
+		r.wanted[key] = now
+		want = append(want, hashes[i])
+	}
+	r.wantedMtx.Unlock()
+
+	if len(want) == 0 {
+		return nil
+	}
+	return r.mempoolCh.Send(ctx, p2p.Envelope{
+		To:      peerID,
+		Message: &protomem.WantTxs{Hashes: want},
+	})
+}
+
+// handleWantTxs sends to a peer the txs it requested which are in the
+// mempool, one per message.
+func (r *Reactor) handleWantTxs(ctx context.Context, peerID types.NodeID, hashes [][]byte) error {
+	keys, err := txKeys(hashes)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		wtx := r.mempool.txStore.GetTxByHash(key)
+		if wtx == nil {
+			continue
+		}
+		if err := r.mempoolCh.Send(ctx, p2p.Envelope{
+			To:      peerID,
+			Message: &protomem.Txs{Txs: [][]byte{wtx.tx}},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txKeys validates the tx hashes of a HaveTxs or WantTxs message, and
+// returns them as keys.
+func txKeys(hashes [][]byte) ([]types.TxKey, error) {
+	if len(hashes) == 0 {
+		return nil, errors.New("empty tx hashes received from peer")
+	}
+	if len(hashes) > maxAnnouncedTxs {
+		return nil, fmt.Errorf("too many tx hashes received from peer: %d > %d", len(hashes), maxAnnouncedTxs)
+	}
+
+	keys := make([]types.TxKey, len(hashes))
+	for i, hash := range hashes {
+		if len(hash) != len(keys[i]) {
+			return nil, fmt.Errorf("invalid tx hash length %d", len(hash))
+		}
+		copy(keys[i][:], hash)
+	}
+	return keys, nil
+}
+
 // handleMessage handles an Envelope sent from a peer on a specific p2p Channel.
 // It will handle errors and any possible panics gracefully. A caller can handle
 // any error returned by sending a PeerError on the respective channel.
@@ -288,9 +432,16 @@ func (r *Reactor) processPeerUpdates(ctx context.Context) {
 	}
 }
 
+// broadcastTxRoutine gossips the mempool txs to a peer, in FIFO order. With
+// FlagAnnounceTxs, the txs are announced by hash, in batches of the txs
+// available at once, and sent on request.
 func (r *Reactor) broadcastTxRoutine(ctx context.Context, peerID types.NodeID, closer *tmsync.Closer) {
 	peerMempoolID := r.ids.GetForPeer(peerID)
-	var nextGossipTx *clist.CElement
+	announce := r.features.Enabled(FlagAnnounceTxs)
+	var (
+		nextGossipTx *clist.CElement
+		announced    [][]byte
+	)
 
 	// remove the peer ID from the map of routines and mark the waitgroup as done
 	defer func() {
@@ -347,7 +498,10 @@ func (r *Reactor) broadcastTxRoutine(ctx context.Context, peerID types.NodeID, c
 
 		// NOTE: Transaction batching was disabled due to:
 		// https://github.com/tendermint/tendermint/issues/5796
-		if ok := r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID); !ok {
+		if ok := r.mempool.txStore.TxHasPeer(memTx.hash, peerMempoolID); !ok && announce {
+			hash := memTx.hash
+			announced = append(announced, hash[:])
+		} else if !ok {
 			// Send the mempool tx to the corresponding peer. Note, the peer may be
 			// behind and thus would not be able to process the mempool tx correctly.
 			if err := r.mempoolCh.Send(ctx, p2p.Envelope{
@@ -366,6 +520,19 @@ func (r *Reactor) broadcastTxRoutine(ctx context.Context, peerID types.NodeID, c
 			)
 		}
 
+		// The announced txs are sent at once before waiting for the next tx.
+		if len(announced) >= maxAnnouncedTxs || (len(announced) > 0 && nextGossipTx.Next() == nil) {
+			if err := r.mempoolCh.Send(ctx, p2p.Envelope{
+				To:      peerID,
+				Message: &protomem.HaveTxs{Hashes: announced},
+			}); err != nil {
+				return
+			}
+
+			r.logger.Debug("announced txs to peer", "num_txs", len(announced), "peer", peerID)
+			announced = nil
+		}
+
 		select {
 		case <-nextGossipTx.NextWaitChan():
 			nextGossipTx = nextGossipTx.Next()
@@ -0,0 +1,67 @@
+package mempool
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/p2psim"
+	"github.com/tendermint/tendermint/libs/log"
+	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
+)
+
+// BenchmarkReactorTxGossip measures the cost of receiving a transaction from
+// one of many peers, and gossiping it to all the others.
+func BenchmarkReactorTxGossip(b *testing.B) {
+	for _, numPeers := range []int{10, 100, 300} {
+		b.Run(fmt.Sprintf("peers=%d", numPeers), func(b *testing.B) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			logger := log.NewNopLogger()
+			sim, err := p2psim.NewNetwork(ctx, logger, p2psim.Options{})
+			require.NoError(b, err)
+
+			txmp := setup(ctx, b, 0)
+			txmp.config.Size = b.N
+			reactor, err := NewReactor(ctx, logger, txmp.config, sim.PeerManager, txmp,
+				sim.OpenChannel, sim.PeerManager.Subscribe(ctx))
+			require.NoError(b, err)
+			require.NoError(b, reactor.Start(ctx))
+
+			// The peers spam the node with the b.N transactions, each sent by
+			// a single peer.
+			var next int64
+			spam := p2psim.Script{
+				Next: func(*p2psim.Peer, int) *p2p.Envelope {
+					i := atomic.AddInt64(&next, 1)
+					if i > int64(b.N) {
+						return nil
+					}
+					tx := []byte(fmt.Sprintf("sender-%d=key=%d", i, 1000+i%9000))
+					return &p2p.Envelope{
+						ChannelID: MempoolChannel,
+						Message:   &protomem.Txs{Txs: [][]byte{tx}},
+					}
+				},
+			}
+
+			b.ResetTimer()
+			sim.ResetStats()
+			_, err = sim.AddPeers(ctx, numPeers, spam)
+			require.NoError(b, err)
+
+			gossiped := uint64(b.N * (numPeers - 1))
+			for txmp.Size() < b.N || sim.Stats().Total().Received < gossiped {
+				time.Sleep(time.Millisecond)
+			}
+			b.StopTimer()
+			sim.Stats().Report(b)
+		})
+	}
+}
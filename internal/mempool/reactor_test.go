@@ -14,6 +14,7 @@ import (
 	"github.com/tendermint/tendermint/abci/example/kvstore"
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/features"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/p2p/p2ptest"
@@ -38,7 +39,15 @@ type reactorTestSuite struct {
 	nodes []types.NodeID
 }
 
-func setupReactors(ctx context.Context, t *testing.T, numNodes int, chBuf uint) *reactorTestSuite {
+// setupReactors starts numNodes reactors, after applying the options to
+// them.
+func setupReactors(
+	ctx context.Context,
+	t *testing.T,
+	numNodes int,
+	chBuf uint,
+	options ...func(*Reactor),
+) *reactorTestSuite {
 	t.Helper()
 
 	cfg, err := config.ResetTestRoot(strings.ReplaceAll(t.Name(), "/", "|"))
@@ -85,6 +94,9 @@ func setupReactors(ctx context.Context, t *testing.T, numNodes int, chBuf uint)
 
 		require.NoError(t, err)
 		rts.nodes = append(rts.nodes, nodeID)
+		for _, option := range options {
+			option(rts.reactors[nodeID])
+		}
 
 		require.NoError(t, rts.reactors[nodeID].Start(ctx))
 		require.True(t, rts.reactors[nodeID].IsRunning())
@@ -213,6 +225,69 @@ func TestReactorBroadcastTxs(t *testing.T) {
 	rts.waitForTxns(t, convertTex(txs), secondaries...)
 }
 
+func TestReactorAnnounceTxs(t *testing.T) {
+	numTxs := 1000
+	numNodes := 5
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	set, err := features.ParseSet([]string{FlagAnnounceTxs.Name})
+	require.NoError(t, err)
+	rts := setupReactors(ctx, t, numNodes, uint(numTxs), func(r *Reactor) { r.SetFeatures(set) })
+
+	primary := rts.nodes[0]
+	secondaries := rts.nodes[1:]
+
+	txs := checkTxs(ctx, t, rts.reactors[primary].mempool, numTxs, UnknownPeerID)
+	rts.start(ctx, t)
+
+	// The txs are announced by the primary node, then by the secondary nodes
+	// once they have them, and requested only once at a time.
+	rts.waitForTxns(t, convertTex(txs), secondaries...)
+}
+
+func TestReactorHaveWantTxs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rts := setupReactors(ctx, t, 2, 0)
+	primary, secondary := rts.nodes[0], rts.nodes[1]
+	reactor := rts.reactors[primary]
+	txs := checkTxs(ctx, t, reactor.mempool, 2, UnknownPeerID)
+	rts.start(ctx, t)
+
+	// The txs of the mempool are not requested, the unknown ones once until
+	// the request times out.
+	wantedAt := func(key types.TxKey) (time.Time, bool) {
+		reactor.wantedMtx.Lock()
+		defer reactor.wantedMtx.Unlock()
+		at, ok := reactor.wanted[key]
+		return at, ok
+	}
+	known, unknown := txs[0].tx.Key(), types.Tx("unknown").Key()
+	require.NoError(t, reactor.handleHaveTxs(ctx, secondary, [][]byte{known[:], unknown[:]}))
+	requested, ok := wantedAt(unknown)
+	require.True(t, ok)
+	_, ok = wantedAt(known)
+	require.False(t, ok)
+	require.True(t, reactor.mempool.txStore.TxHasPeer(known, reactor.ids.GetForPeer(secondary)))
+	require.NoError(t, reactor.handleHaveTxs(ctx, secondary, [][]byte{unknown[:]}))
+	at, _ := wantedAt(unknown)
+	require.Equal(t, requested, at)
+
+	require.Error(t, reactor.handleHaveTxs(ctx, secondary, nil))
+	require.Error(t, reactor.handleHaveTxs(ctx, secondary, [][]byte{{1, 2, 3}}))
+	require.Error(t, reactor.handleWantTxs(ctx, secondary, make([][]byte, maxAnnouncedTxs+1)))
+
+	// The requested txs are sent to the peer.
+	other := txs[1].tx.Key()
+	require.NoError(t, rts.reactors[secondary].handleWantTxs(ctx, primary, [][]byte{other[:]}))
+	require.NoError(t, reactor.handleWantTxs(ctx, secondary, [][]byte{other[:], unknown[:]}))
+	require.Eventually(t, func() bool {
+		return rts.mempools[secondary].txStore.GetTxByHash(other) != nil
+	}, 10*time.Second, 50*time.Millisecond)
+}
+
 // regression test for https://github.com/tendermint/tendermint/issues/5408
 func TestReactorConcurrency(t *testing.T) {
 	numTxs := 10
@@ -0,0 +1,38 @@
+package mempool
+
+import (
+	"fmt"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// ReplacementTx describes a transaction considered by a ReplacementPolicy.
+type ReplacementTx struct {
+	Tx        types.Tx
+	Sender    string
+	Priority  int64
+	GasWanted int64
+}
+
+// ReplacementPolicy decides if the incoming transaction, which CheckTx flagged
+// as a replacement, may replace the existing transaction of the same sender.
+// It returns a non-nil error to reject the incoming transaction, in which case
+// the existing one is kept.
+type ReplacementPolicy func(existing, incoming ReplacementTx) error
+
+// PriorityReplacementPolicy is the default ReplacementPolicy. It only accepts
+// a replacement with a higher priority than the existing transaction, e.g. a
+// fee bump.
+func PriorityReplacementPolicy(existing, incoming ReplacementTx) error {
+	if incoming.Priority <= existing.Priority {
+		return fmt.Errorf("replacement priority %d is not higher than the priority %d of the existing transaction",
+			incoming.Priority, existing.Priority)
+	}
+	return nil
+}
+
+// WithReplacementPolicy sets the policy deciding if a transaction flagged as
+// a replacement by CheckTx replaces the existing transaction of its sender.
+func WithReplacementPolicy(policy ReplacementPolicy) TxMempoolOption {
+	return func(txmp *TxMempool) { txmp.replacementPolicy = policy }
+}
@@ -19,6 +19,10 @@ type TxInfo struct {
 
 	// SenderNodeID is the actual types.NodeID of the sender.
 	SenderNodeID types.NodeID
+
+	// SourceIP is the IP address of the client that submitted the transaction
+	// over RPC, if any.
+	SourceIP string
 }
 
 // WrappedTx defines a wrapper around a raw transaction with additional metadata
@@ -44,6 +48,10 @@ type WrappedTx struct {
 	// the ResponseCheckTx response.
 	sender string
 
+	// sourceIP is the IP address of the RPC client that submitted the
+	// transaction, empty for transactions received from peers.
+	sourceIP string
+
 	// timestamp is the time at which the node first received the transaction from
 	// a peer. It is used as a second dimension is prioritizing transactions when
 	// two transactions have the same priority.
@@ -144,11 +152,14 @@ func (txs *TxStore) IsTxRemoved(hash types.TxKey) bool {
 
 // SetTx stores a *WrappedTx by it's hash. If the transaction also contains a
 // non-empty sender, we additionally store the transaction by the sender as
-// defined by the ABCI application.
+// defined by the ABCI application. A removed transaction stored again is no
+// longer marked as removed.
 func (txs *TxStore) SetTx(wtx *WrappedTx) {
 	txs.mtx.Lock()
 	defer txs.mtx.Unlock()
 
+	wtx.removed = false
+
 	if len(wtx.sender) > 0 {
 		txs.senderTxs[wtx.sender] = wtx
 	}
@@ -0,0 +1,139 @@
+package p2p
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// AddressBookPeer is a peer of the peer store, as exported to be imported by
+// another node, or by the same node after its data was reset.
+type AddressBookPeer struct {
+	NodeID        types.NodeID
+	Addresses     []NodeAddress
+	LastConnected time.Time
+	// Score is the score of the peer from the reports of the reactors.
+	Score int64
+}
+
+// PeerScoreDetails details the score of a peer.
+type PeerScoreDetails struct {
+	NodeID     types.NodeID
+	Score      PeerScore // the score used to rank the peer
+	Persistent bool
+
+	// MutableScore is the score from the reports of the reactors, and
+	// Reputation the points earned or lost from the statistics below.
+	MutableScore int64
+	Reputation   int64
+	DialFailures uint32
+
+	Latency      time.Duration
+	Uptime       time.Duration
+	Misbehaviors uint64
+}
+
+// ExportAddressBook returns the peers of the peer store, best first.
+func (m *PeerManager) ExportAddressBook() []AddressBookPeer {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	peers := make([]AddressBookPeer, 0, m.store.Size())
+	for _, peer := range m.store.Ranked() {
+		exported := AddressBookPeer{
+			NodeID:        peer.ID,
+			LastConnected: peer.LastConnected,
+			Score:         peer.MutableScore,
+		}
+		for address := range peer.AddressInfo {
+			exported.Addresses = append(exported.Addresses, address)
+		}
+		peers = append(peers, exported)
+	}
+	return peers
+}
+
+// ImportAddressBook adds the peers to the peer store, with their addresses,
+// and returns the number of addresses added. The peers the node has no
+// statistics of take the imported scores, while the others keep their own.
+// The peers are validated before any is added, and this node is skipped.
+func (m *PeerManager) ImportAddressBook(peers []AddressBookPeer) (int, error) {
+	for _, peer := range peers {
+		if err := peer.NodeID.Validate(); err != nil {
+			return 0, fmt.Errorf("invalid peer %q: %w", peer.NodeID, err)
+		}
+		for _, address := range peer.Addresses {
+			if err := address.Validate(); err != nil {
+				return 0, fmt.Errorf("invalid address %q of peer %v: %w", address, peer.NodeID, err)
+			}
+			if address.NodeID != peer.NodeID {
+				return 0, fmt.Errorf("address %q is not an address of peer %v", address, peer.NodeID)
+			}
+		}
+	}
+
+	added := 0
+	for _, peer := range peers {
+		if peer.NodeID == m.selfID {
+			continue
+		}
+		for _, address := range peer.Addresses {
+			ok, err := m.Add(address)
+			if err != nil {
+				return added, err
+			}
+			if ok {
+				added++
+			}
+		}
+		if _, ok := m.stats.Get(peer.NodeID); !ok && peer.Score != 0 {
+			m.importScore(peer.NodeID, peer.Score)
+		}
+	}
+	return added, nil
+}
+
+// importScore sets the score of a peer of the peer store.
+func (m *PeerManager) importScore(id types.NodeID, score int64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	peer, ok := m.store.peers[id]
+	if !ok {
+		// The peer was pruned already.
+		return
+	}
+	peer.MutableScore = score
+	m.store.ranked = nil
+	m.stats.RecordScore(id, score)
+}
+
+// ScoreDetails returns the details of the scores of the peers of the peer
+// store, best first.
+func (m *PeerManager) ScoreDetails() []PeerScoreDetails {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := time.Now()
+	details := make([]PeerScoreDetails, 0, m.store.Size())
+	for _, peer := range m.store.Ranked() {
+		d := PeerScoreDetails{
+			NodeID:       peer.ID,
+			Score:        peer.Score(),
+			Persistent:   peer.Persistent,
+			MutableScore: peer.MutableScore,
+			Reputation:   peer.Reputation,
+		}
+		for _, addressInfo := range peer.AddressInfo {
+			d.DialFailures += addressInfo.DialFailures
+		}
+		if stats, ok := m.stats.Get(peer.ID); ok {
+			d.Latency = stats.Latency
+			d.Uptime = stats.Uptime(m.connected[peer.ID], now)
+			d.Misbehaviors = stats.Misbehaviors
+		}
+		details = append(details, d)
+	}
+	return details
+}
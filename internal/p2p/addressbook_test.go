@@ -0,0 +1,112 @@
+package p2p_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPeerManager_AddressBook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	aID := types.NodeID("aa112233445566778899aabbccddeeff00112233")
+	bID := types.NodeID("bb112233445566778899aabbccddeeff00112233")
+	a := p2p.NodeAddress{Protocol: "tcp", NodeID: aID, Hostname: "203.0.113.1", Port: 26656}
+	b := p2p.NodeAddress{Protocol: "tcp", NodeID: bID, Hostname: "203.0.113.2", Port: 26656}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{a, b} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+	updates := peerManager.Subscribe(ctx)
+	updates.SendUpdate(ctx, p2p.PeerUpdate{NodeID: bID, Status: p2p.PeerStatusGood})
+	require.Eventually(t, func() bool { return peerManager.Scores()[bID] == 1 }, time.Second, time.Millisecond)
+
+	exported := peerManager.ExportAddressBook()
+	require.Equal(t, []p2p.AddressBookPeer{
+		{NodeID: bID, Addresses: []p2p.NodeAddress{b}, Score: 1},
+		{NodeID: aID, Addresses: []p2p.NodeAddress{a}},
+	}, exported)
+
+	// Another node imports the peers, with their scores, but not itself.
+	other, err := p2p.NewPeerManager(aID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	added, err := other.ImportAddressBook(exported)
+	require.NoError(t, err)
+	require.Equal(t, 1, added)
+	require.Equal(t, []types.NodeID{bID}, other.Peers())
+	require.EqualValues(t, 1, other.Scores()[bID])
+
+	// Importing again adds nothing.
+	added, err = other.ImportAddressBook(exported)
+	require.NoError(t, err)
+	require.Zero(t, added)
+
+	// An address of another peer is rejected, with the whole import.
+	_, err = other.ImportAddressBook([]p2p.AddressBookPeer{
+		{NodeID: selfID, Addresses: []p2p.NodeAddress{{Protocol: "tcp", NodeID: selfID, Hostname: "203.0.113.3", Port: 26656}}},
+		{NodeID: bID, Addresses: []p2p.NodeAddress{a}},
+	})
+	require.Error(t, err)
+	require.Equal(t, []types.NodeID{bID}, other.Peers())
+}
+
+func TestPeerManager_ScoresPersisted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	aID := types.NodeID("aa112233445566778899aabbccddeeff00112233")
+	bID := types.NodeID("bb112233445566778899aabbccddeeff00112233")
+	db := dbm.NewMemDB()
+
+	peerManager, err := p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for _, id := range []types.NodeID{aID, bID} {
+		_, err := peerManager.Add(p2p.NodeAddress{Protocol: "memory", NodeID: id})
+		require.NoError(t, err)
+	}
+
+	updates := peerManager.Subscribe(ctx)
+	for i := 0; i < 3; i++ {
+		updates.SendUpdate(ctx, p2p.PeerUpdate{NodeID: aID, Status: p2p.PeerStatusGood})
+	}
+	require.Eventually(t, func() bool { return peerManager.Scores()[aID] == 3 }, time.Second, time.Millisecond)
+
+	// A fast peer earns points on reconnection, a misbehaving one loses some.
+	stats := peerManager.Stats()
+	stats.RecordLatency(bID, 10*time.Millisecond)
+	stats.RecordConnected(bID)
+	stats.RecordDisconnected(bID)
+	peerManager.Disconnected(ctx, bID)
+	require.EqualValues(t, 8, peerManager.Scores()[bID])
+	stats.RecordMisbehavior(bID)
+	peerManager.Disconnected(ctx, bID)
+	require.EqualValues(t, 4, peerManager.Scores()[bID])
+	require.NoError(t, stats.Flush())
+
+	// The scores survive a restart.
+	peerManager, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[types.NodeID]p2p.PeerScore{aID: 3, bID: 4}, peerManager.Scores())
+
+	details := peerManager.ScoreDetails()
+	require.Len(t, details, 2)
+	require.Equal(t, p2p.PeerScoreDetails{
+		NodeID:       bID,
+		Score:        4,
+		Reputation:   4,
+		Latency:      10 * time.Millisecond,
+		Uptime:       details[0].Uptime,
+		Misbehaviors: 1,
+	}, details[0])
+}
@@ -0,0 +1,171 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// PeerBandwidth is the bandwidth used by a connected peer since it connected,
+// by channel.
+type PeerBandwidth struct {
+	NodeID    types.NodeID
+	Connected time.Time
+	Channels  []ChannelBandwidth // sorted by channel ID
+}
+
+// ChannelBandwidth is the bandwidth used by a peer on a channel.
+type ChannelBandwidth struct {
+	ChannelID     ChannelID
+	BytesSent     uint64
+	BytesReceived uint64
+	// RecvRateLimit is the rate limit of the messages received from the peer
+	// on the channel, in bytes/second, or 0 if unlimited.
+	RecvRateLimit int64
+	// Throttled is the time spent delaying the messages received from the
+	// peer on the channel to enforce the rate limit.
+	Throttled time.Duration
+}
+
+// bandwidthMeter accounts for the bytes exchanged with the connected peers,
+// by channel, and enforces the rate limits of the messages received from
+// them, with a token bucket per peer and channel. It is safe for concurrent
+// use.
+type bandwidthMeter struct {
+	limits map[ChannelID]int64 // bytes/second
+	now    func() time.Time
+
+	mtx   sync.Mutex
+	peers map[types.NodeID]*peerMeter
+}
+
+type peerMeter struct {
+	connected time.Time
+	channels  map[ChannelID]*channelMeter
+}
+
+type channelMeter struct {
+	sent      uint64
+	received  uint64
+	throttled time.Duration
+
+	// The bucket holds up to one second of the rate limit, and goes negative
+	// when a message exceeds it, until refilled.
+	tokens  float64
+	updated time.Time
+}
+
+func newBandwidthMeter(limits map[ChannelID]int64) *bandwidthMeter {
+	return &bandwidthMeter{
+		limits: limits,
+		now:    time.Now,
+		peers:  make(map[types.NodeID]*peerMeter),
+	}
+}
+
+// connected starts the accounting of a peer.
+func (m *bandwidthMeter) connected(peerID types.NodeID) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.peers[peerID] = &peerMeter{
+		connected: m.now().UTC(),
+		channels:  make(map[ChannelID]*channelMeter),
+	}
+}
+
+// disconnected ends the accounting of a peer.
+func (m *bandwidthMeter) disconnected(peerID types.NodeID) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.peers, peerID)
+}
+
+// channel returns the meter of a peer's channel, or nil if the peer is not
+// connected. The caller must hold the mutex.
+func (m *bandwidthMeter) channel(peerID types.NodeID, chID ChannelID) *channelMeter {
+	peer, ok := m.peers[peerID]
+	if !ok {
+		return nil
+	}
+	ch, ok := peer.channels[chID]
+	if !ok {
+		ch = &channelMeter{tokens: float64(m.limits[chID]), updated: m.now()}
+		peer.channels[chID] = ch
+	}
+	return ch
+}
+
+// addSent accounts for a message of size bytes sent to a peer.
+func (m *bandwidthMeter) addSent(peerID types.NodeID, chID ChannelID, size int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if ch := m.channel(peerID, chID); ch != nil {
+		ch.sent += uint64(size)
+	}
+}
+
+// addReceived accounts for a message of size bytes received from a peer, and
+// returns how long to delay it to enforce the rate limit of the channel.
+func (m *bandwidthMeter) addReceived(peerID types.NodeID, chID ChannelID, size int) time.Duration {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	ch := m.channel(peerID, chID)
+	if ch == nil {
+		return 0
+	}
+	ch.received += uint64(size)
+
+	limit := m.limits[chID]
+	if limit <= 0 {
+		return 0
+	}
+	now := m.now()
+	ch.tokens += now.Sub(ch.updated).Seconds() * float64(limit)
+	if ch.tokens > float64(limit) {
+		ch.tokens = float64(limit)
+	}
+	ch.updated = now
+	ch.tokens -= float64(size)
+	if ch.tokens >= 0 {
+		return 0
+	}
+	delay := time.Duration(-ch.tokens / float64(limit) * float64(time.Second))
+	ch.throttled += delay
+	return delay
+}
+
+// list returns the bandwidth used by the connected peers, sorted by node ID.
+func (m *bandwidthMeter) list() []PeerBandwidth {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	out := make([]PeerBandwidth, 0, len(m.peers))
+	for peerID, peer := range m.peers {
+		pb := PeerBandwidth{
+			NodeID:    peerID,
+			Connected: peer.connected,
+			Channels:  make([]ChannelBandwidth, 0, len(peer.channels)),
+		}
+		for chID, ch := range peer.channels {
+			pb.Channels = append(pb.Channels, ChannelBandwidth{
+				ChannelID:     chID,
+				BytesSent:     ch.sent,
+				BytesReceived: ch.received,
+				RecvRateLimit: m.limits[chID],
+				Throttled:     ch.throttled,
+			})
+		}
+		sort.Slice(pb.Channels, func(i, j int) bool { return pb.Channels[i].ChannelID < pb.Channels[j].ChannelID })
+		out = append(out, pb)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// PeerBandwidth returns the bandwidth used by the connected peers since they
+// connected, by channel.
+func (r *Router) PeerBandwidth() []PeerBandwidth {
+	return r.bandwidth.list()
+}
@@ -0,0 +1,61 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestBandwidthMeter(t *testing.T) {
+	now := time.Now()
+	meter := newBandwidthMeter(map[ChannelID]int64{0x30: 1000})
+	meter.now = func() time.Time { return now }
+
+	peerA, peerB := types.NodeID("aa"), types.NodeID("bb")
+	meter.connected(peerB)
+	meter.connected(peerA)
+
+	// The unlimited channels are never throttled.
+	require.Zero(t, meter.addReceived(peerA, 0x20, 1<<20))
+
+	// The bucket holds one second of the limit, and a message exceeding it
+	// is delayed until refilled.
+	require.Zero(t, meter.addReceived(peerA, 0x30, 600))
+	require.Zero(t, meter.addReceived(peerA, 0x30, 400))
+	require.Equal(t, 500*time.Millisecond, meter.addReceived(peerA, 0x30, 500))
+	now = now.Add(time.Second)
+	require.Zero(t, meter.addReceived(peerA, 0x30, 500))
+
+	// The peers have their own buckets.
+	require.Zero(t, meter.addReceived(peerB, 0x30, 1000))
+
+	meter.addSent(peerA, 0x30, 10)
+	meter.addSent(peerA, 0x20, 20)
+	// The disconnected peers are ignored.
+	require.Zero(t, meter.addReceived("cc", 0x30, 1<<20))
+	meter.addSent("cc", 0x30, 10)
+
+	require.Equal(t, []PeerBandwidth{
+		{
+			NodeID:    peerA,
+			Connected: now.Add(-time.Second).UTC(),
+			Channels: []ChannelBandwidth{
+				{ChannelID: 0x20, BytesSent: 20, BytesReceived: 1 << 20},
+				{ChannelID: 0x30, BytesSent: 10, BytesReceived: 2000, RecvRateLimit: 1000, Throttled: 500 * time.Millisecond},
+			},
+		},
+		{
+			NodeID:    peerB,
+			Connected: now.Add(-time.Second).UTC(),
+			Channels: []ChannelBandwidth{
+				{ChannelID: 0x30, BytesReceived: 1000, RecvRateLimit: 1000},
+			},
+		},
+	}, meter.list())
+
+	meter.disconnected(peerA)
+	require.Len(t, meter.list(), 1)
+}
@@ -0,0 +1,207 @@
+package p2p
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// captureBufferSize is the number of messages buffered for a capture, beyond
+// which messages are dropped rather than slowing down the peer.
+const captureBufferSize = 1024
+
+// CapturedMessage is a message sent to or received from a peer, as encoded on
+// the wire, recorded by Router.CaptureMessages.
+type CapturedMessage struct {
+	Time      time.Time
+	Peer      types.NodeID
+	ChannelID ChannelID
+	Outbound  bool
+	Message   proto.Message
+	Size      int // of the encoded message
+	Dropped   int // number of messages dropped before this one
+}
+
+// Redaction is the redaction of the captured messages written by
+// WriteCapture.
+type Redaction string
+
+const (
+	// RedactNone writes the messages as they are.
+	RedactNone Redaction = ""
+	// RedactBytes replaces the bytes fields of the messages, e.g. the
+	// transactions and the signatures, by their SHA-256 hash, so that they
+	// can be compared with the ones of another capture.
+	RedactBytes Redaction = "bytes"
+	// RedactAll writes only the type and the size of the messages.
+	RedactAll Redaction = "all"
+)
+
+// ParseRedaction parses a redaction; "none" is RedactNone.
+func ParseRedaction(s string) (Redaction, error) {
+	switch r := Redaction(s); r {
+	case RedactNone, RedactBytes, RedactAll:
+		return r, nil
+	case "none":
+		return RedactNone, nil
+	default:
+		return "", fmt.Errorf("unknown redaction %q, expected none, bytes or all", s)
+	}
+}
+
+// captureRecord is the JSON encoding of a captured message.
+type captureRecord struct {
+	Time      time.Time       `json:"time"`
+	Peer      types.NodeID    `json:"peer"`
+	Channel   ChannelID       `json:"channel"`
+	Direction string          `json:"direction"`
+	Type      string          `json:"type"`
+	Size      int             `json:"size"`
+	Dropped   int             `json:"dropped,omitempty"`
+	Message   json.RawMessage `json:"message,omitempty"`
+}
+
+// WriteCapture writes the messages received from msgs to w until msgs is
+// closed, as JSON objects separated by newlines, with the given redaction. It
+// returns the number of messages written.
+func WriteCapture(w io.Writer, msgs <-chan CapturedMessage, redaction Redaction) (int, error) {
+	enc := json.NewEncoder(w)
+	n := 0
+	for msg := range msgs {
+		record := captureRecord{
+			Time:      msg.Time,
+			Peer:      msg.Peer,
+			Channel:   msg.ChannelID,
+			Direction: "in",
+			Type:      proto.MessageName(msg.Message),
+			Size:      msg.Size,
+			Dropped:   msg.Dropped,
+		}
+		if msg.Outbound {
+			record.Direction = "out"
+		}
+		if redaction != RedactAll {
+			if redaction == RedactBytes {
+				redactBytes(reflect.ValueOf(msg.Message))
+			}
+			bz, err := json.Marshal(msg.Message)
+			if err != nil {
+				return n, fmt.Errorf("failed to encode %s: %w", record.Type, err)
+			}
+			record.Message = bz
+		}
+		if err := enc.Encode(record); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// redactBytes replaces the non-empty byte slices reachable from v by their
+// SHA-256 hash.
+func redactBytes(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			redactBytes(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				redactBytes(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			for i := 0; i < v.Len(); i++ {
+				redactBytes(v.Index(i))
+			}
+		} else if v.Len() > 0 && v.CanSet() {
+			sum := sha256.Sum256(v.Bytes())
+			v.SetBytes(sum[:])
+		}
+	}
+}
+
+// messageCapture is a capture of the messages exchanged with a peer on a
+// channel.
+type messageCapture struct {
+	peerID  types.NodeID
+	chID    ChannelID
+	ch      chan CapturedMessage
+	dropped int64 // atomic, since the last message captured
+}
+
+// messageCaptures are the captures of a router.
+type messageCaptures struct {
+	mtx      sync.RWMutex
+	captures map[*messageCapture]struct{}
+}
+
+// CaptureMessages returns the messages sent to and received from the peer on
+// the channel chID, decoded but not unwrapped, until ctx is done, when the
+// returned channel is closed. The messages are dropped rather than slowing
+// down the peer when they are not received fast enough.
+func (r *Router) CaptureMessages(ctx context.Context, peerID types.NodeID, chID ChannelID) <-chan CapturedMessage {
+	c := &messageCapture{
+		peerID: peerID,
+		chID:   chID,
+		ch:     make(chan CapturedMessage, captureBufferSize),
+	}
+
+	r.captures.mtx.Lock()
+	if r.captures.captures == nil {
+		r.captures.captures = make(map[*messageCapture]struct{})
+	}
+	r.captures.captures[c] = struct{}{}
+	r.captures.mtx.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		r.captures.mtx.Lock()
+		defer r.captures.mtx.Unlock()
+		delete(r.captures.captures, c)
+		close(c.ch)
+	}()
+	return c.ch
+}
+
+// capture passes a message exchanged with a peer to the captures of its peer
+// and channel.
+func (r *Router) capture(peerID types.NodeID, chID ChannelID, outbound bool, msg proto.Message, size int) {
+	r.captures.mtx.RLock()
+	defer r.captures.mtx.RUnlock()
+
+	for c := range r.captures.captures {
+		if c.peerID != peerID || c.chID != chID {
+			continue
+		}
+		// The message is copied, since it may be modified by the reactors,
+		// or redacted, while it is written.
+		captured := CapturedMessage{
+			Time:      time.Now().UTC(),
+			Peer:      peerID,
+			ChannelID: chID,
+			Outbound:  outbound,
+			Message:   proto.Clone(msg),
+			Size:      size,
+			Dropped:   int(atomic.SwapInt64(&c.dropped, 0)),
+		}
+		select {
+		case c.ch <- captured:
+		default:
+			atomic.AddInt64(&c.dropped, int64(captured.Dropped)+1)
+		}
+	}
+}
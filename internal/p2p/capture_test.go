@@ -0,0 +1,99 @@
+package p2p_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"testing"
+
+	"github.com/fortytw2/leaktest"
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/p2ptest"
+)
+
+func TestRouter_CaptureMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Cleanup(leaktest.Check(t))
+
+	network := p2ptest.MakeNetwork(ctx, t, p2ptest.NetworkOptions{NumNodes: 2})
+	local := network.RandomNode()
+	peer := network.Peers(local.NodeID)[0]
+	channels := network.MakeChannels(ctx, t, chDesc)
+	network.Start(ctx, t)
+	go echoReactor(ctx, channels[peer.NodeID])
+
+	cctx, ccancel := context.WithCancel(ctx)
+	msgs := local.Router.CaptureMessages(cctx, peer.NodeID, chID)
+	other := local.Router.CaptureMessages(cctx, peer.NodeID, chID+1)
+
+	p2ptest.RequireSendReceive(ctx, t, channels[local.NodeID], peer.NodeID,
+		&p2ptest.Message{Value: "foo"},
+		&p2ptest.Message{Value: "foo"},
+	)
+	ccancel()
+
+	var captured []p2p.CapturedMessage
+	for msg := range msgs {
+		captured = append(captured, msg)
+	}
+	require.Len(t, captured, 2)
+	for i, outbound := range []bool{true, false} {
+		assert.Equal(t, peer.NodeID, captured[i].Peer)
+		assert.Equal(t, chID, captured[i].ChannelID)
+		assert.Equal(t, outbound, captured[i].Outbound)
+		assert.Equal(t, &p2ptest.Message{Value: "foo"}, captured[i].Message)
+		assert.Positive(t, captured[i].Size)
+	}
+	_, ok := <-other
+	assert.False(t, ok)
+}
+
+func TestWriteCapture(t *testing.T) {
+	secret := []byte("secret")
+	capture := func(redaction p2p.Redaction) map[string]interface{} {
+		msgs := make(chan p2p.CapturedMessage, 1)
+		msgs <- p2p.CapturedMessage{
+			ChannelID: chID,
+			Outbound:  true,
+			Message:   &gogotypes.BytesValue{Value: append([]byte(nil), secret...)},
+			Size:      8,
+		}
+		close(msgs)
+
+		var buf bytes.Buffer
+		n, err := p2p.WriteCapture(&buf, msgs, redaction)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+		var record map[string]interface{}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+		assert.Equal(t, "out", record["direction"])
+		assert.Equal(t, "google.protobuf.BytesValue", record["type"])
+		assert.EqualValues(t, 8, record["size"])
+		return record
+	}
+
+	record := capture(p2p.RedactNone)
+	assert.Equal(t, map[string]interface{}{"value": "c2VjcmV0"}, record["message"])
+
+	sum := sha256.Sum256(secret)
+	bz, err := json.Marshal(sum[:])
+	require.NoError(t, err)
+	record = capture(p2p.RedactBytes)
+	assert.Equal(t, map[string]interface{}{"value": string(bz[1 : len(bz)-1])}, record["message"])
+
+	record = capture(p2p.RedactAll)
+	assert.NotContains(t, record, "message")
+
+	_, err = p2p.ParseRedaction("some")
+	assert.Error(t, err)
+	redaction, err := p2p.ParseRedaction("none")
+	require.NoError(t, err)
+	assert.Equal(t, p2p.RedactNone, redaction)
+}
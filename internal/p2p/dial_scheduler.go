@@ -0,0 +1,126 @@
+package p2p
+
+import (
+	"net"
+	"strings"
+	"time"
+)
+
+// Prefix lengths of the subnets the dials are limited by.
+const (
+	dialSubnetBitsIPv4 = 24
+	dialSubnetBitsIPv6 = 48
+)
+
+// defaultMaxConcurrentDials is the maximum number of dials in progress if
+// PeerManagerOptions.MaxConcurrentDials is 0.
+const defaultMaxConcurrentDials = 8
+
+// dialScheduler schedules the outbound dials of the peer manager. It limits
+// the dials in progress, globally and per subnet, spaces the starts of the
+// dials by a minimum interval, and keeps the time each failing address can
+// be retried at. It is not safe for concurrent use: the peer manager calls
+// it with its mutex held.
+type dialScheduler struct {
+	maxDials       int
+	maxSubnetDials int
+	minInterval    time.Duration
+
+	dials       map[NodeAddress]string // subnets of the dials in progress
+	subnetDials map[string]int
+	lastDial    time.Time
+	retryAt     map[NodeAddress]time.Time
+
+	wakeupPending bool // whether a wake up is scheduled for minInterval
+}
+
+func newDialScheduler(options PeerManagerOptions) *dialScheduler {
+	maxDials := int(options.MaxConcurrentDials)
+	if maxDials == 0 {
+		maxDials = defaultMaxConcurrentDials
+	}
+	return &dialScheduler{
+		maxDials:       maxDials,
+		maxSubnetDials: int(options.MaxConcurrentDialsPerSubnet),
+		minInterval:    options.MinDialInterval,
+		dials:          make(map[NodeAddress]string),
+		subnetDials:    make(map[string]int),
+		retryAt:        make(map[NodeAddress]time.Time),
+	}
+}
+
+// dialSubnet returns the subnet of the address the dials are limited by: the
+// /24 of an IPv4 address, the /48 of an IPv6 address, or the hostname, which
+// is not resolved. The addresses without a hostname aren't limited.
+func dialSubnet(address NodeAddress) string {
+	ip := net.ParseIP(address.Hostname)
+	switch {
+	case ip == nil:
+		return strings.ToLower(address.Hostname)
+	case ip.To4() != nil:
+		return ip.Mask(net.CIDRMask(dialSubnetBitsIPv4, 8*net.IPv4len)).String()
+	default:
+		return ip.Mask(net.CIDRMask(dialSubnetBitsIPv6, 8*net.IPv6len)).String()
+	}
+}
+
+// wait returns how long to wait before starting a dial, or 0 if a dial can
+// start now. It returns a negative duration if the dials in progress are at
+// the limit, until one of them finishes.
+func (s *dialScheduler) wait(now time.Time) time.Duration {
+	if len(s.dials) >= s.maxDials {
+		return -1
+	}
+	if s.minInterval > 0 && !s.lastDial.IsZero() {
+		if wait := s.lastDial.Add(s.minInterval).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// subnetFull returns whether the dials in progress to the subnet of the
+// address are at the limit.
+func (s *dialScheduler) subnetFull(address NodeAddress) bool {
+	subnet := dialSubnet(address)
+	return s.maxSubnetDials > 0 && subnet != "" && s.subnetDials[subnet] >= s.maxSubnetDials
+}
+
+// start records the start of a dial to the address.
+func (s *dialScheduler) start(address NodeAddress, now time.Time) {
+	subnet := dialSubnet(address)
+	s.dials[address] = subnet
+	s.subnetDials[subnet]++
+	s.lastDial = now
+}
+
+// finish records the end of a dial to the address.
+func (s *dialScheduler) finish(address NodeAddress) {
+	subnet, ok := s.dials[address]
+	if !ok {
+		return
+	}
+	delete(s.dials, address)
+	if s.subnetDials[subnet]--; s.subnetDials[subnet] <= 0 {
+		delete(s.subnetDials, subnet)
+	}
+}
+
+// setRetry sets the time the address can be dialed again at, after a failed
+// dial. A zero time clears it.
+func (s *dialScheduler) setRetry(address NodeAddress, at time.Time) {
+	if at.IsZero() {
+		delete(s.retryAt, address)
+	} else {
+		s.retryAt[address] = at
+	}
+}
+
+// forget drops the retry times of the addresses not kept.
+func (s *dialScheduler) forget(keep func(NodeAddress) bool) {
+	for address := range s.retryAt {
+		if !keep(address) {
+			delete(s.retryAt, address)
+		}
+	}
+}
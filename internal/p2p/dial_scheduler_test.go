@@ -0,0 +1,117 @@
+package p2p_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPeerManager_DialNext_Limits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	address := func(id byte, hostname string) p2p.NodeAddress {
+		return p2p.NodeAddress{
+			Protocol: "tcp",
+			NodeID:   types.NodeID(strings.Repeat(string(id), 40)),
+			Hostname: hostname,
+			Port:     26656,
+		}
+	}
+	a := address('a', "192.0.2.1")
+	b := address('b', "192.0.2.2") // same /24 as a
+	c := address('c', "198.51.100.1")
+	d := address('d', "2001:db8:1:2::1")
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		MaxConcurrentDials:          2,
+		MaxConcurrentDialsPerSubnet: 1,
+		PeerScores:                  map[types.NodeID]p2p.PeerScore{a.NodeID: 4, b.NodeID: 3, c.NodeID: 2, d.NodeID: 1},
+	})
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{a, b, c, d} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	// b is in the subnet being dialed, and then the dials are at the limit.
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, c, dial)
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+
+	// A failed dial frees its slot, and of its subnet.
+	require.NoError(t, peerManager.DialFailed(ctx, a))
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, b, dial)
+
+	require.NoError(t, peerManager.Dialed(c))
+	dial, err = peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, d, dial)
+}
+
+func TestPeerManager_DialNext_DefaultLimit(t *testing.T) {
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		address := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat(fmt.Sprintf("%x", i), 40))}
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	// The dials in progress are limited by default.
+	for i := 0; i < 8; i++ {
+		dial, err := peerManager.TryDialNext()
+		require.NoError(t, err)
+		require.NotZero(t, dial)
+	}
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+}
+
+func TestPeerManager_DialNext_Interval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("b", 40))}
+
+	options := p2p.PeerManagerOptions{MinDialInterval: 200 * time.Millisecond}
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), options)
+	require.NoError(t, err)
+	for _, address := range []p2p.NodeAddress{a, b} {
+		added, err := peerManager.Add(address)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	start := time.Now()
+	_, err = peerManager.DialNext(ctx)
+	require.NoError(t, err)
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Zero(t, dial)
+
+	// DialNext is woken up once the interval has elapsed.
+	_, err = peerManager.DialNext(ctx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), options.MinDialInterval)
+}
@@ -0,0 +1,120 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// maxMaintenanceWindows is the maximum number of maintenance windows kept for
+// a single peer.
+const maxMaintenanceWindows = 16
+
+// MaintenanceWindow is a period during which a node plans to be unavailable,
+// e.g. to restart for an upgrade.
+type MaintenanceWindow struct {
+	Start  time.Time
+	End    time.Time
+	Reason string
+}
+
+// PeerMaintenance tracks the maintenance windows announced by peers, so that
+// reactors can avoid relying on peers that are about to go away. It is safe
+// for concurrent use, and a nil *PeerMaintenance never avoids any peer.
+type PeerMaintenance struct {
+	margin time.Duration
+	now    func() time.Time
+
+	mtx     sync.RWMutex
+	windows map[types.NodeID][]MaintenanceWindow
+}
+
+// NewPeerMaintenance creates a peer maintenance tracker avoiding peers whose
+// maintenance window is in progress or starts within margin.
+func NewPeerMaintenance(margin time.Duration) *PeerMaintenance {
+	return &PeerMaintenance{
+		margin:  margin,
+		now:     time.Now,
+		windows: make(map[types.NodeID][]MaintenanceWindow),
+	}
+}
+
+// Set replaces the maintenance windows announced by the peer. Invalid and
+// past windows are dropped.
+func (m *PeerMaintenance) Set(id types.NodeID, windows []MaintenanceWindow) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := m.now()
+	upcoming := make([]MaintenanceWindow, 0, len(windows))
+	for _, w := range windows {
+		if w.End.After(w.Start) && w.End.After(now) {
+			upcoming = append(upcoming, w)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].Start.Before(upcoming[j].Start) })
+	if len(upcoming) > maxMaintenanceWindows {
+		upcoming = upcoming[:maxMaintenanceWindows]
+	}
+
+	if len(upcoming) == 0 {
+		delete(m.windows, id)
+	} else {
+		m.windows[id] = upcoming
+	}
+	m.prune(now)
+}
+
+// Upcoming returns the maintenance windows of the peer that have not ended
+// yet, ordered by start time.
+func (m *PeerMaintenance) Upcoming(id types.NodeID) []MaintenanceWindow {
+	if m == nil {
+		return nil
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	now := m.now()
+	var out []MaintenanceWindow
+	for _, w := range m.windows[id] {
+		if w.End.After(now) {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// Avoid returns true if the peer has a maintenance window in progress or
+// starting within the margin.
+func (m *PeerMaintenance) Avoid(id types.NodeID) bool {
+	if m == nil {
+		return false
+	}
+
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	now := m.now()
+	for _, w := range m.windows[id] {
+		if w.End.After(now) && !w.Start.After(now.Add(m.margin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune drops the peers whose windows have all ended.
+func (m *PeerMaintenance) prune(now time.Time) {
+PEERS:
+	for id, windows := range m.windows {
+		for _, w := range windows {
+			if w.End.After(now) {
+				continue PEERS
+			}
+		}
+		delete(m.windows, id)
+	}
+}
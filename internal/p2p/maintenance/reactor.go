@@ -0,0 +1,221 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	protop2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+var _ service.Service = (*Reactor)(nil)
+
+const (
+	// MaintenanceChannel is a channel for maintenance window announcements.
+	MaintenanceChannel = p2p.ChannelID(0x01)
+
+	// maxWindows is the maximum number of windows in an announcement.
+	maxWindows = 16
+
+	// maxMsgSize bounds announcements of maxWindows windows with short
+	// reasons.
+	maxMsgSize = 8192
+)
+
+// ChannelDescriptor returns the descriptor of the maintenance channel.
+func ChannelDescriptor() *p2p.ChannelDescriptor {
+	return &p2p.ChannelDescriptor{
+		ID:                  MaintenanceChannel,
+		MessageType:         new(protop2p.MaintenanceAnnouncement),
+		Priority:            1,
+		SendQueueCapacity:   10,
+		RecvMessageCapacity: maxMsgSize,
+		RecvBufferCapacity:  32,
+	}
+}
+
+// WindowsFromConfig returns the maintenance windows configured for this node.
+func WindowsFromConfig(cfg *config.P2PConfig) ([]p2p.MaintenanceWindow, error) {
+	windows := make([]p2p.MaintenanceWindow, 0, len(cfg.MaintenanceWindows))
+	for _, w := range cfg.MaintenanceWindows {
+		start, end, err := config.ParseMaintenanceWindow(w)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, p2p.MaintenanceWindow{Start: start, End: end})
+	}
+	return windows, nil
+}
+
+// Reactor announces the planned maintenance windows of this node to its peers
+// and records the windows announced by them. Announcing is optional: peers
+// without the channel simply never hear of, or send, any window.
+type Reactor struct {
+	service.BaseService
+	logger log.Logger
+
+	maintenance *p2p.PeerMaintenance
+	channel     *p2p.Channel
+	peerUpdates *p2p.PeerUpdates
+
+	mtx     sync.Mutex
+	windows []p2p.MaintenanceWindow
+}
+
+// NewReactor returns a reactor announcing the given maintenance windows and
+// recording the windows of peers in maintenance.
+func NewReactor(
+	ctx context.Context,
+	logger log.Logger,
+	maintenance *p2p.PeerMaintenance,
+	channelCreator p2p.ChannelCreator,
+	peerUpdates *p2p.PeerUpdates,
+	windows []p2p.MaintenanceWindow,
+) (*Reactor, error) {
+	if len(windows) > maxWindows {
+		return nil, fmt.Errorf("at most %d maintenance windows can be announced", maxWindows)
+	}
+
+	channel, err := channelCreator(ctx, ChannelDescriptor())
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reactor{
+		logger:      logger,
+		maintenance: maintenance,
+		channel:     channel,
+		peerUpdates: peerUpdates,
+		windows:     windows,
+	}
+
+	r.BaseService = *service.NewBaseService(logger, "Maintenance", r)
+	return r, nil
+}
+
+// OnStart starts the goroutines receiving announcements and peer updates.
+func (r *Reactor) OnStart(ctx context.Context) error {
+	go r.processCh(ctx)
+	go r.processPeerUpdates(ctx)
+	return nil
+}
+
+// OnStop stops the reactor.
+func (r *Reactor) OnStop() {}
+
+// Announce replaces the maintenance windows of this node and announces them
+// to all connected peers. Announcing no windows cancels the previous ones.
+func (r *Reactor) Announce(ctx context.Context, windows []p2p.MaintenanceWindow) error {
+	if len(windows) > maxWindows {
+		return fmt.Errorf("at most %d maintenance windows can be announced", maxWindows)
+	}
+
+	r.mtx.Lock()
+	r.windows = windows
+	r.mtx.Unlock()
+
+	return r.channel.Send(ctx, p2p.Envelope{
+		Broadcast: true,
+		Message:   announcement(windows),
+	})
+}
+
+func announcement(windows []p2p.MaintenanceWindow) *protop2p.MaintenanceAnnouncement {
+	msg := &protop2p.MaintenanceAnnouncement{
+		Windows: make([]protop2p.MaintenanceWindow, len(windows)),
+	}
+	for i, w := range windows {
+		msg.Windows[i] = protop2p.MaintenanceWindow{Start: w.Start, End: w.End, Reason: w.Reason}
+	}
+	return msg
+}
+
+// processCh handles announcements received from peers.
+func (r *Reactor) processCh(ctx context.Context) {
+	iter := r.channel.Receive(ctx)
+	for iter.Next(ctx) {
+		envelope := iter.Envelope()
+		if err := r.handleMessage(envelope); err != nil {
+			r.logger.Error("failed to process message", "ch_id", r.channel.ID, "envelope", envelope, "err", err)
+			if serr := r.channel.SendError(ctx, p2p.PeerError{
+				NodeID: envelope.From,
+				Err:    err,
+			}); serr != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleMessage handles an envelope received from a peer, recovering from
+// any panic.
+func (r *Reactor) handleMessage(envelope *p2p.Envelope) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic in processing message: %v", e)
+			r.logger.Error(
+				"recovering from processing message panic",
+				"err", err,
+				"stack", string(debug.Stack()),
+			)
+		}
+	}()
+
+	switch msg := envelope.Message.(type) {
+	case *protop2p.MaintenanceAnnouncement:
+		if len(msg.Windows) > maxWindows {
+			return fmt.Errorf("announced %d maintenance windows, at most %d are allowed", len(msg.Windows), maxWindows)
+		}
+		windows := make([]p2p.MaintenanceWindow, len(msg.Windows))
+		for i, w := range msg.Windows {
+			windows[i] = p2p.MaintenanceWindow{Start: w.Start, End: w.End, Reason: w.Reason}
+		}
+		r.maintenance.Set(envelope.From, windows)
+		if len(windows) > 0 {
+			r.logger.Info("peer announced maintenance", "peer", envelope.From,
+				"start", windows[0].Start, "end", windows[0].End, "windows", len(windows))
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("received unknown message: %T", msg)
+	}
+}
+
+// processPeerUpdates sends the maintenance windows of this node to peers as
+// they connect.
+func (r *Reactor) processPeerUpdates(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case peerUpdate := <-r.peerUpdates.Updates():
+			if peerUpdate.Status != p2p.PeerStatusUp {
+				continue
+			}
+			if err := r.sendWindows(ctx, peerUpdate.NodeID); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (r *Reactor) sendWindows(ctx context.Context, peerID types.NodeID) error {
+	r.mtx.Lock()
+	windows := r.windows
+	r.mtx.Unlock()
+
+	if len(windows) == 0 {
+		return nil
+	}
+	return r.channel.Send(ctx, p2p.Envelope{
+		To:      peerID,
+		Message: announcement(windows),
+	})
+}
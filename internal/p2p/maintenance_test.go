@@ -0,0 +1,48 @@
+package p2p_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPeerMaintenance(t *testing.T) {
+	peerID := types.NodeID("aa112233445566778899aabbccddeeff00112233")
+	now := time.Now()
+
+	maintenance := p2p.NewPeerMaintenance(10 * time.Minute)
+	require.False(t, maintenance.Avoid(peerID))
+	require.Empty(t, maintenance.Upcoming(peerID))
+
+	later := p2p.MaintenanceWindow{Start: now.Add(time.Hour), End: now.Add(2 * time.Hour), Reason: "upgrade"}
+	soon := p2p.MaintenanceWindow{Start: now.Add(5 * time.Minute), End: now.Add(30 * time.Minute)}
+	past := p2p.MaintenanceWindow{Start: now.Add(-time.Hour), End: now.Add(-time.Minute)}
+	invalid := p2p.MaintenanceWindow{Start: now.Add(time.Hour), End: now.Add(time.Minute)}
+
+	// past and invalid windows are dropped, the rest are ordered by start
+	maintenance.Set(peerID, []p2p.MaintenanceWindow{later, past, invalid})
+	require.Equal(t, []p2p.MaintenanceWindow{later}, maintenance.Upcoming(peerID))
+	require.False(t, maintenance.Avoid(peerID))
+
+	maintenance.Set(peerID, []p2p.MaintenanceWindow{later, soon})
+	require.Equal(t, []p2p.MaintenanceWindow{soon, later}, maintenance.Upcoming(peerID))
+	require.True(t, maintenance.Avoid(peerID))
+
+	ongoing := p2p.MaintenanceWindow{Start: now.Add(-time.Minute), End: now.Add(time.Minute)}
+	maintenance.Set(peerID, []p2p.MaintenanceWindow{ongoing})
+	require.True(t, maintenance.Avoid(peerID))
+
+	// an empty announcement cancels the windows
+	maintenance.Set(peerID, nil)
+	require.False(t, maintenance.Avoid(peerID))
+	require.Empty(t, maintenance.Upcoming(peerID))
+
+	// a nil tracker never avoids a peer
+	var none *p2p.PeerMaintenance
+	require.False(t, none.Avoid(peerID))
+	require.Empty(t, none.Upcoming(peerID))
+}
@@ -35,6 +35,9 @@ type Metrics struct {
 	PeerSendBytesTotal metrics.Counter
 	// Pending bytes to be sent to a given peer.
 	PeerPendingSendBytes metrics.Gauge
+	// Time spent delaying the messages received from a given peer on a
+	// channel, to enforce the channel's receive rate limit.
+	PeerThrottledSeconds metrics.Counter
 
 	// RouterPeerQueueRecv defines the time taken to read off of a peer's queue
 	// before sending on the connection.
@@ -56,6 +59,21 @@ type Metrics struct {
 	// queue for a specific flow (i.e. Channel).
 	PeerQueueMsgSize metrics.Gauge
 
+	// PeerStorePeers is the number of peers in the peer store, by reachability
+	// bucket: "new", "tried" or "failing".
+	PeerStorePeers metrics.Gauge
+
+	// PeerStoreAddresses is the number of peer addresses in the peer store.
+	PeerStoreAddresses metrics.Gauge
+
+	// PeerStoreRatio is the ratio of peers in the peer store to its maximum
+	// size, or 0 if unbounded.
+	PeerStoreRatio metrics.Gauge
+
+	// PeerStoreGarbageCollected is the number of addresses and peers removed
+	// from the peer store by garbage collection, by kind: "address" or "peer".
+	PeerStoreGarbageCollected metrics.Counter
+
 	mtx               *sync.RWMutex
 	messageLabelNames map[reflect.Type]string
 }
@@ -97,6 +115,13 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Help:      "Number of pending bytes to be sent to a given peer.",
 		}, append(labels, "peer_id")).With(labelsAndValues...),
 
+		PeerThrottledSeconds: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_throttled_seconds",
+			Help:      "Time spent delaying the messages received from a given peer on a channel, to enforce its rate limit.",
+		}, append(labels, "peer_id", "chID")).With(labelsAndValues...),
+
 		RouterPeerQueueRecv: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
@@ -132,6 +157,34 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Help:      "The size of messages sent over a peer's queue for a specific p2p Channel.",
 		}, append(labels, "ch_id")).With(labelsAndValues...),
 
+		PeerStorePeers: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_store_peers",
+			Help:      "Number of peers in the peer store, by reachability bucket.",
+		}, append(labels, "bucket")).With(labelsAndValues...),
+
+		PeerStoreAddresses: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_store_addresses",
+			Help:      "Number of peer addresses in the peer store.",
+		}, labels).With(labelsAndValues...),
+
+		PeerStoreRatio: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_store_ratio",
+			Help:      "Ratio of peers in the peer store to its maximum size.",
+		}, labels).With(labelsAndValues...),
+
+		PeerStoreGarbageCollected: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "peer_store_garbage_collected",
+			Help:      "Number of addresses and peers removed from the peer store by garbage collection.",
+		}, append(labels, "kind")).With(labelsAndValues...),
+
 		mtx:               &sync.RWMutex{},
 		messageLabelNames: map[reflect.Type]string{},
 	}
@@ -140,17 +193,22 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 // NopMetrics returns no-op Metrics.
 func NopMetrics() *Metrics {
 	return &Metrics{
-		Peers:                  discard.NewGauge(),
-		PeerReceiveBytesTotal:  discard.NewCounter(),
-		PeerSendBytesTotal:     discard.NewCounter(),
-		PeerPendingSendBytes:   discard.NewGauge(),
-		RouterPeerQueueRecv:    discard.NewHistogram(),
-		RouterPeerQueueSend:    discard.NewHistogram(),
-		RouterChannelQueueSend: discard.NewHistogram(),
-		PeerQueueDroppedMsgs:   discard.NewCounter(),
-		PeerQueueMsgSize:       discard.NewGauge(),
-		mtx:                    &sync.RWMutex{},
-		messageLabelNames:      map[reflect.Type]string{},
+		Peers:                     discard.NewGauge(),
+		PeerReceiveBytesTotal:     discard.NewCounter(),
+		PeerSendBytesTotal:        discard.NewCounter(),
+		PeerPendingSendBytes:      discard.NewGauge(),
+		PeerThrottledSeconds:      discard.NewCounter(),
+		RouterPeerQueueRecv:       discard.NewHistogram(),
+		RouterPeerQueueSend:       discard.NewHistogram(),
+		RouterChannelQueueSend:    discard.NewHistogram(),
+		PeerQueueDroppedMsgs:      discard.NewCounter(),
+		PeerQueueMsgSize:          discard.NewGauge(),
+		PeerStorePeers:            discard.NewGauge(),
+		PeerStoreAddresses:        discard.NewGauge(),
+		PeerStoreRatio:            discard.NewGauge(),
+		PeerStoreGarbageCollected: discard.NewCounter(),
+		mtx:                       &sync.RWMutex{},
+		messageLabelNames:         map[reflect.Type]string{},
 	}
 }
 
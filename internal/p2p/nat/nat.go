@@ -0,0 +1,176 @@
+// Package nat maps the p2p port of a node on the NAT gateway of its network,
+// with UPnP or NAT-PMP, so that a node behind a NAT accepts inbound
+// connections without a manual configuration of the router.
+package nat
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+const (
+	// mappingLifetime is the lifetime requested for the port mappings, which
+	// are renewed halfway through.
+	mappingLifetime = time.Hour
+
+	// mappingDescription describes the port mappings on the gateway.
+	mappingDescription = "tendermint p2p"
+)
+
+// Gateway is a NAT gateway mapping its TCP ports to the ports of the hosts of
+// its network.
+type Gateway interface {
+	// ExternalIP returns the IP address of the gateway on the internet.
+	ExternalIP(ctx context.Context) (net.IP, error)
+
+	// AddPortMapping maps the external port of the gateway to the internal
+	// port of the host, for lifetime, and returns the mapped external port.
+	// The gateway may map another external port, or a permanent mapping.
+	AddPortMapping(ctx context.Context, internal, external int, lifetime time.Duration) (int, error)
+
+	// DeletePortMapping deletes the mapping of the external port to the
+	// internal port.
+	DeletePortMapping(ctx context.Context, internal, external int) error
+
+	String() string
+}
+
+// Discover returns the gateway of the network, looking for a UPnP Internet
+// Gateway Device first, and then for a NAT-PMP gateway at the default route.
+func Discover(ctx context.Context) (Gateway, error) {
+	upnpGW, upnpErr := DiscoverUPnP(ctx)
+	if upnpErr == nil {
+		return upnpGW, nil
+	}
+	pmpGW, pmpErr := DiscoverNATPMP(ctx)
+	if pmpErr == nil {
+		return pmpGW, nil
+	}
+	return nil, fmt.Errorf("no NAT gateway found (UPnP: %v, NAT-PMP: %v)", upnpErr, pmpErr)
+}
+
+// Mapping is a port mapping on a gateway, renewed until it is closed.
+type Mapping struct {
+	logger   log.Logger
+	gateway  Gateway
+	internal int
+
+	mtx      sync.Mutex
+	ip       net.IP
+	external int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Map maps a port of the gateway to the internal port of the node, the same
+// port if possible, and renews the mapping until the mapping is closed or ctx
+// is canceled.
+func Map(ctx context.Context, logger log.Logger, gateway Gateway, internal int) (*Mapping, error) {
+	ip, err := gateway.ExternalIP(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting the external IP of %v: %w", gateway, err)
+	}
+	external, err := addPortMapping(ctx, gateway, internal, internal)
+	if err != nil {
+		return nil, fmt.Errorf("mapping port %d on %v: %w", internal, gateway, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m := &Mapping{
+		logger:   logger,
+		gateway:  gateway,
+		internal: internal,
+		ip:       ip,
+		external: external,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	logger.Info("Mapped the p2p port on the NAT gateway", "gateway", gateway, "address", m.ExternalAddress())
+	go m.renewRoutine(ctx)
+	return m, nil
+}
+
+// addPortMapping maps the external port to the internal port, or a random
+// external port if the gateway has mapped it to another host already.
+func addPortMapping(ctx context.Context, gateway Gateway, internal, external int) (int, error) {
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		var mapped int
+		mapped, err = gateway.AddPortMapping(ctx, internal, external, mappingLifetime)
+		if err == nil {
+			return mapped, nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		external = 1024 + rand.Intn(65535-1024) // nolint:gosec // G404: Use of weak random number generator
+	}
+	return 0, err
+}
+
+// ExternalAddress returns the external address (IP:port) of the mapping.
+func (m *Mapping) ExternalAddress() string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return net.JoinHostPort(m.ip.String(), strconv.Itoa(m.external))
+}
+
+// renewRoutine renews the mapping halfway through its lifetime, and deletes
+// it when ctx is done.
+func (m *Mapping) renewRoutine(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(mappingLifetime / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.mtx.Lock()
+			external := m.external
+			m.mtx.Unlock()
+			dctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := m.gateway.DeletePortMapping(dctx, m.internal, external); err != nil {
+				m.logger.Info("Failed to delete the port mapping", "gateway", m.gateway, "err", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+		}
+
+		m.mtx.Lock()
+		external := m.external
+		m.mtx.Unlock()
+		ip, err := m.gateway.ExternalIP(ctx)
+		if err == nil {
+			external, err = m.gateway.AddPortMapping(ctx, m.internal, external, mappingLifetime)
+		}
+		if err != nil {
+			m.logger.Error("Failed to renew the port mapping", "gateway", m.gateway, "err", err)
+			continue
+		}
+		m.mtx.Lock()
+		changed := !ip.Equal(m.ip) || external != m.external
+		m.ip, m.external = ip, external
+		m.mtx.Unlock()
+		if changed {
+			// Peers learn the new address only after a restart, since the
+			// node info is fixed.
+			m.logger.Error("The external address of the node changed", "address", m.ExternalAddress())
+		}
+	}
+}
+
+// Close deletes the mapping.
+func (m *Mapping) Close() error {
+	m.cancel()
+	<-m.done
+	return nil
+}
@@ -0,0 +1,217 @@
+package nat
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+const testDescription = `<?xml version="1.0"?>
+<root xmlns="urn:schemas-upnp-org:device-1-0">
+  <device>
+    <deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType>
+    <deviceList><device>
+      <deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType>
+      <deviceList><device>
+        <deviceType>urn:schemas-upnp-org:device:WANConnectionDevice:1</deviceType>
+        <serviceList><service>
+          <serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+          <controlURL>/ctl/IPConn</controlURL>
+        </service></serviceList>
+      </device></deviceList>
+    </device></deviceList>
+  </device>
+</root>`
+
+func TestUPnPGateway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mtx sync.Mutex
+	var actions []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/desc.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testDescription)
+	})
+	mux.HandleFunc("/ctl/IPConn", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		action := r.Header.Get("SOAPAction")
+		mtx.Lock()
+		actions = append(actions, action)
+		mtx.Unlock()
+		switch {
+		case strings.HasSuffix(action, `#GetExternalIPAddress"`):
+			fmt.Fprint(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+				`<u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">`+
+				`<NewExternalIPAddress>203.0.113.7</NewExternalIPAddress>`+
+				`</u:GetExternalIPAddressResponse></s:Body></s:Envelope>`)
+		case strings.HasSuffix(action, `#AddPortMapping"`) && !strings.Contains(string(body), "<NewLeaseDuration>0<"):
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+				`<s:Fault><faultcode>s:Client</faultcode><faultstring>UPnPError</faultstring><detail>`+
+				`<UPnPError xmlns="urn:schemas-upnp-org:control-1-0"><errorCode>725</errorCode>`+
+				`<errorDescription>OnlyPermanentLeasesSupported</errorDescription></UPnPError>`+
+				`</detail></s:Fault></s:Body></s:Envelope>`)
+		default:
+			fmt.Fprint(w, `<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>`+
+				`<u:Response xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1"></u:Response></s:Body></s:Envelope>`)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	gw, err := newUPnPGateway(ctx, srv.URL+"/desc.xml")
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/ctl/IPConn", gw.controlURL)
+
+	ip, err := gw.ExternalIP(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip.String())
+
+	// The gateway only supports permanent leases.
+	port, err := gw.AddPortMapping(ctx, 26656, 26656, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 26656, port)
+	require.NoError(t, gw.DeletePortMapping(ctx, 26656, 26656))
+
+	prefix := `"urn:schemas-upnp-org:service:WANIPConnection:1#`
+	assert.Equal(t, []string{
+		prefix + `GetExternalIPAddress"`,
+		prefix + `AddPortMapping"`,
+		prefix + `AddPortMapping"`,
+		prefix + `DeletePortMapping"`,
+	}, actions)
+}
+
+func TestParseSSDPResponse(t *testing.T) {
+	location, ok := parseSSDPResponse([]byte("HTTP/1.1 200 OK\r\n" +
+		"CACHE-CONTROL: max-age=120\r\n" +
+		"ST: urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\n" +
+		"LOCATION: http://192.168.1.1:5000/rootDesc.xml\r\n\r\n"))
+	assert.True(t, ok)
+	assert.Equal(t, "http://192.168.1.1:5000/rootDesc.xml", location)
+
+	_, ok = parseSSDPResponse([]byte("HTTP/1.1 200 OK\r\n" +
+		"ST: urn:schemas-upnp-org:device:MediaServer:1\r\n" +
+		"LOCATION: http://192.168.1.2/desc.xml\r\n\r\n"))
+	assert.False(t, ok)
+}
+
+func TestNATPMPGateway(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			rsp := make([]byte, 16)
+			rsp[1] = 128 + buf[1]
+			switch {
+			case n == 2 && buf[1] == natPMPOpExternalAddress:
+				copy(rsp[8:12], []byte{203, 0, 113, 7})
+				rsp = rsp[:12]
+			case n == 12 && buf[1] == natPMPOpMapTCP:
+				copy(rsp[8:10], buf[4:6])
+				// The gateway maps another external port.
+				binary.BigEndian.PutUint16(rsp[10:12], binary.BigEndian.Uint16(buf[6:8])+1)
+				copy(rsp[12:16], buf[8:12])
+			default:
+				binary.BigEndian.PutUint16(rsp[2:4], 5) // unsupported opcode
+			}
+			_, _ = conn.WriteTo(rsp, addr)
+		}
+	}()
+
+	gw := &natPMPGateway{addr: conn.LocalAddr().String()}
+	ip, err := gw.ExternalIP(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", ip.String())
+
+	port, err := gw.AddPortMapping(ctx, 26656, 26656, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 26657, port)
+	require.NoError(t, gw.DeletePortMapping(ctx, 26656, port))
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes := "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\n" +
+		"eth0\t0001A8C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\n" +
+		"eth0\t00000000\t0101A8C0\t0003\t0\t0\t0\t00000000\n"
+	ip, err := parseRoutes(bufio.NewScanner(strings.NewReader(routes)))
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.1", ip.String())
+
+	_, err = parseRoutes(bufio.NewScanner(strings.NewReader(routes[:strings.LastIndex(routes[:len(routes)-1], "\n")+1])))
+	assert.Error(t, err)
+}
+
+type testGateway struct {
+	mtx      sync.Mutex
+	mappings map[int]int // internal ports by external port
+	taken    map[int]bool
+}
+
+func (g *testGateway) String() string { return "test gateway" }
+
+func (g *testGateway) ExternalIP(context.Context) (net.IP, error) {
+	return net.IPv4(203, 0, 113, 7), nil
+}
+
+func (g *testGateway) AddPortMapping(_ context.Context, internal, external int, _ time.Duration) (int, error) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if g.taken[external] {
+		return 0, fmt.Errorf("port %d taken", external)
+	}
+	g.mappings[external] = internal
+	return external, nil
+}
+
+func (g *testGateway) DeletePortMapping(_ context.Context, _, external int) error {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	delete(g.mappings, external)
+	return nil
+}
+
+func TestMap(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gw := &testGateway{mappings: map[int]int{}, taken: map[int]bool{26656: true}}
+	m, err := Map(ctx, log.NewNopLogger(), gw, 26656)
+	require.NoError(t, err)
+
+	// The port is mapped to another host, so a random port was mapped.
+	gw.mtx.Lock()
+	require.Len(t, gw.mappings, 1)
+	for external, internal := range gw.mappings {
+		assert.Equal(t, 26656, internal)
+		assert.Equal(t, fmt.Sprintf("203.0.113.7:%d", external), m.ExternalAddress())
+	}
+	gw.mtx.Unlock()
+
+	require.NoError(t, m.Close())
+	assert.Empty(t, gw.mappings)
+}
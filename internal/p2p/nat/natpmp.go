@@ -0,0 +1,167 @@
+package nat
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	natPMPPort = 5351
+
+	natPMPOpExternalAddress = 0
+	natPMPOpMapTCP          = 2
+
+	// natPMPRetries is the number of retries of a request, the first after
+	// natPMPInitialTimeout and then doubling the timeout each time, as the
+	// RFC 6886 recommends (with fewer retries).
+	natPMPRetries        = 4
+	natPMPInitialTimeout = 250 * time.Millisecond
+)
+
+// natPMPGateway is a NAT-PMP gateway (RFC 6886).
+type natPMPGateway struct {
+	addr string // host:port
+}
+
+// DiscoverNATPMP returns the NAT-PMP gateway at the default route, if it
+// answers.
+func DiscoverNATPMP(ctx context.Context) (Gateway, error) {
+	ip, err := defaultGateway()
+	if err != nil {
+		return nil, err
+	}
+	gw := &natPMPGateway{addr: net.JoinHostPort(ip.String(), strconv.Itoa(natPMPPort))}
+	if _, err := gw.ExternalIP(ctx); err != nil {
+		return nil, err
+	}
+	return gw, nil
+}
+
+// defaultGateway returns the IPv4 gateway of the default route. It is only
+// implemented on Linux.
+func defaultGateway() (net.IP, error) {
+	f, err := os.Open("/proc/net/route")
+	if err != nil {
+		return nil, fmt.Errorf("can't find the default gateway: %w", err)
+	}
+	defer f.Close()
+	return parseRoutes(bufio.NewScanner(f))
+}
+
+// parseRoutes returns the gateway of the default route in the routing table
+// s, in the format of /proc/net/route.
+func parseRoutes(s *bufio.Scanner) (net.IP, error) {
+	s.Scan() // header
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		bz, err := hex.DecodeString(fields[2])
+		if err != nil || len(bz) != 4 {
+			continue
+		}
+		// The addresses are in host byte order, little endian on the
+		// architectures supported.
+		return net.IPv4(bz[3], bz[2], bz[1], bz[0]), nil
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("no default route")
+}
+
+func (g *natPMPGateway) String() string {
+	return "NAT-PMP gateway " + g.addr
+}
+
+// ExternalIP implements Gateway.
+func (g *natPMPGateway) ExternalIP(ctx context.Context) (net.IP, error) {
+	rsp, err := g.request(ctx, []byte{0, natPMPOpExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	return net.IPv4(rsp[8], rsp[9], rsp[10], rsp[11]), nil
+}
+
+// AddPortMapping implements Gateway.
+func (g *natPMPGateway) AddPortMapping(ctx context.Context, internal, external int, lifetime time.Duration) (int, error) {
+	rsp, err := g.mapPort(ctx, internal, external, lifetime)
+	if err != nil {
+		return 0, err
+	}
+	return int(binary.BigEndian.Uint16(rsp[10:12])), nil
+}
+
+// DeletePortMapping implements Gateway.
+func (g *natPMPGateway) DeletePortMapping(ctx context.Context, internal, external int) error {
+	// A mapping is deleted with a lifetime and an external port of 0.
+	_, err := g.mapPort(ctx, internal, 0, 0)
+	return err
+}
+
+func (g *natPMPGateway) mapPort(ctx context.Context, internal, external int, lifetime time.Duration) ([]byte, error) {
+	req := make([]byte, 12)
+	req[1] = natPMPOpMapTCP
+	binary.BigEndian.PutUint16(req[4:6], uint16(internal))
+	binary.BigEndian.PutUint16(req[6:8], uint16(external))
+	binary.BigEndian.PutUint32(req[8:12], uint32(lifetime/time.Second))
+	return g.request(ctx, req, 16)
+}
+
+// request sends the request req to the gateway, retrying until it gets a
+// response of size bytes, which it returns if its result code is a success.
+func (g *natPMPGateway) request(ctx context.Context, req []byte, size int) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp4", g.addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 16)
+	timeout := natPMPInitialTimeout
+	for attempt := 0; attempt <= natPMPRetries; attempt, timeout = attempt+1, timeout*2 {
+		if _, err := conn.Write(req); err != nil {
+			return nil, err
+		}
+		deadline := time.Now().Add(timeout)
+		if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+			deadline = d
+		}
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return nil, err
+		}
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			// Ignore the responses to other requests.
+			if n < size || buf[0] != 0 || buf[1] != 128+req[1] {
+				continue
+			}
+			if code := binary.BigEndian.Uint16(buf[2:4]); code != 0 {
+				return nil, fmt.Errorf("NAT-PMP error %d", code)
+			}
+			return buf[:size], nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("no response from %s", g.addr)
+}
@@ -0,0 +1,342 @@
+package nat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ssdpAddress     = "239.255.255.250:1900"
+	ssdpWait        = 2 * time.Second
+	upnpGatewayType = "urn:schemas-upnp-org:device:InternetGatewayDevice:1"
+)
+
+// upnpServiceTypes are the types of the services of a gateway mapping ports,
+// by order of preference.
+var upnpServiceTypes = []string{
+	"urn:schemas-upnp-org:service:WANIPConnection:2",
+	"urn:schemas-upnp-org:service:WANIPConnection:1",
+	"urn:schemas-upnp-org:service:WANPPPConnection:1",
+}
+
+// upnpErrOnlyPermanentLeases is the UPnP error of the gateways supporting
+// only permanent port mappings.
+const upnpErrOnlyPermanentLeases = 725
+
+// upnpGateway is a UPnP Internet Gateway Device.
+type upnpGateway struct {
+	client      *http.Client
+	controlURL  string
+	serviceType string
+	localIP     net.IP // of the host on the network of the gateway
+}
+
+// DiscoverUPnP looks for a UPnP Internet Gateway Device on the network, with
+// SSDP.
+func DiscoverUPnP(ctx context.Context) (Gateway, error) {
+	locations, err := ssdpSearch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var errs []string
+	for _, location := range locations {
+		gw, err := newUPnPGateway(ctx, location)
+		if err == nil {
+			return gw, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no UPnP gateway answered")
+	}
+	return nil, fmt.Errorf("no UPnP gateway found: %s", strings.Join(errs, "; "))
+}
+
+// ssdpSearch multicasts an SSDP search for the Internet Gateway Devices, and
+// returns the locations of the descriptions of the devices answering.
+func ssdpSearch(ctx context.Context) ([]string, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	addr, err := net.ResolveUDPAddr("udp4", ssdpAddress)
+	if err != nil {
+		return nil, err
+	}
+	search := "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + ssdpAddress + "\r\n" +
+		"ST: " + upnpGatewayType + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: " + strconv.Itoa(int(ssdpWait/time.Second)) + "\r\n\r\n"
+	if _, err := conn.WriteTo([]byte(search), addr); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(ssdpWait)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	var locations []string
+	seen := make(map[string]bool)
+	buf := make([]byte, 2048)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return locations, nil
+			}
+			return locations, err
+		}
+		location, ok := parseSSDPResponse(buf[:n])
+		if ok && !seen[location] {
+			seen[location] = true
+			locations = append(locations, location)
+		}
+	}
+}
+
+// parseSSDPResponse returns the location of the description of the gateway
+// answering an SSDP search with data.
+func parseSSDPResponse(data []byte) (string, bool) {
+	rsp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return "", false
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK || !strings.Contains(rsp.Header.Get("St"), "InternetGatewayDevice") {
+		return "", false
+	}
+	location := rsp.Header.Get("Location")
+	return location, location != ""
+}
+
+type upnpRoot struct {
+	URLBase string     `xml:"URLBase"`
+	Device  upnpDevice `xml:"device"`
+}
+
+type upnpDevice struct {
+	DeviceType string        `xml:"deviceType"`
+	Services   []upnpService `xml:"serviceList>service"`
+	Devices    []upnpDevice  `xml:"deviceList>device"`
+}
+
+type upnpService struct {
+	ServiceType string `xml:"serviceType"`
+	ControlURL  string `xml:"controlURL"`
+}
+
+// findService returns the service of the type serviceType of the device or
+// its embedded devices.
+func (d upnpDevice) findService(serviceType string) (upnpService, bool) {
+	for _, s := range d.Services {
+		if s.ServiceType == serviceType {
+			return s, true
+		}
+	}
+	for _, child := range d.Devices {
+		if s, ok := child.findService(serviceType); ok {
+			return s, true
+		}
+	}
+	return upnpService{}, false
+}
+
+// newUPnPGateway returns the gateway described at location.
+func newUPnPGateway(ctx context.Context, location string) (*upnpGateway, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("getting %s: %s", location, rsp.Status)
+	}
+	var root upnpRoot
+	if err := xml.NewDecoder(io.LimitReader(rsp.Body, 1<<20)).Decode(&root); err != nil {
+		return nil, fmt.Errorf("decoding the description at %s: %w", location, err)
+	}
+
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	if root.URLBase != "" {
+		if base, err = url.Parse(root.URLBase); err != nil {
+			return nil, fmt.Errorf("invalid URLBase: %w", err)
+		}
+	}
+	for _, serviceType := range upnpServiceTypes {
+		service, ok := root.Device.findService(serviceType)
+		if !ok {
+			continue
+		}
+		controlURL, err := base.Parse(service.ControlURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid control URL: %w", err)
+		}
+		localIP, err := localIPTo(controlURL.Host)
+		if err != nil {
+			return nil, err
+		}
+		return &upnpGateway{
+			client:      client,
+			controlURL:  controlURL.String(),
+			serviceType: serviceType,
+			localIP:     localIP,
+		}, nil
+	}
+	return nil, fmt.Errorf("the device at %s has no WAN connection service", location)
+}
+
+// localIPTo returns the local IP address of the host on the route to host,
+// given as host:port.
+func localIPTo(host string) (net.IP, error) {
+	conn, err := net.Dial("udp4", host)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+func (g *upnpGateway) String() string {
+	return "UPnP gateway " + g.controlURL
+}
+
+// ExternalIP implements Gateway.
+func (g *upnpGateway) ExternalIP(ctx context.Context) (net.IP, error) {
+	var rsp struct {
+		IP string `xml:"NewExternalIPAddress"`
+	}
+	if err := g.call(ctx, "GetExternalIPAddress", "", &rsp); err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(strings.TrimSpace(rsp.IP))
+	if ip == nil {
+		return nil, fmt.Errorf("invalid external IP %q", rsp.IP)
+	}
+	return ip, nil
+}
+
+// AddPortMapping implements Gateway.
+func (g *upnpGateway) AddPortMapping(ctx context.Context, internal, external int, lifetime time.Duration) (int, error) {
+	add := func(lifetime time.Duration) error {
+		args := "<NewRemoteHost></NewRemoteHost>" +
+			"<NewExternalPort>" + strconv.Itoa(external) + "</NewExternalPort>" +
+			"<NewProtocol>TCP</NewProtocol>" +
+			"<NewInternalPort>" + strconv.Itoa(internal) + "</NewInternalPort>" +
+			"<NewInternalClient>" + g.localIP.String() + "</NewInternalClient>" +
+			"<NewEnabled>1</NewEnabled>" +
+			"<NewPortMappingDescription>" + mappingDescription + "</NewPortMappingDescription>" +
+			"<NewLeaseDuration>" + strconv.Itoa(int(lifetime/time.Second)) + "</NewLeaseDuration>"
+		return g.call(ctx, "AddPortMapping", args, nil)
+	}
+	err := add(lifetime)
+	var upnpErr *upnpError
+	if errors.As(err, &upnpErr) && upnpErr.Code == upnpErrOnlyPermanentLeases {
+		err = add(0)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return external, nil
+}
+
+// DeletePortMapping implements Gateway.
+func (g *upnpGateway) DeletePortMapping(ctx context.Context, internal, external int) error {
+	args := "<NewRemoteHost></NewRemoteHost>" +
+		"<NewExternalPort>" + strconv.Itoa(external) + "</NewExternalPort>" +
+		"<NewProtocol>TCP</NewProtocol>"
+	return g.call(ctx, "DeletePortMapping", args, nil)
+}
+
+// upnpError is an error returned by a UPnP action.
+type upnpError struct {
+	Code        int    `xml:"errorCode"`
+	Description string `xml:"errorDescription"`
+}
+
+func (e *upnpError) Error() string {
+	return fmt.Sprintf("UPnP error %d: %s", e.Code, e.Description)
+}
+
+type soapEnvelope struct {
+	Body struct {
+		Fault *struct {
+			Detail struct {
+				Error *upnpError `xml:"UPnPError"`
+			} `xml:"detail"`
+		} `xml:"Fault"`
+		Response struct {
+			Inner []byte `xml:",innerxml"`
+		} `xml:",any"`
+	} `xml:"Body"`
+}
+
+// call calls the SOAP action of the service of the gateway with the XML
+// arguments args, and decodes the response arguments into result if it is
+// not nil.
+func (g *upnpGateway) call(ctx context.Context, action, args string, result interface{}) error {
+	body := `<?xml version="1.0"?>` +
+		`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" ` +
+		`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>` +
+		`<u:` + action + ` xmlns:u="` + g.serviceType + `">` + args + `</u:` + action + `>` +
+		`</s:Body></s:Envelope>`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.controlURL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", `text/xml; charset="utf-8"`)
+	req.Header.Set("SOAPAction", `"`+g.serviceType+"#"+action+`"`)
+	rsp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	var env soapEnvelope
+	if err := xml.NewDecoder(io.LimitReader(rsp.Body, 1<<20)).Decode(&env); err != nil {
+		if rsp.StatusCode != http.StatusOK {
+			return fmt.Errorf("%s: %s", action, rsp.Status)
+		}
+		return fmt.Errorf("decoding the %s response: %w", action, err)
+	}
+	if f := env.Body.Fault; f != nil || rsp.StatusCode != http.StatusOK {
+		if f != nil && f.Detail.Error != nil {
+			return fmt.Errorf("%s: %w", action, f.Detail.Error)
+		}
+		return fmt.Errorf("%s: %s", action, rsp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	inner := append(append([]byte("<r>"), env.Body.Response.Inner...), "</r>"...)
+	if err := xml.Unmarshal(inner, result); err != nil {
+		return fmt.Errorf("decoding the %s response: %w", action, err)
+	}
+	return nil
+}
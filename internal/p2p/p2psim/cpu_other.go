@@ -0,0 +1,9 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package p2psim
+
+import "time"
+
+// cpuTime returns 0: the CPU time of the process is not supported.
+func cpuTime() time.Duration { return 0 }
@@ -0,0 +1,268 @@
+// Package p2psim attaches simulated peers to the router of a single node, to
+// benchmark its reactors under the load of a large network without deploying
+// one.
+//
+// A Network runs the router and peer manager of the node under test on an
+// in-memory transport. The reactors under test open their channels with
+// Network.OpenChannel, and subscribe to the peer updates of
+// Network.PeerManager, as they would on a real node. Hundreds of virtual peers
+// can then be connected with AddPeers: each one is a lightweight connection
+// speaking the wire protocol of the router, driven by a scripted Behavior
+// (e.g. vote gossip or mempool spam), which receives and discards what the
+// node sends it.
+//
+// The Network counts the messages exchanged on each channel, how long the
+// peers were blocked sending to the node, i.e. how often its inbound queues
+// were full, and the CPU time of the process. Since the peers are cheap, the
+// CPU time is mostly spent by the router and the reactors of the node.
+//
+// Example:
+//
+//	sim, err := p2psim.NewNetwork(ctx, logger, p2psim.Options{})
+//	if err != nil {
+//	    return err
+//	}
+//	reactor, err := mempool.NewReactor(ctx, logger, cfg, sim.PeerManager,
+//	    txmp, sim.OpenChannel, sim.PeerManager.Subscribe(ctx))
+//	...
+//	sim.ResetStats()
+//	if _, err := sim.AddPeers(ctx, 300, p2psim.TxSpam(mempool.MempoolChannel, time.Millisecond, 1, 256)); err != nil {
+//	    return err
+//	}
+//	time.Sleep(10 * time.Second)
+//	fmt.Printf("%+v\n", sim.Stats())
+package p2psim
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
+)
+
+// Options are the parameters of a Network.
+type Options struct {
+	// Network is the network name (chain ID) of the node and the peers.
+	// Defaults to "p2psim".
+	Network string
+
+	// BufferSize is the number of messages buffered by each connection, in
+	// each direction. Defaults to 1.
+	BufferSize int
+
+	// QueueType is the type of the queues of the router. Defaults to the
+	// router default.
+	QueueType string
+}
+
+func (opts *Options) setDefaults() {
+	if opts.Network == "" {
+		opts.Network = "p2psim"
+	}
+	if opts.BufferSize == 0 {
+		opts.BufferSize = 1
+	}
+}
+
+// Network is a node under test, with a router and a peer manager, and the
+// virtual peers connected to it. It runs until the context given to
+// NewNetwork ends.
+type Network struct {
+	NodeID      types.NodeID
+	Router      *p2p.Router
+	PeerManager *p2p.PeerManager
+
+	logger   log.Logger
+	opts     Options
+	memory   *p2p.MemoryNetwork
+	endpoint p2p.Endpoint
+
+	mtx      sync.RWMutex
+	channels map[p2p.ChannelID]*channel
+	peers    []*Peer
+	started  time.Time
+	cpuStart time.Duration
+}
+
+// channel is a channel opened by the reactors of the node, with the
+// counters of its traffic.
+type channel struct {
+	counters // first, for the alignment of its 64-bit atomic fields
+	desc     *p2p.ChannelDescriptor
+}
+
+// NewNetwork starts the router of a node under test, on an in-memory
+// transport.
+func NewNetwork(ctx context.Context, logger log.Logger, opts Options) (*Network, error) {
+	opts.setDefaults()
+
+	privKey := ed25519.GenPrivKey()
+	nodeID := types.NodeIDFromPubKey(privKey.PubKey())
+	memory := p2p.NewMemoryNetwork(logger, opts.BufferSize)
+	transport := memory.CreateTransport(nodeID)
+
+	peerManager, err := p2p.NewPeerManager(nodeID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	if err != nil {
+		return nil, err
+	}
+	router, err := p2p.NewRouter(
+		ctx,
+		logger,
+		p2p.NopMetrics(),
+		nodeInfo(nodeID, opts.Network),
+		privKey,
+		peerManager,
+		[]p2p.Transport{transport},
+		transport.Endpoints(),
+		p2p.RouterOptions{
+			QueueType: opts.QueueType,
+			// All the peers connect from the same (missing) IP address.
+			MaxIncomingConnectionAttempts: math.MaxUint32,
+			IncomingConnectionWindow:      time.Millisecond,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := router.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	n := &Network{
+		NodeID:      nodeID,
+		Router:      router,
+		PeerManager: peerManager,
+		logger:      logger,
+		opts:        opts,
+		memory:      memory,
+		endpoint:    transport.Endpoints()[0],
+		channels:    make(map[p2p.ChannelID]*channel),
+	}
+	n.ResetStats()
+	return n, nil
+}
+
+func nodeInfo(nodeID types.NodeID, network string) types.NodeInfo {
+	return types.NodeInfo{
+		ProtocolVersion: types.ProtocolVersion{
+			P2P:   version.P2PProtocol,
+			Block: version.BlockProtocol,
+		},
+		NodeID:     nodeID,
+		ListenAddr: "0.0.0.0:0",
+		Network:    network,
+		Moniker:    string(nodeID),
+	}
+}
+
+// OpenChannel opens a channel of the node. It is a p2p.ChannelCreator, to be
+// passed to the reactors under test. The channels must be opened before the
+// peers are added.
+func (n *Network) OpenChannel(ctx context.Context, desc *p2p.ChannelDescriptor) (*p2p.Channel, error) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	ch, err := n.Router.OpenChannel(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	n.channels[desc.ID] = &channel{desc: desc}
+	return ch, nil
+}
+
+// AddPeers connects count new virtual peers to the node, driven by behavior,
+// and waits until the node has reported them to its reactors. The peers
+// disconnect when ctx ends.
+func (n *Network) AddPeers(ctx context.Context, count int, behavior Behavior) ([]*Peer, error) {
+	n.mtx.RLock()
+	info := nodeInfo("", n.opts.Network)
+	for id := range n.channels {
+		info.AddChannel(uint16(id))
+	}
+	n.mtx.RUnlock()
+	if len(info.Channels) == 0 {
+		return nil, fmt.Errorf("no channel is open")
+	}
+
+	subctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	updates := n.PeerManager.Subscribe(subctx)
+
+	peers := make([]*Peer, 0, count)
+	pending := make(map[types.NodeID]bool, count)
+	for i := 0; i < count; i++ {
+		peer, err := n.connect(ctx, info)
+		if err != nil {
+			return nil, fmt.Errorf("connecting peer %d: %w", i, err)
+		}
+		peers = append(peers, peer)
+		pending[peer.ID] = true
+	}
+
+	for len(pending) > 0 {
+		select {
+		case update := <-updates.Updates():
+			if update.Status == p2p.PeerStatusUp {
+				delete(pending, update.NodeID)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	n.mtx.Lock()
+	n.peers = append(n.peers, peers...)
+	n.mtx.Unlock()
+
+	for _, peer := range peers {
+		peer.start(ctx, behavior)
+	}
+	return peers, nil
+}
+
+// connect dials the node from a new virtual peer.
+func (n *Network) connect(ctx context.Context, info types.NodeInfo) (*Peer, error) {
+	privKey := ed25519.GenPrivKey()
+	info.NodeID = types.NodeIDFromPubKey(privKey.PubKey())
+	info.Moniker = string(info.NodeID)
+
+	transport := n.memory.CreateTransport(info.NodeID)
+	conn, err := transport.Dial(ctx, n.endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := conn.Handshake(ctx, info, privKey); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &Peer{
+		ID:        info.NodeID,
+		network:   n,
+		conn:      conn,
+		transport: transport,
+	}, nil
+}
+
+// Peers returns the virtual peers of the network.
+func (n *Network) Peers() []*Peer {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+	return append([]*Peer(nil), n.peers...)
+}
+
+// channel returns the channel with the given ID, or nil if the node has not
+// opened it.
+func (n *Network) channel(id p2p.ChannelID) *channel {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+	return n.channels[id]
+}
@@ -0,0 +1,101 @@
+package p2psim_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/p2psim"
+	"github.com/tendermint/tendermint/internal/p2p/p2ptest"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// echoReceiver collects the messages received by the peers.
+type echoReceiver struct {
+	p2psim.Script
+
+	mtx      sync.Mutex
+	received map[string]int
+}
+
+func (r *echoReceiver) Receive(_ context.Context, _ *p2psim.Peer, _ p2p.ChannelID, msg proto.Message) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.received[msg.(*p2ptest.Message).Value]++
+}
+
+func TestNetwork(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sim, err := p2psim.NewNetwork(ctx, log.TestingLogger(), p2psim.Options{})
+	require.NoError(t, err)
+
+	// The reactor under test broadcasts the messages it receives back to all
+	// the peers.
+	const chID = p2p.ChannelID(0x01)
+	desc := p2ptest.MakeChannelDesc(chID)
+	desc.RecvMessageCapacity = 1024 // the messages carry the IDs of the peers
+	ch, err := sim.OpenChannel(ctx, desc)
+	require.NoError(t, err)
+	go func() {
+		iter := ch.Receive(ctx)
+		for iter.Next(ctx) {
+			msg := iter.Envelope().Message
+			if err := ch.Send(ctx, p2p.Envelope{Broadcast: true, Message: msg}); err != nil {
+				return
+			}
+		}
+	}()
+
+	const numPeers, numMsgs = 20, 5
+	behavior := &echoReceiver{
+		Script: p2psim.Script{
+			Next: func(peer *p2psim.Peer, i int) *p2p.Envelope {
+				if i == numMsgs {
+					return nil
+				}
+				return &p2p.Envelope{
+					ChannelID: chID,
+					Message:   &p2ptest.Message{Value: fmt.Sprintf("%s/%d", peer.ID, i)},
+				}
+			},
+		},
+		received: make(map[string]int),
+	}
+	peers, err := sim.AddPeers(ctx, numPeers, behavior)
+	require.NoError(t, err)
+	require.Len(t, peers, numPeers)
+	require.Len(t, sim.Peers(), numPeers)
+
+	require.Eventually(t, func() bool {
+		stats := sim.Stats().Channels[chID]
+		return stats.Received == numPeers*numPeers*numMsgs
+	}, 10*time.Second, 10*time.Millisecond)
+
+	stats := sim.Stats()
+	require.EqualValues(t, numPeers*numMsgs, stats.Channels[chID].Sent)
+	require.Equal(t, []p2p.ChannelID{chID}, stats.ChannelIDs())
+	require.Equal(t, stats.Channels[chID], stats.Total())
+
+	// Each peer received every message.
+	behavior.mtx.Lock()
+	require.Len(t, behavior.received, numPeers*numMsgs)
+	for value, count := range behavior.received {
+		require.Equal(t, numPeers, count, value)
+	}
+	behavior.mtx.Unlock()
+
+	sim.ResetStats()
+	require.Zero(t, sim.Stats().Total())
+
+	_, err = sim.AddPeers(ctx, 1, p2psim.Idle)
+	require.NoError(t, err)
+	require.Len(t, sim.Peers(), numPeers+1)
+}
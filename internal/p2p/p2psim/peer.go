@@ -0,0 +1,194 @@
+package p2psim
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	protomem "github.com/tendermint/tendermint/proto/tendermint/mempool"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Behavior drives a virtual peer: it sends the messages of the peer to the
+// node.
+type Behavior interface {
+	// Run runs the behavior of peer until ctx ends, or until the behavior is
+	// done. The peer stays connected after Run returns.
+	Run(ctx context.Context, peer *Peer) error
+}
+
+// BehaviorFunc is a function implementing Behavior.
+type BehaviorFunc func(ctx context.Context, peer *Peer) error
+
+// Run implements Behavior.
+func (f BehaviorFunc) Run(ctx context.Context, peer *Peer) error { return f(ctx, peer) }
+
+// Receiver is implemented by the behaviors reacting to the messages sent by
+// the node to the peer. Otherwise, the messages are only counted. Receive is
+// called by a single goroutine per peer, concurrently with Run.
+type Receiver interface {
+	Receive(ctx context.Context, peer *Peer, chID p2p.ChannelID, msg proto.Message)
+}
+
+// Idle is a behavior sending nothing.
+var Idle Behavior = BehaviorFunc(func(context.Context, *Peer) error { return nil })
+
+// Script is a behavior sending the messages returned by Next, in order, until
+// Next returns nil.
+type Script struct {
+	// Interval is the delay between two messages. If zero, the messages are
+	// sent as fast as the node accepts them.
+	Interval time.Duration
+
+	// Next returns the i-th message of the peer, or nil when done. The message
+	// is not wrapped in the message type of its channel.
+	Next func(peer *Peer, i int) *p2p.Envelope
+}
+
+// Run implements Behavior.
+func (s Script) Run(ctx context.Context, peer *Peer) error {
+	var tick <-chan time.Time
+	if s.Interval > 0 {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for i := 0; ; i++ {
+		if tick != nil {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		env := s.Next(peer, i)
+		if env == nil {
+			return nil
+		}
+		if err := peer.Send(ctx, env.ChannelID, env.Message); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// TxSpam returns a behavior sending batches of txsPerMsg random transactions
+// of txSize bytes on the mempool channel chID, every interval, like a peer
+// relaying a flood of transactions.
+func TxSpam(chID p2p.ChannelID, interval time.Duration, txsPerMsg, txSize int) Behavior {
+	return Script{
+		Interval: interval,
+		Next: func(peer *Peer, _ int) *p2p.Envelope {
+			msg := &protomem.Txs{Txs: make([][]byte, txsPerMsg)}
+			for i := range msg.Txs {
+				msg.Txs[i] = make([]byte, txSize)
+				_, _ = peer.rand.Read(msg.Txs[i])
+			}
+			return &p2p.Envelope{ChannelID: chID, Message: msg}
+		},
+	}
+}
+
+// Peer is a virtual peer connected to the node.
+type Peer struct {
+	ID types.NodeID
+
+	network   *Network
+	conn      p2p.Connection
+	transport *p2p.MemoryTransport
+	rand      *rand.Rand // only used by the behavior
+}
+
+// Send sends msg to the node on channel chID, wrapping it in the message type
+// of the channel if necessary. It blocks while the node does not accept it,
+// and this time is accounted in the stats of the channel.
+func (p *Peer) Send(ctx context.Context, chID p2p.ChannelID, msg proto.Message) error {
+	ch := p.network.channel(chID)
+	if ch == nil {
+		return fmt.Errorf("channel %v is not open", chID)
+	}
+	if wrapper, ok := ch.desc.MessageType.(p2p.Wrapper); ok && reflect.TypeOf(msg) != reflect.TypeOf(wrapper) {
+		wrapped := proto.Clone(wrapper).(p2p.Wrapper)
+		if err := wrapped.Wrap(msg); err != nil {
+			return err
+		}
+		msg = wrapped
+	}
+	bz, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := p.conn.SendMessage(ctx, chID, bz); err != nil {
+		return err
+	}
+	ch.addSent(len(bz), time.Since(start))
+	return nil
+}
+
+// start runs the behavior and the receive loop of the peer until ctx ends.
+func (p *Peer) start(ctx context.Context, behavior Behavior) {
+	p.rand = rand.New(rand.NewSource(time.Now().UnixNano())) // nolint:gosec
+	receiver, _ := behavior.(Receiver)
+
+	go func() {
+		<-ctx.Done()
+		_ = p.conn.Close()
+		_ = p.transport.Close()
+	}()
+	go func() {
+		if err := behavior.Run(ctx, p); err != nil {
+			p.network.logger.Error("virtual peer behavior failed", "peer", p.ID, "err", err)
+		}
+	}()
+	go p.receive(ctx, receiver)
+}
+
+// receive counts the messages sent by the node to the peer, and passes them
+// to receiver if it is not nil.
+func (p *Peer) receive(ctx context.Context, receiver Receiver) {
+	for {
+		chID, bz, err := p.conn.ReceiveMessage(ctx)
+		if err != nil {
+			return
+		}
+		ch := p.network.channel(chID)
+		if ch == nil {
+			continue
+		}
+		ch.addReceived(len(bz))
+		if receiver == nil {
+			continue
+		}
+
+		msg, err := decode(ch.desc.MessageType, bz)
+		if err != nil {
+			p.network.logger.Error("virtual peer failed to decode message",
+				"peer", p.ID, "channel", chID, "err", err)
+			continue
+		}
+		receiver.Receive(ctx, p, chID, msg)
+	}
+}
+
+// decode decodes a message of the given type, and unwraps it if it is a
+// p2p.Wrapper.
+func decode(messageType proto.Message, bz []byte) (proto.Message, error) {
+	msg := proto.Clone(messageType)
+	msg.Reset()
+	if err := proto.Unmarshal(bz, msg); err != nil {
+		return nil, err
+	}
+	if wrapper, ok := msg.(p2p.Wrapper); ok {
+		return wrapper.Unwrap()
+	}
+	return msg, nil
+}
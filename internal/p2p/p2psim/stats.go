@@ -0,0 +1,149 @@
+package p2psim
+
+import (
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+)
+
+// Stats are the stats of a Network since it was created, or since the last
+// call to ResetStats.
+type Stats struct {
+	Elapsed  time.Duration // wall time
+	CPU      time.Duration // user and system CPU time of the process, if supported
+	Channels map[p2p.ChannelID]ChannelStats
+}
+
+// ChannelStats are the stats of the traffic on a channel.
+type ChannelStats struct {
+	Sent          uint64        // messages sent by the peers to the node
+	SentBytes     uint64        // size of the messages sent by the peers
+	Received      uint64        // messages received by the peers from the node
+	ReceivedBytes uint64        // size of the messages received by the peers
+	SendWait      time.Duration // total time the peers were blocked sending to the node
+	MaxSendWait   time.Duration // longest time a peer was blocked sending a message
+}
+
+// Total returns the sum of the stats of all the channels.
+func (s Stats) Total() ChannelStats {
+	var total ChannelStats
+	for _, cs := range s.Channels {
+		total.Sent += cs.Sent
+		total.SentBytes += cs.SentBytes
+		total.Received += cs.Received
+		total.ReceivedBytes += cs.ReceivedBytes
+		total.SendWait += cs.SendWait
+		if cs.MaxSendWait > total.MaxSendWait {
+			total.MaxSendWait = cs.MaxSendWait
+		}
+	}
+	return total
+}
+
+// Report reports the stats of all the channels as metrics of the benchmark b,
+// per operation: the messages and bytes sent to and received from the node,
+// the time the peers were blocked sending, and the CPU time. It should be
+// called after the timer of b is stopped.
+func (s Stats) Report(b *testing.B) {
+	n := float64(b.N)
+	total := s.Total()
+	b.ReportMetric(float64(total.Sent)/n, "msgs-in/op")
+	b.ReportMetric(float64(total.Received)/n, "msgs-out/op")
+	b.ReportMetric(float64(total.ReceivedBytes)/n, "B-out/op")
+	b.ReportMetric(float64(total.SendWait.Nanoseconds())/n, "send-wait-ns/op")
+	b.ReportMetric(float64(total.MaxSendWait.Microseconds()), "max-send-wait-µs")
+	if s.CPU > 0 {
+		b.ReportMetric(float64(s.CPU.Nanoseconds())/n, "cpu-ns/op")
+	}
+}
+
+// ChannelIDs returns the IDs of the channels of the stats, in order.
+func (s Stats) ChannelIDs() []p2p.ChannelID {
+	ids := make([]p2p.ChannelID, 0, len(s.Channels))
+	for id := range s.Channels {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Stats returns the stats of the network.
+func (n *Network) Stats() Stats {
+	n.mtx.RLock()
+	defer n.mtx.RUnlock()
+
+	stats := Stats{
+		Elapsed:  time.Since(n.started),
+		Channels: make(map[p2p.ChannelID]ChannelStats, len(n.channels)),
+	}
+	if cpu := cpuTime(); cpu > 0 {
+		stats.CPU = cpu - n.cpuStart
+	}
+	for id, ch := range n.channels {
+		stats.Channels[id] = ch.stats()
+	}
+	return stats
+}
+
+// ResetStats resets the stats of the network, e.g. once the peers are
+// connected.
+func (n *Network) ResetStats() {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+
+	n.started = time.Now()
+	n.cpuStart = cpuTime()
+	for _, ch := range n.channels {
+		ch.reset()
+	}
+}
+
+// counters are the counters of the traffic on a channel, updated atomically.
+type counters struct {
+	sent          uint64
+	sentBytes     uint64
+	received      uint64
+	receivedBytes uint64
+	sendWait      int64
+	maxSendWait   int64
+}
+
+func (c *counters) addSent(size int, wait time.Duration) {
+	atomic.AddUint64(&c.sent, 1)
+	atomic.AddUint64(&c.sentBytes, uint64(size))
+	atomic.AddInt64(&c.sendWait, int64(wait))
+	for {
+		max := atomic.LoadInt64(&c.maxSendWait)
+		if int64(wait) <= max || atomic.CompareAndSwapInt64(&c.maxSendWait, max, int64(wait)) {
+			return
+		}
+	}
+}
+
+func (c *counters) addReceived(size int) {
+	atomic.AddUint64(&c.received, 1)
+	atomic.AddUint64(&c.receivedBytes, uint64(size))
+}
+
+func (c *counters) stats() ChannelStats {
+	return ChannelStats{
+		Sent:          atomic.LoadUint64(&c.sent),
+		SentBytes:     atomic.LoadUint64(&c.sentBytes),
+		Received:      atomic.LoadUint64(&c.received),
+		ReceivedBytes: atomic.LoadUint64(&c.receivedBytes),
+		SendWait:      time.Duration(atomic.LoadInt64(&c.sendWait)),
+		MaxSendWait:   time.Duration(atomic.LoadInt64(&c.maxSendWait)),
+	}
+}
+
+func (c *counters) reset() {
+	atomic.StoreUint64(&c.sent, 0)
+	atomic.StoreUint64(&c.sentBytes, 0)
+	atomic.StoreUint64(&c.received, 0)
+	atomic.StoreUint64(&c.receivedBytes, 0)
+	atomic.StoreInt64(&c.sendWait, 0)
+	atomic.StoreInt64(&c.maxSendWait, 0)
+}
@@ -0,0 +1,216 @@
+package p2p
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+// PeerFilterAction is the action of a peer filter rule.
+type PeerFilterAction string
+
+// PeerFilterKind is what a peer filter rule matches.
+type PeerFilterKind string
+
+const (
+	PeerFilterAllow PeerFilterAction = "allow"
+	PeerFilterDeny  PeerFilterAction = "deny"
+
+	PeerFilterCIDR    PeerFilterKind = "cidr"     // the IP address of the peer
+	PeerFilterNodeID  PeerFilterKind = "node_id"  // the node ID of the peer
+	PeerFilterChainID PeerFilterKind = "chain_id" // the network of the peer
+)
+
+// PeerFilterRule is a rule of a peer filter, allowing or denying the peers
+// matching its value.
+type PeerFilterRule struct {
+	Action PeerFilterAction
+	Kind   PeerFilterKind
+	Value  string
+}
+
+func (r PeerFilterRule) String() string {
+	return fmt.Sprintf("%s %s %s", r.Action, r.Kind, r.Value)
+}
+
+// normalize validates the rule, and returns it with its value in canonical
+// form, and the network of a CIDR rule. A single IP address is a CIDR rule
+// matching only itself.
+func (r PeerFilterRule) normalize() (PeerFilterRule, *net.IPNet, error) {
+	switch r.Action {
+	case PeerFilterAllow, PeerFilterDeny:
+	default:
+		return r, nil, fmt.Errorf("invalid peer filter action %q, must be %q or %q",
+			r.Action, PeerFilterAllow, PeerFilterDeny)
+	}
+
+	value := strings.TrimSpace(r.Value)
+	switch r.Kind {
+	case PeerFilterCIDR:
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return r, nil, fmt.Errorf("invalid IP address %q", r.Value)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			value = fmt.Sprintf("%s/%d", ip, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return r, nil, fmt.Errorf("invalid CIDR %q: %w", r.Value, err)
+		}
+		r.Value = ipNet.String()
+		return r, ipNet, nil
+
+	case PeerFilterNodeID:
+		id, err := types.NewNodeID(value)
+		if err != nil {
+			return r, nil, err
+		}
+		r.Value = string(id)
+
+	case PeerFilterChainID:
+		if value == "" {
+			return r, nil, fmt.Errorf("empty chain ID")
+		}
+		r.Value = value
+
+	default:
+		return r, nil, fmt.Errorf("invalid peer filter kind %q, must be %q, %q or %q",
+			r.Kind, PeerFilterCIDR, PeerFilterNodeID, PeerFilterChainID)
+	}
+	return r, nil, nil
+}
+
+type peerFilterRule struct {
+	PeerFilterRule
+	ipNet *net.IPNet // of a CIDR rule
+}
+
+func (r peerFilterRule) matches(value string, ip net.IP) bool {
+	if r.ipNet != nil {
+		return ip != nil && r.ipNet.Contains(ip)
+	}
+	return r.Value == value
+}
+
+// PeerFilter filters the peers by their IP address, node ID and chain ID,
+// with lists of rules allowing and denying them. A peer matching a deny rule
+// is rejected. Otherwise, if there are allow rules of a kind, the peer must
+// match one of them. The rules only apply to new connections, and can be
+// changed at runtime. PeerFilter is safe for concurrent use.
+type PeerFilter struct {
+	mtx   sync.RWMutex
+	rules []peerFilterRule // in the order they were added
+}
+
+// NewPeerFilter returns a peer filter with the rules.
+func NewPeerFilter(rules []PeerFilterRule) (*PeerFilter, error) {
+	f := &PeerFilter{}
+	for _, rule := range rules {
+		if _, err := f.AddRule(rule); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// AddRule adds the rule to the filter. It returns false if the filter has the
+// rule already.
+func (f *PeerFilter) AddRule(rule PeerFilterRule) (bool, error) {
+	rule, ipNet, err := rule.normalize()
+	if err != nil {
+		return false, err
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, r := range f.rules {
+		if r.PeerFilterRule == rule {
+			return false, nil
+		}
+	}
+	f.rules = append(f.rules, peerFilterRule{PeerFilterRule: rule, ipNet: ipNet})
+	return true, nil
+}
+
+// RemoveRule removes the rule from the filter. It returns false if the filter
+// doesn't have the rule.
+func (f *PeerFilter) RemoveRule(rule PeerFilterRule) (bool, error) {
+	rule, _, err := rule.normalize()
+	if err != nil {
+		return false, err
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for i, r := range f.rules {
+		if r.PeerFilterRule == rule {
+			f.rules = append(f.rules[:i:i], f.rules[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Rules returns the rules of the filter, in the order they were added.
+func (f *PeerFilter) Rules() []PeerFilterRule {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	rules := make([]PeerFilterRule, 0, len(f.rules))
+	for _, r := range f.rules {
+		rules = append(rules, r.PeerFilterRule)
+	}
+	return rules
+}
+
+// FilterIP returns an error if the peer with the IP address ip is rejected.
+func (f *PeerFilter) FilterIP(ip net.IP) error {
+	return f.filter(PeerFilterCIDR, ip.String(), ip)
+}
+
+// FilterNodeID returns an error if the peer id is rejected.
+func (f *PeerFilter) FilterNodeID(id types.NodeID) error {
+	return f.filter(PeerFilterNodeID, string(id), nil)
+}
+
+// FilterNodeInfo returns an error if the peer, as described in its handshake,
+// is rejected by its node ID or chain ID.
+func (f *PeerFilter) FilterNodeInfo(info types.NodeInfo) error {
+	if err := f.FilterNodeID(info.NodeID); err != nil {
+		return err
+	}
+	return f.filter(PeerFilterChainID, info.Network, nil)
+}
+
+// filter applies the rules of kind to the value of a peer, or its IP address
+// for the CIDR rules.
+func (f *PeerFilter) filter(kind PeerFilterKind, value string, ip net.IP) error {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	allowRules, allowed := false, false
+	for _, r := range f.rules {
+		if r.Kind != kind {
+			continue
+		}
+		switch {
+		case r.Action == PeerFilterDeny && r.matches(value, ip):
+			return fmt.Errorf("%s %s is denied by the rule %q", kind, value, r)
+		case r.Action == PeerFilterAllow:
+			allowRules = true
+			allowed = allowed || r.matches(value, ip)
+		}
+	}
+	if allowRules && !allowed {
+		return fmt.Errorf("%s %s is not allowed", kind, value)
+	}
+	return nil
+}
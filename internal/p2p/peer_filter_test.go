@@ -0,0 +1,78 @@
+package p2p_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPeerFilter(t *testing.T) {
+	aID := types.NodeID("aa112233445566778899aabbccddeeff00112233")
+	bID := types.NodeID("bb112233445566778899aabbccddeeff00112233")
+
+	filter, err := p2p.NewPeerFilter([]p2p.PeerFilterRule{
+		{Action: p2p.PeerFilterAllow, Kind: p2p.PeerFilterCIDR, Value: "10.0.0.0/8"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterCIDR, Value: "10.0.0.7"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterNodeID, Value: "BB112233445566778899AABBCCDDEEFF00112233"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []p2p.PeerFilterRule{
+		{Action: p2p.PeerFilterAllow, Kind: p2p.PeerFilterCIDR, Value: "10.0.0.0/8"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterCIDR, Value: "10.0.0.7/32"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterNodeID, Value: string(bID)},
+	}, filter.Rules())
+
+	// The deny rules win over the allow rules, and only the allowed CIDRs
+	// are accepted.
+	require.NoError(t, filter.FilterIP(net.ParseIP("10.1.2.3")))
+	require.NoError(t, filter.FilterIP(net.ParseIP("::ffff:10.1.2.3")))
+	require.Error(t, filter.FilterIP(net.ParseIP("10.0.0.7")))
+	require.Error(t, filter.FilterIP(net.ParseIP("192.0.2.1")))
+
+	// Without allow rules of a kind, everything not denied is accepted.
+	require.NoError(t, filter.FilterNodeID(aID))
+	require.Error(t, filter.FilterNodeID(bID))
+	require.NoError(t, filter.FilterNodeInfo(types.NodeInfo{NodeID: aID, Network: "test"}))
+	require.Error(t, filter.FilterNodeInfo(types.NodeInfo{NodeID: bID, Network: "test"}))
+
+	// Rules are added and removed at runtime, once.
+	chainRule := p2p.PeerFilterRule{Action: p2p.PeerFilterAllow, Kind: p2p.PeerFilterChainID, Value: "test"}
+	added, err := filter.AddRule(chainRule)
+	require.NoError(t, err)
+	require.True(t, added)
+	added, err = filter.AddRule(chainRule)
+	require.NoError(t, err)
+	require.False(t, added)
+	require.NoError(t, filter.FilterNodeInfo(types.NodeInfo{NodeID: aID, Network: "test"}))
+	require.Error(t, filter.FilterNodeInfo(types.NodeInfo{NodeID: aID, Network: "other"}))
+
+	removed, err := filter.RemoveRule(p2p.PeerFilterRule{
+		Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterCIDR, Value: "10.0.0.7",
+	})
+	require.NoError(t, err)
+	require.True(t, removed)
+	require.NoError(t, filter.FilterIP(net.ParseIP("10.0.0.7")))
+	removed, err = filter.RemoveRule(chainRule)
+	require.NoError(t, err)
+	require.True(t, removed)
+	removed, err = filter.RemoveRule(chainRule)
+	require.NoError(t, err)
+	require.False(t, removed)
+	require.NoError(t, filter.FilterNodeInfo(types.NodeInfo{NodeID: aID, Network: "other"}))
+
+	for _, rule := range []p2p.PeerFilterRule{
+		{Action: "block", Kind: p2p.PeerFilterCIDR, Value: "10.0.0.0/8"},
+		{Action: p2p.PeerFilterDeny, Kind: "port", Value: "26656"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterCIDR, Value: "10.0.0.0/33"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterCIDR, Value: "host"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterNodeID, Value: "aa11"},
+		{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterChainID, Value: " "},
+	} {
+		_, err := filter.AddRule(rule)
+		require.Error(t, err, rule)
+	}
+}
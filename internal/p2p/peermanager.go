@@ -51,11 +51,36 @@ type PeerUpdate struct {
 	Status PeerStatus
 }
 
+// ChannelIDSet is a set of channel IDs.
+type ChannelIDSet map[ChannelID]struct{}
+
+// NewChannelIDSet returns the set of the channel IDs ids.
+func NewChannelIDSet(ids []byte) ChannelIDSet {
+	set := make(ChannelIDSet, len(ids))
+	for _, id := range ids {
+		set[ChannelID(id)] = struct{}{}
+	}
+	return set
+}
+
+// Contains returns whether the set contains the channel id. A nil set
+// contains all channels.
+func (s ChannelIDSet) Contains(id ChannelID) bool {
+	if s == nil {
+		return true
+	}
+	_, ok := s[id]
+	return ok
+}
+
 // PeerUpdates is a peer update subscription with notifications about peer
 // events (currently just status changes).
 type PeerUpdates struct {
 	routerUpdatesCh  chan PeerUpdate
 	reactorUpdatesCh chan PeerUpdate
+
+	// channel, if set, limits the subscription to the peers serving it.
+	channel *ChannelID
 }
 
 // NewPeerUpdates creates a new PeerUpdates subscription. It is primarily for
@@ -95,6 +120,25 @@ type PeerManagerOptions struct {
 	// will be deleted. 0 means no limit.
 	MaxPeers uint16
 
+	// MaxAddressesPerPeer is the maximum number of addresses to store for a
+	// peer. When exceeded, the address with the most dial failures is dropped
+	// to make room for a new one, or the new address is ignored if all stored
+	// addresses are reachable. 0 means no limit.
+	MaxAddressesPerPeer uint16
+
+	// MaxAddressFailures is the number of consecutive dial failures after
+	// which GarbageCollect() drops an address. 0 disables this.
+	MaxAddressFailures uint32
+
+	// StaleAddressTime is the time after the last successful dial of an
+	// address whose dials now fail, after which GarbageCollect() drops it.
+	// 0 disables this.
+	StaleAddressTime time.Duration
+
+	// GCInterval is how often the router garbage collects the peer store, see
+	// GarbageCollect(). 0 disables periodic garbage collection.
+	GCInterval time.Duration
+
 	// MaxConnected is the maximum number of connected peers (inbound and
 	// outbound). 0 means no limit.
 	MaxConnected uint16
@@ -128,10 +172,27 @@ type PeerManagerOptions struct {
 	// retry times, to avoid thundering herds. 0 disables jitter.
 	RetryTimeJitter time.Duration
 
+	// MaxConcurrentDials is the maximum number of dials in progress. Defaults
+	// to 8.
+	MaxConcurrentDials uint16
+
+	// MaxConcurrentDialsPerSubnet is the maximum number of dials in progress
+	// to the addresses of a subnet: a /24 for IPv4, a /48 for IPv6, or a
+	// hostname. 0 means no limit.
+	MaxConcurrentDialsPerSubnet uint16
+
+	// MinDialInterval is the minimum time between the starts of two dials,
+	// spreading the dials after a restart. 0 disables it.
+	MinDialInterval time.Duration
+
 	// PeerScores sets fixed scores for specific peers. It is mainly used
 	// for testing. A score of 0 is ignored.
 	PeerScores map[types.NodeID]PeerScore
 
+	// MaintenanceAvoidMargin is how long before an announced maintenance
+	// window reactors start avoiding a peer as a data source.
+	MaintenanceAvoidMargin time.Duration
+
 	// PrivatePeerIDs defines a set of NodeID objects which the PEX reactor will
 	// consider private and never gossip.
 	PrivatePeers map[types.NodeID]struct{}
@@ -171,6 +232,18 @@ func (o *PeerManagerOptions) Validate() error {
 		}
 	}
 
+	if o.StaleAddressTime < 0 {
+		return fmt.Errorf("StaleAddressTime %v can't be negative", o.StaleAddressTime)
+	}
+
+	if o.MinDialInterval < 0 {
+		return fmt.Errorf("MinDialInterval %v can't be negative", o.MinDialInterval)
+	}
+
+	if o.GCInterval < 0 {
+		return fmt.Errorf("GCInterval %v can't be negative", o.GCInterval)
+	}
+
 	if o.MaxRetryTime > 0 {
 		if o.MinRetryTime == 0 {
 			return errors.New("can't set MaxRetryTime without MinRetryTime")
@@ -263,13 +336,18 @@ type PeerManager struct {
 	dialWaker  *tmsync.Waker // wakes up DialNext() on relevant peer changes
 	evictWaker *tmsync.Waker // wakes up EvictNext() on relevant peer changes
 
+	stats       *PeerStatsStore
+	maintenance *PeerMaintenance
+
 	mtx           sync.Mutex
 	store         *peerStore
+	dials         *dialScheduler
 	subscriptions map[*PeerUpdates]*PeerUpdates // keyed by struct identity (address)
 	dialing       map[types.NodeID]bool         // peers being dialed (DialNext → Dialed/DialFail)
 	upgrading     map[types.NodeID]types.NodeID // peers claimed for upgrade (DialNext → Dialed/DialFail)
 	connected     map[types.NodeID]bool         // connected peers (Dialed/Accepted → Disconnected)
 	ready         map[types.NodeID]bool         // ready peers (Ready → Disconnected)
+	channels      map[types.NodeID]ChannelIDSet // channels served by the ready peers
 	evict         map[types.NodeID]bool         // peers scheduled for eviction (Connected → EvictNext)
 	evicting      map[types.NodeID]bool         // peers being evicted (EvictNext → Disconnected)
 }
@@ -290,6 +368,11 @@ func NewPeerManager(selfID types.NodeID, peerDB dbm.DB, options PeerManagerOptio
 		return nil, err
 	}
 
+	stats, err := newPeerStatsStore(peerDB)
+	if err != nil {
+		return nil, err
+	}
+
 	peerManager := &PeerManager{
 		selfID:     selfID,
 		options:    options,
@@ -297,11 +380,15 @@ func NewPeerManager(selfID types.NodeID, peerDB dbm.DB, options PeerManagerOptio
 		dialWaker:  tmsync.NewWaker(),
 		evictWaker: tmsync.NewWaker(),
 
+		stats:         stats,
+		maintenance:   NewPeerMaintenance(options.MaintenanceAvoidMargin),
 		store:         store,
+		dials:         newDialScheduler(options),
 		dialing:       map[types.NodeID]bool{},
 		upgrading:     map[types.NodeID]types.NodeID{},
 		connected:     map[types.NodeID]bool{},
 		ready:         map[types.NodeID]bool{},
+		channels:      map[types.NodeID]ChannelIDSet{},
 		evict:         map[types.NodeID]bool{},
 		evicting:      map[types.NodeID]bool{},
 		subscriptions: map[*PeerUpdates]*PeerUpdates{},
@@ -309,7 +396,8 @@ func NewPeerManager(selfID types.NodeID, peerDB dbm.DB, options PeerManagerOptio
 	if err = peerManager.configurePeers(); err != nil {
 		return nil, err
 	}
-	if err = peerManager.prunePeers(); err != nil {
+	peerManager.restoreScores()
+	if _, err = peerManager.prunePeers(); err != nil {
 		return nil, err
 	}
 	return peerManager, nil
@@ -347,37 +435,118 @@ func (m *PeerManager) configurePeer(peer peerInfo) peerInfo {
 	return peer
 }
 
+// restoreScores restores the scores of the peers in the peer store from their
+// statistics, so that the peers that were good before a restart are preferred.
+// The caller must hold the mutex lock.
+func (m *PeerManager) restoreScores() {
+	for _, peer := range m.store.peers {
+		m.restoreScore(peer)
+	}
+	m.store.ranked = nil
+}
+
+// restoreScore restores the score of the peer from its statistics. The caller
+// must hold the mutex lock, and invalidate the Ranked() cache.
+func (m *PeerManager) restoreScore(peer *peerInfo) {
+	if stats, ok := m.stats.Get(peer.ID); ok {
+		peer.MutableScore = stats.Score
+		peer.Reputation = stats.Reputation()
+	}
+}
+
+// updateReputation updates the reputation of the peer from its statistics.
+// The caller must hold the mutex lock.
+func (m *PeerManager) updateReputation(id types.NodeID) {
+	peer, ok := m.store.peers[id]
+	if !ok {
+		return
+	}
+	if stats, ok := m.stats.Get(id); ok && stats.Reputation() != peer.Reputation {
+		peer.Reputation = stats.Reputation()
+		m.store.ranked = nil
+	}
+}
+
 // newPeerInfo creates a peerInfo for a new peer.
 func (m *PeerManager) newPeerInfo(id types.NodeID) peerInfo {
 	peerInfo := peerInfo{
 		ID:          id,
 		AddressInfo: map[NodeAddress]*peerAddressInfo{},
 	}
+	m.restoreScore(&peerInfo)
 	return m.configurePeer(peerInfo)
 }
 
 // prunePeers removes low-scored peers from the peer store if it contains more
-// than MaxPeers peers. The caller must hold the mutex lock.
-func (m *PeerManager) prunePeers() error {
+// than MaxPeers peers, returning the number of peers removed. Among peers with
+// the same score, the ones we have gone the longest without reaching are
+// removed first. The caller must hold the mutex lock.
+func (m *PeerManager) prunePeers() (int, error) {
 	if m.options.MaxPeers == 0 || m.store.Size() <= int(m.options.MaxPeers) {
-		return nil
+		return 0, nil
 	}
 
-	ranked := m.store.Ranked()
+	ranked := make([]*peerInfo, len(m.store.Ranked()))
+	copy(ranked, m.store.Ranked())
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score() != ranked[j].Score() {
+			return ranked[i].Score() > ranked[j].Score()
+		}
+		return ranked[i].lastReached().After(ranked[j].lastReached())
+	})
+
+	pruned := 0
 	for i := len(ranked) - 1; i >= 0; i-- {
 		peerID := ranked[i].ID
 		switch {
 		case m.store.Size() <= int(m.options.MaxPeers):
-			return nil
+			return pruned, nil
 		case m.dialing[peerID]:
 		case m.connected[peerID]:
 		default:
 			if err := m.store.Delete(peerID); err != nil {
-				return err
+				return pruned, err
 			}
+			pruned++
 		}
 	}
-	return nil
+	return pruned, nil
+}
+
+// limitAddresses makes room for a new address of the peer if it has
+// MaxAddressesPerPeer addresses, by dropping the address with the most dial
+// failures. It returns false if all addresses are reachable, in which case the
+// new address should be ignored.
+func (m *PeerManager) limitAddresses(peer *peerInfo) bool {
+	if m.options.MaxAddressesPerPeer == 0 || len(peer.AddressInfo) < int(m.options.MaxAddressesPerPeer) {
+		return true
+	}
+
+	var worst *peerAddressInfo
+	for _, addressInfo := range peer.AddressInfo {
+		switch {
+		case addressInfo.DialFailures == 0:
+		case worst == nil,
+			addressInfo.DialFailures > worst.DialFailures,
+			addressInfo.DialFailures == worst.DialFailures &&
+				addressInfo.LastDialSuccess.Before(worst.LastDialSuccess):
+			worst = addressInfo
+		}
+	}
+	if worst == nil {
+		return false
+	}
+
+	// The address map may be shared with the stored peer, so replace it
+	// rather than deleting from it.
+	kept := make(map[NodeAddress]*peerAddressInfo, len(peer.AddressInfo))
+	for address, addressInfo := range peer.AddressInfo {
+		if address != worst.Address {
+			kept[address] = addressInfo
+		}
+	}
+	peer.AddressInfo = kept
+	return true
 }
 
 // Add adds a peer to the manager, given as an address. If the peer already
@@ -404,12 +573,15 @@ func (m *PeerManager) Add(address NodeAddress) (bool, error) {
 		return false, nil
 	}
 
-	// else add the new address
+	// else add the new address, if there is room for it
+	if !m.limitAddresses(&peer) {
+		return false, nil
+	}
 	peer.AddressInfo[address] = &peerAddressInfo{Address: address}
 	if err := m.store.Set(peer); err != nil {
 		return false, err
 	}
-	if err := m.prunePeers(); err != nil {
+	if _, err := m.prunePeers(); err != nil {
 		return true, err
 	}
 	m.dialWaker.Wake()
@@ -428,6 +600,104 @@ func (m *PeerManager) PeerRatio() float64 {
 	return float64(m.store.Size()) / float64(m.options.MaxPeers)
 }
 
+// PeerStoreOccupancy describes the contents of the peer store. Peers are
+// bucketed by reachability: New peers have never been reached, Tried peers
+// have been reached before and have an address that is not currently failing,
+// and Failing peers only have addresses whose last dial failed.
+type PeerStoreOccupancy struct {
+	Peers     int
+	Addresses int
+
+	New     int
+	Tried   int
+	Failing int
+}
+
+// Occupancy returns the current occupancy of the peer store.
+func (m *PeerManager) Occupancy() PeerStoreOccupancy {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	var o PeerStoreOccupancy
+	for _, peer := range m.store.Ranked() {
+		o.Peers++
+		o.Addresses += len(peer.AddressInfo)
+		switch {
+		case peer.isFailing():
+			o.Failing++
+		case peer.lastReached().IsZero():
+			o.New++
+		default:
+			o.Tried++
+		}
+	}
+	return o
+}
+
+// GarbageCollect drops the unreachable and stale addresses, as given by
+// MaxAddressFailures and StaleAddressTime, of the peers that are neither
+// persistent, connected nor being dialed, and deletes the peers left without
+// any address. It then prunes the peer store down to MaxPeers. It returns the
+// number of addresses dropped and of peers deleted.
+func (m *PeerManager) GarbageCollect() (addresses int, peers int, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := time.Now()
+	for _, peer := range m.store.List() {
+		if peer.Persistent || m.connected[peer.ID] || m.dialing[peer.ID] {
+			continue
+		}
+
+		kept := make(map[NodeAddress]*peerAddressInfo, len(peer.AddressInfo))
+		for address, addressInfo := range peer.AddressInfo {
+			if m.isGarbage(addressInfo, now) {
+				addresses++
+				continue
+			}
+			kept[address] = addressInfo
+		}
+		if len(kept) == len(peer.AddressInfo) {
+			continue
+		}
+
+		if len(kept) == 0 {
+			if err := m.store.Delete(peer.ID); err != nil {
+				return addresses, peers, err
+			}
+			peers++
+			continue
+		}
+		peer.AddressInfo = kept
+		if err := m.store.Set(peer); err != nil {
+			return addresses, peers, err
+		}
+	}
+
+	pruned, err := m.prunePeers()
+	m.dials.forget(func(address NodeAddress) bool {
+		peer, ok := m.store.peers[address.NodeID]
+		if !ok {
+			return false
+		}
+		_, ok = peer.AddressInfo[address]
+		return ok
+	})
+	return addresses, peers + pruned, err
+}
+
+// isGarbage returns whether GarbageCollect() should drop the address.
+func (m *PeerManager) isGarbage(addressInfo *peerAddressInfo, now time.Time) bool {
+	if addressInfo.DialFailures == 0 {
+		return false
+	}
+	if m.options.MaxAddressFailures > 0 && addressInfo.DialFailures >= m.options.MaxAddressFailures {
+		return true
+	}
+	return m.options.StaleAddressTime > 0 && !addressInfo.LastDialSuccess.IsZero() &&
+		now.Sub(addressInfo.LastDialSuccess) > m.options.StaleAddressTime
+}
+
 // DialNext finds an appropriate peer address to dial, and marks it as dialing.
 // If no peer is found, or all connection slots are full, it blocks until one
 // becomes available. The caller must call Dialed() or DialFailed() for the
@@ -460,13 +730,31 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 		return NodeAddress{}, nil
 	}
 
+	// The dial scheduler limits the dials in progress, and spaces them.
+	now := time.Now()
+	if wait := m.dials.wait(now); wait != 0 {
+		if wait > 0 && !m.dials.wakeupPending {
+			m.dials.wakeupPending = true
+			time.AfterFunc(wait, func() {
+				m.mtx.Lock()
+				m.dials.wakeupPending = false
+				m.mtx.Unlock()
+				m.dialWaker.Wake()
+			})
+		}
+		return NodeAddress{}, nil
+	}
+
 	for _, peer := range m.store.Ranked() {
 		if m.dialing[peer.ID] || m.connected[peer.ID] {
 			continue
 		}
 
 		for _, addressInfo := range peer.AddressInfo {
-			if time.Since(addressInfo.LastDialFailure) < m.retryDelay(addressInfo.DialFailures, peer.Persistent) {
+			if !m.canRetry(addressInfo, peer.Persistent, now) {
+				continue
+			}
+			if m.dials.subnetFull(addressInfo.Address) {
 				continue
 			}
 
@@ -486,12 +774,33 @@ func (m *PeerManager) TryDialNext() (NodeAddress, error) {
 			}
 
 			m.dialing[peer.ID] = true
+			m.dials.start(addressInfo.Address, now)
 			return addressInfo.Address, nil
 		}
 	}
 	return NodeAddress{}, nil
 }
 
+// canRetry returns whether the address can be dialed at now, after its last
+// failed dials. The retry time of an address, with its jitter, is drawn once
+// per failure. The caller must hold the mutex lock.
+func (m *PeerManager) canRetry(addressInfo *peerAddressInfo, persistent bool, now time.Time) bool {
+	if addressInfo.DialFailures == 0 {
+		return true
+	}
+	retryAt, ok := m.dials.retryAt[addressInfo.Address]
+	if !ok {
+		// The address failed before a restart.
+		d := m.retryDelay(addressInfo.DialFailures, persistent)
+		if d == retryNever {
+			return false
+		}
+		retryAt = addressInfo.LastDialFailure.Add(d)
+		m.dials.setRetry(addressInfo.Address, retryAt)
+	}
+	return !now.Before(retryAt)
+}
+
 // DialFailed reports a failed dial attempt. This will make the peer available
 // for dialing again when appropriate (possibly after a retry timeout).
 //
@@ -501,6 +810,7 @@ func (m *PeerManager) DialFailed(ctx context.Context, address NodeAddress) error
 	defer m.mtx.Unlock()
 
 	delete(m.dialing, address.NodeID)
+	m.dials.finish(address)
 	for from, to := range m.upgrading {
 		if to == address.NodeID {
 			delete(m.upgrading, from) // Unmark failed upgrade attempt.
@@ -525,8 +835,14 @@ func (m *PeerManager) DialFailed(ctx context.Context, address NodeAddress) error
 	// We spawn a goroutine that notifies DialNext() again when the retry
 	// timeout has elapsed, so that we can consider dialing it again. We
 	// calculate the retry delay outside the goroutine, since it must hold
-	// the mutex lock.
-	if d := m.retryDelay(addressInfo.DialFailures, peer.Persistent); d != 0 && d != retryNever {
+	// the mutex lock. DialNext() is notified right away too, since the dial
+	// slot is free.
+	d := m.retryDelay(addressInfo.DialFailures, peer.Persistent)
+	if d != retryNever {
+		m.dials.setRetry(address, addressInfo.LastDialFailure.Add(d))
+	}
+	m.dialWaker.Wake()
+	if d != 0 && d != retryNever {
 		go func() {
 			// Use an explicit timer with deferred cleanup instead of
 			// time.After(), to avoid leaking goroutines on PeerManager.Close().
@@ -538,8 +854,6 @@ func (m *PeerManager) DialFailed(ctx context.Context, address NodeAddress) error
 			case <-ctx.Done():
 			}
 		}()
-	} else {
-		m.dialWaker.Wake()
 	}
 
 	return nil
@@ -552,6 +866,9 @@ func (m *PeerManager) Dialed(address NodeAddress) error {
 	defer m.mtx.Unlock()
 
 	delete(m.dialing, address.NodeID)
+	m.dials.finish(address)
+	m.dials.setRetry(address, time.Time{})
+	m.dialWaker.Wake()
 
 	var upgradeFromPeer types.NodeID
 	for from, to := range m.upgrading {
@@ -678,16 +995,17 @@ func (m *PeerManager) Accepted(peerID types.NodeID) error {
 // peer must already be marked as connected. This is separate from Dialed() and
 // Accepted() to allow the router to set up its internal queues before reactors
 // start sending messages.
-func (m *PeerManager) Ready(ctx context.Context, peerID types.NodeID) {
+func (m *PeerManager) Ready(ctx context.Context, peerID types.NodeID, channels ChannelIDSet) {
 	m.mtx.Lock()
 	defer m.mtx.Unlock()
 
 	if m.connected[peerID] {
 		m.ready[peerID] = true
+		m.channels[peerID] = channels
 		m.broadcast(ctx, PeerUpdate{
 			NodeID: peerID,
 			Status: PeerStatusUp,
-		})
+		}, channels)
 	}
 }
 
@@ -750,18 +1068,21 @@ func (m *PeerManager) Disconnected(ctx context.Context, peerID types.NodeID) {
 	defer m.mtx.Unlock()
 
 	ready := m.ready[peerID]
+	channels := m.channels[peerID]
 
 	delete(m.connected, peerID)
 	delete(m.upgrading, peerID)
 	delete(m.evict, peerID)
 	delete(m.evicting, peerID)
 	delete(m.ready, peerID)
+	delete(m.channels, peerID)
+	m.updateReputation(peerID)
 
 	if ready {
 		m.broadcast(ctx, PeerUpdate{
 			NodeID: peerID,
 			Status: PeerStatusDown,
-		})
+		}, channels)
 	}
 
 	m.dialWaker.Wake()
@@ -837,6 +1158,16 @@ func (m *PeerManager) Subscribe(ctx context.Context) *PeerUpdates {
 	return peerUpdates
 }
 
+// SubscribeChannel is equivalent to Subscribe(), but only notifies the status
+// changes of the peers serving the channel chID, for the reactors of the
+// channel.
+func (m *PeerManager) SubscribeChannel(ctx context.Context, chID ChannelID) *PeerUpdates {
+	peerUpdates := NewPeerUpdates(make(chan PeerUpdate, 1), 1)
+	peerUpdates.channel = &chID
+	m.Register(ctx, peerUpdates)
+	return peerUpdates
+}
+
 // Register allows you to inject a custom PeerUpdate instance into the
 // PeerManager, rather than relying on the instance constructed by the
 // Subscribe method, which wraps the functionality of the Register
@@ -886,7 +1217,11 @@ func (m *PeerManager) processPeerEvent(ctx context.Context, pu PeerUpdate) {
 		m.store.peers[pu.NodeID].MutableScore--
 	case PeerStatusGood:
 		m.store.peers[pu.NodeID].MutableScore++
+	default:
+		return
 	}
+	// The score is kept across restarts with the statistics of the peer.
+	m.stats.RecordScore(pu.NodeID, m.store.peers[pu.NodeID].MutableScore)
 }
 
 // broadcast broadcasts a peer update to all subscriptions. The caller must
@@ -896,11 +1231,14 @@ func (m *PeerManager) processPeerEvent(ctx context.Context, pu PeerUpdate) {
 //
 // FIXME: Consider using an internal channel to buffer updates while also
 // maintaining order if this is a problem.
-func (m *PeerManager) broadcast(ctx context.Context, peerUpdate PeerUpdate) {
+func (m *PeerManager) broadcast(ctx context.Context, peerUpdate PeerUpdate, channels ChannelIDSet) {
 	for _, sub := range m.subscriptions {
 		if ctx.Err() != nil {
 			return
 		}
+		if sub.channel != nil && !channels.Contains(*sub.channel) {
+			continue
+		}
 		select {
 		case <-ctx.Done():
 			return
@@ -1014,6 +1352,16 @@ func (m *PeerManager) retryDelay(failures uint32, persistent bool) time.Duration
 	return delay
 }
 
+// Stats returns the store of lifetime peer statistics.
+func (m *PeerManager) Stats() *PeerStatsStore {
+	return m.stats
+}
+
+// Maintenance returns the maintenance windows announced by peers.
+func (m *PeerManager) Maintenance() *PeerMaintenance {
+	return m.maintenance
+}
+
 // GetHeight returns a peer's height, as reported via SetHeight, or 0 if the
 // peer or height is unknown.
 //
@@ -1209,7 +1557,8 @@ type peerInfo struct {
 	Height     int64
 	FixedScore PeerScore // mainly for tests
 
-	MutableScore int64 // updated by router
+	MutableScore int64 // updated by router, persisted with the peer statistics
+	Reputation   int64 // from the peer statistics
 }
 
 // peerInfoFromProto converts a Protobuf PeerInfo message to a peerInfo,
@@ -1273,7 +1622,7 @@ func (p *peerInfo) Score() PeerScore {
 		return PeerScorePersistent
 	}
 
-	score := p.MutableScore
+	score := p.MutableScore + p.Reputation
 
 	for _, addr := range p.AddressInfo {
 		// DialFailures is reset when dials succeed, so this
@@ -1292,6 +1641,31 @@ func (p *peerInfo) Score() PeerScore {
 	return PeerScore(score)
 }
 
+// lastReached returns the last time we connected to the peer or dialed any of
+// its addresses successfully, or zero if we never did.
+func (p *peerInfo) lastReached() time.Time {
+	last := p.LastConnected
+	for _, addressInfo := range p.AddressInfo {
+		if addressInfo.LastDialSuccess.After(last) {
+			last = addressInfo.LastDialSuccess
+		}
+	}
+	return last
+}
+
+// isFailing returns whether the last dial of every address of the peer failed.
+func (p *peerInfo) isFailing() bool {
+	if len(p.AddressInfo) == 0 {
+		return false
+	}
+	for _, addressInfo := range p.AddressInfo {
+		if addressInfo.DialFailures == 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Validate validates the peer info.
 func (p *peerInfo) Validate() error {
 	if p.ID == "" {
@@ -267,6 +267,124 @@ func TestPeerManager_Add(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestPeerManager_Add_MaxAddressesPerPeer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	aID := types.NodeID(strings.Repeat("a", 40))
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		MaxAddressesPerPeer: 2,
+	})
+	require.NoError(t, err)
+
+	a1 := p2p.NodeAddress{Protocol: "tcp", NodeID: aID, Hostname: "127.0.0.1"}
+	a2 := p2p.NodeAddress{Protocol: "tcp", NodeID: aID, Hostname: "127.0.0.2"}
+	a3 := p2p.NodeAddress{Protocol: "tcp", NodeID: aID, Hostname: "127.0.0.3"}
+	for _, addr := range []p2p.NodeAddress{a1, a2} {
+		added, err := peerManager.Add(addr)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	// With all addresses reachable, a new address is ignored.
+	added, err := peerManager.Add(a3)
+	require.NoError(t, err)
+	require.False(t, added)
+	require.ElementsMatch(t, []p2p.NodeAddress{a1, a2}, peerManager.Addresses(aID))
+
+	// Once one fails, it makes room for the new address.
+	require.NoError(t, peerManager.DialFailed(ctx, a1))
+	added, err = peerManager.Add(a3)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.ElementsMatch(t, []p2p.NodeAddress{a2, a3}, peerManager.Addresses(aID))
+}
+
+func TestPeerManager_GarbageCollect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	aID := types.NodeID(strings.Repeat("a", 40))
+	bID := types.NodeID(strings.Repeat("b", 40))
+	cID := types.NodeID(strings.Repeat("c", 40))
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		PersistentPeers:    []types.NodeID{cID},
+		MaxAddressFailures: 2,
+	})
+	require.NoError(t, err)
+
+	aMemory := p2p.NodeAddress{Protocol: "memory", NodeID: aID}
+	aTCP := p2p.NodeAddress{Protocol: "tcp", NodeID: aID, Hostname: "localhost"}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: bID}
+	c := p2p.NodeAddress{Protocol: "memory", NodeID: cID}
+	for _, addr := range []p2p.NodeAddress{aMemory, aTCP, b, c} {
+		added, err := peerManager.Add(addr)
+		require.NoError(t, err)
+		require.True(t, added)
+	}
+
+	// Fail all addresses but a's TCP address twice.
+	for _, addr := range []p2p.NodeAddress{aMemory, b, c} {
+		require.NoError(t, peerManager.DialFailed(ctx, addr))
+		require.NoError(t, peerManager.DialFailed(ctx, addr))
+	}
+	require.Equal(t, p2p.PeerStoreOccupancy{
+		Peers: 3, Addresses: 4, New: 1, Failing: 2,
+	}, peerManager.Occupancy())
+
+	// a's memory address is dropped, and b is deleted since it has no address
+	// left. c is persistent, so it is kept.
+	addresses, peers, err := peerManager.GarbageCollect()
+	require.NoError(t, err)
+	require.Equal(t, 2, addresses)
+	require.Equal(t, 1, peers)
+	require.ElementsMatch(t, []types.NodeID{aID, cID}, peerManager.Peers())
+	require.Equal(t, []p2p.NodeAddress{aTCP}, peerManager.Addresses(aID))
+	require.Equal(t, p2p.PeerStoreOccupancy{
+		Peers: 2, Addresses: 2, New: 1, Failing: 1,
+	}, peerManager.Occupancy())
+
+	// Collecting again is a noop.
+	addresses, peers, err = peerManager.GarbageCollect()
+	require.NoError(t, err)
+	require.Zero(t, addresses)
+	require.Zero(t, peers)
+}
+
+func TestPeerManager_GarbageCollect_Stale(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{
+		StaleAddressTime: time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+
+	// a is reached once, and then fails.
+	dial, err := peerManager.TryDialNext()
+	require.NoError(t, err)
+	require.Equal(t, a, dial)
+	require.NoError(t, peerManager.Dialed(a))
+	peerManager.Disconnected(ctx, a.NodeID)
+	require.NoError(t, peerManager.DialFailed(ctx, a))
+	require.Equal(t, p2p.PeerStoreOccupancy{
+		Peers: 1, Addresses: 1, Failing: 1,
+	}, peerManager.Occupancy())
+
+	time.Sleep(5 * time.Millisecond)
+	addresses, peers, err := peerManager.GarbageCollect()
+	require.NoError(t, err)
+	require.Equal(t, 1, addresses)
+	require.Equal(t, 1, peers)
+	require.Empty(t, peerManager.Peers())
+}
+
 func TestPeerManager_DialNext(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -1311,7 +1429,7 @@ func TestPeerManager_Ready(t *testing.T) {
 	require.Equal(t, p2p.PeerStatusDown, peerManager.Status(a.NodeID))
 
 	// Marking a as ready should transition it to PeerStatusUp and send an update.
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 	require.Equal(t, p2p.PeerStatusUp, peerManager.Status(a.NodeID))
 	require.Equal(t, p2p.PeerUpdate{
 		NodeID: a.NodeID,
@@ -1323,7 +1441,7 @@ func TestPeerManager_Ready(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.Equal(t, p2p.PeerStatusDown, peerManager.Status(b.NodeID))
-	peerManager.Ready(ctx, b.NodeID)
+	peerManager.Ready(ctx, b.NodeID, nil)
 	require.Equal(t, p2p.PeerStatusDown, peerManager.Status(b.NodeID))
 	require.Empty(t, sub.Updates())
 }
@@ -1342,7 +1460,7 @@ func TestPeerManager_EvictNext(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 
 	// Since there are no peers to evict, EvictNext should block until timeout.
 	timeoutCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
@@ -1378,7 +1496,7 @@ func TestPeerManager_EvictNext_WakeOnError(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 
 	// Spawn a goroutine to error a peer after a delay.
 	go func() {
@@ -1413,7 +1531,7 @@ func TestPeerManager_EvictNext_WakeOnUpgradeDialed(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 
 	// Spawn a goroutine to upgrade to b with a delay.
 	go func() {
@@ -1454,7 +1572,7 @@ func TestPeerManager_EvictNext_WakeOnUpgradeAccepted(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 
 	// Spawn a goroutine to upgrade b with a delay.
 	go func() {
@@ -1489,7 +1607,7 @@ func TestPeerManager_TryEvictNext(t *testing.T) {
 
 	// Connecting to a won't evict anything either.
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 
 	// But if a errors it should be evicted.
 	peerManager.Errored(a.NodeID, errors.New("foo"))
@@ -1536,7 +1654,7 @@ func TestPeerManager_Disconnected(t *testing.T) {
 	_, err = peerManager.Add(a)
 	require.NoError(t, err)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 	require.Equal(t, p2p.PeerStatusUp, peerManager.Status(a.NodeID))
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{
@@ -1591,7 +1709,7 @@ func TestPeerManager_Errored(t *testing.T) {
 	require.Zero(t, evict)
 
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 	evict, err = peerManager.TryEvictNext()
 	require.NoError(t, err)
 	require.Zero(t, evict)
@@ -1624,7 +1742,7 @@ func TestPeerManager_Subscribe(t *testing.T) {
 	require.NoError(t, peerManager.Accepted(a.NodeID))
 	require.Empty(t, sub.Updates())
 
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}, <-sub.Updates())
 
@@ -1641,7 +1759,7 @@ func TestPeerManager_Subscribe(t *testing.T) {
 	require.NoError(t, peerManager.Dialed(a))
 	require.Empty(t, sub.Updates())
 
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}, <-sub.Updates())
 
@@ -1683,7 +1801,7 @@ func TestPeerManager_Subscribe_Close(t *testing.T) {
 	require.NoError(t, peerManager.Accepted(a.NodeID))
 	require.Empty(t, sub.Updates())
 
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 	require.NotEmpty(t, sub.Updates())
 	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}, <-sub.Updates())
 
@@ -1716,7 +1834,7 @@ func TestPeerManager_Subscribe_Broadcast(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, added)
 	require.NoError(t, peerManager.Accepted(a.NodeID))
-	peerManager.Ready(ctx, a.NodeID)
+	peerManager.Ready(ctx, a.NodeID, nil)
 
 	expectUp := p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}
 	require.NotEmpty(t, s1)
@@ -1740,6 +1858,52 @@ func TestPeerManager_Subscribe_Broadcast(t *testing.T) {
 	require.Equal(t, expectDown, <-s3.Updates())
 }
 
+func TestPeerManager_SubscribeChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("a", 40))}
+	b := p2p.NodeAddress{Protocol: "memory", NodeID: types.NodeID(strings.Repeat("b", 40))}
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+
+	sub := peerManager.SubscribeChannel(ctx, 0x30)
+	all := peerManager.Subscribe(ctx)
+
+	// Peer a opts out of channel 0x30, so only the subscription to all
+	// peers is notified of it.
+	added, err := peerManager.Add(a)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.NoError(t, peerManager.Accepted(a.NodeID))
+	peerManager.Ready(ctx, a.NodeID, p2p.NewChannelIDSet([]byte{0x20, 0x40}))
+
+	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusUp}, <-all.Updates())
+	require.Empty(t, sub.Updates())
+
+	// Peer b serves channel 0x30, so both subscriptions are notified of it
+	// coming up and going down.
+	added, err = peerManager.Add(b)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.NoError(t, peerManager.Accepted(b.NodeID))
+	peerManager.Ready(ctx, b.NodeID, p2p.NewChannelIDSet([]byte{0x30}))
+
+	expectUp := p2p.PeerUpdate{NodeID: b.NodeID, Status: p2p.PeerStatusUp}
+	require.Equal(t, expectUp, <-all.Updates())
+	require.Equal(t, expectUp, <-sub.Updates())
+
+	peerManager.Disconnected(ctx, a.NodeID)
+	require.Equal(t, p2p.PeerUpdate{NodeID: a.NodeID, Status: p2p.PeerStatusDown}, <-all.Updates())
+	require.Empty(t, sub.Updates())
+
+	peerManager.Disconnected(ctx, b.NodeID)
+	expectDown := p2p.PeerUpdate{NodeID: b.NodeID, Status: p2p.PeerStatusDown}
+	require.Equal(t, expectDown, <-all.Updates())
+	require.Equal(t, expectDown, <-sub.Updates())
+}
+
 func TestPeerManager_Close(t *testing.T) {
 	// leaktest will check that spawned goroutines are closed.
 	t.Cleanup(leaktest.CheckTimeout(t, 1*time.Second))
@@ -0,0 +1,485 @@
+package p2p
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/orderedcode"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// prefixPeerStats is the key prefix for persisted peer statistics, stored
+	// alongside the peer store in the peer database.
+	prefixPeerStats int64 = 2
+
+	// peerStatsPersistInterval is the interval at which the byte counters of
+	// the peers are written to disk. Other statistics are written as soon as
+	// they change.
+	peerStatsPersistInterval = time.Minute
+
+	// maxPeerStats is the maximum number of peers to keep statistics for. The
+	// statistics of the peers disconnected the longest are pruned first.
+	maxPeerStats = 4096
+)
+
+// PeerStats contains the lifetime statistics of a peer, accumulated across
+// connections and node restarts.
+type PeerStats struct {
+	NodeID           types.NodeID `json:"node_id"`
+	FirstConnected   time.Time    `json:"first_connected"`
+	LastConnected    time.Time    `json:"last_connected"`
+	LastDisconnected time.Time    `json:"last_disconnected"`
+	Connects         uint64       `json:"connects"`
+	BytesSent        uint64       `json:"bytes_sent"`
+	BytesReceived    uint64       `json:"bytes_received"`
+	BlocksDelivered  uint64       `json:"blocks_delivered"`
+	Misbehaviors     uint64       `json:"misbehaviors"`
+
+	// ConnectedTime is the time spent connected to the peer, up to the last
+	// disconnection.
+	ConnectedTime time.Duration `json:"connected_time"`
+
+	// Latency is the moving average of the durations of the handshakes with
+	// the peer, which take a few round trips.
+	Latency time.Duration `json:"latency"`
+
+	// Score is the score of the peer from the reports of the reactors, kept
+	// across restarts.
+	Score int64 `json:"score"`
+}
+
+// Reputation bonuses and penalties of the peers, added to their scores.
+const (
+	// A peer earns a point per hour it was connected, up to maxUptimeBonus.
+	uptimeBonusPeriod = time.Hour
+	maxUptimeBonus    = 24
+
+	// Peers with an average handshake latency below lowLatency earn
+	// lowLatencyBonus points, and below mediumLatency mediumLatencyBonus.
+	lowLatency         = 100 * time.Millisecond
+	lowLatencyBonus    = 8
+	mediumLatency      = 500 * time.Millisecond
+	mediumLatencyBonus = 4
+
+	// A peer loses misbehaviorPenalty points per misbehavior.
+	misbehaviorPenalty = 4
+)
+
+// Uptime returns the time spent connected to the peer at the time now,
+// including the current connection if connected is set.
+func (s PeerStats) Uptime(connected bool, now time.Time) time.Duration {
+	uptime := s.ConnectedTime
+	if connected && now.After(s.LastConnected) {
+		uptime += now.Sub(s.LastConnected)
+	}
+	return uptime
+}
+
+// Reputation returns the points the peer earned or lost from its statistics:
+// its uptime, its latency and its misbehaviors.
+func (s PeerStats) Reputation() int64 {
+	reputation := int64(s.ConnectedTime / uptimeBonusPeriod)
+	if reputation > maxUptimeBonus {
+		reputation = maxUptimeBonus
+	}
+	switch {
+	case s.Latency == 0:
+	case s.Latency < lowLatency:
+		reputation += lowLatencyBonus
+	case s.Latency < mediumLatency:
+		reputation += mediumLatencyBonus
+	}
+	return reputation - int64(s.Misbehaviors)*misbehaviorPenalty
+}
+
+type peerStatsEntry struct {
+	// The byte counters are updated with atomics, without holding the lock
+	// of the store, as they change with every message. They come first for
+	// the 64-bit alignment required by atomics on 32-bit platforms.
+	bytesSent     uint64
+	bytesReceived uint64
+
+	stats     PeerStats
+	connected bool
+	// touched is the time of the last update of stats, other than the byte
+	// counters.
+	touched time.Time
+	dirty   bool
+	// persistedSent and persistedReceived are the byte counters last written
+	// to disk.
+	persistedSent     uint64
+	persistedReceived uint64
+}
+
+// snapshot returns the statistics of the peer with its byte counters.
+func (e *peerStatsEntry) snapshot() PeerStats {
+	stats := e.stats
+	stats.BytesSent = atomic.LoadUint64(&e.bytesSent)
+	stats.BytesReceived = atomic.LoadUint64(&e.bytesReceived)
+	return stats
+}
+
+/*
+PeerStatsStore keeps lifetime statistics for every peer the node has been
+connected to, persisting them in the peer database. It is safe for concurrent
+use.
+
+Updates only change the statistics in memory, and the statistics are written
+to disk by Flush, which should be called whenever Changed is signaled and at
+least every peerStatsPersistInterval. The byte counters do not signal Changed.
+
+The store keeps statistics for up to maxPeerStats peers, pruning those of the
+peers disconnected the longest to make room for new ones.
+*/
+type PeerStatsStore struct {
+	db      dbm.DB
+	now     func() time.Time
+	changed chan struct{}
+
+	mtx      sync.RWMutex
+	peers    map[types.NodeID]*peerStatsEntry
+	pruned   map[types.NodeID]bool // pruned peers to delete from disk
+	flushMtx sync.Mutex            // serializes Flush
+}
+
+// newPeerStatsStore creates a peer statistics store, loading all persisted
+// statistics from the database into memory.
+func newPeerStatsStore(db dbm.DB) (*PeerStatsStore, error) {
+	if db == nil {
+		return nil, errors.New("no database provided")
+	}
+
+	s := &PeerStatsStore{
+		db:      db,
+		now:     time.Now,
+		changed: make(chan struct{}, 1),
+		peers:   make(map[types.NodeID]*peerStatsEntry),
+		pruned:  make(map[types.NodeID]bool),
+	}
+
+	start, end := keyPeerStatsRange()
+	iter, err := db.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	for ; iter.Valid(); iter.Next() {
+		var stats PeerStats
+		if err := json.Unmarshal(iter.Value(), &stats); err != nil {
+			return nil, fmt.Errorf("invalid peer statistics: %w", err)
+		}
+		touched := stats.LastConnected
+		if stats.LastDisconnected.After(touched) {
+			touched = stats.LastDisconnected
+		}
+		s.peers[stats.NodeID] = &peerStatsEntry{
+			bytesSent:         stats.BytesSent,
+			bytesReceived:     stats.BytesReceived,
+			stats:             stats,
+			touched:           touched,
+			persistedSent:     stats.BytesSent,
+			persistedReceived: stats.BytesReceived,
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	// Prune the statistics in excess of maxPeerStats, e.g. if it was lowered.
+	for len(s.peers) > maxPeerStats {
+		s.prune()
+	}
+
+	return s, nil
+}
+
+// Get returns the statistics of the given peer, if any.
+func (s *PeerStatsStore) Get(id types.NodeID) (PeerStats, bool) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	entry, ok := s.peers[id]
+	if !ok {
+		return PeerStats{}, false
+	}
+	return entry.snapshot(), true
+}
+
+// List returns the statistics of all known peers, ordered by node ID.
+func (s *PeerStatsStore) List() []PeerStats {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	out := make([]PeerStats, 0, len(s.peers))
+	for _, entry := range s.peers {
+		out = append(out, entry.snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// Changed returns a channel signaled when statistics to write to disk at once
+// changed.
+func (s *PeerStatsStore) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// RecordConnected records a new connection to the peer.
+func (s *PeerStatsStore) RecordConnected(id types.NodeID) {
+	s.update(id, true, func(entry *peerStatsEntry, now time.Time) {
+		stats := &entry.stats
+		if stats.FirstConnected.IsZero() {
+			stats.FirstConnected = now
+		}
+		stats.LastConnected = now
+		stats.Connects++
+		entry.connected = true
+	})
+}
+
+// RecordDisconnected records the end of a connection to the peer.
+func (s *PeerStatsStore) RecordDisconnected(id types.NodeID) {
+	s.update(id, true, func(entry *peerStatsEntry, now time.Time) {
+		stats := &entry.stats
+		if !stats.LastConnected.IsZero() && now.After(stats.LastConnected) &&
+			stats.LastDisconnected.Before(stats.LastConnected) {
+			stats.ConnectedTime += now.Sub(stats.LastConnected)
+		}
+		stats.LastDisconnected = now
+		entry.connected = false
+	})
+}
+
+// RecordLatency records the duration d of a handshake with the peer.
+func (s *PeerStatsStore) RecordLatency(id types.NodeID, d time.Duration) {
+	s.update(id, false, func(entry *peerStatsEntry, _ time.Time) {
+		if entry.stats.Latency == 0 {
+			entry.stats.Latency = d
+		} else {
+			entry.stats.Latency = (3*entry.stats.Latency + d) / 4
+		}
+	})
+}
+
+// RecordScore records the score of the peer from the reports of the
+// reactors.
+func (s *PeerStatsStore) RecordScore(id types.NodeID, score int64) {
+	s.update(id, false, func(entry *peerStatsEntry, _ time.Time) {
+		entry.stats.Score = score
+	})
+}
+
+// RecordMisbehavior records a protocol error reported for the peer.
+func (s *PeerStatsStore) RecordMisbehavior(id types.NodeID) {
+	s.update(id, true, func(entry *peerStatsEntry, _ time.Time) {
+		entry.stats.Misbehaviors++
+	})
+}
+
+// RecordBlockDelivered records that a block received from the peer was
+// verified and applied.
+func (s *PeerStatsStore) RecordBlockDelivered(id types.NodeID) {
+	s.update(id, false, func(entry *peerStatsEntry, _ time.Time) {
+		entry.stats.BlocksDelivered++
+	})
+}
+
+// AddBytesSent adds n to the number of bytes sent to the peer.
+func (s *PeerStatsStore) AddBytesSent(id types.NodeID, n int) {
+	atomic.AddUint64(&s.entry(id).bytesSent, uint64(n))
+}
+
+// AddBytesReceived adds n to the number of bytes received from the peer.
+func (s *PeerStatsStore) AddBytesReceived(id types.NodeID, n int) {
+	atomic.AddUint64(&s.entry(id).bytesReceived, uint64(n))
+}
+
+// Flush writes the changed statistics to disk, and deletes those of the
+// pruned peers.
+func (s *PeerStatsStore) Flush() error {
+	s.flushMtx.Lock()
+	defer s.flushMtx.Unlock()
+
+	// The statistics are marshaled and written without holding the lock, to
+	// not block the updates.
+	s.mtx.Lock()
+	var (
+		entries = make([]*peerStatsEntry, 0, len(s.peers))
+		changed = make([]PeerStats, 0, len(s.peers))
+		pruned  = make([]types.NodeID, 0, len(s.pruned))
+	)
+	for _, entry := range s.peers {
+		stats := entry.snapshot()
+		if entry.dirty || stats.BytesSent != entry.persistedSent ||
+			stats.BytesReceived != entry.persistedReceived {
+			entry.dirty = false
+			entries = append(entries, entry)
+			changed = append(changed, stats)
+		}
+	}
+	for id := range s.pruned {
+		pruned = append(pruned, id)
+	}
+	s.pruned = make(map[types.NodeID]bool)
+	s.mtx.Unlock()
+
+	err := s.write(changed, pruned)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err != nil {
+		// Try again with the next flush.
+		for _, entry := range entries {
+			entry.dirty = true
+		}
+		for _, id := range pruned {
+			if _, ok := s.peers[id]; !ok {
+				s.pruned[id] = true
+			}
+		}
+		return err
+	}
+	for i, entry := range entries {
+		entry.persistedSent = changed[i].BytesSent
+		entry.persistedReceived = changed[i].BytesReceived
+	}
+	return nil
+}
+
+// write writes the changed statistics, and deletes those of the pruned peers,
+// in a single batch.
+func (s *PeerStatsStore) write(changed []PeerStats, pruned []types.NodeID) error {
+	if len(changed) == 0 && len(pruned) == 0 {
+		return nil
+	}
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+
+	for _, stats := range changed {
+		bz, err := json.Marshal(stats)
+		if err != nil {
+			return err
+		}
+		if err := batch.Set(keyPeerStats(stats.NodeID), bz); err != nil {
+			return err
+		}
+	}
+	for _, id := range pruned {
+		if err := batch.Delete(keyPeerStats(id)); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// entry returns the entry of the peer, creating it if needed.
+func (s *PeerStatsStore) entry(id types.NodeID) *peerStatsEntry {
+	s.mtx.RLock()
+	entry, ok := s.peers[id]
+	s.mtx.RUnlock()
+	if ok {
+		return entry
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.getOrAdd(id)
+}
+
+// getOrAdd returns the entry of the peer, creating it if needed and pruning
+// the statistics of another peer if the store is full. The caller must hold
+// the lock.
+func (s *PeerStatsStore) getOrAdd(id types.NodeID) *peerStatsEntry {
+	if entry, ok := s.peers[id]; ok {
+		return entry
+	}
+	if len(s.peers) >= maxPeerStats {
+		s.prune()
+	}
+
+	entry := &peerStatsEntry{stats: PeerStats{NodeID: id}, touched: s.now()}
+	s.peers[id] = entry
+	delete(s.pruned, id)
+	return entry
+}
+
+// prune removes the statistics of the disconnected peer updated the longest
+// ago, or of any peer if all are connected. The caller must hold the lock.
+func (s *PeerStatsStore) prune() {
+	var (
+		oldestID types.NodeID
+		oldest   *peerStatsEntry
+	)
+	for id, entry := range s.peers {
+		switch {
+		case oldest == nil:
+		case entry.connected != oldest.connected:
+			if entry.connected {
+				continue
+			}
+		case !entry.touched.Before(oldest.touched):
+			continue
+		}
+		oldestID, oldest = id, entry
+	}
+	if oldest == nil {
+		return
+	}
+
+	delete(s.peers, oldestID)
+	s.pruned[oldestID] = true
+}
+
+// update applies fn to the statistics of the peer. If notify is set, Changed
+// is signaled for the statistics to be written to disk at once.
+func (s *PeerStatsStore) update(id types.NodeID, notify bool, fn func(*peerStatsEntry, time.Time)) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	now := s.now()
+	entry := s.getOrAdd(id)
+	fn(entry, now)
+	entry.touched = now
+	entry.dirty = true
+
+	if notify {
+		select {
+		case s.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// keyPeerStats generates a peer statistics database key.
+func keyPeerStats(id types.NodeID) []byte {
+	key, err := orderedcode.Append(nil, prefixPeerStats, string(id))
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// keyPeerStatsRange generates start/end keys for the entire peer statistics
+// key range.
+func keyPeerStatsRange() ([]byte, []byte) {
+	start, err := orderedcode.Append(nil, prefixPeerStats, "")
+	if err != nil {
+		panic(err)
+	}
+	end, err := orderedcode.Append(nil, prefixPeerStats, orderedcode.Infinity)
+	if err != nil {
+		panic(err)
+	}
+	return start, end
+}
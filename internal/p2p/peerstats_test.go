@@ -0,0 +1,125 @@
+package p2p_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPeerStatsStore_Persistence(t *testing.T) {
+	selfID := types.NodeID("00112233445566778899aabbccddeeff00112233")
+	peerID := types.NodeID("aa112233445566778899aabbccddeeff00112233")
+	db := dbm.NewMemDB()
+
+	peerManager, err := p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+
+	stats := peerManager.Stats()
+	stats.RecordConnected(peerID)
+	stats.AddBytesSent(peerID, 10)
+	stats.AddBytesReceived(peerID, 20)
+	stats.RecordBlockDelivered(peerID)
+	stats.RecordMisbehavior(peerID)
+	stats.RecordDisconnected(peerID)
+	stats.RecordConnected(peerID)
+
+	s, ok := stats.Get(peerID)
+	require.True(t, ok)
+	require.EqualValues(t, 2, s.Connects)
+	require.EqualValues(t, 10, s.BytesSent)
+	require.EqualValues(t, 20, s.BytesReceived)
+	require.EqualValues(t, 1, s.BlocksDelivered)
+	require.EqualValues(t, 1, s.Misbehaviors)
+	require.False(t, s.FirstConnected.IsZero())
+	require.False(t, s.LastDisconnected.IsZero())
+
+	// Statistics are persisted by Flush, which signals them changed.
+	select {
+	case <-stats.Changed():
+	default:
+		require.Fail(t, "expected the statistics to be signaled changed")
+	}
+	stats.AddBytesSent(peerID, 5)
+	require.NoError(t, stats.Flush())
+
+	// Statistics survive a restart, and do not show up as peers.
+	peerManager, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	require.Empty(t, peerManager.Peers())
+
+	reloaded, ok := peerManager.Stats().Get(peerID)
+	require.True(t, ok)
+	require.EqualValues(t, 15, reloaded.BytesSent)
+	require.EqualValues(t, 2, reloaded.Connects)
+	require.True(t, s.FirstConnected.Equal(reloaded.FirstConnected))
+	require.Len(t, peerManager.Stats().List(), 1)
+}
+
+func TestPeerStatsStore_Prune(t *testing.T) {
+	selfID := types.NodeID("00112233445566778899aabbccddeeff00112233")
+	db := dbm.NewMemDB()
+
+	peerManager, err := p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	stats := peerManager.Stats()
+
+	// The statistics of the connected peer are kept, those of the peers
+	// disconnected the longest are pruned.
+	connectedID := types.NodeID(fmt.Sprintf("%040x", 0))
+	stats.RecordConnected(connectedID)
+	const numPeers = 5000
+	for i := 1; i <= numPeers; i++ {
+		id := types.NodeID(fmt.Sprintf("%040x", i))
+		stats.RecordConnected(id)
+		stats.RecordDisconnected(id)
+	}
+	require.NoError(t, stats.Flush())
+
+	list := stats.List()
+	require.Len(t, list, 4096)
+	_, ok := stats.Get(connectedID)
+	require.True(t, ok)
+	_, ok = stats.Get(types.NodeID(fmt.Sprintf("%040x", 1)))
+	require.False(t, ok)
+	_, ok = stats.Get(types.NodeID(fmt.Sprintf("%040x", numPeers)))
+	require.True(t, ok)
+
+	// The pruned statistics are deleted from disk.
+	peerManager, err = p2p.NewPeerManager(selfID, db, p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	reloaded := peerManager.Stats().List()
+	require.Len(t, reloaded, len(list))
+	for i := range list {
+		require.Equal(t, list[i].NodeID, reloaded[i].NodeID)
+	}
+}
+
+func TestPeerStats_Reputation(t *testing.T) {
+	now := time.Now()
+	testcases := map[string]struct {
+		stats      p2p.PeerStats
+		reputation int64
+	}{
+		"new":          {p2p.PeerStats{}, 0},
+		"uptime":       {p2p.PeerStats{ConnectedTime: 5*time.Hour + time.Minute}, 5},
+		"max uptime":   {p2p.PeerStats{ConnectedTime: 1000 * time.Hour}, 24},
+		"low latency":  {p2p.PeerStats{Latency: 50 * time.Millisecond}, 8},
+		"medium":       {p2p.PeerStats{Latency: 200 * time.Millisecond}, 4},
+		"high latency": {p2p.PeerStats{Latency: time.Second}, 0},
+		"misbehaviors": {p2p.PeerStats{Misbehaviors: 2}, -8},
+		"all together": {p2p.PeerStats{ConnectedTime: 2 * time.Hour, Latency: time.Millisecond, Misbehaviors: 1}, 6},
+	}
+	for name, tc := range testcases {
+		require.Equal(t, tc.reputation, tc.stats.Reputation(), name)
+	}
+
+	stats := p2p.PeerStats{ConnectedTime: time.Hour, LastConnected: now.Add(-time.Minute)}
+	require.Equal(t, time.Hour, stats.Uptime(false, now))
+	require.Equal(t, time.Hour+time.Minute, stats.Uptime(true, now))
+}
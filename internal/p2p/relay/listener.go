@@ -0,0 +1,288 @@
+package relay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+)
+
+// Listener accepts the connections relayed by a relay server, on the port
+// the server reserved for the node. It keeps the reservation until it is
+// closed, reconnecting to the server when the connection drops. It implements
+// net.Listener.
+type Listener struct {
+	logger  log.Logger
+	server  string // host:port
+	privKey crypto.PrivKey
+
+	mtx  sync.Mutex
+	port int
+
+	conns  chan net.Conn
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Listen reserves a port on the relay server at the address server (host:port)
+// for the node with the key privKey, and returns the listener of the
+// connections to it. ctx bounds the reservation, and the lifetime of the
+// listener.
+func Listen(ctx context.Context, logger log.Logger, server string, privKey crypto.PrivKey) (*Listener, error) {
+	if _, ok := privKey.(ed25519.PrivKey); !ok {
+		return nil, fmt.Errorf("unsupported node key type %s", privKey.Type())
+	}
+	l := &Listener{
+		logger:  logger,
+		server:  server,
+		privKey: privKey,
+		conns:   make(chan net.Conn),
+		done:    make(chan struct{}),
+	}
+	conn, err := l.reserve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.logger.Info("Reserved a port on the relay", "relay", server, "address", l.Address())
+	go l.run(ctx, conn)
+	return l, nil
+}
+
+// Address returns the address (host:port) of the port reserved on the relay.
+func (l *Listener) Address() string {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	host, _, _ := net.SplitHostPort(l.server)
+	return net.JoinHostPort(host, strconv.Itoa(l.port))
+}
+
+// Addr implements net.Listener.
+func (l *Listener) Addr() net.Addr {
+	return relayAddr(l.Address())
+}
+
+// Accept implements net.Listener.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements net.Listener. It releases the reservation.
+func (l *Listener) Close() error {
+	l.cancel()
+	<-l.done
+	return nil
+}
+
+// reserve dials the server and reserves the port of the node, the same port
+// as before if the node had one. It returns the connection of the
+// reservation.
+func (l *Listener) reserve(ctx context.Context) (net.Conn, error) {
+	conn, challenge, err := l.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := l.privKey.Sign(reservationSignBytes(challenge))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	l.mtx.Lock()
+	port := l.port
+	l.mtx.Unlock()
+	err = writeMessage(conn, message{
+		Type:      msgReserve,
+		PubKey:    l.privKey.PubKey().Bytes(),
+		Signature: sig,
+		Port:      port,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	msg, err := readMessage(conn)
+	if err == nil && msg.Type != msgReserved {
+		err = fmt.Errorf("reservation rejected: %s", msg.Error)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	l.mtx.Lock()
+	l.port = msg.Port
+	l.mtx.Unlock()
+	if port != 0 && msg.Port != port {
+		// Peers learn the new address only after a restart, since the node
+		// info is fixed.
+		l.logger.Error("The relay reserved another port", "relay", l.server, "address", l.Address())
+	}
+	return conn, nil
+}
+
+// dial dials the server, and returns the connection and the challenge of the
+// server.
+func (l *Listener) dial(ctx context.Context) (net.Conn, []byte, error) {
+	d := net.Dialer{Timeout: handshakeTimeout}
+	conn, err := d.DialContext(ctx, "tcp", l.server)
+	if err != nil {
+		return nil, nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	msg, err := readMessage(conn)
+	if err == nil && msg.Type != msgChallenge {
+		err = fmt.Errorf("unexpected message %q", msg.Type)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, msg.Challenge, nil
+}
+
+// run serves the reservation of conn, and reserves the port again when the
+// connection drops, until ctx is done.
+func (l *Listener) run(ctx context.Context, conn net.Conn) {
+	defer close(l.done)
+
+	delay := minReconnectDelay
+	for {
+		err := l.serve(ctx, conn)
+		if ctx.Err() != nil {
+			return
+		}
+		l.logger.Error("Lost the connection to the relay", "relay", l.server, "err", err)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			conn, err = l.reserve(ctx)
+			if err == nil {
+				delay = minReconnectDelay
+				break
+			}
+			l.logger.Error("Failed to reserve a port on the relay", "relay", l.server, "err", err)
+			if delay *= 2; delay > maxReconnectDelay {
+				delay = maxReconnectDelay
+			}
+		}
+	}
+}
+
+// serve reads the messages of the server on conn, pinging the server, until
+// the connection drops or ctx is done.
+func (l *Listener) serve(ctx context.Context, conn net.Conn) error {
+	var writeMtx sync.Mutex
+	write := func(msg message) error {
+		writeMtx.Lock()
+		defer writeMtx.Unlock()
+		return writeMessage(conn, msg)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		ticker := time.NewTicker(pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-stop:
+				conn.Close()
+				return
+			case <-ticker.C:
+				if err := write(message{Type: msgPing}); err != nil {
+					conn.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(pingTimeout))
+		msg, err := readMessage(conn)
+		if err != nil {
+			return err
+		}
+		switch msg.Type {
+		case msgConnect:
+			go l.accept(ctx, msg.Token, msg.Remote)
+		case msgPong:
+		case msgError:
+			return errors.New(msg.Error)
+		default:
+			return fmt.Errorf("unexpected message %q", msg.Type)
+		}
+	}
+}
+
+// accept dials the server back to accept the connection of token, from the
+// peer at remote.
+func (l *Listener) accept(ctx context.Context, token []byte, remote string) {
+	conn, _, err := l.dial(ctx)
+	if err == nil {
+		err = writeMessage(conn, message{Type: msgAccept, Token: token})
+		if err != nil {
+			conn.Close()
+		}
+	}
+	if err != nil {
+		l.logger.Debug("Failed to accept relayed connection", "remote", remote, "err", err)
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	relayed := &relayedConn{Conn: conn}
+	if addr, err := net.ResolveTCPAddr("tcp", remote); err == nil {
+		relayed.remote = addr
+	}
+	select {
+	case l.conns <- relayed:
+	case <-ctx.Done():
+		conn.Close()
+	}
+}
+
+// relayedConn is a connection relayed by the server, whose remote address is
+// that of the peer.
+type relayedConn struct {
+	net.Conn
+	remote *net.TCPAddr
+}
+
+func (c *relayedConn) RemoteAddr() net.Addr {
+	if c.remote == nil {
+		return c.Conn.RemoteAddr()
+	}
+	return c.remote
+}
+
+// relayAddr is the address of a port reserved on a relay.
+type relayAddr string
+
+func (a relayAddr) Network() string { return "tcp" }
+func (a relayAddr) String() string  { return string(a) }
@@ -0,0 +1,109 @@
+// Package relay lets a node behind a NAT accept inbound p2p connections
+// through a public node, its relay.
+//
+// The node reserves a port of the relay server, which it advertises as its
+// address. The relay accepts the connections to the port, and has the node
+// dial back to it for each of them, over its connection of the reservation.
+// It then splices the two connections, so that the p2p handshake runs end to
+// end: the relay only sees encrypted traffic, and can't impersonate the node.
+//
+// The messages of the protocol are JSON objects, each preceded by its length
+// as a big endian uint32. On every connection, the server first sends a
+// challenge, which the node signs with its node key to reserve a port:
+//
+//	server: {"type": "challenge", "challenge": ...}
+//	node:   {"type": "reserve", "pub_key": ..., "signature": ..., "port": ...}
+//	server: {"type": "reserved", "port": ...}
+//	server: {"type": "connect", "token": ..., "remote": ...}
+//	node:   {"type": "ping"}
+//	server: {"type": "pong"}
+//
+// To accept a connection, the node dials the server and answers the challenge
+// with {"type": "accept", "token": ...}, after which the connection carries
+// the bytes of the relayed connection.
+package relay
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	msgChallenge = "challenge"
+	msgReserve   = "reserve"
+	msgReserved  = "reserved"
+	msgConnect   = "connect"
+	msgAccept    = "accept"
+	msgPing      = "ping"
+	msgPong      = "pong"
+	msgError     = "error"
+
+	// maxMessageSize is the maximum size of a message of the protocol.
+	maxMessageSize = 4096
+
+	// pingInterval is the interval of the pings of the nodes keeping their
+	// reservation alive. The server drops the reservations of the nodes not
+	// pinging for pingTimeout.
+	pingInterval = 20 * time.Second
+	pingTimeout  = 3 * pingInterval
+
+	// handshakeTimeout bounds the dial and the exchange of the first
+	// messages on a connection, and the delay of the node to dial back to
+	// accept a connection.
+	handshakeTimeout = 10 * time.Second
+)
+
+// reservationSignBytesPrefix starts the bytes signed by a node to reserve a
+// port, followed by the challenge.
+const reservationSignBytesPrefix = "tendermint/RelayReservation\n"
+
+type message struct {
+	Type      string `json:"type"`
+	Challenge []byte `json:"challenge,omitempty"`
+	PubKey    []byte `json:"pub_key,omitempty"` // ed25519 node key
+	Signature []byte `json:"signature,omitempty"`
+	Port      int    `json:"port,omitempty"`
+	Token     []byte `json:"token,omitempty"`
+	Remote    string `json:"remote,omitempty"` // address of the relayed peer
+	Error     string `json:"error,omitempty"`
+}
+
+func reservationSignBytes(challenge []byte) []byte {
+	return append([]byte(reservationSignBytesPrefix), challenge...)
+}
+
+func writeMessage(w io.Writer, msg message) error {
+	bz, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, 4+len(bz))
+	binary.BigEndian.PutUint32(buf, uint32(len(bz)))
+	copy(buf[4:], bz)
+	_, err = w.Write(buf)
+	return err
+}
+
+// readMessage reads a message from r, without reading past its end.
+func readMessage(r io.Reader) (message, error) {
+	var msg message
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return msg, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxMessageSize {
+		return msg, fmt.Errorf("message too large (%d bytes)", n)
+	}
+	bz := make([]byte, n)
+	if _, err := io.ReadFull(r, bz); err != nil {
+		return msg, err
+	}
+	if err := json.Unmarshal(bz, &msg); err != nil {
+		return msg, fmt.Errorf("decoding message: %w", err)
+	}
+	return msg, nil
+}
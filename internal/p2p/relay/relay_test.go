@@ -0,0 +1,91 @@
+package relay
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestRelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := log.NewNopLogger()
+
+	server := NewServer(logger, "127.0.0.1:0", ServerOptions{})
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(server.Wait)
+
+	key := ed25519.GenPrivKey()
+	l, err := Listen(ctx, logger, server.Addr().String(), key)
+	require.NoError(t, err)
+	defer l.Close()
+	host, _, err := net.SplitHostPort(l.Address())
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", host)
+
+	// A peer connects to the reserved port, and the node accepts the
+	// connection through the relay.
+	peerConn, err := net.Dial("tcp", l.Address())
+	require.NoError(t, err)
+	defer peerConn.Close()
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, peerConn.LocalAddr().String(), conn.RemoteAddr().String())
+
+	_, err = peerConn.Write([]byte("ping"))
+	require.NoError(t, err)
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	_, err = conn.Write([]byte("pong"))
+	require.NoError(t, err)
+	_, err = io.ReadFull(peerConn, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+
+	// The connections are spliced until either end closes.
+	require.NoError(t, conn.Close())
+	_, err = peerConn.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRelayAllowedNodes(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := log.NewNopLogger()
+
+	allowed := ed25519.GenPrivKey()
+	server := NewServer(logger, "127.0.0.1:0", ServerOptions{
+		MaxReservations: 1,
+		AllowedNodes:    map[types.NodeID]bool{types.NodeIDFromPubKey(allowed.PubKey()): true},
+	})
+	require.NoError(t, server.Start(ctx))
+	t.Cleanup(server.Wait)
+
+	_, err := Listen(ctx, logger, server.Addr().String(), ed25519.GenPrivKey())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not allowed")
+
+	l, err := Listen(ctx, logger, server.Addr().String(), allowed)
+	require.NoError(t, err)
+	port := l.Address()
+
+	// The node reserving again replaces its reservation, and gets its port
+	// back.
+	_, err = l.reserve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, port, l.Address())
+	require.NoError(t, l.Close())
+}
@@ -0,0 +1,385 @@
+package relay
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/types"
+)
+
+// ServerOptions are the options of a relay server.
+type ServerOptions struct {
+	// FirstPort and LastPort bound the ports reserved for the nodes. If they
+	// are 0, the ports are chosen by the system.
+	FirstPort, LastPort int
+
+	// MaxReservations is the maximum number of nodes a port is reserved for
+	// at a time. 0 means unlimited.
+	MaxReservations int
+
+	// AllowedNodes are the nodes allowed to reserve a port. If it is empty,
+	// any node is.
+	AllowedNodes map[types.NodeID]bool
+}
+
+// Server is a relay server, reserving its ports for the nodes behind a NAT
+// and relaying the connections to the ports to the nodes.
+type Server struct {
+	service.BaseService
+	logger  log.Logger
+	address string // host:port
+	options ServerOptions
+
+	mtx          sync.Mutex
+	listener     net.Listener
+	reservations map[types.NodeID]*reservation
+	pending      map[string]net.Conn // connections to relay, by token
+	conns        map[net.Conn]bool   // all the open connections
+}
+
+// reservation is a port reserved for a node.
+type reservation struct {
+	nodeID   types.NodeID
+	listener net.Listener
+	conn     net.Conn // of the node
+
+	writeMtx sync.Mutex
+}
+
+func (r *reservation) write(msg message) error {
+	r.writeMtx.Lock()
+	defer r.writeMtx.Unlock()
+	return writeMessage(r.conn, msg)
+}
+
+// NewServer returns a relay server listening on address (host:port).
+func NewServer(logger log.Logger, address string, options ServerOptions) *Server {
+	s := &Server{
+		logger:       logger,
+		address:      address,
+		options:      options,
+		reservations: make(map[types.NodeID]*reservation),
+		pending:      make(map[string]net.Conn),
+		conns:        make(map[net.Conn]bool),
+	}
+	s.BaseService = *service.NewBaseService(logger, "RelayServer", s)
+	return s
+}
+
+// OnStart starts listening and accepting the connections of the nodes.
+func (s *Server) OnStart(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	s.listener = listener
+	s.mtx.Unlock()
+	s.logger.Info("Starting the relay server", "address", listener.Addr())
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+					s.logger.Error("Failed to accept connection", "err", err)
+				}
+				return
+			}
+			if !s.track(conn) {
+				return
+			}
+			go s.handleConn(conn)
+		}
+	}()
+	return nil
+}
+
+// OnStop closes the listeners and the connections.
+func (s *Server) OnStop() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for _, r := range s.reservations {
+		r.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+// Addr returns the address the server listens on, if it is started.
+func (s *Server) Addr() net.Addr {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// track registers the open connection conn, to close it on stop. It returns
+// false, after closing conn, if the server is stopped.
+func (s *Server) track(conn net.Conn) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.conns == nil {
+		conn.Close()
+		return false
+	}
+	s.conns[conn] = true
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	conn.Close()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.conns, conn)
+}
+
+// handleConn handles a connection of a node, reserving a port or accepting
+// a relayed connection.
+func (s *Server) handleConn(conn net.Conn) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		s.untrack(conn)
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := writeMessage(conn, message{Type: msgChallenge, Challenge: challenge}); err != nil {
+		s.untrack(conn)
+		return
+	}
+	msg, err := readMessage(conn)
+	if err != nil {
+		s.logger.Debug("Failed to read message", "remote", conn.RemoteAddr(), "err", err)
+		s.untrack(conn)
+		return
+	}
+
+	switch msg.Type {
+	case msgReserve:
+		r, err := s.reserve(conn, challenge, msg)
+		if err != nil {
+			s.logger.Info("Rejected reservation", "remote", conn.RemoteAddr(), "err", err)
+			_ = writeMessage(conn, message{Type: msgError, Error: err.Error()})
+			s.untrack(conn)
+			return
+		}
+		_ = conn.SetDeadline(time.Time{})
+		s.serveReservation(r)
+
+	case msgAccept:
+		s.mtx.Lock()
+		peerConn, ok := s.pending[string(msg.Token)]
+		delete(s.pending, string(msg.Token))
+		s.mtx.Unlock()
+		if !ok {
+			_ = writeMessage(conn, message{Type: msgError, Error: "unknown token"})
+			s.untrack(conn)
+			return
+		}
+		_ = conn.SetDeadline(time.Time{})
+		splice(conn, peerConn)
+		s.untrack(conn)
+		s.untrack(peerConn)
+
+	default:
+		_ = writeMessage(conn, message{Type: msgError, Error: fmt.Sprintf("unexpected message %q", msg.Type)})
+		s.untrack(conn)
+	}
+}
+
+// reserve reserves a port for the node of the connection conn, which
+// answered the challenge with the reservation request msg.
+func (s *Server) reserve(conn net.Conn, challenge []byte, msg message) (*reservation, error) {
+	if len(msg.PubKey) != ed25519.PubKeySize {
+		return nil, errors.New("invalid public key")
+	}
+	pubKey := ed25519.PubKey(msg.PubKey)
+	if !pubKey.VerifySignature(reservationSignBytes(challenge), msg.Signature) {
+		return nil, errors.New("invalid signature")
+	}
+	nodeID := types.NodeIDFromPubKey(pubKey)
+	if len(s.options.AllowedNodes) > 0 && !s.options.AllowedNodes[nodeID] {
+		return nil, fmt.Errorf("node %v is not allowed", nodeID)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	// A node reconnecting replaces its reservation, and gets its port back
+	// if it asks for it. Otherwise, the nodes can only ask for the ports of
+	// the range of the server.
+	preferred := 0
+	if s.options.FirstPort > 0 {
+		preferred = msg.Port
+	}
+	if prev, ok := s.reservations[nodeID]; ok {
+		if prev.listener.Addr().(*net.TCPAddr).Port == msg.Port {
+			preferred = msg.Port
+		}
+		prev.listener.Close()
+		prev.conn.Close()
+		delete(s.reservations, nodeID)
+	}
+	if s.options.MaxReservations > 0 && len(s.reservations) >= s.options.MaxReservations {
+		return nil, errors.New("too many reservations")
+	}
+
+	listener, err := s.listenPort(preferred)
+	if err != nil {
+		return nil, err
+	}
+	r := &reservation{nodeID: nodeID, listener: listener, conn: conn}
+	s.reservations[nodeID] = r
+	return r, nil
+}
+
+// listenPort listens on a port to reserve, the port preferred if possible.
+// It must be called with the mutex held.
+func (s *Server) listenPort(preferred int) (net.Listener, error) {
+	host, _, err := net.SplitHostPort(s.listener.Addr().String())
+	if err != nil {
+		return nil, err
+	}
+	listen := func(port int) (net.Listener, error) {
+		return net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	}
+
+	first, last := s.options.FirstPort, s.options.LastPort
+	if first == 0 {
+		if preferred > 0 {
+			if l, err := listen(preferred); err == nil {
+				return l, nil
+			}
+		}
+		return listen(0)
+	}
+	if preferred >= first && preferred <= last {
+		if l, err := listen(preferred); err == nil {
+			return l, nil
+		}
+	}
+	for port := first; port <= last; port++ {
+		if l, err := listen(port); err == nil {
+			return l, nil
+		}
+	}
+	return nil, errors.New("no port available")
+}
+
+// serveReservation serves the reservation r until its node goes away.
+func (s *Server) serveReservation(r *reservation) {
+	port := r.listener.Addr().(*net.TCPAddr).Port
+	logger := s.logger.With("node", r.nodeID, "port", port)
+	if err := r.write(message{Type: msgReserved, Port: port}); err != nil {
+		s.release(r)
+		return
+	}
+	logger.Info("Reserved a port for a node")
+
+	go func() {
+		for {
+			peerConn, err := r.listener.Accept()
+			if err != nil {
+				return
+			}
+			if !s.track(peerConn) {
+				return
+			}
+			if err := s.connect(r, peerConn); err != nil {
+				logger.Debug("Failed to relay connection", "remote", peerConn.RemoteAddr(), "err", err)
+				s.untrack(peerConn)
+			}
+		}
+	}()
+
+	for {
+		_ = r.conn.SetReadDeadline(time.Now().Add(pingTimeout))
+		msg, err := readMessage(r.conn)
+		if err != nil {
+			break
+		}
+		if msg.Type == msgPing {
+			if err := r.write(message{Type: msgPong}); err != nil {
+				break
+			}
+		}
+	}
+	s.release(r)
+	logger.Info("Released the port of a node")
+}
+
+// connect has the node of r dial back to accept the connection peerConn.
+func (s *Server) connect(r *reservation, peerConn net.Conn) error {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	s.pending[string(token)] = peerConn
+	s.mtx.Unlock()
+
+	// The connection is dropped if the node does not accept it in time.
+	time.AfterFunc(handshakeTimeout, func() {
+		s.mtx.Lock()
+		conn, ok := s.pending[string(token)]
+		delete(s.pending, string(token))
+		s.mtx.Unlock()
+		if ok {
+			s.untrack(conn)
+		}
+	})
+
+	err := r.write(message{Type: msgConnect, Token: token, Remote: peerConn.RemoteAddr().String()})
+	if err != nil {
+		s.mtx.Lock()
+		delete(s.pending, string(token))
+		s.mtx.Unlock()
+		return fmt.Errorf("notifying the node: %w", err)
+	}
+	return nil
+}
+
+// release releases the reservation r, unless it was replaced already.
+func (s *Server) release(r *reservation) {
+	r.listener.Close()
+	s.untrack(r.conn)
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.reservations[r.nodeID] == r {
+		delete(s.reservations, r.nodeID)
+	}
+}
+
+// splice copies the bytes between a and b until either is closed.
+func splice(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(a, b)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(b, a)
+		done <- struct{}{}
+	}()
+	<-done
+	a.Close()
+	b.Close()
+	<-done
+}
@@ -65,13 +65,40 @@ type RouterOptions struct {
 	// DialSleep controls the amount of time that the router
 	// sleeps between dialing peers. If not set, a default value
 	// is used that sleeps for a (random) amount of time up to 3
-	// seconds between submitting each peer to be dialed.
+	// seconds between submitting each peer to be dialed. The
+	// dials are further scheduled by the peer manager, see
+	// PeerManagerOptions.MaxConcurrentDials and MinDialInterval.
 	DialSleep func(context.Context)
 
 	// NumConcrruentDials controls how many parallel go routines
 	// are used to dial peers. This defaults to the value of
 	// runtime.NumCPU.
 	NumConcurrentDials func() int
+
+	// ChannelRecvRates limits the rate, in bytes/second, at which each peer
+	// can send messages on the given channels. The messages received faster
+	// are delayed, throttling the peer without disconnecting it.
+	ChannelRecvRates map[ChannelID]int64
+
+	// ValidatorBook records the validators attesting to run the peers in
+	// their handshake, if not nil.
+	ValidatorBook *ValidatorBook
+
+	// OptOutChannels are the channels this node doesn't serve, although its
+	// reactors open them: they are not advertised to the peers, which don't
+	// send messages over them, and the messages received over them are
+	// dropped. The reactors of the channels aren't told of any peer.
+	OptOutChannels []ChannelID
+
+	// PeerFilter allows and denies the peers by their IP address, node ID
+	// and chain ID, when dialing or accepting them, if not nil. It is
+	// applied before FilterPeerByIP and FilterPeerByID.
+	PeerFilter *PeerFilter
+
+	// ShedLoad, if set, reports whether the node sheds load: the incoming
+	// connections are then closed before the handshake. The outgoing ones
+	// are not affected.
+	ShedLoad func() bool
 }
 
 const (
@@ -162,7 +189,7 @@ type Router struct {
 	peerMtx    sync.RWMutex
 	peerQueues map[types.NodeID]queue // outbound messages per peer for all channels
 	// the channels that the peer queue has open
-	peerChannels map[types.NodeID]channelIDs
+	peerChannels map[types.NodeID]ChannelIDSet
 	queueFactory func(int) queue
 
 	// FIXME: We don't strictly need to use a mutex for this if we seal the
@@ -171,6 +198,11 @@ type Router struct {
 	channelMtx      sync.RWMutex
 	channelQueues   map[ChannelID]queue // inbound messages from all peers to a single channel
 	channelMessages map[ChannelID]proto.Message
+	channelLimits   channelLimits // the largest inbound messages, for the channels declaring one
+
+	captures  messageCaptures
+	bandwidth *bandwidthMeter
+	optOut    ChannelIDSet // channels not served, see RouterOptions.OptOutChannels
 }
 
 // NewRouter creates a new Router. The given Transports must already be
@@ -209,9 +241,16 @@ func NewRouter(
 		options:            options,
 		channelQueues:      map[ChannelID]queue{},
 		channelMessages:    map[ChannelID]proto.Message{},
+		channelLimits:      channelLimits{},
 		peerQueues:         map[types.NodeID]queue{},
-		peerChannels:       make(map[types.NodeID]channelIDs),
+		peerChannels:       make(map[types.NodeID]ChannelIDSet),
+		bandwidth:          newBandwidthMeter(options.ChannelRecvRates),
+		optOut:             make(ChannelIDSet, len(options.OptOutChannels)),
+	}
+	for _, id := range options.OptOutChannels {
+		router.optOut[id] = struct{}{}
 	}
+	router.nodeInfo.Channels = router.advertisedChannels(nodeInfo.Channels)
 
 	router.BaseService = service.NewBaseService(logger, "router", router)
 
@@ -233,6 +272,18 @@ func NewRouter(
 	return router, nil
 }
 
+// advertisedChannels returns the channels, without those the node opted out
+// of.
+func (r *Router) advertisedChannels(channels []byte) []byte {
+	advertised := make([]byte, 0, len(channels))
+	for _, id := range channels {
+		if _, ok := r.optOut[ChannelID(id)]; !ok {
+			advertised = append(advertised, id)
+		}
+	}
+	return advertised
+}
+
 func (r *Router) createQueueFactory(ctx context.Context) (func(int) queue, error) {
 	switch r.options.QueueType {
 	case queueTypeFifo:
@@ -290,8 +341,16 @@ func (r *Router) OpenChannel(ctx context.Context, chDesc *ChannelDescriptor) (*C
 	r.channelQueues[id] = queue
 	r.channelMessages[id] = messageType
 
-	// add the channel to the nodeInfo if it's not already there.
-	r.nodeInfo.AddChannel(uint16(chDesc.ID))
+	// add the channel to the nodeInfo if it's not already there, and declare
+	// the largest message it accepts to the peers, unless the node opted out
+	// of the channel.
+	if _, ok := r.optOut[id]; !ok {
+		r.nodeInfo.AddChannel(uint16(chDesc.ID))
+		if chDesc.RecvMessageCapacity > 0 {
+			r.channelLimits[id] = chDesc.RecvMessageCapacity
+			r.nodeInfo.SetChannelLimit(uint16(chDesc.ID), uint32(chDesc.RecvMessageCapacity))
+		}
+	}
 
 	for _, t := range r.transports {
 		t.AddChannelDescriptors([]*ChannelDescriptor{chDesc})
@@ -302,6 +361,7 @@ func (r *Router) OpenChannel(ctx context.Context, chDesc *ChannelDescriptor) (*C
 			r.channelMtx.Lock()
 			delete(r.channelQueues, id)
 			delete(r.channelMessages, id)
+			delete(r.channelLimits, id)
 			r.channelMtx.Unlock()
 			queue.close()
 		}()
@@ -415,6 +475,7 @@ func (r *Router) routeChannel(
 			r.logger.Error("peer error, evicting", "peer", peerError.NodeID, "err", peerError.Err)
 
 			r.peerManager.Errored(peerError.NodeID, peerError.Err)
+			r.peerManager.Stats().RecordMisbehavior(peerError.NodeID)
 		case <-ctx.Done():
 			return
 		}
@@ -430,6 +491,11 @@ func (r *Router) numConccurentDials() int {
 }
 
 func (r *Router) filterPeersIP(ctx context.Context, ip net.IP, port uint16) error {
+	if r.options.PeerFilter != nil && ip != nil {
+		if err := r.options.PeerFilter.FilterIP(ip); err != nil {
+			return err
+		}
+	}
 	if r.options.FilterPeerByIP == nil {
 		return nil
 	}
@@ -509,6 +575,11 @@ func (r *Router) openConnection(ctx context.Context, conn Connection) {
 	re := conn.RemoteEndpoint()
 	incomingIP := re.IP
 
+	if r.options.ShedLoad != nil && r.options.ShedLoad() {
+		r.logger.Debug("shedding load, rejecting incoming peer", "ip", incomingIP.String())
+		return
+	}
+
 	if err := r.filterPeersIP(ctx, incomingIP, re.Port); err != nil {
 		r.logger.Debug("peer filtered by IP", "ip", incomingIP.String(), "err", err)
 		return
@@ -548,10 +619,13 @@ func (r *Router) openConnection(ctx context.Context, conn Connection) {
 		return
 	}
 
-	r.routePeer(ctx, peerInfo.NodeID, conn, toChannelIDs(peerInfo.Channels))
+	r.routePeer(ctx, peerInfo.NodeID, conn, r.sharedChannels(peerInfo.Channels), toChannelLimits(peerInfo.ChannelLimits))
 }
 
-// dialPeers maintains outbound connections to peers by dialing them.
+// dialPeers maintains outbound connections to peers by dialing them. The
+// peer manager schedules the dials: it returns the addresses of the best-scored
+// peers first, backs off the addresses failing to dial, and limits the dials
+// in progress, globally and per subnet.
 func (r *Router) dialPeers(ctx context.Context) {
 	addresses := make(chan NodeAddress)
 	wg := &sync.WaitGroup{}
@@ -607,6 +681,16 @@ LOOP:
 }
 
 func (r *Router) connectPeer(ctx context.Context, address NodeAddress) {
+	if r.options.PeerFilter != nil {
+		if err := r.options.PeerFilter.FilterNodeID(address.NodeID); err != nil {
+			r.logger.Debug("peer filtered by node ID", "peer", address, "err", err)
+			if err = r.peerManager.DialFailed(ctx, address); err != nil {
+				r.logger.Error("failed to report dial failure", "peer", address, "err", err)
+			}
+			return
+		}
+	}
+
 	conn, err := r.dialPeer(ctx, address)
 	switch {
 	case errors.Is(err, context.Canceled):
@@ -641,10 +725,10 @@ func (r *Router) connectPeer(ctx context.Context, address NodeAddress) {
 	}
 
 	// routePeer (also) calls connection close
-	go r.routePeer(ctx, address.NodeID, conn, toChannelIDs(peerInfo.Channels))
+	go r.routePeer(ctx, address.NodeID, conn, r.sharedChannels(peerInfo.Channels), toChannelLimits(peerInfo.ChannelLimits))
 }
 
-func (r *Router) getOrMakeQueue(peerID types.NodeID, channels channelIDs) queue {
+func (r *Router) getOrMakeQueue(peerID types.NodeID, channels ChannelIDSet) queue {
 	r.peerMtx.Lock()
 	defer r.peerMtx.Unlock()
 
@@ -682,6 +766,12 @@ func (r *Router) dialPeer(ctx context.Context, address NodeAddress) (Connection,
 			r.logger.Error("no transport found for protocol", "endpoint", endpoint)
 			continue
 		}
+		if r.options.PeerFilter != nil && endpoint.IP != nil {
+			if err := r.options.PeerFilter.FilterIP(endpoint.IP); err != nil {
+				r.logger.Debug("peer endpoint filtered by IP", "peer", address.NodeID, "endpoint", endpoint, "err", err)
+				continue
+			}
+		}
 
 		dialCtx := ctx
 		if r.options.DialTimeout > 0 {
@@ -722,10 +812,12 @@ func (r *Router) handshakePeer(
 		defer cancel()
 	}
 
+	start := time.Now()
 	peerInfo, peerKey, err := conn.Handshake(ctx, r.nodeInfo, r.privKey)
 	if err != nil {
 		return peerInfo, err
 	}
+	latency := time.Since(start)
 	if err = peerInfo.Validate(); err != nil {
 		return peerInfo, fmt.Errorf("invalid handshake NodeInfo: %w", err)
 	}
@@ -737,6 +829,15 @@ func (r *Router) handshakePeer(
 		return peerInfo, fmt.Errorf("expected to connect with peer %q, got %q",
 			expectID, peerInfo.NodeID)
 	}
+	if r.options.PeerFilter != nil {
+		if err := r.options.PeerFilter.FilterNodeInfo(peerInfo); err != nil {
+			return peerInfo, ErrRejected{
+				err:        err,
+				id:         peerInfo.ID(),
+				isFiltered: true,
+			}
+		}
+	}
 	if err := r.nodeInfo.CompatibleWith(peerInfo); err != nil {
 		return peerInfo, ErrRejected{
 			err:            err,
@@ -744,6 +845,15 @@ func (r *Router) handshakePeer(
 			isIncompatible: true,
 		}
 	}
+	// The attestation was verified for the node ID of the peer, which was
+	// authenticated above.
+	if att := peerInfo.ValidatorAttestation; att != nil && r.options.ValidatorBook != nil {
+		if r.options.ValidatorBook.add(peerInfo.NodeID, att) {
+			r.logger.Info("peer attested to be run by a validator",
+				"peer", peerInfo.NodeID, "validator", att.Address())
+		}
+	}
+	r.peerManager.Stats().RecordLatency(peerInfo.NodeID, latency)
 	return peerInfo, nil
 }
 
@@ -756,11 +866,23 @@ func (r *Router) runWithPeerMutex(fn func() error) error {
 // routePeer routes inbound and outbound messages between a peer and the reactor
 // channels. It will close the given connection and send queue when done, or if
 // they are closed elsewhere it will cause this method to shut down and return.
-func (r *Router) routePeer(ctx context.Context, peerID types.NodeID, conn Connection, channels channelIDs) {
+// limits are the largest messages the peer accepts on its channels.
+func (r *Router) routePeer(
+	ctx context.Context,
+	peerID types.NodeID,
+	conn Connection,
+	channels ChannelIDSet,
+	limits channelLimits,
+) {
 	r.metrics.Peers.Add(1)
-	r.peerManager.Ready(ctx, peerID)
 
+	// The queue must exist before the reactors are told that the peer is up,
+	// otherwise the first messages they send to it are dropped.
 	sendQueue := r.getOrMakeQueue(peerID, channels)
+	r.bandwidth.connected(peerID)
+	r.peerManager.Ready(ctx, peerID, channels)
+	r.peerManager.Stats().RecordConnected(peerID)
+
 	defer func() {
 		r.peerMtx.Lock()
 		delete(r.peerQueues, peerID)
@@ -769,6 +891,10 @@ func (r *Router) routePeer(ctx context.Context, peerID types.NodeID, conn Connec
 
 		sendQueue.close()
 
+		r.bandwidth.disconnected(peerID)
+		// The statistics are recorded first, for the peer manager to update
+		// the reputation of the peer.
+		r.peerManager.Stats().RecordDisconnected(peerID)
 		r.peerManager.Disconnected(ctx, peerID)
 		r.metrics.Peers.Add(-1)
 	}()
@@ -786,7 +912,7 @@ func (r *Router) routePeer(ctx context.Context, peerID types.NodeID, conn Connec
 
 	go func() {
 		select {
-		case errCh <- r.sendPeer(ctx, peerID, conn, sendQueue):
+		case errCh <- r.sendPeer(ctx, peerID, conn, sendQueue, limits):
 		case <-ctx.Done():
 		}
 	}()
@@ -831,22 +957,50 @@ func (r *Router) receivePeer(ctx context.Context, peerID types.NodeID, conn Conn
 		if err != nil {
 			return err
 		}
+		r.peerManager.Stats().AddBytesReceived(peerID, len(bz))
+
+		// Delaying the message throttles the peer, as it stops reading from
+		// the connection.
+		if delay := r.bandwidth.addReceived(peerID, chID, len(bz)); delay > 0 {
+			r.metrics.PeerThrottledSeconds.With(
+				"peer_id", string(peerID),
+				"chID", fmt.Sprint(chID)).Add(delay.Seconds())
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			}
+		}
 
 		r.channelMtx.RLock()
 		queue, ok := r.channelQueues[chID]
 		messageType := r.channelMessages[chID]
+		maxMsgSize := r.channelLimits[chID]
 		r.channelMtx.RUnlock()
 
 		if !ok {
 			r.logger.Debug("dropping message for unknown channel", "peer", peerID, "channel", chID)
 			continue
 		}
+		if _, optedOut := r.optOut[chID]; optedOut {
+			r.logger.Debug("dropping message for opted out channel", "peer", peerID, "channel", chID)
+			continue
+		}
+
+		// The peer was told the limit in the handshake, so it is not honest.
+		if maxMsgSize > 0 && len(bz) > maxMsgSize {
+			return fmt.Errorf("received message of %d bytes on channel %v, exceeding its limit of %d bytes",
+				len(bz), chID, maxMsgSize)
+		}
 
 		msg := proto.Clone(messageType)
 		if err := proto.Unmarshal(bz, msg); err != nil {
 			r.logger.Error("message decoding failed, dropping message", "peer", peerID, "err", err)
 			continue
 		}
+		r.capture(peerID, chID, false, msg, len(bz))
 
 		if wrapper, ok := msg.(Wrapper); ok {
 			msg, err = wrapper.Unwrap()
@@ -876,8 +1030,15 @@ func (r *Router) receivePeer(ctx context.Context, peerID types.NodeID, conn Conn
 	}
 }
 
-// sendPeer sends queued messages to a peer.
-func (r *Router) sendPeer(ctx context.Context, peerID types.NodeID, conn Connection, peerQueue queue) error {
+// sendPeer sends queued messages to a peer, dropping the messages exceeding
+// the limits of its channels.
+func (r *Router) sendPeer(
+	ctx context.Context,
+	peerID types.NodeID,
+	conn Connection,
+	peerQueue queue,
+	limits channelLimits,
+) error {
 	for {
 		start := time.Now().UTC()
 
@@ -895,9 +1056,23 @@ func (r *Router) sendPeer(ctx context.Context, peerID types.NodeID, conn Connect
 				continue
 			}
 
+			// The peer would disconnect us on receipt of the message.
+			if maxMsgSize, ok := limits[envelope.ChannelID]; ok && len(bz) > maxMsgSize {
+				r.logger.Error("dropping message exceeding the peer's channel limit", "peer", peerID,
+					"channel", envelope.ChannelID, "size", len(bz), "limit", maxMsgSize)
+				continue
+			}
+
 			if err = conn.SendMessage(ctx, envelope.ChannelID, bz); err != nil {
 				return err
 			}
+			r.capture(peerID, envelope.ChannelID, true, envelope.Message, len(bz))
+			r.peerManager.Stats().AddBytesSent(peerID, len(bz))
+			r.bandwidth.addSent(peerID, envelope.ChannelID, len(bz))
+			r.metrics.PeerSendBytesTotal.With(
+				"chID", fmt.Sprint(envelope.ChannelID),
+				"peer_id", string(peerID),
+				"message_type", r.metrics.ValueToMetricLabel(envelope.Message)).Add(float64(len(bz)))
 
 			r.logger.Debug("sent message", "peer", envelope.To, "message", envelope.Message)
 
@@ -935,6 +1110,59 @@ func (r *Router) evictPeers(ctx context.Context) {
 	}
 }
 
+// flushPeerStats writes the statistics of the peers to disk as they change,
+// and periodically for the byte counters.
+func (r *Router) flushPeerStats(ctx context.Context) {
+	stats := r.peerManager.Stats()
+	ticker := time.NewTicker(peerStatsPersistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stats.Changed():
+		case <-ticker.C:
+		}
+
+		if err := stats.Flush(); err != nil {
+			r.logger.Error("failed to flush peer statistics", "err", err)
+		}
+	}
+}
+
+// collectGarbage periodically garbage collects the peer store, and reports its
+// occupancy.
+func (r *Router) collectGarbage(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		addresses, peers, err := r.peerManager.GarbageCollect()
+		if err != nil {
+			r.logger.Error("failed to garbage collect peer store", "err", err)
+		}
+		if addresses > 0 || peers > 0 {
+			r.logger.Debug("garbage collected peer store", "addresses", addresses, "peers", peers)
+		}
+		r.metrics.PeerStoreGarbageCollected.With("kind", "address").Add(float64(addresses))
+		r.metrics.PeerStoreGarbageCollected.With("kind", "peer").Add(float64(peers))
+
+		occupancy := r.peerManager.Occupancy()
+		r.metrics.PeerStorePeers.With("bucket", "new").Set(float64(occupancy.New))
+		r.metrics.PeerStorePeers.With("bucket", "tried").Set(float64(occupancy.Tried))
+		r.metrics.PeerStorePeers.With("bucket", "failing").Set(float64(occupancy.Failing))
+		r.metrics.PeerStoreAddresses.Set(float64(occupancy.Addresses))
+		r.metrics.PeerStoreRatio.Set(r.peerManager.PeerRatio())
+	}
+}
+
 // NodeInfo returns a copy of the current NodeInfo. Used for testing.
 func (r *Router) NodeInfo() types.NodeInfo {
 	return r.nodeInfo.Copy()
@@ -960,6 +1188,10 @@ func (r *Router) OnStart(ctx context.Context) error {
 
 	go r.dialPeers(ctx)
 	go r.evictPeers(ctx)
+	if interval := r.peerManager.options.GCInterval; interval > 0 {
+		go r.collectGarbage(ctx, interval)
+	}
+	go r.flushPeerStats(ctx)
 
 	for _, transport := range r.transports {
 		go r.acceptPeers(ctx, transport)
@@ -1001,14 +1233,35 @@ func (r *Router) OnStop() {
 		q.close()
 		<-q.closed()
 	}
+
+	if err := r.peerManager.Stats().Flush(); err != nil {
+		r.logger.Error("failed to flush peer statistics", "err", err)
+	}
+}
+
+// sharedChannels returns the channels served by both this node and the peer,
+// which advertised the channels peerChannels.
+func (r *Router) sharedChannels(peerChannels []byte) ChannelIDSet {
+	r.channelMtx.RLock()
+	defer r.channelMtx.RUnlock()
+
+	local := NewChannelIDSet(r.nodeInfo.Channels)
+	shared := make(ChannelIDSet, len(peerChannels))
+	for _, id := range peerChannels {
+		if local.Contains(ChannelID(id)) {
+			shared[ChannelID(id)] = struct{}{}
+		}
+	}
+	return shared
 }
 
-type channelIDs map[ChannelID]struct{}
+// channelLimits are the sizes of the largest messages accepted on channels.
+type channelLimits map[ChannelID]int
 
-func toChannelIDs(bytes []byte) channelIDs {
-	c := make(map[ChannelID]struct{}, len(bytes))
-	for _, b := range bytes {
-		c[ChannelID(b)] = struct{}{}
+func toChannelLimits(limits []types.ChannelLimit) channelLimits {
+	c := make(channelLimits, len(limits))
+	for _, limit := range limits {
+		c[ChannelID(limit.ChannelID)] = int(limit.MaxMsgSize)
 	}
 	return c
 }
@@ -32,3 +32,48 @@ func TestConnectionFiltering(t *testing.T) {
 	router.openConnection(ctx, &MemoryConnection{logger: logger, closer: sync.NewCloser()})
 	require.Equal(t, 1, filterByIPCount)
 }
+
+func TestConnectionPeerFilter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	filter, err := NewPeerFilter([]PeerFilterRule{{Action: PeerFilterDeny, Kind: PeerFilterCIDR, Value: "192.0.2.0/24"}})
+	require.NoError(t, err)
+	filterByIPCount := 0
+	router := &Router{
+		options: RouterOptions{
+			PeerFilter: filter,
+			FilterPeerByIP: func(ctx context.Context, ip net.IP, port uint16) error {
+				filterByIPCount++
+				return nil
+			},
+		},
+	}
+	// The peers are rejected by the peer filter before FilterPeerByIP.
+	require.Error(t, router.filterPeersIP(ctx, net.ParseIP("192.0.2.1"), 26656))
+	require.Equal(t, 0, filterByIPCount)
+	require.NoError(t, router.filterPeersIP(ctx, net.ParseIP("198.51.100.1"), 26656))
+	require.Equal(t, 1, filterByIPCount)
+}
+
+func TestConnectionShedLoad(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logger := log.TestingLogger()
+
+	filterByIPCount := 0
+	router := &Router{
+		logger:      logger,
+		connTracker: newConnTracker(1, time.Second),
+		options: RouterOptions{
+			ShedLoad: func() bool { return true },
+			FilterPeerByIP: func(ctx context.Context, ip net.IP, port uint16) error {
+				filterByIPCount++
+				return errors.New("mock")
+			},
+		},
+	}
+	// The incoming connection is closed before it is filtered.
+	router.openConnection(ctx, &MemoryConnection{logger: logger, closer: sync.NewCloser()})
+	require.Equal(t, 0, filterByIPCount)
+}
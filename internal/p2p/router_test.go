@@ -19,6 +19,7 @@ import (
 	dbm "github.com/tendermint/tm-db"
 
 	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
 	tmsync "github.com/tendermint/tendermint/internal/libs/sync"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/p2p/mocks"
@@ -446,6 +447,64 @@ func TestRouter_AcceptPeers(t *testing.T) {
 	}
 }
 
+func TestRouter_ValidatorBook(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	valKey := ed25519.GenPrivKey()
+	att, err := types.SignValidatorAttestation(peerInfo.Network, peerID, valKey)
+	require.NoError(t, err)
+	attestedInfo := peerInfo
+	attestedInfo.ValidatorAttestation = att
+
+	mockConnection := &mocks.Connection{}
+	mockConnection.On("String").Maybe().Return("mock")
+	mockConnection.On("Handshake", mock.Anything, selfInfo, selfKey).
+		Return(attestedInfo, peerKey.PubKey(), nil)
+	mockConnection.On("Close").Return(nil).Maybe()
+	mockConnection.On("RemoteEndpoint").Return(p2p.Endpoint{})
+	mockConnection.On("ReceiveMessage", mock.Anything).Return(chID, nil, io.EOF).Maybe()
+
+	mockTransport := &mocks.Transport{}
+	mockTransport.On("String").Maybe().Return("mock")
+	mockTransport.On("Protocols").Return([]p2p.Protocol{"mock"})
+	mockTransport.On("Close").Return(nil).Maybe()
+	mockTransport.On("Accept", mock.Anything).Once().Return(mockConnection, nil)
+	mockTransport.On("Accept", mock.Anything).Maybe().Return(nil, io.EOF)
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	sub := peerManager.Subscribe(ctx)
+
+	book := p2p.NewValidatorBook()
+	router, err := p2p.NewRouter(
+		ctx,
+		log.TestingLogger(),
+		p2p.NopMetrics(),
+		selfInfo,
+		selfKey,
+		peerManager,
+		[]p2p.Transport{mockTransport},
+		nil,
+		p2p.RouterOptions{ValidatorBook: book},
+	)
+	require.NoError(t, err)
+	require.NoError(t, router.Start(ctx))
+
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp})
+
+	addr, ok := book.ValidatorAddress(peerID)
+	require.True(t, ok)
+	require.Equal(t, valKey.PubKey().Address(), addr)
+	identity, ok := book.Lookup(addr)
+	require.True(t, ok)
+	require.Equal(t, peerID, identity.NodeID)
+
+	require.NoError(t, router.Stop())
+}
+
 func TestRouter_AcceptPeers_Error(t *testing.T) {
 	t.Cleanup(leaktest.Check(t))
 
@@ -967,3 +1026,99 @@ func TestRouter_DontSendOnInvalidChannel(t *testing.T) {
 	require.NoError(t, router.Stop())
 	mockTransport.AssertExpectations(t)
 }
+
+func TestRouter_Channel_MessageLimits(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// a accepts messages of 10 bytes at most on the channel, and b of 64.
+	network := p2ptest.MakeNetwork(ctx, t, p2ptest.NetworkOptions{NumNodes: 2})
+	ids := network.NodeIDs()
+	aID, bID := ids[0], ids[1]
+	largeDesc := *chDesc
+	largeDesc.RecvMessageCapacity = 64
+	a := network.Nodes[aID].MakeChannel(ctx, t, chDesc)
+	b := network.Nodes[bID].MakeChannel(ctx, t, &largeDesc)
+
+	network.Start(ctx, t)
+
+	size, ok := network.Nodes[aID].Router.NodeInfo().MaxMsgSize(uint16(chID))
+	require.True(t, ok)
+	require.EqualValues(t, 10, size)
+
+	// b declared a larger limit than a, so a can send it larger messages.
+	large := &p2ptest.Message{Value: "larger than 10 bytes"}
+	p2ptest.RequireSend(ctx, t, a, p2p.Envelope{To: bID, Message: large})
+	p2ptest.RequireReceive(ctx, t, b, p2p.Envelope{From: aID, Message: large})
+
+	// b drops the messages exceeding the limit of a, and stays connected.
+	p2ptest.RequireSend(ctx, t, b, p2p.Envelope{To: aID, Message: large})
+	p2ptest.RequireEmpty(ctx, t, a, b)
+	p2ptest.RequireSend(ctx, t, b, p2p.Envelope{To: aID, Message: &p2ptest.Message{Value: "foo"}})
+	p2ptest.RequireReceive(ctx, t, a, p2p.Envelope{From: bID, Message: &p2ptest.Message{Value: "foo"}})
+}
+
+func TestRouter_ReceiveOverLimit(t *testing.T) {
+	t.Cleanup(leaktest.Check(t))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	small, err := proto.Marshal(&p2ptest.Message{Value: "foo"})
+	require.NoError(t, err)
+	large, err := proto.Marshal(&p2ptest.Message{Value: "larger than 10 bytes"})
+	require.NoError(t, err)
+
+	// The peer ignores the limit of the channel declared by the router.
+	closer := tmsync.NewCloser()
+	mockConnection := &mocks.Connection{}
+	mockConnection.On("String").Maybe().Return("mock")
+	mockConnection.On("Handshake", mock.Anything, mock.Anything, selfKey).
+		Return(peerInfo, peerKey.PubKey(), nil)
+	mockConnection.On("RemoteEndpoint").Return(p2p.Endpoint{})
+	mockConnection.On("Close").Run(func(_ mock.Arguments) { closer.Close() }).Return(nil)
+	mockConnection.On("ReceiveMessage", mock.Anything).Once().Return(chID, small, nil)
+	mockConnection.On("ReceiveMessage", mock.Anything).Once().Return(chID, large, nil)
+	mockConnection.On("ReceiveMessage", mock.Anything).Maybe().
+		Run(func(_ mock.Arguments) { <-closer.Done() }).Return(chID, nil, io.EOF)
+
+	mockTransport := &mocks.Transport{}
+	mockTransport.On("AddChannelDescriptors", mock.Anything).Return()
+	mockTransport.On("String").Maybe().Return("mock")
+	mockTransport.On("Protocols").Return([]p2p.Protocol{"mock"})
+	mockTransport.On("Close").Return(nil)
+	mockTransport.On("Accept", mock.Anything).Once().Return(mockConnection, nil)
+	mockTransport.On("Accept", mock.Anything).Maybe().Return(nil, io.EOF)
+
+	peerManager, err := p2p.NewPeerManager(selfID, dbm.NewMemDB(), p2p.PeerManagerOptions{})
+	require.NoError(t, err)
+	sub := peerManager.Subscribe(ctx)
+
+	router, err := p2p.NewRouter(
+		ctx,
+		log.TestingLogger(),
+		p2p.NopMetrics(),
+		selfInfo,
+		selfKey,
+		peerManager,
+		[]p2p.Transport{mockTransport},
+		nil,
+		p2p.RouterOptions{},
+	)
+	require.NoError(t, err)
+	channel, err := router.OpenChannel(ctx, chDesc)
+	require.NoError(t, err)
+	require.NoError(t, router.Start(ctx))
+
+	// The peer is disconnected on receipt of the message exceeding the
+	// limit, after the delivery of the previous one.
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusUp})
+	p2ptest.RequireReceive(ctx, t, channel, p2p.Envelope{From: peerID, Message: &p2ptest.Message{Value: "foo"}})
+	p2ptest.RequireUpdate(t, sub, p2p.PeerUpdate{NodeID: peerID, Status: p2p.PeerStatusDown})
+	p2ptest.RequireEmpty(ctx, t, channel)
+
+	require.NoError(t, router.Stop())
+	mockTransport.AssertExpectations(t)
+	mockConnection.AssertExpectations(t)
+}
@@ -35,6 +35,11 @@ type MConnTransportOptions struct {
 	// Router, since it will need to do e.g. rate limiting and such as well.
 	// But it might also make sense to have per-transport limits.
 	MaxAcceptedConnections uint32
+
+	// Listeners are additional listeners of inbound connections, e.g. the
+	// connections relayed to a node behind a NAT. The transport accepts their
+	// connections once it listens, and closes them when it is closed.
+	Listeners []net.Listener
 }
 
 // MConnTransport is a Transport implementation using the current multiplexed
@@ -119,6 +124,9 @@ func (m *MConnTransport) Listen(endpoint Endpoint) error {
 	if err != nil {
 		return err
 	}
+	if len(m.options.Listeners) > 0 {
+		listener = newMultiListener(append([]net.Listener{listener}, m.options.Listeners...))
+	}
 	if m.options.MaxAcceptedConnections > 0 {
 		// FIXME: This will establish the inbound connection but simply hang it
 		// until another connection is released. It would probably be better to
@@ -471,3 +479,70 @@ func (c *mConnConnection) Close() error {
 	})
 	return err
 }
+
+// multiListener accepts the connections of several listeners. Its address is
+// the address of the first one.
+type multiListener struct {
+	listeners []net.Listener
+	conns     chan net.Conn
+	errs      chan error
+	closeOnce sync.Once
+	closeCh   chan struct{}
+}
+
+func newMultiListener(listeners []net.Listener) *multiListener {
+	ml := &multiListener{
+		listeners: listeners,
+		conns:     make(chan net.Conn),
+		errs:      make(chan error, len(listeners)),
+		closeCh:   make(chan struct{}),
+	}
+	for _, l := range listeners {
+		go func(l net.Listener) {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					ml.errs <- err
+					return
+				}
+				select {
+				case ml.conns <- conn:
+				case <-ml.closeCh:
+					conn.Close()
+					return
+				}
+			}
+		}(l)
+	}
+	return ml
+}
+
+// Accept implements net.Listener. It returns the first error of a listener,
+// after which the listener is no longer accepting connections.
+func (ml *multiListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-ml.conns:
+		return conn, nil
+	case err := <-ml.errs:
+		return nil, err
+	case <-ml.closeCh:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close implements net.Listener.
+func (ml *multiListener) Close() error {
+	ml.closeOnce.Do(func() { close(ml.closeCh) })
+	var err error
+	for _, l := range ml.listeners {
+		if cerr := l.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Addr implements net.Listener.
+func (ml *multiListener) Addr() net.Addr {
+	return ml.listeners[0].Addr()
+}
@@ -129,6 +129,50 @@ func TestMConnTransport_AcceptMaxAcceptedConnections(t *testing.T) {
 	require.Equal(t, dial3.LocalEndpoint(), accept3.RemoteEndpoint())
 }
 
+func TestMConnTransport_Listeners(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	transport := p2p.NewMConnTransport(
+		log.TestingLogger(),
+		conn.DefaultMConnConfig(),
+		[]*p2p.ChannelDescriptor{{ID: chID, Priority: 1}},
+		p2p.MConnTransportOptions{
+			Listeners: []net.Listener{listener},
+		},
+	)
+	err = transport.Listen(p2p.Endpoint{
+		Protocol: p2p.MConnProtocol,
+		IP:       net.IPv4(127, 0, 0, 1),
+	})
+	require.NoError(t, err)
+	endpoints := transport.Endpoints()
+	require.Len(t, endpoints, 1)
+
+	// The connections to the additional listener are accepted, as well as
+	// those to the endpoint.
+	for _, port := range []uint16{uint16(listener.Addr().(*net.TCPAddr).Port), endpoints[0].Port} {
+		dial, err := transport.Dial(ctx, p2p.Endpoint{
+			Protocol: p2p.MConnProtocol,
+			IP:       net.IPv4(127, 0, 0, 1),
+			Port:     port,
+		})
+		require.NoError(t, err)
+		accept, err := transport.Accept(ctx)
+		require.NoError(t, err)
+		require.Equal(t, dial.LocalEndpoint(), accept.RemoteEndpoint())
+		require.NoError(t, dial.Close())
+		require.NoError(t, accept.Close())
+	}
+
+	// Closing the transport closes the additional listener.
+	require.NoError(t, transport.Close())
+	_, err = listener.Accept()
+	require.Error(t, err)
+}
+
 func TestMConnTransport_Listen(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -0,0 +1,102 @@
+package p2p
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/types"
+)
+
+// ValidatorIdentity is the node run by a validator, learned from the
+// attestation in its handshake.
+type ValidatorIdentity struct {
+	Address  types.Address
+	PubKey   crypto.PubKey
+	NodeID   types.NodeID
+	Attested time.Time // of the last handshake with the node
+}
+
+// ValidatorBook maps the consensus addresses of validators to the nodes
+// running them, as attested in the handshakes with the peers. A validator
+// runs a single node at a time: a new attestation replaces the previous node
+// of the validator. It is kept in memory, and safe for concurrent use.
+type ValidatorBook struct {
+	now func() time.Time
+
+	mtx        sync.RWMutex
+	validators map[string]ValidatorIdentity // by address
+	nodes      map[types.NodeID]string      // addresses by node ID
+}
+
+// NewValidatorBook returns an empty validator book.
+func NewValidatorBook() *ValidatorBook {
+	return &ValidatorBook{
+		now:        time.Now,
+		validators: make(map[string]ValidatorIdentity),
+		nodes:      make(map[types.NodeID]string),
+	}
+}
+
+// add records the attestation of the node nodeID, which must be verified.
+// It returns true if the validator was not known to run the node.
+func (b *ValidatorBook) add(nodeID types.NodeID, att *types.ValidatorAttestation) bool {
+	addr := att.Address()
+
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	prev, known := b.validators[string(addr)]
+	if known && prev.NodeID != nodeID {
+		delete(b.nodes, prev.NodeID)
+	}
+	// A node runs a single validator too.
+	if prevAddr, ok := b.nodes[nodeID]; ok && prevAddr != string(addr) {
+		delete(b.validators, prevAddr)
+	}
+	b.validators[string(addr)] = ValidatorIdentity{
+		Address:  addr,
+		PubKey:   att.PubKey,
+		NodeID:   nodeID,
+		Attested: b.now().UTC(),
+	}
+	b.nodes[nodeID] = string(addr)
+	return !known || prev.NodeID != nodeID
+}
+
+// ValidatorAddress returns the address of the validator running the node
+// nodeID, if known.
+func (b *ValidatorBook) ValidatorAddress(nodeID types.NodeID) (types.Address, bool) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	addr, ok := b.nodes[nodeID]
+	if !ok {
+		return nil, false
+	}
+	return b.validators[addr].Address, true
+}
+
+// Lookup returns the node run by the validator with the address addr, if
+// known.
+func (b *ValidatorBook) Lookup(addr types.Address) (ValidatorIdentity, bool) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	identity, ok := b.validators[string(addr)]
+	return identity, ok
+}
+
+// List returns the known validators, sorted by address.
+func (b *ValidatorBook) List() []ValidatorIdentity {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	out := make([]ValidatorIdentity, 0, len(b.validators))
+	for _, identity := range b.validators {
+		out = append(out, identity)
+	}
+	sort.Slice(out, func(i, j int) bool { return string(out[i].Address) < string(out[j].Address) })
+	return out
+}
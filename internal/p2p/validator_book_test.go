@@ -0,0 +1,49 @@
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestValidatorBook(t *testing.T) {
+	attest := func(nodeID types.NodeID) (*types.ValidatorAttestation, types.Address) {
+		key := ed25519.GenPrivKey()
+		att, err := types.SignValidatorAttestation("test", nodeID, key)
+		require.NoError(t, err)
+		return att, key.PubKey().Address()
+	}
+
+	book := NewValidatorBook()
+	attA, addrA := attest("aa")
+	require.True(t, book.add("aa", attA))
+	require.False(t, book.add("aa", attA))
+
+	addr, ok := book.ValidatorAddress("aa")
+	require.True(t, ok)
+	require.Equal(t, addrA, addr)
+	_, ok = book.ValidatorAddress("bb")
+	require.False(t, ok)
+
+	// A validator moving to a new node leaves the previous one.
+	require.True(t, book.add("bb", attA))
+	_, ok = book.ValidatorAddress("aa")
+	require.False(t, ok)
+	identity, ok := book.Lookup(addrA)
+	require.True(t, ok)
+	require.Equal(t, types.NodeID("bb"), identity.NodeID)
+
+	// A node running a new validator leaves the previous one.
+	attC, addrC := attest("bb")
+	require.True(t, book.add("bb", attC))
+	_, ok = book.Lookup(addrA)
+	require.False(t, ok)
+	addr, ok = book.ValidatorAddress("bb")
+	require.True(t, ok)
+	require.Equal(t, addrC, addr)
+
+	require.Len(t, book.List(), 1)
+}
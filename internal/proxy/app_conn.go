@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-kit/kit/metrics"
@@ -20,6 +21,10 @@ type AppConnConsensus interface {
 
 	InitChain(context.Context, types.RequestInitChain) (*types.ResponseInitChain, error)
 
+	PrepareProposal(context.Context, types.RequestPrepareProposal) (*types.ResponsePrepareProposal, error)
+	ProcessProposal(context.Context, types.RequestProcessProposal) (*types.ResponseProcessProposal, error)
+	ExtendVote(context.Context, types.RequestExtendVote) (*types.ResponseExtendVote, error)
+	VerifyVoteExtension(context.Context, types.RequestVerifyVoteExtension) (*types.ResponseVerifyVoteExtension, error)
 	BeginBlock(context.Context, types.RequestBeginBlock) (*types.ResponseBeginBlock, error)
 	DeliverTx(context.Context, types.RequestDeliverTx) (*types.ResponseDeliverTx, error)
 	EndBlock(context.Context, types.RequestEndBlock) (*types.ResponseEndBlock, error)
@@ -85,6 +90,38 @@ func (app *appConnConsensus) InitChain(
 	return app.appConn.InitChain(ctx, req)
 }
 
+func (app *appConnConsensus) PrepareProposal(
+	ctx context.Context,
+	req types.RequestPrepareProposal,
+) (*types.ResponsePrepareProposal, error) {
+	defer addTimeSample(app.metrics.MethodTiming.With("method", "prepare_proposal", "type", "sync"))()
+	return app.appConn.PrepareProposal(ctx, req)
+}
+
+func (app *appConnConsensus) ProcessProposal(
+	ctx context.Context,
+	req types.RequestProcessProposal,
+) (*types.ResponseProcessProposal, error) {
+	defer addTimeSample(app.metrics.MethodTiming.With("method", "process_proposal", "type", "sync"))()
+	return app.appConn.ProcessProposal(ctx, req)
+}
+
+func (app *appConnConsensus) ExtendVote(
+	ctx context.Context,
+	req types.RequestExtendVote,
+) (*types.ResponseExtendVote, error) {
+	defer addTimeSample(app.metrics.MethodTiming.With("method", "extend_vote", "type", "sync"))()
+	return app.appConn.ExtendVote(ctx, req)
+}
+
+func (app *appConnConsensus) VerifyVoteExtension(
+	ctx context.Context,
+	req types.RequestVerifyVoteExtension,
+) (*types.ResponseVerifyVoteExtension, error) {
+	defer addTimeSample(app.metrics.MethodTiming.With("method", "verify_vote_extension", "type", "sync"))()
+	return app.appConn.VerifyVoteExtension(ctx, req)
+}
+
 func (app *appConnConsensus) BeginBlock(
 	ctx context.Context,
 	req types.RequestBeginBlock,
@@ -117,44 +154,88 @@ func (app *appConnConsensus) Commit(ctx context.Context) (*types.ResponseCommit,
 //------------------------------------------------
 // Implements AppConnMempool (subset of abciclient.Client)
 
+// appConnMempool spreads the CheckTx requests over one or more connections,
+// in turn. The responses of different connections may come in any order.
 type appConnMempool struct {
-	metrics *Metrics
-	appConn abciclient.Client
+	metrics  *Metrics
+	appConns []abciclient.Client
+	next     uint32 // atomic; the connection of the next CheckTx
 }
 
 func NewAppConnMempool(appConn abciclient.Client, metrics *Metrics) AppConnMempool {
+	return NewAppConnMempoolPool([]abciclient.Client{appConn}, metrics)
+}
+
+// NewAppConnMempoolPool returns an AppConnMempool which spreads the CheckTx
+// requests over appConns, which must not be empty.
+func NewAppConnMempoolPool(appConns []abciclient.Client, metrics *Metrics) AppConnMempool {
+	if len(appConns) == 0 {
+		panic("no mempool connection")
+	}
 	return &appConnMempool{
-		metrics: metrics,
-		appConn: appConn,
+		metrics:  metrics,
+		appConns: appConns,
 	}
 }
 
+// nextConn returns the connection of the next CheckTx request.
+func (app *appConnMempool) nextConn() abciclient.Client {
+	if len(app.appConns) == 1 {
+		return app.appConns[0]
+	}
+	i := atomic.AddUint32(&app.next, 1)
+	return app.appConns[int(i)%len(app.appConns)]
+}
+
 func (app *appConnMempool) SetResponseCallback(cb abciclient.Callback) {
-	app.appConn.SetResponseCallback(cb)
+	for _, appConn := range app.appConns {
+		appConn.SetResponseCallback(cb)
+	}
 }
 
 func (app *appConnMempool) Error() error {
-	return app.appConn.Error()
+	for _, appConn := range app.appConns {
+		if err := appConn.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// FlushAsync flushes all the connections, and returns the request of the
+// last one.
 func (app *appConnMempool) FlushAsync(ctx context.Context) (*abciclient.ReqRes, error) {
 	defer addTimeSample(app.metrics.MethodTiming.With("method", "flush", "type", "async"))()
-	return app.appConn.FlushAsync(ctx)
+
+	var reqRes *abciclient.ReqRes
+	for _, appConn := range app.appConns {
+		var err error
+		if reqRes, err = appConn.FlushAsync(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return reqRes, nil
 }
 
 func (app *appConnMempool) Flush(ctx context.Context) error {
 	defer addTimeSample(app.metrics.MethodTiming.With("method", "flush", "type", "sync"))()
-	return app.appConn.Flush(ctx)
+
+	for _, appConn := range app.appConns {
+		if err := appConn.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (app *appConnMempool) CheckTxAsync(ctx context.Context, req types.RequestCheckTx) (*abciclient.ReqRes, error) {
 	defer addTimeSample(app.metrics.MethodTiming.With("method", "check_tx", "type", "async"))()
-	return app.appConn.CheckTxAsync(ctx, req)
+	return app.nextConn().CheckTxAsync(ctx, req)
 }
 
 func (app *appConnMempool) CheckTx(ctx context.Context, req types.RequestCheckTx) (*types.ResponseCheckTx, error) {
 	defer addTimeSample(app.metrics.MethodTiming.With("method", "check_tx", "type", "sync"))()
-	return app.appConn.CheckTx(ctx, req)
+	return app.nextConn().CheckTx(ctx, req)
 }
 
 //------------------------------------------------
@@ -2,12 +2,15 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	abciclient "github.com/tendermint/tendermint/abci/client"
+	abcimocks "github.com/tendermint/tendermint/abci/client/mocks"
 	"github.com/tendermint/tendermint/abci/example/kvstore"
 	"github.com/tendermint/tendermint/abci/server"
 	"github.com/tendermint/tendermint/abci/types"
@@ -161,3 +164,38 @@ func TestInfo(t *testing.T) {
 		t.Error("Expected ResponseInfo with one element '{\"size\":0}' but got something else")
 	}
 }
+
+func TestAppConnMempoolPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The CheckTx requests are spread evenly over the connections.
+	clients := make([]abciclient.Client, 3)
+	for i := range clients {
+		client := &abcimocks.Client{}
+		client.On("CheckTxAsync", mock.Anything, mock.Anything).
+			Return(abciclient.NewReqRes(nil), nil).Times(2)
+		client.On("Flush", mock.Anything).Return(nil).Once()
+		client.On("Error").Return(nil).Once()
+		clients[i] = client
+	}
+	appConn := NewAppConnMempoolPool(clients, NopMetrics())
+
+	for i := 0; i < 2*len(clients); i++ {
+		_, err := appConn.CheckTxAsync(ctx, types.RequestCheckTx{Tx: []byte{byte(i)}})
+		require.NoError(t, err)
+	}
+	require.NoError(t, appConn.Flush(ctx))
+	require.NoError(t, appConn.Error())
+
+	for _, client := range clients {
+		client.(*abcimocks.Client).AssertExpectations(t)
+	}
+
+	// An error of any connection is the error of the pool.
+	failing := &abcimocks.Client{}
+	failing.On("Error").Return(errors.New("EOF"))
+	appConn = NewAppConnMempoolPool([]abciclient.Client{clients[0], failing}, NopMetrics())
+	clients[0].(*abcimocks.Client).On("Error").Return(nil)
+	require.Error(t, appConn.Error())
+}
@@ -35,8 +35,26 @@ type AppConns interface {
 }
 
 // NewAppConns calls NewMultiAppConn.
-func NewAppConns(clientCreator abciclient.Creator, logger log.Logger, metrics *Metrics) AppConns {
-	return NewMultiAppConn(clientCreator, logger, metrics)
+func NewAppConns(
+	clientCreator abciclient.Creator,
+	logger log.Logger,
+	metrics *Metrics,
+	options ...AppConnsOption,
+) AppConns {
+	return NewMultiAppConn(clientCreator, logger, metrics, options...)
+}
+
+// AppConnsOption sets an optional parameter on the multiAppConn.
+type AppConnsOption func(*multiAppConn)
+
+// WithMempoolConnections sets the number of connections of the mempool, over
+// which its CheckTx requests are spread. It is one by default.
+func WithMempoolConnections(n int) AppConnsOption {
+	return func(app *multiAppConn) {
+		if n > 0 {
+			app.mempoolConnections = n
+		}
+	}
 }
 
 // multiAppConn implements AppConns.
@@ -55,11 +73,12 @@ type multiAppConn struct {
 	snapshotConn  AppConnSnapshot
 
 	consensusConnClient stoppableClient
-	mempoolConnClient   stoppableClient
+	mempoolConnClients  []stoppableClient
 	queryConnClient     stoppableClient
 	snapshotConnClient  stoppableClient
 
-	clientCreator abciclient.Creator
+	clientCreator      abciclient.Creator
+	mempoolConnections int
 }
 
 // TODO: this is a totally internal and quasi permanent shim for
@@ -71,11 +90,20 @@ type stoppableClient interface {
 }
 
 // NewMultiAppConn makes all necessary abci connections to the application.
-func NewMultiAppConn(clientCreator abciclient.Creator, logger log.Logger, metrics *Metrics) AppConns {
+func NewMultiAppConn(
+	clientCreator abciclient.Creator,
+	logger log.Logger,
+	metrics *Metrics,
+	options ...AppConnsOption,
+) AppConns {
 	multiAppConn := &multiAppConn{
-		logger:        logger,
-		metrics:       metrics,
-		clientCreator: clientCreator,
+		logger:             logger,
+		metrics:            metrics,
+		clientCreator:      clientCreator,
+		mempoolConnections: 1,
+	}
+	for _, option := range options {
+		option(multiAppConn)
 	}
 	multiAppConn.BaseService = *service.NewBaseService(logger, "multiAppConn", multiAppConn)
 	return multiAppConn
@@ -113,13 +141,17 @@ func (app *multiAppConn) OnStart(ctx context.Context) error {
 	app.snapshotConnClient = c.(stoppableClient)
 	app.snapshotConn = NewAppConnSnapshot(c, app.metrics)
 
-	c, err = app.abciClientFor(ctx, connMempool)
-	if err != nil {
-		app.stopAllClients()
-		return err
+	mempoolClients := make([]abciclient.Client, 0, app.mempoolConnections)
+	for i := 0; i < app.mempoolConnections; i++ {
+		c, err = app.abciClientFor(ctx, connMempool)
+		if err != nil {
+			app.stopAllClients()
+			return err
+		}
+		app.mempoolConnClients = append(app.mempoolConnClients, c.(stoppableClient))
+		mempoolClients = append(mempoolClients, c)
 	}
-	app.mempoolConnClient = c.(stoppableClient)
-	app.mempoolConn = NewAppConnMempool(c, app.metrics)
+	app.mempoolConn = NewAppConnMempoolPool(mempoolClients, app.metrics)
 
 	c, err = app.abciClientFor(ctx, connConsensus)
 	if err != nil {
@@ -159,15 +191,11 @@ func (app *multiAppConn) startWatchersForClientErrorToKillTendermint(ctx context
 		name       string
 	}
 
-	for _, client := range []op{
+	clients := []op{
 		{
 			connClient: app.consensusConnClient,
 			name:       connConsensus,
 		},
-		{
-			connClient: app.mempoolConnClient,
-			name:       connMempool,
-		},
 		{
 			connClient: app.queryConnClient,
 			name:       connQuery,
@@ -176,7 +204,12 @@ func (app *multiAppConn) startWatchersForClientErrorToKillTendermint(ctx context
 			connClient: app.snapshotConnClient,
 			name:       connSnapshot,
 		},
-	} {
+	}
+	for _, client := range app.mempoolConnClients {
+		clients = append(clients, op{connClient: client, name: connMempool})
+	}
+
+	for _, client := range clients {
 		go func(name string, client stoppableClient) {
 			client.Wait()
 			if ctx.Err() != nil {
@@ -197,8 +230,8 @@ func (app *multiAppConn) stopAllClients() {
 			}
 		}
 	}
-	if app.mempoolConnClient != nil {
-		if err := app.mempoolConnClient.Stop(); err != nil {
+	for _, client := range app.mempoolConnClients {
+		if err := client.Stop(); err != nil {
 			if !errors.Is(err, service.ErrAlreadyStopped) {
 				app.logger.Error("error while stopping mempool client", "error", err)
 			}
@@ -56,6 +56,38 @@ func TestAppConns_Start_Stop(t *testing.T) {
 	assert.Equal(t, 4, creatorCallCount)
 }
 
+func TestAppConns_MempoolConnections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientMock := &abcimocks.Client{}
+	clientMock.On("Start", mock.Anything).Return(nil).Times(6)
+	clientMock.On("Error").Return(nil)
+	clientMock.On("Wait").Return(nil).Times(6)
+	cl := &noopStoppableClientImpl{Client: clientMock}
+
+	creatorCallCount := 0
+	creator := func(logger log.Logger) (abciclient.Client, error) {
+		creatorCallCount++
+		return cl, nil
+	}
+
+	appConns := NewAppConns(creator, log.TestingLogger(), NopMetrics(), WithMempoolConnections(3))
+
+	err := appConns.Start(ctx)
+	require.NoError(t, err)
+	require.Len(t, appConns.Mempool().(*appConnMempool).appConns, 3)
+
+	time.Sleep(100 * time.Millisecond)
+
+	cancel()
+	appConns.Wait()
+
+	clientMock.AssertExpectations(t)
+	assert.Equal(t, 6, cl.count)
+	assert.Equal(t, 6, creatorCallCount)
+}
+
 // Upon failure, we call tmos.Kill
 func TestAppConns_Failure(t *testing.T) {
 	ok := make(chan struct{})
@@ -0,0 +1,97 @@
+package pubsub
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/abci/types"
+)
+
+// LogEntry is a message retained by the event log of a server.
+type LogEntry struct {
+	Seq    uint64
+	Time   time.Time
+	Data   interface{}
+	Events []types.Event
+}
+
+// LogStore retains the messages of the event log of a server. The server
+// appends the messages in the order of their sequence numbers, and prunes the
+// store after each append. A LogStore must be safe for concurrent use: the
+// subscriptions catching up read it while the server appends to it.
+type LogStore interface {
+	// Append adds e to the store.
+	Append(e LogEntry) error
+
+	// Scan calls fn with the entries whose sequence number is greater than
+	// after, oldest first, until fn returns false.
+	Scan(after uint64, fn func(LogEntry) bool) error
+
+	// Bounds returns the sequence numbers of the oldest and newest entries
+	// retained, or zeroes if the store is empty.
+	Bounds() (first, last uint64, err error)
+
+	// Prune discards the entries published before cutoff, and the oldest
+	// entries in excess of maxItems if it is positive.
+	Prune(cutoff time.Time, maxItems int) error
+}
+
+// memoryLog is the default LogStore, which retains the entries in memory.
+type memoryLog struct {
+	mtx     sync.RWMutex
+	entries []LogEntry
+}
+
+func (l *memoryLog) Append(e LogEntry) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.entries = append(l.entries, e)
+	return nil
+}
+
+func (l *memoryLog) Scan(after uint64, fn func(LogEntry) bool) error {
+	l.mtx.RLock()
+	entries := l.entries
+	l.mtx.RUnlock()
+
+	// The entries are appended to a copy when pruned, so the slice taken
+	// under the lock is not modified.
+	for _, e := range entries {
+		if e.Seq <= after {
+			continue
+		}
+		if !fn(e) {
+			break
+		}
+	}
+	return nil
+}
+
+func (l *memoryLog) Bounds() (first, last uint64, err error) {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	if len(l.entries) == 0 {
+		return 0, 0, nil
+	}
+	return l.entries[0].Seq, l.entries[len(l.entries)-1].Seq, nil
+}
+
+func (l *memoryLog) Prune(cutoff time.Time, maxItems int) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	entries := l.entries
+	n := 0
+	for n < len(entries) && entries[n].Time.Before(cutoff) {
+		n++
+	}
+	if maxItems > 0 && len(entries)-n > maxItems {
+		n = len(entries) - maxItems
+	}
+	if n > 0 {
+		// Copy the remaining entries, so that the discarded ones can be
+		// collected, and the slices being scanned are left untouched.
+		l.entries = append([]LogEntry(nil), entries[n:]...)
+	}
+	return nil
+}
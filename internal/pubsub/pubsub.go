@@ -39,6 +39,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/libs/log"
@@ -57,6 +58,11 @@ var (
 	// ErrServerStopped is returned when attempting to publish or subscribe to a
 	// server that has been stopped.
 	ErrServerStopped = errors.New("pubsub server is stopped")
+
+	// ErrCannotResume is returned when a client tries to resume a
+	// subscription after a message, and the messages published since then are
+	// not retained by the event log of the server.
+	ErrCannotResume = errors.New("cannot resume subscription")
 )
 
 // Query defines an interface for a query to be used for subscribing. A query
@@ -76,6 +82,20 @@ type SubscribeArgs struct {
 	Query    Query  // filter query for events (required)
 	Limit    int    // subscription queue capacity limit (0 means 1)
 	Quota    int    // subscription queue soft quota (0 uses Limit)
+
+	// If non-zero, the sequence number of the last message received by the
+	// client: the retained messages published after it that match the query
+	// are delivered first, so that the client does not miss any message.
+	// This requires the server to have an event log (see EventLog).
+	After uint64
+
+	// If true, the subscription is paused rather than terminated when its
+	// queue is full: the messages published meanwhile are read back from the
+	// event log once the subscriber has emptied the queue, as with
+	// PersistentEventLog, if the server has one, and are skipped otherwise
+	// (see Subscription.Skipped). The subscription is still terminated if it
+	// falls behind the retention of the event log.
+	Pause bool
 }
 
 // UnsubscribeArgs are the parameters to remove a subscription.
@@ -122,8 +142,20 @@ type Server struct {
 		// before it is delivered to any other subscriber. This allows an index
 		// to be persisted before any subscribers see the messages.
 		observe func(Message) error
+
+		// The sequence number of the last message published. It is only
+		// modified by the sender, with the lock held shared.
+		seq uint64
 	}
 
+	log         LogStore      // the event log; nil if disabled
+	logWindow   time.Duration // how long messages are retained
+	logMaxItems int           // maximum number of messages retained; 0 for no limit
+
+	// Whether the subscriptions whose queue is full catch up from the event
+	// log instead of being terminated (see PersistentEventLog).
+	catchUp bool
+
 	// TODO(creachadair): Rework the options so that this does not need to live
 	// as a field. It is not otherwise needed.
 	queueCap int
@@ -149,6 +181,22 @@ func NewServer(logger log.Logger, options ...Option) *Server {
 	// The index tracks subscriptions by ID and query terms.
 	s.subs.index = newSubIndex()
 
+	// Sequence numbers start from the creation time of the server, so that
+	// they keep increasing across restarts, and the cursor of a client of a
+	// previous server is reported as expired instead of being misinterpreted.
+	// A persistent event log carries on from its last message instead, so
+	// that the clients can resume after it.
+	s.subs.seq = uint64(time.Now().UnixNano())
+	if s.logWindow != 0 && s.log == nil {
+		s.log = &memoryLog{}
+	} else if s.log != nil {
+		if _, last, err := s.log.Bounds(); err != nil {
+			logger.Error("failed to load the bounds of the event log", "err", err)
+		} else if last != 0 {
+			s.subs.seq = last
+		}
+	}
+
 	return s
 }
 
@@ -163,6 +211,40 @@ func BufferCapacity(cap int) Option {
 	return func(s *Server) { s.queueCap = cap }
 }
 
+// EventLog enables the event log of the server, which retains the published
+// messages for the given window of time, up to maxItems messages (0 means no
+// limit), so that a client can resume a subscription without missing the
+// messages published in between (see SubscribeArgs). This function will panic
+// if window <= 0 or maxItems < 0.
+func EventLog(window time.Duration, maxItems int) Option {
+	if window <= 0 {
+		panic("non-positive event log window")
+	} else if maxItems < 0 {
+		panic("negative event log size")
+	}
+	return func(s *Server) {
+		s.logWindow = window
+		s.logMaxItems = maxItems
+	}
+}
+
+// PersistentEventLog makes the event log of the server, enabled with
+// EventLog, retain its messages in store rather than in memory, e.g. on disk
+// so that the clients can resume their subscriptions across restarts of the
+// server. The sequence numbers carry on from the last message of store.
+//
+// The subscriptions of the server are then delivered their messages at least
+// once: when the queue of a subscription is full, its messages are not
+// dropped but read back from the event log once the subscriber has caught
+// up with its queue. The subscription is only terminated if it falls behind
+// the retention of the log.
+func PersistentEventLog(store LogStore) Option {
+	return func(s *Server) {
+		s.log = store
+		s.catchUp = true
+	}
+}
+
 // BufferCapacity returns capacity of the publication queue.
 func (s *Server) BufferCapacity() int { return cap(s.queue) }
 
@@ -228,6 +310,11 @@ func (s *Server) Observe(ctx context.Context, observe func(Message) error, queri
 // SubscribeWithArgs creates a subscription for the given arguments.  It is an
 // error if the query is nil, a subscription already exists for the specified
 // client ID and query, or if the capacity arguments are invalid.
+//
+// If args.After is set, the subscription first delivers the retained messages
+// published after it that match the query. It is an error if the server has no
+// event log, or if some of these messages are no longer retained, with an error
+// wrapping ErrCannotResume.
 func (s *Server) SubscribeWithArgs(ctx context.Context, args SubscribeArgs) (*Subscription, error) {
 	if args.Query == nil {
 		return nil, errors.New("query is nil")
@@ -244,10 +331,33 @@ func (s *Server) SubscribeWithArgs(ctx context.Context, args SubscribeArgs) (*Su
 	if args.Limit == 0 {
 		args.Limit = 1
 	}
+	if s.catchUp || (args.Pause && s.log != nil) {
+		return s.subscribeCatchUp(args)
+	}
+	var backlog []Message
+	if args.After != 0 {
+		var err error
+		backlog, err = s.replay(args.After, args.Query)
+		if err != nil {
+			return nil, err
+		}
+		// Make room for the backlog on top of the requested capacity.
+		args.Limit += len(backlog)
+		if args.Quota != 0 {
+			args.Quota += len(backlog)
+		}
+	}
 	sub, err := newSubscription(args.Quota, args.Limit)
 	if err != nil {
 		return nil, err
 	}
+	sub.pause = args.Pause
+	for _, msg := range backlog {
+		msg.subID = sub.id
+		if err := sub.publish(msg); err != nil {
+			return nil, err
+		}
+	}
 	s.subs.index.add(&subInfo{
 		clientID: args.ClientID,
 		query:    args.Query,
@@ -257,6 +367,125 @@ func (s *Server) SubscribeWithArgs(ctx context.Context, args SubscribeArgs) (*Su
 	return sub, nil
 }
 
+// subscribeCatchUp creates a subscription catching up from the event log
+// when its queue is full, for a server with a persistent event log. If
+// args.After is set, the subscription starts behind, from that message. The
+// caller must hold the s.subs lock exclusively.
+func (s *Server) subscribeCatchUp(args SubscribeArgs) (*Subscription, error) {
+	cursor := s.subs.seq
+	if args.After != 0 {
+		if err := s.checkResume(args.After); err != nil {
+			return nil, err
+		}
+		cursor = args.After
+	}
+	sub, err := newSubscription(args.Quota, args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	sub.catchUp = &catchUp{
+		read: func(after uint64, max int) ([]Message, uint64, bool, error) {
+			return s.read(after, args.Query, max)
+		},
+		limit:   args.Limit,
+		cursor:  cursor,
+		lagging: cursor < s.subs.seq,
+	}
+	s.subs.index.add(&subInfo{
+		clientID: args.ClientID,
+		query:    args.Query,
+		subID:    sub.id,
+		sub:      sub,
+	})
+	return sub, nil
+}
+
+// checkResume reports an error wrapping ErrCannotResume if the messages
+// published after the sequence number after are not all retained by the event
+// log. The caller must hold the s.subs lock exclusively.
+func (s *Server) checkResume(after uint64) error {
+	if s.log == nil {
+		return fmt.Errorf("%w: the event log is disabled", ErrCannotResume)
+	} else if after > s.subs.seq {
+		return fmt.Errorf("%w: cursor %d is ahead of the last message %d",
+			ErrCannotResume, after, s.subs.seq)
+	}
+	if after == s.subs.seq {
+		return nil // nothing was missed
+	}
+	if err := s.pruneLog(time.Now()); err != nil {
+		return err
+	}
+	return s.checkRetained(after)
+}
+
+// checkRetained reports an error wrapping ErrCannotResume if the messages
+// published after the sequence number after were discarded from the event
+// log.
+func (s *Server) checkRetained(after uint64) error {
+	first, _, err := s.log.Bounds()
+	if err != nil {
+		return fmt.Errorf("loading the bounds of the event log: %w", err)
+	}
+	if first == 0 || first > after+1 {
+		return fmt.Errorf("%w: the messages after cursor %d are no longer retained",
+			ErrCannotResume, after)
+	}
+	return nil
+}
+
+// replay returns the messages of the event log published after the sequence
+// number after that match query. The caller must hold the s.subs lock
+// exclusively.
+func (s *Server) replay(after uint64, query Query) ([]Message, error) {
+	if err := s.checkResume(after); err != nil {
+		return nil, err
+	} else if after == s.subs.seq {
+		return nil, nil // nothing was missed
+	}
+	out, _, _, err := s.read(after, query, 0)
+	return out, err
+}
+
+// read returns, oldest first, at most max messages of the event log (0 means
+// no limit) published after the sequence number after that match query, the
+// sequence number of the last message read, and whether the end of the log
+// was reached. It is an error wrapping ErrCannotResume if some of these
+// messages were discarded from the log.
+func (s *Server) read(after uint64, query Query, max int) (msgs []Message, last uint64, done bool, err error) {
+	if err := s.checkRetained(after); err != nil {
+		return nil, after, false, err
+	}
+
+	last, done = after, true
+	var matchErr error
+	err = s.log.Scan(after, func(e LogEntry) bool {
+		if max > 0 && len(msgs) == max {
+			done = false
+			return false
+		}
+		last = e.Seq
+		match, err := query.Matches(e.Events)
+		if err != nil {
+			matchErr = fmt.Errorf("match failed against query: %w", err)
+			return false
+		} else if match {
+			msgs = append(msgs, Message{seq: e.Seq, data: e.Data, events: e.Events})
+		}
+		return true
+	})
+	if err == nil {
+		err = matchErr
+	}
+	return msgs, last, done, err
+}
+
+// pruneLog discards the messages of the event log that are older than the log
+// window at now, or in excess of its maximum size.
+func (s *Server) pruneLog(now time.Time) error {
+	return s.log.Prune(now.Add(-s.logWindow), s.logMaxItems)
+}
+
 // Unsubscribe removes the subscription for the given client and/or query.  It
 // returns ErrSubscriptionNotFound if no such subscription exists.
 func (s *Server) Unsubscribe(ctx context.Context, args UnsubscribeArgs) error {
@@ -426,11 +655,33 @@ func (s *Server) send(data interface{}, events []types.Event) error {
 	s.subs.RLock()
 	defer s.subs.RUnlock()
 
+	// The sender is the only writer of the sequence number and the event log,
+	// and subscribers take the lock exclusively to read them.
+	s.subs.seq++
+	seq := s.subs.seq
+	if s.log != nil {
+		// The message is appended before it is delivered, so that the
+		// subscriptions catching up from the log find it there if they
+		// miss it.
+		now := time.Now()
+		if err := s.log.Append(LogEntry{
+			Seq:    seq,
+			Time:   now,
+			Data:   data,
+			Events: events,
+		}); err != nil {
+			s.logger.Error("failed to append to the event log", "seq", seq, "err", err)
+		} else if err := s.pruneLog(now); err != nil {
+			s.logger.Error("failed to prune the event log", "err", err)
+		}
+	}
+
 	// If an observer is defined, give it control of the message before
 	// attempting to deliver it to any matching subscribers. If the observer
 	// fails, the message will not be forwarded.
 	if s.subs.observe != nil {
 		err := s.subs.observe(Message{
+			seq:    seq,
 			data:   data,
 			events: events,
 		})
@@ -453,6 +704,7 @@ func (s *Server) send(data interface{}, events []types.Event) error {
 		// subscription from the index.
 		if err := si.sub.publish(Message{
 			subID:  si.sub.id,
+			seq:    seq,
 			data:   data,
 			events: events,
 		}); err != nil {
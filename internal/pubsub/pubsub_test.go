@@ -155,6 +155,59 @@ func TestSlowSubscriber(t *testing.T) {
 	sub.mustFail(ctx, pubsub.ErrTerminated)
 }
 
+func TestPausedSubscriber(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.TestingLogger()
+
+	// Without an event log, the messages that don't fit are skipped.
+	s := newTestServer(ctx, t, logger)
+	sub := newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: clientID,
+		Query:    query.MustCompile(`villain.name EXISTS`),
+		Pause:    true,
+	}))
+	// The messages are delivered in order: once the barrier is received, the
+	// messages published before were delivered to sub.
+	barrier := newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: "barrier",
+		Query:    query.MustCompile(`barrier.name EXISTS`),
+	}))
+	publish := func(kind, name string) {
+		require.NoError(t, s.PublishWithEvents(ctx, name, []abci.Event{{
+			Type:       kind,
+			Attributes: []abci.EventAttribute{{Key: "name", Value: name}},
+		}}))
+	}
+	for _, name := range []string{"Fat Cobra", "Viper", "Black Panther"} {
+		publish("villain", name)
+	}
+	publish("barrier", "Wall")
+	barrier.mustReceive(ctx, "Wall")
+	require.Equal(t, uint64(2), sub.Skipped())
+	sub.mustReceive(ctx, "Fat Cobra")
+	publish("villain", "Bullseye")
+	sub.mustReceive(ctx, "Bullseye")
+
+	// With an event log, they are read back from it.
+	s = pubsub.NewServer(logger, pubsub.EventLog(time.Minute, 0))
+	require.NoError(t, s.Start(ctx))
+	t.Cleanup(s.Wait)
+	sub = newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: clientID,
+		Query:    query.All,
+		Pause:    true,
+	}))
+	require.NoError(t, s.Publish(ctx, "Fat Cobra"))
+	require.NoError(t, s.Publish(ctx, "Viper"))
+	require.NoError(t, s.Publish(ctx, "Black Panther"))
+	sub.mustReceive(ctx, "Fat Cobra")
+	sub.mustReceive(ctx, "Viper")
+	sub.mustReceive(ctx, "Black Panther")
+	require.Zero(t, sub.Skipped())
+}
+
 func TestDifferentClients(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -415,6 +468,113 @@ func TestBufferCapacity(t *testing.T) {
 	require.ErrorIs(t, s.Publish(ctx, "Ironclad"), context.DeadlineExceeded)
 }
 
+func TestEventLog(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := pubsub.NewServer(log.TestingLogger(), pubsub.EventLog(time.Minute, 3))
+	require.NoError(t, s.Start(ctx))
+	t.Cleanup(s.Wait)
+
+	sub := newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: clientID,
+		Query:    query.All,
+		Limit:    10,
+	}))
+	var seqs []uint64
+	for _, name := range []string{"Wolverine", "Storm", "Cyclops", "Rogue"} {
+		require.NoError(t, s.PublishWithEvents(ctx, name, []abci.Event{{
+			Type:       "xmen",
+			Attributes: []abci.EventAttribute{{Key: "name", Value: name}},
+		}}))
+		msg, err := sub.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, name, msg.Data())
+		if len(seqs) > 0 {
+			require.Equal(t, seqs[len(seqs)-1]+1, msg.Seq())
+		}
+		seqs = append(seqs, msg.Seq())
+	}
+
+	// The log retains the last 3 messages.
+	resumed := newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: "resumed",
+		Query:    query.All,
+		After:    seqs[0],
+	}))
+	resumed.mustReceive(ctx, "Storm")
+	resumed.mustReceive(ctx, "Cyclops")
+	resumed.mustReceive(ctx, "Rogue")
+	require.NoError(t, s.Publish(ctx, "Beast"))
+	resumed.mustReceive(ctx, "Beast")
+	sub.mustReceive(ctx, "Beast")
+
+	// Only the messages matching the query are replayed.
+	filtered := newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: "filtered",
+		Query:    query.MustCompile(`xmen.name='Rogue'`),
+		After:    seqs[1],
+	}))
+	msg, err := filtered.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Rogue", msg.Data())
+	require.Equal(t, seqs[3], msg.Seq())
+	require.Equal(t, filtered.ID(), msg.SubscriptionID())
+	filtered.mustTimeOut(ctx, 50*time.Millisecond)
+
+	// Storm was discarded from the log when Beast was published.
+	_, err = s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: "expired",
+		Query:    query.All,
+		After:    seqs[0],
+	})
+	require.ErrorIs(t, err, pubsub.ErrCannotResume)
+	require.Equal(t, 3, s.NumClients(), "a failed resume does not subscribe")
+
+	_, err = s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: "ahead",
+		Query:    query.All,
+		After:    seqs[3] + 10,
+	})
+	require.ErrorIs(t, err, pubsub.ErrCannotResume)
+
+	// A server without an event log cannot resume subscriptions.
+	_, err = newTestServer(ctx, t, log.TestingLogger()).SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: clientID,
+		Query:    query.All,
+		After:    seqs[0],
+	})
+	require.ErrorIs(t, err, pubsub.ErrCannotResume)
+}
+
+func TestEventLogWindow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := pubsub.NewServer(log.TestingLogger(), pubsub.EventLog(10*time.Millisecond, 0))
+	require.NoError(t, s.Start(ctx))
+	t.Cleanup(s.Wait)
+
+	sub := newTestSub(t).must(s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: clientID,
+		Query:    query.All,
+		Limit:    10,
+	}))
+	require.NoError(t, s.Publish(ctx, "Gambit"))
+	first, err := sub.Next(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.Publish(ctx, "Jubilee"))
+	sub.mustReceive(ctx, "Jubilee")
+
+	time.Sleep(20 * time.Millisecond)
+	_, err = s.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: "resumed",
+		Query:    query.All,
+		After:    first.Seq(),
+	})
+	require.ErrorIs(t, err, pubsub.ErrCannotResume)
+}
+
 func newTestServer(ctx context.Context, t testing.TB, logger log.Logger) *pubsub.Server {
 	t.Helper()
 
@@ -56,6 +56,14 @@ func MustCompile(query string) *Query {
 
 // Compile compiles the given query AST so it can be used to match events.
 func Compile(ast syntax.Query) (*Query, error) {
+	conds, err := compileConditions(ast)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{ast: ast, conds: conds}, nil
+}
+
+func compileConditions(ast syntax.Query) ([]condition, error) {
 	conds := make([]condition, len(ast))
 	for i, q := range ast {
 		cond, err := compileCondition(q)
@@ -64,7 +72,7 @@ func Compile(ast syntax.Query) (*Query, error) {
 		}
 		conds[i] = cond
 	}
-	return &Query{ast: ast, conds: conds}, nil
+	return conds, nil
 }
 
 // Matches satisfies part of the pubsub.Query interface.  This implementation
@@ -94,20 +102,32 @@ func (q *Query) Syntax() syntax.Query {
 
 // matchesEvents reports whether all the conditions match the given events.
 func (q *Query) matchesEvents(events []types.Event) bool {
-	for _, cond := range q.conds {
+	return matchesAll(q.conds, events) && len(events) != 0
+}
+
+// matchesAll reports whether all the conditions match the given events.
+func matchesAll(conds []condition, events []types.Event) bool {
+	for _, cond := range conds {
 		if !cond.matchesAny(events) {
 			return false
 		}
 	}
-	return len(events) != 0
+	return true
 }
 
 // A condition is a compiled match condition.  A condition matches an event if
 // the event has the designated type, contains an attribute with the given
 // name, and the match function returns true for the attribute value.
+//
+// The conditions combining subqueries match the events as a whole instead:
+// an OR condition if all the conditions of any of its alternatives match, and
+// a NOT condition if the conditions of its operand do not all match.
 type condition struct {
 	tag   string // e.g., "tx.hash"
 	match func(s string) bool
+
+	alternatives [][]condition // OR
+	negated      []condition   // NOT
 }
 
 // findAttr returns a slice of attribute values from event matching the
@@ -131,6 +151,17 @@ func (c condition) findAttr(event types.Event) ([]string, bool) {
 
 // matchesAny reports whether c matches at least one of the given events.
 func (c condition) matchesAny(events []types.Event) bool {
+	switch {
+	case c.alternatives != nil:
+		for _, alt := range c.alternatives {
+			if matchesAll(alt, events) {
+				return true
+			}
+		}
+		return false
+	case c.negated != nil:
+		return !matchesAll(c.negated, events)
+	}
 	for _, event := range events {
 		if c.matchesEvent(event) {
 			return true
@@ -164,11 +195,55 @@ func (c condition) matchesEvent(event types.Event) bool {
 func compileCondition(cond syntax.Condition) (condition, error) {
 	out := condition{tag: cond.Tag}
 
-	// Handle existence checks separately to simplify the logic below for
-	// comparisons that take arguments.
-	if cond.Op == syntax.TExists {
+	// Handle existence checks and the conditions without a single argument
+	// separately to simplify the logic below for comparisons that take one.
+	switch cond.Op {
+	case syntax.TExists:
 		out.match = func(string) bool { return true }
 		return out, nil
+	case syntax.TOr:
+		if len(cond.Sub) == 0 {
+			return condition{}, fmt.Errorf("missing operands for %v", cond.Op)
+		}
+		for _, sub := range cond.Sub {
+			alt, err := compileConditions(sub)
+			if err != nil {
+				return condition{}, err
+			}
+			out.alternatives = append(out.alternatives, alt)
+		}
+		return out, nil
+	case syntax.TNot:
+		if len(cond.Sub) != 1 || len(cond.Sub[0]) == 0 {
+			return condition{}, fmt.Errorf("%v requires a single operand", cond.Op)
+		}
+		negated, err := compileConditions(cond.Sub[0])
+		if err != nil {
+			return condition{}, err
+		}
+		out.negated = negated
+		return out, nil
+	case syntax.TIn:
+		if len(cond.Args) == 0 {
+			return condition{}, fmt.Errorf("missing arguments for %v", cond.Op)
+		}
+		var matches []func(string) bool
+		for _, alt := range cond.Alternatives() {
+			eq, err := compileCondition(alt[0])
+			if err != nil {
+				return condition{}, err
+			}
+			matches = append(matches, eq.match)
+		}
+		out.match = func(s string) bool {
+			for _, match := range matches {
+				if match(s) {
+					return true
+				}
+			}
+			return false
+		}
+		return out, nil
 	}
 
 	// All the other operators require an argument.
@@ -186,6 +186,45 @@ func TestCompiledMatches(t *testing.T) {
 			apiEvents, false},
 		{`tm.event = 'Tx' AND rewards.withdraw.source = 'W'`,
 			apiEvents, false},
+
+		// OR, NOT and IN.
+		{`tm.event = 'Tx' AND (transfer.sender = 'AddrZ' OR rewards.withdraw.source = 'SrcY')`,
+			apiEvents, true},
+		{`transfer.sender = 'AddrZ' OR rewards.withdraw.source = 'W'`,
+			apiEvents, false},
+		{`transfer.sender = 'AddrZ' OR transfer.amount > 100 AND transfer.amount < 200`,
+			apiEvents, true},
+		{`(transfer.sender = 'AddrZ' OR transfer.amount > 100) AND transfer.amount < 150`,
+			apiEvents, false},
+		{`tm.event = 'Tx' AND NOT transfer.sender = 'AddrZ'`,
+			apiEvents, true},
+		{`tm.event = 'Tx' AND NOT transfer.sender = 'AddrC'`,
+			apiEvents, false},
+		{`NOT (transfer.sender = 'AddrC' AND transfer.recipient = 'AddrZ')`,
+			apiEvents, true},
+		{`NOT slash EXISTS`,
+			apiEvents, true},
+		{`NOT NOT slash EXISTS`,
+			apiEvents, false},
+		{`rewards.withdraw.address IN ('AddrZ', 'AddrB')`,
+			apiEvents, true},
+		{`rewards.withdraw.address IN ('AddrY', 'AddrZ')`,
+			apiEvents, false},
+		{`transfer.amount IN (100, 160)`,
+			apiEvents, true},
+		{`tx.date IN (DATE 2016-01-01, DATE 2017-01-01)`,
+			newTestEvents(`tx|date=` + txDate),
+			true},
+		{`NOT transfer.amount IN (100, 150)`,
+			apiEvents, true},
+
+		// Inclusive and exclusive ranges.
+		{`transfer.amount >= 160 AND transfer.amount <= 160`,
+			apiEvents, true},
+		{`transfer.amount > 160 AND transfer.amount <= 200`,
+			apiEvents, false},
+		{`transfer.amount >= 100 AND transfer.amount < 160`,
+			apiEvents, false},
 	}
 
 	// NOTE: The original implementation allowed arbitrary prefix matches on
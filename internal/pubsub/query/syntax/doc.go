@@ -5,14 +5,23 @@
 //
 // The grammar of the query language is defined by the following EBNF:
 //
-//   query      = conditions EOF
-//   conditions = condition {"AND" condition}
-//   condition  = tag comparison
-//   comparison = equal / order / contains / "EXISTS"
-//   equal      = "=" (date / number / time / value)
-//   order      = cmp (date / number / time)
-//   contains   = "CONTAINS" value
-//   cmp        = "<" / "<=" / ">" / ">="
+//   query       = disjunction EOF
+//   disjunction = conjunction {"OR" conjunction}
+//   conjunction = term {"AND" term}
+//   term        = "NOT" term / "(" disjunction ")" / condition
+//   condition   = tag comparison
+//   comparison  = equal / order / contains / in / "EXISTS"
+//   equal       = "=" (date / number / time / value)
+//   order       = cmp (date / number / time)
+//   contains    = "CONTAINS" value
+//   in          = "IN" "(" arg {"," arg} ")"
+//   arg         = date / number / time / value
+//   cmp         = "<" / "<=" / ">" / ">="
+//
+// AND binds tighter than OR. A range is the conjunction of a lower and an
+// upper bound, each of them inclusive or exclusive:
+//
+//   transfer.amount >= 100 AND transfer.amount < 200
 //
 // The lexical terms are defined here using RE2 regular expression notation:
 //
@@ -20,6 +20,10 @@ func Parse(s string) (Query, error) {
 type Query []Condition
 
 func (q Query) String() string {
+	if len(q) == 1 && q[0].Op == TOr {
+		// The parentheses of a single disjunction are implied.
+		return q[0].joinSub(" OR ")
+	}
 	ss := make([]string, len(q))
 	for i, cond := range q {
 		ss[i] = cond.String()
@@ -30,15 +34,37 @@ func (q Query) String() string {
 // A Condition is a single conditional expression, consisting of a tag, a
 // comparison operator, and an optional argument. The type of the argument
 // depends on the operator.
+//
+// The IN operator has a list of arguments, in Args, instead. The OR and NOT
+// operators have no tag, and combine subqueries, in Sub: an OR condition
+// matches if any of its subqueries does, and a NOT condition, with a single
+// subquery, matches if its subquery does not.
 type Condition struct {
-	Tag string
-	Op  Token
-	Arg *Arg
+	Tag  string
+	Op   Token
+	Arg  *Arg
+	Args []*Arg
+	Sub  []Query
 
 	opText string
 }
 
 func (c Condition) String() string {
+	switch c.Op {
+	case TOr:
+		return "(" + c.joinSub(" OR ") + ")"
+	case TNot:
+		if len(c.Sub) == 1 && len(c.Sub[0]) == 1 {
+			return "NOT " + c.Sub[0][0].String()
+		}
+		return "NOT (" + c.joinSub(" AND ") + ")"
+	case TIn:
+		ss := make([]string, len(c.Args))
+		for i, arg := range c.Args {
+			ss[i] = arg.String()
+		}
+		return c.Tag + " IN (" + strings.Join(ss, ", ") + ")"
+	}
 	s := c.Tag + " " + c.opText
 	if c.Arg != nil {
 		return s + " " + c.Arg.String()
@@ -46,6 +72,33 @@ func (c Condition) String() string {
 	return s
 }
 
+func (c Condition) joinSub(sep string) string {
+	ss := make([]string, len(c.Sub))
+	for i, q := range c.Sub {
+		ss[i] = q.String()
+	}
+	return strings.Join(ss, sep)
+}
+
+// Alternatives returns the subqueries of an OR condition, or the equality
+// conditions of the arguments of an IN condition, as single-condition
+// queries, so that the condition matches if any of them does. It returns nil
+// for the other conditions.
+func (c Condition) Alternatives() []Query {
+	switch c.Op {
+	case TOr:
+		return c.Sub
+	case TIn:
+		alts := make([]Query, len(c.Args))
+		for i, arg := range c.Args {
+			alts[i] = Query{{Tag: c.Tag, Op: TEq, Arg: arg, opText: "="}}
+		}
+		return alts
+	default:
+		return nil
+	}
+}
+
 // An Arg is the argument of a comparison operator.
 type Arg struct {
 	Type Token
@@ -113,6 +166,11 @@ func (a *Arg) Value() string {
 // defined in the syntax package documentation.
 type Parser struct {
 	scanner *Scanner
+
+	// If backed is true, the current token of the scanner is returned again
+	// by the next call to next, with its error err.
+	backed bool
+	err    error
 }
 
 // NewParser constructs a new parser that reads the input from r.
@@ -122,22 +180,79 @@ func NewParser(r io.Reader) *Parser {
 
 // Parse parses the complete input and returns the resulting query.
 func (p *Parser) Parse() (Query, error) {
-	cond, err := p.parseCond()
+	q, err := p.parseDisjunction()
 	if err != nil {
 		return nil, err
 	}
-	conds := []Condition{cond}
-	for p.scanner.Next() != io.EOF {
-		if tok := p.scanner.Token(); tok != TAnd {
-			return nil, fmt.Errorf("offset %d: got %v, want %v", p.scanner.Pos(), tok, TAnd)
+	if err := p.next(); err == nil {
+		return nil, fmt.Errorf("offset %d: got %v, want %v", p.scanner.Pos(), p.scanner.Token(), tokLabel([]Token{TAnd, TOr}))
+	} else if err != io.EOF {
+		return nil, fmt.Errorf("offset %d: %w", p.scanner.Pos(), err)
+	}
+	return q, nil
+}
+
+// parseDisjunction parses conjunctions separated by OR. A single conjunction
+// is returned as is.
+func (p *Parser) parseDisjunction() (Query, error) {
+	var alts []Query
+	for {
+		q, err := p.parseConjunction()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, q)
+		if !p.accept(TOr) {
+			break
 		}
-		cond, err := p.parseCond()
+	}
+	if len(alts) == 1 {
+		return alts[0], nil
+	}
+	return Query{{Op: TOr, Sub: alts, opText: "OR"}}, nil
+}
+
+// parseConjunction parses terms separated by AND.
+func (p *Parser) parseConjunction() (Query, error) {
+	var q Query
+	for {
+		term, err := p.parseTerm()
 		if err != nil {
 			return nil, err
 		}
-		conds = append(conds, cond)
+		q = append(q, term...)
+		if !p.accept(TAnd) {
+			return q, nil
+		}
 	}
-	return conds, nil
+}
+
+// parseTerm parses a negated term, a parenthesized disjunction, or a
+// conditional expression. The conditions of a parenthesized conjunction are
+// returned as is.
+func (p *Parser) parseTerm() (Query, error) {
+	if p.accept(TNot) {
+		term, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return Query{{Op: TNot, Sub: []Query{term}, opText: "NOT"}}, nil
+	}
+	if p.accept(TLParen) {
+		q, err := p.parseDisjunction()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.require(TRParen); err != nil {
+			return nil, err
+		}
+		return q, nil
+	}
+	cond, err := p.parseCond()
+	if err != nil {
+		return nil, err
+	}
+	return Query{cond}, nil
 }
 
 // parseCond parses a conditional expression: tag OP value.
@@ -147,7 +262,7 @@ func (p *Parser) parseCond() (Condition, error) {
 		return cond, err
 	}
 	cond.Tag = p.scanner.Text()
-	if err := p.require(TLeq, TGeq, TLt, TGt, TEq, TContains, TExists); err != nil {
+	if err := p.require(TLeq, TGeq, TLt, TGt, TEq, TContains, TExists, TIn); err != nil {
 		return cond, err
 	}
 	cond.Op = p.scanner.Token()
@@ -164,6 +279,8 @@ func (p *Parser) parseCond() (Condition, error) {
 	case TExists:
 		// no argument
 		return cond, nil
+	case TIn:
+		return cond, p.parseInArgs(&cond)
 	default:
 		return cond, fmt.Errorf("offset %d: unexpected operator %v", p.scanner.Pos(), cond.Op)
 	}
@@ -174,10 +291,50 @@ func (p *Parser) parseCond() (Condition, error) {
 	return cond, nil
 }
 
+// parseInArgs parses the parenthesized, comma-separated arguments of an IN
+// condition.
+func (p *Parser) parseInArgs(cond *Condition) error {
+	if err := p.require(TLParen); err != nil {
+		return err
+	}
+	for {
+		if err := p.require(TNumber, TTime, TDate, TString); err != nil {
+			return err
+		}
+		cond.Args = append(cond.Args, &Arg{Type: p.scanner.Token(), text: p.scanner.Text()})
+		if err := p.require(TComma, TRParen); err != nil {
+			return err
+		}
+		if p.scanner.Token() == TRParen {
+			return nil
+		}
+	}
+}
+
+// next advances the scanner, unless the current token was backed up.
+func (p *Parser) next() error {
+	if p.backed {
+		p.backed = false
+		return p.err
+	}
+	p.err = p.scanner.Next()
+	return p.err
+}
+
+// accept advances the scanner if the next token is tok, and reports whether
+// it is. Otherwise, the token is backed up for the next call to next.
+func (p *Parser) accept(tok Token) bool {
+	if p.next() == nil && p.scanner.Token() == tok {
+		return true
+	}
+	p.backed = true
+	return false
+}
+
 // require advances the scanner and requires that the resulting token is one of
 // the specified token types.
 func (p *Parser) require(tokens ...Token) error {
-	if err := p.scanner.Next(); err != nil {
+	if err := p.next(); err != nil {
 		return fmt.Errorf("offset %d: %w", p.scanner.Pos(), err)
 	}
 	got := p.scanner.Token()
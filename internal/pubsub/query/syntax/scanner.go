@@ -28,6 +28,12 @@ const (
 	TLeq             // operator: <=
 	TGt              // operator: >
 	TGeq             // operator: >=
+	TOr              // operator: OR
+	TNot             // operator: NOT
+	TIn              // operator: IN
+	TLParen          // left parenthesis: (
+	TRParen          // right parenthesis: )
+	TComma           // list separator: ,
 
 	// Do not reorder these values without updating the scanner code.
 )
@@ -47,6 +53,12 @@ var tString = [...]string{
 	TLeq:      "<= operator",
 	TGt:       "> operator",
 	TGeq:      ">= operator",
+	TOr:       "OR operator",
+	TNot:      "NOT operator",
+	TIn:       "IN operator",
+	TLParen:   "(",
+	TRParen:   ")",
+	TComma:    ",",
 }
 
 func (t Token) String() string {
@@ -107,6 +119,8 @@ func (s *Scanner) Next() error {
 			return s.scanString(ch)
 		case '<', '>', '=':
 			return s.scanCompare(ch)
+		case '(', ')', ',':
+			return s.scanPunct(ch)
 		default:
 			return s.invalid(ch)
 		}
@@ -193,6 +207,21 @@ func (s *Scanner) scanCompare(first rune) error {
 	return nil
 }
 
+func (s *Scanner) scanPunct(ch rune) error {
+	s.buf.WriteRune(ch)
+	switch ch {
+	case '(':
+		s.tok = TLParen
+	case ')':
+		s.tok = TRParen
+	case ',':
+		s.tok = TComma
+	default:
+		return s.invalid(ch)
+	}
+	return nil
+}
+
 func (s *Scanner) scanTagLike(first rune) error {
 	s.buf.WriteRune(first)
 	var hasSpace bool
@@ -224,6 +253,12 @@ func (s *Scanner) scanTagLike(first rune) error {
 		s.tok = TTag
 	case "AND":
 		s.tok = TAnd
+	case "OR":
+		s.tok = TOr
+	case "NOT":
+		s.tok = TNot
+	case "IN":
+		s.tok = TIn
 	case "EXISTS":
 		s.tok = TExists
 	case "CONTAINS":
@@ -40,6 +40,10 @@ func TestScanner(t *testing.T) {
 		{`x.y CONTAINS 'z'`, []syntax.Token{syntax.TTag, syntax.TContains, syntax.TString}},
 		{`foo EXISTS`, []syntax.Token{syntax.TTag, syntax.TExists}},
 		{`and AND`, []syntax.Token{syntax.TTag, syntax.TAnd}},
+		{`x OR NOT y`, []syntax.Token{syntax.TTag, syntax.TOr, syntax.TNot, syntax.TTag}},
+		{`x IN (1,'y')`, []syntax.Token{
+			syntax.TTag, syntax.TIn, syntax.TLParen, syntax.TNumber, syntax.TComma, syntax.TString, syntax.TRParen,
+		}},
 
 		// Timestamp
 		{`TIME 2021-11-23T15:16:17Z`, []syntax.Token{syntax.TTime}},
@@ -167,6 +171,29 @@ func TestParseValid(t *testing.T) {
 
 		{"hash='136E18F7E4C348B780CF873A0BF43922E5BAFA63'", true},
 		{"hash=136E18F7E4C348B780CF873A0BF43922E5BAFA63", false},
+
+		{"a.b = 1 OR a.b = 2", true},
+		{"a.b = 1 AND c.d = 'x' OR a.b = 2", true},
+		{"a.b = 1 AND (c.d = 'x' OR a.b = 2)", true},
+		{"((a.b = 1) OR (c.d = 'x' AND a.b < 3))", true},
+		{"NOT a.b = 1", true},
+		{"NOT (a.b = 1 AND c.d = 'x')", true},
+		{"NOT (a.b = 1 OR c.d = 'x') AND e EXISTS", true},
+		{"NOT NOT a.b EXISTS", true},
+		{"a.b IN (1, 2.5, 'x', DATE 2013-05-03, TIME 2013-05-03T14:45:00Z)", true},
+		{"a.b IN ('x')", true},
+		{"a.b = 1 OR", false},
+		{"OR a.b = 1", false},
+		{"(a.b = 1", false},
+		{"a.b = 1)", false},
+		{"()", false},
+		{"NOT", false},
+		{"a.b NOT = 1", false},
+		{"a.b IN ()", false},
+		{"a.b IN (1,)", false},
+		{"a.b IN 1", false},
+		{"a.b IN (1 2)", false},
+		{"a.b IN (c.d)", false},
 	}
 
 	for _, test := range tests {
@@ -188,3 +215,43 @@ func TestParseValid(t *testing.T) {
 		}
 	}
 }
+
+func TestParseOperators(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		// AND binds tighter than OR.
+		{"a = 1 AND b = 2 OR c = 3", "a = 1 AND b = 2 OR c = 3"},
+		{"a = 1 AND (b = 2 OR c = 3)", "a = 1 AND (b = 2 OR c = 3)"},
+		// The parentheses of a conjunction are flattened.
+		{"(a = 1 AND b = 2) AND c = 3", "a = 1 AND b = 2 AND c = 3"},
+		{"NOT (a = 1 OR b = 2)", "NOT (a = 1 OR b = 2)"},
+		{"NOT(a = 1 AND b = 2)", "NOT (a = 1 AND b = 2)"},
+		{"a IN(1,'x')", "a IN (1, 'x')"},
+	}
+	for _, test := range tests {
+		q, err := syntax.Parse(test.input)
+		if err != nil {
+			t.Errorf("Parse %#q: unexpected error: %v", test.input, err)
+			continue
+		}
+		if got := q.String(); got != test.want {
+			t.Errorf("Parse %#q: got %#q, want %#q", test.input, got, test.want)
+		}
+	}
+
+	q, err := syntax.Parse("a = 1 AND (b = 2 OR c = 3 AND d EXISTS) AND NOT e IN (4, 5)")
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if len(q) != 3 || q[1].Op != syntax.TOr || q[2].Op != syntax.TNot {
+		t.Fatalf("Parse: got %+v", q)
+	}
+	if alts := q[1].Alternatives(); len(alts) != 2 || len(alts[1]) != 2 {
+		t.Errorf("Alternatives: got %v", alts)
+	}
+	in := q[2].Sub[0][0]
+	if alts := in.Alternatives(); len(alts) != 2 || alts[1].String() != "e = 5" {
+		t.Errorf("Alternatives: got %v", alts)
+	}
+}
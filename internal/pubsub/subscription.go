@@ -3,6 +3,9 @@ package pubsub
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 	"github.com/tendermint/tendermint/abci/types"
@@ -20,9 +23,35 @@ var (
 
 // A Subscription represents a client subscription for a particular query.
 type Subscription struct {
+	// The number of messages skipped by a paused subscription, accessed
+	// atomically. It comes first to be 64-bit aligned on 32-bit platforms.
+	skipped uint64
+
 	id      string
 	queue   *queue.Queue // open until the subscription ends
 	stopErr error        // after queue is closed, the reason why
+
+	// If not nil, the subscription catches up from the event log when its
+	// queue is full (see PersistentEventLog).
+	catchUp *catchUp
+
+	// Whether the messages are skipped rather than the subscription
+	// terminated when the queue is full, without an event log to catch up
+	// from.
+	pause bool
+}
+
+// catchUp is the state of a subscription catching up from the event log.
+type catchUp struct {
+	// read returns at most max messages of the log matching the query of the
+	// subscription after a sequence number, the sequence number of the last
+	// message read, and whether the end of the log was reached.
+	read  func(after uint64, max int) ([]Message, uint64, bool, error)
+	limit int // the capacity of the queue
+
+	mtx     sync.Mutex
+	cursor  uint64 // the last message queued or skipped
+	lagging bool   // whether the messages after cursor are read from the log
 }
 
 // newSubscription returns a new subscription with the given queue capacity.
@@ -45,6 +74,9 @@ func newSubscription(quota, limit int) (*Subscription, error) {
 // s was terminated by the publisher, or a context error if ctx ended without a
 // message being available.
 func (s *Subscription) Next(ctx context.Context) (Message, error) {
+	if s.catchUp != nil {
+		s.catchUpLog()
+	}
 	next, err := s.queue.Wait(ctx)
 	if errors.Is(err, queue.ErrQueueClosed) {
 		return Message{}, s.stopErr
@@ -57,9 +89,78 @@ func (s *Subscription) Next(ctx context.Context) (Message, error) {
 // ID returns the unique subscription identifier for s.
 func (s *Subscription) ID() string { return s.id }
 
+// Pending returns the number of messages published to s and not yet returned
+// by Next.
+func (s *Subscription) Pending() int { return s.queue.Len() }
+
+// Skipped returns the number of messages published to s that were skipped
+// because its queue was full, for a paused subscription without an event log
+// to catch up from (see SubscribeArgs).
+func (s *Subscription) Skipped() uint64 { return atomic.LoadUint64(&s.skipped) }
+
 // publish transmits msg to the subscriber. It reports a queue error if the
-// queue cannot accept any further messages.
-func (s *Subscription) publish(msg Message) error { return s.queue.Add(msg) }
+// queue cannot accept any further messages. A subscription catching up from
+// the event log only reports an error if it was stopped: the messages it
+// cannot queue are read back from the log by Next, and a paused subscription
+// skips them.
+func (s *Subscription) publish(msg Message) error {
+	c := s.catchUp
+	if c == nil {
+		err := s.queue.Add(msg)
+		if err != nil && s.pause && !errors.Is(err, queue.ErrQueueClosed) {
+			atomic.AddUint64(&s.skipped, 1)
+			return nil
+		}
+		return err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.lagging || msg.seq <= c.cursor {
+		return nil // read from the log, or already read
+	}
+	if err := s.queue.Add(msg); errors.Is(err, queue.ErrQueueClosed) {
+		return err
+	} else if err != nil {
+		c.lagging = true
+		return nil
+	}
+	c.cursor = msg.seq
+	return nil
+}
+
+// catchUpLog refills the queue of a lagging subscription from the event log
+// once it is empty, and resumes the delivery of the messages as they are
+// published when the end of the log is reached. If the messages after the
+// cursor are no longer retained by the log, the subscription is terminated.
+func (s *Subscription) catchUpLog() {
+	c := s.catchUp
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.lagging || s.queue.Len() > 0 {
+		return
+	}
+
+	// The publisher waits for the lock to deliver a message, after it is
+	// appended to the log: the messages published after the end of the log
+	// read are delivered to the queue, and those read are skipped.
+	msgs, last, done, err := c.read(c.cursor, c.limit)
+	if err != nil {
+		c.lagging = false
+		s.stop(fmt.Errorf("%w: catching up from the event log: %v", ErrTerminated, err))
+		return
+	}
+	for _, msg := range msgs {
+		msg.subID = s.id
+		if err := s.queue.Add(msg); err != nil {
+			// Out of quota: the rest is read again at the next call.
+			c.cursor = msg.seq - 1
+			return
+		}
+		c.cursor = msg.seq
+	}
+	c.cursor = last
+	c.lagging = !done
+}
 
 // stop terminates the subscription with the given error reason.
 func (s *Subscription) stop(err error) {
@@ -73,6 +174,7 @@ func (s *Subscription) stop(err error) {
 // Message glues data and events together.
 type Message struct {
 	subID  string
+	seq    uint64
 	data   interface{}
 	events []types.Event
 }
@@ -81,6 +183,11 @@ type Message struct {
 // that produced this message.
 func (msg Message) SubscriptionID() string { return msg.subID }
 
+// Seq returns the sequence number of the message, which increases with each
+// message published by the server. It can be used to resume a subscription
+// (see SubscribeArgs).
+func (msg Message) Seq() uint64 { return msg.seq }
+
 // Data returns an original data published.
 func (msg Message) Data() interface{} { return msg.data }
 
@@ -1,23 +1,62 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/proxy"
-	"github.com/tendermint/tendermint/libs/bytes"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 )
 
 // ABCIQuery queries the application for some information.
+//
+// The state queried can be pinned to a block by its hash instead of its
+// height: the query is then made at the height of the block, provided the
+// block is part of the chain of the node, and fails if the application
+// answers at another height.
+//
+// The responses the application declares cacheable, with a max-age or bound
+// to the height, are served from the query cache of the node, if enabled,
+// without querying the application again.
 // More: https://docs.tendermint.com/master/rpc/#/ABCI/abci_query
 func (env *Environment) ABCIQuery(
 	ctx context.Context,
 	path string,
-	data bytes.HexBytes,
+	data tmbytes.HexBytes,
 	height int64,
 	prove bool,
+	blockHash tmbytes.HexBytes,
 ) (*coretypes.ResultABCIQuery, error) {
+	if len(blockHash) > 0 {
+		blockHeight, err := env.blockHeight(blockHash)
+		if err != nil {
+			return nil, err
+		}
+		if height != 0 && height != blockHeight {
+			return nil, fmt.Errorf("block %X is at height %d, not %d: %w",
+				blockHash, blockHeight, height, coretypes.ErrInvalidRequest)
+		}
+		height = blockHeight
+	}
+
+	var (
+		cacheKey   string
+		lastHeight int64
+	)
+	if env.Config.ABCIQueryCacheSize > 0 {
+		cacheKey = abciQueryKey(path, data, height, prove)
+		lastHeight = env.BlockStore.Height()
+		if res, ok := env.abciQueries.get(cacheKey, lastHeight); ok {
+			return &coretypes.ResultABCIQuery{Response: *res}, nil
+		}
+	}
+
+	ctx, cancel := env.abciQueryContext(ctx)
+	defer cancel()
+
 	resQuery, err := env.ProxyAppQuery.Query(ctx, abci.RequestQuery{
 		Path:   path,
 		Data:   data,
@@ -27,13 +66,39 @@ func (env *Environment) ABCIQuery(
 	if err != nil {
 		return nil, err
 	}
+	if len(blockHash) > 0 && resQuery.Height != 0 && resQuery.Height != height {
+		return nil, fmt.Errorf("the application answered at height %d instead of %d", resQuery.Height, height)
+	}
+	if cacheKey != "" && abciQueryCacheable(resQuery) {
+		env.abciQueries.put(cacheKey, resQuery, lastHeight, env.Config.ABCIQueryCacheSize)
+	}
 
 	return &coretypes.ResultABCIQuery{Response: *resQuery}, nil
 }
 
+// blockHeight returns the height of the block with the given hash, which must
+// be the block stored at that height: the hash index may still refer to a
+// block discarded by a rollback.
+func (env *Environment) blockHeight(hash tmbytes.HexBytes) (int64, error) {
+	blockMeta := env.BlockStore.LoadBlockMetaByHash(hash)
+	if blockMeta == nil {
+		return 0, fmt.Errorf("block %X not found: %w", hash, coretypes.ErrInvalidRequest)
+	}
+	height := blockMeta.Header.Height
+	stored := env.BlockStore.LoadBlockMeta(height)
+	if stored == nil || !bytes.Equal(stored.BlockID.Hash, hash) {
+		return 0, fmt.Errorf("block %X is not part of the chain at height %d: %w",
+			hash, height, coretypes.ErrInvalidRequest)
+	}
+	return height, nil
+}
+
 // ABCIInfo gets some info about the application.
 // More: https://docs.tendermint.com/master/rpc/#/ABCI/abci_info
 func (env *Environment) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error) {
+	ctx, cancel := env.abciQueryContext(ctx)
+	defer cancel()
+
 	resInfo, err := env.ProxyAppQuery.Info(ctx, proxy.RequestInfo)
 	if err != nil {
 		return nil, err
@@ -41,3 +106,13 @@ func (env *Environment) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo
 
 	return &coretypes.ResultABCIInfo{Response: *resInfo}, nil
 }
+
+// abciQueryContext bounds the time a query waits for the application by
+// the configured timeout. The query is abandoned earlier if ctx, the context
+// of the request, is done: the client went away or its deadline expired.
+func (env *Environment) abciQueryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if env.Config.TimeoutABCIQuery > 0 {
+		return context.WithTimeout(ctx, env.Config.TimeoutABCIQuery)
+	}
+	return context.WithCancel(ctx)
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+// abciQueryCache is an LRU cache of the responses to the ABCI queries the
+// application declared cacheable, keyed by the path, data, height and prove
+// flag of the query. An entry expires after the max-age of its response, and,
+// if the response is bound to the height, as soon as the node commits another
+// block.
+type abciQueryCache struct {
+	mtx     sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *abciQueryEntry, the least recently used first
+}
+
+type abciQueryEntry struct {
+	key     string
+	res     abci.ResponseQuery
+	expires time.Time // zero if the entry does not expire with time
+
+	// whether the entry expires once the last block height is no longer
+	// height
+	heightBound bool
+	height      int64
+}
+
+// abciQueryKey returns the cache key of a query.
+func abciQueryKey(path string, data []byte, height int64, prove bool) string {
+	return fmt.Sprintf("%d/%t/%q/%X", height, prove, path, data)
+}
+
+// abciQueryCacheable reports whether the application declared res cacheable.
+func abciQueryCacheable(res *abci.ResponseQuery) bool {
+	return res.CacheMaxAgeMs > 0 || res.CacheHeightBound
+}
+
+// get returns the unexpired response with the given key, lastHeight being the
+// height of the last block committed by the node.
+func (c *abciQueryCache) get(key string, lastHeight int64) (*abci.ResponseQuery, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*abciQueryEntry)
+	if (!entry.expires.IsZero() && time.Now().After(entry.expires)) ||
+		(entry.heightBound && entry.height != lastHeight) {
+		delete(c.entries, key)
+		c.lru.Remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToBack(elem)
+	res := entry.res
+	return &res, true
+}
+
+// put adds the cacheable response res with the given key, evicting the least
+// recently used entry if the cache holds size entries already.
+func (c *abciQueryCache) put(key string, res *abci.ResponseQuery, lastHeight int64, size int) {
+	entry := &abciQueryEntry{key: key, res: *res, heightBound: res.CacheHeightBound, height: lastHeight}
+	if res.CacheMaxAgeMs > 0 {
+		entry.expires = time.Now().Add(time.Duration(res.CacheMaxAgeMs) * time.Millisecond)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element, size)
+		c.lru = list.New()
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+	} else if c.lru.Len() >= size {
+		if front := c.lru.Front(); front != nil {
+			delete(c.entries, front.Value.(*abciQueryEntry).key)
+			c.lru.Remove(front)
+		}
+	}
+	c.entries[key] = c.lru.PushBack(entry)
+}
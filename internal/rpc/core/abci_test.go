@@ -0,0 +1,173 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	proxymocks "github.com/tendermint/tendermint/internal/proxy/mocks"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestABCIQueryBlockHash(t *testing.T) {
+	canonical := bytes.HexBytes{0x01}
+	orphaned := bytes.HexBytes{0x02}
+	meta := func(hash bytes.HexBytes) *types.BlockMeta {
+		return &types.BlockMeta{BlockID: types.BlockID{Hash: hash}, Header: types.Header{Height: 5}}
+	}
+
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlockMetaByHash", []byte(canonical)).Return(meta(canonical))
+	blockStore.On("LoadBlockMetaByHash", []byte(orphaned)).Return(meta(orphaned))
+	blockStore.On("LoadBlockMetaByHash", mock.Anything).Return(nil)
+	blockStore.On("LoadBlockMeta", int64(5)).Return(meta(canonical))
+
+	// The application answers at the height of the request, except for
+	// the key "stale".
+	app := &proxymocks.AppConnQuery{}
+	app.On("Query", mock.Anything, mock.Anything).Return(
+		func(_ context.Context, req abci.RequestQuery) *abci.ResponseQuery {
+			if string(req.Data) == "stale" {
+				return &abci.ResponseQuery{Height: req.Height - 1}
+			}
+			return &abci.ResponseQuery{Height: req.Height}
+		},
+		func(context.Context, abci.RequestQuery) error { return nil },
+	)
+
+	env := &Environment{BlockStore: blockStore, ProxyAppQuery: app}
+	ctx := context.Background()
+
+	res, err := env.ABCIQuery(ctx, "/key", []byte("key"), 0, false, canonical)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res.Response.Height)
+
+	res, err = env.ABCIQuery(ctx, "/key", []byte("key"), 5, false, canonical)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), res.Response.Height)
+
+	for _, tc := range []struct {
+		data      string
+		height    int64
+		blockHash bytes.HexBytes
+	}{
+		{"key", 4, canonical},            // another height
+		{"key", 0, orphaned},             // not in the chain
+		{"key", 0, bytes.HexBytes{0x03}}, // unknown
+	} {
+		_, err := env.ABCIQuery(ctx, "/key", []byte(tc.data), tc.height, false, tc.blockHash)
+		assert.True(t, errors.Is(err, coretypes.ErrInvalidRequest), err)
+	}
+
+	_, err = env.ABCIQuery(ctx, "/key", []byte("stale"), 0, false, canonical)
+	assert.Error(t, err)
+}
+
+func TestABCIQueryTimeout(t *testing.T) {
+	// The application never answers, until the query is abandoned.
+	app := &proxymocks.AppConnQuery{}
+	app.On("Query", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, _ abci.RequestQuery) *abci.ResponseQuery { return nil },
+		func(ctx context.Context, _ abci.RequestQuery) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+	app.On("Info", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, _ abci.RequestInfo) *abci.ResponseInfo { return nil },
+		func(ctx context.Context, _ abci.RequestInfo) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+
+	env := &Environment{ProxyAppQuery: app, Config: config.RPCConfig{TimeoutABCIQuery: 10 * time.Millisecond}}
+
+	_, err := env.ABCIQuery(context.Background(), "/key", []byte("key"), 0, false, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = env.ABCIInfo(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The query is abandoned as soon as its client goes away.
+	env.Config.TimeoutABCIQuery = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = env.ABCIQuery(ctx, "/key", []byte("key"), 0, false, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestABCIQueryCache(t *testing.T) {
+	var lastHeight int64 = 5
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Height").Return(func() int64 { return lastHeight })
+
+	// The application counts the queries in the value of its responses, and
+	// declares them cacheable according to their path.
+	queries := 0
+	app := &proxymocks.AppConnQuery{}
+	app.On("Query", mock.Anything, mock.Anything).Return(
+		func(_ context.Context, req abci.RequestQuery) *abci.ResponseQuery {
+			queries++
+			res := &abci.ResponseQuery{Value: []byte{byte(queries)}}
+			switch req.Path {
+			case "/age":
+				res.CacheMaxAgeMs = 50
+			case "/height":
+				res.CacheHeightBound = true
+			}
+			return res
+		},
+		func(context.Context, abci.RequestQuery) error { return nil },
+	)
+
+	env := &Environment{BlockStore: blockStore, ProxyAppQuery: app, Config: config.RPCConfig{ABCIQueryCacheSize: 2}}
+	ctx := context.Background()
+	query := func(path, data string, height int64) byte {
+		res, err := env.ABCIQuery(ctx, path, []byte(data), height, false, nil)
+		require.NoError(t, err)
+		return res.Response.Value[0]
+	}
+
+	// The responses not declared cacheable are not cached.
+	assert.Equal(t, byte(1), query("/none", "key", 0))
+	assert.Equal(t, byte(2), query("/none", "key", 0))
+
+	// The responses with a max-age are cached until it passes, across the
+	// heights.
+	assert.Equal(t, byte(3), query("/age", "key", 0))
+	assert.Equal(t, byte(3), query("/age", "key", 0))
+	assert.Equal(t, byte(4), query("/age", "other", 0))
+	assert.Equal(t, byte(5), query("/age", "key", 4))
+	lastHeight++
+	assert.Equal(t, byte(5), query("/age", "key", 4))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, byte(6), query("/age", "key", 4))
+
+	// The responses bound to the height are cached until the next block.
+	assert.Equal(t, byte(7), query("/height", "key", 0))
+	assert.Equal(t, byte(7), query("/height", "key", 0))
+	lastHeight++
+	assert.Equal(t, byte(8), query("/height", "key", 0))
+
+	// The least recently used responses are evicted beyond the cache size.
+	assert.Equal(t, byte(9), query("/height", "other", 0))
+	assert.Equal(t, byte(10), query("/height", "third", 0))
+	assert.Equal(t, byte(10), query("/height", "third", 0))
+	assert.Equal(t, byte(9), query("/height", "other", 0))
+	assert.Equal(t, byte(11), query("/height", "key", 0))
+
+	// The cache is disabled with a size of 0.
+	env.Config.ABCIQueryCacheSize = 0
+	assert.Equal(t, byte(12), query("/height", "key", 0))
+	assert.Equal(t, byte(13), query("/height", "key", 0))
+}
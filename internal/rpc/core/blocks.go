@@ -6,10 +6,12 @@ import (
 	"sort"
 
 	tmquery "github.com/tendermint/tendermint/internal/pubsub/query"
+	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
 	"github.com/tendermint/tendermint/libs/bytes"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -55,6 +57,112 @@ func (env *Environment) BlockchainInfo(
 	}, nil
 }
 
+// BlockUtilization reports how much of the maximum block size and gas the
+// blocks with minHeight <= height <= maxHeight use, and summarizes it per
+// proposer, which helps spotting proposers that consistently produce empty
+// or under-filled blocks.
+//
+// Heights are filtered as for BlockchainInfo, but at most 100 blocks are
+// reported. Blocks are returned in descending order (highest first), and
+// proposers by decreasing number of blocks.
+func (env *Environment) BlockUtilization(
+	ctx context.Context,
+	minHeight, maxHeight int64) (*coretypes.ResultBlockUtilization, error) {
+
+	const limit int64 = 100
+
+	var err error
+	minHeight, maxHeight, err = filterMinMax(
+		env.BlockStore.Base(),
+		env.BlockStore.Height(),
+		minHeight,
+		maxHeight,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+
+	type proposerTotals struct {
+		coretypes.ProposerUtilization
+		bytesRatio, gasRatio float64
+		gasBlocks            int
+	}
+	totals := make(map[string]*proposerTotals)
+
+	blocks := make([]coretypes.BlockUtilization, 0, maxHeight-minHeight+1)
+	for height := maxHeight; height >= minHeight; height-- {
+		blockMeta := env.BlockStore.LoadBlockMeta(height)
+		if blockMeta == nil {
+			continue
+		}
+		params, err := env.StateStore.LoadConsensusParams(height)
+		if err != nil {
+			return nil, err
+		}
+		u := sm.BlockUtilization{
+			Height:   height,
+			Proposer: blockMeta.Header.ProposerAddress,
+			NumTxs:   blockMeta.NumTxs,
+			Bytes:    int64(blockMeta.BlockSize),
+			MaxBytes: params.Block.MaxBytes,
+			GasUsed:  -1,
+			MaxGas:   params.Block.MaxGas,
+		}
+		// The results may have been discarded or pruned.
+		if results, err := env.StateStore.LoadABCIResponses(height); err == nil {
+			u = sm.NewBlockUtilization(&blockMeta.Header, blockMeta.NumTxs, blockMeta.BlockSize,
+				params.Block, results.DeliverTxs)
+		}
+
+		blocks = append(blocks, coretypes.BlockUtilization{
+			Height:          u.Height,
+			ProposerAddress: bytes.HexBytes(u.Proposer),
+			NumTxs:          u.NumTxs,
+			Bytes:           u.Bytes,
+			MaxBytes:        u.MaxBytes,
+			GasUsed:         u.GasUsed,
+			MaxGas:          u.MaxGas,
+		})
+
+		t, ok := totals[string(u.Proposer)]
+		if !ok {
+			t = &proposerTotals{}
+			t.Address = bytes.HexBytes(u.Proposer)
+			totals[string(u.Proposer)] = t
+		}
+		t.Blocks++
+		if u.IsEmpty() {
+			t.EmptyBlocks++
+		}
+		t.bytesRatio += u.BytesRatio()
+		if ratio, ok := u.GasRatio(); ok && u.GasUsed >= 0 {
+			t.gasRatio += ratio
+			t.gasBlocks++
+		}
+	}
+
+	proposers := make([]coretypes.ProposerUtilization, 0, len(totals))
+	for _, t := range totals {
+		t.AvgBytesUtilization = t.bytesRatio / float64(t.Blocks)
+		if t.gasBlocks > 0 {
+			t.AvgGasUtilization = t.gasRatio / float64(t.gasBlocks)
+		}
+		proposers = append(proposers, t.ProposerUtilization)
+	}
+	sort.Slice(proposers, func(i, j int) bool {
+		if proposers[i].Blocks != proposers[j].Blocks {
+			return proposers[i].Blocks > proposers[j].Blocks
+		}
+		return proposers[i].Address.String() < proposers[j].Address.String()
+	})
+
+	return &coretypes.ResultBlockUtilization{
+		LastHeight: env.BlockStore.Height(),
+		Blocks:     blocks,
+		Proposers:  proposers,
+	}, nil
+}
+
 // error if either min or max are negative or min > max
 // if 0, use blockstore base for min, latest block height for max
 // enforce limit.
@@ -104,6 +212,9 @@ func (env *Environment) Block(ctx context.Context, heightPtr *int64) (*coretypes
 	}
 
 	block := env.BlockStore.LoadBlock(height)
+	if heightPtr != nil {
+		rpctypes.GetCallInfo(ctx).SetImmutable()
+	}
 	return &coretypes.ResultBlock{BlockID: blockMeta.BlockID, Block: block}, nil
 }
 
@@ -186,6 +297,9 @@ func (env *Environment) Commit(ctx context.Context, heightPtr *int64) (*coretype
 	if commit == nil {
 		return nil, nil
 	}
+	if heightPtr != nil {
+		rpctypes.GetCallInfo(ctx).SetImmutable()
+	}
 	return coretypes.NewResultCommit(&header, commit, true), nil
 }
 
@@ -212,6 +326,10 @@ func (env *Environment) BlockResults(ctx context.Context, heightPtr *int64) (*co
 		totalGasUsed += tx.GetGasUsed()
 	}
 
+	if heightPtr != nil {
+		rpctypes.GetCallInfo(ctx).SetImmutable()
+	}
+
 	return &coretypes.ResultBlockResults{
 		Height:                height,
 		TxsResults:            results.DeliverTxs,
@@ -224,12 +342,16 @@ func (env *Environment) BlockResults(ctx context.Context, heightPtr *int64) (*co
 }
 
 // BlockSearch searches for a paginated set of blocks matching BeginBlock and
-// EndBlock event search criteria.
+// EndBlock event search criteria, and returns the cursor of the next page if
+// there is one. If a cursor is given, the blocks following it are returned
+// instead of a page, with the number of blocks following it as the total
+// count.
 func (env *Environment) BlockSearch(
 	ctx context.Context,
 	query string,
 	pagePtr, perPagePtr *int,
 	orderBy string,
+	cursor string,
 ) (*coretypes.ResultBlockSearch, error) {
 
 	if !indexer.KVSinkEnabled(env.EventSinks) {
@@ -265,11 +387,27 @@ func (env *Environment) BlockSearch(
 		return nil, fmt.Errorf("expected order_by to be either `asc` or `desc` or empty: %w", coretypes.ErrInvalidRequest)
 	}
 
+	// skip the results up to the cursor, if any
+	if cursor != "" {
+		after, err := parseCursor(pagePtr, cursor)
+		if err != nil {
+			return nil, err
+		}
+		desc := orderBy != "asc"
+		i := sort.Search(len(results), func(i int) bool {
+			return after.Follows(results[i], 0, desc)
+		})
+		results = results[i:]
+	}
+
 	// paginate results
 	totalCount := len(results)
-	perPage := env.validatePerPage(perPagePtr)
+	perPage, err := env.validatePerPage("block_search", perPagePtr)
+	if err != nil {
+		return nil, err
+	}
 
-	page, err := validatePage(pagePtr, perPage, totalCount)
+	page, err := coretypes.ValidatePage(pagePtr, perPage, totalCount)
 	if err != nil {
 		return nil, err
 	}
@@ -291,5 +429,9 @@ func (env *Environment) BlockSearch(
 		}
 	}
 
-	return &coretypes.ResultBlockSearch{Blocks: apiResults, TotalCount: totalCount}, nil
+	res := &coretypes.ResultBlockSearch{Blocks: apiResults, TotalCount: totalCount}
+	if skipCount+pageSize < totalCount && pageSize > 0 {
+		res.NextCursor = indexer.Cursor{Height: results[skipCount+pageSize-1]}.String()
+	}
+	return res, nil
 }
@@ -2,10 +2,12 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	dbm "github.com/tendermint/tm-db"
@@ -13,8 +15,10 @@ import (
 	abci "github.com/tendermint/tendermint/abci/types"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/libs/bytes"
 	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
 	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
 )
 
 func TestBlockchainInfo(t *testing.T) {
@@ -119,3 +123,51 @@ func TestBlockResults(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockUtilization(t *testing.T) {
+	proposerA := bytes.HexBytes{0x0a}
+	proposerB := bytes.HexBytes{0x0b}
+
+	params := types.DefaultConsensusParams()
+	params.Block.MaxBytes = 1000
+	params.Block.MaxGas = 100
+
+	statestore := &mocks.Store{}
+	statestore.On("LoadConsensusParams", mock.Anything).Return(*params, nil)
+	statestore.On("LoadABCIResponses", int64(1)).Return(&tmstate.ABCIResponses{
+		DeliverTxs: []*abci.ResponseDeliverTx{{GasUsed: 30}, {GasUsed: 20}},
+	}, nil)
+	statestore.On("LoadABCIResponses", int64(2)).Return(&tmstate.ABCIResponses{}, nil)
+	statestore.On("LoadABCIResponses", int64(3)).Return(nil, errors.New("discarded"))
+
+	blockstore := &mocks.BlockStore{}
+	blockstore.On("Base").Return(int64(1))
+	blockstore.On("Height").Return(int64(3))
+	blockstore.On("LoadBlockMeta", int64(1)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 1, ProposerAddress: types.Address(proposerA)}, BlockSize: 500, NumTxs: 2,
+	})
+	blockstore.On("LoadBlockMeta", int64(2)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 2, ProposerAddress: types.Address(proposerB)}, BlockSize: 100,
+	})
+	blockstore.On("LoadBlockMeta", int64(3)).Return(&types.BlockMeta{
+		Header: types.Header{Height: 3, ProposerAddress: types.Address(proposerA)}, BlockSize: 300, NumTxs: 1,
+	})
+
+	env := &Environment{StateStore: statestore, BlockStore: blockstore}
+
+	res, err := env.BlockUtilization(context.Background(), 0, 0)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, res.LastHeight)
+	require.Equal(t, []coretypes.BlockUtilization{
+		{Height: 3, ProposerAddress: proposerA, NumTxs: 1, Bytes: 300, MaxBytes: 1000, GasUsed: -1, MaxGas: 100},
+		{Height: 2, ProposerAddress: proposerB, NumTxs: 0, Bytes: 100, MaxBytes: 1000, GasUsed: 0, MaxGas: 100},
+		{Height: 1, ProposerAddress: proposerA, NumTxs: 2, Bytes: 500, MaxBytes: 1000, GasUsed: 50, MaxGas: 100},
+	}, res.Blocks)
+	require.Equal(t, []coretypes.ProposerUtilization{
+		{Address: proposerA, Blocks: 2, AvgBytesUtilization: 0.4, AvgGasUtilization: 0.5},
+		{Address: proposerB, Blocks: 1, EmptyBlocks: 1, AvgBytesUtilization: 0.1},
+	}, res.Proposers)
+
+	_, err = env.BlockUtilization(context.Background(), 3, 2)
+	require.Error(t, err)
+}
@@ -1,10 +1,15 @@
 package core
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
 
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 )
 
 // Validators gets the validator set at the given block height.
@@ -31,8 +36,12 @@ func (env *Environment) Validators(
 	}
 
 	totalCount := len(validators.Validators)
-	perPage := env.validatePerPage(perPagePtr)
-	page, err := validatePage(pagePtr, perPage, totalCount)
+	perPage, err := env.validatePerPage("validators", perPagePtr)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := coretypes.ValidatePage(pagePtr, perPage, totalCount)
 	if err != nil {
 		return nil, err
 	}
@@ -41,6 +50,11 @@ func (env *Environment) Validators(
 
 	v := validators.Validators[skipCount : skipCount+tmmath.MinInt(perPage, totalCount-skipCount)]
 
+	// The validators of a given height do not change, once known.
+	if heightPtr != nil {
+		rpctypes.GetCallInfo(ctx).SetImmutable()
+	}
+
 	return &coretypes.ResultValidators{
 		BlockHeight: height,
 		Validators:  v,
@@ -48,6 +62,44 @@ func (env *Environment) Validators(
 		Total:       totalCount}, nil
 }
 
+// ValidatorsProof gets the validator set at a given height, with a Merkle
+// proof of each validator against the validators hash of the header at the
+// height, e.g. for bridges to verify the set on-chain. If no height is
+// provided, it will fetch the validator set of the latest block.
+// More: https://docs.tendermint.com/master/rpc/#/Info/validators_proof
+func (env *Environment) ValidatorsProof(ctx context.Context, heightPtr *int64) (*coretypes.ResultValidatorsProof, error) {
+	height, err := env.getHeight(env.BlockStore.Height(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	blockMeta := env.BlockStore.LoadBlockMeta(height)
+	if blockMeta == nil {
+		return nil, fmt.Errorf("header at height %d not found", height)
+	}
+	validators, err := env.StateStore.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+
+	root, proofs := validators.Proofs()
+	if !bytes.Equal(root, blockMeta.Header.ValidatorsHash) {
+		return nil, fmt.Errorf("validators hash %X of the header at height %d does not match the validator set hash %X",
+			blockMeta.Header.ValidatorsHash, height, root)
+	}
+
+	// The validators of a given height do not change, once known.
+	if heightPtr != nil {
+		rpctypes.GetCallInfo(ctx).SetImmutable()
+	}
+
+	return &coretypes.ResultValidatorsProof{
+		BlockHeight:    height,
+		ValidatorsHash: root,
+		Proofs:         proofs,
+	}, nil
+}
+
 // DumpConsensusState dumps consensus state.
 // UNSTABLE
 // More: https://docs.tendermint.com/master/rpc/#/Info/dump_consensus_state
@@ -98,6 +150,13 @@ func (env *Environment) GetConsensusState(ctx context.Context) (*coretypes.Resul
 	return &coretypes.ResultConsensusState{RoundState: bz}, err
 }
 
+// LockHistory returns the most recent changes of the locked and valid blocks
+// of the consensus state machine, oldest first.
+// UNSTABLE
+func (env *Environment) LockHistory(ctx context.Context) (*coretypes.ResultLockHistory, error) {
+	return &coretypes.ResultLockHistory{Changes: env.ConsensusState.GetLockHistory()}, nil
+}
+
 // ConsensusParams gets the consensus parameters at the given block height.
 // If no height is provided, it will fetch the latest consensus params.
 // More: https://docs.tendermint.com/master/rpc/#/Info/consensus_params
@@ -119,3 +178,108 @@ func (env *Environment) ConsensusParams(ctx context.Context, heightPtr *int64) (
 		BlockHeight:     height,
 		ConsensusParams: consensusParams}, nil
 }
+
+// ConsensusParamsHistory gets the consensus parameters in effect at the given
+// block height, with the changes of the parameters up to the height, field by
+// field, to validate the blocks of the height under the parameters in effect
+// at the time. If no height is provided, it will fetch the latest consensus
+// params. The changes start at the oldest version of the params not pruned.
+// UNSTABLE
+func (env *Environment) ConsensusParamsHistory(
+	ctx context.Context,
+	heightPtr *int64,
+) (*coretypes.ResultConsensusParamsHistory, error) {
+	height, err := env.getHeight(env.latestUncommittedHeight(), heightPtr)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := env.StateStore.LoadConsensusParamsHistory(height)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &coretypes.ResultConsensusParamsHistory{
+		BlockHeight:     height,
+		ConsensusParams: history[len(history)-1].Params,
+		BaseHeight:      history[0].Height,
+		Changes:         []coretypes.ConsensusParamChange{},
+	}
+	for i := 1; i < len(history); i++ {
+		oldParams, err := json.Marshal(history[i-1].Params)
+		if err != nil {
+			return nil, err
+		}
+		newParams, err := json.Marshal(history[i].Params)
+		if err != nil {
+			return nil, err
+		}
+		result.Changes, err = appendParamChanges(result.Changes, history[i].Height, "", oldParams, newParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// appendParamChanges appends the changes from the JSON value old to new, at
+// the height, of the field and its subfields, named by the path of their JSON
+// names. A field missing on one side has a null value.
+func appendParamChanges(
+	changes []coretypes.ConsensusParamChange,
+	height int64,
+	field string,
+	old, new json.RawMessage,
+) ([]coretypes.ConsensusParamChange, error) {
+	if bytes.Equal(old, new) {
+		return changes, nil
+	}
+	oldFields, oldIsObject, err := jsonObject(old)
+	if err != nil {
+		return nil, err
+	}
+	newFields, newIsObject, err := jsonObject(new)
+	if err != nil {
+		return nil, err
+	}
+	if !oldIsObject || !newIsObject {
+		return append(changes, coretypes.ConsensusParamChange{Height: height, Field: field, Old: old, New: new}), nil
+	}
+
+	names := make([]string, 0, len(oldFields)+len(newFields))
+	for name := range oldFields {
+		names = append(names, name)
+	}
+	for name := range newFields {
+		if _, ok := oldFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		subfield := name
+		if field != "" {
+			subfield = field + "." + name
+		}
+		if changes, err = appendParamChanges(changes, height, subfield, oldFields[name], newFields[name]); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// jsonObject returns the fields of the JSON value v, and whether it is an
+// object. A missing value is an object without fields.
+func jsonObject(v json.RawMessage) (map[string]json.RawMessage, bool, error) {
+	if len(v) == 0 {
+		return nil, true, nil
+	}
+	if v[0] != '{' {
+		return nil, false, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(v, &fields); err != nil {
+		return nil, false, err
+	}
+	return fields, true, nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestConsensusParamsHistory(t *testing.T) {
+	params1 := *types.DefaultConsensusParams()
+	params5 := params1
+	params5.Block.MaxBytes = 2000
+	params5.Block.MaxGas = 100
+	params9 := params5
+	params9.Feature.ActivationHeights = map[string]int64{"vote_extensions": 20}
+
+	statestore := &mocks.Store{}
+	statestore.On("LoadConsensusParamsHistory", int64(10)).Return([]sm.ConsensusParamsVersion{
+		{Height: 1, Params: params1},
+		{Height: 5, Params: params5},
+		{Height: 9, Params: params9},
+	}, nil)
+	blockstore := &mocks.BlockStore{}
+	blockstore.On("Base").Return(int64(1))
+	blockstore.On("Height").Return(int64(10))
+
+	env := &Environment{StateStore: statestore, BlockStore: blockstore}
+
+	height := int64(10)
+	res, err := env.ConsensusParamsHistory(context.Background(), &height)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, res.BlockHeight)
+	require.EqualValues(t, 1, res.BaseHeight)
+	require.Equal(t, params9, res.ConsensusParams)
+	require.Equal(t, []coretypes.ConsensusParamChange{
+		{Height: 5, Field: "block.max_bytes", Old: json.RawMessage(`"22020096"`), New: json.RawMessage(`"2000"`)},
+		{Height: 5, Field: "block.max_gas", Old: json.RawMessage(`"-1"`), New: json.RawMessage(`"100"`)},
+		{Height: 9, Field: "feature.activation_heights.vote_extensions", New: json.RawMessage(`20`)},
+	}, res.Changes)
+
+	height = 12
+	_, err = env.ConsensusParamsHistory(context.Background(), &height)
+	require.Error(t, err)
+}
+
+func TestValidatorsProof(t *testing.T) {
+	ctx := context.Background()
+	vals, _ := factory.RandValidatorSet(ctx, t, 5, 10)
+	otherVals, _ := factory.RandValidatorSet(ctx, t, 2, 10)
+
+	statestore := &mocks.Store{}
+	statestore.On("LoadValidators", int64(10)).Return(vals, nil)
+	statestore.On("LoadValidators", int64(9)).Return(otherVals, nil)
+	blockstore := &mocks.BlockStore{}
+	blockstore.On("Base").Return(int64(1))
+	blockstore.On("Height").Return(int64(10))
+	for _, height := range []int64{9, 10} {
+		blockstore.On("LoadBlockMeta", height).Return(&types.BlockMeta{
+			Header: types.Header{Height: height, ValidatorsHash: vals.Hash()},
+		})
+	}
+
+	env := &Environment{StateStore: statestore, BlockStore: blockstore}
+
+	res, err := env.ValidatorsProof(ctx, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, res.BlockHeight)
+	require.EqualValues(t, vals.Hash(), res.ValidatorsHash)
+	require.Len(t, res.Proofs, 5)
+	for i, proof := range res.Proofs {
+		require.EqualValues(t, vals.Validators[i].Address, proof.Address)
+		require.NoError(t, proof.Validate(res.ValidatorsHash))
+	}
+
+	// The validator set must match the header.
+	height := int64(9)
+	_, err = env.ValidatorsProof(ctx, &height)
+	require.Error(t, err)
+
+	height = 11
+	_, err = env.ValidatorsProof(ctx, &height)
+	require.Error(t, err)
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// maxBroadcastTxs bounds the number of transactions remembered at once for
+// deduplication; the oldest ones are forgotten first.
+const maxBroadcastTxs = 100000
+
+// broadcastTx is a transaction submitted to the mempool by a broadcast
+// request. Its CheckTx response, or the error of its submission, is set once
+// checked is closed.
+type broadcastTx struct {
+	key     string
+	expires time.Time
+
+	checked chan struct{}
+	res     *abci.ResponseCheckTx
+	err     error
+}
+
+// wait returns the CheckTx response of the transaction, waiting for it if
+// needed.
+func (b *broadcastTx) wait(ctx context.Context) (*abci.ResponseCheckTx, error) {
+	select {
+	case <-b.checked:
+		return b.res, b.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// broadcastTxs remembers the transactions broadcast over each listener of
+// the RPC server, so that their duplicates within the window get the result
+// of the original broadcast instead of reaching the mempool, whose cache
+// would reject them.
+type broadcastTxs struct {
+	mtx   sync.Mutex
+	txs   map[string]*broadcastTx
+	order []*broadcastTx // by expiry
+}
+
+// get returns the unexpired broadcast with the given key, or records a new
+// broadcast made now and returns it with found false.
+func (c *broadcastTxs) get(key string, window time.Duration) (b *broadcastTx, found bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := time.Now()
+	for len(c.order) > 0 && (len(c.order) >= maxBroadcastTxs || now.After(c.order[0].expires)) {
+		if old := c.order[0]; c.txs[old.key] == old {
+			delete(c.txs, old.key)
+		}
+		c.order[0] = nil
+		c.order = c.order[1:]
+	}
+
+	if b, ok := c.txs[key]; ok && !now.After(b.expires) {
+		return b, true
+	}
+
+	b = &broadcastTx{
+		key:     key,
+		expires: now.Add(window),
+		checked: make(chan struct{}),
+	}
+	if c.txs == nil {
+		c.txs = make(map[string]*broadcastTx)
+	}
+	c.txs[key] = b
+	c.order = append(c.order, b)
+	return b, false
+}
+
+func (c *broadcastTxs) forget(b *broadcastTx) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.txs[b.key] == b {
+		delete(c.txs, b.key)
+	}
+}
+
+// checkTx submits tx to the mempool and returns its broadcast. If the same
+// transaction was broadcast over the same listener within the dedup window,
+// it returns that broadcast instead. The transactions that failed to be
+// submitted or were rejected by CheckTx are not remembered, so that they can
+// be retried.
+func (env *Environment) checkTx(ctx context.Context, tx types.Tx) (*broadcastTx, error) {
+	window := env.Config.BroadcastDedupWindow
+
+	var b *broadcastTx
+	if window > 0 {
+		key := rpctypes.GetCallInfo(ctx).LocalAddr() + "/" + string(tx.Hash())
+		var found bool
+		if b, found = env.broadcastTxs.get(key, window); found {
+			return b, nil
+		}
+	} else {
+		b = &broadcastTx{checked: make(chan struct{})}
+	}
+
+	err := env.Mempool.CheckTx(ctx, tx, func(res *abci.Response) {
+		b.res = res.GetCheckTx()
+		close(b.checked)
+		if window > 0 && b.res.IsErr() {
+			env.broadcastTxs.forget(b)
+		}
+	}, rpcTxInfo(ctx))
+	if err != nil {
+		b.err = err
+		close(b.checked)
+		if window > 0 {
+			env.broadcastTxs.forget(b)
+		}
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/mempool"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// rejectingMempool rejects the transactions with CheckTx, without caching
+// them.
+type rejectingMempool struct {
+	countingMempool
+}
+
+func (m *rejectingMempool) CheckTx(_ context.Context, tx types.Tx, cb func(*abci.Response), _ mempool.TxInfo) error {
+	atomic.AddInt32(&m.calls, 1)
+	if cb != nil {
+		cb(abci.ToResponseCheckTx(abci.ResponseCheckTx{Code: 1, Log: "rejected"}))
+	}
+	return nil
+}
+
+func TestBroadcastDedup(t *testing.T) {
+	pool := &countingMempool{seen: make(map[string]bool)}
+	env := &Environment{
+		Mempool: pool,
+		Config:  *config.TestRPCConfig(),
+	}
+	env.Config.BroadcastDedupWindow = time.Minute
+
+	call := func(listener string) context.Context {
+		req := httptest.NewRequest("GET", "/broadcast_tx_sync", nil)
+		addr, err := net.ResolveTCPAddr("tcp", listener)
+		require.NoError(t, err)
+		req = req.WithContext(context.WithValue(req.Context(), http.LocalAddrContextKey, addr))
+		return rpctypes.WithCallInfo(context.Background(), &rpctypes.CallInfo{HTTPRequest: req})
+	}
+
+	// Duplicates get the result of the original broadcast.
+	tx := types.Tx("tx1")
+	res, err := env.BroadcastTxSync(call("127.0.0.1:26657"), tx)
+	require.NoError(t, err)
+	require.Equal(t, []byte(tx), []byte(res.Data))
+	for i := 0; i < 3; i++ {
+		dup, err := env.BroadcastTxSync(call("127.0.0.1:26657"), tx)
+		require.NoError(t, err)
+		require.Equal(t, res, dup)
+	}
+	_, err = env.BroadcastTxAsync(call("127.0.0.1:26657"), tx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&pool.calls))
+
+	// Including the duplicates of an async broadcast.
+	tx = types.Tx("tx2")
+	_, err = env.BroadcastTxAsync(call("127.0.0.1:26657"), tx)
+	require.NoError(t, err)
+	res, err = env.BroadcastTxSync(call("127.0.0.1:26657"), tx)
+	require.NoError(t, err)
+	require.Equal(t, []byte(tx), []byte(res.Data))
+	require.EqualValues(t, 2, atomic.LoadInt32(&pool.calls))
+
+	// The transactions are remembered per listener.
+	_, err = env.BroadcastTxSync(call("127.0.0.1:26658"), tx)
+	require.ErrorIs(t, err, types.ErrTxInCache)
+	require.EqualValues(t, 3, atomic.LoadInt32(&pool.calls))
+
+	// Failed broadcasts are not remembered.
+	_, err = env.BroadcastTxSync(call("127.0.0.1:26658"), tx)
+	require.ErrorIs(t, err, types.ErrTxInCache)
+	require.EqualValues(t, 4, atomic.LoadInt32(&pool.calls))
+
+	// Transactions are forgotten after the window.
+	env.Config.BroadcastDedupWindow = 10 * time.Millisecond
+	tx = types.Tx("tx3")
+	_, err = env.BroadcastTxSync(call("127.0.0.1:26657"), tx)
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = env.BroadcastTxSync(call("127.0.0.1:26657"), tx)
+	require.ErrorIs(t, err, types.ErrTxInCache)
+	require.EqualValues(t, 6, atomic.LoadInt32(&pool.calls))
+}
+
+func TestBroadcastDedupRejected(t *testing.T) {
+	pool := &rejectingMempool{}
+	env := &Environment{
+		Mempool: pool,
+		Config:  *config.TestRPCConfig(),
+	}
+	env.Config.BroadcastDedupWindow = time.Minute
+
+	// The transactions rejected by CheckTx are not remembered, so that they
+	// can be retried.
+	for i := 1; i <= 2; i++ {
+		res, err := env.BroadcastTxSync(context.Background(), types.Tx("tx"))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, res.Code)
+		require.EqualValues(t, i, atomic.LoadInt32(&pool.calls))
+	}
+}
@@ -2,12 +2,140 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/tendermint/tendermint/internal/mempool"
+	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
 )
 
+// maxCaptureDuration is the longest capture of the messages of a peer.
+const maxCaptureDuration = 10 * time.Minute
+
+// mempoolDumpsDir is the directory of the node's home of the mempool dumps.
+const mempoolDumpsDir = "mempool-dumps"
+
+// mempoolDumper is implemented by mempools that can be dumped and restored.
+type mempoolDumper interface {
+	Dump() mempool.Dump
+	Restore(mempool.Dump) (int, error)
+}
+
 // UnsafeFlushMempool removes all transactions from the mempool.
 func (env *Environment) UnsafeFlushMempool(ctx context.Context) (*coretypes.ResultUnsafeFlushMempool, error) {
 	env.Mempool.Flush()
 	return &coretypes.ResultUnsafeFlushMempool{}, nil
 }
+
+// UnsafeCaptureMessages captures the messages exchanged with a peer on a
+// channel for the given number of seconds, into a file of the captures
+// directory of the node's home, with the given redaction: none, bytes or all
+// (see p2p.WriteCapture). It returns the file without waiting for the end of
+// the capture.
+func (env *Environment) UnsafeCaptureMessages(
+	ctx context.Context,
+	peerID string,
+	channel int,
+	seconds int,
+	redact string,
+) (*coretypes.ResultCaptureMessages, error) {
+	nodeID, err := types.NewNodeID(peerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer ID: %v: %w", err, coretypes.ErrInvalidRequest)
+	}
+	if channel < 0 || channel > 0xff {
+		return nil, fmt.Errorf("invalid channel %d: %w", channel, coretypes.ErrInvalidRequest)
+	}
+	duration := time.Duration(seconds) * time.Second
+	if duration <= 0 || duration > maxCaptureDuration {
+		return nil, fmt.Errorf("the capture must last from 1 to %d seconds: %w",
+			int(maxCaptureDuration/time.Second), coretypes.ErrInvalidRequest)
+	}
+	redaction, err := p2p.ParseRedaction(redact)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, coretypes.ErrInvalidRequest)
+	}
+	if env.MessageCapturer == nil {
+		return nil, fmt.Errorf("message captures are not supported by this node")
+	}
+
+	dir := filepath.Join(env.Config.RootDir, "captures")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	start := time.Now().UTC()
+	name := filepath.Join(dir, fmt.Sprintf("%s-%02x-%s.jsonl", nodeID, channel, start.Format("20060102T150405.000Z")))
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	// The capture outlives the call.
+	captureCtx, cancel := context.WithTimeout(context.Background(), duration)
+	msgs := env.MessageCapturer.CaptureMessages(captureCtx, nodeID, p2p.ChannelID(channel))
+	go func() {
+		defer cancel()
+		n, err := p2p.WriteCapture(file, msgs, redaction)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			env.Logger.Error("failed to capture messages", "peer", nodeID, "channel", channel, "file", name, "err", err)
+			return
+		}
+		env.Logger.Info("captured messages", "peer", nodeID, "channel", channel, "file", name, "messages", n)
+	}()
+
+	return &coretypes.ResultCaptureMessages{File: name, Until: start.Add(duration)}, nil
+}
+
+// UnsafeDumpMempool writes the transactions of the mempool, with their
+// metadata, to a file of the mempool-dumps directory of the node's home. It
+// returns the file, which UnsafeRestoreMempool restores from.
+func (env *Environment) UnsafeDumpMempool(ctx context.Context) (*coretypes.ResultDumpMempool, error) {
+	md, ok := env.Mempool.(mempoolDumper)
+	if !ok {
+		return nil, fmt.Errorf("the mempool does not support dumps")
+	}
+
+	dir := filepath.Join(env.Config.RootDir, mempoolDumpsDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	dump := md.Dump()
+	name := filepath.Join(dir, fmt.Sprintf("mempool-%d-%s.json", dump.Height, dump.Time.UTC().Format("20060102T150405.000Z")))
+	if err := dump.Save(name); err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultDumpMempool{File: name, Height: dump.Height, Count: len(dump.Txs)}, nil
+}
+
+// UnsafeRestoreMempool inserts the transactions of the mempool dump in the
+// given file of the mempool-dumps directory of the node's home into the
+// mempool, with their metadata, without checking them with the application.
+// The transactions already in the mempool, or of a sender with a transaction
+// in the mempool, are skipped.
+func (env *Environment) UnsafeRestoreMempool(ctx context.Context, file string) (*coretypes.ResultRestoreMempool, error) {
+	if file == "" || filepath.Base(file) != file {
+		return nil, fmt.Errorf("the file must be the name of a file of the %s directory: %w",
+			mempoolDumpsDir, coretypes.ErrInvalidRequest)
+	}
+	md, ok := env.Mempool.(mempoolDumper)
+	if !ok {
+		return nil, fmt.Errorf("the mempool does not support dumps")
+	}
+
+	dump, err := mempool.LoadDump(filepath.Join(env.Config.RootDir, mempoolDumpsDir, file))
+	if err != nil {
+		return nil, err
+	}
+	restored, err := md.Restore(dump)
+	if err != nil {
+		return nil, fmt.Errorf("restored %d of the %d transactions: %w", restored, len(dump.Txs), err)
+	}
+	return &coretypes.ResultRestoreMempool{Restored: restored, Skipped: len(dump.Txs) - restored}, nil
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gogotypes "github.com/gogo/protobuf/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/mempool"
+	mempoolmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+// capturer captures a single message.
+type capturer struct {
+	peerID types.NodeID
+	chID   p2p.ChannelID
+}
+
+func (c *capturer) CaptureMessages(ctx context.Context, peerID types.NodeID, chID p2p.ChannelID) <-chan p2p.CapturedMessage {
+	c.peerID, c.chID = peerID, chID
+	msgs := make(chan p2p.CapturedMessage, 1)
+	msgs <- p2p.CapturedMessage{
+		Peer:      peerID,
+		ChannelID: chID,
+		Message:   &gogotypes.BytesValue{Value: []byte("secret")},
+	}
+	close(msgs)
+	return msgs
+}
+
+func TestUnsafeCaptureMessages(t *testing.T) {
+	ctx := context.Background()
+	c := &capturer{}
+	env := &Environment{
+		Logger:          log.NewNopLogger(),
+		Config:          config.RPCConfig{RootDir: t.TempDir()},
+		MessageCapturer: c,
+	}
+	peerID := strings.Repeat("ab", 20)
+
+	res, err := env.UnsafeCaptureMessages(ctx, peerID, 0x20, 5, "all")
+	require.NoError(t, err)
+	assert.Equal(t, types.NodeID(peerID), c.peerID)
+	assert.Equal(t, p2p.ChannelID(0x20), c.chID)
+	assert.Equal(t, filepath.Join(env.Config.RootDir, "captures"), filepath.Dir(res.File))
+	assert.WithinDuration(t, time.Now().Add(5*time.Second), res.Until, time.Second)
+
+	require.Eventually(t, func() bool {
+		bz, err := os.ReadFile(res.File)
+		require.NoError(t, err)
+		return strings.Contains(string(bz), `"type":"google.protobuf.BytesValue"`)
+	}, 5*time.Second, 10*time.Millisecond)
+	bz, err := os.ReadFile(res.File)
+	require.NoError(t, err)
+	assert.NotContains(t, string(bz), "message")
+
+	for _, tc := range []struct {
+		peerID           string
+		channel, seconds int
+		redact           string
+	}{
+		{"invalid", 0x20, 5, ""},
+		{peerID, 0x100, 5, ""},
+		{peerID, 0x20, 0, ""},
+		{peerID, 0x20, 601, ""},
+		{peerID, 0x20, 5, "some"},
+	} {
+		_, err := env.UnsafeCaptureMessages(ctx, tc.peerID, tc.channel, tc.seconds, tc.redact)
+		assert.True(t, errors.Is(err, coretypes.ErrInvalidRequest), err)
+	}
+}
+
+// dumpMempool dumps the transactions it is given, and records those it
+// restores.
+type dumpMempool struct {
+	mempoolmock.Mempool
+	dump     mempool.Dump
+	restored []types.Tx
+}
+
+func (m *dumpMempool) Dump() mempool.Dump { return m.dump }
+
+func (m *dumpMempool) Restore(dump mempool.Dump) (int, error) {
+	for i, dtx := range dump.Txs {
+		if dtx.Priority < 0 {
+			return i, errors.New("mempool is full")
+		}
+		m.restored = append(m.restored, dtx.Tx)
+	}
+	return len(dump.Txs), nil
+}
+
+func TestUnsafeDumpRestoreMempool(t *testing.T) {
+	ctx := context.Background()
+	mp := &dumpMempool{dump: mempool.Dump{
+		Height: 7,
+		Time:   time.Now(),
+		Txs: []mempool.DumpedTx{
+			{Tx: types.Tx("a"), Priority: 10, Sender: "alice"},
+			{Tx: types.Tx("b"), Priority: 5},
+		},
+	}}
+	env := &Environment{
+		Config:  config.RPCConfig{RootDir: t.TempDir()},
+		Mempool: mp,
+	}
+
+	res, err := env.UnsafeDumpMempool(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(env.Config.RootDir, "mempool-dumps"), filepath.Dir(res.File))
+	assert.EqualValues(t, 7, res.Height)
+	assert.Equal(t, 2, res.Count)
+
+	restored, err := env.UnsafeRestoreMempool(ctx, filepath.Base(res.File))
+	require.NoError(t, err)
+	assert.Equal(t, &coretypes.ResultRestoreMempool{Restored: 2}, restored)
+	assert.Equal(t, []types.Tx{types.Tx("a"), types.Tx("b")}, mp.restored)
+
+	// Only the files of the dumps directory are restored.
+	for _, file := range []string{"", "../mempool.json", res.File} {
+		_, err := env.UnsafeRestoreMempool(ctx, file)
+		assert.True(t, errors.Is(err, coretypes.ErrInvalidRequest), file)
+	}
+	_, err = env.UnsafeRestoreMempool(ctx, "missing.json")
+	assert.Error(t, err)
+
+	// A mempool too full for the dump is reported.
+	mp.dump.Txs[1].Priority = -1
+	res, err = env.UnsafeDumpMempool(ctx)
+	require.NoError(t, err)
+	_, err = env.UnsafeRestoreMempool(ctx, filepath.Base(res.File))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "restored 1 of the 2 transactions")
+}
@@ -6,40 +6,12 @@ https://github.com/tendermint/tendermint/tree/master/rpc/jsonrpc.
 
 ## Get the list
 
-An HTTP Get request to the root RPC endpoint shows a list of available endpoints.
+An HTTP Get request to the root RPC endpoint, or to /openapi.json, returns an
+OpenAPI 3 document describing the available endpoints, their arguments and
+results.
 
 ```bash
-curl 'localhost:26657'
-```
-
-> Response:
-
-```plain
-Available endpoints:
-/abci_info
-/dump_consensus_state
-/genesis
-/net_info
-/num_unconfirmed_txs
-/status
-/health
-/unconfirmed_txs
-/unsafe_flush_mempool
-/validators
-
-Endpoints that require arguments:
-/abci_query?path=_&data=_&prove=_
-/block?height=_
-/blockchain?minHeight=_&maxHeight=_
-/broadcast_tx_async?tx=_
-/broadcast_tx_commit?tx=_
-/broadcast_tx_sync?tx=_
-/commit?height=_
-/dial_seeds?seeds=_
-/dial_persistent_peers?persistent_peers=_
-/subscribe?event=_
-/tx?hash=_&prove=_
-/unsubscribe?event=_
+curl 'localhost:26657/openapi.json'
 ```
 */
 package core
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,11 +11,15 @@ import (
 	"time"
 
 	"github.com/rs/cors"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/internal/blocksync"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/features"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/proxy"
@@ -27,10 +32,11 @@ import (
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
 	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/version"
 )
 
 const (
-	// see README
+	// see README; used when the pagination settings are not configured
 	defaultPerPage = 30
 	maxPerPage     = 100
 
@@ -52,6 +58,7 @@ type consensusState interface {
 	GetLastHeight() int64
 	GetRoundStateJSON() ([]byte, error)
 	GetRoundStateSimpleJSON() ([]byte, error)
+	GetLockHistory() []types.EventDataLockChange
 }
 
 type transport interface {
@@ -63,6 +70,61 @@ type transport interface {
 type peerManager interface {
 	Peers() []types.NodeID
 	Addresses(types.NodeID) []p2p.NodeAddress
+	Stats() *p2p.PeerStatsStore
+	Maintenance() *p2p.PeerMaintenance
+	ExportAddressBook() []p2p.AddressBookPeer
+	ImportAddressBook([]p2p.AddressBookPeer) (int, error)
+	ScoreDetails() []p2p.PeerScoreDetails
+	Errored(types.NodeID, error)
+}
+
+type peerFilter interface {
+	Rules() []p2p.PeerFilterRule
+	AddRule(p2p.PeerFilterRule) (bool, error)
+	RemoveRule(p2p.PeerFilterRule) (bool, error)
+}
+
+type validatorBook interface {
+	Lookup(types.Address) (p2p.ValidatorIdentity, bool)
+}
+
+type bandwidthMeter interface {
+	PeerBandwidth() []p2p.PeerBandwidth
+}
+
+type snapshotService interface {
+	Status() (statesync.SnapshotStatus, error)
+}
+
+type pruner interface {
+	Status() sm.PruningStatus
+}
+
+type forensics interface {
+	Heights() ([]int64, error)
+	LoadBundle(height int64) (*sm.AppHashMismatchBundle, error)
+}
+
+type loadShedder interface {
+	Shedding() bool
+}
+
+type forkDetector interface {
+	Forks() []types.EventDataForkDetected
+}
+
+type proposalBuilder interface {
+	CreateProposalBlock(
+		ctx context.Context,
+		height int64,
+		state sm.State, commit *types.Commit,
+		proposerAddr []byte,
+	) (*types.Block, *types.PartSet, error)
+	ProcessProposal(ctx context.Context, block *types.Block) (bool, error)
+}
+
+type messageCapturer interface {
+	CaptureMessages(ctx context.Context, peerID types.NodeID, chID p2p.ChannelID) <-chan p2p.CapturedMessage
 }
 
 //----------------------------------------------
@@ -85,7 +147,31 @@ type Environment struct {
 	P2PTransport transport
 
 	// interfaces for new p2p interfaces
-	PeerManager peerManager
+	PeerManager         peerManager
+	MessageCapturer     messageCapturer
+	BandwidthMeter      bandwidthMeter
+	ValidatorIdentities validatorBook
+	PeerFilterRules     peerFilter
+
+	// the snapshot service, if enabled
+	SnapshotService snapshotService
+
+	// the forensic bundles of the app hash mismatches, if enabled
+	Forensics forensics
+
+	// the pruner of the blocks below the retain height
+	Pruner pruner
+
+	// the watchdog of the resource usage, rejecting the requests while the
+	// node sheds load, if enabled
+	LoadShedder loadShedder
+
+	// the detector of the peers committing other blocks than the node
+	ForkDetector forkDetector
+
+	// the block executor of consensus, building and processing the blocks of
+	// SimulateProposal
+	BlockExecutor proposalBuilder
 
 	// objects
 	PubKey            crypto.PubKey
@@ -94,6 +180,13 @@ type Environment struct {
 	EventBus          *eventbus.EventBus // thread safe
 	Mempool           mempool.Mempool
 	StateSyncMetricer statesync.Metricer
+	RPCMetrics        *rpcserver.Metrics
+	Features          *features.Set
+
+	// the external validator authority, whose signed validator updates are
+	// broadcast as transactions
+	ValidatorAuthority      crypto.PubKey
+	ValidatorUpdateMinDelay int64
 
 	Logger log.Logger
 
@@ -101,46 +194,40 @@ type Environment struct {
 
 	// cache of chunked genesis data.
 	genChunks []string
-}
 
-//----------------------------------------------
+	// subscriptions opened with SubscribeLabeled
+	labeledSubs labeledSubscriptions
 
-func validatePage(pagePtr *int, perPage, totalCount int) (int, error) {
-	// this can only happen if we haven't first run validatePerPage
-	if perPage < 1 {
-		panic(fmt.Errorf("%w (%d)", coretypes.ErrZeroOrNegativePerPage, perPage))
-	}
+	// broadcasts made with an idempotency key
+	idempotentCalls idempotentCalls
 
-	if pagePtr == nil { // no page parameter
-		return 1, nil
-	}
+	// recently broadcast transactions, for deduplication
+	broadcastTxs broadcastTxs
 
-	pages := ((totalCount - 1) / perPage) + 1
-	if pages == 0 {
-		pages = 1 // one page (even if it's empty)
-	}
-	page := *pagePtr
-	if page <= 0 || page > pages {
-		return 1, fmt.Errorf("%w expected range: [1, %d], given %d", coretypes.ErrPageOutOfRange, pages, page)
-	}
+	// responses to the cacheable ABCI queries
+	abciQueries abciQueryCache
 
-	return page, nil
+	// websocket connections of the RPC server, set by StartService
+	wsManager *rpcserver.WebsocketManager
 }
 
-func (env *Environment) validatePerPage(perPagePtr *int) int {
-	if perPagePtr == nil { // no per_page parameter
-		return defaultPerPage
-	}
+//----------------------------------------------
 
-	perPage := *perPagePtr
-	if perPage < 1 {
-		return defaultPerPage
-		// in unsafe mode there is no max on the page size but in safe mode
-		// we cap it to maxPerPage
-	} else if perPage > maxPerPage && !env.Config.Unsafe {
-		return maxPerPage
+// validatePerPage returns the page size requested by perPagePtr for the given
+// endpoint, enforcing its configured default and cap. The cap is not enforced
+// in unsafe mode.
+func (env *Environment) validatePerPage(endpoint string, perPagePtr *int) (int, error) {
+	limits := env.Config.PageLimits(endpoint)
+	if limits.DefaultPerPage < 1 {
+		limits.DefaultPerPage = defaultPerPage
 	}
-	return perPage
+	if limits.MaxPerPage < 1 {
+		limits.MaxPerPage = maxPerPage
+	}
+	if env.Config.Unsafe {
+		limits.MaxPerPage = 0
+	}
+	return coretypes.ValidatePerPage(perPagePtr, limits.DefaultPerPage, limits.MaxPerPage)
 }
 
 // InitGenesisChunks configures the environment and should be called on service
@@ -172,6 +259,19 @@ func (env *Environment) InitGenesisChunks() error {
 	return nil
 }
 
+// parseCursor parses the cursor of a search, from which its results are
+// returned instead of from a page.
+func parseCursor(pagePtr *int, cursor string) (indexer.Cursor, error) {
+	if pagePtr != nil && *pagePtr != 1 {
+		return indexer.Cursor{}, fmt.Errorf("page and cursor are mutually exclusive: %w", coretypes.ErrInvalidRequest)
+	}
+	after, err := indexer.ParseCursor(cursor)
+	if err != nil {
+		return indexer.Cursor{}, fmt.Errorf("%v: %w", err, coretypes.ErrInvalidRequest)
+	}
+	return after, nil
+}
+
 func validateSkipCount(page, perPage int) int {
 	skipCount := (page - 1) * perPage
 	if skipCount < 0 {
@@ -213,9 +313,9 @@ func (env *Environment) latestUncommittedHeight() int64 {
 	return env.BlockStore.Height() + 1
 }
 
-// StartService constructs and starts listeners for the RPC service
-// according to the config object, returning an error if the service
-// cannot be constructed or started. The listeners, which provide
+// StartService constructs and starts listeners for the RPC service, and for
+// the gRPC service if one is configured, according to the config object,
+// returning an error if the service cannot be constructed or started. The listeners, which provide
 // access to the service, run until the context is canceled.
 func (env *Environment) StartService(ctx context.Context, conf *config.Config) ([]net.Listener, error) {
 	if err := env.InitGenesisChunks(); err != nil {
@@ -224,13 +324,23 @@ func (env *Environment) StartService(ctx context.Context, conf *config.Config) (
 
 	listenAddrs := strings.SplitAndTrimEmpty(conf.RPC.ListenAddress, ",", " ")
 	routes := NewRoutesMap(env, &RouteOptions{
-		Unsafe: conf.RPC.Unsafe,
+		Unsafe:   conf.RPC.Unsafe,
+		ReadOnly: conf.RPC.ReadOnly,
 	})
 
 	cfg := rpcserver.DefaultConfig()
 	cfg.MaxBodyBytes = conf.RPC.MaxBodyBytes
 	cfg.MaxHeaderBytes = conf.RPC.MaxHeaderBytes
+	cfg.HTTP2 = conf.RPC.HTTP2
+	cfg.HTTP2MaxConcurrentStreams = conf.RPC.HTTP2MaxConcurrentStreams
+	cfg.HTTP2MaxUploadBufferPerStream = conf.RPC.HTTP2StreamWindowBytes
+	cfg.HTTP2MaxUploadBufferPerConnection = conf.RPC.HTTP2ConnWindowBytes
 	cfg.MaxOpenConnections = conf.RPC.MaxOpenConnections
+	unixSocketMode, err := conf.RPC.UnixSocketFileMode()
+	if err != nil {
+		return nil, err
+	}
+	cfg.UnixSocketMode = unixSocketMode
 	// If necessary adjust global WriteTimeout to ensure it's greater than
 	// TimeoutBroadcastTxCommit.
 	// See https://github.com/tendermint/tendermint/issues/3435
@@ -238,27 +348,56 @@ func (env *Environment) StartService(ctx context.Context, conf *config.Config) (
 		cfg.WriteTimeout = conf.RPC.TimeoutBroadcastTxCommit + 1*time.Second
 	}
 
+	methodLimits := make(map[string]rpcserver.RateLimit, len(conf.RPC.MethodRateLimits))
+	for method, limit := range conf.RPC.MethodRateLimits {
+		methodLimits[method] = rpcserver.RateLimit(limit)
+	}
+	rateLimiter := rpcserver.NewRateLimiter(rpcserver.RateLimit(conf.RPC.RateLimit), methodLimits)
+
+	var responseCache *rpcserver.ResponseCache
+	if conf.RPC.ResponseCacheSize > 0 {
+		responseCache = rpcserver.NewResponseCache(conf.RPC.ResponseCacheSize, conf.RPC.ResponseCacheTTL)
+	}
+
+	var acmeTLS *tls.Config
+	if conf.RPC.IsACMEEnabled() {
+		acmeTLS = env.startACME(ctx, conf)
+	}
+
+	// The websocket connections are limited and drained across all the
+	// listeners.
+	rpcLogger := env.Logger.With("module", "rpc-server")
+	wmLogger := rpcLogger.With("protocol", "websocket")
+	wm := rpcserver.NewWebsocketManager(wmLogger, routes,
+		rpcserver.OnDisconnect(func(remoteAddr string) {
+			err := env.EventBus.UnsubscribeAll(context.Background(), remoteAddr)
+			if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
+				wmLogger.Error("Failed to unsubscribe addr from events", "addr", remoteAddr, "err", err)
+			}
+		}),
+		rpcserver.ReadLimit(cfg.MaxBodyBytes),
+		rpcserver.WSRateLimits(rateLimiter),
+		rpcserver.WSRecordMetrics(env.RPCMetrics),
+		rpcserver.WSStrictJSONRPC(conf.RPC.StrictJSONRPC),
+	)
+	wm.SetMaxConnections(conf.RPC.MaxWebsocketConnections)
+	env.wsManager = wm
+
 	// we may expose the rpc over both a unix and tcp socket
 	listeners := make([]net.Listener, len(listenAddrs))
 	for i, listenAddr := range listenAddrs {
 		mux := http.NewServeMux()
-		rpcLogger := env.Logger.With("module", "rpc-server")
-		wmLogger := rpcLogger.With("protocol", "websocket")
-		wm := rpcserver.NewWebsocketManager(wmLogger, routes,
-			rpcserver.OnDisconnect(func(remoteAddr string) {
-				err := env.EventBus.UnsubscribeAll(context.Background(), remoteAddr)
-				if err != nil && err != tmpubsub.ErrSubscriptionNotFound {
-					wmLogger.Error("Failed to unsubscribe addr from events", "addr", remoteAddr, "err", err)
-				}
-			}),
-			rpcserver.ReadLimit(cfg.MaxBodyBytes),
-		)
 		mux.HandleFunc("/websocket", wm.WebsocketHandler)
-		rpcserver.RegisterRPCFuncs(mux, routes, rpcLogger)
-		listener, err := rpcserver.Listen(
-			listenAddr,
-			cfg.MaxOpenConnections,
-		)
+		// The event archive ends before the write timeout, to be resumed.
+		mux.HandleFunc(eventArchivePath, env.eventArchiveHandler(cfg.WriteTimeout*3/4))
+		rpcserver.RegisterRPCFuncs(mux, routes, rpcLogger,
+			rpcserver.StreamBatches(conf.RPC.StreamBatchThreshold),
+			rpcserver.RateLimits(rateLimiter),
+			rpcserver.RecordMetrics(env.RPCMetrics),
+			rpcserver.CacheResponses(responseCache),
+			rpcserver.StrictJSONRPC(conf.RPC.StrictJSONRPC),
+			rpcserver.APIInfo("Tendermint RPC", version.TMVersion))
+		listener, err := rpcserver.ListenWithConfig(listenAddr, cfg)
 		if err != nil {
 			return nil, err
 		}
@@ -272,7 +411,26 @@ func (env *Environment) StartService(ctx context.Context, conf *config.Config) (
 			})
 			rootHandler = corsMiddleware.Handler(mux)
 		}
-		if conf.RPC.IsTLSEnabled() {
+		if env.LoadShedder != nil {
+			rootHandler = rpcserver.ShedLoadHandler(rootHandler, env.LoadShedder.Shedding)
+		}
+		switch {
+		case acmeTLS != nil:
+			go func() {
+				rpcLogger.Info("Starting RPC HTTPS server with ACME certificates",
+					"laddr", listener.Addr(), "domains", conf.RPC.ACMEDomains)
+				if err := rpcserver.ServeWithTLSConfig(
+					ctx,
+					listener,
+					rootHandler,
+					acmeTLS,
+					rpcLogger,
+					cfg,
+				); err != nil {
+					env.Logger.Error("error serving server with ACME TLS", "err", err)
+				}
+			}()
+		case conf.RPC.IsTLSEnabled():
 			go func() {
 				if err := rpcserver.ServeTLS(
 					ctx,
@@ -286,7 +444,7 @@ func (env *Environment) StartService(ctx context.Context, conf *config.Config) (
 					env.Logger.Error("error serving server with TLS", "err", err)
 				}
 			}()
-		} else {
+		default:
 			go func() {
 				if err := rpcserver.Serve(
 					ctx,
@@ -303,6 +461,66 @@ func (env *Environment) StartService(ctx context.Context, conf *config.Config) (
 		listeners[i] = listener
 	}
 
+	if conf.RPC.GRPCListenAddress != "" {
+		listener, err := rpcserver.Listen(conf.RPC.GRPCListenAddress, conf.RPC.GRPCMaxOpenConnections)
+		if err != nil {
+			return nil, err
+		}
+		env.startGRPC(ctx, listener, conf.RPC.ReadOnly)
+		listeners = append(listeners, listener)
+	}
+
 	return listeners, nil
 
 }
+
+// DrainWebsockets gracefully closes the websocket connections of the RPC
+// server, with a close frame carrying reason, after writing the events
+// already published to their subscriptions, and rejects new connections. The
+// connections still open when ctx ends are closed abruptly.
+func (env *Environment) DrainWebsockets(ctx context.Context, reason string) error {
+	if env.wsManager == nil {
+		return nil
+	}
+	return env.wsManager.Drain(ctx, reason)
+}
+
+// startACME constructs the certificate manager used to obtain and renew
+// RPC certificates via ACME, and starts the HTTP-01 challenge responder if
+// one is configured. The returned TLS configuration also answers
+// TLS-ALPN-01 challenges.
+func (env *Environment) startACME(ctx context.Context, conf *config.Config) *tls.Config {
+	mgr := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(conf.RPC.ACMEDomains...),
+		Cache:      autocert.DirCache(conf.RPC.ACMECacheDirPath()),
+		Email:      conf.RPC.ACMEEmail,
+	}
+	if conf.RPC.ACMEDirectoryURL != "" {
+		mgr.Client = &acme.Client{DirectoryURL: conf.RPC.ACMEDirectoryURL}
+	}
+
+	if addr := conf.RPC.ACMEHTTPListenAddress; addr != "" {
+		srv := &http.Server{
+			Addr:              addr,
+			Handler:           mgr.HTTPHandler(nil),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			<-ctx.Done()
+			sctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			_ = srv.Shutdown(sctx)
+		}()
+		go func() {
+			env.Logger.Info("Starting ACME HTTP-01 challenge server", "laddr", addr)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				env.Logger.Error("ACME challenge server error", "err", err)
+			}
+		}()
+	}
+
+	tlsConfig := mgr.TLSConfig()
+	tlsConfig.MinVersion = tls.VersionTLS12
+	return tlsConfig
+}
@@ -5,86 +5,68 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/rpc/coretypes"
 )
 
-func TestPaginationPage(t *testing.T) {
+func TestPaginationPerPage(t *testing.T) {
 	cases := []struct {
-		totalCount int
 		perPage    int
-		page       int
-		newPage    int
+		newPerPage int
 		expErr     bool
 	}{
-		{0, 10, 1, 1, false},
-
-		{0, 10, 0, 1, false},
-		{0, 10, 1, 1, false},
-		{0, 10, 2, 0, true},
-
-		{5, 10, -1, 0, true},
-		{5, 10, 0, 1, false},
-		{5, 10, 1, 1, false},
-		{5, 10, 2, 0, true},
-		{5, 10, 2, 0, true},
-
-		{5, 5, 1, 1, false},
-		{5, 5, 2, 0, true},
-		{5, 5, 3, 0, true},
-
-		{5, 3, 2, 2, false},
-		{5, 3, 3, 0, true},
-
-		{5, 2, 2, 2, false},
-		{5, 2, 3, 3, false},
-		{5, 2, 4, 0, true},
+		{0, defaultPerPage, false},
+		{1, 1, false},
+		{2, 2, false},
+		{defaultPerPage, defaultPerPage, false},
+		{maxPerPage - 1, maxPerPage - 1, false},
+		{maxPerPage, maxPerPage, false},
+		{maxPerPage + 1, 0, true},
 	}
 
+	env := &Environment{Config: *config.DefaultRPCConfig()}
+
 	for _, c := range cases {
-		p, err := validatePage(&c.page, c.perPage, c.totalCount)
+		p, err := env.validatePerPage("validators", &c.perPage)
 		if c.expErr {
-			assert.Error(t, err)
+			assert.ErrorIs(t, err, coretypes.ErrPerPageExceedsMax)
 			continue
 		}
-
-		assert.Equal(t, c.newPage, p, fmt.Sprintf("%v", c))
-	}
-
-	// nil case
-	p, err := validatePage(nil, 1, 1)
-	if assert.NoError(t, err) {
-		assert.Equal(t, 1, p)
-	}
-}
-
-func TestPaginationPerPage(t *testing.T) {
-	cases := []struct {
-		perPage    int
-		newPerPage int
-	}{
-		{0, defaultPerPage},
-		{1, 1},
-		{2, 2},
-		{defaultPerPage, defaultPerPage},
-		{maxPerPage - 1, maxPerPage - 1},
-		{maxPerPage, maxPerPage},
-		{maxPerPage + 1, maxPerPage},
-	}
-
-	env := &Environment{}
-
-	for _, c := range cases {
-		p := env.validatePerPage(&c.perPage)
+		assert.NoError(t, err)
 		assert.Equal(t, c.newPerPage, p, fmt.Sprintf("%v", c))
 	}
 
 	// nil case
-	p := env.validatePerPage(nil)
+	p, err := env.validatePerPage("validators", nil)
+	assert.NoError(t, err)
 	assert.Equal(t, defaultPerPage, p)
 
+	// per-endpoint overrides
+	env.Config.PerPage = map[string]config.RPCPageLimits{
+		"tx_search": {DefaultPerPage: 5, MaxPerPage: 1000},
+	}
+	p, err = env.validatePerPage("tx_search", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, p)
+	perPage := 1000
+	p, err = env.validatePerPage("tx_search", &perPage)
+	assert.NoError(t, err)
+	assert.Equal(t, perPage, p)
+	_, err = env.validatePerPage("block_search", &perPage)
+	assert.Contains(t, err.Error(), "the maximum is 100")
+
 	// test in unsafe mode
 	env.Config.Unsafe = true
-	perPage := 1000
-	p = env.validatePerPage(&perPage)
+	perPage = 10000
+	p, err = env.validatePerPage("tx_search", &perPage)
+	assert.NoError(t, err)
 	assert.Equal(t, perPage, p)
 	env.Config.Unsafe = false
+
+	// unconfigured environments fall back to the defaults
+	env = &Environment{}
+	p, err = env.validatePerPage("validators", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultPerPage, p)
 }
@@ -6,67 +6,97 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/tendermint/tendermint/internal/eventbus"
 	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
 	tmquery "github.com/tendermint/tendermint/internal/pubsub/query"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
 )
 
 const (
 	// Buffer on the Tendermint (server) side to allow some slowness in clients.
 	subBufferSize = 100
 
+	// maxSubBufferSize is the maximum buffer a client can request for a
+	// labeled subscription.
+	maxSubBufferSize = 10000
+
 	// maxQueryLength is the maximum length of a query string that will be
 	// accepted. This is just a safety check to avoid outlandish queries.
 	maxQueryLength = 512
 )
 
 // Subscribe for events via WebSocket.
+// If after is non-zero, it is the sequence number of the last event received
+// by the client before it was disconnected: the retained events published
+// since then are delivered first, provided they are still in the event log.
+// If statsInterval is positive, a frame reporting the delivery statistics of
+// the subscription is sent every statsInterval seconds, and before the
+// subscription is terminated by the server.
 // More: https://docs.tendermint.com/master/rpc/#/Websocket/subscribe
-func (env *Environment) Subscribe(ctx context.Context, query string) (*coretypes.ResultSubscribe, error) {
+func (env *Environment) Subscribe(ctx context.Context, query string, after uint64, statsInterval int64) (*coretypes.ResultSubscribe, error) {
 	callInfo := rpctypes.GetCallInfo(ctx)
 	addr := callInfo.RemoteAddr()
 
-	if env.EventBus.NumClients() >= env.Config.MaxSubscriptionClients {
-		return nil, fmt.Errorf("max_subscription_clients %d reached", env.Config.MaxSubscriptionClients)
-	} else if env.EventBus.NumClientSubscriptions(addr) >= env.Config.MaxSubscriptionsPerClient {
-		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", env.Config.MaxSubscriptionsPerClient)
-	} else if len(query) > maxQueryLength {
-		return nil, errors.New("maximum query length exceeded")
-	}
-
-	env.Logger.Info("Subscribe to query", "remote", addr, "query", query)
-
-	q, err := tmquery.New(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse query: %w", err)
+	if statsInterval < 0 {
+		return nil, fmt.Errorf("stats_interval must be non-negative: %w", coretypes.ErrInvalidRequest)
 	}
-
-	subCtx, cancel := context.WithTimeout(ctx, SubscribeTimeout)
-	defer cancel()
-
-	sub, err := env.EventBus.SubscribeWithArgs(subCtx, tmpubsub.SubscribeArgs{
-		ClientID: addr,
-		Query:    q,
-		Limit:    subBufferSize,
-	})
+	sub, err := env.subscribe(ctx, addr, query, tmpubsub.SubscribeArgs{After: after})
 	if err != nil {
 		return nil, err
 	}
 
 	// Capture the current ID, since it can change in the future.
 	subscriptionID := callInfo.RPCRequest.ID
+	delivered := rpctypes.TrackDelivery(callInfo.WSConn)
 	go func() {
+		defer delivered()
 		opctx, opcancel := context.WithCancel(context.Background())
 		defer opcancel()
 
+		var (
+			stats     coretypes.SubscriptionStats
+			nextStats time.Time
+		)
+		sendStats := func() {
+			stats.Pending = int64(sub.Pending())
+			stats.LatestHeight = env.latestHeight()
+			stats.Lag = 0
+			if stats.Pending > 0 && stats.LastHeight > 0 && stats.LatestHeight > stats.LastHeight {
+				stats.Lag = stats.LatestHeight - stats.LastHeight
+			}
+			frame := stats
+			resp := rpctypes.NewRPCSuccessResponse(subscriptionID, &coretypes.ResultEvent{
+				Query: query,
+				Stats: &frame,
+			})
+			wctx, cancel := context.WithTimeout(opctx, 10*time.Second)
+			defer cancel()
+			if err := callInfo.WSConn.WriteRPCResponse(wctx, resp); err != nil {
+				env.Logger.Info("Unable to write stats (slow client)",
+					"to", addr, "subscriptionID", subscriptionID, "err", err)
+			}
+		}
+		if statsInterval > 0 {
+			nextStats = time.Now().Add(time.Duration(statsInterval) * time.Second)
+		}
+
 		for {
-			msg, err := sub.Next(opctx)
-			if errors.Is(err, tmpubsub.ErrUnsubscribed) {
+			msg, err := waitEvent(opctx, sub, nextStats)
+			if errors.Is(err, context.DeadlineExceeded) && opctx.Err() == nil {
+				// No event before the next stats frame is due.
+				sendStats()
+				nextStats = time.Now().Add(time.Duration(statsInterval) * time.Second)
+				continue
+			} else if errors.Is(err, tmpubsub.ErrUnsubscribed) {
 				// The subscription was removed by the client.
 				return
 			} else if errors.Is(err, tmpubsub.ErrTerminated) {
 				// The subscription was terminated by the publisher.
+				if !nextStats.IsZero() {
+					sendStats()
+				}
 				resp := rpctypes.RPCServerError(subscriptionID, err)
 				ok := callInfo.WSConn.TryWriteRPCResponse(opctx, resp)
 				if !ok {
@@ -79,6 +109,7 @@ func (env *Environment) Subscribe(ctx context.Context, query string) (*coretypes
 			// We have a message to deliver to the client.
 			resp := rpctypes.NewRPCSuccessResponse(subscriptionID, &coretypes.ResultEvent{
 				Query:  query,
+				Seq:    msg.Seq(),
 				Data:   msg.Data(),
 				Events: msg.Events(),
 			})
@@ -88,6 +119,18 @@ func (env *Environment) Subscribe(ctx context.Context, query string) (*coretypes
 			if err != nil {
 				env.Logger.Info("Unable to write response (slow client)",
 					"to", addr, "subscriptionID", subscriptionID, "err", err)
+				stats.Dropped++
+			} else {
+				stats.Delivered++
+				stats.LastSeq = msg.Seq()
+				if height, ok := eventHeight(msg.Data()); ok {
+					stats.LastHeight = height
+				}
+			}
+			if !nextStats.IsZero() && !time.Now().Before(nextStats) {
+				// Events keep coming: the stats frame is sent between them.
+				sendStats()
+				nextStats = time.Now().Add(time.Duration(statsInterval) * time.Second)
 			}
 		}
 	}()
@@ -95,6 +138,88 @@ func (env *Environment) Subscribe(ctx context.Context, query string) (*coretypes
 	return &coretypes.ResultSubscribe{}, nil
 }
 
+// waitEvent waits for the next event of sub, until deadline unless it is
+// zero.
+func waitEvent(ctx context.Context, sub eventbus.Subscription, deadline time.Time) (tmpubsub.Message, error) {
+	if deadline.IsZero() {
+		return sub.Next(ctx)
+	}
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	return sub.Next(ctx)
+}
+
+// latestHeight returns the height of the latest block of the node, or zero if
+// the node has no block store.
+func (env *Environment) latestHeight() int64 {
+	if env.BlockStore == nil {
+		return 0
+	}
+	return env.BlockStore.Height()
+}
+
+// eventHeight returns the height of the block an event was published for,
+// and reports whether the event has one.
+func eventHeight(data interface{}) (int64, bool) {
+	switch evt := data.(type) {
+	case types.EventDataNewBlock:
+		if evt.Block == nil {
+			return 0, false
+		}
+		return evt.Block.Height, true
+	case types.EventDataNewBlockHeader:
+		return evt.Header.Height, true
+	case types.EventDataTx:
+		return evt.Height, true
+	case types.EventDataNewEvidence:
+		return evt.Height, true
+	case types.EventDataRoundState:
+		return evt.Height, true
+	case types.EventDataNewRound:
+		return evt.Height, true
+	case types.EventDataCompleteProposal:
+		return evt.Height, true
+	case types.EventDataVote:
+		if evt.Vote == nil {
+			return 0, false
+		}
+		return evt.Vote.Height, true
+	default:
+		return 0, false
+	}
+}
+
+// subscribe registers a subscription of the client at addr to query,
+// enforcing the subscription limits. The other arguments of the subscription,
+// e.g. the sequence number to resume after, are taken from args; its buffer
+// defaults to subBufferSize.
+func (env *Environment) subscribe(ctx context.Context, addr, query string, args tmpubsub.SubscribeArgs) (eventbus.Subscription, error) {
+	if env.EventBus.NumClients() >= env.Config.MaxSubscriptionClients {
+		return nil, fmt.Errorf("max_subscription_clients %d reached", env.Config.MaxSubscriptionClients)
+	} else if env.EventBus.NumClientSubscriptions(addr) >= env.Config.MaxSubscriptionsPerClient {
+		return nil, fmt.Errorf("max_subscriptions_per_client %d reached", env.Config.MaxSubscriptionsPerClient)
+	} else if len(query) > maxQueryLength {
+		return nil, errors.New("maximum query length exceeded")
+	}
+
+	env.Logger.Info("Subscribe to query", "remote", addr, "query", query)
+
+	q, err := tmquery.New(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, SubscribeTimeout)
+	defer cancel()
+
+	args.ClientID = addr
+	args.Query = q
+	if args.Limit == 0 {
+		args.Limit = subBufferSize
+	}
+	return env.EventBus.SubscribeWithArgs(subCtx, args)
+}
+
 // Unsubscribe from events via WebSocket.
 // More: https://docs.tendermint.com/master/rpc/#/Websocket/unsubscribe
 func (env *Environment) Unsubscribe(ctx context.Context, query string) (*coretypes.ResultUnsubscribe, error) {
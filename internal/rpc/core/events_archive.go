@@ -0,0 +1,223 @@
+package core
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+)
+
+// eventArchivePath is the path of the HTTP endpoint streaming the event
+// archive. It is not a JSON-RPC method, since its response is a stream of
+// NDJSON lines.
+const eventArchivePath = "/events_archive"
+
+// Sources of the events of the archive.
+const (
+	eventSourceBeginBlock = "begin_block"
+	eventSourceTx         = "tx"
+	eventSourceEndBlock   = "end_block"
+)
+
+// archivedEvent is a line of the event archive.
+type archivedEvent struct {
+	// Offset is the position of the event in the archive, as
+	// "<height>:<index of the event in the height>".
+	Offset  string         `json:"offset"`
+	Height  int64          `json:"height,string"`
+	Source  string         `json:"source"`
+	TxIndex *int           `json:"tx_index,omitempty"`
+	TxHash  bytes.HexBytes `json:"tx_hash,omitempty"`
+	Event   abci.Event     `json:"event"`
+}
+
+// eventArchiveTrailer is the last line of the event archive. NextOffset is
+// the offset to resume the download from, if it isn't done.
+type eventArchiveTrailer struct {
+	Done       bool   `json:"done"`
+	NextOffset string `json:"next_offset"`
+	Error      string `json:"error,omitempty"`
+}
+
+// eventArchiveHandler returns the handler of the event archive. The archive
+// ends within budget, unless it is 0, for the response to complete before the
+// write timeout of the server.
+//
+// The archive holds the events of the blocks from_height to to_height (the
+// base and the last height by default), with the events of their
+// transactions, one JSON object per line, compressed with gzip if the client
+// accepts it. A download is resumed from the offset of the next event: the
+// next_offset of the trailer, or the offset of the last event received with
+// its index incremented, given as the offset parameter.
+func (env *Environment) eventArchiveHandler(budget time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		q := r.URL.Query()
+		height, index, toHeight, err := env.eventArchiveRange(q.Get("from_height"), q.Get("to_height"), q.Get("offset"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var out io.Writer = w
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Add("Vary", "Accept-Encoding")
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+
+		var deadline time.Time
+		if budget > 0 {
+			deadline = time.Now().Add(budget)
+		}
+		enc := json.NewEncoder(out)
+		trailer, err := env.writeEventArchive(r.Context(), enc, height, index, toHeight, deadline)
+		if err != nil {
+			env.Logger.Debug("event archive download interrupted", "remote", r.RemoteAddr, "err", err)
+			return
+		}
+		_ = enc.Encode(trailer)
+	}
+}
+
+// eventArchiveRange parses the range of the archive, returning the height and
+// index of its first event, and its last height.
+func (env *Environment) eventArchiveRange(from, to, offset string) (height int64, index int, toHeight int64, err error) {
+	base, last := env.BlockStore.Base(), env.BlockStore.Height()
+	fromHeight := base
+	if from != "" {
+		if fromHeight, err = strconv.ParseInt(from, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid from_height %q", from)
+		}
+	}
+	toHeight = last
+	if to != "" {
+		if toHeight, err = strconv.ParseInt(to, 10, 64); err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid to_height %q", to)
+		}
+	}
+	switch {
+	case fromHeight <= 0:
+		return 0, 0, 0, fmt.Errorf("%w (from_height: %d)", coretypes.ErrZeroOrNegativeHeight, fromHeight)
+	case fromHeight < base:
+		return 0, 0, 0, fmt.Errorf("%w (from_height: %d, base height: %d)", coretypes.ErrHeightNotAvailable, fromHeight, base)
+	case toHeight > last:
+		return 0, 0, 0, fmt.Errorf("%w (to_height: %d, blockchain height: %d)",
+			coretypes.ErrHeightExceedsChainHead, toHeight, last)
+	case fromHeight > toHeight:
+		return 0, 0, 0, fmt.Errorf("from_height %d is greater than to_height %d", fromHeight, toHeight)
+	}
+
+	height = fromHeight
+	if offset != "" {
+		if height, index, err = parseEventArchiveOffset(offset); err != nil {
+			return 0, 0, 0, err
+		}
+		// The trailer of a complete archive gives the height after it.
+		if height < fromHeight || height > toHeight+1 {
+			return 0, 0, 0, fmt.Errorf("offset %q is out of the range of heights %d to %d", offset, fromHeight, toHeight)
+		}
+	}
+	return height, index, toHeight, nil
+}
+
+func eventArchiveOffset(height int64, index int) string {
+	return fmt.Sprintf("%d:%d", height, index)
+}
+
+func parseEventArchiveOffset(offset string) (height int64, index int, err error) {
+	parts := strings.SplitN(offset, ":", 2)
+	if len(parts) == 2 {
+		height, err = strconv.ParseInt(parts[0], 10, 64)
+		if err == nil {
+			index, err = strconv.Atoi(parts[1])
+		}
+	}
+	if len(parts) != 2 || err != nil || index < 0 {
+		return 0, 0, fmt.Errorf("invalid offset %q, must have the form <height>:<index>", offset)
+	}
+	return height, index, nil
+}
+
+// writeEventArchive writes the events from the index-th event of height up to
+// the events of toHeight, and returns the trailer of the archive. The archive
+// ends early at deadline, unless it is zero, or at the first height whose
+// events can't be loaded. It returns an error if the events can't be written.
+func (env *Environment) writeEventArchive(
+	ctx context.Context,
+	enc *json.Encoder,
+	height int64,
+	index int,
+	toHeight int64,
+	deadline time.Time,
+) (eventArchiveTrailer, error) {
+	expired := func() bool { return !deadline.IsZero() && time.Now().After(deadline) }
+	for ; height <= toHeight; height, index = height+1, 0 {
+		if expired() {
+			return eventArchiveTrailer{NextOffset: eventArchiveOffset(height, index)}, nil
+		}
+		results, err := env.StateStore.LoadABCIResponses(height)
+		if err != nil {
+			return eventArchiveTrailer{
+				NextOffset: eventArchiveOffset(height, index),
+				Error:      fmt.Sprintf("loading the events of height %d: %v", height, err),
+			}, nil
+		}
+		var txHashes []bytes.HexBytes
+		if block := env.BlockStore.LoadBlock(height); block != nil {
+			for _, tx := range block.Txs {
+				txHashes = append(txHashes, tx.Hash())
+			}
+		}
+
+		events := make([]archivedEvent, 0)
+		add := func(source string, txIndex *int, evs []abci.Event) {
+			for _, ev := range evs {
+				e := archivedEvent{Height: height, Source: source, TxIndex: txIndex, Event: ev}
+				if txIndex != nil && *txIndex < len(txHashes) {
+					e.TxHash = txHashes[*txIndex]
+				}
+				events = append(events, e)
+			}
+		}
+		if results.BeginBlock != nil {
+			add(eventSourceBeginBlock, nil, results.BeginBlock.Events)
+		}
+		for i, tx := range results.DeliverTxs {
+			txIndex := i
+			add(eventSourceTx, &txIndex, tx.GetEvents())
+		}
+		if results.EndBlock != nil {
+			add(eventSourceEndBlock, nil, results.EndBlock.Events)
+		}
+
+		for ; index < len(events); index++ {
+			if err := ctx.Err(); err != nil {
+				return eventArchiveTrailer{}, err
+			}
+			if expired() {
+				return eventArchiveTrailer{NextOffset: eventArchiveOffset(height, index)}, nil
+			}
+			events[index].Offset = eventArchiveOffset(height, index)
+			if err := enc.Encode(events[index]); err != nil {
+				return eventArchiveTrailer{}, err
+			}
+		}
+	}
+	return eventArchiveTrailer{Done: true, NextOffset: eventArchiveOffset(height, 0)}, nil
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/libs/log"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestEventArchive(t *testing.T) {
+	event := func(typ string) abci.Event {
+		return abci.Event{Type: typ, Attributes: []abci.EventAttribute{{Key: "k", Value: "v", Index: true}}}
+	}
+	env := &Environment{Logger: log.NewNopLogger()}
+	env.StateStore = sm.NewStore(dbm.NewMemDB())
+	require.NoError(t, env.StateStore.SaveABCIResponses(1, &tmstate.ABCIResponses{
+		BeginBlock: &abci.ResponseBeginBlock{Events: []abci.Event{event("begin")}},
+		DeliverTxs: []*abci.ResponseDeliverTx{{Events: []abci.Event{event("transfer"), event("fee")}}},
+		EndBlock:   &abci.ResponseEndBlock{Events: []abci.Event{event("end")}},
+	}))
+	require.NoError(t, env.StateStore.SaveABCIResponses(2, &tmstate.ABCIResponses{
+		BeginBlock: &abci.ResponseBeginBlock{},
+		EndBlock:   &abci.ResponseEndBlock{},
+	}))
+	require.NoError(t, env.StateStore.SaveABCIResponses(3, &tmstate.ABCIResponses{
+		BeginBlock: &abci.ResponseBeginBlock{Events: []abci.Event{event("begin")}},
+		EndBlock:   &abci.ResponseEndBlock{},
+	}))
+	tx := types.Tx("tx")
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Base").Return(int64(1))
+	blockStore.On("Height").Return(int64(4))
+	blockStore.On("LoadBlock", int64(1)).Return(&types.Block{Data: types.Data{Txs: types.Txs{tx}}})
+	blockStore.On("LoadBlock", mock.Anything).Return(nil)
+	env.BlockStore = blockStore
+
+	download := func(query string, gzipped bool) (int, []map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, eventArchivePath+"?"+query, nil)
+		if gzipped {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		rec := httptest.NewRecorder()
+		env.eventArchiveHandler(0)(rec, req)
+		if rec.Code != http.StatusOK {
+			return rec.Code, nil
+		}
+
+		var body io.Reader = rec.Body
+		if gzipped {
+			require.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+			gz, err := gzip.NewReader(rec.Body)
+			require.NoError(t, err)
+			body = gz
+		}
+		var lines []map[string]interface{}
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			var line map[string]interface{}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+			lines = append(lines, line)
+		}
+		require.NoError(t, scanner.Err())
+		return rec.Code, lines
+	}
+
+	code, lines := download("from_height=1&to_height=3", true)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, lines, 6)
+	assert.Equal(t, []interface{}{"1:0", "1:1", "1:2", "1:3", "3:0"},
+		[]interface{}{lines[0]["offset"], lines[1]["offset"], lines[2]["offset"], lines[3]["offset"], lines[4]["offset"]})
+	assert.Equal(t, "begin_block", lines[0]["source"])
+	assert.Equal(t, "tx", lines[2]["source"])
+	assert.EqualValues(t, 0, lines[2]["tx_index"])
+	assert.Equal(t, bytes.HexBytes(tx.Hash()).String(), lines[2]["tx_hash"])
+	assert.Equal(t, "fee", lines[2]["event"].(map[string]interface{})["type"])
+	assert.Equal(t, "end_block", lines[3]["source"])
+	assert.Equal(t, map[string]interface{}{"done": true, "next_offset": "4:0"}, lines[5])
+
+	// A download is resumed from an offset.
+	_, lines = download("from_height=1&to_height=3&offset=1:2", false)
+	require.Len(t, lines, 4)
+	assert.Equal(t, "1:2", lines[0]["offset"])
+
+	// The events of the heights missing from the state store end the archive.
+	_, lines = download("offset=3:0", false)
+	require.Len(t, lines, 2)
+	assert.Equal(t, false, lines[1]["done"])
+	assert.Equal(t, "4:0", lines[1]["next_offset"])
+	assert.Contains(t, lines[1]["error"], "height 4")
+
+	for _, query := range []string{
+		"from_height=0", "to_height=5", "from_height=3&to_height=2", "offset=6:0", "offset=1", "offset=1:-1",
+	} {
+		code, _ := download(query, false)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestEventArchiveBudget(t *testing.T) {
+	env := &Environment{Logger: log.NewNopLogger()}
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Base").Return(int64(1))
+	blockStore.On("Height").Return(int64(10))
+	env.BlockStore = blockStore
+
+	// The archive ends with the budget, to be resumed.
+	rec := httptest.NewRecorder()
+	env.eventArchiveHandler(1)(rec, httptest.NewRequest(http.MethodGet, eventArchivePath+"?offset=5:3", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"done": false, "next_offset": "5:3"}`, rec.Body.String())
+}
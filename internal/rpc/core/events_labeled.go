@@ -0,0 +1,289 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/tendermint/tendermint/internal/eventbus"
+	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+// maxLabelLength is the maximum length of a subscription label.
+const maxLabelLength = 64
+
+// labeledSubscription is a subscription opened with SubscribeLabeled. Events
+// are only written to the client while it has credits left, unless it was
+// opened without credits.
+type labeledSubscription struct {
+	label string
+	query string
+	sub   eventbus.Subscription
+
+	mtx       sync.Mutex
+	unlimited bool
+	credits   int64
+	granted   chan struct{} // signaled when credits are granted
+}
+
+// acquire blocks until a credit is available and consumes it.
+func (ls *labeledSubscription) acquire(ctx context.Context) error {
+	for {
+		ls.mtx.Lock()
+		if ls.unlimited || ls.credits > 0 {
+			if !ls.unlimited {
+				ls.credits--
+			}
+			ls.mtx.Unlock()
+			return nil
+		}
+		ls.mtx.Unlock()
+
+		select {
+		case <-ls.granted:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// grant adds n credits and returns the credits now available.
+func (ls *labeledSubscription) grant(n int64) int64 {
+	ls.mtx.Lock()
+	defer ls.mtx.Unlock()
+
+	ls.credits += n
+	select {
+	case ls.granted <- struct{}{}:
+	default:
+	}
+	return ls.credits
+}
+
+// labeledSubscriptions tracks the labeled subscriptions of each client.
+type labeledSubscriptions struct {
+	mtx  sync.Mutex
+	subs map[string]map[string]*labeledSubscription // remote address -> label
+}
+
+func (l *labeledSubscriptions) get(addr, label string) (*labeledSubscription, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	ls := l.subs[addr][label]
+	return ls, ls != nil
+}
+
+// reserve records a placeholder for the label while the subscription is
+// being set up, failing if the label is in use.
+func (l *labeledSubscriptions) reserve(addr, label string) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if _, ok := l.subs[addr][label]; ok {
+		return fmt.Errorf("subscription label %q is already in use", label)
+	}
+	l.set(addr, label, nil)
+	return nil
+}
+
+// activate replaces the placeholder of ls.label with ls.
+func (l *labeledSubscriptions) activate(addr string, ls *labeledSubscription) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.set(addr, ls.label, ls)
+}
+
+func (l *labeledSubscriptions) set(addr, label string, ls *labeledSubscription) {
+	if l.subs == nil {
+		l.subs = make(map[string]map[string]*labeledSubscription)
+	}
+	if l.subs[addr] == nil {
+		l.subs[addr] = make(map[string]*labeledSubscription)
+	}
+	l.subs[addr][label] = ls
+}
+
+// remove removes the label, unless it is no longer held by ls.
+func (l *labeledSubscriptions) remove(addr, label string, ls *labeledSubscription) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if cur, ok := l.subs[addr][label]; !ok || cur != ls {
+		return
+	}
+	delete(l.subs[addr], label)
+	if len(l.subs[addr]) == 0 {
+		delete(l.subs, addr)
+	}
+}
+
+// SubscribeLabeled opens a subscription to query identified by label on the
+// calling websocket connection. Events are delivered as ResultLabeledEvent
+// frames carrying the label and a per-label cursor, so that a client can
+// multiplex many subscriptions over one connection.
+//
+// Each frame consumes one credit; once the credits are exhausted, events are
+// held back until the client grants more with GrantCredits. A subscription
+// opened with zero credits is not flow controlled.
+//
+// The events held back, or waiting for the connection to write them, are
+// buffered by the subscription, up to buffer events (0 uses the default).
+// Once its buffer is full, the subscription is paused rather than terminated,
+// and the other subscriptions of the connection are not affected: the events
+// published meanwhile are read back from the event log once the client has
+// caught up, if the node has one, and are skipped otherwise, which the next
+// frame reports. The subscription is only terminated if it falls behind the
+// retention of the event log.
+func (env *Environment) SubscribeLabeled(
+	ctx context.Context,
+	label, query string,
+	credits, buffer int64,
+) (*coretypes.ResultSubscribeLabeled, error) {
+	callInfo := rpctypes.GetCallInfo(ctx)
+	if callInfo == nil || callInfo.WSConn == nil {
+		return nil, fmt.Errorf("labeled subscriptions require a websocket connection: %w", coretypes.ErrInvalidRequest)
+	}
+	if label == "" || len(label) > maxLabelLength {
+		return nil, fmt.Errorf("label must be 1-%d characters long: %w", maxLabelLength, coretypes.ErrInvalidRequest)
+	}
+	if credits < 0 {
+		return nil, fmt.Errorf("credits can't be negative: %w", coretypes.ErrInvalidRequest)
+	}
+	if buffer < 0 || buffer > maxSubBufferSize {
+		return nil, fmt.Errorf("buffer must be 0-%d events: %w", maxSubBufferSize, coretypes.ErrInvalidRequest)
+	} else if buffer == 0 {
+		buffer = subBufferSize
+	}
+
+	addr := callInfo.RemoteAddr()
+	if err := env.labeledSubs.reserve(addr, label); err != nil {
+		return nil, fmt.Errorf("%s: %w", err, coretypes.ErrInvalidRequest)
+	}
+
+	sub, err := env.subscribe(ctx, addr, query, tmpubsub.SubscribeArgs{
+		Limit: int(buffer),
+		Pause: true,
+	})
+	if err != nil {
+		env.labeledSubs.remove(addr, label, nil)
+		return nil, err
+	}
+	ls := &labeledSubscription{
+		label:     label,
+		query:     query,
+		sub:       sub,
+		unlimited: credits == 0,
+		credits:   credits,
+		granted:   make(chan struct{}, 1),
+	}
+	env.labeledSubs.activate(addr, ls)
+
+	// Capture the current ID, since it can change in the future.
+	subscriptionID := callInfo.RPCRequest.ID
+	delivered := rpctypes.TrackDelivery(callInfo.WSConn)
+	go func() {
+		defer delivered()
+		env.streamLabeled(callInfo.WSConn, ls, func(res *coretypes.ResultLabeledEvent) rpctypes.RPCResponse {
+			return rpctypes.NewRPCSuccessResponse(subscriptionID, res)
+		})
+	}()
+
+	return &coretypes.ResultSubscribeLabeled{Label: label, Credits: credits, Buffer: buffer}, nil
+}
+
+// streamLabeled writes the events of ls to conn, framed by makeResponse,
+// until the subscription ends.
+func (env *Environment) streamLabeled(
+	conn rpctypes.WSRPCConnection,
+	ls *labeledSubscription,
+	makeResponse func(*coretypes.ResultLabeledEvent) rpctypes.RPCResponse,
+) {
+	addr := conn.GetRemoteAddr()
+	ctx := conn.Context()
+	defer env.labeledSubs.remove(addr, ls.label, ls)
+
+	var (
+		cursor  int64
+		skipped uint64 // the events skipped reported so far
+	)
+	for {
+		msg, err := ls.sub.Next(ctx)
+		if errors.Is(err, tmpubsub.ErrUnsubscribed) || ctx.Err() != nil {
+			// The subscription was removed by the client, or it went away.
+			return
+		} else if err != nil {
+			// The subscription was terminated by the publisher.
+			cursor++
+			resp := makeResponse(&coretypes.ResultLabeledEvent{
+				Label:      ls.label,
+				Cursor:     cursor,
+				Query:      ls.query,
+				Terminated: err.Error(),
+			})
+			if !conn.TryWriteRPCResponse(ctx, resp) {
+				env.Logger.Info("Unable to write response (slow client)",
+					"to", addr, "label", ls.label, "err", err)
+			}
+			return
+		}
+
+		if err := ls.acquire(ctx); err != nil {
+			return
+		}
+
+		// Report the events skipped since the previous frame, while the
+		// buffer was full.
+		total := ls.sub.Skipped()
+		cursor++
+		resp := makeResponse(&coretypes.ResultLabeledEvent{
+			Label:   ls.label,
+			Cursor:  cursor,
+			Query:   ls.query,
+			Data:    msg.Data(),
+			Events:  msg.Events(),
+			Skipped: total - skipped,
+		})
+		skipped = total
+
+		// Wait for the connection to take the frame rather than dropping it:
+		// the events published meanwhile are buffered by the subscription,
+		// which is paused if it fills up.
+		if err := conn.WriteRPCResponse(ctx, resp); err != nil {
+			return
+		}
+	}
+}
+
+// GrantCredits grants additional credits to the labeled subscription.
+func (env *Environment) GrantCredits(ctx context.Context, label string, credits int64) (*coretypes.ResultGrantCredits, error) {
+	if credits <= 0 {
+		return nil, fmt.Errorf("credits must be positive: %w", coretypes.ErrInvalidRequest)
+	}
+	ls, ok := env.labeledSubs.get(rpctypes.GetCallInfo(ctx).RemoteAddr(), label)
+	if !ok {
+		return nil, fmt.Errorf("no subscription labeled %q: %w", label, coretypes.ErrInvalidRequest)
+	}
+	return &coretypes.ResultGrantCredits{Label: label, Credits: ls.grant(credits)}, nil
+}
+
+// UnsubscribeLabeled closes the labeled subscription.
+func (env *Environment) UnsubscribeLabeled(ctx context.Context, label string) (*coretypes.ResultUnsubscribe, error) {
+	addr := rpctypes.GetCallInfo(ctx).RemoteAddr()
+	ls, ok := env.labeledSubs.get(addr, label)
+	if !ok {
+		return nil, fmt.Errorf("no subscription labeled %q: %w", label, coretypes.ErrInvalidRequest)
+	}
+
+	env.Logger.Info("Unsubscribe from labeled subscription", "remote", addr, "label", label)
+	err := env.EventBus.Unsubscribe(ctx, tmpubsub.UnsubscribeArgs{Subscriber: addr, ID: ls.sub.ID()})
+	if err != nil {
+		return nil, err
+	}
+	env.labeledSubs.remove(addr, label, ls)
+	return &coretypes.ResultUnsubscribe{}, nil
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+type testWSConn struct {
+	ctx       context.Context
+	responses chan rpctypes.RPCResponse
+}
+
+func (c *testWSConn) GetRemoteAddr() string { return "test-client" }
+
+func (c *testWSConn) WriteRPCResponse(ctx context.Context, resp rpctypes.RPCResponse) error {
+	select {
+	case c.responses <- resp:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *testWSConn) TryWriteRPCResponse(_ context.Context, resp rpctypes.RPCResponse) bool {
+	select {
+	case c.responses <- resp:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *testWSConn) Context() context.Context { return c.ctx }
+
+func (c *testWSConn) next(t *testing.T) *coretypes.ResultLabeledEvent {
+	t.Helper()
+	select {
+	case resp := <-c.responses:
+		require.Nil(t, resp.Error)
+		ev := new(coretypes.ResultLabeledEvent)
+		require.NoError(t, json.Unmarshal(resp.Result, ev))
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func (c *testWSConn) requireNone(t *testing.T) {
+	t.Helper()
+	select {
+	case resp := <-c.responses:
+		t.Fatalf("unexpected response %s", resp.Result)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLabeledSubscriptions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.NewDefault(log.TestingLogger())
+	require.NoError(t, eventBus.Start(ctx))
+
+	env := &Environment{
+		EventBus: eventBus,
+		Logger:   log.TestingLogger(),
+		Config:   *config.TestRPCConfig(),
+	}
+
+	conn := &testWSConn{ctx: ctx, responses: make(chan rpctypes.RPCResponse, 10)}
+	callCtx := rpctypes.WithCallInfo(ctx, &rpctypes.CallInfo{
+		RPCRequest: &rpctypes.RPCRequest{ID: rpctypes.JSONRPCIntID(1)},
+		WSConn:     conn,
+	})
+
+	_, err := env.SubscribeLabeled(callCtx, "headers", "tm.event = 'NewBlockHeader'", 1, 0)
+	require.NoError(t, err)
+	_, err = env.SubscribeLabeled(callCtx, "txs", "tm.event = 'Tx'", 0, 0)
+	require.NoError(t, err)
+
+	// labels are unique per connection
+	_, err = env.SubscribeLabeled(callCtx, "txs", "tm.event = 'Tx'", 0, 0)
+	require.ErrorIs(t, err, coretypes.ErrInvalidRequest)
+
+	publishHeader := func(height int64) {
+		require.NoError(t, eventBus.PublishEventNewBlockHeader(ctx, types.EventDataNewBlockHeader{
+			Header: types.Header{Height: height},
+		}))
+	}
+
+	publishHeader(1)
+	ev := conn.next(t)
+	require.Equal(t, "headers", ev.Label)
+	require.EqualValues(t, 1, ev.Cursor)
+	require.EqualValues(t, 1, ev.Data.(types.EventDataNewBlockHeader).Header.Height)
+
+	// the single credit is used up, so the next header is held back
+	publishHeader(2)
+	conn.requireNone(t)
+
+	// subscriptions without credits are not flow controlled
+	require.NoError(t, eventBus.PublishEventTx(ctx, types.EventDataTx{}))
+	ev = conn.next(t)
+	require.Equal(t, "txs", ev.Label)
+	require.EqualValues(t, 1, ev.Cursor)
+
+	res, err := env.GrantCredits(callCtx, "headers", 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, res.Credits)
+	ev = conn.next(t)
+	require.Equal(t, "headers", ev.Label)
+	require.EqualValues(t, 2, ev.Cursor)
+	require.EqualValues(t, 2, ev.Data.(types.EventDataNewBlockHeader).Header.Height)
+
+	_, err = env.UnsubscribeLabeled(callCtx, "headers")
+	require.NoError(t, err)
+	publishHeader(3)
+	conn.requireNone(t)
+
+	_, err = env.GrantCredits(callCtx, "headers", 1)
+	require.ErrorIs(t, err, coretypes.ErrInvalidRequest)
+
+	// the label can be reused once released
+	_, err = env.SubscribeLabeled(callCtx, "headers", "tm.event = 'NewBlockHeader'", 0, 0)
+	require.NoError(t, err)
+}
+
+func TestLabeledSubscriptionPaused(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.NewDefault(log.TestingLogger())
+	require.NoError(t, eventBus.Start(ctx))
+
+	env := &Environment{
+		EventBus: eventBus,
+		Logger:   log.TestingLogger(),
+		Config:   *config.TestRPCConfig(),
+	}
+
+	conn := &testWSConn{ctx: ctx, responses: make(chan rpctypes.RPCResponse, 10)}
+	callCtx := rpctypes.WithCallInfo(ctx, &rpctypes.CallInfo{
+		RPCRequest: &rpctypes.RPCRequest{ID: rpctypes.JSONRPCIntID(1)},
+		WSConn:     conn,
+	})
+
+	_, err := env.SubscribeLabeled(callCtx, "headers", "tm.event = 'NewBlockHeader'", 1, maxSubBufferSize+1)
+	require.ErrorIs(t, err, coretypes.ErrInvalidRequest)
+	res, err := env.SubscribeLabeled(callCtx, "headers", "tm.event = 'NewBlockHeader'", 1, 2)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, res.Buffer)
+	_, err = env.SubscribeLabeled(callCtx, "txs", "tm.event = 'Tx'", 0, 0)
+	require.NoError(t, err)
+
+	publishHeader := func(height int64) {
+		require.NoError(t, eventBus.PublishEventNewBlockHeader(ctx, types.EventDataNewBlockHeader{
+			Header: types.Header{Height: height},
+		}))
+	}
+	publishHeader(1)
+	require.EqualValues(t, 1, conn.next(t).Cursor)
+
+	// The headers overflow the buffer of the subscription, out of credits,
+	// which doesn't hold back the other subscription. The events are
+	// delivered in order, so the headers were all published once the
+	// transaction is received.
+	for h := int64(2); h <= 5; h++ {
+		publishHeader(h)
+	}
+	require.NoError(t, eventBus.PublishEventTx(ctx, types.EventDataTx{}))
+	ev := conn.next(t)
+	require.Equal(t, "txs", ev.Label)
+
+	// Once resumed, the subscription delivers the headers it buffered and
+	// reports those it skipped.
+	_, err = env.GrantCredits(callCtx, "headers", 10)
+	require.NoError(t, err)
+	var (
+		delivered int
+		skipped   uint64
+		cursor    int64 = 1
+	)
+	for done := false; !done; {
+		select {
+		case resp := <-conn.responses:
+			ev := new(coretypes.ResultLabeledEvent)
+			require.NoError(t, json.Unmarshal(resp.Result, ev))
+			require.Equal(t, "headers", ev.Label)
+			require.Empty(t, ev.Terminated)
+			cursor++
+			require.Equal(t, cursor, ev.Cursor)
+			skipped += ev.Skipped
+			delivered++
+		case <-time.After(200 * time.Millisecond):
+			done = true
+		}
+	}
+	require.NotZero(t, skipped)
+	require.EqualValues(t, 4, delivered+int(skipped))
+
+	// The subscription carries on.
+	publishHeader(6)
+	ev = conn.next(t)
+	require.Equal(t, cursor+1, ev.Cursor)
+	require.EqualValues(t, 6, ev.Data.(types.EventDataNewBlockHeader).Header.Height)
+}
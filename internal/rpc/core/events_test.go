@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/eventbus"
+	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+func nextEvent(t *testing.T, conn *testWSConn) *coretypes.ResultEvent {
+	t.Helper()
+	select {
+	case resp := <-conn.responses:
+		require.Nil(t, resp.Error)
+		ev := new(coretypes.ResultEvent)
+		require.NoError(t, json.Unmarshal(resp.Result, ev))
+		return ev
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+		return nil
+	}
+}
+
+func TestSubscribeResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.New(log.TestingLogger(), tmpubsub.EventLog(time.Minute, 0))
+	require.NoError(t, eventBus.Start(ctx))
+
+	env := &Environment{
+		EventBus: eventBus,
+		Logger:   log.TestingLogger(),
+		Config:   *config.TestRPCConfig(),
+	}
+	connect := func() (*testWSConn, context.Context) {
+		conn := &testWSConn{ctx: ctx, responses: make(chan rpctypes.RPCResponse, 10)}
+		return conn, rpctypes.WithCallInfo(ctx, &rpctypes.CallInfo{
+			RPCRequest: &rpctypes.RPCRequest{ID: rpctypes.JSONRPCIntID(1)},
+			WSConn:     conn,
+		})
+	}
+	publishHeader := func(height int64) {
+		require.NoError(t, eventBus.PublishEventNewBlockHeader(ctx, types.EventDataNewBlockHeader{
+			Header: types.Header{Height: height},
+		}))
+	}
+	const query = "tm.event = 'NewBlockHeader'"
+
+	conn, callCtx := connect()
+	_, err := env.Subscribe(callCtx, query, 0, 0)
+	require.NoError(t, err)
+	publishHeader(1)
+	last := nextEvent(t, conn)
+	require.NotZero(t, last.Seq)
+
+	// The client is disconnected while headers 2 and 3 are published.
+	_, err = env.UnsubscribeAll(callCtx)
+	require.NoError(t, err)
+	publishHeader(2)
+	publishHeader(3)
+
+	conn, callCtx = connect()
+	_, err = env.Subscribe(callCtx, query, last.Seq, 0)
+	require.NoError(t, err)
+	for _, height := range []int64{2, 3} {
+		ev := nextEvent(t, conn)
+		require.Equal(t, height, ev.Data.(types.EventDataNewBlockHeader).Header.Height)
+		require.Greater(t, ev.Seq, last.Seq)
+		last = ev
+	}
+	publishHeader(4)
+	ev := nextEvent(t, conn)
+	require.EqualValues(t, 4, ev.Data.(types.EventDataNewBlockHeader).Header.Height)
+
+	// The events after a cursor from before the event log are not retained.
+	_, err = env.UnsubscribeAll(callCtx)
+	require.NoError(t, err)
+	_, err = env.Subscribe(callCtx, query, 1, 0)
+	require.ErrorIs(t, err, tmpubsub.ErrCannotResume)
+}
+
+func TestSubscribeStats(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.New(log.TestingLogger(), tmpubsub.EventLog(time.Minute, 0))
+	require.NoError(t, eventBus.Start(ctx))
+
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Height").Return(int64(10))
+	env := &Environment{
+		EventBus:   eventBus,
+		BlockStore: blockStore,
+		Logger:     log.TestingLogger(),
+		Config:     *config.TestRPCConfig(),
+	}
+	conn := &testWSConn{ctx: ctx, responses: make(chan rpctypes.RPCResponse, 10)}
+	callCtx := rpctypes.WithCallInfo(ctx, &rpctypes.CallInfo{
+		RPCRequest: &rpctypes.RPCRequest{ID: rpctypes.JSONRPCIntID(1)},
+		WSConn:     conn,
+	})
+	const query = "tm.event = 'NewBlockHeader'"
+
+	_, err := env.Subscribe(callCtx, query, 0, -1)
+	require.ErrorIs(t, err, coretypes.ErrInvalidRequest)
+
+	_, err = env.Subscribe(callCtx, query, 0, 1)
+	require.NoError(t, err)
+	require.NoError(t, eventBus.PublishEventNewBlockHeader(ctx, types.EventDataNewBlockHeader{
+		Header: types.Header{Height: 7},
+	}))
+	ev := nextEvent(t, conn)
+	require.Nil(t, ev.Stats)
+
+	// Without further events, the stats are reported after the interval.
+	frame := nextEvent(t, conn)
+	require.Nil(t, frame.Data)
+	require.Equal(t, &coretypes.SubscriptionStats{
+		Delivered:    1,
+		LastSeq:      ev.Seq,
+		LastHeight:   7,
+		LatestHeight: 10,
+	}, frame.Stats)
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/tendermint/tendermint/rpc/coretypes"
+)
+
+// UnsafeAppHashMismatch returns the forensic bundle captured on the app hash
+// mismatch of the block at height, or of the highest height if height is
+// nil, with the heights of all the captured bundles.
+// UNSTABLE
+func (env *Environment) UnsafeAppHashMismatch(ctx context.Context, heightPtr *int64) (*coretypes.ResultAppHashMismatch, error) {
+	if env.Forensics == nil {
+		return nil, errors.New("the forensics are disabled on this node")
+	}
+
+	heights, err := env.Forensics.Heights()
+	if err != nil {
+		return nil, err
+	}
+	var height int64
+	if heightPtr != nil {
+		height = *heightPtr
+		if height <= 0 {
+			return nil, coretypes.ErrZeroOrNegativeHeight
+		}
+	}
+	bundle, err := env.Forensics.LoadBundle(height)
+	if err != nil {
+		return nil, err
+	}
+	bz, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultAppHashMismatch{Heights: heights, Bundle: bz}, nil
+}
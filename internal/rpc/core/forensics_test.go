@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestUnsafeAppHashMismatch(t *testing.T) {
+	ctx := context.Background()
+	env := &Environment{}
+	_, err := env.UnsafeAppHashMismatch(ctx, nil)
+	require.Error(t, err)
+
+	forensics := sm.NewForensics(t.TempDir(), nil, nil, log.NewNopLogger())
+	env.Forensics = forensics
+	_, err = env.UnsafeAppHashMismatch(ctx, nil)
+	require.ErrorIs(t, err, sm.ErrNoForensicBundle)
+
+	// Without a block before the initial height, the bundle only has the
+	// state and the hashes.
+	state := sm.State{ChainID: "test", InitialHeight: 5, LastBlockHeight: 4}
+	_, err = forensics.CaptureAppHashMismatch(sm.AppHashMismatchReplay, state, 4, []byte{1}, []byte{2})
+	require.NoError(t, err)
+
+	res, err := env.UnsafeAppHashMismatch(ctx, nil)
+	require.NoError(t, err)
+	require.Equal(t, []int64{4}, res.Heights)
+	var bundle sm.AppHashMismatchBundle
+	require.NoError(t, json.Unmarshal(res.Bundle, &bundle))
+	require.EqualValues(t, 4, bundle.Height)
+	require.EqualValues(t, []byte{1}, bundle.AppHash)
+	require.EqualValues(t, []byte{2}, bundle.ExpectedAppHash)
+	require.Equal(t, "test", bundle.State.ChainID)
+	require.Len(t, bundle.Errors, 1)
+
+	height := int64(3)
+	_, err = env.UnsafeAppHashMismatch(ctx, &height)
+	require.ErrorIs(t, err, sm.ErrNoForensicBundle)
+}
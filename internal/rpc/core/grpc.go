@@ -0,0 +1,629 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/internal/jsontypes"
+	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+// GRPCServer serves the methods of an Environment as the CoreAPI gRPC
+// service. Each method behaves as the JSON-RPC method of the same name; the
+// results are converted to their protobuf form.
+type GRPCServer struct {
+	env *Environment
+}
+
+// NewGRPCServer returns a CoreAPI server for env.
+func NewGRPCServer(env *Environment) *GRPCServer {
+	return &GRPCServer{env: env}
+}
+
+var _ rpcproto.CoreAPIServer = (*GRPCServer)(nil)
+
+// Register registers the CoreAPI service of s with srv.
+func (s *GRPCServer) Register(srv *grpc.Server) {
+	rpcproto.RegisterCoreAPIServer(srv, s)
+}
+
+func (s *GRPCServer) Health(ctx context.Context, req *rpcproto.HealthRequest) (*rpcproto.HealthResponse, error) {
+	if _, err := s.env.Health(ctx); err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.HealthResponse{}, nil
+}
+
+func (s *GRPCServer) Status(ctx context.Context, req *rpcproto.StatusRequest) (*rpcproto.StatusResponse, error) {
+	res, err := s.env.Status(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	info := res.SyncInfo
+	rsp := &rpcproto.StatusResponse{
+		NodeInfo: *res.NodeInfo.ToProto(),
+		SyncInfo: rpcproto.SyncInfo{
+			LatestBlockHash:     info.LatestBlockHash,
+			LatestAppHash:       info.LatestAppHash,
+			LatestBlockHeight:   info.LatestBlockHeight,
+			LatestBlockTime:     info.LatestBlockTime,
+			EarliestBlockHash:   info.EarliestBlockHash,
+			EarliestAppHash:     info.EarliestAppHash,
+			EarliestBlockHeight: info.EarliestBlockHeight,
+			EarliestBlockTime:   info.EarliestBlockTime,
+			MaxPeerBlockHeight:  info.MaxPeerBlockHeight,
+			CatchingUp:          info.CatchingUp,
+			TotalSyncedTime:     info.TotalSyncedTime,
+			RemainingTime:       info.RemainingTime,
+		},
+		ValidatorInfo: rpcproto.ValidatorInfo{
+			Address:     res.ValidatorInfo.Address,
+			VotingPower: res.ValidatorInfo.VotingPower,
+		},
+	}
+	if res.ValidatorInfo.PubKey != nil {
+		pk, err := encoding.PubKeyToProto(res.ValidatorInfo.PubKey)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "converting pubkey: %v", err)
+		}
+		rsp.ValidatorInfo.PubKey = pk
+	}
+	return rsp, nil
+}
+
+func (s *GRPCServer) NetInfo(ctx context.Context, req *rpcproto.NetInfoRequest) (*rpcproto.NetInfoResponse, error) {
+	res, err := s.env.NetInfo(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	peers := make([]rpcproto.Peer, len(res.Peers))
+	for i, p := range res.Peers {
+		peers[i] = rpcproto.Peer{NodeId: string(p.ID), Url: p.URL}
+	}
+	return &rpcproto.NetInfoResponse{
+		Listening: res.Listening,
+		Listeners: res.Listeners,
+		NPeers:    int64(res.NPeers),
+		Peers:     peers,
+	}, nil
+}
+
+func (s *GRPCServer) BlockchainInfo(
+	ctx context.Context,
+	req *rpcproto.BlockchainInfoRequest,
+) (*rpcproto.BlockchainInfoResponse, error) {
+	res, err := s.env.BlockchainInfo(ctx, req.MinHeight, req.MaxHeight)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	metas := make([]*tmproto.BlockMeta, len(res.BlockMetas))
+	for i, meta := range res.BlockMetas {
+		metas[i] = meta.ToProto()
+	}
+	return &rpcproto.BlockchainInfoResponse{LastHeight: res.LastHeight, BlockMetas: metas}, nil
+}
+
+func (s *GRPCServer) GenesisChunked(
+	ctx context.Context,
+	req *rpcproto.GenesisChunkedRequest,
+) (*rpcproto.GenesisChunkedResponse, error) {
+	res, err := s.env.GenesisChunked(ctx, uint(req.Chunk))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(res.Data)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "decoding genesis chunk: %v", err)
+	}
+	return &rpcproto.GenesisChunkedResponse{
+		Chunk: uint32(res.ChunkNumber),
+		Total: uint32(res.TotalChunks),
+		Data:  data,
+	}, nil
+}
+
+func (s *GRPCServer) Header(ctx context.Context, req *rpcproto.HeaderRequest) (*rpcproto.HeaderResponse, error) {
+	res, err := s.env.Header(ctx, optionalHeight(req.Height))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.HeaderResponse{Header: res.Header.ToProto()}, nil
+}
+
+func (s *GRPCServer) HeaderByHash(
+	ctx context.Context,
+	req *rpcproto.HeaderByHashRequest,
+) (*rpcproto.HeaderResponse, error) {
+	res, err := s.env.HeaderByHash(ctx, req.Hash)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.HeaderResponse{Header: res.Header.ToProto()}, nil
+}
+
+func (s *GRPCServer) Block(ctx context.Context, req *rpcproto.BlockRequest) (*rpcproto.BlockResponse, error) {
+	res, err := s.env.Block(ctx, optionalHeight(req.Height))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return blockResponse(res)
+}
+
+func (s *GRPCServer) BlockByHash(
+	ctx context.Context,
+	req *rpcproto.BlockByHashRequest,
+) (*rpcproto.BlockResponse, error) {
+	res, err := s.env.BlockByHash(ctx, req.Hash)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return blockResponse(res)
+}
+
+func (s *GRPCServer) BlockResults(
+	ctx context.Context,
+	req *rpcproto.BlockResultsRequest,
+) (*rpcproto.BlockResultsResponse, error) {
+	res, err := s.env.BlockResults(ctx, optionalHeight(req.Height))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.BlockResultsResponse{
+		Height:                res.Height,
+		TxsResults:            res.TxsResults,
+		TotalGasUsed:          res.TotalGasUsed,
+		BeginBlockEvents:      res.BeginBlockEvents,
+		EndBlockEvents:        res.EndBlockEvents,
+		ValidatorUpdates:      res.ValidatorUpdates,
+		ConsensusParamUpdates: res.ConsensusParamUpdates,
+	}, nil
+}
+
+func (s *GRPCServer) Commit(ctx context.Context, req *rpcproto.CommitRequest) (*rpcproto.CommitResponse, error) {
+	res, err := s.env.Commit(ctx, optionalHeight(req.Height))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.CommitResponse{
+		SignedHeader: *res.SignedHeader.ToProto(),
+		Canonical:    res.CanonicalCommit,
+	}, nil
+}
+
+func (s *GRPCServer) Validators(
+	ctx context.Context,
+	req *rpcproto.ValidatorsRequest,
+) (*rpcproto.ValidatorsResponse, error) {
+	res, err := s.env.Validators(ctx, optionalHeight(req.Height), optionalInt(req.Page), optionalInt(req.PerPage))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	vals := make([]*tmproto.Validator, len(res.Validators))
+	for i, val := range res.Validators {
+		if vals[i], err = val.ToProto(); err != nil {
+			return nil, status.Errorf(codes.Internal, "converting validator: %v", err)
+		}
+	}
+	return &rpcproto.ValidatorsResponse{
+		BlockHeight: res.BlockHeight,
+		Validators:  vals,
+		Count:       int64(res.Count),
+		Total:       int64(res.Total),
+	}, nil
+}
+
+func (s *GRPCServer) ConsensusParams(
+	ctx context.Context,
+	req *rpcproto.ConsensusParamsRequest,
+) (*rpcproto.ConsensusParamsResponse, error) {
+	res, err := s.env.ConsensusParams(ctx, optionalHeight(req.Height))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.ConsensusParamsResponse{
+		BlockHeight:     res.BlockHeight,
+		ConsensusParams: res.ConsensusParams.ToProto(),
+	}, nil
+}
+
+func (s *GRPCServer) UnconfirmedTxs(
+	ctx context.Context,
+	req *rpcproto.UnconfirmedTxsRequest,
+) (*rpcproto.UnconfirmedTxsResponse, error) {
+	res, err := s.env.UnconfirmedTxs(ctx, optionalInt(req.Page), optionalInt(req.PerPage))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return unconfirmedTxsResponse(res), nil
+}
+
+func (s *GRPCServer) NumUnconfirmedTxs(
+	ctx context.Context,
+	req *rpcproto.NumUnconfirmedTxsRequest,
+) (*rpcproto.UnconfirmedTxsResponse, error) {
+	res, err := s.env.NumUnconfirmedTxs(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return unconfirmedTxsResponse(res), nil
+}
+
+func (s *GRPCServer) CheckTx(ctx context.Context, req *rpcproto.CheckTxRequest) (*abci.ResponseCheckTx, error) {
+	res, err := s.env.CheckTx(ctx, req.Tx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &res.ResponseCheckTx, nil
+}
+
+func (s *GRPCServer) RemoveTx(ctx context.Context, req *rpcproto.RemoveTxRequest) (*rpcproto.RemoveTxResponse, error) {
+	var key types.TxKey
+	if len(req.TxKey) != len(key) {
+		return nil, status.Errorf(codes.InvalidArgument, "tx key must be %d bytes long", len(key))
+	}
+	copy(key[:], req.TxKey)
+	if err := s.env.RemoveTx(ctx, key); err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.RemoveTxResponse{}, nil
+}
+
+func (s *GRPCServer) Tx(ctx context.Context, req *rpcproto.TxRequest) (*rpcproto.TxResponse, error) {
+	res, err := s.env.Tx(ctx, req.Hash, req.Prove)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return txResponse(res, req.Prove), nil
+}
+
+func (s *GRPCServer) TxSearch(ctx context.Context, req *rpcproto.TxSearchRequest) (*rpcproto.TxSearchResponse, error) {
+	res, err := s.env.TxSearch(ctx, req.Query, req.Prove,
+		optionalInt(req.Page), optionalInt(req.PerPage), req.OrderBy, req.Cursor)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	txs := make([]*rpcproto.TxResponse, len(res.Txs))
+	for i, tx := range res.Txs {
+		txs[i] = txResponse(tx, req.Prove)
+	}
+	return &rpcproto.TxSearchResponse{Txs: txs, TotalCount: int64(res.TotalCount), NextCursor: res.NextCursor}, nil
+}
+
+func (s *GRPCServer) BlockSearch(
+	ctx context.Context,
+	req *rpcproto.BlockSearchRequest,
+) (*rpcproto.BlockSearchResponse, error) {
+	res, err := s.env.BlockSearch(ctx, req.Query,
+		optionalInt(req.Page), optionalInt(req.PerPage), req.OrderBy, req.Cursor)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	blocks := make([]*rpcproto.BlockResponse, len(res.Blocks))
+	for i, block := range res.Blocks {
+		if blocks[i], err = blockResponse(block); err != nil {
+			return nil, err
+		}
+	}
+	return &rpcproto.BlockSearchResponse{Blocks: blocks, TotalCount: int64(res.TotalCount), NextCursor: res.NextCursor}, nil
+}
+
+func (s *GRPCServer) BroadcastTxAsync(
+	ctx context.Context,
+	req *rpcproto.BroadcastTxRequest,
+) (*rpcproto.BroadcastTxResponse, error) {
+	res, err := s.env.BroadcastTxAsync(ctx, req.Tx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return broadcastTxResponse(res), nil
+}
+
+func (s *GRPCServer) BroadcastTxSync(
+	ctx context.Context,
+	req *rpcproto.BroadcastTxRequest,
+) (*rpcproto.BroadcastTxResponse, error) {
+	res, err := s.env.BroadcastTxSync(ctx, req.Tx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return broadcastTxResponse(res), nil
+}
+
+func (s *GRPCServer) BroadcastTxCommit(
+	ctx context.Context,
+	req *rpcproto.BroadcastTxRequest,
+) (*rpcproto.BroadcastTxCommitResponse, error) {
+	res, err := s.env.BroadcastTxCommit(ctx, req.Tx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.BroadcastTxCommitResponse{
+		CheckTx:   res.CheckTx,
+		DeliverTx: res.DeliverTx,
+		Hash:      res.Hash,
+		Height:    res.Height,
+	}, nil
+}
+
+func (s *GRPCServer) ABCIInfo(ctx context.Context, req *rpcproto.ABCIInfoRequest) (*abci.ResponseInfo, error) {
+	res, err := s.env.ABCIInfo(ctx)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &res.Response, nil
+}
+
+func (s *GRPCServer) ABCIQuery(ctx context.Context, req *rpcproto.ABCIQueryRequest) (*abci.ResponseQuery, error) {
+	res, err := s.env.ABCIQuery(ctx, req.Path, req.Data, req.Height, req.Prove, req.BlockHash)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &res.Response, nil
+}
+
+func (s *GRPCServer) BroadcastEvidence(
+	ctx context.Context,
+	req *rpcproto.BroadcastEvidenceRequest,
+) (*rpcproto.BroadcastEvidenceResponse, error) {
+	var ev coretypes.Evidence
+	if req.Evidence != nil {
+		var err error
+		if ev.Value, err = types.EvidenceFromProto(req.Evidence); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid evidence: %v", err)
+		}
+	}
+	res, err := s.env.BroadcastEvidence(ctx, ev)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &rpcproto.BroadcastEvidenceResponse{Hash: res.Hash}, nil
+}
+
+// Subscribe streams the events matching the query of req until the client
+// cancels the call or the subscription is terminated. Subscriptions count
+// against the same limits as the WebSocket ones, with the address of the
+// client as its ID.
+func (s *GRPCServer) Subscribe(req *rpcproto.SubscribeRequest, stream rpcproto.CoreAPI_SubscribeServer) error {
+	ctx := stream.Context()
+	addr := "grpc"
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = "grpc:" + p.Addr.String()
+	}
+
+	sub, err := s.env.subscribe(ctx, addr, req.Query, tmpubsub.SubscribeArgs{})
+	if err != nil {
+		return grpcError(err)
+	}
+	defer func() {
+		args := tmpubsub.UnsubscribeArgs{Subscriber: addr, ID: sub.ID()}
+		if err := s.env.EventBus.Unsubscribe(context.Background(), args); err != nil &&
+			!errors.Is(err, tmpubsub.ErrSubscriptionNotFound) {
+			s.env.Logger.Error("Failed to unsubscribe from events", "addr", addr, "err", err)
+		}
+	}()
+
+	for {
+		msg, err := sub.Next(ctx)
+		if err != nil {
+			return grpcError(err)
+		}
+		ev, err := eventToProto(msg.Data(), msg.Events())
+		if err != nil {
+			return status.Errorf(codes.Internal, "converting event: %v", err)
+		}
+		if err := stream.Send(ev); err != nil {
+			return err
+		}
+	}
+}
+
+// eventToProto converts the data and the events of a pubsub message. The data
+// of the most common events is also given in protobuf.
+func eventToProto(data interface{}, events []abci.Event) (*rpcproto.Event, error) {
+	tagged, ok := data.(jsontypes.Tagged)
+	if !ok {
+		return nil, fmt.Errorf("type %T is not tagged", data)
+	}
+	bz, err := jsontypes.Marshal(tagged)
+	if err != nil {
+		return nil, err
+	}
+	ev := &rpcproto.Event{
+		Type:   strings.TrimPrefix(tagged.TypeTag(), "tendermint/event/"),
+		Data:   bz,
+		Events: events,
+	}
+
+	switch data := data.(type) {
+	case types.EventDataNewBlock:
+		if data.Block != nil {
+			block, err := data.Block.ToProto()
+			if err != nil {
+				return nil, err
+			}
+			ev.TypedData = &rpcproto.Event_Block{Block: block}
+		}
+	case types.EventDataTx:
+		txResult := data.TxResult
+		ev.TypedData = &rpcproto.Event_Tx{Tx: &txResult}
+	case types.EventDataNewBlockHeader:
+		ev.TypedData = &rpcproto.Event_Header{Header: data.Header.ToProto()}
+	case types.EventDataVote:
+		if data.Vote != nil {
+			ev.TypedData = &rpcproto.Event_Vote{Vote: data.Vote.ToProto()}
+		}
+	}
+	return ev, nil
+}
+
+// grpcError converts an error of a core method to a gRPC status error.
+func grpcError(err error) error {
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, tmpubsub.ErrUnsubscribed):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.Is(err, tmpubsub.ErrTerminated):
+		code = codes.Aborted
+	case errors.Is(err, coretypes.ErrHeightExceedsChainHead), errors.Is(err, coretypes.ErrPageOutOfRange):
+		code = codes.OutOfRange
+	case errors.Is(err, coretypes.ErrHeightNotAvailable):
+		code = codes.NotFound
+	case errors.Is(err, coretypes.ErrInvalidRequest),
+		errors.Is(err, coretypes.ErrZeroOrNegativeHeight),
+		errors.Is(err, coretypes.ErrZeroOrNegativePerPage),
+		errors.Is(err, coretypes.ErrPerPageExceedsMax):
+		code = codes.InvalidArgument
+	case errors.Is(err, coretypes.ErrCatchingUp):
+		code = codes.Unavailable
+	}
+	return status.Error(code, err.Error())
+}
+
+// optionalHeight returns nil for a zero height, which designates the latest
+// height, as an omitted height does in JSON-RPC.
+func optionalHeight(height int64) *int64 {
+	if height == 0 {
+		return nil
+	}
+	return &height
+}
+
+// optionalInt returns nil for a zero page or page size, which designates the
+// default, as an omitted one does in JSON-RPC.
+func optionalInt(n int64) *int {
+	if n == 0 {
+		return nil
+	}
+	v := int(n)
+	return &v
+}
+
+func blockResponse(res *coretypes.ResultBlock) (*rpcproto.BlockResponse, error) {
+	rsp := &rpcproto.BlockResponse{BlockId: res.BlockID.ToProto()}
+	if res.Block != nil {
+		block, err := res.Block.ToProto()
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "converting block: %v", err)
+		}
+		rsp.Block = block
+	}
+	return rsp, nil
+}
+
+func txResponse(res *coretypes.ResultTx, prove bool) *rpcproto.TxResponse {
+	rsp := &rpcproto.TxResponse{
+		Hash:     res.Hash,
+		Height:   res.Height,
+		Index:    res.Index,
+		TxResult: res.TxResult,
+		Tx:       res.Tx,
+	}
+	if prove {
+		proof := res.Proof.ToProto()
+		rsp.Proof = &proof
+	}
+	return rsp
+}
+
+func unconfirmedTxsResponse(res *coretypes.ResultUnconfirmedTxs) *rpcproto.UnconfirmedTxsResponse {
+	txs := make([][]byte, len(res.Txs))
+	for i, tx := range res.Txs {
+		txs[i] = tx
+	}
+	return &rpcproto.UnconfirmedTxsResponse{
+		Count:      int64(res.Count),
+		Total:      int64(res.Total),
+		TotalBytes: res.TotalBytes,
+		Txs:        txs,
+	}
+}
+
+func broadcastTxResponse(res *coretypes.ResultBroadcastTx) *rpcproto.BroadcastTxResponse {
+	return &rpcproto.BroadcastTxResponse{
+		Code:         res.Code,
+		Data:         res.Data,
+		Log:          res.Log,
+		Codespace:    res.Codespace,
+		MempoolError: res.MempoolError,
+		Hash:         res.Hash,
+	}
+}
+
+// mutatingGRPCMethods are the CoreAPI methods rejected in read-only mode, as
+// the mutatingRoutes of the JSON-RPC server.
+var mutatingGRPCMethods = map[string]bool{
+	"/tendermint.rpc.CoreAPI/BroadcastTxAsync":  true,
+	"/tendermint.rpc.CoreAPI/BroadcastTxSync":   true,
+	"/tendermint.rpc.CoreAPI/BroadcastTxCommit": true,
+	"/tendermint.rpc.CoreAPI/BroadcastEvidence": true,
+	"/tendermint.rpc.CoreAPI/RemoveTx":          true,
+}
+
+// readOnlyInterceptor rejects the calls of the mutating methods as
+// unimplemented.
+func readOnlyInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if mutatingGRPCMethods[info.FullMethod] {
+		return nil, status.Errorf(codes.Unimplemented, "method %s is disabled in read-only mode", info.FullMethod)
+	}
+	return handler(ctx, req)
+}
+
+// coreAPIService is the name of the CoreAPI service, as reported by the
+// health service.
+const coreAPIService = "tendermint.rpc.CoreAPI"
+
+// startGRPC serves the CoreAPI service on listener until ctx is canceled. In
+// read-only mode, the mutating methods are rejected.
+//
+// The standard health checking and server reflection services are served
+// alongside, for load balancers, probes and tools such as grpcurl. The server,
+// and the CoreAPI service, are reported as serving until ctx is canceled.
+func (env *Environment) startGRPC(ctx context.Context, listener net.Listener, readOnly bool) {
+	var opts []grpc.ServerOption
+	if readOnly {
+		opts = append(opts, grpc.UnaryInterceptor(readOnlyInterceptor))
+	}
+	srv := grpc.NewServer(opts...)
+	NewGRPCServer(env).Register(srv)
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus(coreAPIService, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthSrv)
+	registerGRPCReflection(srv)
+
+	go func() {
+		<-ctx.Done()
+		healthSrv.Shutdown()
+		srv.Stop()
+	}()
+	go func() {
+		env.Logger.Info("Starting gRPC server", "laddr", listener.Addr())
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			env.Logger.Error("error serving gRPC server", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,224 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	gogoproto "github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// grpcReflectionServer serves the gRPC server reflection protocol for the
+// services of srv, for generic tools such as grpcurl.
+//
+// The reflection service of grpc-go only finds the descriptors registered with
+// golang/protobuf, e.g. those of the health service, while the Tendermint
+// protos are registered with gogo/protobuf: the descriptors are looked up in
+// both registries.
+type grpcReflectionServer struct {
+	srv *grpc.Server
+}
+
+var _ rpb.ServerReflectionServer = (*grpcReflectionServer)(nil)
+
+// registerGRPCReflection registers the reflection service with srv, after
+// its other services.
+func registerGRPCReflection(srv *grpc.Server) {
+	rpb.RegisterServerReflectionServer(srv, &grpcReflectionServer{srv: srv})
+}
+
+// ServerReflectionInfo implements rpb.ServerReflectionServer.
+func (s *grpcReflectionServer) ServerReflectionInfo(stream rpb.ServerReflection_ServerReflectionInfoServer) error {
+	// The files already sent on the stream, which are not sent again as the
+	// dependencies of the next ones.
+	sent := make(map[string]bool)
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		rsp := &rpb.ServerReflectionResponse{ValidHost: req.Host, OriginalRequest: req}
+		var files [][]byte
+		switch r := req.MessageRequest.(type) {
+		case *rpb.ServerReflectionRequest_FileByFilename:
+			files, err = fileDescriptorsWithDeps(r.FileByFilename, sent)
+		case *rpb.ServerReflectionRequest_FileContainingSymbol:
+			var file string
+			if file, err = s.fileContainingSymbol(r.FileContainingSymbol); err == nil {
+				files, err = fileDescriptorsWithDeps(file, sent)
+			}
+		case *rpb.ServerReflectionRequest_FileContainingExtension:
+			err = status.Errorf(codes.NotFound, "extension %d of %s not found",
+				r.FileContainingExtension.ExtensionNumber, r.FileContainingExtension.ContainingType)
+		case *rpb.ServerReflectionRequest_AllExtensionNumbersOfType:
+			// The services use no extensions.
+			rsp.MessageResponse = &rpb.ServerReflectionResponse_AllExtensionNumbersResponse{
+				AllExtensionNumbersResponse: &rpb.ExtensionNumberResponse{BaseTypeName: r.AllExtensionNumbersOfType},
+			}
+		case *rpb.ServerReflectionRequest_ListServices:
+			rsp.MessageResponse = &rpb.ServerReflectionResponse_ListServicesResponse{
+				ListServicesResponse: s.listServices(),
+			}
+		default:
+			err = status.Errorf(codes.InvalidArgument, "invalid reflection request %T", req.MessageRequest)
+		}
+
+		switch {
+		case err != nil:
+			rsp.MessageResponse = &rpb.ServerReflectionResponse_ErrorResponse{
+				ErrorResponse: &rpb.ErrorResponse{ErrorCode: int32(status.Code(err)), ErrorMessage: err.Error()},
+			}
+		case files != nil:
+			rsp.MessageResponse = &rpb.ServerReflectionResponse_FileDescriptorResponse{
+				FileDescriptorResponse: &rpb.FileDescriptorResponse{FileDescriptorProto: files},
+			}
+		}
+		if err := stream.Send(rsp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *grpcReflectionServer) listServices() *rpb.ListServiceResponse {
+	info := s.srv.GetServiceInfo()
+	names := make([]string, 0, len(info))
+	for name := range info {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rsp := &rpb.ListServiceResponse{Service: make([]*rpb.ServiceResponse, len(names))}
+	for i, name := range names {
+		rsp.Service[i] = &rpb.ServiceResponse{Name: name}
+	}
+	return rsp
+}
+
+// fileContainingSymbol returns the name of the file declaring the service,
+// method, message or enum with the given fully-qualified name.
+func (s *grpcReflectionServer) fileContainingSymbol(symbol string) (string, error) {
+	info := s.srv.GetServiceInfo()
+	service := symbol
+	if _, ok := info[service]; !ok {
+		if i := strings.LastIndexByte(symbol, '.'); i >= 0 {
+			service = symbol[:i]
+		}
+	}
+	if svc, ok := info[service]; ok {
+		if file, ok := svc.Metadata.(string); ok {
+			return file, nil
+		}
+	}
+
+	if typ := gogoproto.MessageType(symbol); typ != nil && typ.Kind() == reflect.Ptr {
+		if msg, ok := reflect.New(typ.Elem()).Interface().(interface{ Descriptor() ([]byte, []int) }); ok {
+			gz, _ := msg.Descriptor()
+			raw, err := gunzip(gz)
+			if err != nil {
+				return "", status.Errorf(codes.Internal, "decompressing the descriptor of %s: %v", symbol, err)
+			}
+			fd := &descriptor.FileDescriptorProto{}
+			if err := gogoproto.Unmarshal(raw, fd); err != nil {
+				return "", status.Errorf(codes.Internal, "decoding the descriptor of %s: %v", symbol, err)
+			}
+			return fd.GetName(), nil
+		}
+	}
+	if d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(symbol)); err == nil {
+		return d.ParentFile().Path(), nil
+	}
+	return "", status.Errorf(codes.NotFound, "symbol %s not found", symbol)
+}
+
+// fileDescriptorsWithDeps returns the encoded descriptors of the file with the
+// given name and of its dependencies, skipping those already sent.
+func fileDescriptorsWithDeps(name string, sent map[string]bool) ([][]byte, error) {
+	var files [][]byte
+	queue := []string{name}
+	for len(queue) > 0 {
+		name, queue = queue[0], queue[1:]
+		if sent[name] {
+			continue
+		}
+		raw, deps, err := fileDescriptor(name)
+		if err != nil {
+			return nil, err
+		}
+		sent[name] = true
+		files = append(files, raw)
+		queue = append(queue, deps...)
+	}
+	return files, nil
+}
+
+// gogoFileNames are the names the files imported by the Tendermint protos are
+// registered with by gogo/protobuf, when they differ from the imported ones.
+var gogoFileNames = map[string]string{
+	"gogoproto/gogo.proto": "gogo.proto",
+}
+
+// fileDescriptor returns the encoded descriptor of the file with the given
+// name, and the names of its dependencies.
+func fileDescriptor(name string) ([]byte, []string, error) {
+	gogoName, renamed := gogoFileNames[name]
+	if !renamed {
+		gogoName = name
+	}
+	if gz := gogoproto.FileDescriptor(gogoName); gz != nil {
+		raw, err := gunzip(gz)
+		if err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "decompressing the descriptor of %s: %v", name, err)
+		}
+		fd := &descriptor.FileDescriptorProto{}
+		if err := gogoproto.Unmarshal(raw, fd); err != nil {
+			return nil, nil, status.Errorf(codes.Internal, "decoding the descriptor of %s: %v", name, err)
+		}
+		if renamed {
+			// The file is sent with the name it is imported with.
+			fd.Name = &name
+			if raw, err = gogoproto.Marshal(fd); err != nil {
+				return nil, nil, status.Errorf(codes.Internal, "encoding the descriptor of %s: %v", name, err)
+			}
+		}
+		return raw, fd.Dependency, nil
+	}
+
+	fd, err := protoregistry.GlobalFiles.FindFileByPath(name)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.NotFound, "file %s not found", name)
+	}
+	raw, err := proto.Marshal(protodesc.ToFileDescriptorProto(fd))
+	if err != nil {
+		return nil, nil, status.Errorf(codes.Internal, "encoding the descriptor of %s: %v", name, err)
+	}
+	deps := make([]string, fd.Imports().Len())
+	for i := range deps {
+		deps[i] = fd.Imports().Get(i).Path()
+	}
+	return raw, deps, nil
+}
+
+func gunzip(gz []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	gogoproto "github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/eventbus"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/libs/log"
+	rpcproto "github.com/tendermint/tendermint/proto/tendermint/rpc"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestGRPCServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.NewDefault(log.TestingLogger())
+	require.NoError(t, eventBus.Start(ctx))
+
+	env := &Environment{
+		EventBus:   eventBus,
+		StateStore: sm.NewStore(dbm.NewMemDB()),
+		Logger:     log.TestingLogger(),
+		Config:     *config.TestRPCConfig(),
+	}
+	require.NoError(t, env.StateStore.SaveABCIResponses(100, &tmstate.ABCIResponses{
+		DeliverTxs: []*abci.ResponseDeliverTx{{Code: 1, Log: "not ok"}},
+		EndBlock:   &abci.ResponseEndBlock{},
+		BeginBlock: &abci.ResponseBeginBlock{},
+	}))
+	mockstore := &mocks.BlockStore{}
+	mockstore.On("Height").Return(int64(100))
+	mockstore.On("Base").Return(int64(1))
+	env.BlockStore = mockstore
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	env.startGRPC(ctx, listener, false)
+
+	conn, err := grpc.DialContext(ctx, listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := rpcproto.NewCoreAPIClient(conn)
+
+	_, err = client.Health(ctx, &rpcproto.HealthRequest{})
+	require.NoError(t, err)
+
+	// a zero height is the latest height
+	res, err := client.BlockResults(ctx, &rpcproto.BlockResultsRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, res.Height)
+	require.Len(t, res.TxsResults, 1)
+	assert.Equal(t, "not ok", res.TxsResults[0].Log)
+
+	_, err = client.BlockResults(ctx, &rpcproto.BlockResultsRequest{Height: 101})
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	_, err = client.BlockResults(ctx, &rpcproto.BlockResultsRequest{Height: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	subCtx, subCancel := context.WithCancel(ctx)
+	defer subCancel()
+	stream, err := client.Subscribe(subCtx, &rpcproto.SubscribeRequest{Query: "tm.event = 'NewBlockHeader'"})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return eventBus.NumClients() == 1 }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, eventBus.PublishEventNewBlockHeader(ctx, types.EventDataNewBlockHeader{
+		Header: types.Header{ChainID: "test", Height: 7},
+	}))
+	ev, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "NewBlockHeader", ev.Type)
+	assert.EqualValues(t, 7, ev.GetHeader().Height)
+	assert.Contains(t, string(ev.Data), `"tendermint/event/NewBlockHeader"`)
+
+	// Canceling the call removes the subscription.
+	subCancel()
+	require.Eventually(t, func() bool { return eventBus.NumClients() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestGRPCServerReadOnly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	env := &Environment{Logger: log.TestingLogger(), Config: *config.TestRPCConfig()}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	env.startGRPC(ctx, listener, true)
+
+	conn, err := grpc.DialContext(ctx, listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := rpcproto.NewCoreAPIClient(conn)
+
+	_, err = client.Health(ctx, &rpcproto.HealthRequest{})
+	require.NoError(t, err)
+	_, err = client.BroadcastTxSync(ctx, &rpcproto.BroadcastTxRequest{Tx: []byte("tx")})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = client.RemoveTx(ctx, &rpcproto.RemoveTxRequest{TxKey: make([]byte, 32)})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestGRPCServerHealthAndReflection(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	env := &Environment{Logger: log.TestingLogger(), Config: *config.TestRPCConfig()}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	env.startGRPC(ctx, listener, true)
+
+	conn, err := grpc.DialContext(ctx, listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	health := healthpb.NewHealthClient(conn)
+	for _, service := range []string{"", coreAPIService} {
+		res, err := health.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status, service)
+	}
+	_, err = health.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	defer func() { _ = stream.CloseSend() }()
+	reflect := func(req *rpb.ServerReflectionRequest) *rpb.ServerReflectionResponse {
+		require.NoError(t, stream.Send(req))
+		res, err := stream.Recv()
+		require.NoError(t, err)
+		return res
+	}
+
+	res := reflect(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_ListServices{},
+	})
+	var services []string
+	for _, service := range res.GetListServicesResponse().GetService() {
+		services = append(services, service.Name)
+	}
+	assert.Equal(t, []string{
+		"grpc.health.v1.Health",
+		"grpc.reflection.v1alpha.ServerReflection",
+		coreAPIService,
+	}, services)
+
+	// The files of the gogo and golang protos are found, with their
+	// dependencies, each sent once on the stream.
+	fileNames := func(res *rpb.ServerReflectionResponse) []string {
+		var names []string
+		for _, raw := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptor.FileDescriptorProto{}
+			require.NoError(t, gogoproto.Unmarshal(raw, fd))
+			names = append(names, fd.GetName())
+		}
+		return names
+	}
+	res = reflect(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: coreAPIService},
+	})
+	names := fileNames(res)
+	require.NotEmpty(t, names)
+	assert.Equal(t, "tendermint/rpc/service.proto", names[0])
+	assert.Contains(t, names, "tendermint/types/types.proto")
+	assert.Contains(t, names, "gogoproto/gogo.proto")
+	assert.Contains(t, names, "google/protobuf/descriptor.proto")
+
+	res = reflect(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "tendermint.types.Header",
+		},
+	})
+	assert.Empty(t, fileNames(res))
+	assert.Nil(t, res.GetErrorResponse())
+
+	res = reflect(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{
+			FileContainingSymbol: "grpc.health.v1.Health.Check",
+		},
+	})
+	assert.Equal(t, []string{"grpc/health/v1/health.proto"}, fileNames(res))
+
+	res = reflect(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "unknown.Symbol"},
+	})
+	assert.EqualValues(t, codes.NotFound, res.GetErrorResponse().GetErrorCode())
+}
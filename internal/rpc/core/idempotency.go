@@ -0,0 +1,166 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// IdempotencyKeyHeader is the HTTP header carrying the idempotency key of a
+	// broadcast request. URI requests may pass it as the idempotency_key
+	// parameter instead.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	idempotencyKeyParam     = "idempotency_key"
+	maxIdempotencyKeyLength = 255
+
+	// maxIdempotencyKeys bounds the number of keys remembered at once; the
+	// oldest keys are forgotten first.
+	maxIdempotencyKeys = 10000
+)
+
+// idempotencyKey returns the idempotency key of the HTTP request ctx was
+// created for, or an empty string if there is none.
+func idempotencyKey(ctx context.Context) string {
+	callInfo := rpctypes.GetCallInfo(ctx)
+	if callInfo == nil || callInfo.HTTPRequest == nil {
+		return ""
+	}
+	req := callInfo.HTTPRequest
+	if key := req.Header.Get(IdempotencyKeyHeader); key != "" {
+		return key
+	}
+	// Like other URI parameters, the key may be quoted.
+	return strings.Trim(req.URL.Query().Get(idempotencyKeyParam), `"`)
+}
+
+// idempotentCall is a broadcast request made with an idempotency key, and
+// its response once done is closed.
+type idempotentCall struct {
+	key     string
+	method  string
+	txHash  []byte
+	expires time.Time
+
+	done   chan struct{}
+	result interface{}
+	err    error
+}
+
+// idempotentCalls remembers the broadcast requests made with an idempotency
+// key, so that retries of a request within the window get its response
+// instead of submitting the transaction again.
+type idempotentCalls struct {
+	mtx   sync.Mutex
+	calls map[string]*idempotentCall
+	order []*idempotentCall // by expiry
+}
+
+// do runs fn, the handler of method for tx, unless a request with the same
+// key was made within window, in which case it returns the response to that
+// request, waiting for it if needed. Failed requests are not remembered, so
+// that they can be retried.
+func (c *idempotentCalls) do(
+	ctx context.Context,
+	key string,
+	window time.Duration,
+	method string,
+	tx types.Tx,
+	fn func() (interface{}, error),
+) (interface{}, error) {
+	if key == "" || window <= 0 {
+		return fn()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, fmt.Errorf("idempotency key must be at most %d characters long: %w",
+			maxIdempotencyKeyLength, coretypes.ErrInvalidRequest)
+	}
+
+	call, found := c.get(key, window, method, tx.Hash())
+	if found {
+		if call.method != method || !bytes.Equal(call.txHash, tx.Hash()) {
+			return nil, fmt.Errorf("idempotency key %q was used for another request: %w",
+				key, coretypes.ErrInvalidRequest)
+		}
+		select {
+		case <-call.done:
+			return call.result, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call.result, call.err = fn()
+	close(call.done)
+	if call.err != nil {
+		c.forget(call)
+	}
+	return call.result, call.err
+}
+
+// get returns the unexpired call with the given key, or records a new call
+// made now and returns it with found false.
+func (c *idempotentCalls) get(
+	key string,
+	window time.Duration,
+	method string,
+	txHash []byte,
+) (call *idempotentCall, found bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := time.Now()
+	for len(c.order) > 0 && (len(c.order) >= maxIdempotencyKeys || now.After(c.order[0].expires)) {
+		if old := c.order[0]; c.calls[old.key] == old {
+			delete(c.calls, old.key)
+		}
+		c.order[0] = nil
+		c.order = c.order[1:]
+	}
+
+	if call, ok := c.calls[key]; ok && !now.After(call.expires) {
+		return call, true
+	}
+
+	call = &idempotentCall{
+		key:     key,
+		method:  method,
+		txHash:  txHash,
+		expires: now.Add(window),
+		done:    make(chan struct{}),
+	}
+	if c.calls == nil {
+		c.calls = make(map[string]*idempotentCall)
+	}
+	c.calls[key] = call
+	c.order = append(c.order, call)
+	return call, false
+}
+
+func (c *idempotentCalls) forget(call *idempotentCall) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.calls[call.key] == call {
+		delete(c.calls, call.key)
+	}
+}
+
+// idempotent runs fn, the handler of method for tx, deduplicating requests
+// by their idempotency key.
+func (env *Environment) idempotent(
+	ctx context.Context,
+	method string,
+	tx types.Tx,
+	fn func() (interface{}, error),
+) (interface{}, error) {
+	return env.idempotentCalls.do(ctx, idempotencyKey(ctx), env.Config.IdempotencyWindow, method, tx, fn)
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/mempool"
+	"github.com/tendermint/tendermint/internal/mempool/mock"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// countingMempool accepts every transaction once and rejects duplicates, like
+// the mempool cache does.
+type countingMempool struct {
+	mock.Mempool
+
+	mtx   sync.Mutex
+	calls int32
+	seen  map[string]bool
+}
+
+func (m *countingMempool) CheckTx(_ context.Context, tx types.Tx, cb func(*abci.Response), _ mempool.TxInfo) error {
+	atomic.AddInt32(&m.calls, 1)
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.seen[string(tx)] {
+		return types.ErrTxInCache
+	}
+	m.seen[string(tx)] = true
+	if cb != nil {
+		cb(abci.ToResponseCheckTx(abci.ResponseCheckTx{Code: abci.CodeTypeOK, Data: tx}))
+	}
+	return nil
+}
+
+func TestBroadcastIdempotencyKey(t *testing.T) {
+	pool := &countingMempool{seen: make(map[string]bool)}
+	env := &Environment{
+		Mempool: pool,
+		Config:  *config.TestRPCConfig(),
+	}
+
+	call := func(key, query string) context.Context {
+		req := httptest.NewRequest("GET", "/broadcast_tx_sync"+query, nil)
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		return rpctypes.WithCallInfo(context.Background(), &rpctypes.CallInfo{HTTPRequest: req})
+	}
+
+	// Retries with the same key get the original response.
+	tx := types.Tx("tx1")
+	res, err := env.BroadcastTxSync(call("key1", ""), tx)
+	require.NoError(t, err)
+	require.Equal(t, []byte(tx), []byte(res.Data))
+	for i := 0; i < 3; i++ {
+		retry, err := env.BroadcastTxSync(call("key1", ""), tx)
+		require.NoError(t, err)
+		require.Equal(t, res, retry)
+	}
+	require.EqualValues(t, 1, atomic.LoadInt32(&pool.calls))
+
+	// So do retries passing the key as a URI parameter.
+	_, err = env.BroadcastTxSync(call("", `?idempotency_key="key1"`), tx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, atomic.LoadInt32(&pool.calls))
+
+	// Reusing a key for another request fails.
+	_, err = env.BroadcastTxSync(call("key1", ""), types.Tx("tx2"))
+	require.Error(t, err)
+	_, err = env.BroadcastTxAsync(call("key1", ""), tx)
+	require.Error(t, err)
+
+	// Without a key, duplicates reach the mempool.
+	_, err = env.BroadcastTxSync(call("", ""), tx)
+	require.ErrorIs(t, err, types.ErrTxInCache)
+	require.EqualValues(t, 2, atomic.LoadInt32(&pool.calls))
+
+	// So do the calls of local clients, without call info.
+	_, err = env.BroadcastTxSync(context.Background(), tx)
+	require.ErrorIs(t, err, types.ErrTxInCache)
+	require.EqualValues(t, 3, atomic.LoadInt32(&pool.calls))
+
+	// Failed requests are not remembered.
+	_, err = env.BroadcastTxAsync(call("key2", ""), tx)
+	require.Error(t, err)
+	_, err = env.BroadcastTxAsync(call("key2", ""), tx)
+	require.Error(t, err)
+	require.EqualValues(t, 5, atomic.LoadInt32(&pool.calls))
+
+	// Keys are forgotten after the window.
+	env.Config.IdempotencyWindow = 10 * time.Millisecond
+	_, err = env.BroadcastTxAsync(call("key3", ""), types.Tx("tx3"))
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = env.BroadcastTxAsync(call("key3", ""), types.Tx("tx3"))
+	require.ErrorIs(t, err, types.ErrTxInCache)
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	// Local clients call the environment without call info.
+	require.Empty(t, idempotencyKey(context.Background()))
+
+	// Websocket calls carry no HTTP request.
+	require.Empty(t, idempotencyKey(rpctypes.WithCallInfo(context.Background(), &rpctypes.CallInfo{})))
+
+	req := httptest.NewRequest("GET", `/broadcast_tx_sync?idempotency_key="key1"`, nil)
+	ctx := rpctypes.WithCallInfo(context.Background(), &rpctypes.CallInfo{HTTPRequest: req})
+	require.Equal(t, "key1", idempotencyKey(ctx))
+	req.Header.Set(IdempotencyKeyHeader, "key2")
+	require.Equal(t, "key2", idempotencyKey(ctx))
+}
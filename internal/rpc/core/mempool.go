@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
+	"net"
 	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -12,18 +13,63 @@ import (
 	"github.com/tendermint/tendermint/internal/state/indexer"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 	"github.com/tendermint/tendermint/types"
 )
 
 //-----------------------------------------------------------------------------
 // NOTE: tx should be signed, but this is only checked at the app level (not by Tendermint!)
 
+// rpcTxInfo returns the mempool info of a transaction submitted over RPC,
+// recording the IP address of the client.
+func rpcTxInfo(ctx context.Context) mempool.TxInfo {
+	addr := rpctypes.GetCallInfo(ctx).RemoteAddr()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return mempool.TxInfo{SourceIP: addr}
+}
+
+// checkCatchingUp returns a *coretypes.CatchingUpError if the node is syncing
+// further behind the head of the chain than the configured maximum lag of the
+// broadcast transactions.
+func (env *Environment) checkCatchingUp() error {
+	maxLag := env.Config.BroadcastMaxSyncLag
+	if maxLag == 0 || env.ConsensusReactor == nil || !env.ConsensusReactor.WaitSync() {
+		return nil
+	}
+
+	err := &coretypes.CatchingUpError{Height: env.BlockStore.Height()}
+	if env.BlockSyncReactor != nil {
+		err.TargetHeight = env.BlockSyncReactor.GetMaxPeerBlockHeight()
+	}
+	if env.StateSyncMetricer != nil {
+		if h := env.StateSyncMetricer.SnapshotHeight(); h > err.TargetHeight {
+			err.TargetHeight = h
+		}
+	}
+	if err.Lag() > maxLag {
+		return err
+	}
+	return nil
+}
+
 // BroadcastTxAsync returns right away, with no response. Does not wait for
 // CheckTx nor DeliverTx results.
 // More: https://docs.tendermint.com/master/rpc/#/Tx/broadcast_tx_async
 func (env *Environment) BroadcastTxAsync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
-	err := env.Mempool.CheckTx(ctx, tx, nil, mempool.TxInfo{})
-	if err != nil {
+	if err := env.checkCatchingUp(); err != nil {
+		return nil, err
+	}
+	res, err := env.idempotent(ctx, "broadcast_tx_async", tx, func() (interface{}, error) {
+		return env.broadcastTxAsync(ctx, tx)
+	})
+	result, _ := res.(*coretypes.ResultBroadcastTx)
+	return result, err
+}
+
+func (env *Environment) broadcastTxAsync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
+	if _, err := env.checkTx(ctx, tx); err != nil {
 		return nil, err
 	}
 
@@ -34,19 +80,26 @@ func (env *Environment) BroadcastTxAsync(ctx context.Context, tx types.Tx) (*cor
 // DeliverTx result.
 // More: https://docs.tendermint.com/master/rpc/#/Tx/broadcast_tx_sync
 func (env *Environment) BroadcastTxSync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
-	resCh := make(chan *abci.Response, 1)
-	err := env.Mempool.CheckTx(
-		ctx,
-		tx,
-		func(res *abci.Response) { resCh <- res },
-		mempool.TxInfo{},
-	)
+	if err := env.checkCatchingUp(); err != nil {
+		return nil, err
+	}
+	res, err := env.idempotent(ctx, "broadcast_tx_sync", tx, func() (interface{}, error) {
+		return env.broadcastTxSync(ctx, tx)
+	})
+	result, _ := res.(*coretypes.ResultBroadcastTx)
+	return result, err
+}
+
+func (env *Environment) broadcastTxSync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error) {
+	b, err := env.checkTx(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	res := <-resCh
-	r := res.GetCheckTx()
+	r, err := b.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	return &coretypes.ResultBroadcastTx{
 		Code:         r.Code,
@@ -61,18 +114,28 @@ func (env *Environment) BroadcastTxSync(ctx context.Context, tx types.Tx) (*core
 // BroadcastTxCommit returns with the responses from CheckTx and DeliverTx.
 // More: https://docs.tendermint.com/master/rpc/#/Tx/broadcast_tx_commit
 func (env *Environment) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTxCommit, error) {
-	resCh := make(chan *abci.Response, 1)
-	err := env.Mempool.CheckTx(
-		ctx,
-		tx,
-		func(res *abci.Response) { resCh <- res },
-		mempool.TxInfo{},
-	)
+	if err := env.checkCatchingUp(); err != nil {
+		return nil, err
+	}
+	res, err := env.idempotent(ctx, "broadcast_tx_commit", tx, func() (interface{}, error) {
+		return env.broadcastTxCommit(ctx, tx)
+	})
+	result, _ := res.(*coretypes.ResultBroadcastTxCommit)
+	return result, err
+}
+
+func (env *Environment) broadcastTxCommit(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTxCommit, error) {
+	b, err := env.checkTx(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	r := (<-resCh).GetCheckTx()
+	// The duplicates of a committed transaction get its DeliverTx result
+	// right away, from the lookup below.
+	r, err := b.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	if !indexer.KVSinkEnabled(env.EventSinks) {
 		return &coretypes.ResultBroadcastTxCommit{
@@ -122,8 +185,12 @@ func (env *Environment) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*co
 // More: https://docs.tendermint.com/master/rpc/#/Info/unconfirmed_txs
 func (env *Environment) UnconfirmedTxs(ctx context.Context, pagePtr, perPagePtr *int) (*coretypes.ResultUnconfirmedTxs, error) {
 	totalCount := env.Mempool.Size()
-	perPage := env.validatePerPage(perPagePtr)
-	page, err := validatePage(pagePtr, perPage, totalCount)
+	perPage, err := env.validatePerPage("unconfirmed_txs", perPagePtr)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := coretypes.ValidatePage(pagePtr, perPage, totalCount)
 	if err != nil {
 		return nil, err
 	}
@@ -140,6 +207,64 @@ func (env *Environment) UnconfirmedTxs(ctx context.Context, pagePtr, perPagePtr
 		Txs:        result}, nil
 }
 
+// mempoolInspector is implemented by mempools that expose the metadata of
+// their transactions.
+type mempoolInspector interface {
+	InspectTxs(sender string) []mempool.TxDetails
+}
+
+// MempoolTxs gets the metadata of the unconfirmed transactions, in order of
+// priority, optionally only those of the given sender as defined by the
+// application.
+// More: https://docs.tendermint.com/master/rpc/#/Info/mempool_txs
+func (env *Environment) MempoolTxs(
+	ctx context.Context,
+	sender string,
+	pagePtr, perPagePtr *int,
+) (*coretypes.ResultMempoolTxs, error) {
+	mi, ok := env.Mempool.(mempoolInspector)
+	if !ok {
+		return nil, errors.New("the mempool does not support inspection")
+	}
+
+	details := mi.InspectTxs(sender)
+	totalCount := len(details)
+	perPage, err := env.validatePerPage("mempool_txs", perPagePtr)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := coretypes.ValidatePage(pagePtr, perPage, totalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	skipCount := validateSkipCount(page, perPage)
+	pageSize := tmmath.MinInt(perPage, totalCount-skipCount)
+
+	now := time.Now()
+	txs := make([]coretypes.MempoolTx, 0, pageSize)
+	for _, d := range details[skipCount : skipCount+pageSize] {
+		hash := d.Hash
+		txs = append(txs, coretypes.MempoolTx{
+			Hash:          hash[:],
+			Size:          d.Size,
+			GasWanted:     d.GasWanted,
+			Priority:      d.Priority,
+			Sender:        d.Sender,
+			Height:        d.Height,
+			ReceivedAt:    d.Timestamp,
+			TimeInMempool: now.Sub(d.Timestamp),
+		})
+	}
+
+	return &coretypes.ResultMempoolTxs{
+		Count: len(txs),
+		Total: totalCount,
+		Txs:   txs,
+	}, nil
+}
+
 // NumUnconfirmedTxs gets number of unconfirmed transactions.
 // More: https://docs.tendermint.com/master/rpc/#/Info/num_unconfirmed_txs
 func (env *Environment) NumUnconfirmedTxs(ctx context.Context) (*coretypes.ResultUnconfirmedTxs, error) {
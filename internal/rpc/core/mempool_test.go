@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/consensus"
+	"github.com/tendermint/tendermint/internal/mempool"
+	mempoolmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	ssmocks "github.com/tendermint/tendermint/internal/statesync/mocks"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestBroadcastTxCatchingUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The node restores a snapshot at height 1000.
+	newChannel := func(_ context.Context, desc *p2p.ChannelDescriptor) (*p2p.Channel, error) {
+		return p2p.NewChannel(desc.ID, desc.MessageType, nil, nil, nil), nil
+	}
+	reactor, err := consensus.NewReactor(ctx, log.NewNopLogger(), nil, newChannel,
+		p2p.NewPeerUpdates(make(chan p2p.PeerUpdate), 1), true, consensus.NopMetrics())
+	require.NoError(t, err)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Height").Return(int64(10))
+	metricer := &ssmocks.Metricer{}
+	metricer.On("SnapshotHeight").Return(int64(1000))
+
+	env := &Environment{
+		Config:            *config.TestRPCConfig(),
+		ConsensusReactor:  reactor,
+		BlockStore:        blockStore,
+		StateSyncMetricer: metricer,
+	}
+	env.Config.BroadcastMaxSyncLag = 100
+
+	_, err = env.BroadcastTxSync(ctx, types.Tx("tx"))
+	require.ErrorIs(t, err, coretypes.ErrCatchingUp)
+	var catchingUp *coretypes.CatchingUpError
+	require.ErrorAs(t, err, &catchingUp)
+	assert.Equal(t, int64(10), catchingUp.Height)
+	assert.Equal(t, int64(1000), catchingUp.TargetHeight)
+	assert.Equal(t, int64(990), catchingUp.Lag())
+	_, err = env.BroadcastTxAsync(ctx, types.Tx("tx"))
+	assert.ErrorIs(t, err, coretypes.ErrCatchingUp)
+	_, err = env.BroadcastTxCommit(ctx, types.Tx("tx"))
+	assert.ErrorIs(t, err, coretypes.ErrCatchingUp)
+
+	// The transactions are accepted within the maximum lag, or when the check
+	// is disabled.
+	env.Config.BroadcastMaxSyncLag = 990
+	assert.NoError(t, env.checkCatchingUp())
+	env.Config.BroadcastMaxSyncLag = 0
+	assert.NoError(t, env.checkCatchingUp())
+}
+
+// inspectableMempool returns the given transaction details, regardless of the
+// sender.
+type inspectableMempool struct {
+	mempoolmock.Mempool
+	txs []mempool.TxDetails
+}
+
+func (m *inspectableMempool) InspectTxs(sender string) []mempool.TxDetails {
+	return m.txs
+}
+
+func TestMempoolTxs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := time.Now().Add(-time.Minute)
+	mp := &inspectableMempool{}
+	for i := 0; i < 5; i++ {
+		tx := types.Tx{byte(i)}
+		mp.txs = append(mp.txs, mempool.TxDetails{
+			Hash:      tx.Key(),
+			Size:      len(tx),
+			GasWanted: 10,
+			Priority:  int64(5 - i),
+			Sender:    "sender",
+			Height:    3,
+			Timestamp: received,
+		})
+	}
+	env := &Environment{Config: *config.TestRPCConfig(), Mempool: mp}
+
+	page, perPage := 2, 2
+	res, err := env.MempoolTxs(ctx, "sender", &page, &perPage)
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Count)
+	assert.Equal(t, 5, res.Total)
+	require.Len(t, res.Txs, 2)
+	tx := res.Txs[0]
+	assert.EqualValues(t, types.Tx{2}.Hash(), tx.Hash)
+	assert.Equal(t, 1, tx.Size)
+	assert.Equal(t, int64(10), tx.GasWanted)
+	assert.Equal(t, int64(3), tx.Priority)
+	assert.Equal(t, "sender", tx.Sender)
+	assert.Equal(t, int64(3), tx.Height)
+	assert.Equal(t, received, tx.ReceivedAt)
+	assert.GreaterOrEqual(t, tx.TimeInMempool, time.Minute)
+
+	page = 3
+	res, err = env.MempoolTxs(ctx, "sender", &page, &perPage)
+	require.NoError(t, err)
+	assert.Len(t, res.Txs, 1)
+
+	env.Mempool = mempoolmock.Mempool{}
+	_, err = env.MempoolTxs(ctx, "", nil, nil)
+	assert.Error(t, err)
+}
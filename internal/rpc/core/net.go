@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
 )
 
 // NetInfo returns network info.
@@ -20,9 +24,19 @@ func (env *Environment) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo,
 			continue
 		}
 
+		var windows []coretypes.MaintenanceWindow
+		for _, w := range env.PeerManager.Maintenance().Upcoming(peer) {
+			windows = append(windows, coretypes.MaintenanceWindow{
+				Start:  w.Start,
+				End:    w.End,
+				Reason: w.Reason,
+			})
+		}
+
 		peers = append(peers, coretypes.Peer{
-			ID:  peer,
-			URL: addrs[0].String(),
+			ID:          peer,
+			URL:         addrs[0].String(),
+			Maintenance: windows,
 		})
 	}
 
@@ -34,6 +48,237 @@ func (env *Environment) NetInfo(ctx context.Context) (*coretypes.ResultNetInfo,
 	}, nil
 }
 
+// PeerStats returns the lifetime statistics of every peer the node has been
+// connected to, accumulated across connections and node restarts.
+func (env *Environment) PeerStats(ctx context.Context) (*coretypes.ResultPeerStats, error) {
+	connected := make(map[types.NodeID]bool)
+	for _, peer := range env.PeerManager.Peers() {
+		connected[peer] = true
+	}
+
+	stats := env.PeerManager.Stats().List()
+	peers := make([]coretypes.PeerStats, 0, len(stats))
+	for _, s := range stats {
+		peers = append(peers, coretypes.PeerStats{
+			ID:               s.NodeID,
+			Connected:        connected[s.NodeID],
+			FirstConnected:   s.FirstConnected,
+			LastConnected:    s.LastConnected,
+			LastDisconnected: s.LastDisconnected,
+			Connects:         s.Connects,
+			BytesSent:        s.BytesSent,
+			BytesReceived:    s.BytesReceived,
+			BlocksDelivered:  s.BlocksDelivered,
+			Misbehaviors:     s.Misbehaviors,
+		})
+	}
+
+	return &coretypes.ResultPeerStats{Peers: peers}, nil
+}
+
+// PeerBandwidth returns the bandwidth used by the connected peers since they
+// connected, by channel, with the time spent throttling them.
+func (env *Environment) PeerBandwidth(ctx context.Context) (*coretypes.ResultPeerBandwidth, error) {
+	if env.BandwidthMeter == nil {
+		return nil, errors.New("bandwidth accounting is not supported by this node")
+	}
+
+	bandwidth := env.BandwidthMeter.PeerBandwidth()
+	peers := make([]coretypes.PeerBandwidth, 0, len(bandwidth))
+	for _, b := range bandwidth {
+		channels := make([]coretypes.ChannelBandwidth, 0, len(b.Channels))
+		for _, ch := range b.Channels {
+			channels = append(channels, coretypes.ChannelBandwidth{
+				ChannelID:     byte(ch.ChannelID),
+				BytesSent:     ch.BytesSent,
+				BytesReceived: ch.BytesReceived,
+				RecvRateLimit: ch.RecvRateLimit,
+				Throttled:     ch.Throttled,
+			})
+		}
+		peers = append(peers, coretypes.PeerBandwidth{
+			ID:        b.NodeID,
+			Connected: b.Connected,
+			Channels:  channels,
+		})
+	}
+
+	return &coretypes.ResultPeerBandwidth{Peers: peers}, nil
+}
+
+// AddressBook exports the peers of the peer store, best first, to be imported
+// by another node or after a reset of the data of this one.
+func (env *Environment) AddressBook(ctx context.Context) (*coretypes.ResultAddressBook, error) {
+	exported := env.PeerManager.ExportAddressBook()
+	peers := make([]coretypes.AddressBookPeer, 0, len(exported))
+	for _, peer := range exported {
+		addresses := make([]string, 0, len(peer.Addresses))
+		for _, address := range peer.Addresses {
+			addresses = append(addresses, address.String())
+		}
+		sort.Strings(addresses)
+		peers = append(peers, coretypes.AddressBookPeer{
+			ID:            peer.NodeID,
+			Addresses:     addresses,
+			LastConnected: peer.LastConnected,
+			Score:         peer.Score,
+		})
+	}
+
+	return &coretypes.ResultAddressBook{Peers: peers}, nil
+}
+
+// UnsafeImportAddressBook adds the peers, as exported by AddressBook, to the
+// peer store. The peers the node has no statistics of take the imported
+// scores.
+func (env *Environment) UnsafeImportAddressBook(
+	ctx context.Context,
+	peers []coretypes.AddressBookPeer,
+) (*coretypes.ResultImportAddressBook, error) {
+	imported := make([]p2p.AddressBookPeer, 0, len(peers))
+	for _, peer := range peers {
+		addresses := make([]p2p.NodeAddress, 0, len(peer.Addresses))
+		for _, a := range peer.Addresses {
+			address, err := p2p.ParseNodeAddress(a)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid address %q: %v", coretypes.ErrInvalidRequest, a, err)
+			}
+			addresses = append(addresses, address)
+		}
+		imported = append(imported, p2p.AddressBookPeer{
+			NodeID:        peer.ID,
+			Addresses:     addresses,
+			LastConnected: peer.LastConnected,
+			Score:         peer.Score,
+		})
+	}
+
+	added, err := env.PeerManager.ImportAddressBook(imported)
+	if err != nil {
+		return nil, err
+	}
+	return &coretypes.ResultImportAddressBook{Added: added}, nil
+}
+
+// PeerScores returns the scores of the peers of the peer store, best first,
+// with the latency, uptime and misbehaviors they are computed from.
+func (env *Environment) PeerScores(ctx context.Context) (*coretypes.ResultPeerScores, error) {
+	details := env.PeerManager.ScoreDetails()
+	peers := make([]coretypes.PeerScore, 0, len(details))
+	for _, d := range details {
+		peers = append(peers, coretypes.PeerScore{
+			ID:           d.NodeID,
+			Score:        uint8(d.Score),
+			Persistent:   d.Persistent,
+			MutableScore: d.MutableScore,
+			Reputation:   d.Reputation,
+			DialFailures: d.DialFailures,
+			Latency:      d.Latency,
+			Uptime:       d.Uptime,
+			Misbehaviors: d.Misbehaviors,
+		})
+	}
+
+	return &coretypes.ResultPeerScores{Peers: peers}, nil
+}
+
+// PeerFilter returns the rules allowing and denying the peers, in the order
+// they were added.
+func (env *Environment) PeerFilter(ctx context.Context) (*coretypes.ResultPeerFilter, error) {
+	if env.PeerFilterRules == nil {
+		return nil, errors.New("the peer filter is not available on this node")
+	}
+	return env.peerFilterResult(), nil
+}
+
+// UnsafePeerFilterAdd adds a rule allowing or denying the peers: action is
+// "allow" or "deny", kind "cidr", "node_id" or "chain_id", and value the CIDR
+// or IP address, node ID or chain ID matched. The rules apply to the new
+// connections, but a peer denied by its node ID is disconnected too.
+func (env *Environment) UnsafePeerFilterAdd(
+	ctx context.Context,
+	action, kind, value string,
+) (*coretypes.ResultPeerFilter, error) {
+	if env.PeerFilterRules == nil {
+		return nil, errors.New("the peer filter is not available on this node")
+	}
+	rule := p2p.PeerFilterRule{Action: p2p.PeerFilterAction(action), Kind: p2p.PeerFilterKind(kind), Value: value}
+	if _, err := env.PeerFilterRules.AddRule(rule); err != nil {
+		return nil, fmt.Errorf("%w: %v", coretypes.ErrInvalidRequest, err)
+	}
+	if rule.Action == p2p.PeerFilterDeny && rule.Kind == p2p.PeerFilterNodeID {
+		// The node ID is valid, since the rule is.
+		id, _ := types.NewNodeID(strings.TrimSpace(value))
+		env.PeerManager.Errored(id, errors.New("denied by the peer filter"))
+	}
+	return env.peerFilterResult(), nil
+}
+
+// UnsafePeerFilterRemove removes a rule added with UnsafePeerFilterAdd, or
+// from the configuration.
+func (env *Environment) UnsafePeerFilterRemove(
+	ctx context.Context,
+	action, kind, value string,
+) (*coretypes.ResultPeerFilter, error) {
+	if env.PeerFilterRules == nil {
+		return nil, errors.New("the peer filter is not available on this node")
+	}
+	rule := p2p.PeerFilterRule{Action: p2p.PeerFilterAction(action), Kind: p2p.PeerFilterKind(kind), Value: value}
+	removed, err := env.PeerFilterRules.RemoveRule(rule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", coretypes.ErrInvalidRequest, err)
+	}
+	if !removed {
+		return nil, fmt.Errorf("%w: no rule %q", coretypes.ErrInvalidRequest, rule)
+	}
+	return env.peerFilterResult(), nil
+}
+
+func (env *Environment) peerFilterResult() *coretypes.ResultPeerFilter {
+	rules := env.PeerFilterRules.Rules()
+	result := &coretypes.ResultPeerFilter{Rules: make([]coretypes.PeerFilterRule, 0, len(rules))}
+	for _, rule := range rules {
+		result.Rules = append(result.Rules, coretypes.PeerFilterRule{
+			Action: string(rule.Action),
+			Kind:   string(rule.Kind),
+			Value:  rule.Value,
+		})
+	}
+	return result
+}
+
+// ValidatorBook returns the nodes run by the current validators, as attested
+// in the handshakes with the peers, and whether they are connected.
+func (env *Environment) ValidatorBook(ctx context.Context) (*coretypes.ResultValidatorBook, error) {
+	if env.ValidatorIdentities == nil {
+		return nil, errors.New("the validator book is disabled on this node")
+	}
+
+	height := env.latestUncommittedHeight()
+	validators, err := env.StateStore.LoadValidators(height)
+	if err != nil {
+		return nil, err
+	}
+	connected := make(map[types.NodeID]bool)
+	for _, peerID := range env.PeerManager.Peers() {
+		connected[peerID] = true
+	}
+
+	nodes := make([]coretypes.ValidatorNode, 0, len(validators.Validators))
+	for _, val := range validators.Validators {
+		node := coretypes.ValidatorNode{Address: val.Address, VotingPower: val.VotingPower}
+		if identity, ok := env.ValidatorIdentities.Lookup(val.Address); ok {
+			attested := identity.Attested
+			node.NodeID = identity.NodeID
+			node.Attested = &attested
+			node.Connected = connected[identity.NodeID]
+		}
+		nodes = append(nodes, node)
+	}
+
+	return &coretypes.ResultValidatorBook{BlockHeight: height, Validators: nodes}, nil
+}
+
 // Genesis returns genesis file.
 // More: https://docs.tendermint.com/master/rpc/#/Info/genesis
 func (env *Environment) Genesis(ctx context.Context) (*coretypes.ResultGenesis, error) {
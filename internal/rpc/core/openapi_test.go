@@ -0,0 +1,38 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
+)
+
+func TestRoutesOpenAPI(t *testing.T) {
+	routes := NewRoutesMap(&Environment{}, &RouteOptions{Unsafe: true})
+	bz, err := rpcserver.OpenAPI(routes, "Tendermint RPC", "test")
+	require.NoError(t, err)
+
+	var doc struct {
+		Paths map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(bz, &doc))
+	for _, path := range []string{"/", "/status", "/block", "/tx_search", "/unsafe_flush_mempool"} {
+		assert.Contains(t, doc.Paths, path)
+	}
+	assert.NotContains(t, doc.Paths, "/subscribe")
+}
+
+func TestRoutesReadOnly(t *testing.T) {
+	routes := NewRoutesMap(&Environment{}, &RouteOptions{Unsafe: true, ReadOnly: true})
+	for _, name := range []string{"status", "block", "tx_search", "check_tx", "abci_query", "subscribe"} {
+		assert.Contains(t, routes, name)
+	}
+	for _, name := range append(mutatingRoutes, "unsafe_flush_mempool", "unsafe_capture_messages",
+		"unsafe_import_address_book", "unsafe_peer_filter_add", "unsafe_peer_filter_remove",
+		"unsafe_app_hash_mismatch") {
+		assert.NotContains(t, routes, name)
+	}
+}
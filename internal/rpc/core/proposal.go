@@ -0,0 +1,90 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+// gasWanter is implemented by mempools that record the gas wanted reported by
+// CheckTx for each transaction.
+type gasWanter interface {
+	GasWanted(types.TxKey) (int64, bool)
+}
+
+// SimulateProposal builds the block this node would propose at the next
+// height from the current state, mempool and evidence pool, the way consensus
+// does through PrepareProposal, without signing or broadcasting it. The block
+// is then run through ProcessProposal and the verdict of the application is
+// reported. The mempool is left untouched.
+//
+// The reported gas is the sum of the gas wanted returned by CheckTx for each
+// included transaction, which is the same quantity used to enforce the block
+// gas limit. Transactions added by the application in PrepareProposal are not
+// counted.
+func (env *Environment) SimulateProposal(ctx context.Context) (*coretypes.ResultSimulateProposal, error) {
+	if env.BlockExecutor == nil {
+		return nil, errors.New("proposal simulation is not available on this node")
+	}
+
+	state, err := env.StateStore.Load()
+	if err != nil {
+		return nil, err
+	}
+	if state.IsEmpty() {
+		return nil, errors.New("state is not initialized")
+	}
+
+	height := state.LastBlockHeight + 1
+
+	var commit *types.Commit
+	if height == state.InitialHeight {
+		commit = types.NewCommit(0, 0, types.BlockID{}, nil)
+	} else {
+		commit = env.BlockStore.LoadSeenCommit()
+		if commit == nil || commit.Height != state.LastBlockHeight {
+			return nil, fmt.Errorf("no commit available for height %d", state.LastBlockHeight)
+		}
+	}
+
+	var proposerAddr types.Address
+	if env.PubKey != nil {
+		proposerAddr = env.PubKey.Address()
+	} else {
+		proposerAddr = state.Validators.GetProposer().Address
+	}
+
+	block, _, err := env.BlockExecutor.CreateProposalBlock(ctx, height, state, commit, proposerAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make block: %w", err)
+	}
+
+	accepted, err := env.BlockExecutor.ProcessProposal(ctx, block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process block: %w", err)
+	}
+
+	var totalGas int64
+	if gw, ok := env.Mempool.(gasWanter); ok {
+		for _, tx := range block.Data.Txs {
+			if gas, ok := gw.GasWanted(tx.Key()); ok {
+				totalGas += gas
+			}
+		}
+	}
+
+	return &coretypes.ResultSimulateProposal{
+		Block:          block,
+		BlockSize:      block.Size(),
+		NumTxs:         len(block.Data.Txs),
+		NumEvidence:    len(block.Evidence.Evidence),
+		TotalGasWanted: totalGas,
+		MaxBytes:       state.ConsensusParams.Block.MaxBytes,
+		MaxGas:         state.ConsensusParams.Block.MaxGas,
+		PendingTxs:     env.Mempool.Size(),
+		Accepted:       accepted,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abciclient "github.com/tendermint/tendermint/abci/client"
+	"github.com/tendermint/tendermint/abci/example/kvstore"
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/mempool"
+	"github.com/tendermint/tendermint/internal/proxy"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// pickyApp drops the first transaction of the proposals it prepares and
+// rejects all the proposals it processes.
+type pickyApp struct {
+	*kvstore.Application
+}
+
+func (pickyApp) PrepareProposal(req abci.RequestPrepareProposal) abci.ResponsePrepareProposal {
+	return abci.ResponsePrepareProposal{Txs: req.Txs[1:]}
+}
+
+func (pickyApp) ProcessProposal(req abci.RequestProcessProposal) abci.ResponseProcessProposal {
+	return abci.ResponseProcessProposal{Status: abci.ResponseProcessProposal_REJECT}
+}
+
+func TestSimulateProposal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		app      abci.Application
+		numTxs   int
+		accepted bool
+	}{
+		{"accepted", kvstore.NewApplication(), 3, true},
+		{"prepared and rejected", pickyApp{kvstore.NewApplication()}, 2, false},
+	}
+
+	cfg, err := config.ResetTestRoot(t.Name())
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(cfg.RootDir) })
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			logger := log.TestingLogger()
+
+			genDoc, _ := factory.RandGenesisDoc(ctx, t, cfg, 1, false, 10)
+			state, err := sm.MakeGenesisState(genDoc)
+			require.NoError(t, err)
+
+			stateStore := sm.NewStore(dbm.NewMemDB())
+			require.NoError(t, stateStore.Save(state))
+			blockStore := store.NewBlockStore(dbm.NewMemDB())
+
+			cc := abciclient.NewLocalCreator(tc.app)
+			appConn, err := cc(logger)
+			require.NoError(t, err)
+			require.NoError(t, appConn.Start(ctx))
+			proxyApp := proxy.NewAppConns(cc, logger, proxy.NopMetrics())
+			require.NoError(t, proxyApp.Start(ctx))
+
+			mp := mempool.NewTxMempool(logger, cfg.Mempool, appConn, 0)
+			for _, tx := range []string{"a=1", "b=2", "c=3"} {
+				require.NoError(t, mp.CheckTx(ctx, []byte(tx), nil, mempool.TxInfo{}))
+			}
+
+			env := &Environment{
+				StateStore:   stateStore,
+				BlockStore:   blockStore,
+				EvidencePool: sm.EmptyEvidencePool{},
+				Mempool:      mp,
+				BlockExecutor: sm.NewBlockExecutor(stateStore, logger, proxyApp.Consensus(),
+					mp, sm.EmptyEvidencePool{}, blockStore),
+			}
+
+			res, err := env.SimulateProposal(ctx)
+			require.NoError(t, err)
+			require.Equal(t, genDoc.InitialHeight, res.Block.Height)
+			require.Equal(t, tc.numTxs, res.NumTxs)
+			require.EqualValues(t, tc.numTxs, res.TotalGasWanted)
+			require.Equal(t, res.Block.Size(), res.BlockSize)
+			require.Equal(t, state.Validators.GetProposer().Address, res.Block.ProposerAddress)
+			require.Equal(t, tc.accepted, res.Accepted)
+
+			// simulating a proposal does not remove transactions from the mempool
+			require.Equal(t, 3, mp.Size())
+		})
+	}
+}
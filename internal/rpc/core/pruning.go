@@ -0,0 +1,31 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tendermint/tendermint/rpc/coretypes"
+)
+
+// RetainHeight returns the retain heights of the pruner: the one requested by
+// the application, the ones of the evidence window and of the minimum number
+// of blocks to retain, and the resulting one, with the progress of the
+// pruning.
+// UNSTABLE
+func (env *Environment) RetainHeight(ctx context.Context) (*coretypes.ResultRetainHeight, error) {
+	if env.Pruner == nil {
+		return nil, errors.New("the pruner is disabled on this node")
+	}
+
+	status := env.Pruner.Status()
+	return &coretypes.ResultRetainHeight{
+		AppRetainHeight:      status.Application,
+		EvidenceRetainHeight: status.Evidence,
+		MinRetainHeight:      status.MinRetain,
+		RetainHeight:         status.Retain,
+		BlockBaseHeight:      status.BlockBase,
+		TxIndexBaseHeight:    status.TxIndexBase,
+		LastPruned:           status.LastPruned,
+		LastError:            status.LastError,
+	}, nil
+}
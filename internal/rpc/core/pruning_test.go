@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+)
+
+type staticPruner sm.PruningStatus
+
+func (p staticPruner) Status() sm.PruningStatus {
+	return sm.PruningStatus(p)
+}
+
+func TestRetainHeight(t *testing.T) {
+	env := &Environment{}
+	_, err := env.RetainHeight(context.Background())
+	require.Error(t, err)
+
+	pruned := time.Now()
+	env.Pruner = staticPruner{
+		RetainHeights: sm.RetainHeights{Application: 90, Evidence: 80, MinRetain: 70, Retain: 70},
+		BlockBase:     50,
+		TxIndexBase:   40,
+		LastPruned:    pruned,
+		LastError:     "failed",
+	}
+	res, err := env.RetainHeight(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &coretypes.ResultRetainHeight{
+		AppRetainHeight:      90,
+		EvidenceRetainHeight: 80,
+		MinRetainHeight:      70,
+		RetainHeight:         70,
+		BlockBaseHeight:      50,
+		TxIndexBaseHeight:    40,
+		LastPruned:           pruned,
+		LastError:            "failed",
+	}, res)
+}
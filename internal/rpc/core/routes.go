@@ -16,20 +16,35 @@ type RoutesMap map[string]*rpc.RPCFunc
 // RouteOptions provide optional settings to NewRoutesMap.  A nil *RouteOptions
 // is ready for use and provides defaults as specified.
 type RouteOptions struct {
-	Unsafe bool // include "unsafe" methods (default false)
+	Unsafe   bool // include "unsafe" methods (default false)
+	ReadOnly bool // exclude the mutating methods, even if Unsafe (default false)
+}
+
+// mutatingRoutes are the methods changing the state of the node, or of the
+// network: they are excluded in read-only mode, with the unsafe methods.
+var mutatingRoutes = []string{
+	"broadcast_tx_commit",
+	"broadcast_tx_sync",
+	"broadcast_tx_async",
+	"broadcast_evidence",
+	"remove_tx",
+	"validator_set_update",
 }
 
 // NewRoutesMap constructs an RPC routing map for the given service
 // implementation. If svc implements RPCUnsafe and opts.Unsafe is true, the
-// "unsafe" methods will also be added to the map. The caller may also edit the
-// map after construction; each call to NewRoutesMap returns a fresh map.
+// "unsafe" methods will also be added to the map. If opts.ReadOnly is true,
+// the methods submitting transactions or evidence, or otherwise mutating the
+// state, and the "unsafe" methods are left out of the map, so that they are
+// not found over HTTP and websocket. The caller may also edit the map after
+// construction; each call to NewRoutesMap returns a fresh map.
 func NewRoutesMap(svc RPCService, opts *RouteOptions) RoutesMap {
 	if opts == nil {
 		opts = new(RouteOptions)
 	}
 	out := RoutesMap{
 		// subscribe/unsubscribe are reserved for websocket events.
-		"subscribe":       rpc.NewWSRPCFunc(svc.Subscribe, "query"),
+		"subscribe":       rpc.NewWSRPCFunc(svc.Subscribe, "query", "after", "stats_interval").Subscription(),
 		"unsubscribe":     rpc.NewWSRPCFunc(svc.Unsubscribe, "query"),
 		"unsubscribe_all": rpc.NewWSRPCFunc(svc.UnsubscribeAll),
 
@@ -42,16 +57,16 @@ func NewRoutesMap(svc RPCService, opts *RouteOptions) RoutesMap {
 		"genesis_chunked":      rpc.NewRPCFunc(svc.GenesisChunked, "chunk"),
 		"header":               rpc.NewRPCFunc(svc.Header, "height"),
 		"header_by_hash":       rpc.NewRPCFunc(svc.HeaderByHash, "hash"),
-		"block":                rpc.NewRPCFunc(svc.Block, "height"),
+		"block":                rpc.NewRPCFunc(svc.Block, "height").Cacheable(),
 		"block_by_hash":        rpc.NewRPCFunc(svc.BlockByHash, "hash"),
-		"block_results":        rpc.NewRPCFunc(svc.BlockResults, "height"),
-		"commit":               rpc.NewRPCFunc(svc.Commit, "height"),
+		"block_results":        rpc.NewRPCFunc(svc.BlockResults, "height").Cacheable(),
+		"commit":               rpc.NewRPCFunc(svc.Commit, "height").Cacheable(),
 		"check_tx":             rpc.NewRPCFunc(svc.CheckTx, "tx"),
 		"remove_tx":            rpc.NewRPCFunc(svc.RemoveTx, "txkey"),
 		"tx":                   rpc.NewRPCFunc(svc.Tx, "hash", "prove"),
-		"tx_search":            rpc.NewRPCFunc(svc.TxSearch, "query", "prove", "page", "per_page", "order_by"),
-		"block_search":         rpc.NewRPCFunc(svc.BlockSearch, "query", "page", "per_page", "order_by"),
-		"validators":           rpc.NewRPCFunc(svc.Validators, "height", "page", "per_page"),
+		"tx_search":            rpc.NewRPCFunc(svc.TxSearch, "query", "prove", "page", "per_page", "order_by", "cursor"),
+		"block_search":         rpc.NewRPCFunc(svc.BlockSearch, "query", "page", "per_page", "order_by", "cursor"),
+		"validators":           rpc.NewRPCFunc(svc.Validators, "height", "page", "per_page").Cacheable(),
 		"dump_consensus_state": rpc.NewRPCFunc(svc.DumpConsensusState),
 		"consensus_state":      rpc.NewRPCFunc(svc.GetConsensusState),
 		"consensus_params":     rpc.NewRPCFunc(svc.ConsensusParams, "height"),
@@ -64,14 +79,93 @@ func NewRoutesMap(svc RPCService, opts *RouteOptions) RoutesMap {
 		"broadcast_tx_async":  rpc.NewRPCFunc(svc.BroadcastTxAsync, "tx"),
 
 		// abci API
-		"abci_query": rpc.NewRPCFunc(svc.ABCIQuery, "path", "data", "height", "prove"),
+		"abci_query": rpc.NewRPCFunc(svc.ABCIQuery, "path", "data", "height", "prove", "block_hash"),
 		"abci_info":  rpc.NewRPCFunc(svc.ABCIInfo),
 
 		// evidence API
 		"broadcast_evidence": rpc.NewRPCFunc(svc.BroadcastEvidence, "evidence"),
 	}
+	if ls, ok := svc.(RPCLabeledSubscriptions); ok {
+		out["subscribe_labeled"] = rpc.NewWSRPCFunc(ls.SubscribeLabeled, "label", "query", "credits", "buffer").Subscription()
+		out["grant_credits"] = rpc.NewWSRPCFunc(ls.GrantCredits, "label", "credits")
+		out["unsubscribe_labeled"] = rpc.NewWSRPCFunc(ls.UnsubscribeLabeled, "label")
+	}
+	if va, ok := svc.(RPCValidatorAuthority); ok {
+		out["validator_set_update"] = rpc.NewRPCFunc(va.ScheduleValidatorSetUpdate,
+			"effective_height", "updates", "signature")
+	}
+	if ps, ok := svc.(RPCPeerStats); ok {
+		out["peer_stats"] = rpc.NewRPCFunc(ps.PeerStats)
+	}
+	if ab, ok := svc.(RPCAddressBook); ok {
+		out["address_book"] = rpc.NewRPCFunc(ab.AddressBook)
+		out["peer_scores"] = rpc.NewRPCFunc(ab.PeerScores)
+	}
+	if pf, ok := svc.(RPCPeerFilter); ok {
+		out["peer_filter"] = rpc.NewRPCFunc(pf.PeerFilter)
+	}
+	if pb, ok := svc.(RPCPeerBandwidth); ok {
+		out["peer_bandwidth"] = rpc.NewRPCFunc(pb.PeerBandwidth)
+	}
+	if vb, ok := svc.(RPCValidatorBook); ok {
+		out["validator_book"] = rpc.NewRPCFunc(vb.ValidatorBook)
+	}
+	if tp, ok := svc.(RPCTxProof); ok {
+		out["tx_proof"] = rpc.NewRPCFunc(tp.TxProof, "hashes")
+	}
+	if vp, ok := svc.(RPCValidatorsProof); ok {
+		out["validators_proof"] = rpc.NewRPCFunc(vp.ValidatorsProof, "height").Cacheable()
+	}
+	if bu, ok := svc.(RPCBlockUtilization); ok {
+		out["block_utilization"] = rpc.NewRPCFunc(bu.BlockUtilization, "minHeight", "maxHeight")
+	}
+	if lh, ok := svc.(RPCLockHistory); ok {
+		out["lock_history"] = rpc.NewRPCFunc(lh.LockHistory)
+	}
+	if ph, ok := svc.(RPCConsensusParamsHistory); ok {
+		out["consensus_params_history"] = rpc.NewRPCFunc(ph.ConsensusParamsHistory, "height")
+	}
+	if ss, ok := svc.(RPCSnapshots); ok {
+		out["snapshots"] = rpc.NewRPCFunc(ss.Snapshots)
+	}
+	if rh, ok := svc.(RPCRetainHeight); ok {
+		out["retain_height"] = rpc.NewRPCFunc(rh.RetainHeight)
+	}
+	if sv, ok := svc.(RPCVerifiedStatus); ok {
+		out["status_verified"] = rpc.NewRPCFunc(sv.StatusVerified)
+	}
+	if mi, ok := svc.(RPCMempoolInspector); ok {
+		out["mempool_txs"] = rpc.NewRPCFunc(mi.MempoolTxs, "sender", "page", "per_page")
+	}
+	if ts, ok := svc.(RPCTxStatus); ok {
+		out["tx_status"] = rpc.NewRPCFunc(ts.TxStatus, "hash")
+	}
+	if ps, ok := svc.(RPCProposalSimulator); ok {
+		out["simulate_proposal"] = rpc.NewRPCFunc(ps.SimulateProposal)
+	}
+	if opts.ReadOnly {
+		for _, name := range mutatingRoutes {
+			delete(out, name)
+		}
+		return out
+	}
 	if u, ok := svc.(RPCUnsafe); ok && opts.Unsafe {
 		out["unsafe_flush_mempool"] = rpc.NewRPCFunc(u.UnsafeFlushMempool)
+		out["unsafe_capture_messages"] = rpc.NewRPCFunc(u.UnsafeCaptureMessages, "peer_id", "channel", "seconds", "redact")
+	}
+	if md, ok := svc.(RPCMempoolDump); ok && opts.Unsafe {
+		out["unsafe_dump_mempool"] = rpc.NewRPCFunc(md.UnsafeDumpMempool)
+		out["unsafe_restore_mempool"] = rpc.NewRPCFunc(md.UnsafeRestoreMempool, "file")
+	}
+	if ab, ok := svc.(RPCAddressBook); ok && opts.Unsafe {
+		out["unsafe_import_address_book"] = rpc.NewRPCFunc(ab.UnsafeImportAddressBook, "peers")
+	}
+	if pf, ok := svc.(RPCPeerFilter); ok && opts.Unsafe {
+		out["unsafe_peer_filter_add"] = rpc.NewRPCFunc(pf.UnsafePeerFilterAdd, "action", "kind", "value")
+		out["unsafe_peer_filter_remove"] = rpc.NewRPCFunc(pf.UnsafePeerFilterRemove, "action", "kind", "value")
+	}
+	if f, ok := svc.(RPCForensics); ok && opts.Unsafe {
+		out["unsafe_app_hash_mismatch"] = rpc.NewRPCFunc(f.UnsafeAppHashMismatch, "height")
 	}
 	return out
 }
@@ -80,11 +174,11 @@ func NewRoutesMap(svc RPCService, opts *RouteOptions) RoutesMap {
 // implementation, for use in constructing a routing table.
 type RPCService interface {
 	ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error)
-	ABCIQuery(ctx context.Context, path string, data bytes.HexBytes, height int64, prove bool) (*coretypes.ResultABCIQuery, error)
+	ABCIQuery(ctx context.Context, path string, data bytes.HexBytes, height int64, prove bool, blockHash bytes.HexBytes) (*coretypes.ResultABCIQuery, error)
 	Block(ctx context.Context, heightPtr *int64) (*coretypes.ResultBlock, error)
 	BlockByHash(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultBlock, error)
 	BlockResults(ctx context.Context, heightPtr *int64) (*coretypes.ResultBlockResults, error)
-	BlockSearch(ctx context.Context, query string, pagePtr, perPagePtr *int, orderBy string) (*coretypes.ResultBlockSearch, error)
+	BlockSearch(ctx context.Context, query string, pagePtr, perPagePtr *int, orderBy, cursor string) (*coretypes.ResultBlockSearch, error)
 	BlockchainInfo(ctx context.Context, minHeight, maxHeight int64) (*coretypes.ResultBlockchainInfo, error)
 	BroadcastEvidence(ctx context.Context, ev coretypes.Evidence) (*coretypes.ResultBroadcastEvidence, error)
 	BroadcastTxAsync(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTx, error)
@@ -104,17 +198,155 @@ type RPCService interface {
 	NumUnconfirmedTxs(ctx context.Context) (*coretypes.ResultUnconfirmedTxs, error)
 	RemoveTx(ctx context.Context, txkey types.TxKey) error
 	Status(ctx context.Context) (*coretypes.ResultStatus, error)
-	Subscribe(ctx context.Context, query string) (*coretypes.ResultSubscribe, error)
+	Subscribe(ctx context.Context, query string, after uint64, statsInterval int64) (*coretypes.ResultSubscribe, error)
 	Tx(ctx context.Context, hash bytes.HexBytes, prove bool) (*coretypes.ResultTx, error)
-	TxSearch(ctx context.Context, query string, prove bool, pagePtr, perPagePtr *int, orderBy string) (*coretypes.ResultTxSearch, error)
+	TxSearch(ctx context.Context, query string, prove bool, pagePtr, perPagePtr *int, orderBy, cursor string) (*coretypes.ResultTxSearch, error)
 	UnconfirmedTxs(ctx context.Context, page, perPage *int) (*coretypes.ResultUnconfirmedTxs, error)
 	Unsubscribe(ctx context.Context, query string) (*coretypes.ResultUnsubscribe, error)
 	UnsubscribeAll(ctx context.Context) (*coretypes.ResultUnsubscribe, error)
 	Validators(ctx context.Context, heightPtr *int64, pagePtr, perPagePtr *int) (*coretypes.ResultValidators, error)
 }
 
+// RPCLabeledSubscriptions defines the websocket methods multiplexing labeled,
+// flow-controlled subscriptions over one connection. It is optionally
+// implemented by the RPC service.
+type RPCLabeledSubscriptions interface {
+	SubscribeLabeled(ctx context.Context, label, query string, credits, buffer int64) (*coretypes.ResultSubscribeLabeled, error)
+	GrantCredits(ctx context.Context, label string, credits int64) (*coretypes.ResultGrantCredits, error)
+	UnsubscribeLabeled(ctx context.Context, label string) (*coretypes.ResultUnsubscribe, error)
+}
+
+// RPCValidatorAuthority defines the methods used by an external validator
+// authority to govern the validator set. It is optionally implemented by the
+// RPC service.
+type RPCValidatorAuthority interface {
+	ScheduleValidatorSetUpdate(ctx context.Context, effectiveHeight int64, updates []*types.Validator, signature bytes.HexBytes) (*coretypes.ResultValidatorSetUpdate, error)
+}
+
+// RPCPeerStats defines the methods exposing lifetime peer statistics. It is
+// optionally implemented by the RPC service.
+type RPCPeerStats interface {
+	PeerStats(ctx context.Context) (*coretypes.ResultPeerStats, error)
+}
+
+// RPCAddressBook defines the methods exporting, importing and scoring the
+// peers of the peer store. It is optionally implemented by the RPC service.
+type RPCAddressBook interface {
+	AddressBook(ctx context.Context) (*coretypes.ResultAddressBook, error)
+	UnsafeImportAddressBook(ctx context.Context, peers []coretypes.AddressBookPeer) (*coretypes.ResultImportAddressBook, error)
+	PeerScores(ctx context.Context) (*coretypes.ResultPeerScores, error)
+}
+
+// RPCPeerFilter defines the methods listing and changing the rules allowing
+// and denying the peers. It is optionally implemented by the RPC service.
+type RPCPeerFilter interface {
+	PeerFilter(ctx context.Context) (*coretypes.ResultPeerFilter, error)
+	UnsafePeerFilterAdd(ctx context.Context, action, kind, value string) (*coretypes.ResultPeerFilter, error)
+	UnsafePeerFilterRemove(ctx context.Context, action, kind, value string) (*coretypes.ResultPeerFilter, error)
+}
+
+// RPCForensics defines the method returning the forensic bundles captured on
+// the app hash mismatches. It is optionally implemented by the RPC service.
+type RPCForensics interface {
+	UnsafeAppHashMismatch(ctx context.Context, heightPtr *int64) (*coretypes.ResultAppHashMismatch, error)
+}
+
+// RPCPeerBandwidth defines the methods exposing the bandwidth used by the
+// connected peers. It is optionally implemented by the RPC service.
+type RPCPeerBandwidth interface {
+	PeerBandwidth(ctx context.Context) (*coretypes.ResultPeerBandwidth, error)
+}
+
+// RPCValidatorBook defines the methods exposing the nodes run by the
+// validators. It is optionally implemented by the RPC service.
+type RPCValidatorBook interface {
+	ValidatorBook(ctx context.Context) (*coretypes.ResultValidatorBook, error)
+}
+
+// RPCTxProof defines the methods proving several transactions of a block at
+// once. It is optionally implemented by the RPC service.
+type RPCTxProof interface {
+	TxProof(ctx context.Context, hashes []bytes.HexBytes) (*coretypes.ResultTxProof, error)
+}
+
+// RPCValidatorsProof defines the methods proving the validators of a height
+// against the validators hash of its header. It is optionally implemented by
+// the RPC service.
+type RPCValidatorsProof interface {
+	ValidatorsProof(ctx context.Context, heightPtr *int64) (*coretypes.ResultValidatorsProof, error)
+}
+
+// RPCBlockUtilization defines the methods reporting how much of the allowed
+// block size and gas blocks use. It is optionally implemented by the RPC
+// service.
+type RPCBlockUtilization interface {
+	BlockUtilization(ctx context.Context, minHeight, maxHeight int64) (*coretypes.ResultBlockUtilization, error)
+}
+
+// RPCLockHistory defines the method reporting the recent changes of the
+// locked and valid blocks of consensus. It is optionally implemented by the
+// RPC service.
+type RPCLockHistory interface {
+	LockHistory(ctx context.Context) (*coretypes.ResultLockHistory, error)
+}
+
+// RPCConsensusParamsHistory defines the method reporting the changes of the
+// consensus params up to a height. It is optionally implemented by the RPC
+// service.
+type RPCConsensusParamsHistory interface {
+	ConsensusParamsHistory(ctx context.Context, heightPtr *int64) (*coretypes.ResultConsensusParamsHistory, error)
+}
+
+// RPCSnapshots defines the method reporting the snapshots recorded by the
+// snapshot service. It is optionally implemented by the RPC service.
+type RPCSnapshots interface {
+	Snapshots(ctx context.Context) (*coretypes.ResultSnapshots, error)
+}
+
+// RPCRetainHeight defines the method reporting the retain heights of the
+// pruner. It is optionally implemented by the RPC service.
+type RPCRetainHeight interface {
+	RetainHeight(ctx context.Context) (*coretypes.ResultRetainHeight, error)
+}
+
+// RPCMempoolInspector defines the method reporting the metadata of the
+// unconfirmed transactions, for mempool monitoring. It is optionally
+// implemented by the RPC service.
+type RPCMempoolInspector interface {
+	MempoolTxs(ctx context.Context, sender string, page, perPage *int) (*coretypes.ResultMempoolTxs, error)
+}
+
+// RPCTxStatus defines the method reporting the status of a transaction,
+// whether in the mempool, committed or evicted. It is optionally implemented
+// by the RPC service.
+type RPCTxStatus interface {
+	TxStatus(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultTxStatus, error)
+}
+
+// RPCProposalSimulator defines the methods used to preview the block a node
+// would propose. It is optionally implemented by the RPC service.
+type RPCProposalSimulator interface {
+	SimulateProposal(ctx context.Context) (*coretypes.ResultSimulateProposal, error)
+}
+
+// RPCVerifiedStatus defines the method reporting the latest block verified by
+// a light client, cross-checked with its witnesses. It is optionally
+// implemented by the RPC service.
+type RPCVerifiedStatus interface {
+	StatusVerified(ctx context.Context) (*coretypes.ResultStatusVerified, error)
+}
+
+// RPCMempoolDump defines the "unsafe" methods dumping the mempool into a file,
+// and restoring a dump into the mempool. It is optionally implemented by the
+// RPC service.
+type RPCMempoolDump interface {
+	UnsafeDumpMempool(ctx context.Context) (*coretypes.ResultDumpMempool, error)
+	UnsafeRestoreMempool(ctx context.Context, file string) (*coretypes.ResultRestoreMempool, error)
+}
+
 // RPCUnsafe defines the set of "unsafe" methods that may optionally be
 // exported by the RPC service.
 type RPCUnsafe interface {
 	UnsafeFlushMempool(ctx context.Context) (*coretypes.ResultUnsafeFlushMempool, error)
+	UnsafeCaptureMessages(ctx context.Context, peerID string, channel, seconds int, redact string) (*coretypes.ResultCaptureMessages, error)
 }
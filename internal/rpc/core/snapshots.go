@@ -0,0 +1,43 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/tendermint/tendermint/rpc/coretypes"
+)
+
+// Snapshots returns the snapshots of the application recorded by the snapshot
+// service, most recent first, with the sizes and hashes of their chunks.
+// UNSTABLE
+func (env *Environment) Snapshots(ctx context.Context) (*coretypes.ResultSnapshots, error) {
+	if env.SnapshotService == nil {
+		return nil, errors.New("the snapshot service is disabled on this node")
+	}
+
+	status, err := env.SnapshotService.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &coretypes.ResultSnapshots{
+		Snapshots:   make([]coretypes.SnapshotInfo, 0, len(status.Snapshots)),
+		LastRefresh: status.LastRefresh,
+		LastError:   status.LastError,
+	}
+	for _, s := range status.Snapshots {
+		info := coretypes.SnapshotInfo{
+			Height:     s.Height,
+			Format:     s.Format,
+			Hash:       s.Hash,
+			Metadata:   s.Metadata,
+			Chunks:     make([]coretypes.SnapshotChunkInfo, 0, len(s.Chunks)),
+			RecordedAt: s.RecordedAt,
+		}
+		for _, c := range s.Chunks {
+			info.Chunks = append(info.Chunks, coretypes.SnapshotChunkInfo{Size: c.Size, Hash: c.Hash})
+		}
+		result.Snapshots = append(result.Snapshots, info)
+	}
+	return result, nil
+}
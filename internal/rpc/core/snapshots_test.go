@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/internal/statesync"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+)
+
+type staticSnapshotService statesync.SnapshotStatus
+
+func (s staticSnapshotService) Status() (statesync.SnapshotStatus, error) {
+	return statesync.SnapshotStatus(s), nil
+}
+
+func TestSnapshots(t *testing.T) {
+	env := &Environment{}
+	_, err := env.Snapshots(context.Background())
+	require.Error(t, err)
+
+	refreshed := time.Now()
+	recorded := refreshed.Add(-time.Hour)
+	env.SnapshotService = staticSnapshotService{
+		Snapshots: []statesync.SnapshotRecord{{
+			Height:     10,
+			Format:     1,
+			Hash:       []byte{1},
+			Chunks:     []statesync.SnapshotChunk{{Size: 3, Hash: []byte{2}}},
+			RecordedAt: recorded,
+		}},
+		LastRefresh: refreshed,
+	}
+	res, err := env.Snapshots(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, &coretypes.ResultSnapshots{
+		Snapshots: []coretypes.SnapshotInfo{{
+			Height:     10,
+			Format:     1,
+			Hash:       []byte{1},
+			Chunks:     []coretypes.SnapshotChunkInfo{{Size: 3, Hash: []byte{2}}},
+			RecordedAt: recorded,
+		}},
+		LastRefresh: refreshed,
+	}, res)
+}
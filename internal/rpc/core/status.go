@@ -3,8 +3,11 @@ package core
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/tendermint/tendermint/internal/features"
 	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/rpc/coretypes"
 	"github.com/tendermint/tendermint/types"
@@ -78,6 +81,14 @@ func (env *Environment) Status(ctx context.Context) (*coretypes.ResultStatus, er
 		ValidatorInfo: validatorInfo,
 	}
 
+	for _, flag := range features.Declared() {
+		result.Features = append(result.Features, coretypes.FeatureFlag{
+			Name:    flag.Name,
+			Stage:   string(flag.Stage),
+			Enabled: env.Features.Enabled(flag),
+		})
+	}
+
 	if env.StateSyncMetricer != nil {
 		result.SyncInfo.TotalSnapshots = env.StateSyncMetricer.TotalSnapshots()
 		result.SyncInfo.ChunkProcessAvgTime = env.StateSyncMetricer.ChunkProcessAvgTime()
@@ -88,9 +99,41 @@ func (env *Environment) Status(ctx context.Context) (*coretypes.ResultStatus, er
 		result.SyncInfo.BackFillBlocksTotal = env.StateSyncMetricer.BackFillBlocksTotal()
 	}
 
+	if env.ForkDetector != nil {
+		for _, fork := range env.ForkDetector.Forks() {
+			result.Warnings = append(result.Warnings, forkWarning(fork))
+		}
+	}
+
 	return result, nil
 }
 
+// forkWarning describes the branches of a fork for the status warnings.
+func forkWarning(fork types.EventDataForkDetected) string {
+	var local string
+	branches := make([]string, 0, len(fork.Branches))
+	for _, b := range fork.Branches {
+		desc := fmt.Sprintf("%X (%d peers", b.BlockParts.Hash, len(b.Peers))
+		if b.Local {
+			desc += ", this node"
+		}
+		if b.Majority {
+			desc += ", majority"
+		}
+		branches = append(branches, desc+")")
+	}
+	switch {
+	case !fork.Branches[0].Majority:
+		local = "no branch has a majority"
+	case fork.Branches[0].Local:
+		local = "this node is on the majority branch"
+	default:
+		local = "this node is NOT on the majority branch"
+	}
+	return fmt.Sprintf("peers committed different blocks at height %d: %s; %s",
+		fork.Height, strings.Join(branches, ", "), local)
+}
+
 func (env *Environment) validatorAtHeight(h int64) *types.Validator {
 	valsWithH, err := env.StateStore.LoadValidators(h)
 	if err != nil {
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"sort"
 
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/internal/mempool"
 	tmquery "github.com/tendermint/tendermint/internal/pubsub/query"
 	"github.com/tendermint/tendermint/internal/state/indexer"
 	"github.com/tendermint/tendermint/libs/bytes"
@@ -59,8 +62,74 @@ func (env *Environment) Tx(ctx context.Context, hash bytes.HexBytes, prove bool)
 	return nil, fmt.Errorf("transaction querying is disabled on this node due to the KV event sink being disabled")
 }
 
+// mempoolTxStatus is implemented by mempools that report the status of a
+// transaction: its rank if pending, or its eviction.
+type mempoolTxStatus interface {
+	PendingTx(key types.TxKey) (mempool.TxDetails, int, bool)
+	EvictedTx(key types.TxKey) (mempool.TxEviction, bool)
+}
+
+// TxStatus reports the status of the transaction with the given hash: whether
+// it is committed, according to the KV event sink, pending in the mempool, or
+// evicted from it, according to its eviction journal. The status is unknown
+// otherwise, which is also the case of the committed transactions if the KV
+// event sink is disabled.
+// More: https://docs.tendermint.com/master/rpc/#/Info/tx_status
+func (env *Environment) TxStatus(ctx context.Context, hash bytes.HexBytes) (*coretypes.ResultTxStatus, error) {
+	if len(hash) != tmhash.Size {
+		return nil, fmt.Errorf("invalid tx hash %X: expected %d bytes", hash, tmhash.Size)
+	}
+	res := &coretypes.ResultTxStatus{Hash: hash, Status: coretypes.TxStatusUnknown}
+
+	for _, sink := range env.EventSinks {
+		if sink.Type() != indexer.KV {
+			continue
+		}
+		r, err := sink.GetTxByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			res.Status = coretypes.TxStatusCommitted
+			res.Committed = &coretypes.CommittedTxStatus{
+				Height:   r.Height,
+				Index:    r.Index,
+				TxResult: r.Result,
+			}
+			return res, nil
+		}
+	}
+
+	mp, ok := env.Mempool.(mempoolTxStatus)
+	if !ok {
+		return res, nil
+	}
+	var key types.TxKey
+	copy(key[:], hash)
+	if details, rank, ok := mp.PendingTx(key); ok {
+		res.Status = coretypes.TxStatusPending
+		res.Pending = &coretypes.PendingTxStatus{
+			Rank:       rank,
+			Priority:   details.Priority,
+			Height:     details.Height,
+			ReceivedAt: details.Timestamp,
+		}
+	} else if eviction, ok := mp.EvictedTx(key); ok {
+		res.Status = coretypes.TxStatusEvicted
+		res.Evicted = &coretypes.EvictedTxStatus{
+			Reason:    eviction.Reason,
+			EvictedAt: eviction.Time,
+			Height:    eviction.Height,
+		}
+	}
+	return res, nil
+}
+
 // TxSearch allows you to query for multiple transactions results. It returns a
-// list of transactions (maximum ?per_page entries) and the total count.
+// list of transactions (maximum ?per_page entries) and the total count, with
+// the cursor of the next page if there is one. If a cursor is given, the
+// transactions following it are returned instead of a page, with the number of
+// transactions following it as the total count.
 // More: https://docs.tendermint.com/master/rpc/#/Info/tx_search
 func (env *Environment) TxSearch(
 	ctx context.Context,
@@ -68,6 +137,7 @@ func (env *Environment) TxSearch(
 	prove bool,
 	pagePtr, perPagePtr *int,
 	orderBy string,
+	cursor string,
 ) (*coretypes.ResultTxSearch, error) {
 
 	if !indexer.KVSinkEnabled(env.EventSinks) {
@@ -83,6 +153,10 @@ func (env *Environment) TxSearch(
 
 	for _, sink := range env.EventSinks {
 		if sink.Type() == indexer.KV {
+			if cursor != "" {
+				return env.txSearchAfter(ctx, sink, q, prove, pagePtr, perPagePtr, orderBy, cursor)
+			}
+
 			results, err := sink.SearchTxEvents(ctx, q)
 			if err != nil {
 				return nil, err
@@ -110,9 +184,12 @@ func (env *Environment) TxSearch(
 
 			// paginate results
 			totalCount := len(results)
-			perPage := env.validatePerPage(perPagePtr)
+			perPage, err := env.validatePerPage("tx_search", perPagePtr)
+			if err != nil {
+				return nil, err
+			}
 
-			page, err := validatePage(pagePtr, perPage, totalCount)
+			page, err := coretypes.ValidatePage(pagePtr, perPage, totalCount)
 			if err != nil {
 				return nil, err
 			}
@@ -122,27 +199,142 @@ func (env *Environment) TxSearch(
 
 			apiResults := make([]*coretypes.ResultTx, 0, pageSize)
 			for i := skipCount; i < skipCount+pageSize; i++ {
-				r := results[i]
+				apiResults = append(apiResults, env.resultTx(results[i], prove))
+			}
+
+			res := &coretypes.ResultTxSearch{Txs: apiResults, TotalCount: totalCount}
+			if skipCount+pageSize < totalCount {
+				res.NextCursor = txCursor(apiResults)
+			}
+			return res, nil
+		}
+	}
+
+	return nil, fmt.Errorf("transaction searching is disabled on this node due to the KV event sink being disabled")
+}
+
+// txSearchAfter returns the results of a transaction search following the
+// given cursor.
+func (env *Environment) txSearchAfter(
+	ctx context.Context,
+	sink indexer.EventSink,
+	q *tmquery.Query,
+	prove bool,
+	pagePtr, perPagePtr *int,
+	orderBy string,
+	cursor string,
+) (*coretypes.ResultTxSearch, error) {
+	csink, ok := sink.(indexer.CursorSearchSink)
+	if !ok {
+		return nil, fmt.Errorf("the event sink does not support cursors: %w", coretypes.ErrInvalidRequest)
+	}
 
-				var proof types.TxProof
-				if prove {
-					block := env.BlockStore.LoadBlock(r.Height)
-					proof = block.Data.Txs.Proof(int(r.Index)) // XXX: overflow on 32-bit machines
+	after, err := parseCursor(pagePtr, cursor)
+	if err != nil {
+		return nil, err
+	}
+	var desc bool
+	switch orderBy {
+	case "desc", "":
+		desc = true
+	case "asc":
+	default:
+		return nil, fmt.Errorf("expected order_by to be either `asc` or `desc` or empty: %w", coretypes.ErrInvalidRequest)
+	}
+	perPage, err := env.validatePerPage("tx_search", perPagePtr)
+	if err != nil {
+		return nil, err
+	}
+
+	results, remaining, err := csink.SearchTxEventsAfter(ctx, q, &after, desc, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	apiResults := make([]*coretypes.ResultTx, 0, len(results))
+	for _, r := range results {
+		apiResults = append(apiResults, env.resultTx(r, prove))
+	}
+
+	res := &coretypes.ResultTxSearch{Txs: apiResults, TotalCount: remaining}
+	if len(results) < remaining {
+		res.NextCursor = txCursor(apiResults)
+	}
+	return res, nil
+}
+
+// resultTx returns the RPC result of an indexed transaction.
+func (env *Environment) resultTx(r *abci.TxResult, prove bool) *coretypes.ResultTx {
+	var proof types.TxProof
+	if prove {
+		block := env.BlockStore.LoadBlock(r.Height)
+		proof = block.Data.Txs.Proof(int(r.Index)) // XXX: overflow on 32-bit machines
+	}
+
+	return &coretypes.ResultTx{
+		Hash:     types.Tx(r.Tx).Hash(),
+		Height:   r.Height,
+		Index:    r.Index,
+		TxResult: r.Result,
+		Tx:       r.Tx,
+		Proof:    proof,
+	}
+}
+
+// txCursor returns the cursor following the last of txs, or "" if txs is
+// empty.
+func txCursor(txs []*coretypes.ResultTx) string {
+	if len(txs) == 0 {
+		return ""
+	}
+	last := txs[len(txs)-1]
+	return indexer.Cursor{Height: last.Height, Index: last.Index}.String()
+}
+
+// maxTxProofTxs is the maximum number of transactions proven by one TxProof
+// call.
+const maxTxProofTxs = 100
+
+// TxProof returns a single proof of the inclusion of several transactions of
+// the same block, which is smaller than their individual proofs combined.
+func (env *Environment) TxProof(ctx context.Context, hashes []bytes.HexBytes) (*coretypes.ResultTxProof, error) {
+	if len(hashes) == 0 || len(hashes) > maxTxProofTxs {
+		return nil, fmt.Errorf("expected between 1 and %d hashes, got %d: %w",
+			maxTxProofTxs, len(hashes), coretypes.ErrInvalidRequest)
+	}
+
+	for _, sink := range env.EventSinks {
+		if sink.Type() == indexer.KV {
+			var height int64
+			indices := make([]int, len(hashes))
+			for i, hash := range hashes {
+				r, err := sink.GetTxByHash(hash)
+				if r == nil {
+					return nil, fmt.Errorf("tx (%X) not found, err: %w", hash, err)
+				}
+				if i > 0 && r.Height != height {
+					return nil, fmt.Errorf("txs are in different blocks (%d and %d): %w",
+						height, r.Height, coretypes.ErrInvalidRequest)
 				}
+				height = r.Height
+				indices[i] = int(r.Index) // XXX: overflow on 32-bit machines
+			}
 
-				apiResults = append(apiResults, &coretypes.ResultTx{
-					Hash:     types.Tx(r.Tx).Hash(),
-					Height:   r.Height,
-					Index:    r.Index,
-					TxResult: r.Result,
-					Tx:       r.Tx,
-					Proof:    proof,
-				})
+			block := env.BlockStore.LoadBlock(height)
+			if block == nil {
+				return nil, fmt.Errorf("block at height %d not found", height)
+			}
+			proof, err := block.Data.Txs.MultiProof(indices)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", err, coretypes.ErrInvalidRequest)
 			}
 
-			return &coretypes.ResultTxSearch{Txs: apiResults, TotalCount: totalCount}, nil
+			return &coretypes.ResultTxProof{
+				Height: height,
+				Proof:  proof,
+			}, nil
 		}
 	}
 
-	return nil, fmt.Errorf("transaction searching is disabled on this node due to the KV event sink being disabled")
+	return nil, fmt.Errorf("transaction querying is disabled on this node due to the KV event sink being disabled")
 }
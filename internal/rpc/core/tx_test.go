@@ -0,0 +1,195 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/mempool"
+	mempoolmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestSearchCursors(t *testing.T) {
+	ctx := context.Background()
+	sink := kv.NewEventSink(dbm.NewMemDB())
+	blockStore := &mocks.BlockStore{}
+	env := &Environment{EventSinks: []indexer.EventSink{sink}, BlockStore: blockStore}
+
+	// Two txs in each of the blocks 1 to 5.
+	events := func(typ string) []abci.Event {
+		return []abci.Event{{Type: typ, Attributes: []abci.EventAttribute{
+			{Key: "creator", Value: "alice", Index: true},
+		}}}
+	}
+	for h := int64(1); h <= 5; h++ {
+		require.NoError(t, sink.IndexBlockEvents(types.EventDataNewBlockHeader{
+			Header:         types.Header{Height: h},
+			ResultEndBlock: abci.ResponseEndBlock{Events: events("block")},
+		}))
+		var txs []*abci.TxResult
+		for i := uint32(0); i < 2; i++ {
+			txs = append(txs, &abci.TxResult{
+				Height: h,
+				Index:  i,
+				Tx:     types.Tx(fmt.Sprintf("tx-%d-%d", h, i)),
+				Result: abci.ResponseDeliverTx{Events: events("app")},
+			})
+		}
+		require.NoError(t, sink.IndexTxEvents(txs))
+
+		block := &types.Block{Header: types.Header{Height: h}}
+		blockStore.On("LoadBlock", h).Return(block)
+		blockStore.On("LoadBlockMeta", h).Return(&types.BlockMeta{Header: block.Header})
+	}
+
+	perPage := 3
+	txSearch := func(page *int, orderBy, cursor string) *coretypes.ResultTxSearch {
+		res, err := env.TxSearch(ctx, "app.creator = 'alice'", false, page, &perPage, orderBy, cursor)
+		require.NoError(t, err)
+		return res
+	}
+
+	// The cursor of a page leads to the next one.
+	page := 2
+	res := txSearch(&page, "asc", "")
+	assert.Equal(t, 10, res.TotalCount)
+	require.Len(t, res.Txs, 3)
+	assert.Equal(t, int64(3), res.Txs[2].Height)
+	assert.Equal(t, uint32(1), res.Txs[2].Index)
+	require.NotEmpty(t, res.NextCursor)
+
+	var heights []int64
+	for cursor := res.NextCursor; cursor != ""; cursor = res.NextCursor {
+		res = txSearch(nil, "asc", cursor)
+		for _, tx := range res.Txs {
+			heights = append(heights, tx.Height)
+		}
+	}
+	assert.Equal(t, []int64{4, 4, 5, 5}, heights)
+	assert.Equal(t, 1, res.TotalCount)
+
+	res = txSearch(nil, "desc", indexer.Cursor{Height: 2, Index: 1}.String())
+	assert.Equal(t, 3, res.TotalCount)
+	require.Len(t, res.Txs, 3)
+	assert.Equal(t, []string{"tx-2-0", "tx-1-1", "tx-1-0"},
+		[]string{string(res.Txs[0].Tx), string(res.Txs[1].Tx), string(res.Txs[2].Tx)})
+	assert.Empty(t, res.NextCursor)
+
+	// The blocks too.
+	heights = nil
+	cursor := ""
+	for {
+		res, err := env.BlockSearch(ctx, "block.creator = 'alice'", nil, &perPage, "desc", cursor)
+		require.NoError(t, err)
+		for _, block := range res.Blocks {
+			heights = append(heights, block.Block.Height)
+		}
+		if cursor = res.NextCursor; cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, []int64{5, 4, 3, 2, 1}, heights)
+
+	// A cursor cannot be combined with a page, and must be valid.
+	_, err := env.TxSearch(ctx, "app.creator = 'alice'", false, &page, &perPage, "asc", res.NextCursor+"x")
+	assert.True(t, errors.Is(err, coretypes.ErrInvalidRequest), err)
+	_, err = env.BlockSearch(ctx, "block.creator = 'alice'", &page, &perPage, "asc", indexer.Cursor{Height: 1}.String())
+	assert.True(t, errors.Is(err, coretypes.ErrInvalidRequest), err)
+	_, err = env.TxSearch(ctx, "app.creator = 'alice'", false, nil, &perPage, "asc", "invalid")
+	assert.True(t, errors.Is(err, coretypes.ErrInvalidRequest), err)
+}
+
+// statusMempool reports the pending and evicted transactions it is given.
+type statusMempool struct {
+	mempoolmock.Mempool
+	pending map[types.TxKey]mempool.TxDetails
+	evicted map[types.TxKey]mempool.TxEviction
+}
+
+func (m *statusMempool) PendingTx(key types.TxKey) (mempool.TxDetails, int, bool) {
+	details, ok := m.pending[key]
+	return details, 3, ok
+}
+
+func (m *statusMempool) EvictedTx(key types.TxKey) (mempool.TxEviction, bool) {
+	eviction, ok := m.evicted[key]
+	return eviction, ok
+}
+
+func TestTxStatus(t *testing.T) {
+	ctx := context.Background()
+	sink := kv.NewEventSink(dbm.NewMemDB())
+	committed, pending, evicted := types.Tx("committed"), types.Tx("pending"), types.Tx("evicted")
+	require.NoError(t, sink.IndexTxEvents([]*abci.TxResult{{
+		Height: 5,
+		Index:  1,
+		Tx:     committed,
+		Result: abci.ResponseDeliverTx{Code: 7},
+	}}))
+
+	received, evictedAt := time.Now().Add(-time.Minute), time.Now()
+	mp := &statusMempool{
+		pending: map[types.TxKey]mempool.TxDetails{
+			pending.Key(): {Hash: pending.Key(), Priority: 10, Height: 4, Timestamp: received},
+			// committed, but not yet removed from the mempool
+			committed.Key(): {Hash: committed.Key()},
+		},
+		evicted: map[types.TxKey]mempool.TxEviction{
+			evicted.Key(): {Reason: mempool.EvictedMempoolFull, Time: evictedAt, Height: 4},
+		},
+	}
+	env := &Environment{EventSinks: []indexer.EventSink{sink}, Mempool: mp}
+
+	res, err := env.TxStatus(ctx, committed.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, coretypes.TxStatusCommitted, res.Status)
+	require.NotNil(t, res.Committed)
+	assert.Equal(t, int64(5), res.Committed.Height)
+	assert.Equal(t, uint32(1), res.Committed.Index)
+	assert.Equal(t, uint32(7), res.Committed.TxResult.Code)
+	assert.Nil(t, res.Pending)
+
+	res, err = env.TxStatus(ctx, pending.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, coretypes.TxStatusPending, res.Status)
+	assert.Equal(t, &coretypes.PendingTxStatus{Rank: 3, Priority: 10, Height: 4, ReceivedAt: received}, res.Pending)
+	assert.Nil(t, res.Committed)
+
+	res, err = env.TxStatus(ctx, evicted.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, coretypes.TxStatusEvicted, res.Status)
+	assert.Equal(t, &coretypes.EvictedTxStatus{
+		Reason:    mempool.EvictedMempoolFull,
+		EvictedAt: evictedAt,
+		Height:    4,
+	}, res.Evicted)
+
+	res, err = env.TxStatus(ctx, types.Tx("unknown").Hash())
+	require.NoError(t, err)
+	assert.Equal(t, &coretypes.ResultTxStatus{
+		Hash:   types.Tx("unknown").Hash(),
+		Status: coretypes.TxStatusUnknown,
+	}, res)
+
+	_, err = env.TxStatus(ctx, []byte("short"))
+	assert.Error(t, err)
+
+	// Without the KV event sink, nor a mempool reporting the status of its
+	// transactions, the status is unknown.
+	env = &Environment{Mempool: mempoolmock.Mempool{}}
+	res, err = env.TxStatus(ctx, pending.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, coretypes.TxStatusUnknown, res.Status)
+}
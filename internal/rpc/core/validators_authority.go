@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	"github.com/tendermint/tendermint/types"
+)
+
+// ScheduleValidatorSetUpdate broadcasts validator updates computed outside of
+// the ABCI application, signed by the configured validator authority, as a
+// transaction. The application verifies the transaction against the authority
+// and returns the updates from EndBlock at effectiveHeight.
+func (env *Environment) ScheduleValidatorSetUpdate(
+	ctx context.Context,
+	effectiveHeight int64,
+	updates []*types.Validator,
+	signature bytes.HexBytes,
+) (*coretypes.ResultValidatorSetUpdate, error) {
+	if env.ValidatorAuthority == nil {
+		return nil, errors.New("no validator authority is configured on this node")
+	}
+
+	update := types.ValidatorSetUpdate{
+		ChainID:         env.GenDoc.ChainID,
+		EffectiveHeight: effectiveHeight,
+		Updates:         make([]abci.ValidatorUpdate, 0, len(updates)),
+	}
+	for _, val := range updates {
+		if val == nil || val.PubKey == nil {
+			return nil, fmt.Errorf("%w: validator update without public key", coretypes.ErrInvalidRequest)
+		}
+		update.Updates = append(update.Updates, types.TM2PB.ValidatorUpdate(val))
+	}
+
+	if err := update.ValidateBasic(); err != nil {
+		return nil, fmt.Errorf("%w: %v", coretypes.ErrInvalidRequest, err)
+	}
+	if err := update.Verify(env.ValidatorAuthority, signature); err != nil {
+		return nil, err
+	}
+
+	minHeight := env.BlockStore.Height() + env.ValidatorUpdateMinDelay
+	if effectiveHeight <= env.BlockStore.Height() || effectiveHeight < minHeight {
+		return nil, fmt.Errorf("%w: effective height %d must be at least %d",
+			coretypes.ErrInvalidRequest, effectiveHeight, minHeight)
+	}
+
+	tx, err := update.Tx(signature)
+	if err != nil {
+		return nil, err
+	}
+	if err := env.checkCatchingUp(); err != nil {
+		return nil, err
+	}
+	res, err := env.broadcastTxSync(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if res.Code != abci.CodeTypeOK {
+		return nil, fmt.Errorf("the application rejected the validator set update (code %d): %s", res.Code, res.Log)
+	}
+
+	env.Logger.Info("broadcast external validator updates",
+		"effective_height", effectiveHeight, "num_updates", len(updates), "hash", res.Hash)
+
+	if err := env.EventBus.PublishEventValidatorSetUpdateScheduled(ctx,
+		types.EventDataValidatorSetUpdateScheduled{
+			EffectiveHeight:  effectiveHeight,
+			ValidatorUpdates: updates,
+			Authority:        env.ValidatorAuthority.Address(),
+		}); err != nil {
+		env.Logger.Error("failed to publish validator update audit event", "err", err)
+	}
+
+	return &coretypes.ResultValidatorSetUpdate{EffectiveHeight: effectiveHeight, Hash: res.Hash}, nil
+}
@@ -0,0 +1,64 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestScheduleValidatorSetUpdate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	eventBus := eventbus.NewDefault(log.TestingLogger())
+	require.NoError(t, eventBus.Start(ctx))
+
+	authority := ed25519.GenPrivKey()
+	pool := &countingMempool{seen: make(map[string]bool)}
+	env := &Environment{
+		GenDoc:                  &types.GenesisDoc{ChainID: "test-chain"},
+		BlockStore:              store.NewBlockStore(dbm.NewMemDB()),
+		EventBus:                eventBus,
+		Mempool:                 pool,
+		Logger:                  log.TestingLogger(),
+		Config:                  *config.TestRPCConfig(),
+		ValidatorAuthority:      authority.PubKey(),
+		ValidatorUpdateMinDelay: 1,
+	}
+
+	val := types.NewValidator(ed25519.GenPrivKey().PubKey(), 10)
+	update := types.ValidatorSetUpdate{
+		ChainID:         "test-chain",
+		EffectiveHeight: 5,
+		Updates:         []abci.ValidatorUpdate{types.TM2PB.ValidatorUpdate(val)},
+	}
+	bz, err := update.SignBytes()
+	require.NoError(t, err)
+	sig, err := authority.Sign(bz)
+	require.NoError(t, err)
+
+	// a signature over another update is rejected
+	_, err = env.ScheduleValidatorSetUpdate(ctx, 6, []*types.Validator{val}, sig)
+	require.Error(t, err)
+	require.Empty(t, pool.seen)
+
+	// the update is broadcast as a transaction, for the application to apply
+	res, err := env.ScheduleValidatorSetUpdate(ctx, 5, []*types.Validator{val}, sig)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, res.EffectiveHeight)
+
+	tx, err := update.Tx(sig)
+	require.NoError(t, err)
+	require.True(t, pool.seen[string(tx)])
+	require.EqualValues(t, tx.Hash(), res.Hash)
+}
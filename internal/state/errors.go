@@ -26,6 +26,14 @@ type (
 		StoreBase int64
 	}
 
+	// ErrAppHashMismatch is returned when a block carries another app hash
+	// than the one of the state, resulting from the execution of the block
+	// at the previous height.
+	ErrAppHashMismatch struct {
+		Expected []byte // of the state
+		Got      []byte // of the block
+	}
+
 	ErrLastStateMismatch struct {
 		Height int64
 		Core   []byte
@@ -71,6 +79,10 @@ func (e ErrAppBlockHeightTooLow) Error() string {
 	return fmt.Sprintf("app block height (%d) is too far below block store base (%d)", e.AppHeight, e.StoreBase)
 }
 
+func (e ErrAppHashMismatch) Error() string {
+	return fmt.Sprintf("wrong Block.Header.AppHash.  Expected %X, got %v", e.Expected, e.Got)
+}
+
 func (e ErrLastStateMismatch) Error() string {
 	return fmt.Sprintf(
 		"latest tendermint block (%d) LastAppHash (%X) does not match app's AppHash (%X)",
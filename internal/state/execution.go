@@ -43,6 +43,12 @@ type BlockExecutor struct {
 	logger  log.Logger
 	metrics *Metrics
 
+	// captures the app hash mismatches, may be nil
+	forensics *Forensics
+
+	// prunes the blocks in the background, may be nil
+	pruner *Pruner
+
 	// cache the verification results over a single height
 	cache map[string]struct{}
 }
@@ -55,6 +61,22 @@ func BlockExecutorWithMetrics(metrics *Metrics) BlockExecutorOption {
 	}
 }
 
+// BlockExecutorWithForensics captures a forensic bundle with forensics when
+// a block to execute carries another app hash than the state.
+func BlockExecutorWithForensics(forensics *Forensics) BlockExecutorOption {
+	return func(blockExec *BlockExecutor) {
+		blockExec.forensics = forensics
+	}
+}
+
+// BlockExecutorWithPruner hands the retain heights returned by the
+// application to pruner, instead of pruning the blocks on Commit.
+func BlockExecutorWithPruner(pruner *Pruner) BlockExecutorOption {
+	return func(blockExec *BlockExecutor) {
+		blockExec.pruner = pruner
+	}
+}
+
 // NewBlockExecutor returns a new BlockExecutor with a NopEventBus.
 // Call SetEventBus to provide one.
 func NewBlockExecutor(
@@ -99,7 +121,12 @@ func (blockExec *BlockExecutor) SetEventBus(eventBus types.BlockEventPublisher)
 // and txs from the mempool. The max bytes must be big enough to fit the commit.
 // Up to 1/10th of the block space is allcoated for maximum sized evidence.
 // The rest is given to txs, up to the max gas.
+//
+// The reaped txs are passed to the application with PrepareProposal, which
+// may reorder, remove or add txs. The txs it returns must fit in the same
+// space.
 func (blockExec *BlockExecutor) CreateProposalBlock(
+	ctx context.Context,
 	height int64,
 	state State, commit *types.Commit,
 	proposerAddr []byte,
@@ -110,14 +137,106 @@ func (blockExec *BlockExecutor) CreateProposalBlock(
 
 	evidence, evSize := blockExec.evpool.PendingEvidence(state.ConsensusParams.Evidence.MaxBytes)
 
-	// Fetch a limited amount of valid txs
-	maxDataBytes := types.MaxDataBytes(maxBytes, evSize, state.Validators.Size())
+	// Fetch a limited amount of valid txs, leaving room for the vote
+	// extensions of the last commit
+	maxDataBytes := types.MaxDataBytes(maxBytes, evSize, state.Validators.Size()) - commit.ExtensionBytes()
 
 	txs := blockExec.mempool.ReapMaxBytesMaxGas(maxDataBytes, maxGas)
 
+	res, err := blockExec.proxyApp.PrepareProposal(
+		ctx,
+		abci.RequestPrepareProposal{
+			MaxTxBytes:      maxDataBytes,
+			Txs:             txs.ToSliceOfBytes(),
+			Height:          height,
+			Time:            state.blockTime(height, commit),
+			ProposerAddress: proposerAddr,
+		},
+	)
+	if err != nil {
+		return nil, nil, ErrProxyAppConn(err)
+	}
+
+	txs = types.ToTxs(res.Txs)
+	if size := types.ComputeProtoSizeForTxs(txs); size > maxDataBytes {
+		return nil, nil, fmt.Errorf(
+			"prepared proposal txs (%d bytes) exceed the maximum of %d bytes", size, maxDataBytes)
+	}
+
 	return state.MakeBlock(height, txs, commit, evidence, proposerAddr)
 }
 
+// ProcessProposal asks the application whether the proposed block is
+// acceptable. It returns false if the application rejects it, in which case
+// the block must not be voted for.
+func (blockExec *BlockExecutor) ProcessProposal(
+	ctx context.Context,
+	block *types.Block,
+) (bool, error) {
+	res, err := blockExec.proxyApp.ProcessProposal(
+		ctx,
+		abci.RequestProcessProposal{
+			Txs:             block.Data.Txs.ToSliceOfBytes(),
+			Hash:            block.Hash(),
+			Height:          block.Height,
+			Time:            block.Time,
+			ProposerAddress: block.ProposerAddress,
+		},
+	)
+	if err != nil {
+		return false, ErrProxyAppConn(err)
+	}
+	if res.IsStatusUnknown() {
+		return false, fmt.Errorf("ProcessProposal responded with status %s", res.Status)
+	}
+
+	return res.IsAccepted(), nil
+}
+
+// ExtendVote asks the application for the data to attach to vote, a
+// precommit for a block of the validator of the node.
+func (blockExec *BlockExecutor) ExtendVote(ctx context.Context, vote *types.Vote) ([]byte, error) {
+	res, err := blockExec.proxyApp.ExtendVote(
+		ctx,
+		abci.RequestExtendVote{
+			Hash:   vote.BlockID.Hash,
+			Height: vote.Height,
+		},
+	)
+	if err != nil {
+		return nil, ErrProxyAppConn(err)
+	}
+	if len(res.VoteExtension) > types.MaxVoteExtensionSize {
+		return nil, fmt.Errorf("vote extension (%d bytes) exceeds the maximum of %d bytes",
+			len(res.VoteExtension), types.MaxVoteExtensionSize)
+	}
+
+	return res.VoteExtension, nil
+}
+
+// VerifyVoteExtension asks the application whether the extension of vote, a
+// precommit for a block of another validator, is acceptable. It returns false
+// if the application rejects it, in which case the vote must be ignored.
+func (blockExec *BlockExecutor) VerifyVoteExtension(ctx context.Context, vote *types.Vote) (bool, error) {
+	res, err := blockExec.proxyApp.VerifyVoteExtension(
+		ctx,
+		abci.RequestVerifyVoteExtension{
+			Hash:             vote.BlockID.Hash,
+			ValidatorAddress: vote.ValidatorAddress,
+			Height:           vote.Height,
+			VoteExtension:    vote.Extension,
+		},
+	)
+	if err != nil {
+		return false, ErrProxyAppConn(err)
+	}
+	if res.IsStatusUnknown() {
+		return false, fmt.Errorf("VerifyVoteExtension responded with status %s", res.Status)
+	}
+
+	return res.IsAccepted(), nil
+}
+
 // ValidateBlock validates the given block against the given state.
 // If the block is invalid, it returns an error.
 // Validation does not mutate state, but does require historical information from the stateDB,
@@ -130,6 +249,10 @@ func (blockExec *BlockExecutor) ValidateBlock(state State, block *types.Block) e
 
 	err := validateBlock(state, block)
 	if err != nil {
+		var mismatch ErrAppHashMismatch
+		if blockExec.forensics != nil && errors.As(err, &mismatch) {
+			blockExec.captureAppHashMismatch(state, mismatch)
+		}
 		return err
 	}
 
@@ -142,6 +265,18 @@ func (blockExec *BlockExecutor) ValidateBlock(state State, block *types.Block) e
 	return nil
 }
 
+// captureAppHashMismatch captures the forensic bundle of the execution of the
+// last block of the state, whose app hash doesn't match the one of the next
+// block.
+func (blockExec *BlockExecutor) captureAppHashMismatch(state State, mismatch ErrAppHashMismatch) {
+	_, err := blockExec.forensics.CaptureAppHashMismatch(
+		AppHashMismatchValidation, state, state.LastBlockHeight, mismatch.Expected, mismatch.Got)
+	if err != nil {
+		blockExec.logger.Error("failed to capture the forensic bundle of an app hash mismatch",
+			"height", state.LastBlockHeight, "err", err)
+	}
+}
+
 // ApplyBlock validates the block against the state, executes it against the app,
 // fires the relevant events, commits the app, and saves the new state and responses.
 // It returns the new state.
@@ -154,15 +289,32 @@ func (blockExec *BlockExecutor) ApplyBlock(
 	blockID types.BlockID,
 	block *types.Block,
 ) (State, error) {
+	return blockExec.ApplyBlockWithProfile(ctx, state, blockID, block, types.EventDataBlockProfile{})
+}
+
+// ApplyBlockWithProfile is like ApplyBlock, but takes a partially filled
+// block profile (typically carrying the consensus phase timings) which is
+// completed with the execution timings and published once the block has
+// been applied.
+func (blockExec *BlockExecutor) ApplyBlockWithProfile(
+	ctx context.Context,
+	state State,
+	blockID types.BlockID,
+	block *types.Block,
+	profile types.EventDataBlockProfile,
+) (State, error) {
 
 	// validate the block if we haven't already
 	if err := blockExec.ValidateBlock(state, block); err != nil {
 		return state, ErrInvalidBlock(err)
 	}
 
+	profile.Height = block.Height
+	blockParams := state.ConsensusParams.Block
+
 	startTime := time.Now().UnixNano()
 	abciResponses, err := execBlockOnProxyApp(ctx,
-		blockExec.logger, blockExec.proxyApp, block, blockExec.store, state.InitialHeight,
+		blockExec.logger, blockExec.proxyApp, block, blockExec.store, state.InitialHeight, &profile,
 	)
 	endTime := time.Now().UnixNano()
 	blockExec.metrics.BlockProcessingTime.Observe(float64(endTime-startTime) / 1000000)
@@ -171,9 +323,11 @@ func (blockExec *BlockExecutor) ApplyBlock(
 	}
 
 	// Save the results before we commit.
+	saveStart := time.Now()
 	if err := blockExec.store.SaveABCIResponses(block.Height, abciResponses); err != nil {
 		return state, err
 	}
+	profile.SaveState = time.Since(saveStart)
 
 	// validate the validator updates and convert to tendermint types
 	abciValUpdates := abciResponses.EndBlock.ValidatorUpdates
@@ -186,6 +340,10 @@ func (blockExec *BlockExecutor) ApplyBlock(
 	if err != nil {
 		return state, err
 	}
+	err = validateValidatorPowerChanges(validatorUpdates, state.NextValidators, state.ConsensusParams.Validator)
+	if err != nil {
+		return state, fmt.Errorf("error in validator updates: %w", err)
+	}
 	if len(validatorUpdates) > 0 {
 		blockExec.logger.Debug("updates to validators", "updates", types.ValidatorListString(validatorUpdates))
 	}
@@ -197,22 +355,28 @@ func (blockExec *BlockExecutor) ApplyBlock(
 	}
 
 	// Lock mempool, commit app state, update mempoool.
+	commitStart := time.Now()
 	appHash, retainHeight, err := blockExec.Commit(ctx, state, block, abciResponses.DeliverTxs)
 	if err != nil {
 		return state, fmt.Errorf("commit failed for application: %w", err)
 	}
+	profile.Commit = time.Since(commitStart)
 
 	// Update evpool with the latest state.
 	blockExec.evpool.Update(state, block.Evidence.Evidence)
 
 	// Update the app hash and save the state.
 	state.AppHash = appHash
+	saveStart = time.Now()
 	if err := blockExec.store.Save(state); err != nil {
 		return state, err
 	}
+	profile.SaveState += time.Since(saveStart)
 
 	// Prune old heights, if requested by ABCI app.
-	if retainHeight > 0 {
+	if blockExec.pruner != nil {
+		blockExec.pruner.SetApplicationRetainHeight(retainHeight)
+	} else if retainHeight > 0 {
 		pruned, err := blockExec.pruneBlocks(retainHeight)
 		if err != nil {
 			blockExec.logger.Error("failed to prune blocks", "retain_height", retainHeight, "err", err)
@@ -228,6 +392,13 @@ func (blockExec *BlockExecutor) ApplyBlock(
 	// NOTE: if we crash between Commit and Save, events wont be fired during replay
 	fireEvents(ctx, blockExec.logger, blockExec.eventBus, block, blockID, abciResponses, validatorUpdates)
 
+	blockExec.metrics.observeBlockProfile(profile)
+	blockExec.metrics.observeBlockUtilization(NewBlockUtilization(
+		&block.Header, len(block.Txs), block.Size(), blockParams, abciResponses.DeliverTxs))
+	if err := blockExec.eventBus.PublishEventBlockProfile(ctx, profile); err != nil {
+		blockExec.logger.Error("failed publishing block profile", "height", block.Height, "err", err)
+	}
+
 	return state, nil
 }
 
@@ -286,7 +457,8 @@ func (blockExec *BlockExecutor) Commit(
 // Helper functions for executing blocks and updating state
 
 // Executes block's transactions on proxyAppConn.
-// Returns a list of transaction results and updates to the validator set
+// Returns a list of transaction results and updates to the validator set.
+// If profile is not nil, the duration of each ABCI phase is recorded in it.
 func execBlockOnProxyApp(
 	ctx context.Context,
 	logger log.Logger,
@@ -294,7 +466,12 @@ func execBlockOnProxyApp(
 	block *types.Block,
 	store Store,
 	initialHeight int64,
+	profile *types.EventDataBlockProfile,
 ) (*tmstate.ABCIResponses, error) {
+	if profile == nil {
+		profile = &types.EventDataBlockProfile{}
+	}
+
 	var validTxs, invalidTxs = 0, 0
 
 	txIndex := 0
@@ -316,6 +493,7 @@ func execBlockOnProxyApp(
 		return nil, errors.New("nil header")
 	}
 
+	phaseStart := time.Now()
 	abciResponses.BeginBlock, err = proxyAppConn.BeginBlock(
 		ctx,
 		abci.RequestBeginBlock{
@@ -329,8 +507,10 @@ func execBlockOnProxyApp(
 		logger.Error("error in proxyAppConn.BeginBlock", "err", err)
 		return nil, err
 	}
+	profile.BeginBlock = time.Since(phaseStart)
 
 	// run txs of block
+	phaseStart = time.Now()
 	for _, tx := range block.Txs {
 		resp, err := proxyAppConn.DeliverTx(ctx, abci.RequestDeliverTx{Tx: tx})
 		if err != nil {
@@ -347,11 +527,15 @@ func execBlockOnProxyApp(
 		txIndex++
 	}
 
+	profile.DeliverTxs = time.Since(phaseStart)
+
+	phaseStart = time.Now()
 	abciResponses.EndBlock, err = proxyAppConn.EndBlock(ctx, abci.RequestEndBlock{Height: block.Height})
 	if err != nil {
 		logger.Error("error in proxyAppConn.EndBlock", "err", err)
 		return nil, err
 	}
+	profile.EndBlock = time.Since(phaseStart)
 
 	logger.Info("executed block", "height", block.Height, "num_valid_txs", validTxs, "num_invalid_txs", invalidTxs)
 	return abciResponses, nil
@@ -422,6 +606,51 @@ func validateValidatorUpdates(abciUpdates []abci.ValidatorUpdate,
 	return nil
 }
 
+// validateValidatorPowerChanges checks that the updates of the validator set
+// vals stay within the caps of params on the change of the voting power of a
+// validator and on the sum of the changes, relative to the total voting power
+// of vals.
+func validateValidatorPowerChanges(updates []*types.Validator, vals *types.ValidatorSet,
+	params types.ValidatorParams) error {
+	if len(updates) == 0 || vals.IsNilOrEmpty() ||
+		(params.MaxPowerChangePercent == 0 && params.MaxChurnPercent == 0) {
+		return nil
+	}
+
+	total := vals.TotalVotingPower()
+	// percentOf returns floor(total * percent / 100) without overflowing.
+	percentOf := func(percent uint32) int64 {
+		p := int64(percent)
+		return total/100*p + total%100*p/100
+	}
+	maxChange, maxChurn := percentOf(params.MaxPowerChangePercent), percentOf(params.MaxChurnPercent)
+
+	var churn int64
+	for _, update := range updates {
+		var power int64
+		if _, val := vals.GetByAddress(update.Address); val != nil {
+			power = val.VotingPower
+		}
+		change := update.VotingPower - power
+		if change < 0 {
+			change = -change
+		}
+
+		if params.MaxPowerChangePercent > 0 && change > maxChange {
+			return fmt.Errorf("voting power of validator %v changes by %d, more than %d%% of the total voting power %d",
+				update.Address, change, params.MaxPowerChangePercent, total)
+		}
+		if params.MaxChurnPercent > 0 {
+			if change > maxChurn-churn {
+				return fmt.Errorf("voting powers of the validators change by more than %d%% of the total voting power %d",
+					params.MaxChurnPercent, total)
+			}
+			churn += change
+		}
+	}
+	return nil
+}
+
 // updateState returns a new State updated according to the header and responses.
 func updateState(
 	state State,
@@ -453,9 +682,14 @@ func updateState(
 	nextParams := state.ConsensusParams
 	lastHeightParamsChanged := state.LastHeightConsensusParamsChanged
 	if abciResponses.EndBlock.ConsensusParamUpdates != nil {
+		err := state.ConsensusParams.ValidateUpdate(abciResponses.EndBlock.ConsensusParamUpdates, header.Height)
+		if err != nil {
+			return state, fmt.Errorf("error updating consensus params: %w", err)
+		}
+
 		// NOTE: must not mutate s.ConsensusParams
 		nextParams = state.ConsensusParams.UpdateConsensusParams(abciResponses.EndBlock.ConsensusParamUpdates)
-		err := nextParams.ValidateConsensusParams()
+		err = nextParams.ValidateConsensusParams()
 		if err != nil {
 			return state, fmt.Errorf("error updating consensus params: %w", err)
 		}
@@ -563,7 +797,7 @@ func ExecCommitBlock(
 	initialHeight int64,
 	s State,
 ) ([]byte, error) {
-	abciResponses, err := execBlockOnProxyApp(ctx, logger, appConnConsensus, block, store, initialHeight)
+	abciResponses, err := execBlockOnProxyApp(ctx, logger, appConnConsensus, block, store, initialHeight, nil)
 	if err != nil {
 		logger.Error("failed executing block on proxy app", "height", block.Height, "err", err)
 		return nil, err
@@ -582,6 +816,11 @@ func ExecCommitBlock(
 			logger.Error("err", err)
 			return nil, err
 		}
+		err = validateValidatorPowerChanges(validatorUpdates, s.NextValidators, s.ConsensusParams.Validator)
+		if err != nil {
+			logger.Error("err", err)
+			return nil, err
+		}
 
 		bps, err := block.MakePartSet(types.BlockPartSizeBytes)
 		if err != nil {
@@ -19,6 +19,7 @@ import (
 	"github.com/tendermint/tendermint/internal/eventbus"
 	mmock "github.com/tendermint/tendermint/internal/mempool/mock"
 	"github.com/tendermint/tendermint/internal/proxy"
+	pmocks "github.com/tendermint/tendermint/internal/proxy/mocks"
 	"github.com/tendermint/tendermint/internal/pubsub"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/mocks"
@@ -26,6 +27,7 @@ import (
 	"github.com/tendermint/tendermint/internal/store"
 	"github.com/tendermint/tendermint/libs/log"
 	tmtime "github.com/tendermint/tendermint/libs/time"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 	"github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tendermint/version"
 )
@@ -66,6 +68,61 @@ func TestApplyBlock(t *testing.T) {
 	assert.EqualValues(t, 1, state.Version.Consensus.App, "App version wasn't updated")
 }
 
+// TestApplyBlockWithProfile ensures the timings of the block are published
+// with the BlockProfile event.
+func TestApplyBlockWithProfile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := &testApp{}
+	cc := abciclient.NewLocalCreator(app)
+	logger := log.TestingLogger()
+	proxyApp := proxy.NewAppConns(cc, logger, proxy.NopMetrics())
+	require.NoError(t, proxyApp.Start(ctx))
+
+	state, stateDB, _ := makeState(t, 1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+
+	blockExec := sm.NewBlockExecutor(stateStore, logger, proxyApp.Consensus(),
+		mmock.Mempool{}, sm.EmptyEvidencePool{}, blockStore)
+
+	eventBus := eventbus.NewDefault(logger)
+	require.NoError(t, eventBus.Start(ctx))
+	defer eventBus.Stop() //nolint:errcheck // ignore for tests
+	blockExec.SetEventBus(eventBus)
+
+	profileSub, err := eventBus.SubscribeWithArgs(ctx, pubsub.SubscribeArgs{
+		ClientID: "TestApplyBlockWithProfile",
+		Query:    types.EventQueryBlockProfile,
+	})
+	require.NoError(t, err)
+
+	block, err := sf.MakeBlock(state, 1, new(types.Commit))
+	require.NoError(t, err)
+	bps, err := block.MakePartSet(testPartSize)
+	require.NoError(t, err)
+	blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: bps.Header()}
+
+	_, err = blockExec.ApplyBlockWithProfile(ctx, state, blockID, block, types.EventDataBlockProfile{
+		Round:        2,
+		ProposalWait: time.Second,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel = context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	msg, err := profileSub.Next(ctx)
+	require.NoError(t, err)
+	profile, ok := msg.Data().(types.EventDataBlockProfile)
+	require.True(t, ok, "Expected event of type EventDataBlockProfile, got %T", msg.Data())
+	assert.EqualValues(t, 1, profile.Height)
+	assert.EqualValues(t, 2, profile.Round)
+	assert.Equal(t, time.Second, profile.ProposalWait)
+	assert.NotZero(t, profile.Commit)
+	assert.NotZero(t, profile.SaveState)
+}
+
 // TestBeginBlockValidators ensures we send absent validators list.
 func TestBeginBlockValidators(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -292,6 +349,49 @@ func TestValidateValidatorUpdates(t *testing.T) {
 	}
 }
 
+func TestValidateValidatorPowerChanges(t *testing.T) {
+	val1 := types.NewValidator(ed25519.GenPrivKey().PubKey(), 60)
+	val2 := types.NewValidator(ed25519.GenPrivKey().PubKey(), 40)
+	vals := types.NewValidatorSet([]*types.Validator{val1, val2})
+	newVal := ed25519.GenPrivKey().PubKey()
+	update := func(val *types.Validator, power int64) *types.Validator {
+		return types.NewValidator(val.PubKey, power)
+	}
+	capped := types.ValidatorParams{
+		PubKeyTypes:           []string{types.ABCIPubKeyTypeEd25519},
+		MaxPowerChangePercent: 10,
+		MaxChurnPercent:       15,
+	}
+
+	testCases := []struct {
+		name    string
+		updates []*types.Validator
+		params  types.ValidatorParams
+		valid   bool
+	}{
+		{"no caps", []*types.Validator{update(val1, 1000)}, types.DefaultValidatorParams(), true},
+		{"increase within the cap", []*types.Validator{update(val1, 70)}, capped, true},
+		{"decrease within the cap", []*types.Validator{update(val2, 30)}, capped, true},
+		{"increase over the cap", []*types.Validator{update(val1, 71)}, capped, false},
+		{"removal over the cap", []*types.Validator{update(val2, 0)}, capped, false},
+		{"addition within the cap", []*types.Validator{types.NewValidator(newVal, 10)}, capped, true},
+		{"addition over the cap", []*types.Validator{types.NewValidator(newVal, 11)}, capped, false},
+		{"churn within the cap", []*types.Validator{update(val1, 70), update(val2, 35)}, capped, true},
+		{"churn over the cap", []*types.Validator{update(val1, 70), update(val2, 34)}, capped, false},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := sm.ValidateValidatorPowerChanges(tc.updates, vals, tc.params)
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
 func TestUpdateValidators(t *testing.T) {
 	pubkey1 := ed25519.GenPrivKey().PubKey()
 	val1 := types.NewValidator(pubkey1, 10)
@@ -439,6 +539,45 @@ func TestEndBlockValidatorUpdates(t *testing.T) {
 	}
 }
 
+func TestExecCommitBlockValidatorPowerChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	app := &testApp{}
+	cc := abciclient.NewLocalCreator(app)
+	logger := log.TestingLogger()
+	proxyApp := proxy.NewAppConns(cc, logger, proxy.NopMetrics())
+	err := proxyApp.Start(ctx)
+	require.NoError(t, err)
+
+	state, stateDB, _ := makeState(t, 1, 1)
+	state.ConsensusParams.Validator.MaxPowerChangePercent = 10
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+
+	blockExec := sm.NewBlockExecutor(
+		stateStore,
+		logger,
+		proxyApp.Consensus(),
+		mmock.Mempool{},
+		sm.EmptyEvidencePool{},
+		blockStore,
+	)
+
+	block, err := sf.MakeBlock(state, 1, new(types.Commit))
+	require.NoError(t, err)
+
+	// The final block replayed is held to the caps of ApplyBlock.
+	pubkey := ed25519.GenPrivKey().PubKey()
+	pk, err := encoding.PubKeyToProto(pubkey)
+	require.NoError(t, err)
+	app.ValidatorUpdates = []abci.ValidatorUpdate{
+		{PubKey: pk, Power: state.NextValidators.TotalVotingPower()},
+	}
+	_, err = sm.ExecCommitBlock(ctx, blockExec, proxyApp.Consensus(), block, logger, stateStore, 1, state)
+	require.Error(t, err)
+}
+
 // TestEndBlockValidatorUpdatesResultingInEmptySet checks that processing validator updates that
 // would result in empty set causes no panic, an error is raised and NextValidators is not updated
 func TestEndBlockValidatorUpdatesResultingInEmptySet(t *testing.T) {
@@ -482,6 +621,184 @@ func TestEndBlockValidatorUpdatesResultingInEmptySet(t *testing.T) {
 	assert.NotEmpty(t, state.NextValidators.Validators)
 }
 
+func TestCreateProposalBlockPrepareProposal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, stateDB, _ := makeState(t, 1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	proposerAddr := state.Validators.GetProposer().Address
+	commit := types.NewCommit(0, 0, types.BlockID{}, nil)
+
+	txs := types.Txs{types.Tx("b"), types.Tx("a")}
+	app := &pmocks.AppConnConsensus{}
+	app.On("PrepareProposal", mock.Anything, mock.MatchedBy(func(req abci.RequestPrepareProposal) bool {
+		return req.Height == 1 && req.Time.Equal(state.LastBlockTime) && req.MaxTxBytes > 0
+	})).Return(&abci.ResponsePrepareProposal{Txs: txs.ToSliceOfBytes()}, nil).Once()
+
+	blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), app,
+		mmock.Mempool{}, sm.EmptyEvidencePool{}, blockStore)
+
+	// The application may replace the txs reaped from the mempool.
+	block, _, err := blockExec.CreateProposalBlock(ctx, 1, state, commit, proposerAddr)
+	require.NoError(t, err)
+	assert.Equal(t, txs, block.Data.Txs)
+	app.AssertExpectations(t)
+
+	// But the txs it returns must fit in the block.
+	state.ConsensusParams.Block.MaxBytes = 1024
+	app.On("PrepareProposal", mock.Anything, mock.Anything).Return(&abci.ResponsePrepareProposal{
+		Txs: [][]byte{make([]byte, 1024)},
+	}, nil).Once()
+	_, _, err = blockExec.CreateProposalBlock(ctx, 1, state, commit, proposerAddr)
+	assert.Error(t, err)
+	app.AssertExpectations(t)
+}
+
+func TestProcessProposal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, stateDB, _ := makeState(t, 1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+
+	block, err := sf.MakeBlock(state, 1, new(types.Commit))
+	require.NoError(t, err)
+	block.Data.Txs = types.Txs{types.Tx("a")}
+
+	testCases := []struct {
+		status   abci.ResponseProcessProposal_ProposalStatus
+		accepted bool
+		err      bool
+	}{
+		{abci.ResponseProcessProposal_ACCEPT, true, false},
+		{abci.ResponseProcessProposal_REJECT, false, false},
+		{abci.ResponseProcessProposal_UNKNOWN, false, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			app := &pmocks.AppConnConsensus{}
+			app.On("ProcessProposal", mock.Anything, abci.RequestProcessProposal{
+				Txs:             block.Data.Txs.ToSliceOfBytes(),
+				Hash:            block.Hash(),
+				Height:          block.Height,
+				Time:            block.Time,
+				ProposerAddress: block.ProposerAddress,
+			}).Return(&abci.ResponseProcessProposal{Status: tc.status}, nil)
+
+			blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), app,
+				mmock.Mempool{}, sm.EmptyEvidencePool{}, blockStore)
+
+			accepted, err := blockExec.ProcessProposal(ctx, block)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.accepted, accepted)
+			app.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExtendVote(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, stateDB, _ := makeState(t, 1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+
+	vote := &types.Vote{
+		Type:    tmproto.PrecommitType,
+		Height:  1,
+		BlockID: makeBlockID([]byte("block"), 1, []byte("parts")),
+	}
+
+	testCases := []struct {
+		name      string
+		extension []byte
+		err       bool
+	}{
+		{"empty", nil, false},
+		{"extension", []byte("extension"), false},
+		{"too big", make([]byte, types.MaxVoteExtensionSize+1), true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := &pmocks.AppConnConsensus{}
+			app.On("ExtendVote", mock.Anything, abci.RequestExtendVote{
+				Hash:   vote.BlockID.Hash,
+				Height: vote.Height,
+			}).Return(&abci.ResponseExtendVote{VoteExtension: tc.extension}, nil)
+
+			blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), app,
+				mmock.Mempool{}, sm.EmptyEvidencePool{}, blockStore)
+
+			extension, err := blockExec.ExtendVote(ctx, vote)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.extension, extension)
+			}
+			app.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyVoteExtension(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, stateDB, _ := makeState(t, 1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+
+	vote := &types.Vote{
+		Type:             tmproto.PrecommitType,
+		Height:           1,
+		BlockID:          makeBlockID([]byte("block"), 1, []byte("parts")),
+		ValidatorAddress: crypto.AddressHash([]byte("validator")),
+		Extension:        []byte("extension"),
+	}
+
+	testCases := []struct {
+		status   abci.ResponseVerifyVoteExtension_VerifyStatus
+		accepted bool
+		err      bool
+	}{
+		{abci.ResponseVerifyVoteExtension_ACCEPT, true, false},
+		{abci.ResponseVerifyVoteExtension_REJECT, false, false},
+		{abci.ResponseVerifyVoteExtension_UNKNOWN, false, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.status.String(), func(t *testing.T) {
+			app := &pmocks.AppConnConsensus{}
+			app.On("VerifyVoteExtension", mock.Anything, abci.RequestVerifyVoteExtension{
+				Hash:             vote.BlockID.Hash,
+				ValidatorAddress: vote.ValidatorAddress,
+				Height:           vote.Height,
+				VoteExtension:    vote.Extension,
+			}).Return(&abci.ResponseVerifyVoteExtension{Status: tc.status}, nil)
+
+			blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), app,
+				mmock.Mempool{}, sm.EmptyEvidencePool{}, blockStore)
+
+			accepted, err := blockExec.VerifyVoteExtension(ctx, vote)
+			if tc.err {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.accepted, accepted)
+			app.AssertExpectations(t)
+		})
+	}
+}
+
 func makeBlockID(hash []byte, partSetSize uint32, partSetHash []byte) types.BlockID {
 	var (
 		h   = make([]byte, tmhash.Size)
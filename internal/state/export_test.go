@@ -34,3 +34,10 @@ func UpdateState(
 func ValidateValidatorUpdates(abciUpdates []abci.ValidatorUpdate, params types.ValidatorParams) error {
 	return validateValidatorUpdates(abciUpdates, params)
 }
+
+// ValidateValidatorPowerChanges is an alias for validateValidatorPowerChanges
+// exported from execution.go, exclusively and explicitly for testing.
+func ValidateValidatorPowerChanges(updates []*types.Validator, vals *types.ValidatorSet,
+	params types.ValidatorParams) error {
+	return validateValidatorPowerChanges(updates, vals, params)
+}
@@ -0,0 +1,305 @@
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Sources of the app hash mismatches.
+const (
+	AppHashMismatchValidation = "validation" // a block to execute carries another app hash
+	AppHashMismatchReplay     = "replay"     // the app returned another app hash during the handshake
+)
+
+const (
+	forensicBundlePrefix = "app-hash-mismatch-"
+	forensicBundleSuffix = ".json"
+)
+
+// ErrNoForensicBundle is returned when there is no forensic bundle to load.
+var ErrNoForensicBundle = errors.New("no forensic bundle")
+
+// AppHashMismatchBundle is the forensic bundle captured on an app hash
+// mismatch: the block whose execution resulted in the mismatched app hash,
+// the ABCI requests and responses of its execution, and the state of the
+// node when the mismatch was detected.
+type AppHashMismatchBundle struct {
+	// Height is the height of the executed block. AppHash is the app hash
+	// the local app returned for it, and ExpectedAppHash the one of the
+	// network, or of the state during the handshake.
+	Height          int64            `json:"height"`
+	AppHash         tmbytes.HexBytes `json:"app_hash"`
+	ExpectedAppHash tmbytes.HexBytes `json:"expected_app_hash"`
+	Source          string           `json:"source"`
+	DetectedAt      time.Time        `json:"detected_at"`
+
+	Block *types.Block   `json:"block,omitempty"`
+	ABCI  []ABCIExchange `json:"abci"`
+	State StateSummary   `json:"state"`
+
+	// Errors are the parts of the bundle which could not be captured, e.g.
+	// the block or the ABCI responses of a pruned height.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ABCIExchange is an ABCI request sent to the app while executing a block,
+// with the response of the app. The requests are rebuilt from the block, and
+// the responses loaded from the state store.
+type ABCIExchange struct {
+	Method   string      `json:"method"`
+	Request  interface{} `json:"request"`
+	Response interface{} `json:"response,omitempty"`
+}
+
+// StateSummary summarizes a state: its last block and the hashes it
+// commits to.
+type StateSummary struct {
+	ChainID         string           `json:"chain_id"`
+	InitialHeight   int64            `json:"initial_height"`
+	AppVersion      uint64           `json:"app_version"`
+	LastBlockHeight int64            `json:"last_block_height"`
+	LastBlockID     types.BlockID    `json:"last_block_id"`
+	LastBlockTime   time.Time        `json:"last_block_time"`
+	AppHash         tmbytes.HexBytes `json:"app_hash"`
+	LastResultsHash tmbytes.HexBytes `json:"last_results_hash"`
+
+	ValidatorsHash      tmbytes.HexBytes `json:"validators_hash"`
+	NextValidatorsHash  tmbytes.HexBytes `json:"next_validators_hash"`
+	ConsensusParamsHash tmbytes.HexBytes `json:"consensus_params_hash"`
+}
+
+// NewStateSummary returns the summary of the state.
+func NewStateSummary(state State) StateSummary {
+	summary := StateSummary{
+		ChainID:             state.ChainID,
+		InitialHeight:       state.InitialHeight,
+		AppVersion:          state.Version.Consensus.App,
+		LastBlockHeight:     state.LastBlockHeight,
+		LastBlockID:         state.LastBlockID,
+		LastBlockTime:       state.LastBlockTime,
+		AppHash:             state.AppHash,
+		LastResultsHash:     state.LastResultsHash,
+		ConsensusParamsHash: state.ConsensusParams.HashConsensusParams(),
+	}
+	if state.Validators != nil {
+		summary.ValidatorsHash = state.Validators.Hash()
+	}
+	if state.NextValidators != nil {
+		summary.NextValidatorsHash = state.NextValidators.Hash()
+	}
+	return summary
+}
+
+// Forensics captures a forensic bundle to a directory on each app hash
+// mismatch, once per height, and loads them back. It is safe for concurrent
+// use.
+type Forensics struct {
+	dir        string
+	store      Store
+	blockStore BlockStore
+	logger     log.Logger
+
+	mtx sync.Mutex
+}
+
+// NewForensics returns a Forensics capturing the bundles to dir, with the
+// blocks and ABCI responses of the stores.
+func NewForensics(dir string, store Store, blockStore BlockStore, logger log.Logger) *Forensics {
+	return &Forensics{
+		dir:        dir,
+		store:      store,
+		blockStore: blockStore,
+		logger:     logger,
+	}
+}
+
+// CaptureAppHashMismatch writes the forensic bundle of the mismatch of the
+// app hash resulting from the block at height, unless one was captured for
+// the height already, and returns the path of the bundle. The bundle is
+// captured even if parts of it are missing.
+func (f *Forensics) CaptureAppHashMismatch(
+	source string,
+	state State,
+	height int64,
+	appHash, expectedAppHash []byte,
+) (string, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	path := f.bundlePath(height)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	bundle := AppHashMismatchBundle{
+		Height:          height,
+		AppHash:         appHash,
+		ExpectedAppHash: expectedAppHash,
+		Source:          source,
+		DetectedAt:      time.Now(),
+		ABCI:            []ABCIExchange{},
+		State:           NewStateSummary(state),
+	}
+	if err := f.captureExecution(&bundle, state.InitialHeight); err != nil {
+		bundle.Errors = append(bundle.Errors, err.Error())
+	}
+
+	bz, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return "", err
+	}
+	if err := tempfile.WriteFileAtomic(path, bz, 0600); err != nil {
+		return "", err
+	}
+	f.logger.Error("captured the forensic bundle of an app hash mismatch",
+		"height", height, "app_hash", bundle.AppHash, "expected_app_hash", bundle.ExpectedAppHash,
+		"path", path)
+	return path, nil
+}
+
+// captureExecution adds the block at the height of the bundle to it, with
+// the ABCI requests and responses of its execution.
+func (f *Forensics) captureExecution(bundle *AppHashMismatchBundle, initialHeight int64) error {
+	if bundle.Height < initialHeight {
+		return fmt.Errorf("no block executed before the initial height %d", initialHeight)
+	}
+	block := f.blockStore.LoadBlock(bundle.Height)
+	if block == nil {
+		return fmt.Errorf("block at height %d not found", bundle.Height)
+	}
+	bundle.Block = block
+
+	beginBlock, err := f.beginBlockRequest(block, initialHeight)
+	if err != nil {
+		return fmt.Errorf("rebuilding the BeginBlock request: %w", err)
+	}
+	responses, err := f.store.LoadABCIResponses(bundle.Height)
+	if err != nil {
+		// The requests are still of interest without the responses.
+		bundle.Errors = append(bundle.Errors, fmt.Sprintf("loading the ABCI responses: %v", err))
+	}
+
+	var exchanges []ABCIExchange
+	add := func(method string, req, resp interface{}) {
+		exchanges = append(exchanges, ABCIExchange{Method: method, Request: req, Response: resp})
+	}
+	if responses != nil {
+		add("begin_block", beginBlock, responses.BeginBlock)
+	} else {
+		add("begin_block", beginBlock, nil)
+	}
+	for i, tx := range block.Txs {
+		var resp interface{}
+		if responses != nil && i < len(responses.DeliverTxs) {
+			resp = responses.DeliverTxs[i]
+		}
+		add("deliver_tx", abci.RequestDeliverTx{Tx: tx}, resp)
+	}
+	if responses != nil {
+		add("end_block", abci.RequestEndBlock{Height: block.Height}, responses.EndBlock)
+	} else {
+		add("end_block", abci.RequestEndBlock{Height: block.Height}, nil)
+	}
+	add("commit", abci.RequestCommit{}, abci.ResponseCommit{Data: bundle.AppHash})
+
+	bundle.ABCI = exchanges
+	return nil
+}
+
+// beginBlockRequest rebuilds the BeginBlock request of the block. The
+// validators of the previous height may be missing, which is reported as an
+// error instead of the panic of a block execution.
+func (f *Forensics) beginBlockRequest(block *types.Block, initialHeight int64) (req abci.RequestBeginBlock, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	byzVals := make([]abci.Evidence, 0)
+	for _, evidence := range block.Evidence.Evidence {
+		byzVals = append(byzVals, evidence.ABCI()...)
+	}
+	return abci.RequestBeginBlock{
+		Hash:                block.Hash(),
+		Header:              *block.Header.ToProto(),
+		LastCommitInfo:      getBeginBlockValidatorInfo(block, f.store, initialHeight),
+		ByzantineValidators: byzVals,
+	}, nil
+}
+
+// Heights returns the heights of the captured bundles, in increasing order.
+func (f *Forensics) Heights() ([]int64, error) {
+	entries, err := os.ReadDir(f.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var heights []int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, forensicBundlePrefix) ||
+			!strings.HasSuffix(name, forensicBundleSuffix) {
+			continue
+		}
+		height, err := strconv.ParseInt(
+			strings.TrimSuffix(strings.TrimPrefix(name, forensicBundlePrefix), forensicBundleSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
+
+// LoadBundle loads the bundle captured for the height, or the bundle of the
+// highest height if height is 0. It returns ErrNoForensicBundle if there is
+// no such bundle.
+func (f *Forensics) LoadBundle(height int64) (*AppHashMismatchBundle, error) {
+	if height == 0 {
+		heights, err := f.Heights()
+		if err != nil {
+			return nil, err
+		}
+		if len(heights) == 0 {
+			return nil, ErrNoForensicBundle
+		}
+		height = heights[len(heights)-1]
+	}
+
+	bz, err := os.ReadFile(f.bundlePath(height))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w at height %d", ErrNoForensicBundle, height)
+	} else if err != nil {
+		return nil, err
+	}
+	bundle := new(AppHashMismatchBundle)
+	if err := json.Unmarshal(bz, bundle); err != nil {
+		return nil, fmt.Errorf("invalid forensic bundle at height %d: %w", height, err)
+	}
+	return bundle, nil
+}
+
+func (f *Forensics) bundlePath(height int64) string {
+	return filepath.Join(f.dir, fmt.Sprintf("%s%d%s", forensicBundlePrefix, height, forensicBundleSuffix))
+}
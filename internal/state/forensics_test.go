@@ -0,0 +1,92 @@
+package state_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	memmock "github.com/tendermint/tendermint/internal/mempool/mock"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	statefactory "github.com/tendermint/tendermint/internal/state/test/factory"
+	"github.com/tendermint/tendermint/internal/test/factory"
+	"github.com/tendermint/tendermint/libs/log"
+	tmrand "github.com/tendermint/tendermint/libs/rand"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestForensics_CaptureAppHashMismatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	proxyApp := newTestApp()
+	require.NoError(t, proxyApp.Start(ctx))
+
+	state, stateDB, privVals := makeState(t, 1, 1)
+	stateStore := sm.NewStore(stateDB)
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Base").Return(int64(1))
+	forensics := sm.NewForensics(t.TempDir(), stateStore, blockStore, log.TestingLogger())
+	blockExec := sm.NewBlockExecutor(stateStore, log.TestingLogger(), proxyApp.Consensus(),
+		memmock.Mempool{}, sm.EmptyEvidencePool{}, blockStore, sm.BlockExecutorWithForensics(forensics))
+
+	proposer := state.Validators.GetProposer().Address
+	block, _, err := state.MakeBlock(1, factory.MakeTenTxs(1), new(types.Commit), nil, proposer)
+	require.NoError(t, err)
+	blockID := types.BlockID{Hash: block.Hash(), PartSetHeader: types.PartSetHeader{Total: 3, Hash: tmrand.Bytes(32)}}
+	state, err = blockExec.ApplyBlock(ctx, state, blockID, block)
+	require.NoError(t, err)
+	blockStore.On("LoadBlock", int64(1)).Return(block)
+
+	heights, err := forensics.Heights()
+	require.NoError(t, err)
+	require.Empty(t, heights)
+	_, err = forensics.LoadBundle(0)
+	require.ErrorIs(t, err, sm.ErrNoForensicBundle)
+
+	// The next block carries another app hash than the one of the app.
+	commit := makeValidCommit(ctx, t, 1, blockID, state.Validators, privVals)
+	next, err := statefactory.MakeBlock(state, 2, commit)
+	require.NoError(t, err)
+	next.AppHash = tmhash.Sum([]byte("another app hash"))
+	err = blockExec.ValidateBlock(state, next)
+	var mismatch sm.ErrAppHashMismatch
+	require.True(t, errors.As(err, &mismatch), err)
+
+	bundle, err := forensics.LoadBundle(0)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, bundle.Height)
+	require.True(t, bytes.Equal(state.AppHash, bundle.AppHash))
+	require.EqualValues(t, next.AppHash, bundle.ExpectedAppHash)
+	require.Equal(t, sm.AppHashMismatchValidation, bundle.Source)
+	require.Equal(t, block.Hash(), bundle.Block.Hash())
+	require.Equal(t, sm.NewStateSummary(state).LastResultsHash, bundle.State.LastResultsHash)
+	require.Empty(t, bundle.Errors)
+
+	// BeginBlock, the 10 txs, EndBlock and Commit, with their responses.
+	require.Len(t, bundle.ABCI, 13)
+	require.Equal(t, "begin_block", bundle.ABCI[0].Method)
+	require.Equal(t, "deliver_tx", bundle.ABCI[1].Method)
+	require.Equal(t, "end_block", bundle.ABCI[11].Method)
+	require.Equal(t, "commit", bundle.ABCI[12].Method)
+	for _, exchange := range bundle.ABCI {
+		require.NotNil(t, exchange.Response, exchange.Method)
+	}
+
+	// The bundle is captured once per height.
+	_, err = forensics.CaptureAppHashMismatch(sm.AppHashMismatchReplay, state, 1, nil, nil)
+	require.NoError(t, err)
+	again, err := forensics.LoadBundle(1)
+	require.NoError(t, err)
+	require.Equal(t, sm.AppHashMismatchValidation, again.Source)
+
+	heights, err = forensics.Heights()
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, heights)
+	_, err = forensics.LoadBundle(2)
+	require.ErrorIs(t, err, sm.ErrNoForensicBundle)
+}
@@ -0,0 +1,121 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/orderedcode"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/types"
+)
+
+const (
+	// prefixIndexIntent is unique across all tm db's, see store.go.
+	prefixIndexIntent = int64(14)
+)
+
+func indexIntentKey(height int64) []byte {
+	return encodeKey(prefixIndexIntent, height)
+}
+
+// IndexIntentLog is the log of the index intents recorded in the state
+// database by a store created with WithIndexIntents: an intent is recorded in
+// the batch saving the state of each block, and acknowledged once the block
+// is indexed, so the blocks committed but not indexed, e.g. because the node
+// crashed in between, are found on start.
+type IndexIntentLog struct {
+	db         dbm.DB
+	stateStore Store
+	blockStore BlockStore
+}
+
+var _ indexer.IntentLog = (*IndexIntentLog)(nil)
+
+// NewIndexIntentLog returns the log of the index intents of the state
+// database db, loading the blocks from blockStore and their results from
+// stateStore.
+func NewIndexIntentLog(db dbm.DB, stateStore Store, blockStore BlockStore) *IndexIntentLog {
+	return &IndexIntentLog{db: db, stateStore: stateStore, blockStore: blockStore}
+}
+
+// Pending implements indexer.IntentLog.
+func (l *IndexIntentLog) Pending() ([]int64, error) {
+	start := indexIntentKey(0)
+	end, err := orderedcode.Append(nil, prefixIndexIntent+1)
+	if err != nil {
+		return nil, err
+	}
+	it, err := l.db.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	var heights []int64
+	for ; it.Valid(); it.Next() {
+		var prefix, height int64
+		if _, err := orderedcode.Parse(string(it.Key()), &prefix, &height); err != nil {
+			return nil, fmt.Errorf("invalid index intent key %X: %w", it.Key(), err)
+		}
+		heights = append(heights, height)
+	}
+	return heights, it.Error()
+}
+
+// Ack implements indexer.IntentLog.
+func (l *IndexIntentLog) Ack(height int64) error {
+	return l.db.Delete(indexIntentKey(height))
+}
+
+// LoadBlockEvents implements indexer.IntentLog.
+func (l *IndexIntentLog) LoadBlockEvents(height int64) (types.EventDataNewBlockHeader, []*abci.TxResult, error) {
+	return LoadBlockEvents(l.stateStore, l.blockStore, height)
+}
+
+// LoadBlockEvents returns the events of the block at height, and the results
+// of its transactions, to index them, from the block in blockStore and its
+// results in stateStore. It returns an error wrapping
+// indexer.ErrBlockUnavailable if either was pruned or never saved.
+func LoadBlockEvents(stateStore Store, blockStore BlockStore, height int64) (types.EventDataNewBlockHeader, []*abci.TxResult, error) {
+	block := blockStore.LoadBlock(height)
+	if block == nil {
+		return types.EventDataNewBlockHeader{}, nil, fmt.Errorf("%w: no block at height %d", indexer.ErrBlockUnavailable, height)
+	}
+	responses, err := stateStore.LoadABCIResponses(height)
+	if err != nil {
+		var noResponses ErrNoABCIResponsesForHeight
+		if errors.As(err, &noResponses) {
+			err = fmt.Errorf("%w: %v", indexer.ErrBlockUnavailable, err)
+		}
+		return types.EventDataNewBlockHeader{}, nil, err
+	}
+	if len(responses.DeliverTxs) != len(block.Txs) {
+		return types.EventDataNewBlockHeader{}, nil, fmt.Errorf(
+			"%d results for the %d transactions of the block at height %d",
+			len(responses.DeliverTxs), len(block.Txs), height)
+	}
+
+	header := types.EventDataNewBlockHeader{
+		Header: block.Header,
+		NumTxs: int64(len(block.Txs)),
+	}
+	if responses.BeginBlock != nil {
+		header.ResultBeginBlock = *responses.BeginBlock
+	}
+	if responses.EndBlock != nil {
+		header.ResultEndBlock = *responses.EndBlock
+	}
+	txs := make([]*abci.TxResult, len(block.Txs))
+	for i, tx := range block.Txs {
+		txs[i] = &abci.TxResult{
+			Height: height,
+			Index:  uint32(i),
+			Tx:     tx,
+			Result: *responses.DeliverTxs[i],
+		}
+	}
+	return header, txs, nil
+}
@@ -0,0 +1,79 @@
+package state_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	tmstate "github.com/tendermint/tendermint/proto/tendermint/state"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestIndexIntentLog(t *testing.T) {
+	state, stateDB, _ := makeState(t, 1, 1)
+
+	// Without the option, no intents are recorded.
+	require.NoError(t, sm.NewStore(stateDB).Save(state))
+
+	block := &types.Block{
+		Header: types.Header{Height: 2},
+		Data:   types.Data{Txs: types.Txs{types.Tx("foo"), types.Tx("bar")}},
+	}
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("LoadBlock", int64(2)).Return(block)
+	// The results of the block at height 4 are missing.
+	blockStore.On("LoadBlock", int64(4)).Return(&types.Block{Header: types.Header{Height: 4}})
+	blockStore.On("LoadBlock", mock.Anything).Return(nil)
+
+	stateStore := sm.NewStore(stateDB, sm.WithIndexIntents())
+	intents := sm.NewIndexIntentLog(stateDB, stateStore, blockStore)
+	heights, err := intents.Pending()
+	require.NoError(t, err)
+	require.Empty(t, heights)
+
+	// The intent of each saved state is recorded until it is acknowledged.
+	for _, height := range []int64{1, 2, 3} {
+		state.LastBlockHeight = height
+		require.NoError(t, stateStore.Save(state))
+	}
+	heights, err = intents.Pending()
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2, 3}, heights)
+
+	require.NoError(t, intents.Ack(3))
+	heights, err = intents.Pending()
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 2}, heights)
+
+	// The events of the block are loaded as they were published.
+	require.NoError(t, stateStore.SaveABCIResponses(2, &tmstate.ABCIResponses{
+		DeliverTxs: []*abci.ResponseDeliverTx{{Code: 0}, {Code: 1, Log: "not ok"}},
+		BeginBlock: &abci.ResponseBeginBlock{},
+		EndBlock:   &abci.ResponseEndBlock{Events: []abci.Event{{Type: "end"}}},
+	}))
+	header, txs, err := intents.LoadBlockEvents(2)
+	require.NoError(t, err)
+	assert.Equal(t, block.Header, header.Header)
+	assert.EqualValues(t, 2, header.NumTxs)
+	assert.Equal(t, "end", header.ResultEndBlock.Events[0].Type)
+	require.Len(t, txs, 2)
+	assert.Equal(t, &abci.TxResult{
+		Height: 2,
+		Index:  1,
+		Tx:     types.Tx("bar"),
+		Result: abci.ResponseDeliverTx{Code: 1, Log: "not ok"},
+	}, txs[1])
+
+	// The blocks which cannot be loaded anymore are reported.
+	_, _, err = intents.LoadBlockEvents(1)
+	assert.True(t, errors.Is(err, indexer.ErrBlockUnavailable), err)
+	_, _, err = intents.LoadBlockEvents(4)
+	assert.True(t, errors.Is(err, indexer.ErrBlockUnavailable), err)
+}
@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/google/orderedcode"
@@ -110,6 +109,45 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 		return results, nil
 	}
 
+	filteredHeights, err := idx.matchConditions(ctx, conditions)
+	if err != nil {
+		return nil, err
+	}
+
+	// fetch matching heights
+	results = make([]int64, 0, len(filteredHeights))
+heights:
+	for _, hBz := range filteredHeights {
+		h := int64FromBytes(hBz)
+
+		ok, err := idx.Has(h)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			results = append(results, h)
+		}
+
+		select {
+		case <-ctx.Done():
+			break heights
+
+		default:
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+
+	return results, nil
+}
+
+// matchConditions returns the heights matching all the conditions.
+//
+// The OR and IN conditions match the heights matching any of their
+// alternatives, and the NOT conditions are matched last, removing the heights
+// matching their operand from the matches of the other conditions, or from
+// all the heights.
+func (idx *BlockerIndexer) matchConditions(ctx context.Context, conditions []syntax.Condition) (map[string][]byte, error) {
 	var heightsInitialized bool
 	filteredHeights := make(map[string][]byte)
 
@@ -152,7 +190,44 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 
 	// for all other conditions
 	for i, c := range conditions {
-		if intInSlice(i, skipIndexes) {
+		if intInSlice(i, skipIndexes) || c.Op == syntax.TNot {
+			continue
+		}
+
+		var matches map[string][]byte
+		switch {
+		case c.Op == syntax.TOr || c.Op == syntax.TIn:
+			matches = make(map[string][]byte)
+			for _, alt := range c.Alternatives() {
+				altHeights, err := idx.matchConditions(ctx, alt)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range altHeights {
+					matches[k] = v
+				}
+			}
+		case c.Tag == types.BlockHeightKey && c.Op == syntax.TEq:
+			// e.g. an alternative of an OR condition
+			var err error
+			if matches, err = idx.matchHeight(c); err != nil {
+				return nil, err
+			}
+		}
+		if matches != nil {
+			if heightsInitialized {
+				for k := range filteredHeights {
+					if matches[k] == nil {
+						delete(filteredHeights, k)
+					}
+				}
+			} else {
+				filteredHeights = matches
+				heightsInitialized = true
+			}
+			if len(filteredHeights) == 0 {
+				break
+			}
 			continue
 		}
 
@@ -182,31 +257,73 @@ func (idx *BlockerIndexer) Search(ctx context.Context, q *query.Query) ([]int64,
 		}
 	}
 
-	// fetch matching heights
-	results = make([]int64, 0, len(filteredHeights))
-heights:
-	for _, hBz := range filteredHeights {
-		h := int64FromBytes(hBz)
-
-		ok, err := idx.Has(h)
+	for _, c := range conditions {
+		if c.Op != syntax.TNot {
+			continue
+		}
+		if !heightsInitialized {
+			all, err := idx.matchAll(ctx)
+			if err != nil {
+				return nil, err
+			}
+			filteredHeights = all
+			heightsInitialized = true
+		}
+		if len(filteredHeights) == 0 {
+			break
+		}
+		negated, err := idx.matchConditions(ctx, c.Sub[0])
 		if err != nil {
 			return nil, err
 		}
-		if ok {
-			results = append(results, h)
+		for k := range negated {
+			delete(filteredHeights, k)
 		}
+	}
 
-		select {
-		case <-ctx.Done():
-			break heights
+	return filteredHeights, nil
+}
 
-		default:
-		}
+// matchHeight returns the height that is the argument of the condition c, if
+// it is indexed.
+func (idx *BlockerIndexer) matchHeight(c syntax.Condition) (map[string][]byte, error) {
+	matches := make(map[string][]byte)
+	height := int64(c.Arg.Number())
+	ok, err := idx.Has(height)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		heightBz := int64ToBytes(height)
+		matches[string(heightBz)] = heightBz
 	}
+	return matches, nil
+}
 
-	sort.Slice(results, func(i, j int) bool { return results[i] < results[j] })
+// matchAll returns all the indexed heights.
+func (idx *BlockerIndexer) matchAll(ctx context.Context) (map[string][]byte, error) {
+	prefix, err := orderedcode.Append(nil, types.BlockHeightKey)
+	if err != nil {
+		return nil, err
+	}
+	it, err := dbm.IteratePrefix(idx.store, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prefix iterator: %w", err)
+	}
+	defer it.Close()
 
-	return results, nil
+	matches := make(map[string][]byte)
+	for ; it.Valid(); it.Next() {
+		matches[string(it.Value())] = it.Value()
+
+		if err := ctx.Err(); err != nil {
+			break
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	return matches, nil
 }
 
 // matchRange returns all matching block heights that match a given QueryRange
@@ -230,8 +347,6 @@ func (idx *BlockerIndexer) matchRange(
 	}
 
 	tmpHeights := make(map[string][]byte)
-	lowerBound := qr.LowerBoundValue()
-	upperBound := qr.UpperBoundValue()
 
 	it, err := dbm.IteratePrefix(idx.store, startKey)
 	if err != nil {
@@ -256,24 +371,8 @@ iter:
 			continue
 		}
 
-		if _, ok := qr.AnyBound().(int64); ok {
-			v, err := strconv.ParseInt(eventValue, 10, 64)
-			if err != nil {
-				continue iter
-			}
-
-			include := true
-			if lowerBound != nil && v < lowerBound.(int64) {
-				include = false
-			}
-
-			if upperBound != nil && v > upperBound.(int64) {
-				include = false
-			}
-
-			if include {
-				tmpHeights[string(it.Value())] = it.Value()
-			}
+		if qr.Matches(eventValue) {
+			tmpHeights[string(it.Value())] = it.Value()
 		}
 
 		select {
@@ -129,6 +129,30 @@ func TestBlockIndexer(t *testing.T) {
 			q:       query.MustCompile(`begin_event.proposer CONTAINS 'FCAA001'`),
 			results: []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
 		},
+		"end_event.foo > 4 AND end_event.foo < 10": {
+			q:       query.MustCompile(`end_event.foo > 4 AND end_event.foo < 10`),
+			results: []int64{6, 8},
+		},
+		"end_event.foo = 2 OR end_event.foo >= 10": {
+			q:       query.MustCompile(`end_event.foo = 2 OR end_event.foo >= 10`),
+			results: []int64{1, 2, 10},
+		},
+		"end_event.foo IN (4, 8, 9)": {
+			q:       query.MustCompile(`end_event.foo IN (4, 8, 9)`),
+			results: []int64{4, 8},
+		},
+		"block.height IN (3, 5, 100)": {
+			q:       query.MustCompile(`block.height IN (3, 5, 100)`),
+			results: []int64{3, 5},
+		},
+		"NOT end_event.foo EXISTS": {
+			q:       query.MustCompile(`NOT end_event.foo EXISTS`),
+			results: []int64{3, 5, 7, 9, 11},
+		},
+		"block.height < 5 AND NOT (end_event.foo = 2 OR block.height = 3)": {
+			q:       query.MustCompile(`block.height < 5 AND NOT (end_event.foo = 2 OR block.height = 3)`),
+			results: []int64{1, 4},
+		},
 	}
 
 	for name, tc := range testCases {
@@ -0,0 +1,52 @@
+package indexer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/google/orderedcode"
+)
+
+// Cursor is the position of a result in the results of a search, ordered by
+// height and then by index, from which the next page of results is read. The
+// index is 0 for blocks.
+type Cursor struct {
+	Height int64
+	Index  uint32
+}
+
+// String returns the opaque encoding of c, parsed by ParseCursor.
+func (c Cursor) String() string {
+	bz, err := orderedcode.Append(nil, c.Height, int64(c.Index))
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bz)
+}
+
+// ParseCursor parses the encoding of a cursor.
+func ParseCursor(s string) (Cursor, error) {
+	bz, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var height, index int64
+	remaining, err := orderedcode.Parse(string(bz), &height, &index)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if len(remaining) != 0 || height <= 0 || index < 0 || index > int64(^uint32(0)) {
+		return Cursor{}, errors.New("invalid cursor")
+	}
+	return Cursor{Height: height, Index: uint32(index)}, nil
+}
+
+// Follows reports whether the result at the given position follows c, in
+// descending order if desc is true and in ascending order otherwise.
+func (c Cursor) Follows(height int64, index uint32, desc bool) bool {
+	if desc {
+		return height < c.Height || (height == c.Height && index < c.Index)
+	}
+	return height > c.Height || (height == c.Height && index > c.Index)
+}
@@ -2,6 +2,7 @@ package indexer
 
 import (
 	"context"
+	"errors"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/pubsub/query"
@@ -54,3 +55,76 @@ type EventSink interface {
 	// Stop will close the data store connection, if the eventsink supports it.
 	Stop() error
 }
+
+// BlockProfileSink is an optional interface implemented by event sinks that
+// can store per-height block profiles. The IndexerService hands profiles to
+// every sink implementing it once the corresponding block has been indexed.
+type BlockProfileSink interface {
+	// IndexBlockProfile stores the profile of a block by its height.
+	IndexBlockProfile(types.EventDataBlockProfile) error
+
+	// GetBlockProfile returns the profile stored for the given height, or
+	// nil if no profile is stored.
+	GetBlockProfile(height int64) (*types.EventDataBlockProfile, error)
+}
+
+// CheckTxSink is an optional interface implemented by event sinks that can
+// store the CheckTx responses of the transactions entering the mempool. The
+// IndexerService hands them to every sink implementing it if the indexing of
+// CheckTx responses is enabled.
+type CheckTxSink interface {
+	// IndexCheckTx stores the CheckTx response of a transaction by its hash,
+	// replacing the one of a previous check.
+	IndexCheckTx(types.EventDataCheckTx) error
+
+	// GetCheckTx returns the CheckTx response stored for the transaction with
+	// the given hash, or nil if none is stored.
+	GetCheckTx(hash []byte) (*types.EventDataCheckTx, error)
+}
+
+// CursorSearchSink is an optional interface implemented by event sinks that
+// can return the results of a transaction search one page at a time, without
+// reading the results of the previous pages.
+type CursorSearchSink interface {
+	// SearchTxEventsAfter returns at most limit results matching the query,
+	// following the given cursor (if any) in descending order of height and
+	// index if desc is true, and in ascending order otherwise. It also
+	// returns the number of matching results following the cursor.
+	SearchTxEventsAfter(ctx context.Context, q *query.Query, after *Cursor, desc bool, limit int) ([]*abci.TxResult, int, error)
+}
+
+// PruningSink is an optional interface implemented by event sinks that can
+// delete the transactions indexed below a height. The pruning service prunes
+// every sink implementing it along with the blockstore.
+type PruningSink interface {
+	// TxRetainHeight returns the height of the first transactions indexed by
+	// the sink which were not pruned.
+	TxRetainHeight() (int64, error)
+
+	// PruneTxEvents deletes the transactions indexed below retainHeight. It
+	// returns the number of transactions deleted.
+	PruneTxEvents(retainHeight int64) (uint64, error)
+}
+
+// ErrBlockUnavailable is returned by an IntentLog for a block that cannot be
+// loaded anymore, e.g. as it was pruned.
+var ErrBlockUnavailable = errors.New("block unavailable")
+
+// IntentLog records the heights of the committed blocks until they are
+// indexed. The intents are recorded atomically with the state of the blocks,
+// and acknowledged once the blocks are indexed by every sink: the IndexerService
+// indexes the blocks of the intents left unacknowledged, e.g. by a crash, on
+// start.
+type IntentLog interface {
+	// Pending returns the heights of the intents not yet acknowledged, in
+	// increasing order.
+	Pending() ([]int64, error)
+
+	// Ack acknowledges the intent of the given height.
+	Ack(height int64) error
+
+	// LoadBlockEvents returns the events of the committed block at the given
+	// height, and the results of its transactions, as published on commit.
+	// It returns ErrBlockUnavailable if the block cannot be loaded.
+	LoadBlockEvents(height int64) (types.EventDataNewBlockHeader, []*abci.TxResult, error)
+}
@@ -2,6 +2,8 @@ package indexer
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/tendermint/tendermint/internal/eventbus"
@@ -17,24 +19,35 @@ type Service struct {
 	service.BaseService
 	logger log.Logger
 
-	eventSinks []EventSink
-	eventBus   *eventbus.EventBus
-	metrics    *Metrics
+	eventSinks   []EventSink
+	eventBus     *eventbus.EventBus
+	metrics      *Metrics
+	indexCheckTx bool
+	intentLog    IntentLog
 
 	currentBlock struct {
 		header types.EventDataNewBlockHeader
 		height int64
 		batch  *Batch
 	}
+
+	// the height and duration of the most recently indexed block, used to
+	// complete the block profile that follows its events
+	lastIndexed struct {
+		height   int64
+		duration time.Duration
+	}
 }
 
 // NewService constructs a new indexer service from the given arguments.
 func NewService(args ServiceArgs) *Service {
 	is := &Service{
-		logger:     args.Logger,
-		eventSinks: args.Sinks,
-		eventBus:   args.EventBus,
-		metrics:    args.Metrics,
+		logger:       args.Logger,
+		eventSinks:   args.Sinks,
+		eventBus:     args.EventBus,
+		metrics:      args.Metrics,
+		indexCheckTx: args.IndexCheckTx,
+		intentLog:    args.IntentLog,
 	}
 	if is.metrics == nil {
 		is.metrics = NopMetrics()
@@ -46,6 +59,16 @@ func NewService(args ServiceArgs) *Service {
 // publish publishes a pubsub message to the service. The service blocks until
 // the message has been fully processed.
 func (is *Service) publish(msg pubsub.Message) error {
+	if profile, ok := msg.Data().(types.EventDataBlockProfile); ok {
+		is.indexBlockProfile(profile)
+		return nil
+	}
+	// The CheckTx responses are published independently of the blocks.
+	if checkTx, ok := msg.Data().(types.EventDataCheckTx); ok {
+		is.indexCheckTxResponse(checkTx)
+		return nil
+	}
+
 	// Indexing has three states. Initially, no block is in progress (WAIT) and
 	// we expect a block header. Upon seeing a header, we are waiting for zero
 	// or more transactions (GATHER). Once all the expected transactions have
@@ -82,38 +105,163 @@ func (is *Service) publish(msg pubsub.Message) error {
 
 	if curr.Pending == 0 {
 		// INDEX: We have all the transactions we expect for the current block.
-		for _, sink := range is.eventSinks {
-			start := time.Now()
-			if err := sink.IndexBlockEvents(is.currentBlock.header); err != nil {
-				is.logger.Error("failed to index block header",
-					"height", is.currentBlock.height, "err", err)
-			} else {
-				is.metrics.BlockEventsSeconds.Observe(time.Since(start).Seconds())
-				is.metrics.BlocksIndexed.Add(1)
-				is.logger.Debug("indexed block",
-					"height", is.currentBlock.height, "sink", sink.Type())
+		// A sink failing to index the block does not keep the other sinks
+		// from indexing it.
+		indexStart := time.Now()
+		is.indexBlock(is.currentBlock.header, curr)
+		is.lastIndexed.height = is.currentBlock.height
+		is.lastIndexed.duration = time.Since(indexStart)
+		is.currentBlock.batch = nil // return to the WAIT state for the next block
+	}
+
+	return nil
+}
+
+// indexBlock indexes a block, and its transactions in curr, in every sink,
+// and acknowledges its index intent if every sink indexed it.
+func (is *Service) indexBlock(header types.EventDataNewBlockHeader, curr *Batch) {
+	indexed := true
+	for _, sink := range is.eventSinks {
+		if !is.indexBlockInSink(sink, header, curr) {
+			indexed = false
+		}
+	}
+	if is.intentLog == nil {
+		return
+	}
+	if !indexed {
+		is.logger.Error("block not indexed by every sink, it will be indexed again on restart",
+			"height", header.Header.Height)
+		return
+	}
+	if err := is.intentLog.Ack(header.Header.Height); err != nil {
+		is.logger.Error("failed to acknowledge the index intent", "height", header.Header.Height, "err", err)
+	}
+}
+
+// indexBlockInSink indexes a block, and its transactions in curr, in sink. It
+// reports whether the sink indexed both.
+func (is *Service) indexBlockInSink(sink EventSink, header types.EventDataNewBlockHeader, curr *Batch) bool {
+	sinkType := string(sink.Type())
+	height := header.Header.Height
+	indexed := true
+
+	start := time.Now()
+	if err := callSink(func() error { return sink.IndexBlockEvents(header) }); err != nil {
+		is.metrics.SinkErrors.With("sink", sinkType, "operation", "block").Add(1)
+		is.logger.Error("failed to index block header",
+			"height", height, "sink", sinkType, "err", err)
+		indexed = false
+	} else {
+		is.metrics.BlockEventsSeconds.With("sink", sinkType).Observe(time.Since(start).Seconds())
+		is.metrics.BlocksIndexed.With("sink", sinkType).Add(1)
+		is.metrics.IndexedHeight.With("sink", sinkType).Set(float64(height))
+		is.logger.Debug("indexed block", "height", height, "sink", sinkType)
+	}
+
+	if curr.Size() != 0 {
+		start := time.Now()
+		if err := callSink(func() error { return sink.IndexTxEvents(curr.Ops) }); err != nil {
+			is.metrics.SinkErrors.With("sink", sinkType, "operation", "txs").Add(1)
+			is.logger.Error("failed to index block txs",
+				"height", height, "sink", sinkType, "err", err)
+			indexed = false
+		} else {
+			is.metrics.TxEventsSeconds.With("sink", sinkType).Observe(time.Since(start).Seconds())
+			is.metrics.TransactionsIndexed.With("sink", sinkType).Add(float64(curr.Size()))
+			is.logger.Debug("indexed txs", "height", height, "sink", sinkType)
+		}
+	}
+	return indexed
+}
+
+// replayIntents indexes the blocks of the index intents left unacknowledged,
+// e.g. as the node crashed between the commit and the indexing of the blocks.
+// The intents of the blocks that cannot be loaded anymore are dropped.
+func (is *Service) replayIntents(ctx context.Context) error {
+	heights, err := is.intentLog.Pending()
+	if err != nil {
+		return fmt.Errorf("loading the index intents: %w", err)
+	}
+	if len(heights) > 0 {
+		is.logger.Info("indexing the blocks committed but not indexed",
+			"blocks", len(heights), "first_height", heights[0], "last_height", heights[len(heights)-1])
+	}
+	for _, height := range heights {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, txs, err := is.intentLog.LoadBlockEvents(height)
+		if errors.Is(err, ErrBlockUnavailable) {
+			is.logger.Error("cannot index unavailable block, dropping its index intent", "height", height, "err", err)
+			if err := is.intentLog.Ack(height); err != nil {
+				return fmt.Errorf("acknowledging the index intent of height %d: %w", height, err)
 			}
+			continue
+		} else if err != nil {
+			return fmt.Errorf("loading the block at height %d: %w", height, err)
+		}
 
-			if curr.Size() != 0 {
-				start := time.Now()
-				err := sink.IndexTxEvents(curr.Ops)
-				if err != nil {
-					is.logger.Error("failed to index block txs",
-						"height", is.currentBlock.height, "err", err)
-				} else {
-					is.metrics.TxEventsSeconds.Observe(time.Since(start).Seconds())
-					is.metrics.TransactionsIndexed.Add(float64(curr.Size()))
-					is.logger.Debug("indexed txs",
-						"height", is.currentBlock.height, "sink", sink.Type())
-				}
+		batch := NewBatch(int64(len(txs)))
+		for _, tx := range txs {
+			if err := batch.Add(tx); err != nil {
+				return fmt.Errorf("adding tx %d of height %d: %w", tx.Index, height, err)
 			}
 		}
-		is.currentBlock.batch = nil // return to the WAIT state for the next block
+		is.indexBlock(header, batch)
+		is.metrics.ReplayedBlocks.Add(1)
 	}
-
 	return nil
 }
 
+// callSink calls fn, an operation of an event sink, and returns the panic of
+// the sink as an error, so that a faulty sink cannot bring down the node.
+func callSink(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event sink panicked: %v", r)
+		}
+	}()
+	return fn()
+}
+
+// indexBlockProfile completes a block profile with the time spent indexing
+// the block and stores it in every sink that supports block profiles.
+// Profiles are published after all other events of their block, so the
+// block has already been indexed when its profile arrives.
+func (is *Service) indexBlockProfile(profile types.EventDataBlockProfile) {
+	if is.lastIndexed.height == profile.Height {
+		profile.Indexing = is.lastIndexed.duration
+	}
+
+	for _, sink := range is.eventSinks {
+		ps, ok := sink.(BlockProfileSink)
+		if !ok {
+			continue
+		}
+		if err := callSink(func() error { return ps.IndexBlockProfile(profile) }); err != nil {
+			is.metrics.SinkErrors.With("sink", string(sink.Type()), "operation", "block_profile").Add(1)
+			is.logger.Error("failed to index block profile",
+				"height", profile.Height, "sink", sink.Type(), "err", err)
+		}
+	}
+}
+
+// indexCheckTxResponse stores a CheckTx response in every sink that supports them.
+func (is *Service) indexCheckTxResponse(checkTx types.EventDataCheckTx) {
+	for _, sink := range is.eventSinks {
+		cs, ok := sink.(CheckTxSink)
+		if !ok {
+			continue
+		}
+		if err := callSink(func() error { return cs.IndexCheckTx(checkTx) }); err != nil {
+			is.metrics.SinkErrors.With("sink", string(sink.Type()), "operation", "check_tx").Add(1)
+			is.logger.Error("failed to index CheckTx response",
+				"tx", checkTx.Tx.Hash(), "sink", sink.Type(), "err", err)
+		}
+	}
+}
+
 // OnStart implements part of service.Service. It registers an observer for the
 // indexer if the underlying event sinks support indexing.
 //
@@ -122,8 +270,21 @@ func (is *Service) OnStart(ctx context.Context) error {
 	// If the event sinks support indexing, register an observer to capture
 	// block header data for the indexer.
 	if IndexingEnabled(is.eventSinks) {
-		err := is.eventBus.Observe(ctx, is.publish,
-			types.EventQueryNewBlockHeader, types.EventQueryTx)
+		// The blocks left unindexed are indexed first, the events of the
+		// blocks replayed by the handshake being published afterwards.
+		if is.intentLog != nil {
+			if err := is.replayIntents(ctx); err != nil {
+				return err
+			}
+		}
+
+		queries := []pubsub.Query{
+			types.EventQueryNewBlockHeader, types.EventQueryTx, types.EventQueryBlockProfile,
+		}
+		if is.indexCheckTx {
+			queries = append(queries, types.EventQueryCheckTx)
+		}
+		err := is.eventBus.Observe(ctx, is.publish, queries...)
 		if err != nil {
 			return err
 		}
@@ -146,6 +307,14 @@ type ServiceArgs struct {
 	EventBus *eventbus.EventBus
 	Metrics  *Metrics
 	Logger   log.Logger
+
+	// IndexCheckTx enables the indexing of the CheckTx responses.
+	IndexCheckTx bool
+
+	// IntentLog, if set, is the log of the index intents of the committed
+	// blocks: the blocks of the unacknowledged intents are indexed on start,
+	// and the intents acknowledged once their blocks are indexed.
+	IntentLog IntentLog
 }
 
 // KVSinkEnabled returns the given eventSinks is containing KVEventSink.
@@ -3,8 +3,11 @@ package indexer_test
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -110,6 +113,174 @@ func TestIndexerServiceIndexesBlocks(t *testing.T) {
 	assert.Nil(t, teardown(t, pool))
 }
 
+func TestIndexerServiceIndexesCheckTx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := tmlog.TestingLogger()
+	eventBus := eventbus.NewDefault(logger)
+	require.NoError(t, eventBus.Start(ctx))
+	t.Cleanup(eventBus.Wait)
+
+	sink := kv.NewEventSink(dbm.NewMemDB())
+	service := indexer.NewService(indexer.ServiceArgs{
+		Logger:       logger,
+		Sinks:        []indexer.EventSink{sink},
+		EventBus:     eventBus,
+		IndexCheckTx: true,
+	})
+	require.NoError(t, service.Start(ctx))
+	t.Cleanup(service.Wait)
+
+	checkTx := types.EventDataCheckTx{Tx: types.Tx("foo"), Height: 1, Result: abci.ResponseCheckTx{
+		Events: []abci.Event{{Type: "fee", Attributes: []abci.EventAttribute{{Key: "tier", Value: "high"}}}},
+	}}
+	require.NoError(t, eventBus.PublishEventCheckTx(ctx, checkTx))
+
+	time.Sleep(100 * time.Millisecond)
+
+	res, err := sink.(indexer.CheckTxSink).GetCheckTx(types.Tx("foo").Hash())
+	require.NoError(t, err)
+	require.Equal(t, &checkTx, res)
+}
+
+// faultySink fails to index the blocks, and panics on their transactions.
+type faultySink struct {
+	indexer.EventSink
+}
+
+func (faultySink) IndexBlockEvents(types.EventDataNewBlockHeader) error {
+	return errors.New("database unavailable")
+}
+
+func (faultySink) IndexTxEvents([]*abci.TxResult) error {
+	panic("corrupted database")
+}
+
+func TestIndexerServiceIsolatesSinks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := tmlog.TestingLogger()
+	eventBus := eventbus.NewDefault(logger)
+	require.NoError(t, eventBus.Start(ctx))
+	t.Cleanup(eventBus.Wait)
+
+	// The sinks following the faulty one still index the blocks.
+	sink := kv.NewEventSink(dbm.NewMemDB())
+	service := indexer.NewService(indexer.ServiceArgs{
+		Logger:   logger,
+		Sinks:    []indexer.EventSink{faultySink{EventSink: kv.NewEventSink(dbm.NewMemDB())}, sink},
+		EventBus: eventBus,
+	})
+	require.NoError(t, service.Start(ctx))
+	t.Cleanup(service.Wait)
+
+	require.NoError(t, eventBus.PublishEventNewBlockHeader(ctx, types.EventDataNewBlockHeader{
+		Header: types.Header{Height: 1},
+		NumTxs: 1,
+	}))
+	txResult := &abci.TxResult{Height: 1, Tx: types.Tx("foo")}
+	require.NoError(t, eventBus.PublishEventTx(ctx, types.EventDataTx{TxResult: *txResult}))
+
+	require.Eventually(t, func() bool {
+		ok, err := sink.HasBlock(1)
+		return err == nil && ok
+	}, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		res, err := sink.GetTxByHash(types.Tx("foo").Hash())
+		return err == nil && res != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+// memIntentLog is an in-memory indexer.IntentLog.
+type memIntentLog struct {
+	mtx     sync.Mutex
+	pending map[int64]bool
+	blocks  map[int64][]*abci.TxResult
+}
+
+func (l *memIntentLog) Pending() ([]int64, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	var heights []int64
+	for height := range l.pending {
+		heights = append(heights, height)
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights, nil
+}
+
+func (l *memIntentLog) Ack(height int64) error {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	delete(l.pending, height)
+	return nil
+}
+
+func (l *memIntentLog) LoadBlockEvents(height int64) (types.EventDataNewBlockHeader, []*abci.TxResult, error) {
+	txs, ok := l.blocks[height]
+	if !ok {
+		return types.EventDataNewBlockHeader{}, nil, indexer.ErrBlockUnavailable
+	}
+	header := types.EventDataNewBlockHeader{Header: types.Header{Height: height}, NumTxs: int64(len(txs))}
+	return header, txs, nil
+}
+
+func TestIndexerServiceReplaysIntents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := tmlog.TestingLogger()
+	eventBus := eventbus.NewDefault(logger)
+	require.NoError(t, eventBus.Start(ctx))
+	t.Cleanup(eventBus.Wait)
+
+	// The block at height 1 was pruned, the one at height 2 was committed
+	// but not indexed.
+	intentLog := &memIntentLog{
+		pending: map[int64]bool{1: true, 2: true},
+		blocks: map[int64][]*abci.TxResult{
+			2: {{Height: 2, Index: 0, Tx: types.Tx("foo")}},
+		},
+	}
+	sink := kv.NewEventSink(dbm.NewMemDB())
+	service := indexer.NewService(indexer.ServiceArgs{
+		Logger:    logger,
+		Sinks:     []indexer.EventSink{sink},
+		EventBus:  eventBus,
+		IntentLog: intentLog,
+	})
+	require.NoError(t, service.Start(ctx))
+	t.Cleanup(service.Wait)
+
+	// The blocks are indexed on start.
+	ok, err := sink.HasBlock(2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	res, err := sink.GetTxByHash(types.Tx("foo").Hash())
+	require.NoError(t, err)
+	require.NotNil(t, res)
+	pending, err := intentLog.Pending()
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// The intents of the blocks indexed afterwards are acknowledged.
+	intentLog.mtx.Lock()
+	intentLog.pending[3] = true
+	intentLog.mtx.Unlock()
+	require.NoError(t, eventBus.PublishEventNewBlockHeader(ctx, types.EventDataNewBlockHeader{
+		Header: types.Header{Height: 3},
+	}))
+	require.Eventually(t, func() bool {
+		pending, err := intentLog.Pending()
+		return err == nil && len(pending) == 0
+	}, time.Second, 10*time.Millisecond)
+	ok, err = sink.HasBlock(3)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
 func readSchema() ([]*schema.Migration, error) {
 	filename := "./sink/psql/schema.sql"
 	contents, err := os.ReadFile(filename)
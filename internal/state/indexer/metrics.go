@@ -24,40 +24,72 @@ type Metrics struct {
 
 	// Number of transactions indexed.
 	TransactionsIndexed metrics.Counter
+
+	// Height of the last block indexed.
+	IndexedHeight metrics.Gauge
+
+	// Number of failed indexing operations.
+	SinkErrors metrics.Counter
+
+	// Number of blocks indexed on start, from the unacknowledged index
+	// intents.
+	ReplayedBlocks metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
 // Optionally, labels can be provided along with their values ("foo",
 // "fooValue").
+//
+// The metrics are labeled with the type of the event sink, and the errors with
+// the operation that failed: "block", "txs", "block_profile" or "check_tx".
 func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 	labels := []string{}
 	for i := 0; i < len(labelsAndValues); i += 2 {
 		labels = append(labels, labelsAndValues[i])
 	}
+	sinkLabels := append(labels, "sink")
 	return &Metrics{
 		BlockEventsSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "block_events_seconds",
 			Help:      "Latency for indexing block events.",
-		}, labels).With(labelsAndValues...),
+		}, sinkLabels).With(labelsAndValues...),
 		TxEventsSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "tx_events_seconds",
 			Help:      "Latency for indexing transaction events.",
-		}, labels).With(labelsAndValues...),
+		}, sinkLabels).With(labelsAndValues...),
 		BlocksIndexed: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "blocks_indexed",
 			Help:      "Number of complete blocks indexed.",
-		}, labels).With(labelsAndValues...),
+		}, sinkLabels).With(labelsAndValues...),
 		TransactionsIndexed: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: namespace,
 			Subsystem: MetricsSubsystem,
 			Name:      "transactions_indexed",
 			Help:      "Number of transactions indexed.",
+		}, sinkLabels).With(labelsAndValues...),
+		IndexedHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "indexed_height",
+			Help:      "Height of the last block indexed.",
+		}, sinkLabels).With(labelsAndValues...),
+		SinkErrors: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "sink_errors",
+			Help:      "Number of failed indexing operations.",
+		}, append(sinkLabels, "operation")).With(labelsAndValues...),
+		ReplayedBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "replayed_blocks",
+			Help:      "Number of blocks indexed on start, from the unacknowledged index intents.",
 		}, labels).With(labelsAndValues...),
 	}
 }
@@ -69,5 +101,8 @@ func NopMetrics() *Metrics {
 		TxEventsSeconds:     discard.NewHistogram(),
 		BlocksIndexed:       discard.NewCounter(),
 		TransactionsIndexed: discard.NewCounter(),
+		IndexedHeight:       discard.NewGauge(),
+		SinkErrors:          discard.NewCounter(),
+		ReplayedBlocks:      discard.NewCounter(),
 	}
 }
@@ -1,6 +1,8 @@
 package indexer
 
 import (
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/tendermint/tendermint/internal/pubsub/query/syntax"
@@ -13,8 +15,8 @@ type QueryRanges map[string]QueryRange
 
 // QueryRange defines a range within a query condition.
 type QueryRange struct {
-	LowerBound        interface{} // int || time.Time
-	UpperBound        interface{} // int || time.Time
+	LowerBound        interface{} // float64 || time.Time
+	UpperBound        interface{} // float64 || time.Time
 	Key               string
 	IncludeLowerBound bool
 	IncludeUpperBound bool
@@ -29,49 +31,80 @@ func (qr QueryRange) AnyBound() interface{} {
 	return qr.UpperBound
 }
 
-// LowerBoundValue returns the value for the lower bound. If the lower bound is
-// nil, nil will be returned.
-func (qr QueryRange) LowerBoundValue() interface{} {
-	if qr.LowerBound == nil {
-		return nil
-	}
-
-	if qr.IncludeLowerBound {
-		return qr.LowerBound
-	}
-
-	switch t := qr.LowerBound.(type) {
-	case int64:
-		return t + 1
+// Matches reports whether value, the value of an event attribute, is within
+// the range, honoring the inclusiveness of the bounds. The value is compared
+// as a number, or, if the bounds are times, as a timestamp or a datestamp.
+// Values that cannot be compared with the bounds are not within the range.
+func (qr QueryRange) Matches(value string) bool {
+	var v interface{}
+	switch qr.AnyBound().(type) {
+	case float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(f) {
+			return false
+		}
+		v = f
 
 	case time.Time:
-		return t.Unix() + 1
+		t, err := syntax.ParseTime(value)
+		if err != nil {
+			if t, err = syntax.ParseDate(value); err != nil {
+				return false
+			}
+		}
+		v = t
 
 	default:
-		panic("not implemented")
+		return false
 	}
-}
 
-// UpperBoundValue returns the value for the upper bound. If the upper bound is
-// nil, nil will be returned.
-func (qr QueryRange) UpperBoundValue() interface{} {
-	if qr.UpperBound == nil {
-		return nil
+	if qr.LowerBound != nil {
+		c, ok := compareBound(v, qr.LowerBound)
+		if !ok || c < 0 || (c == 0 && !qr.IncludeLowerBound) {
+			return false
+		}
 	}
-
-	if qr.IncludeUpperBound {
-		return qr.UpperBound
+	if qr.UpperBound != nil {
+		c, ok := compareBound(v, qr.UpperBound)
+		if !ok || c > 0 || (c == 0 && !qr.IncludeUpperBound) {
+			return false
+		}
 	}
+	return true
+}
 
-	switch t := qr.UpperBound.(type) {
-	case int64:
-		return t - 1
+// compareBound compares a and b, two numbers or two times, returning -1, 0 or
+// +1, and reports whether they can be compared.
+func compareBound(a, b interface{}) (int, bool) {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		switch {
+		case !ok:
+			return 0, false
+		case a < b:
+			return -1, true
+		case a > b:
+			return 1, true
+		default:
+			return 0, true
+		}
 
 	case time.Time:
-		return t.Unix() - 1
+		b, ok := b.(time.Time)
+		switch {
+		case !ok:
+			return 0, false
+		case a.Before(b):
+			return -1, true
+		case a.After(b):
+			return 1, true
+		default:
+			return 0, true
+		}
 
 	default:
-		panic("not implemented")
+		return 0, false
 	}
 }
 
@@ -86,20 +119,21 @@ func LookForRanges(conditions []syntax.Condition) (ranges QueryRanges, indexes [
 				r = QueryRange{Key: c.Tag}
 			}
 
+			// Several bounds on the same side are all required: the
+			// tightest one is kept.
+			arg := conditionArg(c)
 			switch c.Op {
-			case syntax.TGt:
-				r.LowerBound = conditionArg(c)
-
-			case syntax.TGeq:
-				r.IncludeLowerBound = true
-				r.LowerBound = conditionArg(c)
-
-			case syntax.TLt:
-				r.UpperBound = conditionArg(c)
-
-			case syntax.TLeq:
-				r.IncludeUpperBound = true
-				r.UpperBound = conditionArg(c)
+			case syntax.TGt, syntax.TGeq:
+				inclusive := c.Op == syntax.TGeq
+				if cmp, ok := compareBound(arg, r.LowerBound); !ok || cmp > 0 || (cmp == 0 && !inclusive) {
+					r.LowerBound, r.IncludeLowerBound = arg, inclusive
+				}
+
+			case syntax.TLt, syntax.TLeq:
+				inclusive := c.Op == syntax.TLeq
+				if cmp, ok := compareBound(arg, r.UpperBound); !ok || cmp < 0 || (cmp == 0 && !inclusive) {
+					r.UpperBound, r.IncludeUpperBound = arg, inclusive
+				}
 			}
 
 			ranges[c.Tag] = r
@@ -128,7 +162,7 @@ func conditionArg(c syntax.Condition) interface{} {
 	}
 	switch c.Arg.Type {
 	case syntax.TNumber:
-		return int64(c.Arg.Number())
+		return c.Arg.Number()
 	case syntax.TTime, syntax.TDate:
 		return c.Arg.Time()
 	default:
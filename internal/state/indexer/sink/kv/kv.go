@@ -2,7 +2,9 @@ package kv
 
 import (
 	"context"
+	"encoding/json"
 
+	"github.com/google/orderedcode"
 	dbm "github.com/tendermint/tm-db"
 
 	abci "github.com/tendermint/tendermint/abci/types"
@@ -14,6 +16,16 @@ import (
 )
 
 var _ indexer.EventSink = (*EventSink)(nil)
+var _ indexer.BlockProfileSink = (*EventSink)(nil)
+var _ indexer.CursorSearchSink = (*EventSink)(nil)
+var _ indexer.CheckTxSink = (*EventSink)(nil)
+var _ indexer.PruningSink = (*EventSink)(nil)
+
+const (
+	blockProfileKey = "block.profile"
+	checkTxKey      = "check.tx"
+	txRetainKey     = "tx.retain_height"
+)
 
 // The EventSink is an aggregator for redirecting the call path of the tx/block kvIndexer.
 // For the implementation details please see the kv.go in the indexer/block and indexer/tx folder.
@@ -51,6 +63,16 @@ func (kves *EventSink) SearchTxEvents(ctx context.Context, q *query.Query) ([]*a
 	return kves.txi.Search(ctx, q)
 }
 
+func (kves *EventSink) SearchTxEventsAfter(
+	ctx context.Context,
+	q *query.Query,
+	after *indexer.Cursor,
+	desc bool,
+	limit int,
+) ([]*abci.TxResult, int, error) {
+	return kves.txi.SearchAfter(ctx, q, after, desc, limit)
+}
+
 func (kves *EventSink) GetTxByHash(hash []byte) (*abci.TxResult, error) {
 	return kves.txi.Get(hash)
 }
@@ -59,6 +81,98 @@ func (kves *EventSink) HasBlock(h int64) (bool, error) {
 	return kves.bi.Has(h)
 }
 
+func (kves *EventSink) IndexBlockProfile(p types.EventDataBlockProfile) error {
+	key, err := orderedcode.Append(nil, blockProfileKey, p.Height)
+	if err != nil {
+		return err
+	}
+	bz, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return kves.store.Set(key, bz)
+}
+
+func (kves *EventSink) GetBlockProfile(height int64) (*types.EventDataBlockProfile, error) {
+	key, err := orderedcode.Append(nil, blockProfileKey, height)
+	if err != nil {
+		return nil, err
+	}
+	bz, err := kves.store.Get(key)
+	if err != nil || bz == nil {
+		return nil, err
+	}
+	p := new(types.EventDataBlockProfile)
+	if err := json.Unmarshal(bz, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (kves *EventSink) IndexCheckTx(c types.EventDataCheckTx) error {
+	key, err := orderedcode.Append(nil, checkTxKey, string(c.Tx.Hash()))
+	if err != nil {
+		return err
+	}
+	bz, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return kves.store.Set(key, bz)
+}
+
+func (kves *EventSink) GetCheckTx(hash []byte) (*types.EventDataCheckTx, error) {
+	key, err := orderedcode.Append(nil, checkTxKey, string(hash))
+	if err != nil {
+		return nil, err
+	}
+	bz, err := kves.store.Get(key)
+	if err != nil || bz == nil {
+		return nil, err
+	}
+	c := new(types.EventDataCheckTx)
+	if err := json.Unmarshal(bz, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (kves *EventSink) TxRetainHeight() (int64, error) {
+	key, err := orderedcode.Append(nil, txRetainKey)
+	if err != nil {
+		return 0, err
+	}
+	bz, err := kves.store.Get(key)
+	if err != nil || bz == nil {
+		return 1, err
+	}
+	var height int64
+	if _, err := orderedcode.Parse(string(bz), &height); err != nil {
+		return 0, err
+	}
+	return height, nil
+}
+
+func (kves *EventSink) PruneTxEvents(retainHeight int64) (uint64, error) {
+	base, err := kves.TxRetainHeight()
+	if err != nil || retainHeight <= base {
+		return 0, err
+	}
+	pruned, err := kves.txi.Prune(base, retainHeight)
+	if err != nil {
+		return 0, err
+	}
+	key, err := orderedcode.Append(nil, txRetainKey)
+	if err != nil {
+		return pruned, err
+	}
+	bz, err := orderedcode.Append(nil, retainHeight)
+	if err != nil {
+		return pruned, err
+	}
+	return pruned, kves.store.SetSync(key, bz)
+}
+
 func (kves *EventSink) Stop() error {
 	return kves.store.Close()
 }
@@ -27,6 +27,46 @@ func TestStop(t *testing.T) {
 	assert.Nil(t, kvSink.Stop())
 }
 
+func TestBlockProfile(t *testing.T) {
+	kvSink := NewEventSink(dbm.NewMemDB())
+	ps, ok := kvSink.(indexer.BlockProfileSink)
+	require.True(t, ok)
+
+	p, err := ps.GetBlockProfile(1)
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	want := types.EventDataBlockProfile{Height: 1, BeginBlock: 5, Indexing: 7}
+	require.NoError(t, ps.IndexBlockProfile(want))
+
+	p, err = ps.GetBlockProfile(1)
+	require.NoError(t, err)
+	require.Equal(t, want, *p)
+}
+
+func TestCheckTx(t *testing.T) {
+	kvSink := NewEventSink(dbm.NewMemDB())
+	cs, ok := kvSink.(indexer.CheckTxSink)
+	require.True(t, ok)
+
+	tx := types.Tx("HELLO WORLD")
+	c, err := cs.GetCheckTx(tx.Hash())
+	require.NoError(t, err)
+	require.Nil(t, c)
+
+	want := types.EventDataCheckTx{Tx: tx, Height: 3, Result: abci.ResponseCheckTx{
+		GasWanted: 10,
+		Events: []abci.Event{
+			{Type: "fee", Attributes: []abci.EventAttribute{{Key: "tier", Value: "high", Index: true}}},
+		},
+	}}
+	require.NoError(t, cs.IndexCheckTx(want))
+
+	c, err = cs.GetCheckTx(tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, want, *c)
+}
+
 func TestBlockFuncs(t *testing.T) {
 	store := dbm.NewPrefixDB(dbm.NewMemDB(), []byte("block_events"))
 	indexer := NewEventSink(store)
@@ -2,6 +2,7 @@ package sink
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/tendermint/tendermint/config"
@@ -12,29 +13,22 @@ import (
 )
 
 // EventSinksFromConfig constructs a slice of indexer.EventSink using the provided
-// configuration.
+// configuration, in the order of the configured indexers. The sinks index
+// every block independently, so that a failing sink does not hold back the
+// others. The "null" indexer disables indexing if it is the only one, and is
+// ignored alongside other indexers.
 func EventSinksFromConfig(cfg *config.Config, dbProvider config.DBProvider, chainID string) ([]indexer.EventSink, error) {
-	if len(cfg.TxIndex.Indexer) == 0 {
+	sinkTypes, err := sinkTypesFromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(sinkTypes) == 0 {
 		return []indexer.EventSink{null.NewEventSink()}, nil
 	}
 
-	// check for duplicated sinks
-	sinks := map[string]struct{}{}
-	for _, s := range cfg.TxIndex.Indexer {
-		sl := strings.ToLower(s)
-		if _, ok := sinks[sl]; ok {
-			return nil, errors.New("found duplicated sinks, please check the tx-index section in the config.toml")
-		}
-		sinks[sl] = struct{}{}
-	}
 	eventSinks := []indexer.EventSink{}
-	for k := range sinks {
-		switch indexer.EventSinkType(k) {
-		case indexer.NULL:
-			// When we see null in the config, the eventsinks will be reset with the
-			// nullEventSink.
-			return []indexer.EventSink{null.NewEventSink()}, nil
-
+	for _, t := range sinkTypes {
+		switch t {
 		case indexer.KV:
 			store, err := dbProvider(&config.DBContext{ID: "tx_index", Config: cfg})
 			if err != nil {
@@ -55,9 +49,34 @@ func EventSinksFromConfig(cfg *config.Config, dbProvider config.DBProvider, chai
 			}
 			eventSinks = append(eventSinks, es)
 		default:
-			return nil, errors.New("unsupported event sink type")
+			return nil, fmt.Errorf("unsupported event sink type %q", t)
 		}
 	}
 	return eventSinks, nil
+}
 
+// IndexingEnabled reports whether the configuration enables an indexer other
+// than "null".
+func IndexingEnabled(cfg *config.Config) bool {
+	sinkTypes, err := sinkTypesFromConfig(cfg)
+	return err == nil && len(sinkTypes) > 0
+}
+
+// sinkTypesFromConfig returns the types of the configured indexers other than
+// "null", in order.
+func sinkTypesFromConfig(cfg *config.Config) ([]indexer.EventSinkType, error) {
+	// check for duplicated sinks
+	var sinkTypes []indexer.EventSinkType
+	seen := map[indexer.EventSinkType]bool{}
+	for _, s := range cfg.TxIndex.Indexer {
+		t := indexer.EventSinkType(strings.ToLower(s))
+		if seen[t] {
+			return nil, errors.New("found duplicated sinks, please check the tx-index section in the config.toml")
+		}
+		seen[t] = true
+		if t != indexer.NULL && t != "" {
+			sinkTypes = append(sinkTypes, t)
+		}
+	}
+	return sinkTypes, nil
 }
@@ -0,0 +1,47 @@
+package sink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+)
+
+func TestEventSinksFromConfig(t *testing.T) {
+	testCases := []struct {
+		indexers []string
+		psqlConn string
+		types    []indexer.EventSinkType
+		err      bool
+	}{
+		{nil, "", []indexer.EventSinkType{indexer.NULL}, false},
+		{[]string{"null"}, "", []indexer.EventSinkType{indexer.NULL}, false},
+		{[]string{"kv"}, "", []indexer.EventSinkType{indexer.KV}, false},
+		// null is ignored alongside other sinks
+		{[]string{"null", "KV"}, "", []indexer.EventSinkType{indexer.KV}, false},
+		{[]string{"kv", "KV"}, "", nil, true},
+		{[]string{"kv", "psql"}, "", nil, true}, // no connection settings
+		{[]string{"kv", "unknown"}, "", nil, true},
+	}
+
+	for _, tc := range testCases {
+		cfg := config.TestConfig()
+		cfg.TxIndex.Indexer = tc.indexers
+		cfg.TxIndex.PsqlConn = tc.psqlConn
+
+		sinks, err := EventSinksFromConfig(cfg, config.DefaultDBProvider, "test-chain")
+		if tc.err {
+			assert.Error(t, err, tc.indexers)
+			continue
+		}
+		require.NoError(t, err, tc.indexers)
+		var types []indexer.EventSinkType
+		for _, sink := range sinks {
+			types = append(types, sink.Type())
+		}
+		assert.Equal(t, tc.types, types, tc.indexers)
+	}
+}
@@ -1,10 +1,11 @@
 package kv
 
 import (
+	"bytes"
 	"context"
 	"encoding/hex"
 	"fmt"
-	"strconv"
+	"sort"
 	"strings"
 
 	"github.com/gogo/protobuf/proto"
@@ -96,6 +97,83 @@ func (txi *TxIndex) Index(results []*abci.TxResult) error {
 	return b.WriteSync()
 }
 
+// Prune deletes the transactions indexed at the heights from fromHeight up
+// to (but not including) toHeight, with their events. It returns the number of
+// transactions deleted.
+func (txi *TxIndex) Prune(fromHeight, toHeight int64) (uint64, error) {
+	b := txi.store.NewBatch()
+	defer b.Close()
+
+	var pruned uint64
+	for height := fromHeight; height < toHeight; height++ {
+		n, err := txi.pruneHeight(height, b)
+		if err != nil {
+			return 0, err
+		}
+		pruned += n
+	}
+	if err := b.WriteSync(); err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+func (txi *TxIndex) pruneHeight(height int64, b dbm.Batch) (uint64, error) {
+	// The keys are collected before reading the transactions, not to read the
+	// store while iterating over it.
+	keys, hashes, err := txi.heightKeys(height)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, hash := range hashes {
+		if err := b.Delete(keys[i]); err != nil {
+			return 0, err
+		}
+
+		// A transaction included again at a later height is indexed by its
+		// last inclusion, which must be kept.
+		result, err := txi.Get(hash)
+		if err != nil {
+			return 0, err
+		}
+		if result == nil || result.Height != height {
+			continue
+		}
+		for _, event := range result.Result.Events {
+			for _, attr := range event.Attributes {
+				if len(event.Type) == 0 || len(attr.Key) == 0 || !attr.GetIndex() {
+					continue
+				}
+				compositeTag := fmt.Sprintf("%s.%s", event.Type, attr.Key)
+				if err := b.Delete(keyFromEvent(compositeTag, attr.Value, result)); err != nil {
+					return 0, err
+				}
+			}
+		}
+		if err := b.Delete(primaryKey(hash)); err != nil {
+			return 0, err
+		}
+	}
+	return uint64(len(hashes)), nil
+}
+
+// heightKeys returns the height keys of the transactions indexed at height,
+// with their hashes.
+func (txi *TxIndex) heightKeys(height int64) (keys, hashes [][]byte, err error) {
+	it, err := dbm.IteratePrefix(txi.store, prefixFromCompositeKeyAndValue(types.TxHeightKey, fmt.Sprintf("%d", height)))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		keys = append(keys, it.Key())
+		hashes = append(hashes, it.Value())
+	}
+	return keys, hashes, it.Error()
+}
+
 func (txi *TxIndex) indexEvents(result *abci.TxResult, hash []byte, store dbm.Batch) error {
 	for _, event := range result.Result.Events {
 		// only index events with a non-empty type
@@ -145,9 +223,6 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 	default:
 	}
 
-	var hashesInitialized bool
-	filteredHashes := make(map[string][]byte)
-
 	// get a list of conditions (like "tx.height > 5")
 	conditions := q.Syntax()
 
@@ -167,6 +242,123 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 		}
 	}
 
+	filteredHashes := txi.matchConditions(ctx, conditions, nil, false)
+
+	results := make([]*abci.TxResult, 0, len(filteredHashes))
+hashes:
+	for h := range filteredHashes {
+		res, err := txi.Get([]byte(h))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
+		}
+		results = append(results, res)
+
+		// Potentially exit early.
+		select {
+		case <-ctx.Done():
+			break hashes
+		default:
+		}
+	}
+
+	return results, nil
+}
+
+// SearchAfter performs a search like Search, and returns at most limit
+// results following the given cursor, if any, in descending order of height
+// and index if desc is true, and in ascending order otherwise. It also
+// returns the number of results following the cursor.
+//
+// Unlike Search, it only reads the results it returns: the matches are
+// ordered by the height and index recorded in the keys of the index. The
+// equality conditions are only matched from the cursor on.
+func (txi *TxIndex) SearchAfter(
+	ctx context.Context,
+	q *query.Query,
+	after *indexer.Cursor,
+	desc bool,
+	limit int,
+) ([]*abci.TxResult, int, error) {
+	select {
+	case <-ctx.Done():
+		return make([]*abci.TxResult, 0), 0, nil
+
+	default:
+	}
+
+	conditions := q.Syntax()
+
+	// if there is a hash condition, return the result immediately
+	hash, ok, err := lookForHash(conditions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error during searching for a hash in the query: %w", err)
+	} else if ok {
+		res, err := txi.Get(hash)
+		switch {
+		case err != nil:
+			return []*abci.TxResult{}, 0, fmt.Errorf("error while retrieving the result: %w", err)
+		case res == nil, after != nil && !after.Follows(res.Height, res.Index, desc):
+			return []*abci.TxResult{}, 0, nil
+		case limit <= 0:
+			return []*abci.TxResult{}, 1, nil
+		default:
+			return []*abci.TxResult{res}, 1, nil
+		}
+	}
+
+	filteredHashes := txi.matchConditions(ctx, conditions, after, desc)
+
+	positions := make([]indexer.Cursor, 0, len(filteredHashes))
+	hashes := make(map[indexer.Cursor]string, len(filteredHashes))
+	for h, key := range filteredHashes {
+		height, index, err := parsePositionFromKey(key)
+		if err != nil {
+			// e.g. the key of block events indexed in the same store
+			continue
+		}
+		if after != nil && !after.Follows(height, index, desc) {
+			continue
+		}
+		pos := indexer.Cursor{Height: height, Index: index}
+		positions = append(positions, pos)
+		hashes[pos] = h
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Follows(positions[j].Height, positions[j].Index, desc)
+	})
+
+	if limit > len(positions) {
+		limit = len(positions)
+	}
+	results := make([]*abci.TxResult, 0, limit)
+	for _, pos := range positions[:limit] {
+		res, err := txi.Get([]byte(hashes[pos]))
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to get Tx{%X}: %w", hashes[pos], err)
+		}
+		results = append(results, res)
+	}
+
+	return results, len(positions), nil
+}
+
+// matchConditions returns the txs matching all the conditions, as a map of
+// their hashes to one of their keys in the index. If after is not nil, the
+// equality conditions are only matched by the txs following it, in
+// descending order if desc is true.
+//
+// The OR and IN conditions match the txs matching any of their alternatives,
+// and the NOT conditions are matched last, removing the txs matching their
+// operand from the matches of the other conditions, or from all the txs.
+func (txi *TxIndex) matchConditions(
+	ctx context.Context,
+	conditions []syntax.Condition,
+	after *indexer.Cursor,
+	desc bool,
+) map[string][]byte {
+	var hashesInitialized bool
+	filteredHashes := make(map[string][]byte)
+
 	// conditions to skip because they're handled before "everything else"
 	skipIndexes := make([]int, 0)
 
@@ -198,12 +390,35 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 
 	// for all other conditions
 	for i, c := range conditions {
-		if intInSlice(i, skipIndexes) {
+		if intInSlice(i, skipIndexes) || c.Op == syntax.TNot {
 			continue
 		}
 
+		var matches map[string][]byte
+		switch {
+		case c.Op == syntax.TOr || c.Op == syntax.TIn:
+			matches = txi.matchAny(ctx, c.Alternatives(), after, desc)
+		case c.Tag == types.TxHashKey && c.Op == syntax.TEq:
+			// e.g. an alternative of an OR condition
+			matches = txi.matchHash(c)
+		}
+		if matches != nil {
+			filteredHashes = intersectHashes(filteredHashes, matches, !hashesInitialized)
+			hashesInitialized = true
+			if len(filteredHashes) == 0 {
+				break
+			}
+			continue
+		}
+
+		startKey := prefixForCondition(c, height)
+		endKey := prefixEnd(startKey)
+		if after != nil && c.Op == syntax.TEq {
+			startKey, endKey = narrowToCursor(c, startKey, endKey, *after, desc)
+		}
+
 		if !hashesInitialized {
-			filteredHashes = txi.match(ctx, c, prefixForCondition(c, height), filteredHashes, true)
+			filteredHashes = txi.match(ctx, c, startKey, endKey, filteredHashes, true)
 			hashesInitialized = true
 
 			// Ignore any remaining conditions if the first condition resulted
@@ -212,33 +427,104 @@ func (txi *TxIndex) Search(ctx context.Context, q *query.Query) ([]*abci.TxResul
 				break
 			}
 		} else {
-			filteredHashes = txi.match(ctx, c, prefixForCondition(c, height), filteredHashes, false)
+			filteredHashes = txi.match(ctx, c, startKey, endKey, filteredHashes, false)
 		}
 	}
 
-	results := make([]*abci.TxResult, 0, len(filteredHashes))
-hashes:
-	for _, h := range filteredHashes {
-		res, err := txi.Get(h)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get Tx{%X}: %w", h, err)
+	for _, c := range conditions {
+		if c.Op != syntax.TNot {
+			continue
 		}
-		results = append(results, res)
+		if !hashesInitialized {
+			filteredHashes = txi.matchAll(ctx)
+			hashesInitialized = true
+		}
+		if len(filteredHashes) == 0 {
+			break
+		}
+		for h := range txi.matchConditions(ctx, c.Sub[0], nil, false) {
+			delete(filteredHashes, h)
+		}
+	}
+
+	return filteredHashes
+}
+
+// matchAny returns the txs matching any of the queries, e.g. the alternatives
+// of an OR condition.
+func (txi *TxIndex) matchAny(
+	ctx context.Context,
+	queries []syntax.Query,
+	after *indexer.Cursor,
+	desc bool,
+) map[string][]byte {
+	matches := make(map[string][]byte)
+	for _, q := range queries {
+		for h, key := range txi.matchConditions(ctx, q, after, desc) {
+			matches[h] = key
+		}
+	}
+	return matches
+}
+
+// matchHash returns the tx whose hash is the argument of the condition c, if
+// it is indexed.
+func (txi *TxIndex) matchHash(c syntax.Condition) map[string][]byte {
+	matches := make(map[string][]byte)
+	hash, err := hex.DecodeString(c.Arg.Value())
+	if err != nil {
+		return matches
+	}
+	res, err := txi.Get(hash)
+	if err != nil {
+		panic(err)
+	}
+	if res != nil {
+		matches[string(hash)] = KeyFromHeight(res)
+	}
+	return matches
+}
+
+// matchAll returns all the indexed txs, by their height keys.
+func (txi *TxIndex) matchAll(ctx context.Context) map[string][]byte {
+	matches := make(map[string][]byte)
+	it, err := dbm.IteratePrefix(txi.store, prefixFromCompositeKey(types.TxHeightKey))
+	if err != nil {
+		panic(err)
+	}
+	defer it.Close()
+
+	for ; it.Valid(); it.Next() {
+		matches[string(it.Value())] = it.Key()
 
 		// Potentially exit early.
-		select {
-		case <-ctx.Done():
-			break hashes
-		default:
+		if ctx.Err() != nil {
+			break
 		}
 	}
+	if err := it.Error(); err != nil {
+		panic(err)
+	}
+	return matches
+}
 
-	return results, nil
+// intersectHashes returns the hashes of filteredHashes also in matches, or
+// matches if firstRun is true.
+func intersectHashes(filteredHashes, matches map[string][]byte, firstRun bool) map[string][]byte {
+	if firstRun {
+		return matches
+	}
+	for h := range filteredHashes {
+		if matches[h] == nil {
+			delete(filteredHashes, h)
+		}
+	}
+	return filteredHashes
 }
 
 func lookForHash(conditions []syntax.Condition) (hash []byte, ok bool, err error) {
 	for _, c := range conditions {
-		if c.Tag == types.TxHashKey {
+		if c.Tag == types.TxHashKey && c.Arg != nil {
 			decoded, err := hex.DecodeString(c.Arg.Value())
 			return decoded, true, err
 		}
@@ -256,15 +542,17 @@ func lookForHeight(conditions []syntax.Condition) (height int64) {
 	return 0
 }
 
-// match returns all matching txs by hash that meet a given condition and start
-// key. An already filtered result (filteredHashes) is provided such that any
+// match returns all matching txs by hash that meet a given condition, mapped
+// to their key in the index. The equality conditions are matched by the keys
+// from startKeyBz (inclusive) to endKeyBz (exclusive, nil for no end). An
+// already filtered result (filteredHashes) is provided such that any
 // non-intersecting matches are removed.
 //
 // NOTE: filteredHashes may be empty if no previous condition has matched.
 func (txi *TxIndex) match(
 	ctx context.Context,
 	c syntax.Condition,
-	startKeyBz []byte,
+	startKeyBz, endKeyBz []byte,
 	filteredHashes map[string][]byte,
 	firstRun bool,
 ) map[string][]byte {
@@ -278,7 +566,10 @@ func (txi *TxIndex) match(
 
 	switch {
 	case c.Op == syntax.TEq:
-		it, err := dbm.IteratePrefix(txi.store, startKeyBz)
+		if endKeyBz != nil && bytes.Compare(startKeyBz, endKeyBz) >= 0 {
+			break
+		}
+		it, err := txi.store.Iterator(startKeyBz, endKeyBz)
 		if err != nil {
 			panic(err)
 		}
@@ -286,7 +577,7 @@ func (txi *TxIndex) match(
 
 	iterEqual:
 		for ; it.Valid(); it.Next() {
-			tmpHashes[string(it.Value())] = it.Value()
+			tmpHashes[string(it.Value())] = it.Key()
 
 			// Potentially exit early.
 			select {
@@ -310,7 +601,7 @@ func (txi *TxIndex) match(
 
 	iterExists:
 		for ; it.Valid(); it.Next() {
-			tmpHashes[string(it.Value())] = it.Value()
+			tmpHashes[string(it.Value())] = it.Key()
 
 			// Potentially exit early.
 			select {
@@ -340,7 +631,7 @@ func (txi *TxIndex) match(
 				continue
 			}
 			if strings.Contains(value, c.Arg.Value()) {
-				tmpHashes[string(it.Value())] = it.Value()
+				tmpHashes[string(it.Value())] = it.Key()
 			}
 
 			// Potentially exit early.
@@ -387,7 +678,7 @@ func (txi *TxIndex) match(
 }
 
 // matchRange returns all matching txs by hash that meet a given queryRange and
-// start key. An already filtered result (filteredHashes) is provided such that
+// start key, mapped to their key in the index. An already filtered result (filteredHashes) is provided such that
 // any non-intersecting matches are removed.
 //
 // NOTE: filteredHashes may be empty if no previous condition has matched.
@@ -405,8 +696,6 @@ func (txi *TxIndex) matchRange(
 	}
 
 	tmpHashes := make(map[string][]byte)
-	lowerBound := qr.LowerBoundValue()
-	upperBound := qr.UpperBoundValue()
 
 	it, err := dbm.IteratePrefix(txi.store, startKey)
 	if err != nil {
@@ -420,31 +709,8 @@ iter:
 		if err != nil {
 			continue
 		}
-		if _, ok := qr.AnyBound().(int64); ok {
-			v, err := strconv.ParseInt(value, 10, 64)
-			if err != nil {
-				continue iter
-			}
-
-			include := true
-			if lowerBound != nil && v < lowerBound.(int64) {
-				include = false
-			}
-
-			if upperBound != nil && v > upperBound.(int64) {
-				include = false
-			}
-
-			if include {
-				tmpHashes[string(it.Value())] = it.Value()
-			}
-
-			// XXX: passing time in a ABCI Events is not yet implemented
-			// case time.Time:
-			// 	v := strconv.ParseInt(extractValueFromKey(it.Key()), 10, 64)
-			// 	if v == r.upperBound {
-			// 		break
-			// 	}
+		if qr.Matches(value) {
+			tmpHashes[string(it.Value())] = it.Key()
 		}
 
 		// Potentially exit early.
@@ -533,18 +799,30 @@ func secondaryKey(compositeKey, value string, height int64, index uint32) []byte
 // This will also involve ensuring that the key has the correct format.
 // CONTRACT: function doesn't check that the prefix is correct. This should have already been done by the iterator
 func parseValueFromKey(key []byte) (string, error) {
+	value, _, _, err := parseKey(key)
+	return value, err
+}
+
+// parsePositionFromKey parses an event key and extracts out the height and
+// the index of the tx.
+func parsePositionFromKey(key []byte) (height int64, index uint32, err error) {
+	_, height, index, err = parseKey(key)
+	return height, index, err
+}
+
+func parseKey(key []byte) (value string, height int64, index uint32, err error) {
 	var (
-		compositeKey, value string
-		height, index       int64
+		compositeKey string
+		index64      int64
 	)
-	remaining, err := orderedcode.Parse(string(key), &compositeKey, &value, &height, &index)
+	remaining, err := orderedcode.Parse(string(key), &compositeKey, &value, &height, &index64)
 	if err != nil {
-		return "", err
+		return "", 0, 0, err
 	}
 	if len(remaining) != 0 {
-		return "", fmt.Errorf("unexpected remainder in key: %s", remaining)
+		return "", 0, 0, fmt.Errorf("unexpected remainder in key: %s", remaining)
 	}
-	return value, nil
+	return value, height, uint32(index64), nil
 }
 
 func keyFromEvent(compositeKey string, value string, result *abci.TxResult) []byte {
@@ -586,3 +864,37 @@ func prefixForCondition(c syntax.Condition, height int64) []byte {
 	}
 	return key
 }
+
+// prefixEnd returns the first key following all the keys starting with
+// prefix, or nil if there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// narrowToCursor narrows the keys from startKey to endKey, matching the
+// equality condition c, to the keys of the txs following the cursor, in
+// descending order if desc is true. Since the keys of a value are ordered by
+// height and index, they are contiguous.
+func narrowToCursor(c syntax.Condition, startKey, endKey []byte, after indexer.Cursor, desc bool) ([]byte, []byte) {
+	cursorKey := secondaryKey(c.Tag, c.Arg.Value(), after.Height, after.Index)
+	if desc {
+		if endKey == nil || bytes.Compare(cursorKey, endKey) < 0 {
+			endKey = cursorKey
+		}
+		return startKey, endKey
+	}
+	// No key has the key of the cursor as a strict prefix.
+	next := append(cursorKey, 0x00)
+	if bytes.Compare(next, startKey) > 0 {
+		startKey = next
+	}
+	return startKey, endKey
+}
@@ -317,6 +317,180 @@ func TestTxSearchMultipleTxs(t *testing.T) {
 	require.Len(t, results, 3)
 }
 
+func TestTxSearchOperators(t *testing.T) {
+	indexer := NewTxIndex(dbm.NewMemDB())
+
+	owners := []string{"Ivan", "Igor", "Vlad", "Oleg"}
+	amounts := []string{"1", "2.5", "4", "10"}
+	var hashes []string
+	for i := range owners {
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "account", Attributes: []abci.EventAttribute{{Key: "owner", Value: owners[i], Index: true}}},
+			{Type: "transfer", Attributes: []abci.EventAttribute{{Key: "amount", Value: amounts[i], Index: true}}},
+		})
+		txResult.Tx = types.Tx(owners[i] + "'s transfer")
+		txResult.Height = int64(i + 1)
+		require.NoError(t, indexer.Index([]*abci.TxResult{txResult}))
+		hashes = append(hashes, fmt.Sprintf("%X", types.Tx(txResult.Tx).Hash()))
+	}
+
+	testCases := []struct {
+		q      string
+		owners []string
+	}{
+		{"account.owner = 'Ivan' OR account.owner = 'Vlad'", []string{"Ivan", "Vlad"}},
+		{"account.owner IN ('Ivan', 'Oleg', 'Boris')", []string{"Ivan", "Oleg"}},
+		{"account.owner IN ('Ivan', 'Oleg') AND tx.height > 1", []string{"Oleg"}},
+		{"account.owner = 'Igor' OR transfer.amount >= 4 AND tx.height < 4", []string{"Igor", "Vlad"}},
+		{"(account.owner = 'Igor' OR transfer.amount >= 4) AND tx.height < 4", []string{"Igor", "Vlad"}},
+		{"NOT account.owner = 'Ivan'", []string{"Igor", "Vlad", "Oleg"}},
+		{"NOT account.owner IN ('Ivan', 'Igor') AND transfer.amount EXISTS", []string{"Vlad", "Oleg"}},
+		{"NOT (account.owner = 'Ivan' OR tx.height >= 3)", []string{"Igor"}},
+		{"NOT account.owner CONTAINS 'I' AND NOT tx.height = 4", []string{"Vlad"}},
+		{fmt.Sprintf("tx.hash = '%s' OR tx.hash = '%s'", hashes[1], hashes[3]), []string{"Igor", "Oleg"}},
+		{fmt.Sprintf("tx.hash IN ('%s', '%s')", hashes[0], hashes[2]), []string{"Ivan", "Vlad"}},
+
+		// Inclusive and exclusive ranges, on decimal values.
+		{"transfer.amount > 1 AND transfer.amount < 10", []string{"Igor", "Vlad"}},
+		{"transfer.amount >= 1 AND transfer.amount <= 10", []string{"Ivan", "Igor", "Vlad", "Oleg"}},
+		{"transfer.amount > 2.5", []string{"Vlad", "Oleg"}},
+		{"transfer.amount >= 2.5 AND transfer.amount < 4", []string{"Igor"}},
+		{"transfer.amount >= 1.5 AND transfer.amount <= 2", nil},
+		{"transfer.amount > 1 AND transfer.amount >= 4", []string{"Vlad", "Oleg"}},
+		{"transfer.amount <= 4 AND transfer.amount < 4", []string{"Ivan", "Igor"}},
+	}
+
+	ctx := context.Background()
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.q, func(t *testing.T) {
+			results, err := indexer.Search(ctx, query.MustCompile(tc.q))
+			require.NoError(t, err)
+
+			var got []string
+			for _, res := range results {
+				got = append(got, owners[res.Height-1])
+			}
+			assert.ElementsMatch(t, tc.owners, got)
+		})
+	}
+}
+
+func TestTxSearchAfter(t *testing.T) {
+	txIndexer := NewTxIndex(dbm.NewMemDB())
+
+	// Seven txs of Alice and one of Bob, over three heights.
+	var txs []*abci.TxResult
+	for i := 0; i < 8; i++ {
+		owner := "alice"
+		if i == 4 {
+			owner = "bob"
+		}
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "account", Attributes: []abci.EventAttribute{
+				{Key: "owner", Value: owner, Index: true},
+				{Key: "number", Value: fmt.Sprint(i), Index: true},
+			}},
+		})
+		txResult.Tx = types.Tx(fmt.Sprintf("tx%d", i))
+		txResult.Height = int64(i/3 + 1)
+		txResult.Index = uint32(i % 3)
+		txs = append(txs, txResult)
+	}
+	require.NoError(t, txIndexer.Index(txs))
+
+	// sweep returns the numbers of the txs matching q, read by pages of 3.
+	sweep := func(q string, desc bool) []int {
+		var numbers []int
+		var after *indexer.Cursor
+		for {
+			results, remaining, err := txIndexer.SearchAfter(context.Background(), query.MustCompile(q), after, desc, 3)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(results), remaining)
+			for _, r := range results {
+				numbers = append(numbers, int(r.Height-1)*3+int(r.Index))
+			}
+			if len(results) == remaining {
+				return numbers
+			}
+			last := results[len(results)-1]
+			after = &indexer.Cursor{Height: last.Height, Index: last.Index}
+		}
+	}
+
+	testCases := []struct {
+		q    string
+		asc  []int
+		desc []int
+	}{
+		{"account.owner = 'alice'", []int{0, 1, 2, 3, 5, 6, 7}, []int{7, 6, 5, 3, 2, 1, 0}},
+		{"account.owner = 'bob'", []int{4}, []int{4}},
+		{"account.owner = 'carol'", nil, nil},
+		{"account.number >= 3", []int{3, 4, 5, 6, 7}, []int{7, 6, 5, 4, 3}},
+		{"account.owner = 'alice' AND account.number >= 3", []int{3, 5, 6, 7}, []int{7, 6, 5, 3}},
+		{"tx.height = 2 AND account.owner = 'alice'", []int{3, 5}, []int{5, 3}},
+		{"account.owner EXISTS", []int{0, 1, 2, 3, 4, 5, 6, 7}, []int{7, 6, 5, 4, 3, 2, 1, 0}},
+		{fmt.Sprintf("tx.hash = '%X'", types.Tx("tx2").Hash()), []int{2}, []int{2}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.q, func(t *testing.T) {
+			assert.Equal(t, tc.asc, sweep(tc.q, false))
+			assert.Equal(t, tc.desc, sweep(tc.q, true))
+		})
+	}
+
+	// A tx matched by hash is only returned if it follows the cursor.
+	q := query.MustCompile(fmt.Sprintf("tx.hash = '%X'", types.Tx("tx2").Hash()))
+	results, remaining, err := txIndexer.SearchAfter(context.Background(), q, &indexer.Cursor{Height: 1, Index: 2}, false, 3)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Zero(t, remaining)
+}
+
+func TestTxIndexPrune(t *testing.T) {
+	store := dbm.NewMemDB()
+	indexer := NewTxIndex(store)
+
+	first := txResultWithEvents(nil)
+	first.Tx = types.Tx("tx at 1")
+	first.Height = 1
+	require.NoError(t, indexer.Index([]*abci.TxResult{first}))
+	for height := int64(2); height <= 12; height++ {
+		txResult := txResultWithEvents([]abci.Event{
+			{Type: "account", Attributes: []abci.EventAttribute{{Key: "number", Value: "1", Index: true}}},
+		})
+		txResult.Tx = types.Tx(fmt.Sprintf("tx at %d", height))
+		txResult.Height = height
+		require.NoError(t, indexer.Index([]*abci.TxResult{txResult}))
+	}
+	// A tx included again at a later height is kept.
+	again := txResultWithEvents(nil)
+	again.Tx = types.Tx("tx at 1")
+	again.Height = 12
+	again.Index = 1
+	require.NoError(t, indexer.Index([]*abci.TxResult{again}))
+
+	pruned, err := indexer.Prune(1, 11)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, pruned)
+
+	for height := int64(2); height < 11; height++ {
+		res, err := indexer.Get(types.Tx(fmt.Sprintf("tx at %d", height)).Hash())
+		require.NoError(t, err)
+		require.Nil(t, res)
+	}
+	res, err := indexer.Get(types.Tx("tx at 1").Hash())
+	require.NoError(t, err)
+	require.EqualValues(t, 12, res.Height)
+
+	results, err := indexer.Search(context.Background(), query.MustCompile(`account.number = 1`))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	results, err = indexer.Search(context.Background(), query.MustCompile(`tx.height < 11`))
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
+
 func txResultWithEvents(events []abci.Event) *abci.TxResult {
 	tx := types.Tx("HELLO WORLD")
 	return &abci.TxResult{
@@ -1,10 +1,14 @@
 package state
 
 import (
+	"time"
+
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/discard"
 	"github.com/go-kit/kit/metrics/prometheus"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/tendermint/tendermint/types"
 )
 
 const (
@@ -17,6 +21,37 @@ const (
 type Metrics struct {
 	// Time between BeginBlock and EndBlock.
 	BlockProcessingTime metrics.Histogram
+
+	// Time spent in each phase of producing a block, in seconds, labeled
+	// by phase.
+	BlockPhaseSeconds metrics.Histogram
+
+	// Size of the last block, in bytes.
+	BlockBytesUsed metrics.Gauge
+	// Maximum size of a block, in bytes.
+	BlockMaxBytes metrics.Gauge
+	// Gas used by the last block.
+	BlockGasUsed metrics.Gauge
+	// Maximum gas of a block, -1 if unlimited.
+	BlockMaxGas metrics.Gauge
+	// Share of the maximum block size used, labeled by proposer.
+	BlockBytesUtilization metrics.Histogram
+	// Share of the maximum block gas used, labeled by proposer. Not
+	// observed when the gas of a block is unlimited.
+	BlockGasUtilization metrics.Histogram
+	// Number of blocks committed, labeled by proposer.
+	ProposerBlocks metrics.Counter
+	// Number of blocks without transactions committed, labeled by proposer.
+	ProposerEmptyBlocks metrics.Counter
+
+	// Height below which the pruner deletes the blocks.
+	PruningRetainHeight metrics.Gauge
+	// Height of the first block left by the pruner.
+	PruningBaseHeight metrics.Gauge
+	// Number of blocks deleted by the pruner.
+	PrunedBlocks metrics.Counter
+	// Number of indexed transactions deleted by the pruner.
+	PrunedTxs metrics.Counter
 }
 
 // PrometheusMetrics returns Metrics build using Prometheus client library.
@@ -35,6 +70,87 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 			Help:      "Time between BeginBlock and EndBlock in ms.",
 			Buckets:   stdprometheus.LinearBuckets(1, 10, 10),
 		}, labels).With(labelsAndValues...),
+		BlockPhaseSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_phase_seconds",
+			Help:      "Time spent in each consensus, execution and storage phase of a block, in seconds.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.001, 4, 8),
+		}, append(labels, "phase")).With(labelsAndValues...),
+		BlockBytesUsed: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_bytes_used",
+			Help:      "Size of the last block, in bytes.",
+		}, labels).With(labelsAndValues...),
+		BlockMaxBytes: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_max_bytes",
+			Help:      "Maximum size of a block, in bytes.",
+		}, labels).With(labelsAndValues...),
+		BlockGasUsed: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_gas_used",
+			Help:      "Gas used by the last block.",
+		}, labels).With(labelsAndValues...),
+		BlockMaxGas: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_max_gas",
+			Help:      "Maximum gas of a block, -1 if unlimited.",
+		}, labels).With(labelsAndValues...),
+		BlockBytesUtilization: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_bytes_utilization",
+			Help:      "Share of the maximum block size used, by proposer.",
+			Buckets:   stdprometheus.LinearBuckets(0.1, 0.1, 10),
+		}, append(labels, "proposer_address")).With(labelsAndValues...),
+		BlockGasUtilization: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "block_gas_utilization",
+			Help:      "Share of the maximum block gas used, by proposer.",
+			Buckets:   stdprometheus.LinearBuckets(0.1, 0.1, 10),
+		}, append(labels, "proposer_address")).With(labelsAndValues...),
+		ProposerBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "proposer_blocks",
+			Help:      "Number of blocks committed, by proposer.",
+		}, append(labels, "proposer_address")).With(labelsAndValues...),
+		ProposerEmptyBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "proposer_empty_blocks",
+			Help:      "Number of blocks without transactions committed, by proposer.",
+		}, append(labels, "proposer_address")).With(labelsAndValues...),
+		PruningRetainHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruning_retain_height",
+			Help:      "Height below which the pruner deletes the blocks.",
+		}, labels).With(labelsAndValues...),
+		PruningBaseHeight: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruning_base_height",
+			Help:      "Height of the first block left by the pruner.",
+		}, labels).With(labelsAndValues...),
+		PrunedBlocks: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruned_blocks",
+			Help:      "Number of blocks deleted by the pruner.",
+		}, labels).With(labelsAndValues...),
+		PrunedTxs: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "pruned_txs",
+			Help:      "Number of indexed transactions deleted by the pruner.",
+		}, labels).With(labelsAndValues...),
 	}
 }
 
@@ -42,5 +158,62 @@ func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
 func NopMetrics() *Metrics {
 	return &Metrics{
 		BlockProcessingTime: discard.NewHistogram(),
+		BlockPhaseSeconds:   discard.NewHistogram(),
+
+		BlockBytesUsed:        discard.NewGauge(),
+		BlockMaxBytes:         discard.NewGauge(),
+		BlockGasUsed:          discard.NewGauge(),
+		BlockMaxGas:           discard.NewGauge(),
+		BlockBytesUtilization: discard.NewHistogram(),
+		BlockGasUtilization:   discard.NewHistogram(),
+		ProposerBlocks:        discard.NewCounter(),
+		ProposerEmptyBlocks:   discard.NewCounter(),
+
+		PruningRetainHeight: discard.NewGauge(),
+		PruningBaseHeight:   discard.NewGauge(),
+		PrunedBlocks:        discard.NewCounter(),
+		PrunedTxs:           discard.NewCounter(),
+	}
+}
+
+// observeBlockProfile records the phase timings of a block profile.
+// Consensus phases are skipped when they were not measured.
+func (m *Metrics) observeBlockProfile(p types.EventDataBlockProfile) {
+	phases := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"proposal_wait", p.ProposalWait},
+		{"prevote_quorum", p.PrevoteQuorum},
+		{"precommit_quorum", p.PrecommitQuorum},
+		{"begin_block", p.BeginBlock},
+		{"deliver_txs", p.DeliverTxs},
+		{"end_block", p.EndBlock},
+		{"commit", p.Commit},
+		{"save_state", p.SaveState},
+	}
+	for _, phase := range phases {
+		if phase.d == 0 {
+			continue
+		}
+		m.BlockPhaseSeconds.With("phase", phase.name).Observe(phase.d.Seconds())
+	}
+}
+
+// observeBlockUtilization records the utilization of a committed block.
+func (m *Metrics) observeBlockUtilization(u BlockUtilization) {
+	proposer := u.Proposer.String()
+
+	m.BlockBytesUsed.Set(float64(u.Bytes))
+	m.BlockMaxBytes.Set(float64(u.MaxBytes))
+	m.BlockGasUsed.Set(float64(u.GasUsed))
+	m.BlockMaxGas.Set(float64(u.MaxGas))
+	m.BlockBytesUtilization.With("proposer_address", proposer).Observe(u.BytesRatio())
+	if ratio, ok := u.GasRatio(); ok {
+		m.BlockGasUtilization.With("proposer_address", proposer).Observe(ratio)
+	}
+	m.ProposerBlocks.With("proposer_address", proposer).Add(1)
+	if u.IsEmpty() {
+		m.ProposerEmptyBlocks.With("proposer_address", proposer).Add(1)
 	}
 }
@@ -108,6 +108,29 @@ func (_m *Store) LoadConsensusParams(_a0 int64) (types.ConsensusParams, error) {
 	return r0, r1
 }
 
+// LoadConsensusParamsHistory provides a mock function with given fields: _a0
+func (_m *Store) LoadConsensusParamsHistory(_a0 int64) ([]state.ConsensusParamsVersion, error) {
+	ret := _m.Called(_a0)
+
+	var r0 []state.ConsensusParamsVersion
+	if rf, ok := ret.Get(0).(func(int64) []state.ConsensusParamsVersion); ok {
+		r0 = rf(_a0)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]state.ConsensusParamsVersion)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(_a0)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // LoadValidators provides a mock function with given fields: _a0
 func (_m *Store) LoadValidators(_a0 int64) (*types.ValidatorSet, error) {
 	ret := _m.Called(_a0)
@@ -0,0 +1,298 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+)
+
+// pruneBatchHeights is the number of heights pruned at once, between which
+// the pruner yields to the other writers of the stores.
+const pruneBatchHeights = 1000
+
+// RetainHeights are the heights below which the pruner may delete the blocks,
+// as requested by the application, and allowed by the evidence window and the
+// minimum number of blocks to retain, and the resulting retain height.
+type RetainHeights struct {
+	// Application is the last retain height returned by the application on
+	// Commit, 0 if it returned none.
+	Application int64
+	// Evidence is the height of the first block evidence could still be
+	// submitted for.
+	Evidence int64
+	// MinRetain is the height of the first of the blocks the node retains
+	// anyway, 0 if the node retains no minimum number of blocks.
+	MinRetain int64
+	// Retain is the lowest of the above: the blocks below it are pruned. It
+	// is 0 if the application requested no pruning.
+	Retain int64
+}
+
+// PruningStatus is the status of the pruner.
+type PruningStatus struct {
+	RetainHeights
+
+	// BlockBase is the height of the first block of the blockstore, and
+	// TxIndexBase the height of the first transactions of the tx index, 0
+	// if it cannot be pruned or was not yet.
+	BlockBase   int64
+	TxIndexBase int64
+
+	// LastPruned is when the pruner last pruned the stores, and LastError
+	// the error it failed with, if any.
+	LastPruned time.Time
+	LastError  string
+}
+
+// Pruner prunes the blockstore, the state store and the tx indexes in the
+// background, below the retain height requested by the application on
+// Commit. The blocks within the evidence window, and the minRetainBlocks last
+// blocks, are retained regardless. At every interval, it prunes up to the
+// retain height, pruneBatchHeights heights at a time.
+type Pruner struct {
+	service.BaseService
+	logger  log.Logger
+	metrics *Metrics
+
+	stateStore      Store
+	blockStore      BlockStore
+	sinks           []indexer.EventSink
+	interval        time.Duration
+	minRetainBlocks int64
+
+	mtx         sync.Mutex
+	appRetain   int64
+	retain      RetainHeights
+	txIndexBase int64
+	lastPruned  time.Time
+	lastErr     error
+}
+
+// NewPruner returns a pruner of the blocks of blockStore, of the states of
+// stateStore, and of the transactions indexed by the sinks implementing
+// indexer.PruningSink.
+func NewPruner(
+	logger log.Logger,
+	stateStore Store,
+	blockStore BlockStore,
+	sinks []indexer.EventSink,
+	interval time.Duration,
+	minRetainBlocks int64,
+	metrics *Metrics,
+) *Pruner {
+	p := &Pruner{
+		logger:          logger,
+		metrics:         metrics,
+		stateStore:      stateStore,
+		blockStore:      blockStore,
+		sinks:           sinks,
+		interval:        interval,
+		minRetainBlocks: minRetainBlocks,
+	}
+	p.BaseService = *service.NewBaseService(logger, "Pruner", p)
+	return p
+}
+
+// OnStart starts pruning the stores. It implements service.Service.
+func (p *Pruner) OnStart(ctx context.Context) error {
+	go p.pruneRoutine(ctx)
+	return nil
+}
+
+// OnStop implements service.Service.
+func (p *Pruner) OnStop() {}
+
+// SetApplicationRetainHeight records the retain height returned by the
+// application on Commit. The application may not lower it.
+func (p *Pruner) SetApplicationRetainHeight(height int64) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if height > p.appRetain {
+		p.appRetain = height
+	}
+}
+
+// Status returns the status of the pruner.
+func (p *Pruner) Status() PruningStatus {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	status := PruningStatus{
+		RetainHeights: p.retain,
+		BlockBase:     p.blockStore.Base(),
+		TxIndexBase:   p.txIndexBase,
+		LastPruned:    p.lastPruned,
+	}
+	status.Application = p.appRetain
+	if p.lastErr != nil {
+		status.LastError = p.lastErr.Error()
+	}
+	return status
+}
+
+func (p *Pruner) pruneRoutine(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := p.Prune(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Error("failed to prune the stores", "err", err)
+		}
+	}
+}
+
+// Prune prunes the stores up to the retain height.
+func (p *Pruner) Prune(ctx context.Context) error {
+	retain, err := p.RetainHeights()
+	if err == nil {
+		p.mtx.Lock()
+		p.retain = retain
+		p.mtx.Unlock()
+		p.metrics.PruningRetainHeight.Set(float64(retain.Retain))
+
+		err = p.prune(ctx, retain.Retain)
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.lastPruned = time.Now()
+	p.lastErr = err
+	return err
+}
+
+// RetainHeights returns the retain heights at the last state of the node.
+func (p *Pruner) RetainHeights() (RetainHeights, error) {
+	p.mtx.Lock()
+	retain := RetainHeights{Application: p.appRetain}
+	p.mtx.Unlock()
+
+	state, err := p.stateStore.Load()
+	if err != nil {
+		return retain, fmt.Errorf("loading the state: %w", err)
+	}
+	height := state.LastBlockHeight
+	if h := p.blockStore.Height(); h < height {
+		height = h
+	}
+	if height <= 0 {
+		return retain, nil
+	}
+
+	retain.Evidence = p.evidenceRetainHeight(state, height)
+	if p.minRetainBlocks > 0 {
+		retain.MinRetain = height - p.minRetainBlocks + 1
+	}
+	if retain.Application <= 0 {
+		return retain, nil
+	}
+
+	retain.Retain = retain.Application
+	if retain.Evidence < retain.Retain {
+		retain.Retain = retain.Evidence
+	}
+	if p.minRetainBlocks > 0 && retain.MinRetain < retain.Retain {
+		retain.Retain = retain.MinRetain
+	}
+	if retain.Retain > height {
+		retain.Retain = height
+	}
+	return retain, nil
+}
+
+// evidenceRetainHeight returns the height of the first block evidence could
+// still be submitted for, which is neither older than the max age in blocks
+// nor older than the max age duration of the evidence parameters.
+func (p *Pruner) evidenceRetainHeight(state State, height int64) int64 {
+	params := state.ConsensusParams.Evidence
+	retain := height - params.MaxAgeNumBlocks
+	base := p.blockStore.Base()
+	if retain <= base {
+		return base
+	}
+
+	// The first block within the max age duration, if it is below retain.
+	cutoff := state.LastBlockTime.Add(-params.MaxAgeDuration)
+	i := sort.Search(int(retain-base), func(i int) bool {
+		meta := p.blockStore.LoadBlockMeta(base + int64(i))
+		return meta == nil || !meta.Header.Time.Before(cutoff)
+	})
+	return base + int64(i)
+}
+
+// prune prunes the blockstore, the state store and the tx indexes up to
+// retainHeight, pruneBatchHeights heights at a time.
+func (p *Pruner) prune(ctx context.Context, retainHeight int64) error {
+	if retainHeight <= 0 {
+		return nil
+	}
+
+	for base := p.blockStore.Base(); base > 0 && base < retainHeight; base = p.blockStore.Base() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next := base + pruneBatchHeights
+		if next > retainHeight {
+			next = retainHeight
+		}
+		pruned, err := p.blockStore.PruneBlocks(next)
+		if err != nil {
+			return fmt.Errorf("pruning the blockstore: %w", err)
+		}
+		if err := p.stateStore.PruneStates(next); err != nil {
+			return fmt.Errorf("pruning the state store: %w", err)
+		}
+		p.metrics.PrunedBlocks.Add(float64(pruned))
+		p.metrics.PruningBaseHeight.Set(float64(next))
+		p.logger.Debug("pruned blocks", "pruned", pruned, "base", next, "retain_height", retainHeight)
+	}
+
+	for _, sink := range p.sinks {
+		ps, ok := sink.(indexer.PruningSink)
+		if !ok {
+			continue
+		}
+		if err := p.pruneSink(ctx, ps, retainHeight); err != nil {
+			return fmt.Errorf("pruning the %s tx index: %w", sink.Type(), err)
+		}
+	}
+	return nil
+}
+
+func (p *Pruner) pruneSink(ctx context.Context, sink indexer.PruningSink, retainHeight int64) error {
+	base, err := sink.TxRetainHeight()
+	for ; err == nil && base < retainHeight; base, err = sink.TxRetainHeight() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next := base + pruneBatchHeights
+		if next > retainHeight {
+			next = retainHeight
+		}
+		pruned, err := sink.PruneTxEvents(next)
+		if err != nil {
+			return err
+		}
+		p.metrics.PrunedTxs.Add(float64(pruned))
+		p.logger.Debug("pruned transactions", "pruned", pruned, "base", next, "retain_height", retainHeight)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.txIndexBase = base
+	return nil
+}
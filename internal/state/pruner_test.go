@@ -0,0 +1,96 @@
+package state_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/state/indexer"
+	sink "github.com/tendermint/tendermint/internal/state/indexer/sink/kv"
+	"github.com/tendermint/tendermint/internal/state/mocks"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestPruner(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	state, stateDB, _ := makeState(t, 1, 2501)
+	stateStore := sm.NewStore(stateDB)
+	lastTime := time.Now()
+	state.LastBlockTime = lastTime
+	state.ConsensusParams.Evidence.MaxAgeNumBlocks = 100
+	state.ConsensusParams.Evidence.MaxAgeDuration = time.Hour
+	require.NoError(t, stateStore.Save(state))
+
+	// A block every 10s: the evidence window spans the last 360 blocks.
+	base := int64(1)
+	var pruneHeights []int64
+	blockStore := &mocks.BlockStore{}
+	blockStore.On("Base").Return(func() int64 { return base })
+	blockStore.On("Height").Return(state.LastBlockHeight)
+	blockStore.On("LoadBlockMeta", mock.Anything).Return(func(height int64) *types.BlockMeta {
+		age := time.Duration(state.LastBlockHeight-height) * 10 * time.Second
+		return &types.BlockMeta{Header: types.Header{Height: height, Time: lastTime.Add(-age)}}
+	})
+	blockStore.On("PruneBlocks", mock.Anything).Return(func(height int64) uint64 {
+		pruned := uint64(height - base)
+		pruneHeights = append(pruneHeights, height)
+		base = height
+		return pruned
+	}, nil)
+
+	eventSink := sink.NewEventSink(dbm.NewMemDB())
+	txAt := func(height int64) types.Tx { return types.Tx(fmt.Sprintf("tx at %d", height)) }
+	for _, height := range []int64{5, 2000, 2001} {
+		require.NoError(t, eventSink.IndexTxEvents([]*abci.TxResult{{Height: height, Tx: txAt(height)}}))
+	}
+
+	pruner := sm.NewPruner(log.TestingLogger(), stateStore, blockStore,
+		[]indexer.EventSink{eventSink}, time.Second, 500, sm.NopMetrics())
+
+	// Without a retain height from the application, nothing is pruned.
+	require.NoError(t, pruner.Prune(ctx))
+	status := pruner.Status()
+	require.EqualValues(t, 0, status.Retain)
+	require.EqualValues(t, 2140, status.Evidence)
+	require.EqualValues(t, 2001, status.MinRetain)
+	require.Empty(t, pruneHeights)
+
+	// The retain height is the lowest of the application, the evidence and
+	// the min-retain ones, and the blocks are pruned in batches.
+	pruner.SetApplicationRetainHeight(2300)
+	require.NoError(t, pruner.Prune(ctx))
+	status = pruner.Status()
+	require.EqualValues(t, 2300, status.Application)
+	require.EqualValues(t, 2001, status.Retain)
+	require.EqualValues(t, 2001, status.BlockBase)
+	require.EqualValues(t, 2001, status.TxIndexBase)
+	require.Empty(t, status.LastError)
+	require.Equal(t, []int64{1001, 2001}, pruneHeights)
+
+	for _, height := range []int64{5, 2000} {
+		res, err := eventSink.GetTxByHash(txAt(height).Hash())
+		require.NoError(t, err)
+		require.Nil(t, res)
+	}
+	res, err := eventSink.GetTxByHash(txAt(2001).Hash())
+	require.NoError(t, err)
+	require.EqualValues(t, 2001, res.Height)
+	_, err = stateStore.LoadValidators(2000)
+	require.Error(t, err)
+	_, err = stateStore.LoadValidators(2001)
+	require.NoError(t, err)
+
+	// The application may not lower its retain height.
+	pruner.SetApplicationRetainHeight(10)
+	require.EqualValues(t, 2300, pruner.Status().Application)
+}
@@ -263,18 +263,10 @@ func (state State) MakeBlock(
 	// Build base block with block data.
 	block := types.MakeBlock(height, txs, commit, evidence)
 
-	// Set time.
-	var timestamp time.Time
-	if height == state.InitialHeight {
-		timestamp = state.LastBlockTime // genesis time
-	} else {
-		timestamp = MedianTime(commit, state.LastValidators)
-	}
-
 	// Fill rest of header with state data.
 	block.Header.Populate(
 		state.Version.Consensus, state.ChainID,
-		timestamp, state.LastBlockID,
+		state.blockTime(height, commit), state.LastBlockID,
 		state.Validators.Hash(), state.NextValidators.Hash(),
 		state.ConsensusParams.HashConsensusParams(), state.AppHash, state.LastResultsHash,
 		proposerAddress,
@@ -288,6 +280,15 @@ func (state State) MakeBlock(
 	return block, bps, nil
 }
 
+// blockTime returns the time of the block at height, which is the genesis
+// time for the initial height and the median time of commit otherwise.
+func (state State) blockTime(height int64, commit *types.Commit) time.Time {
+	if height == state.InitialHeight {
+		return state.LastBlockTime // genesis time
+	}
+	return MedianTime(commit, state.LastValidators)
+}
+
 // MedianTime computes a median time for a given Commit (based on Timestamp field of votes messages) and the
 // corresponding validator set. The computed time is always between timestamps of
 // the votes sent by honest processes, i.e., a faulty processes can not arbitrarily increase or decrease the
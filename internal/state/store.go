@@ -82,6 +82,9 @@ type Store interface {
 	LoadABCIResponses(int64) (*tmstate.ABCIResponses, error)
 	// LoadConsensusParams loads the consensus params for a given height
 	LoadConsensusParams(int64) (types.ConsensusParams, error)
+	// LoadConsensusParamsHistory loads the versions of the consensus params
+	// up to a given height
+	LoadConsensusParamsHistory(int64) ([]ConsensusParamsVersion, error)
 	// Save overwrites the previous state with the updated one
 	Save(State) error
 	// SaveABCIResponses saves ABCIResponses for a given height
@@ -99,13 +102,31 @@ type Store interface {
 // dbStore wraps a db (github.com/tendermint/tm-db)
 type dbStore struct {
 	db dbm.DB
+
+	// indexIntents enables the recording of the index intents, see
+	// WithIndexIntents.
+	indexIntents bool
 }
 
 var _ Store = (*dbStore)(nil)
 
+// StoreOption sets an optional parameter on the dbStore.
+type StoreOption func(*dbStore)
+
+// WithIndexIntents makes the store record an index intent for the last block
+// of every state it saves, in the same batch, so that the blocks committed
+// but not yet indexed are known after a crash. See IndexIntentLog.
+func WithIndexIntents() StoreOption {
+	return func(store *dbStore) { store.indexIntents = true }
+}
+
 // NewStore creates the dbStore of the state pkg.
-func NewStore(db dbm.DB) Store {
-	return dbStore{db}
+func NewStore(db dbm.DB, options ...StoreOption) Store {
+	store := dbStore{db: db}
+	for _, opt := range options {
+		opt(&store)
+	}
+	return store
 }
 
 // LoadState loads the State from the database.
@@ -174,6 +195,12 @@ func (store dbStore) save(state State, key []byte) error {
 		return err
 	}
 
+	if store.indexIntents && state.LastBlockHeight > 0 {
+		if err := batch.Set(indexIntentKey(state.LastBlockHeight), []byte{}); err != nil {
+			return err
+		}
+	}
+
 	return batch.WriteSync()
 }
 
@@ -618,6 +645,60 @@ func (store dbStore) LoadConsensusParams(height int64) (types.ConsensusParams, e
 	return types.ConsensusParamsFromProto(paramsInfo.ConsensusParams), nil
 }
 
+// ConsensusParamsVersion is a version of the consensus params, in effect from
+// Height until the next version.
+type ConsensusParamsVersion struct {
+	Height int64
+	Params types.ConsensusParams
+}
+
+// LoadConsensusParamsHistory loads the versions of the consensus params in
+// effect up to the given height, oldest first. The history starts at the
+// initial height, or at the oldest version not pruned.
+func (store dbStore) LoadConsensusParamsHistory(height int64) ([]ConsensusParamsVersion, error) {
+	paramsInfo, err := store.loadConsensusParamsInfo(height)
+	if err != nil {
+		return nil, fmt.Errorf("could not find consensus params for height #%d: %w", height, err)
+	}
+
+	var history []ConsensusParamsVersion
+	for {
+		changed := paramsInfo.LastHeightChanged
+		params, err := store.LoadConsensusParams(changed)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, ConsensusParamsVersion{Height: changed, Params: params})
+
+		// The params info of the height before the change tells when the
+		// previous version was set, unless it was pruned.
+		if changed <= 1 {
+			break
+		}
+		buf, err := store.db.Get(consensusParamsKey(changed - 1))
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) == 0 {
+			break
+		}
+		paramsInfo = new(tmstate.ConsensusParamsInfo)
+		if err := paramsInfo.Unmarshal(buf); err != nil {
+			// DATA HAS BEEN CORRUPTED OR THE SPEC HAS CHANGED
+			panic(fmt.Sprintf(`data has been corrupted or its spec has changed: %+v`, err))
+		}
+		if paramsInfo.LastHeightChanged >= changed {
+			return nil, fmt.Errorf("consensus params at height %d changed at height %d, after height %d",
+				changed-1, paramsInfo.LastHeightChanged, changed)
+		}
+	}
+
+	for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+		history[i], history[j] = history[j], history[i]
+	}
+	return history, nil
+}
+
 func (store dbStore) loadConsensusParamsInfo(height int64) (*tmstate.ConsensusParamsInfo, error) {
 	buf, err := store.db.Get(consensusParamsKey(height))
 	if err != nil {
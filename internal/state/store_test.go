@@ -174,6 +174,58 @@ func TestStoreLoadConsensusParams(t *testing.T) {
 	require.NotEqual(t, res, differentParams)
 }
 
+func TestStoreLoadConsensusParamsHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stateStore := sm.NewStore(dbm.NewMemDB())
+
+	// The params change at heights 4 and 8.
+	params4 := types.DefaultConsensusParams()
+	params4.Block.MaxBytes = 20000
+	params8 := *params4
+	params8.Block.MaxGas = 100
+	for h := int64(1); h <= 10; h++ {
+		params, changed := types.DefaultConsensusParams(), int64(1)
+		switch {
+		case h >= 8:
+			params, changed = &params8, 8
+		case h >= 4:
+			params, changed = params4, 4
+		}
+		state := makeRandomStateFromConsensusParams(ctx, t, params, h, changed)
+		state.LastHeightValidatorsChanged = 2
+		require.NoError(t, stateStore.Save(state))
+	}
+
+	history, err := stateStore.LoadConsensusParamsHistory(10)
+	require.NoError(t, err)
+	require.Equal(t, []sm.ConsensusParamsVersion{
+		{Height: 1, Params: *types.DefaultConsensusParams()},
+		{Height: 4, Params: *params4},
+		{Height: 8, Params: params8},
+	}, history)
+
+	history, err = stateStore.LoadConsensusParamsHistory(7)
+	require.NoError(t, err)
+	require.Equal(t, []sm.ConsensusParamsVersion{
+		{Height: 1, Params: *types.DefaultConsensusParams()},
+		{Height: 4, Params: *params4},
+	}, history)
+
+	// The history starts at the oldest version not pruned.
+	require.NoError(t, stateStore.PruneStates(6))
+	history, err = stateStore.LoadConsensusParamsHistory(10)
+	require.NoError(t, err)
+	require.Equal(t, []sm.ConsensusParamsVersion{
+		{Height: 4, Params: *params4},
+		{Height: 8, Params: params8},
+	}, history)
+
+	_, err = stateStore.LoadConsensusParamsHistory(11)
+	require.Error(t, err)
+}
+
 func TestPruneStates(t *testing.T) {
 	testcases := map[string]struct {
 		startHeight           int64
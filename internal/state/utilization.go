@@ -0,0 +1,69 @@
+package state
+
+import (
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// BlockUtilization is the share of the capacity allowed by the consensus
+// params that a block uses.
+type BlockUtilization struct {
+	Height   int64
+	Proposer types.Address
+	NumTxs   int
+
+	Bytes    int64
+	MaxBytes int64
+
+	// GasUsed is the gas used by the transactions of the block, as reported
+	// by the application. MaxGas is -1 if the gas of a block is unlimited.
+	GasUsed int64
+	MaxGas  int64
+}
+
+// NewBlockUtilization returns the utilization of a block of size bytes, given
+// the block params it was proposed under and the results of its transactions.
+func NewBlockUtilization(
+	header *types.Header,
+	numTxs int,
+	size int,
+	params types.BlockParams,
+	deliverTxs []*abci.ResponseDeliverTx,
+) BlockUtilization {
+	u := BlockUtilization{
+		Height:   header.Height,
+		Proposer: header.ProposerAddress,
+		NumTxs:   numTxs,
+		Bytes:    int64(size),
+		MaxBytes: params.MaxBytes,
+		MaxGas:   params.MaxGas,
+	}
+	for _, res := range deliverTxs {
+		if res != nil {
+			u.GasUsed += res.GasUsed
+		}
+	}
+	return u
+}
+
+// BytesRatio returns the share of the maximum block size used.
+func (u BlockUtilization) BytesRatio() float64 {
+	if u.MaxBytes <= 0 {
+		return 0
+	}
+	return float64(u.Bytes) / float64(u.MaxBytes)
+}
+
+// GasRatio returns the share of the maximum block gas used, and false if the
+// gas of a block is unlimited.
+func (u BlockUtilization) GasRatio() (float64, bool) {
+	if u.MaxGas <= 0 {
+		return 0, false
+	}
+	return float64(u.GasUsed) / float64(u.MaxGas), true
+}
+
+// IsEmpty returns whether the block has no transactions.
+func (u BlockUtilization) IsEmpty() bool {
+	return u.NumTxs == 0
+}
@@ -1,6 +1,7 @@
 package statesync
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -9,12 +10,17 @@ import (
 	"sync"
 	"time"
 
+	"github.com/tendermint/tendermint/internal/libs/tempfile"
 	"github.com/tendermint/tendermint/types"
 )
 
 // errDone is returned by chunkQueue.Next() when all chunks have been returned.
 var errDone = errors.New("chunk queue has completed")
 
+// chunkProgressFile is the file of a chunk queue directory listing the chunks fetched, with their
+// senders, for a restarted node to resume the snapshot.
+const chunkProgressFile = "progress.json"
+
 // chunk contains data for a chunk.
 type chunk struct {
 	Height uint64
@@ -26,7 +32,8 @@ type chunk struct {
 
 // chunkQueue manages chunks for a state sync process, ordering them if requested. It acts as an
 // iterator over all chunks, but callers can request chunks to be retried, optionally after
-// refetching.
+// refetching. The chunks fetched are kept on disk until the queue is closed, so that a queue
+// created again for the snapshot, e.g. after a restart, resumes with them.
 type chunkQueue struct {
 	sync.Mutex
 	snapshot       *snapshot                  // if this is nil, the queue has been closed
@@ -38,18 +45,24 @@ type chunkQueue struct {
 	waiters        map[uint32][]chan<- uint32 // signals WaitFor() waiters about chunk arrival
 }
 
-// newChunkQueue creates a new chunk queue for a snapshot, using a temp dir for storage.
-// Callers must call Close() when done.
+// newChunkQueue creates a new chunk queue for a snapshot, using a directory of tempDir named
+// after the snapshot for storage. If the directory holds chunks of the snapshot, fetched by a
+// queue which was suspended or interrupted, the queue resumes with them. Callers must call
+// Close() or Suspend() when done.
 func newChunkQueue(snapshot *snapshot, tempDir string) (*chunkQueue, error) {
-	dir, err := os.MkdirTemp(tempDir, "tm-statesync")
-	if err != nil {
-		return nil, fmt.Errorf("unable to create temp dir for state sync chunks: %w", err)
-	}
 	if snapshot.Chunks == 0 {
 		return nil, errors.New("snapshot has no chunks")
 	}
+	if tempDir == "" {
+		tempDir = os.TempDir()
+	}
+	dir := filepath.Join(tempDir,
+		fmt.Sprintf("tm-statesync-%d-%d-%X", snapshot.Height, snapshot.Format, snapshot.Hash))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create temp dir for state sync chunks: %w", err)
+	}
 
-	return &chunkQueue{
+	q := &chunkQueue{
 		snapshot:       snapshot,
 		dir:            dir,
 		chunkFiles:     make(map[uint32]string, snapshot.Chunks),
@@ -57,7 +70,56 @@ func newChunkQueue(snapshot *snapshot, tempDir string) (*chunkQueue, error) {
 		chunkAllocated: make(map[uint32]bool, snapshot.Chunks),
 		chunkReturned:  make(map[uint32]bool, snapshot.Chunks),
 		waiters:        make(map[uint32][]chan<- uint32),
-	}, nil
+	}
+	q.loadProgress()
+	return q, nil
+}
+
+// loadProgress loads the chunks fetched for the snapshot, listed in the progress file, and
+// marks them as allocated, so that they aren't fetched again. An invalid progress file is
+// ignored, and its chunks fetched again.
+func (q *chunkQueue) loadProgress() {
+	bz, err := os.ReadFile(filepath.Join(q.dir, chunkProgressFile))
+	if err != nil {
+		return
+	}
+	var senders map[uint32]types.NodeID
+	if err := json.Unmarshal(bz, &senders); err != nil {
+		return
+	}
+	for index, sender := range senders {
+		if index >= q.snapshot.Chunks {
+			continue
+		}
+		path := q.chunkPath(index)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		q.chunkFiles[index] = path
+		q.chunkSenders[index] = sender
+		q.chunkAllocated[index] = true
+	}
+}
+
+// saveProgress saves the chunks fetched, with their senders, to the progress file. The caller
+// must hold the mutex lock.
+func (q *chunkQueue) saveProgress() error {
+	senders := make(map[uint32]types.NodeID, len(q.chunkFiles))
+	for index := range q.chunkFiles {
+		senders[index] = q.chunkSenders[index]
+	}
+	bz, err := json.Marshal(senders)
+	if err != nil {
+		return err
+	}
+	if err := tempfile.WriteFileAtomic(filepath.Join(q.dir, chunkProgressFile), bz, 0600); err != nil {
+		return fmt.Errorf("failed to save the state sync progress: %w", err)
+	}
+	return nil
+}
+
+func (q *chunkQueue) chunkPath(index uint32) string {
+	return filepath.Join(q.dir, strconv.FormatUint(uint64(index), 10))
 }
 
 // Add adds a chunk to the queue. It ignores chunks that already exist, returning false.
@@ -85,14 +147,17 @@ func (q *chunkQueue) Add(chunk *chunk) (bool, error) {
 		return false, nil
 	}
 
-	path := filepath.Join(q.dir, strconv.FormatUint(uint64(chunk.Index), 10))
-	err := os.WriteFile(path, chunk.Chunk, 0600)
+	path := q.chunkPath(chunk.Index)
+	err := tempfile.WriteFileAtomic(path, chunk.Chunk, 0600)
 	if err != nil {
 		return false, fmt.Errorf("failed to save chunk %v to file %v: %w", chunk.Index, path, err)
 	}
 
 	q.chunkFiles[chunk.Index] = path
 	q.chunkSenders[chunk.Index] = chunk.Sender
+	if err := q.saveProgress(); err != nil {
+		return false, err
+	}
 
 	// Signal any waiters that the chunk has arrived.
 	for _, waiter := range q.waiters[chunk.Index] {
@@ -137,7 +202,28 @@ func (q *chunkQueue) Close() error {
 	if q.snapshot == nil {
 		return nil
 	}
+	q.close()
 
+	if err := os.RemoveAll(q.dir); err != nil {
+		return fmt.Errorf("failed to clean up state sync tempdir %v: %w", q.dir, err)
+	}
+
+	return nil
+}
+
+// Suspend closes the chunk queue, keeping the chunks fetched on disk for a queue created again
+// for the snapshot to resume with them.
+func (q *chunkQueue) Suspend() {
+	q.Lock()
+	defer q.Unlock()
+
+	if q.snapshot != nil {
+		q.close()
+	}
+}
+
+// close releases the waiters and marks the queue as closed. The caller must hold the mutex lock.
+func (q *chunkQueue) close() {
 	for _, waiters := range q.waiters {
 		for _, waiter := range waiters {
 			close(waiter)
@@ -146,12 +232,6 @@ func (q *chunkQueue) Close() error {
 
 	q.waiters = nil
 	q.snapshot = nil
-
-	if err := os.RemoveAll(q.dir); err != nil {
-		return fmt.Errorf("failed to clean up state sync tempdir %v: %w", q.dir, err)
-	}
-
-	return nil
 }
 
 // Discard discards a chunk. It will be removed from the queue, available for allocation, and can
@@ -182,7 +262,7 @@ func (q *chunkQueue) discard(index uint32) error {
 	delete(q.chunkReturned, index)
 	delete(q.chunkAllocated, index)
 
-	return nil
+	return q.saveProgress()
 }
 
 // DiscardSender discards all *unreturned* chunks from a given sender. If the caller wants to
@@ -355,6 +435,13 @@ func (q *chunkQueue) WaitFor(index uint32) <-chan uint32 {
 	return ch
 }
 
+// numChunksFetched returns the number of chunks in the queue, e.g. resumed from disk.
+func (q *chunkQueue) numChunksFetched() int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.chunkFiles)
+}
+
 func (q *chunkQueue) numChunksReturned() int {
 	q.Lock()
 	defer q.Unlock()
@@ -53,6 +53,50 @@ func TestNewChunkQueue_TempDir(t *testing.T) {
 	assert.Len(t, files, 0)
 }
 
+func TestChunkQueue_Resume(t *testing.T) {
+	s := &snapshot{Height: 3, Format: 1, Chunks: 5, Hash: []byte{7}}
+	dir := t.TempDir()
+
+	queue, err := newChunkQueue(s, dir)
+	require.NoError(t, err)
+	for _, index := range []uint32{0, 1, 3} {
+		_, err := queue.Add(&chunk{Height: 3, Format: 1, Index: index, Chunk: []byte{3, 1, byte(index)}, Sender: "a"})
+		require.NoError(t, err)
+	}
+	require.NoError(t, queue.Discard(1))
+	queue.Suspend()
+
+	// The chunks fetched before are kept, and not allocated again.
+	queue, err = newChunkQueue(s, dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, queue.numChunksFetched())
+	assert.True(t, queue.Has(0))
+	assert.False(t, queue.Has(1))
+	assert.True(t, queue.Has(3))
+	assert.EqualValues(t, "a", queue.GetSender(3))
+	for _, expected := range []uint32{1, 2, 4} {
+		index, err := queue.Allocate()
+		require.NoError(t, err)
+		assert.Equal(t, expected, index)
+	}
+	c, err := queue.Next()
+	require.NoError(t, err)
+	assert.Equal(t, &chunk{Height: 3, Format: 1, Index: 0, Chunk: []byte{3, 1, 0}, Sender: "a"}, c)
+
+	// Closing the queue removes the chunks.
+	require.NoError(t, queue.Close())
+	queue, err = newChunkQueue(s, dir)
+	require.NoError(t, err)
+	defer queue.Close()
+	assert.Equal(t, 0, queue.numChunksFetched())
+
+	// The chunks of another snapshot are in another directory.
+	other, err := newChunkQueue(&snapshot{Height: 3, Format: 1, Chunks: 5, Hash: []byte{8}}, dir)
+	require.NoError(t, err)
+	defer other.Close()
+	assert.NotEqual(t, queue.dir, other.dir)
+}
+
 func TestChunkQueue(t *testing.T) {
 	queue, teardown := setupChunkQueue(t)
 	defer teardown()
@@ -0,0 +1,142 @@
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+)
+
+// maxManifestSize is the maximum size of a snapshot manifest served by a chunk
+// mirror.
+const maxManifestSize = 16 << 20
+
+// snapshotManifest is the manifest of a snapshot published by a chunk mirror,
+// at <mirror>/<height>/<format>/manifest.json. The chunks are concatenated in
+// order at <mirror>/<height>/<format>/chunks.
+type snapshotManifest struct {
+	Height uint64           `json:"height"`
+	Format uint32           `json:"format"`
+	Hash   tmbytes.HexBytes `json:"hash"`
+	Chunks []SnapshotChunk  `json:"chunks"`
+}
+
+// SnapshotChunk describes a chunk of a snapshot, in a snapshot manifest or in
+// the records of the snapshot service.
+type SnapshotChunk struct {
+	Size int64            `json:"size"`
+	Hash tmbytes.HexBytes `json:"hash"` // SHA-256 of the chunk
+}
+
+// chunkMirror fetches the chunks of a snapshot from an HTTPS mirror, with
+// range requests, verifying them against the manifest of the mirror.
+type chunkMirror struct {
+	url     string // of the snapshot, i.e. <mirror>/<height>/<format>
+	client  *http.Client
+	chunks  []SnapshotChunk
+	offsets []int64
+}
+
+// loadChunkMirror fetches the manifest of snapshot from mirror and checks that
+// it describes the same snapshot.
+func loadChunkMirror(
+	ctx context.Context,
+	client *http.Client,
+	mirror string,
+	snapshot *snapshot,
+) (*chunkMirror, error) {
+	url := fmt.Sprintf("%s/%d/%d", strings.TrimSuffix(mirror, "/"), snapshot.Height, snapshot.Format)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/manifest.json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q for the manifest", resp.Status)
+	}
+
+	var manifest snapshotManifest
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	switch {
+	case manifest.Height != snapshot.Height || manifest.Format != snapshot.Format:
+		return nil, fmt.Errorf("manifest is for the snapshot at height %d in format %d",
+			manifest.Height, manifest.Format)
+	case !bytes.Equal(manifest.Hash, snapshot.Hash):
+		return nil, fmt.Errorf("manifest hash %v does not match the snapshot hash %X",
+			manifest.Hash, snapshot.Hash)
+	case uint32(len(manifest.Chunks)) != snapshot.Chunks:
+		return nil, fmt.Errorf("manifest has %d chunks, expected %d", len(manifest.Chunks), snapshot.Chunks)
+	}
+
+	offsets := make([]int64, len(manifest.Chunks))
+	var offset int64
+	for i, c := range manifest.Chunks {
+		if c.Size < 0 || c.Size > int64(chunkMsgSize) {
+			return nil, fmt.Errorf("invalid size %d of chunk %d", c.Size, i)
+		}
+		if len(c.Hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid hash of chunk %d", i)
+		}
+		offsets[i] = offset
+		offset += c.Size
+	}
+
+	return &chunkMirror{
+		url:     url,
+		client:  client,
+		chunks:  manifest.Chunks,
+		offsets: offsets,
+	}, nil
+}
+
+// fetchChunk fetches the chunk index with a range request, and checks its
+// hash.
+func (m *chunkMirror) fetchChunk(ctx context.Context, index uint32) ([]byte, error) {
+	if int(index) >= len(m.chunks) {
+		return nil, fmt.Errorf("unexpected chunk %d", index)
+	}
+	size := m.chunks[index].Size
+
+	bz := []byte{}
+	if size > 0 {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.url+"/chunks", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", m.offsets[index], m.offsets[index]+size-1))
+		resp, err := m.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusPartialContent {
+			return nil, fmt.Errorf("unexpected status %q for a range request", resp.Status)
+		}
+
+		bz, err = io.ReadAll(io.LimitReader(resp.Body, size+1))
+		if err != nil {
+			return nil, err
+		}
+		if int64(len(bz)) != size {
+			return nil, fmt.Errorf("received %d bytes, expected %d", len(bz), size)
+		}
+	}
+
+	if hash := sha256.Sum256(bz); !bytes.Equal(hash[:], m.chunks[index].Hash) {
+		return nil, errors.New("chunk hash does not match the manifest")
+	}
+	return bz, nil
+}
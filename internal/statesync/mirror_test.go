@@ -0,0 +1,121 @@
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestMirror serves chunks of the snapshot s from an HTTPS mirror, with the
+// given manifest, or a valid one if nil.
+func newTestMirror(t *testing.T, s *snapshot, chunks [][]byte, manifest *snapshotManifest) *httptest.Server {
+	t.Helper()
+
+	if manifest == nil {
+		manifest = &snapshotManifest{Height: s.Height, Format: s.Format, Hash: s.Hash}
+		for _, c := range chunks {
+			hash := sha256.Sum256(c)
+			manifest.Chunks = append(manifest.Chunks, SnapshotChunk{Size: int64(len(c)), Hash: hash[:]})
+		}
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	data := bytes.Join(chunks, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1/1/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(manifestJSON)
+	})
+	mux.HandleFunc("/1/1/chunks", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "chunks", time.Time{}, bytes.NewReader(data))
+	})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestChunkMirror(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &snapshot{Height: 1, Format: 1, Chunks: 3, Hash: []byte{1, 2, 3}}
+	chunks := [][]byte{{1, 1, 0}, {}, {1, 1, 2, 2}}
+
+	srv := newTestMirror(t, s, chunks, nil)
+	mirror, err := loadChunkMirror(ctx, srv.Client(), srv.URL+"/", s)
+	require.NoError(t, err)
+	for i, c := range chunks {
+		bz, err := mirror.fetchChunk(ctx, uint32(i))
+		require.NoError(t, err)
+		require.Equal(t, c, bz)
+	}
+	_, err = mirror.fetchChunk(ctx, 3)
+	require.Error(t, err)
+
+	// The chunks are checked against the manifest.
+	mirror.chunks[2].Hash = mirror.chunks[0].Hash
+	_, err = mirror.fetchChunk(ctx, 2)
+	require.Error(t, err)
+
+	// The mirrors must serve the same snapshot.
+	_, err = loadChunkMirror(ctx, srv.Client(), srv.URL, &snapshot{Height: 1, Format: 1, Chunks: 3, Hash: []byte{1}})
+	require.Error(t, err)
+	_, err = loadChunkMirror(ctx, srv.Client(), srv.URL, &snapshot{Height: 1, Format: 1, Chunks: 2, Hash: s.Hash})
+	require.Error(t, err)
+	_, err = loadChunkMirror(ctx, srv.Client(), srv.URL, &snapshot{Height: 2, Format: 1, Chunks: 3, Hash: s.Hash})
+	require.Error(t, err)
+
+	bad := newTestMirror(t, s, chunks, &snapshotManifest{
+		Height: 1, Format: 1, Hash: s.Hash,
+		Chunks: []SnapshotChunk{{Size: 3, Hash: []byte{1}}, {}, {}},
+	})
+	_, err = loadChunkMirror(ctx, bad.Client(), bad.URL, s)
+	require.Error(t, err)
+}
+
+func TestSyncer_fetchChunks_mirrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := &snapshot{Height: 1, Format: 1, Chunks: 3, Hash: []byte{1, 2, 3}}
+	chunks := [][]byte{{1, 1, 0}, {1, 1, 1}, {1, 1, 2}}
+
+	rts := setup(ctx, t, nil, nil, nil, 2)
+
+	// The first mirror serves corrupted chunks, so they are fetched from the
+	// second one.
+	corrupted := newTestMirror(t, s, [][]byte{{0, 0, 0}, {0, 0, 0}, {0, 0, 0}}, nil)
+	srv := newTestMirror(t, s, chunks, nil)
+	rts.syncer.chunkMirrors = []string{corrupted.URL, srv.URL, "https://127.0.0.1:0"}
+	rts.syncer.httpClient = srv.Client()
+
+	mirrors := rts.syncer.loadChunkMirrors(ctx, s)
+	require.Len(t, mirrors, 2)
+	mirrors[0].chunks = mirrors[1].chunks
+
+	queue, err := newChunkQueue(s, t.TempDir())
+	require.NoError(t, err)
+	defer queue.Close()
+	go rts.syncer.fetchChunks(ctx, s, queue, mirrors)
+
+	for i, c := range chunks {
+		select {
+		case <-queue.WaitFor(uint32(i)):
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for chunk %d", i)
+		}
+		next, err := queue.Next()
+		require.NoError(t, err)
+		require.Equal(t, c, next.Chunk)
+	}
+
+	// No chunk was requested from peers.
+	require.Empty(t, rts.chunkOutCh)
+}
@@ -158,6 +158,7 @@ type Reactor struct {
 	stateProvider StateProvider
 
 	eventBus           *eventbus.EventBus
+	maintenance        *p2p.PeerMaintenance
 	metrics            *Metrics
 	backfillBlockTotal int64
 	backfilledBlocks   int64
@@ -252,6 +253,13 @@ func (r *Reactor) OnStop() {
 	r.dispatcher.Close()
 }
 
+// SetPeerMaintenance sets the tracker of announced peer maintenance windows,
+// used to avoid fetching chunks from peers about to go down. It must be
+// called before Sync.
+func (r *Reactor) SetPeerMaintenance(maintenance *p2p.PeerMaintenance) {
+	r.maintenance = maintenance
+}
+
 func (r *Reactor) PublishStatus(ctx context.Context, event types.EventDataStateSyncStatus) error {
 	if r.eventBus == nil {
 		return errors.New("event system is not configured")
@@ -264,7 +272,9 @@ func (r *Reactor) PublishStatus(ctx context.Context, event types.EventDataStateS
 // application. At the close of the operation, Sync will bootstrap the state
 // store and persist the commit at that height so that either consensus or
 // blocksync can commence. It will then proceed to backfill the necessary amount
-// of historical blocks before participating in consensus
+// of historical blocks before participating in consensus, and, if a backfill
+// height is configured, keep backfilling the blocks down to it in the
+// background.
 func (r *Reactor) Sync(ctx context.Context) (sm.State, error) {
 	// We need at least two peers (for cross-referencing of light blocks) before we can
 	// begin state sync
@@ -294,6 +304,7 @@ func (r *Reactor) Sync(ctx context.Context) (sm.State, error) {
 		r.tempDir,
 		r.metrics,
 	)
+	r.syncer.snapshots.maintenance = r.maintenance
 	r.mtx.Unlock()
 	defer func() {
 		r.mtx.Lock()
@@ -331,6 +342,14 @@ func (r *Reactor) Sync(ctx context.Context) (sm.State, error) {
 		r.logger.Error("backfill failed. Proceeding optimistically...", "err", err)
 	}
 
+	if r.cfg.BackfillHeight > 0 {
+		go func() {
+			if err := r.backfillHistory(ctx, state); err != nil {
+				r.logger.Error("background backfill failed", "err", err)
+			}
+		}()
+	}
+
 	return state, nil
 }
 
@@ -359,6 +378,38 @@ func (r *Reactor) Backfill(ctx context.Context, state sm.State) error {
 	)
 }
 
+// backfillHistory fetches, verifies and stores the light blocks below the
+// lowest block of the block store, e.g. after the backfill of the evidence
+// window, down to the backfill height of the config. Each block is verified
+// against the last block ID of the block above it, starting with the lowest
+// block of the store.
+func (r *Reactor) backfillHistory(ctx context.Context, state sm.State) error {
+	stopHeight := r.cfg.BackfillHeight
+	if stopHeight < state.InitialHeight {
+		stopHeight = state.InitialHeight
+	}
+	base := r.blockStore.Base()
+	if base <= stopHeight {
+		return nil
+	}
+	blockMeta := r.blockStore.LoadBlockMeta(base)
+	if blockMeta == nil {
+		return fmt.Errorf("no block meta at the base height %d", base)
+	}
+
+	// The blocks below the base are older than the last block of the
+	// state: only the stop height ends the backfill.
+	return r.backfill(
+		ctx,
+		state.ChainID,
+		base-1,
+		stopHeight,
+		state.InitialHeight,
+		blockMeta.Header.LastBlockID,
+		state.LastBlockTime,
+	)
+}
+
 func (r *Reactor) backfill(
 	ctx context.Context,
 	chainID string,
@@ -369,8 +420,10 @@ func (r *Reactor) backfill(
 	r.logger.Info("starting backfill process...", "startHeight", startHeight,
 		"stopHeight", stopHeight, "stopTime", stopTime, "trustedBlockID", trustedBlockID)
 
-	r.backfillBlockTotal = startHeight - stopHeight + 1
+	r.mtx.Lock()
+	r.backfillBlockTotal += startHeight - stopHeight + 1
 	r.metrics.BackFillBlocksTotal.Set(float64(r.backfillBlockTotal))
+	r.mtx.Unlock()
 
 	const sleepTime = 1 * time.Second
 	var (
@@ -507,6 +560,7 @@ func (r *Reactor) backfill(
 
 			lastValidatorSet = resp.block.ValidatorSet
 
+			r.mtx.Lock()
 			r.backfilledBlocks++
 			r.metrics.BackFilledBlocks.Add(1)
 
@@ -516,6 +570,7 @@ func (r *Reactor) backfill(
 				r.backfillBlockTotal++
 				r.metrics.BackFillBlocksTotal.Set(float64(r.backfillBlockTotal))
 			}
+			r.mtx.Unlock()
 
 		case <-queue.done():
 			if err := queue.error(); err != nil {
@@ -18,6 +18,7 @@ import (
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/proxy"
 	proxymocks "github.com/tendermint/tendermint/internal/proxy/mocks"
+	sm "github.com/tendermint/tendermint/internal/state"
 	smmocks "github.com/tendermint/tendermint/internal/state/mocks"
 	"github.com/tendermint/tendermint/internal/statesync/mocks"
 	"github.com/tendermint/tendermint/internal/store"
@@ -691,6 +692,52 @@ func TestReactor_Backfill(t *testing.T) {
 	}
 }
 
+func TestReactor_BackfillHistory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t.Cleanup(leaktest.CheckTimeout(t, 1*time.Minute))
+	rts := setup(ctx, t, nil, nil, nil, 21)
+	rts.reactor.cfg.BackfillHeight = 10
+
+	for _, peer := range []string{"a", "b", "c", "d"} {
+		rts.peerUpdateCh <- p2p.PeerUpdate{
+			NodeID: types.NodeID(peer),
+			Status: p2p.PeerStatusUp,
+		}
+	}
+	rts.stateStore.On("SaveValidatorSets", mock.AnythingOfType("int64"), mock.AnythingOfType("int64"),
+		mock.AnythingOfType("*types.ValidatorSet")).Return(nil)
+
+	startTime := time.Date(2020, 1, 1, 0, 100, 0, 0, time.UTC)
+	chain := buildLightBlockChain(ctx, t, 5, 21, startTime)
+
+	closeCh := make(chan struct{})
+	defer close(closeCh)
+	go handleLightBlockRequests(ctx, t, chain, rts.blockOutCh, rts.blockInCh, closeCh, 0)
+
+	// The evidence window was backfilled down to the height 15.
+	require.NoError(t, rts.blockStore.SaveSignedHeader(
+		chain[15].SignedHeader, factory.MakeBlockIDWithHash(chain[15].Header.Hash())))
+	state := sm.State{
+		ChainID:       factory.DefaultTestChainID,
+		InitialHeight: 1,
+		LastBlockTime: chain[20].Time,
+	}
+
+	require.NoError(t, rts.reactor.backfillHistory(ctx, state))
+	for height := int64(10); height <= 15; height++ {
+		require.NotNil(t, rts.blockStore.LoadBlockMeta(height), height)
+		require.NotNil(t, rts.blockStore.LoadBlockCommit(height), height)
+	}
+	require.Nil(t, rts.blockStore.LoadBlockMeta(9))
+	require.EqualValues(t, 5, rts.reactor.BackFilledBlocks())
+
+	// The backfill is complete.
+	require.NoError(t, rts.reactor.backfillHistory(ctx, state))
+	require.EqualValues(t, 5, rts.reactor.BackFilledBlocks())
+}
+
 // retryUntil will continue to evaluate fn and will return successfully when true
 // or fail when the timeout is reached.
 func retryUntil(ctx context.Context, t *testing.T, fn func() bool, timeout time.Duration) {
@@ -0,0 +1,279 @@
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/orderedcode"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/internal/proxy"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+)
+
+// prefixSnapshotRecord is the key prefix of the snapshot records in the
+// database of the snapshot service.
+const prefixSnapshotRecord int64 = 1
+
+// SnapshotRecord is a snapshot of the application recorded by the snapshot
+// service, with the sizes and SHA-256 hashes of its chunks: the manifest a
+// chunk mirror publishes for the snapshot.
+type SnapshotRecord struct {
+	Height   uint64           `json:"height"`
+	Format   uint32           `json:"format"`
+	Hash     tmbytes.HexBytes `json:"hash"`
+	Metadata tmbytes.HexBytes `json:"metadata"`
+	Chunks   []SnapshotChunk  `json:"chunks"`
+
+	// RecordedAt is when the service first saw the snapshot.
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// SnapshotStatus is the status of the snapshot service.
+type SnapshotStatus struct {
+	// Snapshots are the recorded snapshots, most recent first.
+	Snapshots []SnapshotRecord
+
+	// LastRefresh is when the service last listed the snapshots of the
+	// application, and LastError the error it failed with, if any.
+	LastRefresh time.Time
+	LastError   string
+}
+
+// SnapshotService keeps track of the snapshots taken by the application. At
+// every interval, it lists the snapshots of the application, records the
+// metadata of the chunks of the new ones, and prunes the records of the
+// snapshots the application dropped, and of all but the keepRecent most
+// recent snapshots, unless keepRecent is 0. The application still takes and
+// deletes the snapshots themselves.
+type SnapshotService struct {
+	service.BaseService
+	logger log.Logger
+
+	conn       proxy.AppConnSnapshot
+	db         dbm.DB
+	interval   time.Duration
+	keepRecent int
+
+	mtx         sync.Mutex
+	lastRefresh time.Time
+	lastErr     error
+}
+
+// NewSnapshotService returns a snapshot service listing the snapshots of the
+// application over conn, and recording them in db.
+func NewSnapshotService(
+	logger log.Logger,
+	conn proxy.AppConnSnapshot,
+	db dbm.DB,
+	interval time.Duration,
+	keepRecent uint32,
+) *SnapshotService {
+	s := &SnapshotService{
+		logger:     logger,
+		conn:       conn,
+		db:         db,
+		interval:   interval,
+		keepRecent: int(keepRecent),
+	}
+	s.BaseService = *service.NewBaseService(logger, "SnapshotService", s)
+	return s
+}
+
+// OnStart starts refreshing the snapshot records. It implements
+// service.Service.
+func (s *SnapshotService) OnStart(ctx context.Context) error {
+	go s.refreshRoutine(ctx)
+	return nil
+}
+
+// OnStop implements service.Service.
+func (s *SnapshotService) OnStop() {}
+
+func (s *SnapshotService) refreshRoutine(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("failed to refresh the snapshot records", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh lists the snapshots of the application, records the new ones, and
+// prunes the records of the old ones.
+func (s *SnapshotService) Refresh(ctx context.Context) error {
+	err := s.refresh(ctx)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.lastRefresh = time.Now()
+	s.lastErr = err
+	return err
+}
+
+func (s *SnapshotService) refresh(ctx context.Context) error {
+	resp, err := s.conn.ListSnapshots(ctx, abci.RequestListSnapshots{})
+	if err != nil {
+		return fmt.Errorf("listing the snapshots: %w", err)
+	}
+	records, err := s.loadRecords()
+	if err != nil {
+		return err
+	}
+
+	recorded := make(map[string]SnapshotRecord, len(records))
+	for _, record := range records {
+		recorded[string(snapshotRecordKey(record.Height, record.Format))] = record
+	}
+
+	// The most recent snapshots are recorded, up to the retention. The
+	// records left are of snapshots the application no longer lists, or
+	// beyond the retention, and are pruned.
+	snapshots := resp.Snapshots
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].Height != snapshots[j].Height {
+			return snapshots[i].Height > snapshots[j].Height
+		}
+		return snapshots[i].Format > snapshots[j].Format
+	})
+	if s.keepRecent > 0 && len(snapshots) > s.keepRecent {
+		snapshots = snapshots[:s.keepRecent]
+	}
+	for _, snapshot := range snapshots {
+		key := string(snapshotRecordKey(snapshot.Height, snapshot.Format))
+		record, ok := recorded[key]
+		delete(recorded, key)
+		if ok && bytes.Equal(record.Hash, snapshot.Hash) {
+			continue
+		}
+		if err := s.recordSnapshot(ctx, snapshot); err != nil {
+			return err
+		}
+	}
+
+	batch := s.db.NewBatch()
+	defer batch.Close()
+	for key, record := range recorded {
+		if err := batch.Delete([]byte(key)); err != nil {
+			return err
+		}
+		s.logger.Debug("pruned snapshot record", "height", record.Height, "format", record.Format)
+	}
+	return batch.WriteSync()
+}
+
+// recordSnapshot loads the chunks of the snapshot to record their sizes and
+// hashes, and saves the record.
+func (s *SnapshotService) recordSnapshot(ctx context.Context, snapshot *abci.Snapshot) error {
+	record := SnapshotRecord{
+		Height:     snapshot.Height,
+		Format:     snapshot.Format,
+		Hash:       snapshot.Hash,
+		Metadata:   snapshot.Metadata,
+		Chunks:     make([]SnapshotChunk, 0, snapshot.Chunks),
+		RecordedAt: time.Now(),
+	}
+	for index := uint32(0); index < snapshot.Chunks; index++ {
+		resp, err := s.conn.LoadSnapshotChunk(ctx, abci.RequestLoadSnapshotChunk{
+			Height: snapshot.Height,
+			Format: snapshot.Format,
+			Chunk:  index,
+		})
+		if err != nil {
+			return fmt.Errorf("loading chunk %d of the snapshot at height %d in format %d: %w",
+				index, snapshot.Height, snapshot.Format, err)
+		}
+		hash := sha256.Sum256(resp.Chunk)
+		record.Chunks = append(record.Chunks, SnapshotChunk{Size: int64(len(resp.Chunk)), Hash: hash[:]})
+	}
+
+	bz, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := s.db.SetSync(snapshotRecordKey(record.Height, record.Format), bz); err != nil {
+		return err
+	}
+	s.logger.Info("recorded snapshot", "height", record.Height, "format", record.Format,
+		"chunks", len(record.Chunks))
+	return nil
+}
+
+// Status returns the status of the service, with the recorded snapshots.
+func (s *SnapshotService) Status() (SnapshotStatus, error) {
+	records, err := s.loadRecords()
+	if err != nil {
+		return SnapshotStatus{}, err
+	}
+	sortSnapshotRecords(records)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	status := SnapshotStatus{Snapshots: records, LastRefresh: s.lastRefresh}
+	if s.lastErr != nil {
+		status.LastError = s.lastErr.Error()
+	}
+	return status, nil
+}
+
+func (s *SnapshotService) loadRecords() ([]SnapshotRecord, error) {
+	start, end := snapshotRecordPrefix(prefixSnapshotRecord), snapshotRecordPrefix(prefixSnapshotRecord+1)
+	iter, err := s.db.Iterator(start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var records []SnapshotRecord
+	for ; iter.Valid(); iter.Next() {
+		var record SnapshotRecord
+		if err := json.Unmarshal(iter.Value(), &record); err != nil {
+			return nil, fmt.Errorf("invalid snapshot record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, iter.Error()
+}
+
+// sortSnapshotRecords sorts the records by height and format, most recent
+// first.
+func sortSnapshotRecords(records []SnapshotRecord) {
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Height != records[j].Height {
+			return records[i].Height > records[j].Height
+		}
+		return records[i].Format > records[j].Format
+	})
+}
+
+func snapshotRecordPrefix(prefix int64) []byte {
+	key, err := orderedcode.Append(nil, prefix)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func snapshotRecordKey(height uint64, format uint32) []byte {
+	key, err := orderedcode.Append(nil, prefixSnapshotRecord, height, uint64(format))
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
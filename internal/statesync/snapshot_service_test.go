@@ -0,0 +1,84 @@
+package statesync
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	dbm "github.com/tendermint/tm-db"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	proxymocks "github.com/tendermint/tendermint/internal/proxy/mocks"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestSnapshotService_Refresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	chunkHash := func(chunk []byte) []byte {
+		hash := sha256.Sum256(chunk)
+		return hash[:]
+	}
+
+	conn := &proxymocks.AppConnSnapshot{}
+	conn.On("ListSnapshots", mock.Anything, abci.RequestListSnapshots{}).Return(&abci.ResponseListSnapshots{
+		Snapshots: []*abci.Snapshot{
+			{Height: 10, Format: 1, Chunks: 2, Hash: []byte{10}},
+			{Height: 30, Format: 1, Chunks: 1, Hash: []byte{30}, Metadata: []byte{3}},
+			{Height: 20, Format: 1, Chunks: 1, Hash: []byte{20}},
+		},
+	}, nil).Once()
+	conn.On("LoadSnapshotChunk", mock.Anything, abci.RequestLoadSnapshotChunk{Height: 30, Format: 1, Chunk: 0}).
+		Return(&abci.ResponseLoadSnapshotChunk{Chunk: []byte("thirty")}, nil).Once()
+	conn.On("LoadSnapshotChunk", mock.Anything, abci.RequestLoadSnapshotChunk{Height: 20, Format: 1, Chunk: 0}).
+		Return(&abci.ResponseLoadSnapshotChunk{Chunk: []byte("twenty")}, nil).Once()
+
+	// Only the two most recent snapshots are recorded, with their chunks.
+	s := NewSnapshotService(log.NewNopLogger(), conn, dbm.NewMemDB(), time.Minute, 2)
+	require.NoError(t, s.Refresh(ctx))
+
+	status, err := s.Status()
+	require.NoError(t, err)
+	require.False(t, status.LastRefresh.IsZero())
+	require.Empty(t, status.LastError)
+	require.Len(t, status.Snapshots, 2)
+	require.EqualValues(t, 30, status.Snapshots[0].Height)
+	require.EqualValues(t, []byte{3}, status.Snapshots[0].Metadata)
+	require.Equal(t, []SnapshotChunk{{Size: 6, Hash: chunkHash([]byte("thirty"))}}, status.Snapshots[0].Chunks)
+	require.EqualValues(t, 20, status.Snapshots[1].Height)
+	require.Equal(t, []SnapshotChunk{{Size: 6, Hash: chunkHash([]byte("twenty"))}}, status.Snapshots[1].Chunks)
+
+	// A new snapshot pushes the oldest record out, and the snapshots already
+	// recorded aren't loaded again.
+	conn.On("ListSnapshots", mock.Anything, abci.RequestListSnapshots{}).Return(&abci.ResponseListSnapshots{
+		Snapshots: []*abci.Snapshot{
+			{Height: 20, Format: 1, Chunks: 1, Hash: []byte{20}},
+			{Height: 30, Format: 1, Chunks: 1, Hash: []byte{30}, Metadata: []byte{3}},
+			{Height: 40, Format: 1, Chunks: 0, Hash: []byte{40}},
+		},
+	}, nil).Once()
+	require.NoError(t, s.Refresh(ctx))
+
+	status, err = s.Status()
+	require.NoError(t, err)
+	require.Len(t, status.Snapshots, 2)
+	require.EqualValues(t, 40, status.Snapshots[0].Height)
+	require.Empty(t, status.Snapshots[0].Chunks)
+	require.EqualValues(t, 30, status.Snapshots[1].Height)
+
+	// The failures are reported in the status, and the records kept.
+	conn.On("ListSnapshots", mock.Anything, abci.RequestListSnapshots{}).
+		Return(nil, errors.New("boom")).Once()
+	require.Error(t, s.Refresh(ctx))
+
+	status, err = s.Status()
+	require.NoError(t, err)
+	require.Contains(t, status.LastError, "boom")
+	require.Len(t, status.Snapshots, 2)
+	conn.AssertExpectations(t)
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/types"
 )
 
@@ -54,6 +55,16 @@ type snapshotPool struct {
 	formatBlacklist   map[uint32]bool
 	peerBlacklist     map[types.NodeID]bool
 	snapshotBlacklist map[snapshotKey]bool
+
+	// maintenance, if set, tells which peers are about to go down for
+	// maintenance; they are only picked when no other peer has the snapshot.
+	maintenance *p2p.PeerMaintenance
+
+	// preferred are the preferred snapshot providers: their snapshots are
+	// ranked first, and they are picked first for the chunks. If allowlist
+	// is set, the snapshots of the other peers are refused.
+	preferred map[types.NodeID]bool
+	allowlist bool
 }
 
 // newSnapshotPool creates a new empty snapshot pool.
@@ -85,6 +96,8 @@ func (p *snapshotPool) Add(peerID types.NodeID, snapshot *snapshot) (bool, error
 		return false, nil
 	case p.peerBlacklist[peerID]:
 		return false, nil
+	case !p.allowed(peerID):
+		return false, nil
 	case p.snapshotBlacklist[key]:
 		return false, nil
 	case len(p.peerIndex[peerID]) >= recentSnapshots:
@@ -128,12 +141,50 @@ func (p *snapshotPool) Best() *snapshot {
 	return ranked[0]
 }
 
-// GetPeer returns a random peer for a snapshot, if any.
+// GetPeer returns a random peer for a snapshot, if any, a preferred provider if one offered it.
 func (p *snapshotPool) GetPeer(snapshot *snapshot) types.NodeID {
+	return p.GetPeerExcluding(snapshot, nil)
+}
+
+// GetPeerExcluding is as GetPeer, but only returns one of the excluded peers if the snapshot has
+// no other peers.
+func (p *snapshotPool) GetPeerExcluding(snapshot *snapshot, excluded map[types.NodeID]bool) types.NodeID {
 	peers := p.GetPeers(snapshot)
 	if len(peers) == 0 {
 		return ""
 	}
+
+	if len(excluded) > 0 {
+		others := make([]types.NodeID, 0, len(peers))
+		for _, peer := range peers {
+			if !excluded[peer] {
+				others = append(others, peer)
+			}
+		}
+		if len(others) > 0 {
+			peers = others
+		}
+	}
+
+	available := make([]types.NodeID, 0, len(peers))
+	for _, peer := range peers {
+		if !p.maintenance.Avoid(peer) {
+			available = append(available, peer)
+		}
+	}
+	if len(available) > 0 {
+		peers = available
+	}
+
+	preferred := make([]types.NodeID, 0, len(peers))
+	for _, peer := range peers {
+		if p.preferred[peer] {
+			preferred = append(preferred, peer)
+		}
+	}
+	if len(preferred) > 0 {
+		peers = preferred
+	}
 	return peers[rand.Intn(len(peers))] // nolint:gosec // G404: Use of weak random number generator
 }
 
@@ -157,9 +208,10 @@ func (p *snapshotPool) GetPeers(snapshot *snapshot) []types.NodeID {
 	return peers
 }
 
-// Ranked returns a list of snapshots ranked by preference. The current heuristic is very naïve,
-// preferring the snapshot with the greatest height, then greatest format, then greatest number of
-// peers. This can be improved quite a lot.
+// Ranked returns a list of snapshots ranked by preference. The snapshots offered by a preferred
+// provider come first. Otherwise, the current heuristic is very naïve, preferring the snapshot
+// with the greatest height, then greatest format, then greatest number of peers. This can be
+// improved quite a lot.
 func (p *snapshotPool) Ranked() []*snapshot {
 	p.Lock()
 	defer p.Unlock()
@@ -194,7 +246,32 @@ func (p *snapshotPool) Ranked() []*snapshot {
 	sort.Slice(commonCandidates, p.sorterFactory(commonCandidates))
 	sort.Slice(uncommonCandidates, p.sorterFactory(uncommonCandidates))
 
-	return append(commonCandidates, uncommonCandidates...)
+	ranked := append(commonCandidates, uncommonCandidates...)
+	if len(p.preferred) > 0 {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return p.offeredByPreferred(ranked[i]) && !p.offeredByPreferred(ranked[j])
+		})
+	}
+	return ranked
+}
+
+// offeredByPreferred reports whether a preferred provider offered the
+// snapshot.
+//
+// NOTE: requires the pool lock
+func (p *snapshotPool) offeredByPreferred(snapshot *snapshot) bool {
+	for peer := range p.snapshotPeers[snapshot.Key()] {
+		if p.preferred[peer] {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed reports whether the snapshots and chunks of the peer are accepted:
+// unless the allowlist is set, those of all the peers are.
+func (p *snapshotPool) allowed(peerID types.NodeID) bool {
+	return !p.allowlist || p.preferred[peerID]
 }
 
 func (p *snapshotPool) sorterFactory(candidates []*snapshot) func(int, int) bool {
@@ -100,6 +100,63 @@ func TestSnapshotPool_GetPeer(t *testing.T) {
 	require.EqualValues(t, "", peer)
 }
 
+func TestSnapshotPool_GetPeerExcluding(t *testing.T) {
+	pool := newSnapshotPool()
+
+	s := &snapshot{Height: 1, Format: 1, Chunks: 1, Hash: []byte{1}}
+	peerAID := types.NodeID("aa")
+	peerBID := types.NodeID("bb")
+	for _, peerID := range []types.NodeID{peerAID, peerBID} {
+		_, err := pool.Add(peerID, s)
+		require.NoError(t, err)
+	}
+
+	// The excluded peers are only returned once all the peers are excluded.
+	for i := 0; i < 10; i++ {
+		require.Equal(t, peerBID, pool.GetPeerExcluding(s, map[types.NodeID]bool{peerAID: true}))
+	}
+	peer := pool.GetPeerExcluding(s, map[types.NodeID]bool{peerAID: true, peerBID: true})
+	require.Contains(t, []types.NodeID{peerAID, peerBID}, peer)
+}
+
+func TestSnapshotPool_PreferredProviders(t *testing.T) {
+	peerAID := types.NodeID("aa")
+	peerBID := types.NodeID("bb")
+	low := &snapshot{Height: 1, Format: 1, Chunks: 1, Hash: []byte{1}}
+	high := &snapshot{Height: 2, Format: 1, Chunks: 1, Hash: []byte{2}}
+
+	pool := newSnapshotPool()
+	pool.preferred = map[types.NodeID]bool{peerAID: true}
+	for _, peerID := range []types.NodeID{peerAID, peerBID} {
+		_, err := pool.Add(peerID, low)
+		require.NoError(t, err)
+	}
+	_, err := pool.Add(peerBID, high)
+	require.NoError(t, err)
+
+	// The snapshot of the preferred provider is ranked first, despite its
+	// lower height, and the preferred provider is picked for its chunks.
+	ranked := pool.Ranked()
+	require.Len(t, ranked, 2)
+	require.Equal(t, low, ranked[0])
+	require.Equal(t, high, ranked[1])
+	for i := 0; i < 10; i++ {
+		require.Equal(t, peerAID, pool.GetPeer(low))
+	}
+
+	// With the allowlist, the snapshots of the other peers are refused.
+	pool = newSnapshotPool()
+	pool.preferred = map[types.NodeID]bool{peerAID: true}
+	pool.allowlist = true
+	added, err := pool.Add(peerBID, high)
+	require.NoError(t, err)
+	require.False(t, added)
+	added, err = pool.Add(peerAID, low)
+	require.NoError(t, err)
+	require.True(t, added)
+	require.Equal(t, []*snapshot{low}, pool.Ranked())
+}
+
 func TestSnapshotPool_GetPeers(t *testing.T) {
 	pool := newSnapshotPool()
 
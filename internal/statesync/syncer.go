@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -26,6 +27,10 @@ const (
 	// minimumDiscoveryTime is the lowest allowable time for a
 	// SyncAny discovery time.
 	minimumDiscoveryTime = 5 * time.Second
+
+	// maxChunkRequestBackoff is the maximum number of times the timeout of a chunk request is
+	// doubled, on each request of the chunk.
+	maxChunkRequestBackoff = 3
 )
 
 var (
@@ -62,6 +67,8 @@ type syncer struct {
 	tempDir       string
 	fetchers      int32
 	retryTimeout  time.Duration
+	chunkMirrors  []string
+	httpClient    *http.Client
 
 	mtx     sync.RWMutex
 	chunks  *chunkQueue
@@ -84,17 +91,26 @@ func newSyncer(
 	tempDir string,
 	metrics *Metrics,
 ) *syncer {
+	snapshots := newSnapshotPool()
+	snapshots.allowlist = cfg.ProviderAllowlist
+	snapshots.preferred = make(map[types.NodeID]bool, len(cfg.PreferredProviders))
+	for _, id := range cfg.PreferredProviders {
+		snapshots.preferred[types.NodeID(id)] = true
+	}
+
 	return &syncer{
 		logger:        logger,
 		stateProvider: stateProvider,
 		conn:          conn,
 		connQuery:     connQuery,
-		snapshots:     newSnapshotPool(),
+		snapshots:     snapshots,
 		snapshotCh:    snapshotCh,
 		chunkCh:       chunkCh,
 		tempDir:       tempDir,
 		fetchers:      cfg.Fetchers,
 		retryTimeout:  cfg.ChunkRequestTimeout,
+		chunkMirrors:  cfg.ChunkMirrors,
+		httpClient:    &http.Client{Timeout: cfg.ChunkRequestTimeout},
 		metrics:       metrics,
 	}
 }
@@ -107,6 +123,9 @@ func (s *syncer) AddChunk(chunk *chunk) (bool, error) {
 	if s.chunks == nil {
 		return false, errors.New("no state sync in progress")
 	}
+	if !s.snapshots.allowed(chunk.Sender) {
+		return false, fmt.Errorf("chunk sent by %v, which is not an allowed snapshot provider", chunk.Sender)
+	}
 	added, err := s.chunks.Add(chunk)
 	if err != nil {
 		return false, err
@@ -139,6 +158,10 @@ func (s *syncer) AddSnapshot(peerID types.NodeID, snapshot *snapshot) (bool, err
 // AddPeer adds a peer to the pool. For now we just keep it simple and send a
 // single request to discover snapshots, later we may want to do retries and stuff.
 func (s *syncer) AddPeer(ctx context.Context, peerID types.NodeID) error {
+	if !s.snapshots.allowed(peerID) {
+		s.logger.Debug("Not requesting snapshots from peer; not an allowed provider", "peer", peerID)
+		return nil
+	}
 	s.logger.Debug("Requesting snapshots from peer", "peer", peerID)
 
 	return s.snapshotCh.Send(ctx, p2p.Envelope{
@@ -200,6 +223,10 @@ func (s *syncer) SyncAny(
 				return sm.State{}, nil, fmt.Errorf("failed to create chunk queue: %w", err)
 			}
 			defer chunks.Close() // in case we forget to close it elsewhere
+			if fetched := chunks.numChunksFetched(); fetched > 0 {
+				s.logger.Info("Resuming snapshot with the chunks fetched before", "height", snapshot.Height,
+					"format", snapshot.Format, "hash", snapshot.Hash, "chunks", fetched, "total", snapshot.Chunks)
+			}
 		}
 
 		s.processingSnapshot = snapshot
@@ -207,6 +234,11 @@ func (s *syncer) SyncAny(
 
 		newState, commit, err := s.Sync(ctx, snapshot, chunks)
 		switch {
+		case err != nil && ctx.Err() != nil:
+			// Keep the chunks fetched, to resume the snapshot on restart.
+			chunks.Suspend()
+			return sm.State{}, nil, ctx.Err()
+
 		case err == nil:
 			s.metrics.SnapshotHeight.Set(float64(snapshot.Height))
 			s.lastSyncedSnapshotHeight = int64(snapshot.Height)
@@ -301,12 +333,14 @@ func (s *syncer) Sync(ctx context.Context, snapshot *snapshot, chunks *chunkQueu
 		return sm.State{}, nil, err
 	}
 
+	mirrors := s.loadChunkMirrors(ctx, snapshot)
+
 	// Spawn chunk fetchers. They will terminate when the chunk queue is closed or context canceled.
 	fetchCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
 	fetchStartTime := time.Now()
 	for i := int32(0); i < s.fetchers; i++ {
-		go s.fetchChunks(fetchCtx, snapshot, chunks)
+		go s.fetchChunks(fetchCtx, snapshot, chunks, mirrors)
 	}
 
 	pctx, pcancel := context.WithTimeout(ctx, 1*time.Minute)
@@ -459,17 +493,40 @@ func (s *syncer) applyChunks(ctx context.Context, chunks *chunkQueue, start time
 	}
 }
 
-// fetchChunks requests chunks from peers, receiving allocations from the chunk queue. Chunks
-// will be received from the reactor via syncer.AddChunks() to chunkQueue.Add().
-func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *chunkQueue) {
+// loadChunkMirrors loads the manifests of snapshot from the chunk mirrors, skipping the mirrors
+// which don't serve it.
+func (s *syncer) loadChunkMirrors(ctx context.Context, snapshot *snapshot) []*chunkMirror {
+	var mirrors []*chunkMirror
+	for _, url := range s.chunkMirrors {
+		mirror, err := loadChunkMirror(ctx, s.httpClient, url, snapshot)
+		if err != nil {
+			s.logger.Info("Skipping chunk mirror", "mirror", url, "height", snapshot.Height,
+				"format", snapshot.Format, "err", err)
+			continue
+		}
+		mirrors = append(mirrors, mirror)
+	}
+	return mirrors
+}
+
+// fetchChunks fetches chunks from the mirrors, if any, or requests them from peers, receiving
+// allocations from the chunk queue. Chunks requested from peers will be received from the
+// reactor via syncer.AddChunks() to chunkQueue.Add(). A chunk not received in time is requested
+// again, from another peer if possible, waiting twice as long as for the previous request, up
+// to maxChunkRequestBackoff times.
+func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *chunkQueue, mirrors []*chunkMirror) {
 	var (
-		next  = true
-		index uint32
-		err   error
+		next    = true
+		index   uint32
+		err     error
+		attempt int
+		tried   map[types.NodeID]bool // peers the chunk was requested from
 	)
 
 	for {
 		if next {
+			attempt = 0
+			tried = make(map[types.NodeID]bool)
 			index, err = chunks.Allocate()
 			if errors.Is(err, errDone) {
 				// Keep checking until the context is canceled (restore is done), in case any
@@ -489,39 +546,102 @@ func (s *syncer) fetchChunks(ctx context.Context, snapshot *snapshot, chunks *ch
 		s.logger.Info("Fetching snapshot chunk", "height", snapshot.Height,
 			"format", snapshot.Format, "chunk", index, "total", chunks.Size())
 
-		ticker := time.NewTicker(s.retryTimeout)
-		defer ticker.Stop()
+		if s.fetchChunkFromMirrors(ctx, snapshot, chunks, mirrors, index) {
+			next = true
+			continue
+		}
 
-		if err := s.requestChunk(ctx, snapshot, index); err != nil {
+		peer, err := s.requestChunk(ctx, snapshot, index, tried)
+		if err != nil {
 			return
 		}
+		if peer != "" {
+			tried[peer] = true
+		}
+		timer := time.NewTimer(chunkRequestTimeout(s.retryTimeout, attempt))
+		attempt++
 
 		select {
 		case <-chunks.WaitFor(index):
 			next = true
 
-		case <-ticker.C:
+		case <-timer.C:
 			next = false
+			s.logger.Debug("Timed out waiting for snapshot chunk", "height", snapshot.Height,
+				"format", snapshot.Format, "chunk", index, "peer", peer, "attempt", attempt)
 
 		case <-ctx.Done():
+			timer.Stop()
 			return
 		}
 
-		ticker.Stop()
+		timer.Stop()
+	}
+}
+
+// chunkRequestTimeout returns how long to wait for a chunk after its attempt-th request,
+// starting from 0: timeout, doubled on each attempt up to maxChunkRequestBackoff times.
+func chunkRequestTimeout(timeout time.Duration, attempt int) time.Duration {
+	if attempt > maxChunkRequestBackoff {
+		attempt = maxChunkRequestBackoff
+	}
+	return timeout << uint(attempt)
+}
+
+// fetchChunkFromMirrors fetches a chunk from the mirrors and adds it to the chunk queue, starting
+// with a different mirror for each chunk to spread the load. It returns false if no mirror
+// served the chunk, which must then be requested from peers.
+func (s *syncer) fetchChunkFromMirrors(
+	ctx context.Context,
+	snapshot *snapshot,
+	chunks *chunkQueue,
+	mirrors []*chunkMirror,
+	index uint32,
+) bool {
+	for i := range mirrors {
+		mirror := mirrors[(int(index)+i)%len(mirrors)]
+		bz, err := mirror.fetchChunk(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false
+			}
+			s.logger.Info("Failed to fetch snapshot chunk from mirror", "mirror", mirror.url,
+				"chunk", index, "err", err)
+			continue
+		}
+
+		_, err = chunks.Add(&chunk{
+			Height: snapshot.Height,
+			Format: snapshot.Format,
+			Index:  index,
+			Chunk:  bz,
+		})
+		if err != nil {
+			s.logger.Error("Failed to add chunk from mirror", "chunk", index, "err", err)
+			return false
+		}
+		return true
 	}
+	return false
 }
 
-// requestChunk requests a chunk from a peer.
+// requestChunk requests a chunk from a peer, preferring the peers not tried for the chunk yet,
+// and returns the peer.
 //
 // returns nil if there are no peers for the given snapshot or the
 // request is successfully made and an error if the request cannot be
 // completed
-func (s *syncer) requestChunk(ctx context.Context, snapshot *snapshot, chunk uint32) error {
-	peer := s.snapshots.GetPeer(snapshot)
+func (s *syncer) requestChunk(
+	ctx context.Context,
+	snapshot *snapshot,
+	chunk uint32,
+	tried map[types.NodeID]bool,
+) (types.NodeID, error) {
+	peer := s.snapshots.GetPeerExcluding(snapshot, tried)
 	if peer == "" {
 		s.logger.Error("No valid peers found for snapshot", "height", snapshot.Height,
 			"format", snapshot.Format, "hash", snapshot.Hash)
-		return nil
+		return "", nil
 	}
 
 	s.logger.Debug(
@@ -542,9 +662,9 @@ func (s *syncer) requestChunk(ctx context.Context, snapshot *snapshot, chunk uin
 	}
 
 	if err := s.chunkCh.Send(ctx, msg); err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	return peer, nil
 }
 
 // verifyApp verifies the sync, checking the app hash and last block height. It returns the
@@ -0,0 +1,226 @@
+// Package telemetry implements an opt-in reporter that periodically pushes a
+// compact status report of the node to remote collectors, so that operators
+// of many nodes can monitor them without scraping each one.
+//
+// Reports are signed with the node key: a collector verifies a report with
+// SignedReport.Verify, which also checks that the report was produced by the
+// node it claims to describe.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/internal/jsontypes"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+	"github.com/tendermint/tendermint/types"
+)
+
+// pushTimeout bounds the time spent pushing a report to a collector.
+const pushTimeout = 10 * time.Second
+
+// Report is the status of a node at a point in time.
+type Report struct {
+	NodeID  types.NodeID `json:"node_id"`
+	Moniker string       `json:"moniker"`
+	Network string       `json:"network"`
+	Version string       `json:"version"`
+	Time    time.Time    `json:"time"`
+
+	Height        int64     `json:"height,string"`
+	BlockTime     time.Time `json:"block_time"`
+	MaxPeerHeight int64     `json:"max_peer_height,string"`
+	CatchingUp    bool      `json:"catching_up"`
+	Peers         int       `json:"peers,string"`
+
+	Resources Resources `json:"resources"`
+}
+
+// Resources is the resource usage of a node.
+type Resources struct {
+	Goroutines int    `json:"goroutines,string"`
+	HeapBytes  uint64 `json:"heap_bytes,string"`
+	SysBytes   uint64 `json:"sys_bytes,string"`
+	NumCPU     int    `json:"num_cpu,string"`
+	Uptime     int64  `json:"uptime_seconds,string"`
+}
+
+// SignedReport is a report signed with the key of the node it describes. The
+// signature covers the exact bytes of Report.
+type SignedReport struct {
+	Report    json.RawMessage `json:"report"`
+	PubKey    json.RawMessage `json:"pub_key"`
+	Signature []byte          `json:"signature"`
+}
+
+// Verify checks the signature of the report, and that the report describes
+// the node owning the signing key. It returns the decoded report.
+func (sr *SignedReport) Verify() (*Report, error) {
+	var pubKey crypto.PubKey
+	if err := jsontypes.Unmarshal(sr.PubKey, &pubKey); err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if !pubKey.VerifySignature(sr.Report, sr.Signature) {
+		return nil, errors.New("invalid signature")
+	}
+
+	var report Report
+	if err := json.Unmarshal(sr.Report, &report); err != nil {
+		return nil, fmt.Errorf("invalid report: %w", err)
+	}
+	if nodeID := types.NodeIDFromPubKey(pubKey); report.NodeID != nodeID {
+		return nil, fmt.Errorf("report of node %s signed by node %s", report.NodeID, nodeID)
+	}
+	return &report, nil
+}
+
+// Source returns the current status of the node. The reporter fills in the
+// time and resource usage.
+type Source func(ctx context.Context) (*Report, error)
+
+// Reporter periodically pushes a signed report of the status of the node to
+// the configured collectors.
+type Reporter struct {
+	service.BaseService
+	logger log.Logger
+
+	collectors []string
+	interval   time.Duration
+	privKey    crypto.PrivKey
+	source     Source
+	client     *http.Client
+	started    time.Time
+}
+
+// NewReporter returns a reporter pushing the reports of source, signed with
+// privKey, to the collectors of cfg.
+func NewReporter(
+	logger log.Logger,
+	cfg *config.InstrumentationConfig,
+	privKey crypto.PrivKey,
+	source Source,
+) *Reporter {
+	r := &Reporter{
+		logger:     logger,
+		collectors: cfg.TelemetryCollectors,
+		interval:   cfg.TelemetryInterval,
+		privKey:    privKey,
+		source:     source,
+		client:     &http.Client{Timeout: pushTimeout},
+	}
+	r.BaseService = *service.NewBaseService(logger, "Telemetry", r)
+	return r
+}
+
+// OnStart starts pushing reports.
+func (r *Reporter) OnStart(ctx context.Context) error {
+	r.started = time.Now()
+	go r.run(ctx)
+	return nil
+}
+
+// OnStop stops the reporter.
+func (r *Reporter) OnStop() {}
+
+func (r *Reporter) run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.report(ctx); err != nil {
+				r.logger.Error("failed to report telemetry", "err", err)
+			}
+		}
+	}
+}
+
+// report pushes the current report to all collectors concurrently.
+func (r *Reporter) report(ctx context.Context) error {
+	body, err := r.signedReport(ctx)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, collector := range r.collectors {
+		wg.Add(1)
+		go func(collector string) {
+			defer wg.Done()
+			if err := r.push(ctx, collector, body); err != nil {
+				r.logger.Error("failed to push telemetry", "collector", collector, "err", err)
+			}
+		}(collector)
+	}
+	wg.Wait()
+	return nil
+}
+
+// signedReport returns the JSON encoding of the signed current report.
+func (r *Reporter) signedReport(ctx context.Context) ([]byte, error) {
+	report, err := r.source(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	report.Time = time.Now().UTC()
+	report.Resources = Resources{
+		Goroutines: runtime.NumGoroutine(),
+		HeapBytes:  mem.HeapAlloc,
+		SysBytes:   mem.Sys,
+		NumCPU:     runtime.NumCPU(),
+		Uptime:     int64(time.Since(r.started).Seconds()),
+	}
+
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := r.privKey.Sign(reportBytes)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := jsontypes.Marshal(r.privKey.PubKey())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(SignedReport{
+		Report:    reportBytes,
+		PubKey:    pubKey,
+		Signature: sig,
+	})
+}
+
+func (r *Reporter) push(ctx context.Context, collector string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, collector, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("collector responded with status %s", resp.Status)
+	}
+	return nil
+}
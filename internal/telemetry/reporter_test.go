@@ -0,0 +1,78 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestReporter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reports := make(chan SignedReport, 10)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var sr SignedReport
+		if err := json.NewDecoder(req.Body).Decode(&sr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		reports <- sr
+	}))
+	defer collector.Close()
+
+	privKey := ed25519.GenPrivKey()
+	nodeID := types.NodeIDFromPubKey(privKey.PubKey())
+
+	cfg := config.TestInstrumentationConfig()
+	cfg.TelemetryCollectors = []string{collector.URL}
+	cfg.TelemetryInterval = 10 * time.Millisecond
+
+	reporter := NewReporter(log.NewNopLogger(), cfg, privKey, func(context.Context) (*Report, error) {
+		return &Report{NodeID: nodeID, Network: "test-chain", Height: 42, Peers: 3}, nil
+	})
+	require.NoError(t, reporter.Start(ctx))
+
+	var sr SignedReport
+	select {
+	case sr = <-reports:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no report received")
+	}
+
+	report, err := sr.Verify()
+	require.NoError(t, err)
+	require.Equal(t, nodeID, report.NodeID)
+	require.Equal(t, "test-chain", report.Network)
+	require.EqualValues(t, 42, report.Height)
+	require.Equal(t, 3, report.Peers)
+	require.Positive(t, report.Resources.Goroutines)
+	require.False(t, report.Time.IsZero())
+
+	// A tampered report fails verification.
+	tampered := sr
+	tampered.Report = []byte(`{"node_id":"` + string(nodeID) + `","height":"43"}`)
+	_, err = tampered.Verify()
+	require.Error(t, err)
+
+	// So does a report about another node.
+	other := ed25519.GenPrivKey()
+	otherReporter := NewReporter(log.NewNopLogger(), cfg, other, func(context.Context) (*Report, error) {
+		return &Report{NodeID: nodeID}, nil
+	})
+	body, err := otherReporter.signedReport(ctx)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(body, &sr))
+	_, err = sr.Verify()
+	require.Error(t, err)
+}
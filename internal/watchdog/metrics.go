@@ -0,0 +1,76 @@
+package watchdog
+
+import (
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	prometheus "github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsSubsystem is a the subsystem label for the watchdog package.
+const MetricsSubsystem = "resources"
+
+// Metrics contains metrics exposed by this package.
+type Metrics struct {
+	// Usage of the resources: the number of open files and goroutines, and
+	// the resident memory and free disk space in bytes.
+	Usage metrics.Gauge
+
+	// Limits of the resources.
+	Limit metrics.Gauge
+
+	// Number of alerts on the resource usage, by resource and level.
+	Alerts metrics.Counter
+
+	// Whether the node sheds load.
+	Shedding metrics.Gauge
+}
+
+// PrometheusMetrics returns Metrics build using Prometheus client library.
+// Optionally, labels can be provided along with their values ("foo",
+// "fooValue").
+func PrometheusMetrics(namespace string, labelsAndValues ...string) *Metrics {
+	labels := []string{}
+	for i := 0; i < len(labelsAndValues); i += 2 {
+		labels = append(labels, labelsAndValues[i])
+	}
+	resourceLabels := append(labels, "resource")
+	alertLabels := append(resourceLabels[:len(resourceLabels):len(resourceLabels)], "level")
+	return &Metrics{
+		Usage: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "usage",
+			Help:      "Usage of the resources: open files, goroutines, resident memory and free disk space in bytes.",
+		}, resourceLabels).With(labelsAndValues...),
+		Limit: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "limit",
+			Help:      "Limits of the resources.",
+		}, resourceLabels).With(labelsAndValues...),
+		Alerts: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "alerts",
+			Help:      "Number of alerts on the resource usage, by resource and level.",
+		}, alertLabels).With(labelsAndValues...),
+		Shedding: prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "shedding",
+			Help:      "Whether the node sheds load, rejecting the RPC requests and inbound peers.",
+		}, labels).With(labelsAndValues...),
+	}
+}
+
+// NopMetrics returns no-op Metrics.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		Usage:    discard.NewGauge(),
+		Limit:    discard.NewGauge(),
+		Alerts:   discard.NewCounter(),
+		Shedding: discard.NewGauge(),
+	}
+}
@@ -0,0 +1,23 @@
+package watchdog
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// residentMemory returns the resident memory of the process, in bytes, or the
+// memory obtained from the OS by the Go runtime where the resident memory is
+// not available.
+func residentMemory() (int64, error) {
+	if bz, err := os.ReadFile("/proc/self/statm"); err == nil {
+		var size, resident int64
+		if _, err := fmt.Sscan(string(bz), &size, &resident); err != nil {
+			return 0, fmt.Errorf("invalid /proc/self/statm: %w", err)
+		}
+		return resident * int64(os.Getpagesize()), nil
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	return int64(mem.Sys), nil
+}
@@ -0,0 +1,14 @@
+//go:build !darwin && !linux
+// +build !darwin,!linux
+
+package watchdog
+
+// openFiles returns errUnsupported: the open files of the process are not
+// supported.
+func openFiles() (int64, error) { return 0, errUnsupported }
+
+// openFilesLimit returns 0: the limit of the open files is not supported.
+func openFilesLimit() int64 { return 0 }
+
+// diskFree returns errUnsupported: the free disk space is not supported.
+func diskFree(dir string) (int64, error) { return 0, errUnsupported }
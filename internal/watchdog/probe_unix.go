@@ -0,0 +1,48 @@
+//go:build darwin || linux
+// +build darwin linux
+
+package watchdog
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// openFiles returns the number of file descriptors open by the process.
+func openFiles() (int64, error) {
+	dir := "/dev/fd"
+	if runtime.GOOS == "linux" {
+		dir = "/proc/self/fd"
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	// The directory being read is open too.
+	return int64(len(entries)) - 1, nil
+}
+
+// openFilesLimit returns the soft limit of the number of file descriptors
+// open by the process, or 0 if unlimited.
+func openFilesLimit() int64 {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0
+	}
+	if rlimit.Cur > math.MaxInt64 {
+		return 0
+	}
+	return int64(rlimit.Cur)
+}
+
+// diskFree returns the space of the disk of dir available to the process, in
+// bytes.
+func diskFree(dir string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return int64(uint64(stat.Bavail) * uint64(stat.Bsize)), nil
+}
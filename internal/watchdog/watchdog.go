@@ -0,0 +1,232 @@
+// Package watchdog watches the resource usage of the node against configured
+// limits: its open file descriptors, goroutines and resident memory, and the
+// free space of the disk of its data.
+//
+// As a limit is approached, the watchdog logs a structured alert, and sheds
+// load: the node stops accepting RPC requests and inbound peers until the
+// usage goes down, rather than being killed by the OS, or failing to write
+// its data, once the limit is hit. The load is shed from the shed threshold
+// of a limit until the usage of every resource is back under the alert
+// threshold, so that the node does not flap around the limit.
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/libs/service"
+)
+
+// errUnsupported is returned by the probes of the resources whose usage is
+// not supported on the platform.
+var errUnsupported = errors.New("not supported on this platform")
+
+// Resource is a resource of the node.
+type Resource string
+
+// The resources watched.
+const (
+	OpenFiles  Resource = "open_files"
+	Goroutines Resource = "goroutines"
+	Memory     Resource = "memory"
+	DiskFree   Resource = "disk_free"
+)
+
+// Level is the alert level of the usage of a resource.
+type Level int
+
+// The alert levels.
+const (
+	LevelNormal Level = iota
+	LevelAlert
+	LevelShed
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelAlert:
+		return "alert"
+	case LevelShed:
+		return "shed"
+	default:
+		return "normal"
+	}
+}
+
+// Usage is the usage of a resource against its limit.
+type Usage struct {
+	Resource Resource
+	Value    int64
+	Limit    int64
+}
+
+// Pressure returns how close the usage is to the limit, 1 meaning at the
+// limit. The limit of the free disk space is a minimum: the pressure is the
+// limit over the free space.
+func (u Usage) Pressure() float64 {
+	if u.Resource == DiskFree {
+		if u.Value <= 0 {
+			return math.Inf(1)
+		}
+		return float64(u.Limit) / float64(u.Value)
+	}
+	if u.Limit <= 0 {
+		return 0
+	}
+	return float64(u.Value) / float64(u.Limit)
+}
+
+// Watchdog periodically checks the resource usage of the node against the
+// limits of the config, and reports whether the node must shed load.
+type Watchdog struct {
+	service.BaseService
+	logger  log.Logger
+	metrics *Metrics
+
+	interval       time.Duration
+	alertThreshold float64
+	shedThreshold  float64
+
+	// probe returns the current usage of the resources with a limit.
+	probe func() []Usage
+
+	shedding int32 // atomic
+
+	mtx    sync.Mutex
+	levels map[Resource]Level
+}
+
+// New returns a watchdog of the resources of the node limited by cfg, the
+// free disk space being that of the disk of dataDir.
+func New(logger log.Logger, cfg *config.ResourcesConfig, dataDir string, metrics *Metrics) *Watchdog {
+	w := &Watchdog{
+		logger:         logger,
+		metrics:        metrics,
+		interval:       cfg.CheckInterval,
+		alertThreshold: cfg.AlertThreshold,
+		shedThreshold:  cfg.ShedThreshold,
+		levels:         make(map[Resource]Level),
+	}
+	w.probe = func() []Usage { return w.probeLimits(cfg, dataDir) }
+	w.BaseService = *service.NewBaseService(logger, "Watchdog", w)
+	return w
+}
+
+// OnStart starts checking the resource usage.
+func (w *Watchdog) OnStart(ctx context.Context) error {
+	w.check()
+	go w.run(ctx)
+	return nil
+}
+
+// OnStop stops the watchdog.
+func (w *Watchdog) OnStop() {}
+
+// Shedding reports whether the node must shed load, i.e. reject the RPC
+// requests and inbound peers. It is safe for concurrent use.
+func (w *Watchdog) Shedding() bool {
+	return atomic.LoadInt32(&w.shedding) == 1
+}
+
+func (w *Watchdog) run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check checks the current resource usage, alerting on the changes of level,
+// and updates whether the node sheds load.
+func (w *Watchdog) check() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	maxLevel := LevelNormal
+	for _, u := range w.probe() {
+		pressure := u.Pressure()
+		w.metrics.Usage.With("resource", string(u.Resource)).Set(float64(u.Value))
+		w.metrics.Limit.With("resource", string(u.Resource)).Set(float64(u.Limit))
+
+		level := LevelNormal
+		switch {
+		case pressure >= w.shedThreshold:
+			level = LevelShed
+		case pressure >= w.alertThreshold:
+			level = LevelAlert
+		}
+		if level > maxLevel {
+			maxLevel = level
+		}
+
+		if prev := w.levels[u.Resource]; level != prev {
+			w.levels[u.Resource] = level
+			keyvals := []interface{}{
+				"resource", u.Resource, "value", u.Value, "limit", u.Limit,
+				"pressure", pressure, "level", level,
+			}
+			if level > prev {
+				w.metrics.Alerts.With("resource", string(u.Resource), "level", level.String()).Add(1)
+				w.logger.Error("resource usage approaching its limit", keyvals...)
+			} else {
+				w.logger.Info("resource usage decreased", keyvals...)
+			}
+		}
+	}
+
+	shedding := w.Shedding()
+	switch {
+	case !shedding && maxLevel == LevelShed:
+		atomic.StoreInt32(&w.shedding, 1)
+		w.metrics.Shedding.Set(1)
+		w.logger.Error("shedding load: rejecting the RPC requests and inbound peers")
+	case shedding && maxLevel == LevelNormal:
+		atomic.StoreInt32(&w.shedding, 0)
+		w.metrics.Shedding.Set(0)
+		w.logger.Info("stopped shedding load")
+	}
+}
+
+// probeLimits returns the current usage of the resources limited by cfg. The
+// resources whose usage cannot be probed are left out.
+func (w *Watchdog) probeLimits(cfg *config.ResourcesConfig, dataDir string) []Usage {
+	var usages []Usage
+	add := func(resource Resource, limit int64, value func() (int64, error)) {
+		if limit <= 0 {
+			return
+		}
+		v, err := value()
+		if err != nil {
+			w.logger.Debug("failed to probe the resource usage", "resource", resource, "err", err)
+			return
+		}
+		usages = append(usages, Usage{Resource: resource, Value: v, Limit: limit})
+	}
+
+	fileLimit := cfg.MaxOpenFiles
+	if fileLimit == 0 {
+		fileLimit = openFilesLimit()
+	}
+	add(OpenFiles, fileLimit, openFiles)
+	add(Goroutines, cfg.MaxGoroutines, func() (int64, error) {
+		return int64(runtime.NumGoroutine()), nil
+	})
+	add(Memory, cfg.MaxMemoryBytes, residentMemory)
+	add(DiskFree, cfg.MinFreeDiskBytes, func() (int64, error) {
+		return diskFree(dataDir)
+	})
+	return usages
+}
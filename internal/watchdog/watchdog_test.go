@@ -0,0 +1,64 @@
+package watchdog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+func TestUsagePressure(t *testing.T) {
+	assert.Equal(t, 0.5, Usage{Resource: Goroutines, Value: 50, Limit: 100}.Pressure())
+	assert.Equal(t, 0.0, Usage{Resource: Goroutines, Value: 50}.Pressure())
+	// The free disk space is a minimum.
+	assert.Equal(t, 0.5, Usage{Resource: DiskFree, Value: 200, Limit: 100}.Pressure())
+	assert.True(t, math.IsInf(Usage{Resource: DiskFree, Value: 0, Limit: 100}.Pressure(), 1))
+}
+
+func TestWatchdogShedding(t *testing.T) {
+	w := New(log.NewNopLogger(), config.TestResourcesConfig(), t.TempDir(), NopMetrics())
+	var goroutines int64
+	w.probe = func() []Usage {
+		return []Usage{
+			{Resource: Goroutines, Value: goroutines, Limit: 100},
+			{Resource: DiskFree, Value: 1000, Limit: 100},
+		}
+	}
+
+	for _, tc := range []struct {
+		goroutines int64
+		level      Level
+		shedding   bool
+	}{
+		{50, LevelNormal, false},
+		{85, LevelAlert, false},
+		{95, LevelShed, true},
+		// The load is shed until the usage is back under the alert threshold.
+		{85, LevelAlert, true},
+		{70, LevelNormal, false},
+		{100, LevelShed, true},
+	} {
+		goroutines = tc.goroutines
+		w.check()
+		assert.Equal(t, tc.level, w.levels[Goroutines], "goroutines %d", tc.goroutines)
+		assert.Equal(t, LevelNormal, w.levels[DiskFree])
+		require.Equal(t, tc.shedding, w.Shedding(), "goroutines %d", tc.goroutines)
+	}
+}
+
+func TestWatchdogProbeLimits(t *testing.T) {
+	cfg := config.TestResourcesConfig()
+	cfg.MaxOpenFiles = -1
+	cfg.MaxGoroutines = 1 << 20
+	w := New(log.NewNopLogger(), cfg, t.TempDir(), NopMetrics())
+
+	// Only the limited resources are probed.
+	usages := w.probe()
+	require.Len(t, usages, 1)
+	assert.Equal(t, Goroutines, usages[0].Resource)
+	assert.Positive(t, usages[0].Value)
+}
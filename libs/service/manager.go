@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+var (
+	// ErrDuplicateService is returned when a service is registered
+	// with a manager under a name that is already in use.
+	ErrDuplicateService = errors.New("duplicate service")
+	// ErrUnknownDependency is returned when a service declares a
+	// dependency that has not been registered with the manager.
+	ErrUnknownDependency = errors.New("unknown dependency")
+	// ErrDependencyCycle is returned when the declared dependencies
+	// between services form a cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+)
+
+// RestartPolicy describes how a Manager reacts when a service stops
+// while the manager itself is still running.
+type RestartPolicy struct {
+	// MaxRestarts is the number of times the service will be
+	// recreated and restarted. Zero disables restarts.
+	MaxRestarts int
+	// Backoff is the delay before each restart attempt.
+	Backoff time.Duration
+}
+
+// Factory constructs a fresh instance of a service. Services based on
+// BaseService cannot be restarted once stopped, so restarting a
+// service requires building a new one.
+type Factory func() (Service, error)
+
+// StartupError reports the failure of one or more services during
+// Manager.Start.
+type StartupError struct {
+	// Failed maps the name of each service that failed to start to
+	// the error it returned.
+	Failed map[string]error
+	// Skipped lists the services that were not started because the
+	// startup was aborted.
+	Skipped []string
+}
+
+func (e *StartupError) Error() string {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %v", name, e.Failed[name]))
+	}
+
+	return fmt.Sprintf("failed to start services [%s] (skipped %d)",
+		strings.Join(parts, "; "), len(e.Skipped))
+}
+
+// Unwrap returns the error of the first failed service, in name order.
+func (e *StartupError) Unwrap() error {
+	names := make([]string, 0, len(e.Failed))
+	for name := range e.Failed {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return e.Failed[names[0]]
+}
+
+// ManagerOption configures a single service registered with a Manager.
+type ManagerOption func(*managedService)
+
+// WithDependencies declares that a service may only be started after
+// all of the named services have started successfully.
+func WithDependencies(names ...string) ManagerOption {
+	return func(ms *managedService) { ms.deps = append(ms.deps, names...) }
+}
+
+// WithRestartPolicy causes the manager to rebuild the service with the
+// given factory and start it again if it stops unexpectedly.
+func WithRestartPolicy(policy RestartPolicy, factory Factory) ManagerOption {
+	return func(ms *managedService) {
+		ms.policy = policy
+		ms.factory = factory
+	}
+}
+
+type managedService struct {
+	name    string
+	deps    []string
+	policy  RestartPolicy
+	factory Factory
+
+	mtx      sync.Mutex
+	svc      Service
+	restarts int
+}
+
+func (ms *managedService) current() Service {
+	ms.mtx.Lock()
+	defer ms.mtx.Unlock()
+	return ms.svc
+}
+
+/*
+Manager starts a collection of services in dependency order.
+
+Services are registered with a name and an optional list of services
+they depend on. On Start, the manager groups services into levels such
+that every service only depends on services in earlier levels, and
+starts all services within a level concurrently. If any service fails
+to start, the remaining levels are skipped, every service that was
+already started is stopped by canceling the manager's context, and a
+*StartupError is returned.
+
+Once started, the lifetime of all managed services is bound to the
+context passed to Start; there is no need for callers to keep quit
+channels or to stop services individually.
+
+Typical usage:
+
+	mgr := service.NewManager(logger)
+	_ = mgr.Add("eventbus", eventBus)
+	_ = mgr.Add("mempool", mpReactor, service.WithDependencies("eventbus"))
+	if err := mgr.Start(ctx); err != nil {
+		return err
+	}
+	mgr.Wait()
+*/
+type Manager struct {
+	logger log.Logger
+
+	mtx      sync.Mutex
+	services map[string]*managedService
+	order    []string
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewManager constructs an empty Manager.
+func NewManager(logger log.Logger) *Manager {
+	return &Manager{
+		logger:   logger,
+		services: make(map[string]*managedService),
+	}
+}
+
+// Add registers a service with the manager. Services must be added
+// before the manager is started.
+func (m *Manager) Add(name string, svc Service, opts ...ManagerOption) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if _, ok := m.services[name]; ok {
+		return fmt.Errorf("%w: %q", ErrDuplicateService, name)
+	}
+
+	ms := &managedService{name: name, svc: svc}
+	for _, opt := range opts {
+		opt(ms)
+	}
+
+	m.services[name] = ms
+	m.order = append(m.order, name)
+	return nil
+}
+
+// Service returns the currently running instance of the named service,
+// which may differ from the registered instance after a restart.
+func (m *Manager) Service(name string) (Service, bool) {
+	m.mtx.Lock()
+	ms, ok := m.services[name]
+	m.mtx.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return ms.current(), true
+}
+
+// levels sorts the registered services into startup levels. Every
+// service in a level only depends on services in previous levels.
+func (m *Manager) levels() ([][]string, error) {
+	indegree := make(map[string]int, len(m.services))
+	dependents := make(map[string][]string, len(m.services))
+
+	for _, name := range m.order {
+		ms := m.services[name]
+		indegree[name] += 0
+		for _, dep := range ms.deps {
+			if _, ok := m.services[dep]; !ok {
+				return nil, fmt.Errorf("%w: %q required by %q", ErrUnknownDependency, dep, name)
+			}
+			indegree[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	var (
+		out  [][]string
+		seen int
+	)
+
+	current := make([]string, 0, len(m.order))
+	for _, name := range m.order {
+		if indegree[name] == 0 {
+			current = append(current, name)
+		}
+	}
+
+	for len(current) > 0 {
+		out = append(out, current)
+		seen += len(current)
+
+		var next []string
+		for _, name := range current {
+			for _, dep := range dependents[name] {
+				indegree[dep]--
+				if indegree[dep] == 0 {
+					next = append(next, dep)
+				}
+			}
+		}
+		current = next
+	}
+
+	if seen != len(m.order) {
+		var cycle []string
+		for _, name := range m.order {
+			if indegree[name] > 0 {
+				cycle = append(cycle, name)
+			}
+		}
+		return nil, fmt.Errorf("%w between %s", ErrDependencyCycle, strings.Join(cycle, ", "))
+	}
+
+	return out, nil
+}
+
+// Start starts all registered services in dependency order. Services
+// that do not depend on one another are started concurrently.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.cancel != nil {
+		return ErrAlreadyStarted
+	}
+
+	levels, err := m.levels()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancel = cancel
+
+	for idx, level := range levels {
+		failed := m.startLevel(ctx, level)
+		if len(failed) == 0 {
+			continue
+		}
+
+		serr := &StartupError{Failed: failed}
+		for _, rest := range levels[idx+1:] {
+			serr.Skipped = append(serr.Skipped, rest...)
+		}
+
+		m.logger.Error("aborting service startup", "err", serr)
+		cancel()
+		return serr
+	}
+
+	for _, name := range m.order {
+		ms := m.services[name]
+		if ms.policy.MaxRestarts > 0 && ms.factory != nil {
+			m.wg.Add(1)
+			go m.supervise(ctx, ms)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) startLevel(ctx context.Context, level []string) map[string]error {
+	var (
+		wg     sync.WaitGroup
+		mtx    sync.Mutex
+		failed = make(map[string]error)
+	)
+
+	for _, name := range level {
+		ms := m.services[name]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			m.logger.Debug("starting managed service", "service", ms.name)
+			if err := ms.current().Start(ctx); err != nil && !errors.Is(err, ErrAlreadyStarted) {
+				mtx.Lock()
+				failed[ms.name] = err
+				mtx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return failed
+}
+
+// supervise restarts a service according to its restart policy when it
+// stops while the manager's context is still live.
+func (m *Manager) supervise(ctx context.Context, ms *managedService) {
+	defer m.wg.Done()
+
+	for {
+		svc := ms.current()
+
+		stopped := make(chan struct{})
+		go func() { svc.Wait(); close(stopped) }()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-stopped:
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		ms.mtx.Lock()
+		if ms.restarts >= ms.policy.MaxRestarts {
+			ms.mtx.Unlock()
+			m.logger.Error("managed service stopped; restart budget exhausted",
+				"service", ms.name, "restarts", ms.restarts)
+			return
+		}
+		ms.restarts++
+		attempt := ms.restarts
+		ms.mtx.Unlock()
+
+		m.logger.Info("restarting managed service", "service", ms.name, "attempt", attempt)
+
+		timer := time.NewTimer(ms.policy.Backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		next, err := ms.factory()
+		if err == nil {
+			err = next.Start(ctx)
+		}
+		if err != nil {
+			m.logger.Error("failed to restart managed service", "service", ms.name, "err", err)
+			return
+		}
+
+		ms.mtx.Lock()
+		ms.svc = next
+		ms.mtx.Unlock()
+	}
+}
+
+// Restarts reports how many times the named service has been restarted.
+func (m *Manager) Restarts(name string) int {
+	m.mtx.Lock()
+	ms, ok := m.services[name]
+	m.mtx.Unlock()
+	if !ok {
+		return 0
+	}
+
+	ms.mtx.Lock()
+	defer ms.mtx.Unlock()
+	return ms.restarts
+}
+
+// Stop stops all managed services by canceling the context they were
+// started with.
+func (m *Manager) Stop() {
+	m.mtx.Lock()
+	cancel := m.cancel
+	m.mtx.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// Wait blocks until all managed services have stopped. It returns at once if
+// the manager was not started.
+func (m *Manager) Wait() {
+	// The supervisors are added while Start holds m.mtx: once the manager is
+	// seen started, all of them are accounted for in m.wg.
+	m.mtx.Lock()
+	started := m.cancel != nil
+	services := make([]*managedService, 0, len(m.order))
+	for _, name := range m.order {
+		services = append(services, m.services[name])
+	}
+	m.mtx.Unlock()
+	if !started {
+		return
+	}
+
+	// Once the supervisors exit, the services are no longer replaced.
+	m.wg.Wait()
+	for _, ms := range services {
+		if svc := ms.current(); svc.IsRunning() {
+			svc.Wait()
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+type recordingService struct {
+	BaseService
+
+	name string
+	err  error
+
+	mtx   *sync.Mutex
+	order *[]string
+}
+
+func newRecordingService(logger log.Logger, name string, mtx *sync.Mutex, order *[]string) *recordingService {
+	rs := &recordingService{name: name, mtx: mtx, order: order}
+	rs.BaseService = *NewBaseService(logger, name, rs)
+	return rs
+}
+
+func (rs *recordingService) OnStart(context.Context) error {
+	if rs.err != nil {
+		return rs.err
+	}
+	rs.mtx.Lock()
+	*rs.order = append(*rs.order, rs.name)
+	rs.mtx.Unlock()
+	return nil
+}
+
+func (rs *recordingService) OnStop() {}
+
+func TestManagerDependencyOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewTestingLogger(t)
+
+	var (
+		mtx   sync.Mutex
+		order []string
+	)
+
+	mgr := NewManager(logger)
+	require.NoError(t, mgr.Add("c", newRecordingService(logger, "c", &mtx, &order), WithDependencies("b")))
+	require.NoError(t, mgr.Add("b", newRecordingService(logger, "b", &mtx, &order), WithDependencies("a")))
+	require.NoError(t, mgr.Add("a", newRecordingService(logger, "a", &mtx, &order)))
+
+	require.NoError(t, mgr.Start(ctx))
+	require.Equal(t, []string{"a", "b", "c"}, order)
+
+	mgr.Stop()
+
+	done := make(chan struct{})
+	go func() { mgr.Wait(); close(done) }()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected managed services to stop")
+	}
+}
+
+func TestManagerInvalidGraph(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewTestingLogger(t)
+
+	var (
+		mtx   sync.Mutex
+		order []string
+	)
+
+	mgr := NewManager(logger)
+	require.NoError(t, mgr.Add("a", newRecordingService(logger, "a", &mtx, &order), WithDependencies("b")))
+	require.NoError(t, mgr.Add("b", newRecordingService(logger, "b", &mtx, &order), WithDependencies("a")))
+	require.ErrorIs(t, mgr.Add("a", newRecordingService(logger, "a", &mtx, &order)), ErrDuplicateService)
+	require.ErrorIs(t, mgr.Start(ctx), ErrDependencyCycle)
+
+	mgr = NewManager(logger)
+	require.NoError(t, mgr.Add("a", newRecordingService(logger, "a", &mtx, &order), WithDependencies("missing")))
+	require.ErrorIs(t, mgr.Start(ctx), ErrUnknownDependency)
+}
+
+func TestManagerStartupFailure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewTestingLogger(t)
+
+	var (
+		mtx   sync.Mutex
+		order []string
+	)
+
+	errBoom := errors.New("boom")
+
+	a := newRecordingService(logger, "a", &mtx, &order)
+	b := newRecordingService(logger, "b", &mtx, &order)
+	b.err = errBoom
+
+	mgr := NewManager(logger)
+	require.NoError(t, mgr.Add("a", a))
+	require.NoError(t, mgr.Add("b", b))
+	require.NoError(t, mgr.Add("c", newRecordingService(logger, "c", &mtx, &order), WithDependencies("a", "b")))
+
+	err := mgr.Start(ctx)
+	require.ErrorIs(t, err, errBoom)
+
+	var serr *StartupError
+	require.True(t, errors.As(err, &serr))
+	require.Contains(t, serr.Failed, "b")
+	require.Equal(t, []string{"c"}, serr.Skipped)
+
+	// a was started before the failure and is stopped by the manager.
+	a.Wait()
+	require.False(t, a.IsRunning())
+}
+
+func TestManagerRestartPolicy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewTestingLogger(t)
+
+	var (
+		mtx   sync.Mutex
+		order []string
+	)
+
+	first := newRecordingService(logger, "a", &mtx, &order)
+	factory := func() (Service, error) {
+		return newRecordingService(logger, "a", &mtx, &order), nil
+	}
+
+	mgr := NewManager(logger)
+	require.NoError(t, mgr.Add("a", first, WithRestartPolicy(RestartPolicy{MaxRestarts: 1}, factory)))
+	require.NoError(t, mgr.Start(ctx))
+
+	require.NoError(t, first.Stop())
+	require.Eventually(t, func() bool {
+		return mgr.Restarts("a") == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		svc, ok := mgr.Service("a")
+		return ok && svc != Service(first) && svc.IsRunning()
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	mgr.Wait()
+}
+
+func TestManagerWaitWhileRestarting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewTestingLogger(t)
+
+	var (
+		mtx   sync.Mutex
+		order []string
+	)
+
+	restarted := make(chan Service, 1)
+	first := newRecordingService(logger, "a", &mtx, &order)
+	factory := func() (Service, error) {
+		svc := newRecordingService(logger, "a", &mtx, &order)
+		restarted <- svc
+		return svc, nil
+	}
+
+	mgr := NewManager(logger)
+	require.NoError(t, mgr.Add("a", first,
+		WithRestartPolicy(RestartPolicy{MaxRestarts: 1, Backoff: 10 * time.Millisecond}, factory)))
+
+	// Waiting for a manager not started returns at once.
+	mgr.Wait()
+
+	require.NoError(t, mgr.Start(ctx))
+	waited := make(chan struct{})
+	go func() {
+		mgr.Wait()
+		close(waited)
+	}()
+
+	require.NoError(t, first.Stop())
+	next := <-restarted
+	require.Eventually(t, func() bool {
+		svc, ok := mgr.Service("a")
+		return ok && svc == next && svc.IsRunning()
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while the restarted service was running")
+	default:
+	}
+
+	cancel()
+	<-waited
+	require.False(t, next.IsRunning())
+}
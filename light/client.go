@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	"github.com/tendermint/tendermint/libs/log"
 	tmmath "github.com/tendermint/tendermint/libs/math"
 	"github.com/tendermint/tendermint/light/provider"
@@ -54,6 +55,8 @@ const (
 	defaultMaxBlockLag = 10 * time.Second
 
 	defaultProviderTimeout = 10 * time.Second
+
+	defaultMaxConcurrentFetches = 1
 )
 
 // Option sets a parameter for the light client.
@@ -119,6 +122,21 @@ func ProviderTimeout(d time.Duration) Option {
 	return func(c *Client) { c.providerTimeout = d }
 }
 
+// MaxConcurrentFetches sets the maximum number of light blocks the light
+// client fetches concurrently from a provider. With n > 1, skipping
+// verification fetches up to n of the next bisection pivots at once, and the
+// light blocks the detector compares with the witnesses are fetched while the
+// primary is verified, instead of after: the providers must then serve
+// concurrent requests. Default: 1.
+func MaxConcurrentFetches(n int) Option {
+	return func(c *Client) {
+		if n < 1 {
+			n = 1
+		}
+		c.maxConcurrentFetches = n
+	}
+}
+
 // Client represents a light client, connected to a single chain, which gets
 // light blocks from a primary provider, verifies them either sequentially or by
 // skipping some and stores them in a trusted store (usually, a local FS).
@@ -133,12 +151,18 @@ type Client struct {
 	maxBlockLag      time.Duration
 	providerTimeout  time.Duration
 
+	// See MaxConcurrentFetches option
+	maxConcurrentFetches int
+
 	// Mutex for locking during changes of the light clients providers
 	providerMutex sync.Mutex
 	// Primary provider of new headers.
 	primary provider.Provider
 	// Providers used to "witness" new headers.
 	witnesses []provider.Provider
+	// Incremented whenever witnesses are removed, which changes their
+	// indexes.
+	witnessesVersion uint64
 
 	// Where trusted light blocks are stored.
 	trustedStore store.Store
@@ -206,6 +230,8 @@ func NewClient(
 		providerTimeout:  defaultProviderTimeout,
 		pruningSize:      defaultPruningSize,
 		logger:           log.NewNopLogger(),
+
+		maxConcurrentFetches: defaultMaxConcurrentFetches,
 	}
 
 	for _, o := range options {
@@ -248,6 +274,8 @@ func NewClientFromTrustedStore(
 		trustedStore:     trustedStore,
 		pruningSize:      defaultPruningSize,
 		logger:           log.NewNopLogger(),
+
+		maxConcurrentFetches: defaultMaxConcurrentFetches,
 	}
 
 	for _, o := range options {
@@ -394,6 +422,124 @@ func (c *Client) Update(ctx context.Context, now time.Time) (*types.LightBlock,
 	return c.latestTrustedBlock, nil
 }
 
+// CrossCheckTip updates the client, as Update does, and compares the latest
+// trusted light block with the latest light blocks of the witnesses. A
+// witness agrees with it if its latest height is within tolerance of the
+// trusted one, and if it has the verified light block at the lowest of both
+// heights. It returns the latest trusted light block and the report of each
+// witness, in the order of Witnesses.
+func (c *Client) CrossCheckTip(
+	ctx context.Context,
+	now time.Time,
+	tolerance int64,
+) (*types.LightBlock, []types.WitnessTip, error) {
+	if tolerance < 0 {
+		return nil, nil, errors.New("negative height tolerance")
+	}
+
+	tip, err := c.Update(ctx, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tip == nil {
+		return nil, nil, errors.New("no trusted light block")
+	}
+
+	c.providerMutex.Lock()
+	witnesses := make([]provider.Provider, len(c.witnesses))
+	copy(witnesses, c.witnesses)
+	c.providerMutex.Unlock()
+
+	// Fetch the latest light block of each witness and, if it is ahead of the
+	// tip within tolerance, its light block at the height of the tip.
+	type witnessBlocks struct {
+		latest, compared *types.LightBlock
+		err              error
+	}
+	blocks := make([]witnessBlocks, len(witnesses))
+	var wg sync.WaitGroup
+	for i, witness := range witnesses {
+		wg.Add(1)
+		go func(wb *witnessBlocks, witness provider.Provider) {
+			defer wg.Done()
+
+			if wb.latest, wb.err = c.getLightBlock(ctx, witness, 0); wb.err != nil {
+				return
+			}
+			wb.compared = wb.latest
+			if h := wb.latest.Height; h > tip.Height && h-tip.Height <= tolerance {
+				wb.compared, wb.err = c.getLightBlock(ctx, witness, tip.Height)
+				if wb.err == nil && wb.compared.Height != tip.Height {
+					wb.err = fmt.Errorf("expected light block at height %d, got %d", tip.Height, wb.compared.Height)
+				}
+			}
+		}(&blocks[i], witness)
+	}
+	wg.Wait()
+
+	tips := make([]types.WitnessTip, len(witnesses))
+	hashes := map[int64][]byte{tip.Height: tip.Hash()}
+	for i, wb := range blocks {
+		wt := types.WitnessTip{WitnessID: witnesses[i].ID()}
+		switch {
+		case wb.err != nil:
+			wt.Status = types.WitnessTipUnavailable
+			wt.Error = wb.err.Error()
+		case wb.latest.Height < tip.Height-tolerance:
+			wt.Status = types.WitnessTipLagging
+			wt.LatestHeight = wb.latest.Height
+		case wb.latest.Height > tip.Height+tolerance:
+			wt.Status = types.WitnessTipAhead
+			wt.LatestHeight = wb.latest.Height
+		default:
+			height := wb.compared.Height
+			trustedHash, ok := hashes[height]
+			if !ok {
+				trustedHash, err = c.hashBelowTip(ctx, tip, height)
+				if err != nil {
+					return nil, nil, fmt.Errorf("can't verify light block at height %d: %w", height, err)
+				}
+				hashes[height] = trustedHash
+			}
+
+			wt.LatestHeight = wb.latest.Height
+			wt.Hash = wb.compared.Hash()
+			if bytes.Equal(wt.Hash, trustedHash) {
+				wt.Status = types.WitnessTipAgrees
+			} else {
+				wt.Status = types.WitnessTipConflicting
+				c.logger.Error("witness has a different header than the verified one",
+					"witness", wt.WitnessID, "height", height,
+					"expected", tmbytes.HexBytes(trustedHash), "got", wt.Hash)
+			}
+		}
+		tips[i] = wt
+	}
+
+	return tip, tips, nil
+}
+
+// hashBelowTip returns the hash of the block at height, below the trusted
+// tip, either trusted or linked to the tip by the headers of the primary.
+func (c *Client) hashBelowTip(ctx context.Context, tip *types.LightBlock, height int64) ([]byte, error) {
+	if l, err := c.TrustedLightBlock(height); err == nil {
+		return l.Hash(), nil
+	}
+
+	verified := tip.Header
+	for verified.Height > height {
+		interim, err := c.lightBlockFromPrimary(ctx, verified.Height-1)
+		if err != nil {
+			return nil, err
+		}
+		if err := VerifyBackwards(interim.Header, verified); err != nil {
+			return nil, err
+		}
+		verified = interim.Header
+	}
+	return verified.Hash(), nil
+}
+
 // VerifyLightBlockAtHeight fetches the light block at the given height
 // and verifies it. It returns the block immediately if it exists in
 // the trustedStore (no verification is needed).
@@ -557,6 +703,9 @@ func (c *Client) verifySequential(
 		trace         = []*types.LightBlock{trustedBlock}
 	)
 
+	prefetch := c.prefetchWitnesses(ctx, newLightBlock.Height)
+	defer prefetch.stop()
+
 	for height := trustedBlock.Height + 1; height <= newLightBlock.Height; height++ {
 		// 1) Fetch interim light block if needed.
 		if height == newLightBlock.Height { // last light block
@@ -623,7 +772,7 @@ func (c *Client) verifySequential(
 	//
 	// CORRECTNESS ASSUMPTION: there's at least 1 correct full node
 	// (primary or one of the witnesses).
-	return c.detectDivergence(ctx, trace, now)
+	return c.detectDivergence(ctx, trace, prefetch, now)
 }
 
 // see VerifyHeader
@@ -694,15 +843,21 @@ func (c *Client) verifySkipping(
 			// previously verified one in the hope that it has a better chance
 			// of having a similar validator set
 			if depth == len(blockCache)-1 {
-				// schedule what the next height we need to fetch is
-				pivotHeight := c.schedule(verifiedBlock.Height, blockCache[depth].Height)
-				subCtx, cancel := context.WithTimeout(ctx, c.providerTimeout)
-				defer cancel()
-				interimBlock, providerErr := c.getLightBlock(subCtx, source, pivotHeight)
-				if providerErr != nil {
-					return nil, ErrVerificationFailed{From: verifiedBlock.Height, To: pivotHeight, Reason: providerErr}
+				// schedule what the next height we need to fetch is, and
+				// the heights after it if the client fetches several at once
+				pivotHeights := c.pivotHeights(verifiedBlock.Height, blockCache[depth].Height, c.maxConcurrentFetches)
+				interimBlocks, providerErrs := c.fetchLightBlocks(ctx, source, pivotHeights)
+				if providerErrs[0] != nil {
+					return nil, ErrVerificationFailed{From: verifiedBlock.Height, To: pivotHeights[0], Reason: providerErrs[0]}
+				}
+				// the blocks following a failed fetch are left out, to be
+				// fetched again when needed
+				for i, interimBlock := range interimBlocks {
+					if providerErrs[i] != nil {
+						break
+					}
+					blockCache = append(blockCache, interimBlock)
 				}
-				blockCache = append(blockCache, interimBlock)
 			}
 			depth++
 
@@ -727,6 +882,9 @@ func (c *Client) verifySkippingAgainstPrimary(
 	newLightBlock *types.LightBlock,
 	now time.Time) error {
 
+	prefetch := c.prefetchWitnesses(ctx, newLightBlock.Height)
+	defer prefetch.stop()
+
 	trace, err := c.verifySkipping(ctx, c.primary, trustedBlock, newLightBlock, now)
 	if err == nil {
 		// Success! Now compare the header with the witnesses to ensure it's not a fork.
@@ -734,7 +892,7 @@ func (c *Client) verifySkippingAgainstPrimary(
 		//
 		// CORRECTNESS ASSUMPTION: there's at least 1 correct full node
 		// (primary or one of the witnesses).
-		if cmpErr := c.detectDivergence(ctx, trace, now); cmpErr != nil {
+		if cmpErr := c.detectDivergence(ctx, trace, prefetch, now); cmpErr != nil {
 			return cmpErr
 		}
 	}
@@ -981,6 +1139,9 @@ func (c *Client) removeWitnesses(indexes []int) error {
 
 	// we need to make sure that we remove witnesses by index in the reverse
 	// order so as to not affect the indexes themselves
+	if len(indexes) > 0 {
+		c.witnessesVersion++
+	}
 	sort.Ints(indexes)
 	for i := len(indexes) - 1; i >= 0; i-- {
 		c.witnesses[indexes[i]] = c.witnesses[len(c.witnesses)-1]
@@ -1101,7 +1262,7 @@ func (c *Client) compareFirstHeaderWithWitnesses(ctx context.Context, h *types.S
 
 	errc := make(chan error, len(c.witnesses))
 	for i, witness := range c.witnesses {
-		go c.compareNewHeaderWithWitness(compareCtx, errc, h, witness, i)
+		go c.compareNewHeaderWithWitness(compareCtx, errc, h, witness, i, nil)
 	}
 
 	witnessesToRemove := make([]int, 0, len(c.witnesses))
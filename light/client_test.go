@@ -36,6 +36,51 @@ func init() {
 	}
 }
 
+// slowProvider delays the light blocks of its provider, recording the
+// requests.
+type slowProvider struct {
+	provider.Provider
+	delay time.Duration
+
+	mtx      sync.Mutex
+	inFlight int
+	max      int
+	heights  map[int64]int
+}
+
+func (p *slowProvider) LightBlock(ctx context.Context, height int64) (*types.LightBlock, error) {
+	p.mtx.Lock()
+	p.inFlight++
+	if p.inFlight > p.max {
+		p.max = p.inFlight
+	}
+	if p.heights == nil {
+		p.heights = make(map[int64]int)
+	}
+	p.heights[height]++
+	p.mtx.Unlock()
+
+	defer func() {
+		p.mtx.Lock()
+		p.inFlight--
+		p.mtx.Unlock()
+	}()
+	time.Sleep(p.delay)
+	return p.Provider.LightBlock(ctx, height)
+}
+
+func (p *slowProvider) maxInFlight() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.max
+}
+
+func (p *slowProvider) calls(height int64) int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.heights[height]
+}
+
 func TestClient(t *testing.T) {
 	var (
 		keys        = genPrivKeys(4)
@@ -432,6 +477,43 @@ func TestClient(t *testing.T) {
 		assert.Equal(t, h, h2)
 		mockNode.AssertExpectations(t)
 	})
+	t.Run("ConcurrentFetches", func(t *testing.T) {
+		// the validator set changes enough for the bisection to go through
+		// several pivots
+		numBlocks := int64(100)
+		mockHeaders, mockVals, _ := genLightBlocksWithKeys(t, chainID, numBlocks, 10, 2, bTime)
+		primary := &slowProvider{Provider: mockNodeFromHeadersAndVals(mockHeaders, mockVals), delay: 10 * time.Millisecond}
+		witness := &slowProvider{Provider: mockNodeFromHeadersAndVals(mockHeaders, mockVals), delay: 10 * time.Millisecond}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		c, err := light.NewClient(
+			ctx,
+			chainID,
+			light.TrustOptions{
+				Period: 4 * time.Hour,
+				Height: 1,
+				Hash:   mockHeaders[1].Hash(),
+			},
+			primary,
+			[]provider.Provider{witness},
+			dbs.New(dbm.NewMemDB()),
+			light.SkippingVerification(light.DefaultTrustLevel),
+			light.MaxConcurrentFetches(4),
+		)
+		require.NoError(t, err)
+
+		lightBlock, err := c.VerifyLightBlockAtHeight(ctx, numBlocks, bTime.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, mockHeaders[numBlocks].Hash(), lightBlock.Hash())
+
+		// the pivots are fetched several at once, and the witness while the
+		// primary is verified
+		assert.Greater(t, primary.maxInFlight(), 1)
+		assert.LessOrEqual(t, primary.maxInFlight(), 4)
+		assert.Equal(t, 1, witness.calls(numBlocks))
+	})
 	t.Run("BisectionBetweenTrustedHeaders", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -690,6 +772,74 @@ func TestClient(t *testing.T) {
 		}
 		mockFullNode.AssertExpectations(t)
 	})
+	t.Run("CrossCheckTip", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		witness := func(id string, latest *types.LightBlock, err error) *provider_mocks.Provider {
+			mockNode := &provider_mocks.Provider{}
+			mockNode.On("ID").Return(id)
+			mockNode.On("LightBlock", mock.Anything, int64(1)).Return(l1, nil)
+			mockNode.On("LightBlock", mock.Anything, int64(0)).Return(latest, err)
+			return mockNode
+		}
+
+		mockFullNode := mockNodeFromHeadersAndVals(headerSet, valSet)
+		mockFullNode.On("LightBlock", mock.Anything, int64(0)).Return(l3, nil)
+		mockFullNode.On("ID").Return("primary")
+
+		// The agreeing witness is also cross-checked when the client updates.
+		agreeing := witness("agreeing", l3, nil)
+		agreeing.On("LightBlock", mock.Anything, int64(3)).Return(l3, nil)
+
+		c, err := light.NewClient(
+			ctx,
+			chainID,
+			trustOptions,
+			mockFullNode,
+			[]provider.Provider{agreeing},
+			dbs.New(dbm.NewMemDB()),
+			light.Logger(log.NewTestingLogger(t)),
+		)
+		require.NoError(t, err)
+
+		tip, witnesses, err := c.CrossCheckTip(ctx, bTime.Add(2*time.Hour), 1)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, tip.Height)
+		require.Len(t, witnesses, 1)
+		assert.Equal(t, types.WitnessTip{
+			WitnessID:    "agreeing",
+			Status:       types.WitnessTipAgrees,
+			LatestHeight: 3,
+			Hash:         h3.Hash(),
+		}, witnesses[0])
+
+		conflicting := keys.GenSignedHeaderLastBlockID(t, chainID, 2, bTime.Add(30*time.Minute), nil, vals, vals,
+			hash("other_app_hash"), hash("cons_hash"), hash("results_hash"), 0, len(keys), types.BlockID{Hash: h1.Hash()})
+		ahead := keys.GenSignedHeader(t, chainID, 5, bTime.Add(90*time.Minute), nil, vals, vals,
+			hash("app_hash"), hash("cons_hash"), hash("results_hash"), 0, len(keys))
+		c.AddProvider(witness("lagging", l1, nil))
+		c.AddProvider(witness("conflicting", &types.LightBlock{SignedHeader: conflicting, ValidatorSet: vals}, nil))
+		c.AddProvider(witness("ahead", &types.LightBlock{SignedHeader: ahead, ValidatorSet: vals}, nil))
+		c.AddProvider(witness("unavailable", nil, provider.ErrNoResponse))
+
+		tip, witnesses, err = c.CrossCheckTip(ctx, bTime.Add(2*time.Hour), 1)
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, tip.Height)
+		require.Len(t, witnesses, 5)
+		assert.Equal(t, types.WitnessTipAgrees, witnesses[0].Status)
+		assert.Equal(t, types.WitnessTipLagging, witnesses[1].Status)
+		assert.EqualValues(t, 1, witnesses[1].LatestHeight)
+		assert.Equal(t, types.WitnessTipConflicting, witnesses[2].Status)
+		assert.EqualValues(t, conflicting.Hash(), witnesses[2].Hash)
+		assert.Equal(t, types.WitnessTipAhead, witnesses[3].Status)
+		assert.EqualValues(t, 5, witnesses[3].LatestHeight)
+		assert.Equal(t, types.WitnessTipUnavailable, witnesses[4].Status)
+		assert.NotEmpty(t, witnesses[4].Error)
+
+		_, _, err = c.CrossCheckTip(ctx, bTime.Add(2*time.Hour), -1)
+		assert.Error(t, err)
+	})
 	t.Run("ReplacesPrimaryWithWitnessIfPrimaryIsUnavailable", func(t *testing.T) {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
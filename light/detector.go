@@ -25,7 +25,14 @@ import (
 //
 // If there are no conflictinge headers, the light client deems the verified target header
 // trusted and saves it to the trusted store.
-func (c *Client) detectDivergence(ctx context.Context, primaryTrace []*types.LightBlock, now time.Time) error {
+//
+// The light blocks of the witnesses are taken from prefetch, if they were fetched already.
+func (c *Client) detectDivergence(
+	ctx context.Context,
+	primaryTrace []*types.LightBlock,
+	prefetch *witnessPrefetch,
+	now time.Time,
+) error {
 	if primaryTrace == nil || len(primaryTrace) < 2 {
 		return errors.New("nil or single block primary trace")
 	}
@@ -48,7 +55,7 @@ func (c *Client) detectDivergence(ctx context.Context, primaryTrace []*types.Lig
 	// and compare it with the header from the primary
 	errc := make(chan error, len(c.witnesses))
 	for i, witness := range c.witnesses {
-		go c.compareNewHeaderWithWitness(ctx, errc, lastVerifiedHeader, witness, i)
+		go c.compareNewHeaderWithWitness(ctx, errc, lastVerifiedHeader, witness, i, prefetch.fetch(c, i))
 	}
 
 	// handle errors from the header comparisons as they come in
@@ -107,10 +114,12 @@ func (c *Client) detectDivergence(ctx context.Context, primaryTrace []*types.Lig
 // 2: errBadWitness -> the witness has either not responded, doesn't have the header or has given us an invalid one
 //    Note: In the case of an invalid header we remove the witness
 // 3: nil -> the hashes of the two headers match
+//
+// The header of the witness is taken from prefetched, if not nil and successful.
 func (c *Client) compareNewHeaderWithWitness(ctx context.Context, errc chan error, h *types.SignedHeader,
-	witness provider.Provider, witnessIndex int) {
+	witness provider.Provider, witnessIndex int, prefetched *lightBlockFetch) {
 
-	lightBlock, err := c.getLightBlock(ctx, witness, h.Height)
+	lightBlock, err := c.witnessLightBlock(ctx, witness, h.Height, prefetched)
 	switch err {
 	// no error means we move on to checking the hash of the two headers
 	case nil:
@@ -0,0 +1,137 @@
+package light
+
+import (
+	"context"
+	"sync"
+
+	"github.com/tendermint/tendermint/light/provider"
+	"github.com/tendermint/tendermint/types"
+)
+
+// pivotHeights returns the heights of up to n pivots to fetch after failing
+// to verify the light block at lastFailedHeight: the next pivot, then the
+// pivots the bisection would try after failing to verify each of them in turn.
+func (c *Client) pivotHeights(lastVerifiedHeight, lastFailedHeight int64, n int) []int64 {
+	heights := []int64{c.schedule(lastVerifiedHeight, lastFailedHeight)}
+	for len(heights) < n {
+		pivot := c.schedule(lastVerifiedHeight, heights[len(heights)-1])
+		if pivot <= lastVerifiedHeight || pivot >= heights[len(heights)-1] {
+			break
+		}
+		heights = append(heights, pivot)
+	}
+	return heights
+}
+
+// fetchLightBlocks fetches the light blocks at the given heights from p
+// concurrently, and returns them with their errors, in the order of the
+// heights.
+func (c *Client) fetchLightBlocks(
+	ctx context.Context,
+	p provider.Provider,
+	heights []int64,
+) ([]*types.LightBlock, []error) {
+	blocks := make([]*types.LightBlock, len(heights))
+	errs := make([]error, len(heights))
+	if len(heights) == 1 {
+		blocks[0], errs[0] = c.getLightBlock(ctx, p, heights[0])
+		return blocks, errs
+	}
+
+	var wg sync.WaitGroup
+	for i, height := range heights {
+		wg.Add(1)
+		go func(i int, height int64) {
+			defer wg.Done()
+			blocks[i], errs[i] = c.getLightBlock(ctx, p, height)
+		}(i, height)
+	}
+	wg.Wait()
+	return blocks, errs
+}
+
+// witnessPrefetch fetches the light blocks at a height from the witnesses in
+// the background, while the primary is being verified, for the detector to
+// compare the verified light block with.
+type witnessPrefetch struct {
+	// version is the version of the witnesses the fetches were started
+	// with: the fetches are indexed as the witnesses were then.
+	version uint64
+	fetches []*lightBlockFetch
+	cancel  context.CancelFunc
+}
+
+// lightBlockFetch is the fetch of a light block. Its block and err are set
+// once done is closed.
+type lightBlockFetch struct {
+	height int64
+	done   chan struct{}
+	block  *types.LightBlock
+	err    error
+}
+
+// prefetchWitnesses starts fetching the light blocks at height from the
+// witnesses. It returns nil if the concurrent fetches are disabled. The
+// caller must stop the fetches with stop.
+func (c *Client) prefetchWitnesses(ctx context.Context, height int64) *witnessPrefetch {
+	if c.maxConcurrentFetches <= 1 {
+		return nil
+	}
+
+	c.providerMutex.Lock()
+	defer c.providerMutex.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	prefetch := &witnessPrefetch{
+		version: c.witnessesVersion,
+		fetches: make([]*lightBlockFetch, len(c.witnesses)),
+		cancel:  cancel,
+	}
+	for i, witness := range c.witnesses {
+		fetch := &lightBlockFetch{height: height, done: make(chan struct{})}
+		prefetch.fetches[i] = fetch
+		go func(witness provider.Provider) {
+			defer close(fetch.done)
+			fetch.block, fetch.err = c.getLightBlock(ctx, witness, height)
+		}(witness)
+	}
+	return prefetch
+}
+
+// fetch returns the fetch from the witness at index, or nil if there is none
+// or the witnesses changed since the fetches were started.
+//
+// NOTE: requires a providerMutex lock
+func (p *witnessPrefetch) fetch(c *Client, index int) *lightBlockFetch {
+	if p == nil || p.version != c.witnessesVersion || index >= len(p.fetches) {
+		return nil
+	}
+	return p.fetches[index]
+}
+
+// stop cancels the fetches still running.
+func (p *witnessPrefetch) stop() {
+	if p != nil {
+		p.cancel()
+	}
+}
+
+// witnessLightBlock returns the light block at height of the witness: the
+// prefetched one if its fetch succeeded, or a newly fetched one otherwise.
+func (c *Client) witnessLightBlock(
+	ctx context.Context,
+	witness provider.Provider,
+	height int64,
+	prefetched *lightBlockFetch,
+) (*types.LightBlock, error) {
+	if prefetched != nil && prefetched.height == height {
+		select {
+		case <-prefetched.done:
+			if prefetched.err == nil {
+				return prefetched.block, nil
+			}
+		case <-ctx.Done():
+		}
+	}
+	return c.getLightBlock(ctx, witness, height)
+}
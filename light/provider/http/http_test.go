@@ -38,6 +38,15 @@ func TestProvider(t *testing.T) {
 	cfg, err := rpctest.CreateConfig(t.Name())
 	require.NoError(t, err)
 
+	// the blocks within the evidence window are never pruned, so shrink it to
+	// a window still wide enough to query the latest blocks of the fast chain
+	cfg.PruningInterval = 100 * time.Millisecond
+	genDoc, err := types.GenesisDocFromFile(cfg.GenesisFile())
+	require.NoError(t, err)
+	genDoc.ConsensusParams.Evidence.MaxAgeNumBlocks = 1000
+	genDoc.ConsensusParams.Evidence.MaxAgeDuration = time.Millisecond
+	require.NoError(t, genDoc.SaveAs(cfg.GenesisFile()))
+
 	// start a tendermint node in the background to test against
 	app := kvstore.NewApplication()
 	app.RetainBlocks = 9
@@ -45,8 +54,6 @@ func TestProvider(t *testing.T) {
 	require.NoError(t, err)
 
 	rpcAddr := cfg.RPC.ListenAddress
-	genDoc, err := types.GenesisDocFromFile(cfg.GenesisFile())
-	require.NoError(t, err)
 
 	chainID := genDoc.ChainID
 	t.Log("chainID:", chainID)
@@ -76,6 +83,12 @@ func TestProvider(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, lower, lb.Height)
 
+	// the pruner deletes the blocks below the retain height in the background
+	require.Eventually(t, func() bool {
+		status, err := c.Status(ctx)
+		return err == nil && status.SyncInfo.EarliestBlockHeight > 1
+	}, 10*time.Second, 100*time.Millisecond)
+
 	// fetching missing heights (both future and pruned) should return appropriate errors
 	lb, err = p.LightBlock(ctx, 9001)
 	require.Error(t, err)
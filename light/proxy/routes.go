@@ -2,7 +2,9 @@ package proxy
 
 import (
 	"context"
+	"fmt"
 
+	rpccore "github.com/tendermint/tendermint/internal/rpc/core"
 	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 	lrpc "github.com/tendermint/tendermint/light/rpc"
 	rpcclient "github.com/tendermint/tendermint/rpc/client"
@@ -16,10 +18,21 @@ type proxyService struct {
 	*lrpc.Client
 }
 
-func (p proxyService) ABCIQuery(ctx context.Context, path string, data tmbytes.HexBytes, height int64, prove bool) (*coretypes.ResultABCIQuery, error) {
+// The proxy serves /status_verified in addition to the regular routes.
+var _ rpccore.RPCVerifiedStatus = proxyService{}
+
+func (p proxyService) ABCIQuery(
+	ctx context.Context,
+	path string,
+	data tmbytes.HexBytes,
+	height int64,
+	prove bool,
+	blockHash tmbytes.HexBytes,
+) (*coretypes.ResultABCIQuery, error) {
 	return p.ABCIQueryWithOptions(ctx, path, data, rpcclient.ABCIQueryOptions{
-		Height: height,
-		Prove:  prove,
+		Height:    height,
+		Prove:     prove,
+		BlockHash: blockHash,
 	})
 }
 
@@ -27,10 +40,45 @@ func (p proxyService) GetConsensusState(ctx context.Context) (*coretypes.ResultC
 	return p.ConsensusState(ctx)
 }
 
-func (p proxyService) Subscribe(ctx context.Context, query string) (*coretypes.ResultSubscribe, error) {
+func (p proxyService) Subscribe(ctx context.Context, query string, after uint64, statsInterval int64) (*coretypes.ResultSubscribe, error) {
+	if after != 0 {
+		return nil, fmt.Errorf("resuming a subscription is not supported by the light proxy: %w",
+			coretypes.ErrInvalidRequest)
+	}
+	if statsInterval != 0 {
+		return nil, fmt.Errorf("subscription stats are not supported by the light proxy: %w",
+			coretypes.ErrInvalidRequest)
+	}
 	return p.SubscribeWS(ctx, query)
 }
 
+func (p proxyService) TxSearch(
+	ctx context.Context,
+	query string,
+	prove bool,
+	page, perPage *int,
+	orderBy, cursor string,
+) (*coretypes.ResultTxSearch, error) {
+	if cursor != "" {
+		return nil, fmt.Errorf("search cursors are not supported by the light proxy: %w",
+			coretypes.ErrInvalidRequest)
+	}
+	return p.Client.TxSearch(ctx, query, prove, page, perPage, orderBy)
+}
+
+func (p proxyService) BlockSearch(
+	ctx context.Context,
+	query string,
+	page, perPage *int,
+	orderBy, cursor string,
+) (*coretypes.ResultBlockSearch, error) {
+	if cursor != "" {
+		return nil, fmt.Errorf("search cursors are not supported by the light proxy: %w",
+			coretypes.ErrInvalidRequest)
+	}
+	return p.Client.BlockSearch(ctx, query, page, perPage, orderBy)
+}
+
 func (p proxyService) Unsubscribe(ctx context.Context, query string) (*coretypes.ResultUnsubscribe, error) {
 	return p.UnsubscribeWS(ctx, query)
 }
@@ -33,8 +33,13 @@ type LightClient interface {
 	VerifyLightBlockAtHeight(ctx context.Context, height int64, now time.Time) (*types.LightBlock, error)
 	TrustedLightBlock(height int64) (*types.LightBlock, error)
 	Status(ctx context.Context) *types.LightClientInfo
+	CrossCheckTip(ctx context.Context, now time.Time, tolerance int64) (*types.LightBlock, []types.WitnessTip, error)
 }
 
+// defaultTipHeightTolerance is the default number of blocks by which the
+// witnesses may be behind or ahead of the primary in StatusVerified.
+const defaultTipHeightTolerance = 2
+
 var _ rpcclient.Client = (*Client)(nil)
 
 // Client is an RPC client, which uses light#Client to verify data (if it can
@@ -50,6 +55,10 @@ type Client struct {
 	prt       *merkle.ProofRuntime
 	keyPathFn KeyPathFunc
 
+	// tipHeightTolerance is the number of blocks by which the witnesses may
+	// be behind or ahead of the primary in StatusVerified.
+	tipHeightTolerance int64
+
 	closers []func()
 	quitCh  chan struct{}
 }
@@ -68,6 +77,15 @@ func KeyPathFn(fn KeyPathFunc) Option {
 	}
 }
 
+// TipHeightTolerance option sets the number of blocks by which the witnesses
+// may be behind or ahead of the primary for StatusVerified to consider that
+// they corroborate its latest block. It is 2 by default.
+func TipHeightTolerance(h int64) Option {
+	return func(c *Client) {
+		c.tipHeightTolerance = h
+	}
+}
+
 // DefaultMerkleKeyPathFn creates a function used to generate merkle key paths
 // from a path string and a key. This is the default used by the cosmos SDK.
 // This merkle key paths are required when verifying /abci_query calls
@@ -96,6 +114,8 @@ func NewClient(logger log.Logger, next rpcclient.Client, lc LightClient, opts ..
 		lc:     lc,
 		prt:    merkle.DefaultProofRuntime(),
 		quitCh: make(chan struct{}),
+
+		tipHeightTolerance: defaultTipHeightTolerance,
 	}
 	c.BaseService = *service.NewBaseService(logger, "Client", c)
 	for _, o := range opts {
@@ -139,6 +159,42 @@ func (c *Client) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
 	}, nil
 }
 
+// StatusVerified returns the latest block of the primary, verified by the
+// light client, if the witnesses corroborate it, and how each witness compares
+// with it. Unlike Status, it never returns a block that a witness conflicts
+// with or that is stale, e.g. because the primary forked or was rolled back.
+func (c *Client) StatusVerified(ctx context.Context) (*coretypes.ResultStatusVerified, error) {
+	tip, witnesses, err := c.lc.CrossCheckTip(ctx, time.Now(), c.tipHeightTolerance)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &coretypes.ResultStatusVerified{
+		HeightTolerance: c.tipHeightTolerance,
+		Witnesses:       witnesses,
+	}
+
+	agreeing := 0
+	for _, w := range witnesses {
+		switch w.Status {
+		case types.WitnessTipAgrees:
+			agreeing++
+		case types.WitnessTipConflicting, types.WitnessTipAhead:
+			return res, nil
+		}
+	}
+	if agreeing == 0 {
+		return res, nil
+	}
+
+	res.Corroborated = true
+	res.LatestBlockHash = tip.Hash()
+	res.LatestAppHash = tip.AppHash
+	res.LatestBlockHeight = tip.Height
+	res.LatestBlockTime = tip.Time
+	return res, nil
+}
+
 func (c *Client) ABCIInfo(ctx context.Context) (*coretypes.ResultABCIInfo, error) {
 	return c.next.ABCIInfo(ctx)
 }
@@ -149,8 +205,9 @@ func (c *Client) ABCIQuery(ctx context.Context, path string, data tmbytes.HexByt
 }
 
 // ABCIQueryWithOptions returns an error if opts.Prove is false.
-// ABCIQueryWithOptions returns the result for the given height (opts.Height).
-// If no height is provided, the results of the block preceding the latest are returned.
+// ABCIQueryWithOptions returns the result for the given height (opts.Height),
+// or for the block with the given hash (opts.BlockHash), which is verified.
+// If neither is provided, the results of the block preceding the latest are returned.
 func (c *Client) ABCIQueryWithOptions(ctx context.Context, path string, data tmbytes.HexBytes,
 	opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
 
@@ -159,7 +216,7 @@ func (c *Client) ABCIQueryWithOptions(ctx context.Context, path string, data tmb
 
 	// Can't return the latest block results because we won't be able to
 	// prove them. Return the results for the previous block instead.
-	if opts.Height == 0 {
+	if opts.Height == 0 && len(opts.BlockHash) == 0 {
 		res, err := c.next.Status(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("can't get latest height: %w", err)
@@ -195,6 +252,13 @@ func (c *Client) ABCIQueryWithOptions(ctx context.Context, path string, data tmb
 		return nil, err
 	}
 
+	// Check that the state is the one of the requested block, which the
+	// trusted header H+1 refers to.
+	if len(opts.BlockHash) > 0 && !bytes.Equal(l.LastBlockID.Hash, opts.BlockHash) {
+		return nil, fmt.Errorf("the response is for block %X at height %d, not %X",
+			l.LastBlockID.Hash, resp.Height, opts.BlockHash)
+	}
+
 	// Validate the value proof against the trusted header.
 	if resp.Value != nil {
 		// 1) build a Merkle key path from path and resp.Key
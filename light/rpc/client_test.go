@@ -0,0 +1,66 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	lcmock "github.com/tendermint/tendermint/light/rpc/mocks"
+	"github.com/tendermint/tendermint/types"
+)
+
+func TestStatusVerified(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tip := &types.LightBlock{SignedHeader: &types.SignedHeader{
+		Header: &types.Header{Height: 10, Time: time.Now(), AppHash: []byte("app_hash")},
+	}}
+	agrees := types.WitnessTip{WitnessID: "a", Status: types.WitnessTipAgrees, LatestHeight: 10}
+	lagging := types.WitnessTip{WitnessID: "b", Status: types.WitnessTipLagging, LatestHeight: 2}
+	conflicting := types.WitnessTip{WitnessID: "c", Status: types.WitnessTipConflicting, LatestHeight: 9}
+	ahead := types.WitnessTip{WitnessID: "d", Status: types.WitnessTipAhead, LatestHeight: 20}
+
+	testCases := []struct {
+		name         string
+		witnesses    []types.WitnessTip
+		corroborated bool
+	}{
+		{"agreeing witnesses", []types.WitnessTip{agrees, lagging}, true},
+		{"no agreeing witness", []types.WitnessTip{lagging}, false},
+		{"conflicting witness", []types.WitnessTip{agrees, conflicting}, false},
+		{"witness ahead", []types.WitnessTip{agrees, ahead}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lc := &lcmock.LightClient{}
+			lc.On("CrossCheckTip", mock.Anything, mock.Anything, int64(3)).Return(tip, tc.witnesses, nil)
+			c := NewClient(log.NewNopLogger(), nil, lc, TipHeightTolerance(3))
+
+			res, err := c.StatusVerified(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tc.corroborated, res.Corroborated)
+			assert.Equal(t, tc.witnesses, res.Witnesses)
+			assert.EqualValues(t, 3, res.HeightTolerance)
+			if tc.corroborated {
+				assert.EqualValues(t, 10, res.LatestBlockHeight)
+				assert.EqualValues(t, tip.Hash(), res.LatestBlockHash)
+				assert.EqualValues(t, tip.AppHash, res.LatestAppHash)
+			} else {
+				assert.Zero(t, res.LatestBlockHeight)
+				assert.Empty(t, res.LatestBlockHash)
+			}
+		})
+	}
+
+	lc := &lcmock.LightClient{}
+	lc.On("CrossCheckTip", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, errors.New("no trusted light block"))
+	_, err := NewClient(log.NewNopLogger(), nil, lc).StatusVerified(ctx)
+	assert.Error(t, err)
+}
@@ -31,6 +31,38 @@ func (_m *LightClient) ChainID() string {
 	return r0
 }
 
+// CrossCheckTip provides a mock function with given fields: ctx, now, tolerance
+func (_m *LightClient) CrossCheckTip(ctx context.Context, now time.Time, tolerance int64) (*types.LightBlock, []types.WitnessTip, error) {
+	ret := _m.Called(ctx, now, tolerance)
+
+	var r0 *types.LightBlock
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64) *types.LightBlock); ok {
+		r0 = rf(ctx, now, tolerance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.LightBlock)
+		}
+	}
+
+	var r1 []types.WitnessTip
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64) []types.WitnessTip); ok {
+		r1 = rf(ctx, now, tolerance)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]types.WitnessTip)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, time.Time, int64) error); ok {
+		r2 = rf(ctx, now, tolerance)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // Status provides a mock function with given fields: ctx
 func (_m *LightClient) Status(ctx context.Context) *types.LightClientInfo {
 	ret := _m.Called(ctx)
@@ -0,0 +1,209 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tendermint/tendermint/atrest"
+	"github.com/tendermint/tendermint/config"
+	"github.com/tendermint/tendermint/internal/consensus"
+	sm "github.com/tendermint/tendermint/internal/state"
+	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/libs/log"
+	tmos "github.com/tendermint/tendermint/libs/os"
+	tmstrings "github.com/tendermint/tendermint/libs/strings"
+	"github.com/tendermint/tendermint/privval"
+)
+
+// integrityIssue is an inconsistency between the stores of a node, found at
+// startup.
+type integrityIssue struct {
+	problem string
+	// recovery are the shell commands recovering from the issue.
+	recovery []string
+	// repair repairs the issue, if it can be done safely.
+	repair func() error
+	// warning is true if the node can start despite the issue.
+	warning bool
+}
+
+// checkIntegrity checks the consistency of the blockstore, the state, the
+// consensus WAL and the last signed state of the file private validator,
+// which is nil with a remote signer. It must run before the handshake, which
+// panics on some of the inconsistencies.
+func checkIntegrity(
+	cfg *config.Config,
+	blockStore *store.BlockStore,
+	state sm.State,
+	walCipher *atrest.Cipher,
+	filePrivval *privval.FilePV,
+) ([]integrityIssue, error) {
+	var issues []integrityIssue
+	storeBase, storeHeight := blockStore.Base(), blockStore.Height()
+	// The state of a new chain is at the height before the initial height.
+	stateHeight := state.LastBlockHeight
+	if stateHeight == 0 && state.InitialHeight > 1 {
+		stateHeight = state.InitialHeight - 1
+	}
+
+	// The handshake replays the last block if the state is one block behind,
+	// and can't recover from the other differences.
+	switch {
+	case state.LastBlockHeight > storeHeight:
+		return append(issues, integrityIssue{
+			problem: fmt.Sprintf("the state is at height %d, ahead of the blockstore at height %d",
+				stateHeight, storeHeight),
+			recovery: resyncCommands(cfg),
+		}), nil
+	case storeHeight > stateHeight+1:
+		return append(issues, integrityIssue{
+			problem: fmt.Sprintf("the blockstore is at height %d, more than one block ahead of the state at height %d",
+				storeHeight, stateHeight),
+			recovery: resyncCommands(cfg),
+		}), nil
+	}
+
+	if storeHeight > 0 {
+		for _, height := range []int64{storeBase, storeHeight} {
+			if blockStore.LoadBlockMeta(height) == nil {
+				issues = append(issues, integrityIssue{
+					problem:  fmt.Sprintf("the blockstore is missing the block at height %d", height),
+					recovery: resyncCommands(cfg),
+				})
+			}
+		}
+		// The consensus state reconstructs the votes of the last commit.
+		seenCommit := blockStore.LoadSeenCommit()
+		if (seenCommit == nil || seenCommit.Height != storeHeight) && blockStore.LoadBlockCommit(storeHeight) == nil {
+			issues = append(issues, integrityIssue{
+				problem:  fmt.Sprintf("the blockstore is missing the seen commit of the block at height %d", storeHeight),
+				recovery: resyncCommands(cfg),
+			})
+		}
+	}
+
+	// The WAL is written after the block is saved, so it never ends ahead of
+	// the blockstore, and the consensus refuses to replay it if it does.
+	height := stateHeight
+	if storeHeight > height {
+		height = storeHeight
+	}
+	walFile := cfg.Consensus.WalFile()
+	walHeight, err := consensus.WALLastEndHeight(walFile, walCipher)
+	if err != nil {
+		return nil, fmt.Errorf("reading the consensus WAL: %w", err)
+	}
+	if walHeight > height {
+		files, err := consensus.WALFiles(walFile)
+		if err != nil {
+			return nil, err
+		}
+		// The backup directory is ignored by the WAL, as its name doesn't
+		// start with the name of the WAL file.
+		backup := filepath.Join(filepath.Dir(walFile), "ahead-"+time.Now().UTC().Format("20060102T150405"))
+		issues = append(issues, integrityIssue{
+			problem: fmt.Sprintf("the consensus WAL ends at height %d, ahead of the blockstore at height %d",
+				walHeight, height),
+			recovery: []string{
+				"mkdir -p " + backup,
+				fmt.Sprintf("mv %s %s/", strings.Join(files, " "), backup),
+			},
+			// Without the WAL, the node restarts the height from scratch, and
+			// the last signed state of the validator prevents double signing.
+			repair: func() error { return moveFiles(files, backup) },
+		})
+	}
+
+	if filePrivval != nil && storeHeight > 0 && filePrivval.LastSignState.Height > height+1 {
+		issues = append(issues, integrityIssue{
+			problem: fmt.Sprintf("the validator last signed at height %d, ahead of the blockstore at height %d: "+
+				"the data may have been restored from an old backup, and the validator won't sign "+
+				"before the chain reaches that height", filePrivval.LastSignState.Height, height),
+			warning: true,
+		})
+	}
+
+	return issues, nil
+}
+
+// runIntegrityCheck runs checkIntegrity, logs the issues, and repairs them in
+// the repair mode. It returns an error with the recovery commands if an issue
+// prevents the node from starting.
+func runIntegrityCheck(
+	logger log.Logger,
+	cfg *config.Config,
+	blockStore *store.BlockStore,
+	state sm.State,
+	walCipher *atrest.Cipher,
+	filePrivval *privval.FilePV,
+) error {
+	issues, err := checkIntegrity(cfg, blockStore, state, walCipher, filePrivval)
+	if err != nil {
+		return fmt.Errorf("integrity check: %w", err)
+	}
+
+	var problems, recovery []string
+	for _, issue := range issues {
+		switch {
+		case issue.warning:
+			logger.Error("Integrity check", "warning", issue.problem)
+			continue
+		case issue.repair != nil && cfg.IntegrityCheck == config.IntegrityCheckRepair:
+			if err := issue.repair(); err != nil {
+				return fmt.Errorf("integrity check: repairing %q: %w", issue.problem, err)
+			}
+			logger.Info("Integrity check repaired an issue", "issue", issue.problem)
+			continue
+		}
+		logger.Error("Integrity check", "issue", issue.problem, "recovery", strings.Join(issue.recovery, "; "))
+		problems = append(problems, issue.problem)
+		// Several issues share the same recovery.
+		for _, cmd := range issue.recovery {
+			if !tmstrings.StringInSlice(cmd, recovery) {
+				recovery = append(recovery, cmd)
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("integrity check: %s", strings.Join(problems, "; "))
+	if len(recovery) > 0 {
+		msg += ". To recover, stop the node and run:\n\t" + strings.Join(recovery, "\n\t") +
+			"\nthen restart it to sync from its peers, or restore the data from a backup"
+	}
+	return errors.New(msg)
+}
+
+// resyncCommands are the commands removing the data of a node, to sync it
+// again, while keeping the last signed state of its validator.
+func resyncCommands(cfg *config.Config) []string {
+	stateFile := cfg.PrivValidator.StateFile()
+	if !tmos.FileExists(stateFile) {
+		return []string{"tendermint unsafe-reset-all --home " + cfg.RootDir}
+	}
+	backup := filepath.Join(cfg.RootDir, filepath.Base(stateFile)+".backup")
+	return []string{
+		fmt.Sprintf("cp %s %s", stateFile, backup),
+		"tendermint unsafe-reset-all --home " + cfg.RootDir,
+		fmt.Sprintf("cp %s %s", backup, stateFile),
+	}
+}
+
+// moveFiles moves files to the directory dir, creating it.
+func moveFiles(files []string, dir string) error {
+	if err := tmos.EnsureDir(dir, 0700); err != nil {
+		return err
+	}
+	for _, path := range files {
+		if err := os.Rename(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
@@ -13,25 +14,35 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	abciclient "github.com/tendermint/tendermint/abci/client"
 	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/dbcompress"
 	"github.com/tendermint/tendermint/internal/blocksync"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
+	"github.com/tendermint/tendermint/internal/features"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
+	"github.com/tendermint/tendermint/internal/p2p/maintenance"
 	"github.com/tendermint/tendermint/internal/p2p/pex"
 	"github.com/tendermint/tendermint/internal/proxy"
+	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
 	rpccore "github.com/tendermint/tendermint/internal/rpc/core"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
+	"github.com/tendermint/tendermint/internal/state/indexer/sink"
 	"github.com/tendermint/tendermint/internal/statesync"
 	"github.com/tendermint/tendermint/internal/store"
+	"github.com/tendermint/tendermint/internal/telemetry"
+	"github.com/tendermint/tendermint/internal/watchdog"
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/libs/service"
 	"github.com/tendermint/tendermint/libs/strings"
 	tmtime "github.com/tendermint/tendermint/libs/time"
 	"github.com/tendermint/tendermint/privval"
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
 	"github.com/tendermint/tendermint/types"
 
 	_ "net/http/pprof" // nolint: gosec // securely exposed on separate, optional port
@@ -64,7 +75,7 @@ type nodeImpl struct {
 	stateSync        bool               // whether the node should state sync on startup
 	stateSyncReactor *statesync.Reactor // for hosting and restoring state sync snapshots
 
-	services      []service.Service
+	services      *service.Manager
 	rpcListeners  []net.Listener // rpc servers
 	shutdownOps   closer
 	rpcEnv        *rpccore.Environment
@@ -128,13 +139,48 @@ func makeNode(
 
 	closers := []closer{convertCancelCloser(cancel)}
 
+	// The consensus WAL, blockstore and evidence databases are encrypted at
+	// rest if an encryption key provider is configured, and the values of the
+	// blockstore and state databases are compressed before being encrypted.
+	cipher, err := atrest.CipherFromConfig(cfg.BaseConfig)
+	if err != nil {
+		return nil, combineCloseError(err, makeCloser(closers))
+	}
+	codec, err := dbcompress.CodecFromConfig(cfg.BaseConfig)
+	if err != nil {
+		return nil, combineCloseError(err, makeCloser(closers))
+	}
+	dbProvider = dbcompress.WrapDBProvider(atrest.WrapDBProvider(dbProvider, cipher), codec)
+
 	blockStore, stateDB, dbCloser, err := initDBs(cfg, dbProvider)
 	if err != nil {
 		return nil, combineCloseError(err, dbCloser)
 	}
 	closers = append(closers, dbCloser)
 
-	stateStore := sm.NewStore(stateDB)
+	// The index intents of the blocks are recorded if they are indexed, for
+	// the indexer service to index the blocks left unindexed by a crash.
+	var stateStoreOpts []sm.StoreOption
+	if sink.IndexingEnabled(cfg) {
+		stateStoreOpts = append(stateStoreOpts, sm.WithIndexIntents())
+	}
+	stateStore := sm.NewStore(stateDB, stateStoreOpts...)
+
+	var forensics *sm.Forensics
+	if dir := cfg.ForensicsDir(); dir != "" {
+		forensics = sm.NewForensics(dir, stateStore, blockStore, logger.With("module", "forensics"))
+	}
+
+	var valAuthority crypto.PubKey
+	if cfg.Consensus.ValidatorAuthorityPubKey != "" {
+		bz, err := base64.StdEncoding.DecodeString(cfg.Consensus.ValidatorAuthorityPubKey)
+		if err != nil {
+			return nil, combineCloseError(
+				fmt.Errorf("invalid validator authority public key: %w", err),
+				makeCloser(closers))
+		}
+		valAuthority = ed25519.PubKey(bz)
+	}
 
 	genDoc, err := genesisDocProvider()
 	if err != nil {
@@ -153,10 +199,32 @@ func makeNode(
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
 
+	if cfg.IntegrityCheck != config.IntegrityCheckOff {
+		err := runIntegrityCheck(logger.With("module", "integrity"), cfg, blockStore, state, cipher, filePrivval)
+		if err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+	}
+
 	nodeMetrics := defaultMetricsProvider(cfg.Instrumentation)(genDoc.ChainID)
 
+	featureSet, err := features.ParseSet(cfg.Features)
+	if err != nil {
+		return nil, combineCloseError(err, makeCloser(closers))
+	}
+	nodeMetrics.features.Record(featureSet)
+
+	var resourceWatchdog *watchdog.Watchdog
+	var shedLoad func() bool
+	if cfg.Resources.CheckInterval > 0 {
+		resourceWatchdog = watchdog.New(logger.With("module", "watchdog"), cfg.Resources,
+			cfg.DBDir(), nodeMetrics.watchdog)
+		shedLoad = resourceWatchdog.Shedding
+	}
+
 	// Create the proxyApp and establish connections to the ABCI app (consensus, mempool, query).
-	proxyApp := proxy.NewAppConns(clientCreator, logger.With("module", "proxy"), nodeMetrics.proxy)
+	proxyApp := proxy.NewAppConns(clientCreator, logger.With("module", "proxy"), nodeMetrics.proxy,
+		proxy.WithMempoolConnections(cfg.Mempool.CheckTxConnections))
 	if err := proxyApp.Start(ctx); err != nil {
 		return nil, fmt.Errorf("error starting proxy app connections: %w", err)
 	}
@@ -165,14 +233,27 @@ func makeNode(
 	// we might need to index the txs of the replayed block as this might not have happened
 	// when the node stopped last time (i.e. the node stopped after it saved the block
 	// but before it indexed the txs, or, endblocker panicked)
-	eventBus := eventbus.NewDefault(logger.With("module", "events"))
+	var eventBusOpts []tmpubsub.Option
+	if window := cfg.RPC.EventLogWindowSize; window > 0 {
+		eventBusOpts = append(eventBusOpts, tmpubsub.EventLog(window, cfg.RPC.EventLogMaxItems))
+	}
+	if cfg.RPC.EventLogPersistent {
+		eventLog, eventLogCloser, err := createEventLogStore(cfg, dbProvider)
+		closers = append(closers, eventLogCloser)
+		if err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+		eventBusOpts = append(eventBusOpts, tmpubsub.PersistentEventLog(eventLog))
+	}
+	eventBus := eventbus.New(logger.With("module", "events"), eventBusOpts...)
 	if err := eventBus.Start(ctx); err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
 
 	indexerService, eventSinks, err := createAndStartIndexerService(
 		ctx, cfg, dbProvider, eventBus,
-		logger, genDoc.ChainID, nodeMetrics.indexer)
+		logger, genDoc.ChainID, nodeMetrics.indexer,
+		sm.NewIndexIntentLog(stateDB, stateStore, blockStore))
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
@@ -207,10 +288,24 @@ func makeNode(
 	// Create the handshaker, which calls RequestInfo, sets the AppVersion on the state,
 	// and replays any blocks as necessary to sync tendermint with the app.
 	if !stateSync {
-		if err := consensus.NewHandshaker(
+		handshaker := consensus.NewHandshaker(
 			logger.With("module", "handshaker"),
 			stateStore, state, blockStore, eventBus, genDoc,
-		).Handshake(ctx, proxyApp); err != nil {
+		)
+		if forensics != nil {
+			handshaker.SetForensics(forensics)
+		}
+		handshaker.SetMetrics(nodeMetrics.consensus)
+
+		// Report the progress of the replay until the RPC server starts.
+		stopReplayStatus, err := serveReplayStatus(ctx, cfg,
+			logger.With("module", "rpc-server", "protocol", "replay-status"), handshaker)
+		if err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+		err = handshaker.Handshake(ctx, proxyApp)
+		stopReplayStatus()
+		if err != nil {
 			return nil, combineCloseError(err, makeCloser(closers))
 		}
 
@@ -231,14 +326,35 @@ func makeNode(
 
 	logNodeStartupInfo(state, pubKey, logger, cfg.Mode)
 
+	externalAddress, p2pListeners, natCloser := setupNATTraversal(ctx, logger, cfg, nodeKey)
+	closers = append(closers, natCloser)
+
 	// TODO: Fetch and provide real options and do proper p2p bootstrapping.
 	// TODO: Use a persistent peer database.
 	nodeInfo, err := makeNodeInfo(cfg, nodeKey, eventSinks, genDoc, state)
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
+	if externalAddress != "" {
+		nodeInfo.ListenAddr = externalAddress
+	}
+	if cfg.P2P.AttestValidator {
+		nodeInfo.ValidatorAttestation, err = attestValidator(cfg, nodeKey, genDoc, filePrivval)
+		if err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+	}
+
+	var validatorBook *p2p.ValidatorBook
+	if cfg.P2P.ValidatorBook {
+		validatorBook = p2p.NewValidatorBook()
+	}
+	peerFilter, err := createPeerFilter(cfg)
+	if err != nil {
+		return nil, combineCloseError(err, makeCloser(closers))
+	}
 
-	peerManager, peerCloser, err := createPeerManager(cfg, dbProvider, nodeKey.ID)
+	peerManager, peerCloser, err := createPeerManager(cfg, dbProvider, nodeKey.ID, externalAddress)
 	closers = append(closers, peerCloser)
 	if err != nil {
 		return nil, combineCloseError(
@@ -247,7 +363,7 @@ func makeNode(
 	}
 
 	router, err := createRouter(ctx, logger, nodeMetrics.p2p, nodeInfo, nodeKey,
-		peerManager, cfg, proxyApp)
+		peerManager, cfg, proxyApp, validatorBook, peerFilter, shedLoad, p2pListeners)
 	if err != nil {
 		return nil, combineCloseError(
 			fmt.Errorf("failed to create router: %w", err),
@@ -255,7 +371,7 @@ func makeNode(
 	}
 
 	mpReactor, mp, err := createMempoolReactor(ctx,
-		cfg, proxyApp, state, nodeMetrics.mempool, peerManager, router, logger,
+		cfg, proxyApp, state, nodeMetrics.mempool, eventBus, peerManager, router, featureSet, logger,
 	)
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
@@ -269,6 +385,15 @@ func makeNode(
 	}
 
 	// make block executor for consensus and blockchain reactors to execute blocks
+	blockExecOpts := []sm.BlockExecutorOption{
+		sm.BlockExecutorWithMetrics(nodeMetrics.state),
+	}
+	if forensics != nil {
+		blockExecOpts = append(blockExecOpts, sm.BlockExecutorWithForensics(forensics))
+	}
+	pruner := sm.NewPruner(logger.With("module", "pruner"), stateStore, blockStore, eventSinks,
+		cfg.PruningInterval, cfg.MinRetainBlocks, nodeMetrics.state)
+	blockExecOpts = append(blockExecOpts, sm.BlockExecutorWithPruner(pruner))
 	blockExec := sm.NewBlockExecutor(
 		stateStore,
 		logger.With("module", "state"),
@@ -276,28 +401,46 @@ func makeNode(
 		mp,
 		evPool,
 		blockStore,
-		sm.BlockExecutorWithMetrics(nodeMetrics.state),
+		blockExecOpts...,
 	)
 
 	csReactor, csState, err := createConsensusReactor(ctx,
 		cfg, state, blockExec, blockStore, mp, evPool,
 		privValidator, nodeMetrics.consensus, stateSync || blockSync, eventBus,
-		peerManager, router, logger,
+		peerManager, router, cipher, logger,
 	)
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
+	csReactor.SetFeatures(featureSet)
+	if validatorBook != nil {
+		csReactor.SetValidatorBook(validatorBook)
+	}
+	var quarantine func(types.NodeID, error)
+	if cfg.P2P.QuarantineForkPeers {
+		quarantine = func(id types.NodeID, err error) {
+			rule := p2p.PeerFilterRule{Action: p2p.PeerFilterDeny, Kind: p2p.PeerFilterNodeID, Value: string(id)}
+			if _, err := peerFilter.AddRule(rule); err != nil {
+				logger.Error("failed to deny peer on another branch", "peer", id, "err", err)
+			}
+			peerManager.Errored(id, err)
+		}
+	}
+	forkDetector := consensus.NewForkDetector(logger.With("module", "forks"),
+		blockStore, nodeMetrics.consensus, quarantine)
+	csReactor.SetForkDetector(forkDetector)
 
 	// Create the blockchain reactor. Note, we do not start block sync if we're
 	// doing a state sync first.
 	bcReactor, err := blocksync.NewReactor(ctx,
 		logger.With("module", "blockchain"),
+		cfg.BlockSync,
 		state.Copy(),
 		blockExec,
 		blockStore,
 		csReactor,
 		router.OpenChannel,
-		peerManager.Subscribe(ctx),
+		peerManager.SubscribeChannel(ctx, blocksync.BlockSyncChannel),
 		blockSync && !stateSync,
 		nodeMetrics.consensus,
 		eventBus,
@@ -307,6 +450,9 @@ func makeNode(
 			fmt.Errorf("could not create blocksync reactor: %w", err),
 			makeCloser(closers))
 	}
+	bcReactor.SetPeerStats(peerManager.Stats())
+	bcReactor.SetFeatures(featureSet)
+	bcReactor.SetPeerMaintenance(peerManager.Maintenance())
 
 	// Make ConsensusReactor. Don't enable fully if doing a state sync and/or block sync first.
 	// FIXME We need to update metrics here, since other reactors don't have access to them.
@@ -329,7 +475,7 @@ func makeNode(
 		proxyApp.Snapshot(),
 		proxyApp.Query(),
 		router.OpenChannel,
-		peerManager.Subscribe(ctx),
+		peerManager.SubscribeChannel(ctx, statesync.SnapshotChannel),
 		stateStore,
 		blockStore,
 		cfg.StateSync.TempDir,
@@ -339,10 +485,36 @@ func makeNode(
 	if err != nil {
 		return nil, combineCloseError(err, makeCloser(closers))
 	}
+	stateSyncReactor.SetPeerMaintenance(peerManager.Maintenance())
+
+	maintenanceWindows, err := maintenance.WindowsFromConfig(cfg.P2P)
+	if err != nil {
+		return nil, combineCloseError(err, makeCloser(closers))
+	}
+	maintenanceReactor, err := maintenance.NewReactor(ctx,
+		logger.With("module", "maintenance"),
+		peerManager.Maintenance(),
+		router.OpenChannel,
+		peerManager.SubscribeChannel(ctx, maintenance.MaintenanceChannel),
+		maintenanceWindows,
+	)
+	if err != nil {
+		return nil, combineCloseError(err, makeCloser(closers))
+	}
+
+	var snapshotService *statesync.SnapshotService
+	if cfg.StateSync.SnapshotInterval > 0 {
+		var snapshotCloser closer
+		snapshotService, snapshotCloser, err = createSnapshotService(cfg, dbProvider, proxyApp.Snapshot(), logger)
+		closers = append(closers, snapshotCloser)
+		if err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+	}
 
 	var pexReactor service.Service
 	if cfg.P2P.PexReactor {
-		pexReactor, err = pex.NewReactor(ctx, logger, peerManager, router.OpenChannel, peerManager.Subscribe(ctx))
+		pexReactor, err = pex.NewReactor(ctx, logger, peerManager, router.OpenChannel, peerManager.SubscribeChannel(ctx, pex.PexChannel))
 		if err != nil {
 			return nil, combineCloseError(err, makeCloser(closers))
 		}
@@ -360,15 +532,7 @@ func makeNode(
 
 		eventSinks: eventSinks,
 
-		services: []service.Service{
-			eventBus,
-			indexerService,
-			evReactor,
-			mpReactor,
-			csReactor,
-			bcReactor,
-			pexReactor,
-		},
+		services: service.NewManager(logger.With("module", "services")),
 
 		stateStore:       stateStore,
 		blockStore:       blockStore,
@@ -385,11 +549,15 @@ func makeNode(
 			BlockStore:     blockStore,
 			EvidencePool:   evPool,
 			ConsensusState: csState,
+			BlockExecutor:  blockExec,
+			Pruner:         pruner,
 
 			ConsensusReactor: csReactor,
 			BlockSyncReactor: bcReactor,
 
-			PeerManager: peerManager,
+			PeerManager:     peerManager,
+			MessageCapturer: router,
+			BandwidthMeter:  router,
 
 			GenDoc:     genDoc,
 			EventSinks: eventSinks,
@@ -397,6 +565,11 @@ func makeNode(
 			Mempool:    mp,
 			Logger:     logger.With("module", "rpc"),
 			Config:     *cfg.RPC,
+			RPCMetrics: nodeMetrics.rpc,
+			Features:   featureSet,
+
+			ValidatorAuthority:      valAuthority,
+			ValidatorUpdateMinDelay: cfg.Consensus.ValidatorUpdateMinDelay,
 		},
 	}
 
@@ -404,7 +577,71 @@ func makeNode(
 		node.rpcEnv.PubKey = pubKey
 	}
 
+	// The reactors start once the event bus they publish to is running, and
+	// consensus once the pools it proposes from are. Block sync hands over to
+	// consensus, so it starts after it.
+	for _, s := range []struct {
+		name string
+		svc  service.Service
+		deps []string
+	}{
+		{"eventbus", eventBus, nil},
+		{"indexer", indexerService, []string{"eventbus"}},
+		{"evidence", evReactor, []string{"eventbus"}},
+		{"mempool", mpReactor, []string{"eventbus"}},
+		{"consensus", csReactor, []string{"evidence", "mempool"}},
+		{"blocksync", bcReactor, []string{"consensus"}},
+		{"maintenance", maintenanceReactor, nil},
+		{"pex", pexReactor, nil},
+		{"pruner", pruner, []string{"indexer"}},
+	} {
+		if s.svc == nil {
+			continue
+		}
+		if err := node.services.Add(s.name, s.svc, service.WithDependencies(s.deps...)); err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+	}
+
 	node.rpcEnv.P2PTransport = node
+	node.rpcEnv.PeerFilterRules = peerFilter
+	node.rpcEnv.ForkDetector = forkDetector
+	if validatorBook != nil {
+		node.rpcEnv.ValidatorIdentities = validatorBook
+	}
+	if snapshotService != nil {
+		if err := node.services.Add("snapshots", snapshotService); err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+		node.rpcEnv.SnapshotService = snapshotService
+	}
+	if forensics != nil {
+		node.rpcEnv.Forensics = forensics
+	}
+	if resourceWatchdog != nil {
+		if err := node.services.Add("watchdog", resourceWatchdog); err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+		node.rpcEnv.LoadShedder = resourceWatchdog
+	}
+
+	if cfg.P2P.RelayServerAddress != "" {
+		relayServer, err := createRelayServer(logger, cfg)
+		if err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+		if err := node.services.Add("relay", relayServer); err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+	}
+
+	if len(cfg.Instrumentation.TelemetryCollectors) > 0 {
+		reporter := telemetry.NewReporter(
+			logger.With("module", "telemetry"), cfg.Instrumentation, nodeKey.PrivKey, node.telemetryReport)
+		if err := node.services.Add("telemetry", reporter); err != nil {
+			return nil, combineCloseError(err, makeCloser(closers))
+		}
+	}
 
 	node.BaseService = *service.NewBaseService(logger, "Node", node)
 
@@ -445,7 +682,7 @@ func (n *nodeImpl) OnStart(ctx context.Context) error {
 
 	// Start the RPC server before the P2P server
 	// so we can eg. receive txs for the first block
-	if n.config.RPC.ListenAddress != "" {
+	if n.config.RPC.ListenAddress != "" || n.config.RPC.GRPCListenAddress != "" {
 		var err error
 		n.rpcListeners, err = n.rpcEnv.StartService(ctx, n.config)
 		if err != nil {
@@ -463,14 +700,8 @@ func (n *nodeImpl) OnStart(ctx context.Context) error {
 	}
 	n.isListening = true
 
-	for _, reactor := range n.services {
-		if err := reactor.Start(ctx); err != nil {
-			if errors.Is(err, service.ErrAlreadyStarted) {
-				continue
-			}
-
-			return fmt.Errorf("problem starting service '%T': %w ", reactor, err)
-		}
+	if err := n.services.Start(ctx); err != nil {
+		return fmt.Errorf("problem starting services: %w", err)
 	}
 
 	if err := n.stateSyncReactor.Start(ctx); err != nil {
@@ -551,15 +782,21 @@ func (n *nodeImpl) OnStart(ctx context.Context) error {
 func (n *nodeImpl) OnStop() {
 	n.logger.Info("Stopping Node")
 
+	// Close the websocket connections gracefully while the events already
+	// published can still be delivered.
+	drainCtx, cancel := context.WithTimeout(context.Background(), n.config.RPC.WebsocketDrainTimeout)
+	if err := n.rpcEnv.DrainWebsockets(drainCtx, "the node is shutting down"); err != nil {
+		n.logger.Error("failed to drain websocket connections", "err", err)
+	}
+	cancel()
+
 	for _, es := range n.eventSinks {
 		if err := es.Stop(); err != nil {
 			n.logger.Error("failed to stop event sink", "err", err)
 		}
 	}
 
-	for _, reactor := range n.services {
-		reactor.Wait()
-	}
+	n.services.Wait()
 
 	n.stateSyncReactor.Wait()
 	n.router.Wait()
@@ -635,6 +872,26 @@ func (n *nodeImpl) startPrometheusServer(ctx context.Context, addr string) *http
 	return srv
 }
 
+// telemetryReport returns the status of the node pushed to telemetry
+// collectors.
+func (n *nodeImpl) telemetryReport(ctx context.Context) (*telemetry.Report, error) {
+	status, err := n.rpcEnv.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &telemetry.Report{
+		NodeID:        status.NodeInfo.NodeID,
+		Moniker:       status.NodeInfo.Moniker,
+		Network:       status.NodeInfo.Network,
+		Version:       status.NodeInfo.Version,
+		Height:        status.SyncInfo.LatestBlockHeight,
+		BlockTime:     status.SyncInfo.LatestBlockTime,
+		MaxPeerHeight: status.SyncInfo.MaxPeerBlockHeight,
+		CatchingUp:    status.SyncInfo.CatchingUp,
+		Peers:         len(n.peerManager.Peers()),
+	}, nil
+}
+
 // EventBus returns the Node's EventBus.
 func (n *nodeImpl) EventBus() *eventbus.EventBus {
 	return n.rpcEnv.EventBus
@@ -682,12 +939,15 @@ func defaultGenesisDocProviderFunc(cfg *config.Config) genesisDocProvider {
 
 type nodeMetrics struct {
 	consensus *consensus.Metrics
+	features  *features.Metrics
 	indexer   *indexer.Metrics
 	mempool   *mempool.Metrics
 	p2p       *p2p.Metrics
 	proxy     *proxy.Metrics
+	rpc       *rpcserver.Metrics
 	state     *sm.Metrics
 	statesync *statesync.Metrics
+	watchdog  *watchdog.Metrics
 }
 
 // metricsProvider returns consensus, p2p, mempool, state, statesync Metrics.
@@ -700,22 +960,28 @@ func defaultMetricsProvider(cfg *config.InstrumentationConfig) metricsProvider {
 		if cfg.Prometheus {
 			return &nodeMetrics{
 				consensus: consensus.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				features:  features.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				indexer:   indexer.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				mempool:   mempool.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				p2p:       p2p.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				proxy:     proxy.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				rpc:       rpcserver.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				state:     sm.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 				statesync: statesync.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
+				watchdog:  watchdog.PrometheusMetrics(cfg.Namespace, "chain_id", chainID),
 			}
 		}
 		return &nodeMetrics{
 			consensus: consensus.NopMetrics(),
+			features:  features.NopMetrics(),
 			indexer:   indexer.NopMetrics(),
 			mempool:   mempool.NopMetrics(),
 			p2p:       p2p.NopMetrics(),
 			proxy:     proxy.NopMetrics(),
+			rpc:       rpcserver.NopMetrics(),
 			state:     sm.NopMetrics(),
 			statesync: statesync.NopMetrics(),
+			watchdog:  watchdog.NopMetrics(),
 		}
 	}
 }
@@ -752,6 +1018,19 @@ func getRouterConfig(conf *config.Config, proxyApp proxy.AppConns) p2p.RouterOpt
 		QueueType: conf.P2P.QueueType,
 	}
 
+	// The channels and the rates were validated with the config.
+	optOut, _ := conf.P2P.OptOutChannelIDs()
+	for _, id := range optOut {
+		opts.OptOutChannels = append(opts.OptOutChannels, p2p.ChannelID(id))
+	}
+	rates, _ := conf.P2P.ChannelRecvRateLimits()
+	if len(rates) > 0 {
+		opts.ChannelRecvRates = make(map[p2p.ChannelID]int64, len(rates))
+		for chID, rate := range rates {
+			opts.ChannelRecvRates[p2p.ChannelID(chID)] = rate
+		}
+	}
+
 	if conf.FilterPeers && proxyApp != nil {
 		opts.FilterPeerByID = func(ctx context.Context, id types.NodeID) error {
 			res, err := proxyApp.Query().Query(ctx, abci.RequestQuery{
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -17,10 +20,12 @@ import (
 
 	abciclient "github.com/tendermint/tendermint/abci/client"
 	"github.com/tendermint/tendermint/abci/example/kvstore"
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
 	"github.com/tendermint/tendermint/internal/evidence"
 	"github.com/tendermint/tendermint/internal/mempool"
@@ -81,6 +86,60 @@ func TestNodeStartStop(t *testing.T) {
 	require.False(t, n.IsRunning(), "node must shut down")
 }
 
+func TestNodeEncryptionAtRest(t *testing.T) {
+	cfg, err := config.ResetTestRoot("node_encryption_test")
+	require.NoError(t, err)
+	defer os.RemoveAll(cfg.RootDir)
+
+	ctx, bcancel := context.WithCancel(context.Background())
+	defer bcancel()
+
+	logger := log.NewNopLogger()
+
+	cfg.EncryptionKeyProvider = atrest.FileKeyProvider
+	_, err = newDefaultNode(ctx, cfg, logger)
+	require.Error(t, err, "the key file does not exist")
+
+	keyring := atrest.GenKeyring()
+	require.NoError(t, keyring.Save(cfg.EncryptionKeyFile()))
+	ns, err := newDefaultNode(ctx, cfg, logger)
+	require.NoError(t, err)
+	n, ok := ns.(*nodeImpl)
+	require.True(t, ok)
+
+	require.NoError(t, n.Start(ctx))
+	tctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	blocksSub, err := n.EventBus().SubscribeWithArgs(tctx, pubsub.SubscribeArgs{
+		ClientID: "node_test",
+		Query:    types.EventQueryNewBlock,
+	})
+	require.NoError(t, err)
+	_, err = blocksSub.Next(tctx)
+	require.NoError(t, err, "waiting for event")
+
+	// The node reads its encrypted blocks.
+	require.NotNil(t, n.blockStore.LoadBlock(1))
+
+	cancel()
+	bcancel()
+	n.Wait()
+
+	// The WAL can only be read with the key.
+	wal, err := os.Open(cfg.Consensus.WalFile())
+	require.NoError(t, err)
+	defer wal.Close()
+	_, err = consensus.NewWALDecoder(wal).Decode()
+	assert.Error(t, err)
+
+	_, err = wal.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	dec := consensus.NewWALDecoder(wal)
+	dec.SetCipher(atrest.NewCipher(keyring))
+	_, err = dec.Decode()
+	assert.NoError(t, err)
+}
+
 func getTestNode(ctx context.Context, t *testing.T, conf *config.Config, logger log.Logger) *nodeImpl {
 	t.Helper()
 	ctx, cancel := context.WithCancel(ctx)
@@ -336,6 +395,7 @@ func TestCreateProposalBlock(t *testing.T) {
 
 	commit := types.NewCommit(height-1, 0, types.BlockID{}, nil)
 	block, _, err := blockExec.CreateProposalBlock(
+		ctx,
 		height,
 		state, commit,
 		proposerAddr,
@@ -410,6 +470,7 @@ func TestMaxTxsProposalBlockSize(t *testing.T) {
 
 	commit := types.NewCommit(height-1, 0, types.BlockID{}, nil)
 	block, _, err := blockExec.CreateProposalBlock(
+		ctx,
 		height,
 		state, commit,
 		proposerAddr,
@@ -520,6 +581,7 @@ func TestMaxProposalBlockSize(t *testing.T) {
 	}
 
 	block, partSet, err := blockExec.CreateProposalBlock(
+		ctx,
 		math.MaxInt64,
 		state, commit,
 		proposerAddr,
@@ -601,7 +663,7 @@ func TestNodeSetEventSink(t *testing.T) {
 
 		indexService, eventSinks, err := createAndStartIndexerService(ctx, cfg,
 			config.DefaultDBProvider, eventBus, logger, genDoc.ChainID,
-			indexer.NopMetrics())
+			indexer.NopMetrics(), nil)
 		require.NoError(t, err)
 		t.Cleanup(indexService.Wait)
 		return eventSinks
@@ -633,11 +695,12 @@ func TestNodeSetEventSink(t *testing.T) {
 	assert.Equal(t, 1, len(eventSinks))
 	assert.Equal(t, indexer.NULL, eventSinks[0].Type())
 
+	// null is ignored alongside other sinks
 	cfg.TxIndex.Indexer = []string{"null", "kv"}
 	eventSinks = setupTest(t, cfg)
 
 	assert.Equal(t, 1, len(eventSinks))
-	assert.Equal(t, indexer.NULL, eventSinks[0].Type())
+	assert.Equal(t, indexer.KV, eventSinks[0].Type())
 
 	cfg.TxIndex.Indexer = []string{"kvv"}
 	ns, err := newDefaultNode(ctx, cfg, logger)
@@ -739,3 +802,126 @@ func loadStatefromGenesis(ctx context.Context, t *testing.T) sm.State {
 
 	return state
 }
+
+func TestServeReplayStatus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := config.TestConfig()
+	socket := filepath.Join(t.TempDir(), "rpc.sock")
+	cfg.RPC.ListenAddress = "unix://" + socket
+	handshaker := consensus.NewHandshaker(log.NewNopLogger(), nil, sm.State{}, nil, nil, nil)
+
+	stop, err := serveReplayStatus(ctx, cfg, log.NewNopLogger(), handshaker)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	res, err := client.Get("http://unix/replay_status")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), `"target_height":"0"`)
+
+	// The address is released for the RPC server.
+	stop()
+	_, err = os.Stat(socket)
+	assert.True(t, os.IsNotExist(err), err)
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	cfg, err := config.ResetTestRoot("node_integrity_test")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(cfg.RootDir) })
+
+	st, _, _ := state(t, 1, 1)
+	blockStore := store.NewBlockStore(dbm.NewMemDB())
+	commits := make(map[int64]*types.Commit)
+	for h := int64(1); h <= 3; h++ {
+		block, partSet, err := st.MakeBlock(h, nil, new(types.Commit), nil, st.Validators.GetProposer().Address)
+		require.NoError(t, err)
+		commits[h] = &types.Commit{
+			Height:     h,
+			BlockID:    types.BlockID{Hash: block.Hash(), PartSetHeader: partSet.Header()},
+			Signatures: []types.CommitSig{types.NewCommitSigAbsent()},
+		}
+		blockStore.SaveBlock(block, partSet, commits[h])
+	}
+	atHeight := func(height int64) sm.State {
+		s := st.Copy()
+		s.LastBlockHeight = height
+		return s
+	}
+	problems := func(issues []integrityIssue) []string {
+		var out []string
+		for _, issue := range issues {
+			out = append(out, issue.problem)
+		}
+		return out
+	}
+
+	// The handshake replays the last block if the state is one block behind.
+	for _, height := range []int64{2, 3} {
+		issues, err := checkIntegrity(cfg, blockStore, atHeight(height), nil, nil)
+		require.NoError(t, err)
+		require.Empty(t, issues)
+	}
+
+	issues, err := checkIntegrity(cfg, blockStore, atHeight(5), nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"the state is at height 5, ahead of the blockstore at height 3"}, problems(issues))
+	require.Nil(t, issues[0].repair)
+	err = runIntegrityCheck(log.NewNopLogger(), cfg, blockStore, atHeight(5), nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "tendermint unsafe-reset-all --home "+cfg.RootDir)
+	require.Contains(t, err.Error(), "cp "+cfg.PrivValidator.StateFile())
+
+	issues, err = checkIntegrity(cfg, blockStore, atHeight(1), nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"the blockstore is at height 3, more than one block ahead of the state at height 1"},
+		problems(issues))
+
+	// A consensus WAL ahead of the blockstore is moved aside in the repair mode.
+	walFile := cfg.Consensus.WalFile()
+	require.NoError(t, os.MkdirAll(filepath.Dir(walFile), 0700))
+	f, err := os.Create(walFile)
+	require.NoError(t, err)
+	enc := consensus.NewWALEncoder(f)
+	require.NoError(t, enc.Encode(&consensus.TimedWALMessage{Msg: consensus.EndHeightMessage{Height: 3}}))
+	require.NoError(t, enc.Encode(&consensus.TimedWALMessage{Msg: consensus.EndHeightMessage{Height: 4}}))
+	require.NoError(t, f.Close())
+
+	issues, err = checkIntegrity(cfg, blockStore, atHeight(3), nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"the consensus WAL ends at height 4, ahead of the blockstore at height 3"},
+		problems(issues))
+	require.Error(t, runIntegrityCheck(log.NewNopLogger(), cfg, blockStore, atHeight(3), nil, nil))
+	require.FileExists(t, walFile)
+	cfg.IntegrityCheck = config.IntegrityCheckRepair
+	require.NoError(t, runIntegrityCheck(log.NewNopLogger(), cfg, blockStore, atHeight(3), nil, nil))
+	require.NoFileExists(t, walFile)
+	issues, err = checkIntegrity(cfg, blockStore, atHeight(3), nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+
+	// A validator which signed ahead of the blockstore only gets a warning.
+	filePrivval, err := privval.GenFilePV(cfg.PrivValidator.KeyFile(), cfg.PrivValidator.StateFile(), types.ABCIPubKeyTypeEd25519)
+	require.NoError(t, err)
+	filePrivval.LastSignState.Height = 10
+	issues, err = checkIntegrity(cfg, blockStore, atHeight(3), nil, filePrivval)
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	require.True(t, issues[0].warning)
+	require.NoError(t, runIntegrityCheck(log.NewNopLogger(), cfg, blockStore, atHeight(3), nil, filePrivval))
+
+	// The consensus state can't start without the seen commit of the last block.
+	require.NoError(t, blockStore.SaveSeenCommit(2, commits[2]))
+	issues, err = checkIntegrity(cfg, blockStore, atHeight(3), nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"the blockstore is missing the seen commit of the block at height 3"},
+		problems(issues))
+}
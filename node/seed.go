@@ -69,22 +69,27 @@ func makeSeedNode(
 	// Setup Transport and Switch.
 	p2pMetrics := p2p.PrometheusMetrics(cfg.Instrumentation.Namespace, "chain_id", genDoc.ChainID)
 
-	peerManager, closer, err := createPeerManager(cfg, dbProvider, nodeKey.ID)
+	peerManager, closer, err := createPeerManager(cfg, dbProvider, nodeKey.ID, cfg.P2P.ExternalAddress)
 	if err != nil {
 		return nil, combineCloseError(
 			fmt.Errorf("failed to create peer manager: %w", err),
 			closer)
 	}
 
+	peerFilter, err := createPeerFilter(cfg)
+	if err != nil {
+		return nil, combineCloseError(err, closer)
+	}
+
 	router, err := createRouter(ctx, logger, p2pMetrics, nodeInfo, nodeKey,
-		peerManager, cfg, nil)
+		peerManager, cfg, nil, nil, peerFilter, nil, nil)
 	if err != nil {
 		return nil, combineCloseError(
 			fmt.Errorf("failed to create router: %w", err),
 			closer)
 	}
 
-	pexReactor, err := pex.NewReactor(ctx, logger, peerManager, router.OpenChannel, peerManager.Subscribe(ctx))
+	pexReactor, err := pex.NewReactor(ctx, logger, peerManager, router.OpenChannel, peerManager.SubscribeChannel(ctx, pex.PexChannel))
 	if err != nil {
 		return nil, combineCloseError(err, closer)
 	}
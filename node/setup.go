@@ -5,21 +5,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	dbm "github.com/tendermint/tm-db"
 
+	"github.com/tendermint/tendermint/atrest"
 	"github.com/tendermint/tendermint/config"
 	"github.com/tendermint/tendermint/crypto"
 	"github.com/tendermint/tendermint/internal/blocksync"
 	"github.com/tendermint/tendermint/internal/consensus"
 	"github.com/tendermint/tendermint/internal/eventbus"
 	"github.com/tendermint/tendermint/internal/evidence"
+	"github.com/tendermint/tendermint/internal/features"
 	"github.com/tendermint/tendermint/internal/mempool"
 	"github.com/tendermint/tendermint/internal/p2p"
 	"github.com/tendermint/tendermint/internal/p2p/conn"
+	"github.com/tendermint/tendermint/internal/p2p/maintenance"
+	"github.com/tendermint/tendermint/internal/p2p/nat"
 	"github.com/tendermint/tendermint/internal/p2p/pex"
+	"github.com/tendermint/tendermint/internal/p2p/relay"
 	"github.com/tendermint/tendermint/internal/proxy"
 	sm "github.com/tendermint/tendermint/internal/state"
 	"github.com/tendermint/tendermint/internal/state/indexer"
@@ -32,6 +41,8 @@ import (
 	tmstrings "github.com/tendermint/tendermint/libs/strings"
 	"github.com/tendermint/tendermint/privval"
 	tmgrpc "github.com/tendermint/tendermint/privval/grpc"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+	rpcserver "github.com/tendermint/tendermint/rpc/jsonrpc/server"
 	"github.com/tendermint/tendermint/types"
 	"github.com/tendermint/tendermint/version"
 
@@ -103,6 +114,7 @@ func createAndStartIndexerService(
 	logger log.Logger,
 	chainID string,
 	metrics *indexer.Metrics,
+	intentLog indexer.IntentLog,
 ) (*indexer.Service, []indexer.EventSink, error) {
 	eventSinks, err := sink.EventSinksFromConfig(cfg, dbProvider, chainID)
 	if err != nil {
@@ -114,6 +126,9 @@ func createAndStartIndexerService(
 		EventBus: eventBus,
 		Logger:   logger.With("module", "txindex"),
 		Metrics:  metrics,
+
+		IndexCheckTx: cfg.TxIndex.IndexCheckTx,
+		IntentLog:    intentLog,
 	})
 
 	if err := indexerService.Start(ctx); err != nil {
@@ -174,20 +189,34 @@ func createMempoolReactor(
 	proxyApp proxy.AppConns,
 	state sm.State,
 	memplMetrics *mempool.Metrics,
+	eventBus *eventbus.EventBus,
 	peerManager *p2p.PeerManager,
 	router *p2p.Router,
+	featureSet *features.Set,
 	logger log.Logger,
 ) (service.Service, mempool.Mempool, error) {
 	logger = logger.With("module", "mempool")
 
+	filters, err := mempool.TxFiltersFromConfig(cfg.Mempool)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load mempool filters: %w", err)
+	}
+	proposalFilters, err := mempool.ProposalFiltersFromConfig(cfg.Mempool)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load proposal filters: %w", err)
+	}
+
 	mp := mempool.NewTxMempool(
 		logger,
 		cfg.Mempool,
 		proxyApp.Mempool(),
 		state.LastBlockHeight,
 		mempool.WithMetrics(memplMetrics),
+		mempool.WithEventBus(eventBus),
 		mempool.WithPreCheck(sm.TxPreCheck(state)),
 		mempool.WithPostCheck(sm.TxPostCheck(state)),
+		mempool.WithTxFilters(filters...),
+		mempool.WithProposalFilters(proposalFilters...),
 	)
 
 	reactor, err := mempool.NewReactor(
@@ -197,11 +226,12 @@ func createMempoolReactor(
 		peerManager,
 		mp,
 		router.OpenChannel,
-		peerManager.Subscribe(ctx),
+		peerManager.SubscribeChannel(ctx, mempool.MempoolChannel),
 	)
 	if err != nil {
 		return nil, nil, err
 	}
+	reactor.SetFeatures(featureSet)
 
 	if cfg.Consensus.WaitForTxs() {
 		mp.EnableTxsAvailable()
@@ -210,6 +240,38 @@ func createMempoolReactor(
 	return reactor, mp, nil
 }
 
+func createEventLogStore(cfg *config.Config, dbProvider config.DBProvider) (*eventbus.EventLogStore, closer, error) {
+	eventLogDB, err := dbProvider(&config.DBContext{ID: "eventlog", Config: cfg})
+	if err != nil {
+		return nil, func() error { return nil }, fmt.Errorf("unable to initialize event log db: %w", err)
+	}
+	store, err := eventbus.NewEventLogStore(eventLogDB)
+	if err != nil {
+		return nil, eventLogDB.Close, fmt.Errorf("loading the event log: %w", err)
+	}
+	return store, eventLogDB.Close, nil
+}
+
+func createSnapshotService(
+	cfg *config.Config,
+	dbProvider config.DBProvider,
+	conn proxy.AppConnSnapshot,
+	logger log.Logger,
+) (*statesync.SnapshotService, closer, error) {
+	snapshotDB, err := dbProvider(&config.DBContext{ID: "snapshots", Config: cfg})
+	if err != nil {
+		return nil, func() error { return nil }, fmt.Errorf("unable to initialize snapshot db: %w", err)
+	}
+
+	return statesync.NewSnapshotService(
+		logger.With("module", "snapshots"),
+		conn,
+		snapshotDB,
+		cfg.StateSync.SnapshotInterval,
+		cfg.StateSync.SnapshotKeepRecent,
+	), snapshotDB.Close, nil
+}
+
 func createEvidenceReactor(
 	ctx context.Context,
 	cfg *config.Config,
@@ -236,7 +298,7 @@ func createEvidenceReactor(
 		ctx,
 		logger,
 		router.OpenChannel,
-		peerManager.Subscribe(ctx),
+		peerManager.SubscribeChannel(ctx, evidence.EvidenceChannel),
 		evidencePool,
 	)
 	if err != nil {
@@ -260,6 +322,7 @@ func createConsensusReactor(
 	eventBus *eventbus.EventBus,
 	peerManager *p2p.PeerManager,
 	router *p2p.Router,
+	walCipher *atrest.Cipher,
 	logger log.Logger,
 ) (*consensus.Reactor, *consensus.State, error) {
 	logger = logger.With("module", "consensus")
@@ -273,6 +336,7 @@ func createConsensusReactor(
 		mp,
 		evidencePool,
 		consensus.StateMetrics(csMetrics),
+		consensus.StateWALCipher(walCipher),
 	)
 
 	if privValidator != nil && cfg.Mode == config.ModeValidator {
@@ -284,7 +348,7 @@ func createConsensusReactor(
 		logger,
 		consensusState,
 		router.OpenChannel,
-		peerManager.Subscribe(ctx),
+		peerManager.SubscribeChannel(ctx, consensus.StateChannel),
 		waitSync,
 		csMetrics,
 	)
@@ -303,11 +367,12 @@ func createPeerManager(
 	cfg *config.Config,
 	dbProvider config.DBProvider,
 	nodeID types.NodeID,
+	externalAddress string,
 ) (*p2p.PeerManager, closer, error) {
 
-	selfAddr, err := p2p.ParseNodeAddress(nodeID.AddressString(cfg.P2P.ExternalAddress))
+	selfAddr, err := p2p.ParseNodeAddress(nodeID.AddressString(externalAddress))
 	if err != nil {
-		return nil, func() error { return nil }, fmt.Errorf("couldn't parse ExternalAddress %q: %w", cfg.P2P.ExternalAddress, err)
+		return nil, func() error { return nil }, fmt.Errorf("couldn't parse ExternalAddress %q: %w", externalAddress, err)
 	}
 
 	privatePeerIDs := make(map[types.NodeID]struct{})
@@ -325,15 +390,23 @@ func createPeerManager(
 	}
 
 	options := p2p.PeerManagerOptions{
-		SelfAddress:            selfAddr,
-		MaxConnected:           maxConns,
-		MaxConnectedUpgrade:    4,
-		MaxPeers:               1000,
-		MinRetryTime:           100 * time.Millisecond,
-		MaxRetryTime:           8 * time.Hour,
-		MaxRetryTimePersistent: 5 * time.Minute,
-		RetryTimeJitter:        3 * time.Second,
-		PrivatePeers:           privatePeerIDs,
+		SelfAddress:                 selfAddr,
+		MaxConnected:                maxConns,
+		MaxConnectedUpgrade:         4,
+		MaxPeers:                    cfg.P2P.MaxPeers,
+		MaxAddressesPerPeer:         cfg.P2P.MaxAddressesPerPeer,
+		MaxAddressFailures:          cfg.P2P.MaxAddressFailures,
+		StaleAddressTime:            cfg.P2P.StaleAddressTime,
+		GCInterval:                  cfg.P2P.PeerGCInterval,
+		MinRetryTime:                100 * time.Millisecond,
+		MaxRetryTime:                8 * time.Hour,
+		MaxRetryTimePersistent:      5 * time.Minute,
+		RetryTimeJitter:             3 * time.Second,
+		MaxConcurrentDials:          cfg.P2P.MaxConcurrentDials,
+		MaxConcurrentDialsPerSubnet: cfg.P2P.MaxConcurrentDialsPerSubnet,
+		MinDialInterval:             cfg.P2P.DialInterval,
+		PrivatePeers:                privatePeerIDs,
+		MaintenanceAvoidMargin:      cfg.P2P.MaintenanceAvoidMargin,
 	}
 
 	peers := []p2p.NodeAddress{}
@@ -383,6 +456,10 @@ func createRouter(
 	peerManager *p2p.PeerManager,
 	cfg *config.Config,
 	proxyApp proxy.AppConns,
+	validatorBook *p2p.ValidatorBook,
+	peerFilter *p2p.PeerFilter,
+	shedLoad func() bool,
+	listeners []net.Listener,
 ) (*p2p.Router, error) {
 
 	p2pLogger := logger.With("module", "p2p")
@@ -396,6 +473,7 @@ func createRouter(
 		p2pLogger, transportConf, []*p2p.ChannelDescriptor{},
 		p2p.MConnTransportOptions{
 			MaxAcceptedConnections: uint32(cfg.P2P.MaxConnections),
+			Listeners:              listeners,
 		},
 	)
 
@@ -404,6 +482,11 @@ func createRouter(
 		return nil, err
 	}
 
+	options := getRouterConfig(cfg, proxyApp)
+	options.ValidatorBook = validatorBook
+	options.PeerFilter = peerFilter
+	options.ShedLoad = shedLoad
+
 	return p2p.NewRouter(
 		ctx,
 		p2pLogger,
@@ -413,10 +496,117 @@ func createRouter(
 		peerManager,
 		[]p2p.Transport{transport},
 		[]p2p.Endpoint{ep},
-		getRouterConfig(cfg, proxyApp),
+		options,
 	)
 }
 
+// setupNATTraversal makes the p2p port of the node reachable from behind a
+// NAT, unless the external address is set: it maps the port on the gateway if
+// UPnP is enabled, or else reserves a port on the relay if one is set. It
+// returns the external address of the node, and the listener of the relayed
+// connections if any. A failure only leaves the node unreachable, so it is
+// logged.
+func setupNATTraversal(
+	ctx context.Context,
+	logger log.Logger,
+	cfg *config.Config,
+	nodeKey types.NodeKey,
+) (string, []net.Listener, closer) {
+	noop := func() error { return nil }
+	if cfg.P2P.ExternalAddress != "" || (!cfg.P2P.UPNP && cfg.P2P.Relay == "") {
+		return cfg.P2P.ExternalAddress, nil, noop
+	}
+	logger = logger.With("module", "p2p")
+
+	if cfg.P2P.UPNP {
+		mapping, err := mapListenPort(ctx, logger, cfg.P2P.ListenAddress)
+		if err == nil {
+			return mapping.ExternalAddress(), nil, mapping.Close
+		}
+		logger.Error("Failed to map the p2p port on the NAT gateway", "err", err)
+	}
+
+	if cfg.P2P.Relay != "" {
+		listener, err := relay.Listen(ctx, logger, cfg.P2P.Relay, nodeKey.PrivKey)
+		if err == nil {
+			return listener.Address(), []net.Listener{listener}, listener.Close
+		}
+		logger.Error("Failed to reserve a port on the relay", "relay", cfg.P2P.Relay, "err", err)
+	}
+	return "", nil, noop
+}
+
+// natTraversalTimeout bounds the discovery of the NAT gateway.
+const natTraversalTimeout = 10 * time.Second
+
+// mapListenPort maps the port of the p2p listen address on the NAT gateway.
+func mapListenPort(ctx context.Context, logger log.Logger, listenAddress string) (*nat.Mapping, error) {
+	_, address := tmnet.ProtocolAndAddress(listenAddress)
+	_, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port == 0 {
+		return nil, fmt.Errorf("can't map the port of listen address %q", listenAddress)
+	}
+
+	discoverCtx, cancel := context.WithTimeout(ctx, natTraversalTimeout)
+	defer cancel()
+	gateway, err := nat.Discover(discoverCtx)
+	if err != nil {
+		return nil, err
+	}
+	return nat.Map(ctx, logger, gateway, port)
+}
+
+// createRelayServer returns the relay server of the configuration.
+func createRelayServer(logger log.Logger, cfg *config.Config) (*relay.Server, error) {
+	first, last, err := cfg.P2P.RelayPortRange()
+	if err != nil {
+		return nil, err
+	}
+	options := relay.ServerOptions{
+		FirstPort:       first,
+		LastPort:        last,
+		MaxReservations: cfg.P2P.RelayMaxReservations,
+	}
+	for _, id := range tmstrings.SplitAndTrimEmpty(cfg.P2P.RelayAllowedNodeIDs, ",", " ") {
+		nodeID, err := types.NewNodeID(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay-allowed-node-ids: %w", err)
+		}
+		if options.AllowedNodes == nil {
+			options.AllowedNodes = make(map[types.NodeID]bool)
+		}
+		options.AllowedNodes[nodeID] = true
+	}
+	return relay.NewServer(logger.With("module", "relay"), cfg.P2P.RelayServerAddress, options), nil
+}
+
+// createPeerFilter returns the peer filter with the rules of the
+// configuration.
+func createPeerFilter(cfg *config.Config) (*p2p.PeerFilter, error) {
+	var rules []p2p.PeerFilterRule
+	for _, list := range []struct {
+		action p2p.PeerFilterAction
+		kind   p2p.PeerFilterKind
+		values []string
+	}{
+		{p2p.PeerFilterAllow, p2p.PeerFilterCIDR, cfg.P2P.AllowCIDRs},
+		{p2p.PeerFilterDeny, p2p.PeerFilterCIDR, cfg.P2P.DenyCIDRs},
+		{p2p.PeerFilterAllow, p2p.PeerFilterNodeID, cfg.P2P.AllowNodeIDs},
+		{p2p.PeerFilterDeny, p2p.PeerFilterNodeID, cfg.P2P.DenyNodeIDs},
+		{p2p.PeerFilterAllow, p2p.PeerFilterChainID, cfg.P2P.AllowChainIDs},
+		{p2p.PeerFilterDeny, p2p.PeerFilterChainID, cfg.P2P.DenyChainIDs},
+	} {
+		for _, value := range list.values {
+			rules = append(rules, p2p.PeerFilterRule{Action: list.action, Kind: list.kind, Value: value})
+		}
+	}
+	return p2p.NewPeerFilter(rules)
+}
+
 func makeNodeInfo(
 	cfg *config.Config,
 	nodeKey types.NodeKey,
@@ -452,6 +642,7 @@ func makeNodeInfo(
 			byte(statesync.ChunkChannel),
 			byte(statesync.LightBlockChannel),
 			byte(statesync.ParamsChannel),
+			byte(maintenance.MaintenanceChannel),
 		},
 		Moniker: cfg.Moniker,
 		Other: types.NodeInfoOther{
@@ -472,6 +663,24 @@ func makeNodeInfo(
 	return nodeInfo, nodeInfo.Validate()
 }
 
+// attestValidator returns the attestation that the validator runs the node,
+// signed with the local private validator key.
+func attestValidator(
+	cfg *config.Config,
+	nodeKey types.NodeKey,
+	genDoc *types.GenesisDoc,
+	filePrivval *privval.FilePV,
+) (*types.ValidatorAttestation, error) {
+	if cfg.Mode != config.ModeValidator || filePrivval == nil || cfg.PrivValidator.ListenAddr != "" {
+		return nil, errors.New("p2p.attest-validator requires a validator with a local private validator key")
+	}
+	att, err := types.SignValidatorAttestation(genDoc.ChainID, nodeKey.ID, filePrivval.Key.PrivKey)
+	if err != nil {
+		return nil, fmt.Errorf("attesting the validator: %w", err)
+	}
+	return att, nil
+}
+
 func makeSeedNodeInfo(
 	cfg *config.Config,
 	nodeKey types.NodeKey,
@@ -606,3 +815,69 @@ func createPrivval(ctx context.Context, logger log.Logger, conf *config.Config,
 
 	return defaultPV, nil
 }
+
+// serveReplayStatus serves the replay_status method on the RPC listen
+// addresses until stop is called, to report the progress of the handshake,
+// which replays the stored blocks into the app before the RPC server starts.
+// Nothing is served with ACME, whose certificates are obtained by the RPC
+// server.
+func serveReplayStatus(
+	ctx context.Context,
+	cfg *config.Config,
+	logger log.Logger,
+	handshaker *consensus.Handshaker,
+) (stop func(), err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	stop = func() {
+		cancel()
+		wg.Wait()
+	}
+	if cfg.RPC.IsACMEEnabled() {
+		return stop, nil
+	}
+
+	routes := map[string]*rpcserver.RPCFunc{
+		"replay_status": rpcserver.NewRPCFunc(func(ctx context.Context) (*coretypes.ResultReplayStatus, error) {
+			progress := handshaker.Progress()
+			return &coretypes.ResultReplayStatus{
+				StartHeight:   progress.StartHeight,
+				TargetHeight:  progress.TargetHeight,
+				Height:        progress.Height,
+				StartTime:     progress.StartTime,
+				RemainingTime: progress.Remaining,
+			}, nil
+		}),
+	}
+	rpcConfig := rpcserver.DefaultConfig()
+	rpcConfig.MaxOpenConnections = cfg.RPC.MaxOpenConnections
+	if rpcConfig.UnixSocketMode, err = cfg.RPC.UnixSocketFileMode(); err != nil {
+		stop()
+		return nil, err
+	}
+
+	for _, listenAddr := range tmstrings.SplitAndTrimEmpty(cfg.RPC.ListenAddress, ",", " ") {
+		listener, err := rpcserver.ListenWithConfig(listenAddr, rpcConfig)
+		if err != nil {
+			stop()
+			return nil, err
+		}
+		mux := http.NewServeMux()
+		rpcserver.RegisterRPCFuncs(mux, routes, logger)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// The listener is closed when the server stops, and released
+			// for the RPC server.
+			defer listener.Close()
+			if cfg.RPC.IsTLSEnabled() {
+				_ = rpcserver.ServeTLS(ctx, listener, mux,
+					cfg.RPC.CertFile(), cfg.RPC.KeyFile(), logger, rpcConfig)
+			} else {
+				_ = rpcserver.Serve(ctx, listener, mux, logger, rpcConfig)
+			}
+		}()
+	}
+	return stop, nil
+}
@@ -0,0 +1,445 @@
+// Package conformance checks that a remote signer implementation speaks the
+// privval protocol the way Tendermint expects, so that third-party signers
+// (e.g. HSM bridges) can be validated before they are used on a live network.
+//
+// The Harness plays the role of the node: it listens for the signer to
+// connect, sends it requests and checks the responses, including that the
+// signer refuses to double sign and reconnects after losing its connection.
+// The signer under test must use a throwaway key, since the harness makes it
+// sign votes and proposals at arbitrary heights.
+//
+// The package also provides a fault-injecting request handler, used by the
+// signer simulator to exercise the node side against slow, flaky and
+// misbehaving signers.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/privval"
+	privvalproto "github.com/tendermint/tendermint/proto/tendermint/privval"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Config configures a conformance run.
+type Config struct {
+	// ChainID is the chain ID the signer is configured with.
+	ChainID string
+
+	// StartHeight is the first height signed during the run. It must be
+	// above any height the signer's key has signed before; defaults to the
+	// current Unix time, which keeps consecutive runs increasing.
+	StartHeight int64
+
+	// MaxLatency is the longest acceptable response time of the signer.
+	MaxLatency time.Duration
+
+	// ConnectTimeout is how long to wait for the signer to (re)connect.
+	ConnectTimeout time.Duration
+}
+
+// DefaultConfig returns the default configuration of a conformance run.
+func DefaultConfig(chainID string) Config {
+	return Config{
+		ChainID:        chainID,
+		MaxLatency:     time.Second,
+		ConnectTimeout: 30 * time.Second,
+	}
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name string
+	// Err is nil if the check passed.
+	Err error
+	// Latency is the slowest response received during the check.
+	Latency time.Duration
+}
+
+// Passed returns true if the check passed.
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+type check struct {
+	name string
+	run  func(context.Context, *Harness) error
+}
+
+var checks = []check{
+	{"ping", checkPing},
+	{"public key", checkPubKey},
+	{"reject wrong chain ID", checkWrongChainID},
+	{"sign proposal and votes", checkSign},
+	{"re-sign identical vote", checkResign},
+	{"refuse conflicting vote", checkConflictingVote},
+	{"refuse conflicting proposal", checkConflictingProposal},
+	{"refuse height regression", checkHeightRegression},
+	{"reconnect after disconnect", checkReconnect},
+}
+
+// Harness runs the conformance checks against a signer connecting to its
+// endpoint.
+type Harness struct {
+	logger   log.Logger
+	cfg      Config
+	endpoint *privval.SignerListenerEndpoint
+
+	pubKey  crypto.PubKey
+	height  int64
+	latency time.Duration // slowest response of the current check
+}
+
+// NewHarness returns a harness running the checks over endpoint.
+func NewHarness(logger log.Logger, endpoint *privval.SignerListenerEndpoint, cfg Config) *Harness {
+	if cfg.StartHeight <= 0 {
+		cfg.StartHeight = time.Now().Unix()
+	}
+	return &Harness{
+		logger:   logger,
+		cfg:      cfg,
+		endpoint: endpoint,
+		height:   cfg.StartHeight,
+	}
+}
+
+// Run waits for the signer to connect and runs all the checks. It returns an
+// error if the signer never connects; the outcome of each check is reported
+// in the results.
+func (h *Harness) Run(ctx context.Context) ([]Result, error) {
+	if !h.endpoint.IsRunning() {
+		if err := h.endpoint.Start(ctx); err != nil {
+			return nil, fmt.Errorf("failed to start listener endpoint: %w", err)
+		}
+	}
+	if err := h.endpoint.WaitForConnection(ctx, h.cfg.ConnectTimeout); err != nil {
+		return nil, fmt.Errorf("signer did not connect: %w", err)
+	}
+
+	results := make([]Result, 0, len(checks))
+	for _, c := range checks {
+		h.latency = 0
+		err := c.run(ctx, h)
+		if err == nil && h.cfg.MaxLatency > 0 && h.latency > h.cfg.MaxLatency {
+			err = fmt.Errorf("slowest response took %s, more than %s", h.latency, h.cfg.MaxLatency)
+		}
+		h.logger.Info("conformance check", "check", c.name, "passed", err == nil, "latency", h.latency, "err", err)
+		results = append(results, Result{Name: c.name, Err: err, Latency: h.latency})
+
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+	}
+	return results, nil
+}
+
+// request sends msg to the signer and returns its response. If the request
+// fails, the connection is dropped and the signer is given the chance to
+// reconnect, so that the following checks can go on.
+func (h *Harness) request(ctx context.Context, msg proto.Message) (*privvalproto.Message, error) {
+	start := time.Now()
+	res, err := h.endpoint.SendRequest(ctx, wrapMsg(msg))
+	if d := time.Since(start); d > h.latency {
+		h.latency = d
+	}
+	if err != nil {
+		h.endpoint.DropConnection()
+		if werr := h.endpoint.WaitForConnection(ctx, h.cfg.ConnectTimeout); werr != nil {
+			h.logger.Error("signer did not reconnect", "err", werr)
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func (h *Harness) nextHeight() int64 {
+	h.height++
+	return h.height
+}
+
+func (h *Harness) getPubKey(ctx context.Context, chainID string) (crypto.PubKey, error) {
+	res, err := h.request(ctx, &privvalproto.PubKeyRequest{ChainId: chainID})
+	if err != nil {
+		return nil, err
+	}
+	resp := res.GetPubKeyResponse()
+	if resp == nil {
+		return nil, unexpectedResponse(res)
+	}
+	if resp.Error != nil {
+		return nil, remoteError(resp.Error)
+	}
+	return encoding.PubKeyFromProto(resp.PubKey)
+}
+
+// verifyingKey returns the public key the signatures are verified with,
+// requesting it from the signer if the public key check did not get it: a
+// signature is never accepted unverified.
+func (h *Harness) verifyingKey(ctx context.Context) (crypto.PubKey, error) {
+	if h.pubKey != nil {
+		return h.pubKey, nil
+	}
+	pubKey, err := h.getPubKey(ctx, h.cfg.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("getting the public key to verify the signature: %w", err)
+	}
+	return pubKey, nil
+}
+
+// signVote requests a signature of vote and verifies it.
+func (h *Harness) signVote(ctx context.Context, chainID string, vote *tmproto.Vote) (*tmproto.Vote, error) {
+	res, err := h.request(ctx, &privvalproto.SignVoteRequest{Vote: vote, ChainId: chainID})
+	if err != nil {
+		return nil, err
+	}
+	resp := res.GetSignedVoteResponse()
+	if resp == nil {
+		return nil, unexpectedResponse(res)
+	}
+	if resp.Error != nil {
+		return nil, remoteError(resp.Error)
+	}
+
+	signed := resp.Vote
+	if signed.Type != vote.Type || signed.Height != vote.Height || signed.Round != vote.Round ||
+		!bytes.Equal(signed.BlockID.Hash, vote.BlockID.Hash) {
+		return nil, fmt.Errorf("signed vote %v differs from requested vote %v", &signed, vote)
+	}
+	pubKey, err := h.verifyingKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !pubKey.VerifySignature(types.VoteSignBytes(h.cfg.ChainID, &signed), signed.Signature) {
+		return nil, errors.New("invalid vote signature")
+	}
+	return &signed, nil
+}
+
+// signProposal requests a signature of proposal and verifies it.
+func (h *Harness) signProposal(ctx context.Context, proposal *tmproto.Proposal) (*tmproto.Proposal, error) {
+	res, err := h.request(ctx, &privvalproto.SignProposalRequest{Proposal: proposal, ChainId: h.cfg.ChainID})
+	if err != nil {
+		return nil, err
+	}
+	resp := res.GetSignedProposalResponse()
+	if resp == nil {
+		return nil, unexpectedResponse(res)
+	}
+	if resp.Error != nil {
+		return nil, remoteError(resp.Error)
+	}
+
+	signed := resp.Proposal
+	if signed.Height != proposal.Height || signed.Round != proposal.Round ||
+		!bytes.Equal(signed.BlockID.Hash, proposal.BlockID.Hash) {
+		return nil, fmt.Errorf("signed proposal %v differs from requested proposal %v", &signed, proposal)
+	}
+	pubKey, err := h.verifyingKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !pubKey.VerifySignature(types.ProposalSignBytes(h.cfg.ChainID, &signed), signed.Signature) {
+		return nil, errors.New("invalid proposal signature")
+	}
+	return &signed, nil
+}
+
+func (h *Harness) makeVote(msgType tmproto.SignedMsgType, height int64, blockID tmproto.BlockID) *tmproto.Vote {
+	var addr []byte
+	if h.pubKey != nil {
+		addr = h.pubKey.Address()
+	}
+	return &tmproto.Vote{
+		Type:             msgType,
+		Height:           height,
+		BlockID:          blockID,
+		Timestamp:        time.Now().UTC(),
+		ValidatorAddress: addr,
+	}
+}
+
+func makeProposal(height int64, blockID tmproto.BlockID) *tmproto.Proposal {
+	return &tmproto.Proposal{
+		Type:      tmproto.ProposalType,
+		Height:    height,
+		PolRound:  -1,
+		BlockID:   blockID,
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+func makeBlockID(seed string) tmproto.BlockID {
+	return tmproto.BlockID{
+		Hash: tmhash.Sum([]byte(seed)),
+		PartSetHeader: tmproto.PartSetHeader{
+			Total: 1,
+			Hash:  tmhash.Sum([]byte(seed + "/parts")),
+		},
+	}
+}
+
+func checkPing(ctx context.Context, h *Harness) error {
+	res, err := h.request(ctx, &privvalproto.PingRequest{})
+	if err != nil {
+		return err
+	}
+	// A ping response has no content: anything else, including an empty
+	// message, is malformed.
+	if res.GetPingResponse() == nil {
+		return unexpectedResponse(res)
+	}
+	return nil
+}
+
+func checkPubKey(ctx context.Context, h *Harness) error {
+	pubKey, err := h.getPubKey(ctx, h.cfg.ChainID)
+	if err != nil {
+		return err
+	}
+	again, err := h.getPubKey(ctx, h.cfg.ChainID)
+	if err != nil {
+		return err
+	}
+	if !pubKey.Equals(again) {
+		return fmt.Errorf("public key changed from %X to %X", pubKey.Bytes(), again.Bytes())
+	}
+	h.pubKey = pubKey
+	return nil
+}
+
+func checkWrongChainID(ctx context.Context, h *Harness) error {
+	const otherChainID = "conformance-other-chain"
+
+	if _, err := h.getPubKey(ctx, otherChainID); !isRemoteError(err) {
+		return fmt.Errorf("public key request for another chain: expected a signer error, got %v", err)
+	}
+	vote := h.makeVote(tmproto.PrevoteType, h.nextHeight(), makeBlockID("wrong chain"))
+	if _, err := h.signVote(ctx, otherChainID, vote); !isRemoteError(err) {
+		return fmt.Errorf("vote for another chain: expected a signer error, got %v", err)
+	}
+	return nil
+}
+
+func checkSign(ctx context.Context, h *Harness) error {
+	height := h.nextHeight()
+	blockID := makeBlockID("sign")
+
+	if _, err := h.signProposal(ctx, makeProposal(height, blockID)); err != nil {
+		return fmt.Errorf("proposal: %w", err)
+	}
+	if _, err := h.signVote(ctx, h.cfg.ChainID, h.makeVote(tmproto.PrevoteType, height, blockID)); err != nil {
+		return fmt.Errorf("prevote: %w", err)
+	}
+	if _, err := h.signVote(ctx, h.cfg.ChainID, h.makeVote(tmproto.PrecommitType, height, blockID)); err != nil {
+		return fmt.Errorf("precommit: %w", err)
+	}
+	return nil
+}
+
+func checkResign(ctx context.Context, h *Harness) error {
+	vote := h.makeVote(tmproto.PrevoteType, h.nextHeight(), makeBlockID("resign"))
+	if _, err := h.signVote(ctx, h.cfg.ChainID, vote); err != nil {
+		return err
+	}
+	// The node asks again for a signature it lost, e.g. after a crash.
+	vote.Signature = nil
+	if _, err := h.signVote(ctx, h.cfg.ChainID, vote); err != nil {
+		return fmt.Errorf("re-signing identical vote: %w", err)
+	}
+	return nil
+}
+
+func checkConflictingVote(ctx context.Context, h *Harness) error {
+	height := h.nextHeight()
+	if _, err := h.signVote(ctx, h.cfg.ChainID, h.makeVote(tmproto.PrevoteType, height, makeBlockID("vote A"))); err != nil {
+		return err
+	}
+	_, err := h.signVote(ctx, h.cfg.ChainID, h.makeVote(tmproto.PrevoteType, height, makeBlockID("vote B")))
+	if !isRemoteError(err) {
+		return fmt.Errorf("conflicting vote: expected a signer error, got %v", err)
+	}
+	return nil
+}
+
+func checkConflictingProposal(ctx context.Context, h *Harness) error {
+	height := h.nextHeight()
+	if _, err := h.signProposal(ctx, makeProposal(height, makeBlockID("proposal A"))); err != nil {
+		return err
+	}
+	_, err := h.signProposal(ctx, makeProposal(height, makeBlockID("proposal B")))
+	if !isRemoteError(err) {
+		return fmt.Errorf("conflicting proposal: expected a signer error, got %v", err)
+	}
+	return nil
+}
+
+func checkHeightRegression(ctx context.Context, h *Harness) error {
+	height := h.nextHeight()
+	if _, err := h.signVote(ctx, h.cfg.ChainID, h.makeVote(tmproto.PrecommitType, height, makeBlockID("regression"))); err != nil {
+		return err
+	}
+	vote := h.makeVote(tmproto.PrevoteType, height-1, makeBlockID("regression"))
+	if _, err := h.signVote(ctx, h.cfg.ChainID, vote); !isRemoteError(err) {
+		return fmt.Errorf("vote at a lower height: expected a signer error, got %v", err)
+	}
+	return nil
+}
+
+func checkReconnect(ctx context.Context, h *Harness) error {
+	h.endpoint.DropConnection()
+	start := time.Now()
+	if err := h.endpoint.WaitForConnection(ctx, h.cfg.ConnectTimeout); err != nil {
+		return fmt.Errorf("signer did not reconnect: %w", err)
+	}
+	h.logger.Info("signer reconnected", "after", time.Since(start))
+	return checkPing(ctx, h)
+}
+
+func wrapMsg(pb proto.Message) privvalproto.Message {
+	msg := privvalproto.Message{}
+	switch pb := pb.(type) {
+	case *privvalproto.PubKeyRequest:
+		msg.Sum = &privvalproto.Message_PubKeyRequest{PubKeyRequest: pb}
+	case *privvalproto.PubKeyResponse:
+		msg.Sum = &privvalproto.Message_PubKeyResponse{PubKeyResponse: pb}
+	case *privvalproto.SignVoteRequest:
+		msg.Sum = &privvalproto.Message_SignVoteRequest{SignVoteRequest: pb}
+	case *privvalproto.SignedVoteResponse:
+		msg.Sum = &privvalproto.Message_SignedVoteResponse{SignedVoteResponse: pb}
+	case *privvalproto.SignProposalRequest:
+		msg.Sum = &privvalproto.Message_SignProposalRequest{SignProposalRequest: pb}
+	case *privvalproto.SignedProposalResponse:
+		msg.Sum = &privvalproto.Message_SignedProposalResponse{SignedProposalResponse: pb}
+	case *privvalproto.PingRequest:
+		msg.Sum = &privvalproto.Message_PingRequest{PingRequest: pb}
+	case *privvalproto.PingResponse:
+		msg.Sum = &privvalproto.Message_PingResponse{PingResponse: pb}
+	default:
+		panic(fmt.Errorf("unknown message type %T", pb))
+	}
+	return msg
+}
+
+func unexpectedResponse(res *privvalproto.Message) error {
+	return fmt.Errorf("unexpected response %T", res.Sum)
+}
+
+func remoteError(err *privvalproto.RemoteSignerError) error {
+	return &privval.RemoteSignerError{Code: int(err.Code), Description: err.Description}
+}
+
+func isRemoteError(err error) bool {
+	var rerr *privval.RemoteSignerError
+	return errors.As(err, &rerr)
+}
@@ -0,0 +1,100 @@
+package conformance
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/privval"
+	"github.com/tendermint/tendermint/types"
+)
+
+const testChainID = "conformance-test"
+
+// runHarness runs the harness against a simulated signer serving privVal
+// with the given faults.
+func runHarness(t *testing.T, privVal types.PrivValidator, faults Faults, cfg Config) map[string]Result {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewNopLogger()
+	addr := filepath.Join(t.TempDir(), "signer.sock")
+
+	ln, err := net.Listen("unix", addr)
+	require.NoError(t, err)
+	listener := privval.NewSignerListenerEndpoint(logger, privval.NewUnixListener(ln),
+		privval.SignerListenerEndpointTimeoutReadWrite(time.Second))
+
+	dialer := privval.NewSignerDialerEndpoint(logger, privval.DialUnixFn(addr),
+		privval.SignerDialerEndpointConnRetries(1000),
+		privval.SignerDialerEndpointRetryWaitInterval(10*time.Millisecond),
+	)
+	require.NoError(t, dialer.Start(ctx))
+	server := privval.NewSignerServer(dialer, testChainID, privVal)
+	server.SetRequestHandler(NewFaultyRequestHandler(faults, dialer))
+	require.NoError(t, server.Start(ctx))
+
+	results, err := NewHarness(logger, listener, cfg).Run(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, len(checks))
+
+	byName := make(map[string]Result, len(results))
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	cancel()
+	server.Wait()
+	listener.Wait()
+	return byName
+}
+
+func TestHarness(t *testing.T) {
+	cfg := DefaultConfig(testChainID)
+	cfg.ConnectTimeout = 5 * time.Second
+
+	t.Run("FilePV", func(t *testing.T) {
+		dir := t.TempDir()
+		pv, err := privval.GenFilePV(filepath.Join(dir, "key.json"), filepath.Join(dir, "state.json"), "")
+		require.NoError(t, err)
+
+		for name, r := range runHarness(t, pv, Faults{}, cfg) {
+			require.True(t, r.Passed(), "%s: %v", name, r.Err)
+		}
+	})
+
+	t.Run("DoubleSign", func(t *testing.T) {
+		results := runHarness(t, types.NewMockPV(), Faults{}, cfg)
+		require.True(t, results["sign proposal and votes"].Passed())
+		require.False(t, results["refuse conflicting vote"].Passed())
+		require.False(t, results["refuse conflicting proposal"].Passed())
+		require.False(t, results["refuse height regression"].Passed())
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		results := runHarness(t, types.NewMockPV(), Faults{MalformedRate: 1}, cfg)
+		require.False(t, results["ping"].Passed())
+		require.False(t, results["sign proposal and votes"].Passed())
+	})
+
+	t.Run("Latency", func(t *testing.T) {
+		cfg := cfg
+		cfg.MaxLatency = 10 * time.Millisecond
+		results := runHarness(t, types.NewMockPV(), Faults{Latency: 20 * time.Millisecond}, cfg)
+		require.False(t, results["ping"].Passed())
+		require.GreaterOrEqual(t, results["ping"].Latency, 20*time.Millisecond)
+	})
+
+	t.Run("Disconnects", func(t *testing.T) {
+		results := runHarness(t, types.NewMockPV(), Faults{DisconnectEvery: 3}, cfg)
+		require.False(t, results["public key"].Passed())
+		require.True(t, results["reconnect after disconnect"].Passed())
+	})
+}
@@ -0,0 +1,118 @@
+package conformance
+
+import (
+	"context"
+	mrand "math/rand"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/privval"
+	cryptoproto "github.com/tendermint/tendermint/proto/tendermint/crypto"
+	privvalproto "github.com/tendermint/tendermint/proto/tendermint/privval"
+	"github.com/tendermint/tendermint/types"
+)
+
+// Faults configures the faults injected by a simulated signer.
+type Faults struct {
+	// Latency is added to every response.
+	Latency time.Duration
+	// Jitter is the maximum random latency added on top of Latency.
+	Jitter time.Duration
+	// DisconnectEvery drops the connection instead of answering every
+	// DisconnectEvery-th request. Zero disables disconnects.
+	DisconnectEvery int
+	// MalformedRate is the fraction of requests answered with a malformed
+	// response: either a response of the wrong type, or one whose content is
+	// corrupted, e.g. its signature or public key. Every type of response
+	// can be malformed.
+	MalformedRate float64
+}
+
+// Dropper drops the connection of a signer endpoint.
+type Dropper interface {
+	DropConnection()
+}
+
+// NewFaultyRequestHandler returns a request handler answering requests like
+// privval.DefaultValidationRequestHandler, with the given faults injected. A
+// signer that double signs can be simulated by serving a types.MockPV, which
+// signs everything it is asked to.
+func NewFaultyRequestHandler(faults Faults, conn Dropper) privval.ValidationRequestHandlerFunc {
+	var (
+		mtx      sync.Mutex
+		requests int
+		rng      = mrand.New(mrand.NewSource(time.Now().UnixNano())) // nolint:gosec // G404: simulation only
+	)
+
+	return func(
+		ctx context.Context,
+		privVal types.PrivValidator,
+		req privvalproto.Message,
+		chainID string,
+	) (privvalproto.Message, error) {
+		mtx.Lock()
+		requests++
+		disconnect := faults.DisconnectEvery > 0 && requests%faults.DisconnectEvery == 0
+		malformed := rng.Float64() < faults.MalformedRate
+		corrupt := rng.Intn(2) == 0
+		delay := faults.Latency
+		if faults.Jitter > 0 {
+			delay += time.Duration(rng.Int63n(int64(faults.Jitter)))
+		}
+		mtx.Unlock()
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return privvalproto.Message{}, ctx.Err()
+			}
+		}
+
+		res, err := privval.DefaultValidationRequestHandler(ctx, privVal, req, chainID)
+
+		if disconnect {
+			// The response is written to the dropped connection, and lost.
+			conn.DropConnection()
+			return res, err
+		}
+		if malformed {
+			if corrupt && corruptContent(&res) {
+				return res, err
+			}
+			// Answer with a response of the wrong type.
+			if _, ok := res.Sum.(*privvalproto.Message_PingResponse); ok {
+				return wrapMsg(&privvalproto.PubKeyResponse{}), err
+			}
+			return wrapMsg(&privvalproto.PingResponse{}), err
+		}
+		return res, err
+	}
+}
+
+// corruptContent corrupts the content of res: it flips a bit of the
+// signature of a signed vote or proposal, and drops the key of a public key
+// response. It returns false if res has no content to corrupt, e.g. a ping
+// or error response.
+func corruptContent(res *privvalproto.Message) bool {
+	var sig []byte
+	switch r := res.Sum.(type) {
+	case *privvalproto.Message_PubKeyResponse:
+		if r.PubKeyResponse.Error != nil {
+			return false
+		}
+		r.PubKeyResponse.PubKey = cryptoproto.PublicKey{}
+		return true
+	case *privvalproto.Message_SignedVoteResponse:
+		sig = r.SignedVoteResponse.Vote.Signature
+	case *privvalproto.Message_SignedProposalResponse:
+		sig = r.SignedProposalResponse.Proposal.Signature
+	}
+	if len(sig) == 0 {
+		return false
+	}
+	sig[0] ^= 0x01
+	return true
+}
@@ -70,6 +70,9 @@ func (ss *SignerServer) servicePendingRequest(ctx context.Context) {
 		if err != io.EOF {
 			ss.endpoint.logger.Error("SignerServer: HandleMessage", "err", err)
 		}
+		// Drop the connection, so that the next iteration dials the node
+		// again instead of reading from a closed connection.
+		ss.endpoint.DropConnection()
 		return
 	}
 
@@ -36,6 +36,9 @@ func (m *Message) Wrap(pb proto.Message) error {
 	case *VoteSetBits:
 		m.Sum = &Message_VoteSetBits{VoteSetBits: msg}
 
+	case *GossipFeedback:
+		m.Sum = &Message_GossipFeedback{GossipFeedback: msg}
+
 	default:
 		return fmt.Errorf("unknown message: %T", msg)
 	}
@@ -74,6 +77,9 @@ func (m *Message) Unwrap() (proto.Message, error) {
 	case *Message_VoteSetBits:
 		return m.GetVoteSetBits(), nil
 
+	case *Message_GossipFeedback:
+		return m.GetGossipFeedback(), nil
+
 	default:
 		return nil, fmt.Errorf("unknown message: %T", msg)
 	}
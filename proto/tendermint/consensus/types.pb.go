@@ -623,6 +623,7 @@ type Message struct {
 	//	*Message_HasVote
 	//	*Message_VoteSetMaj23
 	//	*Message_VoteSetBits
+	//	*Message_GossipFeedback
 	Sum isMessage_Sum `protobuf_oneof:"sum"`
 }
 
@@ -692,16 +693,20 @@ type Message_VoteSetMaj23 struct {
 type Message_VoteSetBits struct {
 	VoteSetBits *VoteSetBits `protobuf:"bytes,9,opt,name=vote_set_bits,json=voteSetBits,proto3,oneof" json:"vote_set_bits,omitempty"`
 }
+type Message_GossipFeedback struct {
+	GossipFeedback *GossipFeedback `protobuf:"bytes,10,opt,name=gossip_feedback,json=gossipFeedback,proto3,oneof" json:"gossip_feedback,omitempty"`
+}
 
-func (*Message_NewRoundStep) isMessage_Sum()  {}
-func (*Message_NewValidBlock) isMessage_Sum() {}
-func (*Message_Proposal) isMessage_Sum()      {}
-func (*Message_ProposalPol) isMessage_Sum()   {}
-func (*Message_BlockPart) isMessage_Sum()     {}
-func (*Message_Vote) isMessage_Sum()          {}
-func (*Message_HasVote) isMessage_Sum()       {}
-func (*Message_VoteSetMaj23) isMessage_Sum()  {}
-func (*Message_VoteSetBits) isMessage_Sum()   {}
+func (*Message_NewRoundStep) isMessage_Sum()   {}
+func (*Message_NewValidBlock) isMessage_Sum()  {}
+func (*Message_Proposal) isMessage_Sum()       {}
+func (*Message_ProposalPol) isMessage_Sum()    {}
+func (*Message_BlockPart) isMessage_Sum()      {}
+func (*Message_Vote) isMessage_Sum()           {}
+func (*Message_HasVote) isMessage_Sum()        {}
+func (*Message_VoteSetMaj23) isMessage_Sum()   {}
+func (*Message_VoteSetBits) isMessage_Sum()    {}
+func (*Message_GossipFeedback) isMessage_Sum() {}
 
 func (m *Message) GetSum() isMessage_Sum {
 	if m != nil {
@@ -773,6 +778,13 @@ func (m *Message) GetVoteSetBits() *VoteSetBits {
 	return nil
 }
 
+func (m *Message) GetGossipFeedback() *GossipFeedback {
+	if x, ok := m.GetSum().(*Message_GossipFeedback); ok {
+		return x.GossipFeedback
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Message) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -785,7 +797,62 @@ func (*Message) XXX_OneofWrappers() []interface{} {
 		(*Message_HasVote)(nil),
 		(*Message_VoteSetMaj23)(nil),
 		(*Message_VoteSetBits)(nil),
+		(*Message_GossipFeedback)(nil),
+	}
+}
+
+// GossipFeedback is sent periodically to a peer to report the share of the
+// votes and block parts it sent recently that we already had.
+type GossipFeedback struct {
+	DuplicateVotesPct      uint32 `protobuf:"varint,1,opt,name=duplicate_votes_pct,json=duplicateVotesPct,proto3" json:"duplicate_votes_pct,omitempty"`
+	DuplicateBlockPartsPct uint32 `protobuf:"varint,2,opt,name=duplicate_block_parts_pct,json=duplicateBlockPartsPct,proto3" json:"duplicate_block_parts_pct,omitempty"`
+}
+
+func (m *GossipFeedback) Reset()         { *m = GossipFeedback{} }
+func (m *GossipFeedback) String() string { return proto.CompactTextString(m) }
+func (*GossipFeedback) ProtoMessage()    {}
+func (*GossipFeedback) Descriptor() ([]byte, []int) {
+	return fileDescriptor_81a22d2efc008981, []int{10}
+}
+func (m *GossipFeedback) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *GossipFeedback) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_GossipFeedback.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *GossipFeedback) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GossipFeedback.Merge(m, src)
+}
+func (m *GossipFeedback) XXX_Size() int {
+	return m.Size()
+}
+func (m *GossipFeedback) XXX_DiscardUnknown() {
+	xxx_messageInfo_GossipFeedback.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GossipFeedback proto.InternalMessageInfo
+
+func (m *GossipFeedback) GetDuplicateVotesPct() uint32 {
+	if m != nil {
+		return m.DuplicateVotesPct
 	}
+	return 0
+}
+
+func (m *GossipFeedback) GetDuplicateBlockPartsPct() uint32 {
+	if m != nil {
+		return m.DuplicateBlockPartsPct
+	}
+	return 0
 }
 
 func init() {
@@ -799,66 +866,71 @@ func init() {
 	proto.RegisterType((*VoteSetMaj23)(nil), "tendermint.consensus.VoteSetMaj23")
 	proto.RegisterType((*VoteSetBits)(nil), "tendermint.consensus.VoteSetBits")
 	proto.RegisterType((*Message)(nil), "tendermint.consensus.Message")
+	proto.RegisterType((*GossipFeedback)(nil), "tendermint.consensus.GossipFeedback")
 }
 
 func init() { proto.RegisterFile("tendermint/consensus/types.proto", fileDescriptor_81a22d2efc008981) }
 
 var fileDescriptor_81a22d2efc008981 = []byte{
-	// 853 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x56, 0x4f, 0x8f, 0xdb, 0x44,
-	0x14, 0xb7, 0x59, 0x67, 0x93, 0x7d, 0xde, 0xec, 0xc2, 0x68, 0x5b, 0x85, 0x00, 0x49, 0x30, 0x97,
-	0x15, 0x42, 0x0e, 0xca, 0x1e, 0x90, 0x0a, 0x12, 0x60, 0xfe, 0xd4, 0xad, 0x9a, 0x36, 0x72, 0x4a,
-	0x85, 0xb8, 0x58, 0x4e, 0x3c, 0x4a, 0x86, 0xc6, 0x1e, 0xcb, 0x33, 0xc9, 0xb2, 0x57, 0x3e, 0x01,
-	0x1f, 0x80, 0xaf, 0x81, 0xc4, 0x47, 0xe8, 0xb1, 0x47, 0x4e, 0x15, 0xca, 0x7e, 0x04, 0x04, 0x67,
-	0x34, 0xe3, 0x49, 0x3c, 0xa1, 0xde, 0x85, 0xbd, 0x20, 0xf5, 0x36, 0xe3, 0xf7, 0xde, 0x6f, 0xde,
-	0xfc, 0xde, 0x7b, 0x3f, 0x0f, 0xf4, 0x38, 0x4e, 0x63, 0x9c, 0x27, 0x24, 0xe5, 0xfd, 0x29, 0x4d,
-	0x19, 0x4e, 0xd9, 0x92, 0xf5, 0xf9, 0x45, 0x86, 0x99, 0x9b, 0xe5, 0x94, 0x53, 0x74, 0x52, 0x7a,
-	0xb8, 0x5b, 0x8f, 0xf6, 0xc9, 0x8c, 0xce, 0xa8, 0x74, 0xe8, 0x8b, 0x55, 0xe1, 0xdb, 0x7e, 0x5b,
-	0x43, 0x93, 0x18, 0x3a, 0x52, 0x5b, 0x3f, 0x6b, 0x41, 0x26, 0xac, 0x3f, 0x21, 0x7c, 0xc7, 0xc3,
-	0xf9, 0xc5, 0x84, 0xc3, 0x87, 0xf8, 0x3c, 0xa0, 0xcb, 0x34, 0x1e, 0x73, 0x9c, 0xa1, 0xdb, 0xb0,
-	0x3f, 0xc7, 0x64, 0x36, 0xe7, 0x2d, 0xb3, 0x67, 0x9e, 0xee, 0x05, 0x6a, 0x87, 0x4e, 0xa0, 0x96,
-	0x0b, 0xa7, 0xd6, 0x6b, 0x3d, 0xf3, 0xb4, 0x16, 0x14, 0x1b, 0x84, 0xc0, 0x62, 0x1c, 0x67, 0xad,
-	0xbd, 0x9e, 0x79, 0xda, 0x0c, 0xe4, 0x1a, 0x7d, 0x04, 0x2d, 0x86, 0xa7, 0x34, 0x8d, 0x59, 0xc8,
-	0x48, 0x3a, 0xc5, 0x21, 0xe3, 0x51, 0xce, 0x43, 0x4e, 0x12, 0xdc, 0xb2, 0x24, 0xe6, 0x2d, 0x65,
-	0x1f, 0x0b, 0xf3, 0x58, 0x58, 0x1f, 0x93, 0x04, 0xa3, 0xf7, 0xe1, 0x8d, 0x45, 0xc4, 0x78, 0x38,
-	0xa5, 0x49, 0x42, 0x78, 0x58, 0x1c, 0x57, 0x93, 0xc7, 0x1d, 0x0b, 0xc3, 0x17, 0xf2, 0xbb, 0x4c,
-	0xd5, 0xf9, 0xd3, 0x84, 0xe6, 0x43, 0x7c, 0xfe, 0x24, 0x5a, 0x90, 0xd8, 0x5b, 0xd0, 0xe9, 0xd3,
-	0x1b, 0x26, 0xfe, 0x2d, 0xdc, 0x9a, 0x88, 0xb0, 0x30, 0x13, 0xb9, 0x31, 0xcc, 0xc3, 0x39, 0x8e,
-	0x62, 0x9c, 0xcb, 0x9b, 0xd8, 0x83, 0xae, 0xab, 0xd5, 0xa0, 0xe0, 0x6b, 0x14, 0xe5, 0x7c, 0x8c,
-	0xb9, 0x2f, 0xdd, 0x3c, 0xeb, 0xd9, 0x8b, 0xae, 0x11, 0x20, 0x89, 0xb1, 0x63, 0x41, 0x9f, 0x82,
-	0x5d, 0x22, 0x33, 0x79, 0x63, 0x7b, 0xd0, 0xd1, 0xf1, 0x44, 0x25, 0x5c, 0x51, 0x09, 0xd7, 0x23,
-	0xfc, 0xf3, 0x3c, 0x8f, 0x2e, 0x02, 0xd8, 0x02, 0x31, 0xf4, 0x16, 0x1c, 0x10, 0xa6, 0x48, 0x90,
-	0xd7, 0x6f, 0x04, 0x0d, 0xc2, 0x8a, 0xcb, 0x3b, 0x3e, 0x34, 0x46, 0x39, 0xcd, 0x28, 0x8b, 0x16,
-	0xe8, 0x13, 0x68, 0x64, 0x6a, 0x2d, 0xef, 0x6c, 0x0f, 0xda, 0x15, 0x69, 0x2b, 0x0f, 0x95, 0xf1,
-	0x36, 0xc2, 0xf9, 0xd9, 0x04, 0x7b, 0x63, 0x1c, 0x3d, 0x7a, 0x70, 0x25, 0x7f, 0x1f, 0x00, 0xda,
-	0xc4, 0x84, 0x19, 0x5d, 0x84, 0x3a, 0x99, 0xaf, 0x6f, 0x2c, 0x23, 0xba, 0x90, 0x75, 0x41, 0x77,
-	0xe1, 0x50, 0xf7, 0x56, 0x74, 0xfe, 0xcb, 0xf5, 0x55, 0x6e, 0xb6, 0x86, 0xe6, 0x3c, 0x85, 0x03,
-	0x6f, 0xc3, 0xc9, 0x0d, 0x6b, 0xfb, 0x21, 0x58, 0x82, 0x7b, 0x75, 0xf6, 0xed, 0xea, 0x52, 0xaa,
-	0x33, 0xa5, 0xa7, 0x33, 0x00, 0xeb, 0x09, 0xe5, 0xa2, 0x03, 0xad, 0x15, 0xe5, 0x58, 0xb1, 0x59,
-	0x11, 0x29, 0xbc, 0x02, 0xe9, 0xe3, 0xfc, 0x68, 0x42, 0xdd, 0x8f, 0x98, 0x8c, 0xbb, 0x59, 0x7e,
-	0x67, 0x60, 0x09, 0x34, 0x99, 0xdf, 0x51, 0x55, 0xab, 0x8d, 0xc9, 0x2c, 0xc5, 0xf1, 0x90, 0xcd,
-	0x1e, 0x5f, 0x64, 0x38, 0x90, 0xce, 0x02, 0x8a, 0xa4, 0x31, 0xfe, 0x41, 0x36, 0x54, 0x2d, 0x28,
-	0x36, 0xce, 0xaf, 0x26, 0x1c, 0x8a, 0x0c, 0xc6, 0x98, 0x0f, 0xa3, 0xef, 0x07, 0x67, 0xff, 0x47,
-	0x26, 0x5f, 0x41, 0xa3, 0x68, 0x70, 0x12, 0xab, 0xee, 0x7e, 0xf3, 0xe5, 0x40, 0x59, 0xbb, 0x7b,
-	0x5f, 0x7a, 0xc7, 0x82, 0xe5, 0xf5, 0x8b, 0x6e, 0x5d, 0x7d, 0x08, 0xea, 0x32, 0xf6, 0x5e, 0xec,
-	0xfc, 0x61, 0x82, 0xad, 0x52, 0xf7, 0x08, 0x67, 0xaf, 0x4e, 0xe6, 0xe8, 0x0e, 0xd4, 0x44, 0x07,
-	0x30, 0x39, 0x9c, 0xff, 0xb5, 0xb9, 0x8b, 0x10, 0xe7, 0x2f, 0x0b, 0xea, 0x43, 0xcc, 0x58, 0x34,
-	0xc3, 0xe8, 0x3e, 0x1c, 0xa5, 0xf8, 0xbc, 0x18, 0xa8, 0x50, 0xca, 0x68, 0xd1, 0x77, 0x8e, 0x5b,
-	0xf5, 0x03, 0x70, 0x75, 0x99, 0xf6, 0x8d, 0xe0, 0x30, 0xd5, 0x65, 0x7b, 0x08, 0xc7, 0x02, 0x6b,
-	0x25, 0xf4, 0x30, 0x94, 0x89, 0x4a, 0xbe, 0xec, 0xc1, 0x7b, 0x57, 0x82, 0x95, 0xda, 0xe9, 0x1b,
-	0x41, 0x33, 0xdd, 0x11, 0x53, 0x5d, 0x5a, 0x2a, 0x46, 0xb8, 0xc4, 0xd9, 0x28, 0x88, 0xaf, 0x49,
-	0x0b, 0xfa, 0xfa, 0x1f, 0x22, 0x50, 0x70, 0xfd, 0xee, 0xf5, 0x08, 0xa3, 0x47, 0x0f, 0xfc, 0x5d,
-	0x0d, 0x40, 0x9f, 0x01, 0x94, 0x52, 0xaa, 0xd8, 0xee, 0x56, 0xa3, 0x6c, 0xb5, 0xc2, 0x37, 0x82,
-	0x83, 0xad, 0x98, 0x0a, 0x29, 0x90, 0x03, 0xbd, 0xff, 0xb2, 0x3c, 0x96, 0xb1, 0xa2, 0x0b, 0x7d,
-	0xa3, 0x18, 0x6b, 0x74, 0x07, 0x1a, 0xf3, 0x88, 0x85, 0x32, 0xaa, 0x2e, 0xa3, 0xde, 0xa9, 0x8e,
-	0x52, 0xb3, 0xef, 0x1b, 0x41, 0x7d, 0xae, 0x64, 0xe0, 0x3e, 0x1c, 0x89, 0x38, 0xf9, 0x3b, 0x49,
-	0xc4, 0x38, 0xb6, 0x1a, 0xd7, 0x15, 0x54, 0x1f, 0x5c, 0x51, 0xd0, 0x95, 0x3e, 0xc8, 0x77, 0xa1,
-	0xb9, 0xc5, 0x12, 0xfd, 0xd4, 0x3a, 0xb8, 0x8e, 0x44, 0x6d, 0x90, 0x04, 0x89, 0xab, 0x72, 0xeb,
-	0xd5, 0x60, 0x8f, 0x2d, 0x13, 0xef, 0x9b, 0x67, 0xeb, 0x8e, 0xf9, 0x7c, 0xdd, 0x31, 0x7f, 0x5f,
-	0x77, 0xcc, 0x9f, 0x2e, 0x3b, 0xc6, 0xf3, 0xcb, 0x8e, 0xf1, 0xdb, 0x65, 0xc7, 0xf8, 0xee, 0xe3,
-	0x19, 0xe1, 0xf3, 0xe5, 0xc4, 0x9d, 0xd2, 0xa4, 0xaf, 0xbf, 0x26, 0xca, 0x65, 0xf1, 0xea, 0xa8,
-	0x7a, 0xb7, 0x4c, 0xf6, 0xa5, 0xed, 0xec, 0xef, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc7, 0x5c, 0x91,
-	0x04, 0xd6, 0x08, 0x00, 0x00,
+	// 928 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xd4, 0x56, 0xcf, 0x6e, 0xe3, 0x44,
+	0x18, 0xb7, 0xb7, 0x49, 0x93, 0x7e, 0x69, 0x5a, 0x76, 0xe8, 0x56, 0xde, 0x02, 0x69, 0x31, 0x1c,
+	0x2a, 0x84, 0x12, 0x94, 0x1e, 0x10, 0x0b, 0x12, 0x10, 0x60, 0xeb, 0x45, 0xdb, 0x6d, 0xe4, 0x2c,
+	0x2b, 0xc4, 0xc5, 0x72, 0xec, 0xc1, 0x19, 0x9a, 0x78, 0x2c, 0xcf, 0xa4, 0xa5, 0xe2, 0xc6, 0x13,
+	0xf0, 0x00, 0xbc, 0x06, 0x12, 0x6f, 0xc0, 0x1e, 0xf7, 0xc8, 0x69, 0x85, 0xda, 0x47, 0x40, 0xdc,
+	0xd1, 0x7c, 0x76, 0xec, 0x09, 0x75, 0x2b, 0x7a, 0x41, 0xe2, 0x36, 0x33, 0xdf, 0xef, 0xf7, 0x9b,
+	0x6f, 0xbe, 0x7f, 0x36, 0xec, 0x49, 0x1a, 0x87, 0x34, 0x9d, 0xb1, 0x58, 0xf6, 0x02, 0x1e, 0x0b,
+	0x1a, 0x8b, 0xb9, 0xe8, 0xc9, 0xf3, 0x84, 0x8a, 0x6e, 0x92, 0x72, 0xc9, 0xc9, 0x56, 0x89, 0xe8,
+	0x16, 0x88, 0x9d, 0xad, 0x88, 0x47, 0x1c, 0x01, 0x3d, 0xb5, 0xca, 0xb0, 0x3b, 0xaf, 0x6b, 0x6a,
+	0xa8, 0xa1, 0x2b, 0xed, 0xe8, 0x77, 0x4d, 0xd9, 0x58, 0xf4, 0xc6, 0x4c, 0x2e, 0x21, 0xec, 0x5f,
+	0x4c, 0x58, 0x7f, 0x42, 0xcf, 0x5c, 0x3e, 0x8f, 0xc3, 0x91, 0xa4, 0x09, 0xd9, 0x86, 0xd5, 0x09,
+	0x65, 0xd1, 0x44, 0x5a, 0xe6, 0x9e, 0xb9, 0xbf, 0xe2, 0xe6, 0x3b, 0xb2, 0x05, 0xf5, 0x54, 0x81,
+	0xac, 0x3b, 0x7b, 0xe6, 0x7e, 0xdd, 0xcd, 0x36, 0x84, 0x40, 0x4d, 0x48, 0x9a, 0x58, 0x2b, 0x7b,
+	0xe6, 0x7e, 0xdb, 0xc5, 0x35, 0x79, 0x1f, 0x2c, 0x41, 0x03, 0x1e, 0x87, 0xc2, 0x13, 0x2c, 0x0e,
+	0xa8, 0x27, 0xa4, 0x9f, 0x4a, 0x4f, 0xb2, 0x19, 0xb5, 0x6a, 0xa8, 0x79, 0x2f, 0xb7, 0x8f, 0x94,
+	0x79, 0xa4, 0xac, 0x4f, 0xd9, 0x8c, 0x92, 0x77, 0xe0, 0xee, 0xd4, 0x17, 0xd2, 0x0b, 0xf8, 0x6c,
+	0xc6, 0xa4, 0x97, 0x5d, 0x57, 0xc7, 0xeb, 0x36, 0x95, 0xe1, 0x33, 0x3c, 0x47, 0x57, 0xed, 0xbf,
+	0x4c, 0x68, 0x3f, 0xa1, 0x67, 0xcf, 0xfc, 0x29, 0x0b, 0x07, 0x53, 0x1e, 0x9c, 0xdc, 0xd2, 0xf1,
+	0xaf, 0xe1, 0xde, 0x58, 0xd1, 0xbc, 0x44, 0xf9, 0x26, 0xa8, 0xf4, 0x26, 0xd4, 0x0f, 0x69, 0x8a,
+	0x2f, 0x69, 0xf5, 0x77, 0xbb, 0x5a, 0x0e, 0xb2, 0x78, 0x0d, 0xfd, 0x54, 0x8e, 0xa8, 0x74, 0x10,
+	0x36, 0xa8, 0x3d, 0x7f, 0xb9, 0x6b, 0xb8, 0x04, 0x35, 0x96, 0x2c, 0xe4, 0x63, 0x68, 0x95, 0xca,
+	0x02, 0x5f, 0xdc, 0xea, 0x77, 0x74, 0x3d, 0x95, 0x89, 0xae, 0xca, 0x44, 0x77, 0xc0, 0xe4, 0xa7,
+	0x69, 0xea, 0x9f, 0xbb, 0x50, 0x08, 0x09, 0xf2, 0x1a, 0xac, 0x31, 0x91, 0x07, 0x01, 0x9f, 0xdf,
+	0x74, 0x9b, 0x4c, 0x64, 0x8f, 0xb7, 0x1d, 0x68, 0x0e, 0x53, 0x9e, 0x70, 0xe1, 0x4f, 0xc9, 0x47,
+	0xd0, 0x4c, 0xf2, 0x35, 0xbe, 0xb9, 0xd5, 0xdf, 0xa9, 0x70, 0x3b, 0x47, 0xe4, 0x1e, 0x17, 0x0c,
+	0xfb, 0x67, 0x13, 0x5a, 0x0b, 0xe3, 0xf0, 0xf8, 0xf1, 0xb5, 0xf1, 0x7b, 0x17, 0xc8, 0x82, 0xe3,
+	0x25, 0x7c, 0xea, 0xe9, 0xc1, 0x7c, 0x65, 0x61, 0x19, 0xf2, 0x29, 0xe6, 0x85, 0x1c, 0xc2, 0xba,
+	0x8e, 0xb6, 0x56, 0xfe, 0xcd, 0xf3, 0x73, 0xdf, 0x5a, 0x9a, 0x9a, 0x7d, 0x02, 0x6b, 0x83, 0x45,
+	0x4c, 0x6e, 0x99, 0xdb, 0xf7, 0xa0, 0xa6, 0x62, 0x9f, 0xdf, 0xbd, 0x5d, 0x9d, 0xca, 0xfc, 0x4e,
+	0x44, 0xda, 0x7d, 0xa8, 0x3d, 0xe3, 0x52, 0x55, 0x60, 0xed, 0x94, 0x4b, 0x6a, 0x99, 0xd7, 0x31,
+	0x15, 0xca, 0x45, 0x8c, 0xfd, 0xa3, 0x09, 0x0d, 0xc7, 0x17, 0xc8, 0xbb, 0x9d, 0x7f, 0x07, 0x50,
+	0x53, 0x6a, 0xe8, 0xdf, 0x46, 0x55, 0xa9, 0x8d, 0x58, 0x14, 0xd3, 0xf0, 0x48, 0x44, 0x4f, 0xcf,
+	0x13, 0xea, 0x22, 0x58, 0x49, 0xb1, 0x38, 0xa4, 0xdf, 0x63, 0x41, 0xd5, 0xdd, 0x6c, 0x63, 0xff,
+	0x6a, 0xc2, 0xba, 0xf2, 0x60, 0x44, 0xe5, 0x91, 0xff, 0x5d, 0xff, 0xe0, 0xbf, 0xf0, 0xe4, 0x0b,
+	0x68, 0x66, 0x05, 0xce, 0xc2, 0xbc, 0xba, 0xef, 0x5f, 0x25, 0x62, 0xee, 0x1e, 0x7d, 0x3e, 0xd8,
+	0x54, 0x51, 0xbe, 0x78, 0xb9, 0xdb, 0xc8, 0x0f, 0xdc, 0x06, 0x72, 0x1f, 0x85, 0xf6, 0x9f, 0x26,
+	0xb4, 0x72, 0xd7, 0x07, 0x4c, 0x8a, 0xff, 0x8f, 0xe7, 0xe4, 0x01, 0xd4, 0x55, 0x05, 0x08, 0xab,
+	0x7e, 0x8b, 0xe2, 0xce, 0x28, 0xf6, 0x6f, 0x75, 0x68, 0x1c, 0x51, 0x21, 0xfc, 0x88, 0x92, 0x2f,
+	0x61, 0x23, 0xa6, 0x67, 0x59, 0x43, 0x79, 0x38, 0x46, 0xb3, 0xba, 0xb3, 0xbb, 0x55, 0x1f, 0x80,
+	0xae, 0x3e, 0xa6, 0x1d, 0xc3, 0x5d, 0x8f, 0xb5, 0x3d, 0x39, 0x82, 0x4d, 0xa5, 0x75, 0xaa, 0xe6,
+	0xa1, 0x87, 0x8e, 0x62, 0xbc, 0x5a, 0xfd, 0xb7, 0xae, 0x15, 0x2b, 0x67, 0xa7, 0x63, 0xb8, 0xed,
+	0x58, 0x3f, 0x58, 0x1a, 0x2d, 0x15, 0x2d, 0x5c, 0xea, 0x2c, 0x26, 0x88, 0xa3, 0x8d, 0x16, 0xf2,
+	0xf0, 0x1f, 0x43, 0x20, 0x8b, 0xf5, 0x9b, 0x37, 0x2b, 0x0c, 0x8f, 0x1f, 0x3b, 0xcb, 0x33, 0x80,
+	0x7c, 0x02, 0x50, 0x8e, 0xd2, 0x3c, 0xda, 0xbb, 0xd5, 0x2a, 0xc5, 0xac, 0x70, 0x0c, 0x77, 0xad,
+	0x18, 0xa6, 0x6a, 0x14, 0x60, 0x43, 0xaf, 0x5e, 0x1d, 0x8f, 0x25, 0x57, 0x55, 0xa1, 0x63, 0x64,
+	0x6d, 0x4d, 0x1e, 0x40, 0x73, 0xe2, 0x0b, 0x0f, 0x59, 0x0d, 0x64, 0xbd, 0x51, 0xcd, 0xca, 0x7b,
+	0xdf, 0x31, 0xdc, 0xc6, 0x24, 0x5b, 0xaa, 0x84, 0x2a, 0x1e, 0x7e, 0x4e, 0x66, 0xaa, 0x1d, 0xad,
+	0xe6, 0x4d, 0x09, 0xd5, 0x1b, 0x57, 0x25, 0xf4, 0x54, 0x6f, 0xe4, 0x43, 0x68, 0x17, 0x5a, 0xaa,
+	0x9e, 0xac, 0xb5, 0x9b, 0x82, 0xa8, 0x35, 0x92, 0x0a, 0xe2, 0x69, 0xb9, 0x25, 0xc7, 0xb0, 0x19,
+	0x71, 0x21, 0x58, 0xe2, 0x7d, 0x4b, 0x69, 0x38, 0xf6, 0x83, 0x13, 0x0b, 0x50, 0xea, 0xed, 0x6a,
+	0xa9, 0x43, 0x04, 0x3f, 0xcc, 0xb1, 0x8e, 0xe1, 0x6e, 0x44, 0x4b, 0x27, 0x83, 0x3a, 0xac, 0x88,
+	0xf9, 0xcc, 0xfe, 0x01, 0x36, 0x96, 0xa1, 0xa4, 0x0b, 0xaf, 0x86, 0xf3, 0x64, 0xca, 0x02, 0x5f,
+	0x52, 0x0c, 0xa0, 0xf0, 0x92, 0x20, 0x6b, 0xe7, 0xb6, 0x7b, 0xb7, 0x30, 0x29, 0x5f, 0xc5, 0x30,
+	0x90, 0xe4, 0x03, 0xb8, 0x5f, 0xe2, 0xb5, 0x6f, 0x26, 0xb2, 0xee, 0x20, 0x6b, 0xbb, 0x00, 0x14,
+	0x09, 0x56, 0xd4, 0xc1, 0x57, 0xcf, 0x2f, 0x3a, 0xe6, 0x8b, 0x8b, 0x8e, 0xf9, 0xc7, 0x45, 0xc7,
+	0xfc, 0xe9, 0xb2, 0x63, 0xbc, 0xb8, 0xec, 0x18, 0xbf, 0x5f, 0x76, 0x8c, 0x6f, 0x3e, 0x8c, 0x98,
+	0x9c, 0xcc, 0xc7, 0xdd, 0x80, 0xcf, 0x7a, 0xfa, 0xbf, 0x51, 0xb9, 0xcc, 0xfe, 0xa1, 0xaa, 0xfe,
+	0xc2, 0xc6, 0xab, 0x68, 0x3b, 0xf8, 0x7b, 0x00, 0x19, 0x8d, 0x27, 0xbf, 0xa4, 0x09, 0x00, 0x00,
 }
 
 func (m *NewRoundStep) Marshal() (dAtA []byte, err error) {
@@ -1498,6 +1570,60 @@ func (m *Message_VoteSetBits) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	return len(dAtA) - i, nil
 }
+func (m *Message_GossipFeedback) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Message_GossipFeedback) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.GossipFeedback != nil {
+		{
+			size, err := m.GossipFeedback.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x52
+	}
+	return len(dAtA) - i, nil
+}
+func (m *GossipFeedback) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *GossipFeedback) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *GossipFeedback) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.DuplicateBlockPartsPct != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.DuplicateBlockPartsPct))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.DuplicateVotesPct != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.DuplicateVotesPct))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintTypes(dAtA []byte, offset int, v uint64) int {
 	offset -= sovTypes(v)
 	base := offset
@@ -1798,6 +1924,32 @@ func (m *Message_VoteSetBits) Size() (n int) {
 	}
 	return n
 }
+func (m *Message_GossipFeedback) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.GossipFeedback != nil {
+		l = m.GossipFeedback.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+func (m *GossipFeedback) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.DuplicateVotesPct != 0 {
+		n += 1 + sovTypes(uint64(m.DuplicateVotesPct))
+	}
+	if m.DuplicateBlockPartsPct != 0 {
+		n += 1 + sovTypes(uint64(m.DuplicateBlockPartsPct))
+	}
+	return n
+}
 
 func sovTypes(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
@@ -3321,6 +3473,129 @@ func (m *Message) Unmarshal(dAtA []byte) error {
 			}
 			m.Sum = &Message_VoteSetBits{v}
 			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GossipFeedback", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &GossipFeedback{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Sum = &Message_GossipFeedback{v}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *GossipFeedback) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: GossipFeedback: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: GossipFeedback: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DuplicateVotesPct", wireType)
+			}
+			m.DuplicateVotesPct = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DuplicateVotesPct |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DuplicateBlockPartsPct", wireType)
+			}
+			m.DuplicateBlockPartsPct = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DuplicateBlockPartsPct |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -9,6 +9,7 @@ import (
 	proto "github.com/gogo/protobuf/proto"
 	_ "github.com/gogo/protobuf/types"
 	github_com_gogo_protobuf_types "github.com/gogo/protobuf/types"
+	crypto "github.com/tendermint/tendermint/proto/tendermint/crypto"
 	io "io"
 	math "math"
 	math_bits "math/bits"
@@ -96,6 +97,11 @@ type NodeInfo struct {
 	Channels        []byte          `protobuf:"bytes,6,opt,name=channels,proto3" json:"channels,omitempty"`
 	Moniker         string          `protobuf:"bytes,7,opt,name=moniker,proto3" json:"moniker,omitempty"`
 	Other           NodeInfoOther   `protobuf:"bytes,8,opt,name=other,proto3" json:"other"`
+	// The largest message accepted on each channel, for the channels which
+	// declare one.
+	ChannelLimits []ChannelLimit `protobuf:"bytes,9,rep,name=channel_limits,json=channelLimits,proto3" json:"channel_limits"`
+	// The attestation of the validator running the node, if it publishes one.
+	ValidatorAttestation *ValidatorAttestation `protobuf:"bytes,10,opt,name=validator_attestation,json=validatorAttestation,proto3" json:"validator_attestation,omitempty"`
 }
 
 func (m *NodeInfo) Reset()         { *m = NodeInfo{} }
@@ -187,6 +193,127 @@ func (m *NodeInfo) GetOther() NodeInfoOther {
 	return NodeInfoOther{}
 }
 
+func (m *NodeInfo) GetChannelLimits() []ChannelLimit {
+	if m != nil {
+		return m.ChannelLimits
+	}
+	return nil
+}
+
+func (m *NodeInfo) GetValidatorAttestation() *ValidatorAttestation {
+	if m != nil {
+		return m.ValidatorAttestation
+	}
+	return nil
+}
+
+// ValidatorAttestation binds a node to a validator: the validator signs the
+// node ID with its consensus key.
+type ValidatorAttestation struct {
+	PubKey    crypto.PublicKey `protobuf:"bytes,1,opt,name=pub_key,json=pubKey,proto3" json:"pub_key"`
+	Signature []byte           `protobuf:"bytes,2,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *ValidatorAttestation) Reset()         { *m = ValidatorAttestation{} }
+func (m *ValidatorAttestation) String() string { return proto.CompactTextString(m) }
+func (*ValidatorAttestation) ProtoMessage()    {}
+func (*ValidatorAttestation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c8a29e659aeca578, []int{2}
+}
+func (m *ValidatorAttestation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ValidatorAttestation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ValidatorAttestation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ValidatorAttestation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ValidatorAttestation.Merge(m, src)
+}
+func (m *ValidatorAttestation) XXX_Size() int {
+	return m.Size()
+}
+func (m *ValidatorAttestation) XXX_DiscardUnknown() {
+	xxx_messageInfo_ValidatorAttestation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ValidatorAttestation proto.InternalMessageInfo
+
+func (m *ValidatorAttestation) GetPubKey() crypto.PublicKey {
+	if m != nil {
+		return m.PubKey
+	}
+	return crypto.PublicKey{}
+}
+
+func (m *ValidatorAttestation) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// ChannelLimit is the size of the largest message accepted on a channel.
+type ChannelLimit struct {
+	ChannelID  uint32 `protobuf:"varint,1,opt,name=channel_id,json=channelId,proto3" json:"channel_id,omitempty"`
+	MaxMsgSize uint32 `protobuf:"varint,2,opt,name=max_msg_size,json=maxMsgSize,proto3" json:"max_msg_size,omitempty"`
+}
+
+func (m *ChannelLimit) Reset()         { *m = ChannelLimit{} }
+func (m *ChannelLimit) String() string { return proto.CompactTextString(m) }
+func (*ChannelLimit) ProtoMessage()    {}
+func (*ChannelLimit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c8a29e659aeca578, []int{3}
+}
+func (m *ChannelLimit) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ChannelLimit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ChannelLimit.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ChannelLimit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChannelLimit.Merge(m, src)
+}
+func (m *ChannelLimit) XXX_Size() int {
+	return m.Size()
+}
+func (m *ChannelLimit) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChannelLimit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ChannelLimit proto.InternalMessageInfo
+
+func (m *ChannelLimit) GetChannelID() uint32 {
+	if m != nil {
+		return m.ChannelID
+	}
+	return 0
+}
+
+func (m *ChannelLimit) GetMaxMsgSize() uint32 {
+	if m != nil {
+		return m.MaxMsgSize
+	}
+	return 0
+}
+
 type NodeInfoOther struct {
 	TxIndex    string `protobuf:"bytes,1,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
 	RPCAddress string `protobuf:"bytes,2,opt,name=rpc_address,json=rpcAddress,proto3" json:"rpc_address,omitempty"`
@@ -196,7 +323,7 @@ func (m *NodeInfoOther) Reset()         { *m = NodeInfoOther{} }
 func (m *NodeInfoOther) String() string { return proto.CompactTextString(m) }
 func (*NodeInfoOther) ProtoMessage()    {}
 func (*NodeInfoOther) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c8a29e659aeca578, []int{2}
+	return fileDescriptor_c8a29e659aeca578, []int{4}
 }
 func (m *NodeInfoOther) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -249,7 +376,7 @@ func (m *PeerInfo) Reset()         { *m = PeerInfo{} }
 func (m *PeerInfo) String() string { return proto.CompactTextString(m) }
 func (*PeerInfo) ProtoMessage()    {}
 func (*PeerInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c8a29e659aeca578, []int{3}
+	return fileDescriptor_c8a29e659aeca578, []int{5}
 }
 func (m *PeerInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -310,7 +437,7 @@ func (m *PeerAddressInfo) Reset()         { *m = PeerAddressInfo{} }
 func (m *PeerAddressInfo) String() string { return proto.CompactTextString(m) }
 func (*PeerAddressInfo) ProtoMessage()    {}
 func (*PeerAddressInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_c8a29e659aeca578, []int{4}
+	return fileDescriptor_c8a29e659aeca578, []int{6}
 }
 func (m *PeerAddressInfo) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -370,6 +497,8 @@ func (m *PeerAddressInfo) GetDialFailures() uint32 {
 func init() {
 	proto.RegisterType((*ProtocolVersion)(nil), "tendermint.p2p.ProtocolVersion")
 	proto.RegisterType((*NodeInfo)(nil), "tendermint.p2p.NodeInfo")
+	proto.RegisterType((*ValidatorAttestation)(nil), "tendermint.p2p.ValidatorAttestation")
+	proto.RegisterType((*ChannelLimit)(nil), "tendermint.p2p.ChannelLimit")
 	proto.RegisterType((*NodeInfoOther)(nil), "tendermint.p2p.NodeInfoOther")
 	proto.RegisterType((*PeerInfo)(nil), "tendermint.p2p.PeerInfo")
 	proto.RegisterType((*PeerAddressInfo)(nil), "tendermint.p2p.PeerAddressInfo")
@@ -378,46 +507,57 @@ func init() {
 func init() { proto.RegisterFile("tendermint/p2p/types.proto", fileDescriptor_c8a29e659aeca578) }
 
 var fileDescriptor_c8a29e659aeca578 = []byte{
-	// 610 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xcd, 0x4e, 0x1b, 0x3d,
-	0x14, 0xcd, 0x24, 0x21, 0x09, 0x37, 0x84, 0xf0, 0x59, 0xe8, 0xd3, 0x10, 0xa9, 0x19, 0x14, 0x36,
-	0xac, 0x26, 0x52, 0xaa, 0x2e, 0xba, 0x64, 0x40, 0xad, 0x22, 0x55, 0x25, 0x9a, 0xa2, 0x2e, 0xda,
-	0xc5, 0x68, 0x32, 0x76, 0x82, 0xc5, 0xc4, 0xb6, 0x3c, 0x4e, 0x4b, 0xdf, 0x82, 0x37, 0xe9, 0x63,
-	0x94, 0x25, 0xcb, 0xae, 0xd2, 0x6a, 0xd8, 0xf6, 0x21, 0x2a, 0xdb, 0x33, 0x40, 0xa2, 0x2e, 0xd8,
-	0xf9, 0xdc, 0xe3, 0x73, 0xee, 0x8f, 0xad, 0x0b, 0x3d, 0x45, 0x18, 0x26, 0x72, 0x41, 0x99, 0x1a,
-	0x8a, 0x91, 0x18, 0xaa, 0x6f, 0x82, 0x64, 0xbe, 0x90, 0x5c, 0x71, 0xb4, 0xfb, 0xc8, 0xf9, 0x62,
-	0x24, 0x7a, 0xfb, 0x73, 0x3e, 0xe7, 0x86, 0x1a, 0xea, 0x93, 0xbd, 0xd5, 0xf3, 0xe6, 0x9c, 0xcf,
-	0x53, 0x32, 0x34, 0x68, 0xba, 0x9c, 0x0d, 0x15, 0x5d, 0x90, 0x4c, 0xc5, 0x0b, 0x61, 0x2f, 0x0c,
-	0x2e, 0xa0, 0x3b, 0xd1, 0x87, 0x84, 0xa7, 0x1f, 0x89, 0xcc, 0x28, 0x67, 0xe8, 0x00, 0x6a, 0x62,
-	0x24, 0x5c, 0xe7, 0xd0, 0x39, 0xae, 0x07, 0xcd, 0x7c, 0xe5, 0xd5, 0x26, 0xa3, 0x49, 0xa8, 0x63,
-	0x68, 0x1f, 0xb6, 0xa6, 0x29, 0x4f, 0xae, 0xdc, 0xaa, 0x26, 0x43, 0x0b, 0xd0, 0x1e, 0xd4, 0x62,
-	0x21, 0xdc, 0x9a, 0x89, 0xe9, 0xe3, 0xe0, 0x47, 0x15, 0x5a, 0xef, 0x39, 0x26, 0x63, 0x36, 0xe3,
-	0x68, 0x02, 0x7b, 0xa2, 0x48, 0x11, 0x7d, 0xb1, 0x39, 0x8c, 0x79, 0x7b, 0xe4, 0xf9, 0xeb, 0x4d,
-	0xf8, 0x1b, 0xa5, 0x04, 0xf5, 0xdb, 0x95, 0x57, 0x09, 0xbb, 0x62, 0xa3, 0xc2, 0x23, 0x68, 0x32,
-	0x8e, 0x49, 0x44, 0xb1, 0x29, 0x64, 0x3b, 0x80, 0x7c, 0xe5, 0x35, 0x4c, 0xc2, 0xb3, 0xb0, 0xa1,
-	0xa9, 0x31, 0x46, 0x1e, 0xb4, 0x53, 0x9a, 0x29, 0xc2, 0xa2, 0x18, 0x63, 0x69, 0xaa, 0xdb, 0x0e,
-	0xc1, 0x86, 0x4e, 0x30, 0x96, 0xc8, 0x85, 0x26, 0x23, 0xea, 0x2b, 0x97, 0x57, 0x6e, 0xdd, 0x90,
-	0x25, 0xd4, 0x4c, 0x59, 0xe8, 0x96, 0x65, 0x0a, 0x88, 0x7a, 0xd0, 0x4a, 0x2e, 0x63, 0xc6, 0x48,
-	0x9a, 0xb9, 0x8d, 0x43, 0xe7, 0x78, 0x27, 0x7c, 0xc0, 0x5a, 0xb5, 0xe0, 0x8c, 0x5e, 0x11, 0xe9,
-	0x36, 0xad, 0xaa, 0x80, 0xe8, 0x35, 0x6c, 0x71, 0x75, 0x49, 0xa4, 0xdb, 0x32, 0x6d, 0xbf, 0xd8,
-	0x6c, 0xbb, 0x1c, 0xd5, 0xb9, 0xbe, 0x54, 0x34, 0x6d, 0x15, 0x83, 0xcf, 0xd0, 0x59, 0x63, 0xd1,
-	0x01, 0xb4, 0xd4, 0x75, 0x44, 0x19, 0x26, 0xd7, 0x66, 0x8a, 0xdb, 0x61, 0x53, 0x5d, 0x8f, 0x35,
-	0x44, 0x43, 0x68, 0x4b, 0x91, 0x98, 0x76, 0x49, 0x96, 0x15, 0xa3, 0xd9, 0xcd, 0x57, 0x1e, 0x84,
-	0x93, 0xd3, 0x13, 0x1b, 0x0d, 0x41, 0x8a, 0xa4, 0x38, 0x0f, 0xbe, 0x3b, 0xd0, 0x9a, 0x10, 0x22,
-	0xcd, 0x33, 0xfd, 0x0f, 0x55, 0x8a, 0xad, 0x65, 0xd0, 0xc8, 0x57, 0x5e, 0x75, 0x7c, 0x16, 0x56,
-	0x29, 0x46, 0x01, 0xec, 0x14, 0x8e, 0x11, 0x65, 0x33, 0xee, 0x56, 0x0f, 0x6b, 0xff, 0x7c, 0x3a,
-	0x42, 0x64, 0xe1, 0xab, 0xed, 0xc2, 0x76, 0xfc, 0x08, 0xd0, 0x5b, 0xd8, 0x4d, 0xe3, 0x4c, 0x45,
-	0x09, 0x67, 0x8c, 0x24, 0x8a, 0x60, 0xf3, 0x1c, 0xed, 0x51, 0xcf, 0xb7, 0xff, 0xd3, 0x2f, 0xff,
-	0xa7, 0x7f, 0x51, 0xfe, 0xcf, 0xa0, 0x7e, 0xf3, 0xcb, 0x73, 0xc2, 0x8e, 0xd6, 0x9d, 0x96, 0xb2,
-	0xc1, 0x1f, 0x07, 0xba, 0x1b, 0x99, 0xf4, 0xdc, 0xcb, 0x96, 0x8b, 0x81, 0x14, 0x10, 0xbd, 0x83,
-	0xff, 0x4c, 0x5a, 0x4c, 0xe3, 0x34, 0xca, 0x96, 0x49, 0x52, 0x8e, 0xe5, 0x39, 0x99, 0xbb, 0x5a,
-	0x7a, 0x46, 0xe3, 0xf4, 0x83, 0x15, 0xae, 0xbb, 0xcd, 0x62, 0x9a, 0x2e, 0x25, 0x79, 0x76, 0x1f,
-	0x0f, 0x6e, 0x6f, 0xac, 0x10, 0x1d, 0x41, 0xe7, 0xa9, 0x51, 0x66, 0xfe, 0x60, 0x27, 0xdc, 0xc1,
-	0x8f, 0x77, 0xb2, 0xe0, 0xfc, 0x36, 0xef, 0x3b, 0x77, 0x79, 0xdf, 0xf9, 0x9d, 0xf7, 0x9d, 0x9b,
-	0xfb, 0x7e, 0xe5, 0xee, 0xbe, 0x5f, 0xf9, 0x79, 0xdf, 0xaf, 0x7c, 0x7a, 0x35, 0xa7, 0xea, 0x72,
-	0x39, 0xf5, 0x13, 0xbe, 0x18, 0x3e, 0xd9, 0x12, 0x4f, 0x17, 0x86, 0xd9, 0x05, 0xeb, 0x1b, 0x64,
-	0xda, 0x30, 0xd1, 0x97, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff, 0x0b, 0xe9, 0x56, 0xd3, 0x5a, 0x04,
-	0x00, 0x00,
+	// 796 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0xcd, 0x6e, 0x23, 0x45,
+	0x10, 0xce, 0xd8, 0x89, 0x7f, 0xca, 0x76, 0xb2, 0xb4, 0x02, 0x9a, 0xb5, 0x82, 0xc7, 0xf2, 0x72,
+	0xc8, 0x01, 0x8d, 0x25, 0x23, 0x0e, 0x88, 0x53, 0x9c, 0x08, 0x64, 0xed, 0xc2, 0x5a, 0xbd, 0xab,
+	0x95, 0x00, 0x89, 0xd1, 0x78, 0xba, 0xe3, 0xb4, 0x3c, 0x33, 0xdd, 0x4c, 0xb7, 0x83, 0xbd, 0x4f,
+	0xb1, 0x6f, 0xc2, 0x6b, 0xec, 0x71, 0x8f, 0x9c, 0x0c, 0x9a, 0x5c, 0xe1, 0x1d, 0x50, 0xf7, 0xf4,
+	0xe0, 0x1f, 0xe5, 0x00, 0xb7, 0xae, 0xaa, 0xaf, 0xbe, 0xaf, 0xaa, 0xbb, 0xba, 0xa0, 0xab, 0x68,
+	0x4a, 0x68, 0x96, 0xb0, 0x54, 0x0d, 0xc5, 0x48, 0x0c, 0xd5, 0x5a, 0x50, 0xe9, 0x8b, 0x8c, 0x2b,
+	0x8e, 0x4e, 0xb7, 0x31, 0x5f, 0x8c, 0x44, 0xf7, 0x7c, 0xce, 0xe7, 0xdc, 0x84, 0x86, 0xfa, 0x54,
+	0xa0, 0xba, 0xde, 0x9c, 0xf3, 0x79, 0x4c, 0x87, 0xc6, 0x9a, 0x2d, 0x6f, 0x87, 0x8a, 0x25, 0x54,
+	0xaa, 0x30, 0x11, 0x16, 0x70, 0xb1, 0x23, 0x11, 0x65, 0x6b, 0xa1, 0xf8, 0x70, 0x41, 0xd7, 0x56,
+	0x64, 0xf0, 0x1a, 0xce, 0xa6, 0xfa, 0x10, 0xf1, 0xf8, 0x0d, 0xcd, 0x24, 0xe3, 0x29, 0x7a, 0x0a,
+	0x55, 0x31, 0x12, 0xae, 0xd3, 0x77, 0x2e, 0x8f, 0xc7, 0xf5, 0x7c, 0xe3, 0x55, 0xa7, 0xa3, 0x29,
+	0xd6, 0x3e, 0x74, 0x0e, 0x27, 0xb3, 0x98, 0x47, 0x0b, 0xb7, 0xa2, 0x83, 0xb8, 0x30, 0xd0, 0x13,
+	0xa8, 0x86, 0x42, 0xb8, 0x55, 0xe3, 0xd3, 0xc7, 0xc1, 0xdf, 0x55, 0x68, 0x7c, 0xcf, 0x09, 0x9d,
+	0xa4, 0xb7, 0x1c, 0x4d, 0xe1, 0x89, 0xb0, 0x12, 0xc1, 0x7d, 0xa1, 0x61, 0xc8, 0x5b, 0x23, 0xcf,
+	0xdf, 0x6f, 0xd1, 0x3f, 0x28, 0x65, 0x7c, 0xfc, 0x7e, 0xe3, 0x1d, 0xe1, 0x33, 0x71, 0x50, 0xe1,
+	0x33, 0xa8, 0xa7, 0x9c, 0xd0, 0x80, 0x11, 0x53, 0x48, 0x73, 0x0c, 0xf9, 0xc6, 0xab, 0x19, 0xc1,
+	0x1b, 0x5c, 0xd3, 0xa1, 0x09, 0x41, 0x1e, 0xb4, 0x62, 0x26, 0x15, 0x4d, 0x83, 0x90, 0x90, 0xcc,
+	0x54, 0xd7, 0xc4, 0x50, 0xb8, 0xae, 0x08, 0xc9, 0x90, 0x0b, 0xf5, 0x94, 0xaa, 0x5f, 0x79, 0xb6,
+	0x70, 0x8f, 0x4d, 0xb0, 0x34, 0x75, 0xa4, 0x2c, 0xf4, 0xa4, 0x88, 0x58, 0x13, 0x75, 0xa1, 0x11,
+	0xdd, 0x85, 0x69, 0x4a, 0x63, 0xe9, 0xd6, 0xfa, 0xce, 0x65, 0x1b, 0xff, 0x6b, 0xeb, 0xac, 0x84,
+	0xa7, 0x6c, 0x41, 0x33, 0xb7, 0x5e, 0x64, 0x59, 0x13, 0x7d, 0x05, 0x27, 0x5c, 0xdd, 0xd1, 0xcc,
+	0x6d, 0x98, 0xb6, 0x3f, 0x3d, 0x6c, 0xbb, 0xbc, 0xaa, 0x97, 0x1a, 0x64, 0x9b, 0x2e, 0x32, 0xd0,
+	0x04, 0x4e, 0xad, 0x40, 0x10, 0xb3, 0x84, 0x29, 0xe9, 0x36, 0xfb, 0xd5, 0xcb, 0xd6, 0xe8, 0xe2,
+	0x90, 0xe3, 0xba, 0x40, 0xbd, 0xd0, 0x20, 0x4b, 0xd1, 0x89, 0x76, 0x7c, 0x12, 0xfd, 0x00, 0x1f,
+	0xdf, 0x87, 0x31, 0x23, 0xa1, 0xe2, 0x59, 0x10, 0x2a, 0xa5, 0xa7, 0x44, 0xe9, 0x1e, 0xc1, 0x54,
+	0xf5, 0xd9, 0x21, 0xe3, 0x9b, 0x12, 0x7c, 0xb5, 0xc5, 0xe2, 0xf3, 0xfb, 0x47, 0xbc, 0x83, 0x5f,
+	0xe0, 0xfc, 0x31, 0x34, 0xfa, 0x1a, 0xea, 0x62, 0x39, 0x0b, 0x16, 0x74, 0x6d, 0x5f, 0x7c, 0xaf,
+	0xec, 0x62, 0x1a, 0xfd, 0xe9, 0x72, 0x16, 0xb3, 0xe8, 0x39, 0x5d, 0xdb, 0xb2, 0x6b, 0x62, 0x39,
+	0x7b, 0x4e, 0xd7, 0xe8, 0x02, 0x9a, 0x92, 0xcd, 0xd3, 0x50, 0x2d, 0x33, 0x6a, 0xde, 0xb9, 0x8d,
+	0xb7, 0x8e, 0xc1, 0xcf, 0xd0, 0xde, 0x6d, 0x19, 0x7d, 0x0e, 0x50, 0x5e, 0x14, 0x23, 0x46, 0xad,
+	0x33, 0xee, 0xe4, 0x1b, 0xaf, 0x69, 0x51, 0x93, 0x1b, 0xdc, 0xb4, 0x80, 0x09, 0x41, 0x7d, 0x68,
+	0x27, 0xe1, 0x2a, 0x48, 0xe4, 0x3c, 0x90, 0xec, 0x6d, 0x41, 0xdf, 0xc1, 0x90, 0x84, 0xab, 0xef,
+	0xe4, 0xfc, 0x15, 0x7b, 0x4b, 0x07, 0x3f, 0x41, 0x67, 0xef, 0x59, 0xd0, 0x53, 0x68, 0xa8, 0x55,
+	0xc0, 0x52, 0x42, 0x57, 0x86, 0xbe, 0x89, 0xeb, 0x6a, 0x35, 0xd1, 0x26, 0x1a, 0x42, 0x2b, 0x13,
+	0x91, 0x99, 0x33, 0x2a, 0xa5, 0x9d, 0xc9, 0xd3, 0x7c, 0xe3, 0x01, 0x9e, 0x5e, 0x5f, 0x15, 0x5e,
+	0x0c, 0x99, 0x88, 0xec, 0x79, 0xf0, 0x9b, 0x03, 0x8d, 0x29, 0xa5, 0x99, 0xf9, 0x1f, 0x9f, 0x40,
+	0xc5, 0x56, 0xdc, 0x1c, 0xd7, 0xf2, 0x8d, 0x57, 0x99, 0xdc, 0xe0, 0x0a, 0x23, 0x68, 0x0c, 0x6d,
+	0xcb, 0x18, 0xb0, 0xf4, 0x96, 0xbb, 0x95, 0x7e, 0xf5, 0xd1, 0x3f, 0x43, 0x69, 0x66, 0x79, 0x35,
+	0x1d, 0x6e, 0x85, 0x5b, 0x03, 0x7d, 0x0b, 0xa7, 0x71, 0x28, 0x55, 0x10, 0xf1, 0x34, 0xa5, 0x91,
+	0xa2, 0xc4, 0xfc, 0x83, 0xd6, 0xa8, 0xeb, 0x17, 0x6b, 0xc3, 0x2f, 0xd7, 0x86, 0xff, 0xba, 0x5c,
+	0x1b, 0xe3, 0xe3, 0x77, 0x7f, 0x78, 0x0e, 0xee, 0xe8, 0xbc, 0xeb, 0x32, 0x6d, 0xf0, 0x97, 0x03,
+	0x67, 0x07, 0x4a, 0x7a, 0xe0, 0xcb, 0x96, 0xed, 0x85, 0x58, 0x13, 0xbd, 0x80, 0x8f, 0x8c, 0x2c,
+	0x61, 0x61, 0x1c, 0xc8, 0x65, 0x14, 0x95, 0xd7, 0xf2, 0x5f, 0x94, 0xcf, 0x74, 0xea, 0x0d, 0x0b,
+	0xe3, 0x57, 0x45, 0xe2, 0x3e, 0xdb, 0x6d, 0xc8, 0x62, 0x3d, 0x10, 0xd5, 0xff, 0xcb, 0xf6, 0x4d,
+	0x91, 0x88, 0x9e, 0x41, 0x67, 0x97, 0x48, 0x9a, 0xcf, 0xdf, 0xc1, 0x6d, 0xb2, 0xc5, 0xc8, 0xf1,
+	0xcb, 0xf7, 0x79, 0xcf, 0xf9, 0x90, 0xf7, 0x9c, 0x3f, 0xf3, 0x9e, 0xf3, 0xee, 0xa1, 0x77, 0xf4,
+	0xe1, 0xa1, 0x77, 0xf4, 0xfb, 0x43, 0xef, 0xe8, 0xc7, 0x2f, 0xe7, 0x4c, 0xdd, 0x2d, 0x67, 0x7e,
+	0xc4, 0x93, 0xe1, 0xce, 0x66, 0xdd, 0x39, 0x16, 0x2b, 0x7a, 0x7f, 0xb1, 0xcf, 0x6a, 0xc6, 0xfb,
+	0xc5, 0x3f, 0x03, 0x00, 0x5a, 0x8d, 0xd6, 0xe1, 0xf1, 0x05, 0x00, 0x00,
 }
 
 func (m *ProtocolVersion) Marshal() (dAtA []byte, err error) {
@@ -478,6 +618,32 @@ func (m *NodeInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ValidatorAttestation != nil {
+		{
+			size, err := m.ValidatorAttestation.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTypes(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x52
+	}
+	if len(m.ChannelLimits) > 0 {
+		for iNdEx := len(m.ChannelLimits) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ChannelLimits[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTypes(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x4a
+		}
+	}
 	{
 		size, err := m.Other.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -543,6 +709,79 @@ func (m *NodeInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *ValidatorAttestation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ValidatorAttestation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ValidatorAttestation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Signature) > 0 {
+		i -= len(m.Signature)
+		copy(dAtA[i:], m.Signature)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Signature)))
+		i--
+		dAtA[i] = 0x12
+	}
+	{
+		size, err := m.PubKey.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintTypes(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *ChannelLimit) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ChannelLimit) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ChannelLimit) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.MaxMsgSize != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.MaxMsgSize))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.ChannelID != 0 {
+		i = encodeVarintTypes(dAtA, i, uint64(m.ChannelID))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *NodeInfoOther) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -601,12 +840,12 @@ func (m *PeerInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	var l int
 	_ = l
 	if m.LastConnected != nil {
-		n3, err3 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastConnected, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastConnected):])
-		if err3 != nil {
-			return 0, err3
+		n5, err5 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastConnected, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastConnected):])
+		if err5 != nil {
+			return 0, err5
 		}
-		i -= n3
-		i = encodeVarintTypes(dAtA, i, uint64(n3))
+		i -= n5
+		i = encodeVarintTypes(dAtA, i, uint64(n5))
 		i--
 		dAtA[i] = 0x1a
 	}
@@ -660,22 +899,22 @@ func (m *PeerAddressInfo) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		dAtA[i] = 0x20
 	}
 	if m.LastDialFailure != nil {
-		n4, err4 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialFailure, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialFailure):])
-		if err4 != nil {
-			return 0, err4
+		n6, err6 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialFailure, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialFailure):])
+		if err6 != nil {
+			return 0, err6
 		}
-		i -= n4
-		i = encodeVarintTypes(dAtA, i, uint64(n4))
+		i -= n6
+		i = encodeVarintTypes(dAtA, i, uint64(n6))
 		i--
 		dAtA[i] = 0x1a
 	}
 	if m.LastDialSuccess != nil {
-		n5, err5 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialSuccess, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialSuccess):])
-		if err5 != nil {
-			return 0, err5
+		n7, err7 := github_com_gogo_protobuf_types.StdTimeMarshalTo(*m.LastDialSuccess, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdTime(*m.LastDialSuccess):])
+		if err7 != nil {
+			return 0, err7
 		}
-		i -= n5
-		i = encodeVarintTypes(dAtA, i, uint64(n5))
+		i -= n7
+		i = encodeVarintTypes(dAtA, i, uint64(n7))
 		i--
 		dAtA[i] = 0x12
 	}
@@ -752,6 +991,46 @@ func (m *NodeInfo) Size() (n int) {
 	}
 	l = m.Other.Size()
 	n += 1 + l + sovTypes(uint64(l))
+	if len(m.ChannelLimits) > 0 {
+		for _, e := range m.ChannelLimits {
+			l = e.Size()
+			n += 1 + l + sovTypes(uint64(l))
+		}
+	}
+	if m.ValidatorAttestation != nil {
+		l = m.ValidatorAttestation.Size()
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
+func (m *ValidatorAttestation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = m.PubKey.Size()
+	n += 1 + l + sovTypes(uint64(l))
+	l = len(m.Signature)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
+	return n
+}
+
+func (m *ChannelLimit) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.ChannelID != 0 {
+		n += 1 + sovTypes(uint64(m.ChannelID))
+	}
+	if m.MaxMsgSize != 0 {
+		n += 1 + sovTypes(uint64(m.MaxMsgSize))
+	}
 	return n
 }
 
@@ -1221,6 +1500,281 @@ func (m *NodeInfo) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChannelLimits", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChannelLimits = append(m.ChannelLimits, ChannelLimit{})
+			if err := m.ChannelLimits[len(m.ChannelLimits)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ValidatorAttestation", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.ValidatorAttestation == nil {
+				m.ValidatorAttestation = &ValidatorAttestation{}
+			}
+			if err := m.ValidatorAttestation.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ValidatorAttestation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ValidatorAttestation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ValidatorAttestation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PubKey", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.PubKey.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Signature = append(m.Signature[:0], dAtA[iNdEx:postIndex]...)
+			if m.Signature == nil {
+				m.Signature = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTypes(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ChannelLimit) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTypes
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ChannelLimit: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ChannelLimit: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChannelID", wireType)
+			}
+			m.ChannelID = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChannelID |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxMsgSize", wireType)
+			}
+			m.MaxMsgSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxMsgSize |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -0,0 +1,1131 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: tendermint/rpc/service.proto
+
+package rpc
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	types "github.com/tendermint/tendermint/abci/types"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+func init() { proto.RegisterFile("tendermint/rpc/service.proto", fileDescriptor_d170ca344f015d69) }
+
+var fileDescriptor_d170ca344f015d69 = []byte{
+	// 684 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x96, 0xcf, 0x6f, 0xd3, 0x30,
+	0x14, 0xc7, 0x57, 0x24, 0xf6, 0xc3, 0x4c, 0x1b, 0x8b, 0xc4, 0x81, 0xb2, 0x85, 0xb1, 0xc1, 0x80,
+	0x4b, 0x8b, 0x40, 0x9c, 0x38, 0xad, 0x65, 0xec, 0x07, 0xda, 0x18, 0x6b, 0x99, 0xc4, 0x38, 0x20,
+	0xc7, 0x79, 0x23, 0xd1, 0x1a, 0xbb, 0xd8, 0x4e, 0x95, 0xfe, 0x17, 0xfc, 0x59, 0x1c, 0x77, 0xe4,
+	0x88, 0xd6, 0x3b, 0x7f, 0x03, 0x6a, 0x62, 0x2f, 0x3f, 0x9c, 0xb4, 0x93, 0xe0, 0x56, 0xbd, 0xef,
+	0xd7, 0x1f, 0xbf, 0xbc, 0x67, 0xbf, 0x1a, 0xad, 0x4a, 0xa0, 0x2e, 0xf0, 0xc0, 0xa7, 0xb2, 0xc9,
+	0xfb, 0xa4, 0x29, 0x80, 0x0f, 0x7c, 0x02, 0x8d, 0x3e, 0x67, 0x92, 0x59, 0x4b, 0xa9, 0xda, 0xe0,
+	0x7d, 0x52, 0x7f, 0x90, 0x71, 0x63, 0x87, 0xf8, 0x4d, 0x39, 0xec, 0x83, 0x48, 0xcc, 0xf5, 0x7a,
+	0x01, 0x95, 0xd1, 0x5e, 0xfe, 0x59, 0x41, 0x73, 0x6d, 0xc6, 0x61, 0xfb, 0x78, 0xdf, 0xda, 0x45,
+	0xb3, 0x7b, 0x80, 0x7b, 0xd2, 0xb3, 0xd6, 0x1a, 0x79, 0x7e, 0x23, 0x89, 0x9f, 0xc0, 0xf7, 0x10,
+	0x84, 0xac, 0xdb, 0x55, 0xb2, 0xe8, 0x33, 0x2a, 0x60, 0x0c, 0xea, 0x48, 0x2c, 0x43, 0x61, 0x82,
+	0x92, 0x78, 0x25, 0x48, 0xcb, 0x0a, 0x74, 0x80, 0xe6, 0x8e, 0x40, 0xee, 0xd3, 0x73, 0x66, 0x19,
+	0x56, 0x25, 0x68, 0xd4, 0xc3, 0x4a, 0x5d, 0xb1, 0xbe, 0xa2, 0xa5, 0x56, 0x8f, 0x91, 0x0b, 0xe2,
+	0x61, 0x9f, 0xc6, 0xc8, 0x27, 0xc5, 0x25, 0x79, 0x5d, 0x93, 0xb7, 0xa6, 0xd9, 0xd2, 0x0d, 0x76,
+	0x81, 0x82, 0xf0, 0x45, 0xdb, 0x0b, 0xe9, 0x05, 0xb8, 0xe6, 0x06, 0x79, 0xbd, 0x72, 0x83, 0xa2,
+	0x2d, 0x2d, 0xeb, 0x1e, 0x60, 0x17, 0x78, 0x69, 0x7f, 0x5c, 0xe0, 0x93, 0xfa, 0x13, 0xcb, 0x0a,
+	0xd4, 0x41, 0x8b, 0x49, 0xa4, 0x35, 0xdc, 0xc3, 0xc2, 0xb3, 0x36, 0xcb, 0xfd, 0x89, 0x7a, 0x53,
+	0xe8, 0x5b, 0x74, 0x3b, 0x2e, 0x8c, 0xb5, 0x5a, 0x5a, 0x2f, 0x8d, 0x59, 0xab, 0x50, 0x15, 0xe5,
+	0x18, 0xdd, 0x89, 0x03, 0x2a, 0xb3, 0x8d, 0x52, 0x77, 0x3e, 0xb1, 0x29, 0xc4, 0xcf, 0x68, 0x51,
+	0x07, 0xc2, 0x9e, 0x14, 0xe6, 0xc7, 0x66, 0x55, 0xcd, 0x7c, 0x3c, 0xd9, 0x94, 0x36, 0xa4, 0xcd,
+	0x82, 0xc0, 0x97, 0x66, 0x43, 0x92, 0x78, 0x65, 0xed, 0xb4, 0x7c, 0xdd, 0x10, 0x74, 0x8a, 0x7b,
+	0xbe, 0x8b, 0x25, 0xe3, 0xc2, 0x7a, 0x54, 0x74, 0xa7, 0x9a, 0x06, 0x6e, 0x4c, 0xb2, 0x28, 0xa8,
+	0x83, 0x96, 0xdb, 0xe3, 0x1f, 0x54, 0x84, 0xe2, 0x18, 0x73, 0x1c, 0x08, 0x6b, 0xcb, 0xcc, 0x23,
+	0x67, 0xd0, 0xf8, 0xa7, 0x53, 0x7d, 0xe9, 0x99, 0xff, 0x44, 0x09, 0xa3, 0xe7, 0x3e, 0x0f, 0xc0,
+	0xed, 0x46, 0xc2, 0x3c, 0xf3, 0x79, 0xbd, 0xf2, 0xcc, 0x17, 0x6d, 0x6a, 0x03, 0x40, 0x2b, 0x47,
+	0x61, 0x50, 0xd8, 0xe3, 0x99, 0x71, 0xd7, 0xc3, 0xe0, 0xdf, 0xb6, 0x79, 0x8f, 0xe6, 0xda, 0x1e,
+	0x90, 0x8b, 0x6e, 0x64, 0x0e, 0x1a, 0x25, 0x68, 0xe4, 0x7a, 0x56, 0x1f, 0xcf, 0xda, 0x86, 0xa6,
+	0x68, 0xc2, 0x21, 0x9a, 0x3f, 0x81, 0x80, 0x0d, 0xa0, 0x1b, 0x59, 0xc6, 0x58, 0xd2, 0x4a, 0x29,
+	0x2e, 0x6f, 0x50, 0xb9, 0xbd, 0x41, 0xb7, 0xba, 0x91, 0x75, 0xbf, 0xe8, 0x4b, 0x11, 0xf5, 0x32,
+	0x49, 0x2d, 0x3e, 0x44, 0xf3, 0xdd, 0xa8, 0x03, 0x98, 0x13, 0xcf, 0xcc, 0x45, 0x2b, 0x95, 0xb9,
+	0xa4, 0x06, 0x85, 0x3b, 0x55, 0xd7, 0x53, 0x11, 0xcb, 0xaf, 0x67, 0x1e, 0xba, 0x39, 0xd1, 0xa3,
+	0xb8, 0x5f, 0xd0, 0xdd, 0x16, 0x67, 0xd8, 0x25, 0x58, 0xc8, 0x6e, 0xb4, 0x2d, 0x86, 0x94, 0x94,
+	0xc0, 0x53, 0x47, 0x35, 0x3c, 0xeb, 0x51, 0xf0, 0x33, 0xb4, 0x9c, 0x09, 0x77, 0xfe, 0x2b, 0xdb,
+	0x41, 0x2b, 0x99, 0xb0, 0x9a, 0x06, 0x37, 0xa1, 0x3f, 0x9f, 0xe0, 0x29, 0x4c, 0x87, 0x03, 0x34,
+	0xbf, 0xdd, 0x6a, 0xef, 0xc7, 0xff, 0x59, 0x46, 0x0f, 0xb5, 0x52, 0x3a, 0x0d, 0x73, 0xc7, 0x33,
+	0x5e, 0x7f, 0x88, 0x16, 0xc6, 0x2b, 0x3e, 0x86, 0xc0, 0x87, 0xd6, 0x7a, 0x19, 0x2c, 0x96, 0x4a,
+	0x07, 0x57, 0x8e, 0x96, 0x10, 0xbc, 0xcc, 0xe7, 0xef, 0x0c, 0x7c, 0x17, 0x28, 0x01, 0xf3, 0x7a,
+	0x1a, 0x96, 0xe9, 0x45, 0x48, 0x9d, 0xaa, 0x08, 0xef, 0xd0, 0x42, 0x27, 0x74, 0x04, 0xe1, 0xbe,
+	0x03, 0x66, 0xe2, 0xd7, 0x92, 0x26, 0xdf, 0x2b, 0x3a, 0x76, 0x06, 0x40, 0xe5, 0x8b, 0x5a, 0xeb,
+	0xc3, 0xcf, 0x2b, 0xbb, 0x76, 0x79, 0x65, 0xd7, 0x7e, 0x5f, 0xd9, 0xb5, 0x1f, 0x23, 0x7b, 0xe6,
+	0x72, 0x64, 0xcf, 0xfc, 0x1a, 0xd9, 0x33, 0x67, 0xaf, 0xbf, 0xf9, 0xd2, 0x0b, 0x9d, 0x06, 0x61,
+	0x41, 0x33, 0xf3, 0x62, 0xca, 0xfc, 0x8c, 0x9f, 0x4c, 0xcd, 0xfc, 0x6b, 0xca, 0x99, 0x8d, 0xa3,
+	0xaf, 0xfe, 0x0e, 0x00, 0xef, 0x24, 0x4e, 0xef, 0xb1, 0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// CoreAPIClient is the client API for CoreAPI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CoreAPIClient interface {
+	// info API
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	NetInfo(ctx context.Context, in *NetInfoRequest, opts ...grpc.CallOption) (*NetInfoResponse, error)
+	BlockchainInfo(ctx context.Context, in *BlockchainInfoRequest, opts ...grpc.CallOption) (*BlockchainInfoResponse, error)
+	GenesisChunked(ctx context.Context, in *GenesisChunkedRequest, opts ...grpc.CallOption) (*GenesisChunkedResponse, error)
+	Header(ctx context.Context, in *HeaderRequest, opts ...grpc.CallOption) (*HeaderResponse, error)
+	HeaderByHash(ctx context.Context, in *HeaderByHashRequest, opts ...grpc.CallOption) (*HeaderResponse, error)
+	Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error)
+	BlockByHash(ctx context.Context, in *BlockByHashRequest, opts ...grpc.CallOption) (*BlockResponse, error)
+	BlockResults(ctx context.Context, in *BlockResultsRequest, opts ...grpc.CallOption) (*BlockResultsResponse, error)
+	Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error)
+	Validators(ctx context.Context, in *ValidatorsRequest, opts ...grpc.CallOption) (*ValidatorsResponse, error)
+	ConsensusParams(ctx context.Context, in *ConsensusParamsRequest, opts ...grpc.CallOption) (*ConsensusParamsResponse, error)
+	UnconfirmedTxs(ctx context.Context, in *UnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error)
+	NumUnconfirmedTxs(ctx context.Context, in *NumUnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error)
+	CheckTx(ctx context.Context, in *CheckTxRequest, opts ...grpc.CallOption) (*types.ResponseCheckTx, error)
+	RemoveTx(ctx context.Context, in *RemoveTxRequest, opts ...grpc.CallOption) (*RemoveTxResponse, error)
+	Tx(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*TxResponse, error)
+	TxSearch(ctx context.Context, in *TxSearchRequest, opts ...grpc.CallOption) (*TxSearchResponse, error)
+	BlockSearch(ctx context.Context, in *BlockSearchRequest, opts ...grpc.CallOption) (*BlockSearchResponse, error)
+	// tx broadcast API
+	BroadcastTxAsync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error)
+	BroadcastTxSync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error)
+	BroadcastTxCommit(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxCommitResponse, error)
+	// abci API
+	ABCIInfo(ctx context.Context, in *ABCIInfoRequest, opts ...grpc.CallOption) (*types.ResponseInfo, error)
+	ABCIQuery(ctx context.Context, in *ABCIQueryRequest, opts ...grpc.CallOption) (*types.ResponseQuery, error)
+	// evidence API
+	BroadcastEvidence(ctx context.Context, in *BroadcastEvidenceRequest, opts ...grpc.CallOption) (*BroadcastEvidenceResponse, error)
+	// events API: the stream ends when the client cancels the call, or when
+	// the subscription is terminated by the node.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CoreAPI_SubscribeClient, error)
+}
+
+type coreAPIClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewCoreAPIClient(cc *grpc.ClientConn) CoreAPIClient {
+	return &coreAPIClient{cc}
+}
+
+func (c *coreAPIClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) NetInfo(ctx context.Context, in *NetInfoRequest, opts ...grpc.CallOption) (*NetInfoResponse, error) {
+	out := new(NetInfoResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/NetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BlockchainInfo(ctx context.Context, in *BlockchainInfoRequest, opts ...grpc.CallOption) (*BlockchainInfoResponse, error) {
+	out := new(BlockchainInfoResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BlockchainInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) GenesisChunked(ctx context.Context, in *GenesisChunkedRequest, opts ...grpc.CallOption) (*GenesisChunkedResponse, error) {
+	out := new(GenesisChunkedResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/GenesisChunked", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) Header(ctx context.Context, in *HeaderRequest, opts ...grpc.CallOption) (*HeaderResponse, error) {
+	out := new(HeaderResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/Header", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) HeaderByHash(ctx context.Context, in *HeaderByHashRequest, opts ...grpc.CallOption) (*HeaderResponse, error) {
+	out := new(HeaderResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/HeaderByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) Block(ctx context.Context, in *BlockRequest, opts ...grpc.CallOption) (*BlockResponse, error) {
+	out := new(BlockResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/Block", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BlockByHash(ctx context.Context, in *BlockByHashRequest, opts ...grpc.CallOption) (*BlockResponse, error) {
+	out := new(BlockResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BlockByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BlockResults(ctx context.Context, in *BlockResultsRequest, opts ...grpc.CallOption) (*BlockResultsResponse, error) {
+	out := new(BlockResultsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BlockResults", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) Commit(ctx context.Context, in *CommitRequest, opts ...grpc.CallOption) (*CommitResponse, error) {
+	out := new(CommitResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/Commit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) Validators(ctx context.Context, in *ValidatorsRequest, opts ...grpc.CallOption) (*ValidatorsResponse, error) {
+	out := new(ValidatorsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/Validators", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) ConsensusParams(ctx context.Context, in *ConsensusParamsRequest, opts ...grpc.CallOption) (*ConsensusParamsResponse, error) {
+	out := new(ConsensusParamsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/ConsensusParams", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) UnconfirmedTxs(ctx context.Context, in *UnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error) {
+	out := new(UnconfirmedTxsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/UnconfirmedTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) NumUnconfirmedTxs(ctx context.Context, in *NumUnconfirmedTxsRequest, opts ...grpc.CallOption) (*UnconfirmedTxsResponse, error) {
+	out := new(UnconfirmedTxsResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/NumUnconfirmedTxs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) CheckTx(ctx context.Context, in *CheckTxRequest, opts ...grpc.CallOption) (*types.ResponseCheckTx, error) {
+	out := new(types.ResponseCheckTx)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/CheckTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) RemoveTx(ctx context.Context, in *RemoveTxRequest, opts ...grpc.CallOption) (*RemoveTxResponse, error) {
+	out := new(RemoveTxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/RemoveTx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) Tx(ctx context.Context, in *TxRequest, opts ...grpc.CallOption) (*TxResponse, error) {
+	out := new(TxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/Tx", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) TxSearch(ctx context.Context, in *TxSearchRequest, opts ...grpc.CallOption) (*TxSearchResponse, error) {
+	out := new(TxSearchResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/TxSearch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BlockSearch(ctx context.Context, in *BlockSearchRequest, opts ...grpc.CallOption) (*BlockSearchResponse, error) {
+	out := new(BlockSearchResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BlockSearch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BroadcastTxAsync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error) {
+	out := new(BroadcastTxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BroadcastTxAsync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BroadcastTxSync(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxResponse, error) {
+	out := new(BroadcastTxResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BroadcastTxSync", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BroadcastTxCommit(ctx context.Context, in *BroadcastTxRequest, opts ...grpc.CallOption) (*BroadcastTxCommitResponse, error) {
+	out := new(BroadcastTxCommitResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BroadcastTxCommit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) ABCIInfo(ctx context.Context, in *ABCIInfoRequest, opts ...grpc.CallOption) (*types.ResponseInfo, error) {
+	out := new(types.ResponseInfo)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/ABCIInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) ABCIQuery(ctx context.Context, in *ABCIQueryRequest, opts ...grpc.CallOption) (*types.ResponseQuery, error) {
+	out := new(types.ResponseQuery)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/ABCIQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) BroadcastEvidence(ctx context.Context, in *BroadcastEvidenceRequest, opts ...grpc.CallOption) (*BroadcastEvidenceResponse, error) {
+	out := new(BroadcastEvidenceResponse)
+	err := c.cc.Invoke(ctx, "/tendermint.rpc.CoreAPI/BroadcastEvidence", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *coreAPIClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (CoreAPI_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_CoreAPI_serviceDesc.Streams[0], "/tendermint.rpc.CoreAPI/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &coreAPISubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CoreAPI_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type coreAPISubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *coreAPISubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CoreAPIServer is the server API for CoreAPI service.
+type CoreAPIServer interface {
+	// info API
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	NetInfo(context.Context, *NetInfoRequest) (*NetInfoResponse, error)
+	BlockchainInfo(context.Context, *BlockchainInfoRequest) (*BlockchainInfoResponse, error)
+	GenesisChunked(context.Context, *GenesisChunkedRequest) (*GenesisChunkedResponse, error)
+	Header(context.Context, *HeaderRequest) (*HeaderResponse, error)
+	HeaderByHash(context.Context, *HeaderByHashRequest) (*HeaderResponse, error)
+	Block(context.Context, *BlockRequest) (*BlockResponse, error)
+	BlockByHash(context.Context, *BlockByHashRequest) (*BlockResponse, error)
+	BlockResults(context.Context, *BlockResultsRequest) (*BlockResultsResponse, error)
+	Commit(context.Context, *CommitRequest) (*CommitResponse, error)
+	Validators(context.Context, *ValidatorsRequest) (*ValidatorsResponse, error)
+	ConsensusParams(context.Context, *ConsensusParamsRequest) (*ConsensusParamsResponse, error)
+	UnconfirmedTxs(context.Context, *UnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error)
+	NumUnconfirmedTxs(context.Context, *NumUnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error)
+	CheckTx(context.Context, *CheckTxRequest) (*types.ResponseCheckTx, error)
+	RemoveTx(context.Context, *RemoveTxRequest) (*RemoveTxResponse, error)
+	Tx(context.Context, *TxRequest) (*TxResponse, error)
+	TxSearch(context.Context, *TxSearchRequest) (*TxSearchResponse, error)
+	BlockSearch(context.Context, *BlockSearchRequest) (*BlockSearchResponse, error)
+	// tx broadcast API
+	BroadcastTxAsync(context.Context, *BroadcastTxRequest) (*BroadcastTxResponse, error)
+	BroadcastTxSync(context.Context, *BroadcastTxRequest) (*BroadcastTxResponse, error)
+	BroadcastTxCommit(context.Context, *BroadcastTxRequest) (*BroadcastTxCommitResponse, error)
+	// abci API
+	ABCIInfo(context.Context, *ABCIInfoRequest) (*types.ResponseInfo, error)
+	ABCIQuery(context.Context, *ABCIQueryRequest) (*types.ResponseQuery, error)
+	// evidence API
+	BroadcastEvidence(context.Context, *BroadcastEvidenceRequest) (*BroadcastEvidenceResponse, error)
+	// events API: the stream ends when the client cancels the call, or when
+	// the subscription is terminated by the node.
+	Subscribe(*SubscribeRequest, CoreAPI_SubscribeServer) error
+}
+
+// UnimplementedCoreAPIServer can be embedded to have forward compatible implementations.
+type UnimplementedCoreAPIServer struct {
+}
+
+func (*UnimplementedCoreAPIServer) Health(ctx context.Context, req *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (*UnimplementedCoreAPIServer) Status(ctx context.Context, req *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (*UnimplementedCoreAPIServer) NetInfo(ctx context.Context, req *NetInfoRequest) (*NetInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NetInfo not implemented")
+}
+func (*UnimplementedCoreAPIServer) BlockchainInfo(ctx context.Context, req *BlockchainInfoRequest) (*BlockchainInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockchainInfo not implemented")
+}
+func (*UnimplementedCoreAPIServer) GenesisChunked(ctx context.Context, req *GenesisChunkedRequest) (*GenesisChunkedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenesisChunked not implemented")
+}
+func (*UnimplementedCoreAPIServer) Header(ctx context.Context, req *HeaderRequest) (*HeaderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Header not implemented")
+}
+func (*UnimplementedCoreAPIServer) HeaderByHash(ctx context.Context, req *HeaderByHashRequest) (*HeaderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HeaderByHash not implemented")
+}
+func (*UnimplementedCoreAPIServer) Block(ctx context.Context, req *BlockRequest) (*BlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Block not implemented")
+}
+func (*UnimplementedCoreAPIServer) BlockByHash(ctx context.Context, req *BlockByHashRequest) (*BlockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockByHash not implemented")
+}
+func (*UnimplementedCoreAPIServer) BlockResults(ctx context.Context, req *BlockResultsRequest) (*BlockResultsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockResults not implemented")
+}
+func (*UnimplementedCoreAPIServer) Commit(ctx context.Context, req *CommitRequest) (*CommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (*UnimplementedCoreAPIServer) Validators(ctx context.Context, req *ValidatorsRequest) (*ValidatorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validators not implemented")
+}
+func (*UnimplementedCoreAPIServer) ConsensusParams(ctx context.Context, req *ConsensusParamsRequest) (*ConsensusParamsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConsensusParams not implemented")
+}
+func (*UnimplementedCoreAPIServer) UnconfirmedTxs(ctx context.Context, req *UnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnconfirmedTxs not implemented")
+}
+func (*UnimplementedCoreAPIServer) NumUnconfirmedTxs(ctx context.Context, req *NumUnconfirmedTxsRequest) (*UnconfirmedTxsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NumUnconfirmedTxs not implemented")
+}
+func (*UnimplementedCoreAPIServer) CheckTx(ctx context.Context, req *CheckTxRequest) (*types.ResponseCheckTx, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckTx not implemented")
+}
+func (*UnimplementedCoreAPIServer) RemoveTx(ctx context.Context, req *RemoveTxRequest) (*RemoveTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTx not implemented")
+}
+func (*UnimplementedCoreAPIServer) Tx(ctx context.Context, req *TxRequest) (*TxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Tx not implemented")
+}
+func (*UnimplementedCoreAPIServer) TxSearch(ctx context.Context, req *TxSearchRequest) (*TxSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TxSearch not implemented")
+}
+func (*UnimplementedCoreAPIServer) BlockSearch(ctx context.Context, req *BlockSearchRequest) (*BlockSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockSearch not implemented")
+}
+func (*UnimplementedCoreAPIServer) BroadcastTxAsync(ctx context.Context, req *BroadcastTxRequest) (*BroadcastTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastTxAsync not implemented")
+}
+func (*UnimplementedCoreAPIServer) BroadcastTxSync(ctx context.Context, req *BroadcastTxRequest) (*BroadcastTxResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastTxSync not implemented")
+}
+func (*UnimplementedCoreAPIServer) BroadcastTxCommit(ctx context.Context, req *BroadcastTxRequest) (*BroadcastTxCommitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastTxCommit not implemented")
+}
+func (*UnimplementedCoreAPIServer) ABCIInfo(ctx context.Context, req *ABCIInfoRequest) (*types.ResponseInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ABCIInfo not implemented")
+}
+func (*UnimplementedCoreAPIServer) ABCIQuery(ctx context.Context, req *ABCIQueryRequest) (*types.ResponseQuery, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ABCIQuery not implemented")
+}
+func (*UnimplementedCoreAPIServer) BroadcastEvidence(ctx context.Context, req *BroadcastEvidenceRequest) (*BroadcastEvidenceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BroadcastEvidence not implemented")
+}
+func (*UnimplementedCoreAPIServer) Subscribe(req *SubscribeRequest, srv CoreAPI_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+
+func RegisterCoreAPIServer(s *grpc.Server, srv CoreAPIServer) {
+	s.RegisterService(&_CoreAPI_serviceDesc, srv)
+}
+
+func _CoreAPI_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_NetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).NetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/NetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).NetInfo(ctx, req.(*NetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BlockchainInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockchainInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BlockchainInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BlockchainInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BlockchainInfo(ctx, req.(*BlockchainInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_GenesisChunked_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenesisChunkedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).GenesisChunked(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/GenesisChunked",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).GenesisChunked(ctx, req.(*GenesisChunkedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_Header_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeaderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).Header(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/Header",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).Header(ctx, req.(*HeaderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_HeaderByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeaderByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).HeaderByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/HeaderByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).HeaderByHash(ctx, req.(*HeaderByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_Block_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).Block(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/Block",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).Block(ctx, req.(*BlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BlockByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockByHashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BlockByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BlockByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BlockByHash(ctx, req.(*BlockByHashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BlockResults_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockResultsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BlockResults(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BlockResults",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BlockResults(ctx, req.(*BlockResultsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/Commit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).Commit(ctx, req.(*CommitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_Validators_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).Validators(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/Validators",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).Validators(ctx, req.(*ValidatorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_ConsensusParams_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConsensusParamsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).ConsensusParams(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/ConsensusParams",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).ConsensusParams(ctx, req.(*ConsensusParamsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_UnconfirmedTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnconfirmedTxsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).UnconfirmedTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/UnconfirmedTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).UnconfirmedTxs(ctx, req.(*UnconfirmedTxsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_NumUnconfirmedTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NumUnconfirmedTxsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).NumUnconfirmedTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/NumUnconfirmedTxs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).NumUnconfirmedTxs(ctx, req.(*NumUnconfirmedTxsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_CheckTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).CheckTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/CheckTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).CheckTx(ctx, req.(*CheckTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_RemoveTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).RemoveTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/RemoveTx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).RemoveTx(ctx, req.(*RemoveTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_Tx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).Tx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/Tx",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).Tx(ctx, req.(*TxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_TxSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TxSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).TxSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/TxSearch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).TxSearch(ctx, req.(*TxSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BlockSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BlockSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BlockSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BlockSearch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BlockSearch(ctx, req.(*BlockSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BroadcastTxAsync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BroadcastTxAsync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BroadcastTxAsync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BroadcastTxAsync(ctx, req.(*BroadcastTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BroadcastTxSync_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BroadcastTxSync(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BroadcastTxSync",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BroadcastTxSync(ctx, req.(*BroadcastTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BroadcastTxCommit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BroadcastTxCommit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BroadcastTxCommit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BroadcastTxCommit(ctx, req.(*BroadcastTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_ABCIInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ABCIInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).ABCIInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/ABCIInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).ABCIInfo(ctx, req.(*ABCIInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_ABCIQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ABCIQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).ABCIQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/ABCIQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).ABCIQuery(ctx, req.(*ABCIQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_BroadcastEvidence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BroadcastEvidenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CoreAPIServer).BroadcastEvidence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/tendermint.rpc.CoreAPI/BroadcastEvidence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CoreAPIServer).BroadcastEvidence(ctx, req.(*BroadcastEvidenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CoreAPI_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CoreAPIServer).Subscribe(m, &coreAPISubscribeServer{stream})
+}
+
+type CoreAPI_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type coreAPISubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *coreAPISubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _CoreAPI_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tendermint.rpc.CoreAPI",
+	HandlerType: (*CoreAPIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Health",
+			Handler:    _CoreAPI_Health_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _CoreAPI_Status_Handler,
+		},
+		{
+			MethodName: "NetInfo",
+			Handler:    _CoreAPI_NetInfo_Handler,
+		},
+		{
+			MethodName: "BlockchainInfo",
+			Handler:    _CoreAPI_BlockchainInfo_Handler,
+		},
+		{
+			MethodName: "GenesisChunked",
+			Handler:    _CoreAPI_GenesisChunked_Handler,
+		},
+		{
+			MethodName: "Header",
+			Handler:    _CoreAPI_Header_Handler,
+		},
+		{
+			MethodName: "HeaderByHash",
+			Handler:    _CoreAPI_HeaderByHash_Handler,
+		},
+		{
+			MethodName: "Block",
+			Handler:    _CoreAPI_Block_Handler,
+		},
+		{
+			MethodName: "BlockByHash",
+			Handler:    _CoreAPI_BlockByHash_Handler,
+		},
+		{
+			MethodName: "BlockResults",
+			Handler:    _CoreAPI_BlockResults_Handler,
+		},
+		{
+			MethodName: "Commit",
+			Handler:    _CoreAPI_Commit_Handler,
+		},
+		{
+			MethodName: "Validators",
+			Handler:    _CoreAPI_Validators_Handler,
+		},
+		{
+			MethodName: "ConsensusParams",
+			Handler:    _CoreAPI_ConsensusParams_Handler,
+		},
+		{
+			MethodName: "UnconfirmedTxs",
+			Handler:    _CoreAPI_UnconfirmedTxs_Handler,
+		},
+		{
+			MethodName: "NumUnconfirmedTxs",
+			Handler:    _CoreAPI_NumUnconfirmedTxs_Handler,
+		},
+		{
+			MethodName: "CheckTx",
+			Handler:    _CoreAPI_CheckTx_Handler,
+		},
+		{
+			MethodName: "RemoveTx",
+			Handler:    _CoreAPI_RemoveTx_Handler,
+		},
+		{
+			MethodName: "Tx",
+			Handler:    _CoreAPI_Tx_Handler,
+		},
+		{
+			MethodName: "TxSearch",
+			Handler:    _CoreAPI_TxSearch_Handler,
+		},
+		{
+			MethodName: "BlockSearch",
+			Handler:    _CoreAPI_BlockSearch_Handler,
+		},
+		{
+			MethodName: "BroadcastTxAsync",
+			Handler:    _CoreAPI_BroadcastTxAsync_Handler,
+		},
+		{
+			MethodName: "BroadcastTxSync",
+			Handler:    _CoreAPI_BroadcastTxSync_Handler,
+		},
+		{
+			MethodName: "BroadcastTxCommit",
+			Handler:    _CoreAPI_BroadcastTxCommit_Handler,
+		},
+		{
+			MethodName: "ABCIInfo",
+			Handler:    _CoreAPI_ABCIInfo_Handler,
+		},
+		{
+			MethodName: "ABCIQuery",
+			Handler:    _CoreAPI_ABCIQuery_Handler,
+		},
+		{
+			MethodName: "BroadcastEvidence",
+			Handler:    _CoreAPI_BroadcastEvidence_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _CoreAPI_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tendermint/rpc/service.proto",
+}
@@ -231,6 +231,7 @@ type CanonicalVote struct {
 	BlockID   *CanonicalBlockID `protobuf:"bytes,4,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
 	Timestamp time.Time         `protobuf:"bytes,5,opt,name=timestamp,proto3,stdtime" json:"timestamp"`
 	ChainID   string            `protobuf:"bytes,6,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Extension []byte            `protobuf:"bytes,7,opt,name=extension,proto3" json:"extension,omitempty"`
 }
 
 func (m *CanonicalVote) Reset()         { *m = CanonicalVote{} }
@@ -308,6 +309,13 @@ func (m *CanonicalVote) GetChainID() string {
 	return ""
 }
 
+func (m *CanonicalVote) GetExtension() []byte {
+	if m != nil {
+		return m.Extension
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*CanonicalBlockID)(nil), "tendermint.types.CanonicalBlockID")
 	proto.RegisterType((*CanonicalPartSetHeader)(nil), "tendermint.types.CanonicalPartSetHeader")
@@ -318,38 +326,39 @@ func init() {
 func init() { proto.RegisterFile("tendermint/types/canonical.proto", fileDescriptor_8d1a1a84ff7267ed) }
 
 var fileDescriptor_8d1a1a84ff7267ed = []byte{
-	// 487 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x53, 0x3d, 0x6f, 0xd3, 0x40,
-	0x18, 0xce, 0xa5, 0x4e, 0xe2, 0x5c, 0x1b, 0x08, 0xa7, 0xaa, 0xb2, 0x22, 0x64, 0x5b, 0x1e, 0x90,
-	0x59, 0x6c, 0xa9, 0x1d, 0xd8, 0x5d, 0x06, 0x82, 0x40, 0x94, 0x6b, 0xd5, 0x81, 0x25, 0xba, 0xd8,
-	0x87, 0x6d, 0xe1, 0xf8, 0x4e, 0xf6, 0x65, 0xe8, 0xc2, 0x6f, 0xe8, 0xef, 0xe0, 0x97, 0x74, 0xec,
-	0x08, 0x4b, 0x40, 0xce, 0x1f, 0x41, 0x77, 0x4e, 0xec, 0xa8, 0x01, 0x16, 0x10, 0xcb, 0xe9, 0xfd,
-	0x78, 0xee, 0x79, 0x1f, 0x3d, 0xaf, 0x5e, 0x68, 0x0b, 0x9a, 0x47, 0xb4, 0x58, 0xa4, 0xb9, 0xf0,
-	0xc5, 0x0d, 0xa7, 0xa5, 0x1f, 0x92, 0x9c, 0xe5, 0x69, 0x48, 0x32, 0x8f, 0x17, 0x4c, 0x30, 0x34,
-	0x6e, 0x11, 0x9e, 0x42, 0x4c, 0x8e, 0x63, 0x16, 0x33, 0xd5, 0xf4, 0x65, 0x54, 0xe3, 0x26, 0x4f,
-	0xf7, 0x98, 0xd4, 0xbb, 0xe9, 0x5a, 0x31, 0x63, 0x71, 0x46, 0x7d, 0x95, 0xcd, 0x97, 0x1f, 0x7d,
-	0x91, 0x2e, 0x68, 0x29, 0xc8, 0x82, 0xd7, 0x00, 0xe7, 0x33, 0x1c, 0x9f, 0x6f, 0x27, 0x07, 0x19,
-	0x0b, 0x3f, 0x4d, 0x5f, 0x22, 0x04, 0xb5, 0x84, 0x94, 0x89, 0x01, 0x6c, 0xe0, 0x1e, 0x61, 0x15,
-	0xa3, 0x6b, 0xf8, 0x98, 0x93, 0x42, 0xcc, 0x4a, 0x2a, 0x66, 0x09, 0x25, 0x11, 0x2d, 0x8c, 0xae,
-	0x0d, 0xdc, 0xc3, 0x53, 0xd7, 0x7b, 0x28, 0xd4, 0x6b, 0x08, 0x2f, 0x48, 0x21, 0x2e, 0xa9, 0x78,
-	0xa5, 0xf0, 0x81, 0x76, 0xb7, 0xb2, 0x3a, 0x78, 0xc4, 0x77, 0x8b, 0x4e, 0x00, 0x4f, 0x7e, 0x0d,
-	0x47, 0xc7, 0xb0, 0x27, 0x98, 0x20, 0x99, 0x92, 0x31, 0xc2, 0x75, 0xd2, 0x68, 0xeb, 0xb6, 0xda,
-	0x9c, 0x6f, 0x5d, 0xf8, 0xa4, 0x25, 0x29, 0x18, 0x67, 0x25, 0xc9, 0xd0, 0x19, 0xd4, 0xa4, 0x1c,
-	0xf5, 0xfd, 0xd1, 0xa9, 0xb5, 0x2f, 0xf3, 0x32, 0x8d, 0x73, 0x1a, 0xbd, 0x2d, 0xe3, 0xab, 0x1b,
-	0x4e, 0xb1, 0x02, 0xa3, 0x13, 0xd8, 0x4f, 0x68, 0x1a, 0x27, 0x42, 0x0d, 0x18, 0xe3, 0x4d, 0x26,
-	0xc5, 0x14, 0x6c, 0x99, 0x47, 0xc6, 0x81, 0x2a, 0xd7, 0x09, 0x7a, 0x0e, 0x87, 0x9c, 0x65, 0xb3,
-	0xba, 0xa3, 0xd9, 0xc0, 0x3d, 0x08, 0x8e, 0xaa, 0x95, 0xa5, 0x5f, 0xbc, 0x7b, 0x83, 0x65, 0x0d,
-	0xeb, 0x9c, 0x65, 0x2a, 0x42, 0xaf, 0xa1, 0x3e, 0x97, 0xf6, 0xce, 0xd2, 0xc8, 0xe8, 0x29, 0xe3,
-	0x9c, 0x3f, 0x18, 0xb7, 0xd9, 0x44, 0x70, 0x58, 0xad, 0xac, 0xc1, 0x26, 0xc1, 0x03, 0x45, 0x30,
-	0x8d, 0x50, 0x00, 0x87, 0xcd, 0x1a, 0x8d, 0xbe, 0x22, 0x9b, 0x78, 0xf5, 0xa2, 0xbd, 0xed, 0xa2,
-	0xbd, 0xab, 0x2d, 0x22, 0xd0, 0xa5, 0xef, 0xb7, 0xdf, 0x2d, 0x80, 0xdb, 0x6f, 0xe8, 0x19, 0xd4,
-	0xc3, 0x84, 0xa4, 0xb9, 0xd4, 0x33, 0xb0, 0x81, 0x3b, 0xac, 0x67, 0x9d, 0xcb, 0x9a, 0x9c, 0xa5,
-	0x9a, 0xd3, 0xc8, 0xf9, 0xd2, 0x85, 0xa3, 0x46, 0xd6, 0x35, 0x13, 0xf4, 0x7f, 0xf8, 0xba, 0x6b,
-	0x96, 0xf6, 0x2f, 0xcd, 0xea, 0xfd, 0xbd, 0x59, 0xfd, 0xdf, 0x9b, 0x15, 0xbc, 0xbf, 0xab, 0x4c,
-	0x70, 0x5f, 0x99, 0xe0, 0x47, 0x65, 0x82, 0xdb, 0xb5, 0xd9, 0xb9, 0x5f, 0x9b, 0x9d, 0xaf, 0x6b,
-	0xb3, 0xf3, 0xe1, 0x45, 0x9c, 0x8a, 0x64, 0x39, 0xf7, 0x42, 0xb6, 0xf0, 0x77, 0x0f, 0xb6, 0x0d,
-	0xeb, 0xc3, 0x7e, 0x78, 0xcc, 0xf3, 0xbe, 0xaa, 0x9f, 0xfd, 0x0c, 0x00, 0x00, 0xff, 0xff, 0x6d,
-	0xdd, 0x12, 0x5d, 0x31, 0x04, 0x00, 0x00,
+	// 497 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x53, 0xc1, 0x6e, 0xd3, 0x40,
+	0x10, 0x8d, 0x53, 0x27, 0x71, 0xb6, 0x09, 0x84, 0x55, 0x55, 0x59, 0x51, 0x65, 0x5b, 0x3e, 0x20,
+	0x73, 0xb1, 0xa5, 0xf6, 0xc0, 0xdd, 0xe5, 0x40, 0x10, 0x88, 0xb2, 0xad, 0x7a, 0xe0, 0x12, 0x6d,
+	0xec, 0xc5, 0xb6, 0x70, 0xbc, 0x96, 0xbd, 0x91, 0xe8, 0x85, 0x6f, 0xe8, 0x67, 0xe5, 0xd8, 0x23,
+	0x5c, 0x02, 0x72, 0x7e, 0x04, 0xed, 0x38, 0x89, 0xa3, 0x16, 0xb8, 0x80, 0x7a, 0x59, 0xcd, 0xbc,
+	0x79, 0x3b, 0xf3, 0xf4, 0x46, 0x83, 0x2c, 0xc1, 0xb2, 0x90, 0x15, 0xf3, 0x24, 0x13, 0x9e, 0xb8,
+	0xc9, 0x59, 0xe9, 0x05, 0x34, 0xe3, 0x59, 0x12, 0xd0, 0xd4, 0xcd, 0x0b, 0x2e, 0x38, 0x1e, 0x35,
+	0x0c, 0x17, 0x18, 0xe3, 0xa3, 0x88, 0x47, 0x1c, 0x8a, 0x9e, 0x8c, 0x6a, 0xde, 0xf8, 0xe4, 0x41,
+	0x27, 0x78, 0x37, 0x55, 0x33, 0xe2, 0x3c, 0x4a, 0x99, 0x07, 0xd9, 0x6c, 0xf1, 0xc9, 0x13, 0xc9,
+	0x9c, 0x95, 0x82, 0xce, 0xf3, 0x9a, 0x60, 0x7f, 0x45, 0xa3, 0xf3, 0xed, 0x64, 0x3f, 0xe5, 0xc1,
+	0xe7, 0xc9, 0x2b, 0x8c, 0x91, 0x1a, 0xd3, 0x32, 0xd6, 0x15, 0x4b, 0x71, 0x06, 0x04, 0x62, 0x7c,
+	0x8d, 0x9e, 0xe6, 0xb4, 0x10, 0xd3, 0x92, 0x89, 0x69, 0xcc, 0x68, 0xc8, 0x0a, 0xbd, 0x6d, 0x29,
+	0xce, 0xe1, 0xa9, 0xe3, 0xde, 0x17, 0xea, 0xee, 0x1a, 0x5e, 0xd0, 0x42, 0x5c, 0x32, 0xf1, 0x1a,
+	0xf8, 0xbe, 0xba, 0x5c, 0x99, 0x2d, 0x32, 0xcc, 0xf7, 0x41, 0xdb, 0x47, 0xc7, 0xbf, 0xa7, 0xe3,
+	0x23, 0xd4, 0x11, 0x5c, 0xd0, 0x14, 0x64, 0x0c, 0x49, 0x9d, 0xec, 0xb4, 0xb5, 0x1b, 0x6d, 0xf6,
+	0xf7, 0x36, 0x7a, 0xd6, 0x34, 0x29, 0x78, 0xce, 0x4b, 0x9a, 0xe2, 0x33, 0xa4, 0x4a, 0x39, 0xf0,
+	0xfd, 0xc9, 0xa9, 0xf9, 0x50, 0xe6, 0x65, 0x12, 0x65, 0x2c, 0x7c, 0x57, 0x46, 0x57, 0x37, 0x39,
+	0x23, 0x40, 0xc6, 0xc7, 0xa8, 0x1b, 0xb3, 0x24, 0x8a, 0x05, 0x0c, 0x18, 0x91, 0x4d, 0x26, 0xc5,
+	0x14, 0x7c, 0x91, 0x85, 0xfa, 0x01, 0xc0, 0x75, 0x82, 0x5f, 0xa0, 0x7e, 0xce, 0xd3, 0x69, 0x5d,
+	0x51, 0x2d, 0xc5, 0x39, 0xf0, 0x07, 0xd5, 0xca, 0xd4, 0x2e, 0xde, 0xbf, 0x25, 0x12, 0x23, 0x5a,
+	0xce, 0x53, 0x88, 0xf0, 0x1b, 0xa4, 0xcd, 0xa4, 0xbd, 0xd3, 0x24, 0xd4, 0x3b, 0x60, 0x9c, 0xfd,
+	0x17, 0xe3, 0x36, 0x9b, 0xf0, 0x0f, 0xab, 0x95, 0xd9, 0xdb, 0x24, 0xa4, 0x07, 0x0d, 0x26, 0x21,
+	0xf6, 0x51, 0x7f, 0xb7, 0x46, 0xbd, 0x0b, 0xcd, 0xc6, 0x6e, 0xbd, 0x68, 0x77, 0xbb, 0x68, 0xf7,
+	0x6a, 0xcb, 0xf0, 0x35, 0xe9, 0xfb, 0xed, 0x0f, 0x53, 0x21, 0xcd, 0x37, 0xfc, 0x1c, 0x69, 0x41,
+	0x4c, 0x93, 0x4c, 0xea, 0xe9, 0x59, 0x8a, 0xd3, 0xaf, 0x67, 0x9d, 0x4b, 0x4c, 0xce, 0x82, 0xe2,
+	0x24, 0xb4, 0x97, 0x6d, 0x34, 0xdc, 0xc9, 0xba, 0xe6, 0x82, 0x3d, 0x86, 0xaf, 0xfb, 0x66, 0xa9,
+	0xff, 0xd3, 0xac, 0xce, 0xbf, 0x9b, 0xd5, 0xfd, 0xb3, 0x59, 0xf8, 0x04, 0xf5, 0xd9, 0x17, 0xc1,
+	0xb2, 0x32, 0xe1, 0x19, 0xb8, 0x3a, 0x20, 0x0d, 0xe0, 0x7f, 0x58, 0x56, 0x86, 0x72, 0x57, 0x19,
+	0xca, 0xcf, 0xca, 0x50, 0x6e, 0xd7, 0x46, 0xeb, 0x6e, 0x6d, 0xb4, 0xbe, 0xad, 0x8d, 0xd6, 0xc7,
+	0x97, 0x51, 0x22, 0xe2, 0xc5, 0xcc, 0x0d, 0xf8, 0xdc, 0xdb, 0x3f, 0xe7, 0x26, 0xac, 0xcf, 0xfe,
+	0xfe, 0xa9, 0xcf, 0xba, 0x80, 0x9f, 0xfd, 0x1a, 0x00, 0x73, 0xc8, 0x1a, 0x81, 0x4f, 0x04, 0x00,
+	0x00,
 }
 
 func (m *CanonicalBlockID) Marshal() (dAtA []byte, err error) {
@@ -519,6 +528,13 @@ func (m *CanonicalVote) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Extension) > 0 {
+		i -= len(m.Extension)
+		copy(dAtA[i:], m.Extension)
+		i = encodeVarintCanonical(dAtA, i, uint64(len(m.Extension)))
+		i--
+		dAtA[i] = 0x3a
+	}
 	if len(m.ChainID) > 0 {
 		i -= len(m.ChainID)
 		copy(dAtA[i:], m.ChainID)
@@ -664,6 +680,10 @@ func (m *CanonicalVote) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovCanonical(uint64(l))
 	}
+	l = len(m.Extension)
+	if l > 0 {
+		n += 1 + l + sovCanonical(uint64(l))
+	}
 	return n
 }
 
@@ -1271,6 +1291,40 @@ func (m *CanonicalVote) Unmarshal(dAtA []byte) error {
 			}
 			m.ChainID = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Extension", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCanonical
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthCanonical
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthCanonical
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Extension = append(m.Extension[:0], dAtA[iNdEx:postIndex]...)
+			if m.Extension == nil {
+				m.Extension = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCanonical(dAtA[iNdEx:])
@@ -34,6 +34,7 @@ type ConsensusParams struct {
 	Evidence  *EvidenceParams  `protobuf:"bytes,2,opt,name=evidence,proto3" json:"evidence,omitempty"`
 	Validator *ValidatorParams `protobuf:"bytes,3,opt,name=validator,proto3" json:"validator,omitempty"`
 	Version   *VersionParams   `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Feature   *FeatureParams   `protobuf:"bytes,5,opt,name=feature,proto3" json:"feature,omitempty"`
 }
 
 func (m *ConsensusParams) Reset()         { *m = ConsensusParams{} }
@@ -97,6 +98,13 @@ func (m *ConsensusParams) GetVersion() *VersionParams {
 	return nil
 }
 
+func (m *ConsensusParams) GetFeature() *FeatureParams {
+	if m != nil {
+		return m.Feature
+	}
+	return nil
+}
+
 // BlockParams contains limits on the block size.
 type BlockParams struct {
 	// Max block size, in bytes.
@@ -227,10 +235,17 @@ func (m *EvidenceParams) GetMaxBytes() int64 {
 	return 0
 }
 
-// ValidatorParams restrict the public key types validators can use.
+// ValidatorParams restrict the public key types validators can use, and the
+// changes of the validator set in a block.
 // NOTE: uses ABCI pubkey naming, not Amino names.
 type ValidatorParams struct {
 	PubKeyTypes []string `protobuf:"bytes,1,rep,name=pub_key_types,json=pubKeyTypes,proto3" json:"pub_key_types,omitempty"`
+	// Maximum change of the voting power of a validator in a block, in
+	// percent of the total voting power. 0 means no limit.
+	MaxPowerChangePercent uint32 `protobuf:"varint,2,opt,name=max_power_change_percent,json=maxPowerChangePercent,proto3" json:"max_power_change_percent,omitempty"`
+	// Maximum sum of the changes of the voting powers of the validators in a
+	// block, in percent of the total voting power. 0 means no limit.
+	MaxChurnPercent uint32 `protobuf:"varint,3,opt,name=max_churn_percent,json=maxChurnPercent,proto3" json:"max_churn_percent,omitempty"`
 }
 
 func (m *ValidatorParams) Reset()         { *m = ValidatorParams{} }
@@ -273,6 +288,20 @@ func (m *ValidatorParams) GetPubKeyTypes() []string {
 	return nil
 }
 
+func (m *ValidatorParams) GetMaxPowerChangePercent() uint32 {
+	if m != nil {
+		return m.MaxPowerChangePercent
+	}
+	return 0
+}
+
+func (m *ValidatorParams) GetMaxChurnPercent() uint32 {
+	if m != nil {
+		return m.MaxChurnPercent
+	}
+	return 0
+}
+
 // VersionParams contains the ABCI application version.
 type VersionParams struct {
 	AppVersion uint64 `protobuf:"varint,1,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`
@@ -373,6 +402,109 @@ func (m *HashedParams) GetBlockMaxGas() int64 {
 	return 0
 }
 
+// FeatureParams schedule the activation of protocol features at specific
+// heights.
+type FeatureParams struct {
+	// Activation height of each scheduled feature. Features without an
+	// activation are disabled.
+	Activations []FeatureActivation `protobuf:"bytes,1,rep,name=activations,proto3" json:"activations"`
+}
+
+func (m *FeatureParams) Reset()         { *m = FeatureParams{} }
+func (m *FeatureParams) String() string { return proto.CompactTextString(m) }
+func (*FeatureParams) ProtoMessage()    {}
+func (*FeatureParams) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e12598271a686f57, []int{6}
+}
+func (m *FeatureParams) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *FeatureParams) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_FeatureParams.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *FeatureParams) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FeatureParams.Merge(m, src)
+}
+func (m *FeatureParams) XXX_Size() int {
+	return m.Size()
+}
+func (m *FeatureParams) XXX_DiscardUnknown() {
+	xxx_messageInfo_FeatureParams.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FeatureParams proto.InternalMessageInfo
+
+func (m *FeatureParams) GetActivations() []FeatureActivation {
+	if m != nil {
+		return m.Activations
+	}
+	return nil
+}
+
+// FeatureActivation enables the named feature from the given height onwards.
+type FeatureActivation struct {
+	// Name of the feature.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Height from which the feature is enabled. Must be greater than 0.
+	Height int64 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (m *FeatureActivation) Reset()         { *m = FeatureActivation{} }
+func (m *FeatureActivation) String() string { return proto.CompactTextString(m) }
+func (*FeatureActivation) ProtoMessage()    {}
+func (*FeatureActivation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_e12598271a686f57, []int{7}
+}
+func (m *FeatureActivation) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *FeatureActivation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_FeatureActivation.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *FeatureActivation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FeatureActivation.Merge(m, src)
+}
+func (m *FeatureActivation) XXX_Size() int {
+	return m.Size()
+}
+func (m *FeatureActivation) XXX_DiscardUnknown() {
+	xxx_messageInfo_FeatureActivation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FeatureActivation proto.InternalMessageInfo
+
+func (m *FeatureActivation) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *FeatureActivation) GetHeight() int64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ConsensusParams)(nil), "tendermint.types.ConsensusParams")
 	proto.RegisterType((*BlockParams)(nil), "tendermint.types.BlockParams")
@@ -380,44 +512,54 @@ func init() {
 	proto.RegisterType((*ValidatorParams)(nil), "tendermint.types.ValidatorParams")
 	proto.RegisterType((*VersionParams)(nil), "tendermint.types.VersionParams")
 	proto.RegisterType((*HashedParams)(nil), "tendermint.types.HashedParams")
+	proto.RegisterType((*FeatureParams)(nil), "tendermint.types.FeatureParams")
+	proto.RegisterType((*FeatureActivation)(nil), "tendermint.types.FeatureActivation")
 }
 
 func init() { proto.RegisterFile("tendermint/types/params.proto", fileDescriptor_e12598271a686f57) }
 
 var fileDescriptor_e12598271a686f57 = []byte{
-	// 498 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x93, 0xc1, 0x6a, 0xd4, 0x40,
-	0x1c, 0xc6, 0x77, 0x9a, 0xda, 0xee, 0xfe, 0xe3, 0x76, 0xcb, 0x20, 0x18, 0x2b, 0xcd, 0xae, 0x39,
-	0x48, 0x41, 0x48, 0xc4, 0x22, 0x22, 0x08, 0xe2, 0x56, 0xa9, 0x20, 0x15, 0x09, 0xea, 0xa1, 0x97,
-	0x30, 0xd9, 0x8c, 0x69, 0xe8, 0x4e, 0x66, 0xc8, 0x24, 0xcb, 0xee, 0xcd, 0x47, 0xf0, 0xe8, 0x23,
-	0xe8, 0x9b, 0xf4, 0xd8, 0xa3, 0x27, 0x95, 0xdd, 0x17, 0x91, 0x4c, 0x32, 0xa6, 0x9b, 0xf6, 0x36,
-	0x33, 0xdf, 0xef, 0x9b, 0xe1, 0xfb, 0x86, 0x3f, 0xec, 0xe7, 0x34, 0x8d, 0x68, 0xc6, 0x92, 0x34,
-	0xf7, 0xf2, 0x85, 0xa0, 0xd2, 0x13, 0x24, 0x23, 0x4c, 0xba, 0x22, 0xe3, 0x39, 0xc7, 0xbb, 0x8d,
-	0xec, 0x2a, 0x79, 0xef, 0x4e, 0xcc, 0x63, 0xae, 0x44, 0xaf, 0x5c, 0x55, 0xdc, 0x9e, 0x1d, 0x73,
-	0x1e, 0x4f, 0xa9, 0xa7, 0x76, 0x61, 0xf1, 0xc5, 0x8b, 0x8a, 0x8c, 0xe4, 0x09, 0x4f, 0x2b, 0xdd,
-	0xf9, 0xba, 0x01, 0x83, 0x23, 0x9e, 0x4a, 0x9a, 0xca, 0x42, 0x7e, 0x50, 0x2f, 0xe0, 0x43, 0xb8,
-	0x15, 0x4e, 0xf9, 0xe4, 0xdc, 0x42, 0x23, 0x74, 0x60, 0x3e, 0xd9, 0x77, 0xdb, 0x6f, 0xb9, 0xe3,
-	0x52, 0xae, 0x68, 0xbf, 0x62, 0xf1, 0x0b, 0xe8, 0xd2, 0x59, 0x12, 0xd1, 0x74, 0x42, 0xad, 0x0d,
-	0xe5, 0x1b, 0x5d, 0xf7, 0xbd, 0xa9, 0x89, 0xda, 0xfa, 0xdf, 0x81, 0x5f, 0x42, 0x6f, 0x46, 0xa6,
-	0x49, 0x44, 0x72, 0x9e, 0x59, 0x86, 0xb2, 0x3f, 0xb8, 0x6e, 0xff, 0xac, 0x91, 0xda, 0xdf, 0x78,
-	0xf0, 0x73, 0xd8, 0x9e, 0xd1, 0x4c, 0x26, 0x3c, 0xb5, 0x36, 0x95, 0x7d, 0x78, 0x83, 0xbd, 0x02,
-	0x6a, 0xb3, 0xe6, 0x9d, 0x23, 0x30, 0xaf, 0xe4, 0xc1, 0xf7, 0xa1, 0xc7, 0xc8, 0x3c, 0x08, 0x17,
-	0x39, 0x95, 0xaa, 0x01, 0xc3, 0xef, 0x32, 0x32, 0x1f, 0x97, 0x7b, 0x7c, 0x17, 0xb6, 0x4b, 0x31,
-	0x26, 0x52, 0x85, 0x34, 0xfc, 0x2d, 0x46, 0xe6, 0xc7, 0x44, 0x3a, 0x3f, 0x11, 0xec, 0xac, 0xa7,
-	0xc3, 0x8f, 0x00, 0x97, 0x2c, 0x89, 0x69, 0x90, 0x16, 0x2c, 0x50, 0x35, 0xe9, 0x1b, 0x07, 0x8c,
-	0xcc, 0x5f, 0xc5, 0xf4, 0x7d, 0xc1, 0xd4, 0xd3, 0x12, 0x9f, 0xc0, 0xae, 0x86, 0xf5, 0x0f, 0xd5,
-	0x35, 0xde, 0x73, 0xab, 0x2f, 0x74, 0xf5, 0x17, 0xba, 0xaf, 0x6b, 0x60, 0xdc, 0xbd, 0xf8, 0x3d,
-	0xec, 0x7c, 0xff, 0x33, 0x44, 0xfe, 0x4e, 0x75, 0x9f, 0x56, 0xd6, 0x43, 0x18, 0xeb, 0x21, 0x9c,
-	0xa7, 0x30, 0x68, 0x35, 0x89, 0x1d, 0xe8, 0x8b, 0x22, 0x0c, 0xce, 0xe9, 0x22, 0x50, 0x5d, 0x59,
-	0x68, 0x64, 0x1c, 0xf4, 0x7c, 0x53, 0x14, 0xe1, 0x3b, 0xba, 0xf8, 0x58, 0x1e, 0x39, 0x8f, 0xa1,
-	0xbf, 0xd6, 0x20, 0x1e, 0x82, 0x49, 0x84, 0x08, 0x74, 0xef, 0x65, 0xb2, 0x4d, 0x1f, 0x88, 0x10,
-	0x35, 0xe6, 0x9c, 0xc2, 0xed, 0xb7, 0x44, 0x9e, 0xd1, 0xa8, 0x36, 0x3c, 0x84, 0x81, 0x6a, 0x21,
-	0x68, 0x17, 0xdc, 0x57, 0xc7, 0x27, 0xba, 0x65, 0x07, 0xfa, 0x0d, 0xd7, 0x74, 0x6d, 0x6a, 0xea,
-	0x98, 0xc8, 0xf1, 0xa7, 0x1f, 0x4b, 0x1b, 0x5d, 0x2c, 0x6d, 0x74, 0xb9, 0xb4, 0xd1, 0xdf, 0xa5,
-	0x8d, 0xbe, 0xad, 0xec, 0xce, 0xe5, 0xca, 0xee, 0xfc, 0x5a, 0xd9, 0x9d, 0xd3, 0x67, 0x71, 0x92,
-	0x9f, 0x15, 0xa1, 0x3b, 0xe1, 0xcc, 0xbb, 0x3a, 0x48, 0xcd, 0xb2, 0x9a, 0x94, 0xf6, 0x90, 0x85,
-	0x5b, 0xea, 0xfc, 0xf0, 0x5f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x18, 0x54, 0x4f, 0xe1, 0x7f, 0x03,
-	0x00, 0x00,
+	// 628 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x94, 0xdd, 0x6e, 0xd3, 0x30,
+	0x1c, 0xc5, 0x9b, 0xb5, 0xfb, 0xe8, 0x3f, 0x74, 0xdd, 0x2c, 0x3e, 0xc2, 0xd0, 0xd2, 0x11, 0x24,
+	0x34, 0x81, 0x94, 0xa2, 0xed, 0x62, 0x42, 0x42, 0x9a, 0xd6, 0xf2, 0x25, 0x4d, 0x43, 0x53, 0x04,
+	0x5c, 0x4c, 0x48, 0x91, 0x93, 0x7a, 0x69, 0xb4, 0xc5, 0x8e, 0x12, 0xa7, 0xb4, 0x6f, 0xc1, 0x25,
+	0xe2, 0x09, 0xe0, 0x19, 0x78, 0x81, 0x5d, 0xee, 0x92, 0x2b, 0x40, 0xdd, 0x8b, 0x20, 0xdb, 0xf1,
+	0xba, 0x76, 0xec, 0xce, 0xf6, 0x39, 0x3f, 0x3b, 0x39, 0xc7, 0x32, 0xac, 0x73, 0x42, 0x7b, 0x24,
+	0x4b, 0x62, 0xca, 0xdb, 0x7c, 0x94, 0x92, 0xbc, 0x9d, 0xe2, 0x0c, 0x27, 0xb9, 0x9b, 0x66, 0x8c,
+	0x33, 0xb4, 0x32, 0x91, 0x5d, 0x29, 0xaf, 0xdd, 0x8e, 0x58, 0xc4, 0xa4, 0xd8, 0x16, 0x23, 0xe5,
+	0x5b, 0xb3, 0x23, 0xc6, 0xa2, 0x53, 0xd2, 0x96, 0xb3, 0xa0, 0x38, 0x6e, 0xf7, 0x8a, 0x0c, 0xf3,
+	0x98, 0x51, 0xa5, 0x3b, 0x3f, 0xe7, 0xa0, 0xd9, 0x65, 0x34, 0x27, 0x34, 0x2f, 0xf2, 0x43, 0x79,
+	0x02, 0xda, 0x86, 0xf9, 0xe0, 0x94, 0x85, 0x27, 0x96, 0xb1, 0x61, 0x6c, 0x9a, 0x5b, 0xeb, 0xee,
+	0xec, 0x59, 0x6e, 0x47, 0xc8, 0xca, 0xed, 0x29, 0x2f, 0x7a, 0x01, 0x4b, 0x64, 0x10, 0xf7, 0x08,
+	0x0d, 0x89, 0x35, 0x27, 0xb9, 0x8d, 0xeb, 0xdc, 0xab, 0xd2, 0x51, 0xa2, 0x97, 0x04, 0xda, 0x85,
+	0xfa, 0x00, 0x9f, 0xc6, 0x3d, 0xcc, 0x59, 0x66, 0x55, 0x25, 0xfe, 0xf0, 0x3a, 0xfe, 0x51, 0x5b,
+	0x4a, 0x7e, 0xc2, 0xa0, 0xe7, 0xb0, 0x38, 0x20, 0x59, 0x1e, 0x33, 0x6a, 0xd5, 0x24, 0xde, 0xfa,
+	0x0f, 0xae, 0x0c, 0x25, 0xac, 0xfd, 0x02, 0x3d, 0x26, 0x98, 0x17, 0x19, 0xb1, 0xe6, 0x6f, 0x42,
+	0x5f, 0x2b, 0x83, 0x46, 0x4b, 0xbf, 0xd3, 0x05, 0xf3, 0x4a, 0x14, 0xe8, 0x01, 0xd4, 0x13, 0x3c,
+	0xf4, 0x83, 0x11, 0x27, 0xb9, 0x0c, 0xaf, 0xea, 0x2d, 0x25, 0x78, 0xd8, 0x11, 0x73, 0x74, 0x0f,
+	0x16, 0x85, 0x18, 0xe1, 0x5c, 0xe6, 0x53, 0xf5, 0x16, 0x12, 0x3c, 0x7c, 0x83, 0x73, 0xe7, 0x87,
+	0x01, 0xcb, 0xd3, 0xc1, 0xa0, 0xa7, 0x80, 0x84, 0x17, 0x47, 0xc4, 0xa7, 0x45, 0xe2, 0xcb, 0x84,
+	0xf5, 0x8e, 0xcd, 0x04, 0x0f, 0xf7, 0x22, 0xf2, 0xae, 0x48, 0xe4, 0xd1, 0x39, 0x3a, 0x80, 0x15,
+	0x6d, 0xd6, 0xe5, 0x96, 0x0d, 0xdc, 0x77, 0x55, 0xfb, 0xae, 0x6e, 0xdf, 0x7d, 0x59, 0x1a, 0x3a,
+	0x4b, 0x67, 0xbf, 0x5b, 0x95, 0xaf, 0x7f, 0x5a, 0x86, 0xb7, 0xac, 0xf6, 0xd3, 0xca, 0xf4, 0x4f,
+	0x54, 0xa7, 0x7f, 0xc2, 0xf9, 0x66, 0x40, 0x73, 0xa6, 0x05, 0xe4, 0x40, 0x23, 0x2d, 0x02, 0xff,
+	0x84, 0x8c, 0x7c, 0x19, 0x96, 0x65, 0x6c, 0x54, 0x37, 0xeb, 0x9e, 0x99, 0x16, 0xc1, 0x3e, 0x19,
+	0xbd, 0x17, 0x4b, 0x68, 0x07, 0x2c, 0xb1, 0x69, 0xca, 0x3e, 0x93, 0xcc, 0x0f, 0xfb, 0x98, 0x46,
+	0xc4, 0x4f, 0x49, 0x16, 0x12, 0xca, 0xe5, 0xb7, 0x36, 0xbc, 0x3b, 0x09, 0x1e, 0x1e, 0x0a, 0xb9,
+	0x2b, 0xd5, 0x43, 0x25, 0xa2, 0x27, 0xb0, 0x2a, 0xc0, 0xb0, 0x5f, 0x64, 0xf4, 0x92, 0xa8, 0x4a,
+	0x42, 0x04, 0xd1, 0x15, 0xeb, 0xa5, 0xd7, 0x79, 0x06, 0x8d, 0xa9, 0x8a, 0x51, 0x0b, 0x4c, 0x9c,
+	0xa6, 0xbe, 0xbe, 0x18, 0x22, 0xbf, 0x9a, 0x07, 0x38, 0x4d, 0x4b, 0x9b, 0x73, 0x04, 0xb7, 0xde,
+	0xe2, 0xbc, 0x4f, 0x7a, 0x25, 0xf0, 0x18, 0x9a, 0x32, 0x6b, 0x7f, 0xb6, 0xc6, 0x86, 0x5c, 0x3e,
+	0xd0, 0x5d, 0x3a, 0xd0, 0x98, 0xf8, 0x26, 0x8d, 0x9a, 0xda, 0x25, 0x6a, 0xfd, 0x04, 0x8d, 0xa9,
+	0x5b, 0x83, 0xf6, 0xc1, 0xc4, 0x21, 0x8f, 0x07, 0x32, 0x66, 0x95, 0x92, 0xb9, 0xf5, 0xe8, 0xc6,
+	0xbb, 0xb6, 0x77, 0xe9, 0xed, 0xd4, 0x44, 0x59, 0xde, 0x55, 0xda, 0xd9, 0x85, 0xd5, 0x6b, 0x3e,
+	0x84, 0xa0, 0x46, 0x71, 0x42, 0xe4, 0x37, 0xd7, 0x3d, 0x39, 0x46, 0x77, 0x61, 0xa1, 0x4f, 0xe2,
+	0xa8, 0xcf, 0xf5, 0xad, 0x53, 0xb3, 0xce, 0x87, 0xef, 0x63, 0xdb, 0x38, 0x1b, 0xdb, 0xc6, 0xf9,
+	0xd8, 0x36, 0xfe, 0x8e, 0x6d, 0xe3, 0xcb, 0x85, 0x5d, 0x39, 0xbf, 0xb0, 0x2b, 0xbf, 0x2e, 0xec,
+	0xca, 0xd1, 0x4e, 0x14, 0xf3, 0x7e, 0x11, 0xb8, 0x21, 0x4b, 0xda, 0x57, 0x1f, 0xa2, 0xc9, 0x50,
+	0xbd, 0x34, 0xb3, 0x8f, 0x54, 0xb0, 0x20, 0xd7, 0xb7, 0xff, 0x0d, 0x00, 0x0a, 0x35, 0x42, 0x0f,
+	0xbf, 0x04, 0x00, 0x00,
 }
 
 func (this *ConsensusParams) Equal(that interface{}) bool {
@@ -451,6 +593,9 @@ func (this *ConsensusParams) Equal(that interface{}) bool {
 	if !this.Version.Equal(that1.Version) {
 		return false
 	}
+	if !this.Feature.Equal(that1.Feature) {
+		return false
+	}
 	return true
 }
 func (this *BlockParams) Equal(that interface{}) bool {
@@ -537,6 +682,12 @@ func (this *ValidatorParams) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.MaxPowerChangePercent != that1.MaxPowerChangePercent {
+		return false
+	}
+	if this.MaxChurnPercent != that1.MaxChurnPercent {
+		return false
+	}
 	return true
 }
 func (this *VersionParams) Equal(that interface{}) bool {
@@ -590,6 +741,62 @@ func (this *HashedParams) Equal(that interface{}) bool {
 	}
 	return true
 }
+func (this *FeatureParams) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*FeatureParams)
+	if !ok {
+		that2, ok := that.(FeatureParams)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.Activations) != len(that1.Activations) {
+		return false
+	}
+	for i := range this.Activations {
+		if !this.Activations[i].Equal(&that1.Activations[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *FeatureActivation) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*FeatureActivation)
+	if !ok {
+		that2, ok := that.(FeatureActivation)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Height != that1.Height {
+		return false
+	}
+	return true
+}
 func (m *ConsensusParams) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -610,6 +817,18 @@ func (m *ConsensusParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.Feature != nil {
+		{
+			size, err := m.Feature.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintParams(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
+	}
 	if m.Version != nil {
 		{
 			size, err := m.Version.MarshalToSizedBuffer(dAtA[:i])
@@ -719,12 +938,12 @@ func (m *EvidenceParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i--
 		dAtA[i] = 0x18
 	}
-	n5, err5 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxAgeDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxAgeDuration):])
-	if err5 != nil {
-		return 0, err5
+	n6, err6 := github_com_gogo_protobuf_types.StdDurationMarshalTo(m.MaxAgeDuration, dAtA[i-github_com_gogo_protobuf_types.SizeOfStdDuration(m.MaxAgeDuration):])
+	if err6 != nil {
+		return 0, err6
 	}
-	i -= n5
-	i = encodeVarintParams(dAtA, i, uint64(n5))
+	i -= n6
+	i = encodeVarintParams(dAtA, i, uint64(n6))
 	i--
 	dAtA[i] = 0x12
 	if m.MaxAgeNumBlocks != 0 {
@@ -755,6 +974,16 @@ func (m *ValidatorParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.MaxChurnPercent != 0 {
+		i = encodeVarintParams(dAtA, i, uint64(m.MaxChurnPercent))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.MaxPowerChangePercent != 0 {
+		i = encodeVarintParams(dAtA, i, uint64(m.MaxPowerChangePercent))
+		i--
+		dAtA[i] = 0x10
+	}
 	if len(m.PubKeyTypes) > 0 {
 		for iNdEx := len(m.PubKeyTypes) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.PubKeyTypes[iNdEx])
@@ -828,6 +1057,78 @@ func (m *HashedParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *FeatureParams) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FeatureParams) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *FeatureParams) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Activations) > 0 {
+		for iNdEx := len(m.Activations) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Activations[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintParams(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *FeatureActivation) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FeatureActivation) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *FeatureActivation) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Height != 0 {
+		i = encodeVarintParams(dAtA, i, uint64(m.Height))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintParams(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintParams(dAtA []byte, offset int, v uint64) int {
 	offset -= sovParams(v)
 	base := offset
@@ -861,6 +1162,10 @@ func (m *ConsensusParams) Size() (n int) {
 		l = m.Version.Size()
 		n += 1 + l + sovParams(uint64(l))
 	}
+	if m.Feature != nil {
+		l = m.Feature.Size()
+		n += 1 + l + sovParams(uint64(l))
+	}
 	return n
 }
 
@@ -908,6 +1213,12 @@ func (m *ValidatorParams) Size() (n int) {
 			n += 1 + l + sovParams(uint64(l))
 		}
 	}
+	if m.MaxPowerChangePercent != 0 {
+		n += 1 + sovParams(uint64(m.MaxPowerChangePercent))
+	}
+	if m.MaxChurnPercent != 0 {
+		n += 1 + sovParams(uint64(m.MaxChurnPercent))
+	}
 	return n
 }
 
@@ -938,6 +1249,37 @@ func (m *HashedParams) Size() (n int) {
 	return n
 }
 
+func (m *FeatureParams) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Activations) > 0 {
+		for _, e := range m.Activations {
+			l = e.Size()
+			n += 1 + l + sovParams(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *FeatureActivation) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovParams(uint64(l))
+	}
+	if m.Height != 0 {
+		n += 1 + sovParams(uint64(m.Height))
+	}
+	return n
+}
+
 func sovParams(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -1117,6 +1459,42 @@ func (m *ConsensusParams) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Feature", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Feature == nil {
+				m.Feature = &FeatureParams{}
+			}
+			if err := m.Feature.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipParams(dAtA[iNdEx:])
@@ -1408,6 +1786,44 @@ func (m *ValidatorParams) Unmarshal(dAtA []byte) error {
 			}
 			m.PubKeyTypes = append(m.PubKeyTypes, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxPowerChangePercent", wireType)
+			}
+			m.MaxPowerChangePercent = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxPowerChangePercent |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxChurnPercent", wireType)
+			}
+			m.MaxChurnPercent = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxChurnPercent |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipParams(dAtA[iNdEx:])
@@ -1586,6 +2002,191 @@ func (m *HashedParams) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *FeatureParams) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowParams
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FeatureParams: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FeatureParams: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Activations", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Activations = append(m.Activations, FeatureActivation{})
+			if err := m.Activations[len(m.Activations)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipParams(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthParams
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FeatureActivation) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowParams
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FeatureActivation: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FeatureActivation: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthParams
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthParams
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Height", wireType)
+			}
+			m.Height = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowParams
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Height |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipParams(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthParams
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipParams(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
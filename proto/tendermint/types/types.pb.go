@@ -474,6 +474,7 @@ type Vote struct {
 	ValidatorAddress []byte        `protobuf:"bytes,6,opt,name=validator_address,json=validatorAddress,proto3" json:"validator_address,omitempty"`
 	ValidatorIndex   int32         `protobuf:"varint,7,opt,name=validator_index,json=validatorIndex,proto3" json:"validator_index,omitempty"`
 	Signature        []byte        `protobuf:"bytes,8,opt,name=signature,proto3" json:"signature,omitempty"`
+	Extension        []byte        `protobuf:"bytes,9,opt,name=extension,proto3" json:"extension,omitempty"`
 }
 
 func (m *Vote) Reset()         { *m = Vote{} }
@@ -565,6 +566,13 @@ func (m *Vote) GetSignature() []byte {
 	return nil
 }
 
+func (m *Vote) GetExtension() []byte {
+	if m != nil {
+		return m.Extension
+	}
+	return nil
+}
+
 // Commit contains the evidence that a block was committed by a set of
 // validators.
 type Commit struct {
@@ -641,6 +649,7 @@ type CommitSig struct {
 	ValidatorAddress []byte      `protobuf:"bytes,2,opt,name=validator_address,json=validatorAddress,proto3" json:"validator_address,omitempty"`
 	Timestamp        time.Time   `protobuf:"bytes,3,opt,name=timestamp,proto3,stdtime" json:"timestamp"`
 	Signature        []byte      `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	Extension        []byte      `protobuf:"bytes,5,opt,name=extension,proto3" json:"extension,omitempty"`
 }
 
 func (m *CommitSig) Reset()         { *m = CommitSig{} }
@@ -704,6 +713,13 @@ func (m *CommitSig) GetSignature() []byte {
 	return nil
 }
 
+func (m *CommitSig) GetExtension() []byte {
+	if m != nil {
+		return m.Extension
+	}
+	return nil
+}
+
 type Proposal struct {
 	Type      SignedMsgType `protobuf:"varint,1,opt,name=type,proto3,enum=tendermint.types.SignedMsgType" json:"type,omitempty"`
 	Height    int64         `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
@@ -1051,90 +1067,91 @@ func init() {
 func init() { proto.RegisterFile("tendermint/types/types.proto", fileDescriptor_d3a6e55e2345de56) }
 
 var fileDescriptor_d3a6e55e2345de56 = []byte{
-	// 1314 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0x4f, 0x6f, 0x1b, 0x45,
-	0x14, 0xcf, 0xda, 0x9b, 0xd8, 0x7e, 0xb6, 0x13, 0x67, 0x95, 0xb6, 0xae, 0xdb, 0x38, 0x2b, 0x23,
-	0x20, 0x2d, 0x68, 0x53, 0x52, 0xc4, 0x9f, 0x03, 0x07, 0xdb, 0x49, 0x5b, 0xab, 0x89, 0x63, 0xd6,
-	0x6e, 0x11, 0x5c, 0x56, 0x6b, 0xef, 0xd4, 0x5e, 0xba, 0xde, 0x59, 0xed, 0x8c, 0x43, 0xd2, 0x4f,
-	0x80, 0x72, 0xea, 0x89, 0x5b, 0x4e, 0x70, 0xe0, 0xce, 0x17, 0x40, 0x9c, 0x7a, 0xec, 0x0d, 0x2e,
-	0x14, 0x94, 0x4a, 0x88, 0x8f, 0x81, 0xe6, 0x8f, 0xd7, 0xeb, 0x38, 0x86, 0xaa, 0xaa, 0xb8, 0x58,
-	0x3b, 0xef, 0xfd, 0xde, 0xcc, 0x7b, 0xbf, 0xf7, 0x9b, 0x3f, 0x86, 0xeb, 0x14, 0xf9, 0x0e, 0x0a,
-	0x87, 0xae, 0x4f, 0xb7, 0xe8, 0x71, 0x80, 0x88, 0xf8, 0x35, 0x82, 0x10, 0x53, 0xac, 0x15, 0x26,
-	0x5e, 0x83, 0xdb, 0x4b, 0x6b, 0x7d, 0xdc, 0xc7, 0xdc, 0xb9, 0xc5, 0xbe, 0x04, 0xae, 0xb4, 0xd1,
-	0xc7, 0xb8, 0xef, 0xa1, 0x2d, 0x3e, 0xea, 0x8e, 0x1e, 0x6d, 0x51, 0x77, 0x88, 0x08, 0xb5, 0x87,
-	0x81, 0x04, 0xac, 0xc7, 0x96, 0xe9, 0x85, 0xc7, 0x01, 0xc5, 0x0c, 0x8b, 0x1f, 0x49, 0x77, 0x39,
-	0xe6, 0x3e, 0x44, 0x21, 0x71, 0xb1, 0x1f, 0xcf, 0xa3, 0xa4, 0xcf, 0x64, 0x79, 0x68, 0x7b, 0xae,
-	0x63, 0x53, 0x1c, 0x0a, 0x44, 0xe5, 0x53, 0xc8, 0xb7, 0xec, 0x90, 0xb6, 0x11, 0xbd, 0x87, 0x6c,
-	0x07, 0x85, 0xda, 0x1a, 0x2c, 0x52, 0x4c, 0x6d, 0xaf, 0xa8, 0xe8, 0xca, 0x66, 0xde, 0x14, 0x03,
-	0x4d, 0x03, 0x75, 0x60, 0x93, 0x41, 0x31, 0xa1, 0x2b, 0x9b, 0x39, 0x93, 0x7f, 0x57, 0x06, 0xa0,
-	0xb2, 0x50, 0x16, 0xe1, 0xfa, 0x0e, 0x3a, 0x1a, 0x47, 0xf0, 0x01, 0xb3, 0x76, 0x8f, 0x29, 0x22,
-	0x32, 0x44, 0x0c, 0xb4, 0x0f, 0x61, 0x91, 0xe7, 0x5f, 0x4c, 0xea, 0xca, 0x66, 0x76, 0xbb, 0x68,
-	0xc4, 0x88, 0x12, 0xf5, 0x19, 0x2d, 0xe6, 0xaf, 0xa9, 0xcf, 0x5e, 0x6c, 0x2c, 0x98, 0x02, 0x5c,
-	0xf1, 0x20, 0x55, 0xf3, 0x70, 0xef, 0x71, 0x63, 0x27, 0x4a, 0x44, 0x99, 0x24, 0xa2, 0xed, 0xc3,
-	0x4a, 0x60, 0x87, 0xd4, 0x22, 0x88, 0x5a, 0x03, 0x5e, 0x05, 0x5f, 0x34, 0xbb, 0xbd, 0x61, 0x9c,
-	0xef, 0x83, 0x31, 0x55, 0xac, 0x5c, 0x25, 0x1f, 0xc4, 0x8d, 0x95, 0xbf, 0x54, 0x58, 0x92, 0x64,
-	0x7c, 0x06, 0x29, 0x49, 0x2b, 0x5f, 0x30, 0xbb, 0xbd, 0x1e, 0x9f, 0x51, 0xba, 0x8c, 0x3a, 0xf6,
-	0x09, 0xf2, 0xc9, 0x88, 0xc8, 0xf9, 0xc6, 0x31, 0xda, 0x3b, 0x90, 0xee, 0x0d, 0x6c, 0xd7, 0xb7,
-	0x5c, 0x87, 0x67, 0x94, 0xa9, 0x65, 0xcf, 0x5e, 0x6c, 0xa4, 0xea, 0xcc, 0xd6, 0xd8, 0x31, 0x53,
-	0xdc, 0xd9, 0x70, 0xb4, 0xcb, 0xb0, 0x34, 0x40, 0x6e, 0x7f, 0x40, 0x39, 0x2d, 0x49, 0x53, 0x8e,
-	0xb4, 0x4f, 0x40, 0x65, 0x82, 0x28, 0xaa, 0x7c, 0xed, 0x92, 0x21, 0xd4, 0x62, 0x8c, 0xd5, 0x62,
-	0x74, 0xc6, 0x6a, 0xa9, 0xa5, 0xd9, 0xc2, 0x4f, 0xff, 0xd8, 0x50, 0x4c, 0x1e, 0xa1, 0xd5, 0x21,
-	0xef, 0xd9, 0x84, 0x5a, 0x5d, 0x46, 0x1b, 0x5b, 0x7e, 0x91, 0x4f, 0x71, 0x75, 0x96, 0x10, 0x49,
-	0xac, 0x4c, 0x3d, 0xcb, 0xa2, 0x84, 0xc9, 0xd1, 0x36, 0xa1, 0xc0, 0x27, 0xe9, 0xe1, 0xe1, 0xd0,
-	0xa5, 0x16, 0xe7, 0x7d, 0x89, 0xf3, 0xbe, 0xcc, 0xec, 0x75, 0x6e, 0xbe, 0xc7, 0x3a, 0x70, 0x0d,
-	0x32, 0x8e, 0x4d, 0x6d, 0x01, 0x49, 0x71, 0x48, 0x9a, 0x19, 0xb8, 0xf3, 0x5d, 0x58, 0x89, 0x54,
-	0x47, 0x04, 0x24, 0x2d, 0x66, 0x99, 0x98, 0x39, 0xf0, 0x16, 0xac, 0xf9, 0xe8, 0x88, 0x5a, 0xe7,
-	0xd1, 0x19, 0x8e, 0xd6, 0x98, 0xef, 0xe1, 0x74, 0xc4, 0xdb, 0xb0, 0xdc, 0x1b, 0x93, 0x2f, 0xb0,
-	0xc0, 0xb1, 0xf9, 0xc8, 0xca, 0x61, 0x57, 0x21, 0x6d, 0x07, 0x81, 0x00, 0x64, 0x39, 0x20, 0x65,
-	0x07, 0x01, 0x77, 0xdd, 0x84, 0x55, 0x5e, 0x63, 0x88, 0xc8, 0xc8, 0xa3, 0x72, 0x92, 0x1c, 0xc7,
-	0xac, 0x30, 0x87, 0x29, 0xec, 0x1c, 0xfb, 0x16, 0xe4, 0xd1, 0xa1, 0xeb, 0x20, 0xbf, 0x87, 0x04,
-	0x2e, 0xcf, 0x71, 0xb9, 0xb1, 0x91, 0x83, 0x6e, 0x40, 0x21, 0x08, 0x71, 0x80, 0x09, 0x0a, 0x2d,
-	0xdb, 0x71, 0x42, 0x44, 0x48, 0x71, 0x59, 0xcc, 0x37, 0xb6, 0x57, 0x85, 0xb9, 0x52, 0x04, 0x75,
-	0xc7, 0xa6, 0xb6, 0x56, 0x80, 0x24, 0x3d, 0x22, 0x45, 0x45, 0x4f, 0x6e, 0xe6, 0x4c, 0xf6, 0x59,
-	0xf9, 0x3b, 0x01, 0xea, 0x43, 0x4c, 0x91, 0x76, 0x1b, 0x54, 0xd6, 0x26, 0xae, 0xbe, 0xe5, 0x8b,
-	0xf4, 0xdc, 0x76, 0xfb, 0x3e, 0x72, 0xf6, 0x49, 0xbf, 0x73, 0x1c, 0x20, 0x93, 0x83, 0x63, 0x72,
-	0x4a, 0x4c, 0xc9, 0x69, 0x0d, 0x16, 0x43, 0x3c, 0xf2, 0x1d, 0xae, 0xb2, 0x45, 0x53, 0x0c, 0xb4,
-	0x5d, 0x48, 0x47, 0x2a, 0x51, 0xff, 0x4b, 0x25, 0x2b, 0x4c, 0x25, 0x4c, 0xc3, 0xd2, 0x60, 0xa6,
-	0xba, 0x52, 0x2c, 0x35, 0xc8, 0x44, 0x87, 0x97, 0x54, 0xdb, 0xab, 0x09, 0x76, 0x12, 0xa6, 0xbd,
-	0x07, 0xab, 0x51, 0xef, 0x23, 0xf2, 0x84, 0xe2, 0x0a, 0x91, 0x43, 0xb2, 0x37, 0x25, 0x2b, 0x4b,
-	0x1c, 0x40, 0x29, 0x5e, 0xd7, 0x44, 0x56, 0x0d, 0x7e, 0x12, 0x5d, 0x87, 0x0c, 0x71, 0xfb, 0xbe,
-	0x4d, 0x47, 0x21, 0x92, 0xca, 0x9b, 0x18, 0x2a, 0x3f, 0x2b, 0xb0, 0x24, 0x94, 0x1c, 0xe3, 0x4d,
-	0xb9, 0x98, 0xb7, 0xc4, 0x3c, 0xde, 0x92, 0xaf, 0xcf, 0x5b, 0x15, 0x20, 0x4a, 0x86, 0x14, 0x55,
-	0x3d, 0xb9, 0x99, 0xdd, 0xbe, 0x36, 0x3b, 0x91, 0x48, 0xb1, 0xed, 0xf6, 0xe5, 0x46, 0x8d, 0x05,
-	0x55, 0x7e, 0x57, 0x20, 0x13, 0xf9, 0xb5, 0x2a, 0xe4, 0xc7, 0x79, 0x59, 0x8f, 0x3c, 0xbb, 0x2f,
-	0xb5, 0xb3, 0x3e, 0x37, 0xb9, 0x3b, 0x9e, 0xdd, 0x37, 0xb3, 0x32, 0x1f, 0x36, 0xb8, 0xb8, 0x0f,
-	0x89, 0x39, 0x7d, 0x98, 0x6a, 0x7c, 0xf2, 0xf5, 0x1a, 0x3f, 0xd5, 0x22, 0xf5, 0x7c, 0x8b, 0x7e,
-	0x4a, 0x40, 0xba, 0xc5, 0xf7, 0x8e, 0xed, 0xfd, 0x1f, 0x3b, 0xe2, 0x1a, 0x64, 0x02, 0xec, 0x59,
-	0xc2, 0xa3, 0x72, 0x4f, 0x3a, 0xc0, 0x9e, 0x39, 0xd3, 0xf6, 0xc5, 0x37, 0xb4, 0x5d, 0x96, 0xde,
-	0x00, 0x6b, 0xa9, 0xf3, 0xac, 0x85, 0x90, 0x13, 0x54, 0xc8, 0xbb, 0xec, 0x16, 0xe3, 0x80, 0x5f,
-	0x8e, 0xca, 0xec, 0xdd, 0x2b, 0xd2, 0x16, 0x48, 0x53, 0xe2, 0x58, 0x84, 0x38, 0xfa, 0xe5, 0x75,
-	0x5a, 0x9c, 0x27, 0x4b, 0x53, 0xe2, 0x2a, 0xdf, 0x29, 0x00, 0x7b, 0x8c, 0x59, 0x5e, 0x2f, 0xbb,
-	0x85, 0x08, 0x4f, 0xc1, 0x9a, 0x5a, 0xb9, 0x3c, 0xaf, 0x69, 0x72, 0xfd, 0x1c, 0x89, 0xe7, 0x5d,
-	0x87, 0xfc, 0x44, 0x8c, 0x04, 0x8d, 0x93, 0xb9, 0x60, 0x92, 0xe8, 0x72, 0x68, 0x23, 0x6a, 0xe6,
-	0x0e, 0x63, 0xa3, 0xca, 0x2f, 0x0a, 0x64, 0x78, 0x4e, 0xfb, 0x88, 0xda, 0x53, 0x3d, 0x54, 0x5e,
-	0xbf, 0x87, 0xeb, 0x00, 0x62, 0x1a, 0xe2, 0x3e, 0x41, 0x52, 0x59, 0x19, 0x6e, 0x69, 0xbb, 0x4f,
-	0x90, 0xf6, 0x51, 0x44, 0x78, 0xf2, 0xdf, 0x09, 0x97, 0x5b, 0x7a, 0x4c, 0xfb, 0x15, 0x48, 0xf9,
-	0xa3, 0xa1, 0xc5, 0xae, 0x04, 0x55, 0xa8, 0xd5, 0x1f, 0x0d, 0x3b, 0x47, 0xa4, 0xf2, 0x35, 0xa4,
-	0x3a, 0x47, 0xfc, 0x79, 0xc4, 0x24, 0x1a, 0x62, 0x2c, 0xef, 0x64, 0xf1, 0x16, 0x4a, 0x33, 0x03,
-	0xbf, 0x82, 0x34, 0x50, 0xd9, 0xe5, 0x3b, 0x7e, 0xac, 0xb1, 0x6f, 0xcd, 0x78, 0xc5, 0x87, 0x97,
-	0x7c, 0x72, 0xdd, 0xfc, 0x55, 0x81, 0x6c, 0xec, 0x7c, 0xd0, 0x3e, 0x80, 0x4b, 0xb5, 0xbd, 0x83,
-	0xfa, 0x7d, 0xab, 0xb1, 0x63, 0xdd, 0xd9, 0xab, 0xde, 0xb5, 0x1e, 0x34, 0xef, 0x37, 0x0f, 0xbe,
-	0x68, 0x16, 0x16, 0x4a, 0x97, 0x4f, 0x4e, 0x75, 0x2d, 0x86, 0x7d, 0xe0, 0x3f, 0xf6, 0xf1, 0x37,
-	0xbe, 0xb6, 0x05, 0x6b, 0xd3, 0x21, 0xd5, 0x5a, 0x7b, 0xb7, 0xd9, 0x29, 0x28, 0xa5, 0x4b, 0x27,
-	0xa7, 0xfa, 0x6a, 0x2c, 0xa2, 0xda, 0x25, 0xc8, 0xa7, 0xb3, 0x01, 0xf5, 0x83, 0xfd, 0xfd, 0x46,
-	0xa7, 0x90, 0x98, 0x09, 0x90, 0x07, 0xf6, 0x0d, 0x58, 0x9d, 0x0e, 0x68, 0x36, 0xf6, 0x0a, 0xc9,
-	0x92, 0x76, 0x72, 0xaa, 0x2f, 0xc7, 0xd0, 0x4d, 0xd7, 0x2b, 0xa5, 0xbf, 0xfd, 0xbe, 0xbc, 0xf0,
-	0xe3, 0x0f, 0x65, 0x85, 0x55, 0x96, 0x9f, 0x3a, 0x23, 0xb4, 0xf7, 0xe1, 0x4a, 0xbb, 0x71, 0xb7,
-	0xb9, 0xbb, 0x63, 0xed, 0xb7, 0xef, 0x5a, 0x9d, 0x2f, 0x5b, 0xbb, 0xb1, 0xea, 0x56, 0x4e, 0x4e,
-	0xf5, 0xac, 0x2c, 0x69, 0x1e, 0xba, 0x65, 0xee, 0x3e, 0x3c, 0xe8, 0xec, 0x16, 0x14, 0x81, 0x6e,
-	0x85, 0xe8, 0x10, 0x53, 0xc4, 0xd1, 0xb7, 0xe0, 0xea, 0x05, 0xe8, 0xa8, 0xb0, 0xd5, 0x93, 0x53,
-	0x3d, 0xdf, 0x0a, 0x91, 0xd8, 0x3f, 0x3c, 0xc2, 0x80, 0xe2, 0x6c, 0xc4, 0x41, 0xeb, 0xa0, 0x5d,
-	0xdd, 0x2b, 0xe8, 0xa5, 0xc2, 0xc9, 0xa9, 0x9e, 0x1b, 0x1f, 0x86, 0x0c, 0x3f, 0xa9, 0xac, 0xf6,
-	0xf9, 0xb3, 0xb3, 0xb2, 0xf2, 0xfc, 0xac, 0xac, 0xfc, 0x79, 0x56, 0x56, 0x9e, 0xbe, 0x2c, 0x2f,
-	0x3c, 0x7f, 0x59, 0x5e, 0xf8, 0xed, 0x65, 0x79, 0xe1, 0xab, 0x8f, 0xfb, 0x2e, 0x1d, 0x8c, 0xba,
-	0x46, 0x0f, 0x0f, 0xb7, 0xe2, 0x7f, 0x09, 0x26, 0x9f, 0xe2, 0xaf, 0xc9, 0xf9, 0xbf, 0x0b, 0xdd,
-	0x25, 0x6e, 0xbf, 0xfd, 0x4f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x4c, 0x78, 0x43, 0xdf, 0xef, 0x0c,
-	0x00, 0x00,
+	// 1329 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0x4d, 0x6f, 0xdb, 0x46,
+	0x13, 0x36, 0x25, 0xea, 0x6b, 0x24, 0xd9, 0xf2, 0xc2, 0x49, 0x18, 0x25, 0x96, 0x09, 0xbd, 0x78,
+	0x5b, 0x27, 0x2d, 0xe8, 0xd4, 0x29, 0xfa, 0x71, 0xe8, 0x41, 0x92, 0x9d, 0x44, 0x88, 0x2d, 0xab,
+	0x94, 0x92, 0xa2, 0xbd, 0x10, 0x94, 0xb8, 0x91, 0xd8, 0x50, 0x24, 0x41, 0xae, 0x5c, 0x3b, 0x3f,
+	0xa0, 0x28, 0x7c, 0xca, 0xa9, 0x37, 0x9f, 0xda, 0x43, 0xef, 0xfd, 0x03, 0x45, 0x4f, 0x39, 0xe6,
+	0xd6, 0x9e, 0xd2, 0xc2, 0x01, 0xfa, 0x27, 0x7a, 0x29, 0xf6, 0x43, 0x14, 0x65, 0x59, 0x69, 0x1b,
+	0x04, 0xbd, 0x08, 0xbb, 0x33, 0xcf, 0xec, 0xce, 0x3c, 0xf3, 0xec, 0x2e, 0x05, 0xd7, 0x09, 0x76,
+	0x2d, 0x1c, 0x8c, 0x6c, 0x97, 0x6c, 0x91, 0x63, 0x1f, 0x87, 0xfc, 0x57, 0xf3, 0x03, 0x8f, 0x78,
+	0xa8, 0x34, 0xf5, 0x6a, 0xcc, 0x5e, 0x5e, 0x1b, 0x78, 0x03, 0x8f, 0x39, 0xb7, 0xe8, 0x88, 0xe3,
+	0xca, 0x1b, 0x03, 0xcf, 0x1b, 0x38, 0x78, 0x8b, 0xcd, 0x7a, 0xe3, 0x47, 0x5b, 0xc4, 0x1e, 0xe1,
+	0x90, 0x98, 0x23, 0x5f, 0x00, 0xd6, 0x63, 0xdb, 0xf4, 0x83, 0x63, 0x9f, 0x78, 0x14, 0xeb, 0x3d,
+	0x12, 0xee, 0x4a, 0xcc, 0x7d, 0x88, 0x83, 0xd0, 0xf6, 0xdc, 0x78, 0x1e, 0x65, 0x75, 0x2e, 0xcb,
+	0x43, 0xd3, 0xb1, 0x2d, 0x93, 0x78, 0x01, 0x47, 0x54, 0x3f, 0x86, 0x62, 0xdb, 0x0c, 0x48, 0x07,
+	0x93, 0x7b, 0xd8, 0xb4, 0x70, 0x80, 0xd6, 0x20, 0x45, 0x3c, 0x62, 0x3a, 0x8a, 0xa4, 0x4a, 0x9b,
+	0x45, 0x9d, 0x4f, 0x10, 0x02, 0x79, 0x68, 0x86, 0x43, 0x25, 0xa1, 0x4a, 0x9b, 0x05, 0x9d, 0x8d,
+	0xab, 0x43, 0x90, 0x69, 0x28, 0x8d, 0xb0, 0x5d, 0x0b, 0x1f, 0x4d, 0x22, 0xd8, 0x84, 0x5a, 0x7b,
+	0xc7, 0x04, 0x87, 0x22, 0x84, 0x4f, 0xd0, 0xfb, 0x90, 0x62, 0xf9, 0x2b, 0x49, 0x55, 0xda, 0xcc,
+	0x6f, 0x2b, 0x5a, 0x8c, 0x28, 0x5e, 0x9f, 0xd6, 0xa6, 0xfe, 0xba, 0xfc, 0xec, 0xc5, 0xc6, 0x92,
+	0xce, 0xc1, 0x55, 0x07, 0x32, 0x75, 0xc7, 0xeb, 0x3f, 0x6e, 0xee, 0x44, 0x89, 0x48, 0xd3, 0x44,
+	0xd0, 0x3e, 0xac, 0xf8, 0x66, 0x40, 0x8c, 0x10, 0x13, 0x63, 0xc8, 0xaa, 0x60, 0x9b, 0xe6, 0xb7,
+	0x37, 0xb4, 0xf3, 0x7d, 0xd0, 0x66, 0x8a, 0x15, 0xbb, 0x14, 0xfd, 0xb8, 0xb1, 0xfa, 0x87, 0x0c,
+	0x69, 0x41, 0xc6, 0x27, 0x90, 0x11, 0xb4, 0xb2, 0x0d, 0xf3, 0xdb, 0xeb, 0xf1, 0x15, 0x85, 0x4b,
+	0x6b, 0x78, 0x6e, 0x88, 0xdd, 0x70, 0x1c, 0x8a, 0xf5, 0x26, 0x31, 0xe8, 0x2d, 0xc8, 0xf6, 0x87,
+	0xa6, 0xed, 0x1a, 0xb6, 0xc5, 0x32, 0xca, 0xd5, 0xf3, 0x67, 0x2f, 0x36, 0x32, 0x0d, 0x6a, 0x6b,
+	0xee, 0xe8, 0x19, 0xe6, 0x6c, 0x5a, 0xe8, 0x32, 0xa4, 0x87, 0xd8, 0x1e, 0x0c, 0x09, 0xa3, 0x25,
+	0xa9, 0x8b, 0x19, 0xfa, 0x08, 0x64, 0x2a, 0x08, 0x45, 0x66, 0x7b, 0x97, 0x35, 0xae, 0x16, 0x6d,
+	0xa2, 0x16, 0xad, 0x3b, 0x51, 0x4b, 0x3d, 0x4b, 0x37, 0x7e, 0xfa, 0xdb, 0x86, 0xa4, 0xb3, 0x08,
+	0xd4, 0x80, 0xa2, 0x63, 0x86, 0xc4, 0xe8, 0x51, 0xda, 0xe8, 0xf6, 0x29, 0xb6, 0xc4, 0xd5, 0x79,
+	0x42, 0x04, 0xb1, 0x22, 0xf5, 0x3c, 0x8d, 0xe2, 0x26, 0x0b, 0x6d, 0x42, 0x89, 0x2d, 0xd2, 0xf7,
+	0x46, 0x23, 0x9b, 0x18, 0x8c, 0xf7, 0x34, 0xe3, 0x7d, 0x99, 0xda, 0x1b, 0xcc, 0x7c, 0x8f, 0x76,
+	0xe0, 0x1a, 0xe4, 0x2c, 0x93, 0x98, 0x1c, 0x92, 0x61, 0x90, 0x2c, 0x35, 0x30, 0xe7, 0xdb, 0xb0,
+	0x12, 0xa9, 0x2e, 0xe4, 0x90, 0x2c, 0x5f, 0x65, 0x6a, 0x66, 0xc0, 0x5b, 0xb0, 0xe6, 0xe2, 0x23,
+	0x62, 0x9c, 0x47, 0xe7, 0x18, 0x1a, 0x51, 0xdf, 0xc3, 0xd9, 0x88, 0xff, 0xc3, 0x72, 0x7f, 0x42,
+	0x3e, 0xc7, 0x02, 0xc3, 0x16, 0x23, 0x2b, 0x83, 0x5d, 0x85, 0xac, 0xe9, 0xfb, 0x1c, 0x90, 0x67,
+	0x80, 0x8c, 0xe9, 0xfb, 0xcc, 0x75, 0x13, 0x56, 0x59, 0x8d, 0x01, 0x0e, 0xc7, 0x0e, 0x11, 0x8b,
+	0x14, 0x18, 0x66, 0x85, 0x3a, 0x74, 0x6e, 0x67, 0xd8, 0xff, 0x41, 0x11, 0x1f, 0xda, 0x16, 0x76,
+	0xfb, 0x98, 0xe3, 0x8a, 0x0c, 0x57, 0x98, 0x18, 0x19, 0xe8, 0x06, 0x94, 0xfc, 0xc0, 0xf3, 0xbd,
+	0x10, 0x07, 0x86, 0x69, 0x59, 0x01, 0x0e, 0x43, 0x65, 0x99, 0xaf, 0x37, 0xb1, 0xd7, 0xb8, 0xb9,
+	0xaa, 0x80, 0xbc, 0x63, 0x12, 0x13, 0x95, 0x20, 0x49, 0x8e, 0x42, 0x45, 0x52, 0x93, 0x9b, 0x05,
+	0x9d, 0x0e, 0xab, 0x5f, 0x27, 0x41, 0x7e, 0xe8, 0x11, 0x8c, 0x6e, 0x83, 0x4c, 0xdb, 0xc4, 0xd4,
+	0xb7, 0x7c, 0x91, 0x9e, 0x3b, 0xf6, 0xc0, 0xc5, 0xd6, 0x7e, 0x38, 0xe8, 0x1e, 0xfb, 0x58, 0x67,
+	0xe0, 0x98, 0x9c, 0x12, 0x33, 0x72, 0x5a, 0x83, 0x54, 0xe0, 0x8d, 0x5d, 0x8b, 0xa9, 0x2c, 0xa5,
+	0xf3, 0x09, 0xda, 0x85, 0x6c, 0xa4, 0x12, 0xf9, 0xef, 0x54, 0xb2, 0x42, 0x55, 0x42, 0x35, 0x2c,
+	0x0c, 0x7a, 0xa6, 0x27, 0xc4, 0x52, 0x87, 0x5c, 0x74, 0x79, 0x29, 0xa9, 0x7f, 0x21, 0xd8, 0x69,
+	0x18, 0x7a, 0x07, 0x56, 0xa3, 0xde, 0x47, 0xe4, 0x71, 0xc5, 0x95, 0x22, 0x87, 0x60, 0x6f, 0x46,
+	0x56, 0x06, 0xbf, 0x80, 0x32, 0xac, 0xae, 0xa9, 0xac, 0x9a, 0xd4, 0x8a, 0xae, 0x43, 0x2e, 0xb4,
+	0x07, 0xae, 0x49, 0xc6, 0x01, 0x16, 0xca, 0x9b, 0x1a, 0xa8, 0x17, 0x1f, 0x11, 0xec, 0xb2, 0x43,
+	0xce, 0x95, 0x36, 0x35, 0x54, 0x7f, 0x92, 0x20, 0xcd, 0x75, 0x1e, 0x63, 0x55, 0xba, 0x98, 0xd5,
+	0xc4, 0x22, 0x56, 0x93, 0xaf, 0xcf, 0x6a, 0x0d, 0x20, 0x4a, 0x35, 0x54, 0x64, 0x35, 0xb9, 0x99,
+	0xdf, 0xbe, 0x36, 0xbf, 0x10, 0x4f, 0xb1, 0x63, 0x0f, 0xc4, 0x31, 0x8e, 0x05, 0x55, 0xff, 0x94,
+	0x20, 0x17, 0xf9, 0x51, 0x0d, 0x8a, 0x93, 0xbc, 0x8c, 0x47, 0x8e, 0x39, 0x10, 0xca, 0x5a, 0x5f,
+	0x98, 0xdc, 0x1d, 0xc7, 0x1c, 0xe8, 0x79, 0x91, 0x0f, 0x9d, 0x5c, 0xdc, 0xa5, 0xc4, 0x82, 0x2e,
+	0xcd, 0xc8, 0x22, 0xf9, 0x7a, 0xb2, 0x98, 0x69, 0xa0, 0xfc, 0xca, 0x06, 0xa6, 0xce, 0x37, 0xf0,
+	0xc7, 0x04, 0x64, 0xdb, 0xec, 0xdc, 0x99, 0xce, 0x7f, 0x71, 0x9a, 0xae, 0x41, 0xce, 0xf7, 0x1c,
+	0x83, 0x7b, 0x64, 0xe6, 0xc9, 0xfa, 0x9e, 0xa3, 0xcf, 0x89, 0x22, 0xf5, 0x86, 0x8e, 0x5a, 0xfa,
+	0x0d, 0x70, 0x9a, 0x39, 0xc7, 0x69, 0x35, 0x80, 0x02, 0xa7, 0x42, 0xbc, 0x83, 0xb7, 0x28, 0x07,
+	0x74, 0xa4, 0x48, 0xf3, 0xef, 0x36, 0x4f, 0x9b, 0x23, 0xf5, 0xf4, 0x30, 0x8a, 0xe0, 0xcf, 0x86,
+	0x92, 0x58, 0x14, 0xc1, 0x45, 0xa9, 0x0b, 0x5c, 0xf5, 0x5b, 0x09, 0x60, 0x8f, 0x32, 0xcb, 0xea,
+	0xa5, 0x2f, 0x58, 0xc8, 0x52, 0x30, 0x66, 0x76, 0xae, 0x2c, 0x6a, 0x9a, 0xd8, 0xbf, 0x10, 0xc6,
+	0xf3, 0x6e, 0x40, 0x71, 0x2a, 0xd5, 0x10, 0x4f, 0x92, 0xb9, 0x60, 0x91, 0xe8, 0x61, 0xe9, 0x60,
+	0xa2, 0x17, 0x0e, 0x63, 0xb3, 0xea, 0xcf, 0x12, 0xe4, 0x58, 0x4e, 0xfb, 0x98, 0x98, 0x33, 0x3d,
+	0x94, 0x5e, 0xbf, 0x87, 0xeb, 0x00, 0x7c, 0x99, 0xd0, 0x7e, 0x82, 0x85, 0xb2, 0x72, 0xcc, 0xd2,
+	0xb1, 0x9f, 0x60, 0xf4, 0x41, 0x44, 0x78, 0xf2, 0xd5, 0x84, 0x8b, 0x03, 0x3f, 0xa1, 0xfd, 0x0a,
+	0x64, 0xdc, 0xf1, 0xc8, 0xa0, 0xcf, 0x89, 0xcc, 0xd5, 0xea, 0x8e, 0x47, 0xdd, 0xa3, 0xb0, 0xfa,
+	0x25, 0x64, 0xba, 0x47, 0xec, 0xd3, 0x8a, 0x4a, 0x34, 0xf0, 0x3c, 0xf1, 0x9e, 0xf3, 0xef, 0xa8,
+	0x2c, 0x35, 0xb0, 0xe7, 0x0b, 0x81, 0x4c, 0x1f, 0xee, 0xc9, 0x87, 0x1e, 0x1d, 0x23, 0xed, 0x1f,
+	0x7e, 0xb4, 0x89, 0xcf, 0xb5, 0x9b, 0xbf, 0x48, 0x90, 0x8f, 0xdd, 0x1e, 0xe8, 0x3d, 0xb8, 0x54,
+	0xdf, 0x3b, 0x68, 0xdc, 0x37, 0x9a, 0x3b, 0xc6, 0x9d, 0xbd, 0xda, 0x5d, 0xe3, 0x41, 0xeb, 0x7e,
+	0xeb, 0xe0, 0xb3, 0x56, 0x69, 0xa9, 0x7c, 0xf9, 0xe4, 0x54, 0x45, 0x31, 0xec, 0x03, 0xf7, 0xb1,
+	0xeb, 0x7d, 0xe5, 0xa2, 0x2d, 0x58, 0x9b, 0x0d, 0xa9, 0xd5, 0x3b, 0xbb, 0xad, 0x6e, 0x49, 0x2a,
+	0x5f, 0x3a, 0x39, 0x55, 0x57, 0x63, 0x11, 0xb5, 0x5e, 0x88, 0x5d, 0x32, 0x1f, 0xd0, 0x38, 0xd8,
+	0xdf, 0x6f, 0x76, 0x4b, 0x89, 0xb9, 0x00, 0x71, 0x9d, 0xdf, 0x80, 0xd5, 0xd9, 0x80, 0x56, 0x73,
+	0xaf, 0x94, 0x2c, 0xa3, 0x93, 0x53, 0x75, 0x39, 0x86, 0x6e, 0xd9, 0x4e, 0x39, 0xfb, 0xcd, 0x77,
+	0x95, 0xa5, 0x1f, 0xbe, 0xaf, 0x48, 0xb4, 0xb2, 0xe2, 0xcc, 0x1d, 0x81, 0xde, 0x85, 0x2b, 0x9d,
+	0xe6, 0xdd, 0xd6, 0xee, 0x8e, 0xb1, 0xdf, 0xb9, 0x6b, 0x74, 0x3f, 0x6f, 0xef, 0xc6, 0xaa, 0x5b,
+	0x39, 0x39, 0x55, 0xf3, 0xa2, 0xa4, 0x45, 0xe8, 0xb6, 0xbe, 0xfb, 0xf0, 0xa0, 0xbb, 0x5b, 0x92,
+	0x38, 0xba, 0x1d, 0xe0, 0x43, 0x8f, 0x60, 0x86, 0xbe, 0x05, 0x57, 0x2f, 0x40, 0x47, 0x85, 0xad,
+	0x9e, 0x9c, 0xaa, 0xc5, 0x76, 0x80, 0xf9, 0xf9, 0x61, 0x11, 0x1a, 0x28, 0xf3, 0x11, 0x07, 0xed,
+	0x83, 0x4e, 0x6d, 0xaf, 0xa4, 0x96, 0x4b, 0x27, 0xa7, 0x6a, 0x61, 0x72, 0x19, 0x52, 0xfc, 0xb4,
+	0xb2, 0xfa, 0xa7, 0xcf, 0xce, 0x2a, 0xd2, 0xf3, 0xb3, 0x8a, 0xf4, 0xfb, 0x59, 0x45, 0x7a, 0xfa,
+	0xb2, 0xb2, 0xf4, 0xfc, 0x65, 0x65, 0xe9, 0xd7, 0x97, 0x95, 0xa5, 0x2f, 0x3e, 0x1c, 0xd8, 0x64,
+	0x38, 0xee, 0x69, 0x7d, 0x6f, 0xb4, 0x15, 0xff, 0x3b, 0x31, 0x1d, 0xf2, 0xbf, 0x35, 0xe7, 0xff,
+	0x6a, 0xf4, 0xd2, 0xcc, 0x7e, 0xfb, 0xaf, 0x01, 0x00, 0xa4, 0x94, 0x70, 0x24, 0x2b, 0x0d, 0x00,
+	0x00,
 }
 
 func (m *PartSetHeader) Marshal() (dAtA []byte, err error) {
@@ -1435,6 +1452,13 @@ func (m *Vote) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Extension) > 0 {
+		i -= len(m.Extension)
+		copy(dAtA[i:], m.Extension)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Extension)))
+		i--
+		dAtA[i] = 0x4a
+	}
 	if len(m.Signature) > 0 {
 		i -= len(m.Signature)
 		copy(dAtA[i:], m.Signature)
@@ -1567,6 +1591,13 @@ func (m *CommitSig) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Extension) > 0 {
+		i -= len(m.Extension)
+		copy(dAtA[i:], m.Extension)
+		i = encodeVarintTypes(dAtA, i, uint64(len(m.Extension)))
+		i--
+		dAtA[i] = 0x2a
+	}
 	if len(m.Signature) > 0 {
 		i -= len(m.Signature)
 		copy(dAtA[i:], m.Signature)
@@ -2024,6 +2055,10 @@ func (m *Vote) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovTypes(uint64(l))
 	}
+	l = len(m.Extension)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
 	return n
 }
 
@@ -2069,6 +2104,10 @@ func (m *CommitSig) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovTypes(uint64(l))
 	}
+	l = len(m.Extension)
+	if l > 0 {
+		n += 1 + l + sovTypes(uint64(l))
+	}
 	return n
 }
 
@@ -3364,6 +3403,40 @@ func (m *Vote) Unmarshal(dAtA []byte) error {
 				m.Signature = []byte{}
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Extension", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Extension = append(m.Extension[:0], dAtA[iNdEx:postIndex]...)
+			if m.Extension == nil {
+				m.Extension = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
@@ -3689,6 +3762,40 @@ func (m *CommitSig) Unmarshal(dAtA []byte) error {
 				m.Signature = []byte{}
 			}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Extension", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTypes
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTypes
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTypes
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Extension = append(m.Extension[:0], dAtA[iNdEx:postIndex]...)
+			if m.Extension == nil {
+				m.Extension = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTypes(dAtA[iNdEx:])
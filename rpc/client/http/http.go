@@ -234,8 +234,9 @@ func (c *baseRPCClient) ABCIQueryWithOptions(
 	if err := c.caller.Call(ctx, "abci_query", abciQueryArgs{
 		Path:   path,
 		Data:   data,
-		Height: opts.Height,
-		Prove:  opts.Prove,
+		Height:    opts.Height,
+		Prove:     opts.Prove,
+		BlockHash: opts.BlockHash,
 	}, result); err != nil {
 		return nil, err
 	}
@@ -9,10 +9,11 @@ import (
 )
 
 type abciQueryArgs struct {
-	Path   string         `json:"path"`
-	Data   bytes.HexBytes `json:"data"`
-	Height int64          `json:"height,string"`
-	Prove  bool           `json:"prove"`
+	Path      string         `json:"path"`
+	Data      bytes.HexBytes `json:"data"`
+	Height    int64          `json:"height,string"`
+	Prove     bool           `json:"prove"`
+	BlockHash bytes.HexBytes `json:"block_hash,omitempty"`
 }
 
 type txArgs struct {
@@ -56,6 +56,7 @@ type wsSubscription struct {
 	res   chan coretypes.ResultEvent
 	id    string
 	query string
+	seq   uint64 // of the last event received, to resume after reconnecting
 }
 
 var _ rpcclient.EventsClient = (*wsEvents)(nil)
@@ -191,7 +192,9 @@ func (w *wsEvents) UnsubscribeAll(ctx context.Context, subscriber string) error
 }
 
 // After being reconnected, it is necessary to redo subscription to server
-// otherwise no data will be automatically received.
+// otherwise no data will be automatically received. The subscriptions that
+// received events resume after the last one, so that the events published in
+// the meantime are not missed if the server still retains them.
 func (w *wsEvents) redoSubscriptionsAfter(d time.Duration) {
 	time.Sleep(d)
 
@@ -204,7 +207,12 @@ func (w *wsEvents) redoSubscriptionsAfter(d time.Duration) {
 		if q != "" && q == info.id {
 			continue
 		}
-		err := w.ws.Subscribe(ctx, q)
+		var err error
+		if info.seq != 0 {
+			err = w.ws.SubscribeAfter(ctx, q, info.seq)
+		} else {
+			err = w.ws.Subscribe(ctx, q)
+		}
 		if err != nil {
 			w.Logger.Error("failed to resubscribe", "query", q, "err", err)
 			delete(w.subscriptions, q)
@@ -216,6 +224,20 @@ func isErrAlreadySubscribed(err error) bool {
 	return strings.Contains(err.Error(), pubsub.ErrAlreadySubscribed.Error())
 }
 
+func isErrCannotResume(err error) bool {
+	return strings.Contains(err.Error(), pubsub.ErrCannotResume.Error())
+}
+
+// forgetCursors makes the subscriptions start over from the next event when
+// they are redone, after the server failed to resume them.
+func (w *wsEvents) forgetCursors() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	for _, info := range w.subscriptions {
+		info.seq = 0
+	}
+}
+
 func (w *wsEvents) eventListener(ctx context.Context) {
 	for {
 		select {
@@ -229,8 +251,15 @@ func (w *wsEvents) eventListener(ctx context.Context) {
 				// Error can be ErrAlreadySubscribed or max client (subscriptions per
 				// client) reached or Tendermint exited.
 				// We can ignore ErrAlreadySubscribed, but need to retry in other
-				// cases.
-				if !isErrAlreadySubscribed(resp.Error) {
+				// cases. If a subscription could not be resumed, the events
+				// published while disconnected are lost: subscribe again from
+				// the next event.
+				if isErrCannotResume(resp.Error) {
+					w.Logger.Error("failed to resume subscription, events may have been missed",
+						"err", resp.Error.Error())
+					w.forgetCursors()
+					w.redoSubscriptionsAfter(0 * time.Second)
+				} else if !isErrAlreadySubscribed(resp.Error) {
 					// Resubscribe after 1 second to give Tendermint time to restart (if
 					// crashed).
 					w.redoSubscriptionsAfter(1 * time.Second)
@@ -245,16 +274,19 @@ func (w *wsEvents) eventListener(ctx context.Context) {
 				continue
 			}
 
-			w.mtx.RLock()
+			w.mtx.Lock()
 			out, ok := w.subscriptions[result.Query]
 			if ok {
 				if _, idOk := w.subscriptions[result.SubscriptionID]; !idOk {
 					out.id = result.SubscriptionID
 					w.subscriptions[result.SubscriptionID] = out
 				}
+				if result.Seq != 0 {
+					out.seq = result.Seq
+				}
 			}
 
-			w.mtx.RUnlock()
+			w.mtx.Unlock()
 			if ok {
 				select {
 				case out.res <- *result:
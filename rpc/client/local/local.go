@@ -82,7 +82,7 @@ func (c *Local) ABCIQueryWithOptions(
 	path string,
 	data bytes.HexBytes,
 	opts rpcclient.ABCIQueryOptions) (*coretypes.ResultABCIQuery, error) {
-	return c.env.ABCIQuery(ctx, path, data, opts.Height, opts.Prove)
+	return c.env.ABCIQuery(ctx, path, data, opts.Height, opts.Prove, opts.BlockHash)
 }
 
 func (c *Local) BroadcastTxCommit(ctx context.Context, tx types.Tx) (*coretypes.ResultBroadcastTxCommit, error) {
@@ -185,7 +185,7 @@ func (c *Local) TxSearch(
 	perPage *int,
 	orderBy string,
 ) (*coretypes.ResultTxSearch, error) {
-	return c.env.TxSearch(ctx, queryString, prove, page, perPage, orderBy)
+	return c.env.TxSearch(ctx, queryString, prove, page, perPage, orderBy, "")
 }
 
 func (c *Local) BlockSearch(
@@ -194,7 +194,7 @@ func (c *Local) BlockSearch(
 	page, perPage *int,
 	orderBy string,
 ) (*coretypes.ResultBlockSearch, error) {
-	return c.env.BlockSearch(ctx, queryString, page, perPage, orderBy)
+	return c.env.BlockSearch(ctx, queryString, page, perPage, orderBy, "")
 }
 
 func (c *Local) BroadcastEvidence(ctx context.Context, ev types.Evidence) (*coretypes.ResultBroadcastEvidence, error) {
@@ -262,6 +262,7 @@ func (c *Local) eventsRoutine(
 		outc <- coretypes.ResultEvent{
 			SubscriptionID: msg.SubscriptionID(),
 			Query:          qstr,
+			Seq:            msg.Seq(),
 			Data:           msg.Data(),
 			Events:         msg.Events(),
 		}
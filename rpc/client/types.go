@@ -1,10 +1,16 @@
 package client
 
+import "github.com/tendermint/tendermint/libs/bytes"
+
 // ABCIQueryOptions can be used to provide options for ABCIQuery call other
 // than the DefaultABCIQueryOptions.
 type ABCIQueryOptions struct {
 	Height int64
 	Prove  bool
+
+	// BlockHash pins the query to the state of the block with this hash,
+	// which must be part of the chain of the node, instead of a height.
+	BlockHash bytes.HexBytes
 }
 
 // DefaultABCIQueryOptions are latest height (0) and prove false.
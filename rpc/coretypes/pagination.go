@@ -0,0 +1,47 @@
+package coretypes
+
+import "fmt"
+
+// ValidatePerPage returns the page size requested by perPagePtr, or
+// defaultPerPage if none was given. It returns an error wrapping
+// ErrPerPageExceedsMax if the requested size is greater than maxPerPage. A
+// non-positive maxPerPage disables the cap.
+func ValidatePerPage(perPagePtr *int, defaultPerPage, maxPerPage int) (int, error) {
+	if perPagePtr == nil { // no per_page parameter
+		return defaultPerPage, nil
+	}
+
+	perPage := *perPagePtr
+	if perPage < 1 {
+		return defaultPerPage, nil
+	}
+	if maxPerPage > 0 && perPage > maxPerPage {
+		return 0, fmt.Errorf("%w: given %d, the maximum is %d", ErrPerPageExceedsMax, perPage, maxPerPage)
+	}
+	return perPage, nil
+}
+
+// ValidatePage returns the page requested by pagePtr, or the first page if
+// none was given, checking that it exists given the page size and the total
+// number of items.
+func ValidatePage(pagePtr *int, perPage, totalCount int) (int, error) {
+	// this can only happen if we haven't first run ValidatePerPage
+	if perPage < 1 {
+		panic(fmt.Errorf("%w (%d)", ErrZeroOrNegativePerPage, perPage))
+	}
+
+	if pagePtr == nil { // no page parameter
+		return 1, nil
+	}
+
+	pages := ((totalCount - 1) / perPage) + 1
+	if pages == 0 {
+		pages = 1 // one page (even if it's empty)
+	}
+	page := *pagePtr
+	if page <= 0 || page > pages {
+		return 1, fmt.Errorf("%w expected range: [1, %d], given %d", ErrPageOutOfRange, pages, page)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,78 @@
+package coretypes
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePage(t *testing.T) {
+	cases := []struct {
+		totalCount int
+		perPage    int
+		page       int
+		newPage    int
+		expErr     bool
+	}{
+		{0, 10, 1, 1, false},
+
+		{0, 10, 0, 1, false},
+		{0, 10, 1, 1, false},
+		{0, 10, 2, 0, true},
+
+		{5, 10, -1, 0, true},
+		{5, 10, 0, 1, false},
+		{5, 10, 1, 1, false},
+		{5, 10, 2, 0, true},
+		{5, 10, 2, 0, true},
+
+		{5, 5, 1, 1, false},
+		{5, 5, 2, 0, true},
+		{5, 5, 3, 0, true},
+
+		{5, 3, 2, 2, false},
+		{5, 3, 3, 0, true},
+
+		{5, 2, 2, 2, false},
+		{5, 2, 3, 3, false},
+		{5, 2, 4, 0, true},
+	}
+
+	for _, c := range cases {
+		p, err := ValidatePage(&c.page, c.perPage, c.totalCount)
+		if c.expErr {
+			assert.Error(t, err)
+			continue
+		}
+
+		assert.Equal(t, c.newPage, p, fmt.Sprintf("%v", c))
+	}
+
+	// nil case
+	p, err := ValidatePage(nil, 1, 1)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, p)
+	}
+}
+
+func TestValidatePerPage(t *testing.T) {
+	ten := 10
+	p, err := ValidatePerPage(&ten, 30, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, p)
+
+	_, err = ValidatePerPage(&ten, 5, 9)
+	assert.ErrorIs(t, err, ErrPerPageExceedsMax)
+	assert.Contains(t, err.Error(), "the maximum is 9")
+
+	// no cap
+	p, err = ValidatePerPage(&ten, 5, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, p)
+
+	zero := 0
+	p, err = ValidatePerPage(&zero, 5, 9)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, p)
+}
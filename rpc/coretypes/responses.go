@@ -17,6 +17,7 @@ import (
 // List of standardized errors used across RPC
 var (
 	ErrZeroOrNegativePerPage  = errors.New("zero or negative per_page")
+	ErrPerPageExceedsMax      = errors.New("per_page exceeds the configured maximum")
 	ErrPageOutOfRange         = errors.New("page should be within range")
 	ErrZeroOrNegativeHeight   = errors.New("height must be greater than zero")
 	ErrHeightExceedsChainHead = errors.New("height must be less than or equal to the head of the node's blockchain")
@@ -24,14 +25,72 @@ var (
 	// ErrInvalidRequest is used as a wrapper to cover more specific cases where the user has
 	// made an invalid request
 	ErrInvalidRequest = errors.New("invalid request")
+	// ErrCatchingUp is wrapped by the CatchingUpError of the transactions
+	// rejected while the node is syncing.
+	ErrCatchingUp = errors.New("node catching up")
 )
 
+// CatchingUpError is the error of a transaction rejected because the node is
+// syncing too far behind the head of the chain.
+type CatchingUpError struct {
+	// Height is the height of the node.
+	Height int64
+	// TargetHeight is the height the node syncs to, i.e. the highest height
+	// of its peers, or of the snapshot it restores.
+	TargetHeight int64
+}
+
+// Lag returns the number of blocks the node is behind.
+func (e *CatchingUpError) Lag() int64 {
+	if e.TargetHeight < e.Height {
+		return 0
+	}
+	return e.TargetHeight - e.Height
+}
+
+func (e *CatchingUpError) Error() string {
+	return fmt.Sprintf("%v: %d blocks behind (height %d, target height %d)",
+		ErrCatchingUp, e.Lag(), e.Height, e.TargetHeight)
+}
+
+func (e *CatchingUpError) Unwrap() error { return ErrCatchingUp }
+
 // List of blocks
 type ResultBlockchainInfo struct {
 	LastHeight int64              `json:"last_height,string"`
 	BlockMetas []*types.BlockMeta `json:"block_metas"`
 }
 
+// Utilization of a range of blocks, per block and per proposer
+type ResultBlockUtilization struct {
+	LastHeight int64                 `json:"last_height,string"`
+	Blocks     []BlockUtilization    `json:"blocks"`
+	Proposers  []ProposerUtilization `json:"proposers"`
+}
+
+// Share of the capacity allowed by the consensus params used by a block.
+// GasUsed is -1 if the results of the block are not available, and MaxGas is
+// -1 if the gas of a block is unlimited.
+type BlockUtilization struct {
+	Height          int64          `json:"height,string"`
+	ProposerAddress bytes.HexBytes `json:"proposer_address"`
+	NumTxs          int            `json:"num_txs,string"`
+	Bytes           int64          `json:"bytes,string"`
+	MaxBytes        int64          `json:"max_bytes,string"`
+	GasUsed         int64          `json:"gas_used,string"`
+	MaxGas          int64          `json:"max_gas,string"`
+}
+
+// Utilization of the blocks of a proposer. Gas utilization is averaged over
+// the blocks whose gas used and gas limit are both known.
+type ProposerUtilization struct {
+	Address             bytes.HexBytes `json:"address"`
+	Blocks              int            `json:"blocks,string"`
+	EmptyBlocks         int            `json:"empty_blocks,string"`
+	AvgBytesUtilization float64        `json:"avg_bytes_utilization"`
+	AvgGasUtilization   float64        `json:"avg_gas_utilization"`
+}
+
 // Genesis file
 type ResultGenesis struct {
 	Genesis *types.GenesisDoc `json:"genesis"`
@@ -117,6 +176,16 @@ type SyncInfo struct {
 	BackFillBlocksTotal int64         `json:"backfill_blocks_total,string"`
 }
 
+// ResultReplayStatus is the progress of the replay of the stored blocks into
+// the app, served while the node starts.
+type ResultReplayStatus struct {
+	StartHeight   int64         `json:"start_height,string"`
+	TargetHeight  int64         `json:"target_height,string"`
+	Height        int64         `json:"height,string"`
+	StartTime     time.Time     `json:"start_time"`
+	RemainingTime time.Duration `json:"remaining_time,string"`
+}
+
 // Info about the node's validator
 type ValidatorInfo struct {
 	Address     bytes.HexBytes
@@ -159,6 +228,33 @@ type ResultStatus struct {
 	SyncInfo        SyncInfo              `json:"sync_info"`
 	ValidatorInfo   ValidatorInfo         `json:"validator_info"`
 	LightClientInfo types.LightClientInfo `json:"light_client_info,omitempty"`
+	Features        []FeatureFlag         `json:"features,omitempty"`
+
+	// Conditions the operator should look into, e.g. peers committing other
+	// blocks than the node.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// A feature flag of the node, gating an experimental or beta behavior.
+type FeatureFlag struct {
+	Name    string `json:"name"`
+	Stage   string `json:"stage"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Latest block verified by a light client proxy, cross-checked with its
+// witnesses. The block is only given if it is corroborated: at least one
+// witness agrees with it, and none conflicts with it or is ahead by more than
+// the height tolerance. Witnesses reports each witness either way.
+type ResultStatusVerified struct {
+	Corroborated      bool           `json:"corroborated"`
+	LatestBlockHash   bytes.HexBytes `json:"latest_block_hash"`
+	LatestAppHash     bytes.HexBytes `json:"latest_app_hash"`
+	LatestBlockHeight int64          `json:"latest_block_height,string"`
+	LatestBlockTime   time.Time      `json:"latest_block_time"`
+
+	HeightTolerance int64              `json:"height_tolerance,string"`
+	Witnesses       []types.WitnessTip `json:"witnesses"`
 }
 
 // Is TxIndexing enabled
@@ -177,6 +273,115 @@ type ResultNetInfo struct {
 	Peers     []Peer   `json:"peers"`
 }
 
+// Lifetime statistics of the peers the node has been connected to
+type ResultPeerStats struct {
+	Peers []PeerStats `json:"peers"`
+}
+
+// Lifetime statistics of a peer, accumulated across connections and restarts
+type PeerStats struct {
+	ID               types.NodeID `json:"node_id"`
+	Connected        bool         `json:"connected"`
+	FirstConnected   time.Time    `json:"first_connected"`
+	LastConnected    time.Time    `json:"last_connected"`
+	LastDisconnected time.Time    `json:"last_disconnected"`
+	Connects         uint64       `json:"connects,string"`
+	BytesSent        uint64       `json:"bytes_sent,string"`
+	BytesReceived    uint64       `json:"bytes_received,string"`
+	BlocksDelivered  uint64       `json:"blocks_delivered,string"`
+	Misbehaviors     uint64       `json:"misbehaviors,string"`
+}
+
+// Peers of the peer store, best first
+type ResultAddressBook struct {
+	Peers []AddressBookPeer `json:"peers"`
+}
+
+// A peer of the peer store, as exported and imported
+type AddressBookPeer struct {
+	ID            types.NodeID `json:"node_id"`
+	Addresses     []string     `json:"addresses"`
+	LastConnected time.Time    `json:"last_connected"`
+	// Score from the reports of the reactors
+	Score int64 `json:"score,string"`
+}
+
+// Number of addresses added to the peer store by an import
+type ResultImportAddressBook struct {
+	Added int `json:"added,string"`
+}
+
+// Scores of the peers of the peer store, best first
+type ResultPeerScores struct {
+	Peers []PeerScore `json:"peers"`
+}
+
+// Score of a peer, with the data it is computed from
+type PeerScore struct {
+	ID         types.NodeID `json:"node_id"`
+	Score      uint8        `json:"score"`
+	Persistent bool         `json:"persistent"`
+	// Score from the reports of the reactors
+	MutableScore int64 `json:"mutable_score,string"`
+	// Points earned or lost from the latency, uptime and misbehaviors
+	Reputation   int64         `json:"reputation,string"`
+	DialFailures uint32        `json:"dial_failures"`
+	Latency      time.Duration `json:"latency,string"`
+	Uptime       time.Duration `json:"uptime,string"`
+	Misbehaviors uint64        `json:"misbehaviors,string"`
+}
+
+// Rules of the peer filter, in the order they were added
+type ResultPeerFilter struct {
+	Rules []PeerFilterRule `json:"rules"`
+}
+
+// A rule allowing or denying the peers by IP address, node ID or chain ID
+type PeerFilterRule struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+}
+
+// Nodes of the current validators, from the validator book
+type ResultValidatorBook struct {
+	BlockHeight int64           `json:"block_height,string"`
+	Validators  []ValidatorNode `json:"validators"`
+}
+
+// Node of a validator, if it attested to run one
+type ValidatorNode struct {
+	Address     types.Address `json:"address"`
+	VotingPower int64         `json:"voting_power,string"`
+	NodeID      types.NodeID  `json:"node_id,omitempty"`
+	// Time of the last handshake with the node
+	Attested  *time.Time `json:"attested,omitempty"`
+	Connected bool       `json:"connected"`
+}
+
+// Bandwidth used by the connected peers since they connected
+type ResultPeerBandwidth struct {
+	Peers []PeerBandwidth `json:"peers"`
+}
+
+// Bandwidth used by a connected peer, by channel
+type PeerBandwidth struct {
+	ID        types.NodeID       `json:"node_id"`
+	Connected time.Time          `json:"connected"`
+	Channels  []ChannelBandwidth `json:"channels"`
+}
+
+// Bandwidth used by a peer on a channel
+type ChannelBandwidth struct {
+	ChannelID     byte   `json:"channel_id"`
+	BytesSent     uint64 `json:"bytes_sent,string"`
+	BytesReceived uint64 `json:"bytes_received,string"`
+	// In bytes/second, 0 if unlimited
+	RecvRateLimit int64 `json:"recv_rate_limit,string"`
+	// Time spent delaying the messages received over the rate limit
+	Throttled time.Duration `json:"throttled,string"`
+}
+
 // Log from dialing seeds
 type ResultDialSeeds struct {
 	Log string `json:"log"`
@@ -189,8 +394,17 @@ type ResultDialPeers struct {
 
 // A peer
 type Peer struct {
-	ID  types.NodeID `json:"node_id"`
-	URL string       `json:"url"`
+	ID          types.NodeID        `json:"node_id"`
+	URL         string              `json:"url"`
+	Maintenance []MaintenanceWindow `json:"maintenance,omitempty"`
+}
+
+// MaintenanceWindow is a period during which a peer announced it plans to be
+// unavailable.
+type MaintenanceWindow struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reason string    `json:"reason,omitempty"`
 }
 
 // Validators for a height.
@@ -202,12 +416,90 @@ type ResultValidators struct {
 	Total int `json:"total,string"` // Total number of validators
 }
 
+// Proofs of the validators of a height against the validators hash of its
+// header.
+type ResultValidatorsProof struct {
+	BlockHeight    int64                  `json:"block_height,string"`
+	ValidatorsHash bytes.HexBytes         `json:"validators_hash"`
+	Proofs         []types.ValidatorProof `json:"proofs"`
+}
+
 // ConsensusParams for given height
 type ResultConsensusParams struct {
 	BlockHeight     int64                 `json:"block_height"`
 	ConsensusParams types.ConsensusParams `json:"consensus_params"`
 }
 
+// ConsensusParams for given height, with the changes of the params up to the
+// height, oldest first. The history starts at BaseHeight: the older changes
+// were pruned.
+// UNSTABLE
+type ResultConsensusParamsHistory struct {
+	BlockHeight     int64                  `json:"block_height,string"`
+	ConsensusParams types.ConsensusParams  `json:"consensus_params"`
+	BaseHeight      int64                  `json:"base_height,string"`
+	Changes         []ConsensusParamChange `json:"changes"`
+}
+
+// ConsensusParamChange is a change of a consensus param, in effect from Height.
+// Field is the path of the JSON names of the param, e.g. "block.max_bytes",
+// and Old and New its JSON values, null if not set.
+type ConsensusParamChange struct {
+	Height int64           `json:"height,string"`
+	Field  string          `json:"field"`
+	Old    json.RawMessage `json:"old"`
+	New    json.RawMessage `json:"new"`
+}
+
+// Snapshots of the application recorded by the snapshot service, most recent
+// first, and the last time the service listed them.
+// UNSTABLE
+type ResultSnapshots struct {
+	Snapshots   []SnapshotInfo `json:"snapshots"`
+	LastRefresh time.Time      `json:"last_refresh"`
+	LastError   string         `json:"last_error,omitempty"`
+}
+
+// SnapshotInfo is a snapshot of the application, with the sizes and SHA-256
+// hashes of its chunks.
+type SnapshotInfo struct {
+	Height     uint64              `json:"height,string"`
+	Format     uint32              `json:"format"`
+	Hash       bytes.HexBytes      `json:"hash"`
+	Metadata   bytes.HexBytes      `json:"metadata"`
+	Chunks     []SnapshotChunkInfo `json:"chunks"`
+	RecordedAt time.Time           `json:"recorded_at"`
+}
+
+// SnapshotChunkInfo is the size and SHA-256 hash of a chunk of a snapshot.
+type SnapshotChunkInfo struct {
+	Size int64          `json:"size,string"`
+	Hash bytes.HexBytes `json:"hash"`
+}
+
+// Retain heights of the pruner, and the progress of the pruning. The blocks
+// below the retain height, the lowest of the others, are pruned; it is 0 if
+// the application requested no pruning.
+// UNSTABLE
+type ResultRetainHeight struct {
+	AppRetainHeight      int64     `json:"app_retain_height,string"`
+	EvidenceRetainHeight int64     `json:"evidence_retain_height,string"`
+	MinRetainHeight      int64     `json:"min_retain_height,string"`
+	RetainHeight         int64     `json:"retain_height,string"`
+	BlockBaseHeight      int64     `json:"block_base_height,string"`
+	TxIndexBaseHeight    int64     `json:"tx_index_base_height,string"`
+	LastPruned           time.Time `json:"last_pruned"`
+	LastError            string    `json:"last_error,omitempty"`
+}
+
+// Forensic bundle captured on an app hash mismatch, as written to disk, and
+// the heights of all the captured bundles.
+// UNSTABLE
+type ResultAppHashMismatch struct {
+	Heights []int64         `json:"heights"`
+	Bundle  json.RawMessage `json:"bundle"`
+}
+
 // Info about the consensus state.
 // UNSTABLE
 type ResultDumpConsensusState struct {
@@ -226,6 +518,12 @@ type ResultConsensusState struct {
 	RoundState json.RawMessage `json:"round_state"`
 }
 
+// Recent changes of the locked and valid blocks, oldest first
+// UNSTABLE
+type ResultLockHistory struct {
+	Changes []types.EventDataLockChange `json:"changes"`
+}
+
 // CheckTx result
 type ResultBroadcastTx struct {
 	Code         uint32         `json:"code"`
@@ -259,16 +557,24 @@ type ResultTx struct {
 	Proof    types.TxProof          `json:"proof,omitempty"`
 }
 
+// Result of proving several txs of a block at once
+type ResultTxProof struct {
+	Height int64              `json:"height,string"`
+	Proof  types.TxMultiProof `json:"proof"`
+}
+
 // Result of searching for txs
 type ResultTxSearch struct {
 	Txs        []*ResultTx `json:"txs"`
 	TotalCount int         `json:"total_count,string"`
+	NextCursor string      `json:"next_cursor,omitempty"` // empty on the last page
 }
 
 // ResultBlockSearch defines the RPC response type for a block search by events.
 type ResultBlockSearch struct {
 	Blocks     []*ResultBlock `json:"blocks"`
 	TotalCount int            `json:"total_count,string"`
+	NextCursor string         `json:"next_cursor,omitempty"` // empty on the last page
 }
 
 // List of mempool txs
@@ -279,6 +585,77 @@ type ResultUnconfirmedTxs struct {
 	Txs        []types.Tx `json:"txs"`
 }
 
+// List of the metadata of mempool transactions
+type ResultMempoolTxs struct {
+	Count int         `json:"n_txs,string"`
+	Total int         `json:"total,string"`
+	Txs   []MempoolTx `json:"txs"`
+}
+
+// Metadata of a mempool transaction. Height is the height at which the
+// transaction was last validated by CheckTx.
+type MempoolTx struct {
+	Hash          bytes.HexBytes `json:"hash"`
+	Size          int            `json:"size,string"`
+	GasWanted     int64          `json:"gas_wanted,string"`
+	Priority      int64          `json:"priority,string"`
+	Sender        string         `json:"sender"`
+	Height        int64          `json:"height,string"`
+	ReceivedAt    time.Time      `json:"received_at"`
+	TimeInMempool time.Duration  `json:"time_in_mempool,string"`
+}
+
+// Statuses of a transaction reported by ResultTxStatus.
+const (
+	TxStatusUnknown   = "unknown"
+	TxStatusPending   = "pending"
+	TxStatusCommitted = "committed"
+	TxStatusEvicted   = "evicted"
+)
+
+// Result of querying the status of a transaction: exactly one of Pending,
+// Committed and Evicted is set, according to Status, and none if the status
+// is unknown.
+//
+// UNSTABLE
+type ResultTxStatus struct {
+	Hash      bytes.HexBytes     `json:"hash"`
+	Status    string             `json:"status"`
+	Pending   *PendingTxStatus   `json:"pending,omitempty"`
+	Committed *CommittedTxStatus `json:"committed,omitempty"`
+	Evicted   *EvictedTxStatus   `json:"evicted,omitempty"`
+}
+
+// Status of a transaction in the mempool. Rank is the number of transactions
+// the node would include in a block before it.
+//
+// UNSTABLE
+type PendingTxStatus struct {
+	Rank       int       `json:"rank,string"`
+	Priority   int64     `json:"priority,string"`
+	Height     int64     `json:"height,string"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// Status of a committed transaction.
+//
+// UNSTABLE
+type CommittedTxStatus struct {
+	Height   int64                  `json:"height,string"`
+	Index    uint32                 `json:"index"`
+	TxResult abci.ResponseDeliverTx `json:"tx_result"`
+}
+
+// Status of a transaction evicted from the mempool. Height is the height of
+// the last block the mempool was updated with at the time.
+//
+// UNSTABLE
+type EvictedTxStatus struct {
+	Reason    string    `json:"reason"`
+	EvictedAt time.Time `json:"evicted_at"`
+	Height    int64     `json:"height,string"`
+}
+
 // Info abci msg
 type ResultABCIInfo struct {
 	Response abci.ResponseInfo `json:"response"`
@@ -294,6 +671,26 @@ type ResultBroadcastEvidence struct {
 	Hash []byte `json:"hash"`
 }
 
+// Result of scheduling externally computed validator updates
+type ResultValidatorSetUpdate struct {
+	EffectiveHeight int64          `json:"effective_height,string"`
+	Hash            bytes.HexBytes `json:"hash"`
+}
+
+// ResultSimulateProposal is the block this node would propose at the next
+// height, along with statistics about its contents.
+type ResultSimulateProposal struct {
+	Block          *types.Block `json:"block"`
+	BlockSize      int          `json:"block_size,string"`
+	NumTxs         int          `json:"n_txs,string"`
+	NumEvidence    int          `json:"n_evidence,string"`
+	TotalGasWanted int64        `json:"total_gas_wanted,string"`
+	MaxBytes       int64        `json:"max_bytes,string"`
+	MaxGas         int64        `json:"max_gas,string"`
+	PendingTxs     int          `json:"pending_txs,string"`
+	Accepted       bool         `json:"accepted"`
+}
+
 // empty results
 type (
 	ResultUnsafeFlushMempool struct{}
@@ -303,36 +700,69 @@ type (
 	ResultHealth             struct{}
 )
 
-// Event data from a subscription
+// ResultCaptureMessages is the file into which the messages of a peer are
+// captured until the given time.
+type ResultCaptureMessages struct {
+	File  string    `json:"file"`
+	Until time.Time `json:"until"`
+}
+
+// ResultDumpMempool is the file into which the mempool was dumped, with the
+// height of the last block of the mempool and its number of transactions.
+type ResultDumpMempool struct {
+	File   string `json:"file"`
+	Height int64  `json:"height,string"`
+	Count  int    `json:"n_txs,string"`
+}
+
+// ResultRestoreMempool is the number of transactions of a mempool dump
+// restored into the mempool, and skipped.
+type ResultRestoreMempool struct {
+	Restored int `json:"restored,string"`
+	Skipped  int `json:"skipped,string"`
+}
+
+// Event data from a subscription. A subscription opened with a stats
+// interval is also sent periodic frames with no data, reporting its delivery
+// statistics in Stats.
 type ResultEvent struct {
 	SubscriptionID string
 	Query          string
+	Seq            uint64 // sequence number, to resume the subscription after it
 	Data           types.TMEventData
 	Events         []abci.Event
+	Stats          *SubscriptionStats
 }
 
 type resultEventJSON struct {
-	SubscriptionID string          `json:"subscription_id"`
-	Query          string          `json:"query"`
-	Data           json.RawMessage `json:"data"`
-	Events         []abci.Event    `json:"events"`
+	SubscriptionID string             `json:"subscription_id"`
+	Query          string             `json:"query"`
+	Seq            uint64             `json:"seq,string,omitempty"`
+	Data           json.RawMessage    `json:"data,omitempty"`
+	Events         []abci.Event       `json:"events"`
+	Stats          *SubscriptionStats `json:"stats,omitempty"`
 }
 
 func (r ResultEvent) MarshalJSON() ([]byte, error) {
-	data, ok := r.Data.(jsontypes.Tagged)
-	if !ok {
-		return nil, fmt.Errorf("type %T is not tagged", r.Data)
-	}
-	evt, err := jsontypes.Marshal(data)
-	if err != nil {
-		return nil, err
-	}
-	return json.Marshal(resultEventJSON{
+	res := resultEventJSON{
 		SubscriptionID: r.SubscriptionID,
 		Query:          r.Query,
-		Data:           evt,
+		Seq:            r.Seq,
 		Events:         r.Events,
-	})
+		Stats:          r.Stats,
+	}
+	if r.Data != nil || r.Stats == nil {
+		data, ok := r.Data.(jsontypes.Tagged)
+		if !ok {
+			return nil, fmt.Errorf("type %T is not tagged", r.Data)
+		}
+		evt, err := jsontypes.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		res.Data = evt
+	}
+	return json.Marshal(res)
 }
 
 func (r *ResultEvent) UnmarshalJSON(data []byte) error {
@@ -340,12 +770,121 @@ func (r *ResultEvent) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &res); err != nil {
 		return err
 	}
-	if err := jsontypes.Unmarshal(res.Data, &r.Data); err != nil {
-		return err
+	if len(res.Data) != 0 || res.Stats == nil {
+		if err := jsontypes.Unmarshal(res.Data, &r.Data); err != nil {
+			return err
+		}
 	}
 	r.SubscriptionID = res.SubscriptionID
 	r.Query = res.Query
+	r.Seq = res.Seq
+	r.Events = res.Events
+	r.Stats = res.Stats
+	return nil
+}
+
+// SubscriptionStats reports the delivery statistics of a subscription, since
+// it was opened.
+type SubscriptionStats struct {
+	// Delivered is the number of events written to the client.
+	Delivered int64 `json:"delivered,string"`
+	// Dropped is the number of events the client missed because they could
+	// not be written in time. A client whose buffer on the server is full is
+	// unsubscribed instead, after a last stats frame, unless the event log of
+	// the server is persistent.
+	Dropped int64 `json:"dropped,string"`
+	// LastSeq is the sequence number of the last event delivered.
+	LastSeq uint64 `json:"last_seq,string"`
+	// Pending is the number of events buffered on the server for the client.
+	Pending int64 `json:"pending,string"`
+	// LastHeight is the height of the last event delivered, and LatestHeight
+	// the height of the latest block of the node.
+	LastHeight   int64 `json:"last_height,string"`
+	LatestHeight int64 `json:"latest_height,string"`
+	// Lag is the number of blocks the client is behind the latest block: the
+	// difference of the heights while events are pending, zero otherwise.
+	Lag int64 `json:"lag,string"`
+}
+
+// ResultSubscribeLabeled is the result of opening a labeled subscription,
+// with the number of events it buffers.
+type ResultSubscribeLabeled struct {
+	Label   string `json:"label"`
+	Credits int64  `json:"credits,string"`
+	Buffer  int64  `json:"buffer,string"`
+}
+
+// ResultGrantCredits reports the credits available to a labeled subscription
+// after a grant.
+type ResultGrantCredits struct {
+	Label   string `json:"label"`
+	Credits int64  `json:"credits,string"`
+}
+
+// ResultLabeledEvent frames an event delivered on a labeled subscription.
+// Cursor numbers the frames of each label consecutively, starting at 1. The
+// last frame of a subscription ended by the server has no data and reports
+// the reason in Terminated. Skipped is the number of events skipped since the
+// previous frame because the buffer of the subscription was full.
+type ResultLabeledEvent struct {
+	Label      string
+	Cursor     int64
+	Query      string
+	Data       types.TMEventData
+	Events     []abci.Event
+	Skipped    uint64
+	Terminated string
+}
+
+type resultLabeledEventJSON struct {
+	Label      string          `json:"label"`
+	Cursor     int64           `json:"cursor,string"`
+	Query      string          `json:"query"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Events     []abci.Event    `json:"events,omitempty"`
+	Skipped    uint64          `json:"skipped,omitempty,string"`
+	Terminated string          `json:"terminated,omitempty"`
+}
+
+func (r ResultLabeledEvent) MarshalJSON() ([]byte, error) {
+	res := resultLabeledEventJSON{
+		Label:      r.Label,
+		Cursor:     r.Cursor,
+		Query:      r.Query,
+		Events:     r.Events,
+		Skipped:    r.Skipped,
+		Terminated: r.Terminated,
+	}
+	if r.Data != nil {
+		data, ok := r.Data.(jsontypes.Tagged)
+		if !ok {
+			return nil, fmt.Errorf("type %T is not tagged", r.Data)
+		}
+		evt, err := jsontypes.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		res.Data = evt
+	}
+	return json.Marshal(res)
+}
+
+func (r *ResultLabeledEvent) UnmarshalJSON(data []byte) error {
+	var res resultLabeledEventJSON
+	if err := json.Unmarshal(data, &res); err != nil {
+		return err
+	}
+	if len(res.Data) != 0 {
+		if err := jsontypes.Unmarshal(res.Data, &r.Data); err != nil {
+			return err
+		}
+	}
+	r.Label = res.Label
+	r.Cursor = res.Cursor
+	r.Query = res.Query
 	r.Events = res.Events
+	r.Skipped = res.Skipped
+	r.Terminated = res.Terminated
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 	mrand "math/rand"
 	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -514,6 +515,15 @@ func (c *WSClient) Subscribe(ctx context.Context, query string) error {
 	return c.Call(ctx, "subscribe", params)
 }
 
+// SubscribeAfter subscribes to a query, resuming a previous subscription after
+// the event with the given sequence number: the events published since then
+// are delivered first, if the server still retains them. Note the server must
+// have a "subscribe" route defined.
+func (c *WSClient) SubscribeAfter(ctx context.Context, query string, after uint64) error {
+	params := map[string]interface{}{"query": query, "after": strconv.FormatUint(after, 10)}
+	return c.Call(ctx, "subscribe", params)
+}
+
 // Unsubscribe from a query. Note the server must have a "unsubscribe" route
 // defined.
 func (c *WSClient) Unsubscribe(ctx context.Context, query string) error {
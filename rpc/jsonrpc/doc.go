@@ -16,8 +16,8 @@
 //
 //   curl http://localhost:8008/hello_world?name=\"my_world\"&num=5
 //
-// A GET request to `/` returns a list of available endpoints.
-// For those which take arguments, the arguments will be listed in order, with `_` where the actual value should be.
+// A GET request to `/` or `/openapi.json` returns an OpenAPI 3 document describing the available
+// endpoints, with their arguments and results.
 //
 // POST (JSONRPC)
 //
@@ -37,6 +37,10 @@
 //
 //   curl --data @data.json http://localhost:8008
 //
+// The params may also be given by position, as an array, e.g. `["my_world", 5]`, which may end
+// with an object of the following params given by name, e.g. `["my_world", {"num": 5}]`. The
+// params not given get their default value (see RPCFunc.Default), or the zero value of their type.
+//
 //
 // WebSocket (JSONRPC)
 //
@@ -76,7 +80,7 @@
 //   go rpcserver.Serve(listener, mux, logger)
 //
 // Note that unix sockets are supported as well (eg. `/path/to/socket` instead of `0.0.0.0:8008`)
-// Now see all available endpoints by sending a GET request to `0.0.0.0:8008`.
+// Now see all available endpoints by sending a GET request to `0.0.0.0:8008/openapi.json`.
 // Each route is available as a GET request, as a JSONRPCv2 POST request, and via JSONRPCv2 over websockets.
 //
 // Examples
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+// maxCacheAge is the max-age of the cached responses of a ResponseCache whose
+// entries do not expire, i.e. one year.
+const maxCacheAge = 365 * 24 * time.Hour
+
+// Cacheable makes the results of the calls of f cacheable by the HTTP
+// handlers given a ResponseCache (see CacheResponses), for the calls that f
+// reports immutable with rpctypes.CallInfo.SetImmutable. It returns f, to be
+// used in route maps:
+//
+//	"block": rpc.NewRPCFunc(env.Block, "height").Cacheable(),
+func (f *RPCFunc) Cacheable() *RPCFunc {
+	f.cacheable = true
+	return f
+}
+
+// ResponseCache is an LRU cache of the results of immutable calls, e.g. of
+// the blocks at past heights, which are then served from memory with an
+// entity tag. It is safe for concurrent use, and can be shared by several
+// handlers.
+type ResponseCache struct {
+	size int
+	ttl  time.Duration
+
+	mtx     sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *cacheEntry, the least recently used first
+}
+
+// cacheEntry is the result of a call, encoded in JSON.
+type cacheEntry struct {
+	key     string
+	result  json.RawMessage
+	etag    string
+	expires time.Time // zero if the entry does not expire
+}
+
+// NewResponseCache returns a cache of the results of at most size calls,
+// each kept at most ttl. If ttl is 0, the results do not expire.
+func NewResponseCache(size int, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+// get returns the unexpired entry with the given key.
+func (c *ResponseCache) get(key string) (*cacheEntry, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		c.lru.Remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToBack(elem)
+	return entry, true
+}
+
+// put adds the result of a call with the given key, evicting the least
+// recently used entry if the cache is full, and returns its entry.
+func (c *ResponseCache) put(key string, result json.RawMessage) *cacheEntry {
+	sum := sha256.Sum256(result)
+	entry := &cacheEntry{
+		key:    key,
+		result: result,
+		etag:   `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.size <= 0 {
+		return entry
+	}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.Remove(elem)
+	} else if c.lru.Len() >= c.size {
+		if front := c.lru.Front(); front != nil {
+			delete(c.entries, front.Value.(*cacheEntry).key)
+			c.lru.Remove(front)
+		}
+	}
+	c.entries[key] = c.lru.PushBack(entry)
+	return entry
+}
+
+// cacheControl returns the Cache-Control header of the cached responses.
+func (c *ResponseCache) cacheControl() string {
+	maxAge := c.ttl
+	if maxAge <= 0 {
+		maxAge = maxCacheAge
+	}
+	return fmt.Sprintf("public, max-age=%d, immutable", int64(maxAge/time.Second))
+}
+
+// cacheKey returns the key of the call of method with args.
+func cacheKey(method string, args []reflect.Value) (string, error) {
+	params := make([]interface{}, len(args)-1)
+	for i, arg := range args[1:] {
+		params[i] = arg.Interface()
+	}
+	bz, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	return method + string(bz), nil
+}
+
+// callRPCFunc calls rpcFunc with args, the arguments of a call of method made
+// with ctx, and returns its result. The immutable results of a cacheable
+// function are served from the cache of hopts, if any, and returned with
+// their entity tag; etag is empty otherwise.
+func callRPCFunc(
+	ctx context.Context,
+	hopts handlerOptions,
+	method string,
+	rpcFunc *RPCFunc,
+	args []reflect.Value,
+) (result interface{}, etag string, err error) {
+	cache := hopts.cache
+	if cache == nil || !rpcFunc.cacheable {
+		result, err = unreflectResult(rpcFunc.f.Call(args))
+		return result, "", err
+	}
+
+	key, err := cacheKey(method, args)
+	if err != nil {
+		result, err = unreflectResult(rpcFunc.f.Call(args))
+		return result, "", err
+	}
+	if entry, ok := cache.get(key); ok {
+		return entry.result, entry.etag, nil
+	}
+
+	result, err = unreflectResult(rpcFunc.f.Call(args))
+	if err != nil || !rpctypes.GetCallInfo(ctx).Immutable() {
+		return result, "", err
+	}
+	bz, err := json.Marshal(result)
+	if err != nil {
+		// The error is reported when encoding the response.
+		return result, "", nil
+	}
+	entry := cache.put(key, bz)
+	return entry.result, entry.etag, nil
+}
+
+// setCacheHeaders sets the ETag and Cache-Control headers of an HTTP response
+// with a cached result, and reports whether the client has the result
+// already, according to the If-None-Match header of req.
+func setCacheHeaders(h http.Header, req *http.Request, cache *ResponseCache, etag string) (notModified bool) {
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", cache.cacheControl())
+
+	// See RFC 7232, section 3.2: the weak comparison is used.
+	for _, tag := range strings.Split(req.Header.Get("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+func TestResponseCacheEviction(t *testing.T) {
+	cache := NewResponseCache(2, 0)
+	cache.put("a", json.RawMessage(`1`))
+	cache.put("b", json.RawMessage(`2`))
+
+	// a is used last, and b is evicted.
+	_, ok := cache.get("a")
+	require.True(t, ok)
+	cache.put("c", json.RawMessage(`3`))
+
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+	for _, key := range []string{"a", "c"} {
+		_, ok = cache.get(key)
+		assert.True(t, ok, key)
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	cache := NewResponseCache(2, time.Millisecond)
+	entry := cache.put("a", json.RawMessage(`1`))
+	assert.Equal(t, "public, max-age=0, immutable", cache.cacheControl())
+
+	cached, ok := cache.get("a")
+	require.True(t, ok)
+	assert.Equal(t, entry.etag, cached.etag)
+
+	time.Sleep(2 * time.Millisecond)
+	_, ok = cache.get("a")
+	assert.False(t, ok)
+}
+
+func TestCachedResponses(t *testing.T) {
+	var calls int32
+	block := func(ctx context.Context, height *int64) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		if height != nil {
+			rpctypes.GetCallInfo(ctx).SetImmutable()
+			return "block", nil
+		}
+		return "latest", nil
+	}
+	funcMap := map[string]*RPCFunc{
+		"block":  NewRPCFunc(block, "height").Cacheable(),
+		"latest": NewRPCFunc(block, "height"),
+	}
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger(), CacheResponses(NewResponseCache(10, time.Minute)))
+
+	get := func(url, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+	reset := func() int32 { return atomic.SwapInt32(&calls, 0) }
+
+	// The responses of the immutable calls are cached, with their entity tag.
+	rec := get("/block?height=1", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	assert.Equal(t, "public, max-age=60, immutable", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Body.String(), `"block"`)
+
+	rec = get("/block?height=1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Contains(t, rec.Body.String(), `"block"`)
+	assert.EqualValues(t, 1, reset())
+
+	// A client with the response gets no body.
+	for _, tag := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		rec = get("/block?height=1", tag)
+		assert.Equal(t, http.StatusNotModified, rec.Code, tag)
+		assert.Empty(t, rec.Body.String(), tag)
+	}
+	rec = get("/block?height=1", `"other"`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.EqualValues(t, 0, reset())
+
+	// The other calls are not.
+	for i := 0; i < 2; i++ {
+		rec = get("/block", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("ETag"))
+		assert.Empty(t, rec.Header().Get("Cache-Control"))
+		assert.Contains(t, rec.Body.String(), `"latest"`)
+
+		rec = get("/latest?height=1", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("ETag"))
+	}
+	assert.EqualValues(t, 4, reset())
+
+	// JSON-RPC calls share the cache.
+	req := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "block", "id": 1, "params": {"height": "1"}}`))
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	var rsp rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	assert.Nil(t, rsp.Error)
+	assert.JSONEq(t, `"block"`, string(rsp.Result))
+	assert.EqualValues(t, 0, reset())
+}
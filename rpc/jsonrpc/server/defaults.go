@@ -0,0 +1,79 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Default sets the value of the parameter name of f when a call does not give
+// it, instead of the zero value of its type. It returns f, to be used in
+// route maps:
+//
+//	"tx_search": rpc.NewRPCFunc(env.TxSearch, "query", "prove", "page", "per_page", "order_by").
+//		Default("order_by", "asc"),
+//
+// The value must be of the type of the parameter, or of the type it points
+// to, or an integer for an integer parameter. Default panics otherwise, or if
+// f has no such parameter.
+func (f *RPCFunc) Default(name string, value interface{}) *RPCFunc {
+	i := -1
+	for j, argName := range f.argNames {
+		if argName == name {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		panic(fmt.Sprintf("invalid default: no parameter %q", name))
+	}
+
+	v, err := defaultValue(f.args[i+1], value)
+	if err != nil {
+		panic(fmt.Sprintf("invalid default of parameter %q: %v", name, err))
+	}
+	if f.defaults == nil {
+		f.defaults = make([]reflect.Value, len(f.argNames))
+	}
+	f.defaults[i] = v
+	return f
+}
+
+// defaultValue converts value to the type atype, or the type it points to.
+func defaultValue(atype reflect.Type, value interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return reflect.Value{}, fmt.Errorf("nil value")
+	}
+
+	base := atype
+	if atype.Kind() == reflect.Ptr {
+		base = atype.Elem()
+	}
+	switch {
+	case v.Type().AssignableTo(base):
+	case isIntType(v.Type()) && isIntType(base):
+		v = v.Convert(base)
+	default:
+		return reflect.Value{}, fmt.Errorf("%v is not assignable to %v", v.Type(), atype)
+	}
+
+	out := reflect.New(base).Elem()
+	out.Set(v)
+	return out, nil
+}
+
+// argDefault returns the value of the i-th argument of f (excluding the
+// context) when it is not given: its default, or the zero value of its type.
+func (f *RPCFunc) argDefault(i int) reflect.Value {
+	atype := f.args[i+1]
+	if i >= len(f.defaults) || !f.defaults[i].IsValid() {
+		return reflect.Zero(atype)
+	}
+	if atype.Kind() == reflect.Ptr {
+		// Each call gets its own copy, which it may modify.
+		p := reflect.New(atype.Elem())
+		p.Elem().Set(f.defaults[i])
+		return p
+	}
+	return f.defaults[i]
+}
@@ -0,0 +1,38 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+// Deprecated marks the method of f as deprecated: it is still served, but the
+// responses to its calls carry d, in the deprecation member of JSON-RPC
+// responses and in the Deprecation, Sunset and Warning HTTP headers, so that
+// clients can move to its replacement before it is removed. It returns f, to
+// be used in route maps:
+//
+//	"old_method": rpc.NewRPCFunc(env.OldMethod, "height").Deprecated(rpctypes.Deprecation{
+//	    Replacement:    "new_method",
+//	    RemovalVersion: "v0.37.0",
+//	}),
+func (f *RPCFunc) Deprecated(d rpctypes.Deprecation) *RPCFunc {
+	f.deprecation = &d
+	return f
+}
+
+// setDeprecationHeaders sets the headers of an HTTP response to a call of
+// method, deprecated by d: Deprecation (draft-ietf-httpapi-deprecation-header),
+// Sunset (RFC 8594) and Warning (RFC 7234). The headers of several calls of a
+// batch are combined.
+func setDeprecationHeaders(h http.Header, method string, d *rpctypes.Deprecation) {
+	h.Set("Deprecation", "true")
+	if d.Sunset != nil {
+		// The earliest sunset of a batch applies.
+		if prev, err := http.ParseTime(h.Get("Sunset")); err != nil || d.Sunset.Before(prev) {
+			h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+	}
+	h.Add("Warning", fmt.Sprintf("299 - %q", d.Message(method)))
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+// callCounter is a metrics.Counter counting the calls by method.
+type callCounter struct {
+	mtx    *sync.Mutex
+	calls  map[string]int
+	method string
+}
+
+func (c *callCounter) With(labelValues ...string) metrics.Counter {
+	return &callCounter{mtx: c.mtx, calls: c.calls, method: labelValues[1]}
+}
+
+func (c *callCounter) Add(float64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.calls[c.method]++
+}
+
+func TestDeprecatedMethod(t *testing.T) {
+	sunset := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	deprecation := rpctypes.Deprecation{
+		Replacement:    "new",
+		RemovalVersion: "v0.37.0",
+		Sunset:         &sunset,
+	}
+	call := func(ctx context.Context, s string) (string, error) { return s, nil }
+	funcMap := map[string]*RPCFunc{
+		"old":    NewRPCFunc(call, "s").Deprecated(deprecation),
+		"new":    NewRPCFunc(call, "s"),
+		"old_ws": NewWSRPCFunc(call, "s").Deprecated(rpctypes.Deprecation{}),
+	}
+	counter := &callCounter{mtx: new(sync.Mutex), calls: make(map[string]int)}
+	m := NopMetrics()
+	m.DeprecatedCalls = counter
+
+	logger := log.NewNopLogger()
+	mux := http.NewServeMux()
+	wm := NewWebsocketManager(logger, funcMap, WSRecordMetrics(m))
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+	RegisterRPCFuncs(mux, funcMap, logger, RecordMetrics(m))
+
+	const warning = `299 - "method old is deprecated and will be removed in v0.37.0, use new instead"`
+	post := func(body string) (*http.Response, []byte) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		res := rec.Result()
+		defer res.Body.Close()
+		blob, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, blob
+	}
+
+	// A deprecated method is served, with its deprecation.
+	res, blob := post(`{"jsonrpc": "2.0", "method": "old", "id": 1, "params": ["a"]}`)
+	assert.Equal(t, "true", res.Header.Get("Deprecation"))
+	assert.Equal(t, "Wed, 02 Jan 2030 03:04:05 GMT", res.Header.Get("Sunset"))
+	assert.Equal(t, []string{warning}, res.Header.Values("Warning"))
+	var rsp rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(blob, &rsp))
+	assert.Nil(t, rsp.Error)
+	assert.JSONEq(t, `"a"`, string(rsp.Result))
+	require.NotNil(t, rsp.Deprecation)
+	assert.Equal(t, "new", rsp.Deprecation.Replacement)
+	assert.Equal(t, "v0.37.0", rsp.Deprecation.RemovalVersion)
+	assert.True(t, sunset.Equal(*rsp.Deprecation.Sunset))
+
+	res, blob = post(`{"jsonrpc": "2.0", "method": "new", "id": 1, "params": ["a"]}`)
+	assert.Empty(t, res.Header.Get("Deprecation"))
+	assert.Empty(t, res.Header.Values("Warning"))
+	assert.NotContains(t, string(blob), "deprecation")
+
+	// In a batch, only the responses of the deprecated calls carry the
+	// deprecation, and the headers are not repeated.
+	res, blob = post(`[
+		{"jsonrpc": "2.0", "method": "old", "id": 1, "params": ["a"]},
+		{"jsonrpc": "2.0", "method": "new", "id": 2, "params": ["b"]},
+		{"jsonrpc": "2.0", "method": "old", "id": 3, "params": ["c"]}
+	]`)
+	assert.Equal(t, []string{warning}, res.Header.Values("Warning"))
+	var rsps []rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(blob, &rsps))
+	require.Len(t, rsps, 3)
+	assert.NotNil(t, rsps[0].Deprecation)
+	assert.Nil(t, rsps[1].Deprecation)
+	assert.NotNil(t, rsps[2].Deprecation)
+
+	// URI calls get the headers.
+	req := httptest.NewRequest(http.MethodGet, `/old?s="a"`, nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{warning}, rec.Result().Header.Values("Warning"))
+
+	// Websocket calls get the deprecation in their responses.
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c, dialResp, err := websocket.DefaultDialer.Dial("ws://"+srv.Listener.Addr().String()+"/websocket", nil)
+	require.NoError(t, err)
+	defer dialResp.Body.Close()
+	defer c.Close()
+	wsReq, err := rpctypes.ParamsToRequest(rpctypes.JSONRPCStringID("ws"), "old_ws", map[string]interface{}{"s": "a"})
+	require.NoError(t, err)
+	require.NoError(t, c.WriteJSON(wsReq))
+	var wsRsp rpctypes.RPCResponse
+	require.NoError(t, c.ReadJSON(&wsRsp))
+	assert.Nil(t, wsRsp.Error)
+	assert.NotNil(t, wsRsp.Deprecation)
+
+	counter.mtx.Lock()
+	defer counter.mtx.Unlock()
+	assert.Equal(t, map[string]int{"old": 4, "old_ws": 1}, counter.calls)
+}
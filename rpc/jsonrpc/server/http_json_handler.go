@@ -9,8 +9,9 @@ import (
 	"io"
 	"net/http"
 	"reflect"
-	"sort"
+	"runtime/debug"
 	"strconv"
+	"time"
 
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/rpc/coretypes"
@@ -20,93 +21,261 @@ import (
 // HTTP + JSON handler
 
 // jsonrpc calls grab the given method's function info and runs reflect.Call
-func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger) http.HandlerFunc {
+func makeJSONRPCHandler(funcMap map[string]*RPCFunc, logger log.Logger, hopts handlerOptions) http.HandlerFunc {
+	writeOpenAPI := makeOpenAPIHandler(funcMap, logger, hopts)
 	return func(w http.ResponseWriter, hreq *http.Request) {
+		// In strict mode, even a panic gets a JSON-RPC error. It is a no-op
+		// if the handler panicked after sending a (partial) response.
+		if hopts.strict {
+			defer func() {
+				if v := recover(); v != nil {
+					logger.Error("Panic in JSON-RPC handler", "err", v, "stack", string(debug.Stack()))
+					writeRPCResponse(w, logger, hopts.response(
+						rpctypes.RPCInternalError(nil, fmt.Errorf("panic: %v", v))))
+				}
+			}()
+		}
+
 		// For POST requests, reject a non-root URL path. This should not happen
 		// in the standard configuration, since the wrapper checks the path.
 		if hreq.URL.Path != "/" {
-			writeRPCResponse(w, logger, rpctypes.RPCInvalidRequestError(
-				nil, fmt.Errorf("invalid path: %q", hreq.URL.Path)))
+			writeRPCResponse(w, logger, hopts.response(rpctypes.RPCInvalidRequestError(
+				nil, fmt.Errorf("invalid path: %q", hreq.URL.Path))))
 			return
 		}
 
 		b, err := io.ReadAll(hreq.Body)
 		if err != nil {
-			writeRPCResponse(w, logger, rpctypes.RPCInvalidRequestError(
-				nil, fmt.Errorf("reading request body: %w", err)))
+			writeRPCResponse(w, logger, hopts.response(rpctypes.RPCInvalidRequestError(
+				nil, fmt.Errorf("reading request body: %w", err))))
 			return
 		}
 
-		// if its an empty request (like from a browser), just display a list of
+		// if its an empty request (like from a browser), describe the
 		// functions
 		if len(b) == 0 {
-			writeListOfEndpoints(w, hreq, funcMap)
+			writeOpenAPI(w, hreq)
 			return
 		}
 
-		requests, err := parseRequests(b)
+		// In strict mode, the invalid requests of a batch get their own error,
+		// and the valid ones are still called.
+		var requests []rpctypes.RPCRequest
+		var invalid []error // by request, nil for the valid ones
+		if hopts.strict {
+			requests, invalid, err = parseStrictRequests(b)
+		} else {
+			requests, err = parseRequests(b)
+		}
 		if err != nil {
-			writeRPCResponse(w, logger, rpctypes.RPCParseError(fmt.Errorf("decoding request: %w", err)))
+			writeRPCResponse(w, logger, hopts.response(
+				rpctypes.RPCParseError(fmt.Errorf("decoding request: %w", err))))
+			return
+		}
+		batch := isBatch(b)
+		if hopts.strict && len(requests) == 0 {
+			writeRPCResponse(w, logger, hopts.response(
+				rpctypes.RPCInvalidRequestError(nil, errors.New("empty batch"))))
 			return
 		}
+		valid := func(i int) bool { return invalid == nil || invalid[i] == nil }
 
-		var responses []rpctypes.RPCResponse
-		for _, req := range requests {
-			// Ignore notifications, which this service does not support.
-			if req.ID == nil {
-				logger.Debug("Ignoring notification", "req", req)
-				continue
+		// The headers of the calls of deprecated methods must be set before
+		// any response is written.
+		deprecated := make(map[string]bool)
+		for i, req := range requests {
+			rpcFunc, ok := funcMap[req.Method]
+			if ok && valid(i) && !rpcFunc.ws && rpcFunc.deprecation != nil && req.ID != nil && !deprecated[req.Method] {
+				setDeprecationHeaders(w.Header(), req.Method, rpcFunc.deprecation)
+				deprecated[req.Method] = true
 			}
+		}
 
-			rpcFunc, ok := funcMap[req.Method]
-			if !ok || rpcFunc.ws {
-				responses = append(responses, rpctypes.RPCMethodNotFoundError(req.ID))
-				continue
+		// A single request over the rate limits is rejected with status 429.
+		// In a batch, only the requests over the limits get an error.
+		opts := hopts
+		if len(requests) == 1 && requests[0].ID != nil && valid(0) && !(hopts.strict && batch) {
+			if err := hopts.rateLimiter.Allow(requests[0].Method, hreq.RemoteAddr); err != nil {
+				rsp := rpctypes.RPCRateLimitError(requests[0].ID, err)
+				hopts.metrics.observeCall(methodLabel(funcMap, requests[0].Method), protocolJSONRPC,
+					time.Now(), len(requests[0].Params), rsp)
+				writeRateLimited(w, logger, hopts.response(rsp))
+				return
 			}
+			opts.rateLimiter = nil // the call is counted already
+		}
 
-			req := req
-			ctx := rpctypes.WithCallInfo(hreq.Context(), &rpctypes.CallInfo{
-				RPCRequest:  &req,
-				HTTPRequest: hreq,
-			})
-			args, err := parseParams(ctx, rpcFunc, req.Params)
-			if err != nil {
-				responses = append(responses, rpctypes.RPCInvalidParamsError(
-					req.ID, fmt.Errorf("converting JSON parameters: %w", err)))
-				continue
+		handle := func(i int) (rpctypes.RPCResponse, bool) {
+			if !valid(i) {
+				return hopts.response(rpctypes.RPCInvalidRequestError(requests[i].ID, invalid[i])), true
 			}
+			rsp, ok := handleJSONRPCRequest(hreq, funcMap, logger, opts, requests[i])
+			return hopts.response(rsp), ok
+		}
 
-			returns := rpcFunc.f.Call(args)
-			logger.Debug("HTTPJSONRPC", "method", req.Method, "args", args, "returns", returns)
-			result, err := unreflectResult(returns)
-			switch e := err.(type) {
-			// if no error then return a success response
-			case nil:
-				responses = append(responses, rpctypes.NewRPCSuccessResponse(req.ID, result))
-
-			// if this already of type RPC error then forward that error
-			case *rpctypes.RPCError:
-				responses = append(responses, rpctypes.NewRPCErrorResponse(req.ID, e.Code, e.Message, e.Data))
-			default: // we need to unwrap the error and parse it accordingly
-				switch errors.Unwrap(err) {
-				// check if the error was due to an invald request
-				case coretypes.ErrZeroOrNegativeHeight, coretypes.ErrZeroOrNegativePerPage,
-					coretypes.ErrPageOutOfRange, coretypes.ErrInvalidRequest:
-					responses = append(responses, rpctypes.RPCInvalidRequestError(req.ID, err))
-				// lastly default all remaining errors as internal errors
-				default: // includes ctypes.ErrHeightNotAvailable and ctypes.ErrHeightExceedsChainHead
-					responses = append(responses, rpctypes.RPCInternalError(req.ID, err))
+		// Large batches are streamed, so that the responses need not all be
+		// held in memory at once. A single request is never streamed, since
+		// its response is not an array.
+		if len(requests) > 1 && hopts.streamBatchThreshold > 0 && len(requests) >= hopts.streamBatchThreshold {
+			stream := newBatchStream(w, logger)
+			for i := range requests {
+				if rsp, ok := handle(i); ok {
+					stream.write(rsp)
 				}
 			}
+			stream.close()
+			return
+		}
+
+		var responses []rpctypes.RPCResponse
+		for i := range requests {
+			if rsp, ok := handle(i); ok {
+				responses = append(responses, rsp)
+			}
 		}
 
 		if len(responses) == 0 {
 			return
 		}
+		if hopts.strict && batch {
+			writeRPCBatchResponse(w, logger, responses)
+			return
+		}
 		writeRPCResponse(w, logger, responses...)
 	}
 }
 
+// handleJSONRPCRequest calls the function of req, one of the requests
+// received in hreq, and returns its response. It returns false if req is a
+// notification, which gets no response.
+func handleJSONRPCRequest(
+	hreq *http.Request,
+	funcMap map[string]*RPCFunc,
+	logger log.Logger,
+	hopts handlerOptions,
+	req rpctypes.RPCRequest,
+) (rsp rpctypes.RPCResponse, ok bool) {
+	// Ignore notifications, which this service does not support.
+	if req.ID == nil {
+		logger.Debug("Ignoring notification", "req", req)
+		return rpctypes.RPCResponse{}, false
+	}
+
+	start := time.Now()
+	defer func() {
+		hopts.metrics.observeCall(methodLabel(funcMap, req.Method), protocolJSONRPC, start, len(req.Params), rsp)
+	}()
+
+	rpcFunc, ok := funcMap[req.Method]
+	if !ok || rpcFunc.ws {
+		return rpctypes.RPCMethodNotFoundError(req.ID), true
+	}
+	if err := hopts.rateLimiter.Allow(req.Method, hreq.RemoteAddr); err != nil {
+		return rpctypes.RPCRateLimitError(req.ID, err), true
+	}
+	if d := rpcFunc.deprecation; d != nil {
+		hopts.metrics.deprecatedCall(req.Method)
+		defer func() { rsp.Deprecation = d }()
+	}
+
+	ctx := rpctypes.WithCallInfo(hreq.Context(), &rpctypes.CallInfo{
+		RPCRequest:  &req,
+		HTTPRequest: hreq,
+		Caller:      rpctypes.GetCaller(hreq.Context()),
+	})
+	args, err := parseParams(ctx, rpcFunc, req.Params)
+	if err != nil {
+		return rpctypes.RPCInvalidParamsError(
+			req.ID, fmt.Errorf("converting JSON parameters: %w", err)), true
+	}
+
+	result, _, err := callRPCFunc(ctx, hopts, req.Method, rpcFunc, args)
+	logger.Debug("HTTPJSONRPC", "method", req.Method, "args", args, "result", result, "err", err)
+	switch e := err.(type) {
+	// if no error then return a success response
+	case nil:
+		return rpctypes.NewRPCSuccessResponse(req.ID, result), true
+
+	// if this already of type RPC error then forward that error
+	case *rpctypes.RPCError:
+		return rpctypes.NewRPCErrorResponse(req.ID, e.Code, e.Message, e.Data), true
+	default: // we need to unwrap the error and parse it accordingly
+		switch errors.Unwrap(err) {
+		// check if the error was due to an invald request
+		case coretypes.ErrZeroOrNegativeHeight, coretypes.ErrZeroOrNegativePerPage,
+			coretypes.ErrPerPageExceedsMax, coretypes.ErrPageOutOfRange,
+			coretypes.ErrInvalidRequest:
+			return rpctypes.RPCInvalidRequestError(req.ID, err), true
+		case coretypes.ErrCatchingUp:
+			return rpctypes.RPCCatchingUpError(req.ID, err), true
+		// lastly default all remaining errors as internal errors
+		default: // includes ctypes.ErrHeightNotAvailable and ctypes.ErrHeightExceedsChainHead
+			return rpctypes.RPCInternalError(req.ID, err), true
+		}
+	}
+}
+
+// batchStream writes the responses to a batch one at a time, as elements of
+// a JSON array, flushing each to the client. Since the length of the body is
+// not known in advance, the server uses chunked transfer encoding.
+//
+// The status and the opening bracket are written with the first response, so
+// that a batch of notifications gets an empty response, as when not streaming.
+type batchStream struct {
+	w       http.ResponseWriter
+	logger  log.Logger
+	started bool
+	failed  bool
+}
+
+func newBatchStream(w http.ResponseWriter, logger log.Logger) *batchStream {
+	return &batchStream{w: w, logger: logger}
+}
+
+// write writes rsp to the stream. Once a write fails, e.g. because the client
+// went away, the remaining responses are discarded.
+func (bs *batchStream) write(rsp rpctypes.RPCResponse) {
+	if bs.failed {
+		return
+	}
+	body, err := json.Marshal(rsp)
+	if err != nil {
+		// The status is sent already, so report the error to the client in
+		// place of the response.
+		bs.logger.Error("Error encoding RPC response", "err", err)
+		body, err = json.Marshal(rpctypes.RPCInternalError(rsp.ID, err))
+		if err != nil {
+			bs.logger.Error("Error encoding RPC error response", "err", err)
+			bs.failed = true
+			return
+		}
+	}
+
+	sep := []byte(",")
+	if !bs.started {
+		bs.w.Header().Set("Content-Type", "application/json")
+		bs.w.WriteHeader(http.StatusOK)
+		bs.started = true
+		sep = []byte("[")
+	}
+	if _, err := bs.w.Write(append(sep, body...)); err != nil {
+		bs.logger.Debug("Error writing streamed RPC response", "err", err)
+		bs.failed = true
+		return
+	}
+	if f, ok := bs.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// close terminates the JSON array of responses, if any was written.
+func (bs *batchStream) close() {
+	if !bs.started || bs.failed {
+		return
+	}
+	_, _ = bs.w.Write([]byte("]"))
+}
+
 func handleInvalidJSONRPCPaths(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Since the pattern "/" matches all paths not matched by other registered patterns,
@@ -125,8 +294,7 @@ func parseRequests(data []byte) ([]rpctypes.RPCRequest, error) {
 	var reqs []rpctypes.RPCRequest
 	var err error
 
-	isArray := bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))
-	if isArray {
+	if isBatch(data) {
 		err = json.Unmarshal(data, &reqs)
 	} else {
 		reqs = append(reqs, rpctypes.RPCRequest{})
@@ -138,6 +306,44 @@ func parseRequests(data []byte) ([]rpctypes.RPCRequest, error) {
 	return reqs, nil
 }
 
+// parseStrictRequests parses a JSON-RPC request or request batch from data,
+// enforcing the specification (see rpctypes.ParseStrictRequest). It returns
+// an error only if data is not valid JSON; the errors of the invalid requests
+// are returned by request, along with the requests, or nil if all are valid.
+func parseStrictRequests(data []byte) ([]rpctypes.RPCRequest, []error, error) {
+	var elems []json.RawMessage
+	if isBatch(data) {
+		if err := json.Unmarshal(data, &elems); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if !json.Valid(data) {
+			var v interface{}
+			return nil, nil, json.Unmarshal(data, &v) // for a detailed error
+		}
+		elems = append(elems, data)
+	}
+
+	reqs := make([]rpctypes.RPCRequest, len(elems))
+	var errs []error
+	for i, elem := range elems {
+		req, err := rpctypes.ParseStrictRequest(elem)
+		if err != nil {
+			if errs == nil {
+				errs = make([]error, len(elems))
+			}
+			errs[i] = err
+		}
+		reqs[i] = req
+	}
+	return reqs, errs, nil
+}
+
+// isBatch reports whether data encodes a batch of requests, i.e. an array.
+func isBatch(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))
+}
+
 // parseParams parses the JSON parameters of rpcReq into the arguments of fn,
 // returning the corresponding argument values or an error.
 func parseParams(ctx context.Context, fn *RPCFunc, paramData []byte) ([]reflect.Value, error) {
@@ -151,7 +357,7 @@ func parseParams(ctx context.Context, fn *RPCFunc, paramData []byte) ([]reflect.
 	for i, param := range params {
 		ptype := fn.args[i+1]
 		if len(param) == 0 {
-			args[i+1] = reflect.Zero(ptype)
+			args[i+1] = fn.argDefault(i)
 			continue
 		}
 
@@ -184,21 +390,20 @@ func parseParams(ctx context.Context, fn *RPCFunc, paramData []byte) ([]reflect.
 }
 
 // parseJSONParams parses data and returns a slice of JSON values matching the
-// positional parameters of fn. It reports an error if data is not "null" and
-// does not encode an object or an array, or if the number of array parameters
-// does not match the argument list of fn (excluding the context).
+// positional parameters of fn, with an empty value for each parameter not
+// given. It reports an error if data is not empty or "null" and does not
+// encode an object or an array, or if the array has more parameters than the
+// argument list of fn (excluding the context).
+//
+// An array may end with an object of the parameters following the positional
+// ones, given by name, e.g. ["tx.height=5", {"per_page": 10}], unless the
+// parameter at the position of the object may itself be an object.
 func parseJSONParams(fn *RPCFunc, data []byte) ([]json.RawMessage, error) {
 	base := bytes.TrimSpace(data)
 	if bytes.HasPrefix(base, []byte("{")) {
-		var m map[string]json.RawMessage
-		if err := json.Unmarshal(base, &m); err != nil {
-			return nil, fmt.Errorf("decoding parameter object: %w", err)
-		}
 		out := make([]json.RawMessage, len(fn.argNames))
-		for i, name := range fn.argNames {
-			if p, ok := m[name]; ok {
-				out[i] = p
-			}
+		if err := parseNamedParams(fn, base, out, 0); err != nil {
+			return nil, err
 		}
 		return out, nil
 
@@ -207,18 +412,69 @@ func parseJSONParams(fn *RPCFunc, data []byte) ([]json.RawMessage, error) {
 		if err := json.Unmarshal(base, &m); err != nil {
 			return nil, fmt.Errorf("decoding parameter array: %w", err)
 		}
-		if len(m) != len(fn.argNames) {
-			return nil, fmt.Errorf("got %d parameters, want %d", len(m), len(fn.argNames))
+		if len(m) > len(fn.argNames) {
+			return nil, fmt.Errorf("got %d parameters, want at most %d", len(m), len(fn.argNames))
+		}
+		out := make([]json.RawMessage, len(fn.argNames))
+		copy(out, m)
+		if n := len(m); n > 0 && isObjectValue(m[n-1]) && !acceptsObject(fn.args[n]) {
+			out[n-1] = nil
+			if err := parseNamedParams(fn, m[n-1], out, n-1); err != nil {
+				return nil, err
+			}
 		}
-		return m, nil
+		return out, nil
 
-	} else if bytes.Equal(base, []byte("null")) {
+	} else if len(base) == 0 || bytes.Equal(base, []byte("null")) {
 		return make([]json.RawMessage, len(fn.argNames)), nil
 	}
 
 	return nil, errors.New("parameters must be an object or an array")
 }
 
+// parseNamedParams parses the object data of named parameters into out, for
+// the parameters of fn from the position first on. Names of other parameters
+// are ignored, except those of the parameters before first, which are given
+// by position.
+func parseNamedParams(fn *RPCFunc, data []byte, out []json.RawMessage, first int) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("decoding parameter object: %w", err)
+	}
+	for i, name := range fn.argNames {
+		p, ok := m[name]
+		if !ok {
+			continue
+		}
+		if i < first {
+			return fmt.Errorf("parameter %q given both by position and by name", name)
+		}
+		out[i] = p
+	}
+	return nil
+}
+
+// isObjectValue reports whether data is a JSON object value.
+func isObjectValue(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) != 0 && data[0] == '{'
+}
+
+// acceptsObject reports whether a JSON object may decode into a value of
+// atype.
+func acceptsObject(atype reflect.Type) bool {
+	for atype.Kind() == reflect.Ptr {
+		atype = atype.Elem()
+	}
+	switch atype.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface:
+		return true
+	}
+	return atype.Implements(jsonUnmarshalerType) || reflect.PtrTo(atype).Implements(jsonUnmarshalerType)
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
 // isStringValue reports whether data is a JSON string value.
 func isStringValue(data json.RawMessage) bool {
 	return len(data) != 0 && data[0] == '"'
@@ -234,43 +490,3 @@ func (z *int64String) UnmarshalText(data []byte) error {
 	*z = int64String(v)
 	return nil
 }
-
-// writes a list of available rpc endpoints as an html page
-func writeListOfEndpoints(w http.ResponseWriter, r *http.Request, funcMap map[string]*RPCFunc) {
-	noArgNames := []string{}
-	argNames := []string{}
-	for name, funcData := range funcMap {
-		if len(funcData.args) == 0 {
-			noArgNames = append(noArgNames, name)
-		} else {
-			argNames = append(argNames, name)
-		}
-	}
-	sort.Strings(noArgNames)
-	sort.Strings(argNames)
-	buf := new(bytes.Buffer)
-	buf.WriteString("<html><body>")
-	buf.WriteString("<br>Available endpoints:<br>")
-
-	for _, name := range noArgNames {
-		link := fmt.Sprintf("//%s/%s", r.Host, name)
-		buf.WriteString(fmt.Sprintf("<a href=\"%s\">%s</a></br>", link, link))
-	}
-
-	buf.WriteString("<br>Endpoints that require arguments:<br>")
-	for _, name := range argNames {
-		link := fmt.Sprintf("//%s/%s?", r.Host, name)
-		funcData := funcMap[name]
-		for i, argName := range funcData.argNames {
-			link += argName + "=_"
-			if i < len(funcData.argNames)-1 {
-				link += "&"
-			}
-		}
-		buf.WriteString(fmt.Sprintf("<a href=\"%s\">%s</a></br>", link, link))
-	}
-	buf.WriteString("</body></html>")
-	w.Header().Set("Content-Type", "text/html")
-	w.WriteHeader(200)
-	w.Write(buf.Bytes()) // nolint: errcheck
-}
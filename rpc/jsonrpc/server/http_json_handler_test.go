@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/rpc/coretypes"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 )
 
@@ -45,7 +47,7 @@ func TestRPCParams(t *testing.T) {
 		{`{"jsonrpc": "2.0", "method": "y", "id": "0"}`, "Method not found", rpctypes.JSONRPCStringID("0")},
 		// id not captured in JSON parsing failures
 		{`{"method": "c", "id": "0", "params": a}`, "invalid character", nil},
-		{`{"method": "c", "id": "0", "params": ["a"]}`, "got 1", rpctypes.JSONRPCStringID("0")},
+		{`{"method": "c", "id": "0", "params": ["a", "10", "b"]}`, "got 3", rpctypes.JSONRPCStringID("0")},
 		{`{"method": "c", "id": "0", "params": ["a", "b"]}`, "invalid syntax", rpctypes.JSONRPCStringID("0")},
 		{`{"method": "c", "id": "0", "params": [1, 1]}`, "of type string", rpctypes.JSONRPCStringID("0")},
 
@@ -56,6 +58,7 @@ func TestRPCParams(t *testing.T) {
 		{`{"jsonrpc": "2.0", "method": "c", "id": "0", "params": null}`, "", rpctypes.JSONRPCStringID("0")},
 		{`{"method": "c", "id": "0", "params": {}}`, "", rpctypes.JSONRPCStringID("0")},
 		{`{"method": "c", "id": "0", "params": ["a", "10"]}`, "", rpctypes.JSONRPCStringID("0")},
+		{`{"method": "c", "id": "0", "params": ["a"]}`, "", rpctypes.JSONRPCStringID("0")},
 	}
 
 	for i, tt := range tests {
@@ -259,3 +262,286 @@ func TestRPCResponseCache(t *testing.T) {
 	res.Body.Close()
 	require.NoError(t, err, "reading from the body should not give back an error")
 }
+
+func TestRPCCaller(t *testing.T) {
+	funcMap := map[string]*RPCFunc{
+		"whoami": NewRPCFunc(func(ctx context.Context) (rpctypes.Caller, error) {
+			callInfo := rpctypes.GetCallInfo(ctx)
+			if !callInfo.Authenticated() {
+				return rpctypes.Caller{}, errors.New("anonymous")
+			}
+			return callInfo.Caller, nil
+		}),
+	}
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger())
+
+	caller := rpctypes.Caller{Principal: "alice", TenantID: "acme", RateLimitClass: "gold"}
+	authenticate := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") == "Bearer alice" {
+				req = req.WithContext(rpctypes.WithCaller(req.Context(), caller))
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+	handler := authenticate(mux)
+
+	call := func(req *http.Request, authorized bool) []byte {
+		t.Helper()
+		if authorized {
+			req.Header.Set("Authorization", "Bearer alice")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		res := rec.Result()
+		defer res.Body.Close()
+
+		blob, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return blob
+	}
+
+	for _, authorized := range []bool{true, false} {
+		// The JSON handler responds with a JSON-RPC response.
+		req, _ := http.NewRequest("POST", "http://localhost/",
+			strings.NewReader(`{"jsonrpc": "2.0", "method": "whoami", "id": 0, "params": {}}`))
+		recv := new(rpctypes.RPCResponse)
+		require.NoError(t, json.Unmarshal(call(req, authorized), recv))
+		if authorized {
+			require.Nil(t, recv.Error)
+			var got rpctypes.Caller
+			require.NoError(t, json.Unmarshal(recv.Result, &got))
+			require.Equal(t, caller, got)
+		} else {
+			require.NotNil(t, recv.Error)
+			require.Contains(t, recv.Error.Data, "anonymous")
+		}
+
+		// The URI handler responds with the bare result or error.
+		req, _ = http.NewRequest("GET", "http://localhost/whoami", nil)
+		blob := call(req, authorized)
+		if authorized {
+			var got rpctypes.Caller
+			require.NoError(t, json.Unmarshal(blob, &got))
+			require.Equal(t, caller, got)
+		} else {
+			require.Contains(t, string(blob), "anonymous")
+		}
+	}
+}
+
+func TestRPCStreamBatch(t *testing.T) {
+	funcMap := map[string]*RPCFunc{
+		"block": NewRPCFunc(func(ctx context.Context, h int) (int, error) { return h, nil }, "height"),
+	}
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger(), StreamBatches(3))
+	srv := httptest.NewServer(recoverAndLogHandler(mux, log.NewNopLogger()))
+	defer srv.Close()
+
+	post := func(t *testing.T, payload string) (*http.Response, []byte) {
+		t.Helper()
+		res, err := http.Post(srv.URL, "application/json", strings.NewReader(payload))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		blob, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		return res, blob
+	}
+
+	t.Run("Streamed", func(t *testing.T) {
+		res, blob := post(t, `[
+			{"jsonrpc":"2.0","method":"block","id":1,"params":{"height":"1"}},
+			{"jsonrpc":"2.0","method":"block","params":{"height":"2"}},
+			{"jsonrpc":"2.0","method":"nope","id":3},
+			{"jsonrpc":"2.0","method":"block","id":4,"params":{"height":"4"}}
+		]`)
+		assert.Equal(t, []string{"chunked"}, res.TransferEncoding)
+
+		var responses []rpctypes.RPCResponse
+		require.NoError(t, json.Unmarshal(blob, &responses), "blob: %s", blob)
+		require.Len(t, responses, 3)
+		assert.Equal(t, rpctypes.JSONRPCIntID(1), responses[0].ID)
+		assert.Equal(t, json.RawMessage("1"), responses[0].Result)
+		assert.Equal(t, rpctypes.JSONRPCIntID(3), responses[1].ID)
+		require.NotNil(t, responses[1].Error)
+		assert.Equal(t, "Method not found", responses[1].Error.Message)
+		assert.Equal(t, rpctypes.JSONRPCIntID(4), responses[2].ID)
+		assert.Equal(t, json.RawMessage("4"), responses[2].Result)
+	})
+
+	t.Run("Notifications", func(t *testing.T) {
+		_, blob := post(t, `[
+			{"jsonrpc":"2.0","method":"block","params":{"height":"1"}},
+			{"jsonrpc":"2.0","method":"block","params":{"height":"2"}},
+			{"jsonrpc":"2.0","method":"block","params":{"height":"3"}}
+		]`)
+		assert.Empty(t, blob)
+	})
+
+	t.Run("BelowThreshold", func(t *testing.T) {
+		res, blob := post(t, `[
+			{"jsonrpc":"2.0","method":"block","id":1,"params":{"height":"1"}},
+			{"jsonrpc":"2.0","method":"block","id":2,"params":{"height":"2"}}
+		]`)
+		assert.Empty(t, res.TransferEncoding)
+		assert.EqualValues(t, len(blob), res.ContentLength)
+
+		var responses []rpctypes.RPCResponse
+		require.NoError(t, json.Unmarshal(blob, &responses), "blob: %s", blob)
+		require.Len(t, responses, 2)
+	})
+}
+
+func TestRPCRateLimits(t *testing.T) {
+	funcMap := map[string]*RPCFunc{
+		"status":    NewRPCFunc(func(ctx context.Context) (int, error) { return 0, nil }),
+		"tx_search": NewRPCFunc(func(ctx context.Context, q string) (int, error) { return 1, nil }, "query"),
+	}
+	rl := NewRateLimiter(RateLimit{}, map[string]RateLimit{
+		"tx_search": {Rate: 0.001, Burst: 1, PerIP: true},
+	})
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger(), RateLimits(rl))
+	srv := httptest.NewServer(recoverAndLogHandler(mux, log.NewNopLogger()))
+	defer srv.Close()
+
+	post := func(t *testing.T, payload string) (int, []byte) {
+		t.Helper()
+		res, err := http.Post(srv.URL, "application/json", strings.NewReader(payload))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		blob, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, blob
+	}
+
+	code, _ := post(t, `{"jsonrpc":"2.0","method":"tx_search","id":1,"params":{"query":"a"}}`)
+	require.Equal(t, http.StatusOK, code)
+
+	code, blob := post(t, `{"jsonrpc":"2.0","method":"tx_search","id":2,"params":{"query":"a"}}`)
+	require.Equal(t, http.StatusTooManyRequests, code)
+	var rsp rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(blob, &rsp))
+	assert.Equal(t, rpctypes.JSONRPCIntID(2), rsp.ID)
+	require.NotNil(t, rsp.Error)
+	assert.Equal(t, -32005, rsp.Error.Code)
+
+	// The limit of tx_search does not apply to other methods.
+	code, _ = post(t, `{"jsonrpc":"2.0","method":"status","id":3,"params":{}}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	// In a batch, only the calls over the limit fail.
+	code, blob = post(t, `[
+		{"jsonrpc":"2.0","method":"status","id":4,"params":{}},
+		{"jsonrpc":"2.0","method":"tx_search","id":5,"params":{"query":"a"}}
+	]`)
+	require.Equal(t, http.StatusOK, code)
+	var responses []rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(blob, &responses))
+	require.Len(t, responses, 2)
+	assert.Nil(t, responses[0].Error)
+	require.NotNil(t, responses[1].Error)
+	assert.Equal(t, -32005, responses[1].Error.Code)
+
+	// URI requests are limited too.
+	res, err := http.Get(srv.URL + "/tx_search?query=%22a%22")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+}
+
+func TestRPCCatchingUp(t *testing.T) {
+	funcMap := map[string]*RPCFunc{
+		"broadcast": NewRPCFunc(func(ctx context.Context) (string, error) {
+			return "", &coretypes.CatchingUpError{Height: 10, TargetHeight: 1000}
+		}),
+	}
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger())
+
+	req := httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc": "2.0", "method": "broadcast", "id": 1, "params": {}}`))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	var rsp rpctypes.RPCResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	require.NotNil(t, rsp.Error)
+	assert.Equal(t, -32006, rsp.Error.Code)
+	assert.Equal(t, "Node catching up", rsp.Error.Message)
+	assert.Contains(t, rsp.Error.Data, "990 blocks behind")
+}
+
+func TestStrictJSONRPC(t *testing.T) {
+	funcMap := map[string]*RPCFunc{
+		"c": NewRPCFunc(func(ctx context.Context, s string) (string, error) { return s, nil }, "s"),
+		"p": NewRPCFunc(func(ctx context.Context) (string, error) { panic("boom") }),
+	}
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger(), StrictJSONRPC(true))
+
+	invalid := func(id string) string {
+		return `{"jsonrpc":"2.0","id":` + id + `,"error":{"code":-32600,"message":"Invalid Request"}}`
+	}
+	testCases := []struct {
+		name    string
+		payload string
+		want    string // with the error data removed
+	}{
+		{"string ID", `{"jsonrpc":"2.0","id":"x","method":"c","params":["a"]}`,
+			`{"jsonrpc":"2.0","id":"x","result":"a"}`},
+		{"large ID", `{"jsonrpc":"2.0","id":12345678901234567890,"method":"c","params":["a"]}`,
+			`{"jsonrpc":"2.0","id":12345678901234567890,"result":"a"}`},
+		{"null ID", `{"jsonrpc":"2.0","id":null,"method":"c","params":["a"]}`,
+			`{"jsonrpc":"2.0","id":null,"result":"a"}`},
+		{"notification", `{"jsonrpc":"2.0","method":"c","params":["a"]}`, ``},
+		{"fractional ID", `{"jsonrpc":"2.0","id":1.5,"method":"c","params":["a"]}`, invalid("null")},
+		{"object ID", `{"jsonrpc":"2.0","id":{},"method":"c","params":["a"]}`, invalid("null")},
+		{"missing version", `{"id":1,"method":"c","params":["a"]}`, invalid("1")},
+		{"parse error", `{"jsonrpc":"2.0",`,
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`},
+		{"empty batch", `[]`, invalid("null")},
+		{"batch of one", `[{"jsonrpc":"2.0","id":1,"method":"c","params":["a"]}]`,
+			`[{"jsonrpc":"2.0","id":1,"result":"a"}]`},
+		{"invalid batch", `[1,{"jsonrpc":"2.0","id":2,"method":"c","params":["b"]}]`,
+			`[` + invalid("null") + `,{"jsonrpc":"2.0","id":2,"result":"b"}]`},
+		{"panic", `{"jsonrpc":"2.0","id":1,"method":"p"}`,
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32603,"message":"Internal error"}}`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "http://localhost/", strings.NewReader(tc.payload))
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if tc.want == "" {
+				assert.Empty(t, rec.Body.String())
+				return
+			}
+
+			var got interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got), rec.Body.String())
+			stripErrorData(got)
+			bz, err := json.Marshal(got)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.want, string(bz))
+		})
+	}
+}
+
+// stripErrorData removes the data of the errors of the responses v, decoded
+// into a generic value.
+func stripErrorData(v interface{}) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, rsp := range v {
+			stripErrorData(rsp)
+		}
+	case map[string]interface{}:
+		if e, ok := v["error"].(map[string]interface{}); ok {
+			delete(e, "data")
+		}
+	}
+}
@@ -3,15 +3,19 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"os"
 	"runtime/debug"
 	"strings"
 	"time"
 
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/net/netutil"
 
 	"github.com/tendermint/tendermint/libs/log"
@@ -31,6 +35,20 @@ type Config struct {
 	MaxBodyBytes int64
 	// mirrors http.Server#MaxHeaderBytes
 	MaxHeaderBytes int
+	// HTTP2 enables HTTP/2 over cleartext connections (h2c), both with prior
+	// knowledge and by upgrade, and applies the HTTP/2 settings below to TLS
+	// connections too. Websocket connections are unaffected: they keep using
+	// HTTP/1.1.
+	HTTP2 bool
+	// mirrors http2.Server#MaxConcurrentStreams
+	HTTP2MaxConcurrentStreams uint32
+	// mirrors http2.Server#MaxUploadBufferPerStream
+	HTTP2MaxUploadBufferPerStream int32
+	// mirrors http2.Server#MaxUploadBufferPerConnection
+	HTTP2MaxUploadBufferPerConnection int32
+	// UnixSocketMode sets the permissions of the unix sockets created by
+	// ListenWithConfig. If 0, they are set by the umask of the process.
+	UnixSocketMode os.FileMode
 }
 
 // DefaultConfig returns a default configuration.
@@ -41,7 +59,32 @@ func DefaultConfig() *Config {
 		WriteTimeout:       10 * time.Second,
 		MaxBodyBytes:       int64(1000000), // 1MB
 		MaxHeaderBytes:     1 << 20,        // same as the net/http default
+
+		HTTP2:                             false,
+		HTTP2MaxConcurrentStreams:         250,     // same as the x/net/http2 default
+		HTTP2MaxUploadBufferPerStream:     1 << 20, // same as the x/net/http2 default
+		HTTP2MaxUploadBufferPerConnection: 8 << 20,
+	}
+}
+
+// configureHTTP2 enables HTTP/2 on s according to config: over TLS, where it
+// is negotiated with ALPN, and over cleartext connections.
+func configureHTTP2(s *http.Server, config *Config) error {
+	if !config.HTTP2 {
+		return nil
 	}
+	h2s := &http2.Server{
+		MaxConcurrentStreams:         config.HTTP2MaxConcurrentStreams,
+		MaxUploadBufferPerStream:     config.HTTP2MaxUploadBufferPerStream,
+		MaxUploadBufferPerConnection: config.HTTP2MaxUploadBufferPerConnection,
+	}
+	if err := http2.ConfigureServer(s, h2s); err != nil {
+		return err
+	}
+	// h2c only takes over HTTP/2 connections and h2c upgrades, so websocket
+	// upgrades still reach the handler.
+	s.Handler = h2c.NewHandler(s.Handler, h2s)
+	return nil
 }
 
 // Serve creates a http.Server and calls Serve with the given listener. It
@@ -61,6 +104,9 @@ func Serve(
 		WriteTimeout:   config.WriteTimeout,
 		MaxHeaderBytes: config.MaxHeaderBytes,
 	}
+	if err := configureHTTP2(s, config); err != nil {
+		return err
+	}
 	sig := make(chan struct{})
 	go func() {
 		select {
@@ -93,13 +139,41 @@ func ServeTLS(
 ) error {
 	logger.Info(fmt.Sprintf("Starting RPC HTTPS server on %s (cert: %q, key: %q)",
 		listener.Addr(), certFile, keyFile))
+
+	reloader, err := NewCertificateReloader(certFile, keyFile, logger)
+	if err != nil {
+		return err
+	}
+
+	return ServeWithTLSConfig(ctx, listener, handler, &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}, logger, config)
+}
+
+// ServeWithTLSConfig creates a http.Server and serves TLS connections on
+// the given listener using tlsConfig, which must be able to provide a
+// certificate (for example via GetCertificate). It wraps handler to recover
+// panics and limit the request body size.
+func ServeWithTLSConfig(
+	ctx context.Context,
+	listener net.Listener,
+	handler http.Handler,
+	tlsConfig *tls.Config,
+	logger log.Logger,
+	config *Config,
+) error {
 	h := recoverAndLogHandler(MaxBytesHandler(handler, config.MaxBodyBytes), logger)
 	s := &http.Server{
 		Handler:        h,
+		TLSConfig:      tlsConfig,
 		ReadTimeout:    config.ReadTimeout,
 		WriteTimeout:   config.WriteTimeout,
 		MaxHeaderBytes: config.MaxHeaderBytes,
 	}
+	if err := configureHTTP2(s, config); err != nil {
+		return err
+	}
 	sig := make(chan struct{})
 	go func() {
 		select {
@@ -111,7 +185,7 @@ func ServeTLS(
 		}
 	}()
 
-	if err := s.ServeTLS(listener, certFile, keyFile); err != nil {
+	if err := s.ServeTLS(listener, "", ""); err != nil {
 		logger.Error("RPC HTTPS server stopped", "err", err)
 		close(sig)
 		return err
@@ -173,6 +247,22 @@ func writeRPCResponse(w http.ResponseWriter, log log.Logger, rsps ...rpctypes.RP
 	_, _ = w.Write(body)
 }
 
+// writeRPCBatchResponse writes the responses to a batch to w, as an array even
+// if there is a single response.
+//
+// Unless there is an error encoding the responses, the status is 200 OK.
+func writeRPCBatchResponse(w http.ResponseWriter, log log.Logger, rsps []rpctypes.RPCResponse) {
+	body, err := json.Marshal(rsps)
+	if err != nil {
+		log.Error("Error encoding RPC response: %w", err)
+		writeInternalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
 //-----------------------------------------------------------------------------
 
 // recoverAndLogHandler wraps an HTTP handler, adding error logging.  If the
@@ -244,23 +334,49 @@ func (h maxBytesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	h.handler.ServeHTTP(w, req)
 }
 
+// ShedLoadHandler wraps h in a handler that rejects the requests with 503
+// Service Unavailable while shedding reports true. If shedding is nil, the
+// requests are not rejected.
+func ShedLoadHandler(h http.Handler, shedding func() bool) http.Handler {
+	if shedding == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if shedding() {
+			w.Header().Set("Retry-After", "10")
+			http.Error(w, "node is shedding load", http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, req)
+	})
+}
+
 // newStatusWriter wraps an http.ResponseWriter to capture the HTTP status code
-// in *code.
-func newStatusWriter(w http.ResponseWriter, code *int) statusWriter {
-	return statusWriter{
+// in *code. The wrapper implements http.Hijacker if w does, which is not the
+// case of HTTP/2 response writers.
+func newStatusWriter(w http.ResponseWriter, code *int) http.ResponseWriter {
+	sw := statusWriter{
 		ResponseWriter: w,
-		Hijacker:       w.(http.Hijacker),
 		code:           code,
 	}
+	if hj, ok := w.(http.Hijacker); ok {
+		return hijackStatusWriter{statusWriter: sw, Hijacker: hj}
+	}
+	return sw
 }
 
 type statusWriter struct {
 	http.ResponseWriter
-	http.Hijacker // to support websocket upgrade
 
 	code *int
 }
 
+// hijackStatusWriter is a statusWriter supporting websocket upgrades.
+type hijackStatusWriter struct {
+	statusWriter
+	http.Hijacker
+}
+
 // WriteHeader implements part of http.ResponseWriter. It delegates to the
 // wrapped writer, and as a side effect captures the written code.
 //
@@ -271,9 +387,24 @@ func (w statusWriter) WriteHeader(code int) {
 	w.ResponseWriter.WriteHeader(code)
 }
 
+// Flush implements http.Flusher, if the wrapped writer does, so that handlers
+// can stream their responses.
+func (w statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
 // Listen starts a new net.Listener on the given address.
 // It returns an error if the address is invalid or the call to Listen() fails.
 func Listen(addr string, maxOpenConnections int) (listener net.Listener, err error) {
+	return ListenWithConfig(addr, &Config{MaxOpenConnections: maxOpenConnections})
+}
+
+// ListenWithConfig is like Listen, with the connection limit and the unix
+// socket permissions of config. A unix socket left behind by a process which
+// did not shut down cleanly is replaced.
+func ListenWithConfig(addr string, config *Config) (listener net.Listener, err error) {
 	parts := strings.SplitN(addr, "://", 2)
 	if len(parts) != 2 {
 		return nil, fmt.Errorf(
@@ -282,13 +413,37 @@ func Listen(addr string, maxOpenConnections int) (listener net.Listener, err err
 		)
 	}
 	proto, addr := parts[0], parts[1]
+	if proto == "unix" {
+		removeStaleSocket(addr)
+	}
 	listener, err = net.Listen(proto, addr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen on %v: %v", addr, err)
 	}
-	if maxOpenConnections > 0 {
-		listener = netutil.LimitListener(listener, maxOpenConnections)
+	if proto == "unix" && config.UnixSocketMode != 0 {
+		if err := os.Chmod(addr, config.UnixSocketMode); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to set the permissions of %v: %w", addr, err)
+		}
+	}
+	if config.MaxOpenConnections > 0 {
+		listener = netutil.LimitListener(listener, config.MaxOpenConnections)
 	}
 
 	return listener, nil
 }
+
+// removeStaleSocket removes the unix socket at path if no process listens on
+// it anymore.
+func removeStaleSocket(path string) {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return
+	}
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return
+	}
+	_ = os.Remove(path)
+}
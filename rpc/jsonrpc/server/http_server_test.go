@@ -2,21 +2,32 @@ package server
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/fortytw2/leaktest"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
 
 	"github.com/tendermint/tendermint/libs/log"
 	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
@@ -125,6 +136,213 @@ func TestServeTLS(t *testing.T) {
 	assert.Equal(t, []byte("some body"), body)
 }
 
+// countingListener counts the connections it accepts.
+type countingListener struct {
+	net.Listener
+	accepted int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		atomic.AddInt32(&l.accepted, 1)
+	}
+	return conn, err
+}
+
+func TestServeHTTP2(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger := log.NewNopLogger()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l := &countingListener{Listener: ln}
+	defer l.Close()
+
+	wm := NewWebsocketManager(logger, map[string]*RPCFunc{
+		"c": NewWSRPCFunc(func(ctx context.Context, s string, i int) (string, error) { return "foo", nil }, "s", "i"),
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, r.Proto)
+	})
+
+	config := DefaultConfig()
+	config.HTTP2 = true
+	config.ReadTimeout = 200 * time.Millisecond
+	go Serve(ctx, l, mux, logger, config) //nolint:errcheck // ignore for tests
+
+	// An h2c client with prior knowledge.
+	c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	get := func() (string, error) {
+		res, err := c.Get("http://" + l.Addr().String())
+		if err != nil {
+			return "", err
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		return string(body), err
+	}
+
+	// Concurrent calls are multiplexed over a single connection.
+	const calls = 20
+	var wg sync.WaitGroup
+	protos := make(chan string, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			proto, err := get()
+			assert.NoError(t, err)
+			protos <- proto
+		}()
+	}
+	wg.Wait()
+	close(protos)
+	for proto := range protos {
+		assert.Equal(t, "HTTP/2.0", proto)
+	}
+
+	// A busy connection outlives the read timeout.
+	for start := time.Now(); time.Since(start) < 3*config.ReadTimeout; {
+		proto, err := get()
+		require.NoError(t, err)
+		assert.Equal(t, "HTTP/2.0", proto)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&l.accepted))
+
+	// HTTP/1.1 clients and websockets keep working.
+	res, err := http.Get("http://" + l.Addr().String())
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/1.1", string(body))
+
+	ws, dialResp, err := websocket.DefaultDialer.Dial("ws://"+l.Addr().String()+"/websocket", nil)
+	require.NoError(t, err)
+	defer dialResp.Body.Close()
+	defer ws.Close()
+	req, err := rpctypes.ParamsToRequest(rpctypes.JSONRPCStringID("ws"), "c", map[string]interface{}{"s": "a", "i": 10})
+	require.NoError(t, err)
+	require.NoError(t, ws.WriteJSON(req))
+	var resp rpctypes.RPCResponse
+	require.NoError(t, ws.ReadJSON(&resp))
+	require.Nil(t, resp.Error)
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A socket left behind by a crashed process.
+	path := filepath.Join(t.TempDir(), "rpc.sock")
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	config := DefaultConfig()
+	config.HTTP2 = true
+	config.UnixSocketMode = 0600
+	l, err := ListenWithConfig("unix://"+path, config)
+	require.NoError(t, err)
+	defer l.Close()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// The socket is in use.
+	_, err = ListenWithConfig("unix://"+path, config)
+	require.Error(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	})
+	go Serve(ctx, l, mux, log.NewNopLogger(), config) //nolint:errcheck // ignore for tests
+
+	// An h2c client with prior knowledge, over the socket.
+	c := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(string, string, *tls.Config) (net.Conn, error) {
+			return net.Dial("unix", path)
+		},
+	}}
+	res, err := c.Get("http://unix")
+	require.NoError(t, err)
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "HTTP/2.0", string(body))
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "rpc.crt")
+	keyFile := filepath.Join(dir, "rpc.key")
+
+	writeKeyPair := func(name string, modTime time.Time) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: name},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+		require.NoError(t, err)
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(certFile,
+			pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+		require.NoError(t, os.WriteFile(keyFile,
+			pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+		require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+		require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+	}
+
+	commonName := func(cert *tls.Certificate) string {
+		parsed, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return parsed.Subject.CommonName
+	}
+
+	now := time.Now()
+	writeKeyPair("first", now.Add(-time.Minute))
+
+	reloader, err := NewCertificateReloader(certFile, keyFile, log.NewNopLogger())
+	require.NoError(t, err)
+
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonName(cert))
+
+	writeKeyPair("second", now)
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", commonName(cert))
+
+	// A broken key pair keeps serving the last good certificate.
+	require.NoError(t, os.WriteFile(certFile, []byte("garbage"), 0600))
+	require.NoError(t, os.Chtimes(certFile, now.Add(time.Minute), now.Add(time.Minute)))
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", commonName(cert))
+}
+
 func TestWriteRPCResponse(t *testing.T) {
 	id := rpctypes.JSONRPCIntID(-1)
 
@@ -172,3 +390,20 @@ func TestWriteHTTPResponse(t *testing.T) {
 	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
 	assert.Equal(t, `{"code":-32603,"message":"Internal error","data":"foo"}`, string(body))
 }
+
+func TestShedLoadHandler(t *testing.T) {
+	var shedding int32
+	h := ShedLoadHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), func() bool { return atomic.LoadInt32(&shedding) == 1 })
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	atomic.StoreInt32(&shedding, 1)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
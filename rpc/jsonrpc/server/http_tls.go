@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// CertificateReloader serves a TLS key pair loaded from disk and reloads it
+// whenever either file is modified, so that renewed certificates are picked
+// up without restarting the server.
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	logger   log.Logger
+
+	mtx      sync.Mutex
+	cert     *tls.Certificate
+	certTime time.Time
+	keyTime  time.Time
+}
+
+// NewCertificateReloader loads the key pair from certFile and keyFile and
+// returns a reloader serving it. An error is returned if the initial key
+// pair cannot be loaded.
+func NewCertificateReloader(certFile, keyFile string, logger log.Logger) (*CertificateReloader, error) {
+	cr := &CertificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+	}
+	if err := cr.maybeReload(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+func (cr *CertificateReloader) maybeReload() error {
+	certInfo, err := os.Stat(cr.certFile)
+	if err != nil {
+		return fmt.Errorf("stat certificate %q: %w", cr.certFile, err)
+	}
+	keyInfo, err := os.Stat(cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("stat key %q: %w", cr.keyFile, err)
+	}
+
+	if cr.cert != nil && certInfo.ModTime().Equal(cr.certTime) && keyInfo.ModTime().Equal(cr.keyTime) {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return fmt.Errorf("loading key pair: %w", err)
+	}
+
+	if cr.cert != nil {
+		cr.logger.Info("reloaded RPC TLS certificate", "cert", cr.certFile)
+	}
+
+	cr.cert = &cert
+	cr.certTime = certInfo.ModTime()
+	cr.keyTime = keyInfo.ModTime()
+	return nil
+}
+
+// GetCertificate implements the tls.Config GetCertificate callback. If
+// reloading a modified key pair fails, the previously loaded certificate is
+// served and the error is logged.
+func (cr *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mtx.Lock()
+	defer cr.mtx.Unlock()
+
+	if err := cr.maybeReload(); err != nil {
+		cr.logger.Error("failed to reload RPC TLS certificate; serving previous certificate", "err", err)
+	}
+	return cr.cert, nil
+}
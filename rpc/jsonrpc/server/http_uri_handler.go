@@ -10,6 +10,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/tendermint/tendermint/libs/log"
 	"github.com/tendermint/tendermint/rpc/coretypes"
@@ -19,7 +20,12 @@ import (
 // HTTP + URI handler
 
 // convert from a function name to the http handler
-func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWriter, *http.Request) {
+func makeHTTPHandler(
+	name string,
+	rpcFunc *RPCFunc,
+	logger log.Logger,
+	hopts handlerOptions,
+) func(http.ResponseWriter, *http.Request) {
 	// Always return -1 as there's no ID here.
 	dummyID := rpctypes.JSONRPCIntID(-1) // URIClientRequestID
 
@@ -35,40 +41,65 @@ func makeHTTPHandler(rpcFunc *RPCFunc, logger log.Logger) func(http.ResponseWrit
 
 	// All other endpoints
 	return func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		if err := hopts.rateLimiter.Allow(name, req.RemoteAddr); err != nil {
+			rsp := rpctypes.RPCRateLimitError(dummyID, err)
+			hopts.metrics.observeCall(name, protocolURI, start, len(req.URL.RawQuery), rsp)
+			writeRateLimited(w, logger, rsp.Error)
+			return
+		}
+		if d := rpcFunc.deprecation; d != nil {
+			setDeprecationHeaders(w.Header(), name, d)
+			hopts.metrics.deprecatedCall(name)
+		}
+
 		ctx := rpctypes.WithCallInfo(req.Context(), &rpctypes.CallInfo{
 			HTTPRequest: req,
+			Caller:      rpctypes.GetCaller(req.Context()),
 		})
 		args, err := parseURLParams(ctx, rpcFunc, req)
 		if err != nil {
+			hopts.metrics.observeCall(name, protocolURI, start, len(req.URL.RawQuery),
+				rpctypes.RPCInvalidParamsError(dummyID, err))
 			w.Header().Set("Content-Type", "text/plain")
 			w.WriteHeader(http.StatusBadRequest)
 			fmt.Fprintln(w, err.Error())
 			return
 		}
-		outs := rpcFunc.f.Call(args)
+		result, etag, err := callRPCFunc(ctx, hopts, name, rpcFunc, args)
 
-		logger.Debug("HTTPRestRPC", "method", req.URL.Path, "args", args, "returns", outs)
-		result, err := unreflectResult(outs)
+		logger.Debug("HTTPRestRPC", "method", req.URL.Path, "args", args, "result", result, "err", err)
+		var rsp rpctypes.RPCResponse
 		switch e := err.(type) {
 		// if no error then return a success response
 		case nil:
-			writeHTTPResponse(w, logger, rpctypes.NewRPCSuccessResponse(dummyID, result))
+			if etag != "" && setCacheHeaders(w.Header(), req, hopts.cache, etag) {
+				hopts.metrics.observeCall(name, protocolURI, start, len(req.URL.RawQuery), rpctypes.RPCResponse{})
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			rsp = rpctypes.NewRPCSuccessResponse(dummyID, result)
 
 		// if this already of type RPC error then forward that error.
 		case *rpctypes.RPCError:
-			writeHTTPResponse(w, logger, rpctypes.NewRPCErrorResponse(dummyID, e.Code, e.Message, e.Data))
+			rsp = rpctypes.NewRPCErrorResponse(dummyID, e.Code, e.Message, e.Data)
 
 		default: // we need to unwrap the error and parse it accordingly
 			switch errors.Unwrap(err) {
 			case coretypes.ErrZeroOrNegativeHeight,
 				coretypes.ErrZeroOrNegativePerPage,
+				coretypes.ErrPerPageExceedsMax,
 				coretypes.ErrPageOutOfRange,
 				coretypes.ErrInvalidRequest:
-				writeHTTPResponse(w, logger, rpctypes.RPCInvalidRequestError(dummyID, err))
+				rsp = rpctypes.RPCInvalidRequestError(dummyID, err)
+			case coretypes.ErrCatchingUp:
+				rsp = rpctypes.RPCCatchingUpError(dummyID, err)
 			default: // ctypes.ErrHeightNotAvailable, ctypes.ErrHeightExceedsChainHead:
-				writeHTTPResponse(w, logger, rpctypes.RPCInternalError(dummyID, err))
+				rsp = rpctypes.RPCInternalError(dummyID, err)
 			}
 		}
+		hopts.metrics.observeCall(name, protocolURI, start, len(req.URL.RawQuery), rsp)
+		writeHTTPResponse(w, logger, rsp)
 	}
 }
 
@@ -90,7 +121,7 @@ func parseURLParams(ctx context.Context, rf *RPCFunc, req *http.Request) ([]refl
 
 		text, ok := getArg(name)
 		if !ok {
-			vals[i+1] = reflect.Zero(atype)
+			vals[i+1] = rf.argDefault(i)
 			continue
 		}
 
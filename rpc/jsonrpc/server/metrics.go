@@ -0,0 +1,136 @@
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+const (
+	// MetricsSubsystem is a subsystem shared by all metrics exposed by this
+	// package.
+	MetricsSubsystem = "rpc_server"
+)
+
+// Metrics contains the prometheus metrics exposed by the RPC server.
+type Metrics struct {
+	// Number of calls of deprecated methods, by method.
+	DeprecatedCalls metrics.Counter
+
+	// Number of calls, by method, protocol and JSON-RPC error code, 0 for the
+	// successful calls.
+	Calls metrics.Counter
+	// Time taken by the calls, in seconds, by method and protocol.
+	CallDurationSeconds metrics.Histogram
+	// Size of the parameters of the calls, in bytes, by method and protocol.
+	CallParamsBytes metrics.Histogram
+	// Size of the results of the calls, in bytes, by method and protocol.
+	CallResultBytes metrics.Histogram
+}
+
+// PrometheusMetrics constructs a Metrics instance that collects metrics samples.
+// The resulting metrics will be prefixed with namespace and labeled with the
+// defaultLabelsAndValues. defaultLabelsAndValues must be a list of string pairs
+// where the first of each pair is the label and the second is the value.
+func PrometheusMetrics(namespace string, defaultLabelsAndValues ...string) *Metrics {
+	defaultLabels := []string{}
+	for i := 0; i < len(defaultLabelsAndValues); i += 2 {
+		defaultLabels = append(defaultLabels, defaultLabelsAndValues[i])
+	}
+	return &Metrics{
+		DeprecatedCalls: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "deprecated_calls",
+			Help:      "Number of calls of deprecated RPC methods.",
+		}, append(defaultLabels, "method")).With(defaultLabelsAndValues...),
+		Calls: prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "calls",
+			Help:      "Number of calls of RPC methods, by JSON-RPC error code, 0 for the successful calls.",
+		}, append(defaultLabels, "method", "protocol", "code")).With(defaultLabelsAndValues...),
+		CallDurationSeconds: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "call_duration_seconds",
+			Help:      "Time taken by the calls of RPC methods.",
+			Buckets:   stdprometheus.ExponentialBuckets(0.0005, 4, 8),
+		}, append(defaultLabels, "method", "protocol")).With(defaultLabelsAndValues...),
+		CallParamsBytes: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "call_params_bytes",
+			Help:      "Size of the parameters of the calls of RPC methods.",
+			Buckets:   stdprometheus.ExponentialBuckets(64, 4, 9),
+		}, append(defaultLabels, "method", "protocol")).With(defaultLabelsAndValues...),
+		CallResultBytes: prometheus.NewHistogramFrom(stdprometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: MetricsSubsystem,
+			Name:      "call_result_bytes",
+			Help:      "Size of the results of the calls of RPC methods.",
+			Buckets:   stdprometheus.ExponentialBuckets(64, 4, 9),
+		}, append(defaultLabels, "method", "protocol")).With(defaultLabelsAndValues...),
+	}
+}
+
+// NopMetrics constructs a Metrics instance that discards all samples and is suitable
+// for testing.
+func NopMetrics() *Metrics {
+	return &Metrics{
+		DeprecatedCalls:     discard.NewCounter(),
+		Calls:               discard.NewCounter(),
+		CallDurationSeconds: discard.NewHistogram(),
+		CallParamsBytes:     discard.NewHistogram(),
+		CallResultBytes:     discard.NewHistogram(),
+	}
+}
+
+// deprecatedCall counts a call of the deprecated method. m may be nil.
+func (m *Metrics) deprecatedCall(method string) {
+	if m == nil {
+		return
+	}
+	m.DeprecatedCalls.With("method", method).Add(1)
+}
+
+// The protocols of the calls, and the method label of the calls of unknown
+// methods, which are not labeled with the method to bound the number of
+// series.
+const (
+	protocolJSONRPC   = "jsonrpc"
+	protocolURI       = "uri"
+	protocolWebsocket = "websocket"
+
+	unknownMethod = "unknown"
+)
+
+// observeCall records a call of method made with protocol since start, with
+// the given size of its parameters, which got rsp. m may be nil.
+func (m *Metrics) observeCall(method, protocol string, start time.Time, paramsSize int, rsp rpctypes.RPCResponse) {
+	if m == nil {
+		return
+	}
+	code := 0
+	if rsp.Error != nil {
+		code = rsp.Error.Code
+	}
+	m.Calls.With("method", method, "protocol", protocol, "code", strconv.Itoa(code)).Add(1)
+	m.CallDurationSeconds.With("method", method, "protocol", protocol).Observe(time.Since(start).Seconds())
+	m.CallParamsBytes.With("method", method, "protocol", protocol).Observe(float64(paramsSize))
+	m.CallResultBytes.With("method", method, "protocol", protocol).Observe(float64(len(rsp.Result)))
+}
+
+// methodLabel returns the label of the calls of method in funcMap.
+func methodLabel(funcMap map[string]*RPCFunc, method string) string {
+	if _, ok := funcMap[method]; !ok {
+		return unknownMethod
+	}
+	return method
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tendermint/tendermint/libs/log"
+	"github.com/tendermint/tendermint/rpc/coretypes"
+)
+
+// labeledMetric is a metrics.Counter and metrics.Histogram recording the sum
+// of the samples by label values.
+type labeledMetric struct {
+	mtx    *sync.Mutex
+	sums   map[string]float64
+	labels string
+}
+
+func newLabeledMetric() *labeledMetric {
+	return &labeledMetric{mtx: new(sync.Mutex), sums: make(map[string]float64)}
+}
+
+func (m *labeledMetric) With(labelValues ...string) *labeledMetric {
+	var values []string
+	for i := 1; i < len(labelValues); i += 2 {
+		values = append(values, labelValues[i])
+	}
+	return &labeledMetric{mtx: m.mtx, sums: m.sums, labels: strings.Join(values, ",")}
+}
+
+func (m *labeledMetric) Add(v float64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.sums[m.labels] += v
+}
+
+func (m *labeledMetric) get(labels string) float64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.sums[labels]
+}
+
+type labeledCounter struct{ *labeledMetric }
+
+func (c labeledCounter) With(labelValues ...string) metrics.Counter {
+	return labeledCounter{c.labeledMetric.With(labelValues...)}
+}
+
+type labeledHistogram struct{ *labeledMetric }
+
+func (h labeledHistogram) With(labelValues ...string) metrics.Histogram {
+	return labeledHistogram{h.labeledMetric.With(labelValues...)}
+}
+
+func (h labeledHistogram) Observe(v float64) { h.Add(v) }
+
+func TestCallMetrics(t *testing.T) {
+	echo := func(ctx context.Context, s string) (string, error) {
+		if s == "" {
+			return "", errors.New("empty")
+		}
+		if s == "invalid" {
+			return "", fmt.Errorf("invalid: %w", coretypes.ErrInvalidRequest)
+		}
+		return s, nil
+	}
+	funcMap := map[string]*RPCFunc{"echo": NewRPCFunc(echo, "s")}
+
+	calls, durations := newLabeledMetric(), newLabeledMetric()
+	params, results := newLabeledMetric(), newLabeledMetric()
+	m := NopMetrics()
+	m.Calls = labeledCounter{calls}
+	m.CallDurationSeconds = labeledHistogram{durations}
+	m.CallParamsBytes = labeledHistogram{params}
+	m.CallResultBytes = labeledHistogram{results}
+
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger(), RecordMetrics(m))
+	serve := func(req *http.Request) {
+		mux.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[
+		{"jsonrpc": "2.0", "method": "echo", "id": 1, "params": ["abc"]},
+		{"jsonrpc": "2.0", "method": "echo", "id": 2, "params": [""]},
+		{"jsonrpc": "2.0", "method": "nope", "id": 3, "params": []}
+	]`)))
+	serve(httptest.NewRequest(http.MethodGet, `/echo?s="abcd"`, nil))
+	serve(httptest.NewRequest(http.MethodGet, `/echo?s="invalid"`, nil))
+
+	assert.EqualValues(t, 1, calls.get("echo,jsonrpc,0"))
+	assert.EqualValues(t, 1, calls.get("echo,jsonrpc,-32603"))
+	assert.EqualValues(t, 1, calls.get("unknown,jsonrpc,-32601"))
+	assert.EqualValues(t, 1, calls.get("echo,uri,0"))
+	assert.EqualValues(t, 1, calls.get("echo,uri,-32600"))
+
+	assert.EqualValues(t, len(`["abc"]`)+len(`[""]`), params.get("echo,jsonrpc"))
+	assert.EqualValues(t, len(`s="abcd"`)+len(`s="invalid"`), params.get("echo,uri"))
+	assert.EqualValues(t, len(`"abc"`), results.get("echo,jsonrpc"))
+	assert.EqualValues(t, len(`"abcd"`), results.get("echo,uri"))
+	assert.Positive(t, durations.get("echo,jsonrpc"))
+	assert.Positive(t, durations.get("echo,uri"))
+}
@@ -0,0 +1,391 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// OpenAPI returns an OpenAPI 3 document, in JSON, describing the methods of
+// funcMap, with the given title and version. Each method is described as an
+// HTTP GET path taking its arguments as query parameters, and the JSON-RPC
+// endpoint as a POST to the root path. The schemas of the arguments and
+// results are derived from their Go types, following the rules of
+// encoding/json.
+func OpenAPI(funcMap map[string]*RPCFunc, title, version string) ([]byte, error) {
+	return json.MarshalIndent(newOpenAPIDocument(funcMap, title, version), "", "  ")
+}
+
+// APIInfo sets the title and version of the OpenAPI document served by the
+// handlers (see OpenAPI).
+func APIInfo(title, version string) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.apiTitle = title
+		opts.apiVersion = version
+	}
+}
+
+// makeOpenAPIHandler returns a handler serving the OpenAPI document of
+// funcMap, which is computed on the first request.
+func makeOpenAPIHandler(funcMap map[string]*RPCFunc, logger log.Logger, hopts handlerOptions) http.HandlerFunc {
+	title, version := hopts.apiTitle, hopts.apiVersion
+	if title == "" {
+		title = "JSON-RPC"
+	}
+	if version == "" {
+		version = "unknown"
+	}
+
+	var (
+		once sync.Once
+		doc  []byte
+		err  error
+	)
+	return func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { doc, err = OpenAPI(funcMap, title, version) })
+		if err != nil {
+			logger.Error("failed to generate the OpenAPI document", "err", err)
+			http.Error(w, "failed to generate the OpenAPI document", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(doc) // nolint: errcheck
+	}
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       openAPIInfo            `json:"info"`
+	Paths      map[string]openAPIPath `json:"paths"`
+	Components openAPIComponents      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIPath struct {
+	Get  *openAPIOperation `json:"get,omitempty"`
+	Post *openAPIOperation `json:"post,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Description string                     `json:"description,omitempty"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name   string  `json:"name"`
+	In     string  `json:"in"`
+	Schema *schema `json:"schema"`
+}
+
+type openAPIBody struct {
+	Required bool                    `json:"required,omitempty"`
+	Content  map[string]openAPIMedia `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                  `json:"description"`
+	Content     map[string]openAPIMedia `json:"content,omitempty"`
+}
+
+type openAPIMedia struct {
+	Schema *schema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*schema `json:"schemas"`
+}
+
+// schema is a JSON schema, as used by OpenAPI 3.0.
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+}
+
+func newOpenAPIDocument(funcMap map[string]*RPCFunc, title, version string) *openAPIDocument {
+	g := &schemaGenerator{
+		schemas: make(map[string]*schema),
+		names:   make(map[reflect.Type]string),
+	}
+	g.schemas["RPCError"] = &schema{
+		Type: "object",
+		Properties: map[string]*schema{
+			"code":    {Type: "integer"},
+			"message": {Type: "string"},
+			"data":    {Type: "string"},
+		},
+	}
+
+	names := make([]string, 0, len(funcMap))
+	for name := range funcMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	doc := &openAPIDocument{
+		OpenAPI:    "3.0.3",
+		Info:       openAPIInfo{Title: title, Version: version},
+		Paths:      make(map[string]openAPIPath),
+		Components: openAPIComponents{Schemas: g.schemas},
+	}
+	var wsNames []string
+	for _, name := range names {
+		rf := funcMap[name]
+		if rf.ws {
+			wsNames = append(wsNames, name)
+			continue
+		}
+
+		op := &openAPIOperation{
+			OperationID: name,
+			Responses:   map[string]openAPIResponse{"200": g.response(rf)},
+		}
+		if d := rf.deprecation; d != nil {
+			op.Deprecated = true
+			op.Description = d.Message(name)
+		}
+		for i, argName := range rf.argNames {
+			s := g.schemaOf(rf.args[i+1])
+			if i < len(rf.defaults) && rf.defaults[i].IsValid() {
+				s.Default = rf.defaults[i].Interface()
+			}
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name:   argName,
+				In:     "query",
+				Schema: s,
+			})
+		}
+		doc.Paths["/"+name] = openAPIPath{Get: op}
+	}
+	if len(wsNames) > 0 {
+		doc.Info.Description = "The methods " + strings.Join(wsNames, ", ") +
+			" are only available over the websocket endpoint."
+	}
+
+	doc.Paths["/"] = openAPIPath{Post: &openAPIOperation{
+		OperationID: "jsonrpc",
+		Description: "Calls a method, or a batch of methods given as an array of requests, with JSON-RPC 2.0.",
+		RequestBody: &openAPIBody{
+			Required: true,
+			Content: map[string]openAPIMedia{"application/json": {Schema: &schema{
+				Type: "object",
+				Properties: map[string]*schema{
+					"jsonrpc": {Type: "string", Enum: []string{"2.0"}},
+					"id":      {},
+					"method":  {Type: "string", Enum: names},
+					"params":  {Type: "object"},
+				},
+			}}},
+		},
+		Responses: map[string]openAPIResponse{"200": {
+			Description: "The JSON-RPC response",
+			Content:     map[string]openAPIMedia{"application/json": {Schema: responseSchema(&schema{})}},
+		}},
+	}}
+	return doc
+}
+
+// response returns the description of the response to a call of rf.
+func (g *schemaGenerator) response(rf *RPCFunc) openAPIResponse {
+	result := &schema{}
+	if len(rf.returns) == 2 {
+		result = g.schemaOf(rf.returns[0])
+	}
+	return openAPIResponse{
+		Description: "The JSON-RPC response",
+		Content:     map[string]openAPIMedia{"application/json": {Schema: responseSchema(result)}},
+	}
+}
+
+// responseSchema returns the schema of a JSON-RPC response whose result has
+// the given schema.
+func responseSchema(result *schema) *schema {
+	return &schema{
+		Type: "object",
+		Properties: map[string]*schema{
+			"jsonrpc": {Type: "string", Enum: []string{"2.0"}},
+			"id":      {},
+			"result":  result,
+			"error":   {Ref: "#/components/schemas/RPCError"},
+		},
+	}
+}
+
+// schemaGenerator derives the schemas of Go types. The named struct types are
+// described once, as components referred to by their schemas.
+type schemaGenerator struct {
+	schemas map[string]*schema
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		return marshalerSchema(t)
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Array:
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = g.componentName(t)
+			g.names[t] = name
+			// Register the component before describing the fields, for the
+			// recursive types.
+			s := &schema{}
+			g.schemas[name] = s
+			*s = *g.structSchema(t)
+		}
+		return &schema{Ref: "#/components/schemas/" + name}
+	default:
+		// Interfaces may hold any value.
+		return &schema{}
+	}
+}
+
+// componentName returns a unique name for the named type t, qualified by its
+// package name.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	base := path.Base(t.PkgPath()) + "." + t.Name()
+	name := base
+	for i := 2; g.schemas[name] != nil; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	return name
+}
+
+// structSchema describes the fields of the struct type t, as encoded by
+// encoding/json.
+func (g *schemaGenerator) structSchema(t reflect.Type) *schema {
+	s := &schema{Type: "object", Properties: make(map[string]*schema)}
+	g.addFields(s, t)
+	return s
+}
+
+func (g *schemaGenerator) addFields(s *schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			// The fields of embedded structs are promoted.
+			g.addFields(s, ft)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.schemaOf(f.Type)
+		if opts == "string" && (fs.Type == "integer" || fs.Type == "number" || fs.Type == "boolean") {
+			fs = &schema{Type: "string", Format: fs.Format}
+		}
+		s.Properties[name] = fs
+	}
+}
+
+// marshalerSchema returns the schema of the type t, which implements
+// json.Marshaler, from the encoding of its zero value. Most of these types
+// are encoded as strings, e.g. hex-encoded bytes.
+func marshalerSchema(t reflect.Type) (s *schema) {
+	defer func() {
+		if recover() != nil {
+			s = &schema{}
+		}
+	}()
+
+	bz, err := json.Marshal(reflect.New(t).Interface())
+	if err != nil || len(bz) == 0 {
+		return &schema{}
+	}
+	switch bz[0] {
+	case '"':
+		return &schema{Type: "string"}
+	case '{':
+		return &schema{Type: "object"}
+	case '[':
+		return &schema{Type: "array", Items: &schema{}}
+	case 't', 'f':
+		return &schema{Type: "boolean"}
+	case 'n':
+		return &schema{}
+	default:
+		return &schema{Type: "number"}
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/bytes"
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+type openAPIResult struct {
+	Height   int64          `json:"height,string"`
+	Hash     bytes.HexBytes `json:"hash"`
+	Data     []byte         `json:"data"`
+	Time     time.Time      `json:"time"`
+	Next     *openAPIResult `json:"next,omitempty"`
+	Tags     map[string]int `json:"tags"`
+	Internal string         `json:"-"`
+	openAPIEmbedded
+}
+
+type openAPIEmbedded struct {
+	Count int32
+}
+
+func TestOpenAPI(t *testing.T) {
+	get := func(context.Context, int64, string, bool) (*openAPIResult, error) { return nil, nil }
+	funcMap := map[string]*RPCFunc{
+		"get":       NewRPCFunc(get, "height", "name", "prove"),
+		"old":       NewRPCFunc(get, "height", "name", "prove").Deprecated(rpctypes.Deprecation{Replacement: "get"}),
+		"subscribe": NewWSRPCFunc(func(context.Context) error { return nil }),
+	}
+	mux := http.NewServeMux()
+	RegisterRPCFuncs(mux, funcMap, log.NewNopLogger(), APIInfo("Test API", "1.2.3"))
+
+	// The document is served by its endpoint, and to the empty requests to
+	// the root path.
+	var bodies [][]byte
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/openapi.json", nil),
+		httptest.NewRequest(http.MethodGet, "/", nil),
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		bodies = append(bodies, rec.Body.Bytes())
+	}
+	require.Equal(t, bodies[0], bodies[1])
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title       string `json:"title"`
+			Version     string `json:"version"`
+			Description string `json:"description"`
+		} `json:"info"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(bodies[0], &doc))
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+	assert.Equal(t, "Test API", doc.Info.Title)
+	assert.Equal(t, "1.2.3", doc.Info.Version)
+	assert.Contains(t, doc.Info.Description, "subscribe")
+
+	// The websocket methods have no HTTP path.
+	assert.Len(t, doc.Paths, 3)
+	assert.Contains(t, doc.Paths["/"], "post")
+	assert.NotContains(t, doc.Paths, "/subscribe")
+
+	assert.JSONEq(t, `{
+		"operationId": "get",
+		"parameters": [
+			{"name": "height", "in": "query", "schema": {"type": "integer", "format": "int64"}},
+			{"name": "name", "in": "query", "schema": {"type": "string"}},
+			{"name": "prove", "in": "query", "schema": {"type": "boolean"}}
+		],
+		"responses": {"200": {
+			"description": "The JSON-RPC response",
+			"content": {"application/json": {"schema": {
+				"type": "object",
+				"properties": {
+					"jsonrpc": {"type": "string", "enum": ["2.0"]},
+					"id": {},
+					"result": {"$ref": "#/components/schemas/server.openAPIResult"},
+					"error": {"$ref": "#/components/schemas/RPCError"}
+				}
+			}}}
+		}}
+	}`, string(doc.Paths["/get"]["get"]))
+
+	var old struct {
+		Deprecated  bool   `json:"deprecated"`
+		Description string `json:"description"`
+	}
+	require.NoError(t, json.Unmarshal(doc.Paths["/old"]["get"], &old))
+	assert.True(t, old.Deprecated)
+	assert.Contains(t, old.Description, "method old is deprecated")
+
+	// The schemas follow the JSON encoding of the types.
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"height": {"type": "string", "format": "int64"},
+			"hash": {"type": "string"},
+			"data": {"type": "string", "format": "byte"},
+			"time": {"type": "string", "format": "date-time"},
+			"next": {"$ref": "#/components/schemas/server.openAPIResult"},
+			"tags": {"type": "object", "additionalProperties": {"type": "integer", "format": "int64"}},
+			"Count": {"type": "integer", "format": "int32"}
+		}
+	}`, string(doc.Components.Schemas["server.openAPIResult"]))
+}
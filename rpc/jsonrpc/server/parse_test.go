@@ -218,3 +218,77 @@ func TestParseURI(t *testing.T) {
 
 	}
 }
+
+func TestParseJSONRPCDefaults(t *testing.T) {
+	demo := func(ctx context.Context, query string, page *int, perPage *int, orderBy string, opts map[string]string) error {
+		return nil
+	}
+	call := NewRPCFunc(demo, "query", "page", "per_page", "order_by", "opts").
+		Default("per_page", 30).
+		Default("order_by", "asc")
+
+	cases := []struct {
+		raw     string
+		query   string
+		page    *int
+		perPage int
+		orderBy string
+		opts    map[string]string
+		fail    bool
+	}{
+		// missing parameters get their defaults, or zero values
+		{raw: `null`, perPage: 30, orderBy: "asc"},
+		{raw: `{"query": "a"}`, query: "a", perPage: 30, orderBy: "asc"},
+		{raw: `["a"]`, query: "a", perPage: 30, orderBy: "asc"},
+		{raw: `["a", 2, 10, "desc"]`, query: "a", page: intPtr(2), perPage: 10, orderBy: "desc"},
+		// positional parameters followed by named ones
+		{raw: `["a", {"order_by": "desc"}]`, query: "a", perPage: 30, orderBy: "desc"},
+		{raw: `["a", 2, {"per_page": 5, "unused": 1}]`, query: "a", page: intPtr(2), perPage: 5, orderBy: "asc"},
+		// an object at the position of an object parameter is positional
+		{raw: `["a", 2, 10, "desc", {"k": "v"}]`, query: "a", page: intPtr(2), perPage: 10, orderBy: "desc",
+			opts: map[string]string{"k": "v"}},
+		// should fail
+		{raw: `["a", {"query": "b"}]`, fail: true},
+		{raw: `["a", 2, 10, "desc", {}, "extra"]`, fail: true},
+	}
+	ctx := context.Background()
+	for idx, tc := range cases {
+		i := strconv.Itoa(idx)
+		vals, err := parseParams(ctx, call, []byte(tc.raw))
+		if tc.fail {
+			assert.Error(t, err, i)
+			continue
+		}
+		if !assert.NoError(t, err, "%s: %+v", i, err) || !assert.Len(t, vals, 6, i) {
+			continue
+		}
+		assert.Equal(t, tc.query, vals[1].String(), i)
+		assert.Equal(t, tc.page, vals[2].Interface(), i)
+		assert.Equal(t, tc.perPage, *vals[3].Interface().(*int), i)
+		assert.Equal(t, tc.orderBy, vals[4].String(), i)
+		assert.Equal(t, tc.opts, vals[5].Interface(), i)
+	}
+
+	// Each call gets its own copy of a pointer default.
+	vals, err := parseParams(ctx, call, []byte(`null`))
+	assert.NoError(t, err)
+	*vals[3].Interface().(*int) = 100
+	vals, err = parseParams(ctx, call, []byte(`null`))
+	assert.NoError(t, err)
+	assert.Equal(t, 30, *vals[3].Interface().(*int))
+
+	// The URI parameters get the defaults too.
+	req, err := http.NewRequest("GET", `test.com/method?query="a"`, nil)
+	assert.NoError(t, err)
+	vals, err = parseURLParams(ctx, call, req)
+	assert.NoError(t, err)
+	assert.Equal(t, 30, *vals[3].Interface().(*int))
+	assert.Equal(t, "asc", vals[4].String())
+
+	// The defaults must match the parameters.
+	assert.Panics(t, func() { call.Default("unknown", 1) })
+	assert.Panics(t, func() { call.Default("order_by", 1) })
+	assert.Panics(t, func() { call.Default("per_page", "30") })
+}
+
+func intPtr(i int) *int { return &i }
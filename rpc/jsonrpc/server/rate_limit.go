@@ -0,0 +1,168 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/tendermint/tendermint/libs/log"
+)
+
+// ErrRateLimited is returned, wrapped, by RateLimiter.Allow for a request
+// that exceeds a rate limit.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// rateLimitSweepInterval is how often the buckets of the remote addresses that
+// are back to a full burst are dropped.
+const rateLimitSweepInterval = time.Minute
+
+// RateLimit is a token bucket limit: requests are accepted at Rate requests
+// per second on average, with bursts of up to Burst requests. A zero Rate
+// means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+
+	// PerIP makes the limit apply to each remote IP address separately,
+	// rather than to all the clients together.
+	PerIP bool
+}
+
+// RateLimiter enforces rate limits on the calls of RPC methods: a global limit
+// on all calls, and a limit per method. A call must be within both to be
+// accepted. It is safe for concurrent use.
+type RateLimiter struct {
+	mtx     sync.Mutex
+	global  *limiter
+	methods map[string]*limiter
+}
+
+// NewRateLimiter returns a RateLimiter enforcing the global limit on all
+// calls, and the limits of methods on the calls of each method.
+func NewRateLimiter(global RateLimit, methods map[string]RateLimit) *RateLimiter {
+	rl := &RateLimiter{
+		global:  newLimiter(global),
+		methods: make(map[string]*limiter, len(methods)),
+	}
+	for method, limit := range methods {
+		if l := newLimiter(limit); l != nil {
+			rl.methods[method] = l
+		}
+	}
+	return rl
+}
+
+// Allow reports whether a call of method from the client at remoteAddr, a
+// "host:port" address as in http.Request, is within the rate limits, and
+// counts it if so. It returns an error wrapping ErrRateLimited otherwise.
+func (rl *RateLimiter) Allow(method, remoteAddr string) error {
+	if rl == nil {
+		return nil
+	}
+	ip := remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		ip = host
+	}
+
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	now := time.Now()
+	var buckets []*bucket
+	if rl.global != nil {
+		b := rl.global.bucket(ip, now)
+		if b.tokens < 1 {
+			return fmt.Errorf("%w for all methods", ErrRateLimited)
+		}
+		buckets = append(buckets, b)
+	}
+	if l, ok := rl.methods[method]; ok {
+		b := l.bucket(ip, now)
+		if b.tokens < 1 {
+			return fmt.Errorf("%w for method %s", ErrRateLimited, method)
+		}
+		buckets = append(buckets, b)
+	}
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return nil
+}
+
+// limiter holds the token buckets of a rate limit: a single bucket, or one per
+// remote IP address.
+type limiter struct {
+	limit     RateLimit
+	shared    bucket
+	perIP     map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	l := &limiter{limit: limit, perIP: make(map[string]*bucket)}
+	l.shared.tokens = float64(limit.Burst)
+	return l
+}
+
+// bucket returns the bucket of ip, refilled up to now.
+func (l *limiter) bucket(ip string, now time.Time) *bucket {
+	if !l.limit.PerIP {
+		l.refill(&l.shared, now)
+		return &l.shared
+	}
+
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		for addr, b := range l.perIP {
+			if l.refill(b, now); b.tokens >= float64(l.limit.Burst) {
+				delete(l.perIP, addr)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.perIP[ip]
+	if !ok {
+		b = &bucket{tokens: float64(l.limit.Burst), last: now}
+		l.perIP[ip] = b
+	}
+	l.refill(b, now)
+	return b
+}
+
+func (l *limiter) refill(b *bucket, now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * l.limit.Rate
+		if burst := float64(l.limit.Burst); b.tokens > burst {
+			b.tokens = burst
+		}
+	}
+	b.last = now
+}
+
+// writeRateLimited writes the JSON encoding of body, the response to a request
+// rejected by a rate limit, with status 429 Too Many Requests.
+func writeRateLimited(w http.ResponseWriter, logger log.Logger, body interface{}) {
+	bz, err := json.Marshal(body)
+	if err != nil {
+		logger.Error("Error encoding RPC response", "err", err)
+		writeInternalError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusTooManyRequests)
+	_, _ = w.Write(bz)
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		var nilLimiter *RateLimiter
+		assert.NoError(t, nilLimiter.Allow("status", "1.2.3.4:5"))
+
+		rl := NewRateLimiter(RateLimit{}, map[string]RateLimit{"status": {}})
+		for i := 0; i < 100; i++ {
+			require.NoError(t, rl.Allow("status", "1.2.3.4:5"))
+		}
+	})
+
+	t.Run("PerIP", func(t *testing.T) {
+		rl := NewRateLimiter(RateLimit{Rate: 0.001, Burst: 2, PerIP: true}, nil)
+		require.NoError(t, rl.Allow("status", "1.2.3.4:5"))
+		// the port is ignored
+		require.NoError(t, rl.Allow("block", "1.2.3.4:6"))
+		err := rl.Allow("status", "1.2.3.4:5")
+		assert.True(t, errors.Is(err, ErrRateLimited), err)
+
+		// other addresses have their own bucket
+		require.NoError(t, rl.Allow("status", "5.6.7.8:5"))
+	})
+
+	t.Run("Shared", func(t *testing.T) {
+		rl := NewRateLimiter(RateLimit{Rate: 0.001, Burst: 1}, nil)
+		require.NoError(t, rl.Allow("status", "1.2.3.4:5"))
+		assert.True(t, errors.Is(rl.Allow("status", "5.6.7.8:5"), ErrRateLimited))
+	})
+
+	t.Run("Method", func(t *testing.T) {
+		rl := NewRateLimiter(RateLimit{Rate: 0.001, Burst: 3, PerIP: true}, map[string]RateLimit{
+			"tx_search": {Rate: 0.001, Burst: 1, PerIP: true},
+		})
+		require.NoError(t, rl.Allow("tx_search", "1.2.3.4:5"))
+		err := rl.Allow("tx_search", "1.2.3.4:5")
+		require.True(t, errors.Is(err, ErrRateLimited), err)
+		assert.Contains(t, err.Error(), "tx_search")
+
+		// the rejected call did not count against the global limit
+		require.NoError(t, rl.Allow("status", "1.2.3.4:5"))
+		require.NoError(t, rl.Allow("status", "1.2.3.4:5"))
+		assert.True(t, errors.Is(rl.Allow("status", "1.2.3.4:5"), ErrRateLimited))
+	})
+
+	t.Run("Refill", func(t *testing.T) {
+		rl := NewRateLimiter(RateLimit{Rate: 100, Burst: 1, PerIP: true}, nil)
+		require.NoError(t, rl.Allow("status", "1.2.3.4:5"))
+		assert.True(t, errors.Is(rl.Allow("status", "1.2.3.4:5"), ErrRateLimited))
+		time.Sleep(50 * time.Millisecond)
+		assert.NoError(t, rl.Allow("status", "1.2.3.4:5"))
+	})
+}
@@ -8,31 +8,128 @@ import (
 	"reflect"
 
 	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
 )
 
 // RegisterRPCFuncs adds a route for each function in the funcMap, as well as
 // general jsonrpc and websocket handlers for all functions. "result" is the
 // interface on which the result objects are registered, and is popualted with
 // every RPCResponse
-func RegisterRPCFuncs(mux *http.ServeMux, funcMap map[string]*RPCFunc, logger log.Logger) {
+func RegisterRPCFuncs(mux *http.ServeMux, funcMap map[string]*RPCFunc, logger log.Logger, opts ...HandlerOption) {
+	var hopts handlerOptions
+	for _, opt := range opts {
+		opt(&hopts)
+	}
+
 	// HTTP endpoints
 	for funcName, rpcFunc := range funcMap {
-		mux.HandleFunc("/"+funcName, makeHTTPHandler(rpcFunc, logger))
+		mux.HandleFunc("/"+funcName, makeHTTPHandler(funcName, rpcFunc, logger, hopts))
 	}
 
 	// JSONRPC endpoints
-	mux.HandleFunc("/", handleInvalidJSONRPCPaths(makeJSONRPCHandler(funcMap, logger)))
+	mux.HandleFunc("/", handleInvalidJSONRPCPaths(makeJSONRPCHandler(funcMap, logger, hopts)))
+
+	// API description
+	mux.HandleFunc("/openapi.json", makeOpenAPIHandler(funcMap, logger, hopts))
+}
+
+// HandlerOption sets an optional parameter of the handlers registered by
+// RegisterRPCFuncs.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	streamBatchThreshold int
+	rateLimiter          *RateLimiter
+	metrics              *Metrics
+	cache                *ResponseCache
+	apiTitle             string
+	apiVersion           string
+	strict               bool
+}
+
+// StreamBatches makes the JSON-RPC handler stream the responses to batches of
+// at least threshold requests, writing each response as soon as it is
+// computed instead of buffering the whole batch response. A threshold of 0
+// disables streaming, which is the default.
+func StreamBatches(threshold int) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.streamBatchThreshold = threshold
+	}
+}
+
+// RateLimits makes the handlers reject the calls that exceed the rate limits
+// of rl, with status 429 Too Many Requests. Each request of a JSON-RPC batch
+// counts as a call, and the requests of a batch over the limits get an error
+// response in the batch. By default, calls are not rate limited.
+func RateLimits(rl *RateLimiter) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.rateLimiter = rl
+	}
+}
+
+// RecordMetrics makes the handlers record their metrics in m, e.g. the calls
+// of deprecated methods. By default, no metrics are recorded.
+func RecordMetrics(m *Metrics) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.metrics = m
+	}
+}
+
+// CacheResponses makes the HTTP handlers serve the immutable results of the
+// cacheable methods (see RPCFunc.Cacheable) from c, and set the ETag and
+// Cache-Control headers of their URI responses. By default, nothing is
+// cached.
+func CacheResponses(c *ResponseCache) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.cache = c
+	}
+}
+
+// StrictJSONRPC makes the JSON-RPC handler enforce the JSON-RPC 2.0
+// specification, as ParseStrictRequest does: invalid requests get an Invalid
+// Request error, the IDs are echoed as sent, every response has an ID, which
+// is null if the ID of the request could not be detected, and the responses
+// to a batch are always an array. By default, the handler is lenient.
+func StrictJSONRPC(strict bool) HandlerOption {
+	return func(opts *handlerOptions) {
+		opts.strict = strict
+	}
+}
+
+// response returns rsp as sent by the JSON-RPC handler.
+func (opts handlerOptions) response(rsp rpctypes.RPCResponse) rpctypes.RPCResponse {
+	if opts.strict {
+		return strictResponse(rsp)
+	}
+	return rsp
+}
+
+// strictResponse returns rsp as sent in strict mode: with an ID, null if the
+// ID of the request could not be detected, and without the deprecation of the
+// method, which is not part of the specification (the deprecation headers of
+// HTTP responses are still set).
+func strictResponse(rsp rpctypes.RPCResponse) rpctypes.RPCResponse {
+	if rsp.ID == nil {
+		rsp.ID = rpctypes.JSONRPCNullID{}
+	}
+	rsp.Deprecation = nil
+	return rsp
 }
 
 // Function introspection
 
 // RPCFunc contains the introspected type information for a function.
 type RPCFunc struct {
-	f        reflect.Value  // underlying rpc function
-	args     []reflect.Type // type of each function arg
-	returns  []reflect.Type // type of each return arg
-	argNames []string       // name of each argument
-	ws       bool           // websocket only
+	f        reflect.Value   // underlying rpc function
+	args     []reflect.Type  // type of each function arg
+	returns  []reflect.Type  // type of each return arg
+	argNames []string        // name of each argument
+	defaults []reflect.Value // default value of each argument, if any
+	ws       bool            // websocket only
+
+	deprecation  *rpctypes.Deprecation // set if the method is deprecated
+	cacheable    bool                  // immutable results may be cached
+	subscription bool                  // opens subscriptions to events
 }
 
 // NewRPCFunc constructs an RPCFunc for f, which must be a function whose type
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxCloseReasonLength is the maximum length of the reason of a close frame,
+// whose payload is limited to 125 bytes including the close code.
+const maxCloseReasonLength = 123
+
+// Subscription marks the method of f as opening subscriptions to events, which
+// are rejected by the websocket connections being drained (see
+// WebsocketManager.Drain). It returns f, to be used in route maps:
+//
+//	"subscribe": rpc.NewWSRPCFunc(env.Subscribe, "query", "after").Subscription(),
+func (f *RPCFunc) Subscription() *RPCFunc {
+	f.subscription = true
+	return f
+}
+
+// SetMaxConnections limits the number of concurrent websocket connections to
+// n: further connection requests are rejected with status 503 Service
+// Unavailable. 0, the default, means no limit. It should only be called
+// before the manager serves any connection.
+func (wm *WebsocketManager) SetMaxConnections(n int) {
+	wm.mtx.Lock()
+	defer wm.mtx.Unlock()
+	wm.maxConns = n
+}
+
+// Drain gracefully closes the websocket connections: new connections and new
+// subscriptions are rejected, the subscriptions of the connections are
+// removed, and once the events already published to them have been written,
+// the connections are closed with a close frame carrying reason. If ctx ends
+// before, the remaining connections are closed abruptly and Drain returns an
+// error.
+func (wm *WebsocketManager) Drain(ctx context.Context, reason string) error {
+	if len(reason) > maxCloseReasonLength {
+		reason = reason[:maxCloseReasonLength]
+	}
+
+	wm.mtx.Lock()
+	wm.draining = true
+	wm.drainReason = reason
+	conns := make([]*wsConnection, 0, len(wm.conns))
+	for conn := range wm.conns {
+		conns = append(conns, conn)
+	}
+	wm.mtx.Unlock()
+
+	wm.logger.Info("Draining websocket connections", "conns", len(conns), "reason", reason)
+	for _, conn := range conns {
+		conn.drain(reason)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wm.connsWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	wm.mtx.Lock()
+	remaining := len(wm.conns)
+	for conn := range wm.conns {
+		if err := conn.baseConn.Close(); err != nil {
+			wm.logger.Error("Failed to close connection", "remote", conn.remoteAddr, "err", err)
+		}
+	}
+	wm.mtx.Unlock()
+	return fmt.Errorf("closed %d websocket connections before they were drained: %w", remaining, ctx.Err())
+}
+
+// acquire reserves a connection slot, unless the manager is draining or the
+// maximum number of connections is reached. The slot must be released with
+// release.
+func (wm *WebsocketManager) acquire() error {
+	wm.mtx.Lock()
+	defer wm.mtx.Unlock()
+	switch {
+	case wm.draining:
+		return fmt.Errorf("the server is not accepting connections: %s", wm.drainReason)
+	case wm.maxConns > 0 && wm.numConns >= wm.maxConns:
+		return fmt.Errorf("the maximum of %d websocket connections is reached", wm.maxConns)
+	}
+	wm.numConns++
+	wm.connsWG.Add(1)
+	return nil
+}
+
+// register registers the connection of an acquired slot, draining it at once
+// if the manager started draining in the meantime.
+func (wm *WebsocketManager) register(conn *wsConnection) {
+	wm.mtx.Lock()
+	if wm.conns == nil {
+		wm.conns = make(map[*wsConnection]struct{})
+	}
+	wm.conns[conn] = struct{}{}
+	draining, reason := wm.draining, wm.drainReason
+	wm.mtx.Unlock()
+
+	if draining {
+		conn.drain(reason)
+	}
+}
+
+// release releases the slot of a connection, registered or not.
+func (wm *WebsocketManager) release(conn *wsConnection) {
+	wm.mtx.Lock()
+	defer wm.mtx.Unlock()
+	if conn != nil {
+		delete(wm.conns, conn)
+	}
+	wm.numConns--
+	wm.connsWG.Done()
+}
+
+// TrackDelivery implements rpctypes.DeliveryTracker: a connection being
+// drained is closed once the deliveries in progress are done.
+func (wsc *wsConnection) TrackDelivery() func() {
+	wsc.drainMtx.Lock()
+	wsc.deliveries++
+	wsc.drainMtx.Unlock()
+
+	done := false
+	return func() {
+		wsc.drainMtx.Lock()
+		defer wsc.drainMtx.Unlock()
+		if !done {
+			done = true
+			wsc.deliveries--
+			wsc.checkDrained()
+		}
+	}
+}
+
+// drain makes the connection reject new subscriptions and removes its
+// subscriptions. The write routine then writes the responses of the event
+// deliveries in progress, followed by a close frame carrying reason.
+func (wsc *wsConnection) drain(reason string) {
+	wsc.drainMtx.Lock()
+	if wsc.draining {
+		wsc.drainMtx.Unlock()
+		return
+	}
+	wsc.draining = true
+	wsc.drainReason = reason
+	wsc.drainMtx.Unlock()
+
+	// The events already published to the subscriptions are still delivered.
+	if wsc.onDisconnect != nil {
+		wsc.onDisconnect(wsc.remoteAddr)
+	}
+
+	wsc.drainMtx.Lock()
+	defer wsc.drainMtx.Unlock()
+	wsc.checkDrained()
+}
+
+// isDraining reports whether the connection is being drained, and why.
+func (wsc *wsConnection) isDraining() (bool, string) {
+	wsc.drainMtx.Lock()
+	defer wsc.drainMtx.Unlock()
+	return wsc.draining, wsc.drainReason
+}
+
+// checkDrained closes the drained channel if the connection is being drained
+// and no delivery is in progress. The caller must hold drainMtx.
+func (wsc *wsConnection) checkDrained() {
+	if !wsc.draining || wsc.deliveries > 0 {
+		return
+	}
+	select {
+	case <-wsc.drained:
+	default:
+		close(wsc.drained)
+	}
+}
+
+// writeClose writes the responses still queued, and then a close frame
+// carrying the reason of the drain.
+func (wsc *wsConnection) writeClose() {
+	for flushed := false; !flushed; {
+		select {
+		case msg := <-wsc.writeChan:
+			if err := wsc.writeResponse(msg); err != nil {
+				return
+			}
+		default:
+			flushed = true
+		}
+	}
+
+	_, reason := wsc.isDraining()
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, reason)
+	if err := wsc.baseConn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(wsc.writeWait)); err != nil {
+		wsc.Logger.Error("Failed to write close frame", "err", err)
+	}
+}
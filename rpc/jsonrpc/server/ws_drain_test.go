@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/libs/log"
+	rpctypes "github.com/tendermint/tendermint/rpc/jsonrpc/types"
+)
+
+func TestWebsocketManagerDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A subscription delivers one event once it is released.
+	release := make(chan struct{})
+	subscribe := func(ctx context.Context) (string, error) {
+		callInfo := rpctypes.GetCallInfo(ctx)
+		delivered := rpctypes.TrackDelivery(callInfo.WSConn)
+		go func() {
+			defer delivered()
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return
+			}
+			_ = callInfo.WSConn.WriteRPCResponse(ctx,
+				rpctypes.NewRPCSuccessResponse(rpctypes.JSONRPCStringID("event"), "event"))
+		}()
+		return "subscribed", nil
+	}
+	funcMap := map[string]*RPCFunc{
+		"subscribe": NewWSRPCFunc(subscribe).Subscription(),
+	}
+	unsubscribed := make(chan string, 1)
+	wm := NewWebsocketManager(log.NewNopLogger(), funcMap, OnDisconnect(func(remoteAddr string) {
+		select {
+		case unsubscribed <- remoteAddr:
+		default:
+		}
+	}))
+	wm.SetMaxConnections(1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	url := "ws://" + srv.Listener.Addr().String() + "/websocket"
+	dial := func() (*websocket.Conn, int, error) {
+		c, rsp, err := websocket.DefaultDialer.Dial(url, nil)
+		if rsp == nil {
+			return c, 0, err
+		}
+		defer rsp.Body.Close()
+		return c, rsp.StatusCode, err
+	}
+	call := func(c *websocket.Conn, id string) rpctypes.RPCResponse {
+		require.NoError(t, c.WriteJSON(rpctypes.RPCRequest{
+			ID:     rpctypes.JSONRPCStringID(id),
+			Method: "subscribe",
+		}))
+		var rsp rpctypes.RPCResponse
+		require.NoError(t, c.ReadJSON(&rsp))
+		return rsp
+	}
+
+	c, status, err := dial()
+	require.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, http.StatusSwitchingProtocols, status)
+	rsp := call(c, "1")
+	require.Nil(t, rsp.Error)
+
+	// The connections beyond the limit are rejected.
+	_, status, err = dial()
+	assert.Equal(t, websocket.ErrBadHandshake, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+
+	// The drain removes the subscriptions, and waits for the delivery in
+	// progress.
+	drained := make(chan error, 1)
+	go func() {
+		dctx, dcancel := context.WithTimeout(ctx, 10*time.Second)
+		defer dcancel()
+		drained <- wm.Drain(dctx, "shutting down")
+	}()
+	select {
+	case <-unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the subscriptions were not removed")
+	}
+	rsp = call(c, "2")
+	require.NotNil(t, rsp.Error)
+	assert.Contains(t, rsp.Error.Data, "not accepting subscriptions: shutting down")
+	select {
+	case err := <-drained:
+		t.Fatalf("drained before the delivery: %v", err)
+	default:
+	}
+
+	close(release)
+	rsp = rpctypes.RPCResponse{}
+	require.NoError(t, c.ReadJSON(&rsp))
+	assert.Equal(t, rpctypes.JSONRPCStringID("event"), rsp.ID)
+	_, _, err = c.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+	assert.Contains(t, err.Error(), "shutting down")
+	require.NoError(t, <-drained)
+
+	// New connections are rejected.
+	_, status, err = dial()
+	assert.Equal(t, websocket.ErrBadHandshake, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
+
+func TestWebsocketManagerDrainTimeout(t *testing.T) {
+	// The delivery of a subscription never ends.
+	subscribe := func(ctx context.Context) (string, error) {
+		rpctypes.TrackDelivery(rpctypes.GetCallInfo(ctx).WSConn)
+		return "subscribed", nil
+	}
+	funcMap := map[string]*RPCFunc{
+		"subscribe": NewWSRPCFunc(subscribe).Subscription(),
+	}
+	wm := NewWebsocketManager(log.NewNopLogger(), funcMap)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/websocket", wm.WebsocketHandler)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c, rsp, err := websocket.DefaultDialer.Dial("ws://"+srv.Listener.Addr().String()+"/websocket", nil)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	defer c.Close()
+	require.NoError(t, c.WriteJSON(rpctypes.RPCRequest{ID: rpctypes.JSONRPCIntID(1), Method: "subscribe"}))
+	var res rpctypes.RPCResponse
+	require.NoError(t, c.ReadJSON(&res))
+	require.Nil(t, res.Error)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = wm.Drain(ctx, "shutting down")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The connection is dropped without a close frame.
+	_, _, err = c.ReadMessage()
+	require.Error(t, err)
+	assert.False(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+}
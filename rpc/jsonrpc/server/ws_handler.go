@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"runtime/debug"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -35,6 +37,14 @@ type WebsocketManager struct {
 	funcMap       map[string]*RPCFunc
 	logger        log.Logger
 	wsConnOptions []func(*wsConnection)
+
+	mtx         sync.Mutex
+	conns       map[*wsConnection]struct{}
+	numConns    int // including the connections being upgraded
+	maxConns    int // 0 means no limit
+	draining    bool
+	drainReason string
+	connsWG     sync.WaitGroup
 }
 
 // NewWebsocketManager returns a new WebsocketManager that passes a map of
@@ -68,6 +78,14 @@ func NewWebsocketManager(
 // WebsocketHandler upgrades the request/response (via http.Hijack) and starts
 // the wsConnection.
 func (wm *WebsocketManager) WebsocketHandler(w http.ResponseWriter, r *http.Request) {
+	if err := wm.acquire(); err != nil {
+		wm.logger.Info("Rejected websocket connection", "remote", r.RemoteAddr, "err", err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	var conn *wsConnection
+	defer func() { wm.release(conn) }()
+
 	wsConn, err := wm.Upgrade(w, r, nil)
 	if err != nil {
 		// The upgrader has already reported an HTTP error to the client, so we
@@ -83,7 +101,9 @@ func (wm *WebsocketManager) WebsocketHandler(w http.ResponseWriter, r *http.Requ
 
 	// register connection
 	logger := wm.logger.With("remote", wsConn.RemoteAddr())
-	conn := newWSConnection(wsConn, wm.funcMap, logger, wm.wsConnOptions...)
+	conn = newWSConnection(wsConn, wm.funcMap, logger, wm.wsConnOptions...)
+	conn.caller = rpctypes.GetCaller(r.Context())
+	wm.register(conn)
 	wm.logger.Info("New websocket connection", "remote", conn.remoteAddr)
 
 	// starting the conn is blocking
@@ -136,6 +156,25 @@ type wsConnection struct {
 	// callback which is called upon disconnect
 	onDisconnect func(remoteAddr string)
 
+	// caller attached to the upgrade request by middleware
+	caller rpctypes.Caller
+
+	// rate limits of the calls, if any
+	rateLimiter *RateLimiter
+
+	// metrics of the calls, if any
+	metrics *Metrics
+
+	// whether to enforce the JSON-RPC 2.0 specification
+	strict bool
+
+	// draining state, see drain
+	drainMtx    sync.Mutex
+	draining    bool
+	drainReason string
+	deliveries  int           // event deliveries in progress
+	drained     chan struct{} // closed once draining with no delivery in progress
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -162,6 +201,7 @@ func newWSConnection(
 		readWait:          defaultWSReadWait,
 		pingPeriod:        defaultWSPingPeriod,
 		readRoutineQuit:   make(chan struct{}),
+		drained:           make(chan struct{}),
 	}
 	for _, option := range options {
 		option(wsc)
@@ -218,6 +258,31 @@ func ReadLimit(readLimit int64) func(*wsConnection) {
 	}
 }
 
+// WSRateLimits makes the connection reject the calls that exceed the rate
+// limits of rl with an error response. By default, calls are not rate limited.
+func WSRateLimits(rl *RateLimiter) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		wsc.rateLimiter = rl
+	}
+}
+
+// WSRecordMetrics makes the connection record the metrics of its calls in m.
+// By default, no metrics are recorded.
+func WSRecordMetrics(m *Metrics) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		wsc.metrics = m
+	}
+}
+
+// WSStrictJSONRPC makes the connection enforce the JSON-RPC 2.0
+// specification, as the JSON-RPC handler does with StrictJSONRPC. By default,
+// the connection is lenient.
+func WSStrictJSONRPC(strict bool) func(*wsConnection) {
+	return func(wsc *wsConnection) {
+		wsc.strict = strict
+	}
+}
+
 // Start starts the client service routines and blocks until there is an error.
 func (wsc *wsConnection) Start(ctx context.Context) error {
 	if err := wsc.RunState.Start(ctx); err != nil {
@@ -253,6 +318,12 @@ func (wsc *wsConnection) GetRemoteAddr() string {
 	return wsc.remoteAddr
 }
 
+// GetLocalAddr returns the local address of the underlying connection, i.e.
+// the address of the listener it was accepted on.
+func (wsc *wsConnection) GetLocalAddr() string {
+	return wsc.baseConn.LocalAddr().String()
+}
+
 // WriteRPCResponse pushes a response to the writeChan, and blocks until it is
 // accepted.
 // It implements WSRPCConnection. It is Goroutine-safe.
@@ -301,7 +372,11 @@ func (wsc *wsConnection) readRoutine(ctx context.Context) {
 				err = fmt.Errorf("WSJSONRPC: %v", r)
 			}
 			wsc.Logger.Error("Panic in WSJSONRPC handler", "err", err, "stack", string(debug.Stack()))
-			if err := wsc.WriteRPCResponse(writeCtx, rpctypes.RPCInternalError(rpctypes.JSONRPCIntID(-1), err)); err != nil {
+			resp := rpctypes.RPCInternalError(rpctypes.JSONRPCIntID(-1), err)
+			if wsc.strict {
+				resp.ID = nil // the ID of the request is not known
+			}
+			if err := wsc.WriteRPCResponse(writeCtx, resp); err != nil {
 				wsc.Logger.Error("error writing RPC response", "err", err)
 			}
 			go wsc.readRoutine(ctx)
@@ -336,12 +411,9 @@ func (wsc *wsConnection) readRoutine(ctx context.Context) {
 				return
 			}
 
-			dec := json.NewDecoder(r)
-			var request rpctypes.RPCRequest
-			err = dec.Decode(&request)
-			if err != nil {
-				if err := wsc.WriteRPCResponse(writeCtx,
-					rpctypes.RPCParseError(fmt.Errorf("error unmarshaling request: %w", err))); err != nil {
+			request, errResp, ok := wsc.readRequest(r)
+			if !ok {
+				if err := wsc.WriteRPCResponse(writeCtx, errResp); err != nil {
 					wsc.Logger.Error("error writing RPC response", "err", err)
 				}
 				continue
@@ -358,23 +430,49 @@ func (wsc *wsConnection) readRoutine(ctx context.Context) {
 			}
 
 			// Now, fetch the RPCFunc and execute it.
+			start := time.Now()
 			rpcFunc := wsc.funcMap[request.Method]
 			if rpcFunc == nil {
-				if err := wsc.WriteRPCResponse(writeCtx, rpctypes.RPCMethodNotFoundError(request.ID)); err != nil {
+				resp := rpctypes.RPCMethodNotFoundError(request.ID)
+				wsc.metrics.observeCall(unknownMethod, protocolWebsocket, start, len(request.Params), resp)
+				if err := wsc.WriteRPCResponse(writeCtx, resp); err != nil {
 					wsc.Logger.Error("error writing RPC response", "err", err)
 				}
 				continue
 			}
 
+			if err := wsc.rateLimiter.Allow(request.Method, wsc.remoteAddr); err != nil {
+				resp := rpctypes.RPCRateLimitError(request.ID, err)
+				wsc.metrics.observeCall(request.Method, protocolWebsocket, start, len(request.Params), resp)
+				if err := wsc.WriteRPCResponse(writeCtx, resp); err != nil {
+					wsc.Logger.Error("error writing RPC response", "err", err)
+				}
+				continue
+			}
+			if draining, reason := wsc.isDraining(); draining && rpcFunc.subscription {
+				resp := rpctypes.RPCServerError(request.ID,
+					fmt.Errorf("the server is not accepting subscriptions: %s", reason))
+				wsc.metrics.observeCall(request.Method, protocolWebsocket, start, len(request.Params), resp)
+				if err := wsc.WriteRPCResponse(writeCtx, resp); err != nil {
+					wsc.Logger.Error("error writing RPC response", "err", err)
+				}
+				continue
+			}
+			if rpcFunc.deprecation != nil {
+				wsc.metrics.deprecatedCall(request.Method)
+			}
+
 			fctx := rpctypes.WithCallInfo(wsc.Context(), &rpctypes.CallInfo{
 				RPCRequest: &request,
 				WSConn:     wsc,
+				Caller:     wsc.caller,
 			})
 			args, err := parseParams(fctx, rpcFunc, request.Params)
 			if err != nil {
-				if err := wsc.WriteRPCResponse(writeCtx, rpctypes.RPCInvalidParamsError(
-					request.ID, fmt.Errorf("error converting json params to arguments: %w", err)),
-				); err != nil {
+				resp := rpctypes.RPCInvalidParamsError(
+					request.ID, fmt.Errorf("error converting json params to arguments: %w", err))
+				wsc.metrics.observeCall(request.Method, protocolWebsocket, start, len(request.Params), resp)
+				if err := wsc.WriteRPCResponse(writeCtx, resp); err != nil {
 					wsc.Logger.Error("error writing RPC response", "err", err)
 				}
 				continue
@@ -400,15 +498,21 @@ func (wsc *wsConnection) readRoutine(ctx context.Context) {
 				switch errors.Unwrap(err) {
 				// check if the error was due to an invald request
 				case coretypes.ErrZeroOrNegativeHeight, coretypes.ErrZeroOrNegativePerPage,
-					coretypes.ErrPageOutOfRange, coretypes.ErrInvalidRequest:
+					coretypes.ErrPerPageExceedsMax, coretypes.ErrPageOutOfRange,
+					coretypes.ErrInvalidRequest:
 					resp = rpctypes.RPCInvalidRequestError(request.ID, err)
 
+				case coretypes.ErrCatchingUp:
+					resp = rpctypes.RPCCatchingUpError(request.ID, err)
+
 				// lastly default all remaining errors as internal errors
 				default: // includes ctypes.ErrHeightNotAvailable and ctypes.ErrHeightExceedsChainHead
 					resp = rpctypes.RPCInternalError(request.ID, err)
 				}
 			}
 
+			resp.Deprecation = rpcFunc.deprecation
+			wsc.metrics.observeCall(request.Method, protocolWebsocket, start, len(request.Params), resp)
 			if err := wsc.WriteRPCResponse(writeCtx, resp); err != nil {
 				wsc.Logger.Error("error writing RPC response", "err", err)
 			}
@@ -417,6 +521,31 @@ func (wsc *wsConnection) readRoutine(ctx context.Context) {
 	}
 }
 
+// readRequest reads a request from r. If the request can't be read, it
+// returns the error response to send instead.
+func (wsc *wsConnection) readRequest(r io.Reader) (rpctypes.RPCRequest, rpctypes.RPCResponse, bool) {
+	var request rpctypes.RPCRequest
+	if !wsc.strict {
+		if err := json.NewDecoder(r).Decode(&request); err != nil {
+			return request, rpctypes.RPCParseError(fmt.Errorf("error unmarshaling request: %w", err)), false
+		}
+		return request, rpctypes.RPCResponse{}, true
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return request, rpctypes.RPCInvalidRequestError(nil, fmt.Errorf("reading request: %w", err)), false
+	}
+	if !json.Valid(data) {
+		return request, rpctypes.RPCParseError(errors.New("request is not valid JSON")), false
+	}
+	request, err = rpctypes.ParseStrictRequest(data)
+	if err != nil {
+		return request, rpctypes.RPCInvalidRequestError(request.ID, err), false
+	}
+	return request, rpctypes.RPCResponse{}, true
+}
+
 // receives on a write channel and writes out on the socket
 func (wsc *wsConnection) writeRoutine(ctx context.Context) {
 	pingTicker := time.NewTicker(wsc.pingPeriod)
@@ -438,6 +567,9 @@ func (wsc *wsConnection) writeRoutine(ctx context.Context) {
 			return
 		case <-wsc.readRoutineQuit: // error in readRoutine
 			return
+		case <-wsc.drained:
+			wsc.writeClose()
+			return
 		case m := <-pongs:
 			err := wsc.writeMessageWithDeadline(websocket.PongMessage, []byte(m))
 			if err != nil {
@@ -450,19 +582,31 @@ func (wsc *wsConnection) writeRoutine(ctx context.Context) {
 				return
 			}
 		case msg := <-wsc.writeChan:
-			data, err := json.Marshal(msg)
-			if err != nil {
-				wsc.Logger.Error("Failed to marshal RPCResponse to JSON", "msg", msg, "err", err)
-				continue
-			}
-			if err = wsc.writeMessageWithDeadline(websocket.TextMessage, data); err != nil {
-				wsc.Logger.Error("Failed to write response", "msg", msg, "err", err)
+			if err := wsc.writeResponse(msg); err != nil {
 				return
 			}
 		}
 	}
 }
 
+// writeResponse writes a response on the socket. It only returns the errors
+// of the socket, after logging them.
+func (wsc *wsConnection) writeResponse(msg rpctypes.RPCResponse) error {
+	if wsc.strict {
+		msg = strictResponse(msg)
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		wsc.Logger.Error("Failed to marshal RPCResponse to JSON", "msg", msg, "err", err)
+		return nil
+	}
+	if err = wsc.writeMessageWithDeadline(websocket.TextMessage, data); err != nil {
+		wsc.Logger.Error("Failed to write response", "msg", msg, "err", err)
+		return err
+	}
+	return nil
+}
+
 // All writes to the websocket must (re)set the write deadline.
 // If some writes don't set it while others do, they may timeout incorrectly
 // (https://github.com/tendermint/tendermint/issues/553)
@@ -1,12 +1,16 @@
 package types
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // a wrapper to emulate a sum type: jsonrpcid = string | int
@@ -27,6 +31,24 @@ type JSONRPCIntID int
 func (JSONRPCIntID) isJSONRPCID()      {}
 func (id JSONRPCIntID) String() string { return fmt.Sprintf("%d", id) }
 
+// JSONRPCNumberID a wrapper for JSON-RPC integer IDs kept as sent, which may
+// exceed the range of an int. It is used by ParseStrictRequest.
+type JSONRPCNumberID json.Number
+
+func (JSONRPCNumberID) isJSONRPCID()      {}
+func (id JSONRPCNumberID) String() string { return string(id) }
+
+func (id JSONRPCNumberID) MarshalJSON() ([]byte, error) { return []byte(id), nil }
+
+// JSONRPCNullID the null JSON-RPC ID, of the requests whose ID is null and of
+// the responses to the requests whose ID could not be detected.
+type JSONRPCNullID struct{}
+
+func (JSONRPCNullID) isJSONRPCID()   {}
+func (JSONRPCNullID) String() string { return "null" }
+
+func (JSONRPCNullID) MarshalJSON() ([]byte, error) { return []byte("null"), nil }
+
 func idFromInterface(idInterface interface{}) (jsonrpcid, error) {
 	switch id := idInterface.(type) {
 	case string:
@@ -83,6 +105,72 @@ func (req *RPCRequest) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ParseStrictRequest parses the request object data, enforcing the JSON-RPC
+// 2.0 specification: the jsonrpc member must be "2.0", the method a string,
+// the params, if any, an array or an object, and the ID, if any, a string, an
+// integer or null. The ID is kept as sent, a null ID being a JSONRPCNullID.
+// A request without ID is a notification.
+//
+// If the request is invalid, it returns an error along with the request, whose
+// ID is set if it could be detected.
+func ParseStrictRequest(data []byte) (RPCRequest, error) {
+	var req RPCRequest
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return req, errors.New("request must be an object")
+	}
+
+	if raw, ok := fields["id"]; ok {
+		id, err := strictIDFromJSON(raw)
+		if err != nil {
+			return req, err
+		}
+		req.ID = id
+	}
+	if err := json.Unmarshal(fields["jsonrpc"], &req.JSONRPC); err != nil || req.JSONRPC != "2.0" {
+		return req, errors.New(`jsonrpc must be "2.0"`)
+	}
+	if raw := fields["method"]; !isJSONString(raw) {
+		return req, errors.New("method must be a string")
+	} else if err := json.Unmarshal(raw, &req.Method); err != nil {
+		return req, fmt.Errorf("decoding method: %w", err)
+	}
+	if raw, ok := fields["params"]; ok {
+		if len(raw) == 0 || (raw[0] != '[' && raw[0] != '{') {
+			return req, errors.New("params must be an array or an object")
+		}
+		req.Params = raw
+	}
+	return req, nil
+}
+
+// strictIDFromJSON returns the ID encoded by data, which must be a string, an
+// integer or null.
+func strictIDFromJSON(data json.RawMessage) (jsonrpcid, error) {
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return JSONRPCNullID{}, nil
+	case isJSONString(data):
+		var id string
+		if err := json.Unmarshal(data, &id); err != nil {
+			return nil, fmt.Errorf("decoding id: %w", err)
+		}
+		return JSONRPCStringID(id), nil
+	}
+	var id json.Number
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil, errors.New("id must be a string, a number or null")
+	}
+	if strings.ContainsAny(string(id), ".eE") {
+		return nil, fmt.Errorf("id %s must not have a fractional part", id)
+	}
+	return JSONRPCNumberID(id), nil
+}
+
+func isJSONString(data json.RawMessage) bool {
+	return len(data) != 0 && data[0] == '"'
+}
+
 func NewRPCRequest(id jsonrpcid, method string, params json.RawMessage) RPCRequest {
 	return RPCRequest{
 		JSONRPC: "2.0",
@@ -122,11 +210,41 @@ func (err RPCError) Error() string {
 	return fmt.Sprintf(baseFormat, err.Code, err.Message)
 }
 
+// Deprecation describes a deprecated RPC method. The method is still served
+// until it is removed, and the responses to its calls carry its deprecation.
+type Deprecation struct {
+	// Replacement is the method to call instead, if any.
+	Replacement string `json:"replacement,omitempty"`
+	// RemovalVersion is the version removing the method, if known.
+	RemovalVersion string `json:"removal_version,omitempty"`
+	// Sunset is the date after which the method may be removed, if known.
+	Sunset *time.Time `json:"sunset,omitempty"`
+}
+
+// Message returns a human readable warning about the deprecation of method.
+func (d Deprecation) Message(method string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "method %s is deprecated", method)
+	switch {
+	case d.RemovalVersion != "":
+		fmt.Fprintf(&sb, " and will be removed in %s", d.RemovalVersion)
+	case d.Sunset != nil:
+		fmt.Fprintf(&sb, " and may be removed after %s", d.Sunset.UTC().Format("2006-01-02"))
+	}
+	if d.Replacement != "" {
+		fmt.Fprintf(&sb, ", use %s instead", d.Replacement)
+	}
+	return sb.String()
+}
+
 type RPCResponse struct {
 	JSONRPC string          `json:"jsonrpc"`
 	ID      jsonrpcid       `json:"id,omitempty"`
 	Result  json.RawMessage `json:"result,omitempty"`
 	Error   *RPCError       `json:"error,omitempty"`
+
+	// Deprecation is set in the responses to the calls of a deprecated method.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
 }
 
 // UnmarshalJSON custom JSON unmarshaling due to jsonrpcid being string or int
@@ -136,6 +254,8 @@ func (resp *RPCResponse) UnmarshalJSON(data []byte) error {
 		ID      interface{}     `json:"id,omitempty"`
 		Result  json.RawMessage `json:"result,omitempty"`
 		Error   *RPCError       `json:"error,omitempty"`
+
+		Deprecation *Deprecation `json:"deprecation,omitempty"`
 	}{}
 	err := json.Unmarshal(data, &unsafeResp)
 	if err != nil {
@@ -145,6 +265,7 @@ func (resp *RPCResponse) UnmarshalJSON(data []byte) error {
 	resp.JSONRPC = unsafeResp.JSONRPC
 	resp.Error = unsafeResp.Error
 	resp.Result = unsafeResp.Result
+	resp.Deprecation = unsafeResp.Deprecation
 	if unsafeResp.ID == nil {
 		return nil
 	}
@@ -209,6 +330,18 @@ func RPCServerError(id jsonrpcid, err error) RPCResponse {
 	return NewRPCErrorResponse(id, -32000, "Server error", err.Error())
 }
 
+// RPCRateLimitError is the response to a request rejected because the client
+// exceeded a rate limit of the server.
+func RPCRateLimitError(id jsonrpcid, err error) RPCResponse {
+	return NewRPCErrorResponse(id, -32005, "Rate limit exceeded", err.Error())
+}
+
+// RPCCatchingUpError is the response to a request rejected because the node
+// is syncing too far behind the head of the chain.
+func RPCCatchingUpError(id jsonrpcid, err error) RPCResponse {
+	return NewRPCErrorResponse(id, -32006, "Node catching up", err.Error())
+}
+
 //----------------------------------------
 
 // WSRPCConnection represents a websocket connection.
@@ -223,12 +356,69 @@ type WSRPCConnection interface {
 	Context() context.Context
 }
 
+// A DeliveryTracker is a WSRPCConnection that keeps track of the event
+// deliveries in progress, so that it can wait for them to finish before
+// closing when the server drains its connections.
+type DeliveryTracker interface {
+	// TrackDelivery reports the start of a delivery, and returns the function
+	// reporting its end.
+	TrackDelivery() (done func())
+}
+
+// TrackDelivery reports the start of an event delivery on conn if conn is a
+// DeliveryTracker, and returns the function reporting its end.
+func TrackDelivery(conn WSRPCConnection) (done func()) {
+	if t, ok := conn.(DeliveryTracker); ok {
+		return t.TrackDelivery()
+	}
+	return func() {}
+}
+
 // CallInfo carries JSON-RPC request metadata for RPC functions invoked via
 // JSON-RPC. It can be recovered from the context with GetCallInfo.
 type CallInfo struct {
 	RPCRequest  *RPCRequest     // non-nil for requests via HTTP or websocket
 	HTTPRequest *http.Request   // non-nil for requests via HTTP
 	WSConn      WSRPCConnection // non-nil for requests via websocket
+
+	// Caller describes the caller, as attached to the HTTP request (or to the
+	// websocket upgrade request) by middleware with WithCaller. It is the zero
+	// value if no middleware did.
+	Caller Caller
+
+	immutable bool // see SetImmutable
+}
+
+// Caller describes the caller of an RPC request, as established by HTTP
+// middleware in front of the RPC handlers, e.g. after authenticating it, so
+// that RPC functions can adjust their behavior to it.
+type Caller struct {
+	// Principal is the authenticated identity of the caller, or empty if the
+	// caller is anonymous.
+	Principal string
+
+	// TenantID identifies the tenant the caller acts for, if any.
+	TenantID string
+
+	// RateLimitClass is the class of rate limits the caller is subject to,
+	// if any.
+	RateLimitClass string
+}
+
+type callerKey struct{}
+
+// WithCaller returns a child context of ctx with the caller attached. It is
+// meant for HTTP middleware, which attaches the caller to the context of the
+// request before passing it on to the RPC handlers.
+func WithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// GetCaller returns the caller attached to ctx by WithCaller, or the zero
+// value if there is none.
+func GetCaller(ctx context.Context) Caller {
+	caller, _ := ctx.Value(callerKey{}).(Caller)
+	return caller
 }
 
 type callInfoKey struct{}
@@ -247,6 +437,27 @@ func GetCallInfo(ctx context.Context) *CallInfo {
 	return nil
 }
 
+// Authenticated reports whether the caller was authenticated, i.e. has a
+// principal.
+func (ci *CallInfo) Authenticated() bool {
+	return ci != nil && ci.Caller.Principal != ""
+}
+
+// SetImmutable is called by an RPC function to report that its result will
+// never change, e.g. because it describes a block at a past height given by
+// the caller, so that the server may cache it. ci may be nil.
+func (ci *CallInfo) SetImmutable() {
+	if ci != nil {
+		ci.immutable = true
+	}
+}
+
+// Immutable reports whether the RPC function reported its result immutable
+// with SetImmutable.
+func (ci *CallInfo) Immutable() bool {
+	return ci != nil && ci.immutable
+}
+
 // RemoteAddr returns the remote address (usually a string "IP:port").  If
 // neither HTTPRequest nor WSConn is set, an empty string is returned.
 //
@@ -263,6 +474,26 @@ func (ci *CallInfo) RemoteAddr() string {
 	return ""
 }
 
+// LocalAddr returns the local address the request was received on, which
+// identifies the listener of the RPC server, or an empty string if it is not
+// known.
+//
+// For HTTP requests, this reports the address of the connection of the
+// request. For websocket requests, this reports the connection's
+// GetLocalAddr, if it has this method.
+func (ci *CallInfo) LocalAddr() string {
+	if ci == nil {
+		return ""
+	} else if ci.HTTPRequest != nil {
+		if addr, ok := ci.HTTPRequest.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+			return addr.String()
+		}
+	} else if conn, ok := ci.WSConn.(interface{ GetLocalAddr() string }); ok {
+		return conn.GetLocalAddr()
+	}
+	return ""
+}
+
 //----------------------------------------
 // SOCKETS
 
@@ -81,3 +81,51 @@ func TestRPCError(t *testing.T) {
 			Message: "Badness",
 		}))
 }
+
+func TestParseStrictRequest(t *testing.T) {
+	testCases := []struct {
+		data    string
+		wantID  jsonrpcid
+		wantErr string
+	}{
+		{`{"jsonrpc":"2.0","id":"a","method":"m","params":[1]}`, JSONRPCStringID("a"), ""},
+		{`{"jsonrpc":"2.0","id":12345678901234567890,"method":"m"}`, JSONRPCNumberID("12345678901234567890"), ""},
+		{`{"jsonrpc":"2.0","id":-7,"method":"m","params":{}}`, JSONRPCNumberID("-7"), ""},
+		{`{"jsonrpc":"2.0","id":null,"method":"m"}`, JSONRPCNullID{}, ""},
+		{`{"jsonrpc":"2.0","method":"m"}`, nil, ""}, // notification
+
+		{`{"jsonrpc":"2.0","id":1.5,"method":"m"}`, nil, "fractional"},
+		{`{"jsonrpc":"2.0","id":1e3,"method":"m"}`, nil, "fractional"},
+		{`{"jsonrpc":"2.0","id":{},"method":"m"}`, nil, "id must be"},
+		{`{"jsonrpc":"2.0","id":true,"method":"m"}`, nil, "id must be"},
+		{`{"id":1,"method":"m"}`, JSONRPCNumberID("1"), "jsonrpc"},
+		{`{"jsonrpc":"1.0","id":1,"method":"m"}`, JSONRPCNumberID("1"), "jsonrpc"},
+		{`{"jsonrpc":"2.0","id":1,"method":1}`, JSONRPCNumberID("1"), "method"},
+		{`{"jsonrpc":"2.0","id":1}`, JSONRPCNumberID("1"), "method"},
+		{`{"jsonrpc":"2.0","id":1,"method":"m","params":"x"}`, JSONRPCNumberID("1"), "params"},
+		{`{"jsonrpc":"2.0","id":1,"method":"m","params":null}`, JSONRPCNumberID("1"), "params"},
+		{`[1]`, nil, "object"},
+		{`null`, nil, "object"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.data, func(t *testing.T) {
+			req, err := ParseStrictRequest([]byte(tc.data))
+			if tc.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "m", req.Method)
+			}
+			assert.Equal(t, tc.wantID, req.ID)
+		})
+	}
+
+	// The IDs are echoed as sent.
+	for _, id := range []jsonrpcid{JSONRPCNumberID("12345678901234567890"), JSONRPCNullID{}} {
+		bz, err := json.Marshal(RPCMethodNotFoundError(id))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf(
+			`{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"Method not found"}}`, id), string(bz))
+	}
+}
@@ -18,7 +18,8 @@ REF=$(curl -H "Accept: application/vnd.github.v3.sha" -qL \
 readonly OUTDIR="tendermint-spec-${REF}"
 curl -qL "https://api.github.com/repos/tendermint/spec/tarball/${REF}" | tar -xzf - ${OUTDIR}/
 
-cp -r ${OUTDIR}/proto/tendermint/* ./proto/tendermint
+# The .proto files of this repository take precedence over those of the spec.
+cp -rn ${OUTDIR}/proto/tendermint/* ./proto/tendermint
 cp -r ${OUTDIR}/third_party/** ./third_party
 
 MODNAME="$(go list -m)"
@@ -39,6 +40,8 @@ echo "removing copied files"
 
 find ${OUTDIR}/proto/tendermint/ -name *.proto \
 	| sed "s/$OUTDIR\/\(.*\)/\1/g" \
-	| xargs -I {} rm {}
+	| while read -r file; do
+		git ls-files --error-unmatch "$file" >/dev/null 2>&1 || rm "$file"
+	done
 
 rm -rf ${OUTDIR}
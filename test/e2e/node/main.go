@@ -194,6 +194,10 @@ func startLightNode(ctx context.Context, cfg *Config) error {
 	rpccfg := rpcserver.DefaultConfig()
 	rpccfg.MaxBodyBytes = tmcfg.RPC.MaxBodyBytes
 	rpccfg.MaxHeaderBytes = tmcfg.RPC.MaxHeaderBytes
+	rpccfg.HTTP2 = tmcfg.RPC.HTTP2
+	rpccfg.HTTP2MaxConcurrentStreams = tmcfg.RPC.HTTP2MaxConcurrentStreams
+	rpccfg.HTTP2MaxUploadBufferPerStream = tmcfg.RPC.HTTP2StreamWindowBytes
+	rpccfg.HTTP2MaxUploadBufferPerConnection = tmcfg.RPC.HTTP2ConnWindowBytes
 	rpccfg.MaxOpenConnections = tmcfg.RPC.MaxOpenConnections
 	// If necessary adjust global WriteTimeout to ensure it's greater than
 	// TimeoutBroadcastTxCommit.
@@ -606,6 +606,7 @@ type CommitSig struct {
 	ValidatorAddress Address     `json:"validator_address"`
 	Timestamp        time.Time   `json:"timestamp"`
 	Signature        []byte      `json:"signature"`
+	Extension        []byte      `json:"extension,omitempty"`
 }
 
 // NewCommitSigForBlock returns new CommitSig with BlockIDFlagCommit.
@@ -694,6 +695,9 @@ func (cs CommitSig) ValidateBasic() error {
 		if len(cs.Signature) != 0 {
 			return errors.New("signature is present")
 		}
+		if len(cs.Extension) != 0 {
+			return errors.New("extension is present")
+		}
 	default:
 		if len(cs.ValidatorAddress) != crypto.AddressSize {
 			return fmt.Errorf("expected ValidatorAddress size to be %d bytes, got %d bytes",
@@ -708,6 +712,12 @@ func (cs CommitSig) ValidateBasic() error {
 		if len(cs.Signature) > MaxSignatureSize {
 			return fmt.Errorf("signature is too big (max: %d)", MaxSignatureSize)
 		}
+		if len(cs.Extension) > 0 && cs.BlockIDFlag != BlockIDFlagCommit {
+			return errors.New("extension is only allowed on signatures for the block")
+		}
+		if len(cs.Extension) > MaxVoteExtensionSize {
+			return fmt.Errorf("extension is too big (max: %d)", MaxVoteExtensionSize)
+		}
 	}
 
 	return nil
@@ -724,6 +734,7 @@ func (cs *CommitSig) ToProto() *tmproto.CommitSig {
 		ValidatorAddress: cs.ValidatorAddress,
 		Timestamp:        cs.Timestamp,
 		Signature:        cs.Signature,
+		Extension:        cs.Extension,
 	}
 }
 
@@ -735,6 +746,7 @@ func (cs *CommitSig) FromProto(csp tmproto.CommitSig) error {
 	cs.ValidatorAddress = csp.ValidatorAddress
 	cs.Timestamp = csp.Timestamp
 	cs.Signature = csp.Signature
+	cs.Extension = csp.Extension
 
 	return cs.ValidateBasic()
 }
@@ -801,7 +813,28 @@ func (commit *Commit) GetVote(valIdx int32) *Vote {
 		ValidatorAddress: commitSig.ValidatorAddress,
 		ValidatorIndex:   valIdx,
 		Signature:        commitSig.Signature,
+		Extension:        commitSig.Extension,
+	}
+}
+
+// ExtensionBytes returns the number of bytes that the vote extensions add to
+// the encoding of the commit, which MaxCommitBytes does not account for.
+func (commit *Commit) ExtensionBytes() int64 {
+	if commit == nil {
+		return 0
+	}
+	var n int64
+	for _, cs := range commit.Signatures {
+		if len(cs.Extension) == 0 {
+			continue
+		}
+		pb := cs.ToProto()
+		with := pb.Size()
+		pb.Extension = nil
+		without := pb.Size()
+		n += int64(with + proto.SizeVarint(uint64(with)) - without - proto.SizeVarint(uint64(without)))
 	}
+	return n
 }
 
 // VoteSignBytes returns the bytes of the Vote corresponding to valIdx for
@@ -346,6 +346,13 @@ func TestMaxCommitBytes(t *testing.T) {
 
 	assert.EqualValues(t, MaxCommitBytes(MaxVotesCount), int64(pb.Size()))
 
+	// the vote extensions come on top of it
+	commit.Signatures = []CommitSig{cs, cs}
+	commit.Signatures[0].BlockIDFlag = BlockIDFlagCommit
+	commit.Signatures[0].Extension = make([]byte, MaxVoteExtensionSize)
+	pb = commit.ToProto()
+	assert.EqualValues(t, MaxCommitBytes(2)+commit.ExtensionBytes(), int64(pb.Size()))
+	assert.EqualValues(t, 0, NewCommit(1, 0, BlockID{}, nil).ExtensionBytes())
 }
 
 func TestHeaderHash(t *testing.T) {
@@ -992,6 +999,41 @@ func TestCommitSig_ValidateBasic(t *testing.T) {
 			},
 			false, "",
 		},
+		{
+			"BlockIDFlagAbsent extension present",
+			CommitSig{BlockIDFlag: BlockIDFlagAbsent, Extension: []byte{0xAA}},
+			true, "extension is present",
+		},
+		{
+			"BlockIDFlagNil extension present",
+			CommitSig{
+				BlockIDFlag:      BlockIDFlagNil,
+				ValidatorAddress: make([]byte, crypto.AddressSize),
+				Signature:        make([]byte, MaxSignatureSize),
+				Extension:        []byte{0xAA},
+			},
+			true, "extension is only allowed",
+		},
+		{
+			"BlockIDFlagCommit extension too large",
+			CommitSig{
+				BlockIDFlag:      BlockIDFlagCommit,
+				ValidatorAddress: make([]byte, crypto.AddressSize),
+				Signature:        make([]byte, MaxSignatureSize),
+				Extension:        make([]byte, MaxVoteExtensionSize+1),
+			},
+			true, "extension is too big",
+		},
+		{
+			"BlockIDFlagCommit valid extension",
+			CommitSig{
+				BlockIDFlag:      BlockIDFlagCommit,
+				ValidatorAddress: make([]byte, crypto.AddressSize),
+				Signature:        make([]byte, MaxSignatureSize),
+				Extension:        make([]byte, MaxVoteExtensionSize),
+			},
+			false, "",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,87 @@
+// Package canonical exposes the canonical encodings of the consensus messages:
+// the bytes signed by validators for votes and proposals, and the hash of
+// block headers. It comes with golden test vectors (see Vectors), so that
+// remote signers and other implementations of the protocol can check that
+// they produce exactly the same bytes.
+//
+// The messages are given in their protobuf form. In the vectors, they are
+// encoded with the proto3 JSON mapping, using the original field names, which
+// is what MarshalJSON and UnmarshalJSON implement.
+package canonical
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// VoteSignBytes returns the bytes signed by a validator for vote on the chain
+// chainID: the length-delimited protobuf encoding of the canonical vote.
+func VoteSignBytes(chainID string, vote *tmproto.Vote) ([]byte, error) {
+	if _, err := types.BlockIDFromProto(&vote.BlockID); err != nil {
+		return nil, fmt.Errorf("invalid block ID: %w", err)
+	}
+	return types.VoteSignBytes(chainID, vote), nil
+}
+
+// CanonicalVote returns the canonical form of vote on the chain chainID,
+// whose encoding is signed.
+func CanonicalVote(chainID string, vote *tmproto.Vote) (*tmproto.CanonicalVote, error) {
+	if _, err := types.BlockIDFromProto(&vote.BlockID); err != nil {
+		return nil, fmt.Errorf("invalid block ID: %w", err)
+	}
+	cv := types.CanonicalizeVote(chainID, vote)
+	return &cv, nil
+}
+
+// ProposalSignBytes returns the bytes signed by a validator for proposal on
+// the chain chainID: the length-delimited protobuf encoding of the canonical
+// proposal.
+func ProposalSignBytes(chainID string, proposal *tmproto.Proposal) ([]byte, error) {
+	if _, err := types.BlockIDFromProto(&proposal.BlockID); err != nil {
+		return nil, fmt.Errorf("invalid block ID: %w", err)
+	}
+	return types.ProposalSignBytes(chainID, proposal), nil
+}
+
+// CanonicalProposal returns the canonical form of proposal on the chain
+// chainID, whose encoding is signed.
+func CanonicalProposal(chainID string, proposal *tmproto.Proposal) (*tmproto.CanonicalProposal, error) {
+	if _, err := types.BlockIDFromProto(&proposal.BlockID); err != nil {
+		return nil, fmt.Errorf("invalid block ID: %w", err)
+	}
+	cp := types.CanonicalizeProposal(chainID, proposal)
+	return &cp, nil
+}
+
+// HeaderHash returns the hash of header, which is the root of the Merkle tree
+// of its encoded fields, signed through the block ID of the votes.
+func HeaderHash(header *tmproto.Header) ([]byte, error) {
+	h, err := types.HeaderFromProto(header)
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	return h.Hash(), nil
+}
+
+// MarshalJSON encodes msg with the proto3 JSON mapping, using the original
+// field names.
+func MarshalJSON(msg proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	m := jsonpb.Marshaler{OrigName: true}
+	if err := m.Marshal(&buf, msg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes msg from the proto3 JSON mapping, rejecting unknown
+// fields.
+func UnmarshalJSON(bz []byte, msg proto.Message) error {
+	return jsonpb.Unmarshal(bytes.NewReader(bz), msg)
+}
@@ -0,0 +1,177 @@
+package canonical
+
+import (
+	"bytes"
+	_ "embed" // for the golden vectors
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gogo/protobuf/proto"
+
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// Kind is the kind of message of a test vector.
+type Kind string
+
+const (
+	// KindVote is a vote, whose output is its sign bytes.
+	KindVote Kind = "vote"
+	// KindProposal is a proposal, whose output is its sign bytes.
+	KindProposal Kind = "proposal"
+	// KindHeader is a block header, whose output is its hash.
+	KindHeader Kind = "header"
+)
+
+// Vector is a test vector of a canonical encoding: the expected encoding of a
+// message, in its canonical form and in bytes.
+type Vector struct {
+	Name    string `json:"name"`
+	Kind    Kind   `json:"kind"`
+	ChainID string `json:"chain_id,omitempty"`
+
+	// Input is the vote, proposal or header, in JSON (see MarshalJSON).
+	Input json.RawMessage `json:"input"`
+
+	// Canonical is the canonical form of a vote or proposal, in JSON. It is
+	// empty for headers.
+	Canonical json.RawMessage `json:"canonical,omitempty"`
+
+	// Output is the hex-encoded sign bytes of a vote or proposal, or hash of
+	// a header.
+	Output string `json:"output"`
+}
+
+//go:embed vectors.json
+var goldenVectors []byte
+
+// Vectors returns the golden test vectors, which cover the edge cases of the
+// encodings, e.g. zero values, nil block IDs, negative rounds and timestamps
+// with nanoseconds.
+func Vectors() []Vector {
+	vectors, err := ReadVectors(bytes.NewReader(goldenVectors))
+	if err != nil {
+		panic(err)
+	}
+	return vectors
+}
+
+// ReadVectors reads test vectors encoded as a JSON array.
+func ReadVectors(r io.Reader) ([]Vector, error) {
+	var vectors []Vector
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, fmt.Errorf("invalid test vectors: %w", err)
+	}
+	return vectors, nil
+}
+
+// NewVector returns the test vector of msg, a *tmproto.Vote, *tmproto.Proposal
+// or *tmproto.Header, on the chain chainID, which is ignored for headers.
+func NewVector(name, chainID string, msg proto.Message) (Vector, error) {
+	v := Vector{Name: name, ChainID: chainID}
+	switch msg.(type) {
+	case *tmproto.Vote:
+		v.Kind = KindVote
+	case *tmproto.Proposal:
+		v.Kind = KindProposal
+	case *tmproto.Header:
+		v.Kind = KindHeader
+		v.ChainID = ""
+	default:
+		return Vector{}, fmt.Errorf("unsupported message %T", msg)
+	}
+
+	var err error
+	if v.Input, err = MarshalJSON(msg); err != nil {
+		return Vector{}, err
+	}
+	canonical, output, err := v.Compute()
+	if err != nil {
+		return Vector{}, err
+	}
+	v.Canonical = canonical
+	v.Output = hex.EncodeToString(output)
+	return v, nil
+}
+
+// Compute computes the canonical form, if any, and the output of the input of
+// v with this implementation.
+func (v Vector) Compute() (canonical json.RawMessage, output []byte, err error) {
+	var msg proto.Message
+	switch v.Kind {
+	case KindVote:
+		vote := new(tmproto.Vote)
+		if err := UnmarshalJSON(v.Input, vote); err != nil {
+			return nil, nil, fmt.Errorf("invalid vote: %w", err)
+		}
+		if msg, err = CanonicalVote(v.ChainID, vote); err != nil {
+			return nil, nil, err
+		}
+		if output, err = VoteSignBytes(v.ChainID, vote); err != nil {
+			return nil, nil, err
+		}
+	case KindProposal:
+		proposal := new(tmproto.Proposal)
+		if err := UnmarshalJSON(v.Input, proposal); err != nil {
+			return nil, nil, fmt.Errorf("invalid proposal: %w", err)
+		}
+		if msg, err = CanonicalProposal(v.ChainID, proposal); err != nil {
+			return nil, nil, err
+		}
+		if output, err = ProposalSignBytes(v.ChainID, proposal); err != nil {
+			return nil, nil, err
+		}
+	case KindHeader:
+		header := new(tmproto.Header)
+		if err := UnmarshalJSON(v.Input, header); err != nil {
+			return nil, nil, fmt.Errorf("invalid header: %w", err)
+		}
+		if output, err = HeaderHash(header); err != nil {
+			return nil, nil, err
+		}
+		return nil, output, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown kind %q", v.Kind)
+	}
+
+	if canonical, err = MarshalJSON(msg); err != nil {
+		return nil, nil, err
+	}
+	return canonical, output, nil
+}
+
+// Verify checks that this implementation computes the canonical form, if
+// given, and the output of v. The canonical forms are compared as JSON
+// values, regardless of the formatting.
+func (v Vector) Verify() error {
+	canonical, output, err := v.Compute()
+	if err != nil {
+		return err
+	}
+
+	if len(v.Canonical) > 0 {
+		var want, got interface{}
+		if err := json.Unmarshal(v.Canonical, &want); err != nil {
+			return fmt.Errorf("invalid canonical form: %w", err)
+		}
+		if err := json.Unmarshal(canonical, &got); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(want, got) {
+			return fmt.Errorf("canonical form mismatch: expected %s, got %s", v.Canonical, canonical)
+		}
+	}
+
+	want, err := hex.DecodeString(v.Output)
+	if err != nil {
+		return fmt.Errorf("invalid output: %w", err)
+	}
+	if !bytes.Equal(want, output) {
+		return fmt.Errorf("output mismatch: expected %s, got %s", strings.ToLower(v.Output), hex.EncodeToString(output))
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package canonical
+
+import (
+	"encoding/json"
+	"flag"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/tmhash"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmversion "github.com/tendermint/tendermint/proto/tendermint/version"
+)
+
+var update = flag.Bool("update", false, "update vectors.json")
+
+// vectorCases are the messages of the golden vectors.
+func vectorCases() []struct {
+	name    string
+	chainID string
+	msg     proto.Message
+} {
+	hash := func(s string) []byte { return tmhash.Sum([]byte(s)) }
+	blockID := tmproto.BlockID{
+		Hash:          hash("block"),
+		PartSetHeader: tmproto.PartSetHeader{Total: 3, Hash: hash("parts")},
+	}
+	ts := time.Date(2021, 6, 15, 8, 30, 45, 123456789, time.UTC)
+
+	return []struct {
+		name    string
+		chainID string
+		msg     proto.Message
+	}{
+		{"zero vote", "", &tmproto.Vote{}},
+		{"prevote for nil", "test-chain", &tmproto.Vote{
+			Type: tmproto.PrevoteType, Height: 1, Round: 0, Timestamp: ts,
+		}},
+		{"prevote for a block", "test-chain", &tmproto.Vote{
+			Type: tmproto.PrevoteType, Height: 12345, Round: 2, BlockID: blockID, Timestamp: ts,
+		}},
+		{"precommit for a block", "test-chain", &tmproto.Vote{
+			Type: tmproto.PrecommitType, Height: 12345, Round: 2, BlockID: blockID, Timestamp: ts,
+		}},
+		{"precommit ignores the validator and signature", "test-chain", &tmproto.Vote{
+			Type: tmproto.PrecommitType, Height: 12345, Round: 2, BlockID: blockID, Timestamp: ts,
+			ValidatorAddress: hash("validator")[:20], ValidatorIndex: 7, Signature: []byte("signature"),
+		}},
+		{"precommit with a vote extension", "test-chain", &tmproto.Vote{
+			Type: tmproto.PrecommitType, Height: 12345, Round: 2, BlockID: blockID, Timestamp: ts,
+			Extension: []byte("vote extension"),
+		}},
+		{"precommit at the maximum height", "a-much-longer-chain-id-with-dashes_and_underscores", &tmproto.Vote{
+			Type: tmproto.PrecommitType, Height: math.MaxInt64, Round: math.MaxInt32, BlockID: blockID,
+			Timestamp: time.Date(2262, 4, 11, 23, 47, 16, 854775807, time.UTC),
+		}},
+		{"precommit before the Unix epoch", "test-chain", &tmproto.Vote{
+			Type: tmproto.PrecommitType, Height: 1, BlockID: blockID,
+			Timestamp: time.Date(1969, 12, 31, 23, 59, 59, 1, time.UTC),
+		}},
+		{"zero proposal", "", &tmproto.Proposal{}},
+		{"proposal without POL round", "test-chain", &tmproto.Proposal{
+			Type: tmproto.ProposalType, Height: 12345, Round: 1, PolRound: -1, BlockID: blockID, Timestamp: ts,
+		}},
+		{"proposal with POL round", "test-chain", &tmproto.Proposal{
+			Type: tmproto.ProposalType, Height: 12345, Round: 3, PolRound: 2, BlockID: blockID, Timestamp: ts,
+			Signature: []byte("signature"),
+		}},
+		{"first header", "", &tmproto.Header{
+			Version:            tmversion.Consensus{Block: 11},
+			ChainID:            "test-chain",
+			Height:             1,
+			Time:               ts,
+			ValidatorsHash:     hash("validators"),
+			NextValidatorsHash: hash("validators"),
+			ConsensusHash:      hash("consensus"),
+			ProposerAddress:    hash("proposer")[:20],
+		}},
+		{"header", "", &tmproto.Header{
+			Version:            tmversion.Consensus{Block: 11, App: 2},
+			ChainID:            "test-chain",
+			Height:             12345,
+			Time:               ts,
+			LastBlockId:        blockID,
+			LastCommitHash:     hash("last_commit"),
+			DataHash:           hash("data"),
+			ValidatorsHash:     hash("validators"),
+			NextValidatorsHash: hash("next_validators"),
+			ConsensusHash:      hash("consensus"),
+			AppHash:            []byte("app hash of any length"),
+			LastResultsHash:    hash("last_results"),
+			EvidenceHash:       hash("evidence"),
+			ProposerAddress:    hash("proposer")[:20],
+		}},
+	}
+}
+
+func TestVectors(t *testing.T) {
+	var vectors []Vector
+	for _, c := range vectorCases() {
+		v, err := NewVector(c.name, c.chainID, c.msg)
+		require.NoError(t, err, c.name)
+		vectors = append(vectors, v)
+	}
+
+	if *update {
+		bz, err := json.MarshalIndent(vectors, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile("vectors.json", append(bz, '\n'), 0644))
+		return
+	}
+
+	// The encodings must not change: the golden vectors are what other
+	// implementations check against.
+	golden := Vectors()
+	require.Len(t, golden, len(vectors), "vectors.json is out of date, run the tests with -update")
+	for i, v := range golden {
+		assert.Equal(t, vectors[i].Name, v.Name)
+		assert.Equal(t, vectors[i].Output, v.Output, v.Name)
+		assert.NoError(t, v.Verify(), v.Name)
+	}
+}
+
+func TestVectorVerify(t *testing.T) {
+	v := Vectors()[3]
+	require.NoError(t, v.Verify())
+
+	// The canonical forms are compared regardless of their formatting.
+	var canonical map[string]interface{}
+	require.NoError(t, json.Unmarshal(v.Canonical, &canonical))
+	bz, err := json.MarshalIndent(canonical, "", "\t")
+	require.NoError(t, err)
+	formatted := v
+	formatted.Canonical = bz
+	assert.NoError(t, formatted.Verify())
+
+	// They are optional.
+	formatted.Canonical = nil
+	assert.NoError(t, formatted.Verify())
+
+	wrong := v
+	wrong.ChainID = "other-chain"
+	assert.Error(t, wrong.Verify())
+
+	wrong = v
+	wrong.Output = v.Output[:len(v.Output)-2] + "00"
+	wrong.Canonical = nil
+	assert.Error(t, wrong.Verify())
+
+	wrong = v
+	wrong.Input = json.RawMessage(`{"height": "1", "unknown": true}`)
+	assert.Error(t, wrong.Verify())
+
+	wrong = v
+	wrong.Input = json.RawMessage(`{"block_id": {"hash": "AQID"}}`)
+	assert.Error(t, wrong.Verify())
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	abci "github.com/tendermint/tendermint/abci/types"
 	"github.com/tendermint/tendermint/internal/jsontypes"
 	tmpubsub "github.com/tendermint/tendermint/internal/pubsub"
 	tmquery "github.com/tendermint/tendermint/internal/pubsub/query"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
 )
 
 // Reserved event types (alphabetically sorted).
@@ -18,12 +20,27 @@ const (
 	// after a block has been committed.
 	// These are also used by the tx indexer for async indexing.
 	// All of this data can be fetched through the rpc.
+	EventBlockProfileValue        = "BlockProfile"
 	EventNewBlockValue            = "NewBlock"
 	EventNewBlockHeaderValue      = "NewBlockHeader"
 	EventNewEvidenceValue         = "NewEvidence"
 	EventTxValue                  = "Tx"
+	EventTxExpiredValue           = "TxExpired"
 	EventValidatorSetUpdatesValue = "ValidatorSetUpdates"
 
+	// The CheckTx event is emitted by the mempool when the application first
+	// checks a transaction, with the events of its CheckTx response.
+	EventCheckTxValue = "CheckTx"
+
+	// The ValidatorSetUpdateScheduled event is emitted when the node
+	// broadcasts the externally computed validator updates of a validator
+	// authority.
+	EventValidatorSetUpdateScheduledValue = "ValidatorSetUpdateScheduled"
+
+	// The ForkDetected event is emitted when the peers report different
+	// blocks committed at the same height.
+	EventForkDetectedValue = "ForkDetected"
+
 	// Internal consensus events.
 	// These are used for testing the consensus state machine.
 	// They can also be used to build real-time consensus visualizers.
@@ -32,6 +49,7 @@ const (
 	// state sync mechanism between the consensus reactor and the blocksync reactor.
 	EventBlockSyncStatusValue = "BlockSyncStatus"
 	EventLockValue            = "Lock"
+	EventLockChangeValue      = "LockChange"
 	EventNewRoundValue        = "NewRound"
 	EventNewRoundStepValue    = "NewRoundStep"
 	EventPolkaValue           = "Polka"
@@ -85,6 +103,26 @@ var (
 			},
 		},
 	}
+
+	EventCheckTx = abci.Event{
+		Type: strings.Split(EventTypeKey, ".")[0],
+		Attributes: []abci.EventAttribute{
+			{
+				Key:   strings.Split(EventTypeKey, ".")[1],
+				Value: EventCheckTxValue,
+			},
+		},
+	}
+
+	EventTxExpired = abci.Event{
+		Type: strings.Split(EventTypeKey, ".")[0],
+		Attributes: []abci.EventAttribute{
+			{
+				Key:   strings.Split(EventTypeKey, ".")[1],
+				Value: EventTxExpiredValue,
+			},
+		},
+	}
 )
 
 // ENCODING / DECODING
@@ -93,8 +131,12 @@ var (
 type TMEventData interface{}
 
 func init() {
+	jsontypes.MustRegister(EventDataBlockProfile{})
 	jsontypes.MustRegister(EventDataBlockSyncStatus{})
+	jsontypes.MustRegister(EventDataCheckTx{})
 	jsontypes.MustRegister(EventDataCompleteProposal{})
+	jsontypes.MustRegister(EventDataForkDetected{})
+	jsontypes.MustRegister(EventDataLockChange{})
 	jsontypes.MustRegister(EventDataNewBlock{})
 	jsontypes.MustRegister(EventDataNewBlockHeader{})
 	jsontypes.MustRegister(EventDataNewEvidence{})
@@ -102,7 +144,9 @@ func init() {
 	jsontypes.MustRegister(EventDataRoundState{})
 	jsontypes.MustRegister(EventDataStateSyncStatus{})
 	jsontypes.MustRegister(EventDataTx{})
+	jsontypes.MustRegister(EventDataTxExpired{})
 	jsontypes.MustRegister(EventDataValidatorSetUpdates{})
+	jsontypes.MustRegister(EventDataValidatorSetUpdateScheduled{})
 	jsontypes.MustRegister(EventDataVote{})
 	jsontypes.MustRegister(EventDataString(""))
 }
@@ -149,6 +193,44 @@ type EventDataTx struct {
 // TypeTag implements the required method of jsontypes.Tagged.
 func (EventDataTx) TypeTag() string { return "tendermint/event/Tx" }
 
+// EventDataCheckTx is emitted when the application first checks a
+// transaction entering the mempool, whether it is accepted or not. Its
+// events are those of the CheckTx response, e.g. to classify the
+// transaction before it is included in a block.
+type EventDataCheckTx struct {
+	Tx Tx `json:"tx"`
+	// Height is the height of the last block when the transaction was checked.
+	Height int64                `json:"height,string"`
+	Result abci.ResponseCheckTx `json:"result"`
+}
+
+// TypeTag implements the required method of jsontypes.Tagged.
+func (EventDataCheckTx) TypeTag() string { return "tendermint/event/CheckTx" }
+
+// Reasons of EventDataTxExpired.
+const (
+	// The transaction was not included within mempool.ttl-num-blocks blocks.
+	TxExpiredReasonNumBlocks = "ttl_num_blocks"
+	// The transaction was not included within mempool.ttl-duration.
+	TxExpiredReasonDuration = "ttl_duration"
+)
+
+// EventDataTxExpired is emitted when a transaction is evicted from the
+// mempool because it was not included in a block within its TTL.
+type EventDataTxExpired struct {
+	Tx Tx `json:"tx"`
+	// Height is the height of the block after which the transaction expired.
+	Height int64  `json:"height,string"`
+	Reason string `json:"reason"`
+
+	// The height and time at which the transaction entered the mempool.
+	InsertedHeight int64     `json:"inserted_height,string"`
+	InsertedTime   time.Time `json:"inserted_time"`
+}
+
+// TypeTag implements the required method of jsontypes.Tagged.
+func (EventDataTxExpired) TypeTag() string { return "tendermint/event/TxExpired" }
+
 // NOTE: This goes into the replay WAL
 type EventDataRoundState struct {
 	Height int64  `json:"height"`
@@ -205,6 +287,19 @@ type EventDataValidatorSetUpdates struct {
 // TypeTag implements the required method of jsontypes.Tagged.
 func (EventDataValidatorSetUpdates) TypeTag() string { return "tendermint/event/ValidatorSetUpdates" }
 
+// EventDataValidatorSetUpdateScheduled records validator updates broadcast
+// for the validator authority, for auditing.
+type EventDataValidatorSetUpdateScheduled struct {
+	EffectiveHeight  int64        `json:"effective_height"`
+	ValidatorUpdates []*Validator `json:"validator_updates"`
+	Authority        Address      `json:"authority"`
+}
+
+// TypeTag implements the required method of jsontypes.Tagged.
+func (EventDataValidatorSetUpdateScheduled) TypeTag() string {
+	return "tendermint/event/ValidatorSetUpdateScheduled"
+}
+
 // EventDataBlockSyncStatus shows the fastsync status and the
 // height when the node state sync mechanism changes.
 type EventDataBlockSyncStatus struct {
@@ -225,6 +320,105 @@ type EventDataStateSyncStatus struct {
 // TypeTag implements the required method of jsontypes.Tagged.
 func (EventDataStateSyncStatus) TypeTag() string { return "tendermint/event/StateSyncStatus" }
 
+// EventDataBlockProfile is a per-height breakdown of the time spent
+// reaching consensus on, executing, and storing a block. Consensus phases
+// are measured in the round in which the block was committed, and are zero
+// when the block was not committed through consensus (e.g. during
+// blocksync or replay).
+type EventDataBlockProfile struct {
+	Height int64 `json:"height"`
+	Round  int32 `json:"round"`
+
+	// Time spent in the propose step before moving to prevote.
+	ProposalWait time.Duration `json:"proposal_wait"`
+	// Time from entering the prevote step until +2/3 of the prevotes agreed
+	// on a block or nil; zero if they did before the step.
+	PrevoteQuorum time.Duration `json:"prevote_quorum"`
+	// Time from entering the precommit step until +2/3 of the precommits
+	// agreed on the block; zero if they did before the step.
+	PrecommitQuorum time.Duration `json:"precommit_quorum"`
+
+	BeginBlock time.Duration `json:"begin_block"`
+	DeliverTxs time.Duration `json:"deliver_txs"`
+	EndBlock   time.Duration `json:"end_block"`
+	Commit     time.Duration `json:"commit"`
+	// Time spent persisting the ABCI responses and the new state.
+	SaveState time.Duration `json:"save_state"`
+
+	// Time spent indexing the block and its transactions. This is only set
+	// on profiles stored by the indexer, as indexing completes after the
+	// profile event is published.
+	Indexing time.Duration `json:"indexing"`
+}
+
+// TypeTag implements the required method of jsontypes.Tagged.
+func (EventDataBlockProfile) TypeTag() string { return "tendermint/event/BlockProfile" }
+
+// EventDataForkDetected is emitted when the peers report different blocks
+// committed at the same height, i.e. the chain forked or some peers run
+// another network with the same chain ID. The blocks are identified by their
+// part set headers, which the peers report as they commit them. It is emitted
+// again as more peers report a block at that height.
+type EventDataForkDetected struct {
+	Height   int64        `json:"height,string"`
+	Branches []ForkBranch `json:"branches"`
+}
+
+// ForkBranch is a block committed at the height of a fork, with the peers
+// which committed it.
+type ForkBranch struct {
+	BlockParts PartSetHeader `json:"block_parts"`
+	Peers      []NodeID      `json:"peers"`
+	// Local reports whether the node committed this block.
+	Local bool `json:"local"`
+	// Majority reports whether more peers, counting the node, committed this
+	// block than any other.
+	Majority bool `json:"majority"`
+}
+
+// TypeTag implements the required method of jsontypes.Tagged.
+func (EventDataForkDetected) TypeTag() string { return "tendermint/event/ForkDetected" }
+
+// Kinds of EventDataLockChange.
+const (
+	LockChangeLock       = "lock"
+	LockChangeRelock     = "relock"
+	LockChangeUnlock     = "unlock"
+	LockChangeValidBlock = "valid_block"
+)
+
+// Reasons of EventDataLockChange.
+const (
+	// +2/3 prevoted for the block in the precommit step.
+	LockChangeReasonPolka = "polka"
+	// +2/3 prevoted for nil in the precommit step.
+	LockChangeReasonNilPolka = "nil_polka"
+	// +2/3 prevoted in the precommit step for a block we do not have.
+	LockChangeReasonUnknownBlockPolka = "unknown_block_polka"
+	// +2/3 prevoted for another block in a round after the locked round.
+	LockChangeReasonLaterPolka = "later_polka"
+	// The proposal block that +2/3 prevoted for was completed.
+	LockChangeReasonCompleteProposal = "complete_proposal"
+)
+
+// EventDataLockChange is emitted when the consensus state machine locks on a
+// block, releases its lock, or updates its valid block. LockedRound and
+// ValidRound are those after the change.
+type EventDataLockChange struct {
+	Height    int64            `json:"height,string"`
+	Round     int32            `json:"round"`
+	Kind      string           `json:"kind"`
+	Reason    string           `json:"reason"`
+	BlockHash tmbytes.HexBytes `json:"block_hash"`
+
+	LockedRound int32     `json:"locked_round"`
+	ValidRound  int32     `json:"valid_round"`
+	Time        time.Time `json:"time"`
+}
+
+// TypeTag implements the required method of jsontypes.Tagged.
+func (EventDataLockChange) TypeTag() string { return "tendermint/event/LockChange" }
+
 // PUBSUB
 
 const (
@@ -246,8 +440,12 @@ const (
 )
 
 var (
+	EventQueryBlockProfile        = QueryForEvent(EventBlockProfileValue)
+	EventQueryCheckTx             = QueryForEvent(EventCheckTxValue)
 	EventQueryCompleteProposal    = QueryForEvent(EventCompleteProposalValue)
+	EventQueryForkDetected        = QueryForEvent(EventForkDetectedValue)
 	EventQueryLock                = QueryForEvent(EventLockValue)
+	EventQueryLockChange          = QueryForEvent(EventLockChangeValue)
 	EventQueryNewBlock            = QueryForEvent(EventNewBlockValue)
 	EventQueryNewBlockHeader      = QueryForEvent(EventNewBlockHeaderValue)
 	EventQueryNewEvidence         = QueryForEvent(EventNewEvidenceValue)
@@ -258,12 +456,15 @@ var (
 	EventQueryTimeoutPropose      = QueryForEvent(EventTimeoutProposeValue)
 	EventQueryTimeoutWait         = QueryForEvent(EventTimeoutWaitValue)
 	EventQueryTx                  = QueryForEvent(EventTxValue)
+	EventQueryTxExpired           = QueryForEvent(EventTxExpiredValue)
 	EventQueryUnlock              = QueryForEvent(EventUnlockValue)
 	EventQueryValidatorSetUpdates = QueryForEvent(EventValidatorSetUpdatesValue)
 	EventQueryValidBlock          = QueryForEvent(EventValidBlockValue)
 	EventQueryVote                = QueryForEvent(EventVoteValue)
 	EventQueryBlockSyncStatus     = QueryForEvent(EventBlockSyncStatusValue)
 	EventQueryStateSyncStatus     = QueryForEvent(EventStateSyncStatusValue)
+
+	EventQueryValidatorSetUpdateScheduled = QueryForEvent(EventValidatorSetUpdateScheduledValue)
 )
 
 func EventQueryTxFor(tx Tx) tmpubsub.Query {
@@ -281,6 +482,7 @@ type BlockEventPublisher interface {
 	PublishEventNewEvidence(ctx context.Context, evidence EventDataNewEvidence) error
 	PublishEventTx(context.Context, EventDataTx) error
 	PublishEventValidatorSetUpdates(context.Context, EventDataValidatorSetUpdates) error
+	PublishEventBlockProfile(context.Context, EventDataBlockProfile) error
 }
 
 type TxEventPublisher interface {
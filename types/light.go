@@ -24,6 +24,40 @@ type LightClientInfo struct {
 	TrustedBlockExpired bool `json:"trusted_block_expired"`
 }
 
+// WitnessTipStatus is how the latest block of a witness of a light client
+// compares with the verified latest block of its primary.
+type WitnessTipStatus string
+
+const (
+	// WitnessTipAgrees means that the witness is within the height tolerance
+	// and has the verified block at the lowest of both heights.
+	WitnessTipAgrees WitnessTipStatus = "agrees"
+	// WitnessTipConflicting means that the witness is within the height
+	// tolerance but has another block at the lowest of both heights.
+	WitnessTipConflicting WitnessTipStatus = "conflicting"
+	// WitnessTipLagging means that the witness is behind by more than the
+	// height tolerance.
+	WitnessTipLagging WitnessTipStatus = "lagging"
+	// WitnessTipAhead means that the witness is ahead by more than the height
+	// tolerance, i.e. the primary is stale or was rolled back.
+	WitnessTipAhead WitnessTipStatus = "ahead"
+	// WitnessTipUnavailable means that the latest block of the witness could
+	// not be fetched.
+	WitnessTipUnavailable WitnessTipStatus = "unavailable"
+)
+
+// WitnessTip is the latest block of a witness of a light client, compared
+// with the verified latest block of its primary.
+type WitnessTip struct {
+	WitnessID    string           `json:"witness_id"`
+	Status       WitnessTipStatus `json:"status"`
+	LatestHeight int64            `json:"latest_height,string"`
+	// Hash is the hash of the block of the witness at the lowest of both
+	// heights, if they were compared.
+	Hash  tbytes.HexBytes `json:"hash,omitempty"`
+	Error string          `json:"error,omitempty"`
+}
+
 // LightBlock is a SignedHeader and a ValidatorSet.
 // It is the basis of the light client
 type LightBlock struct {
@@ -51,6 +51,10 @@ func (e ErrPreCheck) Error() string {
 	return e.Reason.Error()
 }
 
+func (e ErrPreCheck) Unwrap() error {
+	return e.Reason
+}
+
 // IsPreCheckError returns true if err is due to pre check failure.
 func IsPreCheckError(err error) bool {
 	return errors.As(err, &ErrPreCheck{})
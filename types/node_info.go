@@ -50,6 +50,22 @@ type NodeInfo struct {
 	// ASCIIText fields
 	Moniker string        `json:"moniker"` // arbitrary moniker
 	Other   NodeInfoOther `json:"other"`   // other application specific data
+
+	// ChannelLimits are the largest messages this node accepts on its
+	// channels. Peers must not send larger messages on these channels. A
+	// channel without a limit accepts messages of the default maximum size.
+	ChannelLimits []ChannelLimit `json:"channel_limits,omitempty"`
+
+	// ValidatorAttestation is the attestation of the validator running the
+	// node, if it publishes one.
+	ValidatorAttestation *ValidatorAttestation `json:"validator_attestation,omitempty"`
+}
+
+// ChannelLimit is the size in bytes of the largest message a node accepts on
+// one of its channels.
+type ChannelLimit struct {
+	ChannelID  uint16 `json:"channel_id"`
+	MaxMsgSize uint32 `json:"max_msg_size"`
 }
 
 // NodeInfoOther is the misc. applcation specific data
@@ -101,6 +117,22 @@ func (info NodeInfo) Validate() error {
 		channels[ch] = struct{}{}
 	}
 
+	// Validate ChannelLimits - they must be positive, and declared once per
+	// channel of the node.
+	limits := make(map[uint16]struct{}, len(info.ChannelLimits))
+	for _, limit := range info.ChannelLimits {
+		if _, ok := channels[byte(limit.ChannelID)]; limit.ChannelID > 0xff || !ok {
+			return fmt.Errorf("info.ChannelLimits contains unknown channel id %v", limit.ChannelID)
+		}
+		if _, ok := limits[limit.ChannelID]; ok {
+			return fmt.Errorf("info.ChannelLimits contains duplicate channel id %v", limit.ChannelID)
+		}
+		if limit.MaxMsgSize == 0 {
+			return fmt.Errorf("info.ChannelLimits has a zero limit for channel id %v", limit.ChannelID)
+		}
+		limits[limit.ChannelID] = struct{}{}
+	}
+
 	// Validate Moniker.
 	if !tmstrings.IsASCIIText(info.Moniker) || tmstrings.ASCIITrim(info.Moniker) == "" {
 		return fmt.Errorf("info.Moniker must be valid non-empty ASCII text without tabs, but got %v", info.Moniker)
@@ -120,6 +152,16 @@ func (info NodeInfo) Validate() error {
 		return fmt.Errorf("info.Other.RPCAddress=%v must be valid ASCII text without tabs", rpcAddr)
 	}
 
+	// Validate ValidatorAttestation, which must be encodable.
+	if att := info.ValidatorAttestation; att != nil {
+		if err := att.Verify(info.Network, info.NodeID); err != nil {
+			return fmt.Errorf("info.ValidatorAttestation is invalid: %w", err)
+		}
+		if _, err := att.ToProto(); err != nil {
+			return fmt.Errorf("info.ValidatorAttestation is invalid: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -171,6 +213,32 @@ func (info *NodeInfo) AddChannel(channel uint16) {
 	info.Channels = append(info.Channels, byte(channel))
 }
 
+// SetChannelLimit is used by the router when a channel is opened to declare
+// the largest message it accepts on the channel. It replaces any previous
+// limit of the channel.
+func (info *NodeInfo) SetChannelLimit(channel uint16, maxMsgSize uint32) {
+	// the limits may be shared with copies of the node info, so they are
+	// never modified in place.
+	limits := make([]ChannelLimit, 0, len(info.ChannelLimits)+1)
+	for _, limit := range info.ChannelLimits {
+		if limit.ChannelID != channel {
+			limits = append(limits, limit)
+		}
+	}
+	info.ChannelLimits = append(limits, ChannelLimit{ChannelID: channel, MaxMsgSize: maxMsgSize})
+}
+
+// MaxMsgSize returns the largest message accepted by the node on channel, or
+// false if the node declared no limit for it.
+func (info NodeInfo) MaxMsgSize(channel uint16) (uint32, bool) {
+	for _, limit := range info.ChannelLimits {
+		if limit.ChannelID == channel {
+			return limit.MaxMsgSize, true
+		}
+	}
+	return 0, false
+}
+
 func (info NodeInfo) Copy() NodeInfo {
 	return NodeInfo{
 		ProtocolVersion: info.ProtocolVersion,
@@ -181,6 +249,7 @@ func (info NodeInfo) Copy() NodeInfo {
 		Channels:        info.Channels,
 		Moniker:         info.Moniker,
 		Other:           info.Other,
+		ChannelLimits:   info.ChannelLimits,
 	}
 }
 
@@ -203,6 +272,16 @@ func (info NodeInfo) ToProto() *tmp2p.NodeInfo {
 		TxIndex:    info.Other.TxIndex,
 		RPCAddress: info.Other.RPCAddress,
 	}
+	for _, limit := range info.ChannelLimits {
+		dni.ChannelLimits = append(dni.ChannelLimits, tmp2p.ChannelLimit{
+			ChannelID:  uint32(limit.ChannelID),
+			MaxMsgSize: limit.MaxMsgSize,
+		})
+	}
+	if info.ValidatorAttestation != nil {
+		// The attestation of a valid NodeInfo is encodable.
+		dni.ValidatorAttestation, _ = info.ValidatorAttestation.ToProto()
+	}
 
 	return dni
 }
@@ -228,6 +307,22 @@ func NodeInfoFromProto(pb *tmp2p.NodeInfo) (NodeInfo, error) {
 			RPCAddress: pb.Other.RPCAddress,
 		},
 	}
+	for _, limit := range pb.ChannelLimits {
+		if limit.ChannelID > 0xff {
+			return NodeInfo{}, fmt.Errorf("invalid channel id %v in channel limits", limit.ChannelID)
+		}
+		dni.ChannelLimits = append(dni.ChannelLimits, ChannelLimit{
+			ChannelID:  uint16(limit.ChannelID),
+			MaxMsgSize: limit.MaxMsgSize,
+		})
+	}
+	if pb.ValidatorAttestation != nil {
+		att, err := ValidatorAttestationFromProto(pb.ValidatorAttestation)
+		if err != nil {
+			return NodeInfo{}, fmt.Errorf("invalid validator attestation: %w", err)
+		}
+		dni.ValidatorAttestation = att
+	}
 
 	return dni, nil
 }
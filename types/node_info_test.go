@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tendermint/tendermint/crypto/ed25519"
 	tmnet "github.com/tendermint/tendermint/libs/net"
+	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
 	"github.com/tendermint/tendermint/version"
 )
 
@@ -44,6 +45,22 @@ func TestNodeInfoValidate(t *testing.T) {
 		{"Duplicate Channel", func(ni *NodeInfo) { ni.Channels = dupChannels }, true},
 		{"Good Channels", func(ni *NodeInfo) { ni.Channels = ni.Channels[:5] }, false},
 
+		{"Unknown Channel Limit", func(ni *NodeInfo) {
+			ni.ChannelLimits = []ChannelLimit{{ChannelID: maxNumChannels, MaxMsgSize: 1}}
+		}, true},
+		{"Out of Range Channel Limit", func(ni *NodeInfo) {
+			ni.ChannelLimits = []ChannelLimit{{ChannelID: 0x100 + testCh, MaxMsgSize: 1}}
+		}, true},
+		{"Duplicate Channel Limit", func(ni *NodeInfo) {
+			ni.ChannelLimits = []ChannelLimit{{ChannelID: testCh, MaxMsgSize: 1}, {ChannelID: testCh, MaxMsgSize: 2}}
+		}, true},
+		{"Zero Channel Limit", func(ni *NodeInfo) {
+			ni.ChannelLimits = []ChannelLimit{{ChannelID: testCh}}
+		}, true},
+		{"Good Channel Limits", func(ni *NodeInfo) {
+			ni.ChannelLimits = []ChannelLimit{{ChannelID: testCh, MaxMsgSize: 1}, {ChannelID: 2, MaxMsgSize: 1024}}
+		}, false},
+
 		{"Invalid NetAddress", func(ni *NodeInfo) { ni.ListenAddr = "not-an-address" }, true},
 		{"Good NetAddress", func(ni *NodeInfo) { ni.ListenAddr = "0.0.0.0:26656" }, false},
 
@@ -174,6 +191,45 @@ func TestNodeInfoAddChannel(t *testing.T) {
 	require.Contains(t, nodeInfo.Channels, byte(0x02))
 }
 
+func TestNodeInfoSetChannelLimit(t *testing.T) {
+	nodeInfo := testNodeInfo(t, testNodeID(), "testing")
+	_, ok := nodeInfo.MaxMsgSize(testCh)
+	require.False(t, ok)
+
+	nodeInfo.SetChannelLimit(testCh, 1024)
+	nodeInfo.AddChannel(2)
+	nodeInfo.SetChannelLimit(2, 64)
+	copied := nodeInfo.Copy()
+
+	// setting a limit again replaces it, without changing the copies
+	nodeInfo.SetChannelLimit(testCh, 512)
+	size, ok := nodeInfo.MaxMsgSize(testCh)
+	require.True(t, ok)
+	require.EqualValues(t, 512, size)
+	size, ok = copied.MaxMsgSize(testCh)
+	require.True(t, ok)
+	require.EqualValues(t, 1024, size)
+	size, ok = nodeInfo.MaxMsgSize(2)
+	require.True(t, ok)
+	require.EqualValues(t, 64, size)
+	require.Len(t, nodeInfo.ChannelLimits, 2)
+	require.NoError(t, nodeInfo.Validate())
+
+	// the limits are exchanged in the handshake
+	pb := nodeInfo.ToProto()
+	bz, err := pb.Marshal()
+	require.NoError(t, err)
+	pb = new(tmp2p.NodeInfo)
+	require.NoError(t, pb.Unmarshal(bz))
+	decoded, err := NodeInfoFromProto(pb)
+	require.NoError(t, err)
+	require.Equal(t, nodeInfo.ChannelLimits, decoded.ChannelLimits)
+
+	pb.ChannelLimits[0].ChannelID = 0x100 + testCh
+	_, err = NodeInfoFromProto(pb)
+	require.Error(t, err)
+}
+
 func TestParseAddressString(t *testing.T) {
 	testCases := []struct {
 		name     string
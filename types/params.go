@@ -3,6 +3,8 @@ package types
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"sort"
 	"time"
 
 	"github.com/tendermint/tendermint/crypto/ed25519"
@@ -28,6 +30,10 @@ const (
 	ABCIPubKeyTypeSr25519   = sr25519.KeyType
 )
 
+// featureNameRegexp restricts feature names to lowercase identifiers, e.g.
+// "vote_extensions".
+var featureNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_.]{0,63}$`)
+
 var ABCIPubKeyTypesToNames = map[string]string{
 	ABCIPubKeyTypeEd25519:   ed25519.PubKeyName,
 	ABCIPubKeyTypeSecp256k1: secp256k1.PubKeyName,
@@ -41,6 +47,7 @@ type ConsensusParams struct {
 	Evidence  EvidenceParams  `json:"evidence"`
 	Validator ValidatorParams `json:"validator"`
 	Version   VersionParams   `json:"version"`
+	Feature   FeatureParams   `json:"feature"`
 }
 
 // HashedParams is a subset of ConsensusParams.
@@ -65,16 +72,43 @@ type EvidenceParams struct {
 	MaxBytes        int64         `json:"max_bytes,string"`
 }
 
-// ValidatorParams restrict the public key types validators can use.
+// ValidatorParams restrict the public key types validators can use, and how
+// fast the validator set can change, so that the light clients trusting a
+// validator set can still verify the headers signed by the next ones.
 // NOTE: uses ABCI pubkey naming, not Amino names.
 type ValidatorParams struct {
 	PubKeyTypes []string `json:"pub_key_types"`
+
+	// MaxPowerChangePercent caps the change of the voting power of any
+	// validator in a block, in percent of the total voting power of the
+	// validator set before the change. 0 means no cap.
+	MaxPowerChangePercent uint32 `json:"max_power_change_percent,omitempty"`
+
+	// MaxChurnPercent caps the sum of the changes of the voting powers of the
+	// validators in a block, in percent of the total voting power of the
+	// validator set before the changes. 0 means no cap.
+	MaxChurnPercent uint32 `json:"max_churn_percent,omitempty"`
 }
 
 type VersionParams struct {
 	AppVersion uint64 `json:"app_version,string"`
 }
 
+// FeatureParams schedule the activation of protocol features at specific
+// heights, so that networks can enable new behavior without coordinating
+// binary upgrades. A feature is enabled from its activation height onwards;
+// features without an activation height are disabled. Once reached, an
+// activation height can no longer be changed.
+type FeatureParams struct {
+	ActivationHeights map[string]int64 `json:"activation_heights,omitempty"`
+}
+
+// IsEnabled returns true if the named feature is enabled at the given height.
+func (p FeatureParams) IsEnabled(name string, height int64) bool {
+	activation, ok := p.ActivationHeights[name]
+	return ok && height >= activation
+}
+
 // DefaultConsensusParams returns a default ConsensusParams.
 func DefaultConsensusParams() *ConsensusParams {
 	return &ConsensusParams{
@@ -82,6 +116,7 @@ func DefaultConsensusParams() *ConsensusParams {
 		Evidence:  DefaultEvidenceParams(),
 		Validator: DefaultValidatorParams(),
 		Version:   DefaultVersionParams(),
+		Feature:   DefaultFeatureParams(),
 	}
 }
 
@@ -116,6 +151,12 @@ func DefaultVersionParams() VersionParams {
 	}
 }
 
+// DefaultFeatureParams returns a default FeatureParams, which enables no
+// features.
+func DefaultFeatureParams() FeatureParams {
+	return FeatureParams{}
+}
+
 func (val *ValidatorParams) IsValidPubkeyType(pubkeyType string) bool {
 	for i := 0; i < len(val.PubKeyTypes); i++ {
 		if val.PubKeyTypes[i] == pubkeyType {
@@ -175,11 +216,71 @@ func (params ConsensusParams) ValidateConsensusParams() error {
 		}
 	}
 
+	if params.Validator.MaxPowerChangePercent > 100 {
+		return fmt.Errorf("validator.MaxPowerChangePercent must be at most 100. Got %d",
+			params.Validator.MaxPowerChangePercent)
+	}
+
+	if params.Validator.MaxChurnPercent > 100 {
+		return fmt.Errorf("validator.MaxChurnPercent must be at most 100. Got %d",
+			params.Validator.MaxChurnPercent)
+	}
+
+	for name, height := range params.Feature.ActivationHeights {
+		if !featureNameRegexp.MatchString(name) {
+			return fmt.Errorf("feature.ActivationHeights has an invalid feature name %q", name)
+		}
+		if height <= 0 {
+			return fmt.Errorf("feature.ActivationHeights[%s] must be greater than 0. Got %d",
+				name, height)
+		}
+	}
+
+	return nil
+}
+
+// ValidateUpdate validates the updates returned by the application at height
+// h against the current params. Features activated at or below h may not be
+// rescheduled or removed, and new activation heights must be greater than h,
+// as the updated params only apply from h+1.
+func (params ConsensusParams) ValidateUpdate(updated *tmproto.ConsensusParams, h int64) error {
+	if updated == nil || updated.Feature == nil {
+		return nil
+	}
+
+	next := make(map[string]int64, len(updated.Feature.Activations))
+	for _, activation := range updated.Feature.Activations {
+		if _, ok := next[activation.Name]; ok {
+			return fmt.Errorf("feature %q is scheduled more than once", activation.Name)
+		}
+		next[activation.Name] = activation.Height
+	}
+
+	for name, height := range params.Feature.ActivationHeights {
+		if height > h {
+			continue
+		}
+		if nextHeight, ok := next[name]; !ok || nextHeight != height {
+			return fmt.Errorf("feature %q was activated at height %d and cannot be changed", name, height)
+		}
+	}
+
+	for name, height := range next {
+		if current, ok := params.Feature.ActivationHeights[name]; ok && current == height {
+			continue
+		}
+		if height <= h {
+			return fmt.Errorf("feature %q must be scheduled after height %d. Got %d", name, h, height)
+		}
+	}
+
 	return nil
 }
 
 // Hash returns a hash of a subset of the parameters to store in the block header.
-// Only the Block.MaxBytes and Block.MaxGas are included in the hash.
+// Only the Block.MaxBytes and Block.MaxGas are included in the hash, along
+// with the feature activation heights if any are scheduled, so that block
+// validation rejects blocks produced with a different feature schedule.
 // This allows the ConsensusParams to evolve more without breaking the block
 // protocol. No need for a Merkle tree here, just a small struct to hash.
 func (params ConsensusParams) HashConsensusParams() []byte {
@@ -199,13 +300,59 @@ func (params ConsensusParams) HashConsensusParams() []byte {
 	if err != nil {
 		panic(err)
 	}
+
+	if len(params.Feature.ActivationHeights) > 0 {
+		bz, err = params.Feature.toProto().Marshal()
+		if err != nil {
+			panic(err)
+		}
+		if _, err = hasher.Write(bz); err != nil {
+			panic(err)
+		}
+	}
+
 	return hasher.Sum(nil)
 }
 
 func (params *ConsensusParams) Equals(params2 *ConsensusParams) bool {
 	return params.Block == params2.Block &&
 		params.Evidence == params2.Evidence &&
-		tmstrings.StringSliceEqual(params.Validator.PubKeyTypes, params2.Validator.PubKeyTypes)
+		tmstrings.StringSliceEqual(params.Validator.PubKeyTypes, params2.Validator.PubKeyTypes) &&
+		params.Validator.MaxPowerChangePercent == params2.Validator.MaxPowerChangePercent &&
+		params.Validator.MaxChurnPercent == params2.Validator.MaxChurnPercent &&
+		params.Feature.equals(params2.Feature)
+}
+
+func (p FeatureParams) equals(p2 FeatureParams) bool {
+	if len(p.ActivationHeights) != len(p2.ActivationHeights) {
+		return false
+	}
+	for name, height := range p.ActivationHeights {
+		if h2, ok := p2.ActivationHeights[name]; !ok || h2 != height {
+			return false
+		}
+	}
+	return true
+}
+
+func (p FeatureParams) toProto() *tmproto.FeatureParams {
+	activations := make([]tmproto.FeatureActivation, 0, len(p.ActivationHeights))
+	for name, height := range p.ActivationHeights {
+		activations = append(activations, tmproto.FeatureActivation{Name: name, Height: height})
+	}
+	sort.Slice(activations, func(i, j int) bool { return activations[i].Name < activations[j].Name })
+	return &tmproto.FeatureParams{Activations: activations}
+}
+
+func featureParamsFromProto(pb *tmproto.FeatureParams) FeatureParams {
+	if pb == nil || len(pb.Activations) == 0 {
+		return FeatureParams{}
+	}
+	heights := make(map[string]int64, len(pb.Activations))
+	for _, activation := range pb.Activations {
+		heights[activation.Name] = activation.Height
+	}
+	return FeatureParams{ActivationHeights: heights}
 }
 
 // Update returns a copy of the params with updates from the non-zero fields of p2.
@@ -231,10 +378,15 @@ func (params ConsensusParams) UpdateConsensusParams(params2 *tmproto.ConsensusPa
 		// Copy params2.Validator.PubkeyTypes, and set result's value to the copy.
 		// This avoids having to initialize the slice to 0 values, and then write to it again.
 		res.Validator.PubKeyTypes = append([]string{}, params2.Validator.PubKeyTypes...)
+		res.Validator.MaxPowerChangePercent = params2.Validator.MaxPowerChangePercent
+		res.Validator.MaxChurnPercent = params2.Validator.MaxChurnPercent
 	}
 	if params2.Version != nil {
 		res.Version.AppVersion = params2.Version.AppVersion
 	}
+	if params2.Feature != nil {
+		res.Feature = featureParamsFromProto(params2.Feature)
+	}
 	return res
 }
 
@@ -250,11 +402,14 @@ func (params *ConsensusParams) ToProto() tmproto.ConsensusParams {
 			MaxBytes:        params.Evidence.MaxBytes,
 		},
 		Validator: &tmproto.ValidatorParams{
-			PubKeyTypes: params.Validator.PubKeyTypes,
+			PubKeyTypes:           params.Validator.PubKeyTypes,
+			MaxPowerChangePercent: params.Validator.MaxPowerChangePercent,
+			MaxChurnPercent:       params.Validator.MaxChurnPercent,
 		},
 		Version: &tmproto.VersionParams{
 			AppVersion: params.Version.AppVersion,
 		},
+		Feature: params.Feature.toProto(),
 	}
 }
 
@@ -270,10 +425,13 @@ func ConsensusParamsFromProto(pbParams tmproto.ConsensusParams) ConsensusParams
 			MaxBytes:        pbParams.Evidence.MaxBytes,
 		},
 		Validator: ValidatorParams{
-			PubKeyTypes: pbParams.Validator.PubKeyTypes,
+			PubKeyTypes:           pbParams.Validator.PubKeyTypes,
+			MaxPowerChangePercent: pbParams.Validator.MaxPowerChangePercent,
+			MaxChurnPercent:       pbParams.Validator.MaxChurnPercent,
 		},
 		Version: VersionParams{
 			AppVersion: pbParams.Version.AppVersion,
 		},
+		Feature: featureParamsFromProto(pbParams.Feature),
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
 )
@@ -186,3 +187,86 @@ func TestProto(t *testing.T) {
 
 	}
 }
+
+func TestConsensusParamsFeatures(t *testing.T) {
+	params := makeParams(1, 2, 3, 0, valEd25519)
+	params.Feature.ActivationHeights = map[string]int64{"vote_extensions": 10}
+	require.NoError(t, params.ValidateConsensusParams())
+
+	assert.False(t, params.Feature.IsEnabled("vote_extensions", 9))
+	assert.True(t, params.Feature.IsEnabled("vote_extensions", 10))
+	assert.False(t, params.Feature.IsEnabled("pbts", 100))
+
+	assert.Equal(t, params, ConsensusParamsFromProto(params.ToProto()))
+
+	// the feature schedule is committed to in the header's consensus hash
+	noFeatures := makeParams(1, 2, 3, 0, valEd25519)
+	assert.NotEqual(t, noFeatures.HashConsensusParams(), params.HashConsensusParams())
+	rescheduled := makeParams(1, 2, 3, 0, valEd25519)
+	rescheduled.Feature.ActivationHeights = map[string]int64{"vote_extensions": 11}
+	assert.NotEqual(t, rescheduled.HashConsensusParams(), params.HashConsensusParams())
+
+	invalid := params
+	invalid.Feature.ActivationHeights = map[string]int64{"Bad Name": 1}
+	assert.Error(t, invalid.ValidateConsensusParams())
+	invalid.Feature.ActivationHeights = map[string]int64{"pbts": 0}
+	assert.Error(t, invalid.ValidateConsensusParams())
+
+	update := func(activations ...tmproto.FeatureActivation) *tmproto.ConsensusParams {
+		return &tmproto.ConsensusParams{Feature: &tmproto.FeatureParams{Activations: activations}}
+	}
+
+	testCases := map[string]struct {
+		height  int64
+		updates *tmproto.ConsensusParams
+		valid   bool
+	}{
+		"no feature updates":           {12, &tmproto.ConsensusParams{}, true},
+		"schedule new feature":         {12, update(tmproto.FeatureActivation{Name: "vote_extensions", Height: 10}, tmproto.FeatureActivation{Name: "pbts", Height: 13}), true},
+		"schedule in the past":         {12, update(tmproto.FeatureActivation{Name: "vote_extensions", Height: 10}, tmproto.FeatureActivation{Name: "pbts", Height: 12}), false},
+		"remove active feature":        {12, update(), false},
+		"reschedule active feature":    {12, update(tmproto.FeatureActivation{Name: "vote_extensions", Height: 20}), false},
+		"reschedule pending feature":   {5, update(tmproto.FeatureActivation{Name: "vote_extensions", Height: 20}), true},
+		"remove pending feature":       {5, update(), true},
+		"duplicate feature activation": {5, update(tmproto.FeatureActivation{Name: "pbts", Height: 20}, tmproto.FeatureActivation{Name: "pbts", Height: 30}), false},
+	}
+	for name, tc := range testCases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := params.ValidateUpdate(tc.updates, tc.height)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestConsensusParamsValidatorChurn(t *testing.T) {
+	params := makeParams(1, 2, 3, 0, valEd25519)
+	params.Validator.MaxPowerChangePercent = 10
+	params.Validator.MaxChurnPercent = 25
+	require.NoError(t, params.ValidateConsensusParams())
+
+	// The caps survive the proto round trip and the updates.
+	pbParams := params.ToProto()
+	assert.Equal(t, params, ConsensusParamsFromProto(pbParams))
+	bz, err := pbParams.Marshal()
+	require.NoError(t, err)
+	var decoded tmproto.ConsensusParams
+	require.NoError(t, decoded.Unmarshal(bz))
+	assert.True(t, pbParams.Equal(decoded))
+
+	updated := makeParams(1, 2, 3, 0, valEd25519).UpdateConsensusParams(&pbParams)
+	assert.True(t, params.Equals(&updated))
+	updated.Validator.MaxChurnPercent = 0
+	assert.False(t, params.Equals(&updated))
+
+	// The caps are percentages.
+	params.Validator.MaxPowerChangePercent = 101
+	assert.Error(t, params.ValidateConsensusParams())
+	params.Validator.MaxPowerChangePercent = 100
+	params.Validator.MaxChurnPercent = 101
+	assert.Error(t, params.ValidateConsensusParams())
+}
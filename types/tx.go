@@ -41,6 +41,24 @@ func (txs Txs) Hash() []byte {
 	return merkle.HashFromByteSlices(txBzs)
 }
 
+// ToSliceOfBytes converts txs to a slice of byte slices, as used by ABCI.
+func (txs Txs) ToSliceOfBytes() [][]byte {
+	txBzs := make([][]byte, len(txs))
+	for i := 0; i < len(txs); i++ {
+		txBzs[i] = txs[i]
+	}
+	return txBzs
+}
+
+// ToTxs converts a slice of byte slices, as used by ABCI, to Txs.
+func ToTxs(txBzs [][]byte) Txs {
+	txs := make(Txs, len(txBzs))
+	for i := 0; i < len(txBzs); i++ {
+		txs[i] = txBzs[i]
+	}
+	return txs
+}
+
 // Index returns the index of this transaction in the list, or -1 if not found
 func (txs Txs) Index(tx Tx) int {
 	for i := range txs {
@@ -110,6 +128,63 @@ func (tp TxProof) Validate(dataHash []byte) error {
 	return nil
 }
 
+// MultiProof returns a proof of the presence of the transactions at the given
+// indices.
+func (txs Txs) MultiProof(indices []int) (TxMultiProof, error) {
+	bzs := make([][]byte, len(txs))
+	for i := 0; i < len(txs); i++ {
+		bzs[i] = txs[i].Hash()
+	}
+	idxs := make([]int64, len(indices))
+	for i, index := range indices {
+		idxs[i] = int64(index)
+	}
+	root, proof, err := merkle.MultiProofFromByteSlices(bzs, idxs)
+	if err != nil {
+		return TxMultiProof{}, err
+	}
+
+	data := make([]Tx, len(proof.Indices))
+	for i, index := range proof.Indices {
+		data[i] = txs[index]
+	}
+	return TxMultiProof{
+		RootHash: root,
+		Data:     data,
+		Proof:    *proof,
+	}, nil
+}
+
+// TxMultiProof represents a Merkle proof of the presence of several
+// transactions of a block in its Merkle tree. Data is ordered by index.
+type TxMultiProof struct {
+	RootHash tmbytes.HexBytes  `json:"root_hash"`
+	Data     []Tx              `json:"data"`
+	Proof    merkle.MultiProof `json:"proof"`
+}
+
+// Leaves returns the hash(tx) of each transaction, which are the leaves in the
+// merkle tree which this proof refers to.
+func (tp TxMultiProof) Leaves() [][]byte {
+	leaves := make([][]byte, len(tp.Data))
+	for i, tx := range tp.Data {
+		leaves[i] = tx.Hash()
+	}
+	return leaves
+}
+
+// Validate verifies the proof. It returns nil if the RootHash matches the dataHash argument,
+// and if the proof is internally consistent. Otherwise, it returns a sensible error.
+func (tp TxMultiProof) Validate(dataHash []byte) error {
+	if !bytes.Equal(dataHash, tp.RootHash) {
+		return errors.New("proof matches different data hash")
+	}
+	if err := tp.Proof.Verify(tp.RootHash, tp.Leaves()); err != nil {
+		return fmt.Errorf("proof is not internally consistent: %w", err)
+	}
+	return nil
+}
+
 func (tp TxProof) ToProto() tmproto.TxProof {
 
 	pbProof := tp.Proof.ToProto()
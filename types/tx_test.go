@@ -150,3 +150,21 @@ func assertBadProof(t *testing.T, root []byte, bad []byte, good TxProof) {
 		}
 	}
 }
+
+func TestValidTxMultiProof(t *testing.T) {
+	txs := makeTxs(20, 5)
+	root := txs.Hash()
+
+	proof, err := txs.MultiProof([]int{17, 3, 4})
+	require.NoError(t, err)
+	assert.EqualValues(t, root, proof.RootHash)
+	assert.Equal(t, []Tx{txs[3], txs[4], txs[17]}, proof.Data)
+	assert.NoError(t, proof.Validate(root))
+	assert.Error(t, proof.Validate([]byte("foobar")))
+
+	proof.Data[1] = Tx("foobar")
+	assert.Error(t, proof.Validate(root))
+
+	_, err = txs.MultiProof([]int{20})
+	assert.Error(t, err)
+}
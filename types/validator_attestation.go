@@ -0,0 +1,106 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/tendermint/tendermint/crypto"
+	ce "github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/internal/jsontypes"
+	tmp2p "github.com/tendermint/tendermint/proto/tendermint/p2p"
+)
+
+// validatorAttestationPrefix starts the sign bytes of a validator
+// attestation. The sign bytes of votes and proposals start with a field tag,
+// which no ASCII letter is, so an attestation can't be replayed as either.
+const validatorAttestationPrefix = "tendermint/ValidatorAttestation"
+
+// ValidatorAttestation binds a node to a validator: the validator signs the
+// ID of the node with its consensus key, for a chain. A node publishes it in
+// its NodeInfo, whose node ID is authenticated by the handshake, so that its
+// peers learn which validator runs it.
+type ValidatorAttestation struct {
+	PubKey    crypto.PubKey `json:"pub_key"`
+	Signature []byte        `json:"signature"`
+}
+
+// ValidatorAttestationSignBytes returns the bytes signed by a validator to
+// attest that it runs the node nodeID on the chain chainID.
+func ValidatorAttestationSignBytes(chainID string, nodeID NodeID) []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s", validatorAttestationPrefix, chainID, nodeID))
+}
+
+// SignValidatorAttestation returns the attestation that the validator with
+// the consensus key privKey runs the node nodeID on the chain chainID.
+func SignValidatorAttestation(chainID string, nodeID NodeID, privKey crypto.PrivKey) (*ValidatorAttestation, error) {
+	sig, err := privKey.Sign(ValidatorAttestationSignBytes(chainID, nodeID))
+	if err != nil {
+		return nil, err
+	}
+	return &ValidatorAttestation{PubKey: privKey.PubKey(), Signature: sig}, nil
+}
+
+// Address returns the address of the attesting validator.
+func (a *ValidatorAttestation) Address() Address {
+	return a.PubKey.Address()
+}
+
+// Verify checks that the attestation was signed for the node nodeID on the
+// chain chainID.
+func (a *ValidatorAttestation) Verify(chainID string, nodeID NodeID) error {
+	if a.PubKey == nil {
+		return errors.New("missing public key")
+	}
+	if len(a.Signature) == 0 || len(a.Signature) > MaxSignatureSize {
+		return fmt.Errorf("invalid signature size %d", len(a.Signature))
+	}
+	if !a.PubKey.VerifySignature(ValidatorAttestationSignBytes(chainID, nodeID), a.Signature) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (a *ValidatorAttestation) ToProto() (*tmp2p.ValidatorAttestation, error) {
+	pk, err := ce.PubKeyToProto(a.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &tmp2p.ValidatorAttestation{PubKey: pk, Signature: a.Signature}, nil
+}
+
+func ValidatorAttestationFromProto(pb *tmp2p.ValidatorAttestation) (*ValidatorAttestation, error) {
+	if pb == nil {
+		return nil, errors.New("nil validator attestation")
+	}
+	pk, err := ce.PubKeyFromProto(pb.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ValidatorAttestation{PubKey: pk, Signature: pb.Signature}, nil
+}
+
+type validatorAttestationJSON struct {
+	PubKey    json.RawMessage `json:"pub_key"`
+	Signature []byte          `json:"signature"`
+}
+
+func (a ValidatorAttestation) MarshalJSON() ([]byte, error) {
+	pk, err := jsontypes.Marshal(a.PubKey)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(validatorAttestationJSON{PubKey: pk, Signature: a.Signature})
+}
+
+func (a *ValidatorAttestation) UnmarshalJSON(data []byte) error {
+	var v validatorAttestationJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if err := jsontypes.Unmarshal(v.PubKey, &a.PubKey); err != nil {
+		return err
+	}
+	a.Signature = v.Signature
+	return nil
+}
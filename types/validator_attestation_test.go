@@ -0,0 +1,47 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto/ed25519"
+)
+
+func TestValidatorAttestation(t *testing.T) {
+	key := ed25519.GenPrivKey()
+	nodeID := NodeIDFromPubKey(ed25519.GenPrivKey().PubKey())
+
+	att, err := SignValidatorAttestation("test-chain", nodeID, key)
+	require.NoError(t, err)
+	require.Equal(t, key.PubKey().Address(), att.Address())
+	require.NoError(t, att.Verify("test-chain", nodeID))
+
+	// The attestation is bound to the node and the chain.
+	require.Error(t, att.Verify("other-chain", nodeID))
+	require.Error(t, att.Verify("test-chain", NodeIDFromPubKey(key.PubKey())))
+
+	pb, err := att.ToProto()
+	require.NoError(t, err)
+	fromProto, err := ValidatorAttestationFromProto(pb)
+	require.NoError(t, err)
+	require.Equal(t, att, fromProto)
+
+	bz, err := json.Marshal(att)
+	require.NoError(t, err)
+	var fromJSON ValidatorAttestation
+	require.NoError(t, json.Unmarshal(bz, &fromJSON))
+	require.Equal(t, *att, fromJSON)
+
+	// The attestation of a node info is verified, and survives its encoding.
+	info := testNodeInfoWithNetwork(t, nodeID, "node", "test-chain")
+	info.ValidatorAttestation = att
+	require.NoError(t, info.Validate())
+	decoded, err := NodeInfoFromProto(info.ToProto())
+	require.NoError(t, err)
+	require.Equal(t, att, decoded.ValidatorAttestation)
+
+	info.ValidatorAttestation = &ValidatorAttestation{PubKey: att.PubKey, Signature: []byte{1}}
+	require.Error(t, info.Validate())
+}
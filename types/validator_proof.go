@@ -0,0 +1,143 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/crypto/merkle"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+)
+
+// ValidatorProof is a Merkle proof of the presence of a validator in a
+// validator set, against the hash of the set: the validators hash, or next
+// validators hash, of a header. It carries everything needed to verify it
+// without a Tendermint implementation, e.g. by a bridge contract:
+//
+//	hash := SHA256(0x00 || Leaf)
+//	for i, aunt := range Aunts {
+//		if Left[i] {
+//			hash = SHA256(0x01 || aunt || hash)
+//		} else {
+//			hash = SHA256(0x01 || hash || aunt)
+//		}
+//	}
+//
+// and hash must equal the validators hash.
+type ValidatorProof struct {
+	Address     Address          `json:"address"`
+	PubKeyType  string           `json:"pub_key_type"`
+	PubKey      tmbytes.HexBytes `json:"pub_key"`
+	VotingPower int64            `json:"voting_power,string"`
+
+	// Leaf is the leaf of the validator in the Merkle tree: the protobuf
+	// encoding of a SimpleValidator, i.e. of its public key and voting power.
+	Leaf     tmbytes.HexBytes `json:"leaf"`
+	LeafHash tmbytes.HexBytes `json:"leaf_hash"`
+
+	Index int64 `json:"index,string"`
+	Total int64 `json:"total,string"`
+
+	// Aunts are the sibling hashes on the path from the leaf to the root,
+	// starting from the leaf, and Left reports whether each is the left
+	// sibling. The sides follow from Index and Total, and are given so that
+	// verifiers need not split the tree.
+	Aunts []tmbytes.HexBytes `json:"aunts"`
+	Left  []bool             `json:"left"`
+}
+
+// Proofs returns the hash of the set, and a proof of the presence of each of
+// its validators, in the order of the set.
+func (vals *ValidatorSet) Proofs() ([]byte, []ValidatorProof) {
+	bzs := make([][]byte, len(vals.Validators))
+	for i, val := range vals.Validators {
+		bzs[i] = val.Bytes()
+	}
+	root, proofs := merkle.ProofsFromByteSlices(bzs)
+
+	vps := make([]ValidatorProof, len(proofs))
+	for i, proof := range proofs {
+		val := vals.Validators[i]
+		aunts := make([]tmbytes.HexBytes, len(proof.Aunts))
+		for j, aunt := range proof.Aunts {
+			aunts[j] = aunt
+		}
+		vps[i] = ValidatorProof{
+			Address:     val.Address,
+			PubKeyType:  val.PubKey.Type(),
+			PubKey:      val.PubKey.Bytes(),
+			VotingPower: val.VotingPower,
+			Leaf:        bzs[i],
+			LeafHash:    proof.LeafHash,
+			Index:       proof.Index,
+			Total:       proof.Total,
+			Aunts:       aunts,
+			Left:        auntSides(proof.Index, proof.Total),
+		}
+	}
+	return root, vps
+}
+
+// Validate verifies the proof. It returns nil if the leaf encodes the
+// validator, and if the proof matches validatorsHash. Otherwise, it returns
+// a sensible error.
+func (vp ValidatorProof) Validate(validatorsHash []byte) error {
+	var sv tmproto.SimpleValidator
+	if err := sv.Unmarshal(vp.Leaf); err != nil {
+		return fmt.Errorf("invalid leaf: %w", err)
+	}
+	if sv.PubKey == nil {
+		return errors.New("leaf has no public key")
+	}
+	pk, err := encoding.PubKeyFromProto(*sv.PubKey)
+	if err != nil {
+		return fmt.Errorf("invalid leaf public key: %w", err)
+	}
+	switch {
+	case pk.Type() != vp.PubKeyType || !bytes.Equal(pk.Bytes(), vp.PubKey):
+		return errors.New("leaf does not match the public key")
+	case sv.VotingPower != vp.VotingPower:
+		return errors.New("leaf does not match the voting power")
+	case !bytes.Equal(pk.Address(), vp.Address):
+		return errors.New("address does not match the public key")
+	}
+
+	if len(vp.Left) != len(vp.Aunts) {
+		return fmt.Errorf("%d sides for %d aunts", len(vp.Left), len(vp.Aunts))
+	}
+	aunts := make([][]byte, len(vp.Aunts))
+	for i, aunt := range vp.Aunts {
+		aunts[i] = aunt
+	}
+	proof := merkle.Proof{Total: vp.Total, Index: vp.Index, LeafHash: vp.LeafHash, Aunts: aunts}
+	if err := proof.ValidateBasic(); err != nil {
+		return err
+	}
+	if err := proof.Verify(validatorsHash, vp.Leaf); err != nil {
+		return err
+	}
+	for i, left := range auntSides(vp.Index, vp.Total) {
+		if vp.Left[i] != left {
+			return fmt.Errorf("wrong side of aunt %d", i)
+		}
+	}
+	return nil
+}
+
+// auntSides returns, for the aunts of the leaf at index in a tree of total
+// leaves, starting from the leaf, whether each is the left sibling. The tree
+// is split as by the merkle package: the left subtree has the largest power
+// of two of leaves less than total.
+func auntSides(index, total int64) []bool {
+	if total <= 1 {
+		return []bool{}
+	}
+	split := int64(1) << (bits.Len64(uint64(total-1)) - 1)
+	if index < split {
+		return append(auntSides(index, split), false)
+	}
+	return append(auntSides(index-split, total-split), true)
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/crypto/sr25519"
+	tmbytes "github.com/tendermint/tendermint/libs/bytes"
+)
+
+var updateVectors = flag.Bool("update", false, "update the golden vectors in testdata")
+
+const validatorProofVectorsFile = "validator_proofs.json"
+
+// validatorProofVector is a golden vector of the proofs of a validator set.
+type validatorProofVector struct {
+	Name           string           `json:"name"`
+	ValidatorsHash tmbytes.HexBytes `json:"validators_hash"`
+	Proofs         []ValidatorProof `json:"proofs"`
+}
+
+// vectorValidatorSet returns a set of n validators with deterministic keys,
+// cycling through the key types, and voting powers.
+func vectorValidatorSet(n int) *ValidatorSet {
+	vals := make([]*Validator, n)
+	for i := range vals {
+		secret := []byte(fmt.Sprintf("validator %d", i))
+		var pk crypto.PubKey
+		switch i % 3 {
+		case 0:
+			pk = ed25519.GenPrivKeyFromSecret(secret).PubKey()
+		case 1:
+			pk = secp256k1.GenPrivKeySecp256k1(secret).PubKey()
+		case 2:
+			pk = sr25519.GenPrivKeyFromSecret(secret).PubKey()
+		}
+		// Some voting powers are equal, so that the validators are also
+		// sorted by address.
+		vals[i] = NewValidator(pk, int64(10+(i%4)*1000))
+	}
+	return NewValidatorSet(vals)
+}
+
+func TestValidatorProofVectors(t *testing.T) {
+	var vectors []validatorProofVector
+	for _, n := range []int{1, 2, 3, 4, 5, 7, 8, 13} {
+		vals := vectorValidatorSet(n)
+		root, proofs := vals.Proofs()
+		require.Equal(t, vals.Hash(), root)
+		vectors = append(vectors, validatorProofVector{
+			Name:           fmt.Sprintf("%d-validator set", n),
+			ValidatorsHash: root,
+			Proofs:         proofs,
+		})
+	}
+
+	path := filepath.Join("testdata", validatorProofVectorsFile)
+	if *updateVectors {
+		bz, err := json.MarshalIndent(vectors, "", "  ")
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll("testdata", 0755))
+		require.NoError(t, os.WriteFile(path, append(bz, '\n'), 0644))
+		return
+	}
+
+	// The proofs must not change: the golden vectors are what bridges and
+	// other implementations check against.
+	bz, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var golden []validatorProofVector
+	require.NoError(t, json.Unmarshal(bz, &golden))
+	require.Equal(t, vectors, golden, "%s is out of date, run the tests with -update", path)
+
+	for _, v := range golden {
+		for _, proof := range v.Proofs {
+			assert.NoError(t, proof.Validate(v.ValidatorsHash), v.Name)
+
+			// The proof verifies as documented, without the merkle package.
+			leaf := sha256.Sum256(append([]byte{0}, proof.Leaf...))
+			hash := leaf[:]
+			assert.Equal(t, proof.LeafHash, tmbytes.HexBytes(hash), v.Name)
+			for i, aunt := range proof.Aunts {
+				var inner [32]byte
+				if proof.Left[i] {
+					inner = sha256.Sum256(append(append([]byte{1}, aunt...), hash...))
+				} else {
+					inner = sha256.Sum256(append(append([]byte{1}, hash...), aunt...))
+				}
+				hash = inner[:]
+			}
+			assert.Equal(t, v.ValidatorsHash, tmbytes.HexBytes(hash), v.Name)
+		}
+	}
+}
+
+func TestValidatorProofValidate(t *testing.T) {
+	vals := vectorValidatorSet(5)
+	root, proofs := vals.Proofs()
+	require.Len(t, proofs, 5)
+	for _, proof := range proofs {
+		require.NoError(t, proof.Validate(root))
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(*ValidatorProof)
+	}{
+		{"other root", func(vp *ValidatorProof) { vp.Index = 0; vp.Aunts = proofs[0].Aunts }},
+		{"voting power", func(vp *ValidatorProof) { vp.VotingPower++ }},
+		{"public key", func(vp *ValidatorProof) { vp.PubKey = proofs[0].PubKey }},
+		{"public key type", func(vp *ValidatorProof) { vp.PubKeyType = "ed25519" }},
+		{"address", func(vp *ValidatorProof) { vp.Address = proofs[0].Address }},
+		{"leaf", func(vp *ValidatorProof) { vp.Leaf = proofs[0].Leaf }},
+		{"invalid leaf", func(vp *ValidatorProof) { vp.Leaf = []byte("leaf") }},
+		{"side", func(vp *ValidatorProof) { vp.Left[0] = !vp.Left[0] }},
+		{"missing side", func(vp *ValidatorProof) { vp.Left = vp.Left[1:] }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vp := proofs[2]
+			vp.Left = append([]bool(nil), vp.Left...)
+			tc.mutate(&vp)
+			assert.Error(t, vp.Validate(root))
+		})
+	}
+}
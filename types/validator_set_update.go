@@ -0,0 +1,192 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/encoding"
+	"github.com/tendermint/tendermint/crypto/secp256k1"
+	"github.com/tendermint/tendermint/crypto/sr25519"
+)
+
+// ValidatorSetUpdateTxPrefix prefixes the transactions carrying a signed
+// ValidatorSetUpdate.
+const ValidatorSetUpdateTxPrefix = "valset:"
+
+// ValidatorSetUpdate is a set of validator updates computed outside of the
+// ABCI application, e.g. by an external staking module, and signed by a
+// validator authority.
+//
+// The update reaches the chain as a transaction (see Tx), which the
+// application verifies against the authority and turns into the validator
+// updates it returns from EndBlock at EffectiveHeight. They thus take effect
+// at EffectiveHeight+2, on every node, including the nodes syncing later.
+type ValidatorSetUpdate struct {
+	ChainID         string                 `json:"chain_id"`
+	EffectiveHeight int64                  `json:"effective_height"`
+	Updates         []abci.ValidatorUpdate `json:"updates"`
+}
+
+// validatorSetUpdateSignDoc is the canonical, deterministic JSON document
+// signed by the validator authority.
+type validatorSetUpdateSignDoc struct {
+	ChainID         string                    `json:"chain_id"`
+	EffectiveHeight int64                     `json:"effective_height"`
+	Updates         []validatorUpdateSignItem `json:"updates"`
+}
+
+type validatorUpdateSignItem struct {
+	PubKeyType string `json:"pub_key_type"`
+	PubKey     []byte `json:"pub_key"`
+	Power      int64  `json:"power"`
+}
+
+// validatorSetUpdateTx is the payload of the transactions carrying an update.
+type validatorSetUpdateTx struct {
+	validatorSetUpdateSignDoc
+	Signature []byte `json:"signature"`
+}
+
+// ValidateBasic performs stateless validation of the update.
+func (u ValidatorSetUpdate) ValidateBasic() error {
+	if u.ChainID == "" {
+		return errors.New("empty chain ID")
+	}
+	if u.EffectiveHeight <= 0 {
+		return fmt.Errorf("effective height must be positive, got %d", u.EffectiveHeight)
+	}
+	if len(u.Updates) == 0 {
+		return errors.New("no validator updates")
+	}
+
+	seen := make(map[string]struct{}, len(u.Updates))
+	for i, vu := range u.Updates {
+		if vu.Power < 0 {
+			return fmt.Errorf("update #%d has negative power %d", i, vu.Power)
+		}
+		pk, err := encoding.PubKeyFromProto(vu.PubKey)
+		if err != nil {
+			return fmt.Errorf("update #%d: %w", i, err)
+		}
+		addr := string(pk.Address())
+		if _, ok := seen[addr]; ok {
+			return fmt.Errorf("update #%d: duplicate validator %X", i, pk.Address())
+		}
+		seen[addr] = struct{}{}
+	}
+	return nil
+}
+
+// SignBytes returns the bytes the validator authority signs.
+func (u ValidatorSetUpdate) SignBytes() ([]byte, error) {
+	doc, err := u.signDoc()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func (u ValidatorSetUpdate) signDoc() (validatorSetUpdateSignDoc, error) {
+	doc := validatorSetUpdateSignDoc{
+		ChainID:         u.ChainID,
+		EffectiveHeight: u.EffectiveHeight,
+		Updates:         make([]validatorUpdateSignItem, len(u.Updates)),
+	}
+	for i, vu := range u.Updates {
+		pk, err := encoding.PubKeyFromProto(vu.PubKey)
+		if err != nil {
+			return validatorSetUpdateSignDoc{}, err
+		}
+		doc.Updates[i] = validatorUpdateSignItem{
+			PubKeyType: pk.Type(),
+			PubKey:     pk.Bytes(),
+			Power:      vu.Power,
+		}
+	}
+	return doc, nil
+}
+
+// Verify checks that sig is a valid signature of the update by authority.
+func (u ValidatorSetUpdate) Verify(authority crypto.PubKey, sig []byte) error {
+	if authority == nil {
+		return errors.New("no validator authority configured")
+	}
+	bz, err := u.SignBytes()
+	if err != nil {
+		return err
+	}
+	if !authority.VerifySignature(bz, sig) {
+		return errors.New("invalid validator authority signature")
+	}
+	return nil
+}
+
+// Tx returns the transaction carrying the update signed by the validator
+// authority with sig.
+func (u ValidatorSetUpdate) Tx(sig []byte) (Tx, error) {
+	doc, err := u.signDoc()
+	if err != nil {
+		return nil, err
+	}
+	bz, err := json.Marshal(validatorSetUpdateTx{validatorSetUpdateSignDoc: doc, Signature: sig})
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(ValidatorSetUpdateTxPrefix), bz...), nil
+}
+
+// IsValidatorSetUpdateTx reports whether tx carries a validator set update.
+func IsValidatorSetUpdateTx(tx Tx) bool {
+	return bytes.HasPrefix(tx, []byte(ValidatorSetUpdateTxPrefix))
+}
+
+// ValidatorSetUpdateFromTx decodes the update carried by tx and the signature
+// of the validator authority. The caller must still validate and verify the
+// update.
+func ValidatorSetUpdateFromTx(tx Tx) (ValidatorSetUpdate, []byte, error) {
+	if !IsValidatorSetUpdateTx(tx) {
+		return ValidatorSetUpdate{}, nil, errors.New("not a validator set update transaction")
+	}
+
+	var payload validatorSetUpdateTx
+	if err := json.Unmarshal(tx[len(ValidatorSetUpdateTxPrefix):], &payload); err != nil {
+		return ValidatorSetUpdate{}, nil, fmt.Errorf("decoding validator set update: %w", err)
+	}
+
+	update := ValidatorSetUpdate{
+		ChainID:         payload.ChainID,
+		EffectiveHeight: payload.EffectiveHeight,
+		Updates:         make([]abci.ValidatorUpdate, len(payload.Updates)),
+	}
+	for i, item := range payload.Updates {
+		var (
+			pk   crypto.PubKey
+			size int
+		)
+		switch item.PubKeyType {
+		case ed25519.KeyType:
+			pk, size = ed25519.PubKey(item.PubKey), ed25519.PubKeySize
+		case secp256k1.KeyType:
+			pk, size = secp256k1.PubKey(item.PubKey), secp256k1.PubKeySize
+		case sr25519.KeyType:
+			pk, size = sr25519.PubKey(item.PubKey), sr25519.PubKeySize
+		default:
+			return ValidatorSetUpdate{}, nil, fmt.Errorf("update #%d: unsupported key type %q", i, item.PubKeyType)
+		}
+		if len(item.PubKey) != size {
+			return ValidatorSetUpdate{}, nil, fmt.Errorf("update #%d: %s public key must be %d bytes, got %d",
+				i, item.PubKeyType, size, len(item.PubKey))
+		}
+		pkp, err := encoding.PubKeyToProto(pk)
+		if err != nil {
+			return ValidatorSetUpdate{}, nil, fmt.Errorf("update #%d: %w", i, err)
+		}
+		update.Updates[i] = abci.ValidatorUpdate{PubKey: pkp, Power: item.Power}
+	}
+	return update, payload.Signature, nil
+}
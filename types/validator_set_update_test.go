@@ -0,0 +1,102 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	"github.com/tendermint/tendermint/crypto/ed25519"
+	"github.com/tendermint/tendermint/crypto/encoding"
+)
+
+func TestValidatorSetUpdateVerify(t *testing.T) {
+	authority := ed25519.GenPrivKey()
+
+	pk, err := encoding.PubKeyToProto(ed25519.GenPrivKey().PubKey())
+	require.NoError(t, err)
+
+	update := ValidatorSetUpdate{
+		ChainID:         "test-chain",
+		EffectiveHeight: 10,
+		Updates:         []abci.ValidatorUpdate{{PubKey: pk, Power: 5}},
+	}
+	require.NoError(t, update.ValidateBasic())
+
+	bz, err := update.SignBytes()
+	require.NoError(t, err)
+	sig, err := authority.Sign(bz)
+	require.NoError(t, err)
+
+	assert.NoError(t, update.Verify(authority.PubKey(), sig))
+	assert.Error(t, update.Verify(ed25519.GenPrivKey().PubKey(), sig))
+	assert.Error(t, update.Verify(nil, sig))
+
+	tampered := update
+	tampered.EffectiveHeight = 11
+	assert.Error(t, tampered.Verify(authority.PubKey(), sig))
+}
+
+func TestValidatorSetUpdateValidateBasic(t *testing.T) {
+	pk, err := encoding.PubKeyToProto(ed25519.GenPrivKey().PubKey())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		update ValidatorSetUpdate
+		valid  bool
+	}{
+		{"valid", ValidatorSetUpdate{"c", 1, []abci.ValidatorUpdate{{PubKey: pk, Power: 1}}}, true},
+		{"removal", ValidatorSetUpdate{"c", 1, []abci.ValidatorUpdate{{PubKey: pk, Power: 0}}}, true},
+		{"no chain id", ValidatorSetUpdate{"", 1, []abci.ValidatorUpdate{{PubKey: pk, Power: 1}}}, false},
+		{"zero height", ValidatorSetUpdate{"c", 0, []abci.ValidatorUpdate{{PubKey: pk, Power: 1}}}, false},
+		{"no updates", ValidatorSetUpdate{"c", 1, nil}, false},
+		{"negative power", ValidatorSetUpdate{"c", 1, []abci.ValidatorUpdate{{PubKey: pk, Power: -1}}}, false},
+		{"duplicate", ValidatorSetUpdate{"c", 1, []abci.ValidatorUpdate{{PubKey: pk, Power: 1}, {PubKey: pk, Power: 2}}}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.update.ValidateBasic()
+			if tc.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatorSetUpdateTx(t *testing.T) {
+	authority := ed25519.GenPrivKey()
+
+	pk, err := encoding.PubKeyToProto(ed25519.GenPrivKey().PubKey())
+	require.NoError(t, err)
+
+	update := ValidatorSetUpdate{
+		ChainID:         "test-chain",
+		EffectiveHeight: 10,
+		Updates:         []abci.ValidatorUpdate{{PubKey: pk, Power: 5}},
+	}
+	bz, err := update.SignBytes()
+	require.NoError(t, err)
+	sig, err := authority.Sign(bz)
+	require.NoError(t, err)
+
+	tx, err := update.Tx(sig)
+	require.NoError(t, err)
+	require.True(t, IsValidatorSetUpdateTx(tx))
+
+	decoded, decodedSig, err := ValidatorSetUpdateFromTx(tx)
+	require.NoError(t, err)
+	assert.Equal(t, update, decoded)
+	assert.NoError(t, decoded.Verify(authority.PubKey(), decodedSig))
+
+	_, _, err = ValidatorSetUpdateFromTx(Tx("key=value"))
+	assert.Error(t, err)
+	_, _, err = ValidatorSetUpdateFromTx(Tx(ValidatorSetUpdateTxPrefix + "{"))
+	assert.Error(t, err)
+	_, _, err = ValidatorSetUpdateFromTx(Tx(ValidatorSetUpdateTxPrefix +
+		`{"updates":[{"pub_key_type":"ed25519","pub_key":"AAAA","power":1}]}`))
+	assert.Error(t, err)
+}
@@ -14,6 +14,10 @@ import (
 
 const (
 	nilVoteStr string = "nil-Vote"
+
+	// MaxVoteExtensionSize is the maximum size of the extension that an
+	// application can attach to a precommit.
+	MaxVoteExtensionSize int = 1024
 )
 
 var (
@@ -56,6 +60,10 @@ type Vote struct {
 	ValidatorAddress Address               `json:"validator_address"`
 	ValidatorIndex   int32                 `json:"validator_index"`
 	Signature        []byte                `json:"signature"`
+
+	// Extension is the data attached to a precommit for a block by the
+	// application (see ExtendVote). It is signed along with the vote.
+	Extension []byte `json:"extension,omitempty"`
 }
 
 // CommitSig converts the Vote to a CommitSig.
@@ -79,6 +87,7 @@ func (vote *Vote) CommitSig() CommitSig {
 		ValidatorAddress: vote.ValidatorAddress,
 		Timestamp:        vote.Timestamp,
 		Signature:        vote.Signature,
+		Extension:        vote.Extension,
 	}
 }
 
@@ -198,6 +207,15 @@ func (vote *Vote) ValidateBasic() error {
 		return fmt.Errorf("signature is too big (max: %d)", MaxSignatureSize)
 	}
 
+	if len(vote.Extension) > 0 {
+		if vote.Type != tmproto.PrecommitType || !vote.BlockID.IsComplete() {
+			return errors.New("extension is only allowed on precommits for a block")
+		}
+		if len(vote.Extension) > MaxVoteExtensionSize {
+			return fmt.Errorf("extension is too big (max: %d)", MaxVoteExtensionSize)
+		}
+	}
+
 	return nil
 }
 
@@ -217,6 +235,7 @@ func (vote *Vote) ToProto() *tmproto.Vote {
 		ValidatorAddress: vote.ValidatorAddress,
 		ValidatorIndex:   vote.ValidatorIndex,
 		Signature:        vote.Signature,
+		Extension:        vote.Extension,
 	}
 }
 
@@ -241,6 +260,7 @@ func VoteFromProto(pv *tmproto.Vote) (*Vote, error) {
 	vote.ValidatorAddress = pv.ValidatorAddress
 	vote.ValidatorIndex = pv.ValidatorIndex
 	vote.Signature = pv.Signature
+	vote.Extension = pv.Extension
 
 	return vote, vote.ValidateBasic()
 }
@@ -256,6 +256,16 @@ func TestVoteValidateBasic(t *testing.T) {
 		{"Invalid ValidatorIndex", func(v *Vote) { v.ValidatorIndex = -1 }, true},
 		{"Invalid Signature", func(v *Vote) { v.Signature = nil }, true},
 		{"Too big Signature", func(v *Vote) { v.Signature = make([]byte, MaxSignatureSize+1) }, true},
+		{"Extension", func(v *Vote) { v.Extension = make([]byte, MaxVoteExtensionSize) }, false},
+		{"Too big Extension", func(v *Vote) { v.Extension = make([]byte, MaxVoteExtensionSize+1) }, true},
+		{"Extension on prevote", func(v *Vote) {
+			v.Type = tmproto.PrevoteType
+			v.Extension = []byte("extension")
+		}, true},
+		{"Extension on nil precommit", func(v *Vote) {
+			v.BlockID = BlockID{}
+			v.Extension = []byte("extension")
+		}, true},
 	}
 	for _, tc := range testCases {
 		tc := tc